@@ -0,0 +1,1269 @@
+// Code generated by openapi-gen from the OpenAPI document. DO NOT EDIT.
+
+// OpenTrusty API client for the operations under /api/.
+
+/** AccountDeletionRequest asks to delete the current user's account */
+export interface AccountDeletionRequest {
+  /** The user's current password */
+  password?: string;
+}
+
+/** AccountDeletionResponse represents a pending account deletion */
+export interface AccountDeletionResponse {
+  /** DeleteAt ends the grace period; the account is erased then unless the user logs in or cancels before */
+  delete_at?: string;
+  requested_at?: string;
+}
+
+/** AddMemberRequest names the user to add to a tenant */
+export interface AddMemberRequest {
+  user_id: string;
+}
+
+/** AssignRoleRequest represents role assignment data */
+export interface AssignRoleRequest {
+  role: string;
+}
+
+/** AuditEventResponse represents a stored audit event */
+export interface AuditEventResponse {
+  actor_id?: string;
+  id?: string;
+  ip_address?: string;
+  metadata?: Record<string, unknown>;
+  /** X-Request-Id of the request that caused the event */
+  request_id?: string;
+  resource?: string;
+  /** 0 marks legacy free-form metadata */
+  schema_version?: number;
+  tenant_id?: string;
+  timestamp?: string;
+  type?: string;
+  user_agent?: string;
+}
+
+/** AuditRetentionRequest sets a tenant's audit retention */
+export interface AuditRetentionRequest {
+  retention_days?: number;
+}
+
+/** AuditRetentionResponse represents the audit retention that applies to a tenant */
+export interface AuditRetentionResponse {
+  /** The platform default applies */
+  inherited?: boolean;
+  /** Zero keeps events forever */
+  retention_days?: number;
+  tenant_id?: string;
+  updated_at?: string;
+  updated_by?: string;
+}
+
+/** ChangePasswordRequest represents password change data */
+export interface ChangePasswordRequest {
+  new_password: string;
+  old_password: string;
+}
+
+/** Check is one item of the clustering readiness checklist */
+export interface Check {
+  detail?: string;
+  name?: string;
+  /** pass or warn */
+  status?: string;
+}
+
+/** Client represents an OAuth2 client application */
+export interface Client {
+  access_token_lifetime?: number;
+  /** Browser origins that may call the token and revocation endpoints; empty derives them from RedirectURIs */
+  allowed_origins?: string[];
+  allowed_scopes?: string[];
+  /** web, native, spa or service; restricts the other metadata. Empty is unrestricted */
+  application_type?: string;
+  /** Clients of the tenant in the same group may be ID token audiences of each other */
+  client_group?: string;
+  client_id?: string;
+  client_name?: string;
+  client_uri?: string;
+  created_at?: string;
+  deleted_at?: string;
+  grant_types?: string[];
+  id?: string;
+  id_token_lifetime?: number;
+  is_active?: boolean;
+  is_trusted?: boolean;
+  /** Public keys verifying the client's request objects (RFC 7591 Section 2) */
+  jwks?: unknown;
+  /** https URL the client publishes its public keys at, instead of JWKS */
+  jwks_uri?: string;
+  logo_uri?: string;
+  /** Authorization requests must carry a state of at least this length; zero leaves state optional */
+  min_state_length?: number;
+  owner_id?: string;
+  redirect_uris?: string[];
+  refresh_token_lifetime?: number;
+  /** https URLs the server may fetch the client's request objects from */
+  request_uris?: string[];
+  /** Authorization requests must come in a request object (RFC 9101 Section 10.5) */
+  require_signed_request_object?: boolean;
+  response_types?: string[];
+  tenant_id?: string;
+  token_endpoint_auth_method?: string;
+  updated_at?: string;
+  /** Incremented on every update; Update requires it to match the stored version */
+  version?: number;
+}
+
+/** ClientKeysRequest replaces the public keys of a client: a JWKS, or the jwks_uri the client publishes its keys at. Leaving both empty removes the keys. */
+export interface ClientKeysRequest {
+  jwks?: unknown;
+  jwks_uri?: string;
+}
+
+/** ClientKeysResponse represents the public keys of a client */
+export interface ClientKeysResponse {
+  client_id?: string;
+  jwks?: unknown;
+  jwks_uri?: string;
+  /** Keys in use after an update; for a jwks_uri, as fetched then */
+  key_ids?: string[];
+}
+
+/** ClientStats counts the tokens issued to one client */
+export interface ClientStats {
+  client_id?: string;
+  tenant_id?: string;
+  tokens_issued?: number;
+}
+
+/** CreateTenantRequest represents tenant creation data */
+export interface CreateTenantRequest {
+  name: string;
+}
+
+/** EmailChangeRequest starts changing the current user's email address */
+export interface EmailChangeRequest {
+  new_email?: string;
+  /** The user's current password */
+  password?: string;
+}
+
+/** EmailChangeResponse represents an email change in progress */
+export interface EmailChangeResponse {
+  /** ExpiresAt ends the verification link of a pending change; a verified change takes effect then */
+  expires_at?: string;
+  new_email?: string;
+  old_email?: string;
+  /** pending until the new address is verified, then verified until the change takes effect */
+  status?: string;
+}
+
+/** EmailChangeTokenRequest carries the token of a link sent by the email change flow */
+export interface EmailChangeTokenRequest {
+  token?: string;
+}
+
+/** EmailSettingsRequest represents the tenant outbound email configuration */
+export interface EmailSettingsRequest {
+  enabled?: boolean;
+  from_address: string;
+  from_name?: string;
+  host: string;
+  password?: string;
+  port: number;
+  tls_mode?: string;
+  username?: string;
+}
+
+/** EmailSettingsResponse represents the tenant outbound email configuration without credentials */
+export interface EmailSettingsResponse {
+  enabled?: boolean;
+  from_address?: string;
+  from_name?: string;
+  has_password?: boolean;
+  host?: string;
+  port?: number;
+  tenant_id?: string;
+  tls_mode?: string;
+  updated_at?: string;
+  username?: string;
+}
+
+/** ForceLogoutResponse counts what a force-logout invalidated */
+export interface ForceLogoutResponse {
+  access_tokens_revoked?: number;
+  refresh_tokens_revoked?: number;
+  sessions_revoked?: number;
+}
+
+/** ListAuditEventsResponse is a page of audit events */
+export interface ListAuditEventsResponse {
+  events?: AuditEventResponse[];
+  next_cursor?: string;
+}
+
+/** ListClientsResponse is a page of OAuth2 clients */
+export interface ListClientsResponse {
+  clients?: Client[];
+  next_cursor?: string;
+}
+
+/** ListProjectsResponse is a page of projects */
+export interface ListProjectsResponse {
+  next_cursor?: string;
+  projects?: ProjectResponse[];
+}
+
+/** ListResult is a single page of tenants */
+export interface ListResult {
+  next_cursor?: string;
+  tenants?: Tenant[];
+}
+
+/** ListScopesResponse lists a tenant's custom scopes */
+export interface ListScopesResponse {
+  scopes?: ScopeResponse[];
+}
+
+/** ListWebhookDeliveriesResponse is a page of webhook deliveries */
+export interface ListWebhookDeliveriesResponse {
+  deliveries?: WebhookDeliveryResponse[];
+  next_cursor?: string;
+}
+
+/** ListWebhooksResponse lists a tenant's webhook subscriptions */
+export interface ListWebhooksResponse {
+  webhooks?: WebhookResponse[];
+}
+
+/** LoginRequest represents login credentials */
+export interface LoginRequest {
+  /** AccountID chooses among the accounts offered when the email is registered in several tenants */
+  account_id?: string;
+  /** AuthorizationRequest is the pending authorization request that sent the user to login. It implies an auth session, and the response's redirect_to resumes the request. */
+  authorization_request?: string;
+  /** CaptchaResponse is the solved challenge, once a login was answered with captcha_provider */
+  captcha_response?: string;
+  email: string;
+  /** MembershipID chooses the tenant of an admin session among those offered when the account is a member of several tenants */
+  membership_id?: string;
+  /** Namespace is the plane the session is for: "auth" to authorize OAuth2 clients or "admin" for the admin API. The auth plane only issues auth sessions; serving both planes, the default is admin. */
+  namespace?: string;
+  /** NewPassword replaces an expired password as part of the login */
+  new_password?: string;
+  password: string;
+}
+
+/** PlatformErrorRates are failure ratios between 0 and 1; zero when there were no attempts */
+export interface PlatformErrorRates {
+  /** client_auth_failed / (client_auth_failed + token_issued) */
+  client_auth?: number;
+  /** login_failed / (login_success + login_failed) */
+  login?: number;
+}
+
+/** PlatformStatsResponse aggregates platform activity over a window for the ops dashboard */
+export interface PlatformStatsResponse {
+  /** Distinct users with a successful login in the window */
+  active_users?: number;
+  error_rates?: PlatformErrorRates;
+  since?: string;
+  tenants?: TenantCounts;
+  /** Token responses issued in the window */
+  tokens_issued?: number;
+  /** Clients issued the most tokens, busiest first */
+  top_clients?: ClientStats[];
+  until?: string;
+  window?: string;
+}
+
+/** Profile represents user profile information */
+export interface Profile {
+  /** YYYY-MM-DD, 0000-MM-DD when the year is withheld, or YYYY alone */
+  Birthdate?: string;
+  FamilyName?: string;
+  FullName?: string;
+  GivenName?: string;
+  /** BCP 47 language tag, released as the locale claim */
+  Locale?: string;
+  Nickname?: string;
+  Picture?: string;
+  /** IANA time zone, released as the zoneinfo claim */
+  Timezone?: string;
+  Website?: string;
+}
+
+/** ProjectMemberRequest sets a user's role in a project */
+export interface ProjectMemberRequest {
+  role: string;
+}
+
+/** ProjectMemberResponse represents a user's role in a project */
+export interface ProjectMemberResponse {
+  email?: string;
+  granted_at?: string;
+  granted_by?: string;
+  project_id?: string;
+  role?: string;
+  user_id?: string;
+}
+
+/** ProjectRequest represents a project to create or update */
+export interface ProjectRequest {
+  description?: string;
+  name: string;
+}
+
+/** ProjectResponse represents a project */
+export interface ProjectResponse {
+  created_at?: string;
+  description?: string;
+  id?: string;
+  name?: string;
+  owner_id?: string;
+  tenant_id?: string;
+  updated_at?: string;
+}
+
+/** ProtocolLogRequest turns a tenant's protocol decision logging on or off */
+export interface ProtocolLogRequest {
+  enabled?: boolean;
+  /** Logging stops by itself after this time */
+  expires_at?: string;
+}
+
+/** ProtocolLogResponse represents a tenant's protocol decision logging */
+export interface ProtocolLogResponse {
+  /** Enabled and not yet expired */
+  active?: boolean;
+  enabled?: boolean;
+  expires_at?: string;
+  tenant_id?: string;
+  updated_at?: string;
+  updated_by?: string;
+}
+
+/** RegisterClientRequest represents the data for registering a new OAuth2 client */
+export interface RegisterClientRequest {
+  /** AllowedOrigins may call the token and revocation endpoints from a browser; empty uses the redirect URI origins */
+  allowed_origins?: string[];
+  allowed_scopes?: string[];
+  /** ApplicationType is web, native, spa or service, and restricts the other metadata to what the type needs */
+  application_type?: string;
+  /** ClientGroup names related clients of the tenant, such as a mobile and a web app sharing a backend, that may request ID tokens for each other */
+  client_group?: string;
+  client_name: string;
+  grant_types?: string[];
+  jwks?: unknown;
+  /** Where the client publishes its public keys, instead of jwks */
+  jwks_uri?: string;
+  /** MinStateLength makes authorization requests carry a random state of at least this many characters (8 to 256); zero leaves state optional */
+  min_state_length?: number;
+  redirect_uris: string[];
+  request_uris?: string[];
+  /** Refuse authorization requests without a signed request object (RFC 9101) */
+  require_signed_request_object?: boolean;
+  response_types?: string[];
+  token_endpoint_auth_method?: string;
+}
+
+/** RegisterClientResponse represents the response after registering a client */
+export interface RegisterClientResponse {
+  client_id?: string;
+  client_name?: string;
+  client_secret?: string;
+}
+
+/** RegisterRequest represents registration data */
+export interface RegisterRequest {
+  email: string;
+  family_name?: string;
+  given_name?: string;
+  password: string;
+}
+
+/** Replica is the announcement of one replica */
+export interface Replica {
+  /** Fingerprint digests the signing and published kids; replicas serving the same keys have the same fingerprint */
+  fingerprint?: string;
+  id?: string;
+  last_seen?: string;
+  published_key_ids?: string[];
+  /** SigningKeyID is the kid of the key signing new tokens; PublishedKeyIDs are the kids in its JWKS, sorted */
+  signing_key_id?: string;
+}
+
+/** Report is the clustering state as seen by one replica */
+export interface Report {
+  checks?: Check[];
+  /** Consistent is false when the listed replicas do not all have the same key fingerprint */
+  consistent?: boolean;
+  replica_id?: string;
+  replicas?: Replica[];
+}
+
+/** ScopeRequest represents a tenant's custom OAuth2 scope */
+export interface ScopeRequest {
+  /** User claims released in the ID token */
+  claims?: string[];
+  description: string;
+  /** Create only; the name cannot be changed */
+  name?: string;
+  /** Reported by token introspection */
+  permissions?: string[];
+}
+
+/** ScopeResponse represents a custom scope */
+export interface ScopeResponse {
+  claims?: string[];
+  created_at?: string;
+  description?: string;
+  id?: string;
+  name?: string;
+  permissions?: string[];
+  tenant_id?: string;
+  updated_at?: string;
+}
+
+/** SecurityPolicyResponse represents a tenant's security policy and the settings in force */
+export interface SecurityPolicyResponse {
+  /** The tenant's settings over the platform's */
+  effective?: SecuritySettings;
+  /** The loosest settings a tenant may choose */
+  platform?: SecuritySettings;
+  /** The tenant's own settings */
+  settings?: SecuritySettings;
+  /** The strictest settings a tenant may choose */
+  strictest?: SecuritySettings;
+  tenant_id?: string;
+  updated_at?: string;
+  updated_by?: string;
+}
+
+/** SecuritySettings are a tenant's lockout, rate limit and password age settings; in a request, settings left out inherit the platform's */
+export interface SecuritySettings {
+  /** How long a locked account stays locked */
+  lockout_duration_seconds?: number;
+  /** Failed logins that lock an account */
+  lockout_max_attempts?: number;
+  login_burst?: number;
+  /** Login attempts per second for one user */
+  login_rps?: number;
+  /** Password age after which the user must change it at the next login */
+  password_max_age_seconds?: number;
+  token_burst?: number;
+  /** Token endpoint requests per second for one OAuth2 client */
+  token_rps?: number;
+}
+
+/** SendTestEmailRequest represents a request to send a test email */
+export interface SendTestEmailRequest {
+  to: string;
+}
+
+/** SessionRenewalResponse reports the lifetime left to a renewed console session */
+export interface SessionRenewalResponse {
+  /** ExpiresAt is the absolute expiry, which renewal never extends; the user must sign in again then */
+  expires_at?: string;
+  /** ExpiresIn is the number of seconds until the session ends without further activity */
+  expires_in?: number;
+  /** IdleExpiresAt is when the session ends without further activity */
+  idle_expires_at?: string;
+}
+
+/** SetUserPasswordRequest sets a user's password */
+export interface SetUserPasswordRequest {
+  /** The password is temporary: the user must change it at the next login */
+  must_change?: boolean;
+  password?: string;
+}
+
+/** Tenant represents an isolated environment or customer account */
+export interface Tenant {
+  created_at?: string;
+  id?: string;
+  name?: string;
+  status?: string;
+  updated_at?: string;
+}
+
+/** TenantCounts counts the tenants that are not deleted */
+export interface TenantCounts {
+  active?: number;
+  total?: number;
+}
+
+/** TenantMemberResponse is a member of a tenant */
+export interface TenantMemberResponse {
+  created_at?: string;
+  /** Platform admin who added the member */
+  created_by?: string;
+  email?: string;
+  /** The user is registered in this tenant; false for users added from another tenant */
+  home?: boolean;
+  id?: string;
+  tenant_id?: string;
+  user_id?: string;
+}
+
+/** TenantUserRoleResponse is a role assignment and who granted it */
+export interface TenantUserRoleResponse {
+  granted_at?: string;
+  /** User ID of the granter, or "system" for bootstrap, CLI and legacy grants */
+  granted_by?: string;
+  /** Email of the granting user, while that user exists */
+  granted_by_email?: string;
+  id?: string;
+  role?: string;
+  tenant_id?: string;
+  user_id?: string;
+}
+
+/** UserLockoutResponse represents a user's failed-login state */
+export interface UserLockoutResponse {
+  failed_login_attempts?: number;
+  locked?: boolean;
+  /** Only set while locked */
+  locked_until?: string;
+  password_expired?: boolean;
+  password_expires_at?: string;
+  user_id?: string;
+}
+
+/** WebhookDeliveryResponse represents one delivery of an event and its latest attempt */
+export interface WebhookDeliveryResponse {
+  attempts?: number;
+  created_at?: string;
+  event_id?: string;
+  event_type?: string;
+  id?: string;
+  last_attempt_at?: string;
+  last_error?: string;
+  /** Only set while pending */
+  next_attempt_at?: string;
+  /** The signed request body */
+  payload?: unknown;
+  response_status?: number;
+  /** pending, succeeded or dead */
+  status?: string;
+}
+
+/** WebhookRequest represents a tenant webhook subscription */
+export interface WebhookRequest {
+  description?: string;
+  enabled?: boolean;
+  /** Empty subscribes to all events */
+  event_types?: string[];
+  /** Update only: issue a new signing secret */
+  rotate_secret?: boolean;
+  url: string;
+}
+
+/** WebhookResponse represents a webhook subscription without its signing secret */
+export interface WebhookResponse {
+  created_at?: string;
+  created_by?: string;
+  description?: string;
+  enabled?: boolean;
+  event_types?: string[];
+  id?: string;
+  /** Secret is only returned when the subscription is created or its secret is rotated */
+  secret?: string;
+  tenant_id?: string;
+  updated_at?: string;
+  url?: string;
+}
+
+/** Thrown for responses with a non-2xx status; body is the decoded error response, if any. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(`request failed with status ${status}`);
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Server URL, e.g. "https://auth.example.com"; empty for same-origin requests. */
+  baseUrl?: string;
+  /** fetch implementation; defaults to the global fetch. */
+  fetch?: typeof fetch;
+  /** Headers sent with every request. */
+  headers?: Record<string, string>;
+}
+
+type Params = Record<string, string | number | boolean | undefined>;
+
+interface Body {
+  type: string;
+  value: unknown;
+}
+
+/** Requests carry the browser's session cookie (credentials: "include"). */
+export class AdminClient {
+  private readonly baseUrl: string;
+  private readonly fetch: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseUrl = (options.baseUrl ?? "").replace(/\/+$/, "");
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+    this.headers = options.headers ?? {};
+  }
+
+  /**
+   * Login
+   *
+   * Authenticate admin user and create a session (tenant derived from user record, or the membership chosen for an admin session). With authorization_request, redirect_to resumes the pending authorization request. An expired or temporary password gets password_change_required and a session that can only change it, unless new_password replaces it in the same request.
+   */
+  login(body: LoginRequest): Promise<Record<string, unknown>> {
+    return this.request("POST", `/api/v1/auth/login`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Logout
+   *
+   * Destroy the current session
+   */
+  logout(params: { "X-Tenant-ID": string }): Promise<Record<string, string>> {
+    return this.request("POST", `/api/v1/auth/logout`, {}, { "X-Tenant-ID": params["X-Tenant-ID"] });
+  }
+
+  /**
+   * Get current user
+   *
+   * Get the current authenticated user's information
+   */
+  getCurrentUser(): Promise<Record<string, unknown>> {
+    return this.request("GET", `/api/v1/auth/me`, {}, {});
+  }
+
+  /**
+   * Renew console session
+   *
+   * Extend the idle timeout of the current admin session (sliding expiry) for a console user still at work and return how long it has left. The absolute lifetime is never extended. A renewal from another address or user agent than the session last saw is audited. A session restricted to a password change is not renewed (403).
+   */
+  refreshSession(): Promise<SessionRenewalResponse> {
+    return this.request("POST", `/api/v1/auth/refresh-session`, {}, {});
+  }
+
+  /**
+   * Register a new user (DISABLED)
+   *
+   * Anonymous registration is disabled for security; admins must be provisioned by platform admins
+   */
+  register(body: RegisterRequest): Promise<Record<string, unknown>> {
+    return this.request("POST", `/api/v1/auth/register`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Revert Email Change
+   *
+   * Keep the current email address by cancelling a verified change during its cooling-off window, with the token mailed to the old address
+   */
+  revertEmailChange(body: EmailChangeTokenRequest): Promise<Record<string, string>> {
+    return this.request("POST", `/api/v1/email-change/revert`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Verify Email Change
+   *
+   * Verify a new email address with the token mailed to it. The address changes at once, or, with a cooling-off window, when the window ends unless the old address reverts it.
+   */
+  verifyEmailChange(body: EmailChangeTokenRequest): Promise<EmailChangeResponse> {
+    return this.request("POST", `/api/v1/email-change/verify`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Get Platform Stats
+   *
+   * Aggregates platform activity from the audit trail for the ops dashboard: tenant counts, distinct users who logged in, tokens issued, login and client authentication failure rates, and the ten clients issued the most tokens.
+   * window is 1h, 24h (default), 7d or 30d, ending now. On CockroachDB the figures are follower reads and may miss the last few seconds.
+   */
+  getPlatformStats(params: { window?: string } = {}): Promise<PlatformStatsResponse> {
+    return this.request("GET", `/api/v1/platform/stats`, { window: params.window }, {});
+  }
+
+  /**
+   * Get Cluster Status
+   *
+   * Lists the clustering readiness checks of this replica's configuration (signing keys, sessions, rate limits, replay store and event bus, each "pass" or "warn") and the replicas heard from on the event bus in the last three key reload intervals, with the kids they sign and publish.
+   * consistent is false when the replicas' key fingerprints differ, that is when they serve different JWKS documents. Right after a rotation this lasts until every replica has reloaded its keys.
+   */
+  getClusterStatus(): Promise<Report> {
+    return this.request("GET", `/api/v1/system/cluster`, {}, {});
+  }
+
+  /**
+   * List Tenants
+   *
+   * List platform tenants with cursor pagination, name search and status filtering (Platform Admin Only)
+   */
+  listTenants(params: { limit?: number; cursor?: string; q?: string; status?: "active" | "inactive"; sort?: "created_at_desc" | "created_at_asc" | "name_asc" | "name_desc" } = {}): Promise<ListResult> {
+    return this.request("GET", `/api/v1/tenants`, { limit: params.limit, cursor: params.cursor, q: params.q, status: params.status, sort: params.sort }, {});
+  }
+
+  /**
+   * Create Tenant
+   *
+   * Create a new platform tenant (Platform Admin Only)
+   */
+  createTenant(body: CreateTenantRequest): Promise<Tenant> {
+    return this.request("POST", `/api/v1/tenants`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * List Audit Events
+   *
+   * List a tenant's audit events oldest first with filters and cursor pagination
+   */
+  listAuditEvents(tenantID: string, params: { type?: string; actor_id?: string; since?: string; until?: string; limit?: number; cursor?: string } = {}): Promise<ListAuditEventsResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/audit-events`, { type: params.type, actor_id: params.actor_id, since: params.since, until: params.until, limit: params.limit, cursor: params.cursor }, {});
+  }
+
+  /**
+   * Delete Audit Retention
+   *
+   * Remove the tenant's retention override so the platform default applies again. Platform administrators only.
+   */
+  deleteAuditRetention(tenantID: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/audit-retention`, {}, {});
+  }
+
+  /**
+   * Get Audit Retention
+   *
+   * Get how long the tenant's audit events are kept before they are purged
+   */
+  getAuditRetention(tenantID: string): Promise<AuditRetentionResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/audit-retention`, {}, {});
+  }
+
+  /**
+   * Update Audit Retention
+   *
+   * Override the platform audit retention for a tenant, e.g. seven years for a regulated tenant. Zero keeps events forever. Platform administrators only.
+   */
+  updateAuditRetention(tenantID: string, body: AuditRetentionRequest): Promise<AuditRetentionResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/audit-retention`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * List Clients
+   *
+   * List a tenant's OAuth2 clients in creation order with cursor pagination
+   */
+  listClients(tenantID: string, params: { limit?: number; cursor?: string } = {}): Promise<ListClientsResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/clients`, { limit: params.limit, cursor: params.cursor }, {});
+  }
+
+  /**
+   * Register Client
+   *
+   * Register a new OAuth2 client for the tenant
+   */
+  registerClient(tenantID: string, body: RegisterClientRequest): Promise<RegisterClientResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/clients`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Force Logout Client
+   *
+   * Revoke every unexpired access and refresh token issued to the client and destroy the sessions of the users holding them, in batches. Progress is logged per batch; a failure part way keeps what was already revoked.
+   */
+  forceLogoutClient(tenantID: string, clientID: string): Promise<ForceLogoutResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/clients/${encodeURIComponent(clientID)}/force-logout`, {}, {});
+  }
+
+  /**
+   * Get Client Keys
+   *
+   * Get the JWKS or jwks_uri that verifies the client's request objects
+   */
+  getClientKeys(tenantID: string, clientID: string): Promise<ClientKeysResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/clients/${encodeURIComponent(clientID)}/jwks`, {}, {});
+  }
+
+  /**
+   * Update Client Keys
+   *
+   * Upload or rotate the client's public keys with a jwks, or set the jwks_uri the client publishes them at. A jwks_uri is fetched at once and then refetched every 5 minutes, or sooner for an unknown kid. Keys must be public RSA (2048 to 8192 bits) or EC P-256 keys, at most 10 with distinct kid values. To rotate, publish the new key next to the old one until clients sign with it.
+   */
+  updateClientKeys(tenantID: string, clientID: string, body: ClientKeysRequest): Promise<ClientKeysResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/clients/${encodeURIComponent(clientID)}/jwks`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Delete Email Settings
+   *
+   * Remove tenant SMTP credentials and fall back to the platform default sender
+   */
+  deleteEmailSettings(tenantID: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/email-settings`, {}, {});
+  }
+
+  /**
+   * Get Email Settings
+   *
+   * Get the tenant's outbound email configuration. Credentials are never returned.
+   */
+  getEmailSettings(tenantID: string): Promise<EmailSettingsResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/email-settings`, {}, {});
+  }
+
+  /**
+   * Update Email Settings
+   *
+   * Configure tenant SMTP credentials. Omit password to keep the stored credential. tls_mode "none" is refused with a username or password.
+   */
+  updateEmailSettings(tenantID: string, body: EmailSettingsRequest): Promise<EmailSettingsResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/email-settings`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Send Test Email
+   *
+   * Send a test message using the tenant's stored SMTP settings, even if they are not yet enabled
+   */
+  sendTestEmail(tenantID: string, body: SendTestEmailRequest): Promise<Record<string, string>> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/email-settings/test`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Force Logout Tenant
+   *
+   * Destroy every session of the tenant and revoke every unexpired access and refresh token issued in it, in batches. Progress is logged per batch; a failure part way keeps what was already revoked.
+   */
+  forceLogoutTenant(tenantID: string): Promise<ForceLogoutResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/force-logout`, {}, {});
+  }
+
+  /**
+   * List Tenant Members
+   *
+   * List the users who are members of a tenant: those registered in it and those added from other tenants. Only members can sign in to the tenant and be granted roles in it.
+   */
+  listTenantMembers(tenantID: string): Promise<TenantMemberResponse[]> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/members`, {}, {});
+  }
+
+  /**
+   * Add Tenant Member
+   *
+   * Make a user registered in another tenant a member of this tenant, so they can sign in to it and be granted roles in it (Platform Admin Only). The user is sent an invitation through the tenant's email sender. Platform users, who belong to no tenant, cannot be added.
+   */
+  addTenantMember(tenantID: string, body: AddMemberRequest): Promise<TenantMemberResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/members`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Remove Tenant Member
+   *
+   * Remove a user added from another tenant, revoking their roles in this tenant. Users cannot be removed from the tenant they are registered in, and the tenant's last owner cannot be removed; both are refused with 409.
+   */
+  removeTenantMember(tenantID: string, userID: string): Promise<Record<string, string>> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/members/${encodeURIComponent(userID)}`, {}, {});
+  }
+
+  /**
+   * List Projects
+   *
+   * List a tenant's projects with cursor pagination. Users without tenant:manage_projects see only the projects they have a role in, in a single page.
+   */
+  listProjects(tenantID: string, params: { limit?: number; cursor?: string } = {}): Promise<ListProjectsResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects`, { limit: params.limit, cursor: params.cursor }, {});
+  }
+
+  /**
+   * Create Project
+   *
+   * Create a project in the tenant. The creator becomes its owner and a project admin.
+   */
+  createProject(tenantID: string, body: ProjectRequest): Promise<ProjectResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Delete Project
+   *
+   * Delete a project (requires project:manage). Its members lose their project roles.
+   */
+  deleteProject(tenantID: string, projectID: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects/${encodeURIComponent(projectID)}`, {}, {});
+  }
+
+  /**
+   * Get Project
+   *
+   * Get a project of the tenant (requires project:view)
+   */
+  getProject(tenantID: string, projectID: string): Promise<ProjectResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects/${encodeURIComponent(projectID)}`, {}, {});
+  }
+
+  /**
+   * Update Project
+   *
+   * Replace a project's name and description (requires project:manage)
+   */
+  updateProject(tenantID: string, projectID: string, body: ProjectRequest): Promise<ProjectResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects/${encodeURIComponent(projectID)}`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * List Project Members
+   *
+   * List the users holding a role in a project (requires project:view)
+   */
+  listProjectMembers(tenantID: string, projectID: string): Promise<ProjectMemberResponse[]> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects/${encodeURIComponent(projectID)}/members`, {}, {});
+  }
+
+  /**
+   * Remove Project Member
+   *
+   * Remove a user's role in a project (requires project:manage_members). Removing the last project admin is refused with 409.
+   */
+  removeProjectMember(tenantID: string, projectID: string, userID: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects/${encodeURIComponent(projectID)}/members/${encodeURIComponent(userID)}`, {}, {});
+  }
+
+  /**
+   * Set Project Member
+   *
+   * Give a member of the tenant a role in a project, replacing the role they had (requires project:manage_members). project_admin manages the project and its members; project_member can view it. Demoting the last project admin is refused with 409.
+   */
+  setProjectMember(tenantID: string, projectID: string, userID: string, body: ProjectMemberRequest): Promise<ProjectMemberResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/projects/${encodeURIComponent(projectID)}/members/${encodeURIComponent(userID)}`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Get Protocol Logging
+   *
+   * Get whether the tenant's authorization and token decisions are logged
+   */
+  getProtocolLog(tenantID: string): Promise<ProtocolLogResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/protocol-logging`, {}, {});
+  }
+
+  /**
+   * Update Protocol Logging
+   *
+   * Log the client, scopes, decision and error code of the tenant's authorization and token requests, correlated by request ID, to debug a client integration. Codes, tokens and secrets are never logged. Set expires_at, at most 30 days ahead, to stop logging by itself.
+   */
+  updateProtocolLog(tenantID: string, body: ProtocolLogRequest): Promise<ProtocolLogResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/protocol-logging`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * List Scopes
+   *
+   * List the tenant's custom OAuth2 scopes
+   */
+  listScopes(tenantID: string): Promise<ListScopesResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/scopes`, {}, {});
+  }
+
+  /**
+   * Create Scope
+   *
+   * Define a custom OAuth2 scope that the tenant's clients can be allowed to request. Standard scopes such as openid and profile cannot be redefined.
+   */
+  createScope(tenantID: string, body: ScopeRequest): Promise<ScopeResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/scopes`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Delete Scope
+   *
+   * Remove a custom scope. Clients that still allow it can no longer request it.
+   */
+  deleteScope(tenantID: string, scopeName: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/scopes/${encodeURIComponent(scopeName)}`, {}, {});
+  }
+
+  /**
+   * Get Scope
+   *
+   * Get one of the tenant's custom scopes by name
+   */
+  getScope(tenantID: string, scopeName: string): Promise<ScopeResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/scopes/${encodeURIComponent(scopeName)}`, {}, {});
+  }
+
+  /**
+   * Update Scope
+   *
+   * Replace a scope's description, claims and permissions. Tokens already issued with the scope report the new permissions when introspected.
+   */
+  updateScope(tenantID: string, scopeName: string, body: ScopeRequest): Promise<ScopeResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/scopes/${encodeURIComponent(scopeName)}`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Delete Security Policy
+   *
+   * Remove the tenant's security policy so the platform's settings apply again
+   */
+  deleteSecurityPolicy(tenantID: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/security-policy`, {}, {});
+  }
+
+  /**
+   * Get Security Policy
+   *
+   * Get the tenant's lockout and rate limit settings, those in force, and the range the platform allows
+   */
+  getSecurityPolicy(tenantID: string): Promise<SecurityPolicyResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/security-policy`, {}, {});
+  }
+
+  /**
+   * Update Security Policy
+   *
+   * Tighten the platform's account lockout and login and token endpoint rate limits for the tenant. Each setting must lie between the platform's and the strictest allowed; settings left out inherit the platform's. Changes reach every instance within 30 seconds.
+   */
+  updateSecurityPolicy(tenantID: string, body: SecuritySettings): Promise<SecurityPolicyResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/security-policy`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * List Tenant Users
+   *
+   * List all users and their roles in a tenant, with who granted each role. granted_by is the granting user's ID, or "system" for roles granted by bootstrap, the CLI or before granters were recorded.
+   */
+  listTenantUsers(tenantID: string): Promise<TenantUserRoleResponse[]> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/users`, {}, {});
+  }
+
+  /**
+   * Unlock User
+   *
+   * Clear a user's failed login attempts and lockout
+   */
+  unlockUser(tenantID: string, userID: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/users/${encodeURIComponent(userID)}/lockout`, {}, {});
+  }
+
+  /**
+   * Get User Lockout
+   *
+   * Show a user's failed login attempts, lockout and password expiry
+   */
+  getUserLockout(tenantID: string, userID: string): Promise<UserLockoutResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/users/${encodeURIComponent(userID)}/lockout`, {}, {});
+  }
+
+  /**
+   * Set User Password
+   *
+   * Replace a user's password, clearing a forced password change. With must_change the password is temporary: its next login only gets a session that can change it.
+   */
+  setUserPassword(tenantID: string, userID: string, body: SetUserPasswordRequest): Promise<void> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/users/${encodeURIComponent(userID)}/password`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Expire User Password
+   *
+   * Expire a user's password. The user must set a new one (new_password) at their next login.
+   */
+  expireUserPassword(tenantID: string, userID: string): Promise<void> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/users/${encodeURIComponent(userID)}/password/expire`, {}, {});
+  }
+
+  /**
+   * Assign Role
+   *
+   * Assign a role to a member of a tenant; assigning to a user who is not a member is refused with 409
+   */
+  assignTenantRole(tenantID: string, userID: string, body: AssignRoleRequest): Promise<Record<string, string>> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/users/${encodeURIComponent(userID)}/roles`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Revoke Role
+   *
+   * Revoke a role from a user within a tenant. Revoking tenant_owner or tenant_admin from the tenant's last owner, including yourself, is refused with 409.
+   */
+  revokeTenantRole(tenantID: string, userID: string, role: string): Promise<Record<string, string>> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/users/${encodeURIComponent(userID)}/roles/${encodeURIComponent(role)}`, {}, {});
+  }
+
+  /**
+   * List Webhooks
+   *
+   * List the tenant's outbound webhook subscriptions. Signing secrets are never returned.
+   */
+  listWebhooks(tenantID: string): Promise<ListWebhooksResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/webhooks`, {}, {});
+  }
+
+  /**
+   * Create Webhook
+   *
+   * Subscribe an HTTPS endpoint to the tenant's events. The signing secret is returned once.
+   */
+  createWebhook(tenantID: string, body: WebhookRequest): Promise<WebhookResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/webhooks`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Delete Webhook
+   *
+   * Remove a webhook subscription together with its delivery history
+   */
+  deleteWebhook(tenantID: string, webhookID: string): Promise<void> {
+    return this.request("DELETE", `/api/v1/tenants/${encodeURIComponent(tenantID)}/webhooks/${encodeURIComponent(webhookID)}`, {}, {});
+  }
+
+  /**
+   * Get Webhook
+   *
+   * Get one of the tenant's webhook subscriptions
+   */
+  getWebhook(tenantID: string, webhookID: string): Promise<WebhookResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/webhooks/${encodeURIComponent(webhookID)}`, {}, {});
+  }
+
+  /**
+   * Update Webhook
+   *
+   * Replace a webhook's endpoint, event types and state. Set rotate_secret to issue a new signing secret, which is returned once.
+   */
+  updateWebhook(tenantID: string, webhookID: string, body: WebhookRequest): Promise<WebhookResponse> {
+    return this.request("PUT", `/api/v1/tenants/${encodeURIComponent(tenantID)}/webhooks/${encodeURIComponent(webhookID)}`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * List Webhook Deliveries
+   *
+   * List a webhook's deliveries oldest first with cursor pagination. Dead deliveries exhausted their retries.
+   */
+  listWebhookDeliveries(tenantID: string, webhookID: string, params: { status?: string; limit?: number; cursor?: string } = {}): Promise<ListWebhookDeliveriesResponse> {
+    return this.request("GET", `/api/v1/tenants/${encodeURIComponent(tenantID)}/webhooks/${encodeURIComponent(webhookID)}/deliveries`, { status: params.status, limit: params.limit, cursor: params.cursor }, {});
+  }
+
+  /**
+   * Redeliver Webhook Event
+   *
+   * Queue a succeeded or dead delivery again. The event keeps its ID; the new delivery gets its own.
+   */
+  redeliverWebhook(tenantID: string, webhookID: string, deliveryID: string): Promise<WebhookDeliveryResponse> {
+    return this.request("POST", `/api/v1/tenants/${encodeURIComponent(tenantID)}/webhooks/${encodeURIComponent(webhookID)}/deliveries/${encodeURIComponent(deliveryID)}/redeliver`, {}, {});
+  }
+
+  /**
+   * Cancel Account Deletion
+   *
+   * Cancel the pending deletion of the current user's account
+   */
+  cancelAccountDeletion(): Promise<void> {
+    return this.request("DELETE", `/api/v1/user/account-deletion`, {}, {});
+  }
+
+  /**
+   * Get Account Deletion
+   *
+   * Get the deletion of the current user's account, pending until its grace period ends
+   */
+  getAccountDeletion(): Promise<AccountDeletionResponse> {
+    return this.request("GET", `/api/v1/user/account-deletion`, {}, {});
+  }
+
+  /**
+   * Request Account Deletion
+   *
+   * Delete the current user's account, confirmed with their password. The account is erased when the grace period ends unless the user logs in or cancels before; its record is anonymized and all its sessions and tokens are revoked. Without a grace period the account is erased at once and 204 is returned. Wrong passwords count towards lockout; the last owner of a tenant or the last platform admin is refused with 409.
+   */
+  requestAccountDeletion(body: AccountDeletionRequest): Promise<AccountDeletionResponse> {
+    return this.request("POST", `/api/v1/user/account-deletion`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Change password
+   *
+   * Change the current user's password
+   */
+  changePassword(body: ChangePasswordRequest): Promise<Record<string, string>> {
+    return this.request("POST", `/api/v1/user/change-password`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Cancel Email Change
+   *
+   * Abandon the email address change the current user has in progress
+   */
+  cancelEmailChange(): Promise<void> {
+    return this.request("DELETE", `/api/v1/user/email-change`, {}, {});
+  }
+
+  /**
+   * Get Email Change
+   *
+   * Get the email address change the current user has in progress
+   */
+  getEmailChange(): Promise<EmailChangeResponse> {
+    return this.request("GET", `/api/v1/user/email-change`, {}, {});
+  }
+
+  /**
+   * Request Email Change
+   *
+   * Start changing the current user's email address. A verification link is mailed to the new address; the address changes only once it is followed. A new request replaces one in progress.
+   */
+  requestEmailChange(body: EmailChangeRequest): Promise<EmailChangeResponse> {
+    return this.request("POST", `/api/v1/user/email-change`, {}, {}, { type: "application/json", value: body });
+  }
+
+  /**
+   * Get user profile
+   *
+   * Get the current user's profile information. The ETag response header carries the record version for If-Match on update.
+   */
+  getProfile(): Promise<Record<string, unknown>> {
+    return this.request("GET", `/api/v1/user/profile`, {}, {});
+  }
+
+  /**
+   * Update user profile
+   *
+   * Update the current user's profile information. Picture and Website must be http(s) URLs, Locale a BCP 47 tag, Timezone an IANA time zone and Birthdate YYYY-MM-DD, 0000-MM-DD or YYYY; they are released as the picture, website, locale, zoneinfo and birthdate claims under the profile scope. Send the ETag from the last read in If-Match to avoid overwriting a concurrent change.
+   */
+  updateProfile(body: Profile, params: { "If-Match"?: string } = {}): Promise<Record<string, unknown>> {
+    return this.request("PUT", `/api/v1/user/profile`, {}, { "If-Match": params["If-Match"] }, { type: "application/json", value: body });
+  }
+
+  private async request<T>(method: string, path: string, query: Params, headers: Params, body?: Body): Promise<T> {
+    const search = new URLSearchParams();
+    for (const [name, value] of Object.entries(query)) {
+      if (value !== undefined) search.set(name, String(value));
+    }
+    const init: RequestInit = { method, credentials: "include", headers: { Accept: "application/json", ...this.headers } };
+    const requestHeaders = init.headers as Record<string, string>;
+    for (const [name, value] of Object.entries(headers)) {
+      if (value !== undefined) requestHeaders[name] = String(value);
+    }
+    if (body !== undefined && body.value !== undefined) {
+      requestHeaders["Content-Type"] = body.type;
+      init.body =
+        body.type === "application/json"
+          ? JSON.stringify(body.value)
+          : new URLSearchParams(body.value as Record<string, string>).toString();
+    }
+
+    const queryString = search.toString();
+    const response = await this.fetch(this.baseUrl + path + (queryString ? "?" + queryString : ""), init);
+    const text = await response.text();
+    let data: unknown = undefined;
+    if (text !== "") {
+      data = response.headers.get("Content-Type")?.includes("json") ? JSON.parse(text) : text;
+    }
+    if (!response.ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+}
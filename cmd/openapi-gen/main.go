@@ -0,0 +1,83 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command openapi-gen generates the OpenAPI document served at /openapi.json
+// from the handler annotations in internal/transport/http.
+//
+// With -ts it also generates the TypeScript admin API client from the document
+// (internal/openapi TypeScript), so that the two are always regenerated
+// together. It runs via go generate in that package. With -check it writes
+// nothing and exits non-zero when a committed file is stale, for use in CI.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/opentrusty/opentrusty/internal/openapi"
+	transportHTTP "github.com/opentrusty/opentrusty/internal/transport/http"
+)
+
+// output is a generated file
+type output struct {
+	path string
+	data []byte
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the annotated handler package")
+	out := flag.String("out", "openapi.json", "output file")
+	tsOut := flag.String("ts", "", "output file of the TypeScript admin API client; none if empty")
+	check := flag.Bool("check", false, "fail if the output files are out of date instead of writing them")
+	flag.Parse()
+
+	doc, err := openapi.Generate(openapi.Config{
+		Dir:    *dir,
+		Routes: transportHTTP.Routes(),
+		Unmounted: func(operationID, route string) {
+			fmt.Fprintf(os.Stderr, "openapi-gen: skipping %s: %s is not mounted\n", operationID, route)
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "openapi-gen: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputs := []output{{*out, doc}}
+	if *tsOut != "" {
+		client, err := openapi.TypeScript(doc, transportHTTP.AdminClient)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "openapi-gen: TypeScript client: %v\n", err)
+			os.Exit(1)
+		}
+		outputs = append(outputs, output{*tsOut, client})
+	}
+
+	for _, o := range outputs {
+		if *check {
+			current, err := os.ReadFile(o.path)
+			if err != nil || !bytes.Equal(current, o.data) {
+				fmt.Fprintf(os.Stderr, "openapi-gen: %s is out of date; run go generate ./internal/transport/http\n", o.path)
+				os.Exit(1)
+			}
+			continue
+		}
+		if err := os.WriteFile(o.path, o.data, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "openapi-gen: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
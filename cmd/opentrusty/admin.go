@@ -0,0 +1,328 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/spf13/cobra"
+)
+
+func newAdminCommand() *cobra.Command {
+	admin := &cobra.Command{
+		Use:   "admin",
+		Short: "Manage users and roles directly in the database",
+		Long: "Manage users and roles directly in the database, for example to recover access when no admin can log in.\n" +
+			"Users are identified by --email within --tenant; without --tenant they are platform users.",
+	}
+
+	var createFlags struct {
+		givenName, familyName, role string
+		passwordStdin               bool
+	}
+	create := &cobra.Command{
+		Use:   "create-user",
+		Short: "Create a user with a password and an optional role",
+		Args:  cobra.NoArgs,
+	}
+	tenantID, email := userFlags(create)
+	create.Flags().StringVar(&createFlags.givenName, "given-name", "", "given name")
+	create.Flags().StringVar(&createFlags.familyName, "family-name", "", "family name")
+	create.Flags().StringVar(&createFlags.role, "role", "", "role to grant (default tenant_member for tenant users, none for platform users)")
+	create.Flags().BoolVar(&createFlags.passwordStdin, "password-stdin", false, "read the password from stdin instead of generating one")
+	create.RunE = func(cmd *cobra.Command, args []string) error {
+		env, err := openCommandEnv(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer env.close()
+
+		password, generated, err := readPassword(cmd, createFlags.passwordStdin)
+		if err != nil {
+			return err
+		}
+		role := createFlags.role
+		if role == "" && *tenantID != "" {
+			role = tenant.RoleTenantMember
+		}
+
+		user, err := env.createUser(cmd.Context(), *tenantID, *email, identity.Profile{
+			GivenName:  createFlags.givenName,
+			FamilyName: createFlags.familyName,
+			FullName:   strings.TrimSpace(createFlags.givenName + " " + createFlags.familyName),
+		}, password, role)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "user_id: %s\n", user.ID)
+		if generated {
+			fmt.Fprintf(cmd.OutOrStdout(), "password: %s\n", password)
+		}
+		return nil
+	}
+
+	var passwordStdin, mustChange bool
+	setPassword := newUserCommand("set-password", "Replace a user's password, generating one unless --password-stdin is set",
+		func(cmd *cobra.Command, env *commandEnv, tenantID string, user *identity.User) error {
+			password, generated, err := readPassword(cmd, passwordStdin)
+			if err != nil {
+				return err
+			}
+			if err := env.identity.ResetPassword(cmd.Context(), tenantID, user.ID, password, audit.ActorCLI, mustChange); err != nil {
+				return err
+			}
+			if generated {
+				fmt.Fprintf(cmd.OutOrStdout(), "password: %s\n", password)
+			}
+			return nil
+		})
+	setPassword.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read the password from stdin instead of generating one")
+	setPassword.Flags().BoolVar(&mustChange, "must-change", false, "make the password temporary: the user must change it at the next login")
+
+	var grantRoleName, revokeRoleName string
+	grant := newUserCommand("grant-role", "Grant a tenant role, or platform_admin to a platform user",
+		func(cmd *cobra.Command, env *commandEnv, tenantID string, user *identity.User) error {
+			return env.grantRole(cmd.Context(), tenantID, user.ID, grantRoleName)
+		})
+	grant.Flags().StringVar(&grantRoleName, "role", "", "tenant_owner, tenant_admin, tenant_member or platform_admin")
+	grant.MarkFlagRequired("role")
+	revoke := newUserCommand("revoke-role", "Revoke a tenant role, or platform_admin from a platform user",
+		func(cmd *cobra.Command, env *commandEnv, tenantID string, user *identity.User) error {
+			return env.revokeRole(cmd.Context(), tenantID, user.ID, revokeRoleName)
+		})
+	revoke.Flags().StringVar(&revokeRoleName, "role", "", "tenant_owner, tenant_admin, tenant_member or platform_admin")
+	revoke.MarkFlagRequired("role")
+
+	admin.AddCommand(
+		create,
+		setPassword,
+		grant,
+		revoke,
+		newListUsersCommand(),
+		newUserCommand("unlock", "Clear a user's failed login attempts and lockout",
+			func(cmd *cobra.Command, env *commandEnv, tenantID string, user *identity.User) error {
+				return env.identity.Unlock(cmd.Context(), tenantID, user.ID, audit.ActorCLI)
+			}),
+		newUserCommand("expire-password", "Force a user to set a new password at their next login",
+			func(cmd *cobra.Command, env *commandEnv, tenantID string, user *identity.User) error {
+				return env.identity.ExpirePassword(cmd.Context(), tenantID, user.ID, audit.ActorCLI)
+			}),
+	)
+	return admin
+}
+
+// userFlags adds the --tenant and --email flags that identify a user
+func userFlags(cmd *cobra.Command) (tenantID, email *string) {
+	tenantID = cmd.Flags().String("tenant", "", "tenant ID of the user; empty for platform users")
+	email = cmd.Flags().String("email", "", "email address of the user")
+	cmd.MarkFlagRequired("email")
+	return tenantID, email
+}
+
+// newUserCommand returns a command that looks a user up by --tenant and --email and applies action
+func newUserCommand(use, short string, action func(cmd *cobra.Command, env *commandEnv, tenantID string, user *identity.User) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+	}
+	tenantID, email := userFlags(cmd)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		env, err := openCommandEnv(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer env.close()
+
+		user, err := env.identity.GetByEmail(cmd.Context(), *tenantID, *email)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", *email, err)
+		}
+		if err := action(cmd, env, *tenantID, user); err != nil {
+			return fmt.Errorf("user %s: %w", *email, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: done for user %s (%s)\n", use, user.Email, user.ID)
+		return nil
+	}
+	return cmd
+}
+
+func newListUsersCommand() *cobra.Command {
+	var tenantID string
+	cmd := &cobra.Command{
+		Use:   "list-users",
+		Short: "List the users holding a role in a tenant, or the platform admins without --tenant",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			type row struct{ userID, role string }
+			var rows []row
+			if tenantID == "" {
+				userIDs, err := env.store.Assignments.ListByRole(cmd.Context(), rbac.RoleIDPlatformAdmin, authz.ScopePlatform, nil)
+				if err != nil {
+					return fmt.Errorf("failed to list platform admins: %w", err)
+				}
+				for _, userID := range userIDs {
+					rows = append(rows, row{userID, authz.RolePlatformAdmin})
+				}
+			} else {
+				roles, err := env.store.TenantRoles.GetTenantUsers(cmd.Context(), tenantID)
+				if err != nil {
+					return fmt.Errorf("failed to list tenant users: %w", err)
+				}
+				for _, r := range roles {
+					rows = append(rows, row{r.UserID, r.Role})
+				}
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "USER ID\tEMAIL\tROLE\tLOCKED")
+			for _, r := range rows {
+				email, locked := "-", false
+				if user, err := env.identity.GetUser(cmd.Context(), r.userID); err == nil {
+					email = user.Email
+					locked = user.LockedUntil != nil && user.LockedUntil.After(time.Now())
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", r.userID, email, r.role, locked)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&tenantID, "tenant", "", "tenant ID; empty lists platform admins")
+	return cmd
+}
+
+// createUser provisions a user with a password and grants role unless it is empty
+func (e *commandEnv) createUser(ctx context.Context, tenantID, email string, profile identity.Profile, password, role string) (*identity.User, error) {
+	user, err := e.identity.ProvisionIdentity(ctx, tenantID, email, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", email, err)
+	}
+	if err := e.identity.AddPassword(ctx, user.ID, password); err != nil {
+		return nil, fmt.Errorf("failed to set password for %s: %w", email, err)
+	}
+	e.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserCreated,
+		TenantID: tenantID,
+		ActorID:  audit.ActorCLI,
+		Resource: audit.ResourceUser,
+		Payload:  &audit.UserCreatedPayload{UserID: user.ID, Email: user.Email},
+	})
+	if role != "" {
+		if err := e.grantRole(ctx, tenantID, user.ID, role); err != nil {
+			return nil, fmt.Errorf("user %s created but role %s not granted: %w", email, role, err)
+		}
+	}
+	return user, nil
+}
+
+// grantRole grants a tenant role, or the platform admin role when tenantID is empty.
+// Assignments record a granting user, so CLI grants are stored as system grants
+// and the audit event names the CLI as the actor.
+func (e *commandEnv) grantRole(ctx context.Context, tenantID, userID, role string) error {
+	var err error
+	switch {
+	case tenantID != "" && (role == tenant.RoleTenantOwner || role == tenant.RoleTenantAdmin || role == tenant.RoleTenantMember):
+		err = e.store.TenantRoles.AssignRole(ctx, &tenant.TenantUserRole{
+			ID:        id.NewUUIDv7(),
+			TenantID:  tenantID,
+			UserID:    userID,
+			Role:      role,
+			GrantedBy: tenant.GranterSystem,
+		})
+	case tenantID == "" && role == authz.RolePlatformAdmin:
+		err = e.store.Assignments.Grant(ctx, &authz.Assignment{
+			ID:        id.NewUUIDv7(),
+			UserID:    userID,
+			RoleID:    rbac.RoleIDPlatformAdmin,
+			Scope:     authz.ScopePlatform,
+			GrantedAt: time.Now(),
+			GrantedBy: audit.ActorSystemBootstrap,
+		})
+	default:
+		return fmt.Errorf("invalid role: %s", role)
+	}
+	if err != nil {
+		return err
+	}
+	e.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeRoleAssigned,
+		TenantID: tenantID,
+		ActorID:  audit.ActorCLI,
+		Resource: audit.ResourceRole,
+		Payload:  &audit.RolePayload{RoleID: role, TargetUserID: userID},
+	})
+	return nil
+}
+
+// revokeRole revokes a tenant role, or the platform admin role when tenantID is empty.
+// The last tenant owner and the last platform admin cannot be revoked.
+func (e *commandEnv) revokeRole(ctx context.Context, tenantID, userID, role string) error {
+	if tenantID != "" {
+		// The tenant service audits the revocation itself
+		tenants := tenant.NewService(e.store.Tenants, e.store.TenantRoles, e.store.Assignments, e.auditLogger)
+		return tenants.RevokeRole(ctx, tenantID, userID, role, audit.ActorCLI)
+	}
+
+	if role != authz.RolePlatformAdmin {
+		return fmt.Errorf("invalid role: %s", role)
+	}
+	if err := authz.NewService(nil, e.store.Roles, e.store.Assignments).RevokePlatformAdmin(ctx, userID); err != nil {
+		return err
+	}
+	e.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeRoleRevoked,
+		ActorID:  audit.ActorCLI,
+		Resource: audit.ResourceRole,
+		Payload:  &audit.RolePayload{RoleID: role, TargetUserID: userID},
+	})
+	return nil
+}
+
+// readPassword reads a password from the first line of stdin, or generates a random one
+func readPassword(cmd *cobra.Command, fromStdin bool) (password string, generated bool, err error) {
+	if fromStdin {
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", false, fmt.Errorf("failed to read password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), false, nil
+	}
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), true, nil
+}
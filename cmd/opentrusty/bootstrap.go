@@ -0,0 +1,218 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/store"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/spf13/cobra"
+)
+
+// bootstrapOutput reports what `opentrusty bootstrap` created and what was already present.
+// A generated password is shown only when the user was created.
+type bootstrapOutput struct {
+	UserID       string                 `json:"user_id"`
+	Email        string                 `json:"email"`
+	UserTenantID string                 `json:"user_tenant_id,omitempty"`
+	UserCreated  bool                   `json:"user_created"`
+	RoleGranted  bool                   `json:"role_granted"`
+	Password     string                 `json:"password,omitempty"`
+	Tenant       *bootstrapTenantOutput `json:"tenant,omitempty"`
+}
+
+type bootstrapTenantOutput struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Created bool   `json:"created"`
+}
+
+func newBootstrapCommand() *cobra.Command {
+	var (
+		email         string
+		tenantID      string
+		createTenant  string
+		passwordStdin bool
+		output        string
+	)
+	cmd := &cobra.Command{
+		Use:   "bootstrap",
+		Short: "Create the initial platform admin",
+		Long: "Create the initial platform admin and, optionally, a first tenant administered by it.\n" +
+			"The admin user is created if it does not exist, with a generated one-time password that must\n" +
+			"be changed at first login unless --password-stdin is set. An existing user is only granted the role.\n" +
+			"The command is idempotent: running it again reports everything as already present.\n" +
+			"It fails if a different user already holds the platform admin role.\n" +
+			"--email and --tenant default to " + identity.EnvBootstrapAdminEmail + " and " + identity.EnvBootstrapAdminTenantID + ";\n" +
+			"the email is prompted for when stdin is a terminal.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputText && output != outputJSON {
+				return fmt.Errorf("invalid output format %q: use text or json", output)
+			}
+			if email == "" && !passwordStdin && isTerminal(cmd.InOrStdin()) {
+				line, err := prompt(cmd, "Admin email: ")
+				if err != nil {
+					return err
+				}
+				email = line
+			}
+			if email == "" {
+				return fmt.Errorf("an admin email is required: set --email or %s", identity.EnvBootstrapAdminEmail)
+			}
+
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+			if env.store.Driver() == store.DriverMemory {
+				return fmt.Errorf("bootstrap cannot populate DB_DRIVER=memory: the data would be lost when the command exits")
+			}
+			if env.store.Driver() == store.DriverSQLite {
+				if err := env.store.Migrate(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to migrate database: %w", err)
+				}
+			}
+
+			password, generated, err := readPassword(cmd, passwordStdin)
+			if err != nil {
+				return err
+			}
+			out, err := env.bootstrap(cmd.Context(), identity.BootstrapRequest{
+				Email:          email,
+				TenantID:       tenantID,
+				Password:       password,
+				ExpirePassword: generated,
+			}, createTenant)
+			if err != nil {
+				return err
+			}
+			if generated && out.UserCreated {
+				out.Password = password
+			}
+
+			if output == outputJSON {
+				return printJSON(cmd, out)
+			}
+			return printBootstrap(cmd, out)
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", os.Getenv(identity.EnvBootstrapAdminEmail), "admin email")
+	cmd.Flags().StringVar(&tenantID, "tenant", os.Getenv(identity.EnvBootstrapAdminTenantID), "tenant ID of an existing admin user; empty for a platform-level user")
+	cmd.Flags().StringVar(&createTenant, "create-tenant", "", "name of a first tenant to create with the admin as tenant_admin")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read the admin password from stdin instead of generating a one-time password")
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format: text or json")
+	return cmd
+}
+
+// bootstrap makes the requested user the platform admin and creates the tenant named
+// createTenant unless it already exists
+func (e *commandEnv) bootstrap(ctx context.Context, req identity.BootstrapRequest, createTenant string) (*bootstrapOutput, error) {
+	bootstrapService := identity.NewBootstrapService(e.identity, e.store.Assignments, e.store.Roles, e.auditLogger)
+	result, err := bootstrapService.Bootstrap(ctx, req)
+	if err != nil {
+		if errors.Is(err, identity.ErrAlreadyBootstrapped) {
+			return nil, fmt.Errorf("bootstrap failed: %w; use `opentrusty admin grant-role` to add more admins", err)
+		}
+		return nil, fmt.Errorf("bootstrap failed: %w", err)
+	}
+	out := &bootstrapOutput{
+		UserID:       result.UserID,
+		Email:        strings.TrimSpace(req.Email),
+		UserTenantID: req.TenantID,
+		UserCreated:  result.UserCreated,
+		RoleGranted:  result.RoleGranted,
+	}
+	if createTenant == "" {
+		return out, nil
+	}
+
+	tenantService := tenant.NewService(e.store.Tenants, e.store.TenantRoles, e.store.Assignments, e.auditLogger)
+	t, err := tenantService.GetTenantByName(ctx, strings.TrimSpace(createTenant))
+	switch {
+	case err == nil:
+		out.Tenant = &bootstrapTenantOutput{ID: t.ID, Name: t.Name}
+	case errors.Is(err, tenant.ErrTenantNotFound):
+		t, err = tenantService.CreateTenant(ctx, createTenant, result.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tenant %q: %w", createTenant, err)
+		}
+		e.auditLogger.Log(ctx, audit.Event{
+			Type:     audit.TypeTenantCreated,
+			ActorID:  audit.ActorCLI,
+			Resource: audit.ResourceTenant,
+			Payload:  &audit.TenantPayload{TenantID: t.ID, TenantName: t.Name},
+		})
+		out.Tenant = &bootstrapTenantOutput{ID: t.ID, Name: t.Name, Created: true}
+	default:
+		return nil, fmt.Errorf("failed to look up tenant %q: %w", createTenant, err)
+	}
+	return out, nil
+}
+
+func printBootstrap(cmd *cobra.Command, out *bootstrapOutput) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "user:\t%s (%s)\t%s\n", out.Email, out.UserID, reportStatus(out.UserCreated, "created"))
+	fmt.Fprintf(w, "platform_admin role:\t\t%s\n", reportStatus(out.RoleGranted, "granted"))
+	if out.Tenant != nil {
+		fmt.Fprintf(w, "tenant:\t%s (%s)\t%s\n", out.Tenant.Name, out.Tenant.ID, reportStatus(out.Tenant.Created, "created"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if out.Password != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "\nOne-time password: %s\nIt is shown only once and must be changed at first login.\n", out.Password)
+	}
+	return nil
+}
+
+// reportStatus describes a bootstrap item as changed by this run or already present
+func reportStatus(changed bool, verb string) string {
+	if changed {
+		return verb
+	}
+	return "already present"
+}
+
+// isTerminal reports whether r is an interactive terminal, so prompts can be shown
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// prompt writes label to stderr and reads one line from stdin
+func prompt(cmd *cobra.Command, label string) (string, error) {
+	fmt.Fprint(cmd.ErrOrStderr(), label)
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
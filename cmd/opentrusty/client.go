@@ -0,0 +1,345 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the client commands
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// errClientNotFound is returned for unknown clients and clients of other tenants
+var errClientNotFound = errors.New("client not found")
+
+// clientFlags are shared by all client commands
+type clientFlags struct {
+	tenantID string
+	output   string
+}
+
+// clientOutput is a client together with its secret, printed once after creation or rotation
+type clientOutput struct {
+	*oauth2.Client
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+func newClientCommand() *cobra.Command {
+	var flags clientFlags
+	client := &cobra.Command{
+		Use:   "client",
+		Short: "Manage OAuth2 clients",
+		Long: "Manage a tenant's OAuth2 clients directly in the database.\n" +
+			"Clients are identified by their public client_id. Use --output json for machine-readable output.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if flags.output != outputText && flags.output != outputJSON {
+				return fmt.Errorf("invalid output format %q: use text or json", flags.output)
+			}
+			return nil
+		},
+	}
+	client.PersistentFlags().StringVar(&flags.tenantID, "tenant", "", "tenant ID")
+	client.PersistentFlags().StringVarP(&flags.output, "output", "o", outputText, "output format: text or json")
+	client.MarkPersistentFlagRequired("tenant")
+	client.AddCommand(
+		newClientListCommand(&flags),
+		newClientShowCommand(&flags),
+		newClientCreateCommand(&flags),
+		newClientRotateSecretCommand(&flags),
+		newClientDeleteCommand(&flags),
+	)
+	return client
+}
+
+// oauth2Service returns an OAuth2 service for client management; it does not issue tokens.
+// Client changes are published so that running servers evict the client from their caches.
+func (e *commandEnv) oauth2Service() *oauth2.Service {
+	return oauth2.NewService(
+		oauth2.NewClientCache(e.store.Clients, 0, e.bus),
+		e.store.Codes,
+		e.store.AccessTokens,
+		e.store.RefreshTokens,
+		e.auditLogger,
+		nil,
+		e.cfg.OAuth2.AuthCodeLifetime,
+		e.cfg.OAuth2.AccessTokenLifetime,
+		e.cfg.OAuth2.RefreshTokenLifetime,
+	)
+}
+
+// createClient registers client with the same defaults as registration through the admin API.
+// Confidential clients get a generated secret, returned once in the output.
+func (e *commandEnv) createClient(ctx context.Context, client *oauth2.Client, public bool) (*clientOutput, error) {
+	out := &clientOutput{Client: client}
+	client.TokenEndpointAuthMethod = "client_secret_basic"
+	if public {
+		client.TokenEndpointAuthMethod = "none"
+	} else {
+		out.ClientSecret = oauth2.GenerateClientSecret()
+		client.ClientSecretHash = oauth2.HashClientSecret(out.ClientSecret)
+	}
+	if len(client.AllowedScopes) == 0 {
+		client.AllowedScopes = []string{"openid"}
+	}
+	if len(client.GrantTypes) == 0 {
+		client.GrantTypes = []string{"authorization_code"}
+	}
+	client.ResponseTypes = []string{"code"}
+	client.AccessTokenLifetime = 3600
+	client.RefreshTokenLifetime = 2592000
+	client.IDTokenLifetime = 3600
+	client.IsActive = true
+	if err := e.oauth2Service().CreateClient(ctx, client); err != nil {
+		return nil, fmt.Errorf("failed to register client: %w", err)
+	}
+
+	e.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeClientCreated,
+		TenantID: client.TenantID,
+		ActorID:  audit.ActorCLI,
+		Resource: audit.ResourceClient,
+		Payload: &audit.ClientPayload{
+			ClientID:   client.ClientID,
+			ClientName: client.ClientName,
+		},
+	})
+	return out, nil
+}
+
+// tenantClient returns the client with the public clientID if it belongs to the tenant
+func tenantClient(ctx context.Context, svc *oauth2.Service, tenantID, clientID string) (*oauth2.Client, error) {
+	client, err := svc.GetClientByClientID(ctx, clientID)
+	if err != nil || client.TenantID != tenantID {
+		return nil, errClientNotFound
+	}
+	return client, nil
+}
+
+func newClientListCommand(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List a tenant's OAuth2 clients",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			svc := env.oauth2Service()
+			clients, err := pagination.All(func(p pagination.Params) ([]*oauth2.Client, error) {
+				clients, _, err := svc.ListClients(cmd.Context(), flags.tenantID, p)
+				return clients, err
+			}, func(c *oauth2.Client) string { return c.ID })
+			if err != nil {
+				return fmt.Errorf("failed to list clients: %w", err)
+			}
+
+			if flags.output == outputJSON {
+				if clients == nil {
+					clients = []*oauth2.Client{}
+				}
+				return printJSON(cmd, clients)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CLIENT ID\tNAME\tAUTH METHOD\tACTIVE\tREDIRECT URIS")
+			for _, c := range clients {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n", c.ClientID, c.ClientName, c.TokenEndpointAuthMethod, c.IsActive, strings.Join(c.RedirectURIs, ","))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newClientShowCommand(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show CLIENT_ID",
+		Short: "Show an OAuth2 client",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			client, err := tenantClient(cmd.Context(), env.oauth2Service(), flags.tenantID, args[0])
+			if err != nil {
+				return err
+			}
+			return printClient(cmd, flags, clientOutput{Client: client})
+		},
+	}
+}
+
+func newClientCreateCommand(flags *clientFlags) *cobra.Command {
+	var (
+		name         string
+		redirectURIs []string
+		scopes       []string
+		grantTypes   []string
+		public       bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Register an OAuth2 client and print its credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			out, err := env.createClient(cmd.Context(), &oauth2.Client{
+				TenantID:      flags.tenantID,
+				ClientName:    name,
+				RedirectURIs:  redirectURIs,
+				AllowedScopes: scopes,
+				GrantTypes:    grantTypes,
+			}, public)
+			if err != nil {
+				return err
+			}
+			return printClient(cmd, flags, *out)
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "client name")
+	cmd.Flags().StringSliceVar(&redirectURIs, "redirect-uri", nil, "allowed redirect URI (repeatable)")
+	cmd.Flags().StringSliceVar(&scopes, "scope", nil, "allowed scope (repeatable, default openid)")
+	cmd.Flags().StringSliceVar(&grantTypes, "grant-type", nil, "allowed grant type (repeatable, default authorization_code)")
+	cmd.Flags().BoolVar(&public, "public", false, "register a public client without a secret")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("redirect-uri")
+	return cmd
+}
+
+func newClientRotateSecretCommand(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-secret CLIENT_ID",
+		Short: "Replace a confidential client's secret and print the new one",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			svc := env.oauth2Service()
+			client, err := tenantClient(cmd.Context(), svc, flags.tenantID, args[0])
+			if err != nil {
+				return err
+			}
+			if client.TokenEndpointAuthMethod == "none" {
+				return errors.New("cannot regenerate secret for public client")
+			}
+
+			newSecret := oauth2.GenerateClientSecret()
+			client.ClientSecretHash = oauth2.HashClientSecret(newSecret)
+			if err := svc.UpdateClient(cmd.Context(), client); err != nil {
+				return fmt.Errorf("failed to update client secret: %w", err)
+			}
+
+			env.auditLogger.Log(cmd.Context(), audit.Event{
+				Type:     audit.TypeSecretRotated,
+				TenantID: flags.tenantID,
+				ActorID:  audit.ActorCLI,
+				Resource: audit.ResourceClient,
+				Payload:  &audit.ClientPayload{ClientID: client.ClientID, ClientName: client.ClientName},
+			})
+			return printClient(cmd, flags, clientOutput{Client: client, ClientSecret: newSecret})
+		},
+	}
+}
+
+func newClientDeleteCommand(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete CLIENT_ID",
+		Short: "Delete an OAuth2 client",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			svc := env.oauth2Service()
+			client, err := tenantClient(cmd.Context(), svc, flags.tenantID, args[0])
+			if err != nil {
+				return err
+			}
+			if err := svc.DeleteClient(cmd.Context(), client.ID); err != nil {
+				return fmt.Errorf("failed to delete client: %w", err)
+			}
+
+			env.auditLogger.Log(cmd.Context(), audit.Event{
+				Type:     audit.TypeClientDeleted,
+				TenantID: flags.tenantID,
+				ActorID:  audit.ActorCLI,
+				Resource: audit.ResourceClient,
+				Payload:  &audit.ClientPayload{ClientID: client.ClientID, ClientName: client.ClientName},
+			})
+
+			if flags.output == outputJSON {
+				return printJSON(cmd, map[string]any{"client_id": client.ClientID, "deleted": true})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "deleted client %s\n", client.ClientID)
+			return nil
+		},
+	}
+}
+
+// printClient prints a client in the selected format; the secret is only present after creation or rotation
+func printClient(cmd *cobra.Command, flags *clientFlags, c clientOutput) error {
+	if flags.output == outputJSON {
+		return printJSON(cmd, c)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "client_id:\t%s\n", c.ClientID)
+	if c.ClientSecret != "" {
+		fmt.Fprintf(w, "client_secret:\t%s\n", c.ClientSecret)
+	}
+	fmt.Fprintf(w, "client_name:\t%s\n", c.ClientName)
+	fmt.Fprintf(w, "tenant_id:\t%s\n", c.TenantID)
+	fmt.Fprintf(w, "redirect_uris:\t%s\n", strings.Join(c.RedirectURIs, ","))
+	fmt.Fprintf(w, "allowed_scopes:\t%s\n", strings.Join(c.AllowedScopes, " "))
+	fmt.Fprintf(w, "grant_types:\t%s\n", strings.Join(c.GrantTypes, " "))
+	fmt.Fprintf(w, "token_endpoint_auth_method:\t%s\n", c.TokenEndpointAuthMethod)
+	fmt.Fprintf(w, "is_active:\t%t\n", c.IsActive)
+	return w.Flush()
+}
+
+// printJSON writes v as indented JSON to the command's output
+func printJSON(cmd *cobra.Command, v any) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand() *cobra.Command {
+	cfg := &cobra.Command{
+		Use:   "config",
+		Short: "Check and print the configuration",
+		Long: "Check and print the configuration the server would start with. Configuration is read from\n" +
+			"environment variables; unset variables take their defaults.",
+	}
+	cfg.AddCommand(newConfigValidateCommand(), newConfigShowCommand())
+	return cfg
+}
+
+func newConfigValidateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration and list every problem",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := config.Load(); err != nil {
+				w := cmd.ErrOrStderr()
+				fmt.Fprintln(w, "invalid configuration:")
+				problems := configProblems(err)
+				for _, problem := range problems {
+					fmt.Fprintf(w, "  - %s\n", problem)
+				}
+				return fmt.Errorf("%d configuration problem(s) found", len(problems))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+			return nil
+		},
+	}
+}
+
+func newConfigShowCommand() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration with secrets redacted",
+		Long: "Print every configuration variable with its effective value. Variables that are not set\n" +
+			"are marked as defaults; secrets are printed as " + config.Redacted + " when set.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputText && output != outputJSON {
+				return fmt.Errorf("invalid output format %q: use text or json", output)
+			}
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("%w (run `opentrusty config validate` to list every problem)", err)
+			}
+
+			if output == outputJSON {
+				return printJSON(cmd, cfg.Settings())
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, s := range cfg.Settings() {
+				if s.Default {
+					fmt.Fprintf(w, "%s=%s\t# default\n", s.Name, s.Value)
+				} else {
+					fmt.Fprintf(w, "%s=%s\t\n", s.Name, s.Value)
+				}
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format: text or json")
+	return cmd
+}
+
+// configProblems flattens the joined errors returned by config.Load
+func configProblems(err error) []string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var problems []string
+		for _, e := range joined.Unwrap() {
+			problems = append(problems, configProblems(e)...)
+		}
+		return problems
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		return configProblems(inner)
+	}
+	return []string{err.Error()}
+}
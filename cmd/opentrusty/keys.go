@@ -0,0 +1,292 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/bus"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/oidc"
+	"github.com/spf13/cobra"
+)
+
+// keyOutput is a signing key without its private part
+type keyOutput struct {
+	ID          string           `json:"id"`
+	KeyID       string           `json:"kid"`
+	Algorithm   oauth2.Algorithm `json:"alg"`
+	Status      oauth2.KeyStatus `json:"status"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ActivatedAt *time.Time       `json:"activated_at,omitempty"`
+	RetiredAt   *time.Time       `json:"retired_at,omitempty"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+	// KMSKeyID is the KMS key wrapping the private key; empty when OPENID_KEY_ENCRYPTION_KEY seals it
+	KMSKeyID string `json:"kms_key_id,omitempty"`
+}
+
+func newKeysCommand() *cobra.Command {
+	keys := &cobra.Command{
+		Use:   "keys",
+		Short: "Generate and manage secret keys",
+		Long: "Manage the token signing keys in the database and generate secret keys.\n\n" +
+			"A planned rotation is two steps: `keys generate` publishes a pending key in the JWKS,\n" +
+			"and once relying parties have refreshed their cached JWKS, `keys rotate` makes it the\n" +
+			"signing key. The previous key stays published for the rotation grace period.\n" +
+			"Running servers pick up changes within OAUTH2_KEY_RELOAD_INTERVAL.",
+	}
+	keys.AddCommand(
+		newKeysListCommand(),
+		newKeysGenerateCommand(),
+		newKeysRotateCommand(),
+		newKeysRetireCommand(),
+		newKeysExportJWKSCommand(),
+		&cobra.Command{
+			Use:   "generate-encryption-key",
+			Short: "Print a random value for OPENID_KEY_ENCRYPTION_KEY",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				// 24 random bytes encode to exactly the 32 characters AES-256 needs
+				b := make([]byte, 24)
+				if _, err := rand.Read(b); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), base64.RawURLEncoding.EncodeToString(b))
+				return nil
+			},
+		},
+	)
+	return keys
+}
+
+// withKeyManager runs fn with a signing key manager on the configured database
+func withKeyManager(cmd *cobra.Command, fn func(env *commandEnv, m *oauth2.KeyManager) error) error {
+	env, err := openCommandEnv(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	kms, err := env.cfg.Secrets.KMS()
+	if err != nil {
+		return err
+	}
+	m, err := oauth2.NewKeyManager(env.store.Keys, []byte(env.cfg.Security.KeyEncryptionKey), kms, env.auditLogger)
+	if err != nil {
+		return err
+	}
+	return fn(env, m)
+}
+
+// keysChanged tells running servers to reload their keys instead of waiting for
+// OAUTH2_KEY_RELOAD_INTERVAL. The change is already stored, so a failure is only a warning.
+func keysChanged(cmd *cobra.Command, env *commandEnv) {
+	if err := env.bus.Publish(cmd.Context(), bus.TopicKeysChanged, ""); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+	}
+}
+
+func newKeysListCommand() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List signing keys, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputText && output != outputJSON {
+				return fmt.Errorf("invalid output format %q: use text or json", output)
+			}
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			keys, err := env.store.Keys.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			out := make([]keyOutput, 0, len(keys))
+			for _, key := range keys {
+				o, err := newKeyOutput(key)
+				if err != nil {
+					return err
+				}
+				out = append(out, o)
+			}
+
+			if output == outputJSON {
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tKID\tSTATUS\tCREATED\tACTIVATED\tEXPIRES")
+			for _, o := range out {
+				activated := "-"
+				if o.ActivatedAt != nil {
+					activated = o.ActivatedAt.Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", o.ID, o.KeyID, o.Status,
+					o.CreatedAt.Format(time.RFC3339), activated, o.ExpiresAt.Format(time.RFC3339))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format: text or json")
+	return cmd
+}
+
+func newKeysGenerateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a pending signing key and publish it in the JWKS",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withKeyManager(cmd, func(env *commandEnv, m *oauth2.KeyManager) error {
+				key, err := m.Generate(cmd.Context(), audit.ActorCLI)
+				if err != nil {
+					return err
+				}
+				keysChanged(cmd, env)
+				return printKeyChange(cmd, "generated pending", key)
+			})
+		},
+	}
+}
+
+func newKeysRotateCommand() *cobra.Command {
+	var (
+		grace     time.Duration
+		emergency bool
+	)
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Make the newest pending key (or a new key) the signing key",
+		Long: "Make the newest pending key the signing key, generating one if none is pending.\n" +
+			"The previous signing key stays published for --grace so tokens it signed still verify.\n" +
+			"With --emergency a new key is activated and every other key is retired immediately;\n" +
+			"use it when a private key may be compromised.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withKeyManager(cmd, func(env *commandEnv, m *oauth2.KeyManager) error {
+				var key *oauth2.Key
+				var err error
+				if emergency {
+					key, err = m.EmergencyRotate(cmd.Context(), audit.ActorCLI)
+				} else {
+					if grace == 0 {
+						grace = env.cfg.OAuth2.KeyRotationGrace
+					}
+					key, err = m.Rotate(cmd.Context(), grace, audit.ActorCLI)
+				}
+				if err != nil {
+					return err
+				}
+				keysChanged(cmd, env)
+				return printKeyChange(cmd, "activated", key)
+			})
+		},
+	}
+	cmd.Flags().DurationVar(&grace, "grace", 0, "how long the previous key stays published (default OAUTH2_KEY_ROTATION_GRACE)")
+	cmd.Flags().BoolVar(&emergency, "emergency", false, "retire every other key immediately")
+	cmd.MarkFlagsMutuallyExclusive("grace", "emergency")
+	return cmd
+}
+
+func newKeysRetireCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "retire KEY_ID",
+		Short: "Stop publishing a pending or retiring signing key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withKeyManager(cmd, func(env *commandEnv, m *oauth2.KeyManager) error {
+				if err := m.Retire(cmd.Context(), args[0], audit.ActorCLI); err != nil {
+					return err
+				}
+				keysChanged(cmd, env)
+				fmt.Fprintf(cmd.OutOrStdout(), "retired signing key %s\n", args[0])
+				return nil
+			})
+		},
+	}
+}
+
+func newKeysExportJWKSCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export-jwks",
+		Short: "Print the JWKS of the published signing keys",
+		Long: "Print the JWKS that servers publish at /jwks.json, for example to preload it\n" +
+			"into relying parties that cannot reach the issuer.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			keys, err := env.store.Keys.ListValidKeys(cmd.Context())
+			if err != nil {
+				return err
+			}
+			// The signing key comes first, as in the served JWKS
+			var published []*rsa.PublicKey
+			for _, key := range keys {
+				pub, err := key.RSAPublicKey()
+				if err != nil {
+					return err
+				}
+				if key.Status == oauth2.KeyStatusActive {
+					published = append([]*rsa.PublicKey{pub}, published...)
+				} else {
+					published = append(published, pub)
+				}
+			}
+			return printJSON(cmd, oidc.NewJWKS(published))
+		},
+	}
+}
+
+func newKeyOutput(key *oauth2.Key) (keyOutput, error) {
+	pub, err := key.RSAPublicKey()
+	if err != nil {
+		return keyOutput{}, err
+	}
+	return keyOutput{
+		ID:          key.ID,
+		KeyID:       oidc.KeyID(pub),
+		Algorithm:   key.Algorithm,
+		Status:      key.Status,
+		CreatedAt:   key.CreatedAt,
+		ActivatedAt: key.ActivatedAt,
+		RetiredAt:   key.RetiredAt,
+		ExpiresAt:   key.ExpiresAt,
+		KMSKeyID:    key.KMSKeyID,
+	}, nil
+}
+
+func printKeyChange(cmd *cobra.Command, change string, key *oauth2.Key) error {
+	o, err := newKeyOutput(key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s signing key %s (kid %s, expires %s)\n", change, o.ID, o.KeyID, o.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
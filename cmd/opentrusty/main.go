@@ -0,0 +1,146 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command opentrusty runs the identity provider and its operational tasks.
+// Every command reads the same environment configuration as the server.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/opentrusty/opentrusty/internal/app"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/bus"
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/store"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	// Commands, and the server's startup and background workers, are platform-wide work; requests
+	// are scoped by the HTTP handler
+	if err := newRootCommand().ExecuteContext(tenant.WithoutScope(context.Background())); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "opentrusty",
+		Short:         "OpenTrusty identity provider",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		// Running the binary without a command serves both planes, as it always has
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd.Context(), "all")
+		},
+	}
+	root.PersistentFlags().BoolVar(&waitForDeps, "wait-for-deps", false, "retry the database and secret managers until they are reachable")
+	root.AddCommand(
+		newServeCommand(),
+		newMigrateCommand(),
+		newBootstrapCommand(),
+		newAdminCommand(),
+		newClientCommand(),
+		newKeysCommand(),
+		newConfigCommand(),
+		newSeedCommand(),
+		newTokenCommand(),
+	)
+	return root
+}
+
+// waitForDeps is set by --wait-for-deps, for compose files and init containers that start
+// alongside the database; it has the effect of STARTUP_WAIT_FOR_DEPS=true
+var waitForDeps bool
+
+// loadConfig reads the environment configuration and initializes logging from it.
+// Logs go to logOutput so one-shot commands can keep stdout for their results.
+func loadConfig(logOutput io.Writer) (*config.Config, error) {
+	if waitForDeps {
+		os.Setenv("STARTUP_WAIT_FOR_DEPS", "true")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger.InitLogger(logger.Config{
+		Level:       cfg.Observability.LogLevel,
+		Format:      cfg.Observability.LogFormat,
+		ServiceName: cfg.Observability.ServiceName,
+		Output:      logOutput,
+	})
+	return cfg, nil
+}
+
+// commandEnv holds the store and services used by the one-shot commands.
+// Audit events are written to the log and the database synchronously, and changes that running
+// servers keep in memory are published on the bus.
+type commandEnv struct {
+	cfg         *config.Config
+	store       *store.Store
+	bus         *bus.Bus
+	auditLogger audit.Logger
+	identity    *identity.Service
+}
+
+// openCommandEnv loads the configuration and connects to the database; callers must call close
+func openCommandEnv(ctx context.Context) (*commandEnv, error) {
+	cfg, err := loadConfig(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	st, err := app.OpenStore(ctx, cfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	eventBus, err := app.OpenBus(cfg, st)
+	if err != nil {
+		st.Close()
+		return nil, err
+	}
+	auditLogger := audit.NewMultiLogger(audit.NewSlogLogger(), audit.NewStoreLogger(st.AuditEvents))
+	passwordHasher := identity.NewPasswordHasher(
+		cfg.Security.Argon2Memory,
+		cfg.Security.Argon2Iterations,
+		cfg.Security.Argon2Parallelism,
+		cfg.Security.Argon2SaltLength,
+		cfg.Security.Argon2KeyLength,
+	)
+	return &commandEnv{
+		cfg:         cfg,
+		store:       st,
+		bus:         eventBus,
+		auditLogger: auditLogger,
+		identity: identity.NewService(
+			st.Users,
+			passwordHasher,
+			auditLogger,
+			cfg.Security.LockoutMaxAttempts,
+			cfg.Security.LockoutDuration,
+		),
+	}, nil
+}
+
+func (e *commandEnv) close() {
+	e.bus.Close()
+	e.store.Close()
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/spf13/cobra"
+)
+
+// seedProfile is a set of fixtures created by `opentrusty seed`
+type seedProfile struct {
+	tenantName string
+	// platformAdmin is created without a tenant and creates the tenant
+	platformAdmin seedUser
+	users         []seedUser
+	client        seedClient
+}
+
+type seedUser struct {
+	email      string
+	givenName  string
+	familyName string
+	role       string
+}
+
+type seedClient struct {
+	clientID     string
+	name         string
+	redirectURIs []string
+	scopes       []string
+	grantTypes   []string
+}
+
+// seedProfiles are the fixture sets selectable with --profile
+var seedProfiles = map[string]seedProfile{
+	"demo": {
+		tenantName:    "Demo",
+		platformAdmin: seedUser{email: "admin@demo.local", givenName: "Demo", familyName: "Admin", role: authz.RolePlatformAdmin},
+		users: []seedUser{
+			{email: "alice@demo.local", givenName: "Alice", familyName: "Example", role: tenant.RoleTenantAdmin},
+			{email: "bob@demo.local", givenName: "Bob", familyName: "Example", role: tenant.RoleTenantMember},
+		},
+		client: seedClient{
+			clientID:     "demo-app",
+			name:         "Demo App",
+			redirectURIs: []string{"http://localhost:3000/callback", "http://127.0.0.1:3000/callback"},
+			scopes:       []string{"openid", "profile", "email"},
+			grantTypes:   []string{"authorization_code", "refresh_token"},
+		},
+	},
+}
+
+// seedOutput describes the created fixtures; the password and client secret are shown only once
+type seedOutput struct {
+	Profile    string           `json:"profile"`
+	TenantID   string           `json:"tenant_id"`
+	TenantName string           `json:"tenant_name"`
+	Users      []seedUserOutput `json:"users"`
+	Password   string           `json:"password,omitempty"`
+	Client     clientOutput     `json:"client"`
+}
+
+type seedUserOutput struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	TenantID string `json:"tenant_id,omitempty"`
+	Role     string `json:"role"`
+}
+
+func newSeedCommand() *cobra.Command {
+	var (
+		profileName   string
+		output        string
+		passwordStdin bool
+	)
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Create demo fixtures for local development",
+		Long: "Create a fixture set for local development and demos: a platform admin, a tenant with\n" +
+			"an admin and a member, and a confidential OAuth2 client with fixed client_id and redirect URIs.\n" +
+			"All users share one password, generated and printed unless --password-stdin is set.\n" +
+			"On SQLite the schema is applied first, so a fresh database needs no migrate step.\n" +
+			"Do not run it against a production database.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, ok := seedProfiles[profileName]
+			if !ok {
+				return fmt.Errorf("unknown seed profile %q: use %s", profileName, strings.Join(seedProfileNames(), ", "))
+			}
+			if output != outputText && output != outputJSON {
+				return fmt.Errorf("invalid output format %q: use text or json", output)
+			}
+
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+			if env.store.Driver() == store.DriverMemory {
+				return fmt.Errorf("seed cannot populate DB_DRIVER=memory: the data would be lost when the command exits")
+			}
+			if env.store.Driver() == store.DriverSQLite {
+				if err := env.store.Migrate(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to migrate database: %w", err)
+				}
+			}
+
+			password, generated, err := readPassword(cmd, passwordStdin)
+			if err != nil {
+				return err
+			}
+			out, err := env.seed(cmd.Context(), profile, password)
+			if err != nil {
+				return err
+			}
+			out.Profile = profileName
+			if generated {
+				out.Password = password
+			}
+
+			if output == outputJSON {
+				return printJSON(cmd, out)
+			}
+			return printSeed(cmd, out)
+		},
+	}
+	cmd.Flags().StringVar(&profileName, "profile", "demo", "fixture set: "+strings.Join(seedProfileNames(), ", "))
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format: text or json")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read the users' password from stdin instead of generating one")
+	return cmd
+}
+
+func seedProfileNames() []string {
+	names := make([]string, 0, len(seedProfiles))
+	for name := range seedProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// seed creates the fixtures of profile. It refuses to run when any of them already exists,
+// so a database is never half seeded twice.
+func (e *commandEnv) seed(ctx context.Context, profile seedProfile, password string) (*seedOutput, error) {
+	tenantService := tenant.NewService(e.store.Tenants, e.store.TenantRoles, e.store.Assignments, e.auditLogger)
+	if _, err := tenantService.GetTenantByName(ctx, profile.tenantName); err == nil {
+		return nil, fmt.Errorf("tenant %q already exists; the database is already seeded", profile.tenantName)
+	}
+	if _, err := e.identity.GetByEmail(ctx, "", profile.platformAdmin.email); err == nil {
+		return nil, fmt.Errorf("platform user %s already exists", profile.platformAdmin.email)
+	}
+	if _, err := e.oauth2Service().GetClientByClientID(ctx, profile.client.clientID); err == nil {
+		return nil, fmt.Errorf("client %s already exists", profile.client.clientID)
+	}
+
+	out := &seedOutput{TenantName: profile.tenantName}
+	admin, err := e.createSeedUser(ctx, "", profile.platformAdmin, password)
+	if err != nil {
+		return nil, err
+	}
+	out.Users = append(out.Users, seedUserOutput{UserID: admin.ID, Email: admin.Email, Role: profile.platformAdmin.role})
+
+	t, err := tenantService.CreateTenant(ctx, profile.tenantName, admin.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tenant %q: %w", profile.tenantName, err)
+	}
+	e.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTenantCreated,
+		ActorID:  audit.ActorCLI,
+		Resource: audit.ResourceTenant,
+		Payload:  &audit.TenantPayload{TenantID: t.ID, TenantName: t.Name},
+	})
+	out.TenantID = t.ID
+
+	for _, u := range profile.users {
+		user, err := e.createSeedUser(ctx, t.ID, u, password)
+		if err != nil {
+			return nil, err
+		}
+		out.Users = append(out.Users, seedUserOutput{UserID: user.ID, Email: user.Email, TenantID: t.ID, Role: u.role})
+	}
+
+	client, err := e.createClient(ctx, &oauth2.Client{
+		ClientID:      profile.client.clientID,
+		TenantID:      t.ID,
+		ClientName:    profile.client.name,
+		RedirectURIs:  profile.client.redirectURIs,
+		AllowedScopes: profile.client.scopes,
+		GrantTypes:    profile.client.grantTypes,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	out.Client = *client
+	return out, nil
+}
+
+func (e *commandEnv) createSeedUser(ctx context.Context, tenantID string, u seedUser, password string) (*identity.User, error) {
+	return e.createUser(ctx, tenantID, u.email, identity.Profile{
+		GivenName:  u.givenName,
+		FamilyName: u.familyName,
+		FullName:   u.givenName + " " + u.familyName,
+	}, password, u.role)
+}
+
+func printSeed(cmd *cobra.Command, out *seedOutput) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "tenant:\t%s (%s)\n", out.TenantName, out.TenantID)
+	for _, u := range out.Users {
+		fmt.Fprintf(w, "user:\t%s\t%s\n", u.Email, u.Role)
+	}
+	if out.Password != "" {
+		fmt.Fprintf(w, "password:\t%s\n", out.Password)
+	}
+	fmt.Fprintf(w, "client_id:\t%s\n", out.Client.ClientID)
+	fmt.Fprintf(w, "client_secret:\t%s\n", out.Client.ClientSecret)
+	fmt.Fprintf(w, "redirect_uris:\t%s\n", strings.Join(out.Client.RedirectURIs, ","))
+	return w.Flush()
+}
@@ -0,0 +1,260 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/opentrusty/opentrusty/internal/app"
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/observability/tracing"
+	transportHTTP "github.com/opentrusty/opentrusty/internal/transport/http"
+	"github.com/opentrusty/opentrusty/internal/transport/tlsconfig"
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:       "serve [auth|admin|all]",
+		Short:     "Run the HTTP server for the auth plane, the admin plane or both",
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"auth", "admin", "all"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode := "all"
+			if len(args) > 0 {
+				mode = args[0]
+			}
+			return runServe(cmd.Context(), mode)
+		},
+	}
+}
+
+// runServe starts the listeners for mode and blocks until SIGINT or SIGTERM
+func runServe(ctx context.Context, mode string) error {
+	cfg, err := loadConfig(os.Stdout)
+	if err != nil {
+		return err
+	}
+	slog.Info("starting opentrusty identity provider")
+	slog.Info("running in mode", "mode", mode)
+
+	// Initialize tracer and meter; both export to the same OTLP collector
+	tracer, err := tracing.New(ctx, tracing.Config{
+		Enabled:            cfg.Observability.OTELEnabled,
+		ServiceName:        cfg.Observability.ServiceName,
+		ServiceVersion:     cfg.Observability.ServiceVersion,
+		SamplingRate:       cfg.Observability.TraceSamplingRate,
+		ResourceAttributes: cfg.Observability.ResourceAttributes,
+		Exporter:           cfg.Observability.OTLP(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracer: %w", err)
+	}
+	defer tracer.Shutdown(ctx)
+
+	meter, err := metrics.New(ctx, metrics.Config{
+		Enabled:            cfg.Observability.OTELEnabled,
+		ServiceVersion:     cfg.Observability.ServiceVersion,
+		ResourceAttributes: cfg.Observability.ResourceAttributes,
+		Exporter:           cfg.Observability.OTLP(),
+		ExportInterval:     cfg.Observability.MetricsExportInterval,
+	}, cfg.Observability.ServiceName)
+	if err != nil {
+		return fmt.Errorf("failed to initialize meter: %w", err)
+	}
+	defer meter.Shutdown(ctx)
+
+	// Initialize database
+	st, err := app.OpenStore(ctx, cfg, meter)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer st.Close()
+	slog.Info("connected to database", "driver", st.Driver())
+
+	application, err := app.New(ctx, cfg, st, meter)
+	if err != nil {
+		return err
+	}
+
+	// TLS (certificate files or ACME), shared by all listeners
+	var certs *tlsconfig.Provider
+	if cfg.TLS.Enabled() {
+		certs, err = tlsconfig.New(tlsconfig.Config{
+			CertFile:         cfg.TLS.CertFile,
+			KeyFile:          cfg.TLS.KeyFile,
+			ACMEDomains:      cfg.TLS.ACMEDomains,
+			ACMEEmail:        cfg.TLS.ACMEEmail,
+			ACMECacheDir:     cfg.TLS.ACMECacheDir,
+			ACMEDirectoryURL: cfg.TLS.ACMEDirectoryURL,
+			MinVersion:       cfg.TLS.MinVersion,
+			CipherSuites:     cfg.TLS.CipherSuites,
+		})
+		if err != nil {
+			application.Close(ctx)
+			return fmt.Errorf("failed to initialize tls: %w", err)
+		}
+	}
+
+	// Create one HTTP server per listener; each plane gets its own handler, middleware stack and rate limiters
+	planes := listeners(cfg, mode)
+	servers := make([]*http.Server, 0, len(planes))
+	for _, l := range planes {
+		handler, err := application.Handler(l.mode)
+		if err != nil {
+			application.Close(ctx)
+			return err
+		}
+		server := &http.Server{
+			Addr:         l.addr,
+			Handler:      handler,
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		}
+		if certs != nil {
+			server.TLSConfig = certs.TLSConfig()
+		}
+		servers = append(servers, server)
+	}
+
+	// Reload certificate files when they change or on SIGHUP
+	certsCtx, stopCerts := context.WithCancel(ctx)
+	defer stopCerts()
+	if certs != nil {
+		go certs.Watch(certsCtx, cfg.TLS.ReloadInterval)
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for {
+				select {
+				case <-certsCtx.Done():
+					return
+				case <-hup:
+					if err := certs.Reload(); err != nil {
+						slog.Error("failed to reload tls certificate", logger.Error(err))
+					}
+				}
+			}
+		}()
+	}
+
+	// Start the event bus and the background jobs
+	if err := application.Start(ctx); err != nil {
+		application.Close(ctx)
+		return err
+	}
+
+	// Start servers; the first listener that fails stops the process
+	serveErr := make(chan error, len(servers)+1)
+	for i, server := range servers {
+		l := planes[i]
+		go func() {
+			slog.Info("starting http server", logger.Component("server"), logger.Operation("listen"), "plane", l.mode)
+			slog.Info(fmt.Sprintf("listening on %s", server.Addr), "plane", l.mode, "tls", server.TLSConfig != nil)
+			serve := server.ListenAndServe
+			if server.TLSConfig != nil {
+				serve = func() error { return server.ListenAndServeTLS("", "") }
+			}
+			if err := serve(); err != nil && err != http.ErrServerClosed {
+				serveErr <- fmt.Errorf("%s plane: %w", l.mode, err)
+			}
+		}()
+	}
+
+	// Profiling and runtime metrics on a loopback-only port, when enabled
+	var diagnostics *http.Server
+	if cfg.Server.DiagnosticsPort != "" {
+		diagnostics = &http.Server{
+			Addr:              net.JoinHostPort(cfg.Server.DiagnosticsHost, cfg.Server.DiagnosticsPort),
+			Handler:           transportHTTP.NewDiagnosticsHandler(),
+			ReadHeaderTimeout: cfg.Server.ReadTimeout,
+			// No write timeout: CPU profiles and traces stream for as long as requested
+		}
+		go func() {
+			slog.Info(fmt.Sprintf("diagnostics listening on %s", diagnostics.Addr), logger.Component("diagnostics"))
+			if err := diagnostics.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serveErr <- fmt.Errorf("diagnostics: %w", err)
+			}
+		}()
+	}
+
+	// Wait for interrupt signal or a listener failure
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	var runErr error
+	select {
+	case <-quit:
+	case runErr = <-serveErr:
+		slog.Error("server error", logger.Error(runErr))
+	}
+
+	slog.Info("shutting down server")
+	stopCerts()
+
+	// Graceful shutdown: drain requests, then stop the background jobs, all within the deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("server shutdown error", logger.Error(err))
+		}
+	}
+	if diagnostics != nil {
+		if err := diagnostics.Close(); err != nil {
+			slog.Error("diagnostics shutdown error", logger.Error(err))
+		}
+	}
+
+	// Let job runs in progress finish, then deliver queued and buffered audit events
+	application.Close(shutdownCtx)
+
+	slog.Info("server stopped")
+	return runErr
+}
+
+// listener is an address serving the routes of one mode
+type listener struct {
+	mode string
+	addr string
+}
+
+// listeners returns the HTTP listeners for mode. Without SERVER_ADMIN_PORT every mode is served
+// on SERVER_HOST:SERVER_PORT; with it the admin plane moves to its own address, so "all" runs
+// an auth plane and an admin plane server side by side.
+func listeners(cfg *config.Config, mode string) []listener {
+	authAddr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
+	if cfg.Server.AdminPort == "" {
+		return []listener{{mode: mode, addr: authAddr}}
+	}
+	adminAddr := fmt.Sprintf("%s:%s", cfg.Server.AdminHost, cfg.Server.AdminPort)
+	switch mode {
+	case "auth":
+		return []listener{{mode: "auth", addr: authAddr}}
+	case "admin":
+		return []listener{{mode: "admin", addr: adminAddr}}
+	}
+	return []listener{{mode: "auth", addr: authAddr}, {mode: "admin", addr: adminAddr}}
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/oidc"
+	"github.com/spf13/cobra"
+)
+
+// Token formats recognized by token inspect
+const (
+	tokenFormatJWT    = "jwt"
+	tokenFormatOpaque = "opaque"
+)
+
+// tokenInspection is the result of token inspect. JWTs report their signature and claims;
+// opaque tokens report the stored token.
+type tokenInspection struct {
+	Format         string             `json:"format"`
+	KeyID          string             `json:"kid,omitempty"`
+	SigningKey     *keyOutput         `json:"signing_key,omitempty"`
+	SignatureValid bool               `json:"signature_valid,omitempty"`
+	SignatureError string             `json:"signature_error,omitempty"`
+	Claims         jwt.MapClaims      `json:"claims,omitempty"`
+	Token          *oauth2.TokenInfo  `json:"token,omitempty"`
+	ExpiresAt      *time.Time         `json:"expires_at,omitempty"`
+	Expired        bool               `json:"expired"`
+	Client         *tokenClientOutput `json:"client,omitempty"`
+	User           *tokenUserOutput   `json:"user,omitempty"`
+}
+
+type tokenClientOutput struct {
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	TenantID   string `json:"tenant_id"`
+}
+
+type tokenUserOutput struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+func newTokenCommand() *cobra.Command {
+	token := &cobra.Command{
+		Use:   "token",
+		Short: "Inspect issued tokens",
+	}
+	token.AddCommand(newTokenInspectCommand())
+	return token
+}
+
+func newTokenInspectCommand() *cobra.Command {
+	var output, tenantID string
+	cmd := &cobra.Command{
+		Use:   "inspect TOKEN",
+		Short: "Verify an ID token or look up an access or refresh token",
+		Long: "Inspect a token for debugging an integration.\n\n" +
+			"A JWT (ID token) is verified against the signing keys in the database, including retired\n" +
+			"ones, and its claims are printed even when the signature does not verify. Any other value is\n" +
+			"looked up as an access or refresh token of the --tenant tenant and printed with its expiry,\n" +
+			"revocation status, client and user. Pass - to read the token from stdin and keep it out of\n" +
+			"the shell history.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputText && output != outputJSON {
+				return fmt.Errorf("invalid output format %q: use text or json", output)
+			}
+			raw := args[0]
+			if raw == "-" {
+				line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil && err != io.EOF {
+					return fmt.Errorf("failed to read token: %w", err)
+				}
+				raw = line
+			}
+			raw = strings.TrimSpace(raw)
+
+			env, err := openCommandEnv(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer env.close()
+
+			var result *tokenInspection
+			if strings.Count(raw, ".") == 2 {
+				result, err = env.inspectJWT(cmd, raw)
+			} else {
+				if tenantID == "" {
+					return fmt.Errorf("--tenant is required to look up an access or refresh token")
+				}
+				result, err = env.inspectOpaqueToken(cmd, tenantID, raw)
+			}
+			if err != nil {
+				return err
+			}
+
+			if output == outputJSON {
+				return printJSON(cmd, result)
+			}
+			return printTokenInspection(cmd, result)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format: text or json")
+	cmd.Flags().StringVar(&tenantID, "tenant", "", "tenant ID the access or refresh token was issued in")
+	return cmd
+}
+
+// inspectJWT verifies a JWT against every stored signing key and reports the audience's client
+func (e *commandEnv) inspectJWT(cmd *cobra.Command, raw string) (*tokenInspection, error) {
+	keys, err := e.store.Keys.List(cmd.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	byKid := make(map[string]*rsa.PublicKey, len(keys))
+	stored := make(map[string]*oauth2.Key, len(keys))
+	for _, key := range keys {
+		pub, err := key.RSAPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		byKid[oidc.KeyID(pub)] = pub
+		stored[oidc.KeyID(pub)] = key
+	}
+
+	result := &tokenInspection{Format: tokenFormatJWT}
+	kid, claims, err := oidc.VerifyIDToken(raw, byKid)
+	result.KeyID = kid
+	if err != nil {
+		result.SignatureError = err.Error()
+		// Show what the token claims anyway; it is marked as not verified
+		token, _, parseErr := jwt.NewParser().ParseUnverified(raw, jwt.MapClaims{})
+		if parseErr != nil {
+			return nil, fmt.Errorf("not a valid JWT: %w", parseErr)
+		}
+		claims, _ = token.Claims.(jwt.MapClaims)
+	} else {
+		result.SignatureValid = true
+	}
+	result.Claims = claims
+	if key, ok := stored[kid]; ok {
+		o, err := newKeyOutput(key)
+		if err != nil {
+			return nil, err
+		}
+		result.SigningKey = &o
+	}
+
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		result.ExpiresAt = &exp.Time
+		result.Expired = exp.Before(time.Now())
+	}
+	if aud, err := claims.GetAudience(); err == nil && len(aud) > 0 {
+		result.Client = e.tokenClient(cmd, aud[0])
+	}
+	return result, nil
+}
+
+// inspectOpaqueToken looks a token of the tenant up by its hash and reports its client and user
+func (e *commandEnv) inspectOpaqueToken(cmd *cobra.Command, tenantID, raw string) (*tokenInspection, error) {
+	info, err := e.oauth2Service().LookupToken(cmd.Context(), tenantID, raw)
+	if errors.Is(err, oauth2.ErrTokenNotFound) {
+		return nil, fmt.Errorf("no access or refresh token of tenant %s matches this value; tokens are stored hashed and purged after expiry", tenantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &tokenInspection{
+		Format:    tokenFormatOpaque,
+		Token:     info,
+		ExpiresAt: &info.ExpiresAt,
+		Expired:   info.ExpiresAt.Before(time.Now()),
+		Client:    e.tokenClient(cmd, info.ClientID),
+	}
+	if user, err := e.identity.GetUser(cmd.Context(), info.UserID); err == nil {
+		result.User = &tokenUserOutput{UserID: user.ID, Email: user.Email}
+	}
+	return result, nil
+}
+
+// tokenClient returns the client with the public clientID, or nil if it no longer exists
+func (e *commandEnv) tokenClient(cmd *cobra.Command, clientID string) *tokenClientOutput {
+	client, err := e.oauth2Service().GetClientByClientID(cmd.Context(), clientID)
+	if err != nil {
+		return nil
+	}
+	return &tokenClientOutput{ClientID: client.ClientID, ClientName: client.ClientName, TenantID: client.TenantID}
+}
+
+func printTokenInspection(cmd *cobra.Command, r *tokenInspection) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "format:\t%s\n", r.Format)
+	if r.Format == tokenFormatJWT {
+		fmt.Fprintf(w, "kid:\t%s\n", r.KeyID)
+		if r.SigningKey != nil {
+			fmt.Fprintf(w, "signing key:\t%s (%s)\n", r.SigningKey.ID, r.SigningKey.Status)
+		}
+		if r.SignatureValid {
+			fmt.Fprintf(w, "signature:\tvalid\n")
+		} else {
+			fmt.Fprintf(w, "signature:\tINVALID (%s); the claims below are unverified\n", r.SignatureError)
+		}
+		names := make([]string, 0, len(r.Claims))
+		for name := range r.Claims {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "claim %s:\t%s\n", name, claimValue(name, r.Claims[name]))
+		}
+	} else {
+		t := r.Token
+		fmt.Fprintf(w, "kind:\t%s\n", t.Kind)
+		fmt.Fprintf(w, "id:\t%s\n", t.ID)
+		fmt.Fprintf(w, "tenant:\t%s\n", t.TenantID)
+		fmt.Fprintf(w, "scope:\t%s\n", t.Scope)
+		fmt.Fprintf(w, "issued:\t%s\n", t.CreatedAt.Format(time.RFC3339))
+		if t.Revoked {
+			revokedAt := "unknown time"
+			if t.RevokedAt != nil {
+				revokedAt = t.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "revoked:\tyes, at %s\n", revokedAt)
+		} else {
+			fmt.Fprintf(w, "revoked:\tno\n")
+		}
+		if r.User != nil {
+			fmt.Fprintf(w, "user:\t%s (%s)\n", r.User.Email, r.User.UserID)
+		} else {
+			fmt.Fprintf(w, "user:\t%s (not found)\n", t.UserID)
+		}
+	}
+	if r.ExpiresAt != nil {
+		state := "valid"
+		if r.Expired {
+			state = "EXPIRED"
+		}
+		fmt.Fprintf(w, "expires:\t%s (%s)\n", r.ExpiresAt.Format(time.RFC3339), state)
+	}
+	if r.Client != nil {
+		fmt.Fprintf(w, "client:\t%s %q (tenant %s)\n", r.Client.ClientID, r.Client.ClientName, r.Client.TenantID)
+	}
+	return w.Flush()
+}
+
+// claimValue formats a claim for display; NumericDate claims also show the time they stand for
+func claimValue(name string, v any) string {
+	n, ok := v.(float64)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	formatted := strconv.FormatFloat(n, 'f', -1, 64)
+	switch name {
+	case "exp", "iat", "nbf", "auth_time":
+		return fmt.Sprintf("%s (%s)", formatted, time.Unix(int64(n), 0).UTC().Format(time.RFC3339))
+	}
+	return formatted
+}
@@ -36,6 +36,7 @@ const docsConfig = {
                 { title: "Storage Model", file: "docs/fundamentals/storage-model.md" },
                 { title: "Operations Guide", file: "docs/deployment/OPERATIONS.md" },
                 { title: "Operations Manual", file: "docs/operations/bootstrap.md" },
+                { title: "Secrets", file: "docs/operations/secrets.md" },
                 { title: "Systemd Guide", file: "deploy/systemd/README.md" }
             ]
         },
@@ -0,0 +1,824 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package app assembles the identity provider from its configuration: audit logging, the domain
+// services, the HTTP handler of each plane and the background jobs.
+//
+// `opentrusty serve` runs an App on its own listeners; pkg/opentrusty embeds one in another
+// program. Listeners, TLS and process signals are left to the caller.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/audit/archive"
+	"github.com/opentrusty/opentrusty/internal/audit/sink"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/bus"
+	"github.com/opentrusty/opentrusty/internal/captcha"
+	"github.com/opentrusty/opentrusty/internal/cluster"
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/janitor"
+	"github.com/opentrusty/opentrusty/internal/jobs"
+	"github.com/opentrusty/opentrusty/internal/lifecycle"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/oidc"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/replay"
+	"github.com/opentrusty/opentrusty/internal/secrets"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store"
+	"github.com/opentrusty/opentrusty/internal/store/postgres"
+	"github.com/opentrusty/opentrusty/internal/store/sqlite"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	transportHTTP "github.com/opentrusty/opentrusty/internal/transport/http"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// App is an assembled identity provider
+type App struct {
+	cfg   *config.Config
+	store *store.Store
+	meter *metrics.Meter
+	bus   *bus.Bus
+
+	auditLogger  audit.Logger
+	auditQueue   *audit.AsyncLogger
+	auditStreams []*audit.SinkLogger
+
+	Identity *identity.Service
+	Sessions *session.Service
+	OAuth2   *oauth2.Service
+	OIDC     *oidc.Service
+	Authz    *authz.Service
+	Tenants  *tenant.Service
+	// Memberships records the tenants each user is a member of besides the one they are registered in
+	Memberships *tenant.MembershipService
+	Email       *email.Service
+	Webhooks    *webhook.Service
+	Audit       *audit.Service
+	// Replay records one-time identifiers (jti) for the protocol features that refuse replays
+	Replay replay.Store
+
+	keyManager *oauth2.KeyManager
+	ipFilter   *transportHTTP.IPFilter
+	challenge  *captcha.Challenge
+	accessLog  *transportHTTP.AccessLog
+	jobRunner  *jobs.Runner
+	cluster    *cluster.Registry
+	lifecycle  *lifecycle.Manager
+}
+
+// New assembles the identity provider on st. Metrics are recorded on meter. The caller keeps
+// ownership of st and closes it after Close.
+func New(ctx context.Context, cfg *config.Config, st *store.Store, meter *metrics.Meter) (*App, error) {
+	a := &App{cfg: cfg, store: st, meter: meter}
+
+	// SQLite is for development; apply the schema on startup so it works without a migrate step
+	if st.Driver() == store.DriverSQLite {
+		if err := st.Migrate(ctx); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	if err := a.initAudit(); err != nil {
+		return nil, err
+	}
+	// From here on, failures must release the audit queue and sinks
+	ok := false
+	defer func() {
+		if !ok {
+			a.Close(ctx)
+		}
+	}()
+
+	// Initialize helpers
+	passwordHasher := identity.NewPasswordHasher(
+		cfg.Security.Argon2Memory,
+		cfg.Security.Argon2Iterations,
+		cfg.Security.Argon2Parallelism,
+		cfg.Security.Argon2SaltLength,
+		cfg.Security.Argon2KeyLength,
+	)
+
+	// Initialize services
+	a.Identity = identity.NewService(
+		st.Users,
+		passwordHasher,
+		a.auditLogger,
+		cfg.Security.LockoutMaxAttempts,
+		cfg.Security.LockoutDuration,
+	)
+	a.Sessions = session.NewService(st.Sessions, a.auditLogger, cfg.Session.Lifetime, cfg.Session.IdleTimeout)
+
+	// Phase II.1: Initialize OIDC Service
+	var err error
+	a.OIDC, err = oidc.NewService(cfg.OAuth2.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize OIDC service: %w", err)
+	}
+	kms, err := cfg.Secrets.KMS()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize KMS: %w", err)
+	}
+	a.keyManager, err = oauth2.NewKeyManager(st.Keys, []byte(cfg.Security.KeyEncryptionKey), kms, a.auditLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize key manager: %w", err)
+	}
+	if err := a.loadSigningKeys(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	// Replicas share client changes and key rotations over the event bus so that their in-memory
+	// copies do not go stale until the next TTL or reload interval
+	a.bus, err = OpenBus(cfg, st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize event bus: %w", err)
+	}
+	a.Replay, err = OpenReplay(cfg, st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize replay store: %w", err)
+	}
+
+	// Cache client lookups for the authorize, token and revoke endpoints
+	clientCache := oauth2.NewClientCache(st.Clients, cfg.OAuth2.ClientCacheTTL, a.bus)
+	reloadKeys := func(ctx context.Context) {
+		if err := a.loadSigningKeys(ctx); err != nil {
+			slog.Error("failed to reload signing keys", logger.Error(err))
+		}
+	}
+	a.bus.Subscribe(bus.TopicClientChanged, func(_ context.Context, id string) {
+		clientCache.Invalidate(id)
+	})
+	a.bus.Subscribe(bus.TopicKeysChanged, func(ctx context.Context, _ string) {
+		reloadKeys(ctx)
+	})
+	// Messages sent while disconnected are lost; start over from the database
+	a.bus.OnConnect(func(ctx context.Context) {
+		clientCache.Invalidate("")
+		reloadKeys(ctx)
+	})
+
+	a.OAuth2 = oauth2.NewService(
+		clientCache,
+		st.Codes,
+		st.AccessTokens,
+		st.RefreshTokens,
+		a.auditLogger,
+		a.OIDC,
+		cfg.OAuth2.AuthCodeLifetime,
+		cfg.OAuth2.AccessTokenLifetime,
+		cfg.OAuth2.RefreshTokenLifetime,
+	)
+	a.OAuth2.SetScopes(st.Scopes, a.Identity)
+	a.OAuth2.EnableRequestObjects(cfg.OAuth2.Issuer, nil)
+	a.OAuth2.SetSessions(a.Sessions)
+	a.OAuth2.EnablePendingAuthorizations(st.PendingAuthorizations)
+	a.OAuth2.EnableProtocolLogging(st.ProtocolLogs)
+	a.OIDC.SetCapabilities(oauth2Capabilities(a.OAuth2))
+	if cfg.OAuth2.ClaimsWebhookURL != "" {
+		a.OAuth2.SetClaimEnricher(oauth2.NewWebhookEnricher(cfg.OAuth2.ClaimsWebhookURL, cfg.OAuth2.ClaimsWebhookSecret, cfg.OAuth2.ClaimsWebhookTimeout), cfg.OAuth2.ClaimsRequired)
+	}
+	a.Authz = authz.NewService(st.Projects, st.Roles, st.Assignments)
+	a.Tenants = tenant.NewService(st.Tenants, st.TenantRoles, st.Assignments, a.auditLogger)
+	a.Tenants.EnableSecurityPolicies(st.SecurityPolicies, securityBounds(cfg))
+	a.Identity.SetLockoutPolicy(func(ctx context.Context, tenantID string) (int, time.Duration) {
+		settings := a.Tenants.EffectiveSecurity(ctx, tenantID)
+		return settings.LockoutMaxAttempts, settings.LockoutDuration
+	})
+	a.Identity.SetPasswordAgePolicy(func(ctx context.Context, tenantID string) time.Duration {
+		if tenantID == "" {
+			return cfg.Security.PasswordMaxAge
+		}
+		return a.Tenants.EffectiveSecurity(ctx, tenantID).PasswordMaxAge
+	})
+	// Erasing an account ends its sessions and revokes its tokens before anonymizing the user
+	a.Identity.EnableAccountDeletion(st.AccountDeletions, cfg.Security.AccountDeletionGrace,
+		a.Sessions.DestroyAllForUser,
+		a.OAuth2.RevokeUserTokens,
+	)
+	a.Memberships = tenant.NewMembershipService(st.Memberships, a.Tenants, a.auditLogger)
+	// Deleting an account must not leave a tenant without an owner or the platform without an admin
+	a.Identity.SetDeletionGuard(func(ctx context.Context, user *identity.User) error {
+		homeTenantID := ""
+		if user.TenantID != nil {
+			homeTenantID = *user.TenantID
+		}
+		lastOwner, err := a.Memberships.IsLastOwnerAnywhere(ctx, homeTenantID, user.ID)
+		if err != nil {
+			return err
+		}
+		lastAdmin, err := a.Authz.IsLastPlatformAdmin(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if lastOwner || lastAdmin {
+			return identity.ErrLastAdministrator
+		}
+		return nil
+	})
+
+	// Platform default sender (optional); tenants may override with their own SMTP settings
+	var platformSender email.Sender
+	if cfg.Email.SMTPHost != "" {
+		platformSender = email.NewSMTPSender(email.SMTPConfig{
+			Host:        cfg.Email.SMTPHost,
+			Port:        cfg.Email.SMTPPort,
+			Username:    cfg.Email.SMTPUsername,
+			Password:    cfg.Email.SMTPPassword,
+			FromAddress: cfg.Email.FromAddress,
+			FromName:    cfg.Email.FromName,
+			TLSMode:     cfg.Email.SMTPTLSMode,
+		})
+	} else {
+		slog.Warn("no platform email sender configured; set EMAIL_SMTP_HOST to enable outbound email")
+	}
+	a.Email, err = email.NewService(
+		st.EmailSettings,
+		platformSender,
+		[]byte(cfg.Security.KeyEncryptionKey),
+		a.auditLogger,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize email service: %w", err)
+	}
+	a.Email.SetAllowedHosts(cfg.Email.TenantSMTPAllowedHosts)
+	a.Identity.SetMailer(a.Email)
+	if cfg.Email.ChangeURL != "" {
+		a.Identity.EnableEmailChange(st.EmailChanges, a.Email, identity.EmailChangeConfig{
+			URL:        cfg.Email.ChangeURL,
+			CoolingOff: cfg.Email.ChangeCoolingOff,
+		})
+	}
+
+	a.Webhooks, err = webhook.NewService(
+		st.Webhooks,
+		st.Deliveries,
+		[]byte(cfg.Security.KeyEncryptionKey),
+		a.auditLogger,
+		cfg.Webhook.AllowInsecure,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize webhook service: %w", err)
+	}
+	if cfg.Webhook.AllowInsecure {
+		slog.Warn("webhooks may target http:// and private network endpoints; do not use WEBHOOK_ALLOW_INSECURE in production")
+	}
+	a.Audit = audit.NewService(st.AuditEvents, st.AuditRetention, a.auditLogger, cfg.Audit.RetentionDays)
+
+	// The initial platform admin is created by `opentrusty bootstrap`, never at startup
+	if exists, err := st.Assignments.CheckExists(ctx, rbac.RoleIDPlatformAdmin, authz.ScopePlatform, nil); err != nil {
+		slog.Warn("failed to check for a platform admin", logger.Error(err))
+	} else if !exists {
+		slog.Warn("no platform admin exists; run `opentrusty bootstrap` to create one")
+	}
+
+	// Admin plane client address allow/deny lists
+	a.ipFilter, err = transportHTTP.NewIPFilter(transportHTTP.IPFilterConfig{
+		Allow:          cfg.AdminIP.Allow,
+		Deny:           cfg.AdminIP.Deny,
+		TenantAllow:    cfg.AdminIP.TenantAllowlists(),
+		TrustedProxies: cfg.Server.TrustedProxies,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize admin ip filter: %w", err)
+	}
+
+	// Login challenge; its abuse flags are shared by all planes
+	if cfg.Captcha.Enabled() {
+		verifier, err := captcha.NewVerifier(cfg.Captcha.Provider, cfg.Captcha.SecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize captcha verifier: %w", err)
+		}
+		a.challenge = &captcha.Challenge{
+			Provider:    cfg.Captcha.Provider,
+			SiteKey:     cfg.Captcha.SiteKey,
+			Verifier:    verifier,
+			Mode:        cfg.Captcha.Mode,
+			TenantModes: cfg.Captcha.TenantModeMap(),
+			Flags:       captcha.NewFlags(cfg.Captcha.FailureThreshold, cfg.Captcha.FlagWindow),
+		}
+	}
+
+	// Access log and per-route latency histogram, shared by all planes
+	a.accessLog, err = transportHTTP.NewAccessLog(meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize access log: %w", err)
+	}
+
+	// Background jobs report their last run to metrics and the health check
+	a.jobRunner, err = jobs.NewRunner(meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize job runner: %w", err)
+	}
+
+	// Replicas announce their signing key fingerprints so key drift shows up on the admin API
+	busTransport := ""
+	switch {
+	case cfg.Bus.RedisURL != "":
+		busTransport = "redis"
+	case st.BusTransport() != nil:
+		busTransport = "database"
+	}
+	a.cluster = cluster.NewRegistry(a.bus, cfg.OAuth2.KeyReloadInterval, cluster.Checklist(cluster.Setup{
+		SharedDatabase: st.Driver() == store.DriverPostgres,
+		BusTransport:   busTransport,
+		ReplayRedis:    cfg.Replay.RedisURL != "",
+	}), a.OIDC.KeyIDs)
+
+	ok = true
+	return a, nil
+}
+
+// initAudit sets up audit logging: logs, database and tenant webhooks, business metrics, plus
+// optional streaming sinks, behind an optional queue
+func (a *App) initAudit() error {
+	cfg := a.cfg
+	businessMetrics, err := audit.NewMetricsLogger(audit.MetricsConfig{
+		MaxTenants: cfg.Observability.MetricsMaxTenants,
+		MaxClients: cfg.Observability.MetricsMaxClients,
+	}, a.meter)
+	if err != nil {
+		return fmt.Errorf("failed to initialize business metrics: %w", err)
+	}
+	auditLoggers := []audit.Logger{
+		audit.NewSlogLogger(),
+		audit.NewStoreLogger(a.store.AuditEvents),
+		webhook.NewPublisher(a.store.Webhooks, a.store.Deliveries),
+		businessMetrics,
+	}
+	for _, sinkType := range cfg.Audit.Sinks {
+		auditStream, err := newAuditStream(cfg, sinkType, a.meter)
+		if err != nil {
+			a.closeAuditStreams(context.Background())
+			return fmt.Errorf("failed to initialize audit sink %s: %w", sinkType, err)
+		}
+		a.auditStreams = append(a.auditStreams, auditStream)
+		auditLoggers = append(auditLoggers, auditStream)
+	}
+	a.auditLogger = audit.NewMultiLogger(auditLoggers...)
+	if cfg.Audit.Async {
+		a.auditQueue, err = audit.NewAsyncLogger(a.auditLogger, audit.AsyncConfig{
+			QueueSize: cfg.Audit.QueueSize,
+			Overflow:  audit.OverflowPolicy(cfg.Audit.QueueOverflow),
+		}, a.meter)
+		if err != nil {
+			a.closeAuditStreams(context.Background())
+			return fmt.Errorf("failed to initialize audit queue: %w", err)
+		}
+		a.auditLogger = a.auditQueue
+	}
+	return nil
+}
+
+// AuditLogger returns the logger audit events are recorded with
+func (a *App) AuditLogger() audit.Logger {
+	return a.auditLogger
+}
+
+// Handler returns the HTTP handler serving the routes of mode (auth, admin or all). Each call
+// creates a handler with its own middleware stack and rate limiters, as every listener gets.
+func (a *App) Handler(mode string) (http.Handler, error) {
+	cfg := a.cfg
+
+	// Rate Limiters (per IP, tenant, OAuth2 client and login user)
+	ipLimit := transportHTTP.RateLimit{RequestsPerSecond: cfg.RateLimit.RequestsPerSecond, Burst: cfg.RateLimit.Burst}
+	if mode == "admin" {
+		ipLimit = transportHTTP.RateLimit{RequestsPerSecond: cfg.RateLimit.AdminRPS, Burst: cfg.RateLimit.AdminBurst}
+	}
+	rateLimits, err := transportHTTP.NewRateLimits(transportHTTP.RateLimitConfig{
+		IP:     ipLimit,
+		Tenant: transportHTTP.RateLimit{RequestsPerSecond: cfg.RateLimit.TenantRPS, Burst: cfg.RateLimit.TenantBurst},
+		Client: transportHTTP.RateLimit{RequestsPerSecond: cfg.RateLimit.ClientRPS, Burst: cfg.RateLimit.ClientBurst},
+		User:   transportHTTP.RateLimit{RequestsPerSecond: cfg.RateLimit.LoginRPS, Burst: cfg.RateLimit.LoginBurst},
+		ClientAuth: transportHTTP.ClientAuthBackoff{
+			MaxFailures: cfg.RateLimit.ClientAuthMaxFailures,
+			Backoff:     cfg.RateLimit.ClientAuthBackoff,
+			MaxBackoff:  cfg.RateLimit.ClientAuthMaxBackoff,
+		},
+		Abuse:   a.challengeFlags(),
+		Tenants: a.tenantRateLimits,
+	}, a.meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rate limiters: %w", err)
+	}
+
+	// Configure SameSite mode
+	sameSite := http.SameSiteLaxMode
+	switch cfg.Session.CookieSameSite {
+	case "Strict":
+		sameSite = http.SameSiteStrictMode
+	case "None":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	handler := transportHTTP.NewHandler(
+		a.Identity,
+		a.Sessions,
+		a.OAuth2,
+		a.Authz,
+		a.Tenants,
+		a.Memberships,
+		a.OIDC,
+		a.Email,
+		a.Audit,
+		a.Webhooks,
+		a.auditLogger,
+		transportHTTP.SessionConfig{
+			CookieName:     cfg.Session.CookieName,
+			CookieDomain:   cfg.Session.CookieDomain,
+			CookiePath:     cfg.Session.CookiePath,
+			CookieSecure:   cfg.Session.CookieSecure,
+			CookieHTTPOnly: cfg.Session.CookieHTTPOnly,
+			CookieSameSite: sameSite,
+		},
+		transportHTTP.APIDocsConfig{
+			Version:  cfg.Observability.ServiceVersion,
+			Explorer: cfg.Server.APIExplorer,
+		},
+		transportHTTP.RouteTimeouts{
+			Default: cfg.Server.RequestTimeout,
+			OAuth2:  cfg.Server.OAuth2Timeout,
+			Bulk:    cfg.Server.BulkTimeout,
+		},
+		a.ipFilter,
+		authz.ScopePermissions(cfg.OAuth2.AdminScopePermissions()),
+		a.challenge,
+		a.jobRunner,
+		a.cluster,
+		a.store.Saturated,
+		cfg.OAuth2.LoginURL,
+		cfg.Security.SecretGuard,
+		cfg.Server.DevRP,
+		mode,
+	)
+	return transportHTTP.NewRouter(handler, rateLimits, a.accessLog, mode), nil
+}
+
+// securityBounds returns the platform's lockout and rate limit settings and the strictest a tenant's
+// security policy may choose
+func securityBounds(cfg *config.Config) tenant.SecurityBounds {
+	return tenant.SecurityBounds{
+		Platform: tenant.SecuritySettings{
+			LockoutMaxAttempts: cfg.Security.LockoutMaxAttempts,
+			LockoutDuration:    cfg.Security.LockoutDuration,
+			LoginRPS:           cfg.RateLimit.LoginRPS,
+			LoginBurst:         max(cfg.RateLimit.LoginBurst, 1),
+			TokenRPS:           cfg.RateLimit.ClientRPS,
+			TokenBurst:         max(cfg.RateLimit.ClientBurst, 1),
+			PasswordMaxAge:     cfg.Security.PasswordMaxAge,
+		},
+		Strictest: tenant.SecuritySettings{
+			LockoutMaxAttempts: cfg.Security.TenantMinLockoutAttempts,
+			LockoutDuration:    cfg.Security.TenantMaxLockoutDuration,
+			LoginRPS:           cfg.RateLimit.TenantMinLoginRPS,
+			LoginBurst:         1,
+			TokenRPS:           cfg.RateLimit.TenantMinClientRPS,
+			TokenBurst:         1,
+			PasswordMaxAge:     cfg.Security.TenantMinPasswordMaxAge,
+		},
+	}
+}
+
+// tenantRateLimits returns the login and client rate limits of a tenant whose security policy
+// changes them. Other tenants get none, so only the platform's layers apply to them.
+func (a *App) tenantRateLimits(ctx context.Context, tenantID string) (login, client transportHTTP.RateLimit) {
+	settings, platform := a.Tenants.EffectiveSecurity(ctx, tenantID), a.Tenants.SecurityBounds().Platform
+	if settings.LoginRPS != platform.LoginRPS || settings.LoginBurst != platform.LoginBurst {
+		login = transportHTTP.RateLimit{RequestsPerSecond: settings.LoginRPS, Burst: settings.LoginBurst}
+	}
+	if settings.TokenRPS != platform.TokenRPS || settings.TokenBurst != platform.TokenBurst {
+		client = transportHTTP.RateLimit{RequestsPerSecond: settings.TokenRPS, Burst: settings.TokenBurst}
+	}
+	return login, client
+}
+
+// challengeFlags returns the abuse flags of the login challenge, or nil when none is configured
+func (a *App) challengeFlags() *captcha.Flags {
+	if a.challenge == nil {
+		return nil
+	}
+	return a.challenge.Flags
+}
+
+// Start runs the event bus and the background jobs until ctx is done or Close is called: the
+// janitor, the signing key reload and the webhook dispatcher. They are platform-wide work,
+// whatever scope ctx has: without one, PostgreSQL row-level security would show them no rows.
+func (a *App) Start(ctx context.Context) error {
+	ctx = tenant.WithoutScope(ctx)
+	cfg := a.cfg
+	a.lifecycle = a.newLifecycle(ctx)
+	a.lifecycle.Go("bus", func(ctx context.Context, stop <-chan struct{}) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		a.bus.Run(ctx)
+		return nil
+	})
+
+	// Start the janitor that purges expired sessions, codes and tokens, old soft-deleted rows
+	// and audit events past their retention
+	if cfg.Janitor.Enabled {
+		var archiver audit.Archiver
+		if cfg.Audit.ArchiveBucket != "" {
+			var err error
+			archiver, err = archive.NewS3(archive.Config{
+				Endpoint:  cfg.Audit.ArchiveEndpoint,
+				Region:    cfg.Audit.ArchiveRegion,
+				Bucket:    cfg.Audit.ArchiveBucket,
+				Prefix:    cfg.Audit.ArchivePrefix,
+				AccessKey: cfg.Audit.ArchiveAccessKey,
+				SecretKey: cfg.Audit.ArchiveSecretKey,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to initialize audit archive: %w", err)
+			}
+		}
+		tasks := append(janitor.StoreTasks(a.store, cfg.Janitor.SoftDeleteRetention), janitor.Task{
+			Name:  "audit_events",
+			Purge: audit.NewPurger(a.store.AuditEvents, a.store.AuditRetention, cfg.Audit.RetentionDays, archiver).Purge,
+		})
+		if retention := cfg.Webhook.DeliveryRetention; retention > 0 {
+			tasks = append(tasks, janitor.Task{
+				Name: "webhook_deliveries",
+				Purge: func(ctx context.Context, limit int) (int64, error) {
+					return a.store.Deliveries.DeleteFinished(ctx, time.Now().Add(-retention), limit)
+				},
+			})
+		}
+		j, err := janitor.New(janitor.Config{
+			Interval:  cfg.Janitor.Interval,
+			Jitter:    cfg.Janitor.Jitter,
+			BatchSize: cfg.Janitor.BatchSize,
+		}, tasks, a.meter)
+		if err != nil {
+			return fmt.Errorf("failed to initialize janitor: %w", err)
+		}
+		a.startJob(j.Job())
+	}
+
+	// Reload the signing keys so rotations made with `opentrusty keys` reach every instance
+	a.startJob(jobs.Job{
+		Name:     "signing_key_reload",
+		Interval: cfg.OAuth2.KeyReloadInterval,
+		Run: func(ctx context.Context) (int64, error) {
+			return 0, a.loadSigningKeys(ctx)
+		},
+	})
+	a.startJob(a.cluster.Job())
+
+	// Apply email changes whose cooling-off window has ended
+	if cfg.Email.ChangeURL != "" && cfg.Email.ChangeCoolingOff > 0 {
+		a.startJob(jobs.Job{
+			Name:     "email_changes",
+			Interval: time.Minute,
+			Run: func(ctx context.Context) (int64, error) {
+				return a.Identity.ApplyEmailChanges(ctx, 100)
+			},
+		})
+	}
+
+	// Erase accounts whose deletion grace period has ended
+	if cfg.Security.AccountDeletionGrace > 0 {
+		a.startJob(jobs.Job{
+			Name:     "account_deletions",
+			Interval: time.Minute,
+			Run: func(ctx context.Context) (int64, error) {
+				return a.Identity.EraseDueAccounts(ctx, 100)
+			},
+		})
+	}
+
+	// Start the webhook dispatcher that sends queued deliveries and retries failed ones
+	if cfg.Webhook.Enabled {
+		dispatcher, err := webhook.NewDispatcher(a.Webhooks, webhook.DispatcherConfig{
+			PollInterval:   cfg.Webhook.PollInterval,
+			BatchSize:      cfg.Webhook.BatchSize,
+			Timeout:        cfg.Webhook.Timeout,
+			MaxAttempts:    cfg.Webhook.MaxAttempts,
+			InitialBackoff: cfg.Webhook.InitialBackoff,
+			MaxBackoff:     cfg.Webhook.MaxBackoff,
+		}, a.meter)
+		if err != nil {
+			return fmt.Errorf("failed to initialize webhook dispatcher: %w", err)
+		}
+		a.startJob(dispatcher.Job())
+	}
+	return nil
+}
+
+// startJob runs job until shutdown; a run in progress when Close is called is allowed to finish
+func (a *App) startJob(job jobs.Job) {
+	a.lifecycle.Go(job.Name, func(ctx context.Context, stop <-chan struct{}) error {
+		a.jobRunner.Run(ctx, stop, job)
+		return nil
+	})
+}
+
+// newLifecycle creates the manager of the background workers. Once they have stopped it delivers
+// queued and buffered audit events, which includes those the workers emitted, and closes the
+// event bus.
+func (a *App) newLifecycle(ctx context.Context) *lifecycle.Manager {
+	m := lifecycle.New(ctx)
+	m.OnStop("audit", func(ctx context.Context) error {
+		var err error
+		if a.auditQueue != nil {
+			err = a.auditQueue.Close(ctx)
+		}
+		a.closeAuditStreams(ctx)
+		return err
+	})
+	m.OnStop("bus", func(ctx context.Context) error {
+		if a.bus != nil {
+			a.bus.Close()
+		}
+		if r, ok := a.Replay.(*replay.Redis); ok {
+			r.Close()
+		}
+		return nil
+	})
+	return m
+}
+
+// Close stops the event bus and the background jobs, waits for runs in progress, then delivers
+// queued and buffered audit events and closes the event bus. ctx bounds the whole shutdown: jobs
+// still running when it is done are cancelled and the audit events left in the queue are dropped.
+func (a *App) Close(ctx context.Context) {
+	if a.lifecycle == nil {
+		a.lifecycle = a.newLifecycle(ctx)
+	}
+	if err := a.lifecycle.Shutdown(ctx); err != nil {
+		slog.Error("background shutdown error", logger.Error(err))
+	}
+}
+
+func (a *App) closeAuditStreams(ctx context.Context) {
+	for _, auditStream := range a.auditStreams {
+		if err := auditStream.Close(ctx); err != nil {
+			slog.Error("audit sink shutdown error", logger.Error(err))
+		}
+	}
+}
+
+// loadSigningKeys installs the active signing key and the published keys from the key store
+func (a *App) loadSigningKeys(ctx context.Context) error {
+	signing, published, err := a.keyManager.SigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	a.OIDC.SetSigningKeys(signing, published)
+	return nil
+}
+
+// oauth2Capabilities describes what the OAuth2 service accepts, for the discovery and
+// authorization server metadata
+func oauth2Capabilities(svc *oauth2.Service) oidc.Capabilities {
+	c := oidc.Capabilities{
+		GrantTypes:               oauth2.GrantTypes,
+		TokenEndpointAuthMethods: oauth2.TokenEndpointAuthMethods,
+		CodeChallengeMethods:     oauth2.CodeChallengeMethods,
+	}
+	if svc.RequestObjectsEnabled() {
+		c.RequestObjectSigningAlgs = oauth2.RequestObjectSigningAlgs
+	}
+	return c
+}
+
+// OpenStore connects to the database backend selected by DB_DRIVER, retrying as configured by
+// STARTUP_RETRY_TIMEOUT and STARTUP_WAIT_FOR_DEPS. Query metrics are recorded on meter; nil
+// records none.
+func OpenStore(ctx context.Context, cfg *config.Config, meter *metrics.Meter) (*store.Store, error) {
+	var st *store.Store
+	err := cfg.Startup.Retry().Do(ctx, "database", func(ctx context.Context) error {
+		var err error
+		st, err = store.Open(ctx, storeConfig(cfg, meter))
+		return err
+	})
+	return st, err
+}
+
+func storeConfig(cfg *config.Config, meter *metrics.Meter) store.Config {
+	return store.Config{
+		Driver: cfg.Database.Driver,
+		Postgres: postgres.Config{
+			Host:               cfg.Database.Host,
+			Port:               cfg.Database.Port,
+			User:               cfg.Database.User,
+			Password:           cfg.Database.Password,
+			Database:           cfg.Database.Database,
+			SSLMode:            cfg.Database.SSLMode,
+			MaxOpenConns:       cfg.Database.MaxOpenConns,
+			MaxIdleConns:       cfg.Database.MaxIdleConns,
+			ConnMaxLifetime:    cfg.Database.ConnMaxLifetime,
+			PasswordFunc:       databasePassword(cfg),
+			Compat:             cfg.Database.Compat,
+			Meter:              meter,
+			SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+			AcquireTimeout:     cfg.Database.PoolAcquireTimeout,
+			BreakerCooldown:    cfg.Database.PoolBreakerCooldown,
+		},
+		SQLite: sqlite.Config{
+			Path: cfg.Database.SQLitePath,
+		},
+	}
+}
+
+// databasePassword returns the source of the password of each new PostgreSQL connection: an IAM
+// authentication token with DB_AUTH, or DB_PASSWORD resolved again every DB_PASSWORD_REFRESH. It is
+// nil for a password resolved once at startup.
+func databasePassword(cfg *config.Config) func(ctx context.Context) (string, error) {
+	var source secrets.PasswordSource
+	switch db := cfg.Database; {
+	case db.Auth == config.DBAuthAWSIAM:
+		source = secrets.NewRDSIAMAuth(cfg.Secrets.AWS(), db.Host, db.Port, db.User)
+	case db.Auth == config.DBAuthGCPIAM:
+		source = secrets.NewCloudSQLIAMAuth(cfg.Secrets.GCP())
+	case db.PasswordRefresh > 0 && db.PasswordRef != "":
+		source = secrets.NewRefreshingSecret(cfg.Secrets.Resolver(), db.PasswordRef, db.PasswordRefresh)
+	default:
+		return nil
+	}
+	return source.Password
+}
+
+// OpenBus creates the cluster event bus: Redis pub/sub when BUS_REDIS_URL is set, otherwise the
+// transport the database offers, if any
+func OpenBus(cfg *config.Config, st *store.Store) (*bus.Bus, error) {
+	if cfg.Bus.RedisURL != "" {
+		transport, err := bus.NewRedis(cfg.Bus.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		return bus.New(transport), nil
+	}
+	return bus.New(st.BusTransport()), nil
+}
+
+// OpenReplay creates the store of one-time identifiers: Redis when REPLAY_REDIS_URL is set,
+// otherwise the database
+func OpenReplay(cfg *config.Config, st *store.Store) (replay.Store, error) {
+	if cfg.Replay.RedisURL != "" {
+		return replay.NewRedis(cfg.Replay.RedisURL)
+	}
+	return st.Replay, nil
+}
+
+// newAuditStream creates a buffered logger for one configured audit sink
+func newAuditStream(cfg *config.Config, sinkType string, meter *metrics.Meter) (*audit.SinkLogger, error) {
+	version := cfg.Observability.ServiceVersion
+	format := cfg.Audit.NATSFormat
+	if sinkType == sink.TypeKafka {
+		format = cfg.Audit.KafkaFormat
+	}
+	formatter, err := audit.NewFormatter(audit.Format(format), version)
+	if err != nil {
+		return nil, err
+	}
+
+	auditSink, err := sink.New(sink.Config{
+		Type:  sinkType,
+		Kafka: sink.KafkaConfig{Brokers: cfg.Audit.KafkaBrokers, Topic: cfg.Audit.KafkaTopic, Formatter: formatter},
+		NATS:  sink.NATSConfig{URL: cfg.Audit.NATSURL, Subject: cfg.Audit.NATSSubject, Formatter: formatter},
+	})
+	if err != nil {
+		return nil, err
+	}
+	auditStream, err := audit.NewSinkLogger(auditSink, audit.SinkConfig{
+		BufferSize:    cfg.Audit.SinkBufferSize,
+		BatchSize:     cfg.Audit.SinkBatchSize,
+		FlushInterval: cfg.Audit.SinkFlushInterval,
+		Overflow:      audit.OverflowPolicy(cfg.Audit.SinkOverflow),
+	}, meter)
+	if err != nil {
+		auditSink.Close()
+		return nil, err
+	}
+	slog.Info("streaming audit events", "sink", auditSink.Name(), "format", format)
+	return auditStream, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/opentrusty/opentrusty/internal/jobs"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TestPurpose: Validates that background jobs run as platform-wide work when the caller's context has no tenant scope.
+// Scope: Unit Test
+// Security: Tenant isolation (PostgreSQL row-level security shows an unscoped query no rows, so a job without a scope would silently skip email changes and account erasure)
+// Expected: A job started by Start from a context without a scope runs with tenant.AllTenants.
+// Test Case ID: JOB-05
+func TestApp_Start_JobsArePlatformWide(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Database.Driver = "memory"
+	cfg.Security.KeyEncryptionKey = "q8ZrT1vXbN4mK7pLw2sYc9dHf6gJa3eU"
+	cfg.Janitor.Enabled = false
+
+	meter, err := metrics.New(ctx, metrics.Config{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := OpenStore(ctx, cfg, meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	a, err := New(ctx, cfg, st, meter)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer a.Close(ctx)
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	scopes := make(chan string, 1)
+	a.startJob(jobs.Job{
+		Name:     "scope_probe",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) (int64, error) {
+			select {
+			case scopes <- tenant.ScopeFrom(ctx):
+			default:
+			}
+			return 0, nil
+		},
+	})
+	select {
+	case scope := <-scopes:
+		if scope != tenant.AllTenants {
+			t.Errorf("expected jobs to run platform-wide, got scope %q", scope)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job did not run")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apperr classifies domain errors so that transports can translate them without knowing
+// every package's sentinels.
+//
+// Domain packages declare their sentinels with New and a Code; callers may wrap them freely with
+// fmt.Errorf("...: %w", err) and still match them with errors.Is. Transports look the code up with
+// CodeOf and translate it with HTTPStatus or OAuthError. Error types that carry their own codes,
+// such as oauth2.Error, implement Coder to take part in the same translation.
+package apperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code is the category of a domain error
+type Code string
+
+// Error codes
+const (
+	CodeInvalidArgument    Code = "invalid_argument"    // The request is malformed or fails validation
+	CodeNotFound           Code = "not_found"           // The resource does not exist
+	CodeAlreadyExists      Code = "already_exists"      // A resource with the same identity exists
+	CodeConflict           Code = "conflict"            // The resource was modified concurrently
+	CodeUnauthenticated    Code = "unauthenticated"     // Credentials are missing or wrong
+	CodePermissionDenied   Code = "permission_denied"   // The caller may not perform the operation
+	CodeFailedPrecondition Code = "failed_precondition" // The resource is not in a state that allows the operation
+	CodeUnavailable        Code = "unavailable"         // A dependency is not configured or not reachable
+	CodeInternal           Code = "internal"            // Anything else; details must not reach the client
+)
+
+// Coder is implemented by errors that carry a Code
+type Coder interface {
+	error
+	ErrorCode() Code
+}
+
+// Error is a domain error with a code. Its message is safe to show to clients.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+// New creates a domain error; it is meant for package-level sentinels
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorCode returns the error's code
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+// CodeOf returns the code of the first error in err's tree that carries one, or CodeInternal
+func CodeOf(err error) Code {
+	var c Coder
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	return CodeInternal
+}
+
+// Message returns the client-safe message of the first coded error in err's tree, leaving out the
+// context that wrapping added. Internal errors return "internal server error".
+func Message(err error) string {
+	var c Coder
+	if !errors.As(err, &c) || c.ErrorCode() == CodeInternal {
+		return "internal server error"
+	}
+	if e, ok := c.(*Error); ok {
+		return e.Message
+	}
+	return c.Error()
+}
+
+// httpStatus translates codes to HTTP status codes
+var httpStatus = map[Code]int{
+	CodeInvalidArgument:    http.StatusBadRequest,
+	CodeNotFound:           http.StatusNotFound,
+	CodeAlreadyExists:      http.StatusConflict,
+	CodeConflict:           http.StatusConflict,
+	CodeUnauthenticated:    http.StatusUnauthorized,
+	CodePermissionDenied:   http.StatusForbidden,
+	CodeFailedPrecondition: http.StatusConflict,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+	CodeInternal:           http.StatusInternalServerError,
+}
+
+// HTTPStatus returns the HTTP status for err
+func HTTPStatus(err error) int {
+	if status, ok := httpStatus[CodeOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// oauthErrors translates codes to OAuth 2.0 error codes (RFC 6749 section 5.2)
+var oauthErrors = map[Code]string{
+	CodeInvalidArgument:    "invalid_request",
+	CodeNotFound:           "invalid_request",
+	CodeAlreadyExists:      "invalid_request",
+	CodeConflict:           "invalid_request",
+	CodeUnauthenticated:    "invalid_client",
+	CodePermissionDenied:   "unauthorized_client",
+	CodeFailedPrecondition: "invalid_grant",
+	CodeUnavailable:        "temporarily_unavailable",
+	CodeInternal:           "server_error",
+}
+
+// OAuthError returns the OAuth 2.0 error code for err
+func OAuthError(err error) string {
+	if code, ok := oauthErrors[CodeOf(err)]; ok {
+		return code
+	}
+	return "server_error"
+}
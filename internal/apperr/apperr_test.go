@@ -0,0 +1,84 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apperr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// TestPurpose: Validates that domain errors keep their code and client-safe message through wrapping and translate consistently.
+// Scope: Unit Test
+// Security: Information disclosure (CWE-209); internal error details must not reach clients
+// Expected: Wrapped sentinels still match errors.Is and map to their HTTP status and OAuth error; protocol errors keep their own code; errors without a code become opaque 500 / server_error.
+// Test Case ID: ERR-01
+func TestTranslation(t *testing.T) {
+	wrapped := fmt.Errorf("failed to load user %s: %w", "u1", identity.ErrUserNotFound)
+	internal := errors.New("connection refused by 10.0.0.5:5432")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   apperr.Code
+		wantStatus int
+		wantOAuth  string
+		wantMsg    string
+	}{
+		{"wrapped sentinel", wrapped, apperr.CodeNotFound, http.StatusNotFound, "invalid_request", "user not found"},
+		{"conflict", fmt.Errorf("update: %w", oauth2.ErrClientVersionConflict), apperr.CodeConflict, http.StatusConflict, "invalid_request", "client was modified concurrently"},
+		{"credentials", identity.ErrInvalidCredentials, apperr.CodeUnauthenticated, http.StatusUnauthorized, "invalid_client", "invalid credentials"},
+		{"expired code", oauth2.ErrCodeExpired, apperr.CodeFailedPrecondition, http.StatusConflict, "invalid_grant", "authorization code expired"},
+		{"protocol error", fmt.Errorf("token: %w", oauth2.NewError(oauth2.ErrInvalidClient, "client is disabled")), apperr.CodeUnauthenticated, http.StatusUnauthorized, "invalid_client", ""},
+		{"uncoded", internal, apperr.CodeInternal, http.StatusInternalServerError, "server_error", "internal server error"},
+		{"nil", nil, apperr.CodeInternal, http.StatusInternalServerError, "server_error", "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apperr.CodeOf(tt.err); got != tt.wantCode {
+				t.Errorf("CodeOf = %s, want %s", got, tt.wantCode)
+			}
+			if got := apperr.HTTPStatus(tt.err); got != tt.wantStatus {
+				t.Errorf("HTTPStatus = %d, want %d", got, tt.wantStatus)
+			}
+			if got := apperr.OAuthError(tt.err); got != tt.wantOAuth {
+				t.Errorf("OAuthError = %s, want %s", got, tt.wantOAuth)
+			}
+			if tt.wantMsg != "" {
+				if got := apperr.Message(tt.err); got != tt.wantMsg {
+					t.Errorf("Message = %q, want %q", got, tt.wantMsg)
+				}
+			}
+		})
+	}
+
+	if !errors.Is(wrapped, identity.ErrUserNotFound) {
+		t.Error("wrapped sentinel no longer matches errors.Is")
+	}
+
+	oauthErr := oauth2.AsError(fmt.Errorf("exchange: %w", oauth2.ErrCodeAlreadyUsed))
+	if oauthErr.Code != oauth2.ErrInvalidGrant || oauthErr.Description != "authorization code already used" {
+		t.Errorf("unexpected translation of a domain error: %+v", oauthErr)
+	}
+	oauthErr = oauth2.AsError(internal)
+	if oauthErr.Code != oauth2.ErrServerError || oauthErr.Description != "internal server error" {
+		t.Errorf("internal details leaked into the protocol error: %+v", oauthErr)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archive implements audit.Archiver on S3-compatible object storage.
+// Google Cloud Storage is supported through its S3-interoperable XML API
+// (endpoint storage.googleapis.com with HMAC keys).
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// platformPrefix groups events that do not belong to a tenant
+const platformPrefix = "platform"
+
+// Config configures the object storage archive
+type Config struct {
+	Endpoint string // e.g. s3.amazonaws.com or storage.googleapis.com
+	Region   string
+	Bucket   string
+	Prefix   string
+	// AccessKey and SecretKey are static credentials. When empty, credentials are taken
+	// from the AWS environment variables, shared credentials file or instance role.
+	AccessKey string
+	SecretKey string
+}
+
+// S3 archives audit events as gzipped JSON lines, one object per tenant and batch
+type S3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3 creates an archive client
+func NewS3(cfg Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("audit archive requires an endpoint and a bucket")
+	}
+
+	creds := credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, "")
+	if cfg.AccessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		})
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: true,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit archive client: %w", err)
+	}
+	return &S3{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+// Archive uploads the events. It returns only after every object is stored.
+func (a *S3) Archive(ctx context.Context, events []*audit.Event) error {
+	for key, group := range a.objects(events) {
+		body, err := encode(group)
+		if err != nil {
+			return err
+		}
+		_, err = a.client.PutObject(ctx, a.bucket, key, bytes.NewReader(body), int64(len(body)), minio.PutObjectOptions{
+			ContentType:     "application/x-ndjson",
+			ContentEncoding: "gzip",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// objects groups events by tenant and names each group's object
+// "<prefix>/<tenant>/<yyyy>/<mm>/<dd>/<first event ID>.jsonl.gz"
+func (a *S3) objects(events []*audit.Event) map[string][]*audit.Event {
+	byTenant := make(map[string][]*audit.Event)
+	for _, e := range events {
+		byTenant[e.TenantID] = append(byTenant[e.TenantID], e)
+	}
+
+	objects := make(map[string][]*audit.Event, len(byTenant))
+	for tenantID, group := range byTenant {
+		if tenantID == "" {
+			tenantID = platformPrefix
+		}
+		first := group[0]
+		key := path.Join(a.prefix, tenantID, first.Timestamp.UTC().Format("2006/01/02"), first.ID+".jsonl.gz")
+		objects[key] = group
+	}
+	return objects
+}
+
+// encode writes events as gzipped JSON lines
+func encode(events []*audit.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	var f audit.JSONFormatter
+	for _, e := range events {
+		line, err := f.Format(*e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit event %s: %w", e.ID, err)
+		}
+		zw.Write(line)
+		zw.Write([]byte("\n"))
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// AsyncConfig controls the queue in front of an AsyncLogger
+type AsyncConfig struct {
+	QueueSize int
+	Overflow  OverflowPolicy
+}
+
+// AsyncLogger implements Logger by handing events to a background worker that logs
+// them with the wrapped logger, so slow storage or sinks do not add latency to the
+// audited request. When the queue is full the overflow policy either blocks the
+// caller (the default, so no event is lost) or drops events and counts them.
+type AsyncLogger struct {
+	next  Logger
+	cfg   AsyncConfig
+	queue chan queuedEvent
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	depth   metric.Int64UpDownCounter
+	dropped metric.Int64Counter
+}
+
+// queuedEvent keeps the caller's context values (request details, trace) with the event
+type queuedEvent struct {
+	ctx   context.Context
+	event Event
+}
+
+// NewAsyncLogger creates an asynchronous wrapper around next and starts its worker
+func NewAsyncLogger(next Logger, cfg AsyncConfig, meter *metrics.Meter) (*AsyncLogger, error) {
+	switch cfg.Overflow {
+	case "":
+		cfg.Overflow = OverflowBlock
+	case OverflowDropNewest, OverflowDropOldest, OverflowBlock:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOverflowPolicy, cfg.Overflow)
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+
+	depth, err := meter.CreateUpDownCounter("audit.queue.depth", "Audit events waiting to be logged")
+	if err != nil {
+		return nil, err
+	}
+	dropped, err := meter.CreateCounter("audit.queue.dropped", "Audit events dropped because the queue was full or closed")
+	if err != nil {
+		return nil, err
+	}
+
+	l := &AsyncLogger{
+		next:    next,
+		cfg:     cfg,
+		queue:   make(chan queuedEvent, cfg.QueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		depth:   depth,
+		dropped: dropped,
+	}
+	go l.run()
+	return l, nil
+}
+
+// Log queues an event. The ID and timestamp are assigned now so they reflect
+// when the action happened rather than when the event was dequeued.
+func (l *AsyncLogger) Log(ctx context.Context, event Event) {
+	select {
+	case <-l.stop:
+		l.drop(ctx, "closed")
+		return
+	default:
+	}
+
+	if event.ID == "" {
+		event.ID = id.NewUUIDv7()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	// The request context is canceled once the response is written; the worker only needs its values
+	item := queuedEvent{ctx: context.WithoutCancel(ctx), event: event}
+
+	switch l.cfg.Overflow {
+	case OverflowDropNewest:
+		select {
+		case l.queue <- item:
+			l.depth.Add(ctx, 1)
+		default:
+			l.drop(ctx, "overflow")
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case l.queue <- item:
+				l.depth.Add(ctx, 1)
+				return
+			default:
+			}
+			select {
+			case <-l.queue:
+				l.depth.Add(ctx, -1)
+				l.drop(ctx, "overflow")
+			default:
+			}
+		}
+	default:
+		select {
+		case l.queue <- item:
+			l.depth.Add(ctx, 1)
+		case <-l.stop:
+			l.drop(ctx, "closed")
+		case <-ctx.Done():
+			l.drop(ctx, "overflow")
+		}
+	}
+}
+
+// Close stops accepting events and waits until the queued ones are logged.
+// It returns ctx.Err() if the queue is not flushed before ctx is done.
+func (l *AsyncLogger) Close(ctx context.Context) error {
+	l.once.Do(func() { close(l.stop) })
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run logs queued events until Close is called, then flushes the queue
+func (l *AsyncLogger) run() {
+	defer close(l.done)
+	for {
+		select {
+		case item := <-l.queue:
+			l.log(item)
+		case <-l.stop:
+			for {
+				select {
+				case item := <-l.queue:
+					l.log(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (l *AsyncLogger) log(item queuedEvent) {
+	l.depth.Add(item.ctx, -1)
+	l.next.Log(item.ctx, item.event)
+}
+
+// drop records an event that will never be logged
+func (l *AsyncLogger) drop(ctx context.Context, reason string) {
+	l.dropped.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+)
+
+// gatedLogger records events; while gate is non-nil it signals started and waits for the gate on each event
+type gatedLogger struct {
+	mu       sync.Mutex
+	events   []Event
+	requests []string
+	started  chan struct{}
+	gate     chan struct{}
+}
+
+func (l *gatedLogger) Log(ctx context.Context, event Event) {
+	if l.gate != nil {
+		l.started <- struct{}{}
+		<-l.gate
+	}
+	req, _ := requestFrom(ctx)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ctx.Err() != nil {
+		req.ID = "canceled"
+	}
+	l.events = append(l.events, event)
+	l.requests = append(l.requests, req.ID)
+}
+
+func (l *gatedLogger) types() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var types []string
+	for _, e := range l.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func newTestAsyncLogger(t *testing.T, next Logger, cfg AsyncConfig) *AsyncLogger {
+	t.Helper()
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewAsyncLogger(next, cfg, meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// TestPurpose: Validates the asynchronous audit queue: overflow policies, request context and flush on close.
+// Scope: Unit Test
+// Security: Audit events must not be lost silently, and a slow store must not stall requests unless configured to
+// Expected: drop_newest drops when full; block waits for room; queued events keep their request details and are flushed by Close.
+// Test Case ID: AUD-10
+func TestAudit_AsyncLogger(t *testing.T) {
+	t.Run("drop newest", func(t *testing.T) {
+		next := &gatedLogger{started: make(chan struct{}, 3), gate: make(chan struct{})}
+		l := newTestAsyncLogger(t, next, AsyncConfig{QueueSize: 1, Overflow: OverflowDropNewest})
+		ctx := context.Background()
+
+		l.Log(ctx, Event{Type: "1"})
+		<-next.started // The worker holds "1"
+		l.Log(ctx, Event{Type: "2"})
+		l.Log(ctx, Event{Type: "3"}) // Queue is full
+
+		close(next.gate)
+		if err := l.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := next.types(); len(got) != 2 || got[0] != "1" || got[1] != "2" {
+			t.Errorf("expected [1 2], got %v", got)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		next := &gatedLogger{started: make(chan struct{}, 3), gate: make(chan struct{})}
+		l := newTestAsyncLogger(t, next, AsyncConfig{QueueSize: 1})
+		ctx := context.Background()
+
+		l.Log(ctx, Event{Type: "1"})
+		<-next.started
+		l.Log(ctx, Event{Type: "2"})
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		l.Log(canceled, Event{Type: "dropped"}) // A caller that gives up is not blocked
+
+		logged := make(chan struct{})
+		go func() {
+			l.Log(ctx, Event{Type: "3"})
+			close(logged)
+		}()
+		select {
+		case <-logged:
+			t.Fatal("expected Log to block while the queue is full")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(next.gate)
+		<-logged
+		if err := l.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := next.types(); len(got) != 3 || got[2] != "3" {
+			t.Errorf("expected [1 2 3], got %v", got)
+		}
+	})
+
+	t.Run("request context and close", func(t *testing.T) {
+		next := &gatedLogger{}
+		l := newTestAsyncLogger(t, next, AsyncConfig{})
+
+		ctx, cancel := context.WithCancel(WithRequest(context.Background(), Request{ID: "req-1"}))
+		l.Log(ctx, Event{Type: TypeLogout})
+		cancel() // The request finishes before the event is logged
+		if err := l.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		l.Log(context.Background(), Event{Type: "after close"})
+
+		if len(next.events) != 1 {
+			t.Fatalf("expected one event, got %v", next.types())
+		}
+		if next.requests[0] != "req-1" {
+			t.Errorf("expected the request details to survive cancellation, got %q", next.requests[0])
+		}
+		if next.events[0].ID == "" || next.events[0].Timestamp.IsZero() {
+			t.Errorf("expected ID and timestamp to be assigned when logged, got %+v", next.events[0])
+		}
+	})
+}
@@ -23,73 +23,137 @@ import (
 
 // Event types
 const (
-	TypeLoginSuccess           = "login_success"
-	TypeLoginFailed            = "login_failed"
-	TypeTokenIssued            = "token_issued"
-	TypeTokenRevoked           = "token_revoked"
-	TypeRoleAssigned           = "role_assigned"
-	TypeRoleRevoked            = "role_revoked"
-	TypeClientCreated          = "client_created"
-	TypeSecretRotated          = "secret_rotated"
-	TypeUserLocked             = "user_locked"
-	TypeUserUnlocked           = "user_unlocked"
-	TypeUserCreated            = "user_created"
-	TypePasswordChanged        = "password_changed"
-	TypeLogout                 = "logout"
-	TypePlatformAdminBootstrap = "platform_admin_bootstrap"
-	TypeTenantCreated          = "tenant_created"
+	TypeLoginSuccess             = "login_success"
+	TypeLoginFailed              = "login_failed"
+	TypeTokenIssued              = "token_issued"
+	TypeTokenRevoked             = "token_revoked"
+	TypeTokensRevoked            = "tokens_revoked"
+	TypeRoleAssigned             = "role_assigned"
+	TypeRoleRevoked              = "role_revoked"
+	TypeClientCreated            = "client_created"
+	TypeClientDeleted            = "client_deleted"
+	TypeSecretRotated            = "secret_rotated"
+	TypeClientKeysUpdated        = "client_keys_updated"
+	TypeUserLocked               = "user_locked"
+	TypeUserUnlocked             = "user_unlocked"
+	TypeUserCreated              = "user_created"
+	TypePasswordChanged          = "password_changed"
+	TypePasswordExpired          = "password_expired"
+	TypePasswordReset            = "password_reset"
+	TypeLogout                   = "logout"
+	TypeSessionRevoked           = "session_revoked"
+	TypeSessionsRevoked          = "sessions_revoked"
+	TypeSessionRenewalUnusual    = "session_renewal_unusual"
+	TypeProfileUpdated           = "profile_updated"
+	TypeEmailChangeRequested     = "email_change_requested"
+	TypeEmailChangeVerified      = "email_change_verified"
+	TypeEmailChangeReverted      = "email_change_reverted"
+	TypeEmailChangeCancelled     = "email_change_cancelled"
+	TypeEmailChanged             = "email_changed"
+	TypeAccountDeletionRequested = "account_deletion_requested"
+	TypeAccountDeletionCancelled = "account_deletion_cancelled"
+	TypeAccountErased            = "account_erased"
+	TypePlatformAdminBootstrap   = "platform_admin_bootstrap"
+	TypeTenantCreated            = "tenant_created"
+	TypeMemberAdded              = "member_added"
+	TypeMemberRemoved            = "member_removed"
+	TypeProjectCreated           = "project_created"
+	TypeProjectUpdated           = "project_updated"
+	TypeProjectDeleted           = "project_deleted"
+	TypeEmailSettingsUpdated     = "email_settings_updated"
+	TypeEmailSettingsDeleted     = "email_settings_deleted"
+	TypeEmailTestSent            = "email_test_sent"
+	TypeAuditRetentionUpdated    = "audit_retention_updated"
+	TypeAuditRetentionDeleted    = "audit_retention_deleted"
+	TypeWebhookCreated           = "webhook_created"
+	TypeWebhookUpdated           = "webhook_updated"
+	TypeWebhookDeleted           = "webhook_deleted"
+	TypeWebhookRedelivered       = "webhook_redelivered"
+	TypeScopeCreated             = "scope_created"
+	TypeScopeUpdated             = "scope_updated"
+	TypeScopeDeleted             = "scope_deleted"
+	TypeProtocolLogUpdated       = "protocol_log_updated"
+	TypeSecurityPolicyUpdated    = "security_policy_updated"
+	TypeSecurityPolicyDeleted    = "security_policy_deleted"
+	TypeIPBlocked                = "ip_blocked"
+	TypeClientAuthFailed         = "client_auth_failed"
+	TypeClientAuthBanned         = "client_auth_banned"
+	TypeSigningKeyGenerated      = "signing_key_generated"
+	TypeSigningKeyRotated        = "signing_key_rotated"
+	TypeSigningKeyRetired        = "signing_key_retired"
 )
 
 // Standard audit attribute keys
 const (
-	AttrAuditType = "audit_type"
-	AttrTenantID  = "tenant_id"
-	AttrActorID   = "actor_id"
-	AttrResource  = "resource"
-	AttrTimestamp = "timestamp"
-	AttrIPAddress = "ip_address"
-	AttrUserAgent = "user_agent"
-	AttrComponent = "component"
-	AttrMetadata  = "metadata"
+	AttrAuditType     = "audit_type"
+	AttrSchemaVersion = "schema_version"
+	AttrTenantID      = "tenant_id"
+	AttrActorID       = "actor_id"
+	AttrResource      = "resource"
+	AttrTimestamp     = "timestamp"
+	AttrRequestID     = "request_id"
+	AttrIPAddress     = "ip_address"
+	AttrUserAgent     = "user_agent"
+	AttrComponent     = "component"
+	AttrMetadata      = "metadata"
 )
 
 // Common Resource Types
 const (
 	ResourcePlatform        = "platform"
 	ResourceTenant          = "tenant"
+	ResourceMembership      = "membership"
+	ResourceProject         = "project"
 	ResourceUser            = "user"
 	ResourceRole            = "role"
 	ResourceClient          = "client"
 	ResourceSession         = "session"
 	ResourceUserCredentials = "user_credentials"
 	ResourceToken           = "token"
+	ResourceEmailSettings   = "email_settings"
+	ResourceAuditRetention  = "audit_retention"
+	ResourceWebhook         = "webhook"
+	ResourceScope           = "scope"
+	ResourceProtocolLog     = "protocol_log"
+	ResourceSecurityPolicy  = "security_policy"
+	ResourceAdminPlane      = "admin_plane"
+	ResourceSigningKey      = "signing_key"
 )
 
 // Standard Actor IDs
 const (
 	ActorSystemBootstrap = ""
+	ActorCLI             = "cli" // Operator commands run with the opentrusty CLI
 )
 
 // Common Metadata Keys
 const (
-	AttrEmail      = "email"
-	AttrRoleID     = "role_id"
-	AttrReason     = "reason"
-	AttrAttempts   = "attempts"
-	AttrSessionID  = "session_id"
-	AttrTenantName = "tenant_name"
+	AttrEmail         = "email"
+	AttrRoleID        = "role_id"
+	AttrReason        = "reason"
+	AttrAttempts      = "attempts"
+	AttrSessionID     = "session_id"
+	AttrTenantName    = "tenant_name"
+	AttrRetentionDays = "retention_days"
 )
 
-// Event represents an auditable action
+// Event represents an auditable action.
+// Callers set Payload to the typed body for the event type; it is validated and encoded
+// into Metadata when the event is logged. Setting Metadata directly is still accepted
+// for compatibility and produces a legacy (schema version 0) event.
 type Event struct {
-	Type      string
-	TenantID  string
-	ActorID   string
-	Resource  string
-	Metadata  map[string]any
-	Timestamp time.Time
-	IPAddress string
-	UserAgent string
+	ID            string         `json:"id"` // Assigned when the event is stored
+	SchemaVersion int            `json:"schema_version"`
+	Type          string         `json:"type"`
+	TenantID      string         `json:"tenant_id"`
+	ActorID       string         `json:"actor_id"`
+	Resource      string         `json:"resource"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+	Timestamp     time.Time      `json:"timestamp"`
+	RequestID     string         `json:"request_id,omitempty"`
+	IPAddress     string         `json:"ip_address,omitempty"`
+	UserAgent     string         `json:"user_agent,omitempty"`
+	Payload       Payload        `json:"-"`
 }
 
 // Logger defines the interface for audit logging
@@ -107,20 +171,21 @@ func NewSlogLogger() *SlogLogger {
 
 // Log records an audit event
 func (l *SlogLogger) Log(ctx context.Context, event Event) {
-	// Ensure timestamp is set
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
-	}
+	event = prepare(ctx, event)
 
 	// Prepare attributes
 	attrs := []any{
 		slog.String(AttrAuditType, event.Type),
+		slog.Int(AttrSchemaVersion, event.SchemaVersion),
 		slog.String(AttrTenantID, event.TenantID),
 		slog.String(AttrActorID, event.ActorID),
 		slog.String(AttrResource, event.Resource),
 		slog.Time(AttrTimestamp, event.Timestamp),
 	}
 
+	if event.RequestID != "" {
+		attrs = append(attrs, slog.String(AttrRequestID, event.RequestID))
+	}
 	if event.IPAddress != "" {
 		attrs = append(attrs, slog.String(AttrIPAddress, event.IPAddress))
 	}
@@ -128,14 +193,10 @@ func (l *SlogLogger) Log(ctx context.Context, event Event) {
 		attrs = append(attrs, slog.String(AttrUserAgent, event.UserAgent))
 	}
 
-	// Flatten metadata
+	// Flatten metadata; secrets were redacted by prepare
 	if len(event.Metadata) > 0 {
 		group := []any{}
 		for k, v := range event.Metadata {
-			// Redact secrets
-			if isSecret(k) {
-				v = "[REDACTED]"
-			}
 			group = append(group, slog.Any(k, v))
 		}
 		attrs = append(attrs, slog.Group(AttrMetadata, group...))
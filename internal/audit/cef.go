@@ -0,0 +1,95 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CEF severities (0-10) for the shared severity levels
+var cefSeverity = map[int]int{
+	severityInformational: 3,
+	severityMedium:        6,
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// CEFFormatter encodes events as single-line CEF records:
+//
+//	CEF:0|OpenTrusty|OpenTrusty|<version>|<event type>|<name>|<severity>|<extension>
+//
+// Tenant, resource, metadata and request ID are carried in the custom string fields cs1 to cs4
+// and the payload schema version in the custom number field cn1.
+type CEFFormatter struct {
+	Version string
+}
+
+// Format encodes the event
+func (f CEFFormatter) Format(e Event) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(ProductVendor),
+		cefHeaderEscaper.Replace(ProductName),
+		cefHeaderEscaper.Replace(f.Version),
+		cefHeaderEscaper.Replace(e.Type),
+		cefHeaderEscaper.Replace(displayName(e.Type)),
+		cefSeverity[severity(e.Type)],
+	)
+
+	outcome := "success"
+	if failed(e.Type) {
+		outcome = "failure"
+	}
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtensionEscaper.Replace(value))
+		}
+	}
+	addCustom := func(n int, label, value string) {
+		if value != "" {
+			add(fmt.Sprintf("cs%dLabel", n), label)
+			add(fmt.Sprintf("cs%d", n), value)
+		}
+	}
+
+	add("rt", strconv.FormatInt(e.Timestamp.UnixMilli(), 10))
+	add("externalId", e.ID)
+	add("outcome", outcome)
+	add("suser", e.ActorID)
+	add("src", e.IPAddress)
+	add("requestClientApplication", e.UserAgent)
+	add("cn1Label", "schemaVersion")
+	add("cn1", strconv.Itoa(e.SchemaVersion))
+	addCustom(1, "tenantId", e.TenantID)
+	addCustom(2, "resource", e.Resource)
+	if len(e.Metadata) > 0 {
+		metadata, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		addCustom(3, "metadata", string(metadata))
+	}
+	addCustom(4, "requestId", e.RequestID)
+
+	b.WriteString(strings.Join(ext, " "))
+	return []byte(b.String()), nil
+}
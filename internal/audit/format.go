@@ -0,0 +1,99 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// Format names an encoding for exported audit events
+type Format string
+
+// Export formats
+const (
+	// FormatJSON is the native event structure
+	FormatJSON Format = "json"
+	// FormatOCSF is the Open Cybersecurity Schema Framework, ingested natively by Amazon Security Lake and Splunk
+	FormatOCSF Format = "ocsf"
+	// FormatCEF is ArcSight Common Event Format, ingested natively by Microsoft Sentinel and most SIEMs
+	FormatCEF Format = "cef"
+)
+
+// ErrInvalidFormat is returned for an unknown export format
+var ErrInvalidFormat = apperr.New(apperr.CodeInvalidArgument, "invalid audit export format")
+
+// Product identifies this service in exported events
+const (
+	ProductVendor = "OpenTrusty"
+	ProductName   = "OpenTrusty"
+)
+
+// Formatter encodes an event for an external consumer
+type Formatter interface {
+	Format(event Event) ([]byte, error)
+}
+
+// NewFormatter returns the formatter for format.
+// version is the product version reported in OCSF metadata and the CEF header.
+func NewFormatter(format Format, version string) (Formatter, error) {
+	switch format {
+	case "", FormatJSON:
+		return JSONFormatter{}, nil
+	case FormatOCSF:
+		return OCSFFormatter{Version: version}, nil
+	case FormatCEF:
+		return CEFFormatter{Version: version}, nil
+	default:
+		return nil, ErrInvalidFormat
+	}
+}
+
+// JSONFormatter encodes events as JSON
+type JSONFormatter struct{}
+
+// Format encodes the event
+func (JSONFormatter) Format(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Severity levels shared by the OCSF and CEF mappings
+const (
+	severityInformational = iota
+	severityMedium
+)
+
+// severity rates how urgently a SOC should look at an event type
+func severity(eventType string) int {
+	switch eventType {
+	case TypeLoginFailed, TypeUserLocked, TypePlatformAdminBootstrap, TypeIPBlocked, TypeClientAuthFailed, TypeClientAuthBanned,
+		TypeSessionRenewalUnusual:
+		return severityMedium
+	default:
+		return severityInformational
+	}
+}
+
+// failed reports whether the event records a failed attempt
+func failed(eventType string) bool {
+	return eventType == TypeLoginFailed || eventType == TypeIPBlocked || eventType == TypeClientAuthFailed
+}
+
+// displayName turns an event type into a human-readable name, e.g. "login failed"
+func displayName(eventType string) string {
+	return strings.ReplaceAll(eventType, "_", " ")
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPurpose: Validates the mapping of audit events onto OCSF classes and fields.
+// Scope: Unit Test
+// Security: SIEM correlation rules depend on correct class, status and actor fields
+// Expected: A failed login is an Authentication Logon with failure status; unmapped types fall back to base events.
+// Test Case ID: AUD-05
+func TestAudit_OCSFFormatter(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f, err := NewFormatter(FormatOCSF, "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := f.Format(Event{
+		ID: "e1", Type: TypeLoginFailed, TenantID: "t1", ActorID: "u1", Resource: ResourceUser,
+		RequestID: "req-1", IPAddress: "192.0.2.1", Timestamp: ts, Metadata: map[string]any{AttrReason: "invalid_credentials"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"class_uid":   float64(ocsfClassAuthentication),
+		"activity_id": float64(1),
+		"type_uid":    float64(300201),
+		"status_id":   float64(ocsfStatusFailure),
+		"severity_id": float64(ocsfSeverityMedium),
+		"time":        float64(ts.UnixMilli()),
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, got[k])
+		}
+	}
+	metadata := got["metadata"].(map[string]any)
+	if metadata["uid"] != "e1" || metadata["tenant_uid"] != "t1" || metadata["correlation_uid"] != "req-1" {
+		t.Errorf("unexpected metadata: %v", metadata)
+	}
+	if actor := got["actor"].(map[string]any)["user"].(map[string]any); actor["uid"] != "u1" {
+		t.Errorf("unexpected actor: %v", actor)
+	}
+	if src := got["src_endpoint"].(map[string]any); src["ip"] != "192.0.2.1" {
+		t.Errorf("unexpected src_endpoint: %v", src)
+	}
+
+	b, err = f.Format(Event{ID: "e2", Type: TypeEmailTestSent, Timestamp: ts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["class_uid"] != float64(ocsfClassBase) || got["activity_id"] != float64(ocsfActivityOther) {
+		t.Errorf("expected base event for unmapped type, got class %v activity %v", got["class_uid"], got["activity_id"])
+	}
+	if _, ok := got["actor"]; ok {
+		t.Error("expected no actor for system event")
+	}
+
+	if _, err := NewFormatter("leef", ""); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+// TestPurpose: Validates CEF record layout and escaping of attacker-controlled values.
+// Scope: Unit Test
+// Security: Log injection prevention (CWE-117); values must not forge extra header or extension fields
+// Expected: The header has seven pipe-separated fields; pipes, equals signs and newlines in values are escaped.
+// Test Case ID: AUD-06
+func TestAudit_CEFFormatter(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f, err := NewFormatter(FormatCEF, "1.0|beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := f.Format(Event{
+		ID: "e1", Type: TypeLoginFailed, TenantID: "t1", Resource: ResourceUser,
+		RequestID: "req-1", UserAgent: "curl/8.0\nsuser=admin", Timestamp: ts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := string(b)
+
+	wantHeader := `CEF:0|OpenTrusty|OpenTrusty|1.0\|beta|login_failed|login failed|6|`
+	if !strings.HasPrefix(line, wantHeader) {
+		t.Fatalf("expected header %q, got %q", wantHeader, line)
+	}
+	if strings.Contains(line, "\n") {
+		t.Error("expected a single line")
+	}
+	for _, want := range []string{
+		"rt=1767323045000",
+		"externalId=e1",
+		"outcome=failure",
+		`requestClientApplication=curl/8.0\nsuser\=admin`,
+		"cs1Label=tenantId cs1=t1",
+		"cs2Label=resource cs2=user",
+		"cs4Label=requestId cs4=req-1",
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %q", want, line)
+		}
+	}
+	if strings.Contains(line, " suser=") || strings.Contains(line, "cs3") {
+		t.Errorf("expected empty fields to be omitted, got %q", line)
+	}
+}
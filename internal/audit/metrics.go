@@ -0,0 +1,162 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MetricsConfig bounds the cardinality of the tenant and client labels on business
+// metrics. The first MaxTenants (MaxClients) distinct values get their own series and
+// later ones are reported as "other"; zero drops the label altogether.
+type MetricsConfig struct {
+	MaxTenants int
+	MaxClients int
+}
+
+// MetricsLogger implements Logger by counting authentication and token events as
+// business metrics. It is added next to the other loggers, so every audited login,
+// lockout, client authentication failure, issuance and revocation is counted exactly once.
+type MetricsLogger struct {
+	tenants *labelSet
+	clients *labelSet
+
+	logins         metric.Int64Counter
+	lockouts       metric.Int64Counter
+	clientFailures metric.Int64Counter
+	clientBans     metric.Int64Counter
+	issued         metric.Int64Counter
+	revoked        metric.Int64Counter
+}
+
+// NewMetricsLogger creates the business metric instruments
+func NewMetricsLogger(cfg MetricsConfig, meter *metrics.Meter) (*MetricsLogger, error) {
+	logins, err := meter.CreateCounter("auth.logins", "Login attempts by outcome and failure reason")
+	if err != nil {
+		return nil, err
+	}
+	lockouts, err := meter.CreateCounter("auth.lockouts", "Accounts locked after repeated login failures")
+	if err != nil {
+		return nil, err
+	}
+	clientFailures, err := meter.CreateCounter("oauth2.client_auth.failures", "Failed OAuth2 client authentications by client")
+	if err != nil {
+		return nil, err
+	}
+	clientBans, err := meter.CreateCounter("oauth2.client_auth.bans", "Temporary bans after repeated failed client authentications, by banned key")
+	if err != nil {
+		return nil, err
+	}
+	issued, err := meter.CreateCounter("oauth2.tokens.issued", "Token responses by grant type and client")
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := meter.CreateCounter("oauth2.tokens.revoked", "Refresh tokens revoked by their client")
+	if err != nil {
+		return nil, err
+	}
+	return &MetricsLogger{
+		tenants:        newLabelSet("tenant_id", cfg.MaxTenants),
+		clients:        newLabelSet("client_id", cfg.MaxClients),
+		logins:         logins,
+		lockouts:       lockouts,
+		clientFailures: clientFailures,
+		clientBans:     clientBans,
+		issued:         issued,
+		revoked:        revoked,
+	}, nil
+}
+
+// Log counts the event if it is one of the measured types
+func (l *MetricsLogger) Log(ctx context.Context, event Event) {
+	attrs := l.tenants.attrs(nil, event.TenantID)
+	switch event.Type {
+	case TypeLoginSuccess:
+		attrs = append(attrs, attribute.String("outcome", "success"))
+		l.logins.Add(ctx, 1, metric.WithAttributes(attrs...))
+	case TypeLoginFailed:
+		attrs = append(attrs, attribute.String("outcome", "failure"))
+		if p, ok := event.Payload.(*LoginFailedPayload); ok {
+			attrs = append(attrs, attribute.String("reason", p.Reason))
+		}
+		l.logins.Add(ctx, 1, metric.WithAttributes(attrs...))
+	case TypeUserLocked:
+		l.lockouts.Add(ctx, 1, metric.WithAttributes(attrs...))
+	case TypeClientAuthFailed:
+		if p, ok := event.Payload.(*ClientAuthFailedPayload); ok {
+			attrs = l.clients.attrs(attrs, p.ClientID)
+		}
+		l.clientFailures.Add(ctx, 1, metric.WithAttributes(attrs...))
+	case TypeClientAuthBanned:
+		if p, ok := event.Payload.(*ClientAuthBannedPayload); ok {
+			attrs = append(attrs, attribute.String("key", p.Key))
+		}
+		l.clientBans.Add(ctx, 1, metric.WithAttributes(attrs...))
+	case TypeTokenIssued:
+		if p, ok := event.Payload.(*TokenPayload); ok {
+			attrs = l.clients.attrs(attrs, p.ClientID)
+			attrs = append(attrs, attribute.String("grant_type", p.GrantType))
+		}
+		l.issued.Add(ctx, 1, metric.WithAttributes(attrs...))
+	case TypeTokenRevoked:
+		if p, ok := event.Payload.(*TokenPayload); ok {
+			attrs = l.clients.attrs(attrs, p.ClientID)
+		}
+		l.revoked.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+}
+
+// labelSet admits the first max distinct values of a label and folds the rest into
+// "other", so a flood of tenants or clients cannot grow the number of series unbounded
+type labelSet struct {
+	key string
+	max int
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newLabelSet(key string, max int) *labelSet {
+	return &labelSet{key: key, max: max, seen: make(map[string]struct{})}
+}
+
+// attrs appends the label for value to attrs, unless the label is disabled
+func (s *labelSet) attrs(attrs []attribute.KeyValue, value string) []attribute.KeyValue {
+	if s.max <= 0 {
+		return attrs
+	}
+	return append(attrs, attribute.String(s.key, s.value(value)))
+}
+
+func (s *labelSet) value(value string) string {
+	if value == "" {
+		return "none"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.seen[value]; ok {
+		return value
+	}
+	if len(s.seen) >= s.max {
+		return "other"
+	}
+	s.seen[value] = struct{}{}
+	return value
+}
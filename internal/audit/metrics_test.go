@@ -0,0 +1,89 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// TestPurpose: Validates that authentication and token events are counted with bounded tenant and client labels.
+// Scope: Unit Test
+// Security: Observability (failed logins and lockouts are visible per tenant without unbounded series)
+// Expected: Logins are split by outcome and reason, tokens by grant type; tenants beyond the limit are reported as "other".
+// Test Case ID: AUD-11
+func TestAudit_MetricsLogger(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewMetricsLogger(MetricsConfig{MaxTenants: 1, MaxClients: 10}, meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	l.Log(ctx, Event{Type: TypeLoginSuccess, TenantID: "t1"})
+	l.Log(ctx, Event{Type: TypeLoginFailed, TenantID: "t1", Payload: &LoginFailedPayload{Reason: "invalid_password"}})
+	l.Log(ctx, Event{Type: TypeLoginFailed, TenantID: "t2", Payload: &LoginFailedPayload{Reason: "invalid_password"}})
+	l.Log(ctx, Event{Type: TypeUserLocked, TenantID: "t1", Payload: &UserLockedPayload{Attempts: 5}})
+	l.Log(ctx, Event{Type: TypeTokenIssued, TenantID: "t1", Payload: &TokenPayload{ClientID: "c1", GrantType: "refresh_token"}})
+	l.Log(ctx, Event{Type: TypeClientCreated, TenantID: "t1"}) // Not measured
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]map[attribute.Distinct]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			counts[m.Name] = map[attribute.Distinct]int64{}
+			for _, dp := range sum.DataPoints {
+				counts[m.Name][dp.Attributes.Equivalent()] = dp.Value
+			}
+		}
+	}
+
+	expect := func(name string, want int64, attrs ...attribute.KeyValue) {
+		t.Helper()
+		set := attribute.NewSet(attrs...)
+		if got := counts[name][set.Equivalent()]; got != want {
+			t.Errorf("%s%v = %d, want %d", name, attrs, got, want)
+		}
+	}
+	expect("auth.logins", 1, attribute.String("tenant_id", "t1"), attribute.String("outcome", "success"))
+	expect("auth.logins", 1, attribute.String("tenant_id", "t1"), attribute.String("outcome", "failure"), attribute.String("reason", "invalid_password"))
+	expect("auth.logins", 1, attribute.String("tenant_id", "other"), attribute.String("outcome", "failure"), attribute.String("reason", "invalid_password"))
+	expect("auth.lockouts", 1, attribute.String("tenant_id", "t1"))
+	expect("oauth2.tokens.issued", 1, attribute.String("tenant_id", "t1"), attribute.String("client_id", "c1"), attribute.String("grant_type", "refresh_token"))
+	if len(counts["auth.logins"]) != 3 {
+		t.Errorf("expected 3 login series, got %d", len(counts["auth.logins"]))
+	}
+}
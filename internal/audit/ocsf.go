@@ -0,0 +1,222 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+)
+
+// ocsfVersion is the OCSF schema version the mapping targets
+const ocsfVersion = "1.3.0"
+
+// OCSF categories and classes used by the mapping
+const (
+	ocsfCategoryIAM           = 3
+	ocsfClassBase             = 0
+	ocsfClassAccountChange    = 3001
+	ocsfClassAuthentication   = 3002
+	ocsfClassEntityManagement = 3004
+	ocsfClassUserAccess       = 3005
+)
+
+// OCSF activity, severity and status identifiers
+const (
+	ocsfActivityOther = 99
+
+	ocsfSeverityInformational = 1
+	ocsfSeverityMedium        = 3
+
+	ocsfStatusSuccess = 1
+	ocsfStatusFailure = 2
+)
+
+// ocsfActivity places an event type in an OCSF class
+type ocsfActivity struct {
+	class int
+	id    int
+	name  string
+}
+
+// ocsfActivities maps event types onto OCSF classes; unlisted types become base events
+var ocsfActivities = map[string]ocsfActivity{
+	TypeLoginSuccess:             {ocsfClassAuthentication, 1, "Logon"},
+	TypeLoginFailed:              {ocsfClassAuthentication, 1, "Logon"},
+	TypeLogout:                   {ocsfClassAuthentication, 2, "Logoff"},
+	TypeSessionRevoked:           {ocsfClassAuthentication, 2, "Logoff"},
+	TypeSessionsRevoked:          {ocsfClassAuthentication, 2, "Logoff"},
+	TypeSessionRenewalUnusual:    {ocsfClassAuthentication, ocsfActivityOther, "Session Renewal"},
+	TypeTokenIssued:              {ocsfClassAuthentication, ocsfActivityOther, "Token Issued"},
+	TypeTokenRevoked:             {ocsfClassAuthentication, ocsfActivityOther, "Token Revoked"},
+	TypeTokensRevoked:            {ocsfClassAuthentication, ocsfActivityOther, "Tokens Revoked"},
+	TypeClientAuthFailed:         {ocsfClassAuthentication, 1, "Logon"},
+	TypeClientAuthBanned:         {ocsfClassAuthentication, ocsfActivityOther, "Ban"},
+	TypeUserCreated:              {ocsfClassAccountChange, 1, "Create"},
+	TypePasswordChanged:          {ocsfClassAccountChange, 3, "Password Change"},
+	TypePasswordExpired:          {ocsfClassAccountChange, 4, "Password Reset"},
+	TypePasswordReset:            {ocsfClassAccountChange, 4, "Password Reset"},
+	TypeProfileUpdated:           {ocsfClassAccountChange, ocsfActivityOther, "Profile Update"},
+	TypeEmailChangeRequested:     {ocsfClassAccountChange, ocsfActivityOther, "Email Change Request"},
+	TypeEmailChangeVerified:      {ocsfClassAccountChange, ocsfActivityOther, "Email Change Verification"},
+	TypeEmailChangeReverted:      {ocsfClassAccountChange, ocsfActivityOther, "Email Change Revert"},
+	TypeEmailChangeCancelled:     {ocsfClassAccountChange, ocsfActivityOther, "Email Change Cancel"},
+	TypeEmailChanged:             {ocsfClassAccountChange, ocsfActivityOther, "Email Change"},
+	TypeAccountDeletionRequested: {ocsfClassAccountChange, ocsfActivityOther, "Account Deletion Request"},
+	TypeAccountDeletionCancelled: {ocsfClassAccountChange, ocsfActivityOther, "Account Deletion Cancel"},
+	TypeAccountErased:            {ocsfClassAccountChange, ocsfActivityOther, "Account Erasure"},
+	TypeUserLocked:               {ocsfClassAccountChange, 9, "Lock"},
+	TypeUserUnlocked:             {ocsfClassAccountChange, 12, "Unlock"},
+	TypeRoleAssigned:             {ocsfClassUserAccess, 1, "Assign Privileges"},
+	TypeRoleRevoked:              {ocsfClassUserAccess, 2, "Revoke Privileges"},
+	TypePlatformAdminBootstrap:   {ocsfClassUserAccess, 1, "Assign Privileges"},
+	TypeTenantCreated:            {ocsfClassEntityManagement, 1, "Create"},
+	TypeMemberAdded:              {ocsfClassUserAccess, 1, "Assign Privileges"},
+	TypeMemberRemoved:            {ocsfClassUserAccess, 2, "Revoke Privileges"},
+	TypeClientCreated:            {ocsfClassEntityManagement, 1, "Create"},
+	TypeClientDeleted:            {ocsfClassEntityManagement, 4, "Delete"},
+	TypeSecretRotated:            {ocsfClassEntityManagement, 3, "Update"},
+	TypeClientKeysUpdated:        {ocsfClassEntityManagement, 3, "Update"},
+	TypeEmailSettingsUpdated:     {ocsfClassEntityManagement, 3, "Update"},
+	TypeEmailSettingsDeleted:     {ocsfClassEntityManagement, 4, "Delete"},
+	TypeAuditRetentionUpdated:    {ocsfClassEntityManagement, 3, "Update"},
+	TypeAuditRetentionDeleted:    {ocsfClassEntityManagement, 4, "Delete"},
+	TypeProjectCreated:           {ocsfClassEntityManagement, 1, "Create"},
+	TypeProjectUpdated:           {ocsfClassEntityManagement, 3, "Update"},
+	TypeProjectDeleted:           {ocsfClassEntityManagement, 4, "Delete"},
+	TypeWebhookCreated:           {ocsfClassEntityManagement, 1, "Create"},
+	TypeWebhookUpdated:           {ocsfClassEntityManagement, 3, "Update"},
+	TypeWebhookDeleted:           {ocsfClassEntityManagement, 4, "Delete"},
+	TypeWebhookRedelivered:       {ocsfClassEntityManagement, ocsfActivityOther, "Redeliver"},
+	TypeScopeCreated:             {ocsfClassEntityManagement, 1, "Create"},
+	TypeScopeUpdated:             {ocsfClassEntityManagement, 3, "Update"},
+	TypeScopeDeleted:             {ocsfClassEntityManagement, 4, "Delete"},
+	TypeProtocolLogUpdated:       {ocsfClassEntityManagement, 3, "Update"},
+	TypeSecurityPolicyUpdated:    {ocsfClassEntityManagement, 3, "Update"},
+	TypeSecurityPolicyDeleted:    {ocsfClassEntityManagement, 4, "Delete"},
+	TypeSigningKeyGenerated:      {ocsfClassEntityManagement, 1, "Create"},
+	TypeSigningKeyRotated:        {ocsfClassEntityManagement, 3, "Update"},
+	TypeSigningKeyRetired:        {ocsfClassEntityManagement, ocsfActivityOther, "Retire"},
+}
+
+type ocsfEvent struct {
+	CategoryUID  int              `json:"category_uid"`
+	ClassUID     int              `json:"class_uid"`
+	ActivityID   int              `json:"activity_id"`
+	ActivityName string           `json:"activity_name"`
+	TypeUID      int              `json:"type_uid"`
+	Time         int64            `json:"time"`
+	SeverityID   int              `json:"severity_id"`
+	StatusID     int              `json:"status_id"`
+	Message      string           `json:"message"`
+	Metadata     ocsfMetadata     `json:"metadata"`
+	Actor        *ocsfActor       `json:"actor,omitempty"`
+	SrcEndpoint  *ocsfEndpoint    `json:"src_endpoint,omitempty"`
+	HTTPRequest  *ocsfHTTPRequest `json:"http_request,omitempty"`
+	Unmapped     map[string]any   `json:"unmapped,omitempty"`
+}
+
+type ocsfMetadata struct {
+	Version        string      `json:"version"`
+	UID            string      `json:"uid"`
+	CorrelationUID string      `json:"correlation_uid,omitempty"`
+	TenantUID      string      `json:"tenant_uid,omitempty"`
+	Product        ocsfProduct `json:"product"`
+}
+
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version,omitempty"`
+}
+
+type ocsfActor struct {
+	User ocsfUser `json:"user"`
+}
+
+type ocsfUser struct {
+	UID string `json:"uid"`
+}
+
+type ocsfEndpoint struct {
+	IP string `json:"ip"`
+}
+
+type ocsfHTTPRequest struct {
+	UserAgent string `json:"user_agent"`
+}
+
+// OCSFFormatter encodes events as OCSF JSON.
+// Fields without an OCSF equivalent (event type, schema version, resource and metadata) are kept under "unmapped".
+type OCSFFormatter struct {
+	Version string
+}
+
+// Format encodes the event
+func (f OCSFFormatter) Format(event Event) ([]byte, error) {
+	return json.Marshal(f.event(event))
+}
+
+func (f OCSFFormatter) event(e Event) ocsfEvent {
+	activity, ok := ocsfActivities[e.Type]
+	if !ok {
+		activity = ocsfActivity{ocsfClassBase, ocsfActivityOther, "Other"}
+	}
+	category := ocsfCategoryIAM
+	if activity.class == ocsfClassBase {
+		category = 0
+	}
+
+	out := ocsfEvent{
+		CategoryUID:  category,
+		ClassUID:     activity.class,
+		ActivityID:   activity.id,
+		ActivityName: activity.name,
+		TypeUID:      activity.class*100 + activity.id,
+		Time:         e.Timestamp.UnixMilli(),
+		SeverityID:   ocsfSeverityInformational,
+		StatusID:     ocsfStatusSuccess,
+		Message:      displayName(e.Type),
+		Metadata: ocsfMetadata{
+			Version:        ocsfVersion,
+			UID:            e.ID,
+			CorrelationUID: e.RequestID,
+			TenantUID:      e.TenantID,
+			Product:        ocsfProduct{Name: ProductName, VendorName: ProductVendor, Version: f.Version},
+		},
+		Unmapped: map[string]any{
+			"type":           e.Type,
+			"schema_version": e.SchemaVersion,
+			"resource":       e.Resource,
+		},
+	}
+	if severity(e.Type) == severityMedium {
+		out.SeverityID = ocsfSeverityMedium
+	}
+	if failed(e.Type) {
+		out.StatusID = ocsfStatusFailure
+	}
+	if e.ActorID != "" {
+		out.Actor = &ocsfActor{User: ocsfUser{UID: e.ActorID}}
+	}
+	if e.IPAddress != "" {
+		out.SrcEndpoint = &ocsfEndpoint{IP: e.IPAddress}
+	}
+	if e.UserAgent != "" {
+		out.HTTPRequest = &ocsfHTTPRequest{UserAgent: e.UserAgent}
+	}
+	if len(e.Metadata) > 0 {
+		out.Unmapped["metadata"] = e.Metadata
+	}
+	return out
+}
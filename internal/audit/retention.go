@@ -0,0 +1,187 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// Retention errors
+var (
+	ErrRetentionNotFound = apperr.New(apperr.CodeNotFound, "audit retention policy not found")
+	ErrInvalidRetention  = apperr.New(apperr.CodeInvalidArgument, "invalid audit retention")
+)
+
+// MaxRetentionDays bounds a tenant's retention period (10 years)
+const MaxRetentionDays = 3650
+
+// RetentionPolicy overrides the platform audit retention for one tenant,
+// e.g. seven years for a regulated tenant
+type RetentionPolicy struct {
+	TenantID  string    `json:"tenant_id"`
+	Days      int       `json:"retention_days"` // Zero keeps events forever
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Inherited is set when the tenant has no policy of its own and the platform default applies
+	Inherited bool `json:"inherited"`
+}
+
+// RetentionRepository persists per-tenant retention policies
+type RetentionRepository interface {
+	// Get retrieves a tenant's policy
+	Get(ctx context.Context, tenantID string) (*RetentionPolicy, error)
+
+	// Upsert creates or replaces a tenant's policy
+	Upsert(ctx context.Context, policy *RetentionPolicy) error
+
+	// Delete removes a tenant's policy so the platform default applies again
+	Delete(ctx context.Context, tenantID string) error
+
+	// List retrieves all policies
+	List(ctx context.Context) ([]*RetentionPolicy, error)
+}
+
+// GetRetention returns the retention that applies to a tenant
+func (s *Service) GetRetention(ctx context.Context, tenantID string) (*RetentionPolicy, error) {
+	policy, err := s.retention.Get(ctx, tenantID)
+	if errors.Is(err, ErrRetentionNotFound) {
+		return &RetentionPolicy{TenantID: tenantID, Days: s.defaultRetentionDays, Inherited: true}, nil
+	}
+	return policy, err
+}
+
+// SetRetention sets a tenant's retention in days; zero keeps its events forever
+func (s *Service) SetRetention(ctx context.Context, tenantID, actorID string, days int) (*RetentionPolicy, error) {
+	if days < 0 || days > MaxRetentionDays {
+		return nil, fmt.Errorf("%w: retention_days must be between 0 and %d", ErrInvalidRetention, MaxRetentionDays)
+	}
+
+	policy := &RetentionPolicy{
+		TenantID:  tenantID,
+		Days:      days,
+		UpdatedBy: actorID,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.retention.Upsert(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	s.logger.Log(ctx, Event{
+		Type:     TypeAuditRetentionUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: ResourceAuditRetention,
+		Payload:  &RetentionPayload{RetentionDays: days},
+	})
+	return policy, nil
+}
+
+// DeleteRetention removes a tenant's policy so the platform default applies again
+func (s *Service) DeleteRetention(ctx context.Context, tenantID, actorID string) error {
+	if err := s.retention.Delete(ctx, tenantID); err != nil {
+		return err
+	}
+
+	s.logger.Log(ctx, Event{
+		Type:     TypeAuditRetentionDeleted,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: ResourceAuditRetention,
+	})
+	return nil
+}
+
+// Archiver copies events to long-term storage before they are purged
+type Archiver interface {
+	Archive(ctx context.Context, events []*Event) error
+}
+
+// Purger deletes audit events once they outlive their retention period
+type Purger struct {
+	repo        Repository
+	retention   RetentionRepository
+	defaultDays int
+	archiver    Archiver
+}
+
+// NewPurger creates a purger. defaultDays applies to platform events and tenants
+// without a policy; zero keeps them forever. archiver may be nil.
+func NewPurger(repo Repository, retention RetentionRepository, defaultDays int, archiver Archiver) *Purger {
+	return &Purger{repo: repo, retention: retention, defaultDays: defaultDays, archiver: archiver}
+}
+
+// Purge deletes up to limit expired events, archiving them first when an archiver is set.
+// Tenants with a policy are purged by their own cutoff; all other events, including those
+// of platform operations and deleted tenants, use the default.
+func (p *Purger) Purge(ctx context.Context, limit int) (int64, error) {
+	policies, err := p.retention.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var total int64
+	var custom []string
+	for _, policy := range policies {
+		custom = append(custom, policy.TenantID)
+		if policy.Days == 0 || total >= int64(limit) {
+			continue
+		}
+		n, err := p.purge(ctx, ExpiredFilter{
+			Before:    now.AddDate(0, 0, -policy.Days),
+			TenantIDs: []string{policy.TenantID},
+			Limit:     limit - int(total),
+		})
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+
+	if p.defaultDays == 0 || total >= int64(limit) {
+		return total, nil
+	}
+	n, err := p.purge(ctx, ExpiredFilter{
+		Before:          now.AddDate(0, 0, -p.defaultDays),
+		ExceptTenantIDs: custom,
+		Limit:           limit - int(total),
+	})
+	return total + n, err
+}
+
+func (p *Purger) purge(ctx context.Context, filter ExpiredFilter) (int64, error) {
+	events, err := p.repo.ListExpired(ctx, filter)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	// Events are only deleted once they are safely archived
+	if p.archiver != nil {
+		if err := p.archiver.Archive(ctx, events); err != nil {
+			return 0, fmt.Errorf("failed to archive audit events: %w", err)
+		}
+	}
+
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return p.repo.Delete(ctx, ids)
+}
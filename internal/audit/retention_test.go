@@ -0,0 +1,178 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type stubRetentionRepo struct {
+	policies map[string]*RetentionPolicy
+}
+
+func (r *stubRetentionRepo) Get(ctx context.Context, tenantID string) (*RetentionPolicy, error) {
+	p, ok := r.policies[tenantID]
+	if !ok {
+		return nil, ErrRetentionNotFound
+	}
+	return p, nil
+}
+
+func (r *stubRetentionRepo) Upsert(ctx context.Context, p *RetentionPolicy) error {
+	r.policies[p.TenantID] = p
+	return nil
+}
+
+func (r *stubRetentionRepo) Delete(ctx context.Context, tenantID string) error {
+	if _, ok := r.policies[tenantID]; !ok {
+		return ErrRetentionNotFound
+	}
+	delete(r.policies, tenantID)
+	return nil
+}
+
+func (r *stubRetentionRepo) List(ctx context.Context) ([]*RetentionPolicy, error) {
+	var out []*RetentionPolicy
+	for _, p := range r.policies {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+type recordingArchiver struct {
+	archived []*Event
+	err      error
+}
+
+func (a *recordingArchiver) Archive(ctx context.Context, events []*Event) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.archived = append(a.archived, events...)
+	return nil
+}
+
+func ids(events []*Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.ID
+	}
+	return out
+}
+
+// TestPurpose: Validates that audit events are purged by their tenant's retention and archived first.
+// Scope: Unit Test
+// Security: Compliance retention (regulated tenants keep events longer) and no loss of evidence on archive failure
+// Expected: Events older than their tenant's or the default retention are archived then deleted; nothing is deleted when archiving fails.
+// Test Case ID: AUD-07
+func TestAudit_Purger(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	newRepo := func() *recordingRepo {
+		return &recordingRepo{events: []*Event{
+			{ID: "01", TenantID: "standard", Timestamp: days(100)},
+			{ID: "02", TenantID: "standard", Timestamp: days(10)},
+			{ID: "03", TenantID: "regulated", Timestamp: days(100)},
+			{ID: "04", TenantID: "regulated", Timestamp: days(3000)},
+			{ID: "05", TenantID: "", Timestamp: days(100)},
+			{ID: "06", TenantID: "forever", Timestamp: days(5000)},
+		}}
+	}
+	retention := &stubRetentionRepo{policies: map[string]*RetentionPolicy{
+		"regulated": {TenantID: "regulated", Days: 2555},
+		"forever":   {TenantID: "forever", Days: 0},
+	}}
+
+	repo := newRepo()
+	archiver := &recordingArchiver{}
+	n, err := NewPurger(repo, retention, 90, archiver).Purge(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 events purged, got %d", n)
+	}
+	want := []string{"02", "03", "06"}
+	if got := ids(repo.events); !slices.Equal(got, want) {
+		t.Errorf("expected remaining events %v, got %v", want, got)
+	}
+	if len(archiver.archived) != 3 {
+		t.Errorf("expected purged events to be archived, got %d", len(archiver.archived))
+	}
+
+	// Batches are bounded by the limit
+	repo = newRepo()
+	n, err = NewPurger(repo, retention, 90, nil).Purge(ctx, 1)
+	if err != nil || n != 1 {
+		t.Errorf("expected a single event purged, got %d (%v)", n, err)
+	}
+
+	// Nothing is deleted when archiving fails
+	repo = newRepo()
+	_, err = NewPurger(repo, retention, 90, &recordingArchiver{err: errors.New("bucket unavailable")}).Purge(ctx, 100)
+	if err == nil {
+		t.Error("expected archive failure to be reported")
+	}
+	if len(repo.events) != 6 {
+		t.Errorf("expected no events deleted after archive failure, got %d remaining", len(repo.events))
+	}
+}
+
+// TestPurpose: Validates managing a tenant's audit retention.
+// Scope: Unit Test
+// Security: Retention changes are bounded and themselves audited
+// Expected: Tenants inherit the default; invalid values are rejected; updates and resets are recorded as audit events.
+// Test Case ID: AUD-08
+func TestAudit_Service_Retention(t *testing.T) {
+	ctx := context.Background()
+	events := &recordingRepo{}
+	svc := NewService(events, &stubRetentionRepo{policies: map[string]*RetentionPolicy{}}, NewStoreLogger(events), 90)
+
+	policy, err := svc.GetRetention(ctx, "t1")
+	if err != nil || !policy.Inherited || policy.Days != 90 {
+		t.Fatalf("expected inherited default retention, got %+v (%v)", policy, err)
+	}
+
+	for _, days := range []int{-1, MaxRetentionDays + 1} {
+		if _, err := svc.SetRetention(ctx, "t1", "admin", days); !errors.Is(err, ErrInvalidRetention) {
+			t.Errorf("expected ErrInvalidRetention for %d days, got %v", days, err)
+		}
+	}
+
+	if _, err := svc.SetRetention(ctx, "t1", "admin", 2555); err != nil {
+		t.Fatal(err)
+	}
+	policy, _ = svc.GetRetention(ctx, "t1")
+	if policy.Inherited || policy.Days != 2555 || policy.UpdatedBy != "admin" {
+		t.Errorf("expected tenant policy, got %+v", policy)
+	}
+
+	if err := svc.DeleteRetention(ctx, "t1", "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteRetention(ctx, "t1", "admin"); !errors.Is(err, ErrRetentionNotFound) {
+		t.Errorf("expected ErrRetentionNotFound, got %v", err)
+	}
+
+	if len(events.events) != 2 || events.events[0].Type != TypeAuditRetentionUpdated || events.events[1].Type != TypeAuditRetentionDeleted {
+		t.Errorf("expected retention changes to be audited, got %+v", events.events)
+	}
+}
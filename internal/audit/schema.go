@@ -0,0 +1,644 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// SchemaVersion is the version of the event payloads written by this build.
+// Version 0 marks legacy events whose metadata is a free-form map; they were
+// written before payloads were typed or by callers that still pass Metadata.
+const SchemaVersion = 1
+
+// Schema errors
+var (
+	ErrUnknownEventType = apperr.New(apperr.CodeInvalidArgument, "unknown audit event type")
+	ErrInvalidPayload   = apperr.New(apperr.CodeInvalidArgument, "invalid audit payload")
+)
+
+// Payload is the typed body of an audit event.
+// It is encoded into Event.Metadata when the event is logged, so the JSON keys
+// are part of the schema that downstream consumers rely on.
+type Payload interface {
+	Validate() error
+}
+
+// payloads maps each event type to a constructor for its payload; nil means the type carries none
+var payloads = map[string]func() Payload{
+	TypeLoginSuccess:             func() Payload { return &LoginSuccessPayload{} },
+	TypeLoginFailed:              func() Payload { return &LoginFailedPayload{} },
+	TypeLogout:                   func() Payload { return &SessionPayload{} },
+	TypeSessionRevoked:           func() Payload { return &SessionPayload{} },
+	TypeSessionsRevoked:          func() Payload { return &SessionsRevokedPayload{} },
+	TypeSessionRenewalUnusual:    func() Payload { return &SessionRenewalPayload{} },
+	TypeProfileUpdated:           nil,
+	TypeEmailChangeRequested:     func() Payload { return &EmailChangePayload{} },
+	TypeEmailChangeVerified:      func() Payload { return &EmailChangePayload{} },
+	TypeEmailChangeReverted:      func() Payload { return &EmailChangePayload{} },
+	TypeEmailChangeCancelled:     func() Payload { return &EmailChangePayload{} },
+	TypeEmailChanged:             func() Payload { return &EmailChangePayload{} },
+	TypeAccountDeletionRequested: func() Payload { return &AccountDeletionPayload{} },
+	TypeAccountDeletionCancelled: func() Payload { return &AccountDeletionPayload{} },
+	TypeAccountErased:            func() Payload { return &AccountDeletionPayload{} },
+	TypeTokenIssued:              func() Payload { return &TokenPayload{} },
+	TypeTokenRevoked:             func() Payload { return &TokenPayload{} },
+	TypeTokensRevoked:            func() Payload { return &TokensRevokedPayload{} },
+	TypeRoleAssigned:             func() Payload { return &RolePayload{} },
+	TypeRoleRevoked:              func() Payload { return &RolePayload{} },
+	TypeClientCreated:            func() Payload { return &ClientPayload{} },
+	TypeClientDeleted:            func() Payload { return &ClientPayload{} },
+	TypeSecretRotated:            func() Payload { return &ClientPayload{} },
+	TypeClientKeysUpdated:        func() Payload { return &ClientKeysPayload{} },
+	TypeUserLocked:               func() Payload { return &UserLockedPayload{} },
+	TypeUserUnlocked:             func() Payload { return &UserUnlockedPayload{} },
+	TypeUserCreated:              func() Payload { return &UserCreatedPayload{} },
+	TypePasswordChanged:          nil,
+	TypePasswordExpired:          func() Payload { return &PasswordExpiredPayload{} },
+	TypePasswordReset:            func() Payload { return &PasswordResetPayload{} },
+	TypePlatformAdminBootstrap:   func() Payload { return &BootstrapPayload{} },
+	TypeTenantCreated:            func() Payload { return &TenantPayload{} },
+	TypeMemberAdded:              func() Payload { return &MembershipPayload{} },
+	TypeMemberRemoved:            func() Payload { return &MembershipPayload{} },
+	TypeProjectCreated:           func() Payload { return &ProjectPayload{} },
+	TypeProjectUpdated:           func() Payload { return &ProjectPayload{} },
+	TypeProjectDeleted:           func() Payload { return &ProjectPayload{} },
+	TypeEmailSettingsUpdated:     func() Payload { return &EmailSettingsPayload{} },
+	TypeEmailSettingsDeleted:     nil,
+	TypeEmailTestSent:            func() Payload { return &EmailTestPayload{} },
+	TypeAuditRetentionUpdated:    func() Payload { return &RetentionPayload{} },
+	TypeAuditRetentionDeleted:    nil,
+	TypeWebhookCreated:           func() Payload { return &WebhookPayload{} },
+	TypeWebhookUpdated:           func() Payload { return &WebhookPayload{} },
+	TypeWebhookDeleted:           func() Payload { return &WebhookPayload{} },
+	TypeWebhookRedelivered:       func() Payload { return &WebhookDeliveryPayload{} },
+	TypeScopeCreated:             func() Payload { return &ScopePayload{} },
+	TypeScopeUpdated:             func() Payload { return &ScopePayload{} },
+	TypeScopeDeleted:             func() Payload { return &ScopePayload{} },
+	TypeProtocolLogUpdated:       func() Payload { return &ProtocolLogPayload{} },
+	TypeSecurityPolicyUpdated:    func() Payload { return &SecurityPolicyPayload{} },
+	TypeSecurityPolicyDeleted:    nil,
+	TypeIPBlocked:                func() Payload { return &IPBlockedPayload{} },
+	TypeClientAuthFailed:         func() Payload { return &ClientAuthFailedPayload{} },
+	TypeClientAuthBanned:         func() Payload { return &ClientAuthBannedPayload{} },
+	TypeSigningKeyGenerated:      func() Payload { return &SigningKeyPayload{} },
+	TypeSigningKeyRotated:        func() Payload { return &SigningKeyPayload{} },
+	TypeSigningKeyRetired:        func() Payload { return &SigningKeyPayload{} },
+}
+
+// LoginSuccessPayload describes a successful login
+type LoginSuccessPayload struct {
+	SessionID string `json:"session_id,omitempty"`
+	// The password has expired or is temporary, so the session can only change it
+	PasswordChangeRequired bool `json:"password_change_required,omitempty"`
+}
+
+// Validate checks the payload
+func (p *LoginSuccessPayload) Validate() error { return nil }
+
+// LoginFailedPayload describes a rejected login
+type LoginFailedPayload struct {
+	Reason   string `json:"reason"`
+	Attempts int    `json:"attempts,omitempty"` // Consecutive failures, when known
+}
+
+// Validate checks the payload
+func (p *LoginFailedPayload) Validate() error {
+	return required("reason", p.Reason)
+}
+
+// SessionPayload identifies the session an event applies to
+type SessionPayload struct {
+	SessionID string `json:"session_id"`
+}
+
+// Validate checks the payload
+func (p *SessionPayload) Validate() error {
+	return required("session_id", p.SessionID)
+}
+
+// SessionsRevokedPayload describes sessions destroyed in bulk
+type SessionsRevokedPayload struct {
+	Reason   string `json:"reason"`              // tenant or client
+	ClientID string `json:"client_id,omitempty"` // Client whose users were signed out, for reason client
+	Count    int64  `json:"count"`
+}
+
+// Validate checks the payload
+func (p *SessionsRevokedPayload) Validate() error {
+	switch p.Reason {
+	case "tenant":
+		return nil
+	case "client":
+		return required("client_id", p.ClientID)
+	}
+	return fmt.Errorf("%w: reason must be tenant or client", ErrInvalidPayload)
+}
+
+// SessionRenewalPayload describes a session renewed from another address or user agent than it
+// last saw; the event carries the new ones
+type SessionRenewalPayload struct {
+	SessionID         string   `json:"session_id"`
+	Reasons           []string `json:"reasons"` // address_changed, user_agent_changed
+	PreviousIPAddress string   `json:"previous_ip_address,omitempty"`
+	PreviousUserAgent string   `json:"previous_user_agent,omitempty"`
+}
+
+// Validate checks the payload
+func (p *SessionRenewalPayload) Validate() error {
+	if len(p.Reasons) == 0 {
+		return fmt.Errorf("%w: reasons is required", ErrInvalidPayload)
+	}
+	return required("session_id", p.SessionID)
+}
+
+// TokenPayload describes tokens issued to or revoked for a client
+type TokenPayload struct {
+	ClientID        string `json:"client_id"`
+	GrantType       string `json:"grant_type,omitempty"` // OAuth2 grant that issued the tokens
+	Scope           string `json:"scope,omitempty"`
+	HasRefreshToken bool   `json:"has_rt"`
+	HasIDToken      bool   `json:"has_it"`
+}
+
+// Validate checks the payload
+func (p *TokenPayload) Validate() error {
+	return required("client_id", p.ClientID)
+}
+
+// TokensRevokedPayload describes access and refresh tokens revoked in bulk
+type TokensRevokedPayload struct {
+	Reason        string `json:"reason"`              // tenant or client
+	ClientID      string `json:"client_id,omitempty"` // Client whose tokens were revoked, for reason client
+	RefreshTokens int64  `json:"refresh_tokens"`
+	AccessTokens  int64  `json:"access_tokens"`
+}
+
+// Validate checks the payload
+func (p *TokensRevokedPayload) Validate() error {
+	switch p.Reason {
+	case "tenant":
+		return nil
+	case "client":
+		return required("client_id", p.ClientID)
+	}
+	return fmt.Errorf("%w: reason must be tenant or client", ErrInvalidPayload)
+}
+
+// RolePayload describes a role granted to or revoked from a user
+type RolePayload struct {
+	RoleID       string `json:"role_id"`
+	TargetUserID string `json:"target_user_id"`
+	ProjectID    string `json:"project_id,omitempty"` // Set for project roles
+}
+
+// Validate checks the payload
+func (p *RolePayload) Validate() error {
+	if err := required("role_id", p.RoleID); err != nil {
+		return err
+	}
+	return required("target_user_id", p.TargetUserID)
+}
+
+// ClientPayload identifies the OAuth2 client an event applies to
+type ClientPayload struct {
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name,omitempty"`
+}
+
+// Validate checks the payload
+func (p *ClientPayload) Validate() error {
+	return required("client_id", p.ClientID)
+}
+
+// ClientKeysPayload describes a change to the public keys of an OAuth2 client
+type ClientKeysPayload struct {
+	ClientID string   `json:"client_id"`
+	JWKSURI  string   `json:"jwks_uri,omitempty"` // Set when the keys are fetched from the client
+	KeyIDs   []string `json:"key_ids,omitempty"`
+}
+
+// Validate checks the payload
+func (p *ClientKeysPayload) Validate() error {
+	return required("client_id", p.ClientID)
+}
+
+// UserLockedPayload describes an account lockout
+type UserLockedPayload struct {
+	Attempts int `json:"attempts"`
+}
+
+// Validate checks the payload
+func (p *UserLockedPayload) Validate() error {
+	if p.Attempts <= 0 {
+		return fmt.Errorf("%w: attempts must be positive", ErrInvalidPayload)
+	}
+	return nil
+}
+
+// UserUnlockedPayload describes an admin clearing a user's lockout
+type UserUnlockedPayload struct {
+	TargetUserID string `json:"target_user_id"`
+	Attempts     int    `json:"attempts"` // Failed attempts cleared
+}
+
+// Validate checks the payload
+func (p *UserUnlockedPayload) Validate() error {
+	return required("target_user_id", p.TargetUserID)
+}
+
+// PasswordExpiredPayload describes an admin forcing a user to change their password
+type PasswordExpiredPayload struct {
+	TargetUserID string `json:"target_user_id"`
+}
+
+// Validate checks the payload
+func (p *PasswordExpiredPayload) Validate() error {
+	return required("target_user_id", p.TargetUserID)
+}
+
+// PasswordResetPayload describes an admin replacing a user's password
+type PasswordResetPayload struct {
+	TargetUserID string `json:"target_user_id"`
+	MustChange   bool   `json:"must_change,omitempty"` // The password is temporary: the user must change it at the next login
+}
+
+// Validate checks the payload
+func (p *PasswordResetPayload) Validate() error {
+	return required("target_user_id", p.TargetUserID)
+}
+
+// EmailChangePayload describes a step of a user changing their email address
+type EmailChangePayload struct {
+	ChangeID    string     `json:"change_id"`
+	OldEmail    string     `json:"old_email"`
+	NewEmail    string     `json:"new_email"`
+	EffectiveAt *time.Time `json:"effective_at,omitempty"` // End of the cooling-off window of a verified change
+}
+
+// Validate checks the payload
+func (p *EmailChangePayload) Validate() error {
+	if err := required("change_id", p.ChangeID); err != nil {
+		return err
+	}
+	return required("new_email", p.NewEmail)
+}
+
+// AccountDeletionPayload describes a step of a user deleting their own account
+type AccountDeletionPayload struct {
+	UserID   string     `json:"user_id"`
+	DeleteAt *time.Time `json:"delete_at,omitempty"` // End of the grace period of a requested deletion
+	Reason   string     `json:"reason,omitempty"`    // Why a deletion was cancelled: user, login or last_administrator
+}
+
+// Validate checks the payload
+func (p *AccountDeletionPayload) Validate() error {
+	return required("user_id", p.UserID)
+}
+
+// UserCreatedPayload describes a new user
+type UserCreatedPayload struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// Validate checks the payload
+func (p *UserCreatedPayload) Validate() error {
+	if err := required("user_id", p.UserID); err != nil {
+		return err
+	}
+	return required("email", p.Email)
+}
+
+// BootstrapPayload describes the creation of the initial platform admin
+type BootstrapPayload struct {
+	Email    string `json:"email"`
+	TenantID string `json:"tenant_id,omitempty"`
+	RoleID   string `json:"role_id"`
+}
+
+// Validate checks the payload
+func (p *BootstrapPayload) Validate() error {
+	if err := required("email", p.Email); err != nil {
+		return err
+	}
+	return required("role_id", p.RoleID)
+}
+
+// TenantPayload describes a tenant
+type TenantPayload struct {
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+}
+
+// Validate checks the payload
+func (p *TenantPayload) Validate() error {
+	if err := required("tenant_id", p.TenantID); err != nil {
+		return err
+	}
+	return required("tenant_name", p.TenantName)
+}
+
+// MembershipPayload describes a user added to or removed from a tenant they do not belong to by registration
+type MembershipPayload struct {
+	MembershipID string `json:"membership_id"`
+	TargetUserID string `json:"target_user_id"`
+	HomeTenantID string `json:"home_tenant_id,omitempty"` // Tenant the user is registered in
+}
+
+// Validate checks the payload
+func (p *MembershipPayload) Validate() error {
+	if err := required("membership_id", p.MembershipID); err != nil {
+		return err
+	}
+	return required("target_user_id", p.TargetUserID)
+}
+
+// ProjectPayload identifies the project an event applies to
+type ProjectPayload struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name,omitempty"`
+}
+
+// Validate checks the payload
+func (p *ProjectPayload) Validate() error {
+	return required("project_id", p.ProjectID)
+}
+
+// EmailSettingsPayload describes a change to a tenant's outbound email settings
+type EmailSettingsPayload struct {
+	Host            string `json:"host"`
+	Enabled         bool   `json:"enabled"`
+	PasswordChanged bool   `json:"password_changed"`
+}
+
+// Validate checks the payload
+func (p *EmailSettingsPayload) Validate() error {
+	return required("host", p.Host)
+}
+
+// EmailTestPayload describes a test message sent with a tenant's settings
+type EmailTestPayload struct {
+	Email   string `json:"email"` // Recipient
+	Success bool   `json:"success"`
+}
+
+// Validate checks the payload
+func (p *EmailTestPayload) Validate() error {
+	return required("email", p.Email)
+}
+
+// RetentionPayload describes a tenant's audit retention
+type RetentionPayload struct {
+	RetentionDays int `json:"retention_days"`
+}
+
+// Validate checks the payload
+func (p *RetentionPayload) Validate() error {
+	if p.RetentionDays < 0 {
+		return fmt.Errorf("%w: retention_days must not be negative", ErrInvalidPayload)
+	}
+	return nil
+}
+
+// WebhookPayload describes a change to a tenant's webhook subscription
+type WebhookPayload struct {
+	WebhookID     string   `json:"webhook_id"`
+	URL           string   `json:"url"`
+	EventTypes    []string `json:"event_types,omitempty"` // Empty subscribes to all events
+	Enabled       bool     `json:"enabled"`
+	SecretRotated bool     `json:"rotated,omitempty"` // The signing secret was replaced; the key avoids secret redaction
+}
+
+// Validate checks the payload
+func (p *WebhookPayload) Validate() error {
+	if err := required("webhook_id", p.WebhookID); err != nil {
+		return err
+	}
+	return required("url", p.URL)
+}
+
+// WebhookDeliveryPayload describes a manual redelivery of a webhook event
+type WebhookDeliveryPayload struct {
+	WebhookID  string `json:"webhook_id"`
+	DeliveryID string `json:"delivery_id"` // The new delivery
+	EventID    string `json:"event_id"`
+}
+
+// Validate checks the payload
+func (p *WebhookDeliveryPayload) Validate() error {
+	if err := required("webhook_id", p.WebhookID); err != nil {
+		return err
+	}
+	if err := required("delivery_id", p.DeliveryID); err != nil {
+		return err
+	}
+	return required("event_id", p.EventID)
+}
+
+// ScopePayload describes a change to a tenant's custom OAuth2 scope
+type ScopePayload struct {
+	ScopeID     string   `json:"scope_id"`
+	Name        string   `json:"name"`
+	Claims      []string `json:"claims,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Validate checks the payload
+func (p *ScopePayload) Validate() error {
+	if err := required("scope_id", p.ScopeID); err != nil {
+		return err
+	}
+	return required("name", p.Name)
+}
+
+// ProtocolLogPayload describes a change to a tenant's protocol decision logging
+type ProtocolLogPayload struct {
+	Enabled   bool       `json:"enabled"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Validate checks the payload
+func (p *ProtocolLogPayload) Validate() error { return nil }
+
+// SecurityPolicyPayload describes a tenant's new security settings; settings left out inherit the platform's
+type SecurityPolicyPayload struct {
+	LockoutMaxAttempts     int     `json:"lockout_max_attempts,omitempty"`
+	LockoutDurationSeconds int     `json:"lockout_duration_seconds,omitempty"`
+	LoginRPS               float64 `json:"login_rps,omitempty"`
+	LoginBurst             int     `json:"login_burst,omitempty"`
+	TokenRPS               float64 `json:"token_rps,omitempty"`
+	TokenBurst             int     `json:"token_burst,omitempty"`
+	PasswordMaxAgeSeconds  int     `json:"password_max_age_seconds,omitempty"`
+}
+
+// Validate checks the payload
+func (p *SecurityPolicyPayload) Validate() error { return nil }
+
+// IPBlockedPayload describes an admin plane request refused because of its client address
+type IPBlockedPayload struct {
+	ClientIP string `json:"client_ip"`
+	Rule     string `json:"rule"` // allowlist, denylist or tenant_allowlist
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+}
+
+// Validate checks the payload
+func (p *IPBlockedPayload) Validate() error {
+	if err := required("client_ip", p.ClientIP); err != nil {
+		return err
+	}
+	return required("rule", p.Rule)
+}
+
+// ClientAuthFailedPayload describes a failed OAuth2 client authentication
+type ClientAuthFailedPayload struct {
+	ClientID string `json:"client_id,omitempty"` // Empty when no credentials were presented
+	ClientIP string `json:"client_ip"`
+	Failures int    `json:"failures"` // Recent failures of the client_id, or of the address without one
+}
+
+// Validate checks the payload
+func (p *ClientAuthFailedPayload) Validate() error {
+	return required("client_ip", p.ClientIP)
+}
+
+// ClientAuthBannedPayload describes a temporary ban after repeated failed client authentications
+type ClientAuthBannedPayload struct {
+	ClientID   string `json:"client_id,omitempty"`
+	ClientIP   string `json:"client_ip"`
+	Key        string `json:"key"` // client_id (the client_id from client_ip) or client_ip (the whole address): which is banned
+	BanSeconds int    `json:"ban_seconds"`
+}
+
+// Validate checks the payload
+func (p *ClientAuthBannedPayload) Validate() error {
+	if err := required("client_ip", p.ClientIP); err != nil {
+		return err
+	}
+	return required("key", p.Key)
+}
+
+// SigningKeyPayload describes a token signing key lifecycle change
+type SigningKeyPayload struct {
+	KeyID          string   `json:"key_id"`                     // Generated, newly active or retired key
+	PreviousKeyIDs []string `json:"previous_key_ids,omitempty"` // Keys a rotation moved out of signing
+	Emergency      bool     `json:"emergency,omitempty"`        // Previous keys were retired immediately
+}
+
+// Validate checks the payload
+func (p *SigningKeyPayload) Validate() error {
+	return required("key_id", p.KeyID)
+}
+
+func required(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%w: %s is required", ErrInvalidPayload, field)
+	}
+	return nil
+}
+
+// encodePayload encodes the event's payload into Metadata and validates it against the event type.
+// Only valid events are stamped with SchemaVersion; an invalid payload is still encoded so nothing
+// is lost, but the event stays at version 0. Events with free-form Metadata and no payload are
+// passed through as legacy events, and events that were already encoded are left untouched.
+func encodePayload(event *Event) error {
+	if event.Payload == nil && (event.SchemaVersion > 0 || len(event.Metadata) > 0) {
+		return nil
+	}
+
+	payload := event.Payload
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+		var metadata map[string]any
+		if err := json.Unmarshal(b, &metadata); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+		event.Metadata = metadata
+		event.Payload = nil
+	}
+
+	newPayload, ok := payloads[event.Type]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownEventType, event.Type)
+	}
+	switch {
+	case newPayload == nil && payload != nil:
+		return fmt.Errorf("%w: %s events carry no payload", ErrInvalidPayload, event.Type)
+	case newPayload == nil:
+	case payload == nil:
+		if err := newPayload().Validate(); err != nil {
+			return err
+		}
+	case reflect.TypeOf(payload) != reflect.TypeOf(newPayload()):
+		return fmt.Errorf("%w: %s events carry %T, not %T", ErrInvalidPayload, event.Type, newPayload(), payload)
+	default:
+		if err := payload.Validate(); err != nil {
+			return err
+		}
+	}
+
+	event.SchemaVersion = SchemaVersion
+	return nil
+}
+
+// EventTypes returns every known event type in sorted order
+func EventTypes() []string {
+	return slices.Sorted(maps.Keys(payloads))
+}
+
+// ValidateEvent reports whether an event's payload matches its type's schema
+func ValidateEvent(event Event) error {
+	return encodePayload(&event)
+}
+
+// DecodePayload returns the typed payload of a stored or received event, or nil for
+// types that carry none. Legacy events are decoded best effort: their metadata mostly
+// uses the same keys, but fields that were never recorded are left empty.
+func DecodePayload(event Event) (Payload, error) {
+	if event.Payload != nil {
+		return event.Payload, nil
+	}
+	newPayload, ok := payloads[event.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, event.Type)
+	}
+	if newPayload == nil {
+		return nil, nil
+	}
+
+	payload := newPayload()
+	if len(event.Metadata) == 0 {
+		return payload, nil
+	}
+	b, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestPurpose: Validates typed audit payloads: schema validation at log time, encoding and decoding.
+// Scope: Unit Test
+// Security: Downstream detection rules rely on a stable, versioned event schema
+// Expected: Valid payloads are stamped with SchemaVersion; invalid or mismatched payloads are reported and kept at version 0; legacy metadata still decodes.
+// Test Case ID: AUD-09
+func TestAudit_Schema(t *testing.T) {
+	ctx := context.Background()
+
+	event := prepare(ctx, Event{Type: TypeLoginFailed, Payload: &LoginFailedPayload{Reason: "invalid_password", Attempts: 3}})
+	if event.SchemaVersion != SchemaVersion || event.Payload != nil {
+		t.Fatalf("expected an encoded version %d event, got %+v", SchemaVersion, event)
+	}
+	if event.Metadata["reason"] != "invalid_password" || event.Metadata["attempts"] != float64(3) {
+		t.Errorf("expected payload fields in metadata, got %v", event.Metadata)
+	}
+
+	// Consumers decode the payload from the wire format
+	b, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received Event
+	if err := json.Unmarshal(b, &received); err != nil {
+		t.Fatal(err)
+	}
+	payload, err := DecodePayload(received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := payload.(*LoginFailedPayload); !ok || p.Reason != "invalid_password" || p.Attempts != 3 {
+		t.Errorf("unexpected decoded payload %#v", payload)
+	}
+
+	invalid := []Event{
+		{Type: TypeLoginFailed, Payload: &LoginFailedPayload{}},
+		{Type: TypeLoginFailed},
+		{Type: TypeLoginFailed, Payload: &ClientPayload{ClientID: "c1"}},
+		{Type: TypePasswordChanged, Payload: &ClientPayload{ClientID: "c1"}},
+		{Type: "made_up"},
+	}
+	for _, e := range invalid {
+		if err := ValidateEvent(e); !errors.Is(err, ErrInvalidPayload) && !errors.Is(err, ErrUnknownEventType) {
+			t.Errorf("expected %s with %T to be rejected, got %v", e.Type, e.Payload, err)
+		}
+		if prepared := prepare(ctx, e); prepared.SchemaVersion != 0 {
+			t.Errorf("expected invalid %s event to stay at version 0", e.Type)
+		}
+	}
+
+	// Types without a payload are versioned too
+	if event := prepare(ctx, Event{Type: TypePasswordChanged}); event.SchemaVersion != SchemaVersion {
+		t.Errorf("expected password_changed to be versioned, got %d", event.SchemaVersion)
+	}
+
+	// Free-form metadata is accepted as a legacy event and decodes best effort
+	legacy := prepare(ctx, Event{Type: TypeTokenIssued, Metadata: map[string]any{"client_id": "c1", "has_rt": true}})
+	if legacy.SchemaVersion != 0 {
+		t.Errorf("expected legacy event at version 0, got %d", legacy.SchemaVersion)
+	}
+	payload, err = DecodePayload(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := payload.(*TokenPayload); p.ClientID != "c1" || !p.HasRefreshToken {
+		t.Errorf("unexpected legacy payload %#v", p)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Sink delivers audit events to an external system such as a message broker
+type Sink interface {
+	// Name identifies the sink in logs and metrics
+	Name() string
+
+	// Send delivers a batch of events. The slice is reused after Send returns.
+	Send(ctx context.Context, events []Event) error
+
+	// Close releases the sink's connections
+	Close() error
+}
+
+// OverflowPolicy decides what happens when the sink buffer is full
+type OverflowPolicy string
+
+// Overflow policies
+const (
+	// OverflowDropNewest discards the event being logged
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest discards the oldest buffered event to make room
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock waits for room, applying back-pressure to the audited request
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// ErrInvalidOverflowPolicy is returned for an unknown overflow policy
+var ErrInvalidOverflowPolicy = apperr.New(apperr.CodeInvalidArgument, "invalid audit sink overflow policy")
+
+// SinkConfig controls buffering and batching in front of a Sink
+type SinkConfig struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+	SendTimeout   time.Duration
+	Overflow      OverflowPolicy
+}
+
+// SinkLogger implements Logger by streaming events to a Sink asynchronously.
+// Events are buffered in memory and sent in batches by a background worker,
+// so a slow or unavailable sink never adds latency to the audited request
+// unless the OverflowBlock policy is chosen.
+type SinkLogger struct {
+	sink   Sink
+	cfg    SinkConfig
+	buffer chan Event
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	delivered metric.Int64Counter
+	dropped   metric.Int64Counter
+	latency   metric.Float64Histogram
+	attrs     metric.MeasurementOption
+}
+
+// NewSinkLogger creates a logger for the sink and starts its delivery worker
+func NewSinkLogger(sink Sink, cfg SinkConfig, meter *metrics.Meter) (*SinkLogger, error) {
+	switch cfg.Overflow {
+	case "":
+		cfg.Overflow = OverflowDropNewest
+	case OverflowDropNewest, OverflowDropOldest, OverflowBlock:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOverflowPolicy, cfg.Overflow)
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = 10 * time.Second
+	}
+
+	delivered, err := meter.CreateCounter("audit.sink.delivered", "Audit events delivered to a sink")
+	if err != nil {
+		return nil, err
+	}
+	dropped, err := meter.CreateCounter("audit.sink.dropped", "Audit events dropped before reaching a sink")
+	if err != nil {
+		return nil, err
+	}
+	latency, err := meter.CreateHistogram("audit.sink.send.duration", "Duration of a batch send to an audit sink", "s")
+	if err != nil {
+		return nil, err
+	}
+
+	l := &SinkLogger{
+		sink:      sink,
+		cfg:       cfg,
+		buffer:    make(chan Event, cfg.BufferSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		delivered: delivered,
+		dropped:   dropped,
+		latency:   latency,
+		attrs:     metric.WithAttributes(attribute.String("sink", sink.Name())),
+	}
+	go l.run()
+	return l, nil
+}
+
+// Log queues an event for delivery. Secrets in metadata are redacted first.
+func (l *SinkLogger) Log(ctx context.Context, event Event) {
+	select {
+	case <-l.stop:
+		l.drop(ctx, 1, "closed")
+		return
+	default:
+	}
+
+	event = prepare(ctx, event)
+	switch l.cfg.Overflow {
+	case OverflowBlock:
+		select {
+		case l.buffer <- event:
+		case <-l.stop:
+			l.drop(ctx, 1, "closed")
+		case <-ctx.Done():
+			l.drop(ctx, 1, "overflow")
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case l.buffer <- event:
+				return
+			default:
+			}
+			select {
+			case <-l.buffer:
+				l.drop(ctx, 1, "overflow")
+			default:
+			}
+		}
+	default:
+		select {
+		case l.buffer <- event:
+		default:
+			l.drop(ctx, 1, "overflow")
+		}
+	}
+}
+
+// Close stops accepting events, delivers what is buffered and closes the sink.
+// Buffered events that cannot be sent before ctx is done are dropped.
+func (l *SinkLogger) Close(ctx context.Context) error {
+	l.once.Do(func() { close(l.stop) })
+	select {
+	case <-l.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return l.sink.Close()
+}
+
+// run batches buffered events and sends them until Close is called
+func (l *SinkLogger) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, l.cfg.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			l.send(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case event := <-l.buffer:
+			batch = append(batch, event)
+			if len(batch) >= l.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-l.stop:
+			// Drain whatever is already buffered
+			for {
+				select {
+				case event := <-l.buffer:
+					batch = append(batch, event)
+					if len(batch) >= l.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send delivers one batch and records the outcome
+func (l *SinkLogger) send(batch []Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.cfg.SendTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := l.sink.Send(ctx, batch)
+	l.latency.Record(ctx, time.Since(start).Seconds(), l.attrs)
+
+	if err != nil {
+		l.drop(ctx, len(batch), "send_failed")
+		slog.WarnContext(ctx, "failed to deliver audit events",
+			slog.String("sink", l.sink.Name()),
+			slog.Int("events", len(batch)),
+			slog.String("error", err.Error()),
+			slog.String(AttrComponent, "audit"),
+		)
+		return
+	}
+	l.delivered.Add(ctx, int64(len(batch)), l.attrs)
+}
+
+// drop records events that will never reach the sink
+func (l *SinkLogger) drop(ctx context.Context, n int, reason string) {
+	l.dropped.Add(ctx, int64(n), metric.WithAttributes(
+		attribute.String("sink", l.sink.Name()),
+		attribute.String("reason", reason),
+	))
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaConfig configures the Kafka sink
+type KafkaConfig struct {
+	Brokers []string
+	Topic   string
+	// Formatter encodes message values; nil means JSON
+	Formatter audit.Formatter
+}
+
+// Kafka publishes audit events to a Kafka topic.
+// Messages are keyed by tenant ID so each tenant's events stay ordered within a partition.
+type Kafka struct {
+	writer    *kafka.Writer
+	formatter audit.Formatter
+}
+
+// NewKafka creates a Kafka sink
+func NewKafka(cfg KafkaConfig) (*Kafka, error) {
+	if len(cfg.Brokers) == 0 || cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka audit sink requires brokers and a topic")
+	}
+	return &Kafka{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// Batching happens in audit.SinkLogger; do not wait for more messages here
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		formatter: cfg.Formatter,
+	}, nil
+}
+
+// Name identifies the sink
+func (k *Kafka) Name() string {
+	return TypeKafka
+}
+
+// Send writes a batch of events and waits for all in-sync replicas to acknowledge
+func (k *Kafka) Send(ctx context.Context, events []audit.Event) error {
+	msgs, err := kafkaMessages(k.formatter, events)
+	if err != nil {
+		return err
+	}
+	return k.writer.WriteMessages(ctx, msgs...)
+}
+
+// Close flushes and closes the writer
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
+
+func kafkaMessages(f audit.Formatter, events []audit.Event) ([]kafka.Message, error) {
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, e := range events {
+		value, err := encode(f, e)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(e.TenantID),
+			Value: value,
+			Time:  e.Timestamp,
+			Headers: []kafka.Header{
+				{Key: "audit_type", Value: []byte(e.Type)},
+			},
+		})
+	}
+	return msgs, nil
+}
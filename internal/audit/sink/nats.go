@@ -0,0 +1,82 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// NATSConfig configures the NATS sink
+type NATSConfig struct {
+	URL string
+	// Subject is the prefix; events are published to "<Subject>.<event type>"
+	Subject string
+	// Formatter encodes message payloads; nil means JSON
+	Formatter audit.Formatter
+}
+
+// NATS publishes audit events to NATS subjects
+type NATS struct {
+	conn      *nats.Conn
+	subject   string
+	formatter audit.Formatter
+}
+
+// NewNATS connects to NATS and creates a sink
+func NewNATS(cfg NATSConfig) (*NATS, error) {
+	if cfg.URL == "" || cfg.Subject == "" {
+		return nil, fmt.Errorf("nats audit sink requires a url and a subject")
+	}
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("opentrusty-audit"),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	return &NATS{conn: conn, subject: cfg.Subject, formatter: cfg.Formatter}, nil
+}
+
+// Name identifies the sink
+func (n *NATS) Name() string {
+	return TypeNATS
+}
+
+// Send publishes a batch of events and waits until the server has received them
+func (n *NATS) Send(ctx context.Context, events []audit.Event) error {
+	for _, e := range events {
+		data, err := encode(n.formatter, e)
+		if err != nil {
+			return err
+		}
+		if err := n.conn.Publish(natsSubject(n.subject, e), data); err != nil {
+			return fmt.Errorf("failed to publish audit event: %w", err)
+		}
+	}
+	return n.conn.FlushWithContext(ctx)
+}
+
+// Close drains pending messages and closes the connection
+func (n *NATS) Close() error {
+	return n.conn.Drain()
+}
+
+func natsSubject(prefix string, e audit.Event) string {
+	return prefix + "." + e.Type
+}
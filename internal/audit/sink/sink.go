@@ -0,0 +1,61 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink implements audit.Sink on message brokers so security teams can
+// consume audit events in real time. Each sink encodes events with its own
+// audit.Formatter, so one deployment can feed JSON to a data lake and CEF to a SIEM.
+package sink
+
+import (
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// Supported sink types
+const (
+	TypeKafka = "kafka"
+	TypeNATS  = "nats"
+)
+
+// Config selects and configures a sink
+type Config struct {
+	Type  string
+	Kafka KafkaConfig
+	NATS  NATSConfig
+}
+
+// New creates the configured sink
+func New(cfg Config) (audit.Sink, error) {
+	switch cfg.Type {
+	case TypeKafka:
+		return NewKafka(cfg.Kafka)
+	case TypeNATS:
+		return NewNATS(cfg.NATS)
+	default:
+		return nil, fmt.Errorf("unsupported audit sink %q", cfg.Type)
+	}
+}
+
+// encode serializes an event for the wire; a nil formatter encodes JSON
+func encode(f audit.Formatter, event audit.Event) ([]byte, error) {
+	if f == nil {
+		f = audit.JSONFormatter{}
+	}
+	b, err := f.Format(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit event %s: %w", event.ID, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPurpose: Validates how audit events are mapped onto broker messages.
+// Scope: Unit Test
+// Security: Per-tenant ordering and routable subjects for SOC consumers
+// Expected: Kafka messages are keyed by tenant and carry the event as JSON; NATS subjects end with the event type.
+// Test Case ID: SNK-01
+func TestSink_MessageMapping(t *testing.T) {
+	event := audit.Event{ID: "e1", Type: audit.TypeLoginFailed, TenantID: "t1", Timestamp: time.Now().UTC()}
+
+	msgs, err := kafkaMessages(nil, []audit.Event{event})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "t1", string(msgs[0].Key))
+	assert.Equal(t, audit.TypeLoginFailed, string(msgs[0].Headers[0].Value))
+
+	var decoded audit.Event
+	require.NoError(t, json.Unmarshal(msgs[0].Value, &decoded))
+	assert.Equal(t, "e1", decoded.ID)
+	assert.Equal(t, "t1", decoded.TenantID)
+
+	assert.Equal(t, "opentrusty.audit.login_failed", natsSubject("opentrusty.audit", event))
+
+	_, err = New(Config{Type: "syslog"})
+	assert.Error(t, err)
+	_, err = NewKafka(KafkaConfig{Topic: "audit"})
+	assert.Error(t, err)
+}
+
+// TestPurpose: Validates that each sink encodes messages with its configured formatter.
+// Scope: Unit Test
+// Security: SIEM pipelines receive events in the schema they parse
+// Expected: A Kafka sink configured for CEF emits CEF records while keeping tenant keys.
+// Test Case ID: SNK-02
+func TestSink_MessageFormat(t *testing.T) {
+	event := audit.Event{ID: "e1", Type: audit.TypeLoginSuccess, TenantID: "t1", Timestamp: time.Now().UTC()}
+	formatter, err := audit.NewFormatter(audit.FormatCEF, "1.0.0")
+	require.NoError(t, err)
+
+	msgs, err := kafkaMessages(formatter, []audit.Event{event})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "t1", string(msgs[0].Key))
+	assert.True(t, strings.HasPrefix(string(msgs[0].Value), "CEF:0|OpenTrusty|OpenTrusty|1.0.0|login_success|"))
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+)
+
+// fakeSink records delivered events; it blocks in Send while gate is non-nil and open
+type fakeSink struct {
+	mu      sync.Mutex
+	batches [][]Event
+	gate    chan struct{}
+	closed  bool
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Send(ctx context.Context, events []Event) error {
+	if s.gate != nil {
+		<-s.gate
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSink) types() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var types []string
+	for _, b := range s.batches {
+		for _, e := range b {
+			types = append(types, e.Type)
+		}
+	}
+	return types
+}
+
+func newTestSinkLogger(t *testing.T, sink Sink, cfg SinkConfig) *SinkLogger {
+	t.Helper()
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewSinkLogger(sink, cfg, meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// TestPurpose: Validates that streamed events are batched, redacted and flushed on close.
+// Scope: Unit Test
+// Security: Real-time audit delivery without leaking secrets (CWE-532)
+// Expected: Five events with a batch size of two arrive in order in three batches; secrets are redacted; the sink is closed.
+// Test Case ID: AUD-03
+func TestAudit_SinkLogger_BatchesAndDrains(t *testing.T) {
+	sink := &fakeSink{}
+	l := newTestSinkLogger(t, sink, SinkConfig{BatchSize: 2, FlushInterval: time.Hour})
+
+	for _, typ := range []string{"a", "b", "c", "d", "e"} {
+		l.Log(context.Background(), Event{Type: typ, Metadata: map[string]any{"refresh_token": "rt"}})
+	}
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sink.types(); len(got) != 5 || got[0] != "a" || got[4] != "e" {
+		t.Fatalf("expected events a..e in order, got %v", got)
+	}
+	if len(sink.batches) != 3 {
+		t.Errorf("expected 3 batches, got %d", len(sink.batches))
+	}
+	if sink.batches[0][0].Metadata["refresh_token"] != "[REDACTED]" || sink.batches[0][0].ID == "" {
+		t.Errorf("expected a redacted event with an ID, got %+v", sink.batches[0][0])
+	}
+	if !sink.closed {
+		t.Error("expected the sink to be closed")
+	}
+}
+
+// TestPurpose: Validates the overflow policies when the sink cannot keep up.
+// Scope: Unit Test
+// Security: Availability (a stalled broker must not block authentication traffic)
+// Expected: drop_newest keeps the first buffered events, drop_oldest keeps the latest ones; Log never blocks.
+// Test Case ID: AUD-04
+func TestAudit_SinkLogger_Overflow(t *testing.T) {
+	cases := map[OverflowPolicy][]string{
+		OverflowDropNewest: {"first", "1", "2"},
+		OverflowDropOldest: {"first", "3", "4"},
+	}
+	for policy, want := range cases {
+		t.Run(string(policy), func(t *testing.T) {
+			sink := &fakeSink{gate: make(chan struct{})}
+			l := newTestSinkLogger(t, sink, SinkConfig{BufferSize: 2, BatchSize: 1, FlushInterval: time.Hour, Overflow: policy})
+
+			// The worker takes the first event and stalls in Send, leaving the buffer to fill
+			l.Log(context.Background(), Event{Type: "first"})
+			deadline := time.Now().Add(time.Second)
+			for len(l.buffer) != 0 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			for _, typ := range []string{"1", "2", "3", "4"} {
+				l.Log(context.Background(), Event{Type: typ})
+			}
+
+			close(sink.gate)
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			got := sink.types()
+			if len(got) != len(want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("expected %v, got %v", want, got)
+				}
+			}
+		})
+	}
+
+	_, err := NewSinkLogger(&fakeSink{}, SinkConfig{Overflow: "spill"}, nil)
+	if !errors.Is(err, ErrInvalidOverflowPolicy) {
+		t.Errorf("expected ErrInvalidOverflowPolicy, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// Filter selects audit events for a query. Empty fields other than TenantID match everything.
+type Filter struct {
+	TenantID string // Events of this tenant; empty selects platform-level events
+	Type     string
+	ActorID  string
+	Since    time.Time // Inclusive lower bound on Timestamp
+	Until    time.Time // Exclusive upper bound on Timestamp
+	Page     pagination.Params
+}
+
+// ExpiredFilter selects events that have outlived their retention period
+type ExpiredFilter struct {
+	Before          time.Time // Exclusive upper bound on Timestamp
+	TenantIDs       []string  // Only events of these tenants; empty matches all events
+	ExceptTenantIDs []string  // Skips events of these tenants
+	Limit           int
+}
+
+// StatsFilter selects the window that Stats aggregates, across all tenants
+type StatsFilter struct {
+	Since      time.Time // Inclusive lower bound on Timestamp
+	Until      time.Time // Exclusive upper bound on Timestamp
+	TopClients int       // Number of clients to report, busiest first
+}
+
+// Stats aggregates the audit events of a window
+type Stats struct {
+	EventCounts map[string]int64 // Events by type
+	ActiveUsers int64            // Distinct users with at least one successful login
+	TopClients  []ClientStats    // Clients that were issued the most tokens, busiest first
+}
+
+// ClientStats counts the tokens issued to one client
+type ClientStats struct {
+	TenantID     string `json:"tenant_id"`
+	ClientID     string `json:"client_id"`
+	TokensIssued int64  `json:"tokens_issued"`
+}
+
+// Repository persists audit events
+type Repository interface {
+	// Create stores an event
+	Create(ctx context.Context, event *Event) error
+
+	// List retrieves a page of events matching the filter, ordered by ID (oldest first)
+	List(ctx context.Context, filter Filter) ([]*Event, error)
+
+	// ListExpired retrieves up to filter.Limit events older than the cutoff, ordered by ID
+	ListExpired(ctx context.Context, filter ExpiredFilter) ([]*Event, error)
+
+	// Delete removes events by ID and returns the number deleted
+	Delete(ctx context.Context, ids []string) (int64, error)
+
+	// Stats aggregates the events of every tenant within the filter's window
+	Stats(ctx context.Context, filter StatsFilter) (*Stats, error)
+}
+
+// StoreLogger implements Logger by writing events to a Repository
+type StoreLogger struct {
+	repo Repository
+}
+
+// NewStoreLogger creates an audit logger backed by a repository
+func NewStoreLogger(repo Repository) *StoreLogger {
+	return &StoreLogger{repo: repo}
+}
+
+// Log stores an audit event. Secrets in metadata are redacted before they are persisted.
+// Storage failures are reported through slog so the audited operation itself is not failed.
+func (l *StoreLogger) Log(ctx context.Context, event Event) {
+	event = prepare(ctx, event)
+	if err := l.repo.Create(ctx, &event); err != nil {
+		slog.ErrorContext(ctx, "failed to store audit event",
+			slog.String(AttrAuditType, event.Type),
+			slog.String(AttrTenantID, event.TenantID),
+			slog.String("error", err.Error()),
+			slog.String(AttrComponent, "audit"),
+		)
+	}
+}
+
+// MultiLogger fans each event out to several loggers
+type MultiLogger struct {
+	loggers []Logger
+}
+
+// NewMultiLogger creates a logger that writes to all of the given loggers
+func NewMultiLogger(loggers ...Logger) *MultiLogger {
+	return &MultiLogger{loggers: loggers}
+}
+
+// Log records the event with every logger.
+// The event is prepared once so every destination sees the same ID, timestamp and payload.
+func (l *MultiLogger) Log(ctx context.Context, event Event) {
+	event = prepare(ctx, event)
+	for _, logger := range l.loggers {
+		logger.Log(ctx, event)
+	}
+}
+
+// Prepare returns the event exactly as this package's loggers record it.
+// Loggers implemented outside the package call it so their copy matches the stored event.
+func Prepare(ctx context.Context, event Event) Event {
+	return prepare(ctx, event)
+}
+
+// prepare assigns a missing ID and timestamp, fills request details from the context, encodes the typed payload and returns a copy
+// of the event whose metadata has secrets redacted, ready to leave the process.
+// An event that fails schema validation is still recorded, as a legacy event, and the
+// failure is reported so the offending call site can be fixed.
+func prepare(ctx context.Context, event Event) Event {
+	if err := encodePayload(&event); err != nil {
+		slog.ErrorContext(ctx, "audit event does not match its schema",
+			slog.String(AttrAuditType, event.Type),
+			slog.String("error", err.Error()),
+			slog.String(AttrComponent, "audit"),
+		)
+	}
+	if event.ID == "" {
+		event.ID = id.NewUUIDv7()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if req, ok := requestFrom(ctx); ok {
+		if event.RequestID == "" {
+			event.RequestID = req.ID
+		}
+		if event.IPAddress == "" {
+			event.IPAddress = req.IPAddress
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = req.UserAgent
+		}
+	}
+	if len(event.Metadata) > 0 {
+		redacted := make(map[string]any, len(event.Metadata))
+		for k, v := range event.Metadata {
+			if isSecret(k) {
+				v = "[REDACTED]"
+			}
+			redacted[k] = v
+		}
+		event.Metadata = redacted
+	}
+	return event
+}
+
+// Service provides read access to stored audit events and manages their retention
+type Service struct {
+	repo                 Repository
+	retention            RetentionRepository
+	logger               Logger
+	defaultRetentionDays int
+}
+
+// NewService creates a new audit service.
+// defaultRetentionDays is reported for tenants without a retention policy of their own.
+func NewService(repo Repository, retention RetentionRepository, logger Logger, defaultRetentionDays int) *Service {
+	return &Service{
+		repo:                 repo,
+		retention:            retention,
+		logger:               logger,
+		defaultRetentionDays: defaultRetentionDays,
+	}
+}
+
+// ListEvents returns a page of events and the cursor for the next page
+func (s *Service) ListEvents(ctx context.Context, filter Filter) ([]*Event, string, error) {
+	filter.Page = filter.Page.Normalize()
+	events, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return events, pagination.NextCursor(events, filter.Page, func(e *Event) string { return e.ID }), nil
+}
+
+// Stats aggregates the events of every tenant within the filter's window
+func (s *Service) Stats(ctx context.Context, filter StatsFilter) (*Stats, error) {
+	return s.repo.Stats(ctx, filter)
+}
@@ -16,9 +16,13 @@ package authz_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
 )
 
 // MockRoleRepository implements authz.RoleRepository for testing
@@ -57,8 +61,8 @@ func NewMockRoleRepository() *MockRoleRepository {
 	}
 }
 
-func (m *MockRoleRepository) Create(role *authz.Role) error { return nil }
-func (m *MockRoleRepository) GetByID(id string) (*authz.Role, error) {
+func (m *MockRoleRepository) Create(ctx context.Context, role *authz.Role) error { return nil }
+func (m *MockRoleRepository) GetByID(ctx context.Context, id string) (*authz.Role, error) {
 	for _, r := range m.roles {
 		if r.ID == id {
 			return r, nil
@@ -66,15 +70,17 @@ func (m *MockRoleRepository) GetByID(id string) (*authz.Role, error) {
 	}
 	return nil, authz.ErrRoleNotFound
 }
-func (m *MockRoleRepository) GetByName(name string, scope authz.Scope) (*authz.Role, error) {
+func (m *MockRoleRepository) GetByName(ctx context.Context, name string, scope authz.Scope) (*authz.Role, error) {
 	if r, ok := m.roles[name]; ok && r.Scope == scope {
 		return r, nil
 	}
 	return nil, authz.ErrRoleNotFound
 }
-func (m *MockRoleRepository) Update(role *authz.Role) error                  { return nil }
-func (m *MockRoleRepository) Delete(id string) error                         { return nil }
-func (m *MockRoleRepository) List(scope *authz.Scope) ([]*authz.Role, error) { return nil, nil }
+func (m *MockRoleRepository) Update(ctx context.Context, role *authz.Role) error { return nil }
+func (m *MockRoleRepository) Delete(ctx context.Context, id string) error        { return nil }
+func (m *MockRoleRepository) List(ctx context.Context, scope *authz.Scope) ([]*authz.Role, error) {
+	return nil, nil
+}
 
 // MockAssignmentRepository implements authz.AssignmentRepository for testing
 type MockAssignmentRepository struct {
@@ -87,14 +93,33 @@ func NewMockAssignmentRepository() *MockAssignmentRepository {
 	}
 }
 
-func (m *MockAssignmentRepository) Grant(a *authz.Assignment) error {
+func (m *MockAssignmentRepository) Grant(ctx context.Context, a *authz.Assignment) error {
 	m.assignments = append(m.assignments, a)
 	return nil
 }
-func (m *MockAssignmentRepository) Revoke(userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+func (m *MockAssignmentRepository) Revoke(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	m.assignments = slices.DeleteFunc(m.assignments, func(a *authz.Assignment) bool {
+		return a.UserID == userID && a.RoleID == roleID && a.Scope == scope
+	})
 	return nil
 }
-func (m *MockAssignmentRepository) ListForUser(userID string) ([]*authz.Assignment, error) {
+func (m *MockAssignmentRepository) RevokeUnlessLast(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	held, holders := false, 0
+	for _, a := range m.assignments {
+		if a.RoleID == roleID && a.Scope == scope {
+			holders++
+			held = held || a.UserID == userID
+		}
+	}
+	if !held {
+		return authz.ErrAssignmentNotFound
+	}
+	if holders == 1 {
+		return authz.ErrLastAssignment
+	}
+	return m.Revoke(ctx, userID, roleID, scope, scopeContextID)
+}
+func (m *MockAssignmentRepository) ListForUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Assignment, error) {
 	var result []*authz.Assignment
 	for _, a := range m.assignments {
 		if a.UserID == userID {
@@ -103,25 +128,55 @@ func (m *MockAssignmentRepository) ListForUser(userID string) ([]*authz.Assignme
 	}
 	return result, nil
 }
-func (m *MockAssignmentRepository) ListByRole(roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
-	return nil, nil
+func (m *MockAssignmentRepository) ListByRole(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
+	var userIDs []string
+	for _, a := range m.assignments {
+		if a.RoleID == roleID && a.Scope == scope {
+			userIDs = append(userIDs, a.UserID)
+		}
+	}
+	return userIDs, nil
 }
-func (m *MockAssignmentRepository) CheckExists(roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
+func (m *MockAssignmentRepository) CheckExists(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
 	return false, nil
 }
 
 // MockProjectRepository implements authz.ProjectRepository for testing
 type MockProjectRepository struct{}
 
-func (m *MockProjectRepository) Create(project *authz.Project) error           { return nil }
-func (m *MockProjectRepository) GetByID(id string) (*authz.Project, error)     { return nil, nil }
-func (m *MockProjectRepository) GetByName(name string) (*authz.Project, error) { return nil, nil }
-func (m *MockProjectRepository) Update(project *authz.Project) error           { return nil }
-func (m *MockProjectRepository) Delete(id string) error                        { return nil }
-func (m *MockProjectRepository) ListByOwner(ownerID string) ([]*authz.Project, error) {
+func (m *MockProjectRepository) Create(ctx context.Context, project *authz.Project) error { return nil }
+func (m *MockProjectRepository) GetByID(ctx context.Context, id string) (*authz.Project, error) {
+	return nil, nil
+}
+func (m *MockProjectRepository) GetByName(ctx context.Context, name string) (*authz.Project, error) {
+	return nil, nil
+}
+func (m *MockProjectRepository) Update(ctx context.Context, project *authz.Project) error { return nil }
+func (m *MockProjectRepository) Delete(ctx context.Context, id string) error              { return nil }
+func (m *MockProjectRepository) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*authz.Project, error) {
+	return nil, nil
+}
+func (m *MockProjectRepository) ListByUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Project, error) {
+	return nil, nil
+}
+func (m *MockProjectRepository) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*authz.Project, error) {
+	return nil, nil
+}
+func (m *MockProjectRepository) SetMember(ctx context.Context, member *authz.ProjectMember) error {
+	return nil
+}
+func (m *MockProjectRepository) RemoveMember(ctx context.Context, projectID, userID string) error {
+	return nil
+}
+func (m *MockProjectRepository) GetMember(ctx context.Context, projectID, userID string) (*authz.ProjectMember, error) {
+	return nil, authz.ErrProjectMemberNotFound
+}
+func (m *MockProjectRepository) ListMembers(ctx context.Context, projectID string) ([]*authz.ProjectMember, error) {
 	return nil, nil
 }
-func (m *MockProjectRepository) ListByUser(userID string) ([]*authz.Project, error) { return nil, nil }
+func (m *MockProjectRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return 0, nil
+}
 
 // TestPurpose: Validates that Platform Admin privileges are scoped to the platform and strictly completely isolated from Tenant Admin privileges where appropriate (and vice versa).
 // Scope: Unit Test
@@ -140,7 +195,7 @@ func TestAuthz_Scope_PlatformAdminVsTenantAdmin(t *testing.T) {
 	tenantID := "tenant-123"
 
 	// Setup: User A is platform admin
-	assignmentRepo.Grant(&authz.Assignment{
+	assignmentRepo.Grant(ctx, &authz.Assignment{
 		ID:             "assign-1",
 		UserID:         "user-platform-admin",
 		RoleID:         "role-platform-admin",
@@ -149,7 +204,7 @@ func TestAuthz_Scope_PlatformAdminVsTenantAdmin(t *testing.T) {
 	})
 
 	// Setup: User B is tenant admin
-	assignmentRepo.Grant(&authz.Assignment{
+	assignmentRepo.Grant(ctx, &authz.Assignment{
 		ID:             "assign-2",
 		UserID:         "user-tenant-admin",
 		RoleID:         "role-tenant-admin",
@@ -193,7 +248,7 @@ func TestAuthz_Scope_TenantAdminVsTenantMember(t *testing.T) {
 	tenantID := "tenant-123"
 
 	// Setup: User A is tenant admin
-	assignmentRepo.Grant(&authz.Assignment{
+	assignmentRepo.Grant(ctx, &authz.Assignment{
 		ID:             "assign-1",
 		UserID:         "user-tenant-admin",
 		RoleID:         "role-tenant-admin",
@@ -202,7 +257,7 @@ func TestAuthz_Scope_TenantAdminVsTenantMember(t *testing.T) {
 	})
 
 	// Setup: User B is tenant member
-	assignmentRepo.Grant(&authz.Assignment{
+	assignmentRepo.Grant(ctx, &authz.Assignment{
 		ID:             "assign-2",
 		UserID:         "user-tenant-member",
 		RoleID:         "role-tenant-member",
@@ -257,7 +312,7 @@ func TestAuthz_Isolation_CrossTenantAccessDenied(t *testing.T) {
 	tenantB := "tenant-B"
 
 	// Setup: User is admin of Tenant A only
-	assignmentRepo.Grant(&authz.Assignment{
+	assignmentRepo.Grant(ctx, &authz.Assignment{
 		ID:             "assign-1",
 		UserID:         "user-admin-A",
 		RoleID:         "role-tenant-admin",
@@ -331,3 +386,86 @@ func TestAuthz_Role_HasPermission(t *testing.T) {
 		})
 	}
 }
+
+// TestPurpose: Validates that an access token limits its user's permissions to those mapped from its scopes.
+// Scope: Unit Test
+// Security: Least privilege for token-based API access (a token never exceeds its user's roles or its scopes)
+// Permissions: tenant:manage_clients, tenant:manage_users
+// Expected: A permission is allowed only when both the role and the token grant it; "*" defers to the role.
+// Test Case ID: AUT-07
+func TestAuthz_TokenPermissions(t *testing.T) {
+	assignmentRepo := NewMockAssignmentRepository()
+	svc := authz.NewService(&MockProjectRepository{}, NewMockRoleRepository(), assignmentRepo)
+	ctx := context.Background()
+	tenantID := "tenant-123"
+	assignmentRepo.Grant(ctx, &authz.Assignment{
+		ID:             "assign-1",
+		UserID:         "user-tenant-member",
+		RoleID:         "role-tenant-member",
+		Scope:          authz.ScopeTenant,
+		ScopeContextID: &tenantID,
+	})
+
+	mapping := authz.ScopePermissions{
+		"admin:clients": {authz.PermTenantManageClients, authz.PermTenantView},
+		"admin:read":    {authz.PermTenantView},
+		"admin:all":     {"*"},
+	}
+	if got := mapping.Permissions("openid admin:clients admin:read"); len(got) != 2 {
+		t.Errorf("expected two distinct permissions, got %v", got)
+	}
+
+	tokenCtx := authz.WithTokenPermissions(ctx, mapping.Permissions("admin:clients"))
+	if allowed, _ := svc.HasPermission(tokenCtx, "user-tenant-member", authz.ScopeTenant, &tenantID, authz.PermTenantView); !allowed {
+		t.Error("expected a permission granted by both the role and the token")
+	}
+	if allowed, _ := svc.HasPermission(tokenCtx, "user-tenant-member", authz.ScopeTenant, &tenantID, authz.PermTenantManageClients); allowed {
+		t.Error("a token must not exceed its user's roles")
+	}
+	if allowed, _ := svc.HasPermission(tokenCtx, "user-tenant-member", authz.ScopeTenant, &tenantID, authz.PermTenantViewUsers); allowed {
+		t.Error("a token must not exceed its scopes")
+	}
+	if allowed, _ := svc.HasPermission(authz.WithTokenPermissions(ctx, nil), "user-tenant-member", authz.ScopeTenant, &tenantID, authz.PermTenantView); allowed {
+		t.Error("a token without mapped scopes grants nothing")
+	}
+	allCtx := authz.WithTokenPermissions(ctx, mapping.Permissions("admin:all"))
+	if allowed, _ := svc.HasPermission(allCtx, "user-tenant-member", authz.ScopeTenant, &tenantID, authz.PermUserReadProfile); !allowed {
+		t.Error("expected \"*\" to defer to the user's roles")
+	}
+}
+
+// TestPurpose: Validates that the last platform admin cannot be revoked.
+// Scope: Unit Test
+// Security: Availability of platform administration (the platform must not be left without an administrator)
+// Expected: Revoking the only platform admin, including self-demotion, is ErrLastPlatformAdmin, and IsLastPlatformAdmin reports them; a user without the role is ErrAssignmentNotFound; with two admins one may be revoked.
+// Test Case ID: AUT-08
+func TestAuthz_RevokePlatformAdmin(t *testing.T) {
+	assignmentRepo := NewMockAssignmentRepository()
+	svc := authz.NewService(&MockProjectRepository{}, NewMockRoleRepository(), assignmentRepo)
+	ctx := context.Background()
+	assignmentRepo.Grant(ctx, &authz.Assignment{ID: "a1", UserID: "admin-1", RoleID: "role-platform-admin", Scope: authz.ScopePlatform})
+
+	if err := svc.RevokePlatformAdmin(ctx, "admin-1"); !errors.Is(err, authz.ErrLastPlatformAdmin) {
+		t.Errorf("expected ErrLastPlatformAdmin, got %v", err)
+	}
+	if last, err := svc.IsLastPlatformAdmin(ctx, "admin-1"); err != nil || !last {
+		t.Errorf("expected admin-1 to be the last platform admin, got %v, %v", last, err)
+	}
+	if last, _ := svc.IsLastPlatformAdmin(ctx, "user-1"); last {
+		t.Error("expected a user without the role not to be the last platform admin")
+	}
+	if err := svc.RevokePlatformAdmin(ctx, "user-1"); !errors.Is(err, authz.ErrAssignmentNotFound) {
+		t.Errorf("expected ErrAssignmentNotFound, got %v", err)
+	}
+
+	assignmentRepo.Grant(ctx, &authz.Assignment{ID: "a2", UserID: "admin-2", RoleID: "role-platform-admin", Scope: authz.ScopePlatform})
+	if last, _ := svc.IsLastPlatformAdmin(ctx, "admin-1"); last {
+		t.Error("expected admin-1 not to be the last of two platform admins")
+	}
+	if err := svc.RevokePlatformAdmin(ctx, "admin-1"); err != nil {
+		t.Fatalf("RevokePlatformAdmin: %v", err)
+	}
+	if err := svc.RevokePlatformAdmin(ctx, "admin-2"); !errors.Is(err, authz.ErrLastPlatformAdmin) {
+		t.Errorf("expected the remaining admin to be kept, got %v", err)
+	}
+}
@@ -15,21 +15,30 @@
 package authz
 
 import (
-	"errors"
+	"context"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/pagination"
 )
 
 // Domain errors
 var (
-	ErrProjectNotFound         = errors.New("project not found")
-	ErrProjectAlreadyExists    = errors.New("project already exists")
-	ErrAssignmentNotFound      = errors.New("assignment not found")
-	ErrAssignmentAlreadyExists = errors.New("assignment already exists")
-	ErrRoleNotFound            = errors.New("role not found")
-	ErrRoleAlreadyExists       = errors.New("role already exists")
-	ErrAccessDenied            = errors.New("access denied")
-	ErrInvalidPermission       = errors.New("invalid permission")
-	ErrInvalidScope            = errors.New("invalid scope")
+	ErrProjectNotFound         = apperr.New(apperr.CodeNotFound, "project not found")
+	ErrProjectAlreadyExists    = apperr.New(apperr.CodeAlreadyExists, "project already exists")
+	ErrInvalidProject          = apperr.New(apperr.CodeInvalidArgument, "invalid project")
+	ErrProjectMemberNotFound   = apperr.New(apperr.CodeNotFound, "project member not found")
+	ErrInvalidProjectRole      = apperr.New(apperr.CodeInvalidArgument, "invalid project role")
+	ErrLastProjectAdmin        = apperr.New(apperr.CodeConflict, "cannot remove the last project admin")
+	ErrAssignmentNotFound      = apperr.New(apperr.CodeNotFound, "assignment not found")
+	ErrAssignmentAlreadyExists = apperr.New(apperr.CodeAlreadyExists, "assignment already exists")
+	ErrRoleNotFound            = apperr.New(apperr.CodeNotFound, "role not found")
+	ErrRoleAlreadyExists       = apperr.New(apperr.CodeAlreadyExists, "role already exists")
+	ErrAccessDenied            = apperr.New(apperr.CodePermissionDenied, "access denied")
+	ErrInvalidPermission       = apperr.New(apperr.CodeInvalidArgument, "invalid permission")
+	ErrInvalidScope            = apperr.New(apperr.CodeInvalidArgument, "invalid scope")
+	ErrLastPlatformAdmin       = apperr.New(apperr.CodeConflict, "cannot revoke the last platform admin")
+	ErrLastAssignment          = apperr.New(apperr.CodeConflict, "cannot revoke the last assignment of the role")
 )
 
 // Scope defines the level at which a role is assigned
@@ -39,11 +48,14 @@ const (
 	ScopePlatform Scope = "platform"
 	ScopeTenant   Scope = "tenant"
 	ScopeClient   Scope = "client"
+	// ScopeProject is checked against project roles (see ProjectMember), not against assignments
+	ScopeProject Scope = "project"
 )
 
 // Project represents a project/resource that users can access
 type Project struct {
 	ID          string
+	TenantID    string
 	Name        string
 	Description string
 	OwnerID     string
@@ -52,6 +64,15 @@ type Project struct {
 	DeletedAt   *time.Time
 }
 
+// ProjectMember is a user's role in a project
+type ProjectMember struct {
+	ProjectID string
+	UserID    string
+	Role      string // RoleProjectAdmin or RoleProjectMember
+	GrantedAt time.Time
+	GrantedBy string
+}
+
 // Role represents a scoped role with associated permission names
 type Role struct {
 	ID          string
@@ -87,62 +108,86 @@ type Assignment struct {
 // AssignmentRepository defines the interface for RBAC assignments
 type AssignmentRepository interface {
 	// Grant assigns a role to a user
-	Grant(assignment *Assignment) error
+	Grant(ctx context.Context, assignment *Assignment) error
 
 	// Revoke removes a role assignment
-	Revoke(userID, roleID string, scope Scope, scopeContextID *string) error
+	Revoke(ctx context.Context, userID, roleID string, scope Scope, scopeContextID *string) error
 
-	// ListForUser retrieves all assignments for a user
-	ListForUser(userID string) ([]*Assignment, error)
+	// RevokeUnlessLast removes a role assignment unless it is the only one of the role at the scope,
+	// which returns ErrLastAssignment, or ErrAssignmentNotFound if the user lacks the role. The check
+	// and the removal are atomic, so concurrent revocations cannot remove every holder of the role.
+	RevokeUnlessLast(ctx context.Context, userID, roleID string, scope Scope, scopeContextID *string) error
+
+	// ListForUser retrieves a page of assignments for a user, ordered by ID
+	ListForUser(ctx context.Context, userID string, p pagination.Params) ([]*Assignment, error)
 
 	// ListByRole retrieves all users assigned a specific role at a scope
-	ListByRole(roleID string, scope Scope, scopeContextID *string) ([]string, error)
+	ListByRole(ctx context.Context, roleID string, scope Scope, scopeContextID *string) ([]string, error)
 
 	// CheckExists checks if a specific assignment exists
-	CheckExists(roleID string, scope Scope, scopeContextID *string) (bool, error)
+	CheckExists(ctx context.Context, roleID string, scope Scope, scopeContextID *string) (bool, error)
 }
 
 // ProjectRepository defines the interface for project persistence
 type ProjectRepository interface {
 	// Create creates a new project
-	Create(project *Project) error
+	Create(ctx context.Context, project *Project) error
 
 	// GetByID retrieves a project by ID
-	GetByID(id string) (*Project, error)
+	GetByID(ctx context.Context, id string) (*Project, error)
 
 	// GetByName retrieves a project by name
-	GetByName(name string) (*Project, error)
+	GetByName(ctx context.Context, name string) (*Project, error)
 
 	// Update updates project information
-	Update(project *Project) error
+	Update(ctx context.Context, project *Project) error
 
 	// Delete soft-deletes a project
-	Delete(id string) error
+	Delete(ctx context.Context, id string) error
+
+	// PurgeDeleted permanently removes up to limit projects soft-deleted before the given time,
+	// with their members
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
+
+	// ListByOwner retrieves a page of projects owned by a user, ordered by ID
+	ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*Project, error)
+
+	// ListByUser retrieves a page of projects a user has access to, ordered by ID
+	ListByUser(ctx context.Context, userID string, p pagination.Params) ([]*Project, error)
+
+	// ListByTenant retrieves a page of a tenant's projects, ordered by ID
+	ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*Project, error)
+
+	// SetMember gives a user a role in a project, replacing the role they had
+	SetMember(ctx context.Context, member *ProjectMember) error
+
+	// RemoveMember removes a user from a project
+	RemoveMember(ctx context.Context, projectID, userID string) error
 
-	// ListByOwner retrieves all projects owned by a user
-	ListByOwner(ownerID string) ([]*Project, error)
+	// GetMember retrieves a user's role in a project
+	GetMember(ctx context.Context, projectID, userID string) (*ProjectMember, error)
 
-	// ListByUser retrieves all projects a user has access to
-	ListByUser(userID string) ([]*Project, error)
+	// ListMembers retrieves the members of a project, oldest first
+	ListMembers(ctx context.Context, projectID string) ([]*ProjectMember, error)
 }
 
 // RoleRepository defines the interface for role persistence
 type RoleRepository interface {
 	// Create creates a new role
-	Create(role *Role) error
+	Create(ctx context.Context, role *Role) error
 
 	// GetByID retrieves a role by ID
-	GetByID(id string) (*Role, error)
+	GetByID(ctx context.Context, id string) (*Role, error)
 
 	// GetByName retrieves a role by name and scope
-	GetByName(name string, scope Scope) (*Role, error)
+	GetByName(ctx context.Context, name string, scope Scope) (*Role, error)
 
 	// Update updates role information
-	Update(role *Role) error
+	Update(ctx context.Context, role *Role) error
 
 	// Delete deletes a role
-	Delete(id string) error
+	Delete(ctx context.Context, id string) error
 
 	// List retrieves all roles, optionally filtered by scope
-	List(scope *Scope) ([]*Role, error)
+	List(ctx context.Context, scope *Scope) ([]*Role, error)
 }
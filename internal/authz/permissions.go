@@ -54,6 +54,26 @@ const (
 
 	// PermTenantViewAudit allows viewing tenant-scoped audit logs.
 	PermTenantViewAudit = "tenant:view_audit"
+
+	// PermTenantManageProjects allows creating projects and grants every project permission
+	// in the tenant's projects.
+	PermTenantManageProjects = "tenant:manage_projects"
+)
+
+// -----------------------------------------------------------------------------
+// Project Permissions
+// Granted by project roles rather than RBAC assignments (see ProjectRolePermissions).
+// -----------------------------------------------------------------------------
+
+const (
+	// PermProjectView allows viewing a project and its members.
+	PermProjectView = "project:view"
+
+	// PermProjectManage allows updating and deleting a project.
+	PermProjectManage = "project:manage"
+
+	// PermProjectManageMembers allows assigning and removing project roles.
+	PermProjectManageMembers = "project:manage_members"
 )
 
 // -----------------------------------------------------------------------------
@@ -104,6 +124,11 @@ var AllPermissions = []string{
 	PermTenantViewUsers,
 	PermTenantView,
 	PermTenantViewAudit,
+	PermTenantManageProjects,
+	// Project
+	PermProjectView,
+	PermProjectManage,
+	PermProjectManageMembers,
 	// User
 	PermUserReadProfile,
 	PermUserWriteProfile,
@@ -0,0 +1,249 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// maxProjectNameLength matches the projects.name column
+const maxProjectNameLength = 255
+
+// CreateProject creates a project in a tenant and makes its owner a project admin
+func (s *Service) CreateProject(ctx context.Context, tenantID, ownerID, name, description string) (*Project, error) {
+	name, err := validateProjectName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	project := &Project{
+		ID:          id.NewUUIDv7(),
+		TenantID:    tenantID,
+		Name:        name,
+		Description: description,
+		OwnerID:     ownerID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.projectRepo.Create(ctx, project); err != nil {
+		return nil, err
+	}
+	if err := s.projectRepo.SetMember(ctx, &ProjectMember{
+		ProjectID: project.ID,
+		UserID:    ownerID,
+		Role:      RoleProjectAdmin,
+		GrantedAt: now,
+		GrantedBy: ownerID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add project owner: %w", err)
+	}
+	return project, nil
+}
+
+// GetProject retrieves a project of a tenant; the projects of other tenants are not found
+func (s *Service) GetProject(ctx context.Context, tenantID, projectID string) (*Project, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.TenantID != tenantID {
+		return nil, ErrProjectNotFound
+	}
+	return project, nil
+}
+
+// ListProjects retrieves a page of a tenant's projects and the cursor of the next page
+func (s *Service) ListProjects(ctx context.Context, tenantID string, p pagination.Params) ([]*Project, string, error) {
+	p = p.Normalize()
+	projects, err := s.projectRepo.ListByTenant(ctx, tenantID, p)
+	if err != nil {
+		return nil, "", err
+	}
+	return projects, pagination.NextCursor(projects, p, func(p *Project) string { return p.ID }), nil
+}
+
+// ListMemberProjects retrieves the projects of a tenant that a user has a role in
+func (s *Service) ListMemberProjects(ctx context.Context, tenantID, userID string) ([]*Project, error) {
+	projects, err := s.GetUserProjects(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(projects, func(p *Project) bool { return p.TenantID != tenantID }), nil
+}
+
+// UpdateProject renames a project of a tenant and replaces its description
+func (s *Service) UpdateProject(ctx context.Context, tenantID, projectID, name, description string) (*Project, error) {
+	name, err := validateProjectName(name)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.GetProject(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	project.Name = name
+	project.Description = description
+	project.UpdatedAt = time.Now()
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// DeleteProject soft-deletes a project of a tenant
+func (s *Service) DeleteProject(ctx context.Context, tenantID, projectID string) error {
+	if _, err := s.GetProject(ctx, tenantID, projectID); err != nil {
+		return err
+	}
+	return s.projectRepo.Delete(ctx, projectID)
+}
+
+// ListProjectMembers retrieves the members of a project of a tenant
+func (s *Service) ListProjectMembers(ctx context.Context, tenantID, projectID string) ([]*ProjectMember, error) {
+	if _, err := s.GetProject(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.ListMembers(ctx, projectID)
+}
+
+// SetProjectMember gives a user a role in a project of a tenant, replacing the role they had.
+// The last project admin cannot be demoted, so every project keeps someone to manage it.
+func (s *Service) SetProjectMember(ctx context.Context, tenantID, projectID, userID, role, grantedBy string) (*ProjectMember, error) {
+	if _, ok := ProjectRolePermissions(role); !ok {
+		return nil, fmt.Errorf("%w: use %s or %s", ErrInvalidProjectRole, RoleProjectAdmin, RoleProjectMember)
+	}
+	if _, err := s.GetProject(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	if role != RoleProjectAdmin {
+		if err := s.checkLastProjectAdmin(ctx, projectID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &ProjectMember{
+		ProjectID: projectID,
+		UserID:    userID,
+		Role:      role,
+		GrantedAt: time.Now(),
+		GrantedBy: grantedBy,
+	}
+	if err := s.projectRepo.SetMember(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveProjectMember removes a user from a project of a tenant and returns the membership removed.
+// The last project admin cannot be removed.
+func (s *Service) RemoveProjectMember(ctx context.Context, tenantID, projectID, userID string) (*ProjectMember, error) {
+	if _, err := s.GetProject(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	member, err := s.projectRepo.GetMember(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLastProjectAdmin(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.projectRepo.RemoveMember(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// checkLastProjectAdmin returns ErrLastProjectAdmin when userID is the only admin of a project
+func (s *Service) checkLastProjectAdmin(ctx context.Context, projectID, userID string) error {
+	members, err := s.projectRepo.ListMembers(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	admins := 0
+	isAdmin := false
+	for _, m := range members {
+		if m.Role == RoleProjectAdmin {
+			admins++
+			isAdmin = isAdmin || m.UserID == userID
+		}
+	}
+	if isAdmin && admins == 1 {
+		return ErrLastProjectAdmin
+	}
+	return nil
+}
+
+// hasProjectPermission checks a permission in a project. Platform assignments apply as at every scope,
+// tenant assignments granting PermTenantManageProjects apply to every project of their tenant, and
+// otherwise the user's project role decides.
+func (s *Service) hasProjectPermission(ctx context.Context, userID string, assignments []*Assignment, projectID *string, permission string) (bool, error) {
+	if s.projectRepo == nil || projectID == nil {
+		return false, nil
+	}
+	project, err := s.projectRepo.GetByID(ctx, *projectID)
+	if errors.Is(err, ErrProjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	for _, a := range assignments {
+		required := permission
+		if a.Scope == ScopeTenant && a.ScopeContextID != nil && *a.ScopeContextID == project.TenantID {
+			required = PermTenantManageProjects
+		} else if a.Scope != ScopePlatform {
+			continue
+		}
+		role, err := s.roleRepo.GetByID(ctx, a.RoleID)
+		if err != nil {
+			continue
+		}
+		if role.HasPermission(required) {
+			return true, nil
+		}
+	}
+
+	member, err := s.projectRepo.GetMember(ctx, project.ID, userID)
+	if errors.Is(err, ErrProjectMemberNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get project member: %w", err)
+	}
+	permissions, _ := ProjectRolePermissions(member.Role)
+	return slices.Contains(permissions, permission), nil
+}
+
+// validateProjectName trims a project name and checks it is present and fits the column
+func validateProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidProject)
+	}
+	if len(name) > maxProjectNameLength {
+		return "", fmt.Errorf("%w: name must be at most %d bytes", ErrInvalidProject, maxProjectNameLength)
+	}
+	return name, nil
+}
@@ -36,6 +36,14 @@ const (
 	// RoleTenantMember is a basic tenant membership role.
 	// Scope: Tenant
 	RoleTenantMember = "tenant_member"
+
+	// RoleProjectAdmin manages a project and its members.
+	// Scope: Project
+	RoleProjectAdmin = "project_admin"
+
+	// RoleProjectMember can view a project.
+	// Scope: Project
+	RoleProjectMember = "project_member"
 )
 
 // -----------------------------------------------------------------------------
@@ -72,6 +80,7 @@ var TenantOwnerPermissions = []string{
 	PermTenantManageUsers,
 	PermTenantManageClients,
 	PermTenantManageSettings,
+	PermTenantManageProjects,
 	PermTenantViewUsers,
 	PermTenantView,
 	PermTenantViewAudit,
@@ -85,6 +94,7 @@ var TenantOwnerPermissions = []string{
 var TenantAdminPermissions = []string{
 	PermTenantManageUsers,
 	PermTenantManageClients,
+	PermTenantManageProjects,
 	PermTenantViewUsers,
 	PermTenantView,
 	PermUserReadProfile,
@@ -100,3 +110,27 @@ var TenantMemberPermissions = []string{
 	PermUserWriteProfile,
 	PermUserChangePassword,
 }
+
+// ProjectAdminPermissions defines permissions for the project_admin role.
+var ProjectAdminPermissions = []string{
+	PermProjectView,
+	PermProjectManage,
+	PermProjectManageMembers,
+}
+
+// ProjectMemberPermissions defines permissions for the project_member role.
+var ProjectMemberPermissions = []string{
+	PermProjectView,
+}
+
+// ProjectRolePermissions returns the permissions of a project role, and false for an unknown role.
+// Project roles are fixed, so unlike tenant roles they are not stored.
+func ProjectRolePermissions(role string) ([]string, bool) {
+	switch role {
+	case RoleProjectAdmin:
+		return ProjectAdminPermissions, true
+	case RoleProjectMember:
+		return ProjectMemberPermissions, true
+	}
+	return nil, false
+}
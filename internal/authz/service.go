@@ -16,7 +16,12 @@ package authz
 
 import (
 	"context"
+	"errors"
 	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/observability/tracing"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Service provides authorization business logic
@@ -41,14 +46,14 @@ func NewService(
 
 // GetUserRoles retrieves all unique role names for a user across all scopes
 func (s *Service) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
-	assignments, err := s.assignmentRepo.ListForUser(userID)
+	assignments, err := s.listAssignments(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user assignments: %w", err)
 	}
 
 	roleMap := make(map[string]bool)
 	for _, a := range assignments {
-		role, err := s.roleRepo.GetByID(a.RoleID)
+		role, err := s.roleRepo.GetByID(ctx, a.RoleID)
 		if err != nil {
 			continue
 		}
@@ -72,14 +77,14 @@ type UserRoleAssignment struct {
 
 // GetUserRoleAssignments retrieves all role assignments for a user with details
 func (s *Service) GetUserRoleAssignments(ctx context.Context, userID string) ([]UserRoleAssignment, error) {
-	assignments, err := s.assignmentRepo.ListForUser(userID)
+	assignments, err := s.listAssignments(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user assignments: %w", err)
 	}
 
 	var result []UserRoleAssignment
 	for _, a := range assignments {
-		role, err := s.roleRepo.GetByID(a.RoleID)
+		role, err := s.roleRepo.GetByID(ctx, a.RoleID)
 		if err != nil {
 			continue
 		}
@@ -95,7 +100,16 @@ func (s *Service) GetUserRoleAssignments(ctx context.Context, userID string) ([]
 
 // GetUserProjects retrieves all projects a user has access to (deprecated/legacy support)
 func (s *Service) GetUserProjects(ctx context.Context, userID string) ([]*Project, error) {
-	return s.projectRepo.ListByUser(userID)
+	return pagination.All(func(p pagination.Params) ([]*Project, error) {
+		return s.projectRepo.ListByUser(ctx, userID, p)
+	}, func(p *Project) string { return p.ID })
+}
+
+// listAssignments retrieves every assignment of a user; permission checks must see the full set
+func (s *Service) listAssignments(ctx context.Context, userID string) ([]*Assignment, error) {
+	return pagination.All(func(p pagination.Params) ([]*Assignment, error) {
+		return s.assignmentRepo.ListForUser(ctx, userID, p)
+	}, func(a *Assignment) string { return a.ID })
 }
 
 // ProjectInfo represents simplified project information for external systems
@@ -141,12 +155,61 @@ func (s *Service) BuildUserInfoClaims(ctx context.Context, userID string) (*User
 	}, nil
 }
 
-// HasPermission checks if a user has a specific permission at a scope
+// RevokePlatformAdmin revokes the platform admin role from a user.
+// The last platform admin cannot be revoked, including by themselves, so the platform always keeps an administrator.
+func (s *Service) RevokePlatformAdmin(ctx context.Context, userID string) error {
+	role, err := s.roleRepo.GetByName(ctx, RolePlatformAdmin, ScopePlatform)
+	if err != nil {
+		return err
+	}
+	err = s.assignmentRepo.RevokeUnlessLast(ctx, userID, role.ID, ScopePlatform, nil)
+	if errors.Is(err, ErrLastAssignment) {
+		return ErrLastPlatformAdmin
+	}
+	return err
+}
+
+// IsLastPlatformAdmin reports whether userID is the only platform admin
+func (s *Service) IsLastPlatformAdmin(ctx context.Context, userID string) (bool, error) {
+	role, err := s.roleRepo.GetByName(ctx, RolePlatformAdmin, ScopePlatform)
+	if errors.Is(err, ErrRoleNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	admins, err := s.assignmentRepo.ListByRole(ctx, role.ID, ScopePlatform, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(admins) == 1 && admins[0] == userID, nil
+}
+
+// HasPermission checks if a user has a specific permission at a scope. Under an access token
+// (see WithTokenPermissions) the token's scopes must also grant the permission.
 func (s *Service) HasPermission(ctx context.Context, userID string, scope Scope, scopeContextID *string, permission string) (bool, error) {
-	assignments, err := s.assignmentRepo.ListForUser(userID)
+	ctx, span := tracing.StartSpan(ctx, "authz.HasPermission",
+		attribute.String("scope", string(scope)),
+		attribute.String("permission", permission),
+	)
+	allowed, err := s.hasPermission(ctx, userID, scope, scopeContextID, permission)
+	span.SetAttributes(attribute.Bool("allowed", allowed))
+	tracing.EndSpan(span, err)
+	return allowed, err
+}
+
+func (s *Service) hasPermission(ctx context.Context, userID string, scope Scope, scopeContextID *string, permission string) (bool, error) {
+	if !tokenAllows(ctx, permission) {
+		return false, nil
+	}
+
+	assignments, err := s.listAssignments(ctx, userID)
 	if err != nil {
 		return false, fmt.Errorf("failed to get user assignments: %w", err)
 	}
+	if scope == ScopeProject {
+		return s.hasProjectPermission(ctx, userID, assignments, scopeContextID, permission)
+	}
 
 	for _, a := range assignments {
 		// Scope check: assignment scope must be same as requested, OR assignment is platform scope
@@ -169,7 +232,7 @@ func (s *Service) HasPermission(ctx context.Context, userID string, scope Scope,
 			continue
 		}
 
-		role, err := s.roleRepo.GetByID(a.RoleID)
+		role, err := s.roleRepo.GetByID(ctx, a.RoleID)
 		if err != nil {
 			continue
 		}
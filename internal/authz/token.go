@@ -0,0 +1,59 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// ScopePermissions maps OAuth2 scopes to the permissions that an access token carrying them may
+// exercise on OpenTrusty's own API. A scope may map to "*", which defers entirely to the user's roles.
+type ScopePermissions map[string][]string
+
+// Permissions returns the permissions granted by the space-separated scope, without duplicates
+func (m ScopePermissions) Permissions(scope string) []string {
+	var permissions []string
+	for _, s := range strings.Fields(scope) {
+		for _, p := range m[s] {
+			if !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	return permissions
+}
+
+type tokenPermissionsKey struct{}
+
+// WithTokenPermissions restricts the permission checks made with ctx to permissions. A request
+// authenticated with an access token may only do what both its user's roles and its scopes allow;
+// a session carries no restriction.
+func WithTokenPermissions(ctx context.Context, permissions []string) context.Context {
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return context.WithValue(ctx, tokenPermissionsKey{}, permissions)
+}
+
+// tokenAllows reports whether the access token of ctx, if any, was granted permission
+func tokenAllows(ctx context.Context, permission string) bool {
+	granted, ok := ctx.Value(tokenPermissionsKey{}).([]string)
+	if !ok {
+		return true
+	}
+	return slices.Contains(granted, "*") || slices.Contains(granted, permission)
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bus delivers events to every replica of a deployment, so that state a replica keeps in
+// memory, such as the client cache or the signing keys, follows changes made by another replica or
+// by the CLI. Events are hints to reload, not a durable log: a replica that misses some while its
+// connection is down is told to start over when it reconnects.
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// Topics
+const (
+	// TopicClientChanged is published when an OAuth2 client is updated or deleted; the payload is its internal ID
+	TopicClientChanged = "client.changed"
+	// TopicKeysChanged is published when the signing keys are rotated or retired
+	TopicKeysChanged = "keys.changed"
+)
+
+// retryDelay is how long Run waits before reconnecting a failed transport
+var retryDelay = 5 * time.Second
+
+// Transport carries messages between the replicas
+type Transport interface {
+	// Publish sends a message to every listening replica, including this one
+	Publish(ctx context.Context, message []byte) error
+
+	// Listen subscribes to the messages of every replica and passes them to deliver until ctx is
+	// done or the connection fails. connected is called once the subscription is in place.
+	Listen(ctx context.Context, connected func(), deliver func(message []byte)) error
+
+	// Close releases the transport's connections
+	Close() error
+}
+
+// Publisher publishes events; *Bus implements it
+type Publisher interface {
+	Publish(ctx context.Context, topic, payload string) error
+}
+
+// Handler handles the payload of an event
+type Handler func(ctx context.Context, payload string)
+
+// Bus publishes events to and dispatches events from the other replicas. Without a transport it
+// serves a single process and dispatches published events directly.
+type Bus struct {
+	transport Transport
+
+	mu        sync.RWMutex
+	handlers  map[string][]Handler
+	onConnect []func(ctx context.Context)
+}
+
+// message is the wire format of an event
+type message struct {
+	Topic   string `json:"topic"`
+	Payload string `json:"payload,omitempty"`
+}
+
+// New creates a bus on transport; nil creates a bus local to this process
+func New(transport Transport) *Bus {
+	return &Bus{transport: transport, handlers: make(map[string][]Handler)}
+}
+
+// Subscribe calls h for every event on topic
+func (b *Bus) Subscribe(topic string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = append(b.handlers[topic], h)
+}
+
+// OnConnect calls fn whenever Run (re)connects. Events published while the bus was disconnected
+// are lost, so fn should drop whatever state the subscriptions keep up to date.
+func (b *Bus) OnConnect(fn func(ctx context.Context)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onConnect = append(b.onConnect, fn)
+}
+
+// Publish sends an event on topic to every replica
+func (b *Bus) Publish(ctx context.Context, topic, payload string) error {
+	if b.transport == nil {
+		b.dispatch(ctx, topic, payload)
+		return nil
+	}
+	data, err := json.Marshal(message{Topic: topic, Payload: payload})
+	if err != nil {
+		return err
+	}
+	if err := b.transport.Publish(ctx, data); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", topic, err)
+	}
+	return nil
+}
+
+// Run dispatches the events of every replica to the subscriptions until ctx is done, reconnecting
+// after a lost connection. Without a transport it returns at once.
+func (b *Bus) Run(ctx context.Context) {
+	if b.transport == nil {
+		return
+	}
+	for {
+		err := b.transport.Listen(ctx, func() { b.connected(ctx) }, func(data []byte) {
+			var m message
+			if err := json.Unmarshal(data, &m); err != nil {
+				slog.WarnContext(ctx, "ignoring malformed bus message", logger.Component("bus"), logger.Error(err))
+				return
+			}
+			b.dispatch(ctx, m.Topic, m.Payload)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		slog.WarnContext(ctx, "bus disconnected", logger.Component("bus"), logger.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// Close releases the transport
+func (b *Bus) Close() error {
+	if b.transport == nil {
+		return nil
+	}
+	return b.transport.Close()
+}
+
+func (b *Bus) connected(ctx context.Context) {
+	b.mu.RLock()
+	onConnect := b.onConnect
+	b.mu.RUnlock()
+	for _, fn := range onConnect {
+		fn(ctx)
+	}
+}
+
+func (b *Bus) dispatch(ctx context.Context, topic, payload string) {
+	b.mu.RLock()
+	handlers := b.handlers[topic]
+	b.mu.RUnlock()
+	for _, h := range handlers {
+		h(ctx, payload)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTransport delivers queued messages on every Listen and then fails, forcing a reconnect
+type fakeTransport struct {
+	mu        sync.Mutex
+	published [][]byte
+	deliver   [][]byte
+	listens   int
+}
+
+func (f *fakeTransport) Publish(_ context.Context, message []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published = append(f.published, message)
+	return nil
+}
+
+func (f *fakeTransport) Listen(ctx context.Context, connected func(), deliver func([]byte)) error {
+	f.mu.Lock()
+	f.listens++
+	messages := f.deliver
+	f.mu.Unlock()
+	connected()
+	for _, m := range messages {
+		deliver(m)
+	}
+	return errors.New("connection lost")
+}
+
+func (f *fakeTransport) Close() error { return nil }
+
+// TestPurpose: Validates that events reach the subscriptions of their topic, locally and through a transport.
+// Scope: Unit Test
+// Security: Availability (a replica must not keep serving a changed client or a retired key after a lost connection)
+// Expected: Without a transport Publish dispatches directly; with one, events are encoded for the transport, delivered messages are dispatched by topic, malformed ones are skipped and OnConnect runs on every reconnect.
+// Test Case ID: BUS-01
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("local", func(t *testing.T) {
+		b := New(nil)
+		var got []string
+		b.Subscribe("a", func(_ context.Context, payload string) { got = append(got, payload) })
+		if err := b.Publish(ctx, "a", "1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Publish(ctx, "b", "2"); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0] != "1" {
+			t.Errorf("expected only the event on topic a, got %v", got)
+		}
+		b.Run(ctx) // returns at once
+	})
+
+	t.Run("transport", func(t *testing.T) {
+		defer func(d time.Duration) { retryDelay = d }(retryDelay)
+		retryDelay = time.Millisecond
+
+		transport := &fakeTransport{}
+		b := New(transport)
+		if err := b.Publish(ctx, "a", "1"); err != nil {
+			t.Fatal(err)
+		}
+		if len(transport.published) != 1 || string(transport.published[0]) != `{"topic":"a","payload":"1"}` {
+			t.Fatalf("unexpected message: %q", transport.published)
+		}
+		transport.deliver = append(transport.published, []byte("not json"), []byte(`{"topic":"b","payload":"2"}`))
+
+		var mu sync.Mutex
+		var got []string
+		connects := 0
+		b.Subscribe("a", func(_ context.Context, payload string) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, payload)
+		})
+		b.OnConnect(func(context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			connects++
+		})
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			b.Run(runCtx)
+			close(done)
+		}()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			n, events := connects, len(got)
+			mu.Unlock()
+			if n >= 2 && events >= 2 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("bus did not reconnect")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+		if got[0] != "1" || got[1] != "1" {
+			t.Errorf("expected the topic a event on every connection, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisChannel is the pub/sub channel the replicas share
+const redisChannel = "opentrusty:bus"
+
+// Redis is a Transport on Redis pub/sub, for deployments whose database has no LISTEN/NOTIFY or
+// that already run Redis
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis creates a transport for the Redis server at url, such as redis://:password@host:6379/0
+// or rediss:// for TLS
+func NewRedis(url string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	return &Redis{client: redis.NewClient(opts)}, nil
+}
+
+// Publish sends message on the shared channel
+func (r *Redis) Publish(ctx context.Context, message []byte) error {
+	return r.client.Publish(ctx, redisChannel, message).Err()
+}
+
+// Listen subscribes to the shared channel. The client resubscribes by itself after a dropped
+// connection; every new subscription is reported to connected.
+func (r *Redis) Listen(ctx context.Context, connected func(), deliver func(message []byte)) error {
+	sub := r.client.Subscribe(ctx, redisChannel)
+	defer sub.Close()
+	for {
+		received, err := sub.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		switch m := received.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				connected()
+			}
+		case *redis.Message:
+			deliver([]byte(m.Payload))
+		}
+	}
+}
+
+// Close closes the client's connections
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
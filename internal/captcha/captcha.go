@@ -0,0 +1,120 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package captcha requires bot-mitigation challenges (Cloudflare Turnstile, hCaptcha, Google
+// reCAPTCHA) on interactive endpoints, for every request of a tenant or only for client
+// addresses flagged for abuse.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Challenge providers selectable with CAPTCHA_PROVIDER
+const (
+	ProviderNone      = "none"
+	ProviderTurnstile = "turnstile"
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderReCAPTCHA = "recaptcha"
+)
+
+// siteverifyURLs are the verification endpoints of each provider; they share one protocol
+var siteverifyURLs = map[string]string{
+	ProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	ProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	ProviderReCAPTCHA: "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// ErrChallengeFailed is returned when the provider rejects a challenge response
+var ErrChallengeFailed = errors.New("challenge failed")
+
+// Verifier checks the response a client obtained by solving a challenge
+type Verifier interface {
+	// Verify returns ErrChallengeFailed when response is not a valid, unused solution, and
+	// another error when the provider could not be asked
+	Verify(ctx context.Context, response, remoteIP string) error
+}
+
+// NewVerifier returns the verifier of provider, or nil for none
+func NewVerifier(provider, secret string) (Verifier, error) {
+	switch provider {
+	case "", ProviderNone:
+		return nil, nil
+	case ProviderTurnstile, ProviderHCaptcha, ProviderReCAPTCHA:
+		return &SiteVerifier{
+			URL:    siteverifyURLs[provider],
+			Secret: secret,
+			Client: &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported captcha provider %q: use none, turnstile, hcaptcha or recaptcha", provider)
+	}
+}
+
+// SiteVerifier verifies responses with the siteverify protocol that Turnstile, hCaptcha and
+// reCAPTCHA (v2 and invisible) have in common
+type SiteVerifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// siteverifyResponse is the part of the provider's answer that decides the outcome
+type siteverifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify asks the provider whether response solves a challenge issued for the site
+func (v *SiteVerifier) Verify(ctx context.Context, response, remoteIP string) error {
+	if response == "" {
+		return ErrChallengeFailed
+	}
+	form := url.Values{"secret": {v.Secret}, "response": {response}}
+	// The address is a hint to the provider; it is only sent when it is a single address
+	if addr, err := netip.ParseAddr(remoteIP); err == nil {
+		form.Set("remoteip", addr.String())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha verification request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha verification returned status %d", resp.StatusCode)
+	}
+
+	var result siteverifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode captcha verification response: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrChallengeFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
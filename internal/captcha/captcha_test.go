@@ -0,0 +1,117 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package captcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestPurpose: Validates the siteverify exchange shared by Turnstile, hCaptcha and reCAPTCHA.
+// Scope: Unit Test
+// Security: Bot mitigation (a response only passes when the provider accepts it)
+// Expected: The secret, response and a single remote address are posted; a rejected response is ErrChallengeFailed and an unreachable provider is a different error.
+// Test Case ID: CAP-01
+func TestCaptcha_SiteVerifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("secret") != "s3cret" {
+			t.Errorf("expected the secret to be posted, got %q", r.PostFormValue("secret"))
+		}
+		if ip := r.PostFormValue("remoteip"); ip != "" && ip != "203.0.113.7" {
+			t.Errorf("unexpected remoteip %q", ip)
+		}
+		if r.PostFormValue("response") == "solved" {
+			w.Write([]byte(`{"success":true}`))
+			return
+		}
+		w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+	}))
+	defer srv.Close()
+
+	v := &SiteVerifier{URL: srv.URL, Secret: "s3cret", Client: srv.Client()}
+	ctx := context.Background()
+	if err := v.Verify(ctx, "solved", "203.0.113.7"); err != nil {
+		t.Errorf("expected a solved challenge to pass, got %v", err)
+	}
+	if err := v.Verify(ctx, "solved", "203.0.113.7, 10.0.0.1"); err != nil {
+		t.Errorf("expected an address list to be left out, got %v", err)
+	}
+	for _, response := range []string{"forged", ""} {
+		if err := v.Verify(ctx, response, "203.0.113.7"); !errors.Is(err, ErrChallengeFailed) {
+			t.Errorf("%q: expected ErrChallengeFailed, got %v", response, err)
+		}
+	}
+
+	srv.Close()
+	if err := v.Verify(ctx, "solved", ""); err == nil || errors.Is(err, ErrChallengeFailed) {
+		t.Errorf("expected an unreachable provider to be reported apart from a failed challenge, got %v", err)
+	}
+
+	if _, err := NewVerifier("captcha.example", "s3cret"); err == nil {
+		t.Error("expected an unknown provider to be rejected")
+	}
+	if v, err := NewVerifier(ProviderNone, ""); v != nil || err != nil {
+		t.Errorf("expected no verifier for none, got %v (%v)", v, err)
+	}
+}
+
+// TestPurpose: Validates when a challenge is required, per tenant and by the abuse flags of a client address.
+// Scope: Unit Test
+// Security: Bot mitigation (credential stuffing from one address is slowed once it is flagged)
+// Expected: always and off apply regardless of flags; abuse requires a challenge once the address is flagged, until the window passes.
+// Test Case ID: CAP-02
+func TestCaptcha_Challenge_Required(t *testing.T) {
+	now := time.Now()
+	flags := NewFlags(3, 15*time.Minute)
+	flags.now = func() time.Time { return now }
+	c := &Challenge{
+		Mode:        ModeAbuse,
+		TenantModes: map[string]string{"strict": ModeAlways, "kiosk": ModeOff},
+		Flags:       flags,
+	}
+
+	if c.Required("", "203.0.113.7") || !c.Required("strict", "203.0.113.7") {
+		t.Error("expected only the always tenant to require a challenge from an unflagged address")
+	}
+	flags.Record("203.0.113.7")
+	flags.Record("203.0.113.7")
+	if c.Required("acme", "203.0.113.7") {
+		t.Error("expected no challenge below the threshold")
+	}
+	flags.Record("203.0.113.7")
+	if !c.Required("acme", "203.0.113.7") || !c.Required("", "203.0.113.7") {
+		t.Error("expected a challenge once the address is flagged")
+	}
+	if c.Required("kiosk", "203.0.113.7") || c.Required("acme", "198.51.100.1") {
+		t.Error("expected off tenants and other addresses to be unaffected")
+	}
+
+	flags.Flag("198.51.100.1")
+	if !c.Required("acme", "198.51.100.1") {
+		t.Error("expected Flag to flag immediately")
+	}
+	now = now.Add(16 * time.Minute)
+	if c.Required("acme", "203.0.113.7") || c.Required("acme", "198.51.100.1") {
+		t.Error("expected flags to expire after the window")
+	}
+	flags.Record("203.0.113.7")
+	if len(flags.entries) != 1 {
+		t.Errorf("expected expired entries to be pruned, got %d", len(flags.entries))
+	}
+}
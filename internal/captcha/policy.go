@@ -0,0 +1,160 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package captcha
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Modes decide when a challenge is required
+const (
+	ModeOff    = "off"    // Never
+	ModeAbuse  = "abuse"  // When the client address is flagged for abuse
+	ModeAlways = "always" // On every attempt
+)
+
+// ValidMode reports whether mode is off, abuse or always
+func ValidMode(mode string) bool {
+	return mode == ModeOff || mode == ModeAbuse || mode == ModeAlways
+}
+
+// Challenge decides when a challenge is required and verifies the responses
+type Challenge struct {
+	// Provider and SiteKey are handed to clients so they can render the widget
+	Provider string
+	SiteKey  string
+	Verifier Verifier
+	// Mode applies to tenants without an entry in TenantModes, and to requests whose tenant is unknown
+	Mode        string
+	TenantModes map[string]string
+	Flags       *Flags
+}
+
+// PerTenant reports whether any tenant has its own mode, so the tenant of a request must be resolved
+func (c *Challenge) PerTenant() bool {
+	return len(c.TenantModes) > 0
+}
+
+// Required reports whether a request of the tenant ("" when unknown) from remoteIP must carry a
+// solved challenge
+func (c *Challenge) Required(tenantID, remoteIP string) bool {
+	mode, ok := c.TenantModes[tenantID]
+	if !ok || tenantID == "" {
+		mode = c.Mode
+	}
+	switch mode {
+	case ModeAlways:
+		return true
+	case ModeAbuse:
+		return c.Flags.Flagged(remoteIP)
+	}
+	return false
+}
+
+// Verify checks a challenge response; a failed challenge counts against remoteIP
+func (c *Challenge) Verify(ctx context.Context, response, remoteIP string) error {
+	err := c.Verifier.Verify(ctx, response, remoteIP)
+	if errors.Is(err, ErrChallengeFailed) {
+		c.Flags.Record(remoteIP)
+	}
+	return err
+}
+
+// Flags remembers the client addresses flagged for abuse. An address is flagged by a rate
+// limiter rejection or a risk signal (Flag), or after threshold suspicious events such as failed
+// logins within the window (Record). A flag lasts for the window. Flags are kept in memory per
+// instance, like the rate limiter buckets.
+type Flags struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Duration
+	entries   map[string]*flagEntry
+	pruned    time.Time
+	now       func() time.Time
+}
+
+type flagEntry struct {
+	count   int
+	expires time.Time
+}
+
+// NewFlags creates the flags of an instance; a threshold below 1 flags on the first event
+func NewFlags(threshold int, window time.Duration) *Flags {
+	return &Flags{
+		threshold: max(threshold, 1),
+		window:    window,
+		entries:   make(map[string]*flagEntry),
+		now:       time.Now,
+	}
+}
+
+// Record counts a suspicious event from key, flagging it once the threshold is reached
+func (f *Flags) Record(key string) {
+	f.add(key, 1)
+}
+
+// Flag flags key immediately
+func (f *Flags) Flag(key string) {
+	if f == nil {
+		return
+	}
+	f.add(key, f.threshold)
+}
+
+func (f *Flags) add(key string, n int) {
+	if f == nil || key == "" {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.prune(now)
+	e, ok := f.entries[key]
+	if !ok || !now.Before(e.expires) {
+		e = &flagEntry{}
+		f.entries[key] = e
+	}
+	e.count += n
+	e.expires = now.Add(f.window)
+}
+
+// Flagged reports whether key reached the threshold within the window
+func (f *Flags) Flagged(key string) bool {
+	if f == nil || key == "" {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	e, ok := f.entries[key]
+	return ok && e.count >= f.threshold && f.now().Before(e.expires)
+}
+
+// prune drops expired entries at most once per window, so drive-by addresses do not accumulate
+func (f *Flags) prune(now time.Time) {
+	if now.Sub(f.pruned) < f.window {
+		return
+	}
+	for key, e := range f.entries {
+		if !now.Before(e.expires) {
+			delete(f.entries, key)
+		}
+	}
+	f.pruned = now
+}
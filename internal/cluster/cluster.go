@@ -0,0 +1,241 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cluster reports whether a deployment can run more than one replica and whether its
+// replicas agree on the signing keys. Each replica announces its key fingerprint on the event
+// bus, so any one of them can list the others and spot a replica serving a different JWKS.
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/bus"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/jobs"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// TopicHeartbeat carries the announcement of one replica; the payload is a Replica as JSON
+const TopicHeartbeat = "replica.heartbeat"
+
+// missedHeartbeats is how many heartbeats a replica may miss before it is no longer listed
+const missedHeartbeats = 3
+
+// Check outcomes
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+)
+
+// Check is one item of the clustering readiness checklist
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // pass or warn
+	Detail string `json:"detail"`
+}
+
+// Setup is the part of the configuration that decides whether state is shared between replicas
+type Setup struct {
+	// SharedDatabase is set when the database is a server every replica connects to, not a local file
+	SharedDatabase bool
+	// BusTransport is "redis", "database" or "" for a bus local to the process
+	BusTransport string
+	// ReplayRedis is set when one-time identifiers are recorded in Redis instead of the database
+	ReplayRedis bool
+}
+
+// Checklist reports, for each piece of state a replica relies on, whether the other replicas see it
+func Checklist(s Setup) []Check {
+	shared := func(name, pass, warn string, ok bool) Check {
+		if ok {
+			return Check{Name: name, Status: CheckPass, Detail: pass}
+		}
+		return Check{Name: name, Status: CheckWarn, Detail: warn}
+	}
+	checks := []Check{
+		shared("signing_keys",
+			"signing keys are persisted in the shared database and reloaded by every replica",
+			"signing keys are stored in a database file local to this replica; other replicas publish a different JWKS",
+			s.SharedDatabase),
+		shared("sessions",
+			"sessions are stored in the shared database",
+			"sessions are stored in a database file local to this replica; a session is unknown to the other replicas",
+			s.SharedDatabase),
+		{
+			Name:   "rate_limits",
+			Status: CheckWarn,
+			Detail: "rate limits are counted in memory by each replica; behind a load balancer a client may reach the configured rate on every replica",
+		},
+	}
+	switch {
+	case s.ReplayRedis:
+		checks = append(checks, Check{Name: "replay_store", Status: CheckPass, Detail: "one-time identifiers are recorded in Redis"})
+	default:
+		checks = append(checks, shared("replay_store",
+			"one-time identifiers are recorded in the shared database",
+			"one-time identifiers are recorded in a database file local to this replica; a replayed jti is accepted by another replica",
+			s.SharedDatabase))
+	}
+	switch s.BusTransport {
+	case "redis":
+		checks = append(checks, Check{Name: "event_bus", Status: CheckPass, Detail: "client and key changes are published on Redis"})
+	case "database":
+		checks = append(checks, Check{Name: "event_bus", Status: CheckPass, Detail: "client and key changes are published with PostgreSQL LISTEN/NOTIFY"})
+	default:
+		checks = append(checks, Check{Name: "event_bus", Status: CheckWarn, Detail: "the event bus is local to this process; other replicas see client and key changes only when their caches expire"})
+	}
+	return checks
+}
+
+// Replica is the announcement of one replica
+type Replica struct {
+	ID string `json:"id"`
+	// SigningKeyID is the kid of the key signing new tokens; PublishedKeyIDs are the kids in its JWKS, sorted
+	SigningKeyID    string   `json:"signing_key_id"`
+	PublishedKeyIDs []string `json:"published_key_ids"`
+	// Fingerprint digests the signing and published kids; replicas serving the same keys have the same fingerprint
+	Fingerprint string    `json:"fingerprint"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// Report is the clustering state as seen by one replica
+type Report struct {
+	ReplicaID string `json:"replica_id"`
+	// Consistent is false when the listed replicas do not all have the same key fingerprint
+	Consistent bool      `json:"consistent"`
+	Checks     []Check   `json:"checks"`
+	Replicas   []Replica `json:"replicas"`
+}
+
+// KeyIDs returns the kid of the signing key and the kids of the published keys
+type KeyIDs func() (signing string, published []string)
+
+// Registry announces this replica and keeps the announcements of the others
+type Registry struct {
+	id       string
+	checks   []Check
+	keys     KeyIDs
+	bus      bus.Publisher
+	interval time.Duration
+
+	mu       sync.Mutex
+	replicas map[string]Replica
+}
+
+// NewRegistry creates the registry of this replica, announced every interval on b. The replica is
+// identified by its host name, which is the pod name on Kubernetes.
+func NewRegistry(b *bus.Bus, interval time.Duration, checks []Check, keys KeyIDs) *Registry {
+	r := &Registry{
+		id:       replicaID(),
+		checks:   checks,
+		keys:     keys,
+		bus:      b,
+		interval: interval,
+		replicas: make(map[string]Replica),
+	}
+	b.Subscribe(TopicHeartbeat, func(ctx context.Context, payload string) {
+		var replica Replica
+		if err := json.Unmarshal([]byte(payload), &replica); err != nil || replica.ID == "" {
+			slog.WarnContext(ctx, "ignoring malformed replica heartbeat", logger.Component("cluster"))
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.replicas[replica.ID] = replica
+	})
+	return r
+}
+
+// Job returns the heartbeat as a background job for a jobs.Runner
+func (r *Registry) Job() jobs.Job {
+	return jobs.Job{
+		Name:     "replica_heartbeat",
+		Interval: r.interval,
+		Run: func(ctx context.Context) (int64, error) {
+			return 1, r.Announce(ctx)
+		},
+	}
+}
+
+// Announce publishes the current key fingerprint of this replica
+func (r *Registry) Announce(ctx context.Context) error {
+	self := r.self(time.Now())
+	data, err := json.Marshal(self)
+	if err != nil {
+		return err
+	}
+	return r.bus.Publish(ctx, TopicHeartbeat, string(data))
+}
+
+// Report lists this replica, fresh, and the others heard from recently, ordered by ID
+func (r *Registry) Report(now time.Time) Report {
+	self := r.self(now)
+	replicas := []Replica{self}
+
+	r.mu.Lock()
+	for id, replica := range r.replicas {
+		if now.Sub(replica.LastSeen) > missedHeartbeats*r.interval {
+			delete(r.replicas, id)
+			continue
+		}
+		if id != self.ID {
+			replicas = append(replicas, replica)
+		}
+	}
+	r.mu.Unlock()
+
+	slices.SortFunc(replicas, func(a, b Replica) int { return strings.Compare(a.ID, b.ID) })
+	consistent := true
+	for _, replica := range replicas {
+		if replica.Fingerprint != self.Fingerprint {
+			consistent = false
+		}
+	}
+	return Report{ReplicaID: self.ID, Consistent: consistent, Checks: r.checks, Replicas: replicas}
+}
+
+func (r *Registry) self(now time.Time) Replica {
+	signing, published := r.keys()
+	published = slices.Sorted(slices.Values(published))
+	return Replica{
+		ID:              r.id,
+		SigningKeyID:    signing,
+		PublishedKeyIDs: published,
+		Fingerprint:     Fingerprint(signing, published),
+		LastSeen:        now,
+	}
+}
+
+// Fingerprint digests a signing kid and the sorted published kids
+func Fingerprint(signing string, published []string) string {
+	sum := sha256.Sum256([]byte(signing + "|" + strings.Join(published, ",")))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// replicaID is the host name, or a random ID when it cannot be read
+func replicaID() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return id.NewUUIDv7()
+}
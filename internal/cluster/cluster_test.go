@@ -0,0 +1,109 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/bus"
+)
+
+func statuses(checks []Check) map[string]string {
+	out := make(map[string]string, len(checks))
+	for _, c := range checks {
+		out[c.Name] = c.Status
+	}
+	return out
+}
+
+// TestPurpose: Validates the clustering readiness checklist.
+// Scope: Unit Test
+// Security: Token integrity and replay prevention (replicas must share signing keys and one-time identifiers)
+// Expected: A local database fails every shared-state check; PostgreSQL passes them; Redis passes the replay store and bus; rate limits always warn.
+// Test Case ID: CLU-01
+func TestChecklist(t *testing.T) {
+	local := statuses(Checklist(Setup{}))
+	for _, name := range []string{"signing_keys", "sessions", "rate_limits", "replay_store", "event_bus"} {
+		if local[name] != CheckWarn {
+			t.Errorf("expected %s to warn on a local database, got %q", name, local[name])
+		}
+	}
+
+	shared := statuses(Checklist(Setup{SharedDatabase: true, BusTransport: "database"}))
+	for _, name := range []string{"signing_keys", "sessions", "replay_store", "event_bus"} {
+		if shared[name] != CheckPass {
+			t.Errorf("expected %s to pass on PostgreSQL, got %q", name, shared[name])
+		}
+	}
+	if shared["rate_limits"] != CheckWarn {
+		t.Errorf("expected rate limits to warn, got %q", shared["rate_limits"])
+	}
+
+	redis := statuses(Checklist(Setup{BusTransport: "redis", ReplayRedis: true}))
+	if redis["replay_store"] != CheckPass || redis["event_bus"] != CheckPass {
+		t.Errorf("expected Redis to pass the replay store and bus, got %v", redis)
+	}
+}
+
+// TestPurpose: Validates that replicas announce their key fingerprints and that drift is reported.
+// Scope: Unit Test
+// Security: Token integrity (a replica serving a different JWKS makes tokens fail verification at random)
+// Expected: Each replica lists the other after the heartbeats; equal kids are consistent, a different signing kid is not; a replica that stops announcing is dropped.
+// Test Case ID: CLU-02
+func TestRegistry_Report(t *testing.T) {
+	b := bus.New(nil)
+	keysA := func() (string, []string) { return "k2", []string{"k2", "k1"} }
+	keysB := func() (string, []string) { return "k2", []string{"k1", "k2"} }
+	a := NewRegistry(b, time.Minute, Checklist(Setup{}), keysA)
+	a.id = "replica-a"
+	other := NewRegistry(b, time.Minute, nil, func() (string, []string) { return keysB() })
+	other.id = "replica-b"
+
+	ctx := context.Background()
+	if err := a.Announce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Announce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	report := a.Report(now)
+	if report.ReplicaID != "replica-a" || len(report.Replicas) != 2 || report.Replicas[1].ID != "replica-b" {
+		t.Fatalf("unexpected replicas %+v", report.Replicas)
+	}
+	if !report.Consistent || len(report.Checks) != 5 {
+		t.Errorf("expected the same keys in any order to be consistent, got %+v", report)
+	}
+
+	keysB = func() (string, []string) { return "k3", []string{"k3", "k2"} }
+	if err := other.Announce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	report = a.Report(now)
+	if report.Consistent {
+		t.Error("expected a different signing key to be reported as drift")
+	}
+	if fp := report.Replicas[1].Fingerprint; fp != Fingerprint("k3", []string{"k2", "k3"}) || fp == report.Replicas[0].Fingerprint {
+		t.Errorf("unexpected fingerprint %q", fp)
+	}
+
+	report = a.Report(now.Add(4 * time.Minute))
+	if len(report.Replicas) != 1 || !report.Consistent {
+		t.Errorf("expected a silent replica to be dropped, got %+v", report.Replicas)
+	}
+}
@@ -15,48 +15,416 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/captcha"
+	"github.com/opentrusty/opentrusty/internal/observability/otlp"
+	"github.com/opentrusty/opentrusty/internal/secrets"
+	"github.com/opentrusty/opentrusty/internal/startup"
+)
+
+// secretResolveTimeout bounds each lookup of a secret reference in an external secret manager
+const secretResolveTimeout = 30 * time.Second
+
+// Deployment environments selectable with ENVIRONMENT
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentProduction  = "production"
+)
+
+// PostgreSQL authentication methods selectable with DB_AUTH
+const (
+	DBAuthPassword = "password"
+	DBAuthAWSIAM   = "aws-iam"
+	DBAuthGCPIAM   = "gcp-iam"
 )
 
 // Config holds all application configuration
 type Config struct {
+	// Environment is development or production; production refuses insecure settings
+	Environment   string
 	Server        ServerConfig
+	TLS           TLSConfig
+	AdminIP       AdminIPConfig
 	Database      DatabaseConfig
 	Session       SessionConfig
 	Observability ObservabilityConfig
 	Security      SecurityConfig
 	RateLimit     RateLimitConfig
+	Captcha       CaptchaConfig
 	OAuth2        OAuth2Config
+	Email         EmailConfig
+	Janitor       JanitorConfig
+	Audit         AuditConfig
+	Webhook       WebhookConfig
+	Bus           BusConfig
+	Replay        ReplayConfig
+	Secrets       SecretsConfig
+	Startup       StartupConfig
+
+	settings []Setting
 }
 
-// RateLimitConfig holds rate limiting configuration
+// Setting is an environment variable as resolved by Load
+type Setting struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`             // Secrets are redacted
+	Default bool   `json:"default,omitempty"` // Not set in the environment
+}
+
+// Redacted replaces the value of secret settings
+const Redacted = "[redacted]"
+
+// Settings returns every environment variable read by Load with its effective value, in load order
+func (c *Config) Settings() []Setting {
+	return c.settings
+}
+
+// AuditConfig controls how audit events are dispatched, streamed to external sinks and retained
+type AuditConfig struct {
+	// Async logs events from a background queue instead of on the request path
+	Async             bool
+	QueueSize         int
+	QueueOverflow     string   // block, drop_newest or drop_oldest
+	Sinks             []string // Any of "kafka" and "nats"; empty disables streaming
+	SinkBufferSize    int
+	SinkBatchSize     int
+	SinkFlushInterval time.Duration
+	SinkOverflow      string // drop_newest, drop_oldest or block
+	KafkaBrokers      []string
+	KafkaTopic        string
+	KafkaFormat       string // json, ocsf or cef
+	NATSURL           string
+	NATSSubject       string
+	NATSFormat        string // json, ocsf or cef
+	// RetentionDays applies to tenants without a retention policy; zero keeps events forever
+	RetentionDays int
+	// Expired events are copied to this S3-compatible bucket before deletion; empty disables archiving
+	ArchiveBucket    string
+	ArchiveEndpoint  string
+	ArchiveRegion    string
+	ArchivePrefix    string
+	ArchiveAccessKey string
+	ArchiveSecretKey string
+}
+
+// SecretsConfig configures the external secret managers and the KMS.
+// Secret settings may hold a reference such as vault:secret/opentrusty#db_password
+// instead of the value, or be read from the file named by their _FILE variable.
+type SecretsConfig struct {
+	VaultAddr      string
+	VaultToken     string
+	VaultNamespace string
+	// AWS credentials for Secrets Manager and KMS
+	AWSRegion          string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	AWSEndpoint        string
+	// GCPCredentialsFile is a service account key; empty uses the instance metadata server
+	GCPCredentialsFile string
+	// KMSProvider envelope-encrypts stored signing keys: none, aws or gcp
+	KMSProvider string
+	KMSKeyID    string
+}
+
+// StartupConfig controls how long startup keeps retrying the database and the secret managers
+// when they are not reachable yet
+type StartupConfig struct {
+	// WaitForDeps retries until the dependencies are reachable, however long it takes
+	WaitForDeps bool
+	// RetryTimeout is how long to keep retrying otherwise; zero fails on the first error
+	RetryTimeout    time.Duration
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+}
+
+// Retry returns the retry policy for the startup dependencies
+func (c StartupConfig) Retry() startup.Policy {
+	return startup.Policy{
+		Timeout:    c.RetryTimeout,
+		Forever:    c.WaitForDeps,
+		Backoff:    c.RetryBackoff,
+		MaxBackoff: c.RetryMaxBackoff,
+	}
+}
+
+// AWS returns the AWS settings for the secrets package
+func (c SecretsConfig) AWS() secrets.AWSConfig {
+	return secrets.AWSConfig{
+		Region:          c.AWSRegion,
+		AccessKeyID:     c.AWSAccessKeyID,
+		SecretAccessKey: c.AWSSecretAccessKey,
+		SessionToken:    c.AWSSessionToken,
+		Endpoint:        c.AWSEndpoint,
+	}
+}
+
+// Resolver returns the resolver of secret references for the configured secret managers
+func (c SecretsConfig) Resolver() *secrets.Resolver {
+	return secrets.NewResolver(
+		secrets.FileProvider{},
+		secrets.NewVaultProvider(secrets.VaultConfig{
+			Addr:      c.VaultAddr,
+			Token:     c.VaultToken,
+			Namespace: c.VaultNamespace,
+		}),
+		secrets.NewAWSSecretsManagerProvider(c.AWS()),
+		secrets.NewGCPSecretManagerProvider(c.GCP()),
+	)
+}
+
+// GCP returns the Google Cloud settings for the secrets package
+func (c SecretsConfig) GCP() secrets.GCPConfig {
+	return secrets.GCPConfig{CredentialsFile: c.GCPCredentialsFile}
+}
+
+// KMS returns the configured KMS client, or nil when stored keys are encrypted with
+// OPENID_KEY_ENCRYPTION_KEY only
+func (c SecretsConfig) KMS() (secrets.KMS, error) {
+	return secrets.NewKMS(c.KMSProvider, c.KMSKeyID, c.AWS(), c.GCP())
+}
+
+// WebhookConfig controls outbound webhook delivery
+type WebhookConfig struct {
+	// Enabled runs the delivery dispatcher; subscriptions can be managed either way
+	Enabled        bool
+	PollInterval   time.Duration
+	BatchSize      int
+	Timeout        time.Duration
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// DeliveryRetention is how long succeeded and dead deliveries are kept; zero keeps them forever
+	DeliveryRetention time.Duration
+	// AllowInsecure permits http:// endpoints and private network addresses; for development only
+	AllowInsecure bool
+}
+
+// BusConfig selects how replicas tell each other about changes
+type BusConfig struct {
+	// RedisURL publishes events on Redis pub/sub; empty uses PostgreSQL LISTEN/NOTIFY, or stays in-process
+	// for the other databases
+	RedisURL string
+}
+
+// ReplayConfig selects where one-time identifiers (jti) are recorded
+type ReplayConfig struct {
+	// RedisURL records them in Redis with expiring keys; empty uses the database, purged by the janitor
+	RedisURL string
+}
+
+// JanitorConfig controls the background purge of expired and soft-deleted records
+type JanitorConfig struct {
+	Enabled   bool
+	Interval  time.Duration
+	Jitter    time.Duration
+	BatchSize int
+	// SoftDeleteRetention is how long soft-deleted rows are kept; zero keeps them forever
+	SoftDeleteRetention time.Duration
+}
+
+// EmailConfig holds the platform default outbound email sender.
+// Tenants without their own settings fall back to this sender.
+type EmailConfig struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTLSMode  string
+	FromAddress  string
+	FromName     string
+	// ChangeURL is the page the email change links point to, with the token and action (verify or
+	// revert) in the query; empty turns email changes off
+	ChangeURL string
+	// ChangeCoolingOff delays a verified email change so the old address can revert it; zero applies it at once
+	ChangeCoolingOff time.Duration
+	// TenantSMTPAllowedHosts are SMTP hosts tenants may configure although they are private or loopback addresses
+	TenantSMTPAllowedHosts []string
+}
+
+// RateLimitConfig holds rate limiting configuration.
+// Each layer has its own token bucket per key; a zero rate disables the layer.
 type RateLimitConfig struct {
-	RequestsPerSecond float64
+	RequestsPerSecond float64 // Per client IP, for every request
 	Burst             int
+	AdminRPS          float64 // Per client IP on the admin plane listener; defaults to RequestsPerSecond
+	AdminBurst        int
+	TenantRPS         float64 // Per tenant, for tenant-scoped admin routes and the token endpoint
+	TenantBurst       int
+	ClientRPS         float64 // Per OAuth2 client, for the token and revocation endpoints
+	ClientBurst       int
+	LoginRPS          float64 // Per user (submitted email), for login attempts
+	LoginBurst        int
+	// ClientAuthMaxFailures failed client authentications of a client_id, or from an address, are
+	// tolerated before it is banned for ClientAuthBackoff, doubling up to ClientAuthMaxBackoff; 0 disables bans
+	ClientAuthMaxFailures int
+	ClientAuthBackoff     time.Duration
+	ClientAuthMaxBackoff  time.Duration
+	// A tenant's security policy may lower the login and client rates down to these, but never raise them
+	TenantMinLoginRPS  float64
+	TenantMinClientRPS float64
+}
+
+// CaptchaConfig selects the bot-mitigation challenge that login may require
+type CaptchaConfig struct {
+	Provider  string // none, turnstile, hcaptcha or recaptcha
+	SiteKey   string
+	SecretKey string
+	// Mode (off, abuse or always) applies to tenants without a TenantModes entry
+	Mode string
+	// TenantModes entries are "tenantID=mode"
+	TenantModes []string
+	// FailureThreshold failed logins or challenges from a client address within FlagWindow flag
+	// it for abuse; a rate limiter rejection flags it at once
+	FailureThreshold int
+	FlagWindow       time.Duration
+}
+
+// Enabled reports whether a provider is configured
+func (c CaptchaConfig) Enabled() bool {
+	return c.Provider != "" && c.Provider != captcha.ProviderNone
+}
+
+// TenantModeMap returns the TenantModes entries by tenant
+func (c CaptchaConfig) TenantModeMap() map[string]string {
+	if len(c.TenantModes) == 0 {
+		return nil
+	}
+	modes := make(map[string]string, len(c.TenantModes))
+	for _, entry := range c.TenantModes {
+		tenantID, mode, _ := strings.Cut(entry, "=")
+		modes[tenantID] = mode
+	}
+	return modes
 }
 
 // OAuth2Config holds OAuth2/OIDC related configurations
 type OAuth2Config struct {
+	// Issuer is the public base URL of the auth plane; discovery, endpoints and the iss claim derive from it
+	Issuer               string
 	AuthCodeLifetime     time.Duration
 	AccessTokenLifetime  time.Duration
 	RefreshTokenLifetime time.Duration
 	IDTokenLifetime      time.Duration
+	// KeyReloadInterval is how often the signing keys are reloaded from the key store
+	KeyReloadInterval time.Duration
+	// KeyRotationGrace is how long `keys rotate` keeps publishing the previous signing key
+	KeyRotationGrace time.Duration
+	// ClientCacheTTL is how long a client looked up by client_id is cached; zero disables the cache
+	ClientCacheTTL time.Duration
+	// AdminScopes entries are "scope=permission": an access token carrying the scope may use the
+	// permission on the admin API, within its tenant. Empty refuses access tokens on the admin API.
+	AdminScopes []string
+	// ClaimsWebhookURL is called on every token issuance for custom claims; empty disables it
+	ClaimsWebhookURL string
+	// ClaimsWebhookSecret signs the calls to ClaimsWebhookURL like webhook deliveries
+	ClaimsWebhookSecret  string
+	ClaimsWebhookTimeout time.Duration
+	// ClaimsRequired fails issuance when the claims webhook does; otherwise tokens are issued
+	// without custom claims
+	ClaimsRequired bool
+	// LoginURL is the hosted login page an unauthenticated authorization request is redirected to,
+	// with the authorization_request to resume; empty answers such requests with 401
+	LoginURL string
+}
+
+// AdminScopePermissions groups AdminScopes entries by scope
+func (c OAuth2Config) AdminScopePermissions() map[string][]string {
+	if len(c.AdminScopes) == 0 {
+		return nil
+	}
+	permissions := make(map[string][]string)
+	for _, entry := range c.AdminScopes {
+		scope, permission, _ := strings.Cut(entry, "=")
+		permissions[scope] = append(permissions[scope], permission)
+	}
+	return permissions
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Host         string
-	Port         string
+	Host string
+	Port string
+	// AdminPort serves the admin plane on its own listener at AdminHost:AdminPort;
+	// empty serves both planes on Host:Port
+	AdminHost    string
+	AdminPort    string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// RequestTimeout bounds request handling; OAuth2Timeout the /oauth2/ endpoints and
+	// BulkTimeout long-running admin operations such as audit trail queries
+	RequestTimeout time.Duration
+	OAuth2Timeout  time.Duration
+	BulkTimeout    time.Duration
+	// ShutdownTimeout bounds a graceful shutdown: draining requests, stopping background jobs and
+	// delivering queued audit events
+	ShutdownTimeout time.Duration
+	// APIExplorer serves the interactive API explorer at /docs/
+	APIExplorer bool
+	// DevRP serves the test relying party at /dev/rp; refused in production
+	DevRP bool
+	// TrustedProxies are the CIDRs of reverse proxies whose X-Forwarded-For entries are believed
+	TrustedProxies []string
+	// DiagnosticsPort serves pprof and runtime metrics at DiagnosticsHost:DiagnosticsPort, which
+	// must be a loopback address; empty disables the listener
+	DiagnosticsHost string
+	DiagnosticsPort string
+}
+
+// AdminIPConfig restricts the client addresses that may use the admin plane (CIDRs or addresses)
+type AdminIPConfig struct {
+	Allow []string // Empty admits every address that is not denied
+	Deny  []string
+	// TenantAllow entries are "tenantID=CIDR"; a tenant listed here is reachable only from its CIDRs
+	TenantAllow []string
+}
+
+// TenantAllowlists groups TenantAllow entries by tenant
+func (c AdminIPConfig) TenantAllowlists() map[string][]string {
+	lists := make(map[string][]string)
+	for _, entry := range c.TenantAllow {
+		tenantID, cidr, _ := strings.Cut(entry, "=")
+		lists[tenantID] = append(lists[tenantID], cidr)
+	}
+	return lists
+}
+
+// TLSConfig enables HTTPS on every listener, from certificate files or ACME
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ReloadInterval is how often the certificate files are checked for changes; SIGHUP also reloads them
+	ReloadInterval   time.Duration
+	ACMEDomains      []string
+	ACMEEmail        string
+	ACMECacheDir     string
+	ACMEDirectoryURL string
+	MinVersion       string   // 1.2 or 1.3
+	CipherSuites     []string // TLS 1.2 suites; empty uses the Go defaults
+}
+
+// Enabled reports whether the servers should listen with TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || len(c.ACMEDomains) > 0
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
+	Driver          string // "postgres", "sqlite" or "memory"
+	Compat          string // "" or "cockroachdb"; adapts the postgres driver to a compatible database
+	SQLitePath      string
 	Host            string
 	Port            string
 	User            string
@@ -66,6 +434,18 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// Auth is how PostgreSQL connections authenticate: password, aws-iam or gcp-iam
+	Auth string
+	// PasswordRef is the secret reference DB_PASSWORD names; with PasswordRefresh it is resolved
+	// again at most that often for new connections, otherwise only at startup
+	PasswordRef     string
+	PasswordRefresh time.Duration
+	// SlowQueryThreshold logs PostgreSQL queries that take at least this long; zero disables the log
+	SlowQueryThreshold time.Duration
+	// PoolAcquireTimeout bounds the wait for a free PostgreSQL connection; once it elapses, queries
+	// fail fast with 503 for PoolBreakerCooldown while no connection is free. Zero disables both.
+	PoolAcquireTimeout  time.Duration
+	PoolBreakerCooldown time.Duration
 }
 
 // SessionConfig holds session management configuration
@@ -87,6 +467,23 @@ type ObservabilityConfig struct {
 	OTELEnabled    bool
 	ServiceName    string
 	ServiceVersion string
+	// OTLP collector for traces and metrics
+	OTLPEndpoint string
+	OTLPProtocol string
+	OTLPHeaders  map[string]string
+	// Fraction of new traces that are sampled
+	TraceSamplingRate     float64
+	ResourceAttributes    map[string]string
+	MetricsExportInterval time.Duration
+	// Distinct tenant and client label values on business metrics; further values are
+	// reported as "other" and 0 drops the label
+	MetricsMaxTenants int
+	MetricsMaxClients int
+}
+
+// OTLP returns the exporter settings for the observability packages
+func (c ObservabilityConfig) OTLP() otlp.Config {
+	return otlp.Config{Endpoint: c.OTLPEndpoint, Protocol: c.OTLPProtocol, Headers: c.OTLPHeaders}
 }
 
 // SecurityConfig holds security-related configuration
@@ -98,117 +495,795 @@ type SecurityConfig struct {
 	Argon2KeyLength    uint32
 	LockoutMaxAttempts int
 	LockoutDuration    time.Duration
+	// A tenant's security policy may lower the lockout threshold to TenantMinLockoutAttempts and
+	// lengthen the lockout to TenantMaxLockoutDuration, but never loosen either
+	TenantMinLockoutAttempts int
+	TenantMaxLockoutDuration time.Duration
+	// PasswordMaxAge is how long a password lasts before the user must change it at the next login;
+	// zero keeps passwords forever. A tenant may shorten it down to TenantMinPasswordMaxAge.
+	PasswordMaxAge          time.Duration
+	TenantMinPasswordMaxAge time.Duration
+	// AccountDeletionGrace delays the erasure of an account its user asked to delete; logging in
+	// before it ends keeps the account. Zero erases the account at once.
+	AccountDeletionGrace time.Duration
+	// KeyEncryptionKey encrypts signing keys, SMTP passwords and webhook secrets at rest (AES-256)
+	KeyEncryptionKey string
+	// SecretGuard is what happens to a JSON response carrying an undesignated secret: off, log or block
+	SecretGuard string
 }
 
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
+	cfg, errs := load(os.Getenv)
+	if err := errors.Join(append(errs, cfg.Validate())...); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Default returns the configuration Load would return with no variables set, without reading the
+// environment. It is not valid as is: at least the key encryption key and, for PostgreSQL, the
+// database password must be set.
+func Default() *Config {
+	cfg, _ := load(func(string) string { return "" })
+	return cfg
+}
+
+// load resolves the configuration with getenv, returning the values that could not be parsed as errors
+func load(getenv func(string) string) (*Config, []error) {
+	l := &loader{seen: make(map[string]bool), getenv: getenv, resolver: secrets.NewResolver(secrets.FileProvider{})}
+	// The retry policy comes first, as resolving secret references uses it
+	startupConfig := StartupConfig{
+		WaitForDeps:     l.parseBool("STARTUP_WAIT_FOR_DEPS", false),
+		RetryTimeout:    l.parseDuration("STARTUP_RETRY_TIMEOUT", "0s"),
+		RetryBackoff:    l.parseDuration("STARTUP_RETRY_BACKOFF", "1s"),
+		RetryMaxBackoff: l.parseDuration("STARTUP_RETRY_MAX_BACKOFF", "15s"),
+	}
+	l.retry = startupConfig.Retry()
+	// The secret managers are configured first, so the settings below can reference them.
+	// Their own credentials can only come from the environment or files.
+	secretsConfig := SecretsConfig{
+		VaultAddr:          l.getEnv("VAULT_ADDR", ""),
+		VaultToken:         l.secret("VAULT_TOKEN"),
+		VaultNamespace:     l.getEnv("VAULT_NAMESPACE", ""),
+		AWSRegion:          l.getEnv("AWS_REGION", ""),
+		AWSAccessKeyID:     l.getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey: l.secret("AWS_SECRET_ACCESS_KEY"),
+		AWSSessionToken:    l.secret("AWS_SESSION_TOKEN"),
+		AWSEndpoint:        l.getEnv("AWS_ENDPOINT_URL", ""),
+		GCPCredentialsFile: l.getEnv("GOOGLE_APPLICATION_CREDENTIALS", ""),
+		KMSProvider:        l.getEnv("KMS_PROVIDER", secrets.KMSProviderNone),
+		KMSKeyID:           l.getEnv("KMS_KEY_ID", ""),
+	}
+	l.resolver = secretsConfig.Resolver()
+
 	cfg := &Config{
+		Environment: l.getEnv("ENVIRONMENT", EnvironmentDevelopment),
 		Server: ServerConfig{
-			Host:         getEnv("SERVER_HOST", "0.0.0.0"),
-			Port:         getEnv("SERVER_PORT", "8080"),
-			ReadTimeout:  parseDuration("SERVER_READ_TIMEOUT", "15s"),
-			WriteTimeout: parseDuration("SERVER_WRITE_TIMEOUT", "15s"),
-			IdleTimeout:  parseDuration("SERVER_IDLE_TIMEOUT", "60s"),
+			Host:            l.getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:            l.getEnv("SERVER_PORT", "8080"),
+			AdminHost:       l.getEnv("SERVER_ADMIN_HOST", "127.0.0.1"),
+			AdminPort:       l.getEnv("SERVER_ADMIN_PORT", ""),
+			ReadTimeout:     l.parseDuration("SERVER_READ_TIMEOUT", "15s"),
+			WriteTimeout:    l.parseDuration("SERVER_WRITE_TIMEOUT", "15s"),
+			IdleTimeout:     l.parseDuration("SERVER_IDLE_TIMEOUT", "60s"),
+			RequestTimeout:  l.parseDuration("SERVER_REQUEST_TIMEOUT", "60s"),
+			OAuth2Timeout:   l.parseDuration("SERVER_OAUTH2_TIMEOUT", "10s"),
+			BulkTimeout:     l.parseDuration("SERVER_BULK_TIMEOUT", "2m"),
+			ShutdownTimeout: l.parseDuration("SERVER_SHUTDOWN_TIMEOUT", "30s"),
+			APIExplorer:     l.parseBool("SERVER_API_EXPLORER", false),
+			DevRP:           l.parseBool("SERVER_DEV_RP", false),
+			TrustedProxies:  l.parseList("SERVER_TRUSTED_PROXIES"),
+			DiagnosticsHost: l.getEnv("SERVER_DIAGNOSTICS_HOST", "127.0.0.1"),
+			DiagnosticsPort: l.getEnv("SERVER_DIAGNOSTICS_PORT", ""),
+		},
+		AdminIP: AdminIPConfig{
+			Allow:       l.parseList("ADMIN_IP_ALLOWLIST"),
+			Deny:        l.parseList("ADMIN_IP_DENYLIST"),
+			TenantAllow: l.parseList("ADMIN_IP_TENANT_ALLOWLIST"),
+		},
+		TLS: TLSConfig{
+			CertFile:         l.getEnv("TLS_CERT_FILE", ""),
+			KeyFile:          l.getEnv("TLS_KEY_FILE", ""),
+			ReloadInterval:   l.parseDuration("TLS_RELOAD_INTERVAL", "1m"),
+			ACMEDomains:      l.parseList("TLS_ACME_DOMAINS"),
+			ACMEEmail:        l.getEnv("TLS_ACME_EMAIL", ""),
+			ACMECacheDir:     l.getEnv("TLS_ACME_CACHE_DIR", "acme-cache"),
+			ACMEDirectoryURL: l.getEnv("TLS_ACME_DIRECTORY_URL", ""),
+			MinVersion:       l.getEnv("TLS_MIN_VERSION", "1.2"),
+			CipherSuites:     l.parseList("TLS_CIPHER_SUITES"),
 		},
 		Database: DatabaseConfig{
-			Host:            getEnv("DB_HOST", "localhost"),
-			Port:            getEnv("DB_PORT", "5432"),
-			User:            getEnv("DB_USER", "opentrusty"),
-			Password:        getEnv("DB_PASSWORD", ""),
-			Database:        getEnv("DB_NAME", "opentrusty"),
-			SSLMode:         getEnv("DB_SSLMODE", "disable"),
-			MaxOpenConns:    parseInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    parseInt("DB_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime: parseDuration("DB_CONN_MAX_LIFETIME", "5m"),
+			Driver:              l.getEnv("DB_DRIVER", "postgres"),
+			Compat:              l.getEnv("DB_COMPAT", ""),
+			SQLitePath:          l.getEnv("DB_SQLITE_PATH", "opentrusty.db"),
+			Host:                l.getEnv("DB_HOST", "localhost"),
+			Port:                l.getEnv("DB_PORT", "5432"),
+			User:                l.getEnv("DB_USER", "opentrusty"),
+			Password:            l.secret("DB_PASSWORD"),
+			Database:            l.getEnv("DB_NAME", "opentrusty"),
+			SSLMode:             l.getEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:        l.parseInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:        l.parseInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime:     l.parseDuration("DB_CONN_MAX_LIFETIME", "5m"),
+			Auth:                l.getEnv("DB_AUTH", DBAuthPassword),
+			PasswordRef:         l.secretReference("DB_PASSWORD"),
+			PasswordRefresh:     l.parseDuration("DB_PASSWORD_REFRESH", "0s"),
+			SlowQueryThreshold:  l.parseDuration("DB_SLOW_QUERY_THRESHOLD", "500ms"),
+			PoolAcquireTimeout:  l.parseDuration("DB_POOL_ACQUIRE_TIMEOUT", "2s"),
+			PoolBreakerCooldown: l.parseDuration("DB_POOL_BREAKER_COOLDOWN", "5s"),
 		},
 		Session: SessionConfig{
-			CookieName:     getEnv("SESSION_COOKIE_NAME", "opentrusty_session"),
-			CookieDomain:   getEnv("SESSION_COOKIE_DOMAIN", ""),
-			CookiePath:     getEnv("SESSION_COOKIE_PATH", "/"),
-			CookieSecure:   parseBool("SESSION_COOKIE_SECURE", true),
-			CookieHTTPOnly: parseBool("SESSION_COOKIE_HTTP_ONLY", true),
-			CookieSameSite: getEnv("SESSION_COOKIE_SAME_SITE", "Lax"),
-			Lifetime:       parseDuration("SESSION_LIFETIME", "24h"),
-			IdleTimeout:    parseDuration("SESSION_IDLE_TIMEOUT", "30m"),
+			CookieName:     l.getEnv("SESSION_COOKIE_NAME", "opentrusty_session"),
+			CookieDomain:   l.getEnv("SESSION_COOKIE_DOMAIN", ""),
+			CookiePath:     l.getEnv("SESSION_COOKIE_PATH", "/"),
+			CookieSecure:   l.parseBool("SESSION_COOKIE_SECURE", true),
+			CookieHTTPOnly: l.parseBool("SESSION_COOKIE_HTTP_ONLY", true),
+			CookieSameSite: l.getEnv("SESSION_COOKIE_SAME_SITE", "Lax"),
+			Lifetime:       l.parseDuration("SESSION_LIFETIME", "24h"),
+			IdleTimeout:    l.parseDuration("SESSION_IDLE_TIMEOUT", "30m"),
 		},
 		Observability: ObservabilityConfig{
-			LogLevel:       getEnv("LOG_LEVEL", "info"),
-			LogFormat:      getEnv("LOG_FORMAT", "json"),
-			OTELEnabled:    parseBool("OTEL_ENABLED", false),
-			ServiceName:    getEnv("OTEL_SERVICE_NAME", "opentrusty"),
-			ServiceVersion: getEnv("OTEL_SERVICE_VERSION", "0.1.0"),
+			LogLevel:       l.getEnv("LOG_LEVEL", "info"),
+			LogFormat:      l.getEnv("LOG_FORMAT", "json"),
+			OTELEnabled:    l.parseBool("OTEL_ENABLED", false),
+			ServiceName:    l.getEnv("OTEL_SERVICE_NAME", "opentrusty"),
+			ServiceVersion: l.getEnv("OTEL_SERVICE_VERSION", "0.1.0"),
+
+			OTLPEndpoint:          l.getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			OTLPProtocol:          l.getEnv("OTEL_EXPORTER_OTLP_PROTOCOL", otlp.ProtocolHTTP),
+			OTLPHeaders:           l.parsePairs("OTEL_EXPORTER_OTLP_HEADERS", l.secret("OTEL_EXPORTER_OTLP_HEADERS")),
+			TraceSamplingRate:     l.parseFloat("OTEL_TRACES_SAMPLER_ARG", 1.0),
+			ResourceAttributes:    l.parsePairs("OTEL_RESOURCE_ATTRIBUTES", l.getEnv("OTEL_RESOURCE_ATTRIBUTES", "")),
+			MetricsExportInterval: l.parseDuration("METRICS_EXPORT_INTERVAL", "1m"),
+
+			MetricsMaxTenants: l.parseInt("METRICS_MAX_TENANT_LABELS", 100),
+			MetricsMaxClients: l.parseInt("METRICS_MAX_CLIENT_LABELS", 100),
 		},
 		Security: SecurityConfig{
-			Argon2Memory:       uint32(parseInt("ARGON2_MEMORY", 65536)),
-			Argon2Iterations:   uint32(parseInt("ARGON2_ITERATIONS", 3)),
-			Argon2Parallelism:  uint8(parseInt("ARGON2_PARALLELISM", 4)),
-			Argon2SaltLength:   uint32(parseInt("ARGON2_SALT_LENGTH", 16)),
-			Argon2KeyLength:    uint32(parseInt("ARGON2_KEY_LENGTH", 32)),
-			LockoutMaxAttempts: parseInt("SECURITY_LOCKOUT_MAX_ATTEMPTS", 5),
-			LockoutDuration:    parseDuration("SECURITY_LOCKOUT_DURATION", "15m"),
+			Argon2Memory:             uint32(l.parseInt("ARGON2_MEMORY", 65536)),
+			Argon2Iterations:         uint32(l.parseInt("ARGON2_ITERATIONS", 3)),
+			Argon2Parallelism:        uint8(l.parseInt("ARGON2_PARALLELISM", 4)),
+			Argon2SaltLength:         uint32(l.parseInt("ARGON2_SALT_LENGTH", 16)),
+			Argon2KeyLength:          uint32(l.parseInt("ARGON2_KEY_LENGTH", 32)),
+			LockoutMaxAttempts:       l.parseInt("SECURITY_LOCKOUT_MAX_ATTEMPTS", 5),
+			LockoutDuration:          l.parseDuration("SECURITY_LOCKOUT_DURATION", "15m"),
+			TenantMinLockoutAttempts: l.parseInt("SECURITY_TENANT_MIN_LOCKOUT_ATTEMPTS", 3),
+			TenantMaxLockoutDuration: l.parseDuration("SECURITY_TENANT_MAX_LOCKOUT_DURATION", "24h"),
+			PasswordMaxAge:           l.parseDuration("SECURITY_PASSWORD_MAX_AGE", "0s"),
+			TenantMinPasswordMaxAge:  l.parseDuration("SECURITY_TENANT_MIN_PASSWORD_MAX_AGE", "24h"),
+			AccountDeletionGrace:     l.parseDuration("SECURITY_ACCOUNT_DELETION_GRACE", "336h"),
+			KeyEncryptionKey:         l.secret("OPENID_KEY_ENCRYPTION_KEY"),
+			SecretGuard:              l.getEnv("SECURITY_SECRET_GUARD", "block"),
 		},
 		RateLimit: RateLimitConfig{
-			RequestsPerSecond: float64(parseInt("RATELIMIT_RPS", 10)),
-			Burst:             parseInt("RATELIMIT_BURST", 20),
+			RequestsPerSecond: l.parseFloat("RATELIMIT_RPS", 10),
+			Burst:             l.parseInt("RATELIMIT_BURST", 20),
+			AdminRPS:          l.parseFloat("RATELIMIT_ADMIN_RPS", l.parseFloat("RATELIMIT_RPS", 10)),
+			AdminBurst:        l.parseInt("RATELIMIT_ADMIN_BURST", l.parseInt("RATELIMIT_BURST", 20)),
+			TenantRPS:         l.parseFloat("RATELIMIT_TENANT_RPS", 50),
+			TenantBurst:       l.parseInt("RATELIMIT_TENANT_BURST", 100),
+			ClientRPS:         l.parseFloat("RATELIMIT_CLIENT_RPS", 10),
+			ClientBurst:       l.parseInt("RATELIMIT_CLIENT_BURST", 20),
+			LoginRPS:          l.parseFloat("RATELIMIT_LOGIN_RPS", 0.1),
+			LoginBurst:        l.parseInt("RATELIMIT_LOGIN_BURST", 5),
+
+			ClientAuthMaxFailures: l.parseInt("RATELIMIT_CLIENT_AUTH_MAX_FAILURES", 5),
+			ClientAuthBackoff:     l.parseDuration("RATELIMIT_CLIENT_AUTH_BACKOFF", "1s"),
+			ClientAuthMaxBackoff:  l.parseDuration("RATELIMIT_CLIENT_AUTH_MAX_BACKOFF", "15m"),
+
+			TenantMinLoginRPS:  l.parseFloat("RATELIMIT_TENANT_MIN_LOGIN_RPS", 0.01),
+			TenantMinClientRPS: l.parseFloat("RATELIMIT_TENANT_MIN_CLIENT_RPS", 1),
+		},
+		Captcha: CaptchaConfig{
+			Provider:         l.getEnv("CAPTCHA_PROVIDER", captcha.ProviderNone),
+			SiteKey:          l.getEnv("CAPTCHA_SITE_KEY", ""),
+			SecretKey:        l.secret("CAPTCHA_SECRET_KEY"),
+			Mode:             l.getEnv("CAPTCHA_MODE", captcha.ModeAbuse),
+			TenantModes:      l.parseList("CAPTCHA_TENANT_MODES"),
+			FailureThreshold: l.parseInt("CAPTCHA_FAILURE_THRESHOLD", 5),
+			FlagWindow:       l.parseDuration("CAPTCHA_FLAG_WINDOW", "15m"),
 		},
 		OAuth2: OAuth2Config{
-			AuthCodeLifetime:     parseDuration("OAUTH2_AUTH_CODE_LIFETIME", "10m"),
-			AccessTokenLifetime:  parseDuration("OAUTH2_ACCESS_TOKEN_LIFETIME", "1h"),
-			RefreshTokenLifetime: parseDuration("OAUTH2_REFRESH_TOKEN_LIFETIME", "720h"), // 30 days
-			IDTokenLifetime:      parseDuration("OAUTH2_ID_TOKEN_LIFETIME", "1h"),
+			Issuer:               l.getEnv("OIDC_ISSUER", "http://localhost:"+l.getEnv("SERVER_PORT", "8080")),
+			AuthCodeLifetime:     l.parseDuration("OAUTH2_AUTH_CODE_LIFETIME", "10m"),
+			AccessTokenLifetime:  l.parseDuration("OAUTH2_ACCESS_TOKEN_LIFETIME", "1h"),
+			RefreshTokenLifetime: l.parseDuration("OAUTH2_REFRESH_TOKEN_LIFETIME", "720h"), // 30 days
+			IDTokenLifetime:      l.parseDuration("OAUTH2_ID_TOKEN_LIFETIME", "1h"),
+			KeyReloadInterval:    l.parseDuration("OAUTH2_KEY_RELOAD_INTERVAL", "1m"),
+			KeyRotationGrace:     l.parseDuration("OAUTH2_KEY_ROTATION_GRACE", "24h"),
+			ClientCacheTTL:       l.parseDuration("OAUTH2_CLIENT_CACHE_TTL", "30s"),
+			AdminScopes:          l.parseList("OAUTH2_ADMIN_SCOPE_PERMISSIONS"),
+			ClaimsWebhookURL:     l.getEnv("OAUTH2_CLAIMS_WEBHOOK_URL", ""),
+			ClaimsWebhookSecret:  l.secret("OAUTH2_CLAIMS_WEBHOOK_SECRET"),
+			ClaimsWebhookTimeout: l.parseDuration("OAUTH2_CLAIMS_WEBHOOK_TIMEOUT", "2s"),
+			ClaimsRequired:       l.parseBool("OAUTH2_CLAIMS_REQUIRED", false),
+			LoginURL:             l.getEnv("OAUTH2_LOGIN_URL", ""),
+		},
+		Email: EmailConfig{
+			SMTPHost:               l.getEnv("EMAIL_SMTP_HOST", ""),
+			SMTPPort:               l.parseInt("EMAIL_SMTP_PORT", 587),
+			SMTPUsername:           l.getEnv("EMAIL_SMTP_USERNAME", ""),
+			SMTPPassword:           l.secret("EMAIL_SMTP_PASSWORD"),
+			SMTPTLSMode:            l.getEnv("EMAIL_SMTP_TLS_MODE", "starttls"),
+			FromAddress:            l.getEnv("EMAIL_FROM_ADDRESS", "no-reply@opentrusty.local"),
+			FromName:               l.getEnv("EMAIL_FROM_NAME", "OpenTrusty"),
+			ChangeURL:              l.getEnv("EMAIL_CHANGE_URL", ""),
+			ChangeCoolingOff:       l.parseDuration("EMAIL_CHANGE_COOLING_OFF", "0s"),
+			TenantSMTPAllowedHosts: l.parseList("EMAIL_TENANT_SMTP_ALLOWED_HOSTS"),
+		},
+		Janitor: JanitorConfig{
+			Enabled:             l.parseBool("JANITOR_ENABLED", true),
+			Interval:            l.parseDuration("JANITOR_INTERVAL", "15m"),
+			Jitter:              l.parseDuration("JANITOR_JITTER", "1m"),
+			BatchSize:           l.parseInt("JANITOR_BATCH_SIZE", 1000),
+			SoftDeleteRetention: l.parseDuration("JANITOR_SOFT_DELETE_RETENTION", "720h"), // 30 days
+		},
+		Audit: AuditConfig{
+			Async:             l.parseBool("AUDIT_ASYNC", true),
+			QueueSize:         l.parseInt("AUDIT_QUEUE_SIZE", 10000),
+			QueueOverflow:     l.getEnv("AUDIT_QUEUE_OVERFLOW", "block"),
+			Sinks:             l.parseList("AUDIT_SINK"),
+			SinkBufferSize:    l.parseInt("AUDIT_SINK_BUFFER_SIZE", 10000),
+			SinkBatchSize:     l.parseInt("AUDIT_SINK_BATCH_SIZE", 100),
+			SinkFlushInterval: l.parseDuration("AUDIT_SINK_FLUSH_INTERVAL", "1s"),
+			SinkOverflow:      l.getEnv("AUDIT_SINK_OVERFLOW", "drop_newest"),
+			KafkaBrokers:      l.parseList("AUDIT_KAFKA_BROKERS"),
+			KafkaTopic:        l.getEnv("AUDIT_KAFKA_TOPIC", "opentrusty.audit"),
+			KafkaFormat:       l.getEnv("AUDIT_KAFKA_FORMAT", "json"),
+			NATSURL:           l.getEnv("AUDIT_NATS_URL", "nats://localhost:4222"),
+			NATSSubject:       l.getEnv("AUDIT_NATS_SUBJECT", "opentrusty.audit"),
+			NATSFormat:        l.getEnv("AUDIT_NATS_FORMAT", "json"),
+			RetentionDays:     l.parseInt("AUDIT_RETENTION_DAYS", 90),
+			ArchiveBucket:     l.getEnv("AUDIT_ARCHIVE_BUCKET", ""),
+			ArchiveEndpoint:   l.getEnv("AUDIT_ARCHIVE_ENDPOINT", "s3.amazonaws.com"),
+			ArchiveRegion:     l.getEnv("AUDIT_ARCHIVE_REGION", ""),
+			ArchivePrefix:     l.getEnv("AUDIT_ARCHIVE_PREFIX", "audit"),
+			ArchiveAccessKey:  l.getEnv("AUDIT_ARCHIVE_ACCESS_KEY", ""),
+			ArchiveSecretKey:  l.secret("AUDIT_ARCHIVE_SECRET_KEY"),
+		},
+		Webhook: WebhookConfig{
+			Enabled:           l.parseBool("WEBHOOK_ENABLED", true),
+			PollInterval:      l.parseDuration("WEBHOOK_POLL_INTERVAL", "5s"),
+			BatchSize:         l.parseInt("WEBHOOK_BATCH_SIZE", 50),
+			Timeout:           l.parseDuration("WEBHOOK_TIMEOUT", "10s"),
+			MaxAttempts:       l.parseInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			InitialBackoff:    l.parseDuration("WEBHOOK_INITIAL_BACKOFF", "30s"),
+			MaxBackoff:        l.parseDuration("WEBHOOK_MAX_BACKOFF", "1h"),
+			DeliveryRetention: l.parseDuration("WEBHOOK_DELIVERY_RETENTION", "720h"), // 30 days
+			AllowInsecure:     l.parseBool("WEBHOOK_ALLOW_INSECURE", false),
+		},
+		Bus: BusConfig{
+			RedisURL: l.secret("BUS_REDIS_URL"),
 		},
+		Replay: ReplayConfig{
+			RedisURL: l.secret("REPLAY_REDIS_URL"),
+		},
+		Secrets: secretsConfig,
+		Startup: startupConfig,
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
+	cfg.settings = l.settings
+	return cfg, l.errs
+}
 
-	return cfg, nil
+// Production reports whether ENVIRONMENT=production
+func (c *Config) Production() bool {
+	return c.Environment == EnvironmentProduction
 }
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.Database.Password == "" {
-		return fmt.Errorf("DB_PASSWORD is required")
+	var errs []error
+	switch c.Environment {
+	case EnvironmentDevelopment:
+	case EnvironmentProduction:
+		errs = append(errs, c.productionProblems()...)
+	default:
+		errs = append(errs, fmt.Errorf("unsupported ENVIRONMENT %q: use development or production", c.Environment))
+	}
+	switch c.Database.Driver {
+	case "postgres":
+		errs = append(errs, c.Database.authProblems(c.Secrets)...)
+		errs = append(errs, c.Database.poolProblems()...)
+	case "sqlite":
+		if c.Database.SQLitePath == "" {
+			errs = append(errs, fmt.Errorf("DB_SQLITE_PATH is required"))
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported DB_DRIVER %q", c.Database.Driver))
+	}
+	if url := c.Bus.RedisURL; url != "" && !strings.HasPrefix(url, "redis://") && !strings.HasPrefix(url, "rediss://") {
+		errs = append(errs, fmt.Errorf("BUS_REDIS_URL must be a redis:// or rediss:// URL"))
+	}
+	if url := c.Replay.RedisURL; url != "" && !strings.HasPrefix(url, "redis://") && !strings.HasPrefix(url, "rediss://") {
+		errs = append(errs, fmt.Errorf("REPLAY_REDIS_URL must be a redis:// or rediss:// URL"))
+	}
+	switch c.Database.Compat {
+	case "":
+	case "cockroachdb":
+		if c.Database.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("DB_COMPAT=cockroachdb requires DB_DRIVER=postgres"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported DB_COMPAT %q", c.Database.Compat))
+	}
+	if c.Server.RequestTimeout <= 0 || c.Server.OAuth2Timeout <= 0 || c.Server.BulkTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_REQUEST_TIMEOUT, SERVER_OAUTH2_TIMEOUT and SERVER_BULK_TIMEOUT must be positive"))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.Server.AdminPort != "" && c.Server.AdminHost == c.Server.Host && c.Server.AdminPort == c.Server.Port {
+		errs = append(errs, fmt.Errorf("SERVER_ADMIN_PORT must differ from SERVER_PORT"))
 	}
-	if os.Getenv("OPENID_KEY_ENCRYPTION_KEY") == "" {
-		return fmt.Errorf("OPENID_KEY_ENCRYPTION_KEY is required for OIDC support")
+	if c.Server.DiagnosticsPort != "" {
+		if !isLoopback(c.Server.DiagnosticsHost) {
+			errs = append(errs, fmt.Errorf("SERVER_DIAGNOSTICS_HOST must be a loopback address, not %q; profiles expose memory contents", c.Server.DiagnosticsHost))
+		}
+		if c.Server.DiagnosticsPort == c.Server.Port || c.Server.DiagnosticsPort == c.Server.AdminPort {
+			errs = append(errs, fmt.Errorf("SERVER_DIAGNOSTICS_PORT must differ from SERVER_PORT and SERVER_ADMIN_PORT"))
+		}
+	}
+	for _, entry := range c.AdminIP.TenantAllow {
+		if tenantID, cidr, ok := strings.Cut(entry, "="); !ok || tenantID == "" || cidr == "" {
+			errs = append(errs, fmt.Errorf("invalid ADMIN_IP_TENANT_ALLOWLIST entry %q: use tenantID=CIDR", entry))
+		}
+	}
+	if c.RateLimit.ClientAuthMaxFailures > 0 && (c.RateLimit.ClientAuthBackoff <= 0 || c.RateLimit.ClientAuthMaxBackoff < c.RateLimit.ClientAuthBackoff) {
+		errs = append(errs, fmt.Errorf("RATELIMIT_CLIENT_AUTH_BACKOFF must be positive and no longer than RATELIMIT_CLIENT_AUTH_MAX_BACKOFF"))
+	}
+	if c.Security.TenantMinLockoutAttempts < 1 || c.Security.TenantMaxLockoutDuration < 0 {
+		errs = append(errs, fmt.Errorf("SECURITY_TENANT_MIN_LOCKOUT_ATTEMPTS must be positive and SECURITY_TENANT_MAX_LOCKOUT_DURATION not negative"))
+	}
+	if c.Security.PasswordMaxAge < 0 || c.Security.TenantMinPasswordMaxAge <= 0 {
+		errs = append(errs, fmt.Errorf("SECURITY_PASSWORD_MAX_AGE must not be negative and SECURITY_TENANT_MIN_PASSWORD_MAX_AGE must be positive"))
+	}
+	if c.Security.AccountDeletionGrace < 0 {
+		errs = append(errs, fmt.Errorf("SECURITY_ACCOUNT_DELETION_GRACE must not be negative"))
+	}
+	if c.RateLimit.TenantMinLoginRPS <= 0 || c.RateLimit.TenantMinClientRPS <= 0 {
+		errs = append(errs, fmt.Errorf("RATELIMIT_TENANT_MIN_LOGIN_RPS and RATELIMIT_TENANT_MIN_CLIENT_RPS must be positive"))
+	}
+	switch c.Captcha.Provider {
+	case "", captcha.ProviderNone:
+	case captcha.ProviderTurnstile, captcha.ProviderHCaptcha, captcha.ProviderReCAPTCHA:
+		if c.Captcha.SiteKey == "" || c.Captcha.SecretKey == "" {
+			errs = append(errs, fmt.Errorf("CAPTCHA_SITE_KEY and CAPTCHA_SECRET_KEY are required with CAPTCHA_PROVIDER=%s", c.Captcha.Provider))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported CAPTCHA_PROVIDER %q: use none, turnstile, hcaptcha or recaptcha", c.Captcha.Provider))
+	}
+	if !captcha.ValidMode(c.Captcha.Mode) {
+		errs = append(errs, fmt.Errorf("unsupported CAPTCHA_MODE %q: use off, abuse or always", c.Captcha.Mode))
+	}
+	for _, entry := range c.Captcha.TenantModes {
+		if tenantID, mode, ok := strings.Cut(entry, "="); !ok || tenantID == "" || !captcha.ValidMode(mode) {
+			errs = append(errs, fmt.Errorf("invalid CAPTCHA_TENANT_MODES entry %q: use tenantID=off|abuse|always", entry))
+		}
+	}
+	for _, entry := range c.OAuth2.AdminScopes {
+		scope, permission, ok := strings.Cut(entry, "=")
+		if !ok || scope == "" || (permission != "*" && !slices.Contains(authz.AllPermissions, permission)) {
+			errs = append(errs, fmt.Errorf("invalid OAUTH2_ADMIN_SCOPE_PERMISSIONS entry %q: use scope=permission with a known permission or *", entry))
+		}
+	}
+	if c.TLS.Enabled() {
+		if len(c.TLS.ACMEDomains) > 0 && (c.TLS.CertFile != "" || c.TLS.KeyFile != "") {
+			errs = append(errs, fmt.Errorf("TLS_ACME_DOMAINS cannot be combined with TLS_CERT_FILE and TLS_KEY_FILE"))
+		}
+		if len(c.TLS.ACMEDomains) == 0 && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+			errs = append(errs, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+		}
+		if c.TLS.MinVersion != "1.2" && c.TLS.MinVersion != "1.3" {
+			errs = append(errs, fmt.Errorf("unsupported TLS_MIN_VERSION %q: use 1.2 or 1.3", c.TLS.MinVersion))
+		}
+	}
+	switch c.Observability.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported LOG_LEVEL %q: use debug, info, warn or error", c.Observability.LogLevel))
+	}
+	if c.Observability.LogFormat != "json" && c.Observability.LogFormat != "text" {
+		errs = append(errs, fmt.Errorf("unsupported LOG_FORMAT %q: use json or text", c.Observability.LogFormat))
+	}
+	if c.Security.Argon2Memory == 0 || c.Security.Argon2Iterations == 0 || c.Security.Argon2Parallelism == 0 ||
+		c.Security.Argon2SaltLength == 0 || c.Security.Argon2KeyLength == 0 {
+		errs = append(errs, fmt.Errorf("ARGON2_MEMORY, ARGON2_ITERATIONS, ARGON2_PARALLELISM, ARGON2_SALT_LENGTH and ARGON2_KEY_LENGTH must be positive"))
+	}
+	switch c.Security.SecretGuard {
+	case "off", "log", "block":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported SECURITY_SECRET_GUARD %q: use off, log or block", c.Security.SecretGuard))
+	}
+	if err := validateIssuer(c.OAuth2.Issuer); err != nil {
+		errs = append(errs, err)
+	}
+	if c.OAuth2.KeyReloadInterval <= 0 || c.OAuth2.KeyRotationGrace <= 0 {
+		errs = append(errs, fmt.Errorf("OAUTH2_KEY_RELOAD_INTERVAL and OAUTH2_KEY_ROTATION_GRACE must be positive"))
+	}
+	if hook := c.OAuth2.ClaimsWebhookURL; hook != "" {
+		if u, err := url.Parse(hook); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("invalid OAUTH2_CLAIMS_WEBHOOK_URL %q: must be an http or https URL", hook))
+		}
+		if c.OAuth2.ClaimsWebhookSecret == "" {
+			errs = append(errs, fmt.Errorf("OAUTH2_CLAIMS_WEBHOOK_SECRET is required with OAUTH2_CLAIMS_WEBHOOK_URL"))
+		}
+		if c.OAuth2.ClaimsWebhookTimeout <= 0 {
+			errs = append(errs, fmt.Errorf("OAUTH2_CLAIMS_WEBHOOK_TIMEOUT must be positive"))
+		}
+	}
+	if login := c.OAuth2.LoginURL; login != "" {
+		if u, err := url.Parse(login); err != nil || u.Fragment != "" || (u.Host == "" && !strings.HasPrefix(u.Path, "/")) || (u.Host != "" && u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("invalid OAUTH2_LOGIN_URL %q: must be an http or https URL or an absolute path", login))
+		}
+	}
+	if change := c.Email.ChangeURL; change != "" {
+		if u, err := url.Parse(change); err != nil || u.Fragment != "" || (u.Host == "" && !strings.HasPrefix(u.Path, "/")) || (u.Host != "" && u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("invalid EMAIL_CHANGE_URL %q: must be an http or https URL or an absolute path", change))
+		}
+	}
+	if c.Email.ChangeCoolingOff < 0 {
+		errs = append(errs, fmt.Errorf("EMAIL_CHANGE_COOLING_OFF must not be negative"))
+	}
+	if c.Startup.RetryTimeout < 0 || c.Startup.RetryBackoff <= 0 || c.Startup.RetryMaxBackoff < c.Startup.RetryBackoff {
+		errs = append(errs, fmt.Errorf("STARTUP_RETRY_TIMEOUT must not be negative, STARTUP_RETRY_BACKOFF must be positive and STARTUP_RETRY_MAX_BACKOFF at least STARTUP_RETRY_BACKOFF"))
+	}
+	if c.Janitor.Enabled && (c.Janitor.Interval <= 0 || c.Janitor.BatchSize <= 0) {
+		errs = append(errs, fmt.Errorf("JANITOR_INTERVAL and JANITOR_BATCH_SIZE must be positive"))
+	}
+	switch c.Observability.OTLPProtocol {
+	case otlp.ProtocolHTTP, otlp.ProtocolGRPC:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported OTEL_EXPORTER_OTLP_PROTOCOL %q: use %s or %s", c.Observability.OTLPProtocol, otlp.ProtocolHTTP, otlp.ProtocolGRPC))
+	}
+	if endpoint := c.Observability.OTLPEndpoint; endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_ENDPOINT %q: must be an http or https URL such as http://collector:4318", endpoint))
+		}
+	}
+	if rate := c.Observability.TraceSamplingRate; rate <= 0 || rate > 1 {
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG must be greater than 0 and at most 1"))
+	}
+	if c.Observability.MetricsExportInterval <= 0 {
+		errs = append(errs, fmt.Errorf("METRICS_EXPORT_INTERVAL must be positive"))
+	}
+	if c.Observability.MetricsMaxTenants < 0 || c.Observability.MetricsMaxClients < 0 {
+		errs = append(errs, fmt.Errorf("METRICS_MAX_TENANT_LABELS and METRICS_MAX_CLIENT_LABELS must not be negative"))
+	}
+	if c.Audit.RetentionDays < 0 {
+		errs = append(errs, fmt.Errorf("AUDIT_RETENTION_DAYS must not be negative"))
+	}
+	if c.Webhook.Enabled && (c.Webhook.PollInterval <= 0 || c.Webhook.BatchSize <= 0 || c.Webhook.Timeout <= 0 ||
+		c.Webhook.MaxAttempts <= 0 || c.Webhook.InitialBackoff <= 0 || c.Webhook.MaxBackoff < c.Webhook.InitialBackoff) {
+		errs = append(errs, fmt.Errorf("WEBHOOK_POLL_INTERVAL, WEBHOOK_BATCH_SIZE, WEBHOOK_TIMEOUT, WEBHOOK_MAX_ATTEMPTS and WEBHOOK_INITIAL_BACKOFF must be positive and WEBHOOK_MAX_BACKOFF at least WEBHOOK_INITIAL_BACKOFF"))
+	}
+	for _, sink := range c.Audit.Sinks {
+		switch sink {
+		case "nats":
+			if !validAuditFormat(c.Audit.NATSFormat) {
+				errs = append(errs, fmt.Errorf("unsupported AUDIT_NATS_FORMAT %q", c.Audit.NATSFormat))
+			}
+		case "kafka":
+			if len(c.Audit.KafkaBrokers) == 0 {
+				errs = append(errs, fmt.Errorf("AUDIT_KAFKA_BROKERS is required for the kafka audit sink"))
+			}
+			if !validAuditFormat(c.Audit.KafkaFormat) {
+				errs = append(errs, fmt.Errorf("unsupported AUDIT_KAFKA_FORMAT %q", c.Audit.KafkaFormat))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unsupported AUDIT_SINK %q", sink))
+		}
+	}
+	switch c.Secrets.KMSProvider {
+	case secrets.KMSProviderNone:
+	case secrets.KMSProviderAWS:
+		if c.Secrets.KMSKeyID == "" || c.Secrets.AWSRegion == "" {
+			errs = append(errs, fmt.Errorf("KMS_KEY_ID and AWS_REGION are required for KMS_PROVIDER=aws"))
+		}
+	case secrets.KMSProviderGCP:
+		if !strings.HasPrefix(c.Secrets.KMSKeyID, "projects/") {
+			errs = append(errs, fmt.Errorf("KMS_KEY_ID must be a Cloud KMS key name projects/.../cryptoKeys/... for KMS_PROVIDER=gcp"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported KMS_PROVIDER %q: use none, aws or gcp", c.Secrets.KMSProvider))
+	}
+	switch n := len(c.Security.KeyEncryptionKey); n {
+	case 32:
+	case 0:
+		errs = append(errs, fmt.Errorf("OPENID_KEY_ENCRYPTION_KEY is required; generate one with `opentrusty keys generate-encryption-key`"))
+	default:
+		errs = append(errs, fmt.Errorf("OPENID_KEY_ENCRYPTION_KEY must be exactly 32 bytes, got %d; generate one with `opentrusty keys generate-encryption-key`", n))
+	}
+	return errors.Join(errs...)
+}
+
+// authProblems lists the problems of the PostgreSQL authentication settings
+func (c DatabaseConfig) authProblems(s SecretsConfig) []error {
+	var errs []error
+	switch c.Auth {
+	case DBAuthPassword:
+		if c.Password == "" {
+			errs = append(errs, fmt.Errorf("DB_PASSWORD is required"))
+		}
+		if c.PasswordRefresh > 0 && c.PasswordRef == "" {
+			errs = append(errs, fmt.Errorf("DB_PASSWORD_REFRESH requires DB_PASSWORD to be a secret reference or DB_PASSWORD_FILE"))
+		}
+	case DBAuthAWSIAM:
+		if s.AWSRegion == "" || s.AWSAccessKeyID == "" || s.AWSSecretAccessKey == "" {
+			errs = append(errs, fmt.Errorf("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for DB_AUTH=aws-iam"))
+		}
+		if c.SSLMode == "disable" {
+			errs = append(errs, fmt.Errorf("DB_AUTH=aws-iam requires TLS: set DB_SSLMODE to require or verify-full"))
+		}
+	case DBAuthGCPIAM:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported DB_AUTH %q: use password, aws-iam or gcp-iam", c.Auth))
+	}
+	if c.Auth != DBAuthPassword && c.Password != "" {
+		errs = append(errs, fmt.Errorf("DB_PASSWORD must be empty with DB_AUTH=%s", c.Auth))
+	}
+	if c.PasswordRefresh < 0 {
+		errs = append(errs, fmt.Errorf("DB_PASSWORD_REFRESH must not be negative"))
+	}
+	return errs
+}
+
+// poolProblems lists the problems of the PostgreSQL connection pool settings
+func (c DatabaseConfig) poolProblems() []error {
+	var errs []error
+	if c.PoolAcquireTimeout < 0 {
+		errs = append(errs, fmt.Errorf("DB_POOL_ACQUIRE_TIMEOUT must not be negative"))
+	}
+	if c.PoolBreakerCooldown < 0 {
+		errs = append(errs, fmt.Errorf("DB_POOL_BREAKER_COOLDOWN must not be negative"))
+	}
+	return errs
+}
+
+// productionProblems lists the settings that are acceptable for development but insecure in production
+func (c *Config) productionProblems() []error {
+	var errs []error
+	if !c.TLS.Enabled() {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT=production requires TLS: set TLS_CERT_FILE and TLS_KEY_FILE, or TLS_ACME_DOMAINS"))
+	}
+	if !c.Session.CookieSecure {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT=production requires SESSION_COOKIE_SECURE=true"))
+	}
+	if c.Database.Driver == "memory" {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT=production does not allow DB_DRIVER=memory"))
+	}
+	if c.Webhook.AllowInsecure {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT=production does not allow WEBHOOK_ALLOW_INSECURE=true"))
+	}
+	if c.Server.DevRP {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT=production does not allow SERVER_DEV_RP=true"))
+	}
+	if u, err := url.Parse(c.OAuth2.Issuer); err == nil && (u.Scheme != "https" || isLoopback(u.Hostname())) {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT=production requires OIDC_ISSUER to be a public https URL, not %q", c.OAuth2.Issuer))
+	}
+	if strings.HasPrefix(c.OAuth2.ClaimsWebhookURL, "http://") {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT=production requires OAUTH2_CLAIMS_WEBHOOK_URL to be https"))
+	}
+	if len(c.Security.KeyEncryptionKey) == 32 && repeatsPattern(c.Security.KeyEncryptionKey) {
+		errs = append(errs, fmt.Errorf("OPENID_KEY_ENCRYPTION_KEY looks like a placeholder; generate one with `opentrusty keys generate-encryption-key`"))
+	}
+	return errs
+}
+
+// validateIssuer checks OIDC_ISSUER against OIDC Discovery Section 3: an absolute http(s) URL
+// without query or fragment. A trailing slash would be repeated in every derived endpoint.
+func validateIssuer(issuer string) error {
+	u, err := url.Parse(issuer)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid OIDC_ISSUER %q: must be an absolute https URL such as https://auth.example.com", issuer)
+	}
+	if u.RawQuery != "" || u.Fragment != "" || strings.HasSuffix(issuer, "?") || strings.HasSuffix(issuer, "#") {
+		return fmt.Errorf("invalid OIDC_ISSUER %q: must not have a query or fragment", issuer)
+	}
+	if strings.HasSuffix(u.Path, "/") {
+		return fmt.Errorf("invalid OIDC_ISSUER %q: must not end with /", issuer)
 	}
 	return nil
 }
 
-// Helper functions
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// isLoopback reports whether host is localhost or a loopback address
+func isLoopback(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// repeatsPattern reports whether s repeats a string at least twice, like the sample key
+// 0123456789abcdef0123456789abcdef; random keys practically never do
+func repeatsPattern(s string) bool {
+	for period := 1; period <= len(s)/2; period++ {
+		if s[period:] == s[:len(s)-period] {
+			return true
+		}
+	}
+	return false
+}
+
+// validAuditFormat reports whether f names an audit export format
+func validAuditFormat(f string) bool {
+	switch f {
+	case "json", "ocsf", "cef":
+		return true
+	}
+	return false
+}
+
+// loader reads environment variables, recording each effective value and every value that does not parse.
+// An empty variable counts as unset.
+type loader struct {
+	settings []Setting
+	seen     map[string]bool
+	errs     []error
+	getenv   func(string) string
+	resolver *secrets.Resolver
+	retry    startup.Policy
+}
+
+func (l *loader) record(key, value string, set bool) {
+	if l.seen[key] {
+		return
+	}
+	l.seen[key] = true
+	l.settings = append(l.settings, Setting{Name: key, Value: value, Default: !set})
+}
+
+func (l *loader) invalid(key, value, want string) {
+	l.errs = append(l.errs, fmt.Errorf("invalid %s %q: must be %s", key, value, want))
+}
+
+func (l *loader) getEnv(key, defaultValue string) string {
+	if value := l.getenv(key); value != "" {
+		l.record(key, value, true)
+		return value
+	}
+	l.record(key, defaultValue, false)
+	return defaultValue
+}
+
+// secret reads a value that is shown redacted in the settings. When the variable is unset,
+// KEY_FILE names a file holding the value, as with Docker secrets. A reference such as
+// vault:secret/opentrusty#db_password is resolved and shown instead of the value.
+func (l *loader) secret(key string) string {
+	value := l.getenv(key)
+	if path := l.getenv(key + "_FILE"); value == "" && path != "" {
+		value = "file:" + path
+	}
+	if value == "" {
+		l.record(key, "", false)
+		return ""
+	}
+	if !l.resolver.IsReference(value) {
+		l.record(key, Redacted, true)
 		return value
 	}
+
+	l.record(key, value, true)
+	var resolved string
+	err := l.retry.Do(context.Background(), key, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, secretResolveTimeout)
+		defer cancel()
+		var err error
+		resolved, err = l.resolver.Resolve(ctx, value)
+		if errors.Is(err, secrets.ErrSecretNotFound) || errors.Is(err, secrets.ErrNotConfigured) {
+			return startup.Permanent(err)
+		}
+		return err
+	})
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("failed to resolve %s: %w", key, err))
+		return ""
+	}
+	return resolved
+}
+
+// secretReference returns the reference a secret setting holds, with a _FILE variable as a file:
+// reference, or "" when it holds a plain value
+func (l *loader) secretReference(key string) string {
+	value := l.getenv(key)
+	if path := l.getenv(key + "_FILE"); value == "" && path != "" {
+		value = "file:" + path
+	}
+	if !l.resolver.IsReference(value) {
+		return ""
+	}
+	return value
+}
+
+func (l *loader) parseInt(key string, defaultValue int) int {
+	if value := l.getenv(key); value != "" {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			l.invalid(key, value, "an integer")
+			return defaultValue
+		}
+		l.record(key, value, true)
+		return i
+	}
+	l.record(key, strconv.Itoa(defaultValue), false)
 	return defaultValue
 }
 
-func parseInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if i, err := strconv.Atoi(value); err == nil {
-			return i
+func (l *loader) parseFloat(key string, defaultValue float64) float64 {
+	if value := l.getenv(key); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.invalid(key, value, "a number")
+			return defaultValue
 		}
+		l.record(key, value, true)
+		return f
 	}
+	l.record(key, strconv.FormatFloat(defaultValue, 'g', -1, 64), false)
 	return defaultValue
 }
 
-func parseBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if b, err := strconv.ParseBool(value); err == nil {
-			return b
+func (l *loader) parseBool(key string, defaultValue bool) bool {
+	if value := l.getenv(key); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			l.invalid(key, value, "true or false")
+			return defaultValue
 		}
+		l.record(key, value, true)
+		return b
 	}
+	l.record(key, strconv.FormatBool(defaultValue), false)
 	return defaultValue
 }
 
-func parseDuration(key string, defaultValue string) time.Duration {
-	value := getEnv(key, defaultValue)
-	d, err := time.ParseDuration(value)
-	if err != nil {
-		// Fallback to default
-		d, _ = time.ParseDuration(defaultValue)
+// parseList splits a comma-separated value, ignoring empty entries
+func (l *loader) parseList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(l.getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	l.record(key, strings.Join(list, ","), len(list) > 0)
+	return list
+}
+
+// parsePairs parses comma-separated key=value pairs with URL-encoded values, the format of
+// OTEL_EXPORTER_OTLP_HEADERS and OTEL_RESOURCE_ATTRIBUTES. Errors do not repeat the value,
+// which may hold credentials.
+func (l *loader) parsePairs(key, value string) map[string]string {
+	if value == "" {
+		return nil
+	}
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(item), "=")
+		unescaped, err := url.PathUnescape(strings.TrimSpace(v))
+		if !ok || strings.TrimSpace(k) == "" || err != nil {
+			l.errs = append(l.errs, fmt.Errorf("invalid %s: must be comma-separated key=value pairs", key))
+			return nil
+		}
+		pairs[strings.TrimSpace(k)] = unescaped
+	}
+	return pairs
+}
+
+func (l *loader) parseDuration(key string, defaultValue string) time.Duration {
+	if value := l.getenv(key); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			l.invalid(key, value, "a duration such as 30s, 15m or 24h")
+		} else {
+			l.record(key, value, true)
+			return d
+		}
+	} else {
+		l.record(key, defaultValue, false)
 	}
+	d, _ := time.ParseDuration(defaultValue)
 	return d
 }
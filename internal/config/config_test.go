@@ -0,0 +1,467 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setValidEnv(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
+}
+
+// TestPurpose: Validates that configuration problems are all reported instead of silently falling back to defaults.
+// Scope: Unit Test
+// Security: Fail-closed startup (a mistyped or short secret must stop the server before it serves traffic)
+// Expected: Load fails listing the unparsable duration, the unsupported log level and the short encryption key.
+// Test Case ID: CFG-01
+func TestConfig_Load_ReportsEveryProblem(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("SESSION_LIFETIME", "24")
+	t.Setenv("LOG_LEVEL", "verbose")
+	t.Setenv("OPENID_KEY_ENCRYPTION_KEY", "too-short")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected an invalid configuration")
+	}
+	for _, want := range []string{"SESSION_LIFETIME", "LOG_LEVEL", "OPENID_KEY_ENCRYPTION_KEY must be exactly 32 bytes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+// TestPurpose: Validates that the effective settings mark defaults and never expose secrets.
+// Scope: Unit Test
+// Security: Secret handling (printed configuration must not leak passwords or keys)
+// Expected: Set variables carry their value, unset ones are defaults and secrets are redacted.
+// Test Case ID: CFG-02
+func TestConfig_Settings_RedactsSecrets(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("SERVER_PORT", "9090")
+	t.Setenv("EMAIL_SMTP_PASSWORD", "hunter2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := make(map[string]Setting)
+	for _, s := range cfg.Settings() {
+		settings[s.Name] = s
+		if strings.Contains(s.Value, "hunter2") || strings.Contains(s.Value, "0123456789") {
+			t.Errorf("secret exposed in %s", s.Name)
+		}
+	}
+	if s := settings["SERVER_PORT"]; s.Value != "9090" || s.Default {
+		t.Errorf("unexpected SERVER_PORT setting %+v", s)
+	}
+	if s := settings["SERVER_HOST"]; s.Value != "0.0.0.0" || !s.Default {
+		t.Errorf("unexpected SERVER_HOST setting %+v", s)
+	}
+	if s := settings["EMAIL_SMTP_PASSWORD"]; s.Value != Redacted {
+		t.Errorf("unexpected EMAIL_SMTP_PASSWORD setting %+v", s)
+	}
+}
+
+// TestPurpose: Validates that secrets can come from files and secret manager references instead of plain variables.
+// Scope: Unit Test
+// Security: Secret handling (Docker secrets and Vault references keep secrets out of the environment)
+// Expected: KEY_FILE supplies the value and is shown as a reference; an unresolvable reference fails the load.
+// Test Case ID: CFG-03
+func TestConfig_Load_SecretReferences(t *testing.T) {
+	setValidEnv(t)
+	path := filepath.Join(t.TempDir(), "smtp_password")
+	if err := os.WriteFile(path, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EMAIL_SMTP_PASSWORD_FILE", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Email.SMTPPassword != "hunter2" {
+		t.Errorf("unexpected SMTP password %q", cfg.Email.SMTPPassword)
+	}
+	for _, s := range cfg.Settings() {
+		if s.Name == "EMAIL_SMTP_PASSWORD" && s.Value != "file:"+path {
+			t.Errorf("unexpected EMAIL_SMTP_PASSWORD setting %+v", s)
+		}
+	}
+
+	t.Setenv("DB_PASSWORD", "vault:secret/opentrusty#db_password")
+	_, err = Load()
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve DB_PASSWORD") || !strings.Contains(err.Error(), "VAULT_ADDR") {
+		t.Errorf("expected an unresolvable DB_PASSWORD, got %v", err)
+	}
+}
+
+// TestPurpose: Validates that production mode refuses settings that are only safe for development.
+// Scope: Unit Test
+// Security: Secure defaults (insecure cookies, plain HTTP and placeholder keys stop a production server)
+// Expected: Development accepts the settings; production reports missing TLS, insecure cookies, insecure webhooks,
+// the test relying party, the localhost issuer and the placeholder key.
+// Test Case ID: CFG-04
+func TestConfig_Load_ProductionRefusesInsecureSettings(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("SESSION_COOKIE_SECURE", "false")
+	t.Setenv("WEBHOOK_ALLOW_INSECURE", "true")
+	t.Setenv("SERVER_DEV_RP", "true")
+	if _, err := Load(); err != nil {
+		t.Fatalf("development should accept the settings: %v", err)
+	}
+
+	t.Setenv("ENVIRONMENT", EnvironmentProduction)
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected production to refuse the settings")
+	}
+	for _, want := range []string{"requires TLS", "SESSION_COOKIE_SECURE=true", "WEBHOOK_ALLOW_INSECURE", "SERVER_DEV_RP", "OIDC_ISSUER to be a public https URL", "OPENID_KEY_ENCRYPTION_KEY looks like a placeholder"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	t.Setenv("SESSION_COOKIE_SECURE", "true")
+	t.Setenv("WEBHOOK_ALLOW_INSECURE", "false")
+	t.Setenv("SERVER_DEV_RP", "false")
+	t.Setenv("TLS_ACME_DOMAINS", "auth.example.com")
+	t.Setenv("OIDC_ISSUER", "https://auth.example.com")
+	t.Setenv("OPENID_KEY_ENCRYPTION_KEY", "q8ZrT1vXbN4mK7pLw2sYc9dHf6gJa3eU")
+	if _, err := Load(); err != nil {
+		t.Errorf("expected a valid production configuration: %v", err)
+	}
+}
+
+// TestPurpose: Validates the OIDC issuer setting.
+// Scope: Unit Test
+// Security: Issuer binding (a malformed issuer would produce unverifiable tokens and broken discovery)
+// Expected: The issuer defaults to localhost on SERVER_PORT; URLs with a query, fragment, trailing slash or no scheme are rejected.
+// Test Case ID: CFG-05
+func TestConfig_Load_Issuer(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("SERVER_PORT", "9090")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OAuth2.Issuer != "http://localhost:9090" {
+		t.Errorf("unexpected default issuer %q", cfg.OAuth2.Issuer)
+	}
+
+	for _, issuer := range []string{"auth.example.com", "https://auth.example.com/", "https://auth.example.com?x=1", "https://auth.example.com#top", "ftp://auth.example.com"} {
+		t.Setenv("OIDC_ISSUER", issuer)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "OIDC_ISSUER") {
+			t.Errorf("expected %q to be rejected, got %v", issuer, err)
+		}
+	}
+}
+
+// TestPurpose: Validates that the diagnostics listener can only bind a loopback address.
+// Scope: Unit Test
+// Security: Information disclosure (heap profiles contain keys and tokens and pprof has no authentication)
+// Expected: The listener is off by default; a loopback host is accepted, a public one or a port shared with SERVER_PORT is rejected.
+// Test Case ID: CFG-06
+func TestConfig_Load_Diagnostics(t *testing.T) {
+	setValidEnv(t)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.DiagnosticsPort != "" {
+		t.Errorf("expected diagnostics to be disabled, got port %q", cfg.Server.DiagnosticsPort)
+	}
+
+	t.Setenv("SERVER_DIAGNOSTICS_PORT", "6060")
+	for _, host := range []string{"127.0.0.1", "::1", "localhost"} {
+		t.Setenv("SERVER_DIAGNOSTICS_HOST", host)
+		if _, err := Load(); err != nil {
+			t.Errorf("expected %q to be accepted, got %v", host, err)
+		}
+	}
+	for _, host := range []string{"0.0.0.0", "10.0.0.5", "example.com"} {
+		t.Setenv("SERVER_DIAGNOSTICS_HOST", host)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SERVER_DIAGNOSTICS_HOST") {
+			t.Errorf("expected %q to be rejected, got %v", host, err)
+		}
+	}
+
+	t.Setenv("SERVER_DIAGNOSTICS_HOST", "127.0.0.1")
+	t.Setenv("SERVER_PORT", "8080")
+	t.Setenv("SERVER_DIAGNOSTICS_PORT", "8080")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SERVER_DIAGNOSTICS_PORT") {
+		t.Errorf("expected a port shared with SERVER_PORT to be rejected, got %v", err)
+	}
+}
+
+// TestPurpose: Validates the OTLP exporter settings.
+// Scope: Unit Test
+// Security: Secret handling (exporter headers carry backend credentials and are never shown or echoed in errors)
+// Expected: Headers and resource attributes are parsed into maps with headers redacted in the settings; unknown protocols, bad endpoints, out-of-range sampling rates and malformed headers are rejected.
+// Test Case ID: CFG-07
+func TestConfig_Load_OTLP(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://otel.example.com")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=s3cr%3Dt, x-team=identity")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=prod")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter := cfg.Observability.OTLP()
+	if exporter.Endpoint != "https://otel.example.com" || exporter.Protocol != "grpc" {
+		t.Errorf("unexpected exporter %+v", exporter)
+	}
+	if exporter.Headers["api-key"] != "s3cr=t" || exporter.Headers["x-team"] != "identity" {
+		t.Errorf("unexpected headers %v", exporter.Headers)
+	}
+	if cfg.Observability.ResourceAttributes["deployment.environment"] != "prod" || cfg.Observability.TraceSamplingRate != 0.25 {
+		t.Errorf("unexpected resource attributes %v or sampling rate %v", cfg.Observability.ResourceAttributes, cfg.Observability.TraceSamplingRate)
+	}
+	for _, s := range cfg.Settings() {
+		if s.Name == "OTEL_EXPORTER_OTLP_HEADERS" && s.Value != Redacted {
+			t.Errorf("expected headers to be redacted, got %q", s.Value)
+		}
+	}
+
+	cases := map[string]string{
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "thrift",
+		"OTEL_EXPORTER_OTLP_ENDPOINT": "otel.example.com:4317",
+		"OTEL_TRACES_SAMPLER_ARG":     "1.5",
+		"OTEL_EXPORTER_OTLP_HEADERS":  "s3cr3t-token",
+	}
+	for key, value := range cases {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Fatalf("expected %s=%q to be rejected, got %v", key, value, err)
+			}
+			if strings.Contains(err.Error(), "s3cr3t") {
+				t.Errorf("error leaks the header value: %v", err)
+			}
+		})
+	}
+}
+
+// TestPurpose: Validates the database compatibility mode.
+// Scope: Unit Test
+// Security: Fail-closed startup (an unknown mode must not fall back to plain PostgreSQL behavior)
+// Expected: cockroachdb is accepted with the postgres driver; other drivers and unknown modes are rejected.
+// Test Case ID: CFG-08
+func TestConfig_Load_DatabaseCompat(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("DB_DRIVER", "postgres")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("DB_COMPAT", "cockroachdb")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Compat != "cockroachdb" {
+		t.Errorf("expected cockroachdb, got %q", cfg.Database.Compat)
+	}
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DB_COMPAT") {
+		t.Errorf("expected cockroachdb with sqlite to be rejected, got %v", err)
+	}
+
+	t.Setenv("DB_DRIVER", "postgres")
+	t.Setenv("DB_COMPAT", "yugabyte")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DB_COMPAT") {
+		t.Errorf("expected an unknown mode to be rejected, got %v", err)
+	}
+}
+
+// TestPurpose: Validates the mapping of OAuth2 scopes to admin API permissions.
+// Scope: Unit Test
+// Security: Fail-closed startup (a misspelt permission must not silently grant nothing or something else)
+// Expected: Access tokens are refused by default; entries are grouped by scope; malformed entries and unknown permissions are rejected.
+// Test Case ID: CFG-09
+func TestConfig_Load_AdminScopes(t *testing.T) {
+	setValidEnv(t)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OAuth2.AdminScopePermissions() != nil {
+		t.Errorf("expected no mapping by default, got %v", cfg.OAuth2.AdminScopePermissions())
+	}
+
+	t.Setenv("OAUTH2_ADMIN_SCOPE_PERMISSIONS", "admin:clients=tenant:manage_clients, admin:clients=tenant:view,admin:all=*")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cfg.OAuth2.AdminScopePermissions()
+	if len(got["admin:clients"]) != 2 || len(got["admin:all"]) != 1 {
+		t.Errorf("unexpected mapping: %v", got)
+	}
+
+	for _, value := range []string{"admin:clients", "=tenant:view", "admin:clients=tenant:manage_everything"} {
+		t.Setenv("OAUTH2_ADMIN_SCOPE_PERMISSIONS", value)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "OAUTH2_ADMIN_SCOPE_PERMISSIONS") {
+			t.Errorf("expected %q to be rejected, got %v", value, err)
+		}
+	}
+}
+
+// TestPurpose: Validates the bot-mitigation challenge settings.
+// Scope: Unit Test
+// Security: Fail-closed startup (a challenge cannot be enabled without the keys to verify it)
+// Expected: No challenge by default; a provider needs both keys; unknown providers, modes and malformed tenant entries are rejected.
+// Test Case ID: CFG-10
+func TestConfig_Load_Captcha(t *testing.T) {
+	setValidEnv(t)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Captcha.Enabled() || cfg.Captcha.Mode != "abuse" {
+		t.Errorf("expected no provider and the abuse mode by default, got %+v", cfg.Captcha)
+	}
+
+	t.Setenv("CAPTCHA_PROVIDER", "turnstile")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "CAPTCHA_SECRET_KEY") {
+		t.Errorf("expected missing keys to be rejected, got %v", err)
+	}
+	t.Setenv("CAPTCHA_SITE_KEY", "site")
+	t.Setenv("CAPTCHA_SECRET_KEY", "secret")
+	t.Setenv("CAPTCHA_TENANT_MODES", "t1=always,t2=off")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modes := cfg.Captcha.TenantModeMap(); !cfg.Captcha.Enabled() || modes["t1"] != "always" || modes["t2"] != "off" {
+		t.Errorf("unexpected settings: %+v %v", cfg.Captcha, modes)
+	}
+
+	for name, value := range map[string]string{
+		"CAPTCHA_PROVIDER":     "friendlycaptcha",
+		"CAPTCHA_MODE":         "sometimes",
+		"CAPTCHA_TENANT_MODES": "t1=never",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("expected %s=%s to be rejected, got %v", name, value, err)
+			}
+		})
+	}
+}
+
+// TestPurpose: Validates that secret references are retried while the secret manager is unavailable at startup.
+// Scope: Unit Test
+// Security: Availability (a secret manager that starts after the server does not stop it; a missing secret still fails)
+// Expected: With STARTUP_RETRY_TIMEOUT set, a reference resolves once Vault answers; without it the first failure fails the load.
+// Test Case ID: CFG-11
+func TestConfig_Load_StartupRetry(t *testing.T) {
+	failures := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"db_password":"s3cret"}}}`))
+	}))
+	defer server.Close()
+
+	setValidEnv(t)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("DB_PASSWORD", "vault:secret/opentrusty#db_password")
+	t.Setenv("STARTUP_RETRY_BACKOFF", "1ms")
+	t.Setenv("STARTUP_RETRY_MAX_BACKOFF", "1ms")
+
+	failures = 1
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "failed to resolve DB_PASSWORD") {
+		t.Errorf("expected the load to fail without retries, got %v", err)
+	}
+
+	t.Setenv("STARTUP_RETRY_TIMEOUT", "5s")
+	failures = 2
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Password != "s3cret" || failures != 0 {
+		t.Errorf("expected the password after the retries, got %q with %d failures left", cfg.Database.Password, failures)
+	}
+}
+
+// TestPurpose: Validates the PostgreSQL authentication settings.
+// Scope: Unit Test
+// Security: Credential management (IAM authentication replaces a static password; IAM tokens never travel without TLS)
+// Expected: aws-iam needs AWS credentials and TLS and no DB_PASSWORD; DB_PASSWORD_REFRESH keeps the reference DB_PASSWORD_FILE names and requires one; unknown methods are rejected.
+// Test Case ID: CFG-12
+func TestConfig_Load_DatabaseAuth(t *testing.T) {
+	setValidEnv(t)
+	t.Setenv("DB_DRIVER", "postgres")
+	t.Setenv("DB_AUTH", "aws-iam")
+	t.Setenv("DB_SSLMODE", "verify-full")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Auth != DBAuthAWSIAM {
+		t.Errorf("expected aws-iam, got %q", cfg.Database.Auth)
+	}
+
+	t.Setenv("DB_SSLMODE", "disable")
+	t.Setenv("DB_PASSWORD", "secret")
+	_, err = Load()
+	if err == nil || !strings.Contains(err.Error(), "DB_SSLMODE") || !strings.Contains(err.Error(), "DB_PASSWORD must be empty") {
+		t.Errorf("expected aws-iam without TLS and with a password to be rejected, got %v", err)
+	}
+
+	t.Setenv("DB_AUTH", "password")
+	t.Setenv("DB_PASSWORD_REFRESH", "5m")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_REFRESH") {
+		t.Errorf("expected a refresh of a plain password to be rejected, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_PASSWORD", "")
+	t.Setenv("DB_PASSWORD_FILE", path)
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Password != "s3cret" || cfg.Database.PasswordRef != "file:"+path {
+		t.Errorf("expected the password and its reference, got %q and %q", cfg.Database.Password, cfg.Database.PasswordRef)
+	}
+
+	t.Setenv("DB_AUTH", "kerberos")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DB_AUTH") {
+		t.Errorf("expected an unknown method to be rejected, got %v", err)
+	}
+}
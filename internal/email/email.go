@@ -0,0 +1,89 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// Domain errors
+var (
+	ErrSettingsNotFound = apperr.New(apperr.CodeNotFound, "email settings not found")
+	ErrInvalidSettings  = apperr.New(apperr.CodeInvalidArgument, "invalid email settings")
+	ErrInvalidRecipient = apperr.New(apperr.CodeInvalidArgument, "invalid recipient address")
+	ErrNoSender         = apperr.New(apperr.CodeUnavailable, "no email sender configured")
+)
+
+// Kind identifies the purpose of an outbound message
+type Kind string
+
+const (
+	KindPasswordReset     Kind = "password_reset"
+	KindInvitation        Kind = "invitation"
+	KindEmailChange       Kind = "email_change"        // Verification of a new address, sent to it
+	KindEmailChangeNotice Kind = "email_change_notice" // Notice of an email change, sent to the old address
+	KindTest              Kind = "test"
+)
+
+// TLS modes supported for SMTP delivery
+const (
+	TLSModeStartTLS = "starttls"
+	TLSModeImplicit = "implicit"
+	TLSModeNone     = "none"
+)
+
+// Message represents an outbound email
+type Message struct {
+	Kind     Kind
+	To       string
+	Subject  string
+	TextBody string
+}
+
+// Sender delivers outbound email
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Settings represents a tenant's outbound email configuration.
+// The SMTP password is stored encrypted and never leaves the service in plaintext.
+type Settings struct {
+	TenantID          string    `json:"tenant_id"`
+	Enabled           bool      `json:"enabled"`
+	Host              string    `json:"host"`
+	Port              int       `json:"port"`
+	Username          string    `json:"username"`
+	PasswordEncrypted []byte    `json:"-"`
+	FromAddress       string    `json:"from_address"`
+	FromName          string    `json:"from_name"`
+	TLSMode           string    `json:"tls_mode"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// HasPassword reports whether a credential is stored for these settings
+func (s *Settings) HasPassword() bool {
+	return len(s.PasswordEncrypted) > 0
+}
+
+// SettingsRepository defines the interface for tenant email settings storage
+type SettingsRepository interface {
+	Get(ctx context.Context, tenantID string) (*Settings, error)
+	Upsert(ctx context.Context, settings *Settings) error
+	Delete(ctx context.Context, tenantID string) error
+}
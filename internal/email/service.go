@@ -0,0 +1,319 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// SettingsInput carries the fields a tenant admin may change.
+// A nil Password keeps the currently stored credential.
+type SettingsInput struct {
+	Enabled     bool
+	Host        string
+	Port        int
+	Username    string
+	Password    *string
+	FromAddress string
+	FromName    string
+	TLSMode     string
+}
+
+// Service resolves the outbound sender for a tenant and manages tenant email settings
+type Service struct {
+	repo           SettingsRepository
+	platformSender Sender
+	encryptionKey  []byte
+	auditLogger    audit.Logger
+	allowedHosts   []string
+	newSender      func(cfg SMTPConfig) Sender
+}
+
+// NewService creates a new email service.
+// platformSender is the default sender used when a tenant has no settings of its own; it may be nil.
+// encryptionKey MUST be exactly 32 bytes (AES-256).
+func NewService(repo SettingsRepository, platformSender Sender, encryptionKey []byte, auditLogger audit.Logger) (*Service, error) {
+	if len(encryptionKey) != 32 {
+		return nil, errors.New("email credential encryption key must be exactly 32 bytes")
+	}
+	return &Service{
+		repo:           repo,
+		platformSender: platformSender,
+		encryptionKey:  encryptionKey,
+		auditLogger:    auditLogger,
+		newSender: func(cfg SMTPConfig) Sender {
+			return NewSMTPSender(cfg)
+		},
+	}, nil
+}
+
+// SetAllowedHosts lets tenants use SMTP servers on these hosts even though they are, or resolve to,
+// private or loopback addresses. Other tenant servers must be reachable on a public address, so
+// tenant admins cannot make the server connect to internal services.
+func (s *Service) SetAllowedHosts(hosts []string) {
+	s.allowedHosts = hosts
+}
+
+// GetSettings retrieves the email settings for a tenant
+func (s *Service) GetSettings(ctx context.Context, tenantID string) (*Settings, error) {
+	return s.repo.Get(ctx, tenantID)
+}
+
+// UpdateSettings validates and stores the email settings for a tenant
+func (s *Service) UpdateSettings(ctx context.Context, tenantID, actorID string, in SettingsInput) (*Settings, error) {
+	// 1. Validate input
+	in.Host = strings.TrimSpace(in.Host)
+	in.FromAddress = strings.TrimSpace(in.FromAddress)
+	if in.TLSMode == "" {
+		in.TLSMode = TLSModeStartTLS
+	}
+	if in.Host == "" || in.Port <= 0 || in.Port > 65535 {
+		return nil, fmt.Errorf("%w: host and port are required", ErrInvalidSettings)
+	}
+	if _, err := mail.ParseAddress(in.FromAddress); err != nil {
+		return nil, fmt.Errorf("%w: invalid from address", ErrInvalidSettings)
+	}
+	switch in.TLSMode {
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, fmt.Errorf("%w: unsupported tls mode %q", ErrInvalidSettings, in.TLSMode)
+	}
+	if !s.hostAllowed(in.Host) {
+		if ip, err := netip.ParseAddr(in.Host); (err == nil && !publicAddr(ip)) || strings.EqualFold(in.Host, "localhost") {
+			return nil, fmt.Errorf("%w: host must not be a private or loopback address", ErrInvalidSettings)
+		}
+	}
+
+	// 2. Merge with existing settings so the stored credential survives partial updates
+	now := time.Now()
+	settings, err := s.repo.Get(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, ErrSettingsNotFound) {
+			return nil, err
+		}
+		settings = &Settings{TenantID: tenantID, CreatedAt: now}
+	}
+
+	settings.Enabled = in.Enabled
+	settings.Host = in.Host
+	settings.Port = in.Port
+	settings.Username = in.Username
+	settings.FromAddress = in.FromAddress
+	settings.FromName = in.FromName
+	settings.TLSMode = in.TLSMode
+	settings.UpdatedAt = now
+
+	// 3. Encrypt credential at rest
+	if in.Password != nil {
+		if *in.Password == "" {
+			settings.PasswordEncrypted = nil
+		} else {
+			encrypted, err := s.encrypt([]byte(*in.Password), tenantID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt smtp password: %w", err)
+			}
+			settings.PasswordEncrypted = encrypted
+		}
+	}
+	// Credentials are never sent over an unencrypted connection
+	if settings.TLSMode == TLSModeNone && (settings.Username != "" || settings.HasPassword()) {
+		return nil, fmt.Errorf("%w: tls mode %q cannot be used with a username or password", ErrInvalidSettings, TLSModeNone)
+	}
+
+	if err := s.repo.Upsert(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeEmailSettingsUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceEmailSettings,
+		Payload: &audit.EmailSettingsPayload{
+			Host:            settings.Host,
+			Enabled:         settings.Enabled,
+			PasswordChanged: in.Password != nil,
+		},
+	})
+
+	return settings, nil
+}
+
+// DeleteSettings removes a tenant's email settings, reverting it to the platform sender
+func (s *Service) DeleteSettings(ctx context.Context, tenantID, actorID string) error {
+	if err := s.repo.Delete(ctx, tenantID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeEmailSettingsDeleted,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceEmailSettings,
+	})
+
+	return nil
+}
+
+// SenderFor returns the sender to use for a tenant.
+// Tenants without enabled settings fall back to the platform default sender.
+func (s *Service) SenderFor(ctx context.Context, tenantID string) (Sender, error) {
+	if tenantID != "" {
+		settings, err := s.repo.Get(ctx, tenantID)
+		if err != nil && !errors.Is(err, ErrSettingsNotFound) {
+			return nil, err
+		}
+		if err == nil && settings.Enabled {
+			return s.senderFromSettings(settings)
+		}
+	}
+
+	if s.platformSender == nil {
+		return nil, ErrNoSender
+	}
+	return s.platformSender, nil
+}
+
+// Send delivers a message on behalf of a tenant
+func (s *Service) Send(ctx context.Context, tenantID string, msg Message) error {
+	sender, err := s.SenderFor(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return sender.Send(ctx, msg)
+}
+
+// SendTest delivers a test message using the tenant's stored settings, even if they are not yet enabled.
+// This lets admins verify credentials before switching traffic away from the platform sender.
+func (s *Service) SendTest(ctx context.Context, tenantID, actorID, to string) error {
+	if _, err := mail.ParseAddress(to); err != nil {
+		return ErrInvalidRecipient
+	}
+
+	settings, err := s.repo.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	sender, err := s.senderFromSettings(settings)
+	if err != nil {
+		return err
+	}
+
+	sendErr := sender.Send(ctx, Message{
+		Kind:     KindTest,
+		To:       to,
+		Subject:  "OpenTrusty test email",
+		TextBody: "This is a test message confirming that your outbound email settings work.\r\n",
+	})
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeEmailTestSent,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceEmailSettings,
+		Payload: &audit.EmailTestPayload{
+			Email:   to,
+			Success: sendErr == nil,
+		},
+	})
+
+	return sendErr
+}
+
+func (s *Service) senderFromSettings(settings *Settings) (Sender, error) {
+	var password string
+	if settings.HasPassword() {
+		plain, err := s.decrypt(settings.PasswordEncrypted, settings.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt smtp password: %w", err)
+		}
+		password = string(plain)
+	}
+
+	return s.newSender(SMTPConfig{
+		Host:        settings.Host,
+		Port:        settings.Port,
+		Username:    settings.Username,
+		Password:    password,
+		FromAddress: settings.FromAddress,
+		FromName:    settings.FromName,
+		TLSMode:     settings.TLSMode,
+		PublicOnly:  !s.hostAllowed(settings.Host),
+	}), nil
+}
+
+// hostAllowed reports whether host is exempt from the public address check
+func (s *Service) hostAllowed(host string) bool {
+	return slices.ContainsFunc(s.allowedHosts, func(allowed string) bool {
+		return strings.EqualFold(allowed, host)
+	})
+}
+
+// Helper functions
+
+// encrypt seals data with the tenant ID as additional data, so a ciphertext copied to another
+// tenant's settings does not decrypt
+func (s *Service) encrypt(data []byte, tenantID string) ([]byte, error) {
+	block, err := aes.NewCipher(s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, []byte(tenantID)), nil
+}
+
+func (s *Service) decrypt(data []byte, tenantID string) ([]byte, error) {
+	block, err := aes.NewCipher(s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	return gcm.Open(nil, nonce, ciphertext, []byte(tenantID))
+}
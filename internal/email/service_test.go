@@ -0,0 +1,266 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = []byte("01234567890123456789012345678901")
+
+type stubSettingsRepo struct {
+	settings map[string]*Settings
+}
+
+func newStubSettingsRepo() *stubSettingsRepo {
+	return &stubSettingsRepo{settings: make(map[string]*Settings)}
+}
+
+func (r *stubSettingsRepo) Get(ctx context.Context, tenantID string) (*Settings, error) {
+	s, ok := r.settings[tenantID]
+	if !ok {
+		return nil, ErrSettingsNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (r *stubSettingsRepo) Upsert(ctx context.Context, s *Settings) error {
+	cp := *s
+	r.settings[s.TenantID] = &cp
+	return nil
+}
+
+func (r *stubSettingsRepo) Delete(ctx context.Context, tenantID string) error {
+	if _, ok := r.settings[tenantID]; !ok {
+		return ErrSettingsNotFound
+	}
+	delete(r.settings, tenantID)
+	return nil
+}
+
+type recordingSender struct {
+	cfg  SMTPConfig
+	sent []Message
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func newTestService(t *testing.T, platform Sender) (*Service, *stubSettingsRepo, *recordingSender) {
+	t.Helper()
+	repo := newStubSettingsRepo()
+	svc, err := NewService(repo, platform, testKey, audit.NewSlogLogger())
+	require.NoError(t, err)
+
+	tenantSender := &recordingSender{}
+	svc.newSender = func(cfg SMTPConfig) Sender {
+		tenantSender.cfg = cfg
+		return tenantSender
+	}
+	return svc, repo, tenantSender
+}
+
+// TestPurpose: Validates that tenant SMTP credentials are encrypted at rest and decrypted only when building a sender.
+// Scope: Unit Test
+// Security: Confidentiality of tenant-supplied credentials (no plaintext in storage)
+// Expected: Stored ciphertext differs from the plaintext and does not decrypt for another tenant; the resolved sender receives the original password.
+// Test Case ID: EML-01
+func TestEmail_Service_UpdateSettings_EncryptsPassword(t *testing.T) {
+	svc, repo, tenantSender := newTestService(t, nil)
+	ctx := context.Background()
+	password := "s3cret-smtp-pass"
+
+	_, err := svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{
+		Enabled:     true,
+		Host:        "smtp.example.com",
+		Port:        587,
+		Username:    "mailer",
+		Password:    &password,
+		FromAddress: "no-reply@example.com",
+	})
+	require.NoError(t, err)
+
+	stored := repo.settings["tenant-1"]
+	require.NotNil(t, stored)
+	assert.NotEmpty(t, stored.PasswordEncrypted)
+	assert.NotContains(t, string(stored.PasswordEncrypted), password)
+
+	sender, err := svc.SenderFor(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Same(t, tenantSender, sender)
+	assert.Equal(t, password, tenantSender.cfg.Password)
+	assert.Equal(t, TLSModeStartTLS, tenantSender.cfg.TLSMode)
+
+	// The ciphertext is bound to its tenant
+	copied := *stored
+	copied.TenantID = "tenant-2"
+	repo.settings["tenant-2"] = &copied
+	_, err = svc.SenderFor(ctx, "tenant-2")
+	assert.Error(t, err)
+}
+
+// TestPurpose: Validates that a partial update without a password keeps the existing encrypted credential.
+// Scope: Unit Test
+// Security: Prevents accidental credential loss when admins edit non-secret fields
+// Expected: PasswordEncrypted is unchanged after an update with a nil password.
+// Test Case ID: EML-02
+func TestEmail_Service_UpdateSettings_KeepsPassword(t *testing.T) {
+	svc, repo, _ := newTestService(t, nil)
+	ctx := context.Background()
+	password := "s3cret"
+
+	input := SettingsInput{Host: "smtp.example.com", Port: 465, TLSMode: TLSModeImplicit, FromAddress: "a@example.com", Password: &password}
+	_, err := svc.UpdateSettings(ctx, "tenant-1", "admin-1", input)
+	require.NoError(t, err)
+	original := repo.settings["tenant-1"].PasswordEncrypted
+
+	input.Password = nil
+	input.FromName = "Example"
+	_, err = svc.UpdateSettings(ctx, "tenant-1", "admin-1", input)
+	require.NoError(t, err)
+
+	assert.Equal(t, original, repo.settings["tenant-1"].PasswordEncrypted)
+	assert.Equal(t, "Example", repo.settings["tenant-1"].FromName)
+}
+
+// TestPurpose: Validates that invalid settings are rejected before being stored.
+// Scope: Unit Test
+// Security: Input validation on tenant-controlled configuration
+// Expected: ErrInvalidSettings is returned and nothing is persisted, including credentials without TLS.
+// Test Case ID: EML-03
+func TestEmail_Service_UpdateSettings_Validation(t *testing.T) {
+	svc, repo, _ := newTestService(t, nil)
+	ctx := context.Background()
+	password := "s3cret"
+
+	cases := []SettingsInput{
+		{Host: "", Port: 587, FromAddress: "a@example.com"},
+		{Host: "smtp.example.com", Port: 0, FromAddress: "a@example.com"},
+		{Host: "smtp.example.com", Port: 587, FromAddress: "not-an-address"},
+		{Host: "smtp.example.com", Port: 587, FromAddress: "a@example.com", TLSMode: "ssl3"},
+		{Host: "smtp.example.com", Port: 25, FromAddress: "a@example.com", TLSMode: TLSModeNone, Username: "mailer"},
+		{Host: "smtp.example.com", Port: 25, FromAddress: "a@example.com", TLSMode: TLSModeNone, Password: &password},
+	}
+	for _, in := range cases {
+		_, err := svc.UpdateSettings(ctx, "tenant-1", "admin-1", in)
+		assert.ErrorIs(t, err, ErrInvalidSettings)
+	}
+	assert.Empty(t, repo.settings)
+
+	// A stored password cannot be kept when switching to plaintext either
+	_, err := svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{Host: "smtp.example.com", Port: 587, FromAddress: "a@example.com", Password: &password})
+	require.NoError(t, err)
+	_, err = svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{Host: "smtp.example.com", Port: 25, FromAddress: "a@example.com", TLSMode: TLSModeNone})
+	assert.ErrorIs(t, err, ErrInvalidSettings)
+	assert.Equal(t, TLSModeStartTLS, repo.settings["tenant-1"].TLSMode)
+}
+
+// TestPurpose: Validates fallback to the platform default sender.
+// Scope: Unit Test
+// Security: Tenants without their own configuration must not be left unable to send verification or reset emails
+// Expected: Platform sender is used when settings are missing or disabled; ErrNoSender when neither exists.
+// Test Case ID: EML-04
+func TestEmail_Service_SenderFor_Fallback(t *testing.T) {
+	ctx := context.Background()
+	platform := &recordingSender{}
+	svc, _, _ := newTestService(t, platform)
+
+	// No settings
+	sender, err := svc.SenderFor(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Same(t, platform, sender)
+
+	// Disabled settings
+	_, err = svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{
+		Enabled: false, Host: "smtp.example.com", Port: 587, FromAddress: "a@example.com",
+	})
+	require.NoError(t, err)
+	sender, err = svc.SenderFor(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Same(t, platform, sender)
+
+	// No platform sender
+	svcNoPlatform, _, _ := newTestService(t, nil)
+	_, err = svcNoPlatform.SenderFor(ctx, "tenant-1")
+	assert.ErrorIs(t, err, ErrNoSender)
+}
+
+// TestPurpose: Validates that the send-test operation uses the tenant's stored settings even when not enabled.
+// Scope: Unit Test
+// Security: Allows credential verification before routing real traffic through tenant SMTP
+// Expected: Test message is delivered through the tenant sender; invalid recipients are rejected.
+// Test Case ID: EML-05
+func TestEmail_Service_SendTest(t *testing.T) {
+	ctx := context.Background()
+	platform := &recordingSender{}
+	svc, _, tenantSender := newTestService(t, platform)
+
+	err := svc.SendTest(ctx, "tenant-1", "admin-1", "admin@example.com")
+	assert.ErrorIs(t, err, ErrSettingsNotFound)
+
+	_, err = svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{
+		Host: "smtp.example.com", Port: 587, FromAddress: "a@example.com",
+	})
+	require.NoError(t, err)
+
+	err = svc.SendTest(ctx, "tenant-1", "admin-1", "bad address")
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+
+	err = svc.SendTest(ctx, "tenant-1", "admin-1", "admin@example.com")
+	require.NoError(t, err)
+	require.Len(t, tenantSender.sent, 1)
+	assert.Equal(t, KindTest, tenantSender.sent[0].Kind)
+	assert.Empty(t, platform.sent)
+}
+
+// TestPurpose: Validates that tenant SMTP settings cannot point the server at internal services.
+// Scope: Unit Test
+// Security: Server-side request forgery (CWE-918) through tenant-controlled SMTP hosts
+// Expected: Private and loopback hosts are rejected unless allowed; tenant senders refuse private addresses at connect time; allowed hosts are exempt.
+// Test Case ID: EML-06
+func TestEmail_Service_PrivateHosts(t *testing.T) {
+	svc, _, tenantSender := newTestService(t, nil)
+	ctx := context.Background()
+
+	for _, host := range []string{"127.0.0.1", "localhost", "10.0.0.5", "169.254.169.254", "::1"} {
+		_, err := svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{Host: host, Port: 587, FromAddress: "a@example.com"})
+		assert.ErrorIs(t, err, ErrInvalidSettings, host)
+	}
+
+	_, err := svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{Host: "smtp.example.com", Port: 587, FromAddress: "a@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, svc.SendTest(ctx, "tenant-1", "admin-1", "admin@example.com"))
+	assert.True(t, tenantSender.cfg.PublicOnly, "a host name may resolve to a private address")
+
+	svc.SetAllowedHosts([]string{"mail.internal", "localhost"})
+	_, err = svc.UpdateSettings(ctx, "tenant-1", "admin-1", SettingsInput{Host: "LOCALHOST", Port: 25, FromAddress: "a@example.com", TLSMode: TLSModeNone})
+	require.NoError(t, err)
+	require.NoError(t, svc.SendTest(ctx, "tenant-1", "admin-1", "admin@example.com"))
+	assert.False(t, tenantSender.cfg.PublicOnly)
+
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		sender := NewSMTPSender(SMTPConfig{Host: host, Port: 25, FromAddress: "a@example.com", TLSMode: TLSModeNone, PublicOnly: true})
+		err := sender.Send(ctx, Message{To: "b@example.com"})
+		assert.ErrorIs(t, err, errPrivateAddress, host)
+	}
+}
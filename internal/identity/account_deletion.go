@@ -0,0 +1,243 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// Account deletion errors
+var (
+	ErrAccountDeletionNotFound    = apperr.New(apperr.CodeNotFound, "account deletion not found")
+	ErrAccountDeletionUnavailable = apperr.New(apperr.CodeFailedPrecondition, "account deletion is not available")
+	ErrLastAdministrator          = apperr.New(apperr.CodeConflict, "account is the last owner of a tenant or the last platform admin")
+)
+
+// erasedEmailDomain replaces the domain of an erased user's email; .invalid never resolves
+const erasedEmailDomain = "erased.invalid"
+
+// AccountDeletion is a user's request to delete their own account. The account is erased once
+// DeleteAt has passed unless the user cancels the request or logs in before then.
+type AccountDeletion struct {
+	UserID      string
+	RequestedAt time.Time
+	DeleteAt    time.Time
+}
+
+// AccountDeletionRepository defines the interface for account deletion persistence
+type AccountDeletionRepository interface {
+	// Create stores an account deletion, replacing any earlier one of the user
+	Create(ctx context.Context, deletion *AccountDeletion) error
+
+	// Get retrieves the account deletion of a user
+	Get(ctx context.Context, userID string) (*AccountDeletion, error)
+
+	// Delete removes the account deletion of a user, returning ErrAccountDeletionNotFound if it is already gone
+	Delete(ctx context.Context, userID string) error
+
+	// ListDue lists up to limit account deletions whose grace period ended before the given time
+	ListDue(ctx context.Context, before time.Time, limit int) ([]*AccountDeletion, error)
+}
+
+// ErasureHook removes what another service holds for a user being erased, such as their
+// sessions or tokens. A failing hook leaves the account to be erased on the next attempt.
+type ErasureHook func(ctx context.Context, userID string) error
+
+// DeletionGuard refuses to delete an account that something else still depends on, such as the
+// last owner of a tenant, by returning an error
+type DeletionGuard func(ctx context.Context, user *User) error
+
+// accountDeletions holds what account deletion needs
+type accountDeletions struct {
+	repo  AccountDeletionRepository
+	grace time.Duration
+	hooks []ErasureHook
+}
+
+// EnableAccountDeletion lets users delete their own account. The account is erased after grace,
+// or at once when grace is zero; hooks run before the user record is anonymized.
+// Without it, RequestAccountDeletion returns ErrAccountDeletionUnavailable.
+func (s *Service) EnableAccountDeletion(repo AccountDeletionRepository, grace time.Duration, hooks ...ErasureHook) {
+	s.accountDeletions = &accountDeletions{repo: repo, grace: grace, hooks: hooks}
+}
+
+// SetDeletionGuard checks every account deletion request with guard before it is accepted
+func (s *Service) SetDeletionGuard(guard DeletionGuard) {
+	s.deletionGuard = guard
+}
+
+// RequestAccountDeletion schedules the deletion of a user's account. The user confirms it with
+// their password, which is subject to lockout as at login. Without a grace period the account is
+// erased at once.
+func (s *Service) RequestAccountDeletion(ctx context.Context, userID, password string) (*AccountDeletion, error) {
+	if s.accountDeletions == nil {
+		return nil, ErrAccountDeletionUnavailable
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if _, err := s.checkPassword(ctx, userTenant(user), user, password); err != nil {
+		return nil, err
+	}
+	if s.deletionGuard != nil {
+		if err := s.deletionGuard(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	deletion := &AccountDeletion{
+		UserID:      user.ID,
+		RequestedAt: now,
+		DeleteAt:    now.Add(s.accountDeletions.grace),
+	}
+	if s.accountDeletions.grace <= 0 {
+		s.logAccountDeletion(ctx, audit.TypeAccountDeletionRequested, userTenant(user), user.ID, nil, "")
+		if err := s.eraseAccount(ctx, user); err != nil {
+			return nil, err
+		}
+		return deletion, nil
+	}
+
+	if err := s.accountDeletions.repo.Create(ctx, deletion); err != nil {
+		return nil, err
+	}
+	s.logAccountDeletion(ctx, audit.TypeAccountDeletionRequested, userTenant(user), user.ID, &deletion.DeleteAt, "")
+	return deletion, nil
+}
+
+// GetAccountDeletion returns the account deletion a user has pending
+func (s *Service) GetAccountDeletion(ctx context.Context, userID string) (*AccountDeletion, error) {
+	if s.accountDeletions == nil {
+		return nil, ErrAccountDeletionNotFound
+	}
+	return s.accountDeletions.repo.Get(ctx, userID)
+}
+
+// CancelAccountDeletion keeps a user's account that was to be deleted
+func (s *Service) CancelAccountDeletion(ctx context.Context, userID string) error {
+	if s.accountDeletions == nil {
+		return ErrAccountDeletionNotFound
+	}
+	if err := s.accountDeletions.repo.Delete(ctx, userID); err != nil {
+		return err
+	}
+	tenantID := ""
+	if user, err := s.repo.GetByID(ctx, userID); err == nil {
+		tenantID = userTenant(user)
+	}
+	s.logAccountDeletion(ctx, audit.TypeAccountDeletionCancelled, tenantID, userID, nil, "user")
+	return nil
+}
+
+// cancelAccountDeletionOnLogin cancels the pending deletion of a user who has just logged in
+func (s *Service) cancelAccountDeletionOnLogin(ctx context.Context, tenantID string, user *User) {
+	if s.accountDeletions == nil {
+		return
+	}
+	if err := s.accountDeletions.repo.Delete(ctx, user.ID); err != nil {
+		return
+	}
+	s.logAccountDeletion(ctx, audit.TypeAccountDeletionCancelled, tenantID, user.ID, nil, "login")
+}
+
+// EraseDueAccounts erases up to limit accounts whose grace period has ended and returns how many
+// were erased. The deletion guard is checked again first: a user who is now the last owner or admin
+// keeps their account, and a guard that fails otherwise postpones the erasure to the next run.
+func (s *Service) EraseDueAccounts(ctx context.Context, limit int) (int64, error) {
+	if s.accountDeletions == nil {
+		return 0, nil
+	}
+	due, err := s.accountDeletions.repo.ListDue(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+	var erased int64
+	var errs []error
+	for _, deletion := range due {
+		// Removing the deletion first keeps a login or another instance from racing the erasure
+		if err := s.accountDeletions.repo.Delete(ctx, deletion.UserID); err != nil {
+			if !errors.Is(err, ErrAccountDeletionNotFound) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		user, err := s.repo.GetByID(ctx, deletion.UserID)
+		if errors.Is(err, ErrUserNotFound) {
+			// Deleted by an administrator meanwhile; the janitor purges it
+			continue
+		}
+		if err == nil && s.deletionGuard != nil {
+			// The user may have become the last owner or admin during the grace period; the deletion
+			// is then cancelled rather than leaving a tenant or the platform without one
+			if err = s.deletionGuard(ctx, user); errors.Is(err, ErrLastAdministrator) {
+				s.logAccountDeletion(ctx, audit.TypeAccountDeletionCancelled, userTenant(user), user.ID, nil, "last_administrator")
+				continue
+			}
+		}
+		if err == nil {
+			err = s.eraseAccount(ctx, user)
+		}
+		if err != nil {
+			_ = s.accountDeletions.repo.Create(ctx, deletion)
+			errs = append(errs, err)
+			continue
+		}
+		erased++
+	}
+	return erased, errors.Join(errs...)
+}
+
+// eraseAccount runs the erasure hooks, drops a pending email change and anonymizes the user,
+// removing their credentials, role assignments and memberships and soft-deleting them so the
+// janitor later purges the record
+func (s *Service) eraseAccount(ctx context.Context, user *User) error {
+	for _, hook := range s.accountDeletions.hooks {
+		if err := hook(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to erase account: %w", err)
+		}
+	}
+	if s.emailChanges != nil {
+		if change, err := s.emailChanges.repo.GetByUser(ctx, user.ID); err == nil {
+			_ = s.emailChanges.repo.Delete(ctx, change.ID)
+		}
+	}
+	if err := s.repo.Erase(ctx, user.ID, user.ID+"@"+erasedEmailDomain, time.Now()); err != nil {
+		return err
+	}
+	s.logAccountDeletion(ctx, audit.TypeAccountErased, userTenant(user), user.ID, nil, "")
+	return nil
+}
+
+func (s *Service) logAccountDeletion(ctx context.Context, eventType, tenantID, userID string, deleteAt *time.Time, reason string) {
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     eventType,
+		TenantID: tenantID,
+		ActorID:  userID,
+		Resource: audit.ResourceUser,
+		Payload: &audit.AccountDeletionPayload{
+			UserID:   userID,
+			DeleteAt: deleteAt,
+			Reason:   reason,
+		},
+	})
+}
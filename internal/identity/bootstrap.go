@@ -16,23 +16,28 @@ package identity
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
+	"errors"
 	"fmt"
-	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/opentrusty/opentrusty/internal/apperr"
 	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/authz"
 	"github.com/opentrusty/opentrusty/internal/id"
 	"github.com/opentrusty/opentrusty/internal/rbac"
 )
 
+// Environment variables read by `opentrusty bootstrap` as defaults for its flags
 const (
 	EnvBootstrapAdminEmail    = "OT_BOOTSTRAP_ADMIN_EMAIL"
 	EnvBootstrapAdminTenantID = "OT_BOOTSTRAP_ADMIN_TENANT_ID"
 )
 
+// ErrAlreadyBootstrapped is returned when another user already holds the platform admin role
+var ErrAlreadyBootstrapped = apperr.New(apperr.CodeAlreadyExists, "a platform admin already exists")
+
 // BootstrapService manages the initial initialization of the system
 type BootstrapService struct {
 	identityService *Service
@@ -56,93 +61,116 @@ func NewBootstrapService(
 	}
 }
 
-// Bootstrap checks for bootstrap configuration and executes it if necessary
-func (s *BootstrapService) Bootstrap(ctx context.Context) error {
-	email := os.Getenv(EnvBootstrapAdminEmail)
-	tenantID := os.Getenv(EnvBootstrapAdminTenantID)
-
-	if email == "" {
-		return nil
-	}
+// BootstrapRequest describes the initial platform admin
+type BootstrapRequest struct {
+	Email string
+	// TenantID is the tenant of the admin account; empty for a platform-level user
+	TenantID string
+	// Password is set only when the user is created
+	Password string
+	// ExpirePassword forces a password change at first login, for generated one-time passwords
+	ExpirePassword bool
+}
 
-	// 1. Check if ANY platform admin already exists
-	roleID := rbac.RoleIDPlatformAdmin
-	exists, err := s.authzRepo.CheckExists(roleID, authz.ScopePlatform, nil)
-	if err != nil {
-		return fmt.Errorf("failed to check for existing platform admin: %w", err)
-	}
+// BootstrapResult reports what Bootstrap created and what was already present
+type BootstrapResult struct {
+	UserID      string
+	UserCreated bool
+	RoleGranted bool
+}
 
-	if exists {
-		// Already bootstrapped or admin exists, skip silently
-		return nil
+// Bootstrap makes the requested user the initial platform admin, creating the user if needed.
+// It is idempotent: running it again for the same user changes nothing. It returns
+// ErrAlreadyBootstrapped when a different user already holds the platform admin role.
+func (s *BootstrapService) Bootstrap(ctx context.Context, req BootstrapRequest) (*BootstrapResult, error) {
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		return nil, ErrInvalidEmail
 	}
 
-	// 2. Look up the user by email and tenant
-	var tID *string
-	if tenantID != "" {
-		tID = &tenantID
-	}
-	user, err := s.identityService.repo.GetByEmail(tID, email)
+	admins, err := s.authzRepo.ListByRole(ctx, rbac.RoleIDPlatformAdmin, authz.ScopePlatform, nil)
 	if err != nil {
-		// User not found, create it
-		fmt.Printf("Bootstrap user not found, creating new platform admin: %s\n", email)
+		return nil, fmt.Errorf("failed to check for existing platform admin: %w", err)
+	}
 
-		// Generate random password
-		pwBytes := make([]byte, 16)
-		if _, randErr := rand.Read(pwBytes); randErr != nil {
-			return fmt.Errorf("failed to generate random password: %w", randErr)
+	result := &BootstrapResult{}
+	user, err := s.identityService.GetByEmail(ctx, req.TenantID, req.Email)
+	switch {
+	case err == nil:
+		result.UserID = user.ID
+		if slices.Contains(admins, user.ID) {
+			return result, nil
 		}
-		password := base64.RawURLEncoding.EncodeToString(pwBytes) + "!" // Ensure complexity often requires special char depending on rules, adding '!' just in case
-
-		// Provision Identity
-		profile := Profile{
-			GivenName:  "Platform",
-			FamilyName: "Admin",
-			FullName:   "Platform Admin",
+		if len(admins) > 0 {
+			return nil, ErrAlreadyBootstrapped
 		}
-		// We call ProvisionIdentity on Service? ProvisionIdentity expects Profile struct which is in identity package.
-		// bootstrap.go is in identity package, so Profile is available.
-		user, err = s.identityService.ProvisionIdentity(ctx, tenantID, email, profile)
-		if err != nil {
-			return fmt.Errorf("failed to provision bootstrap user: %w", err)
+	case errors.Is(err, ErrUserNotFound):
+		if len(admins) > 0 {
+			return nil, ErrAlreadyBootstrapped
 		}
-
-		// Set Password
-		if err := s.identityService.AddPassword(ctx, user.ID, password); err != nil {
-			return fmt.Errorf("failed to set bootstrap user password: %w", err)
+		user, err = s.createAdmin(ctx, req)
+		if err != nil {
+			return nil, err
 		}
-
-		fmt.Printf("\n\n=== BOOTSTRAP ADMIN CREDENTIALS ===\nEmail: %s\nPassword: %s\n===================================\n\n", email, password)
+		result.UserID = user.ID
+		result.UserCreated = true
+	default:
+		return nil, fmt.Errorf("failed to look up bootstrap user: %w", err)
 	}
 
-	// 3. Assign the platform admin role
 	assignment := &authz.Assignment{
-		ID:             id.NewUUIDv7(),
-		UserID:         user.ID,
-		RoleID:         roleID,
-		Scope:          authz.ScopePlatform,
-		ScopeContextID: nil,
-		GrantedAt:      time.Now(),
-		GrantedBy:      audit.ActorSystemBootstrap,
+		ID:        id.NewUUIDv7(),
+		UserID:    user.ID,
+		RoleID:    rbac.RoleIDPlatformAdmin,
+		Scope:     authz.ScopePlatform,
+		GrantedAt: time.Now(),
+		GrantedBy: audit.ActorSystemBootstrap,
 	}
-
-	if err := s.authzRepo.Grant(assignment); err != nil {
-		return fmt.Errorf("failed to grant platform admin role during bootstrap: %w", err)
+	if err := s.authzRepo.Grant(ctx, assignment); err != nil {
+		return nil, fmt.Errorf("failed to grant platform admin role during bootstrap: %w", err)
 	}
+	result.RoleGranted = true
 
-	// 4. Record audit log
 	s.auditLogger.Log(ctx, audit.Event{
 		Type:     audit.TypePlatformAdminBootstrap,
-		TenantID: tenantID,
+		TenantID: req.TenantID,
 		ActorID:  user.ID,
 		Resource: audit.ResourcePlatform,
-		Metadata: map[string]any{
-			audit.AttrEmail:    email,
-			audit.AttrTenantID: tenantID,
-			audit.AttrRoleID:   roleID,
+		Payload: &audit.BootstrapPayload{
+			Email:    req.Email,
+			TenantID: req.TenantID,
+			RoleID:   rbac.RoleIDPlatformAdmin,
 		},
 	})
+	return result, nil
+}
 
-	fmt.Printf("Successfully bootstrapped initial Platform Admin: %s (Tenant: %s)", email, tenantID)
-	return nil
+func (s *BootstrapService) createAdmin(ctx context.Context, req BootstrapRequest) (*User, error) {
+	if req.Password == "" {
+		return nil, fmt.Errorf("a password is required to create bootstrap user %s", req.Email)
+	}
+	user, err := s.identityService.ProvisionIdentity(ctx, req.TenantID, req.Email, Profile{
+		GivenName:  "Platform",
+		FamilyName: "Admin",
+		FullName:   "Platform Admin",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision bootstrap user: %w", err)
+	}
+	if err := s.identityService.AddPassword(ctx, user.ID, req.Password); err != nil {
+		return nil, fmt.Errorf("failed to set bootstrap user password: %w", err)
+	}
+	if req.ExpirePassword {
+		if err := s.identityService.repo.ExpirePassword(ctx, user.ID, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to expire bootstrap user password: %w", err)
+		}
+	}
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserCreated,
+		TenantID: req.TenantID,
+		ActorID:  audit.ActorSystemBootstrap,
+		Resource: audit.ResourceUser,
+		Payload:  &audit.UserCreatedPayload{UserID: user.ID, Email: user.Email},
+	})
+	return user, nil
 }
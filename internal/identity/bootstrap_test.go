@@ -0,0 +1,68 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPurpose: Validates that the explicit bootstrap creates the initial platform admin once and is safe to re-run.
+// Scope: Unit Test
+// Security: Privilege bootstrap (only the first admin can be created this way; generated passwords are one-time)
+// Expected: The first run creates the user and grants platform_admin; a re-run reports both as present; another email is rejected once an admin exists.
+// Test Case ID: IDN-05
+func TestIdentity_Bootstrap_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	auditLogger := audit.NewStoreLogger(memory.NewAuditRepository(db))
+	identityService := identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 3, 4, 16, 32), auditLogger, 5, 5*time.Minute)
+	assignments := memory.NewAssignmentRepository(db)
+	s := identity.NewBootstrapService(identityService, assignments, memory.NewRoleRepository(db), auditLogger)
+
+	req := identity.BootstrapRequest{Email: "admin@example.com", Password: "SecurePassword123", ExpirePassword: true}
+	first, err := s.Bootstrap(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, first.UserCreated)
+	assert.True(t, first.RoleGranted)
+
+	admins, err := assignments.ListByRole(ctx, rbac.RoleIDPlatformAdmin, authz.ScopePlatform, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.UserID}, admins)
+
+	bootstrapped, err := identityService.Authenticate(ctx, "", req.Email, req.Password)
+	require.NoError(t, err)
+	assert.True(t, bootstrapped.PasswordChangeRequired)
+
+	again, err := s.Bootstrap(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.UserID, again.UserID)
+	assert.False(t, again.UserCreated)
+	assert.False(t, again.RoleGranted)
+
+	_, err = s.Bootstrap(ctx, identity.BootstrapRequest{Email: "other@example.com", Password: "SecurePassword123"})
+	assert.ErrorIs(t, err, identity.ErrAlreadyBootstrapped)
+	_, err = identityService.GetByEmail(ctx, "", "other@example.com")
+	assert.ErrorIs(t, err, identity.ErrUserNotFound)
+}
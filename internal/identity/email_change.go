@@ -0,0 +1,387 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// emailChangeLifetime bounds how long the link sent to the new address stays valid
+const emailChangeLifetime = 24 * time.Hour
+
+// Email change errors
+var (
+	ErrEmailChangeNotFound    = apperr.New(apperr.CodeNotFound, "email change not found")
+	ErrEmailChangeUnavailable = apperr.New(apperr.CodeFailedPrecondition, "email change is not available")
+	ErrEmailUnchanged         = apperr.New(apperr.CodeInvalidArgument, "new email must differ from the current one")
+)
+
+// Email change states
+const (
+	// EmailChangePending waits for the link sent to the new address
+	EmailChangePending = "pending"
+	// EmailChangeVerified waits for the cooling-off window to end; the old address may revert it until then
+	EmailChangeVerified = "verified"
+)
+
+// EmailChange is a user's request to change their email address. The address is changed only once
+// the new one is verified and, with a cooling-off window, the window has passed.
+type EmailChange struct {
+	ID       string
+	UserID   string
+	OldEmail string
+	NewEmail string
+	Status   string
+	// TokenHash is the SHA-256 of the token mailed for the current state: the verification token
+	// while pending, the revert token once verified
+	TokenHash string
+	// ExpiresAt ends the verification link while pending, and the cooling-off window once verified
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// EmailChangeRepository defines the interface for email change persistence
+type EmailChangeRepository interface {
+	// Create stores an email change, replacing any earlier change of the user
+	Create(ctx context.Context, change *EmailChange) error
+
+	// GetByUser retrieves the email change of a user
+	GetByUser(ctx context.Context, userID string) (*EmailChange, error)
+
+	// GetByTokenHash retrieves the email change whose current token has the given hash
+	GetByTokenHash(ctx context.Context, tokenHash string) (*EmailChange, error)
+
+	// Update replaces the status, token hash and expiry of an email change
+	Update(ctx context.Context, change *EmailChange) error
+
+	// Delete removes an email change, returning ErrEmailChangeNotFound if it is already gone
+	Delete(ctx context.Context, id string) error
+
+	// ListDue lists up to limit verified email changes whose cooling-off window ended before the given time
+	ListDue(ctx context.Context, before time.Time, limit int) ([]*EmailChange, error)
+
+	// DeleteExpired deletes up to limit pending email changes whose link expired and returns how many were removed
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
+}
+
+// Mailer sends email on behalf of a tenant; email.Service implements it
+type Mailer interface {
+	Send(ctx context.Context, tenantID string, msg email.Message) error
+}
+
+// EmailChangeConfig configures the email change flow
+type EmailChangeConfig struct {
+	// URL is the page the links in the messages point to; it receives the token in the token query
+	// parameter and action verify or revert
+	URL string
+	// CoolingOff delays a verified change so the old address can revert it; zero changes the
+	// address as soon as the new one is verified
+	CoolingOff time.Duration
+}
+
+// emailChanges holds what the email change flow needs
+type emailChanges struct {
+	repo   EmailChangeRepository
+	mailer Mailer
+	cfg    EmailChangeConfig
+}
+
+// EnableEmailChange lets users change their email address, verifying the new address with a link
+// sent to it and telling the old address. Without it, RequestEmailChange returns ErrEmailChangeUnavailable.
+func (s *Service) EnableEmailChange(repo EmailChangeRepository, mailer Mailer, cfg EmailChangeConfig) {
+	s.emailChanges = &emailChanges{repo: repo, mailer: mailer, cfg: cfg}
+}
+
+// RequestEmailChange starts changing a user's email address to newEmail. The user confirms it with
+// their password; the address changes only after the link mailed to newEmail is followed.
+// A new request replaces an earlier one.
+func (s *Service) RequestEmailChange(ctx context.Context, userID, newEmail, password string) (*EmailChange, error) {
+	if s.emailChanges == nil {
+		return nil, ErrEmailChangeUnavailable
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	credentials, err := s.repo.GetCredentials(ctx, userID)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if valid, err := s.hasher.Verify(password, credentials.PasswordHash); err != nil || !valid {
+		return nil, ErrInvalidCredentials
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if !isValidEmail(newEmail) {
+		return nil, ErrInvalidEmail
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, ErrEmailUnchanged
+	}
+	if _, err := s.repo.GetByEmail(ctx, user.TenantID, newEmail); err == nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	token := generateEmailChangeToken()
+	now := time.Now()
+	change := &EmailChange{
+		ID:        id.NewUUIDv7(),
+		UserID:    user.ID,
+		OldEmail:  user.Email,
+		NewEmail:  newEmail,
+		Status:    EmailChangePending,
+		TokenHash: hashEmailChangeToken(token),
+		ExpiresAt: now.Add(emailChangeLifetime),
+		CreatedAt: now,
+	}
+	if err := s.emailChanges.repo.Create(ctx, change); err != nil {
+		return nil, err
+	}
+
+	tenantID := userTenant(user)
+	err = s.emailChanges.mailer.Send(ctx, tenantID, email.Message{
+		Kind:    email.KindEmailChange,
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		TextBody: "A change of your account's email address to this address was requested.\n\n" +
+			"Confirm it within 24 hours by opening this link:\n" + s.emailChangeLink("verify", token) + "\n\n" +
+			"If you did not request it, ignore this message and your address stays unchanged.\n",
+	})
+	if err != nil {
+		_ = s.emailChanges.repo.Delete(ctx, change.ID)
+		return nil, fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	s.logEmailChange(ctx, audit.TypeEmailChangeRequested, tenantID, change, nil)
+	return change, nil
+}
+
+// GetEmailChange returns the email change a user has in progress
+func (s *Service) GetEmailChange(ctx context.Context, userID string) (*EmailChange, error) {
+	if s.emailChanges == nil {
+		return nil, ErrEmailChangeNotFound
+	}
+	return s.emailChanges.repo.GetByUser(ctx, userID)
+}
+
+// CancelEmailChange abandons the email change a user has in progress
+func (s *Service) CancelEmailChange(ctx context.Context, userID string) error {
+	change, err := s.GetEmailChange(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.emailChanges.repo.Delete(ctx, change.ID); err != nil {
+		return err
+	}
+	s.logEmailChange(ctx, audit.TypeEmailChangeCancelled, s.changeTenant(ctx, change), change, nil)
+	return nil
+}
+
+// VerifyEmailChange confirms the new address with the token mailed to it and tells the old address.
+// Without a cooling-off window the address changes at once; otherwise the old address gets a link
+// to revert the change, which takes effect when the window ends.
+func (s *Service) VerifyEmailChange(ctx context.Context, token string) (*EmailChange, error) {
+	change, err := s.emailChangeByToken(ctx, token, EmailChangePending)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := s.changeTenant(ctx, change)
+
+	notice := "The email address of your account is being changed to " + change.NewEmail + ".\n\n"
+	if s.emailChanges.cfg.CoolingOff <= 0 {
+		if err := s.applyEmailChange(ctx, change); err != nil {
+			return nil, err
+		}
+		s.logEmailChange(ctx, audit.TypeEmailChangeVerified, tenantID, change, nil)
+		notice = "The email address of your account was changed to " + change.NewEmail + ".\n\n" +
+			"If you did not make this change, contact your administrator.\n"
+	} else {
+		revert := generateEmailChangeToken()
+		change.Status = EmailChangeVerified
+		change.TokenHash = hashEmailChangeToken(revert)
+		change.ExpiresAt = time.Now().Add(s.emailChanges.cfg.CoolingOff)
+		if err := s.emailChanges.repo.Update(ctx, change); err != nil {
+			return nil, err
+		}
+		s.logEmailChange(ctx, audit.TypeEmailChangeVerified, tenantID, change, &change.ExpiresAt)
+		notice += "It takes effect on " + change.ExpiresAt.UTC().Format(time.RFC1123) + ". " +
+			"If you did not request it, keep your current address by opening this link before then:\n" +
+			s.emailChangeLink("revert", revert) + "\n"
+	}
+
+	if err := s.emailChanges.mailer.Send(ctx, tenantID, email.Message{
+		Kind:     email.KindEmailChangeNotice,
+		To:       change.OldEmail,
+		Subject:  "Your email address is being changed",
+		TextBody: notice,
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to notify the previous email address of an email change",
+			logger.Component("identity"),
+			logger.Error(err),
+		)
+	}
+	return change, nil
+}
+
+// RevertEmailChange cancels a verified email change during its cooling-off window with the token
+// mailed to the old address
+func (s *Service) RevertEmailChange(ctx context.Context, token string) (*EmailChange, error) {
+	change, err := s.emailChangeByToken(ctx, token, EmailChangeVerified)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.emailChanges.repo.Delete(ctx, change.ID); err != nil {
+		return nil, err
+	}
+	s.logEmailChange(ctx, audit.TypeEmailChangeReverted, s.changeTenant(ctx, change), change, nil)
+	return change, nil
+}
+
+// ApplyEmailChanges changes the address of up to limit users whose cooling-off window has ended
+// and returns how many were changed
+func (s *Service) ApplyEmailChanges(ctx context.Context, limit int) (int64, error) {
+	if s.emailChanges == nil {
+		return 0, nil
+	}
+	due, err := s.emailChanges.repo.ListDue(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+	var applied int64
+	var errs []error
+	for _, change := range due {
+		if err := s.applyEmailChange(ctx, change); err != nil {
+			if !errors.Is(err, ErrEmailChangeNotFound) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		applied++
+	}
+	return applied, errors.Join(errs...)
+}
+
+// applyEmailChange removes change and sets the user's address to the new, verified one. Removing
+// the change first keeps a revert or another instance from racing it. A user whose address moved
+// on since the request, or a new address taken meanwhile, leaves the account unchanged.
+func (s *Service) applyEmailChange(ctx context.Context, change *EmailChange) error {
+	if err := s.emailChanges.repo.Delete(ctx, change.ID); err != nil {
+		return err
+	}
+	user, err := s.repo.GetByID(ctx, change.UserID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.Email != change.OldEmail {
+		return ErrEmailChangeNotFound
+	}
+	if _, err := s.repo.GetByEmail(ctx, user.TenantID, change.NewEmail); err == nil {
+		return ErrUserAlreadyExists
+	}
+
+	user.Email = change.NewEmail
+	user.EmailVerified = true
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.logEmailChange(ctx, audit.TypeEmailChanged, userTenant(user), change, nil)
+	return nil
+}
+
+// emailChangeByToken returns the unexpired email change in the given state whose token is token
+func (s *Service) emailChangeByToken(ctx context.Context, token, status string) (*EmailChange, error) {
+	if s.emailChanges == nil || token == "" {
+		return nil, ErrEmailChangeNotFound
+	}
+	change, err := s.emailChanges.repo.GetByTokenHash(ctx, hashEmailChangeToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != status || !time.Now().Before(change.ExpiresAt) {
+		return nil, ErrEmailChangeNotFound
+	}
+	return change, nil
+}
+
+// emailChangeLink returns the link that performs action with token
+func (s *Service) emailChangeLink(action, token string) string {
+	u, err := url.Parse(s.emailChanges.cfg.URL)
+	if err != nil {
+		return token
+	}
+	q := u.Query()
+	q.Set("action", action)
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// changeTenant returns the tenant of the user changing their address
+func (s *Service) changeTenant(ctx context.Context, change *EmailChange) string {
+	user, err := s.repo.GetByID(ctx, change.UserID)
+	if err != nil {
+		return ""
+	}
+	return userTenant(user)
+}
+
+func (s *Service) logEmailChange(ctx context.Context, eventType, tenantID string, change *EmailChange, effectiveAt *time.Time) {
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     eventType,
+		TenantID: tenantID,
+		ActorID:  change.UserID,
+		Resource: audit.ResourceUser,
+		Payload: &audit.EmailChangePayload{
+			ChangeID:    change.ID,
+			OldEmail:    change.OldEmail,
+			NewEmail:    change.NewEmail,
+			EffectiveAt: effectiveAt,
+		},
+	})
+}
+
+// userTenant returns the tenant a user is registered in, empty for platform users
+func userTenant(user *User) string {
+	if user.TenantID == nil {
+		return ""
+	}
+	return *user.TenantID
+}
+
+func generateEmailChangeToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashEmailChangeToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // zoneinfo is checked against the embedded database, whatever the host has
+)
+
+// Limits of the profile fields
+const (
+	maxProfileNameLength = 255
+	maxProfileURLLength  = 2048
+	maxLocaleLength      = 35
+	maxTimezoneLength    = 50
+)
+
+// localePattern matches a BCP 47 language tag: a language subtag and optional further subtags
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// Validate checks the profile against the formats of the OIDC standard claims (OIDC Core Section 5.1).
+// Empty fields are left out of the claims and always valid.
+func (p Profile) Validate() error {
+	for _, f := range []struct{ name, value string }{
+		{"given_name", p.GivenName},
+		{"family_name", p.FamilyName},
+		{"name", p.FullName},
+		{"nickname", p.Nickname},
+	} {
+		if len(f.value) > maxProfileNameLength {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidProfile, f.name, maxProfileNameLength)
+		}
+	}
+	for _, f := range []struct{ name, value string }{
+		{"picture", p.Picture},
+		{"website", p.Website},
+	} {
+		if f.value != "" && !isWebURL(f.value) {
+			return fmt.Errorf("%w: %s must be an http or https URL of at most %d characters", ErrInvalidProfile, f.name, maxProfileURLLength)
+		}
+	}
+	if p.Locale != "" && (len(p.Locale) > maxLocaleLength || !localePattern.MatchString(p.Locale)) {
+		return fmt.Errorf("%w: locale must be a BCP 47 language tag such as en-US", ErrInvalidProfile)
+	}
+	if p.Timezone != "" && !isTimezone(p.Timezone) {
+		return fmt.Errorf("%w: zoneinfo must be an IANA time zone such as Europe/Paris", ErrInvalidProfile)
+	}
+	if p.Birthdate != "" && !isBirthdate(p.Birthdate, time.Now()) {
+		return fmt.Errorf("%w: birthdate must be a past date as YYYY-MM-DD, 0000-MM-DD or YYYY", ErrInvalidProfile)
+	}
+	return nil
+}
+
+// isWebURL reports whether s is an absolute http or https URL
+func isWebURL(s string) bool {
+	if len(s) > maxProfileURLLength {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isTimezone reports whether s names a zone of the IANA time zone database
+func isTimezone(s string) bool {
+	if len(s) > maxTimezoneLength || s == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(s)
+	return err == nil
+}
+
+// isBirthdate reports whether s is a birthdate no later than now: a full date, a date whose year
+// is withheld as 0000, or a year alone
+func isBirthdate(s string, now time.Time) bool {
+	if len(s) == 4 {
+		year, err := time.Parse("2006", s)
+		return err == nil && year.Year() > 0 && year.Year() <= now.Year()
+	}
+	if year, monthDay, ok := strings.Cut(s, "-"); ok && year == "0000" {
+		// Any leap year lets February 29 through
+		_, err := time.Parse(time.DateOnly, "2000-"+monthDay)
+		return err == nil
+	}
+	date, err := time.Parse(time.DateOnly, s)
+	return err == nil && !date.After(now)
+}
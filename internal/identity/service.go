@@ -18,11 +18,17 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/email"
 	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/argon2"
 )
 
@@ -159,6 +165,12 @@ type Service struct {
 	auditLogger        audit.Logger
 	lockoutMaxAttempts int
 	lockoutDuration    time.Duration
+	lockoutPolicy      LockoutPolicy     // nil applies lockoutMaxAttempts and lockoutDuration to every tenant
+	passwordAgePolicy  PasswordAgePolicy // nil lets passwords last until they are expired
+	emailChanges       *emailChanges     // nil refuses email changes
+	accountDeletions   *accountDeletions // nil refuses account deletion
+	deletionGuard      DeletionGuard     // nil lets every account be deleted
+	mailer             Mailer            // nil sends no account notices
 }
 
 // NewService creates a new identity service
@@ -178,19 +190,89 @@ func NewService(
 	}
 }
 
+// LockoutPolicy returns the failed logins that lock an account of a tenant and how long it stays
+// locked. A zero value leaves the service's own in place.
+type LockoutPolicy func(ctx context.Context, tenantID string) (maxAttempts int, duration time.Duration)
+
+// SetLockoutPolicy lets each tenant choose its own lockout; platform users keep the service's
+func (s *Service) SetLockoutPolicy(policy LockoutPolicy) {
+	s.lockoutPolicy = policy
+}
+
+// PasswordAgePolicy returns how long the passwords of a tenant's users last before they must be
+// changed; an empty tenantID asks for platform users. Zero keeps passwords forever.
+type PasswordAgePolicy func(ctx context.Context, tenantID string) time.Duration
+
+// SetPasswordAgePolicy limits the age of passwords: a user whose password is older must change it
+// at the next login
+func (s *Service) SetPasswordAgePolicy(policy PasswordAgePolicy) {
+	s.passwordAgePolicy = policy
+}
+
+// SetMailer sends account notices, such as a password reset by an administrator, through mailer,
+// with the sender of the user's tenant
+func (s *Service) SetMailer(mailer Mailer) {
+	s.mailer = mailer
+}
+
+// passwordChangeRequired reports whether credentials have expired, or are older than the tenant allows
+func (s *Service) passwordChangeRequired(ctx context.Context, tenantID string, credentials *Credentials) bool {
+	now := time.Now()
+	if credentials.ExpiresAt != nil && !credentials.ExpiresAt.After(now) {
+		return true
+	}
+	if s.passwordAgePolicy == nil || credentials.UpdatedAt.IsZero() {
+		return false
+	}
+	maxAge := s.passwordAgePolicy(ctx, tenantID)
+	return maxAge > 0 && !credentials.UpdatedAt.Add(maxAge).After(now)
+}
+
+// PasswordChangeRequired reports whether a user must change their password before doing anything
+// else, as Authenticate does for a login to tenantID. A user without a password never has to.
+func (s *Service) PasswordChangeRequired(ctx context.Context, tenantID, userID string) (bool, error) {
+	credentials, err := s.repo.GetCredentials(ctx, userID)
+	if errors.Is(err, ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return s.passwordChangeRequired(ctx, tenantID, credentials), nil
+}
+
+// lockout returns the lockout threshold and duration of a tenant's users
+func (s *Service) lockout(ctx context.Context, tenantID string) (int, time.Duration) {
+	maxAttempts, duration := s.lockoutMaxAttempts, s.lockoutDuration
+	if s.lockoutPolicy == nil || tenantID == "" {
+		return maxAttempts, duration
+	}
+	n, d := s.lockoutPolicy(ctx, tenantID)
+	if n > 0 {
+		maxAttempts = n
+	}
+	if d > 0 {
+		duration = d
+	}
+	return maxAttempts, duration
+}
+
 // ProvisionIdentity creates a new user identity without credentials
 func (s *Service) ProvisionIdentity(ctx context.Context, tenantID, email string, profile Profile) (*User, error) {
 	// Validate email
 	if !isValidEmail(email) {
 		return nil, ErrInvalidEmail
 	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
 
 	// Check if user already exists
 	var tID *string
 	if tenantID != "" {
 		tID = &tenantID
 	}
-	existing, err := s.repo.GetByEmail(tID, email)
+	existing, err := s.repo.GetByEmail(ctx, tID, email)
 	if err == nil && existing != nil {
 		return nil, ErrUserAlreadyExists
 	}
@@ -204,7 +286,7 @@ func (s *Service) ProvisionIdentity(ctx context.Context, tenantID, email string,
 		Profile:       profile,
 	}
 
-	if err := s.repo.Create(user); err != nil {
+	if err := s.repo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create identity: %w", err)
 	}
 
@@ -229,7 +311,7 @@ func (s *Service) AddPassword(ctx context.Context, userID, password string) erro
 		PasswordHash: passwordHash,
 	}
 
-	if err := s.repo.AddCredentials(credentials); err != nil {
+	if err := s.repo.AddCredentials(ctx, credentials); err != nil {
 		return fmt.Errorf("failed to add credentials: %w", err)
 	}
 
@@ -238,23 +320,56 @@ func (s *Service) AddPassword(ctx context.Context, userID, password string) erro
 
 // Authenticate authenticates a user with email and password
 func (s *Service) Authenticate(ctx context.Context, tenantID, email, password string) (*User, error) {
+	ctx, span := tracing.StartSpan(ctx, "identity.Authenticate", attribute.String("tenant_id", tenantID))
+	user, err := s.authenticate(ctx, tenantID, email, password)
+	tracing.EndSpan(span, err)
+	return user, err
+}
+
+func (s *Service) authenticate(ctx context.Context, tenantID, email, password string) (*User, error) {
 	// Get user by email
 	var tID *string
 	if tenantID != "" {
 		tID = &tenantID
 	}
-	user, err := s.repo.GetByEmail(tID, email)
+	user, err := s.repo.GetByEmail(ctx, tID, email)
 	if err != nil {
 		// Audit failed attempt (unknown user)
 		s.auditLogger.Log(ctx, audit.Event{
 			Type:     audit.TypeLoginFailed,
 			TenantID: tenantID,
 			Resource: email,
-			Metadata: map[string]any{audit.AttrReason: "user_not_found"},
+			Payload:  &audit.LoginFailedPayload{Reason: "user_not_found"},
 		})
 		return nil, ErrInvalidCredentials
 	}
 
+	credentials, err := s.checkPassword(ctx, tenantID, user, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logging in during the grace period keeps the account
+	s.cancelAccountDeletionOnLogin(ctx, tenantID, user)
+
+	// The login succeeds with an expired password, but only to set a new one
+	user.PasswordChangeRequired = s.passwordChangeRequired(ctx, tenantID, credentials)
+
+	// Audit success
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeLoginSuccess,
+		TenantID: tenantID,
+		ActorID:  user.ID,
+		Resource: "login",
+		Payload:  &audit.LoginSuccessPayload{PasswordChangeRequired: user.PasswordChangeRequired},
+	})
+
+	return user, nil
+}
+
+// checkPassword verifies the password of user under the lockout of its tenant: a locked account is
+// refused, a wrong password counts as a failed attempt and a right one clears them
+func (s *Service) checkPassword(ctx context.Context, tenantID string, user *User, password string) (*Credentials, error) {
 	// Check if locked out
 	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
 		s.auditLogger.Log(ctx, audit.Event{
@@ -262,69 +377,106 @@ func (s *Service) Authenticate(ctx context.Context, tenantID, email, password st
 			TenantID: tenantID,
 			ActorID:  user.ID,
 			Resource: "login",
-			Metadata: map[string]any{audit.AttrReason: "locked_out"},
+			Payload:  &audit.LoginFailedPayload{Reason: "locked_out"},
 		})
 		return nil, ErrAccountLocked
 	}
 
 	// Get credentials
-	credentials, err := s.repo.GetCredentials(user.ID)
+	credentials, err := s.repo.GetCredentials(ctx, user.ID)
 	if err != nil {
 		return nil, ErrInvalidCredentials
 	}
 
-	// Verify password
+	// Verify password; Argon2id is deliberately slow, so it gets its own span
+	_, verifySpan := tracing.StartSpan(ctx, "identity.VerifyPassword")
 	valid, err := s.hasher.Verify(password, credentials.PasswordHash)
+	verifySpan.End()
 	if err != nil || !valid {
-		// Increment failed attempts
-		newAttempts := user.FailedLoginAttempts + 1
-		var newLockedUntil *time.Time
-
-		if newAttempts >= s.lockoutMaxAttempts {
-			until := time.Now().Add(s.lockoutDuration)
-			newLockedUntil = &until
-			// Audit lockout
-			s.auditLogger.Log(ctx, audit.Event{
-				Type:     audit.TypeUserLocked,
-				TenantID: tenantID,
-				ActorID:  user.ID,
-				Resource: "login",
-				Metadata: map[string]any{audit.AttrAttempts: newAttempts},
-			})
-		}
+		s.recordFailedPassword(ctx, tenantID, user)
+		return nil, ErrInvalidCredentials
+	}
 
-		// Update lockout status
-		_ = s.repo.UpdateLockout(user.ID, newAttempts, newLockedUntil)
+	// Reset failed attempts if > 0
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		_ = s.repo.UpdateLockout(ctx, user.ID, 0, nil)
+	}
+	return credentials, nil
+}
 
-		// Audit failed attempt
+// recordFailedPassword counts a wrong password against user, locking the account once it reaches
+// the maximum number of attempts of its tenant
+func (s *Service) recordFailedPassword(ctx context.Context, tenantID string, user *User) {
+	// Increment failed attempts
+	newAttempts := user.FailedLoginAttempts + 1
+	var newLockedUntil *time.Time
+
+	maxAttempts, duration := s.lockout(ctx, tenantID)
+	if newAttempts >= maxAttempts {
+		until := time.Now().Add(duration)
+		newLockedUntil = &until
+		// Audit lockout
 		s.auditLogger.Log(ctx, audit.Event{
-			Type:     audit.TypeLoginFailed,
+			Type:     audit.TypeUserLocked,
 			TenantID: tenantID,
 			ActorID:  user.ID,
 			Resource: "login",
-			Metadata: map[string]any{
-				audit.AttrReason:   "invalid_password",
-				audit.AttrAttempts: newAttempts,
-			},
+			Payload:  &audit.UserLockedPayload{Attempts: newAttempts},
 		})
-
-		return nil, ErrInvalidCredentials
 	}
 
-	// Reset failed attempts if > 0
-	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
-		_ = s.repo.UpdateLockout(user.ID, 0, nil)
-	}
+	// Update lockout status
+	_ = s.repo.UpdateLockout(ctx, user.ID, newAttempts, newLockedUntil)
 
-	// Audit success
+	// Audit failed attempt
 	s.auditLogger.Log(ctx, audit.Event{
-		Type:     audit.TypeLoginSuccess,
+		Type:     audit.TypeLoginFailed,
 		TenantID: tenantID,
 		ActorID:  user.ID,
 		Resource: "login",
+		Payload: &audit.LoginFailedPayload{
+			Reason:   "invalid_password",
+			Attempts: newAttempts,
+		},
 	})
+}
 
-	return user, nil
+// Accounts returns the users registered with email in any tenant, platform admins included. The
+// same email may name one account per tenant, each with its own password.
+func (s *Service) Accounts(ctx context.Context, email string) ([]*User, error) {
+	return s.repo.ListByEmail(ctx, email)
+}
+
+// MatchAccounts returns the accounts whose password is password, so that a login only offers the
+// accounts the user proved to own. Locked accounts never match. A password matching none of them
+// counts as a failed login on each, so lockout applies as it does to Authenticate.
+func (s *Service) MatchAccounts(ctx context.Context, accounts []*User, password string) ([]*User, error) {
+	var matched, failed []*User
+	for _, user := range accounts {
+		if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+			continue
+		}
+		credentials, err := s.repo.GetCredentials(ctx, user.ID)
+		if err != nil {
+			continue
+		}
+		if valid, err := s.hasher.Verify(password, credentials.PasswordHash); err == nil && valid {
+			matched = append(matched, user)
+		} else {
+			failed = append(failed, user)
+		}
+	}
+	if len(matched) == 0 {
+		for _, user := range failed {
+			tenantID := ""
+			if user.TenantID != nil {
+				tenantID = *user.TenantID
+			}
+			s.recordFailedPassword(ctx, tenantID, user)
+		}
+		return nil, ErrInvalidCredentials
+	}
+	return matched, nil
 }
 
 // GetByEmail retrieves a user by email
@@ -333,7 +485,7 @@ func (s *Service) GetByEmail(ctx context.Context, tenantID, email string) (*User
 	if tenantID != "" {
 		tID = &tenantID
 	}
-	user, err := s.repo.GetByEmail(tID, email)
+	user, err := s.repo.GetByEmail(ctx, tID, email)
 	if err != nil {
 		// Can't distinguish between not found and error comfortably without error wrapping check
 		// But GetByEmail usually returns error if not found?
@@ -346,28 +498,68 @@ func (s *Service) GetByEmail(ctx context.Context, tenantID, email string) (*User
 
 // GetUser retrieves a user by ID
 func (s *Service) GetUser(ctx context.Context, userID string) (*User, error) {
-	user, err := s.repo.GetByID(userID)
+	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
 	return user, nil
 }
 
-// UpdateProfile updates user profile information
-func (s *Service) UpdateProfile(ctx context.Context, userID string, profile Profile) error {
-	user, err := s.repo.GetByID(userID)
+// UserClaims returns the standard claims of a user (OIDC Core Section 5.1); empty profile fields
+// are left out
+func (s *Service) UserClaims(ctx context.Context, userID string) (map[string]any, error) {
+	user, err := s.GetUser(ctx, userID)
 	if err != nil {
-		return ErrUserNotFound
+		return nil, err
+	}
+	claims := map[string]any{
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+	}
+	for name, value := range map[string]string{
+		"name":        user.Profile.FullName,
+		"given_name":  user.Profile.GivenName,
+		"family_name": user.Profile.FamilyName,
+		"nickname":    user.Profile.Nickname,
+		"picture":     user.Profile.Picture,
+		"locale":      user.Profile.Locale,
+		"zoneinfo":    user.Profile.Timezone,
+		"website":     user.Profile.Website,
+		"birthdate":   user.Profile.Birthdate,
+	} {
+		if value != "" {
+			claims[name] = value
+		}
+	}
+	return claims, nil
+}
+
+// UpdateProfile updates user profile information and returns the updated user.
+// A non-zero version is the version the caller based the change on; if the user has moved on since,
+// ErrUserVersionConflict is returned. With version 0 the profile replaces whatever version is current.
+func (s *Service) UpdateProfile(ctx context.Context, userID string, profile Profile, version int) (*User, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if version != 0 {
+		user.Version = version
 	}
 
 	user.Profile = profile
-	return s.repo.Update(user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 // ChangePassword changes user password
 func (s *Service) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) error {
 	// Get credentials
-	credentials, err := s.repo.GetCredentials(userID)
+	credentials, err := s.repo.GetCredentials(ctx, userID)
 	if err != nil {
 		return ErrUserNotFound
 	}
@@ -382,6 +574,9 @@ func (s *Service) ChangePassword(ctx context.Context, userID, oldPassword, newPa
 	if !isStrongPassword(newPassword) {
 		return ErrWeakPassword
 	}
+	if newPassword == oldPassword {
+		return ErrPasswordReused
+	}
 
 	// Hash new password
 	newHash, err := s.hasher.Hash(newPassword)
@@ -389,7 +584,197 @@ func (s *Service) ChangePassword(ctx context.Context, userID, oldPassword, newPa
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	return s.repo.UpdatePassword(userID, newHash)
+	return s.repo.UpdatePassword(ctx, userID, newHash)
+}
+
+// ChangeExpiredPassword authenticates a user and, when their password must be changed, replaces
+// it, so the new password can be set as part of the login. A password that need not change is kept.
+func (s *Service) ChangeExpiredPassword(ctx context.Context, tenantID, email, password, newPassword string) (*User, error) {
+	user, err := s.Authenticate(ctx, tenantID, email, password)
+	if err != nil || !user.PasswordChangeRequired {
+		return user, err
+	}
+
+	if !isStrongPassword(newPassword) {
+		return nil, ErrWeakPassword
+	}
+	if newPassword == password {
+		return nil, ErrPasswordReused
+	}
+	newHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.repo.UpdatePassword(ctx, user.ID, newHash); err != nil {
+		return nil, err
+	}
+	user.PasswordChangeRequired = false
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypePasswordChanged,
+		TenantID: tenantID,
+		ActorID:  user.ID,
+		Resource: audit.ResourceUserCredentials,
+	})
+	return user, nil
+}
+
+// Lockout describes a user's failed-login state
+type Lockout struct {
+	UserID              string
+	FailedLoginAttempts int
+	LockedUntil         *time.Time // May be in the past once the lockout has lapsed
+	PasswordExpiresAt   *time.Time
+}
+
+// Locked reports whether the account is locked at the given time
+func (l *Lockout) Locked(now time.Time) bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(now)
+}
+
+// PasswordExpired reports whether the password has expired at the given time
+func (l *Lockout) PasswordExpired(now time.Time) bool {
+	return l.PasswordExpiresAt != nil && !l.PasswordExpiresAt.After(now)
+}
+
+// GetLockout returns the failed-login state of a user in a tenant
+func (s *Service) GetLockout(ctx context.Context, tenantID, userID string) (*Lockout, error) {
+	user, err := s.tenantUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	lockout := &Lockout{
+		UserID:              user.ID,
+		FailedLoginAttempts: user.FailedLoginAttempts,
+		LockedUntil:         user.LockedUntil,
+	}
+	if credentials, err := s.repo.GetCredentials(ctx, user.ID); err == nil {
+		lockout.PasswordExpiresAt = credentials.ExpiresAt
+	}
+	return lockout, nil
+}
+
+// Unlock clears a user's failed login attempts and lockout
+func (s *Service) Unlock(ctx context.Context, tenantID, userID, actorID string) error {
+	user, err := s.tenantUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	if err := s.repo.UpdateLockout(ctx, user.ID, 0, nil); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserUnlocked,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceUser,
+		Payload:  &audit.UserUnlockedPayload{TargetUserID: user.ID, Attempts: user.FailedLoginAttempts},
+	})
+	return nil
+}
+
+// ExpirePassword forces a user to set a new password at their next login
+func (s *Service) ExpirePassword(ctx context.Context, tenantID, userID, actorID string) error {
+	user, err := s.tenantUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.ExpirePassword(ctx, user.ID, time.Now()); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrNoPassword
+		}
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypePasswordExpired,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceUserCredentials,
+		Payload:  &audit.PasswordExpiredPayload{TargetUserID: user.ID},
+	})
+	return nil
+}
+
+// ResetPassword replaces a user's password without knowing the current one, for account recovery.
+// It also clears a forced password change, unless mustChange makes the new password temporary: the
+// user can then only sign in to set a password of their own.
+func (s *Service) ResetPassword(ctx context.Context, tenantID, userID, newPassword, actorID string, mustChange bool) error {
+	user, err := s.tenantUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !isStrongPassword(newPassword) {
+		return ErrWeakPassword
+	}
+	newHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// Users provisioned without a password get their first credential
+	if _, err := s.repo.GetCredentials(ctx, user.ID); err != nil {
+		err = s.repo.AddCredentials(ctx, &Credentials{UserID: user.ID, PasswordHash: newHash})
+	} else {
+		err = s.repo.UpdatePassword(ctx, user.ID, newHash)
+	}
+	if err != nil {
+		return err
+	}
+	if mustChange {
+		if err := s.repo.ExpirePassword(ctx, user.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypePasswordReset,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceUserCredentials,
+		Payload:  &audit.PasswordResetPayload{TargetUserID: user.ID, MustChange: mustChange},
+	})
+
+	if s.mailer != nil {
+		notice := "An administrator reset the password of your account.\n"
+		if mustChange {
+			notice += "\nYou will be asked to choose a new password when you next sign in.\n"
+		}
+		notice += "\nIf you did not ask for this, contact your administrator.\n"
+		if err := s.mailer.Send(ctx, userTenant(user), email.Message{
+			Kind:     email.KindPasswordReset,
+			To:       user.Email,
+			Subject:  "Your password was reset",
+			TextBody: notice,
+		}); err != nil {
+			slog.WarnContext(ctx, "failed to notify a user of a password reset",
+				logger.Component("identity"),
+				logger.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// tenantUser returns a user that belongs to the tenant; users of other tenants are not found.
+// An empty tenantID selects platform users.
+func (s *Service) tenantUser(ctx context.Context, tenantID, userID string) (*User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	owner := ""
+	if user.TenantID != nil {
+		owner = *user.TenantID
+	}
+	if owner != tenantID {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
 }
 
 // Helper functions
@@ -16,10 +16,19 @@ package identity
 
 import (
 	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // MockUserRepository is a simple in-memory implementation of UserRepository
@@ -35,17 +44,17 @@ func NewMockUserRepository() *MockUserRepository {
 	}
 }
 
-func (m *MockUserRepository) Create(user *User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *User) error {
 	m.users[user.ID] = user
 	return nil
 }
 
-func (m *MockUserRepository) AddCredentials(credentials *Credentials) error {
+func (m *MockUserRepository) AddCredentials(ctx context.Context, credentials *Credentials) error {
 	m.credentials[credentials.UserID] = credentials
 	return nil
 }
 
-func (m *MockUserRepository) GetByID(id string) (*User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*User, error) {
 	u, ok := m.users[id]
 	if !ok {
 		return nil, ErrUserNotFound
@@ -53,7 +62,7 @@ func (m *MockUserRepository) GetByID(id string) (*User, error) {
 	return u, nil
 }
 
-func (m *MockUserRepository) GetByEmail(tenantID *string, email string) (*User, error) {
+func (m *MockUserRepository) GetByEmail(ctx context.Context, tenantID *string, email string) (*User, error) {
 	for _, u := range m.users {
 		uTenant := ""
 		if u.TenantID != nil {
@@ -71,12 +80,22 @@ func (m *MockUserRepository) GetByEmail(tenantID *string, email string) (*User,
 	return nil, ErrUserNotFound
 }
 
-func (m *MockUserRepository) Update(user *User) error {
+func (m *MockUserRepository) ListByEmail(ctx context.Context, email string) ([]*User, error) {
+	var users []*User
+	for _, u := range m.users {
+		if u.Email == email {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *User) error {
 	m.users[user.ID] = user
 	return nil
 }
 
-func (m *MockUserRepository) UpdateLockout(userID string, failedAttempts int, lockedUntil *time.Time) error {
+func (m *MockUserRepository) UpdateLockout(ctx context.Context, userID string, failedAttempts int, lockedUntil *time.Time) error {
 	u, ok := m.users[userID]
 	if !ok {
 		return ErrUserNotFound
@@ -86,12 +105,29 @@ func (m *MockUserRepository) UpdateLockout(userID string, failedAttempts int, lo
 	return nil
 }
 
-func (m *MockUserRepository) Delete(id string) error {
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	delete(m.users, id)
 	return nil
 }
 
-func (m *MockUserRepository) GetCredentials(userID string) (*Credentials, error) {
+func (m *MockUserRepository) Erase(ctx context.Context, userID, anonEmail string, at time.Time) error {
+	u, ok := m.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	delete(m.credentials, userID)
+	u.Email = anonEmail
+	u.EmailVerified = false
+	u.Profile = Profile{}
+	u.DeletedAt = &at
+	return nil
+}
+
+func (m *MockUserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockUserRepository) GetCredentials(ctx context.Context, userID string) (*Credentials, error) {
 	c, ok := m.credentials[userID]
 	if !ok {
 		return nil, ErrUserNotFound
@@ -99,12 +135,23 @@ func (m *MockUserRepository) GetCredentials(userID string) (*Credentials, error)
 	return c, nil
 }
 
-func (m *MockUserRepository) UpdatePassword(userID string, passwordHash string) error {
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
 	c, ok := m.credentials[userID]
 	if !ok {
 		return ErrUserNotFound
 	}
 	c.PasswordHash = passwordHash
+	c.UpdatedAt = time.Now()
+	c.ExpiresAt = nil
+	return nil
+}
+
+func (m *MockUserRepository) ExpirePassword(ctx context.Context, userID string, at time.Time) error {
+	c, ok := m.credentials[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	c.ExpiresAt = &at
 	return nil
 }
 
@@ -187,3 +234,762 @@ func TestIdentity_Service_ProvisionIdentity_Conflict(t *testing.T) {
 		t.Errorf("expected ErrUserAlreadyExists, got %v", err)
 	}
 }
+
+// TestPurpose: Validates admin lockout management and forced password expiry.
+// Scope: Unit Test
+// Security: Admins can only act on users of their own tenant; an expired password only signs in to be replaced
+// Expected: Unlock clears the lockout, an expired password signs in with a required password change until a different strong password is set, and other tenants' users are not found.
+// Test Case ID: IDN-03
+func TestIdentity_Service_LockoutManagement(t *testing.T) {
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(65536, 3, 4, 16, 32)
+	s := NewService(repo, hasher, audit.NewSlogLogger(), 2, 5*time.Minute)
+
+	ctx := context.Background()
+	tenantID := "tenant-1"
+	email := "locked@example.com"
+	password := "SecurePassword123"
+
+	user, err := s.ProvisionIdentity(ctx, tenantID, email, Profile{})
+	if err != nil {
+		t.Fatalf("failed to provision: %v", err)
+	}
+	if err := s.AddPassword(ctx, user.ID, password); err != nil {
+		t.Fatalf("failed to add password: %v", err)
+	}
+
+	// Lock the account
+	s.Authenticate(ctx, tenantID, email, "WrongPassword")
+	s.Authenticate(ctx, tenantID, email, "WrongPassword")
+	lockout, err := s.GetLockout(ctx, tenantID, user.ID)
+	if err != nil {
+		t.Fatalf("GetLockout: %v", err)
+	}
+	if !lockout.Locked(time.Now()) || lockout.FailedLoginAttempts != 2 {
+		t.Errorf("expected locked account with 2 attempts, got %+v", lockout)
+	}
+
+	// Other tenants cannot see or unlock the user
+	if _, err := s.GetLockout(ctx, "tenant-2", user.ID); err != ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound for another tenant, got %v", err)
+	}
+	if err := s.Unlock(ctx, "tenant-2", user.ID, "admin"); err != ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound for another tenant, got %v", err)
+	}
+
+	if err := s.Unlock(ctx, tenantID, user.ID, "admin"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if _, err := s.Authenticate(ctx, tenantID, email, password); err != nil {
+		t.Errorf("expected login after unlock, got %v", err)
+	}
+
+	// Force a password change
+	if err := s.ExpirePassword(ctx, tenantID, user.ID, "admin"); err != nil {
+		t.Fatalf("ExpirePassword: %v", err)
+	}
+	lockout, _ = s.GetLockout(ctx, tenantID, user.ID)
+	if !lockout.PasswordExpired(time.Now()) {
+		t.Errorf("expected expired password, got %+v", lockout)
+	}
+	if _, err := s.Authenticate(ctx, tenantID, email, "WrongPassword"); err != ErrInvalidCredentials {
+		t.Errorf("expected ErrInvalidCredentials before expiry is revealed, got %v", err)
+	}
+	if expired, err := s.Authenticate(ctx, tenantID, email, password); err != nil || !expired.PasswordChangeRequired {
+		t.Errorf("expected a login that must change the password, got %+v, %v", expired, err)
+	}
+	if _, err := s.ChangeExpiredPassword(ctx, tenantID, email, password, password); err != ErrPasswordReused {
+		t.Errorf("expected ErrPasswordReused, got %v", err)
+	}
+	if _, err := s.ChangeExpiredPassword(ctx, tenantID, email, "WrongPassword", "NewSecurePassword456"); err != ErrInvalidCredentials {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+	changed, err := s.ChangeExpiredPassword(ctx, tenantID, email, password, "NewSecurePassword456")
+	if err != nil || changed.ID != user.ID || changed.PasswordChangeRequired {
+		t.Fatalf("ChangeExpiredPassword: %+v, %v", changed, err)
+	}
+	if again, err := s.Authenticate(ctx, tenantID, email, "NewSecurePassword456"); err != nil || again.PasswordChangeRequired {
+		t.Errorf("expected an unrestricted login with the new password, got %+v, %v", again, err)
+	}
+}
+
+// TestPurpose: Validates operator password resets used for account recovery.
+// Scope: Unit Test
+// Security: Resets are tenant-scoped, enforce password strength and clear a forced password change
+// Expected: The new password logs in, the old one fails, the user is notified, weak passwords and other tenants' users are rejected, and platform users are reset with an empty tenant ID.
+// Test Case ID: IDN-04
+func TestIdentity_Service_ResetPassword(t *testing.T) {
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(65536, 3, 4, 16, 32)
+	s := NewService(repo, hasher, audit.NewSlogLogger(), 5, 5*time.Minute)
+	mail := &mailbox{}
+	s.SetMailer(mail)
+
+	ctx := context.Background()
+	tenantID := "tenant-1"
+	address := "reset@example.com"
+	password := "SecurePassword123"
+
+	user, err := s.ProvisionIdentity(ctx, tenantID, address, Profile{})
+	if err != nil {
+		t.Fatalf("failed to provision: %v", err)
+	}
+	if err := s.AddPassword(ctx, user.ID, password); err != nil {
+		t.Fatalf("failed to add password: %v", err)
+	}
+	if err := s.ExpirePassword(ctx, tenantID, user.ID, "admin"); err != nil {
+		t.Fatalf("ExpirePassword: %v", err)
+	}
+
+	if err := s.ResetPassword(ctx, "tenant-2", user.ID, "NewSecurePassword456", "cli", false); err != ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound for another tenant, got %v", err)
+	}
+	if err := s.ResetPassword(ctx, tenantID, user.ID, "weak", "cli", false); err != ErrWeakPassword {
+		t.Errorf("expected ErrWeakPassword, got %v", err)
+	}
+	if err := s.ResetPassword(ctx, tenantID, user.ID, "NewSecurePassword456", "cli", false); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if _, err := s.Authenticate(ctx, tenantID, address, password); err != ErrInvalidCredentials {
+		t.Errorf("expected the old password to fail, got %v", err)
+	}
+	if reset, err := s.Authenticate(ctx, tenantID, address, "NewSecurePassword456"); err != nil || reset.PasswordChangeRequired {
+		t.Errorf("expected an unrestricted login with the reset password, got %+v, %v", reset, err)
+	}
+	if len(*mail) != 1 || (*mail)[0].Kind != email.KindPasswordReset || (*mail)[0].To != address {
+		t.Errorf("expected one password reset notice to the user, got %+v", *mail)
+	}
+
+	// Platform users have no tenant and may have no password yet
+	admin, err := s.ProvisionIdentity(ctx, "", "admin@example.com", Profile{})
+	if err != nil {
+		t.Fatalf("failed to provision platform user: %v", err)
+	}
+	if err := s.ResetPassword(ctx, tenantID, admin.ID, "AdminPassword789", "cli", false); err != ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound for a platform user in a tenant, got %v", err)
+	}
+	if err := s.ResetPassword(ctx, "", admin.ID, "AdminPassword789", "cli", false); err != nil {
+		t.Fatalf("ResetPassword for platform user: %v", err)
+	}
+	if _, err := s.Authenticate(ctx, "", "admin@example.com", "AdminPassword789"); err != nil {
+		t.Errorf("expected platform user login, got %v", err)
+	}
+}
+
+// TestPurpose: Validates temporary passwords and the maximum password age of a tenant.
+// Scope: Unit Test
+// Security: Credential lifecycle; a temporary or aged password signs in only to be replaced (CWE-262)
+// Expected: A password reset with mustChange and a password older than the tenant's maximum age sign in with a required change, which a new password clears; other tenants and passwords without a timestamp are unaffected.
+// Test Case ID: IDN-09
+func TestIdentity_Service_PasswordChangeRequired(t *testing.T) {
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(65536, 3, 4, 16, 32)
+	s := NewService(repo, hasher, audit.NewSlogLogger(), 5, 5*time.Minute)
+	s.SetPasswordAgePolicy(func(ctx context.Context, tenantID string) time.Duration {
+		if tenantID == "strict" {
+			return 24 * time.Hour
+		}
+		return 0
+	})
+	ctx := context.Background()
+
+	user, err := s.ProvisionIdentity(ctx, "strict", "temp@example.com", Profile{})
+	if err != nil {
+		t.Fatalf("failed to provision: %v", err)
+	}
+	if err := s.ResetPassword(ctx, "strict", user.ID, "TemporaryPassword1", "admin", true); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	temp, err := s.Authenticate(ctx, "strict", "temp@example.com", "TemporaryPassword1")
+	if err != nil || !temp.PasswordChangeRequired {
+		t.Fatalf("expected a temporary password to require a change, got %+v, %v", temp, err)
+	}
+	if err := s.ChangePassword(ctx, user.ID, "TemporaryPassword1", "TemporaryPassword1"); err != ErrPasswordReused {
+		t.Errorf("expected ErrPasswordReused, got %v", err)
+	}
+	if err := s.ChangePassword(ctx, user.ID, "TemporaryPassword1", "OwnSecurePassword2"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if own, err := s.Authenticate(ctx, "strict", "temp@example.com", "OwnSecurePassword2"); err != nil || own.PasswordChangeRequired {
+		t.Errorf("expected an unrestricted login after the change, got %+v, %v", own, err)
+	}
+
+	// The password outlives the tenant's maximum age
+	repo.credentials[user.ID].UpdatedAt = time.Now().Add(-25 * time.Hour)
+	if aged, err := s.Authenticate(ctx, "strict", "temp@example.com", "OwnSecurePassword2"); err != nil || !aged.PasswordChangeRequired {
+		t.Errorf("expected an aged password to require a change, got %+v, %v", aged, err)
+	}
+
+	// Tenants without a maximum age keep passwords forever, and passwords of unknown age are not expired
+	other, _ := s.ProvisionIdentity(ctx, "relaxed", "old@example.com", Profile{})
+	if err := s.AddPassword(ctx, other.ID, "SecurePassword123"); err != nil {
+		t.Fatalf("failed to add password: %v", err)
+	}
+	repo.credentials[other.ID].UpdatedAt = time.Now().Add(-365 * 24 * time.Hour)
+	if old, err := s.Authenticate(ctx, "relaxed", "old@example.com", "SecurePassword123"); err != nil || old.PasswordChangeRequired {
+		t.Errorf("expected no change without a maximum age, got %+v, %v", old, err)
+	}
+	repo.credentials[user.ID].UpdatedAt = time.Time{}
+	if unknown, err := s.Authenticate(ctx, "strict", "temp@example.com", "OwnSecurePassword2"); err != nil || unknown.PasswordChangeRequired {
+		t.Errorf("expected no change for a password of unknown age, got %+v, %v", unknown, err)
+	}
+}
+
+// TestPurpose: Validates that authentication is traced with password verification as a child span.
+// Scope: Unit Test
+// Security: Observability (spans carry the tenant but never the email or password)
+// Expected: identity.VerifyPassword is a child of identity.Authenticate; a wrong password marks the span as failed.
+// Test Case ID: IDN-06
+func TestIdentity_Service_Authenticate_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	s := NewService(NewMockUserRepository(), NewPasswordHasher(65536, 3, 4, 16, 32), audit.NewSlogLogger(), 3, 5*time.Minute)
+	ctx := context.Background()
+	user, err := s.ProvisionIdentity(ctx, "tenant-1", "test@example.com", Profile{FullName: "Test User"})
+	if err != nil {
+		t.Fatalf("failed to provision: %v", err)
+	}
+	if err := s.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatalf("failed to add password: %v", err)
+	}
+
+	if _, err := s.Authenticate(ctx, "tenant-1", "test@example.com", "WrongPassword"); err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	auth, verify := spans["identity.Authenticate"], spans["identity.VerifyPassword"]
+	if auth == nil || verify == nil {
+		t.Fatalf("expected authenticate and verify spans, got %v", spans)
+	}
+	if verify.Parent().SpanID() != auth.SpanContext().SpanID() {
+		t.Error("expected identity.VerifyPassword to be a child of identity.Authenticate")
+	}
+	if auth.Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", auth.Status().Code)
+	}
+	for _, attr := range auth.Attributes() {
+		if attr.Value.AsString() == "test@example.com" {
+			t.Errorf("span attribute %s leaks the email", attr.Key)
+		}
+	}
+}
+
+// TestPurpose: Validates that a tenant's lockout policy replaces the service's lockout threshold and duration.
+// Scope: Unit Test
+// Security: Brute-force protection (CWE-307) tightened per tenant
+// Expected: A user of a tenant with a policy is locked after the tenant's number of failures, for the tenant's duration; users of other tenants and unset values keep the service's.
+// Test Case ID: IDN-07
+func TestIdentity_Service_TenantLockoutPolicy(t *testing.T) {
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(65536, 3, 4, 16, 32)
+	s := NewService(repo, hasher, audit.NewSlogLogger(), 5, 5*time.Minute)
+	s.SetLockoutPolicy(func(ctx context.Context, tenantID string) (int, time.Duration) {
+		if tenantID == "strict" {
+			return 2, time.Hour
+		}
+		return 0, 0
+	})
+	ctx := context.Background()
+
+	provision := func(tenantID string) *User {
+		user, err := s.ProvisionIdentity(ctx, tenantID, "user@example.com", Profile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	strict, relaxed := provision("strict"), provision("relaxed")
+
+	for range 2 {
+		s.Authenticate(ctx, "strict", "user@example.com", "WrongPassword")
+		s.Authenticate(ctx, "relaxed", "user@example.com", "WrongPassword")
+	}
+	if _, err := s.Authenticate(ctx, "strict", "user@example.com", "SecurePassword123"); err != ErrAccountLocked {
+		t.Errorf("expected the tenant's threshold to lock the account, got %v", err)
+	}
+	if lockout, _ := s.GetLockout(ctx, "strict", strict.ID); lockout.LockedUntil == nil || time.Until(*lockout.LockedUntil) < 55*time.Minute {
+		t.Errorf("expected the tenant's lockout duration, got %+v", lockout)
+	}
+	if _, err := s.Authenticate(ctx, "relaxed", "user@example.com", "SecurePassword123"); err != nil {
+		t.Errorf("expected a tenant without a policy to keep the service's threshold, got %v", err)
+	}
+	if lockout, _ := s.GetLockout(ctx, "relaxed", relaxed.ID); lockout.LockedUntil != nil {
+		t.Errorf("expected no lockout, got %+v", lockout)
+	}
+}
+
+// mockEmailChanges is an in-memory EmailChangeRepository
+type mockEmailChanges map[string]*EmailChange
+
+func (m mockEmailChanges) Create(ctx context.Context, change *EmailChange) error {
+	for id, c := range m {
+		if c.UserID == change.UserID {
+			delete(m, id)
+		}
+	}
+	c := *change
+	m[c.ID] = &c
+	return nil
+}
+
+func (m mockEmailChanges) find(match func(c *EmailChange) bool) (*EmailChange, error) {
+	for _, c := range m {
+		if match(c) {
+			cp := *c
+			return &cp, nil
+		}
+	}
+	return nil, ErrEmailChangeNotFound
+}
+
+func (m mockEmailChanges) GetByUser(ctx context.Context, userID string) (*EmailChange, error) {
+	return m.find(func(c *EmailChange) bool { return c.UserID == userID })
+}
+
+func (m mockEmailChanges) GetByTokenHash(ctx context.Context, tokenHash string) (*EmailChange, error) {
+	return m.find(func(c *EmailChange) bool { return c.TokenHash == tokenHash })
+}
+
+func (m mockEmailChanges) Update(ctx context.Context, change *EmailChange) error {
+	c := *change
+	m[c.ID] = &c
+	return nil
+}
+
+func (m mockEmailChanges) Delete(ctx context.Context, id string) error {
+	if _, ok := m[id]; !ok {
+		return ErrEmailChangeNotFound
+	}
+	delete(m, id)
+	return nil
+}
+
+func (m mockEmailChanges) ListDue(ctx context.Context, before time.Time, limit int) ([]*EmailChange, error) {
+	var due []*EmailChange
+	for _, c := range m {
+		if c.Status == EmailChangeVerified && c.ExpiresAt.Before(before) && len(due) < limit {
+			cp := *c
+			due = append(due, &cp)
+		}
+	}
+	return due, nil
+}
+
+func (m mockEmailChanges) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	return 0, nil
+}
+
+// mailbox records the messages sent through it
+type mailbox []email.Message
+
+func (b *mailbox) Send(ctx context.Context, tenantID string, msg email.Message) error {
+	*b = append(*b, msg)
+	return nil
+}
+
+var linkPattern = regexp.MustCompile(`https://\S+`)
+
+// token returns the token of the link in the last message sent to "to"
+func (b *mailbox) token(t *testing.T, to string) string {
+	t.Helper()
+	for i := len(*b) - 1; i >= 0; i-- {
+		if msg := (*b)[i]; msg.To == to {
+			link, err := url.Parse(linkPattern.FindString(msg.TextBody))
+			if err != nil || link.Query().Get("token") == "" {
+				t.Fatalf("expected a link in %q", msg.TextBody)
+			}
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("expected a message to %s", to)
+	return ""
+}
+
+// TestPurpose: Validates the email change flow: verification of the new address, notice to the old one and the cooling-off window.
+// Scope: Unit Test
+// Security: Account takeover protection (CWE-640); the address and the email claim change only after the new address is verified, and the old address can revert a change
+// Expected: A request needs the current password and a free address; the address and email claim change only once the mailed token is verified; the old address is told, and during a cooling-off window can revert the change, which otherwise applies when the window ends; tokens work once.
+// Test Case ID: IDN-08
+func TestIdentity_Service_EmailChange(t *testing.T) {
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(65536, 3, 4, 16, 32)
+	s := NewService(repo, hasher, audit.NewSlogLogger(), 5, 5*time.Minute)
+	ctx := context.Background()
+
+	if _, err := s.RequestEmailChange(ctx, "u1", "new@example.com", "SecurePassword123"); !errors.Is(err, ErrEmailChangeUnavailable) {
+		t.Errorf("expected email changes to be unavailable until enabled, got %v", err)
+	}
+	changes, mail := mockEmailChanges{}, &mailbox{}
+	s.EnableEmailChange(changes, mail, EmailChangeConfig{URL: "https://console.example.com/email-change"})
+
+	user, err := s.ProvisionIdentity(ctx, "t1", "old@example.com", Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ProvisionIdentity(ctx, "t1", "taken@example.com", Profile{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RequestEmailChange(ctx, user.ID, "new@example.com", "WrongPassword"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected the current password to be required, got %v", err)
+	}
+	if _, err := s.RequestEmailChange(ctx, user.ID, "taken@example.com", "SecurePassword123"); !errors.Is(err, ErrUserAlreadyExists) {
+		t.Errorf("expected an address in use to be refused, got %v", err)
+	}
+	if _, err := s.RequestEmailChange(ctx, user.ID, "OLD@example.com", "SecurePassword123"); !errors.Is(err, ErrEmailUnchanged) {
+		t.Errorf("expected the current address to be refused, got %v", err)
+	}
+
+	change, err := s.RequestEmailChange(ctx, user.ID, "new@example.com", "SecurePassword123")
+	if err != nil || change.Status != EmailChangePending {
+		t.Fatalf("RequestEmailChange: %+v, %v", change, err)
+	}
+	token := mail.token(t, "new@example.com")
+	if claims, _ := s.UserClaims(ctx, user.ID); claims["email"] != "old@example.com" {
+		t.Errorf("expected the email claim to wait for verification, got %v", claims["email"])
+	}
+	if _, err := s.VerifyEmailChange(ctx, "forged"); !errors.Is(err, ErrEmailChangeNotFound) {
+		t.Errorf("expected an unknown token to be refused, got %v", err)
+	}
+
+	if _, err := s.VerifyEmailChange(ctx, token); err != nil {
+		t.Fatalf("VerifyEmailChange: %v", err)
+	}
+	if claims, _ := s.UserClaims(ctx, user.ID); claims["email"] != "new@example.com" || claims["email_verified"] != true {
+		t.Errorf("expected the verified address in the claims, got %v", claims)
+	}
+	if notice := (*mail)[len(*mail)-1]; notice.To != "old@example.com" || notice.Kind != email.KindEmailChangeNotice {
+		t.Errorf("expected the old address to be told, got %+v", notice)
+	}
+	if _, err := s.VerifyEmailChange(ctx, token); !errors.Is(err, ErrEmailChangeNotFound) {
+		t.Errorf("expected a token to work once, got %v", err)
+	}
+
+	// With a cooling-off window, the old address may revert a verified change until it applies
+	s.EnableEmailChange(changes, mail, EmailChangeConfig{URL: "https://console.example.com/email-change", CoolingOff: time.Hour})
+	if _, err := s.RequestEmailChange(ctx, user.ID, "third@example.com", "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	change, err = s.VerifyEmailChange(ctx, mail.token(t, "third@example.com"))
+	if err != nil || change.Status != EmailChangeVerified {
+		t.Fatalf("expected the change to wait for the cooling-off window, got %+v, %v", change, err)
+	}
+	if claims, _ := s.UserClaims(ctx, user.ID); claims["email"] != "new@example.com" {
+		t.Errorf("expected the address to stay during the window, got %v", claims["email"])
+	}
+	if _, err := s.RevertEmailChange(ctx, mail.token(t, "new@example.com")); err != nil {
+		t.Fatalf("RevertEmailChange: %v", err)
+	}
+	if _, err := s.GetEmailChange(ctx, user.ID); !errors.Is(err, ErrEmailChangeNotFound) {
+		t.Errorf("expected the reverted change to be gone, got %v", err)
+	}
+
+	if _, err := s.RequestEmailChange(ctx, user.ID, "third@example.com", "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyEmailChange(ctx, mail.token(t, "third@example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.ApplyEmailChanges(ctx, 10); err != nil || n != 0 {
+		t.Errorf("expected nothing to apply during the window, got %d, %v", n, err)
+	}
+	for _, c := range changes {
+		c.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	if n, err := s.ApplyEmailChanges(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("expected the change to apply once the window ended, got %d, %v", n, err)
+	}
+	if claims, _ := s.UserClaims(ctx, user.ID); claims["email"] != "third@example.com" {
+		t.Errorf("expected the new address once the window ended, got %v", claims["email"])
+	}
+}
+
+// mockAccountDeletions stores account deletions by user ID
+type mockAccountDeletions map[string]*AccountDeletion
+
+func (m mockAccountDeletions) Create(ctx context.Context, deletion *AccountDeletion) error {
+	d := *deletion
+	m[d.UserID] = &d
+	return nil
+}
+
+func (m mockAccountDeletions) Get(ctx context.Context, userID string) (*AccountDeletion, error) {
+	d, ok := m[userID]
+	if !ok {
+		return nil, ErrAccountDeletionNotFound
+	}
+	return d, nil
+}
+
+func (m mockAccountDeletions) Delete(ctx context.Context, userID string) error {
+	if _, ok := m[userID]; !ok {
+		return ErrAccountDeletionNotFound
+	}
+	delete(m, userID)
+	return nil
+}
+
+func (m mockAccountDeletions) ListDue(ctx context.Context, before time.Time, limit int) ([]*AccountDeletion, error) {
+	var due []*AccountDeletion
+	for _, d := range m {
+		if d.DeleteAt.Before(before) && len(due) < limit {
+			cp := *d
+			due = append(due, &cp)
+		}
+	}
+	return due, nil
+}
+
+// TestPurpose: Validates account self-deletion: re-authentication, the grace period a login cancels, and the erasure that follows.
+// Scope: Unit Test
+// Security: Right to erasure (GDPR Art. 17) without letting a hijacked session delete an account (CWE-306); the password is required and the owner keeps the account by logging in
+// Expected: A request needs the current password; logging in or cancelling during the grace period keeps the account; once the period ends the erasure hooks run, the record is anonymized and its credentials removed; a failing hook leaves the deletion to be retried; without a grace period the account is erased at once.
+// Test Case ID: IDN-10
+func TestIdentity_Service_AccountDeletion(t *testing.T) {
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(65536, 3, 4, 16, 32)
+	s := NewService(repo, hasher, audit.NewSlogLogger(), 5, 5*time.Minute)
+	ctx := context.Background()
+
+	if _, err := s.RequestAccountDeletion(ctx, "u1", "SecurePassword123"); !errors.Is(err, ErrAccountDeletionUnavailable) {
+		t.Errorf("expected account deletion to be unavailable until enabled, got %v", err)
+	}
+	var erased []string
+	var hookErr error
+	deletions := mockAccountDeletions{}
+	s.EnableAccountDeletion(deletions, time.Hour, func(ctx context.Context, userID string) error {
+		if hookErr != nil {
+			return hookErr
+		}
+		erased = append(erased, userID)
+		return nil
+	})
+
+	user, err := s.ProvisionIdentity(ctx, "t1", "leaving@example.com", Profile{GivenName: "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RequestAccountDeletion(ctx, user.ID, "WrongPassword"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected the current password to be required, got %v", err)
+	}
+
+	// Logging in during the grace period keeps the account
+	deletion, err := s.RequestAccountDeletion(ctx, user.ID, "SecurePassword123")
+	if err != nil || !deletion.DeleteAt.After(deletion.RequestedAt) {
+		t.Fatalf("RequestAccountDeletion: %+v, %v", deletion, err)
+	}
+	if _, err := s.Authenticate(ctx, "t1", "leaving@example.com", "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetAccountDeletion(ctx, user.ID); !errors.Is(err, ErrAccountDeletionNotFound) {
+		t.Errorf("expected a login to cancel the deletion, got %v", err)
+	}
+
+	if _, err := s.RequestAccountDeletion(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CancelAccountDeletion(ctx, user.ID); err != nil {
+		t.Fatalf("CancelAccountDeletion: %v", err)
+	}
+	if err := s.CancelAccountDeletion(ctx, user.ID); !errors.Is(err, ErrAccountDeletionNotFound) {
+		t.Errorf("expected nothing left to cancel, got %v", err)
+	}
+
+	if _, err := s.RequestAccountDeletion(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.EraseDueAccounts(ctx, 10); err != nil || n != 0 {
+		t.Errorf("expected nothing to erase during the grace period, got %d, %v", n, err)
+	}
+	deletions[user.ID].DeleteAt = time.Now().Add(-time.Second)
+
+	// A failing hook keeps the deletion for the next run
+	hookErr = errors.New("session store down")
+	if n, err := s.EraseDueAccounts(ctx, 10); err == nil || n != 0 {
+		t.Errorf("expected the hook failure to be reported, got %d, %v", n, err)
+	}
+	if _, err := s.GetAccountDeletion(ctx, user.ID); err != nil {
+		t.Errorf("expected the deletion to be kept for a retry, got %v", err)
+	}
+
+	hookErr = nil
+	if n, err := s.EraseDueAccounts(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("expected the account to be erased once the period ended, got %d, %v", n, err)
+	}
+	if len(erased) != 1 || erased[0] != user.ID {
+		t.Errorf("expected the erasure hooks to run for the user, got %v", erased)
+	}
+	got, _ := repo.GetByID(ctx, user.ID)
+	if got.Email == "leaving@example.com" || got.Profile.GivenName != "" || got.DeletedAt == nil {
+		t.Errorf("expected the user to be anonymized and deleted, got %+v", got)
+	}
+	if _, err := s.Authenticate(ctx, "t1", "leaving@example.com", "SecurePassword123"); err == nil {
+		t.Error("expected an erased account to be unable to log in")
+	}
+
+	// Without a grace period the account is erased at once
+	s.EnableAccountDeletion(deletions, 0)
+	other, err := s.ProvisionIdentity(ctx, "t1", "now@example.com", Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPassword(ctx, other.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RequestAccountDeletion(ctx, other.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetByID(ctx, other.ID); got.DeletedAt == nil {
+		t.Error("expected the account to be erased at once without a grace period")
+	}
+}
+
+// TestPurpose: Validates the guards on account self-deletion: password lockout and the deletion guard.
+// Scope: Unit Test
+// Security: Brute force protection (CWE-307) on the deletion password check; a tenant keeps an owner and the platform an admin
+// Expected: Wrong passwords count towards lockout as at login, after which even the right password is refused with ErrAccountLocked; an account refused by the deletion guard gets its error and no deletion is scheduled; the guard is checked again at erasure, a user who became the last owner keeps the account and a failing check postpones the erasure.
+// Test Case ID: IDN-12
+func TestIdentity_Service_AccountDeletionGuards(t *testing.T) {
+	repo := NewMockUserRepository()
+	s := NewService(repo, NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 2, time.Hour)
+	ctx := context.Background()
+	deletions := mockAccountDeletions{}
+	s.EnableAccountDeletion(deletions, time.Hour)
+
+	provision := func(email string) *User {
+		user, err := s.ProvisionIdentity(ctx, "t1", email, Profile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+
+	guessed := provision("guessed@example.com")
+	for range 2 {
+		if _, err := s.RequestAccountDeletion(ctx, guessed.ID, "WrongPassword"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("expected a wrong password to be refused, got %v", err)
+		}
+	}
+	if _, err := s.RequestAccountDeletion(ctx, guessed.ID, "SecurePassword123"); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("expected the account to be locked after repeated wrong passwords, got %v", err)
+	}
+	if _, err := s.Authenticate(ctx, "t1", "guessed@example.com", "SecurePassword123"); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("expected the lockout to apply to login too, got %v", err)
+	}
+
+	owner := provision("owner@example.com")
+	guardErrs := map[string]error{owner.ID: ErrLastAdministrator}
+	s.SetDeletionGuard(func(ctx context.Context, user *User) error {
+		return guardErrs[user.ID]
+	})
+	if _, err := s.RequestAccountDeletion(ctx, owner.ID, "SecurePassword123"); !errors.Is(err, ErrLastAdministrator) {
+		t.Errorf("expected the guard to refuse the deletion, got %v", err)
+	}
+	if _, err := s.GetAccountDeletion(ctx, owner.ID); !errors.Is(err, ErrAccountDeletionNotFound) {
+		t.Errorf("expected no deletion to be scheduled, got %v", err)
+	}
+	member := provision("member@example.com")
+	if _, err := s.RequestAccountDeletion(ctx, member.ID, "SecurePassword123"); err != nil {
+		t.Errorf("expected an account the guard allows to be deleted, got %v", err)
+	}
+
+	// The guard is checked again when the grace period ends: a failing check postpones the erasure,
+	// and a member who became the last owner meanwhile keeps the account
+	deletions[member.ID].DeleteAt = time.Now().Add(-time.Second)
+	guardErrs[member.ID] = errors.New("database down")
+	if n, err := s.EraseDueAccounts(ctx, 10); err == nil || n != 0 {
+		t.Errorf("expected the guard failure to be reported, got %d, %v", n, err)
+	}
+	if _, err := s.GetAccountDeletion(ctx, member.ID); err != nil {
+		t.Errorf("expected the erasure to be postponed, got %v", err)
+	}
+	guardErrs[member.ID] = ErrLastAdministrator
+	if n, err := s.EraseDueAccounts(ctx, 10); err != nil || n != 0 {
+		t.Errorf("expected the last owner not to be erased, got %d, %v", n, err)
+	}
+	if _, err := s.GetAccountDeletion(ctx, member.ID); !errors.Is(err, ErrAccountDeletionNotFound) {
+		t.Errorf("expected the deletion of the last owner to be cancelled, got %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, member.ID); got.DeletedAt != nil {
+		t.Error("expected the last owner to keep the account")
+	}
+}
+
+// TestPurpose: Validates the checks on the profile fields released as OIDC standard claims and their release.
+// Scope: Unit Test
+// Security: Input validation (CWE-20); a profile URL cannot carry a javascript: or data: scheme into relying parties
+// Expected: Picture and website must be http(s) URLs, locale a BCP 47 tag, zoneinfo an IANA zone and birthdate a past full date, a date without year or a year; invalid profiles are refused on update with ErrInvalidProfile; set fields appear in the user claims and empty ones are left out.
+// Test Case ID: IDN-11
+func TestIdentity_Service_ProfileClaims(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		profile Profile
+		valid   bool
+	}{
+		{"empty", Profile{}, true},
+		{"complete", Profile{Picture: "https://example.com/a.png", Website: "http://example.com", Locale: "zh-Hant-TW", Timezone: "America/New_York", Birthdate: "1990-02-28"}, true},
+		{"year withheld on a leap day", Profile{Birthdate: "0000-02-29"}, true},
+		{"year only", Profile{Birthdate: "1990"}, true},
+		{"script URL", Profile{Picture: "javascript:alert(1)"}, false},
+		{"relative website", Profile{Website: "/about"}, false},
+		{"underscore locale", Profile{Locale: "en_US"}, false},
+		{"unknown zone", Profile{Timezone: "Mars/Olympus"}, false},
+		{"local zone", Profile{Timezone: "Local"}, false},
+		{"future birthdate", Profile{Birthdate: time.Now().AddDate(1, 0, 0).Format(time.DateOnly)}, false},
+		{"impossible date", Profile{Birthdate: "1990-02-30"}, false},
+		{"year zero", Profile{Birthdate: "0000"}, false},
+		{"long name", Profile{GivenName: strings.Repeat("a", 256)}, false},
+	} {
+		if err := tc.profile.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tc.name, tc.valid, err)
+		} else if err != nil && !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("%s: expected ErrInvalidProfile, got %v", tc.name, err)
+		}
+	}
+
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(65536, 3, 4, 16, 32)
+	s := NewService(repo, hasher, audit.NewSlogLogger(), 5, 5*time.Minute)
+	ctx := context.Background()
+
+	user, err := s.ProvisionIdentity(ctx, "t1", "ada@example.com", Profile{GivenName: "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateProfile(ctx, user.ID, Profile{Website: "ftp://example.com"}, 0); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("expected an invalid profile to be refused, got %v", err)
+	}
+	if _, err := s.UpdateProfile(ctx, user.ID, Profile{GivenName: "Ada", Website: "https://ada.example.com", Birthdate: "1815-12-10", Timezone: "Europe/London"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := s.UserClaims(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["website"] != "https://ada.example.com" || claims["birthdate"] != "1815-12-10" || claims["zoneinfo"] != "Europe/London" {
+		t.Errorf("expected the profile claims, got %v", claims)
+	}
+	if _, ok := claims["picture"]; ok {
+		t.Errorf("expected an empty picture to be left out, got %v", claims)
+	}
+}
@@ -15,18 +15,24 @@
 package identity
 
 import (
-	"errors"
+	"context"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
 )
 
 // Domain errors
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrInvalidEmail       = errors.New("invalid email address")
-	ErrWeakPassword       = errors.New("password does not meet security requirements")
-	ErrAccountLocked      = errors.New("account is locked")
+	ErrUserNotFound        = apperr.New(apperr.CodeNotFound, "user not found")
+	ErrUserAlreadyExists   = apperr.New(apperr.CodeAlreadyExists, "user already exists")
+	ErrUserVersionConflict = apperr.New(apperr.CodeConflict, "user was modified concurrently")
+	ErrInvalidCredentials  = apperr.New(apperr.CodeUnauthenticated, "invalid credentials")
+	ErrInvalidEmail        = apperr.New(apperr.CodeInvalidArgument, "invalid email address")
+	ErrWeakPassword        = apperr.New(apperr.CodeInvalidArgument, "password does not meet security requirements")
+	ErrAccountLocked       = apperr.New(apperr.CodePermissionDenied, "account is locked")
+	ErrPasswordReused      = apperr.New(apperr.CodeInvalidArgument, "new password must differ from the current one")
+	ErrNoPassword          = apperr.New(apperr.CodeFailedPrecondition, "user has no password")
+	ErrInvalidProfile      = apperr.New(apperr.CodeInvalidArgument, "invalid profile")
 )
 
 // Platform Authorization Principles:
@@ -47,9 +53,13 @@ type User struct {
 	Profile             Profile
 	FailedLoginAttempts int
 	LockedUntil         *time.Time
+	Version             int // Incremented on every Update; Update requires it to match the stored version
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 	DeletedAt           *time.Time
+	// PasswordChangeRequired is set by Authenticate when the password has expired, was set by an
+	// admin as temporary or is older than the tenant allows; it is not stored
+	PasswordChangeRequired bool
 }
 
 // Profile represents user profile information
@@ -59,8 +69,10 @@ type Profile struct {
 	FullName   string
 	Nickname   string
 	Picture    string
-	Locale     string
-	Timezone   string
+	Locale     string // BCP 47 language tag, released as the locale claim
+	Timezone   string // IANA time zone, released as the zoneinfo claim
+	Website    string
+	Birthdate  string // YYYY-MM-DD, 0000-MM-DD when the year is withheld, or YYYY alone
 }
 
 // Credentials represents user authentication credentials
@@ -68,34 +80,51 @@ type Credentials struct {
 	UserID       string
 	PasswordHash string
 	UpdatedAt    time.Time
+	ExpiresAt    *time.Time // Set when an admin forces a password change or sets a temporary password
 }
 
 // UserRepository defines the interface for user persistence
 type UserRepository interface {
 	// Create creates a new user identity
-	Create(user *User) error
+	Create(ctx context.Context, user *User) error
 
 	// AddCredentials adds credentials for a user
-	AddCredentials(credentials *Credentials) error
+	AddCredentials(ctx context.Context, credentials *Credentials) error
 
 	// GetByID retrieves a user by ID
-	GetByID(id string) (*User, error)
+	GetByID(ctx context.Context, id string) (*User, error)
 
 	// GetByEmail retrieves a user by email within a tenant (or no tenant for Platform Admins)
-	GetByEmail(tenantID *string, email string) (*User, error)
+	GetByEmail(ctx context.Context, tenantID *string, email string) (*User, error)
+
+	// ListByEmail retrieves the users registered with email in any tenant, platform admins
+	// included, oldest first
+	ListByEmail(ctx context.Context, email string) ([]*User, error)
 
-	// Update updates user information
-	Update(user *User) error
+	// Update updates user information if user.Version still matches the stored version,
+	// returning ErrUserVersionConflict otherwise. On success user.Version is incremented.
+	Update(ctx context.Context, user *User) error
 
 	// UpdateLockout updates user lockout status
-	UpdateLockout(userID string, failedAttempts int, lockedUntil *time.Time) error
+	UpdateLockout(ctx context.Context, userID string, failedAttempts int, lockedUntil *time.Time) error
 
 	// Delete soft-deletes a user
-	Delete(id string) error
+	Delete(ctx context.Context, id string) error
 
 	// GetCredentials retrieves user credentials
-	GetCredentials(userID string) (*Credentials, error)
+	GetCredentials(ctx context.Context, userID string) (*Credentials, error)
+
+	// UpdatePassword updates user password and clears its expiry
+	UpdatePassword(ctx context.Context, userID string, passwordHash string) error
+
+	// ExpirePassword marks user password as expired from the given time
+	ExpirePassword(ctx context.Context, userID string, at time.Time) error
+
+	// Erase anonymizes a user in one transaction: it replaces the email with anonEmail, clears the
+	// profile, removes the credentials, role assignments, tenant memberships and project memberships,
+	// and soft-deletes the user from the given time
+	Erase(ctx context.Context, userID, anonEmail string, at time.Time) error
 
-	// UpdatePassword updates user password
-	UpdatePassword(userID string, passwordHash string) error
+	// PurgeDeleted permanently removes up to limit users soft-deleted before the given time
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
 }
@@ -0,0 +1,174 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package janitor periodically purges expired OAuth2 artifacts, expired sessions
+// and soft-deleted rows that are past their retention period.
+package janitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/jobs"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Config controls how often and how aggressively the janitor runs
+type Config struct {
+	// Interval is the base delay between runs
+	Interval time.Duration
+	// Jitter adds a random delay in [0, Jitter) to each interval so replicas do not run in lockstep
+	Jitter time.Duration
+	// BatchSize caps the rows removed by a single delete statement
+	BatchSize int
+}
+
+// PurgeFunc removes up to limit rows and returns how many were removed
+type PurgeFunc func(ctx context.Context, limit int) (int64, error)
+
+// Task is a named purge operation
+type Task struct {
+	Name  string
+	Purge PurgeFunc
+}
+
+// Janitor runs purge tasks on a schedule
+type Janitor struct {
+	cfg      Config
+	tasks    []Task
+	purged   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// New creates a janitor for the given tasks
+func New(cfg Config, tasks []Task, meter *metrics.Meter) (*Janitor, error) {
+	purged, err := meter.CreateCounter("janitor.purged_rows", "Rows removed by the janitor")
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.CreateHistogram("janitor.task.duration", "Duration of a janitor task", "s")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Janitor{
+		cfg:      cfg,
+		tasks:    tasks,
+		purged:   purged,
+		duration: duration,
+	}, nil
+}
+
+// Run executes all tasks every interval until ctx is cancelled
+func (j *Janitor) Run(ctx context.Context) {
+	timer := time.NewTimer(j.nextDelay())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			_, _ = j.RunOnce(ctx)
+			timer.Reset(j.nextDelay())
+		}
+	}
+}
+
+// Job returns the janitor as a background job for a jobs.Runner
+func (j *Janitor) Job() jobs.Job {
+	return jobs.Job{
+		Name:     "janitor",
+		Interval: j.cfg.Interval,
+		Jitter:   j.cfg.Jitter,
+		Run:      j.RunOnce,
+	}
+}
+
+// RunOnce executes every task once and returns the total rows removed. A failing task is logged
+// and does not stop the others; the errors of all failed tasks are returned together.
+func (j *Janitor) RunOnce(ctx context.Context) (int64, error) {
+	// Purges span every tenant
+	ctx = tenant.WithoutScope(ctx)
+	var (
+		total int64
+		errs  []error
+	)
+	for _, task := range j.tasks {
+		if ctx.Err() != nil {
+			break
+		}
+
+		start := time.Now()
+		n, err := j.runTask(ctx, task)
+		elapsed := time.Since(start)
+
+		attrs := metric.WithAttributes(attribute.String("task", task.Name))
+		j.purged.Add(ctx, n, attrs)
+		j.duration.Record(ctx, elapsed.Seconds(), attrs)
+		total += n
+
+		if err != nil {
+			slog.ErrorContext(ctx, "janitor task failed",
+				logger.Component("janitor"),
+				logger.Operation(task.Name),
+				logger.RowsAffected(n),
+				logger.Error(err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", task.Name, err))
+			continue
+		}
+		if n > 0 {
+			slog.InfoContext(ctx, "janitor task completed",
+				logger.Component("janitor"),
+				logger.Operation(task.Name),
+				logger.RowsAffected(n),
+				logger.Duration(elapsed.Milliseconds()),
+			)
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// runTask purges in batches until a batch comes back short
+func (j *Janitor) runTask(ctx context.Context, task Task) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		n, err := task.Purge(ctx, j.cfg.BatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(j.cfg.BatchSize) {
+			break
+		}
+	}
+	return total, nil
+}
+
+// nextDelay returns the interval plus a random jitter
+func (j *Janitor) nextDelay() time.Duration {
+	if j.cfg.Jitter <= 0 {
+		return j.cfg.Interval
+	}
+	return j.cfg.Interval + rand.N(j.cfg.Jitter)
+}
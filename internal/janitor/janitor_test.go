@@ -0,0 +1,169 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package janitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJanitor(t *testing.T, cfg Config, tasks []Task) *Janitor {
+	t.Helper()
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	require.NoError(t, err)
+	j, err := New(cfg, tasks, meter)
+	require.NoError(t, err)
+	return j
+}
+
+// fakeRows simulates a table holding a number of purgeable rows
+type fakeRows struct {
+	remaining int
+	calls     int
+}
+
+func (f *fakeRows) purge(ctx context.Context, limit int) (int64, error) {
+	f.calls++
+	n := min(limit, f.remaining)
+	f.remaining -= n
+	return int64(n), nil
+}
+
+// TestPurpose: Validates that a task is repeated in batches until the backlog is drained.
+// Scope: Unit Test
+// Security: Availability (bounded delete statements do not hold long locks)
+// Expected: Five rows with a batch size of two are removed in three calls.
+// Test Case ID: JAN-01
+func TestJanitor_RunOnce_Batches(t *testing.T) {
+	rows := &fakeRows{remaining: 5}
+	j := newTestJanitor(t, Config{Interval: time.Hour, BatchSize: 2}, []Task{{Name: "rows", Purge: rows.purge}})
+
+	n, err := j.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5), n)
+	assert.Zero(t, rows.remaining)
+	assert.Equal(t, 3, rows.calls)
+}
+
+// TestPurpose: Validates that one failing task does not prevent the others from running.
+// Scope: Unit Test
+// Security: Data minimisation keeps working when a single table is unavailable
+// Expected: The task after the failing one still drains its rows; the failure is reported with the task name.
+// Test Case ID: JAN-02
+func TestJanitor_RunOnce_ContinuesAfterError(t *testing.T) {
+	failing := func(ctx context.Context, limit int) (int64, error) {
+		return 0, errors.New("table locked")
+	}
+	rows := &fakeRows{remaining: 3}
+	j := newTestJanitor(t, Config{Interval: time.Hour, BatchSize: 10}, []Task{
+		{Name: "failing", Purge: failing},
+		{Name: "rows", Purge: rows.purge},
+	})
+
+	n, err := j.RunOnce(context.Background())
+	assert.ErrorContains(t, err, "failing: table locked")
+
+	assert.Equal(t, int64(3), n)
+	assert.Zero(t, rows.remaining)
+}
+
+// TestPurpose: Validates the store tasks purge expired artifacts and soft-deleted rows past retention.
+// Scope: Unit Test
+// Security: Expired codes and tokens and deleted clients do not accumulate
+// Expected: Expired rows and old soft-deleted clients and projects are removed; live rows remain; zero retention skips soft-delete purging.
+// Test Case ID: JAN-03
+func TestJanitor_StoreTasks(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.Open(ctx, store.Config{Driver: store.DriverMemory})
+	require.NoError(t, err)
+
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	require.NoError(t, st.Codes.Create(ctx, &oauth2.AuthorizationCode{ID: "1", TenantID: "t1", Code: "expired", ExpiresAt: past}))
+	require.NoError(t, st.Codes.Create(ctx, &oauth2.AuthorizationCode{ID: "2", TenantID: "t1", Code: "live", ExpiresAt: future}))
+	require.NoError(t, st.RefreshTokens.Create(ctx, &oauth2.RefreshToken{ID: "1", TenantID: "t1", TokenHash: "expired", ExpiresAt: past}))
+	require.NoError(t, st.Clients.Create(ctx, &oauth2.Client{ID: "c1", ClientID: "client-1", TenantID: "t1"}))
+	require.NoError(t, st.Clients.Delete(ctx, "c1"))
+	require.NoError(t, st.Projects.Create(ctx, &authz.Project{ID: "p1", TenantID: "t1", Name: "p1"}))
+	require.NoError(t, st.Projects.Delete(ctx, "p1"))
+
+	assert.Len(t, StoreTasks(st, 0), 7)
+
+	// A one-nanosecond retention makes the just-deleted client and project old enough to purge
+	j := newTestJanitor(t, Config{Interval: time.Hour, BatchSize: 10}, StoreTasks(st, time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, err = j.RunOnce(ctx)
+	require.NoError(t, err)
+
+	_, err = st.Codes.GetByCode(ctx, "t1", "expired")
+	assert.ErrorIs(t, err, oauth2.ErrCodeNotFound)
+	_, err = st.Codes.GetByCode(ctx, "t1", "live")
+	assert.NoError(t, err)
+	_, err = st.RefreshTokens.GetByTokenHash(ctx, "t1", "expired")
+	assert.ErrorIs(t, err, oauth2.ErrTokenNotFound)
+
+	n, err := st.Clients.PurgeDeleted(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Zero(t, n, "client should already have been purged")
+	n, err = st.Projects.PurgeDeleted(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Zero(t, n, "project should already have been purged")
+}
+
+// TestPurpose: Validates that the scheduler stops when its context is cancelled.
+// Scope: Unit Test
+// Security: N/A (clean shutdown)
+// Expected: Run returns promptly after cancellation.
+// Test Case ID: JAN-04
+func TestJanitor_Run_StopsOnCancel(t *testing.T) {
+	ran := make(chan struct{}, 1)
+	signal := func(ctx context.Context, limit int) (int64, error) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+		return 0, nil
+	}
+	j := newTestJanitor(t, Config{Interval: time.Millisecond, Jitter: time.Millisecond, BatchSize: 10}, []Task{{Name: "signal", Purge: signal}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		j.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("janitor did not run")
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("janitor did not stop after cancellation")
+	}
+}
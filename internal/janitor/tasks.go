@@ -0,0 +1,73 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package janitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/store"
+)
+
+// partitioned is implemented by token repositories that keep tokens in time partitions (PostgreSQL)
+type partitioned interface {
+	MaintainPartitions(ctx context.Context) (int64, error)
+}
+
+// StoreTasks returns the purge tasks for a store.
+// Soft-deleted rows are purged once older than retention; a zero retention keeps them forever.
+// Clients, projects and users are purged before tenants because tenants with remaining users are skipped.
+// Token partitions are maintained before expired token rows are deleted.
+func StoreTasks(st *store.Store, retention time.Duration) []Task {
+	tasks := []Task{
+		{Name: "sessions", Purge: st.Sessions.DeleteExpired},
+		{Name: "authorization_codes", Purge: st.Codes.DeleteExpired},
+	}
+	for _, t := range []struct {
+		name string
+		repo any
+	}{
+		{"refresh_token_partitions", st.RefreshTokens},
+		{"access_token_partitions", st.AccessTokens},
+	} {
+		if p, ok := t.repo.(partitioned); ok {
+			tasks = append(tasks, Task{Name: t.name, Purge: func(ctx context.Context, _ int) (int64, error) {
+				return p.MaintainPartitions(ctx)
+			}})
+		}
+	}
+	tasks = append(tasks,
+		Task{Name: "refresh_tokens", Purge: st.RefreshTokens.DeleteExpired},
+		Task{Name: "access_tokens", Purge: st.AccessTokens.DeleteExpired},
+		Task{Name: "replay_markers", Purge: st.Replay.DeleteExpired},
+		Task{Name: "pending_authorizations", Purge: st.PendingAuthorizations.DeleteExpired},
+		Task{Name: "email_changes", Purge: st.EmailChanges.DeleteExpired},
+	)
+	if retention <= 0 {
+		return tasks
+	}
+
+	softDeleted := func(purge func(ctx context.Context, before time.Time, limit int) (int64, error)) PurgeFunc {
+		return func(ctx context.Context, limit int) (int64, error) {
+			return purge(ctx, time.Now().Add(-retention), limit)
+		}
+	}
+	return append(tasks,
+		Task{Name: "deleted_clients", Purge: softDeleted(st.Clients.PurgeDeleted)},
+		Task{Name: "deleted_projects", Purge: softDeleted(st.Projects.PurgeDeleted)},
+		Task{Name: "deleted_users", Purge: softDeleted(st.Users.PurgeDeleted)},
+		Task{Name: "deleted_tenants", Purge: softDeleted(st.Tenants.PurgeDeleted)},
+	)
+}
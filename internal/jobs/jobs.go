@@ -0,0 +1,263 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobs runs background work on a schedule and keeps track of how each job is doing,
+// so a janitor that stopped making progress shows up in metrics and the health check.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// staleIntervals is how many intervals a job may go without a successful run before it is reported as stale
+const staleIntervals = 3
+
+// Func runs one iteration of a job and returns how many items it processed
+type Func func(ctx context.Context) (int64, error)
+
+// Job is a named function run on a fixed interval
+type Job struct {
+	Name string
+	// Interval is the base delay between runs
+	Interval time.Duration
+	// Jitter adds a random delay in [0, Jitter) to each interval so replicas do not run in lockstep
+	Jitter time.Duration
+	Run    Func
+}
+
+// Status is the last known state of a job
+type Status struct {
+	Name          string
+	Interval      time.Duration
+	Started       time.Time // when the job was scheduled
+	LastRun       time.Time // when the last completed run started
+	LastSuccess   time.Time
+	LastDuration  time.Duration
+	LastProcessed int64
+	LastError     string
+	Runs          int64
+	Failures      int64
+	Running       bool
+	// StaleAfter is how long the job may go without a successful run before it is reported as stale
+	StaleAfter time.Duration
+}
+
+// Stale reports whether the job has gone more than a few intervals without a successful run.
+// A job that is still inside its first intervals is not stale, even if it has not run yet.
+func (s Status) Stale(now time.Time) bool {
+	since := s.LastSuccess
+	if since.IsZero() {
+		since = s.Started
+	}
+	return now.Sub(since) > s.StaleAfter
+}
+
+// Failing reports whether the most recent run returned an error
+func (s Status) Failing() bool {
+	return s.LastError != ""
+}
+
+// Runner starts jobs and records the outcome of every run
+type Runner struct {
+	runs      metric.Int64Counter
+	processed metric.Int64Counter
+	duration  metric.Float64Histogram
+
+	mu   sync.Mutex
+	jobs map[string]*Status
+}
+
+// NewRunner creates a runner that reports to the given meter
+func NewRunner(meter *metrics.Meter) (*Runner, error) {
+	runs, err := meter.CreateCounter("jobs.runs", "Background job runs by outcome")
+	if err != nil {
+		return nil, err
+	}
+	processed, err := meter.CreateCounter("jobs.processed", "Items processed by background jobs")
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.CreateHistogram("jobs.duration", "Duration of a background job run", "s")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Runner{
+		runs:      runs,
+		processed: processed,
+		duration:  duration,
+		jobs:      make(map[string]*Status),
+	}
+
+	lastSuccess, err := meter.GetMeter().Float64ObservableGauge("jobs.last_success",
+		metric.WithDescription("Unix time of the last successful run of a background job"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gauge jobs.last_success: %w", err)
+	}
+	_, err = meter.GetMeter().RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for _, s := range r.Status() {
+			if !s.LastSuccess.IsZero() {
+				o.ObserveFloat64(lastSuccess, float64(s.LastSuccess.UnixNano())/1e9, metric.WithAttributes(attribute.String("job", s.Name)))
+			}
+		}
+		return nil
+	}, lastSuccess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register gauge jobs.last_success: %w", err)
+	}
+
+	return r, nil
+}
+
+// Start runs job every interval in a new goroutine until ctx is cancelled
+func (r *Runner) Start(ctx context.Context, job Job) {
+	r.register(job, time.Now())
+	go r.loop(ctx, ctx.Done(), job)
+}
+
+// Run runs job every interval until stop is closed or ctx is cancelled. A run in progress when
+// stop is closed completes with ctx before Run returns.
+func (r *Runner) Run(ctx context.Context, stop <-chan struct{}, job Job) {
+	r.register(job, time.Now())
+	r.loop(ctx, stop, job)
+}
+
+// Status returns a snapshot of every registered job, ordered by name. A nil runner has no jobs.
+func (r *Runner) Status() []Status {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]Status, 0, len(r.jobs))
+	for _, s := range r.jobs {
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b Status) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+func (r *Runner) register(job Job, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.Name] = &Status{
+		Name:       job.Name,
+		Interval:   job.Interval,
+		Started:    now,
+		StaleAfter: staleIntervals * (job.Interval + job.Jitter),
+	}
+}
+
+func (r *Runner) loop(ctx context.Context, stop <-chan struct{}, job Job) {
+	timer := time.NewTimer(nextDelay(job))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-timer.C:
+			r.run(ctx, job)
+			timer.Reset(nextDelay(job))
+		}
+	}
+}
+
+// run executes one iteration of job and records its outcome. A panic is recovered and recorded
+// as a failure so one bad run does not take the process down or silently stop the schedule.
+func (r *Runner) run(ctx context.Context, job Job) {
+	start := time.Now()
+	r.update(job.Name, func(s *Status) { s.Running = true })
+
+	var (
+		n   int64
+		err error
+	)
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("job panicked: %v", p)
+			}
+		}()
+		n, err = job.Run(ctx)
+	}()
+	elapsed := time.Since(start)
+
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	attrs := attribute.String("job", job.Name)
+	r.runs.Add(ctx, 1, metric.WithAttributes(attrs, attribute.String("outcome", outcome)))
+	r.processed.Add(ctx, n, metric.WithAttributes(attrs))
+	r.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrs))
+
+	r.update(job.Name, func(s *Status) {
+		s.Running = false
+		s.Runs++
+		s.LastRun = start
+		s.LastDuration = elapsed
+		s.LastProcessed = n
+		s.LastError = ""
+		if err != nil {
+			s.Failures++
+			s.LastError = err.Error()
+			return
+		}
+		s.LastSuccess = start
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "background job failed",
+			logger.Component("jobs"),
+			logger.Operation(job.Name),
+			logger.RowsAffected(n),
+			logger.Duration(elapsed.Milliseconds()),
+			logger.Error(err),
+		)
+	}
+}
+
+func (r *Runner) update(name string, fn func(*Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.jobs[name]; ok {
+		fn(s)
+	}
+}
+
+// nextDelay returns the interval plus a random jitter
+func nextDelay(job Job) time.Duration {
+	if job.Jitter <= 0 {
+		return job.Interval
+	}
+	return job.Interval + rand.N(job.Jitter)
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRunner(t *testing.T) *Runner {
+	t.Helper()
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	require.NoError(t, err)
+	r, err := NewRunner(meter)
+	require.NoError(t, err)
+	return r
+}
+
+// TestPurpose: Validates that every run is recorded with its duration, processed count and error.
+// Scope: Unit Test
+// Security: Availability (a janitor that keeps failing is visible instead of silent)
+// Expected: Successes update the last success time; failures and panics keep it and record the error.
+// Test Case ID: JOB-01
+func TestRunner_RecordsRuns(t *testing.T) {
+	r := newTestRunner(t)
+	ctx := context.Background()
+
+	var fail error
+	job := Job{Name: "purge", Interval: time.Hour, Run: func(ctx context.Context) (int64, error) {
+		return 7, fail
+	}}
+	r.register(job, time.Now())
+
+	r.run(ctx, job)
+	first := r.Status()[0]
+	assert.Equal(t, int64(1), first.Runs)
+	assert.Equal(t, int64(7), first.LastProcessed)
+	assert.False(t, first.LastSuccess.IsZero())
+	assert.False(t, first.Failing())
+
+	fail = errors.New("table locked")
+	r.run(ctx, job)
+	second := r.Status()[0]
+	assert.Equal(t, int64(2), second.Runs)
+	assert.Equal(t, int64(1), second.Failures)
+	assert.Equal(t, "table locked", second.LastError)
+	assert.Equal(t, first.LastSuccess, second.LastSuccess)
+	assert.True(t, second.Failing())
+
+	panicking := Job{Name: "panics", Interval: time.Hour, Run: func(ctx context.Context) (int64, error) {
+		panic("boom")
+	}}
+	r.register(panicking, time.Now())
+	r.run(ctx, panicking)
+	status := r.Status()
+	require.Len(t, status, 2)
+	assert.Equal(t, "panics", status[0].Name, "status is ordered by name")
+	assert.Contains(t, status[0].LastError, "boom")
+}
+
+// TestPurpose: Validates when a job is reported as stale.
+// Scope: Unit Test
+// Security: Availability (a stuck janitor is detected)
+// Expected: A job is stale only after three intervals (plus jitter) without a successful run.
+// Test Case ID: JOB-02
+func TestStatus_Stale(t *testing.T) {
+	r := newTestRunner(t)
+	start := time.Now()
+	r.register(Job{Name: "purge", Interval: time.Minute, Jitter: time.Minute}, start)
+	s := r.Status()[0]
+
+	assert.Equal(t, 6*time.Minute, s.StaleAfter)
+	assert.False(t, s.Stale(start.Add(5*time.Minute)), "not yet run but still within its first intervals")
+	assert.True(t, s.Stale(start.Add(7*time.Minute)))
+
+	s.LastSuccess = start.Add(5 * time.Minute)
+	assert.False(t, s.Stale(start.Add(7*time.Minute)))
+}
+
+// TestPurpose: Validates that a started job runs on its interval and stops when its context is cancelled.
+// Scope: Unit Test
+// Security: N/A (clean shutdown)
+// Expected: The job runs at least twice, and no further runs happen after cancellation.
+// Test Case ID: JOB-03
+func TestRunner_Start_StopsOnCancel(t *testing.T) {
+	r := newTestRunner(t)
+	ran := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx, Job{Name: "tick", Interval: time.Millisecond, Run: func(ctx context.Context) (int64, error) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+		return 0, nil
+	}})
+
+	for range 2 {
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatal("job did not run")
+		}
+	}
+	cancel()
+
+	// An in-flight run may still finish; after that the count must stop growing
+	require.Eventually(t, func() bool {
+		before := r.Status()[0]
+		time.Sleep(20 * time.Millisecond)
+		after := r.Status()[0]
+		return !after.Running && before.Runs == after.Runs
+	}, time.Second, time.Millisecond)
+}
+
+// TestPurpose: Validates that Run lets an in-flight run finish when stop is closed.
+// Scope: Unit Test
+// Security: N/A (clean shutdown)
+// Expected: Run returns only after the run in progress completes, and that run's context is not cancelled.
+// Test Case ID: JOB-04
+func TestRunner_Run_FinishesInFlightRun(t *testing.T) {
+	r := newTestRunner(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var runErr error
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(context.Background(), stop, Job{Name: "slow", Interval: time.Millisecond, Run: func(ctx context.Context) (int64, error) {
+			close(started)
+			<-release
+			runErr = ctx.Err()
+			return 1, nil
+		}})
+	}()
+
+	<-started
+	close(stop)
+	select {
+	case <-done:
+		t.Fatal("Run returned before the in-flight run finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	assert.NoError(t, runErr)
+	assert.Equal(t, int64(1), r.Status()[0].Runs)
+}
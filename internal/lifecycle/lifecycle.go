@@ -0,0 +1,140 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lifecycle runs the background workers of a process and stops them in order: workers are
+// signalled to stop, allowed to finish what they are doing until the shutdown deadline, and then
+// the stop hooks flush what is left, such as queued audit events.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Func is a worker. It should return soon after stop is closed, once its in-flight work is
+// done; ctx is cancelled when the shutdown deadline passes or another worker fails.
+type Func func(ctx context.Context, stop <-chan struct{}) error
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager starts workers and shuts them down within a deadline
+type Manager struct {
+	cancel context.CancelFunc
+	group  *errgroup.Group
+	ctx    context.Context
+	stop   chan struct{}
+
+	mu      sync.Mutex
+	running map[string]int
+	hooks   []hook
+
+	once sync.Once
+	err  error
+}
+
+// New creates a manager whose workers run until ctx is done or Shutdown is called
+func New(ctx context.Context) *Manager {
+	ctx, cancel := context.WithCancel(ctx)
+	group, ctx := errgroup.WithContext(ctx)
+	return &Manager{
+		cancel:  cancel,
+		group:   group,
+		ctx:     ctx,
+		stop:    make(chan struct{}),
+		running: make(map[string]int),
+	}
+}
+
+// Go runs fn in a new goroutine. An error returned by fn cancels the context of every worker.
+func (m *Manager) Go(name string, fn Func) {
+	m.mu.Lock()
+	m.running[name]++
+	m.mu.Unlock()
+	m.group.Go(func() error {
+		defer func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if m.running[name]--; m.running[name] == 0 {
+				delete(m.running, name)
+			}
+		}()
+		if err := fn(m.ctx, m.stop); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// OnStop registers fn to run during Shutdown after the workers have stopped. Hooks run in the
+// order they were registered and get the shutdown context.
+func (m *Manager) OnStop(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Running returns the names of the workers that have not returned yet, ordered by name
+func (m *Manager) Running() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.running))
+	for name := range m.running {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Shutdown signals the workers to stop and waits for them until ctx is done, when the workers
+// still running are cancelled and abandoned. The stop hooks run either way. Only the first call
+// shuts down; later calls return its result.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.once.Do(func() {
+		close(m.stop)
+		done := make(chan error, 1)
+		go func() { done <- m.group.Wait() }()
+
+		var errs []error
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("shutdown deadline passed with %s still running: %w",
+				strings.Join(m.Running(), ", "), ctx.Err()))
+		}
+		m.cancel()
+
+		m.mu.Lock()
+		hooks := m.hooks
+		m.mu.Unlock()
+		for _, h := range hooks {
+			if err := h.fn(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			}
+		}
+		m.err = errors.Join(errs...)
+	})
+	return m.err
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPurpose: Validates that Shutdown lets workers finish their in-flight work before the stop hooks run.
+// Scope: Unit Test
+// Security: Audit integrity (queued audit events are flushed only after the workers that emit them have stopped)
+// Expected: A worker sees stop closed with a live context, finishes, and the hooks then run in registration order.
+// Test Case ID: LFC-01
+func TestManager_Shutdown_Graceful(t *testing.T) {
+	m := New(context.Background())
+	var order []string
+	m.Go("worker", func(ctx context.Context, stop <-chan struct{}) error {
+		<-stop
+		if ctx.Err() != nil {
+			t.Error("expected the worker context to be live while it finishes")
+		}
+		order = append(order, "worker")
+		return nil
+	})
+	m.OnStop("audit", func(ctx context.Context) error {
+		order = append(order, "audit")
+		return nil
+	})
+	m.OnStop("bus", func(ctx context.Context) error {
+		order = append(order, "bus")
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if strings.Join(order, ",") != "worker,audit,bus" {
+		t.Errorf("unexpected shutdown order %v", order)
+	}
+	if len(m.Running()) != 0 {
+		t.Errorf("expected no running workers, got %v", m.Running())
+	}
+	if err := m.Shutdown(ctx); err != nil || len(order) != 3 {
+		t.Errorf("expected a second Shutdown to do nothing, got %v, %v", err, order)
+	}
+}
+
+// TestPurpose: Validates that the shutdown deadline is enforced and that a failing worker stops the others.
+// Scope: Unit Test
+// Security: Availability (a stuck worker cannot hold the process open past the deadline)
+// Expected: A worker ignoring stop is reported by name and cancelled at the deadline while the hooks still run; a worker error cancels every worker context and is returned.
+// Test Case ID: LFC-02
+func TestManager_Shutdown_Deadline(t *testing.T) {
+	m := New(context.Background())
+	cancelled := make(chan struct{})
+	m.Go("stuck", func(ctx context.Context, stop <-chan struct{}) error {
+		<-ctx.Done()
+		close(cancelled)
+		return nil
+	})
+	hookRan := false
+	m.OnStop("audit", func(ctx context.Context) error {
+		hookRan = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := m.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stuck") {
+		t.Errorf("expected the deadline error to name the stuck worker, got %v", err)
+	}
+	if !hookRan {
+		t.Error("expected the stop hooks to run after the deadline")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stuck worker to be cancelled")
+	}
+
+	m = New(context.Background())
+	failure := errors.New("listener failed")
+	m.Go("broken", func(ctx context.Context, stop <-chan struct{}) error {
+		return failure
+	})
+	m.Go("other", func(ctx context.Context, stop <-chan struct{}) error {
+		<-ctx.Done()
+		return nil
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, failure) || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the worker error, got %v", err)
+	}
+}
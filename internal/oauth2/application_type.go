@@ -0,0 +1,97 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// Application types of a client (OIDC Dynamic Client Registration Section 2, extended with spa and
+// service). Each type restricts the metadata a client may register, so that whole classes of
+// misconfiguration, such as a browser app holding a secret, are refused at registration. Clients
+// registered without a type are not restricted.
+const (
+	ApplicationTypeWeb     = "web"     // Server-side web app: https redirect URIs, except loopback
+	ApplicationTypeNative  = "native"  // Mobile or desktop app: PKCE; private-use scheme, https or loopback redirect URIs
+	ApplicationTypeSPA     = "spa"     // Browser app: public client with PKCE
+	ApplicationTypeService = "service" // Backend service or resource server: confidential, no redirect URIs
+)
+
+// ApplicationTypes are the accepted values of Client.ApplicationType
+var ApplicationTypes = []string{ApplicationTypeWeb, ApplicationTypeNative, ApplicationTypeSPA, ApplicationTypeService}
+
+// RequiresPKCE reports whether authorization requests of the client must carry an S256 code challenge
+func (c *Client) RequiresPKCE() bool {
+	return c.ApplicationType == ApplicationTypeSPA || c.ApplicationType == ApplicationTypeNative
+}
+
+// validateApplicationType checks the client metadata against the policy of its application type
+func validateApplicationType(client *Client) error {
+	public := client.TokenEndpointAuthMethod == "none"
+	switch client.ApplicationType {
+	case "":
+		return nil
+	case ApplicationTypeWeb:
+		for _, uri := range client.RedirectURIs {
+			if !secureRedirectURI(uri, false) {
+				return fmt.Errorf("%w: redirect URI %q of a web application must use https, or http on a loopback address", ErrDomainInvalidMetadata, uri)
+			}
+		}
+	case ApplicationTypeNative:
+		for _, uri := range client.RedirectURIs {
+			if !secureRedirectURI(uri, true) {
+				return fmt.Errorf("%w: redirect URI %q of a native application must use a private-use scheme, https, or http on a loopback address", ErrDomainInvalidMetadata, uri)
+			}
+		}
+	case ApplicationTypeSPA:
+		if !public || client.ClientSecretHash != "" {
+			return fmt.Errorf("%w: a single-page application must be a public client (token_endpoint_auth_method none)", ErrDomainInvalidMetadata)
+		}
+		for _, uri := range client.RedirectURIs {
+			if !secureRedirectURI(uri, false) {
+				return fmt.Errorf("%w: redirect URI %q of a single-page application must use https, or http on a loopback address", ErrDomainInvalidMetadata, uri)
+			}
+		}
+	case ApplicationTypeService:
+		if public {
+			return fmt.Errorf("%w: a service must be a confidential client", ErrDomainInvalidMetadata)
+		}
+		if len(client.RedirectURIs) > 0 || slices.Contains(client.GrantTypes, "authorization_code") || len(client.ResponseTypes) > 0 {
+			return fmt.Errorf("%w: a service cannot have redirect URIs or use the authorization code flow", ErrDomainInvalidMetadata)
+		}
+	default:
+		return fmt.Errorf("%w: application_type must be one of %v", ErrDomainInvalidMetadata, ApplicationTypes)
+	}
+	return nil
+}
+
+// secureRedirectURI reports whether a redirect URI uses https or loopback http; with
+// privateSchemes, as native apps use (RFC 8252 Section 7.1), any scheme other than http also passes
+func secureRedirectURI(uri string, privateSchemes bool) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return u.Host != ""
+	case "http":
+		return isLoopback(u.Hostname())
+	default:
+		return privateSchemes
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestPurpose: Validates the registration policy of each application type and the PKCE requirement of browser and native apps.
+// Scope: Unit Test
+// Security: Client misconfiguration (secrets in browser apps, plain-http redirects, services with redirect URIs)
+// Expected: Metadata fitting the type is accepted and any other is invalid metadata; clients without a type are unrestricted; spa and native authorization requests without an S256 code challenge are invalid_request.
+// Test Case ID: OA2-20
+// RelatedSpecs: OIDC Dynamic Client Registration Section 2, RFC 8252 Sections 7 and 8.1
+func TestOAuth2_ApplicationTypePolicy(t *testing.T) {
+	confidential := "client_secret_basic"
+	for _, tc := range []struct {
+		name   string
+		client Client
+		valid  bool
+	}{
+		{"untyped http", Client{RedirectURIs: []string{"http://app.example.com/cb"}, TokenEndpointAuthMethod: confidential}, true},
+		{"web https", Client{ApplicationType: ApplicationTypeWeb, RedirectURIs: []string{"https://app.example.com/cb", "http://127.0.0.1:8080/cb"}, TokenEndpointAuthMethod: confidential}, true},
+		{"web http", Client{ApplicationType: ApplicationTypeWeb, RedirectURIs: []string{"http://app.example.com/cb"}, TokenEndpointAuthMethod: confidential}, false},
+		{"web custom scheme", Client{ApplicationType: ApplicationTypeWeb, RedirectURIs: []string{"com.example.app:/cb"}, TokenEndpointAuthMethod: confidential}, false},
+		{"native custom scheme", Client{ApplicationType: ApplicationTypeNative, RedirectURIs: []string{"com.example.app:/cb", "http://localhost/cb"}, TokenEndpointAuthMethod: "none"}, true},
+		{"native http", Client{ApplicationType: ApplicationTypeNative, RedirectURIs: []string{"http://app.example.com/cb"}, TokenEndpointAuthMethod: "none"}, false},
+		{"spa public", Client{ApplicationType: ApplicationTypeSPA, RedirectURIs: []string{"https://app.example.com/cb"}, TokenEndpointAuthMethod: "none"}, true},
+		{"spa confidential", Client{ApplicationType: ApplicationTypeSPA, RedirectURIs: []string{"https://app.example.com/cb"}, TokenEndpointAuthMethod: confidential}, false},
+		{"service", Client{ApplicationType: ApplicationTypeService, TokenEndpointAuthMethod: confidential}, true},
+		{"service with redirect", Client{ApplicationType: ApplicationTypeService, RedirectURIs: []string{"https://api.example.com/cb"}, TokenEndpointAuthMethod: confidential}, false},
+		{"public service", Client{ApplicationType: ApplicationTypeService, TokenEndpointAuthMethod: "none"}, false},
+		{"unknown type", Client{ApplicationType: "desktop", TokenEndpointAuthMethod: confidential}, false},
+	} {
+		err := validateApplicationType(&tc.client)
+		if tc.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tc.name, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrDomainInvalidMetadata) {
+			t.Errorf("%s: expected invalid metadata, got %v", tc.name, err)
+		}
+	}
+
+	s := &Service{clientRepo: &MockClientRepo{clients: map[string]*Client{
+		"spa": {ClientID: "spa", TenantID: "t1", ApplicationType: ApplicationTypeSPA, RedirectURIs: []string{"https://app.example.com/cb"}, AllowedScopes: []string{"openid"}, ResponseTypes: []string{"code"}, IsActive: true},
+	}}}
+	req := &AuthorizeRequest{ClientID: "spa", RedirectURI: "https://app.example.com/cb", ResponseType: "code", Scope: "openid", State: "s"}
+	for _, method := range []string{"", "plain"} {
+		req.CodeChallenge, req.CodeChallengeMethod = "challenge", method
+		if _, err := s.ValidateAuthorizeRequest(context.Background(), req); AsError(err).Code != ErrInvalidRequest {
+			t.Errorf("method %q: expected invalid_request, got %v", method, err)
+		}
+	}
+	req.CodeChallengeMethod = "S256"
+	if _, err := s.ValidateAuthorizeRequest(context.Background(), req); err != nil {
+		t.Errorf("expected an S256 challenge to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxAudiences bounds the clients an ID token is addressed to
+	maxAudiences = 10
+	// maxClientGroupLength bounds the name of a client group
+	maxClientGroupLength = 64
+)
+
+// idTokenAudience returns the audiences of an ID token issued to client: the client itself, then
+// the requested clients. Clients of one tenant that share a client group, such as the mobile and
+// web apps of a backend, may be named; any other audience is refused with invalid_target.
+func (s *Service) idTokenAudience(ctx context.Context, client *Client, requested []string) ([]string, error) {
+	audience := []string{client.ClientID}
+	for _, clientID := range requested {
+		if clientID == "" || slices.Contains(audience, clientID) {
+			continue
+		}
+		if client.ClientGroup == "" {
+			return nil, NewError(ErrInvalidTarget, "client is not in a client group")
+		}
+		related, err := s.clientRepo.GetByClientID(ctx, clientID)
+		if err != nil || !related.IsActive || related.TenantID != client.TenantID || related.ClientGroup != client.ClientGroup {
+			return nil, NewError(ErrInvalidTarget, "audience is not a client of the same group")
+		}
+		audience = append(audience, clientID)
+	}
+	if len(audience) > maxAudiences {
+		return nil, NewError(ErrInvalidTarget, "too many audiences")
+	}
+	return audience, nil
+}
+
+// validateClientGroup checks the client group name: short, and without whitespace
+func validateClientGroup(client *Client) error {
+	if len(client.ClientGroup) > maxClientGroupLength || strings.ContainsFunc(client.ClientGroup, unicode.IsSpace) {
+		return fmt.Errorf("%w: client group must be at most %d characters without whitespace", ErrDomainInvalidMetadata, maxClientGroupLength)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// TestPurpose: Validates which clients may be named as additional ID token audiences at the token endpoint.
+// Scope: Unit Test
+// Security: Token audience restriction (an ID token only reaches clients of the same tenant and client group)
+// Expected: A client of the group is added after the requesting client; clients of another group or tenant, inactive clients and clients in no group are refused with invalid_target; group names with whitespace are invalid metadata.
+// Test Case ID: OA2-18
+// RelatedSpecs: OIDC Core Section 2 (aud, azp), RFC 8707 Section 2
+func TestOAuth2_Service_IDTokenAudience(t *testing.T) {
+	clients := map[string]*Client{
+		"web":    {ClientID: "web", TenantID: "t1", ClientGroup: "shop", IsActive: true, RedirectURIs: []string{"https://shop.example.com/cb"}},
+		"mobile": {ClientID: "mobile", TenantID: "t1", ClientGroup: "shop", IsActive: true},
+		"old":    {ClientID: "old", TenantID: "t1", ClientGroup: "shop"},
+		"other":  {ClientID: "other", TenantID: "t1", ClientGroup: "blog", IsActive: true},
+		"remote": {ClientID: "remote", TenantID: "t2", ClientGroup: "shop", IsActive: true},
+		"solo":   {ClientID: "solo", TenantID: "t1", IsActive: true, RedirectURIs: []string{"https://solo.example.com/cb"}},
+	}
+	oidcProvider := &MockOIDCProvider{}
+	s := &Service{
+		clientRepo:   &MockClientRepo{clients: clients},
+		codeRepo:     &MockCodeRepo{codes: make(map[string]*AuthorizationCode)},
+		accessRepo:   &MockAccessRepo{},
+		refreshRepo:  &MockRefreshRepo{},
+		auditLogger:  audit.NewSlogLogger(),
+		oidcProvider: oidcProvider,
+	}
+	ctx := context.Background()
+
+	exchange := func(clientID string, audience ...string) error {
+		client := clients[clientID]
+		code, err := s.CreateAuthorizationCode(ctx, &AuthorizeRequest{ClientID: clientID, RedirectURI: client.RedirectURIs[0], Scope: "openid"}, "user-1", "")
+		if err != nil {
+			t.Fatalf("create code: %v", err)
+		}
+		_, err = s.ExchangeCodeForToken(ctx, &TokenRequest{
+			GrantType:   "authorization_code",
+			ClientID:    clientID,
+			RedirectURI: client.RedirectURIs[0],
+			Code:        code.Code,
+			Audience:    audience,
+		})
+		return err
+	}
+
+	if err := exchange("web", "mobile", "web"); err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if !slices.Equal(oidcProvider.CapturedAudience, []string{"web", "mobile"}) {
+		t.Errorf("expected audience [web mobile], got %v", oidcProvider.CapturedAudience)
+	}
+	if err := exchange("web"); err != nil || !slices.Equal(oidcProvider.CapturedAudience, []string{"web"}) {
+		t.Errorf("expected the client alone as audience, got %v, %v", oidcProvider.CapturedAudience, err)
+	}
+
+	for _, tc := range []struct{ client, audience string }{
+		{"web", "old"}, {"web", "other"}, {"web", "remote"}, {"web", "unknown"}, {"solo", "web"},
+	} {
+		if err := exchange(tc.client, tc.audience); AsError(err).Code != ErrInvalidTarget {
+			t.Errorf("%s for %s: expected invalid_target, got %v", tc.client, tc.audience, err)
+		}
+	}
+
+	if err := validateClientGroup(&Client{ClientGroup: "my shop"}); !errors.Is(err, ErrDomainInvalidMetadata) {
+		t.Errorf("expected a group name with whitespace to be invalid, got %v", err)
+	}
+}
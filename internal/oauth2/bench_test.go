@@ -27,13 +27,15 @@ type BenchMockCodeRepo struct {
 	code *AuthorizationCode
 }
 
-func (m *BenchMockCodeRepo) Create(code *AuthorizationCode) error { return nil }
-func (m *BenchMockCodeRepo) GetByCode(code string) (*AuthorizationCode, error) {
+func (m *BenchMockCodeRepo) Create(ctx context.Context, code *AuthorizationCode) error { return nil }
+func (m *BenchMockCodeRepo) GetByCode(ctx context.Context, tenantID, code string) (*AuthorizationCode, error) {
 	return m.code, nil
 }
-func (m *BenchMockCodeRepo) MarkAsUsed(code string) error { return nil }
-func (m *BenchMockCodeRepo) Delete(code string) error     { return nil }
-func (m *BenchMockCodeRepo) DeleteExpired() error         { return nil }
+func (m *BenchMockCodeRepo) MarkAsUsed(ctx context.Context, tenantID, code string) error { return nil }
+func (m *BenchMockCodeRepo) Delete(ctx context.Context, tenantID, code string) error     { return nil }
+func (m *BenchMockCodeRepo) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	return 0, nil
+}
 
 func BenchmarkService_ExchangeCodeForToken(b *testing.B) {
 	// Setup Mocks
@@ -0,0 +1,184 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// ClaimRequest describes a token issuance to a ClaimEnricher
+type ClaimRequest struct {
+	TenantID  string         `json:"tenant_id"`
+	ClientID  string         `json:"client_id"`
+	UserID    string         `json:"user_id"`
+	GrantType string         `json:"grant_type"`
+	Scope     string         `json:"scope"`
+	User      map[string]any `json:"user,omitempty"` // The user's profile attributes (see ClaimsSource)
+}
+
+// EnrichedClaims are the custom claims added to the tokens of one issuance. Access tokens are
+// opaque; their claims are reported by introspection.
+type EnrichedClaims struct {
+	IDToken     map[string]any `json:"id_token,omitempty"`
+	AccessToken map[string]any `json:"access_token,omitempty"`
+}
+
+// ClaimEnricher adds deployment-specific claims, such as entitlements or organization IDs, to
+// tokens as they are issued. Reserved claims it returns are dropped (see ReservedClaims).
+type ClaimEnricher interface {
+	EnrichClaims(ctx context.Context, req *ClaimRequest) (*EnrichedClaims, error)
+}
+
+// ReservedClaims are set by the server only: the registered JWT and ID token claims, and the
+// members of an introspection response (RFC 7662 Section 2.2)
+var ReservedClaims = []string{
+	"iss", "sub", "aud", "exp", "nbf", "iat", "jti",
+	"nonce", "at_hash", "c_hash", "auth_time", "azp", "acr", "amr", "sid",
+	"active", "scope", "client_id", "username", "token_type", "tenant_id", "permissions",
+}
+
+const (
+	// maxEnrichedClaims bounds the custom claims of one token
+	maxEnrichedClaims = 32
+	// maxEnrichedClaimsSize bounds the serialized custom claims of one token, keeping ID tokens
+	// small enough for redirects and headers
+	maxEnrichedClaimsSize = 4 << 10
+)
+
+// SetClaimEnricher enables custom claims. When required, an issuance fails with server_error if the
+// enricher does; otherwise tokens are issued without custom claims.
+func (s *Service) SetClaimEnricher(enricher ClaimEnricher, required bool) {
+	s.claimEnricher = enricher
+	s.claimsRequired = required
+}
+
+// enrichClaims asks the enricher for the custom claims of an issuance and applies the guardrails
+// to them. The user attributes passed are those of ClaimsSource.
+func (s *Service) enrichClaims(ctx context.Context, client *Client, userID, grantType, scope string) (*EnrichedClaims, error) {
+	if s.claimEnricher == nil {
+		return &EnrichedClaims{}, nil
+	}
+	req := &ClaimRequest{
+		TenantID:  client.TenantID,
+		ClientID:  client.ClientID,
+		UserID:    userID,
+		GrantType: grantType,
+		Scope:     scope,
+	}
+	if s.claimsSource != nil {
+		user, err := s.claimsSource.UserClaims(ctx, userID)
+		if err != nil {
+			return s.enrichmentFailed(ctx, client, err)
+		}
+		req.User = user
+	}
+	enriched, err := s.claimEnricher.EnrichClaims(ctx, req)
+	if err != nil {
+		return s.enrichmentFailed(ctx, client, err)
+	}
+	if enriched == nil {
+		return &EnrichedClaims{}, nil
+	}
+	return &EnrichedClaims{
+		IDToken:     guardClaims(ctx, client.ClientID, "id_token", enriched.IDToken),
+		AccessToken: guardClaims(ctx, client.ClientID, "access_token", enriched.AccessToken),
+	}, nil
+}
+
+func (s *Service) enrichmentFailed(ctx context.Context, client *Client, err error) (*EnrichedClaims, error) {
+	slog.ErrorContext(ctx, "claim enrichment failed", "client_id", client.ClientID, "required", s.claimsRequired, logger.Error(err))
+	if s.claimsRequired {
+		return nil, NewError(ErrServerError, "claim enrichment failed")
+	}
+	return &EnrichedClaims{}, nil
+}
+
+// guardClaims drops reserved claims, and all claims when there are too many or they are too large
+func guardClaims(ctx context.Context, clientID, token string, claims map[string]any) map[string]any {
+	var dropped []string
+	for name := range claims {
+		if name == "" || slices.Contains(ReservedClaims, name) {
+			dropped = append(dropped, name)
+			delete(claims, name)
+		}
+	}
+	if len(dropped) > 0 {
+		slices.Sort(dropped)
+		slog.WarnContext(ctx, "claim enricher returned reserved claims", "client_id", clientID, "token", token, "claims", dropped)
+	}
+	if len(claims) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(claims)
+	if err != nil || len(claims) > maxEnrichedClaims || len(body) > maxEnrichedClaimsSize {
+		slog.WarnContext(ctx, "claim enricher returned too many claims; none are added",
+			"client_id", clientID, "token", token, "count", len(claims), "bytes", len(body))
+		return nil
+	}
+	return claims
+}
+
+// WebhookEnricher is a ClaimEnricher that posts the ClaimRequest to a URL and takes the
+// EnrichedClaims from the response. Requests are signed like webhook deliveries, with the
+// OpenTrusty-Signature header, so the receiver can verify them with webhook.Verify.
+type WebhookEnricher struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// NewWebhookEnricher creates a webhook enricher with the given request timeout
+func NewWebhookEnricher(url, secret string, timeout time.Duration) *WebhookEnricher {
+	return &WebhookEnricher{URL: url, Secret: secret, Client: &http.Client{Timeout: timeout}}
+}
+
+// EnrichClaims calls the webhook
+func (w *WebhookEnricher) EnrichClaims(ctx context.Context, req *ClaimRequest) (*EnrichedClaims, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(webhook.HeaderSignature, webhook.Sign(w.Secret, time.Now(), body))
+
+	resp, err := w.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("claims webhook returned status %d", resp.StatusCode)
+	}
+	var enriched EnrichedClaims
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4*maxEnrichedClaimsSize)).Decode(&enriched); err != nil {
+		return nil, fmt.Errorf("invalid claims webhook response: %w", err)
+	}
+	return &enriched, nil
+}
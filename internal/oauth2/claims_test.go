@@ -0,0 +1,150 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+type enricherFunc func(ctx context.Context, req *ClaimRequest) (*EnrichedClaims, error)
+
+func (f enricherFunc) EnrichClaims(ctx context.Context, req *ClaimRequest) (*EnrichedClaims, error) {
+	return f(ctx, req)
+}
+
+// TestPurpose: Validates the guardrails applied to claims returned by a ClaimEnricher.
+// Scope: Unit Test
+// Security: Token integrity (an enricher cannot override iss, sub, aud, scope or other server-set claims)
+// Expected: Reserved claims are dropped and the rest kept; oversized claim sets are dropped entirely; a failing enricher yields no claims unless claims are required, when issuance fails with server_error.
+// Test Case ID: OA2-14
+func TestOAuth2_ClaimEnricherGuardrails(t *testing.T) {
+	client := &Client{ClientID: "app", TenantID: "t1"}
+	ctx := context.Background()
+
+	var got *ClaimRequest
+	svc := &Service{}
+	svc.SetClaimEnricher(enricherFunc(func(ctx context.Context, req *ClaimRequest) (*EnrichedClaims, error) {
+		got = req
+		return &EnrichedClaims{
+			IDToken:     map[string]any{"org_id": "o1", "sub": "attacker", "aud": "other", "": "x"},
+			AccessToken: map[string]any{"entitlements": []string{"reports"}, "scope": "admin", "permissions": []string{"*"}},
+		}, nil
+	}), false)
+
+	enriched, err := svc.enrichClaims(ctx, client, "u1", "authorization_code", "openid")
+	if err != nil {
+		t.Fatalf("enrichClaims: %v", err)
+	}
+	if got.TenantID != "t1" || got.ClientID != "app" || got.UserID != "u1" || got.GrantType != "authorization_code" || got.Scope != "openid" {
+		t.Errorf("unexpected claim request %+v", got)
+	}
+	if len(enriched.IDToken) != 1 || enriched.IDToken["org_id"] != "o1" {
+		t.Errorf("expected only org_id in the ID token claims, got %v", enriched.IDToken)
+	}
+	if len(enriched.AccessToken) != 1 || enriched.AccessToken["entitlements"] == nil {
+		t.Errorf("expected only entitlements in the access token claims, got %v", enriched.AccessToken)
+	}
+
+	svc.SetClaimEnricher(enricherFunc(func(ctx context.Context, req *ClaimRequest) (*EnrichedClaims, error) {
+		many := make(map[string]any)
+		for i := range maxEnrichedClaims + 1 {
+			many[fmt.Sprintf("c%d", i)] = true
+		}
+		return &EnrichedClaims{
+			IDToken:     many,
+			AccessToken: map[string]any{"blob": strings.Repeat("x", maxEnrichedClaimsSize)},
+		}, nil
+	}), false)
+	if enriched, err := svc.enrichClaims(ctx, client, "u1", "authorization_code", "openid"); err != nil || enriched.IDToken != nil || enriched.AccessToken != nil {
+		t.Errorf("expected too many or too large claims to be dropped, got %v, %v", enriched, err)
+	}
+
+	failing := enricherFunc(func(ctx context.Context, req *ClaimRequest) (*EnrichedClaims, error) {
+		return nil, errors.New("enricher unavailable")
+	})
+	svc.SetClaimEnricher(failing, false)
+	if enriched, err := svc.enrichClaims(ctx, client, "u1", "refresh_token", "openid"); err != nil || enriched.IDToken != nil || enriched.AccessToken != nil {
+		t.Errorf("expected an optional enricher failure to issue without claims, got %v, %v", enriched, err)
+	}
+	svc.SetClaimEnricher(failing, true)
+	if _, err := svc.enrichClaims(ctx, client, "u1", "refresh_token", "openid"); AsError(err).Code != ErrServerError {
+		t.Errorf("expected a required enricher failure to be server_error, got %v", err)
+	}
+}
+
+// TestPurpose: Validates the claims webhook and how enriched access token claims are introspected.
+// Scope: Unit Test
+// Security: Request authenticity (webhook calls are signed; custom claims never replace introspection members)
+// Expected: The webhook receives a signed ClaimRequest and its claims are used; a non-200 response is an error; introspection reports custom claims as top-level members without overriding standard ones.
+// Test Case ID: OA2-15
+func TestOAuth2_WebhookEnricher(t *testing.T) {
+	const secret = "whsec-test"
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(secret, r.Header.Get(webhook.HeaderSignature), body, time.Minute, time.Now()); err != nil {
+			t.Errorf("expected a signed request, got %v", err)
+		}
+		var req ClaimRequest
+		if err := json.Unmarshal(body, &req); err != nil || req.UserID != "u1" || req.User["email"] != "a@example.com" {
+			t.Errorf("unexpected claim request %s", body)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id_token":{"org_id":"o1"},"access_token":{"entitlements":["reports"]}}`))
+	}))
+	defer srv.Close()
+
+	enricher := NewWebhookEnricher(srv.URL, secret, time.Second)
+	req := &ClaimRequest{UserID: "u1", User: map[string]any{"email": "a@example.com"}}
+	enriched, err := enricher.EnrichClaims(context.Background(), req)
+	if err != nil {
+		t.Fatalf("EnrichClaims: %v", err)
+	}
+	if enriched.IDToken["org_id"] != "o1" || enriched.AccessToken["entitlements"] == nil {
+		t.Errorf("unexpected enriched claims %+v", enriched)
+	}
+
+	status = http.StatusServiceUnavailable
+	if _, err := enricher.EnrichClaims(context.Background(), req); err == nil {
+		t.Error("expected a failing webhook to be an error")
+	}
+
+	body, err := json.Marshal(IntrospectionResponse{
+		Active:   true,
+		ClientID: "app",
+		Claims:   map[string]any{"entitlements": []string{"reports"}, "client_id": "forged"},
+	})
+	if err != nil {
+		t.Fatalf("marshal introspection response: %v", err)
+	}
+	var members map[string]any
+	if err := json.Unmarshal(body, &members); err != nil {
+		t.Fatal(err)
+	}
+	if members["active"] != true || members["client_id"] != "app" || members["entitlements"] == nil {
+		t.Errorf("unexpected introspection response %s", body)
+	}
+}
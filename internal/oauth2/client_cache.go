@@ -0,0 +1,167 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/bus"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// clientCacheSize caps the number of cached clients; lookups beyond it go to the repository
+const clientCacheSize = 10000
+
+// ClientCache is a ClientRepository that keeps GetByClientID results for a short TTL, so the
+// authorize, token and revoke endpoints do not load and decode the client on every call.
+// Update and Delete evict the client at once and publish bus.TopicClientChanged; the other
+// replicas evict it through Invalidate, or after the TTL if the event is lost. Lookups of unknown
+// clients are not cached, and a zero TTL caches nothing but still publishes changes.
+type ClientCache struct {
+	ClientRepository
+
+	ttl       time.Duration
+	now       func() time.Time
+	publisher bus.Publisher
+
+	mu      sync.Mutex
+	entries map[string]clientCacheEntry // by client_id
+	// generation counts invalidations, so that a lookup racing one does not cache what it loaded
+	generation uint64
+}
+
+// clientCacheEntry is a cached client and when it stops being served
+type clientCacheEntry struct {
+	client  *Client
+	expires time.Time
+}
+
+// NewClientCache wraps repo with a cache whose entries live for ttl. Changes are published on
+// publisher; nil publishes nothing.
+func NewClientCache(repo ClientRepository, ttl time.Duration, publisher bus.Publisher) *ClientCache {
+	return &ClientCache{
+		ClientRepository: repo,
+		ttl:              ttl,
+		now:              time.Now,
+		publisher:        publisher,
+		entries:          make(map[string]clientCacheEntry),
+	}
+}
+
+// GetByClientID returns the cached client or loads it from the repository. Callers get their own
+// copy and may modify it.
+func (c *ClientCache) GetByClientID(ctx context.Context, clientID string) (*Client, error) {
+	if c.ttl <= 0 {
+		return c.ClientRepository.GetByClientID(ctx, clientID)
+	}
+	now := c.now()
+	c.mu.Lock()
+	entry, ok := c.entries[clientID]
+	generation := c.generation
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.client.clone(), nil
+	}
+
+	client, err := c.ClientRepository.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.entries) >= clientCacheSize {
+		c.evictExpired(now)
+	}
+	if generation == c.generation && len(c.entries) < clientCacheSize {
+		c.entries[clientID] = clientCacheEntry{client: client.clone(), expires: now.Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return client, nil
+}
+
+// Update updates the client and evicts it from every replica's cache
+func (c *ClientCache) Update(ctx context.Context, client *Client) error {
+	err := c.ClientRepository.Update(ctx, client)
+	c.changed(ctx, client.ID, err)
+	return err
+}
+
+// Delete soft-deletes the client and evicts it from every replica's cache
+func (c *ClientCache) Delete(ctx context.Context, id string) error {
+	err := c.ClientRepository.Delete(ctx, id)
+	c.changed(ctx, id, err)
+	return err
+}
+
+// changed evicts a client after a write and, if the write succeeded, tells the other replicas.
+// A lost event leaves them serving the client until their TTL, so a failure is only logged.
+func (c *ClientCache) changed(ctx context.Context, id string, err error) {
+	c.Invalidate(id)
+	if err != nil || c.publisher == nil {
+		return
+	}
+	if err := c.publisher.Publish(ctx, bus.TopicClientChanged, id); err != nil {
+		slog.WarnContext(ctx, "failed to publish client change",
+			logger.Component("oauth2"),
+			logger.Error(err),
+		)
+	}
+}
+
+// Invalidate evicts the client with the given internal ID; an empty ID evicts every client
+func (c *ClientCache) Invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if id == "" {
+		clear(c.entries)
+		return
+	}
+	for clientID, entry := range c.entries {
+		if entry.client.ID == id {
+			delete(c.entries, clientID)
+		}
+	}
+}
+
+// evictExpired removes the entries that are no longer served; c.mu must be held
+func (c *ClientCache) evictExpired(now time.Time) {
+	for clientID, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, clientID)
+		}
+	}
+}
+
+// clone returns a deep copy of the client
+func (c *Client) clone() *Client {
+	copied := *c
+	copied.RedirectURIs = slices.Clone(c.RedirectURIs)
+	copied.AllowedScopes = slices.Clone(c.AllowedScopes)
+	copied.GrantTypes = slices.Clone(c.GrantTypes)
+	copied.ResponseTypes = slices.Clone(c.ResponseTypes)
+	copied.JWKS = slices.Clone(c.JWKS)
+	copied.RequestURIs = slices.Clone(c.RequestURIs)
+	copied.AllowedOrigins = slices.Clone(c.AllowedOrigins)
+	if c.DeletedAt != nil {
+		deletedAt := *c.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+	return &copied
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/bus"
+)
+
+// countingClientRepo counts the lookups that reach the repository
+type countingClientRepo struct {
+	MockClientRepo
+	lookups int
+}
+
+func (m *countingClientRepo) GetByClientID(ctx context.Context, clientID string) (*Client, error) {
+	m.lookups++
+	return m.MockClientRepo.GetByClientID(ctx, clientID)
+}
+
+// TestPurpose: Validates that client lookups are cached for their TTL and evicted when the client changes.
+// Scope: Unit Test
+// Security: Authorization (a deactivated client or a rotated secret must not outlive the change on this or another replica)
+// Expected: Repeated lookups hit the repository once; Update, Delete and Invalidate (by ID or all) force a reload, as does expiry; Update and Delete are published to the other replicas; callers cannot modify the cached copy; unknown clients are not cached.
+// Test Case ID: OA2-09
+func TestClientCache(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingClientRepo{MockClientRepo: MockClientRepo{clients: map[string]*Client{
+		"client-1": {ID: "id-1", ClientID: "client-1", RedirectURIs: []string{"https://app.example.com/callback"}, IsActive: true},
+	}}}
+	now := time.Now()
+	var published []string
+	eventBus := bus.New(nil)
+	eventBus.Subscribe(bus.TopicClientChanged, func(_ context.Context, id string) {
+		published = append(published, id)
+	})
+	cache := NewClientCache(repo, time.Minute, eventBus)
+	cache.now = func() time.Time { return now }
+
+	lookup := func(wantLookups int) *Client {
+		t.Helper()
+		client, err := cache.GetByClientID(ctx, "client-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repo.lookups != wantLookups {
+			t.Fatalf("expected %d repository lookups, got %d", wantLookups, repo.lookups)
+		}
+		return client
+	}
+
+	client := lookup(1)
+	client.RedirectURIs[0] = "https://evil.example.com/callback"
+	client.IsActive = false
+	if cached := lookup(1); cached.RedirectURIs[0] != "https://app.example.com/callback" || !cached.IsActive {
+		t.Errorf("caller modified the cached client: %+v", cached)
+	}
+
+	if err := cache.Update(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	lookup(2)
+	if err := cache.Delete(ctx, "id-1"); err != nil {
+		t.Fatal(err)
+	}
+	lookup(3)
+	if len(published) != 2 || published[0] != "id-1" || published[1] != "id-1" {
+		t.Errorf("expected both changes to be published, got %v", published)
+	}
+	cache.Invalidate("id-other")
+	lookup(3)
+	cache.Invalidate("id-1")
+	lookup(4)
+	cache.Invalidate("")
+	lookup(5)
+
+	now = now.Add(time.Minute)
+	lookup(6)
+
+	for range 2 {
+		if _, err := cache.GetByClientID(ctx, "unknown"); err != ErrClientNotFound {
+			t.Fatalf("expected ErrClientNotFound, got %v", err)
+		}
+	}
+	if repo.lookups != 8 {
+		t.Errorf("expected unknown clients to reach the repository every time, got %d lookups", repo.lookups)
+	}
+}
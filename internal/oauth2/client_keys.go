@@ -0,0 +1,236 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// maxClientKeys bounds the keys of a client's JWKS
+	maxClientKeys = 10
+	// maxClientJWKSSize bounds a JWKS fetched from a jwks_uri
+	maxClientJWKSSize = 64 << 10
+	// clientJWKSRefreshInterval is how long keys fetched from a jwks_uri are used before they are fetched again
+	clientJWKSRefreshInterval = 5 * time.Minute
+	// clientJWKSRetryInterval bounds how often a jwks_uri is fetched, including for an unknown kid
+	// after the client rotated its keys
+	clientJWKSRetryInterval = 30 * time.Second
+	// clientJWKSMaxStale is how long fetched keys are still used while their jwks_uri cannot be reached
+	clientJWKSMaxStale = time.Hour
+	// clientJWKSFetchTimeout bounds fetching a jwks_uri
+	clientJWKSFetchTimeout = 5 * time.Second
+	// maxCachedClientJWKS bounds the jwks_uris whose keys are cached; the least recently used is evicted
+	maxCachedClientJWKS = 1024
+)
+
+// errPrivateAddress is returned when a jwks_uri or request_uri resolves to an address the server must not reach
+var errPrivateAddress = errors.New("uri resolves to a private or loopback address")
+
+// remoteJWKS holds the keys last fetched from a client's jwks_uri
+type remoteJWKS struct {
+	mu          sync.Mutex
+	keys        []publicKey
+	fetchedAt   time.Time // Last successful fetch
+	attemptedAt time.Time // Last fetch, successful or not
+	usedAt      time.Time // Last lookup, guarded by Service.clientJWKSMu
+}
+
+// clientKeys returns the public keys of client: its registered JWKS, or the keys published at its
+// jwks_uri. Published keys are cached for clientJWKSRefreshInterval and fetched again early when
+// kid names a key not among them, so a client can rotate its keys without waiting for the cache.
+func (s *Service) clientKeys(ctx context.Context, client *Client, kid string) ([]publicKey, error) {
+	if client.JWKSURI == "" {
+		return parseJWKS(client.JWKS)
+	}
+
+	remote := s.cachedClientJWKS(client.JWKSURI)
+	remote.mu.Lock()
+	defer remote.mu.Unlock()
+
+	now := time.Now()
+	stale := now.Sub(remote.fetchedAt) >= clientJWKSRefreshInterval
+	unknown := kid != "" && !slices.ContainsFunc(remote.keys, func(k publicKey) bool { return k.kid == kid })
+	if (stale || unknown) && now.Sub(remote.attemptedAt) >= clientJWKSRetryInterval {
+		remote.attemptedAt = now
+		keys, err := s.fetchClientJWKS(ctx, client.JWKSURI)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to fetch client jwks", "client_id", client.ClientID, "jwks_uri", client.JWKSURI, "error", err)
+		} else {
+			remote.keys, remote.fetchedAt = keys, now
+		}
+	}
+	if remote.fetchedAt.IsZero() || now.Sub(remote.fetchedAt) > clientJWKSMaxStale {
+		return nil, errors.New("client keys could not be retrieved from jwks_uri")
+	}
+	return remote.keys, nil
+}
+
+// cachedClientJWKS returns the cache entry of a jwks_uri, adding an empty one if there is none.
+// Beyond maxCachedClientJWKS entries, the least recently used is evicted.
+func (s *Service) cachedClientJWKS(uri string) *remoteJWKS {
+	s.clientJWKSMu.Lock()
+	defer s.clientJWKSMu.Unlock()
+
+	remote, ok := s.clientJWKS[uri]
+	if !ok {
+		if s.clientJWKS == nil {
+			s.clientJWKS = make(map[string]*remoteJWKS)
+		}
+		if len(s.clientJWKS) >= maxCachedClientJWKS {
+			var oldest string
+			for u, r := range s.clientJWKS {
+				if oldest == "" || r.usedAt.Before(s.clientJWKS[oldest].usedAt) {
+					oldest = u
+				}
+			}
+			delete(s.clientJWKS, oldest)
+		}
+		remote = &remoteJWKS{}
+		s.clientJWKS[uri] = remote
+	}
+	remote.usedAt = time.Now()
+	return remote
+}
+
+// forgetClientJWKS drops the cached keys of the given jwks_uris
+func (s *Service) forgetClientJWKS(uris ...string) {
+	s.clientJWKSMu.Lock()
+	defer s.clientJWKSMu.Unlock()
+	for _, uri := range uris {
+		delete(s.clientJWKS, uri)
+	}
+}
+
+// publicHTTPClient returns a client for URIs registered by clients, such as a jwks_uri. Connections
+// to private, loopback and link-local addresses are refused at dial time, after DNS resolution, so a
+// client cannot make the server reach its internal network.
+func publicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip, err := netip.ParseAddr(host); err != nil || !publicAddr(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy: the address check must see the host itself
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		// A redirect could point anywhere; the registered URI must answer itself
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddr reports whether ip is a routable unicast address outside private and loopback ranges
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// fetchClientJWKS retrieves and parses the JWKS published at a client's jwks_uri
+func (s *Service) fetchClientJWKS(ctx context.Context, uri string) ([]publicKey, error) {
+	client := s.requestObjectClient
+	if client == nil {
+		client = publicHTTPClient(clientJWKSFetchTimeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxClientJWKSSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxClientJWKSSize {
+		return nil, fmt.Errorf("jwks exceeds %d bytes", maxClientJWKSSize)
+	}
+	return parseJWKS(body)
+}
+
+// SetClientKeys replaces the public keys of a client, which verify its request objects: either
+// a JWKS registered with the client, or a jwks_uri the client publishes its keys at. A jwks_uri is
+// fetched at once, and must serve at least one valid key. Both empty removes the client's keys.
+// It returns the IDs of the keys now in use.
+func (s *Service) SetClientKeys(ctx context.Context, client *Client, jwks json.RawMessage, jwksURI string) ([]string, error) {
+	var keys []publicKey
+	var err error
+	switch {
+	case len(jwks) > 0 && jwksURI != "":
+		return nil, fmt.Errorf("%w: jwks and jwks_uri must not both be set", ErrDomainInvalidMetadata)
+	case jwksURI != "":
+		if !httpsURL(jwksURI) {
+			return nil, fmt.Errorf("%w: jwks_uri must be an absolute https URL", ErrDomainInvalidMetadata)
+		}
+		if keys, err = s.fetchClientJWKS(ctx, jwksURI); err != nil {
+			return nil, fmt.Errorf("%w: jwks_uri: %v", ErrDomainInvalidMetadata, err)
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("%w: jwks_uri serves no signing keys", ErrDomainInvalidMetadata)
+		}
+	default:
+		if keys, err = parseJWKS(jwks); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDomainInvalidMetadata, err)
+		}
+	}
+
+	previous := client.JWKSURI
+	client.JWKS, client.JWKSURI = jwks, jwksURI
+	if err := s.UpdateClient(ctx, client); err != nil {
+		return nil, err
+	}
+	// The keys are fetched again on next use, from the new jwks_uri or after a rotation behind the old one
+	s.forgetClientJWKS(previous, jwksURI)
+
+	kids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		kids = append(kids, k.kid)
+	}
+	return kids, nil
+}
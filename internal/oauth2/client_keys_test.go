@@ -0,0 +1,181 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestPurpose: Validates managing a client's public keys and verifying request objects with keys published at a jwks_uri.
+// Scope: Unit Test
+// Security: Key hygiene and rotation (only valid public keys are accepted; a rotated key is picked up without a restart)
+// Expected: A jwks_uri is fetched when set and its keys verify request objects; fetched keys are cached and fetched again for an unknown kid; keys outlive a failing jwks_uri until they are too stale; jwks with jwks_uri, http URIs, too many keys and repeated kid values are invalid metadata.
+// Test Case ID: OA2-23
+// RelatedSpecs: RFC 7591 Section 2 (jwks, jwks_uri), RFC 9101 Section 6.2
+func TestOAuth2_ClientKeys(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	var fetches atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	served := encodeJWKS(t, rsaJWK("old", &oldKey.PublicKey))
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.WriteHeader(int(status.Load()))
+		w.Write(served)
+	}))
+	defer srv.Close()
+
+	client := &Client{ClientID: "jar-client", TenantID: "t1", IsActive: true}
+	svc := &Service{clientRepo: &MockClientRepo{clients: map[string]*Client{client.ClientID: client}}}
+	svc.EnableRequestObjects(testIssuer, srv.Client())
+	ctx := context.Background()
+
+	kids, err := svc.SetClientKeys(ctx, client, nil, srv.URL+"/jwks.json")
+	if err != nil {
+		t.Fatalf("SetClientKeys: %v", err)
+	}
+	if !slices.Equal(kids, []string{"old"}) || client.JWKSURI != srv.URL+"/jwks.json" || client.JWKS != nil {
+		t.Fatalf("expected the jwks_uri with key old, got %v, %+v", kids, client)
+	}
+
+	resolve := func(method jwt.SigningMethod, kid string, key any) error {
+		object := signRequestObject(t, method, kid, key, jwt.MapClaims{
+			"iss": client.ClientID, "aud": testIssuer, "exp": time.Now().Add(time.Minute).Unix(),
+			"response_type": "code", "redirect_uri": "https://app.example.com/cb",
+		})
+		_, err := svc.ResolveRequestObject(ctx, url.Values{"client_id": {client.ClientID}, "request": {object}})
+		return err
+	}
+
+	fetches.Store(0)
+	for range 3 {
+		if err := resolve(jwt.SigningMethodRS256, "old", oldKey); err != nil {
+			t.Fatalf("expected a request object signed with the published key to resolve, got %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected the published keys to be fetched once and cached, got %d fetches", n)
+	}
+
+	// The client rotates: an unknown kid fetches the keys again once the retry interval has passed
+	served = encodeJWKS(t, rsaJWK("old", &oldKey.PublicKey), ecJWK("new", &newKey.PublicKey))
+	if err := resolve(jwt.SigningMethodES256, "new", newKey); AsError(err).Code != ErrInvalidRequestObject {
+		t.Errorf("expected the new key to be unknown within the retry interval, got %v", err)
+	}
+	remote := func() *remoteJWKS {
+		return svc.clientJWKS[client.JWKSURI]
+	}
+	remote().attemptedAt = time.Now().Add(-clientJWKSRetryInterval)
+	if err := resolve(jwt.SigningMethodES256, "new", newKey); err != nil {
+		t.Errorf("expected the rotated key to be fetched and accepted, got %v", err)
+	}
+
+	// A failing jwks_uri leaves the cached keys in use until they are too stale
+	status.Store(http.StatusInternalServerError)
+	remote().fetchedAt = time.Now().Add(-clientJWKSRefreshInterval)
+	remote().attemptedAt = time.Now().Add(-clientJWKSRetryInterval)
+	if err := resolve(jwt.SigningMethodRS256, "old", oldKey); err != nil {
+		t.Errorf("expected cached keys to outlive a failing jwks_uri, got %v", err)
+	}
+	remote().fetchedAt = time.Now().Add(-clientJWKSMaxStale - time.Minute)
+	if err := resolve(jwt.SigningMethodRS256, "old", oldKey); AsError(err).Code != ErrInvalidRequestObject {
+		t.Errorf("expected stale keys to be refused, got %v", err)
+	}
+	if _, err := svc.SetClientKeys(ctx, client, nil, srv.URL+"/jwks.json"); !errors.Is(err, ErrDomainInvalidMetadata) {
+		t.Errorf("expected an unreachable jwks_uri to be refused, got %v", err)
+	}
+
+	// Uploading a JWKS replaces the jwks_uri
+	jwks := encodeJWKS(t, rsaJWK("old", &oldKey.PublicKey))
+	if kids, err := svc.SetClientKeys(ctx, client, jwks, ""); err != nil || client.JWKSURI != "" || !slices.Equal(kids, []string{"old"}) {
+		t.Errorf("expected the uploaded jwks to replace the jwks_uri, got %v, %v", kids, err)
+	}
+
+	many := make([]map[string]string, maxClientKeys+1)
+	for i := range many {
+		many[i] = ecJWK(fmt.Sprintf("k%d", i), &newKey.PublicKey)
+	}
+	invalid := map[string]struct {
+		jwks json.RawMessage
+		uri  string
+	}{
+		"both":          {jwks, srv.URL + "/jwks.json"},
+		"http uri":      {nil, "http://app.example.com/jwks.json"},
+		"too many keys": {encodeJWKS(t, many...), ""},
+		"repeated kid":  {encodeJWKS(t, rsaJWK("k", &oldKey.PublicKey), ecJWK("k", &newKey.PublicKey)), ""},
+	}
+	for name, tc := range invalid {
+		if _, err := svc.SetClientKeys(ctx, client, tc.jwks, tc.uri); !errors.Is(err, ErrDomainInvalidMetadata) {
+			t.Errorf("%s: expected invalid metadata, got %v", name, err)
+		}
+	}
+}
+
+// TestPurpose: Validates that a jwks_uri is fetched only from public addresses and that the cache of fetched keys is bounded.
+// Scope: Unit Test
+// Security: SSRF (a client must not make the server fetch from its internal network); memory exhaustion (clients registering many jwks_uris)
+// Expected: The default client refuses a jwks_uri on a loopback address without contacting it; the cache holds at most maxCachedClientJWKS jwks_uris and evicts the least recently used.
+// Test Case ID: OA2-25
+// RelatedSpecs: RFC 9101 Section 10.4
+func TestOAuth2_ClientKeys_PublicAddressesAndBoundedCache(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+	}))
+	defer srv.Close()
+
+	client := &Client{ClientID: "jar-client", TenantID: "t1", IsActive: true}
+	svc := &Service{clientRepo: &MockClientRepo{clients: map[string]*Client{client.ClientID: client}}}
+	svc.EnableRequestObjects(testIssuer, nil)
+
+	_, err := svc.SetClientKeys(context.Background(), client, nil, srv.URL+"/jwks.json")
+	if !errors.Is(err, ErrDomainInvalidMetadata) || !strings.Contains(err.Error(), errPrivateAddress.Error()) {
+		t.Errorf("expected a loopback jwks_uri to be refused, got %v", err)
+	}
+	if n := fetches.Load(); n != 0 {
+		t.Errorf("expected the loopback jwks_uri not to be contacted, got %d fetches", n)
+	}
+
+	first := svc.cachedClientJWKS("https://app.example.com/0")
+	first.usedAt = time.Now().Add(-time.Hour)
+	for i := 1; i <= maxCachedClientJWKS; i++ {
+		svc.cachedClientJWKS(fmt.Sprintf("https://app.example.com/%d", i))
+	}
+	if n := len(svc.clientJWKS); n != maxCachedClientJWKS {
+		t.Errorf("expected at most %d cached jwks_uris, got %d", maxCachedClientJWKS, n)
+	}
+	if _, ok := svc.clientJWKS["https://app.example.com/0"]; ok {
+		t.Error("expected the least recently used jwks_uri to be evicted")
+	}
+}
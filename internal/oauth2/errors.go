@@ -14,7 +14,12 @@
 
 package oauth2
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
 
 // Error represents a protocol-level OAuth2 error (RFC 6749).
 // Fix for B-PROTOCOL-01 (Rule 4.2 - Compliance)
@@ -29,6 +34,19 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("oauth2 error: %s (%s)", e.Code, e.Description)
 }
 
+// ErrorCode classifies the error so that apperr.HTTPStatus picks the response status
+func (e *Error) ErrorCode() apperr.Code {
+	switch e.Code {
+	case ErrInvalidClient:
+		return apperr.CodeUnauthenticated
+	case ErrServerError:
+		return apperr.CodeInternal
+	case ErrAccessDenied:
+		return apperr.CodePermissionDenied
+	}
+	return apperr.CodeInvalidArgument
+}
+
 // OAuth2 Standard Error Codes
 const (
 	ErrInvalidRequest         = "invalid_request"
@@ -39,6 +57,18 @@ const (
 	ErrInvalidScope           = "invalid_scope"
 	ErrServerError            = "server_error"
 	ErrTemporarilyUnavailable = "temporarily_unavailable"
+	ErrAccessDenied           = "access_denied"
+)
+
+// ErrInvalidTarget is returned when a requested audience is not acceptable (RFC 8707 Section 2)
+const ErrInvalidTarget = "invalid_target"
+
+// Request object error codes (OIDC Core Section 3.1.2.6)
+const (
+	ErrInvalidRequestURI      = "invalid_request_uri"
+	ErrInvalidRequestObject   = "invalid_request_object"
+	ErrRequestNotSupported    = "request_not_supported"
+	ErrRequestURINotSupported = "request_uri_not_supported"
 )
 
 // NewError creates a new protocol error
@@ -49,6 +79,16 @@ func NewError(code, description string) *Error {
 	}
 }
 
+// AsError returns the protocol error in err's tree, or translates a domain error into one by its
+// apperr code. Errors without a code become an opaque server_error.
+func AsError(err error) *Error {
+	var oauthErr *Error
+	if errors.As(err, &oauthErr) {
+		return oauthErr
+	}
+	return NewError(apperr.OAuthError(err), apperr.Message(err))
+}
+
 // WithState attaches a state parameter to the error
 func (e *Error) WithState(state string) *Error {
 	e.State = state
@@ -16,7 +16,22 @@ package oauth2
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/secrets"
 )
 
 // KeyType represents the type of key
@@ -33,6 +48,33 @@ const (
 	AlgorithmRS256 Algorithm = "RS256"
 )
 
+// KeyStatus is the lifecycle state of a signing key
+type KeyStatus string
+
+const (
+	// KeyStatusPending keys are published in the JWKS ahead of a rotation but do not sign yet
+	KeyStatusPending KeyStatus = "pending"
+	// KeyStatusActive is the key that signs new tokens
+	KeyStatusActive KeyStatus = "active"
+	// KeyStatusRetiring keys no longer sign but stay published until they expire,
+	// so tokens they signed can still be verified
+	KeyStatusRetiring KeyStatus = "retiring"
+	// KeyStatusRetired keys are no longer published
+	KeyStatusRetired KeyStatus = "retired"
+)
+
+// KeyLifetime is how long a new signing key stays valid. Keys should be rotated well before;
+// when the active key expires the server generates a replacement on its next key reload.
+const KeyLifetime = 365 * 24 * time.Hour
+
+// Key lifecycle errors
+var (
+	ErrKeyNotFound      = apperr.New(apperr.CodeNotFound, "signing key not found")
+	ErrKeyActive        = apperr.New(apperr.CodeFailedPrecondition, "the active signing key cannot be retired; rotate first")
+	ErrKeyEncryptionKey = apperr.New(apperr.CodeInvalidArgument, "key encryption key must be exactly 32 bytes")
+	ErrKeyKMSMismatch   = apperr.New(apperr.CodeFailedPrecondition, "private key is wrapped by a KMS key that is not configured; restore the KMS settings or rotate")
+)
+
 // Key represents a cryptographic key for signing tokens
 type Key struct {
 	ID                  string
@@ -40,21 +82,382 @@ type Key struct {
 	Algorithm           Algorithm
 	PublicKey           string // PEM encoded or JWK JSON
 	PrivateKeyEncrypted []byte // Encrypted private key
-	CreatedAt           time.Time
-	ExpiresAt           time.Time
+	// DataKeyEncrypted is the KMS-wrapped data key that seals PrivateKeyEncrypted; nil when the
+	// private key is sealed with the key encryption key directly
+	DataKeyEncrypted []byte
+	KMSKeyID         string // KMS key that wrapped DataKeyEncrypted
+	Status           KeyStatus
+	CreatedAt        time.Time
+	ActivatedAt      *time.Time
+	RetiredAt        *time.Time
+	ExpiresAt        time.Time // Not published or used after this time
+}
+
+// Published reports whether the key belongs in the JWKS at the given time
+func (k *Key) Published(now time.Time) bool {
+	return k.Status != KeyStatusRetired && k.ExpiresAt.After(now)
+}
+
+// RSAPublicKey parses the PEM encoded public key
+func (k *Key) RSAPublicKey() (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(k.PublicKey))
+	if block == nil {
+		return nil, fmt.Errorf("key %s: invalid public key PEM", k.ID)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", k.ID, err)
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key %s: not an RSA public key", k.ID)
+	}
+	return rsaPub, nil
 }
 
 // KeyRepository defines the interface for key persistence
 type KeyRepository interface {
-	// Create stores a new key
+	// Create stores a new key; an empty status is stored as active
 	Create(ctx context.Context, key *Key) error
 
+	// GetByID retrieves a key by ID
+	GetByID(ctx context.Context, id string) (*Key, error)
+
 	// GetActiveKey retrieves the current active signing key
 	GetActiveKey(ctx context.Context) (*Key, error)
 
-	// ListValidKeys retrieves all valid keys (active and not expired)
+	// ListValidKeys retrieves all published keys (not retired and not expired), newest first
 	ListValidKeys(ctx context.Context) ([]*Key, error)
 
-	// Rotate creates a new key and makes it active
-	// Rotate(ctx context.Context) (*Key, error) // For future
+	// List retrieves all keys including retired and expired ones, newest first
+	List(ctx context.Context) ([]*Key, error)
+
+	// Update stores a key's status, activation, retirement and expiry times
+	Update(ctx context.Context, key *Key) error
+}
+
+// KeyManager runs the signing key lifecycle on the persisted key store.
+// Private keys are stored encrypted with the key encryption key (OPENID_KEY_ENCRYPTION_KEY).
+// With a KMS they are envelope-encrypted instead: each key is sealed with its own data key,
+// which only the KMS can unwrap.
+type KeyManager struct {
+	repo          KeyRepository
+	encryptionKey []byte
+	kms           secrets.KMS
+	auditLogger   audit.Logger
+
+	// The unwrapped signing key is cached so reloads do not call the KMS
+	mu         sync.Mutex
+	signingID  string
+	signingKey *rsa.PrivateKey
+}
+
+// NewKeyManager creates a key manager. kms may be nil.
+func NewKeyManager(repo KeyRepository, encryptionKey []byte, kms secrets.KMS, auditLogger audit.Logger) (*KeyManager, error) {
+	if len(encryptionKey) != 32 {
+		return nil, ErrKeyEncryptionKey
+	}
+	return &KeyManager{repo: repo, encryptionKey: encryptionKey, kms: kms, auditLogger: auditLogger}, nil
+}
+
+// List returns all keys, newest first
+func (m *KeyManager) List(ctx context.Context) ([]*Key, error) {
+	return m.repo.List(ctx)
+}
+
+// Generate creates a pending key. It is published right away so relying parties can cache
+// it before a later Rotate makes it the signing key.
+func (m *KeyManager) Generate(ctx context.Context, actorID string) (*Key, error) {
+	key, err := m.newKey(ctx, KeyStatusPending, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := m.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	m.log(ctx, audit.TypeSigningKeyGenerated, actorID, &audit.SigningKeyPayload{KeyID: key.ID})
+	return key, nil
+}
+
+// Rotate activates the newest pending key, or a new key when none is pending. The previous
+// active key keeps being published for grace so tokens it signed still verify.
+func (m *KeyManager) Rotate(ctx context.Context, grace time.Duration, actorID string) (*Key, error) {
+	keys, err := m.repo.ListValidKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	var next *Key
+	for _, key := range keys {
+		if key.Status == KeyStatusPending {
+			next = key
+			break
+		}
+	}
+	if next == nil {
+		if next, err = m.newKey(ctx, KeyStatusPending, now); err != nil {
+			return nil, err
+		}
+		if err := m.repo.Create(ctx, next); err != nil {
+			return nil, err
+		}
+	}
+	next.Status = KeyStatusActive
+	next.ActivatedAt = &now
+	if err := m.repo.Update(ctx, next); err != nil {
+		return nil, err
+	}
+
+	var previous []string
+	for _, key := range keys {
+		if key.Status != KeyStatusActive || key.ID == next.ID {
+			continue
+		}
+		key.Status = KeyStatusRetiring
+		if deadline := now.Add(grace); deadline.Before(key.ExpiresAt) {
+			key.ExpiresAt = deadline
+		}
+		if err := m.repo.Update(ctx, key); err != nil {
+			return nil, err
+		}
+		previous = append(previous, key.ID)
+	}
+
+	m.log(ctx, audit.TypeSigningKeyRotated, actorID, &audit.SigningKeyPayload{KeyID: next.ID, PreviousKeyIDs: previous})
+	return next, nil
+}
+
+// EmergencyRotate activates a new key and retires every other key at once, so tokens signed
+// by a compromised key stop verifying as soon as relying parties refresh the JWKS.
+func (m *KeyManager) EmergencyRotate(ctx context.Context, actorID string) (*Key, error) {
+	keys, err := m.repo.ListValidKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	next, err := m.newKey(ctx, KeyStatusActive, now)
+	if err != nil {
+		return nil, err
+	}
+	next.ActivatedAt = &now
+	if err := m.repo.Create(ctx, next); err != nil {
+		return nil, err
+	}
+
+	var previous []string
+	for _, key := range keys {
+		if err := m.retire(ctx, key, now); err != nil {
+			return nil, err
+		}
+		previous = append(previous, key.ID)
+	}
+
+	m.log(ctx, audit.TypeSigningKeyRotated, actorID, &audit.SigningKeyPayload{KeyID: next.ID, PreviousKeyIDs: previous, Emergency: true})
+	return next, nil
+}
+
+// Retire stops publishing a pending or retiring key. The active key must be rotated out first.
+func (m *KeyManager) Retire(ctx context.Context, keyID, actorID string) error {
+	key, err := m.repo.GetByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.Status == KeyStatusRetired {
+		return nil
+	}
+	if key.Status == KeyStatusActive && key.ExpiresAt.After(time.Now()) {
+		return ErrKeyActive
+	}
+	if err := m.retire(ctx, key, time.Now()); err != nil {
+		return err
+	}
+	m.log(ctx, audit.TypeSigningKeyRetired, actorID, &audit.SigningKeyPayload{KeyID: key.ID})
+	return nil
+}
+
+// SigningKeys returns the private key that signs tokens and the public keys to publish.
+// On first use, when the store has no active key, one is generated and activated.
+func (m *KeyManager) SigningKeys(ctx context.Context) (*rsa.PrivateKey, []*rsa.PublicKey, error) {
+	active, err := m.repo.GetActiveKey(ctx)
+	if err != nil {
+		// Only generate when the store is reachable; a transient error must not mint a key per reload
+		if _, err := m.repo.ListValidKeys(ctx); err != nil {
+			return nil, nil, err
+		}
+		now := time.Now()
+		if active, err = m.newKey(ctx, KeyStatusActive, now); err != nil {
+			return nil, nil, err
+		}
+		active.ActivatedAt = &now
+		if err := m.repo.Create(ctx, active); err != nil {
+			return nil, nil, err
+		}
+		m.log(ctx, audit.TypeSigningKeyGenerated, audit.ActorSystemBootstrap, &audit.SigningKeyPayload{KeyID: active.ID})
+	}
+
+	signing, err := m.privateKey(ctx, active)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys, err := m.repo.ListValidKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	published := []*rsa.PublicKey{&signing.PublicKey}
+	for _, key := range keys {
+		if key.ID == active.ID {
+			continue
+		}
+		pub, err := key.RSAPublicKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		published = append(published, pub)
+	}
+	return signing, published, nil
+}
+
+// privateKey decrypts a stored private key, unwrapping its data key with the KMS if it has one
+func (m *KeyManager) privateKey(ctx context.Context, key *Key) (*rsa.PrivateKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.signingID == key.ID {
+		return m.signingKey, nil
+	}
+
+	sealingKey := m.encryptionKey
+	if key.DataKeyEncrypted != nil {
+		if m.kms == nil || m.kms.KeyID() != key.KMSKeyID {
+			return nil, fmt.Errorf("key %s: %w", key.ID, ErrKeyKMSMismatch)
+		}
+		dataKey, err := m.kms.Decrypt(ctx, key.DataKeyEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: failed to unwrap data key with %s: %w", key.ID, key.KMSKeyID, err)
+		}
+		sealingKey = dataKey
+	}
+	der, err := decrypt(sealingKey, key.PrivateKeyEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: failed to decrypt private key: %w", key.ID, err)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", key.ID, err)
+	}
+	private, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key %s: not an RSA private key", key.ID)
+	}
+	m.signingID, m.signingKey = key.ID, private
+	return private, nil
+}
+
+// newKey generates an RSA key pair with the private key encrypted for storage
+func (m *KeyManager) newKey(ctx context.Context, status KeyStatus, now time.Time) (*Key, error) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	sealingKey := m.encryptionKey
+	var wrapped []byte
+	var kmsKeyID string
+	if m.kms != nil {
+		sealingKey = make([]byte, 32)
+		if _, err := rand.Read(sealingKey); err != nil {
+			return nil, err
+		}
+		if wrapped, err = m.kms.Encrypt(ctx, sealingKey); err != nil {
+			return nil, fmt.Errorf("failed to wrap data key with %s: %w", m.kms.KeyID(), err)
+		}
+		kmsKeyID = m.kms.KeyID()
+	}
+	encrypted, err := encrypt(sealingKey, der)
+	if err != nil {
+		return nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		ID:                  id.NewUUIDv7(),
+		Type:                KeyTypeRSA,
+		Algorithm:           AlgorithmRS256,
+		PublicKey:           string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})),
+		PrivateKeyEncrypted: encrypted,
+		DataKeyEncrypted:    wrapped,
+		KMSKeyID:            kmsKeyID,
+		Status:              status,
+		CreatedAt:           now,
+		ExpiresAt:           now.Add(KeyLifetime),
+	}, nil
+}
+
+func (m *KeyManager) retire(ctx context.Context, key *Key, now time.Time) error {
+	key.Status = KeyStatusRetired
+	key.RetiredAt = &now
+	if now.Before(key.ExpiresAt) {
+		key.ExpiresAt = now
+	}
+	return m.repo.Update(ctx, key)
+}
+
+func (m *KeyManager) log(ctx context.Context, eventType, actorID string, payload *audit.SigningKeyPayload) {
+	if m.auditLogger == nil {
+		return
+	}
+	m.auditLogger.Log(ctx, audit.Event{
+		Type:     eventType,
+		ActorID:  actorID,
+		Resource: audit.ResourceSigningKey,
+		Payload:  payload,
+	})
+}
+
+// encrypt seals data with AES-256-GCM, prefixing the nonce
+func encrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// decrypt opens data sealed by encrypt
+func decrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	return gcm.Open(nil, nonce, ciphertext, nil)
 }
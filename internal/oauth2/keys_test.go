@@ -0,0 +1,190 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2_test
+
+import (
+	"context"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEncryptionKey = []byte("01234567890123456789012345678901")
+
+func newKeyManager(t *testing.T) (*oauth2.KeyManager, *memory.KeyRepository) {
+	t.Helper()
+	db := memory.New()
+	repo := memory.NewKeyRepository(db)
+	m, err := oauth2.NewKeyManager(repo, testEncryptionKey, nil, audit.NewStoreLogger(memory.NewAuditRepository(db)))
+	require.NoError(t, err)
+	return m, repo
+}
+
+func publishedIDs(t *testing.T, repo *memory.KeyRepository) []string {
+	t.Helper()
+	keys, err := repo.ListValidKeys(context.Background())
+	require.NoError(t, err)
+	var ids []string
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	return ids
+}
+
+func samePublicKey(t *testing.T, key *oauth2.Key, pub *rsa.PublicKey) bool {
+	t.Helper()
+	stored, err := key.RSAPublicKey()
+	require.NoError(t, err)
+	return stored.Equal(pub)
+}
+
+// TestPurpose: Validates the planned signing key rotation: publish a pending key, activate it, keep the old key for the grace period.
+// Scope: Unit Test
+// Security: Key rotation (relying parties can verify tokens across the rotation; private keys are stored encrypted)
+// Expected: The first load creates an active key; a pending key is published but does not sign; rotation activates it and the old key retires after the grace period.
+// Test Case ID: OA2-05
+// RelatedSpecs: RFC 7517, OIDC Core Section 10.1.1
+func TestOAuth2_KeyManager_Rotate(t *testing.T) {
+	ctx := context.Background()
+	m, repo := newKeyManager(t)
+
+	signing, published, err := m.SigningKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, published, 1)
+	first, err := repo.GetActiveKey(ctx)
+	require.NoError(t, err)
+	assert.True(t, samePublicKey(t, first, &signing.PublicKey))
+	assert.NotContains(t, string(first.PrivateKeyEncrypted), "PRIVATE KEY")
+
+	pending, err := m.Generate(ctx, audit.ActorCLI)
+	require.NoError(t, err)
+	assert.Equal(t, oauth2.KeyStatusPending, pending.Status)
+	signing, published, err = m.SigningKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, published, 2, "the pending key is published ahead of the rotation")
+	assert.True(t, samePublicKey(t, first, &signing.PublicKey), "the pending key must not sign yet")
+
+	rotated, err := m.Rotate(ctx, time.Hour, audit.ActorCLI)
+	require.NoError(t, err)
+	assert.Equal(t, pending.ID, rotated.ID)
+	signing, published, err = m.SigningKeys(ctx)
+	require.NoError(t, err)
+	assert.True(t, samePublicKey(t, pending, &signing.PublicKey))
+	assert.Len(t, published, 2, "the previous key stays published for the grace period")
+
+	old, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, oauth2.KeyStatusRetiring, old.Status)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), old.ExpiresAt, time.Minute)
+
+	require.NoError(t, m.Retire(ctx, first.ID, audit.ActorCLI))
+	assert.Equal(t, []string{pending.ID}, publishedIDs(t, repo))
+}
+
+// TestPurpose: Validates emergency rotation and the guard against retiring the signing key.
+// Scope: Unit Test
+// Security: Key compromise response (a compromised key stops being published at once)
+// Expected: Retiring the active key fails; emergency rotation leaves only the new key published.
+// Test Case ID: OA2-06
+// RelatedSpecs: RFC 7517
+func TestOAuth2_KeyManager_EmergencyRotate(t *testing.T) {
+	ctx := context.Background()
+	m, repo := newKeyManager(t)
+
+	_, _, err := m.SigningKeys(ctx)
+	require.NoError(t, err)
+	active, err := repo.GetActiveKey(ctx)
+	require.NoError(t, err)
+	_, err = m.Generate(ctx, audit.ActorCLI)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, m.Retire(ctx, active.ID, audit.ActorCLI), oauth2.ErrKeyActive)
+	assert.ErrorIs(t, m.Retire(ctx, "missing", audit.ActorCLI), oauth2.ErrKeyNotFound)
+
+	next, err := m.EmergencyRotate(ctx, audit.ActorCLI)
+	require.NoError(t, err)
+	assert.Equal(t, []string{next.ID}, publishedIDs(t, repo))
+
+	compromised, err := repo.GetByID(ctx, active.ID)
+	require.NoError(t, err)
+	assert.Equal(t, oauth2.KeyStatusRetired, compromised.Status)
+	assert.NotNil(t, compromised.RetiredAt)
+}
+
+// fakeKMS wraps data keys by XOR with a fixed byte and counts unwraps
+type fakeKMS struct {
+	decrypts int
+}
+
+func (k *fakeKMS) KeyID() string { return "fake-kms:test" }
+
+func (k *fakeKMS) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	out := make([]byte, len(plaintext))
+	for i, b := range plaintext {
+		out[i] = b ^ 0x5a
+	}
+	return out, nil
+}
+
+func (k *fakeKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	k.decrypts++
+	return k.Encrypt(ctx, ciphertext)
+}
+
+// TestPurpose: Validates envelope encryption of stored signing keys with a KMS.
+// Scope: Unit Test
+// Security: Key protection at rest (a stolen database and OPENID_KEY_ENCRYPTION_KEY do not reveal KMS-wrapped private keys)
+// Expected: New keys carry a wrapped data key; they load only with the same KMS, which is called once per key;
+// rotating without the KMS moves signing back to keys sealed with the key encryption key.
+// Test Case ID: OA2-08
+func TestOAuth2_KeyManager_EnvelopeEncryption(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	repo := memory.NewKeyRepository(db)
+	kms := &fakeKMS{}
+	m, err := oauth2.NewKeyManager(repo, testEncryptionKey, kms, nil)
+	require.NoError(t, err)
+
+	signing, _, err := m.SigningKeys(ctx)
+	require.NoError(t, err)
+	active, err := repo.GetActiveKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fake-kms:test", active.KMSKeyID)
+	assert.Len(t, active.DataKeyEncrypted, 32)
+
+	again, _, err := m.SigningKeys(ctx)
+	require.NoError(t, err)
+	assert.True(t, signing.Equal(again))
+	assert.Equal(t, 1, kms.decrypts, "the unwrapped key is cached across reloads")
+
+	plain, err := oauth2.NewKeyManager(repo, testEncryptionKey, nil, nil)
+	require.NoError(t, err)
+	_, _, err = plain.SigningKeys(ctx)
+	assert.ErrorIs(t, err, oauth2.ErrKeyKMSMismatch)
+
+	next, err := plain.Rotate(ctx, time.Hour, audit.ActorCLI)
+	require.NoError(t, err)
+	assert.Empty(t, next.KMSKeyID)
+	assert.Nil(t, next.DataKeyEncrypted)
+	_, published, err := plain.SigningKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, published, 2)
+}
@@ -15,25 +15,31 @@
 package oauth2
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
 	"strings"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/pagination"
 )
 
 // Domain errors (Internal)
 var (
-	ErrClientNotFound           = errors.New("client not found")
-	ErrClientAlreadyExists      = errors.New("client already exists")
-	ErrDomainInvalidRedirectURI = errors.New("invalid redirect URI")
-	ErrDomainInvalidScope       = errors.New("invalid scope")
-	ErrDomainInvalidGrantType   = errors.New("invalid grant type")
-	ErrCodeExpired              = errors.New("authorization code expired")
-	ErrCodeAlreadyUsed          = errors.New("authorization code already used")
-	ErrCodeNotFound             = errors.New("authorization code not found")
-	ErrDomainInvalidClient      = errors.New("invalid client credentials")
-	ErrTokenExpired             = errors.New("token expired")
-	ErrTokenRevoked             = errors.New("token revoked")
-	ErrTokenNotFound            = errors.New("token not found")
+	ErrClientNotFound           = apperr.New(apperr.CodeNotFound, "client not found")
+	ErrClientAlreadyExists      = apperr.New(apperr.CodeAlreadyExists, "client already exists")
+	ErrClientVersionConflict    = apperr.New(apperr.CodeConflict, "client was modified concurrently")
+	ErrDomainInvalidRedirectURI = apperr.New(apperr.CodeInvalidArgument, "invalid redirect URI")
+	ErrDomainInvalidScope       = apperr.New(apperr.CodeInvalidArgument, "invalid scope")
+	ErrDomainInvalidGrantType   = apperr.New(apperr.CodeInvalidArgument, "invalid grant type")
+	ErrCodeExpired              = apperr.New(apperr.CodeFailedPrecondition, "authorization code expired")
+	ErrCodeAlreadyUsed          = apperr.New(apperr.CodeFailedPrecondition, "authorization code already used")
+	ErrCodeNotFound             = apperr.New(apperr.CodeNotFound, "authorization code not found")
+	ErrDomainInvalidClient      = apperr.New(apperr.CodeUnauthenticated, "invalid client credentials")
+	ErrTokenExpired             = apperr.New(apperr.CodeFailedPrecondition, "token expired")
+	ErrTokenRevoked             = apperr.New(apperr.CodeFailedPrecondition, "token revoked")
+	ErrTokenNotFound            = apperr.New(apperr.CodeNotFound, "token not found")
+	ErrDomainInvalidMetadata    = apperr.New(apperr.CodeInvalidArgument, "invalid client metadata")
 )
 
 const (
@@ -43,27 +49,36 @@ const (
 
 // Client represents an OAuth2 client application
 type Client struct {
-	ID                      string     `json:"id"`
-	ClientID                string     `json:"client_id"`
-	TenantID                string     `json:"tenant_id"`
-	ClientSecretHash        string     `json:"-"`
-	ClientName              string     `json:"client_name"`
-	ClientURI               string     `json:"client_uri,omitempty"`
-	LogoURI                 string     `json:"logo_uri,omitempty"`
-	RedirectURIs            []string   `json:"redirect_uris"`
-	AllowedScopes           []string   `json:"allowed_scopes"`
-	GrantTypes              []string   `json:"grant_types"`
-	ResponseTypes           []string   `json:"response_types"`
-	TokenEndpointAuthMethod string     `json:"token_endpoint_auth_method"`
-	AccessTokenLifetime     int        `json:"access_token_lifetime"`
-	RefreshTokenLifetime    int        `json:"refresh_token_lifetime"`
-	IDTokenLifetime         int        `json:"id_token_lifetime"`
-	OwnerID                 string     `json:"owner_id,omitempty"`
-	IsTrusted               bool       `json:"is_trusted"`
-	IsActive                bool       `json:"is_active"`
-	CreatedAt               time.Time  `json:"created_at"`
-	UpdatedAt               time.Time  `json:"updated_at"`
-	DeletedAt               *time.Time `json:"deleted_at,omitempty"`
+	ID                         string          `json:"id"`
+	ClientID                   string          `json:"client_id"`
+	TenantID                   string          `json:"tenant_id"`
+	ClientSecretHash           string          `json:"-"`
+	ClientName                 string          `json:"client_name"`
+	ClientURI                  string          `json:"client_uri,omitempty"`
+	LogoURI                    string          `json:"logo_uri,omitempty"`
+	RedirectURIs               []string        `json:"redirect_uris"`
+	AllowedScopes              []string        `json:"allowed_scopes"`
+	GrantTypes                 []string        `json:"grant_types"`
+	ResponseTypes              []string        `json:"response_types"`
+	TokenEndpointAuthMethod    string          `json:"token_endpoint_auth_method"`
+	JWKS                       json.RawMessage `json:"jwks,omitempty" swaggertype:"object"` // Public keys verifying the client's request objects (RFC 7591 Section 2)
+	JWKSURI                    string          `json:"jwks_uri,omitempty"`                  // https URL the client publishes its public keys at, instead of JWKS
+	RequestURIs                []string        `json:"request_uris,omitempty"`              // https URLs the server may fetch the client's request objects from
+	RequireSignedRequestObject bool            `json:"require_signed_request_object"`       // Authorization requests must come in a request object (RFC 9101 Section 10.5)
+	AllowedOrigins             []string        `json:"allowed_origins,omitempty"`           // Browser origins that may call the token and revocation endpoints; empty derives them from RedirectURIs
+	ClientGroup                string          `json:"client_group,omitempty"`              // Clients of the tenant in the same group may be ID token audiences of each other
+	ApplicationType            string          `json:"application_type,omitempty"`          // web, native, spa or service; restricts the other metadata. Empty is unrestricted
+	MinStateLength             int             `json:"min_state_length,omitempty"`          // Authorization requests must carry a state of at least this length; zero leaves state optional
+	AccessTokenLifetime        int             `json:"access_token_lifetime"`
+	RefreshTokenLifetime       int             `json:"refresh_token_lifetime"`
+	IDTokenLifetime            int             `json:"id_token_lifetime"`
+	OwnerID                    string          `json:"owner_id,omitempty"`
+	IsTrusted                  bool            `json:"is_trusted"`
+	IsActive                   bool            `json:"is_active"`
+	Version                    int             `json:"version"` // Incremented on every update; Update requires it to match the stored version
+	CreatedAt                  time.Time       `json:"created_at"`
+	UpdatedAt                  time.Time       `json:"updated_at"`
+	DeletedAt                  *time.Time      `json:"deleted_at,omitempty"`
 }
 
 // ValidateRedirectURI checks if the redirect URI is allowed for this client
@@ -105,6 +120,7 @@ func (c *Client) ValidateScope(requestedScope string) bool {
 // AuthorizationCode represents a short-lived authorization code
 type AuthorizationCode struct {
 	ID                  string
+	TenantID            string
 	Code                string
 	ClientID            string
 	UserID              string
@@ -114,6 +130,7 @@ type AuthorizationCode struct {
 	Nonce               string
 	CodeChallenge       string
 	CodeChallengeMethod string
+	SessionID           string // The login session the code was issued under; empty when issued without one
 	ExpiresAt           time.Time
 	UsedAt              *time.Time
 	IsUsed              bool
@@ -134,6 +151,7 @@ type AccessToken struct {
 	UserID    string
 	Scope     string
 	TokenType string
+	Claims    map[string]any // Custom claims added at issuance (ClaimEnricher), reported by introspection
 	ExpiresAt time.Time
 	RevokedAt *time.Time
 	IsRevoked bool
@@ -168,71 +186,99 @@ func (r *RefreshToken) IsExpired() bool {
 // ClientRepository defines the interface for OAuth2 client persistence
 type ClientRepository interface {
 	// Create creates a new OAuth2 client
-	Create(client *Client) error
+	Create(ctx context.Context, client *Client) error
 
 	// GetByClientID retrieves a client by client_id
-	GetByClientID(clientID string) (*Client, error)
+	GetByClientID(ctx context.Context, clientID string) (*Client, error)
 
 	// GetByID retrieves a client by internal ID
-	GetByID(id string) (*Client, error)
+	GetByID(ctx context.Context, id string) (*Client, error)
 
-	// Update updates client information
-	Update(client *Client) error
+	// Update updates client information if client.Version still matches the stored version,
+	// returning ErrClientVersionConflict otherwise. On success client.Version is incremented.
+	Update(ctx context.Context, client *Client) error
 
 	// Delete soft-deletes a client
-	Delete(id string) error
+	Delete(ctx context.Context, id string) error
+
+	// ListByOwner retrieves a page of clients for an owner, ordered by ID
+	ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*Client, error)
 
-	// ListByOwner retrieves all clients for an owner
-	ListByOwner(ownerID string) ([]*Client, error)
+	// ListByTenant retrieves a page of clients for a tenant, ordered by ID
+	ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*Client, error)
 
-	// ListByTenant retrieves all clients for a tenant
-	ListByTenant(tenantID string) ([]*Client, error)
+	// PurgeDeleted permanently removes up to limit clients soft-deleted before the given time
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
 }
 
-// AuthorizationCodeRepository defines the interface for authorization code persistence
+// AuthorizationCodeRepository defines the interface for authorization code persistence.
+// Lookups are scoped to a tenant: a code issued for one tenant's client is not found in another tenant.
 type AuthorizationCodeRepository interface {
 	// Create creates a new authorization code
-	Create(code *AuthorizationCode) error
+	Create(ctx context.Context, code *AuthorizationCode) error
 
-	// GetByCode retrieves an authorization code
-	GetByCode(code string) (*AuthorizationCode, error)
+	// GetByCode retrieves an authorization code issued within a tenant
+	GetByCode(ctx context.Context, tenantID, code string) (*AuthorizationCode, error)
 
 	// MarkAsUsed marks the code as used
-	MarkAsUsed(code string) error
+	MarkAsUsed(ctx context.Context, tenantID, code string) error
 
 	// Delete deletes an authorization code
-	Delete(code string) error
+	Delete(ctx context.Context, tenantID, code string) error
 
-	// DeleteExpired deletes all expired authorization codes
-	DeleteExpired() error
+	// DeleteExpired deletes up to limit expired authorization codes and returns how many were removed
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
 }
 
-// AccessTokenRepository defines the interface for access token persistence
+// AccessTokenRepository defines the interface for access token persistence.
+// Lookups are scoped to the tenant the token was issued in, except FindByTokenHash.
 type AccessTokenRepository interface {
 	// Create creates a new access token
-	Create(token *AccessToken) error
+	Create(ctx context.Context, token *AccessToken) error
+
+	// GetByTokenHash retrieves an access token issued within a tenant
+	GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*AccessToken, error)
 
-	// GetByTokenHash retrieves an access token
-	GetByTokenHash(tokenHash string) (*AccessToken, error)
+	// FindByTokenHash retrieves an access token issued within any tenant, for requests that name no tenant
+	FindByTokenHash(ctx context.Context, tokenHash string) (*AccessToken, error)
 
 	// Revoke revokes an access token
-	Revoke(tokenHash string) error
+	Revoke(ctx context.Context, tenantID, tokenHash string) error
+
+	// RevokeByUserID revokes every unrevoked access token of a user and returns how many were revoked
+	RevokeByUserID(ctx context.Context, userID string) (int64, error)
+
+	// RevokeByTenantID revokes up to limit unexpired, unrevoked access tokens of a tenant and returns how many were revoked
+	RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error)
 
-	// DeleteExpired deletes all expired access tokens
-	DeleteExpired() error
+	// RevokeByClientID revokes up to limit unexpired, unrevoked access tokens issued to a client and returns how many were revoked
+	RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error)
+
+	// DeleteExpired deletes up to limit expired access tokens and returns how many were removed
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
 }
 
-// RefreshTokenRepository defines the interface for refresh token persistence
+// RefreshTokenRepository defines the interface for refresh token persistence.
+// Lookups are scoped to the tenant the token was issued in.
 type RefreshTokenRepository interface {
 	// Create creates a new refresh token
-	Create(token *RefreshToken) error
+	Create(ctx context.Context, token *RefreshToken) error
 
-	// GetByTokenHash retrieves a refresh token
-	GetByTokenHash(tokenHash string) (*RefreshToken, error)
+	// GetByTokenHash retrieves a refresh token issued within a tenant
+	GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*RefreshToken, error)
 
 	// Revoke revokes a refresh token
-	Revoke(tokenHash string) error
+	Revoke(ctx context.Context, tenantID, tokenHash string) error
+
+	// RevokeByUserID revokes every unrevoked refresh token of a user and returns how many were revoked
+	RevokeByUserID(ctx context.Context, userID string) (int64, error)
+
+	// RevokeByTenantID revokes up to limit unexpired, unrevoked refresh tokens of a tenant and returns how many were revoked
+	RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error)
+
+	// RevokeByClientID revokes up to limit unexpired, unrevoked refresh tokens issued to a client and returns how many were revoked
+	RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error)
 
-	// DeleteExpired deletes all expired refresh tokens
-	DeleteExpired() error
+	// DeleteExpired deletes up to limit expired refresh tokens and returns how many were removed
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
 }
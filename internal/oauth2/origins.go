@@ -0,0 +1,96 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Origins returns the browser origins allowed to call the token and revocation endpoints for the
+// client: AllowedOrigins when set, otherwise the origins of its http and https redirect URIs
+func (c *Client) Origins() []string {
+	if len(c.AllowedOrigins) > 0 {
+		return c.AllowedOrigins
+	}
+	var origins []string
+	for _, uri := range c.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		if origin := webOrigin(u); !slices.Contains(origins, origin) {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// AllowsOrigin reports whether a browser at origin may call the token and revocation endpoints
+// for the client
+func (c *Client) AllowsOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || !IsWebOrigin(origin) {
+		return false
+	}
+	return slices.Contains(c.Origins(), webOrigin(u))
+}
+
+// IsWebOrigin reports whether origin is a serialized http or https origin (RFC 6454 Section 6.2),
+// without path, query or fragment
+func IsWebOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.User == nil && u.Path == "" && u.RawQuery == "" && u.Fragment == "" && !strings.HasSuffix(origin, "?")
+}
+
+// webOrigin serializes the origin of u, lowercase and without the scheme's default port
+func webOrigin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && !(scheme == "https" && port == "443") && !(scheme == "http" && port == "80") {
+		host += ":" + port
+	}
+	return scheme + "://" + host
+}
+
+// validateAllowedOrigins checks that the explicit origins of a client are web origins, served
+// over https unless they are loopback addresses
+func validateAllowedOrigins(client *Client) error {
+	for _, origin := range client.AllowedOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || !IsWebOrigin(origin) {
+			return fmt.Errorf("%w: allowed origin %q must be a scheme and host without a path", ErrDomainInvalidMetadata, origin)
+		}
+		if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+			return fmt.Errorf("%w: allowed origin %q must use https", ErrDomainInvalidMetadata, origin)
+		}
+	}
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
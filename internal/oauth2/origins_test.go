@@ -0,0 +1,51 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestPurpose: Validates the browser origins a client allows and the validation of explicit ones.
+// Scope: Unit Test
+// Security: Cross-origin token access (origins are exact web origins, https unless loopback)
+// Expected: Origins derive from http and https redirect URIs without default ports or duplicates; explicit origins replace them; origins with a path, a wildcard or plain http on a public host are invalid metadata.
+// Test Case ID: OA2-17
+func TestOAuth2_ClientOrigins(t *testing.T) {
+	client := &Client{RedirectURIs: []string{"https://App.example.com:443/cb", "https://app.example.com/other", "http://localhost:3000/cb", "com.example.app:/cb"}}
+	if got := client.Origins(); len(got) != 2 || got[0] != "https://app.example.com" || got[1] != "http://localhost:3000" {
+		t.Errorf("unexpected derived origins %v", got)
+	}
+	if !client.AllowsOrigin("https://app.example.com") || client.AllowsOrigin("https://app.example.com/") || client.AllowsOrigin("https://evil.example.com") {
+		t.Error("expected only the exact derived origins to be allowed")
+	}
+
+	client.AllowedOrigins = []string{"https://cdn.example.com"}
+	if client.AllowsOrigin("https://app.example.com") || !client.AllowsOrigin("https://cdn.example.com") {
+		t.Error("expected explicit origins to replace the derived ones")
+	}
+
+	for _, origin := range []string{"https://app.example.com", "http://127.0.0.1:8080", "http://localhost:3000"} {
+		if err := validateAllowedOrigins(&Client{AllowedOrigins: []string{origin}}); err != nil {
+			t.Errorf("expected %s to be valid, got %v", origin, err)
+		}
+	}
+	for _, origin := range []string{"https://app.example.com/", "https://app.example.com/path", "*", "http://app.example.com", "https://user@app.example.com", "app.example.com"} {
+		if err := validateAllowedOrigins(&Client{AllowedOrigins: []string{origin}}); !errors.Is(err, ErrDomainInvalidMetadata) {
+			t.Errorf("expected %s to be invalid metadata, got %v", origin, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// pendingAuthorizationLifetime bounds how long a user has to sign in before the authorization
+// request they started must be sent again
+const pendingAuthorizationLifetime = 10 * time.Minute
+
+// ErrPendingAuthorizationNotFound is returned for an unknown, expired or already resumed request
+var ErrPendingAuthorizationNotFound = apperr.New(apperr.CodeNotFound, "pending authorization not found")
+
+// PendingAuthorization is a validated authorization request kept while the user signs in
+type PendingAuthorization struct {
+	ID        string
+	ClientID  string
+	Params    url.Values
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// PendingAuthorizationRepository defines the interface for pending authorization persistence
+type PendingAuthorizationRepository interface {
+	// Create stores a pending authorization
+	Create(ctx context.Context, pending *PendingAuthorization) error
+
+	// Consume deletes an unexpired pending authorization and returns it
+	Consume(ctx context.Context, id string) (*PendingAuthorization, error)
+
+	// DeleteExpired deletes up to limit expired pending authorizations and returns how many were removed
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
+}
+
+// EnablePendingAuthorizations keeps authorization requests made before signing in in repo, so they
+// resume once the user has authenticated. Without it, such requests are refused and must be sent
+// again after login.
+func (s *Service) EnablePendingAuthorizations(repo PendingAuthorizationRepository) {
+	s.pendingRepo = repo
+}
+
+// SavePendingAuthorization keeps the parameters of a validated authorization request and returns
+// the ID that resumes it. It returns an empty ID when pending authorizations are not enabled.
+func (s *Service) SavePendingAuthorization(ctx context.Context, params url.Values) (string, error) {
+	if s.pendingRepo == nil {
+		return "", nil
+	}
+
+	now := time.Now()
+	pending := &PendingAuthorization{
+		ID:        generateToken(),
+		ClientID:  params.Get("client_id"),
+		Params:    params,
+		ExpiresAt: now.Add(pendingAuthorizationLifetime),
+		CreatedAt: now,
+	}
+	if err := s.pendingRepo.Create(ctx, pending); err != nil {
+		return "", NewError(ErrServerError, "failed to save authorization request")
+	}
+	return pending.ID, nil
+}
+
+// ResumePendingAuthorization returns the parameters of a pending authorization request. A request
+// resumes once; it must be validated again since the client may have changed while the user signed in.
+func (s *Service) ResumePendingAuthorization(ctx context.Context, id string) (url.Values, error) {
+	if s.pendingRepo == nil {
+		return nil, NewError(ErrInvalidRequest, "authorization_request not supported")
+	}
+
+	pending, err := s.pendingRepo.Consume(ctx, id)
+	if errors.Is(err, ErrPendingAuthorizationNotFound) {
+		return nil, NewError(ErrInvalidRequest, "authorization request expired or unknown")
+	}
+	if err != nil {
+		return nil, NewError(ErrServerError, "failed to resume authorization request")
+	}
+	return pending.Params, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// Protocol log errors
+var (
+	ErrProtocolLogNotFound = apperr.New(apperr.CodeNotFound, "protocol log settings not found")
+	ErrInvalidProtocolLog  = apperr.New(apperr.CodeInvalidArgument, "invalid protocol log settings")
+)
+
+// MaxProtocolLogDuration bounds how long protocol logging stays on once it is given an end
+const MaxProtocolLogDuration = 30 * 24 * time.Hour
+
+// protocolLogCacheTTL bounds how long a tenant's setting is cached, and so how long a change takes
+// to reach every instance
+const protocolLogCacheTTL = 30 * time.Second
+
+// Protocol decisions
+const (
+	DecisionGranted       = "granted"
+	DecisionDenied        = "denied"
+	DecisionLoginRequired = "login_required"
+)
+
+// ProtocolLogSettings turns on logging of a tenant's authorization and token decisions, so that a
+// client integration can be debugged without debug logging for the whole platform
+type ProtocolLogSettings struct {
+	TenantID  string     `json:"tenant_id"`
+	Enabled   bool       `json:"enabled"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Logging stops by itself after this time
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// active reports whether decisions are logged at now
+func (p *ProtocolLogSettings) active(now time.Time) bool {
+	return p.Enabled && (p.ExpiresAt == nil || now.Before(*p.ExpiresAt))
+}
+
+// ProtocolLogRepository persists per-tenant protocol log settings
+type ProtocolLogRepository interface {
+	// Get retrieves a tenant's settings
+	Get(ctx context.Context, tenantID string) (*ProtocolLogSettings, error)
+
+	// Upsert creates or replaces a tenant's settings
+	Upsert(ctx context.Context, settings *ProtocolLogSettings) error
+}
+
+// ProtocolDecision is the outcome of an authorization or token request. It carries only what
+// helps to debug an integration: codes, tokens, secrets, redirect URIs and users are never part of it.
+type ProtocolDecision struct {
+	RequestID string
+	Endpoint  string // authorize or token
+	TenantID  string // Resolved from ClientID when empty
+	ClientID  string
+	GrantType string // The response_type at the authorization endpoint
+	Scope     string
+	Decision  string
+	ErrorCode string // OAuth2 error code of a denied request
+}
+
+// protocolLogEntry caches whether a tenant logs decisions
+type protocolLogEntry struct {
+	settings  *ProtocolLogSettings
+	fetchedAt time.Time
+}
+
+// protocolLog holds the protocol log settings and their cache
+type protocolLog struct {
+	repo  ProtocolLogRepository
+	cache sync.Map // tenant ID -> protocolLogEntry
+}
+
+// EnableProtocolLogging lets tenants turn on logging of their authorization and token decisions,
+// with settings kept in repo. Without it, no decisions are logged.
+func (s *Service) EnableProtocolLogging(repo ProtocolLogRepository) {
+	s.protocolLog = &protocolLog{repo: repo}
+}
+
+// GetProtocolLogSettings returns a tenant's protocol log settings; logging is off for a tenant
+// that never set them
+func (s *Service) GetProtocolLogSettings(ctx context.Context, tenantID string) (*ProtocolLogSettings, error) {
+	if s.protocolLog == nil {
+		return &ProtocolLogSettings{TenantID: tenantID}, nil
+	}
+	settings, err := s.protocolLog.repo.Get(ctx, tenantID)
+	if errors.Is(err, ErrProtocolLogNotFound) {
+		return &ProtocolLogSettings{TenantID: tenantID}, nil
+	}
+	return settings, err
+}
+
+// SetProtocolLogSettings turns a tenant's protocol logging on or off. Given expiresAt, logging
+// stops by itself at that time, which must fall within the next MaxProtocolLogDuration.
+func (s *Service) SetProtocolLogSettings(ctx context.Context, tenantID, actorID string, enabled bool, expiresAt *time.Time) (*ProtocolLogSettings, error) {
+	if s.protocolLog == nil {
+		return nil, fmt.Errorf("%w: protocol logging is not available", ErrInvalidProtocolLog)
+	}
+	now := time.Now()
+	if expiresAt != nil && (!expiresAt.After(now) || expiresAt.Sub(now) > MaxProtocolLogDuration) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future and within %s", ErrInvalidProtocolLog, MaxProtocolLogDuration)
+	}
+
+	settings := &ProtocolLogSettings{
+		TenantID:  tenantID,
+		Enabled:   enabled,
+		ExpiresAt: expiresAt,
+		UpdatedBy: actorID,
+		UpdatedAt: now,
+	}
+	if err := s.protocolLog.repo.Upsert(ctx, settings); err != nil {
+		return nil, err
+	}
+	s.protocolLog.cache.Delete(tenantID)
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeProtocolLogUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceProtocolLog,
+		Payload:  &audit.ProtocolLogPayload{Enabled: enabled, ExpiresAt: expiresAt},
+	})
+	return settings, nil
+}
+
+// LogProtocolDecision logs an authorization or token decision when the client's tenant has
+// protocol logging on. Requests of unknown clients are not logged, as they belong to no tenant.
+func (s *Service) LogProtocolDecision(ctx context.Context, d ProtocolDecision) {
+	if s.protocolLog == nil || d.ClientID == "" {
+		return
+	}
+	if d.TenantID == "" {
+		client, err := s.clientRepo.GetByClientID(ctx, d.ClientID)
+		if err != nil {
+			return
+		}
+		d.TenantID = client.TenantID
+	}
+	if !s.protocolLogActive(ctx, d.TenantID) {
+		return
+	}
+
+	slog.InfoContext(ctx, "oauth2 protocol decision",
+		logger.RequestID(d.RequestID),
+		logger.String("endpoint", d.Endpoint),
+		logger.TenantID(d.TenantID),
+		logger.ClientID(d.ClientID),
+		logger.GrantType(d.GrantType),
+		logger.Scope(d.Scope),
+		logger.String("decision", d.Decision),
+		logger.String("error_code", d.ErrorCode),
+	)
+}
+
+// protocolLogActive reports whether a tenant logs decisions, reading its settings at most once per
+// protocolLogCacheTTL. Settings that cannot be read leave logging off.
+func (s *Service) protocolLogActive(ctx context.Context, tenantID string) bool {
+	now := time.Now()
+	if v, ok := s.protocolLog.cache.Load(tenantID); ok {
+		if entry := v.(protocolLogEntry); now.Sub(entry.fetchedAt) < protocolLogCacheTTL {
+			return entry.settings.active(now)
+		}
+	}
+
+	settings, err := s.GetProtocolLogSettings(ctx, tenantID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read protocol log settings", logger.TenantID(tenantID), logger.Error(err))
+		return false
+	}
+	s.protocolLog.cache.Store(tenantID, protocolLogEntry{settings: settings, fetchedAt: now})
+	return settings.active(now)
+}
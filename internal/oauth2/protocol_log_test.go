@@ -0,0 +1,130 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+type mockProtocolLogRepo map[string]*ProtocolLogSettings
+
+func (m mockProtocolLogRepo) Get(ctx context.Context, tenantID string) (*ProtocolLogSettings, error) {
+	s, ok := m[tenantID]
+	if !ok {
+		return nil, ErrProtocolLogNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (m mockProtocolLogRepo) Upsert(ctx context.Context, s *ProtocolLogSettings) error {
+	cp := *s
+	m[s.TenantID] = &cp
+	return nil
+}
+
+// TestPurpose: Validates that authorization and token decisions are logged only for tenants that turned protocol logging on.
+// Scope: Unit Test
+// Security: Privacy (decision logs carry no codes, tokens or secrets and stay off unless a tenant enables them)
+// Expected: A decision of an enabled tenant is logged with its request ID, client, scope, decision and error code; decisions of other tenants, unknown clients and expired settings are not logged; a setting ending in the past or beyond MaxProtocolLogDuration is invalid.
+// Test Case ID: OA2-21
+func TestOAuth2_ProtocolLogging(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	repo := mockProtocolLogRepo{}
+	s := &Service{
+		clientRepo: &MockClientRepo{clients: map[string]*Client{
+			"app":   {ClientID: "app", TenantID: "t1", IsActive: true},
+			"other": {ClientID: "other", TenantID: "t2", IsActive: true},
+		}},
+		auditLogger: audit.NewSlogLogger(),
+	}
+	ctx := context.Background()
+
+	// Without protocol logging enabled, settings read as off and nothing is logged
+	if settings, err := s.GetProtocolLogSettings(ctx, "t1"); err != nil || settings.Enabled {
+		t.Fatalf("expected logging off by default, got %+v, %v", settings, err)
+	}
+	if _, err := s.SetProtocolLogSettings(ctx, "t1", "admin", true, nil); !errors.Is(err, ErrInvalidProtocolLog) {
+		t.Errorf("expected settings to be refused without a repository, got %v", err)
+	}
+
+	s.EnableProtocolLogging(repo)
+	if _, err := s.SetProtocolLogSettings(ctx, "t1", "admin", true, nil); err != nil {
+		t.Fatalf("SetProtocolLogSettings: %v", err)
+	}
+	logs.Reset()
+
+	s.LogProtocolDecision(ctx, ProtocolDecision{
+		RequestID: "req-1", Endpoint: "token", ClientID: "app", GrantType: "authorization_code",
+		Scope: "openid profile", Decision: DecisionDenied, ErrorCode: ErrInvalidGrant,
+	})
+	s.LogProtocolDecision(ctx, ProtocolDecision{Endpoint: "token", ClientID: "other", Decision: DecisionGranted})
+	s.LogProtocolDecision(ctx, ProtocolDecision{Endpoint: "authorize", ClientID: "unknown", Decision: DecisionDenied})
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one decision logged, got %q", logs.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"request_id": "req-1", "endpoint": "token", "tenant_id": "t1", "client_id": "app",
+		"grant_type": "authorization_code", "scope": "openid profile", "decision": DecisionDenied, "error_code": ErrInvalidGrant,
+	} {
+		if entry[key] != want {
+			t.Errorf("expected %s=%q, got %v", key, want, entry[key])
+		}
+	}
+
+	// Turning logging off reaches the next decision despite the cache
+	if _, err := s.SetProtocolLogSettings(ctx, "t1", "admin", false, nil); err != nil {
+		t.Fatalf("SetProtocolLogSettings: %v", err)
+	}
+	logs.Reset()
+	s.LogProtocolDecision(ctx, ProtocolDecision{Endpoint: "token", ClientID: "app", Decision: DecisionGranted})
+	if logs.Len() != 0 {
+		t.Errorf("expected no decision logged once disabled, got %q", logs.String())
+	}
+
+	past := time.Now().Add(-time.Minute)
+	repo["t1"] = &ProtocolLogSettings{TenantID: "t1", Enabled: true, ExpiresAt: &past}
+	s.protocolLog.cache.Clear()
+	s.LogProtocolDecision(ctx, ProtocolDecision{Endpoint: "token", ClientID: "app", Decision: DecisionGranted})
+	if logs.Len() != 0 {
+		t.Errorf("expected no decision logged after the settings expired, got %q", logs.String())
+	}
+
+	tooLate := time.Now().Add(MaxProtocolLogDuration + time.Hour)
+	for _, expiresAt := range []*time.Time{&past, &tooLate} {
+		if _, err := s.SetProtocolLogSettings(ctx, "t1", "admin", true, expiresAt); !errors.Is(err, ErrInvalidProtocolLog) {
+			t.Errorf("expected expires_at %v to be invalid, got %v", expiresAt, err)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RequestObjectSigningAlgs are the algorithms accepted on request objects; unsigned ("none")
+// request objects are never accepted
+var RequestObjectSigningAlgs = []string{"RS256", "PS256", "ES256"}
+
+const (
+	// maxRequestObjectLifetime bounds exp of a request object, as FAPI 1.0 Advanced Section 5.2.2 does
+	maxRequestObjectLifetime = time.Hour
+	// maxRequestObjectSize bounds a request object fetched from a request_uri
+	maxRequestObjectSize = 64 << 10
+	// requestObjectFetchTimeout bounds fetching a request object from a request_uri
+	requestObjectFetchTimeout = 5 * time.Second
+)
+
+// requestObjectClaims are the JWT claims of a request object that are not authorization parameters
+var requestObjectClaims = []string{"iss", "aud", "exp", "nbf", "iat", "jti", "request", "request_uri"}
+
+// EnableRequestObjects accepts JWT-secured authorization requests (RFC 9101) addressed to issuer.
+// Request objects passed by reference and keys published at a jwks_uri are fetched with client, or,
+// when nil, a default client that refuses private and loopback addresses. Without it, requests
+// carrying request or request_uri are refused as not supported.
+func (s *Service) EnableRequestObjects(issuer string, client *http.Client) {
+	if client == nil {
+		client = publicHTTPClient(requestObjectFetchTimeout)
+	}
+	s.issuer = issuer
+	s.requestObjectClient = client
+}
+
+// RequestObjectsEnabled reports whether request objects are accepted
+func (s *Service) RequestObjectsEnabled() bool {
+	return s.issuer != ""
+}
+
+// ResolveRequestObject returns the parameters of an authorization request. When it carries a
+// request object, by value in request or by reference in request_uri, only the parameters in the
+// signed object count (RFC 9101 Section 6.3); client_id must name the client that signed it. A
+// request without one is returned unchanged, unless its client requires signed request objects.
+func (s *Service) ResolveRequestObject(ctx context.Context, params url.Values) (url.Values, error) {
+	object, uri := params.Get("request"), params.Get("request_uri")
+	if object == "" && uri == "" {
+		if client, err := s.clientRepo.GetByClientID(ctx, params.Get("client_id")); err == nil && client.RequireSignedRequestObject {
+			return nil, NewError(ErrInvalidRequest, "client requires a signed request object")
+		}
+		return params, nil
+	}
+	if object != "" && uri != "" {
+		return nil, NewError(ErrInvalidRequest, "request and request_uri must not both be present")
+	}
+	if s.issuer == "" {
+		if uri != "" {
+			return nil, NewError(ErrRequestURINotSupported, "request_uri is not supported")
+		}
+		return nil, NewError(ErrRequestNotSupported, "request is not supported")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, params.Get("client_id"))
+	if err != nil {
+		return nil, NewError(ErrInvalidRequest, "invalid client_id")
+	}
+	if uri != "" {
+		// Only registered URIs are fetched, so requests cannot point the server at arbitrary hosts
+		if !slices.Contains(client.RequestURIs, uri) {
+			return nil, NewError(ErrInvalidRequestURI, "request_uri is not registered for the client")
+		}
+		if object, err = s.fetchRequestObject(ctx, uri); err != nil {
+			slog.WarnContext(ctx, "failed to fetch request object", "client_id", client.ClientID, "request_uri", uri, "error", err)
+			return nil, NewError(ErrInvalidRequestURI, "request object could not be retrieved from request_uri")
+		}
+	}
+
+	claims, err := s.verifyRequestObject(ctx, client, object)
+	if err != nil {
+		return nil, NewError(ErrInvalidRequestObject, err.Error())
+	}
+	if id, ok := claims["client_id"]; ok && id != client.ClientID {
+		return nil, NewError(ErrInvalidRequestObject, "client_id of the request object does not match")
+	}
+
+	resolved := url.Values{"client_id": {client.ClientID}}
+	for name, value := range claims {
+		if slices.Contains(requestObjectClaims, name) || name == "client_id" {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			resolved.Set(name, v)
+		case float64:
+			resolved.Set(name, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			// Structured parameters such as claims keep their JSON form
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, NewError(ErrInvalidRequestObject, fmt.Sprintf("invalid %s", name))
+			}
+			resolved.Set(name, string(b))
+		}
+	}
+	return resolved, nil
+}
+
+// verifyRequestObject checks the signature of object against the client's keys, and that the
+// client issued it to this server for at most maxRequestObjectLifetime
+func (s *Service) verifyRequestObject(ctx context.Context, client *Client, object string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(object, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		keys, err := s.clientKeys(ctx, client, kid)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return nil, errors.New("client has no registered keys")
+		}
+		return selectKey(keys, kid, t.Method.Alg())
+	},
+		jwt.WithValidMethods(RequestObjectSigningAlgs),
+		jwt.WithIssuer(client.ClientID),
+		jwt.WithAudience(s.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request object: %w", err)
+	}
+	exp, _ := claims.GetExpirationTime()
+	if time.Until(exp.Time) > maxRequestObjectLifetime {
+		return nil, fmt.Errorf("request object expires more than %s ahead", maxRequestObjectLifetime)
+	}
+	return claims, nil
+}
+
+// fetchRequestObject retrieves a request object from a registered request_uri
+func (s *Service) fetchRequestObject(ctx context.Context, uri string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/oauth-authz-req+jwt")
+	resp, err := s.requestObjectClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRequestObjectSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > maxRequestObjectSize {
+		return "", fmt.Errorf("request object exceeds %d bytes", maxRequestObjectSize)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// validateRequestObjectMetadata checks the request object metadata of a client: its JWKS holds
+// only usable public keys, or is published at an https jwks_uri, its request URIs are absolute
+// https URLs, and a client that requires request objects has keys to sign them with
+func validateRequestObjectMetadata(client *Client) error {
+	keys, err := parseJWKS(client.JWKS)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDomainInvalidMetadata, err)
+	}
+	if client.JWKSURI != "" {
+		// RFC 7591 Section 2: jwks and jwks_uri must not both be present
+		if len(keys) > 0 {
+			return fmt.Errorf("%w: jwks and jwks_uri must not both be set", ErrDomainInvalidMetadata)
+		}
+		if !httpsURL(client.JWKSURI) {
+			return fmt.Errorf("%w: jwks_uri must be an absolute https URL", ErrDomainInvalidMetadata)
+		}
+	}
+	for _, uri := range client.RequestURIs {
+		if !httpsURL(uri) {
+			return fmt.Errorf("%w: request_uri %q must be an absolute https URL", ErrDomainInvalidMetadata, uri)
+		}
+	}
+	if client.RequireSignedRequestObject && len(keys) == 0 && client.JWKSURI == "" {
+		return fmt.Errorf("%w: require_signed_request_object needs a jwks or jwks_uri", ErrDomainInvalidMetadata)
+	}
+	return nil
+}
+
+// httpsURL reports whether uri is an absolute https URL
+func httpsURL(uri string) bool {
+	u, err := url.Parse(uri)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+// jwk is a public JSON Web Key (RFC 7517) of type RSA or EC
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+}
+
+// publicKey is a key of a client's JWKS
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// parseJWKS parses a registered JWKS; an empty one has no keys. Private key material is refused,
+// as a registered JWKS is returned to administrators and must never hold a secret. A set holds
+// at most maxClientKeys keys with distinct IDs.
+func parseJWKS(raw json.RawMessage) ([]publicKey, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+	if len(set.Keys) > maxClientKeys {
+		return nil, fmt.Errorf("jwks holds more than %d keys", maxClientKeys)
+	}
+	keys := make([]publicKey, 0, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.D != "" || k.P != "" || k.Q != "" {
+			return nil, fmt.Errorf("jwks key %d holds private key material", i)
+		}
+		if k.Kid != "" && slices.ContainsFunc(keys, func(p publicKey) bool { return p.kid == k.Kid }) {
+			return nil, fmt.Errorf("jwks key %d repeats kid %q", i, k.Kid)
+		}
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %d: %w", i, err)
+		}
+		keys = append(keys, publicKey{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	return keys, nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil || len(n) < 256 || len(n) > 1024 {
+			return nil, errors.New("RSA modulus must be 2048 to 8192 bits")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		exponent := new(big.Int).SetBytes(e).Int64()
+		if exponent < 3 || exponent%2 == 0 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent)}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid EC coordinates")
+		}
+		// Round-trip through the uncompressed encoding, which rejects points off the curve
+		point := append([]byte{4}, append(x, y...)...)
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// selectKey picks the key that verifies a request object: the one named by kid, or else the only
+// key fit for alg
+func selectKey(keys []publicKey, kid, alg string) (crypto.PublicKey, error) {
+	var match []publicKey
+	for _, k := range keys {
+		if (kid == "" || k.kid == kid) && (k.alg == "" || k.alg == alg) && keyFits(k.key, alg) {
+			match = append(match, k)
+		}
+	}
+	switch len(match) {
+	case 0:
+		return nil, errors.New("no registered key verifies the request object")
+	case 1:
+		return match[0].key, nil
+	}
+	return nil, errors.New("request object must name its key with kid")
+}
+
+// keyFits reports whether key can verify signatures made with alg
+func keyFits(key crypto.PublicKey, alg string) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return alg == "ES256"
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testIssuer = "https://id.example.com"
+
+func encodeJWKS(t *testing.T, keys ...map[string]string) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	point, _ := key.Bytes()
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(point[1:33]),
+		"y":   base64.RawURLEncoding.EncodeToString(point[33:]),
+	}
+}
+
+func signRequestObject(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// TestPurpose: Validates JWT-secured authorization requests passed by value and by reference.
+// Scope: Unit Test
+// Security: Request integrity (RFC 9101; parameters come only from an object signed by the client)
+// Expected: A request object signed with a registered RSA or EC key replaces the query parameters; request_uri is fetched only when registered; unsigned, foreign, expired and mis-addressed objects are invalid_request_object.
+// Test Case ID: OA2-12
+func TestOAuth2_ResolveRequestObject(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	client := &Client{
+		ClientID:    "fapi-client",
+		JWKS:        encodeJWKS(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)),
+		RequestURIs: []string{"https://app.example.com/request.jwt"},
+	}
+	svc := &Service{clientRepo: &MockClientRepo{clients: map[string]*Client{client.ClientID: client}}}
+	ctx := context.Background()
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":           client.ClientID,
+			"aud":           testIssuer,
+			"exp":           time.Now().Add(5 * time.Minute).Unix(),
+			"client_id":     client.ClientID,
+			"response_type": "code",
+			"redirect_uri":  "https://app.example.com/cb",
+			"scope":         "openid",
+			"state":         "s1",
+			"max_age":       300,
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+	params := func(extra ...string) url.Values {
+		v := url.Values{"client_id": {client.ClientID}, "scope": {"openid admin"}}
+		for i := 0; i < len(extra); i += 2 {
+			v.Set(extra[i], extra[i+1])
+		}
+		return v
+	}
+
+	if _, err := svc.ResolveRequestObject(ctx, params("request", "x")); AsError(err).Code != ErrRequestNotSupported {
+		t.Fatalf("expected request_not_supported before request objects are enabled, got %v", err)
+	}
+
+	var served string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(served))
+	}))
+	defer srv.Close()
+	svc.EnableRequestObjects(testIssuer, srv.Client())
+
+	object := signRequestObject(t, jwt.SigningMethodPS256, "rsa-1", rsaKey, claims(nil))
+	got, err := svc.ResolveRequestObject(ctx, params("request", object))
+	if err != nil {
+		t.Fatalf("expected a signed request object to resolve, got %v", err)
+	}
+	if got.Get("scope") != "openid" || got.Get("state") != "s1" || got.Get("max_age") != "300" || got.Has("iss") || got.Has("request") {
+		t.Errorf("expected only the request object's parameters, got %v", got)
+	}
+
+	served = signRequestObject(t, jwt.SigningMethodES256, "ec-1", ecKey, claims(nil))
+	client.RequestURIs = append(client.RequestURIs, srv.URL+"/request.jwt")
+	if got, err := svc.ResolveRequestObject(ctx, params("request_uri", srv.URL+"/request.jwt")); err != nil || got.Get("redirect_uri") != "https://app.example.com/cb" {
+		t.Errorf("expected an EC-signed request object to resolve by reference, got %v, %v", got, err)
+	}
+	if _, err := svc.ResolveRequestObject(ctx, params("request_uri", srv.URL+"/other.jwt")); AsError(err).Code != ErrInvalidRequestURI {
+		t.Errorf("expected an unregistered request_uri to be refused, got %v", err)
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, claims(nil))
+	none, _ := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	invalid := map[string]string{
+		"unsigned":      none,
+		"foreign key":   signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", otherKey, claims(nil)),
+		"expired":       signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"no exp":        signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": nil})),
+		"long-lived":    signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": time.Now().Add(2 * time.Hour).Unix()})),
+		"wrong aud":     signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"aud": "https://other.example.com"})),
+		"wrong iss":     signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"iss": "other-client"})),
+		"wrong client":  signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"client_id": "other-client"})),
+		"key type":      signRequestObject(t, jwt.SigningMethodRS256, "ec-1", rsaKey, claims(nil)),
+		"malformed jwt": "not.a.jwt",
+	}
+	for name, object := range invalid {
+		if _, err := svc.ResolveRequestObject(ctx, params("request", object)); AsError(err).Code != ErrInvalidRequestObject {
+			t.Errorf("%s: expected invalid_request_object, got %v", name, err)
+		}
+	}
+	if _, err := svc.ResolveRequestObject(ctx, params("request", object, "request_uri", srv.URL+"/request.jwt")); AsError(err).Code != ErrInvalidRequest {
+		t.Errorf("expected request and request_uri together to be refused, got %v", err)
+	}
+
+	if got, err := svc.ResolveRequestObject(ctx, params()); err != nil || got.Get("scope") != "openid admin" {
+		t.Errorf("expected a plain request to pass unchanged, got %v, %v", got, err)
+	}
+	client.RequireSignedRequestObject = true
+	if _, err := svc.ResolveRequestObject(ctx, params()); AsError(err).Code != ErrInvalidRequest {
+		t.Errorf("expected a plain request to be refused once the client requires request objects, got %v", err)
+	}
+}
+
+// TestPurpose: Validates that a request_uri is fetched only from public addresses.
+// Scope: Unit Test
+// Security: SSRF (a client must not make the server fetch from its internal network, even through a registered request_uri)
+// Expected: With the default client, a registered request_uri on a loopback address is invalid_request_uri and is never contacted.
+// Test Case ID: OA2-26
+// RelatedSpecs: RFC 9101 Section 10.4
+func TestOAuth2_ResolveRequestObject_RefusesPrivateRequestURI(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	object := signRequestObject(t, jwt.SigningMethodRS256, "rsa-1", key, jwt.MapClaims{
+		"iss": "fapi-client", "aud": testIssuer, "exp": time.Now().Add(time.Minute).Unix(),
+		"response_type": "code", "redirect_uri": "https://app.example.com/cb",
+	})
+	var fetches atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write([]byte(object))
+	}))
+	defer srv.Close()
+
+	client := &Client{
+		ClientID:    "fapi-client",
+		JWKS:        encodeJWKS(t, rsaJWK("rsa-1", &key.PublicKey)),
+		RequestURIs: []string{srv.URL + "/request.jwt"},
+	}
+	svc := &Service{clientRepo: &MockClientRepo{clients: map[string]*Client{client.ClientID: client}}}
+	svc.EnableRequestObjects(testIssuer, nil)
+
+	params := url.Values{"client_id": {client.ClientID}, "request_uri": {srv.URL + "/request.jwt"}}
+	if _, err := svc.ResolveRequestObject(context.Background(), params); AsError(err).Code != ErrInvalidRequestURI {
+		t.Errorf("expected a loopback request_uri to be refused, got %v", err)
+	}
+	if n := fetches.Load(); n != 0 {
+		t.Errorf("expected the loopback request_uri not to be contacted, got %d fetches", n)
+	}
+}
+
+// TestPurpose: Validates the request object metadata accepted on client registration.
+// Scope: Unit Test
+// Security: Key hygiene (no private keys stored with the client, no fetching over plain http)
+// Expected: Public RSA and EC keys and https request URIs are accepted; private keys, weak or off-curve keys, http URIs and a requirement without keys are ErrDomainInvalidMetadata.
+// Test Case ID: OA2-13
+func TestOAuth2_RequestObjectMetadata(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	weakKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	private := rsaJWK("rsa-1", &rsaKey.PublicKey)
+	private["d"] = base64.RawURLEncoding.EncodeToString(rsaKey.D.Bytes())
+	offCurve := ecJWK("ec-1", &ecKey.PublicKey)
+	offCurve["y"] = offCurve["x"]
+
+	valid := &Client{
+		JWKS:                       encodeJWKS(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)),
+		RequestURIs:                []string{"https://app.example.com/request.jwt"},
+		RequireSignedRequestObject: true,
+	}
+	if err := validateRequestObjectMetadata(valid); err != nil {
+		t.Fatalf("expected valid metadata, got %v", err)
+	}
+	if err := validateRequestObjectMetadata(&Client{}); err != nil {
+		t.Fatalf("expected a client without request objects to be valid, got %v", err)
+	}
+
+	invalid := map[string]*Client{
+		"private key":     {JWKS: encodeJWKS(t, private)},
+		"weak key":        {JWKS: encodeJWKS(t, rsaJWK("rsa-1", &weakKey.PublicKey))},
+		"off-curve point": {JWKS: encodeJWKS(t, offCurve)},
+		"malformed jwks":  {JWKS: json.RawMessage(`{"keys":`)},
+		"http uri":        {RequestURIs: []string{"http://app.example.com/request.jwt"}},
+		"no keys":         {RequireSignedRequestObject: true},
+	}
+	for name, client := range invalid {
+		if err := validateRequestObjectMetadata(client); !errors.Is(err, ErrDomainInvalidMetadata) {
+			t.Errorf("%s: expected ErrDomainInvalidMetadata, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// recordingAuditLogger keeps the logged events
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (l *recordingAuditLogger) Log(ctx context.Context, event audit.Event) {
+	l.events = append(l.events, event)
+}
+
+// TestPurpose: Validates that revoking the tokens of a tenant or a client revokes all of them in batches, reports progress and is audited.
+// Scope: Unit Test
+// Security: Incident response (a force-logout must leave no live token of the tenant or client)
+// Expected: Every unexpired token of the tenant is revoked across several batches with a progress report per batch, other tenants' tokens are kept, and one tokens_revoked event reports the counts; nothing is audited when there was nothing to revoke.
+// Test Case ID: OA2-24
+func TestService_RevokeTenantAndClientTokens(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	access := memory.NewAccessTokenRepository(db)
+	refresh := memory.NewRefreshTokenRepository(db)
+	auditLogger := &recordingAuditLogger{}
+	svc := oauth2.NewService(nil, nil, access, refresh, auditLogger, nil, time.Minute, time.Hour, time.Hour)
+
+	create := func(i int, tenantID, clientID string) {
+		t.Helper()
+		if err := access.Create(ctx, &oauth2.AccessToken{
+			ID: fmt.Sprintf("at-%s-%d", tenantID, i), TenantID: tenantID, TokenHash: fmt.Sprintf("at-%s-%d", tenantID, i), ClientID: clientID, UserID: "user-1",
+			ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := refresh.Create(ctx, &oauth2.RefreshToken{
+			ID: fmt.Sprintf("rt-%s-%d", tenantID, i), TenantID: tenantID, TokenHash: fmt.Sprintf("rt-%s-%d", tenantID, i), ClientID: clientID, UserID: "user-1",
+			ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const tenantTokens = 1200
+	for i := range tenantTokens {
+		create(i, "tenant-a", "client-a")
+	}
+	create(0, "tenant-b", "client-b")
+	create(1, "tenant-b", "client-c")
+
+	var reports []oauth2.BulkRevocation
+	done, err := svc.RevokeTenantTokens(ctx, "tenant-a", "admin-1", func(p oauth2.BulkRevocation) { reports = append(reports, p) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.RefreshTokens != tenantTokens || done.AccessTokens != tenantTokens {
+		t.Errorf("expected %d tokens of each kind revoked, got %+v", tenantTokens, done)
+	}
+	if len(reports) != 6 || reports[len(reports)-1] != done {
+		t.Errorf("expected a progress report per batch ending with the totals, got %+v", reports)
+	}
+	if at, err := access.GetByTokenHash(ctx, "tenant-b", "at-tenant-b-0"); err != nil || at.IsRevoked {
+		t.Errorf("other tenant's token was revoked: %+v, %v", at, err)
+	}
+	if len(auditLogger.events) != 1 {
+		t.Fatalf("expected one audit event, got %d", len(auditLogger.events))
+	}
+	event := auditLogger.events[0]
+	payload, ok := event.Payload.(*audit.TokensRevokedPayload)
+	if event.Type != audit.TypeTokensRevoked || event.TenantID != "tenant-a" || event.ActorID != "admin-1" || !ok || payload.Reason != "tenant" || payload.RefreshTokens != tenantTokens || payload.AccessTokens != tenantTokens {
+		t.Errorf("unexpected audit event: %+v", event)
+	}
+
+	done, err = svc.RevokeClientTokens(ctx, "tenant-b", "client-b", "admin-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.RefreshTokens != 1 || done.AccessTokens != 1 {
+		t.Errorf("expected one token of each kind revoked, got %+v", done)
+	}
+	if rt, err := refresh.GetByTokenHash(ctx, "tenant-b", "rt-tenant-b-1"); err != nil || rt.IsRevoked {
+		t.Errorf("other client's token was revoked: %+v, %v", rt, err)
+	}
+	if payload, ok := auditLogger.events[len(auditLogger.events)-1].Payload.(*audit.TokensRevokedPayload); !ok || payload.Reason != "client" || payload.ClientID != "client-b" {
+		t.Errorf("unexpected audit event: %+v", auditLogger.events[len(auditLogger.events)-1])
+	}
+
+	if _, err := svc.RevokeClientTokens(ctx, "tenant-b", "client-b", "admin-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(auditLogger.events) != 2 {
+		t.Errorf("expected no audit event for an empty revocation, got %d events", len(auditLogger.events))
+	}
+}
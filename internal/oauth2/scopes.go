@@ -0,0 +1,397 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// Scope definition errors
+var (
+	ErrScopeNotFound          = apperr.New(apperr.CodeNotFound, "scope not found")
+	ErrScopeAlreadyExists     = apperr.New(apperr.CodeAlreadyExists, "scope already exists")
+	ErrInvalidScopeDefinition = apperr.New(apperr.CodeInvalidArgument, "invalid scope definition")
+)
+
+// StandardScopes are the scopes defined by OAuth 2.0 and OpenID Connect, plus roles. They need no
+// definition and a tenant cannot redefine them.
+var StandardScopes = []string{ScopeOpenID, "profile", "email", "address", "phone", "offline_access", ScopeRoles}
+
+// ReleasableClaims are the user claims a custom scope may release in the ID token (OIDC Core Section 5.1)
+var ReleasableClaims = []string{
+	"email", "email_verified", "name", "given_name", "family_name", "nickname", "picture", "website",
+	"birthdate", "zoneinfo", "locale",
+}
+
+// ProfileClaims are the user claims the standard profile scope releases in the ID token (OIDC Core Section 5.4)
+var ProfileClaims = []string{
+	"name", "given_name", "family_name", "nickname", "picture", "website", "birthdate", "zoneinfo", "locale",
+}
+
+// Limits of a scope definition
+const (
+	MaxScopes           = 100 // Custom scopes of one tenant
+	maxScopeNameLength  = 128
+	maxScopeDescLength  = 512
+	maxScopePermissions = 50
+	maxPermissionLength = 128
+)
+
+// ScopeDefinition is a custom OAuth2 scope of a tenant. Clients of the tenant may be allowed to
+// request it; granting it releases the listed user claims in the ID token and reports the listed
+// permissions when the access token is introspected.
+type ScopeDefinition struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"` // Shown to the user when consent is asked
+	Claims      []string  `json:"claims"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ScopeRepository defines the interface for custom scope persistence. Scopes are identified by
+// their name, which is unique within a tenant.
+type ScopeRepository interface {
+	// Create stores a new scope, returning ErrScopeAlreadyExists if the tenant has one of that name
+	Create(ctx context.Context, scope *ScopeDefinition) error
+
+	// Get retrieves a tenant's scope by name
+	Get(ctx context.Context, tenantID, name string) (*ScopeDefinition, error)
+
+	// List retrieves all of a tenant's scopes ordered by name
+	List(ctx context.Context, tenantID string) ([]*ScopeDefinition, error)
+
+	// Update replaces a scope's description, claims and permissions
+	Update(ctx context.Context, scope *ScopeDefinition) error
+
+	// Delete removes a tenant's scope
+	Delete(ctx context.Context, tenantID, name string) error
+}
+
+// ClaimsSource provides the claims of a user that custom scopes may release
+type ClaimsSource interface {
+	UserClaims(ctx context.Context, userID string) (map[string]any, error)
+}
+
+// ScopeInput is the editable part of a scope definition
+type ScopeInput struct {
+	Name        string // Ignored on update
+	Description string
+	Claims      []string
+	Permissions []string
+}
+
+// SetScopes enables tenant-defined scopes. Without it only the client's allowed scopes are checked,
+// and no claims or permissions are released.
+func (s *Service) SetScopes(repo ScopeRepository, claims ClaimsSource) {
+	s.scopeRepo = repo
+	s.claimsSource = claims
+}
+
+// IsStandardScope reports whether scope is a standard scope
+func IsStandardScope(scope string) bool {
+	return slices.Contains(StandardScopes, scope)
+}
+
+// ListScopes retrieves a tenant's custom scopes
+func (s *Service) ListScopes(ctx context.Context, tenantID string) ([]*ScopeDefinition, error) {
+	if s.scopeRepo == nil {
+		return nil, nil
+	}
+	return s.scopeRepo.List(tenant.WithScope(ctx, tenantID), tenantID)
+}
+
+// GetScope retrieves one of a tenant's custom scopes
+func (s *Service) GetScope(ctx context.Context, tenantID, name string) (*ScopeDefinition, error) {
+	if s.scopeRepo == nil {
+		return nil, ErrScopeNotFound
+	}
+	return s.scopeRepo.Get(tenant.WithScope(ctx, tenantID), tenantID, name)
+}
+
+// CreateScope defines a custom scope for a tenant
+func (s *Service) CreateScope(ctx context.Context, tenantID, actorID string, in ScopeInput) (*ScopeDefinition, error) {
+	if s.scopeRepo == nil {
+		return nil, fmt.Errorf("%w: custom scopes are not enabled", ErrInvalidScopeDefinition)
+	}
+	if err := validateScopeName(in.Name); err != nil {
+		return nil, err
+	}
+	if err := validateScopeInput(&in); err != nil {
+		return nil, err
+	}
+	ctx = tenant.WithScope(ctx, tenantID)
+	existing, err := s.scopeRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxScopes {
+		return nil, fmt.Errorf("%w: a tenant may have at most %d scopes", ErrInvalidScopeDefinition, MaxScopes)
+	}
+
+	now := time.Now()
+	scope := &ScopeDefinition{
+		ID:          id.NewUUIDv7(),
+		TenantID:    tenantID,
+		Name:        in.Name,
+		Description: in.Description,
+		Claims:      in.Claims,
+		Permissions: in.Permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.scopeRepo.Create(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	s.logScope(ctx, audit.TypeScopeCreated, actorID, scope)
+	return scope, nil
+}
+
+// UpdateScope replaces the description, claims and permissions of a tenant's custom scope. Tokens
+// already issued with the scope report the new permissions when introspected.
+func (s *Service) UpdateScope(ctx context.Context, tenantID, name, actorID string, in ScopeInput) (*ScopeDefinition, error) {
+	if s.scopeRepo == nil {
+		return nil, ErrScopeNotFound
+	}
+	if err := validateScopeInput(&in); err != nil {
+		return nil, err
+	}
+	ctx = tenant.WithScope(ctx, tenantID)
+	scope, err := s.scopeRepo.Get(ctx, tenantID, name)
+	if err != nil {
+		return nil, err
+	}
+	scope.Description = in.Description
+	scope.Claims = in.Claims
+	scope.Permissions = in.Permissions
+	scope.UpdatedAt = time.Now()
+	if err := s.scopeRepo.Update(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	s.logScope(ctx, audit.TypeScopeUpdated, actorID, scope)
+	return scope, nil
+}
+
+// DeleteScope removes a tenant's custom scope. Clients that still list it in their allowed scopes
+// can no longer request it.
+func (s *Service) DeleteScope(ctx context.Context, tenantID, name, actorID string) error {
+	if s.scopeRepo == nil {
+		return ErrScopeNotFound
+	}
+	ctx = tenant.WithScope(ctx, tenantID)
+	scope, err := s.scopeRepo.Get(ctx, tenantID, name)
+	if err != nil {
+		return err
+	}
+	if err := s.scopeRepo.Delete(ctx, tenantID, name); err != nil {
+		return err
+	}
+
+	s.logScope(ctx, audit.TypeScopeDeleted, actorID, scope)
+	return nil
+}
+
+func (s *Service) logScope(ctx context.Context, eventType, actorID string, scope *ScopeDefinition) {
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     eventType,
+		TenantID: scope.TenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceScope,
+		Payload: &audit.ScopePayload{
+			ScopeID:     scope.ID,
+			Name:        scope.Name,
+			Claims:      scope.Claims,
+			Permissions: scope.Permissions,
+		},
+	})
+}
+
+// validateScopeName checks that name is a scope token (RFC 6749 Section 3.3) that does not shadow a
+// standard scope
+func validateScopeName(name string) error {
+	if name == "" || len(name) > maxScopeNameLength {
+		return fmt.Errorf("%w: name is required and must be at most %d characters", ErrInvalidScopeDefinition, maxScopeNameLength)
+	}
+	for _, c := range name {
+		// scope-token = 1*( %x21 / %x23-5B / %x5D-7E )
+		if c < 0x21 || c > 0x7e || c == '"' || c == '\\' {
+			return fmt.Errorf("%w: name may only contain printable ASCII characters other than space, '\"' and '\\'", ErrInvalidScopeDefinition)
+		}
+	}
+	if name == "*" || IsStandardScope(name) {
+		return fmt.Errorf("%w: %q is a reserved scope", ErrInvalidScopeDefinition, name)
+	}
+	return nil
+}
+
+// validateScopeInput checks the description, claims and permissions of in, dropping duplicates
+func validateScopeInput(in *ScopeInput) error {
+	in.Description = strings.TrimSpace(in.Description)
+	if in.Description == "" || len(in.Description) > maxScopeDescLength {
+		return fmt.Errorf("%w: description is required and must be at most %d characters", ErrInvalidScopeDefinition, maxScopeDescLength)
+	}
+	for _, claim := range in.Claims {
+		if !slices.Contains(ReleasableClaims, claim) {
+			return fmt.Errorf("%w: claim %q cannot be released; supported claims are %s", ErrInvalidScopeDefinition, claim, strings.Join(ReleasableClaims, ", "))
+		}
+	}
+	if len(in.Permissions) > maxScopePermissions {
+		return fmt.Errorf("%w: a scope may grant at most %d permissions", ErrInvalidScopeDefinition, maxScopePermissions)
+	}
+	for _, p := range in.Permissions {
+		if p == "" || len(p) > maxPermissionLength || strings.ContainsFunc(p, func(r rune) bool { return r <= ' ' }) {
+			return fmt.Errorf("%w: permissions must be non-empty, at most %d characters and contain no whitespace", ErrInvalidScopeDefinition, maxPermissionLength)
+		}
+	}
+	in.Claims = dedupe(in.Claims)
+	in.Permissions = dedupe(in.Permissions)
+	return nil
+}
+
+// dedupe returns values without duplicates, in their first order, and never nil
+func dedupe(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// grantedScopes returns the tenant's definitions of the custom scopes in the space-separated scope,
+// leaving out those that are not defined, and the first undefined one
+func (s *Service) grantedScopes(ctx context.Context, tenantID, scope string) ([]*ScopeDefinition, string, error) {
+	if s.scopeRepo == nil {
+		return nil, "", nil
+	}
+	var (
+		granted   []*ScopeDefinition
+		undefined string
+	)
+	for _, name := range strings.Fields(scope) {
+		if IsStandardScope(name) {
+			continue
+		}
+		def, err := s.scopeRepo.Get(ctx, tenantID, name)
+		if errors.Is(err, ErrScopeNotFound) {
+			if undefined == "" {
+				undefined = name
+			}
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		granted = append(granted, def)
+	}
+	return granted, undefined, nil
+}
+
+// validateRequestedScopes rejects a requested scope that is neither standard nor defined in the tenant
+func (s *Service) validateRequestedScopes(ctx context.Context, tenantID, scope string) error {
+	_, undefined, err := s.grantedScopes(ctx, tenantID, scope)
+	if err != nil {
+		return NewError(ErrServerError, "failed to look up scope")
+	}
+	if undefined != "" {
+		return NewError(ErrInvalidScope, fmt.Sprintf("scope %q is not defined", undefined))
+	}
+	return nil
+}
+
+// checkAllowedScopes rejects allowed scopes of a client that are neither standard nor defined in its tenant
+func (s *Service) checkAllowedScopes(ctx context.Context, client *Client) error {
+	if s.scopeRepo == nil {
+		return nil
+	}
+	var custom []string
+	for _, name := range client.AllowedScopes {
+		if name != "*" {
+			custom = append(custom, name)
+		}
+	}
+	_, undefined, err := s.grantedScopes(tenant.WithScope(ctx, client.TenantID), client.TenantID, strings.Join(custom, " "))
+	if err != nil {
+		return err
+	}
+	if undefined != "" {
+		return fmt.Errorf("%w: scope %q is not defined in the tenant", ErrDomainInvalidScope, undefined)
+	}
+	return nil
+}
+
+// scopeClaims returns the user claims released by the profile scope and the custom scopes granted
+// in scope, or nil if they release none. Scopes deleted since they were granted release nothing.
+func (s *Service) scopeClaims(ctx context.Context, tenantID, userID, scope string) (map[string]any, error) {
+	if s.claimsSource == nil {
+		return nil, nil
+	}
+	granted, _, err := s.grantedScopes(ctx, tenantID, scope)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if containsScope(scope, "profile") {
+		names = append(names, ProfileClaims...)
+	}
+	for _, def := range granted {
+		names = append(names, def.Claims...)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	all, err := s.claimsSource.UserClaims(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	claims := make(map[string]any, len(names))
+	for _, name := range names {
+		if v, ok := all[name]; ok {
+			claims[name] = v
+		}
+	}
+	return claims, nil
+}
+
+// scopePermissions returns the permissions granted by the custom scopes in scope, without duplicates
+func (s *Service) scopePermissions(ctx context.Context, tenantID, scope string) ([]string, error) {
+	granted, _, err := s.grantedScopes(ctx, tenantID, scope)
+	if err != nil {
+		return nil, err
+	}
+	var permissions []string
+	for _, def := range granted {
+		permissions = append(permissions, def.Permissions...)
+	}
+	if len(permissions) == 0 {
+		return nil, nil
+	}
+	return dedupe(permissions), nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+type mockScopeRepo struct {
+	scopes map[string]*ScopeDefinition
+}
+
+func (m *mockScopeRepo) Create(ctx context.Context, scope *ScopeDefinition) error {
+	if _, ok := m.scopes[scope.TenantID+"/"+scope.Name]; ok {
+		return ErrScopeAlreadyExists
+	}
+	m.scopes[scope.TenantID+"/"+scope.Name] = scope
+	return nil
+}
+
+func (m *mockScopeRepo) Get(ctx context.Context, tenantID, name string) (*ScopeDefinition, error) {
+	if s, ok := m.scopes[tenantID+"/"+name]; ok {
+		return s, nil
+	}
+	return nil, ErrScopeNotFound
+}
+
+func (m *mockScopeRepo) List(ctx context.Context, tenantID string) ([]*ScopeDefinition, error) {
+	var out []*ScopeDefinition
+	for _, s := range m.scopes {
+		if s.TenantID == tenantID {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockScopeRepo) Update(ctx context.Context, scope *ScopeDefinition) error {
+	m.scopes[scope.TenantID+"/"+scope.Name] = scope
+	return nil
+}
+
+func (m *mockScopeRepo) Delete(ctx context.Context, tenantID, name string) error {
+	delete(m.scopes, tenantID+"/"+name)
+	return nil
+}
+
+type mockClaimsSource map[string]any
+
+func (m mockClaimsSource) UserClaims(ctx context.Context, userID string) (map[string]any, error) {
+	return m, nil
+}
+
+// TestPurpose: Validates the rules for defining a tenant's custom scopes.
+// Scope: Unit Test
+// Security: Standard OIDC scopes cannot be redefined and only profile claims can be released
+// Expected: Invalid names, reserved names, unknown claims and duplicates are rejected; a valid scope is stored once per tenant.
+// Test Case ID: OA2-10
+func TestOAuth2_Service_CreateScope_Validation(t *testing.T) {
+	s := &Service{auditLogger: audit.NewSlogLogger()}
+	s.SetScopes(&mockScopeRepo{scopes: map[string]*ScopeDefinition{}}, nil)
+	ctx := context.Background()
+
+	invalid := []ScopeInput{
+		{Name: "", Description: "empty"},
+		{Name: "has space", Description: "space"},
+		{Name: "*", Description: "wildcard"},
+		{Name: "profile", Description: "standard"},
+		{Name: "orders:read"},
+		{Name: "orders:read", Description: "secret claim", Claims: []string{"password_hash"}},
+		{Name: "orders:read", Description: "bad permission", Permissions: []string{"orders read"}},
+	}
+	for _, in := range invalid {
+		if _, err := s.CreateScope(ctx, "tenant-1", "admin-1", in); !errors.Is(err, ErrInvalidScopeDefinition) {
+			t.Errorf("%+v: expected ErrInvalidScopeDefinition, got %v", in, err)
+		}
+	}
+
+	in := ScopeInput{Name: "orders:read", Description: " Read your orders ", Claims: []string{"email", "email"}, Permissions: []string{"orders.read"}}
+	scope, err := s.CreateScope(ctx, "tenant-1", "admin-1", in)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if scope.Description != "Read your orders" || !slices.Equal(scope.Claims, []string{"email"}) {
+		t.Errorf("expected a trimmed description and deduplicated claims, got %+v", scope)
+	}
+	if _, err := s.CreateScope(ctx, "tenant-1", "admin-1", in); !errors.Is(err, ErrScopeAlreadyExists) {
+		t.Errorf("expected ErrScopeAlreadyExists, got %v", err)
+	}
+	if _, err := s.CreateScope(ctx, "tenant-2", "admin-2", in); err != nil {
+		t.Errorf("expected another tenant to define the same name, got %v", err)
+	}
+}
+
+// TestPurpose: Validates that custom scopes gate authorization requests and drive the released claims and introspected permissions.
+// Scope: Unit Test
+// Security: Scope enforcement (RFC 6749 Section 3.3) and claim minimization
+// Expected: An undefined scope is rejected with invalid_scope even for a wildcard client; a granted scope releases only its claims and reports its permissions; the profile scope releases the profile claims but not the email.
+// Test Case ID: OA2-11
+func TestOAuth2_Service_CustomScopes_Grant(t *testing.T) {
+	repo := &mockScopeRepo{scopes: map[string]*ScopeDefinition{
+		"tenant-1/orders:read": {TenantID: "tenant-1", Name: "orders:read", Claims: []string{"email"}, Permissions: []string{"orders.read"}},
+		"tenant-1/orders:all":  {TenantID: "tenant-1", Name: "orders:all", Permissions: []string{"orders.read", "orders.write"}},
+	}}
+	s := &Service{
+		clientRepo: &MockClientRepo{clients: map[string]*Client{
+			"client-1": {
+				ClientID:      "client-1",
+				TenantID:      "tenant-1",
+				RedirectURIs:  []string{"https://app.example.com/callback"},
+				AllowedScopes: []string{"*"},
+				IsActive:      true,
+			},
+		}},
+		auditLogger: audit.NewSlogLogger(),
+	}
+	s.SetScopes(repo, mockClaimsSource{"email": "alice@example.com", "name": "Alice"})
+	ctx := context.Background()
+
+	req := &AuthorizeRequest{ClientID: "client-1", RedirectURI: "https://app.example.com/callback", ResponseType: "code", Scope: "openid orders:write"}
+	_, err := s.ValidateAuthorizeRequest(ctx, req)
+	var oauthErr *Error
+	if !errors.As(err, &oauthErr) || oauthErr.Code != ErrInvalidScope {
+		t.Errorf("expected invalid_scope for an undefined scope, got %v", err)
+	}
+	req.Scope = "openid orders:read"
+	if _, err := s.ValidateAuthorizeRequest(ctx, req); err != nil {
+		t.Errorf("expected a defined scope to be accepted, got %v", err)
+	}
+
+	claims, err := s.scopeClaims(ctx, "tenant-1", "user-1", "openid orders:read")
+	if err != nil || len(claims) != 1 || claims["email"] != "alice@example.com" {
+		t.Errorf("expected only the email claim, got %v (%v)", claims, err)
+	}
+	if claims, _ := s.scopeClaims(ctx, "tenant-1", "user-1", "openid"); claims != nil {
+		t.Errorf("expected no claims without custom scopes, got %v", claims)
+	}
+	claims, err = s.scopeClaims(ctx, "tenant-1", "user-1", "openid profile")
+	if err != nil || len(claims) != 1 || claims["name"] != "Alice" {
+		t.Errorf("expected the profile scope to release only the name, got %v (%v)", claims, err)
+	}
+
+	permissions, err := s.scopePermissions(ctx, "tenant-1", "orders:read orders:all deleted:scope")
+	if err != nil || !slices.Equal(permissions, []string{"orders.read", "orders.write"}) {
+		t.Errorf("expected the union of permissions, got %v (%v)", permissions, err)
+	}
+
+	client := &Client{TenantID: "tenant-1", AllowedScopes: []string{"openid", "orders:write"}}
+	if err := s.checkAllowedScopes(ctx, client); !errors.Is(err, ErrDomainInvalidScope) {
+		t.Errorf("expected an undefined allowed scope to be rejected, got %v", err)
+	}
+}
@@ -16,24 +16,48 @@ package oauth2
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"errors"
-	"io"
-	"os"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/observability/tracing"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Protocol features of the authorize and token endpoints, advertised in the server metadata
+var (
+	// GrantTypes are the grant types the token endpoint accepts
+	GrantTypes = []string{"authorization_code", "refresh_token"}
+	// TokenEndpointAuthMethods are how clients authenticate at the token, revocation and
+	// introspection endpoints; "none" is a public client (RFC 6749 Section 2.3.1)
+	TokenEndpointAuthMethods = []string{"client_secret_basic", "client_secret_post", "none"}
+	// CodeChallengeMethods are the PKCE transformations accepted (RFC 7636 Section 4.3)
+	CodeChallengeMethods = []string{"S256", "plain"}
 )
 
 // OIDCProvider defines the interface for OIDC integration (Phase II.3)
 type OIDCProvider interface {
-	GenerateIDToken(userID, tenantID, clientID, nonce, accessToken string) (string, error)
+	// GenerateIDTokenForAudience issues an ID token to audience[0], also addressed to the other
+	// audiences; claims are additional user claims to include, and may be nil
+	GenerateIDTokenForAudience(ctx context.Context, userID, tenantID string, audience []string, nonce, accessToken string, claims map[string]any) (string, error)
+}
+
+// SessionChecker looks up the login sessions authorization codes are bound to
+type SessionChecker interface {
+	// Get returns an active session, or an error when it has ended or never existed
+	Get(ctx context.Context, sessionID string) (*session.Session, error)
 }
 
 // Service provides OAuth2 business logic
@@ -44,14 +68,37 @@ type Service struct {
 	refreshRepo  RefreshTokenRepository
 	auditLogger  audit.Logger
 	oidcProvider OIDCProvider // Optional OIDC integration hook
+	scopeRepo    ScopeRepository
+	claimsSource ClaimsSource
+
+	// Request objects (RFC 9101); an empty issuer refuses them
+	issuer              string
+	requestObjectClient *http.Client
+	clientJWKSMu        sync.Mutex
+	clientJWKS          map[string]*remoteJWKS // jwks_uri -> keys, at most maxCachedClientJWKS
+
+	// Custom claims added at issuance; nil adds none
+	claimEnricher  ClaimEnricher
+	claimsRequired bool
+
+	// Login sessions codes are bound to; nil redeems codes without checking their session
+	sessions SessionChecker
+
+	// Authorization requests waiting for the user to sign in; nil keeps none
+	pendingRepo PendingAuthorizationRepository
+
+	// Per-tenant logging of authorization and token decisions; nil logs none
+	protocolLog *protocolLog
 
 	// Configuration
 	authCodeLifetime     time.Duration
 	accessTokenLifetime  time.Duration
 	refreshTokenLifetime time.Duration
-	encryptionKey        []byte // Master key for encrypting private keys in DB
 }
 
+// defaultAuthCodeLifetime applies when the service is built without an authorization code lifetime
+const defaultAuthCodeLifetime = 5 * time.Minute
+
 // NewService creates a new OAuth2 service
 func NewService(
 	clientRepo ClientRepository,
@@ -64,12 +111,6 @@ func NewService(
 	accessTokenLifetime time.Duration,
 	refreshTokenLifetime time.Duration,
 ) *Service {
-	// Load encryption key from env - MUST be exactly 32 bytes
-	encKey := []byte(os.Getenv("OPENID_KEY_ENCRYPTION_KEY"))
-	if len(encKey) != 32 {
-		panic("OPENID_KEY_ENCRYPTION_KEY environment variable must be exactly 32 bytes")
-	}
-
 	return &Service{
 		clientRepo:           clientRepo,
 		codeRepo:             codeRepo,
@@ -80,7 +121,6 @@ func NewService(
 		authCodeLifetime:     authCodeLifetime,
 		accessTokenLifetime:  accessTokenLifetime,
 		refreshTokenLifetime: refreshTokenLifetime,
-		encryptionKey:        encKey,
 	}
 }
 
@@ -94,8 +134,15 @@ type AuthorizeRequest struct {
 	Nonce               string
 	CodeChallenge       string
 	CodeChallengeMethod string
+	ResponseMode        string // "query" (default) or "fragment"
 }
 
+// Response modes (OAuth 2.0 Multiple Response Type Encoding Practices, Section 2.1)
+const (
+	ResponseModeQuery    = "query"
+	ResponseModeFragment = "fragment"
+)
+
 // TokenRequest represents an OAuth2 token request
 type TokenRequest struct {
 	GrantType    string
@@ -106,6 +153,7 @@ type TokenRequest struct {
 	CodeVerifier string
 	RefreshToken string
 	Scope        string
+	Audience     []string // Clients of the same group the ID token is also issued to
 }
 
 // TokenResponse represents an OAuth2 token response
@@ -120,6 +168,24 @@ type TokenResponse struct {
 
 // CreateClient registers a new OAuth2 client
 func (s *Service) CreateClient(ctx context.Context, client *Client) error {
+	if err := s.checkAllowedScopes(ctx, client); err != nil {
+		return err
+	}
+	if err := validateRequestObjectMetadata(client); err != nil {
+		return err
+	}
+	if err := validateAllowedOrigins(client); err != nil {
+		return err
+	}
+	if err := validateClientGroup(client); err != nil {
+		return err
+	}
+	if err := validateApplicationType(client); err != nil {
+		return err
+	}
+	if err := validateStatePolicy(client); err != nil {
+		return err
+	}
 	if client.ID == "" {
 		client.ID = id.NewUUIDv7()
 	}
@@ -132,34 +198,64 @@ func (s *Service) CreateClient(ctx context.Context, client *Client) error {
 	}
 	client.UpdatedAt = time.Now()
 
-	return s.clientRepo.Create(client)
+	return s.clientRepo.Create(ctx, client)
 }
 
-// ListClients retrieves all OAuth2 clients for a tenant
-func (s *Service) ListClients(ctx context.Context, tenantID string) ([]*Client, error) {
-	return s.clientRepo.ListByTenant(tenantID)
+// ListClients retrieves a page of OAuth2 clients for a tenant and the cursor of the next page
+func (s *Service) ListClients(ctx context.Context, tenantID string, p pagination.Params) ([]*Client, string, error) {
+	p = p.Normalize()
+	clients, err := s.clientRepo.ListByTenant(ctx, tenantID, p)
+	if err != nil {
+		return nil, "", err
+	}
+	return clients, pagination.NextCursor(clients, p, func(c *Client) string { return c.ID }), nil
 }
 
 // GetClient retrieves an OAuth2 client by ID
 func (s *Service) GetClient(ctx context.Context, id string) (*Client, error) {
-	return s.clientRepo.GetByID(id)
+	return s.clientRepo.GetByID(ctx, id)
+}
+
+// GetClientByClientID retrieves an OAuth2 client by its public client_id
+func (s *Service) GetClientByClientID(ctx context.Context, clientID string) (*Client, error) {
+	return s.clientRepo.GetByClientID(ctx, clientID)
 }
 
 // DeleteClient deletes an OAuth2 client
 func (s *Service) DeleteClient(ctx context.Context, id string) error {
-	return s.clientRepo.Delete(id)
+	return s.clientRepo.Delete(ctx, id)
 }
 
 // UpdateClient updates an existing OAuth2 client
 func (s *Service) UpdateClient(ctx context.Context, client *Client) error {
+	if err := s.checkAllowedScopes(ctx, client); err != nil {
+		return err
+	}
+	if err := validateRequestObjectMetadata(client); err != nil {
+		return err
+	}
+	if err := validateAllowedOrigins(client); err != nil {
+		return err
+	}
+	if err := validateClientGroup(client); err != nil {
+		return err
+	}
+	if err := validateApplicationType(client); err != nil {
+		return err
+	}
+	if err := validateStatePolicy(client); err != nil {
+		return err
+	}
 	client.UpdatedAt = time.Now()
-	return s.clientRepo.Update(client)
+	return s.clientRepo.Update(ctx, client)
 }
 
-// ValidateAuthorizeRequest validates an authorization request (RFC 6749 Section 4.1.1)
+// ValidateAuthorizeRequest validates an authorization request (RFC 6749 Section 4.1.1).
+// Errors found once the client is resolved and the redirect_uri matched are returned with the
+// client, so they can be redirected back to it; earlier errors return no client (Section 4.1.2.1).
 func (s *Service) ValidateAuthorizeRequest(ctx context.Context, req *AuthorizeRequest) (*Client, error) {
 	// 1. Validate Client (RFC 6749 Section 4.1.1)
-	client, err := s.clientRepo.GetByClientID(req.ClientID)
+	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
 	if err != nil {
 		return nil, NewError(ErrInvalidRequest, "invalid client_id")
 	}
@@ -177,28 +273,67 @@ func (s *Service) ValidateAuthorizeRequest(ctx context.Context, req *AuthorizeRe
 	// 3. Validate Response Type (RFC 6749 Section 3.1.1)
 	// Phase I.1 only supports 'code'
 	if req.ResponseType != "code" {
-		return nil, NewError(ErrUnsupportedGrantType, "response_type must be 'code'")
+		return client, NewError(ErrUnsupportedGrantType, "response_type must be 'code'")
 	}
 
-	// 4. Validate Scope (RFC 6749 Section 3.3)
+	// 4. Validate Response Mode (OAuth 2.0 Multiple Response Type Encoding Practices, Section 2.1)
+	switch req.ResponseMode {
+	case "", ResponseModeQuery, ResponseModeFragment:
+	default:
+		return client, NewError(ErrInvalidRequest, "unsupported response_mode")
+	}
+
+	// 5. Validate Scope (RFC 6749 Section 3.3)
 	if req.Scope != "" && !client.ValidateScope(req.Scope) {
-		return nil, NewError(ErrInvalidScope, "invalid scope")
+		return client, NewError(ErrInvalidScope, "invalid scope")
+	}
+	// Custom scopes must be defined in the client's tenant, even when the client allows "*"
+	if err := s.validateRequestedScopes(tenant.WithScope(ctx, client.TenantID), client.TenantID, req.Scope); err != nil {
+		return client, err
 	}
 
-	// 5. Validate PKCE Method (RFC 7636 Section 4.3)
+	// 6. Validate PKCE Method (RFC 7636 Section 4.3)
 	if req.CodeChallenge != "" {
-		if req.CodeChallengeMethod != "" && req.CodeChallengeMethod != "plain" && req.CodeChallengeMethod != "S256" {
-			return nil, NewError(ErrInvalidRequest, "transform algorithm not supported")
+		if req.CodeChallengeMethod != "" && !slices.Contains(CodeChallengeMethods, req.CodeChallengeMethod) {
+			return client, NewError(ErrInvalidRequest, "transform algorithm not supported")
 		}
 	}
+	// Browser and native apps cannot keep a secret, so their codes are bound with S256 (RFC 8252 Section 8.1)
+	if client.RequiresPKCE() && (req.CodeChallenge == "" || req.CodeChallengeMethod != "S256") {
+		return client, NewError(ErrInvalidRequest, "code_challenge with code_challenge_method S256 is required for this client")
+	}
+
+	// 7. Validate State (RFC 6749 Section 10.12)
+	if err := checkState(client, req.State); err != nil {
+		return client, err
+	}
 
 	return client, nil
 }
 
-// CreateAuthorizationCode creates a new authorization code (RFC 6749 Section 4.1.2)
-func (s *Service) CreateAuthorizationCode(ctx context.Context, req *AuthorizeRequest, userID string) (*AuthorizationCode, error) {
+// SetSessions binds authorization codes to the login session they are issued under: a code
+// can only be redeemed while its session is active, so logging out invalidates codes in flight.
+func (s *Service) SetSessions(sessions SessionChecker) {
+	s.sessions = sessions
+}
+
+// CreateAuthorizationCode creates a new authorization code (RFC 6749 Section 4.1.2).
+// The code belongs to the client's tenant and can only be redeemed there, while the login
+// session identified by sessionID is active.
+func (s *Service) CreateAuthorizationCode(ctx context.Context, req *AuthorizeRequest, userID, sessionID string) (*AuthorizationCode, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, NewError(ErrInvalidClient, "client not found")
+	}
+	ctx = tenant.WithScope(ctx, client.TenantID)
+
+	lifetime := s.authCodeLifetime
+	if lifetime <= 0 {
+		lifetime = defaultAuthCodeLifetime
+	}
 	code := &AuthorizationCode{
 		ID:                  id.NewUUIDv7(),
+		TenantID:            client.TenantID,
 		Code:                generateAuthorizationCode(),
 		ClientID:            req.ClientID,
 		UserID:              userID,
@@ -208,14 +343,14 @@ func (s *Service) CreateAuthorizationCode(ctx context.Context, req *AuthorizeReq
 		Nonce:               req.Nonce,
 		CodeChallenge:       req.CodeChallenge,
 		CodeChallengeMethod: req.CodeChallengeMethod,
+		SessionID:           sessionID,
 		// Authorization codes MUST be short-lived (RFC 6749 Section 4.1.2 recommends < 10min)
-		// We use 5 minutes per Phase I.1 requirements.
-		ExpiresAt: time.Now().Add(5 * time.Minute),
+		ExpiresAt: time.Now().Add(lifetime),
 		IsUsed:    false,
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.codeRepo.Create(code); err != nil {
+	if err := s.codeRepo.Create(ctx, code); err != nil {
 		return nil, NewError(ErrServerError, "failed to persist authorization code")
 	}
 
@@ -240,19 +375,33 @@ func validatePKCE(challenge, method, verifier string) bool {
 
 // ExchangeCodeForToken exchanges an authorization code for tokens (RFC 6749 Section 4.1.3)
 func (s *Service) ExchangeCodeForToken(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
+	ctx, span := tracing.StartSpan(ctx, "oauth2.ExchangeCodeForToken", attribute.String("client_id", req.ClientID))
+	resp, err := s.exchangeCodeForToken(ctx, req)
+	tracing.EndSpan(span, err)
+	return resp, err
+}
+
+func (s *Service) exchangeCodeForToken(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
 	// 1. Authenticate Client (RFC 6749 Section 3.2.1)
-	client, err := s.ValidateClientCredentials(req.ClientID, req.ClientSecret)
+	client, err := s.ValidateClientCredentials(ctx, req.ClientID, req.ClientSecret)
 	if err != nil {
 		return nil, err
 	}
+	ctx = tenant.WithScope(ctx, client.TenantID)
 
 	// 2. Validate Grant Type (RFC 6749 Section 4.1.3)
 	if req.GrantType != "authorization_code" {
 		return nil, NewError(ErrUnsupportedGrantType, "grant_type must be 'authorization_code'")
 	}
 
-	// 3. Retrieve and Validate Code (RFC 6749 Section 4.1.3)
-	code, err := s.codeRepo.GetByCode(req.Code)
+	// Additional ID token audiences must be related clients (OIDC Core Section 2, azp)
+	audience, err := s.idTokenAudience(ctx, client, req.Audience)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Retrieve and Validate Code (RFC 6749 Section 4.1.3); only codes issued in the client's tenant are found
+	code, err := s.codeRepo.GetByCode(ctx, client.TenantID, req.Code)
 	if err != nil {
 		return nil, NewError(ErrInvalidGrant, "authorization code not found")
 	}
@@ -284,12 +433,25 @@ func (s *Service) ExchangeCodeForToken(ctx context.Context, req *TokenRequest) (
 		// If the code was issued without PKCE, we continue.
 	}
 
-	// 5. Mark code as used
-	if err := s.codeRepo.MarkAsUsed(req.Code); err != nil {
+	// 5. The login session the code was issued under must not have ended since, by logout or revocation
+	if code.SessionID != "" && s.sessions != nil {
+		sess, err := s.sessions.Get(ctx, code.SessionID)
+		if err != nil || sess.UserID != code.UserID {
+			s.codeRepo.MarkAsUsed(ctx, client.TenantID, req.Code)
+			return nil, NewError(ErrInvalidGrant, "authorization session has ended")
+		}
+	}
+
+	// 6. Mark code as used
+	if err := s.codeRepo.MarkAsUsed(ctx, client.TenantID, req.Code); err != nil {
 		return nil, NewError(ErrServerError, "failed to invalidate authorization code")
 	}
 
-	// 6. Issue Access Token
+	// 7. Issue Access Token, with the custom claims of the deployment
+	enriched, err := s.enrichClaims(ctx, client, code.UserID, "authorization_code", code.Scope)
+	if err != nil {
+		return nil, err
+	}
 	rawAccessToken := generateToken()
 	accessToken := &AccessToken{
 		ID:        id.NewUUIDv7(),
@@ -299,16 +461,17 @@ func (s *Service) ExchangeCodeForToken(ctx context.Context, req *TokenRequest) (
 		UserID:    code.UserID,
 		Scope:     code.Scope,
 		TokenType: "Bearer",
+		Claims:    enriched.AccessToken,
 		ExpiresAt: time.Now().Add(time.Duration(client.AccessTokenLifetime) * time.Second),
 		IsRevoked: false,
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.accessRepo.Create(accessToken); err != nil {
+	if err := s.accessRepo.Create(ctx, accessToken); err != nil {
 		return nil, NewError(ErrServerError, "failed to issue access token")
 	}
 
-	// 7. Issue Refresh Token (Optional, RFC 6749 Section 1.5)
+	// 8. Issue Refresh Token (Optional, RFC 6749 Section 1.5)
 	var refreshToken string
 	allowedRefresh := false
 	for _, gt := range client.GrantTypes {
@@ -332,19 +495,31 @@ func (s *Service) ExchangeCodeForToken(ctx context.Context, req *TokenRequest) (
 			IsRevoked:     false,
 			CreatedAt:     time.Now(),
 		}
-		if err := s.refreshRepo.Create(rt); err == nil {
+		if err := s.refreshRepo.Create(ctx, rt); err == nil {
 			refreshToken = rawRefreshToken
 		}
 	}
 
-	// 8. Issue ID Token (OIDC Core Section 2)
+	// 9. Issue ID Token (OIDC Core Section 2)
 	var idToken string
 	if s.oidcProvider != nil && containsScope(code.Scope, "openid") {
-		// Pass nonce and raw access token for at_hash computation (Phase II.3)
-		it, err := s.oidcProvider.GenerateIDToken(code.UserID, client.TenantID, client.ClientID, code.Nonce, rawAccessToken)
+		// Pass nonce and raw access token for at_hash computation (Phase II.3), and the user claims
+		// released by the custom scopes granted; enriched claims cannot override those
+		claims, err := s.scopeClaims(ctx, client.TenantID, code.UserID, code.Scope)
+		if err == nil && len(enriched.IDToken) > 0 {
+			if claims == nil {
+				claims = make(map[string]any, len(enriched.IDToken))
+			}
+			for name, value := range enriched.IDToken {
+				if _, ok := claims[name]; !ok {
+					claims[name] = value
+				}
+			}
+		}
 		if err == nil {
-			idToken = it
-		} else {
+			idToken, err = s.oidcProvider.GenerateIDTokenForAudience(ctx, code.UserID, client.TenantID, audience, code.Nonce, rawAccessToken, claims)
+		}
+		if err != nil {
 			// Log error but don't fail the OAuth2 exchange if OIDC fails
 			// This is a trade-off; some might prefer failing.
 		}
@@ -356,11 +531,12 @@ func (s *Service) ExchangeCodeForToken(ctx context.Context, req *TokenRequest) (
 		TenantID: client.TenantID,
 		ActorID:  code.UserID,
 		Resource: audit.ResourceToken,
-		Metadata: map[string]any{
-			"client_id": client.ClientID,
-			"scope":     code.Scope,
-			"has_rt":    refreshToken != "",
-			"has_it":    idToken != "",
+		Payload: &audit.TokenPayload{
+			ClientID:        client.ClientID,
+			GrantType:       "authorization_code",
+			Scope:           code.Scope,
+			HasRefreshToken: refreshToken != "",
+			HasIDToken:      idToken != "",
 		},
 	})
 
@@ -377,13 +553,14 @@ func (s *Service) ExchangeCodeForToken(ctx context.Context, req *TokenRequest) (
 // RefreshAccessToken handles the refresh_token grant type (RFC 6749 Section 6)
 func (s *Service) RefreshAccessToken(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
 	// 1. Authenticate Client (RFC 6749 Section 3.2.1)
-	client, err := s.ValidateClientCredentials(req.ClientID, req.ClientSecret)
+	client, err := s.ValidateClientCredentials(ctx, req.ClientID, req.ClientSecret)
 	if err != nil {
 		return nil, err
 	}
+	ctx = tenant.WithScope(ctx, client.TenantID)
 
-	// 2. Validate Refresh Token
-	rt, err := s.refreshRepo.GetByTokenHash(hashToken(req.RefreshToken))
+	// 2. Validate Refresh Token; only tokens issued in the client's tenant are found
+	rt, err := s.refreshRepo.GetByTokenHash(ctx, client.TenantID, hashToken(req.RefreshToken))
 	if err != nil {
 		return nil, NewError(ErrInvalidGrant, "refresh token not found")
 	}
@@ -400,7 +577,11 @@ func (s *Service) RefreshAccessToken(ctx context.Context, req *TokenRequest) (*T
 		return nil, NewError(ErrInvalidGrant, "client_id mismatch")
 	}
 
-	// 3. Issue New Access Token
+	// 3. Issue New Access Token; custom claims are evaluated again, so they follow the user's attributes
+	enriched, err := s.enrichClaims(ctx, client, rt.UserID, "refresh_token", rt.Scope)
+	if err != nil {
+		return nil, err
+	}
 	rawAccessToken := generateToken()
 	accessToken := &AccessToken{
 		ID:        id.NewUUIDv7(),
@@ -410,12 +591,13 @@ func (s *Service) RefreshAccessToken(ctx context.Context, req *TokenRequest) (*T
 		UserID:    rt.UserID,
 		Scope:     rt.Scope, // Scope SHOULD be same or subset (RFC 6749 Section 6)
 		TokenType: "Bearer",
+		Claims:    enriched.AccessToken,
 		ExpiresAt: time.Now().Add(time.Duration(client.AccessTokenLifetime) * time.Second),
 		IsRevoked: false,
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.accessRepo.Create(accessToken); err != nil {
+	if err := s.accessRepo.Create(ctx, accessToken); err != nil {
 		return nil, NewError(ErrServerError, "failed to issue access token")
 	}
 
@@ -423,6 +605,20 @@ func (s *Service) RefreshAccessToken(ctx context.Context, req *TokenRequest) (*T
 	// For now, we keep the same refresh token to keep it simple as per Minimal Core.
 	// But issuance of new RT is allowed.
 
+	// Audit token issuance
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTokenIssued,
+		TenantID: client.TenantID,
+		ActorID:  rt.UserID,
+		Resource: audit.ResourceToken,
+		Payload: &audit.TokenPayload{
+			ClientID:        client.ClientID,
+			GrantType:       "refresh_token",
+			Scope:           rt.Scope,
+			HasRefreshToken: true,
+		},
+	})
+
 	return &TokenResponse{
 		AccessToken:  rawAccessToken,
 		TokenType:    "Bearer",
@@ -433,8 +629,8 @@ func (s *Service) RefreshAccessToken(ctx context.Context, req *TokenRequest) (*T
 }
 
 // ValidateClientCredentials validates client credentials (RFC 6749 Section 3.2.1)
-func (s *Service) ValidateClientCredentials(clientID, clientSecret string) (*Client, error) {
-	client, err := s.clientRepo.GetByClientID(clientID)
+func (s *Service) ValidateClientCredentials(ctx context.Context, clientID, clientSecret string) (*Client, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
 	if err != nil {
 		return nil, NewError(ErrInvalidClient, "invalid client credentials")
 	}
@@ -458,12 +654,22 @@ func (s *Service) ValidateClientCredentials(clientID, clientSecret string) (*Cli
 	return client, nil
 }
 
-// ValidateAccessToken validates an access token
-func (s *Service) ValidateAccessToken(ctx context.Context, token string) (*AccessToken, error) {
-	at, err := s.accessRepo.GetByTokenHash(hashToken(token))
+// ValidateAccessToken validates an access token issued within a tenant. A token issued in another
+// tenant is not found, so the returned token's TenantID, the tenant it resolved in, is always tenantID.
+func (s *Service) ValidateAccessToken(ctx context.Context, tenantID, token string) (*AccessToken, error) {
+	if tenantID == "" {
+		return nil, ErrTokenNotFound
+	}
+	at, err := s.accessRepo.GetByTokenHash(tenant.WithScope(ctx, tenantID), tenantID, hashToken(token))
 	if err != nil {
 		return nil, ErrTokenNotFound
 	}
+	// Tenant isolation does not rest on the repository alone
+	if at.TenantID != tenantID {
+		slog.WarnContext(ctx, "access token presented outside its tenant",
+			"tenant_id", tenantID, "token_tenant_id", at.TenantID, "client_id", at.ClientID)
+		return nil, ErrTokenNotFound
+	}
 
 	if at.IsRevoked {
 		return nil, ErrTokenRevoked
@@ -476,50 +682,130 @@ func (s *Service) ValidateAccessToken(ctx context.Context, token string) (*Acces
 	return at, nil
 }
 
-// Helper functions
-
-func (s *Service) encrypt(data []byte) ([]byte, error) {
-	block, err := aes.NewCipher(s.encryptionKey)
+// AuthenticateAccessToken validates an access token issued within any tenant, for requests that
+// name no tenant; the caller must hold the request to the returned token's tenant
+func (s *Service) AuthenticateAccessToken(ctx context.Context, token string) (*AccessToken, error) {
+	at, err := s.accessRepo.FindByTokenHash(ctx, hashToken(token))
 	if err != nil {
-		return nil, err
+		return nil, ErrTokenNotFound
 	}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+	if at.IsRevoked {
+		return nil, ErrTokenRevoked
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
+	if at.IsExpired() {
+		return nil, ErrTokenExpired
 	}
 
-	return gcm.Seal(nonce, nonce, data, nil), nil
+	return at, nil
 }
 
-func (s *Service) decrypt(data []byte) ([]byte, error) {
-	block, err := aes.NewCipher(s.encryptionKey)
-	if err != nil {
+// IntrospectionResponse is the token introspection response (RFC 7662 Section 2.2). Only Active is
+// set for an inactive token.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	JWTID     string `json:"jti,omitempty"`       // The token's ID, unique per issuance
+	TenantID  string `json:"tenant_id,omitempty"` // Extension: the tenant that issued the token
+	// Extension: the permissions of the tenant's custom scopes granted to the token
+	Permissions []string `json:"permissions,omitempty"`
+	// Extension: the custom claims added to the token at issuance, as top-level members
+	Claims map[string]any `json:"-"`
+}
+
+// MarshalJSON adds the custom claims to the response members; ReservedClaims keeps them from
+// replacing a standard member
+func (r IntrospectionResponse) MarshalJSON() ([]byte, error) {
+	type response IntrospectionResponse
+	body, err := json.Marshal(response(r))
+	if err != nil || len(r.Claims) == 0 {
+		return body, err
+	}
+	members := make(map[string]any, len(r.Claims)+10)
+	for name, value := range r.Claims {
+		members[name] = value
+	}
+	if err := json.Unmarshal(body, &members); err != nil {
 		return nil, err
 	}
+	return json.Marshal(members)
+}
 
-	gcm, err := cipher.NewGCM(block)
+// IntrospectAccessToken reports whether an access token issued within a tenant is active.
+// Unknown, expired and revoked tokens are all reported as inactive without telling them apart
+// (RFC 7662 Section 2.2); the caller fills in the subject and issuer of active tokens.
+func (s *Service) IntrospectAccessToken(ctx context.Context, tenantID, token string) (*IntrospectionResponse, *AccessToken) {
+	at, err := s.ValidateAccessToken(ctx, tenantID, token)
 	if err != nil {
-		return nil, err
-	}
+		return &IntrospectionResponse{Active: false}, nil
+	}
+	// Permissions follow the current scope definitions; a failed lookup leaves them out
+	permissions, _ := s.scopePermissions(tenant.WithScope(ctx, tenantID), tenantID, at.Scope)
+	return &IntrospectionResponse{
+		Active:      true,
+		Scope:       at.Scope,
+		ClientID:    at.ClientID,
+		TokenType:   at.TokenType,
+		ExpiresAt:   at.ExpiresAt.Unix(),
+		IssuedAt:    at.CreatedAt.Unix(),
+		JWTID:       at.ID,
+		TenantID:    at.TenantID,
+		Permissions: permissions,
+		Claims:      at.Claims,
+	}, at
+}
 
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return nil, errors.New("ciphertext too short")
-	}
+// Token kinds reported by LookupToken (RFC 7009 Section 2.1 token type hints)
+const (
+	TokenKindAccess  = "access_token"
+	TokenKindRefresh = "refresh_token"
+)
+
+// TokenInfo describes a stored access or refresh token
+type TokenInfo struct {
+	Kind      string     `json:"kind"`
+	ID        string     `json:"id"`
+	TenantID  string     `json:"tenant_id"`
+	ClientID  string     `json:"client_id"`
+	UserID    string     `json:"user_id"`
+	Scope     string     `json:"scope"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	Revoked   bool       `json:"revoked"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
 
-	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	return gcm.Open(nil, nonce, ciphertext, nil)
+// LookupToken finds an access or refresh token of a tenant by its raw value. Unlike ValidateAccessToken
+// it also returns expired and revoked tokens, for support and debugging.
+func (s *Service) LookupToken(ctx context.Context, tenantID, token string) (*TokenInfo, error) {
+	ctx = tenant.WithScope(ctx, tenantID)
+	hash := hashToken(token)
+	if at, err := s.accessRepo.GetByTokenHash(ctx, tenantID, hash); err == nil && at != nil {
+		return &TokenInfo{
+			Kind: TokenKindAccess, ID: at.ID, TenantID: at.TenantID, ClientID: at.ClientID, UserID: at.UserID,
+			Scope: at.Scope, CreatedAt: at.CreatedAt, ExpiresAt: at.ExpiresAt, Revoked: at.IsRevoked, RevokedAt: at.RevokedAt,
+		}, nil
+	}
+	if rt, err := s.refreshRepo.GetByTokenHash(ctx, tenantID, hash); err == nil && rt != nil {
+		return &TokenInfo{
+			Kind: TokenKindRefresh, ID: rt.ID, TenantID: rt.TenantID, ClientID: rt.ClientID, UserID: rt.UserID,
+			Scope: rt.Scope, CreatedAt: rt.CreatedAt, ExpiresAt: rt.ExpiresAt, Revoked: rt.IsRevoked, RevokedAt: rt.RevokedAt,
+		}, nil
+	}
+	return nil, ErrTokenNotFound
 }
 
-// RevokeRefreshToken revokes a refresh token (Security Best Practice)
-func (s *Service) RevokeRefreshToken(ctx context.Context, token string, clientID string) error {
-	rt, err := s.refreshRepo.GetByTokenHash(hashToken(token))
+// RevokeRefreshToken revokes a refresh token issued to a client of the tenant (Security Best Practice)
+func (s *Service) RevokeRefreshToken(ctx context.Context, tenantID, token, clientID string) error {
+	ctx = tenant.WithScope(ctx, tenantID)
+	rt, err := s.refreshRepo.GetByTokenHash(ctx, tenantID, hashToken(token))
 	if err != nil {
 		return ErrTokenNotFound
 	}
@@ -528,7 +814,29 @@ func (s *Service) RevokeRefreshToken(ctx context.Context, token string, clientID
 		return NewError(ErrInvalidClient, "client_id mismatch")
 	}
 
-	return s.refreshRepo.Revoke(hashToken(token))
+	if err := s.refreshRepo.Revoke(ctx, tenantID, hashToken(token)); err != nil {
+		return err
+	}
+
+	// Audit token revocation
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTokenRevoked,
+		TenantID: rt.TenantID,
+		ActorID:  rt.UserID,
+		Resource: audit.ResourceToken,
+		Payload:  &audit.TokenPayload{ClientID: rt.ClientID, Scope: rt.Scope, HasRefreshToken: true},
+	})
+
+	return nil
+}
+
+// RevokeUserTokens revokes every access and refresh token issued to a user, in any tenant
+func (s *Service) RevokeUserTokens(ctx context.Context, userID string) error {
+	if _, err := s.refreshRepo.RevokeByUserID(ctx, userID); err != nil {
+		return err
+	}
+	_, err := s.accessRepo.RevokeByUserID(ctx, userID)
+	return err
 }
 
 func containsScope(scope, target string) bool {
@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/session"
 )
 
 // Mock repos for OAuth2
@@ -29,14 +31,14 @@ type MockClientRepo struct {
 	clients map[string]*Client
 }
 
-func (m *MockClientRepo) GetByClientID(clientID string) (*Client, error) {
+func (m *MockClientRepo) GetByClientID(ctx context.Context, clientID string) (*Client, error) {
 	c, ok := m.clients[clientID]
 	if !ok {
 		return nil, ErrClientNotFound
 	}
 	return c, nil
 }
-func (m *MockClientRepo) GetByID(id string) (*Client, error) {
+func (m *MockClientRepo) GetByID(ctx context.Context, id string) (*Client, error) {
 	for _, c := range m.clients {
 		if c.ID == id {
 			return c, nil
@@ -44,11 +46,16 @@ func (m *MockClientRepo) GetByID(id string) (*Client, error) {
 	}
 	return nil, ErrClientNotFound
 }
-func (m *MockClientRepo) Create(client *Client) error                   { return nil }
-func (m *MockClientRepo) Update(client *Client) error                   { return nil }
-func (m *MockClientRepo) Delete(id string) error                        { return nil }
-func (m *MockClientRepo) ListByOwner(ownerID string) ([]*Client, error) { return nil, nil }
-func (m *MockClientRepo) ListByTenant(tenantID string) ([]*Client, error) {
+func (m *MockClientRepo) Create(ctx context.Context, client *Client) error { return nil }
+func (m *MockClientRepo) Update(ctx context.Context, client *Client) error { return nil }
+func (m *MockClientRepo) Delete(ctx context.Context, id string) error      { return nil }
+func (m *MockClientRepo) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+func (m *MockClientRepo) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*Client, error) {
+	return nil, nil
+}
+func (m *MockClientRepo) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*Client, error) {
 	var res []*Client
 	for _, c := range m.clients {
 		if c.TenantID == tenantID {
@@ -62,57 +69,82 @@ type MockCodeRepo struct {
 	codes map[string]*AuthorizationCode
 }
 
-func (m *MockCodeRepo) Create(code *AuthorizationCode) error {
+func (m *MockCodeRepo) Create(ctx context.Context, code *AuthorizationCode) error {
 	m.codes[code.Code] = code
 	return nil
 }
-func (m *MockCodeRepo) GetByCode(code string) (*AuthorizationCode, error) {
+func (m *MockCodeRepo) GetByCode(ctx context.Context, tenantID, code string) (*AuthorizationCode, error) {
 	c, ok := m.codes[code]
-	if !ok {
+	if !ok || c.TenantID != tenantID {
 		return nil, ErrCodeNotFound
 	}
 	return c, nil
 }
-func (m *MockCodeRepo) MarkAsUsed(code string) error {
-	if c, ok := m.codes[code]; ok {
+func (m *MockCodeRepo) MarkAsUsed(ctx context.Context, tenantID, code string) error {
+	if c, ok := m.codes[code]; ok && c.TenantID == tenantID {
 		c.IsUsed = true
 	}
 	return nil
 }
-func (m *MockCodeRepo) Delete(code string) error {
+func (m *MockCodeRepo) Delete(ctx context.Context, tenantID, code string) error {
 	delete(m.codes, code)
 	return nil
 }
-func (m *MockCodeRepo) DeleteExpired() error { return nil }
+func (m *MockCodeRepo) DeleteExpired(ctx context.Context, limit int) (int64, error) { return 0, nil }
 
 type MockAccessRepo struct {
 }
 
-func (m *MockAccessRepo) Create(token *AccessToken) error { return nil }
-func (m *MockAccessRepo) GetByTokenHash(hash string) (*AccessToken, error) {
+func (m *MockAccessRepo) Create(ctx context.Context, token *AccessToken) error { return nil }
+func (m *MockAccessRepo) GetByTokenHash(ctx context.Context, tenantID, hash string) (*AccessToken, error) {
+	return nil, nil
+}
+func (m *MockAccessRepo) FindByTokenHash(ctx context.Context, hash string) (*AccessToken, error) {
 	return nil, nil
 }
-func (m *MockAccessRepo) Revoke(hash string) error { return nil }
-func (m *MockAccessRepo) DeleteExpired() error     { return nil }
+func (m *MockAccessRepo) Revoke(ctx context.Context, tenantID, hash string) error     { return nil }
+func (m *MockAccessRepo) DeleteExpired(ctx context.Context, limit int) (int64, error) { return 0, nil }
+func (m *MockAccessRepo) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	return 0, nil
+}
+func (m *MockAccessRepo) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	return 0, nil
+}
+func (m *MockAccessRepo) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	return 0, nil
+}
 
 type MockRefreshRepo struct {
 }
 
-func (m *MockRefreshRepo) Create(token *RefreshToken) error { return nil }
-func (m *MockRefreshRepo) GetByTokenHash(hash string) (*RefreshToken, error) {
+func (m *MockRefreshRepo) Create(ctx context.Context, token *RefreshToken) error { return nil }
+func (m *MockRefreshRepo) GetByTokenHash(ctx context.Context, tenantID, hash string) (*RefreshToken, error) {
 	return nil, nil
 }
-func (m *MockRefreshRepo) Revoke(hash string) error { return nil }
-func (m *MockRefreshRepo) DeleteExpired() error     { return nil }
+func (m *MockRefreshRepo) Revoke(ctx context.Context, tenantID, hash string) error     { return nil }
+func (m *MockRefreshRepo) DeleteExpired(ctx context.Context, limit int) (int64, error) { return 0, nil }
+func (m *MockRefreshRepo) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	return 0, nil
+}
+func (m *MockRefreshRepo) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	return 0, nil
+}
+func (m *MockRefreshRepo) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	return 0, nil
+}
 
 type MockOIDCProvider struct {
+	CapturedAudience    []string
 	CapturedNonce       string
 	CapturedAccessToken string
+	CapturedClaims      map[string]any
 }
 
-func (m *MockOIDCProvider) GenerateIDToken(userID, tenantID, clientID, nonce, accessToken string) (string, error) {
+func (m *MockOIDCProvider) GenerateIDTokenForAudience(ctx context.Context, userID, tenantID string, audience []string, nonce, accessToken string, claims map[string]any) (string, error) {
+	m.CapturedAudience = audience
 	m.CapturedNonce = nonce
 	m.CapturedAccessToken = accessToken
+	m.CapturedClaims = claims
 	return "mock-id-token", nil
 }
 
@@ -158,7 +190,7 @@ func TestOAuth2_Service_ExchangeCodeForToken_Success(t *testing.T) {
 	}
 
 	// 1. Create code
-	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-123")
+	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-123", "")
 
 	// 2. Exchange code
 	tokenReq := &TokenRequest{
@@ -227,7 +259,7 @@ func TestOAuth2_Service_ExchangeCodeForToken_PKCEFailure(t *testing.T) {
 		CodeChallenge:       challenge,
 		CodeChallengeMethod: "S256",
 	}
-	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-1")
+	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-1", "")
 
 	// Exchange with WRONG verifier
 	tokenReq := &TokenRequest{
@@ -277,7 +309,7 @@ func TestOAuth2_Service_ExchangeCodeForToken_Replay(t *testing.T) {
 		RedirectURI:   "https://app.example.com/callback",
 		CodeChallenge: "challenge",
 	}
-	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-1")
+	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-1", "")
 
 	tokenReq := &TokenRequest{
 		GrantType:    "authorization_code",
@@ -311,6 +343,86 @@ func TestOAuth2_Service_ExchangeCodeForToken_Replay(t *testing.T) {
 	}
 }
 
+type MockSessions struct {
+	sessions map[string]*session.Session
+}
+
+func (m *MockSessions) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	if sess, ok := m.sessions[sessionID]; ok {
+		return sess, nil
+	}
+	return nil, session.ErrSessionNotFound
+}
+
+// TestPurpose: Validates that an authorization code is bound to the login session it was issued under.
+// Scope: Unit Test
+// Security: Session revocation (logout invalidates authorization codes in flight)
+// Expected: A code is redeemed while its session is active; once the session has ended, or belongs to another user, the exchange fails with invalid_grant and the code is spent.
+// Test Case ID: OA2-16
+func TestOAuth2_Service_ExchangeCodeForToken_SessionBinding(t *testing.T) {
+	codes := &MockCodeRepo{codes: make(map[string]*AuthorizationCode)}
+	sessions := &MockSessions{sessions: map[string]*session.Session{
+		"sess-1": {ID: "sess-1", UserID: "user-1"},
+		"sess-2": {ID: "sess-2", UserID: "user-2"},
+	}}
+	s := &Service{
+		clientRepo: &MockClientRepo{
+			clients: map[string]*Client{
+				"client-1": {
+					ClientID:         "client-1",
+					ClientSecretHash: hashClientSecret("secret-1"),
+					RedirectURIs:     []string{"https://app.example.com/callback"},
+					IsActive:         true,
+				},
+			},
+		},
+		codeRepo:    codes,
+		accessRepo:  &MockAccessRepo{},
+		refreshRepo: &MockRefreshRepo{},
+		auditLogger: audit.NewSlogLogger(),
+	}
+	s.SetSessions(sessions)
+
+	ctx := context.Background()
+	exchange := func(userID, sessionID string) (*AuthorizationCode, error) {
+		authReq := &AuthorizeRequest{
+			ClientID:      "client-1",
+			RedirectURI:   "https://app.example.com/callback",
+			CodeChallenge: "challenge",
+		}
+		code, err := s.CreateAuthorizationCode(ctx, authReq, userID, sessionID)
+		if err != nil {
+			t.Fatalf("CreateAuthorizationCode: %v", err)
+		}
+		_, err = s.ExchangeCodeForToken(ctx, &TokenRequest{
+			GrantType:    "authorization_code",
+			ClientID:     "client-1",
+			ClientSecret: "secret-1",
+			RedirectURI:  "https://app.example.com/callback",
+			Code:         code.Code,
+			CodeVerifier: "challenge",
+		})
+		return codes.codes[code.Code], err
+	}
+
+	if code, err := exchange("user-1", "sess-1"); err != nil || code.SessionID != "sess-1" {
+		t.Fatalf("expected a code of an active session to be redeemed, got %v", err)
+	}
+
+	delete(sessions.sessions, "sess-1")
+	code, err := exchange("user-1", "sess-1")
+	if AsError(err).Code != ErrInvalidGrant {
+		t.Errorf("expected invalid_grant after logout, got %v", err)
+	}
+	if !code.IsUsed {
+		t.Error("expected the code of an ended session to be spent")
+	}
+
+	if _, err := exchange("user-1", "sess-2"); AsError(err).Code != ErrInvalidGrant {
+		t.Errorf("expected invalid_grant for another user's session, got %v", err)
+	}
+}
+
 // TestPurpose: Validates that an expired authorization code cannot be exchanged for tokens.
 // Scope: Unit Test
 // Security: Temporary credential lifecycle enforcement
@@ -339,7 +451,7 @@ func TestOAuth2_Service_ExchangeCodeForToken_Expired(t *testing.T) {
 
 	ctx := context.Background()
 	authReq := &AuthorizeRequest{ClientID: "client-1"}
-	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-1")
+	code, _ := s.CreateAuthorizationCode(ctx, authReq, "user-1", "")
 
 	// Manually expire the code
 	code.ExpiresAt = time.Now().Add(-1 * time.Hour)
@@ -0,0 +1,74 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// minRequiredStateLength and maxRequiredStateLength bound Client.MinStateLength
+	minRequiredStateLength = 8
+	maxRequiredStateLength = 256
+	// minStateBitsPerChar is the entropy each required character of state must carry on average.
+	// Random hex or base64url values carry about 3.5 to 6 bits; repeated patterns far less.
+	minStateBitsPerChar = 2
+)
+
+// validateStatePolicy checks the minimum state length a client requires
+func validateStatePolicy(client *Client) error {
+	n := client.MinStateLength
+	if n != 0 && (n < minRequiredStateLength || n > maxRequiredStateLength) {
+		return fmt.Errorf("%w: min_state_length must be 0 or between %d and %d", ErrDomainInvalidMetadata, minRequiredStateLength, maxRequiredStateLength)
+	}
+	return nil
+}
+
+// checkState enforces the state policy of the client on an authorization request. State is the
+// client's CSRF protection (RFC 6749 Section 10.12), so a client that requires it gets a protocol
+// error when it is missing, too short, or too predictable to protect anything.
+func checkState(client *Client, state string) error {
+	if client.MinStateLength == 0 {
+		return nil
+	}
+	if state == "" {
+		return NewError(ErrInvalidRequest, "state is required for this client")
+	}
+	if len(state) < client.MinStateLength {
+		return NewError(ErrInvalidRequest, fmt.Sprintf("state must be at least %d characters", client.MinStateLength))
+	}
+	if stateEntropy(state) < float64(minStateBitsPerChar*client.MinStateLength) {
+		return NewError(ErrInvalidRequest, "state has too little entropy")
+	}
+	return nil
+}
+
+// stateEntropy estimates the entropy of state in bits from the frequency of its characters
+func stateEntropy(state string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range state {
+		counts[r]++
+		n++
+	}
+
+	var bitsPerChar float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		bitsPerChar -= p * math.Log2(p)
+	}
+	return bitsPerChar * float64(n)
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestPurpose: Validates the per-client state policy of authorization requests.
+// Scope: Unit Test
+// Security: CSRF (RFC 6749 Section 10.12; a client requiring state cannot be sent requests without an unguessable one)
+// Expected: Clients without a minimum accept any state; otherwise a missing, short or low-entropy state is invalid_request and a random one is accepted; minimums outside 8 to 256 are invalid metadata.
+// Test Case ID: OA2-22
+// RelatedSpecs: RFC 6749 Section 10.12, RFC 9700 Section 2.1
+func TestOAuth2_StatePolicy(t *testing.T) {
+	for _, n := range []int{0, minRequiredStateLength, maxRequiredStateLength} {
+		if err := validateStatePolicy(&Client{MinStateLength: n}); err != nil {
+			t.Errorf("min_state_length %d: expected valid, got %v", n, err)
+		}
+	}
+	for _, n := range []int{-1, minRequiredStateLength - 1, maxRequiredStateLength + 1} {
+		if err := validateStatePolicy(&Client{MinStateLength: n}); !errors.Is(err, ErrDomainInvalidMetadata) {
+			t.Errorf("min_state_length %d: expected invalid metadata, got %v", n, err)
+		}
+	}
+
+	s := &Service{clientRepo: &MockClientRepo{clients: map[string]*Client{
+		"strict": {ClientID: "strict", TenantID: "t1", RedirectURIs: []string{"https://app.example.com/cb"}, MinStateLength: 16, IsActive: true},
+		"lax":    {ClientID: "lax", TenantID: "t1", RedirectURIs: []string{"https://app.example.com/cb"}, IsActive: true},
+	}}}
+	validate := func(clientID, state string) error {
+		_, err := s.ValidateAuthorizeRequest(context.Background(), &AuthorizeRequest{
+			ClientID: clientID, RedirectURI: "https://app.example.com/cb", ResponseType: "code", State: state,
+		})
+		return err
+	}
+
+	if err := validate("lax", ""); err != nil {
+		t.Errorf("expected state to be optional without a policy, got %v", err)
+	}
+	if err := validate("strict", "Jx3_kP9qLm2vR8tYwZ4a"); err != nil {
+		t.Errorf("expected a random state to be accepted, got %v", err)
+	}
+	for _, state := range []string{"", "short", "aaaaaaaaaaaaaaaaaaaa", "abababababababababab"} {
+		if err := validate("strict", state); AsError(err).Code != ErrInvalidRequest {
+			t.Errorf("state %q: expected invalid_request, got %v", state, err)
+		}
+	}
+}
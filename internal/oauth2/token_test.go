@@ -0,0 +1,155 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth2_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// TestPurpose: Validates that stored tokens can be looked up by their raw value for support, whatever their state.
+// Scope: Unit Test
+// Security: Token inspection (only the hash is stored; revoked and expired tokens are reported, not hidden)
+// Expected: Access and refresh tokens are found with their kind and revocation state; unknown values and other tenants' tokens are not found.
+// Test Case ID: OA2-07
+// RelatedSpecs: RFC 7662 Section 2.2
+func TestOAuth2_Service_LookupToken(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	access := memory.NewAccessTokenRepository(db)
+	refresh := memory.NewRefreshTokenRepository(db)
+	svc := oauth2.NewService(memory.NewClientRepository(db), memory.NewAuthorizationCodeRepository(db), access, refresh,
+		nil, nil, time.Minute, time.Hour, 24*time.Hour)
+
+	revokedAt := time.Now()
+	require.NoError(t, access.Create(ctx, &oauth2.AccessToken{
+		ID: "at-1", TenantID: "t1", TokenHash: tokenHash("access-raw"), ClientID: "c1", UserID: "u1",
+		Scope: "openid", TokenType: "Bearer", ExpiresAt: time.Now().Add(-time.Minute), CreatedAt: time.Now().Add(-time.Hour),
+	}))
+	require.NoError(t, refresh.Create(ctx, &oauth2.RefreshToken{
+		ID: "rt-1", TenantID: "t1", TokenHash: tokenHash("refresh-raw"), AccessTokenID: "at-1", ClientID: "c1", UserID: "u1",
+		Scope: "openid", ExpiresAt: time.Now().Add(time.Hour), IsRevoked: true, RevokedAt: &revokedAt, CreatedAt: time.Now(),
+	}))
+
+	info, err := svc.LookupToken(ctx, "t1", "access-raw")
+	require.NoError(t, err)
+	assert.Equal(t, oauth2.TokenKindAccess, info.Kind)
+	assert.Equal(t, "at-1", info.ID)
+	assert.True(t, info.ExpiresAt.Before(time.Now()), "expired tokens are still returned")
+
+	info, err = svc.LookupToken(ctx, "t1", "refresh-raw")
+	require.NoError(t, err)
+	assert.Equal(t, oauth2.TokenKindRefresh, info.Kind)
+	assert.True(t, info.Revoked)
+	assert.NotNil(t, info.RevokedAt)
+
+	_, err = svc.LookupToken(ctx, "t1", "unknown")
+	assert.ErrorIs(t, err, oauth2.ErrTokenNotFound)
+	_, err = svc.LookupToken(ctx, "t2", "access-raw")
+	assert.ErrorIs(t, err, oauth2.ErrTokenNotFound, "tokens of another tenant are not found")
+}
+
+// TestPurpose: Validates that token introspection reports only live access tokens of the tenant as active.
+// Scope: Unit Test
+// Security: Token introspection (RFC 7662); inactive tokens must not reveal why they are inactive
+// Expected: A live access token is active with its scope, client, expiry and ID as jti; expired, unknown and other tenants' tokens are inactive with no other fields set.
+// Test Case ID: OA2-08
+// RelatedSpecs: RFC 7662 Section 2.2
+func TestOAuth2_Service_IntrospectAccessToken(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	access := memory.NewAccessTokenRepository(db)
+	svc := oauth2.NewService(memory.NewClientRepository(db), memory.NewAuthorizationCodeRepository(db), access,
+		memory.NewRefreshTokenRepository(db), nil, nil, time.Minute, time.Hour, 24*time.Hour)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, access.Create(ctx, &oauth2.AccessToken{
+		ID: "at-1", TenantID: "t1", TokenHash: tokenHash("live"), ClientID: "c1", UserID: "u1",
+		Scope: "openid orders:read", TokenType: "Bearer", ExpiresAt: expiresAt, CreatedAt: time.Now(),
+	}))
+	require.NoError(t, access.Create(ctx, &oauth2.AccessToken{
+		ID: "at-2", TenantID: "t1", TokenHash: tokenHash("expired"), ClientID: "c1", UserID: "u1",
+		Scope: "openid", TokenType: "Bearer", ExpiresAt: time.Now().Add(-time.Minute), CreatedAt: time.Now().Add(-time.Hour),
+	}))
+
+	resp, at := svc.IntrospectAccessToken(ctx, "t1", "live")
+	require.NotNil(t, at)
+	assert.True(t, resp.Active)
+	assert.Equal(t, "openid orders:read", resp.Scope)
+	assert.Equal(t, "c1", resp.ClientID)
+	assert.Equal(t, "t1", resp.TenantID)
+	assert.Equal(t, expiresAt.Unix(), resp.ExpiresAt)
+	assert.Equal(t, "at-1", resp.JWTID)
+
+	for _, tc := range []struct{ tenantID, token string }{{"t1", "expired"}, {"t1", "unknown"}, {"t2", "live"}} {
+		resp, at := svc.IntrospectAccessToken(ctx, tc.tenantID, tc.token)
+		assert.Nil(t, at)
+		assert.Equal(t, &oauth2.IntrospectionResponse{Active: false}, resp, "%s in %s", tc.token, tc.tenantID)
+	}
+}
+
+// tenantBlindAccessRepo returns tokens whatever tenant they are asked for, as a faulty store would
+type tenantBlindAccessRepo struct {
+	oauth2.AccessTokenRepository
+}
+
+func (r tenantBlindAccessRepo) GetByTokenHash(ctx context.Context, tenantID, hash string) (*oauth2.AccessToken, error) {
+	return r.FindByTokenHash(ctx, hash)
+}
+
+// TestPurpose: Validates that access token validation is bound to the tenant the caller names.
+// Scope: Unit Test
+// Security: Tenant isolation (a resource server of one tenant cannot validate or introspect another tenant's tokens)
+// Expected: A token validates in its own tenant with that TenantID; it is not found in another tenant or without a tenant, even when the store ignores the tenant.
+// Test Case ID: OA2-19
+func TestOAuth2_Service_ValidateAccessToken_TenantBound(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	access := memory.NewAccessTokenRepository(db)
+	require.NoError(t, access.Create(ctx, &oauth2.AccessToken{
+		ID: "at-1", TenantID: "t1", TokenHash: tokenHash("live"), ClientID: "c1", UserID: "u1",
+		Scope: "openid", TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+	}))
+
+	for name, repo := range map[string]oauth2.AccessTokenRepository{"store": access, "tenant-blind store": tenantBlindAccessRepo{access}} {
+		svc := oauth2.NewService(memory.NewClientRepository(db), memory.NewAuthorizationCodeRepository(db), repo,
+			memory.NewRefreshTokenRepository(db), nil, nil, time.Minute, time.Hour, 24*time.Hour)
+
+		at, err := svc.ValidateAccessToken(ctx, "t1", "live")
+		require.NoError(t, err, name)
+		assert.Equal(t, "t1", at.TenantID, name)
+
+		for _, tenantID := range []string{"t2", ""} {
+			_, err := svc.ValidateAccessToken(ctx, tenantID, "live")
+			assert.ErrorIs(t, err, oauth2.ErrTokenNotFound, "%s: tenant %q", name, tenantID)
+			resp, at := svc.IntrospectAccessToken(ctx, tenantID, "live")
+			assert.Nil(t, at, name)
+			assert.False(t, resp.Active, name)
+		}
+	}
+}
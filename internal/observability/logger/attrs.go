@@ -31,6 +31,10 @@ func Path(path string) slog.Attr {
 	return slog.String("path", path)
 }
 
+func Route(pattern string) slog.Attr {
+	return slog.String("route", pattern)
+}
+
 func RemoteAddr(addr string) slog.Attr {
 	return slog.String("remote_addr", addr)
 }
@@ -47,6 +51,10 @@ func Duration(ms int64) slog.Attr {
 	return slog.Int64("duration_ms", ms)
 }
 
+func Bytes(n int) slog.Attr {
+	return slog.Int("bytes", n)
+}
+
 // Identity attributes
 func UserID(id string) slog.Attr {
 	return slog.String("user_id", id)
@@ -60,6 +68,10 @@ func SessionID(id string) slog.Attr {
 	return slog.String("session_id", id)
 }
 
+func TenantID(id string) slog.Attr {
+	return slog.String("tenant_id", id)
+}
+
 // OAuth/OIDC attributes
 func ClientID(id string) slog.Attr {
 	return slog.String("client_id", id)
@@ -16,10 +16,12 @@ package logger
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
 	"time"
 
+	"github.com/opentrusty/opentrusty/internal/observability/redact"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -29,6 +31,7 @@ type Config struct {
 	Level       string // debug, info, warn, error
 	Format      string // json, text
 	ServiceName string
+	Output      io.Writer // Defaults to stdout
 }
 
 // InitLogger initializes the global logger with OTel support
@@ -58,11 +61,15 @@ func InitLogger(cfg Config) {
 	}
 
 	// 1. Stdout Handler (with Trace Context support)
+	output := cfg.Output
+	if output == nil {
+		output = os.Stdout
+	}
 	var baseHandler slog.Handler
 	if cfg.Format == "json" {
-		baseHandler = slog.NewJSONHandler(os.Stdout, opts)
+		baseHandler = slog.NewJSONHandler(output, opts)
 	} else {
-		baseHandler = slog.NewTextHandler(os.Stdout, opts)
+		baseHandler = slog.NewTextHandler(output, opts)
 	}
 	stdoutHandler := &TraceContextHandler{Handler: baseHandler}
 
@@ -70,11 +77,11 @@ func InitLogger(cfg Config) {
 	// otelslog handler extracts trace context automatically
 	otelHandler := otelslog.NewHandler(cfg.ServiceName)
 
-	// 3. Fanout Handler
+	// 3. Fanout Handler, behind the redaction of secret-bearing attributes
 	fanout := NewFanoutHandler(stdoutHandler, otelHandler)
 
 	// Set as global default
-	logger := slog.New(fanout)
+	logger := slog.New(redact.NewHandler(fanout))
 	slog.SetDefault(logger)
 }
 
@@ -17,22 +17,32 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/opentrusty/opentrusty/internal/observability/otlp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
 // Config holds metrics configuration
 type Config struct {
-	Enabled bool
+	Enabled            bool
+	ServiceVersion     string
+	ResourceAttributes map[string]string
+	Exporter           otlp.Config
+	// ExportInterval is how often metrics are pushed to the collector (default 1m)
+	ExportInterval time.Duration
 }
 
 // Meter wraps OpenTelemetry meter
 type Meter struct {
-	meter metric.Meter
+	meter    metric.Meter
+	provider *sdkmetric.MeterProvider
 }
 
-// New creates a new meter instance
+// New creates a new meter instance. When enabled it installs a global meter provider that
+// pushes to the configured OTLP collector.
 func New(ctx context.Context, cfg Config, serviceName string) (*Meter, error) {
 	if !cfg.Enabled {
 		return &Meter{
@@ -40,15 +50,39 @@ func New(ctx context.Context, cfg Config, serviceName string) (*Meter, error) {
 		}, nil
 	}
 
-	// Get meter from global meter provider
-	// In production, configure a proper meter provider with exporters
-	meter := otel.Meter(serviceName)
+	exporter, err := otlp.NewMetricExporter(ctx, cfg.Exporter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+	}
+	res, err := otlp.Resource(ctx, serviceName, cfg.ServiceVersion, cfg.ResourceAttributes)
+	if err != nil {
+		return nil, err
+	}
+	interval := cfg.ExportInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(provider)
 
 	return &Meter{
-		meter: meter,
+		meter:    provider.Meter(serviceName),
+		provider: provider,
 	}, nil
 }
 
+// Shutdown flushes pending metrics and stops the exporter
+func (m *Meter) Shutdown(ctx context.Context) error {
+	if m.provider != nil {
+		return m.provider.Shutdown(ctx)
+	}
+	return nil
+}
+
 // GetMeter returns the underlying meter
 func (m *Meter) GetMeter() metric.Meter {
 	return m.meter
@@ -0,0 +1,142 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp builds the OTLP exporters and the resource shared by tracing and metrics.
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Protocols accepted in OTEL_EXPORTER_OTLP_PROTOCOL
+const (
+	ProtocolHTTP = "http/protobuf"
+	ProtocolGRPC = "grpc"
+)
+
+// Config selects the collector that traces and metrics are exported to
+type Config struct {
+	// Endpoint is the collector's base URL, such as https://otel.example.com:4318; the
+	// HTTP protocol appends /v1/traces and /v1/metrics. An http URL disables TLS.
+	// Empty uses the exporter default, localhost on the protocol's port.
+	Endpoint string
+	Protocol string
+	// Headers are sent with every export, typically an API key for a hosted backend
+	Headers map[string]string
+}
+
+// target splits Endpoint into host:port and base path, and reports whether TLS is disabled
+func (c Config) target() (host, basePath string, insecure bool, err error) {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", false, fmt.Errorf("invalid OTLP endpoint %q: must be an http or https URL", c.Endpoint)
+	}
+	return u.Host, u.Path, u.Scheme == "http", nil
+}
+
+// NewTraceExporter creates a span exporter for cfg
+func NewTraceExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Protocol {
+	case ProtocolGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			host, _, insecure, err := cfg.target()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracegrpc.WithEndpoint(host))
+			if insecure {
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			}
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case ProtocolHTTP, "":
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			host, basePath, insecure, err := cfg.target()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracehttp.WithEndpoint(host), otlptracehttp.WithURLPath(path.Join("/", basePath, "v1/traces")))
+			if insecure {
+				opts = append(opts, otlptracehttp.WithInsecure())
+			}
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unsupported OTLP protocol %q", cfg.Protocol)
+}
+
+// NewMetricExporter creates a metric exporter for cfg
+func NewMetricExporter(ctx context.Context, cfg Config) (sdkmetric.Exporter, error) {
+	switch cfg.Protocol {
+	case ProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			host, _, insecure, err := cfg.target()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(host))
+			if insecure {
+				opts = append(opts, otlpmetricgrpc.WithInsecure())
+			}
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case ProtocolHTTP, "":
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			host, basePath, insecure, err := cfg.target()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetrichttp.WithEndpoint(host), otlpmetrichttp.WithURLPath(path.Join("/", basePath, "v1/metrics")))
+			if insecure {
+				opts = append(opts, otlpmetrichttp.WithInsecure())
+			}
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unsupported OTLP protocol %q", cfg.Protocol)
+}
+
+// Resource describes this process to the backend. attrs, such as deployment.environment,
+// are added to the service name and version.
+func Resource(ctx context.Context, serviceName, serviceVersion string, attrs map[string]string) (*resource.Resource, error) {
+	kvs := []attribute.KeyValue{
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+	}
+	for key, value := range attrs {
+		kvs = append(kvs, attribute.String(key, value))
+	}
+	res, err := resource.New(ctx, resource.WithAttributes(kvs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	return res, nil
+}
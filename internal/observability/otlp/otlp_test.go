@@ -0,0 +1,89 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TestPurpose: Validates that the HTTP exporters send to the configured collector path with the configured headers.
+// Scope: Unit Test
+// Security: Telemetry delivery (hosted backends authenticate exports with a header such as an API key)
+// Expected: Traces and metrics are posted to <endpoint path>/v1/traces and /v1/metrics carrying the header; an invalid endpoint or protocol is rejected.
+// Test Case ID: OTL-01
+func TestOTLP_HTTPExporters(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]string{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path] = r.Header.Get("X-Api-Key")
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	ctx := context.Background()
+	cfg := Config{Endpoint: collector.URL + "/otel", Protocol: ProtocolHTTP, Headers: map[string]string{"X-Api-Key": "secret"}}
+
+	traceExporter, err := NewTraceExporter(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(traceExporter))
+	_, span := tracerProvider.Tracer("test").Start(ctx, "span")
+	span.End()
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	metricExporter, err := NewMetricExporter(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+	counter, err := meterProvider.Meter("test").Int64Counter("requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Add(ctx, 1)
+	if err := meterProvider.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"/otel/v1/traces", "/otel/v1/metrics"} {
+		header, ok := received[path]
+		if !ok {
+			t.Errorf("expected an export to %s, got %v", path, received)
+		} else if header != "secret" {
+			t.Errorf("expected the API key header on %s, got %q", path, header)
+		}
+	}
+
+	if _, err := NewTraceExporter(ctx, Config{Endpoint: "collector:4318"}); err == nil {
+		t.Error("expected an endpoint without scheme to be rejected")
+	}
+	if _, err := NewMetricExporter(ctx, Config{Protocol: "thrift"}); err == nil {
+		t.Error("expected an unknown protocol to be rejected")
+	}
+}
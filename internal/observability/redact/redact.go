@@ -0,0 +1,157 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact keeps secrets out of API responses and logs. Secret-bearing fields are recognized
+// by name: passwords, client and webhook secrets, OAuth2 tokens, PKCE verifiers, private keys,
+// and the stored hashes of any of them.
+package redact
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Marker replaces a redacted value
+const Marker = "[REDACTED]"
+
+// sensitiveSuffixes end the normalized names of secret-bearing fields, e.g. client_secret_hash
+var sensitiveSuffixes = []string{"password", "secret", "hash", "token", "verifier", "privatekey", "apikey"}
+
+// normalize lowercases key and drops separators, so clientSecret and client_secret compare equal
+func normalize(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// SensitiveKey reports whether a field or attribute named key holds a secret
+func SensitiveKey(key string) bool {
+	k := normalize(key)
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(k, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveValue reports whether a string value would disclose something; empty values and values
+// that were already redacted do not
+func sensitiveValue(v string) bool {
+	return v != "" && !strings.EqualFold(v, Marker)
+}
+
+// ScanJSON returns the paths (e.g. $.clients[0].secret_hash) of the non-empty string fields of doc
+// whose names are sensitive, except the designated fields, which are meant to carry a secret. A
+// document that is not JSON has none.
+func ScanJSON(doc []byte, designated ...string) []string {
+	var v any
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return nil
+	}
+	allowed := make([]string, len(designated))
+	for i, d := range designated {
+		allowed[i] = normalize(d)
+	}
+	var found []string
+	scan(v, "$", allowed, &found)
+	slices.Sort(found)
+	return found
+}
+
+func scan(v any, path string, allowed []string, found *[]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := path + "." + key
+			if s, ok := child.(string); ok {
+				if SensitiveKey(key) && sensitiveValue(s) && !slices.Contains(allowed, normalize(key)) {
+					*found = append(*found, childPath)
+				}
+				continue
+			}
+			scan(child, childPath, allowed, found)
+		}
+	case []any:
+		for i, child := range v {
+			scan(child, path+"["+strconv.Itoa(i)+"]", allowed, found)
+		}
+	}
+}
+
+// Attr returns a with the string values of sensitive attributes redacted, descending into groups
+func Attr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if SensitiveKey(a.Key) && sensitiveValue(a.Value.String()) {
+			a.Value = slog.StringValue(Marker)
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = Attr(ga)
+		}
+		a.Value = slog.GroupValue(attrs...)
+	}
+	return a
+}
+
+// Handler redacts sensitive attributes (Attr) before passing records to the wrapped handler
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler wraps next
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes a copy of r with its sensitive attributes redacted
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(Attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs redacts attrs once, when they are bound to the logger
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = Attr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup opens a group on the wrapped handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
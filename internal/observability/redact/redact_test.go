@@ -0,0 +1,78 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestPurpose: Validates the detection of secrets in JSON documents.
+// Scope: Unit Test
+// Security: Credential disclosure (a stored hash or token in a response is found wherever it is nested)
+// Expected: Non-empty secret-named string fields are reported by path unless designated; empty, redacted and non-string values are not.
+// Test Case ID: SEC-12
+func TestRedact_ScanJSON(t *testing.T) {
+	doc := []byte(`{
+		"client_id": "c1",
+		"client_secret": "issued",
+		"clients": [{"client_id": "c2", "ClientSecretHash": "abc"}, {"token_type": "Bearer", "secret_hash": ""}],
+		"user": {"email": "a@example.com", "password": "[REDACTED]", "profile": {"refresh_token": "rt"}},
+		"has_password": true,
+		"token_count": 3
+	}`)
+
+	got := ScanJSON(doc, "client_secret")
+	want := []string{"$.clients[0].ClientSecretHash", "$.user.profile.refresh_token"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := ScanJSON(doc); !slices.Contains(got, "$.client_secret") {
+		t.Errorf("expected an undesignated client_secret to be reported, got %v", got)
+	}
+	if got := ScanJSON([]byte("not json")); got != nil {
+		t.Errorf("expected nothing in a non-JSON document, got %v", got)
+	}
+}
+
+// TestPurpose: Validates that the log handler redacts secret-bearing attributes.
+// Scope: Unit Test
+// Security: Credential disclosure (secrets passed to a logger never reach the log output)
+// Expected: Secret-named string attributes, also inside groups and bound with With, are replaced by the marker; other attributes are kept.
+// Test Case ID: SEC-13
+func TestRedact_Handler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil))).With("clientSecret", "bound")
+	logger.Info("login",
+		"email", "a@example.com",
+		"password", "hunter2",
+		slog.Group("oauth", "refresh_token", "rt", "token_type", "Bearer"),
+	)
+
+	out := buf.String()
+	for _, leaked := range []string{"bound", "hunter2", "rt "} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("expected %q to be redacted: %s", leaked, out)
+		}
+	}
+	for _, kept := range []string{"email=a@example.com", "password=" + Marker, "oauth.refresh_token=" + Marker, "oauth.token_type=Bearer"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("expected %q in the output: %s", kept, out)
+		}
+	}
+}
@@ -18,12 +18,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/opentrusty/opentrusty/internal/observability/otlp"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -32,7 +30,11 @@ type Config struct {
 	Enabled        bool
 	ServiceName    string
 	ServiceVersion string
-	SamplingRate   float64
+	// SamplingRate is the fraction of new traces recorded; requests that arrive with a
+	// sampled trace context are always recorded
+	SamplingRate       float64
+	ResourceAttributes map[string]string
+	Exporter           otlp.Config
 }
 
 // Tracer wraps OpenTelemetry tracer
@@ -49,23 +51,14 @@ func New(ctx context.Context, cfg Config) (*Tracer, error) {
 		}, nil
 	}
 
-	// Create OTLP HTTP exporter using standard environment variables
-	exporter, err := otlptracehttp.New(ctx)
+	exporter, err := otlp.NewTraceExporter(ctx, cfg.Exporter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
 	}
 
-	// Create resource with service information
-	// Note: OTEL_RESOURCE_ATTRIBUTES env var is also handled automatically by SDK if we use WithFromEnv()
-	// But keeping explicit service name/version here is fine as it merges.
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName(cfg.ServiceName),
-			semconv.ServiceVersion(cfg.ServiceVersion),
-		),
-	)
+	res, err := otlp.Resource(ctx, cfg.ServiceName, cfg.ServiceVersion, cfg.ResourceAttributes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create resource: %w", err)
+		return nil, err
 	}
 
 	// Create trace provider
@@ -77,7 +70,7 @@ func New(ctx context.Context, cfg Config) (*Tracer, error) {
 	provider := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(samplingRate)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRate))),
 	)
 
 	otel.SetTracerProvider(provider)
@@ -15,6 +15,7 @@
 package oidc
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"testing"
@@ -36,7 +37,7 @@ func BenchmarkService_GenerateIDToken(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := s.GenerateIDToken(userID, tenantID, clientID, nonce, accessToken)
+		_, err := s.GenerateIDToken(context.Background(), userID, tenantID, clientID, nonce, accessToken, nil)
 		if err != nil {
 			b.Fatal(err)
 		}
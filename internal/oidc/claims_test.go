@@ -15,6 +15,7 @@
 package oidc_test
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
@@ -42,10 +43,10 @@ func TestOIDC_Claims_SubClaimStability_SameTenantAndUserProduceSameSub(t *testin
 	clientID := id.NewUUIDv7()
 
 	// Generate two tokens with the same tenant+user
-	token1, err := svc.GenerateIDToken(userID, tenantID, clientID, "", "access-token-1")
+	token1, err := svc.GenerateIDToken(context.Background(), userID, tenantID, clientID, "", "access-token-1", nil)
 	require.NoError(t, err)
 
-	token2, err := svc.GenerateIDToken(userID, tenantID, clientID, "", "access-token-2")
+	token2, err := svc.GenerateIDToken(context.Background(), userID, tenantID, clientID, "", "access-token-2", nil)
 	require.NoError(t, err)
 
 	// Parse tokens to extract sub claims
@@ -70,10 +71,10 @@ func TestOIDC_Claims_SubClaimStability_DifferentTenantProducesDifferentSub(t *te
 	userID := id.NewUUIDv7()
 	clientID := id.NewUUIDv7()
 
-	tokenA, err := svc.GenerateIDToken(userID, tenantA, clientID, "", "access-token")
+	tokenA, err := svc.GenerateIDToken(context.Background(), userID, tenantA, clientID, "", "access-token", nil)
 	require.NoError(t, err)
 
-	tokenB, err := svc.GenerateIDToken(userID, tenantB, clientID, "", "access-token")
+	tokenB, err := svc.GenerateIDToken(context.Background(), userID, tenantB, clientID, "", "access-token", nil)
 	require.NoError(t, err)
 
 	subA := extractClaim(t, svc, tokenA, "sub")
@@ -96,10 +97,10 @@ func TestOIDC_Claims_SubClaimStability_DifferentUserProducesDifferentSub(t *test
 	userB := id.NewUUIDv7()
 	clientID := id.NewUUIDv7()
 
-	tokenA, err := svc.GenerateIDToken(userA, tenantID, clientID, "", "access-token")
+	tokenA, err := svc.GenerateIDToken(context.Background(), userA, tenantID, clientID, "", "access-token", nil)
 	require.NoError(t, err)
 
-	tokenB, err := svc.GenerateIDToken(userB, tenantID, clientID, "", "access-token")
+	tokenB, err := svc.GenerateIDToken(context.Background(), userB, tenantID, clientID, "", "access-token", nil)
 	require.NoError(t, err)
 
 	subA := extractClaim(t, svc, tokenA, "sub")
@@ -122,7 +123,7 @@ func TestOIDC_Claims_NoncePropagation_NonceIsIncludedWhenProvided(t *testing.T)
 	clientID := id.NewUUIDv7()
 	expectedNonce := "random-nonce-12345"
 
-	token, err := svc.GenerateIDToken(userID, tenantID, clientID, expectedNonce, "access-token")
+	token, err := svc.GenerateIDToken(context.Background(), userID, tenantID, clientID, expectedNonce, "access-token", nil)
 	require.NoError(t, err)
 
 	nonce := extractClaim(t, svc, token, "nonce")
@@ -140,7 +141,7 @@ func TestNoncePropagation_NonceIsOmittedWhenEmpty(t *testing.T) {
 	userID := id.NewUUIDv7()
 	clientID := id.NewUUIDv7()
 
-	token, err := svc.GenerateIDToken(userID, tenantID, clientID, "", "access-token")
+	token, err := svc.GenerateIDToken(context.Background(), userID, tenantID, clientID, "", "access-token", nil)
 	require.NoError(t, err)
 
 	// Parse without validation to check claims map
@@ -166,7 +167,7 @@ func TestOIDC_Claims_AtHashCorrectness_AtHashIsCorrectlyComputed(t *testing.T) {
 	clientID := id.NewUUIDv7()
 	accessToken := "test-access-token-for-hash-computation"
 
-	token, err := svc.GenerateIDToken(userID, tenantID, clientID, "", accessToken)
+	token, err := svc.GenerateIDToken(context.Background(), userID, tenantID, clientID, "", accessToken, nil)
 	require.NoError(t, err)
 
 	// Compute expected at_hash
@@ -189,7 +190,7 @@ func TestAtHashCorrectness_AtHashIsOmittedWhenNoAccessToken(t *testing.T) {
 	userID := id.NewUUIDv7()
 	clientID := id.NewUUIDv7()
 
-	token, err := svc.GenerateIDToken(userID, tenantID, clientID, "", "")
+	token, err := svc.GenerateIDToken(context.Background(), userID, tenantID, clientID, "", "", nil)
 	require.NoError(t, err)
 
 	// Parse without validation to check claims map
@@ -212,7 +213,7 @@ func TestOIDC_Claims_IssuerMatch(t *testing.T) {
 	svc, err := oidc.NewService(expectedIssuer)
 	require.NoError(t, err)
 
-	token, err := svc.GenerateIDToken(id.NewUUIDv7(), id.NewUUIDv7(), id.NewUUIDv7(), "", "token")
+	token, err := svc.GenerateIDToken(context.Background(), id.NewUUIDv7(), id.NewUUIDv7(), id.NewUUIDv7(), "", "token", nil)
 	require.NoError(t, err)
 
 	iss := extractClaim(t, svc, token, "iss")
@@ -230,13 +231,65 @@ func TestOIDC_Claims_AudienceMatch(t *testing.T) {
 	require.NoError(t, err)
 
 	clientID := id.NewUUIDv7()
-	token, err := svc.GenerateIDToken(id.NewUUIDv7(), id.NewUUIDv7(), clientID, "", "token")
+	token, err := svc.GenerateIDToken(context.Background(), id.NewUUIDv7(), id.NewUUIDv7(), clientID, "", "token", nil)
 	require.NoError(t, err)
 
 	aud := extractClaim(t, svc, token, "aud")
 	assert.Equal(t, clientID, aud, "aud claim must match client ID")
 }
 
+// TestPurpose: Verifies that user claims released by custom scopes are added to the ID token without replacing registered claims.
+// Scope: Unit Test
+// Security: Token Integrity (a profile value cannot forge sub, aud or iss)
+// Expected: email is included while sub and aud keep their computed values.
+// Test Case ID: OIC-10
+func TestOIDC_Claims_ScopeClaims_CannotOverrideRegisteredClaims(t *testing.T) {
+	svc, err := oidc.NewService("https://auth.example.com")
+	require.NoError(t, err)
+
+	tenantID := id.NewUUIDv7()
+	userID := id.NewUUIDv7()
+	clientID := id.NewUUIDv7()
+	token, err := svc.GenerateIDToken(context.Background(), userID, tenantID, clientID, "", "token", map[string]any{
+		"email": "alice@example.com",
+		"sub":   "forged",
+		"aud":   "other-client",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "alice@example.com", extractClaim(t, svc, token, "email"))
+	assert.Equal(t, oidc.Subject(tenantID, userID), extractClaim(t, svc, token, "sub"))
+	assert.Equal(t, clientID, extractClaim(t, svc, token, "aud"))
+}
+
+// TestPurpose: Verifies that an ID token for several clients carries an aud array and names the requesting client in azp.
+// Scope: Unit Test
+// Security: Intended Audience Verification (relying parties check azp when aud has several values)
+// Expected: aud lists the clients with the requesting one first and azp names it; a single audience keeps a string aud and no azp.
+// Test Case ID: OIC-11
+// RelatedSpecs: OIDC Core Section 2 (aud, azp)
+func TestOIDC_Claims_MultipleAudiences(t *testing.T) {
+	svc, err := oidc.NewService("https://auth.example.com")
+	require.NoError(t, err)
+
+	token, err := svc.GenerateIDTokenForAudience(context.Background(), id.NewUUIDv7(), id.NewUUIDv7(), []string{"web", "mobile"}, "", "token", nil)
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	claims := parsed.Claims.(jwt.MapClaims)
+	assert.Equal(t, []any{"web", "mobile"}, claims["aud"])
+	assert.Equal(t, "web", claims["azp"])
+
+	token, err = svc.GenerateIDTokenForAudience(context.Background(), id.NewUUIDv7(), id.NewUUIDv7(), []string{"web"}, "", "token", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "web", extractClaim(t, svc, token, "aud"))
+	assert.Empty(t, extractClaim(t, svc, token, "azp"))
+
+	_, err = svc.GenerateIDTokenForAudience(context.Background(), id.NewUUIDv7(), id.NewUUIDv7(), nil, "", "token", nil)
+	assert.Error(t, err)
+}
+
 // extractClaim is a helper that parses a JWT and extracts a string claim.
 func extractClaim(t *testing.T, svc *oidc.Service, tokenString, claimName string) string {
 	t.Helper()
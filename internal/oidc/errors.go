@@ -14,7 +14,11 @@
 
 package oidc
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
 
 // Error represents a protocol-level OIDC error (OIDC Core).
 // Fix for B-PROTOCOL-01 (Rule 4.2 - Compliance)
@@ -29,6 +33,11 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("oidc error: %s (%s)", e.Code, e.Description)
 }
 
+// ErrorCode classifies the error; every OIDC error is a rejected request
+func (e *Error) ErrorCode() apperr.Code {
+	return apperr.CodeInvalidArgument
+}
+
 // OIDC Standard Error Codes
 const (
 	ErrInteractionRequired      = "interaction_required"
@@ -0,0 +1,104 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import "slices"
+
+// Capabilities are the protocol features of the authorization server advertised in its metadata.
+// They are set from the OAuth2 service, so that the metadata follows what the endpoints accept.
+type Capabilities struct {
+	GrantTypes               []string
+	TokenEndpointAuthMethods []string // Also used at the revocation and introspection endpoints
+	CodeChallengeMethods     []string
+	RequestObjectSigningAlgs []string // Empty when request objects (RFC 9101) are refused
+}
+
+func defaultCapabilities() Capabilities {
+	return Capabilities{
+		GrantTypes:               []string{"authorization_code", "refresh_token"},
+		TokenEndpointAuthMethods: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethods:     []string{"S256", "plain"},
+		RequestObjectSigningAlgs: []string{"RS256", "PS256", "ES256"},
+	}
+}
+
+// AuthorizationServerMetadata represents OAuth 2.0 authorization server metadata (RFC 8414 Section 2)
+type AuthorizationServerMetadata struct {
+	Issuer                                    string   `json:"issuer"`
+	AuthorizationEndpoint                     string   `json:"authorization_endpoint"`
+	TokenEndpoint                             string   `json:"token_endpoint"`
+	JWKSURI                                   string   `json:"jwks_uri"`
+	ScopesSupported                           []string `json:"scopes_supported"`
+	ResponseTypesSupported                    []string `json:"response_types_supported"`
+	ResponseModesSupported                    []string `json:"response_modes_supported"`
+	GrantTypesSupported                       []string `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported         []string `json:"token_endpoint_auth_methods_supported"`
+	RevocationEndpoint                        string   `json:"revocation_endpoint"`
+	RevocationEndpointAuthMethodsSupported    []string `json:"revocation_endpoint_auth_methods_supported"`
+	IntrospectionEndpoint                     string   `json:"introspection_endpoint"`
+	IntrospectionEndpointAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported             []string `json:"code_challenge_methods_supported"`
+	RequestParameterSupported                 bool     `json:"request_parameter_supported"`
+	RequestURIParameterSupported              bool     `json:"request_uri_parameter_supported"`
+	RequireRequestURIRegistration             bool     `json:"require_request_uri_registration"`
+	RequestObjectSigningAlgValuesSupported    []string `json:"request_object_signing_alg_values_supported,omitempty"`
+}
+
+// SetCapabilities replaces the advertised protocol features and drops the cached metadata documents
+func (s *Service) SetCapabilities(c Capabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities = c
+	s.discovery = nil
+	s.asMetadata = nil
+}
+
+// AuthorizationServerDocument returns the serialized authorization server metadata
+func (s *Service) AuthorizationServerDocument() (*Document, error) {
+	return s.document(&s.asMetadata, func() any { return s.authorizationServerMetadataLocked() })
+}
+
+// GetAuthorizationServerMetadata returns the OAuth 2.0 authorization server metadata (RFC 8414 Section 3)
+func (s *Service) GetAuthorizationServerMetadata() AuthorizationServerMetadata {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.authorizationServerMetadataLocked()
+}
+
+// authorizationServerMetadataLocked builds the authorization server metadata from the discovery
+// metadata, so that the two never disagree; the caller holds s.mu
+func (s *Service) authorizationServerMetadataLocked() AuthorizationServerMetadata {
+	d := s.discoveryMetadataLocked()
+	return AuthorizationServerMetadata{
+		Issuer:                                    d.Issuer,
+		AuthorizationEndpoint:                     d.AuthorizationEndpoint,
+		TokenEndpoint:                             d.TokenEndpoint,
+		JWKSURI:                                   d.JWKSURI,
+		ScopesSupported:                           d.ScopesSupported,
+		ResponseTypesSupported:                    d.ResponseTypesSupported,
+		ResponseModesSupported:                    d.ResponseModesSupported,
+		GrantTypesSupported:                       d.GrantTypesSupported,
+		TokenEndpointAuthMethodsSupported:         d.TokenEndpointAuthMethodsSupported,
+		RevocationEndpoint:                        d.RevocationEndpoint,
+		RevocationEndpointAuthMethodsSupported:    slices.Clone(d.TokenEndpointAuthMethodsSupported),
+		IntrospectionEndpoint:                     d.IntrospectionEndpoint,
+		IntrospectionEndpointAuthMethodsSupported: slices.Clone(d.TokenEndpointAuthMethodsSupported),
+		CodeChallengeMethodsSupported:             d.CodeChallengeMethodsSupported,
+		RequestParameterSupported:                 d.RequestParameterSupported,
+		RequestURIParameterSupported:              d.RequestURIParameterSupported,
+		RequireRequestURIRegistration:             d.RequireRequestURIRegistration,
+		RequestObjectSigningAlgValuesSupported:    d.RequestObjectSigningAlgValuesSupported,
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// TestPurpose: Validates that the authorization server metadata follows the configured capabilities and agrees with discovery.
+// Scope: Unit Test
+// Security: Accurate metadata (clients never pick a grant, authentication or PKCE method the server refuses)
+// Expected: Both documents list the same grant types, client authentication and PKCE methods; changing the capabilities rebuilds both; without request objects the request parameters are advertised as unsupported.
+// Test Case ID: OID-08
+// RelatedSpecs: RFC 8414 Section 2
+func TestOIDC_Service_AuthorizationServerMetadata(t *testing.T) {
+	issuer := "https://auth.opentrusty.org"
+	s, _ := NewService(issuer)
+
+	meta := s.GetAuthorizationServerMetadata()
+	if meta.Issuer != issuer || meta.TokenEndpoint != issuer+"/oauth2/token" || meta.RevocationEndpoint != issuer+"/oauth2/revoke" || meta.IntrospectionEndpoint != issuer+"/oauth2/introspect" {
+		t.Errorf("unexpected endpoints %+v", meta)
+	}
+	if !slices.Contains(meta.CodeChallengeMethodsSupported, "S256") || !meta.RequestParameterSupported {
+		t.Errorf("expected default capabilities, got %+v", meta)
+	}
+
+	before, _ := s.AuthorizationServerDocument()
+	discoveryBefore, _ := s.DiscoveryDocument()
+	s.SetCapabilities(Capabilities{
+		GrantTypes:               []string{"authorization_code"},
+		TokenEndpointAuthMethods: []string{"client_secret_basic"},
+		CodeChallengeMethods:     []string{"S256"},
+	})
+	after, _ := s.AuthorizationServerDocument()
+	discoveryAfter, _ := s.DiscoveryDocument()
+	if after.ETag == before.ETag || discoveryAfter.ETag == discoveryBefore.ETag {
+		t.Fatal("expected both documents to be rebuilt when the capabilities change")
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(after.Body, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc["request_object_signing_alg_values_supported"]; ok || doc["request_parameter_supported"] != false {
+		t.Errorf("expected request objects to be unsupported, got %s", after.Body)
+	}
+	discovery := s.GetDiscoveryMetadata()
+	meta = s.GetAuthorizationServerMetadata()
+	for _, pair := range [][2][]string{
+		{meta.GrantTypesSupported, discovery.GrantTypesSupported},
+		{meta.TokenEndpointAuthMethodsSupported, discovery.TokenEndpointAuthMethodsSupported},
+		{meta.RevocationEndpointAuthMethodsSupported, discovery.TokenEndpointAuthMethodsSupported},
+		{meta.CodeChallengeMethodsSupported, discovery.CodeChallengeMethodsSupported},
+	} {
+		if !slices.Equal(pair[0], pair[1]) || len(pair[0]) != 1 {
+			t.Errorf("expected the metadata to follow the capabilities, got %v and %v", pair[0], pair[1])
+		}
+	}
+}
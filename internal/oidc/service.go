@@ -15,21 +15,53 @@
 package oidc
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/observability/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Service handles OpenID Connect specific logic (Phase II.2)
 type Service struct {
-	issuer     string
+	issuer string
+
+	mu         sync.RWMutex
 	signingKey *rsa.PrivateKey
 	kid        string // Stable, deterministic Key ID
+	// Keys published in the JWKS; includes the signing key and keys still in their rotation grace period
+	published []*rsa.PublicKey
+	// Protocol features advertised in the metadata
+	capabilities Capabilities
+	// Serialized metadata and JWKS responses; built on first use and dropped when what they describe changes
+	discovery  *Document
+	asMetadata *Document
+	jwks       *Document
+}
+
+// Document is a serialized JSON response with a strong entity tag derived from its content
+type Document struct {
+	Body []byte
+	ETag string
+}
+
+func newDocument(v any) (*Document, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	return &Document{Body: body, ETag: `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`}, nil
 }
 
 // DiscoveryMetadata represents OIDC Discovery metadata (OIDC Discovery Section 3)
@@ -38,11 +70,39 @@ type DiscoveryMetadata struct {
 	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
 	TokenEndpoint                    string   `json:"token_endpoint"`
 	JWKSURI                          string   `json:"jwks_uri"`
+	RevocationEndpoint               string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint            string   `json:"introspection_endpoint"`
 	ResponseTypesSupported           []string `json:"response_types_supported"`
+	ResponseModesSupported           []string `json:"response_modes_supported"`
 	SubjectTypesSupported            []string `json:"subject_types_supported"`
 	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
 	ScopesSupported                  []string `json:"scopes_supported"`
 	GrantTypesSupported              []string `json:"grant_types_supported"`
+
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+
+	// JWT-secured authorization requests (RFC 9101 Section 10.5); request_uri must be registered by the client
+	RequestParameterSupported              bool     `json:"request_parameter_supported"`
+	RequestURIParameterSupported           bool     `json:"request_uri_parameter_supported"`
+	RequireRequestURIRegistration          bool     `json:"require_request_uri_registration"`
+	RequestObjectSigningAlgValuesSupported []string `json:"request_object_signing_alg_values_supported,omitempty"`
+}
+
+// TenantDiscoveryMetadata extends the discovery metadata with the custom scopes of one tenant, so
+// that its relying parties and consent screens learn what each scope means. The issuer and endpoints
+// are those of the server.
+type TenantDiscoveryMetadata struct {
+	DiscoveryMetadata
+	TenantID          string                      `json:"tenant_id"`
+	ClaimsSupported   []string                    `json:"claims_supported"`
+	ScopeDescriptions map[string]ScopeDescription `json:"scope_descriptions"` // Keyed by scope name
+}
+
+// ScopeDescription describes a custom scope in the tenant discovery metadata
+type ScopeDescription struct {
+	Description string   `json:"description"`
+	Claims      []string `json:"claims,omitempty"` // User claims the scope releases in the ID token
 }
 
 // JWK represents a JSON Web Key (RFC 7517)
@@ -68,69 +128,244 @@ func NewService(issuer string) (*Service, error) {
 		return nil, err
 	}
 
-	// T6 (Phase II.2): Stable, deterministic kid
-	// Generate kid using SHA-256 thumbprint of the N component (simplified)
-	nBytes := key.PublicKey.N.Bytes()
-	hash := sha256.Sum256(nBytes)
-	kid := base64.RawURLEncoding.EncodeToString(hash[:16]) // First 16 bytes is enough for kid
-
 	return &Service{
 		issuer:     issuer,
 		signingKey: key,
-		kid:        kid,
+		kid:        KeyID(&key.PublicKey),
+		published:  []*rsa.PublicKey{&key.PublicKey},
+
+		capabilities: defaultCapabilities(),
 	}, nil
 }
 
+// KeyID derives a stable, deterministic kid (T6, Phase II.2)
+func KeyID(key *rsa.PublicKey) string {
+	// Generate kid using SHA-256 thumbprint of the N component (simplified)
+	hash := sha256.Sum256(key.N.Bytes())
+	return base64.RawURLEncoding.EncodeToString(hash[:16]) // First 16 bytes is enough for kid
+}
+
+// RotateSigningKey replaces the signing key and publishes only its public key. ID tokens
+// signed afterwards carry the new kid and the cached JWKS document is rebuilt on its next use.
+func (s *Service) RotateSigningKey(key *rsa.PrivateKey) {
+	s.SetSigningKeys(key, nil)
+}
+
+// SetSigningKeys replaces the signing key and the set of published keys, for example after
+// loading them from the key store. The signing key is always published. The cached JWKS
+// document is kept when neither the signing kid nor the published kids changed.
+func (s *Service) SetSigningKeys(signing *rsa.PrivateKey, published []*rsa.PublicKey) {
+	kid := KeyID(&signing.PublicKey)
+	keys := []*rsa.PublicKey{&signing.PublicKey}
+	for _, pub := range published {
+		if KeyID(pub) != kid {
+			keys = append(keys, pub)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unchanged := s.kid == kid && sameKeyIDs(s.published, keys)
+	s.signingKey = signing
+	s.kid = kid
+	s.published = keys
+	if !unchanged {
+		s.jwks = nil
+	}
+}
+
+// KeyIDs returns the kid of the signing key and the kids of the published keys
+func (s *Service) KeyIDs() (signing string, published []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, pub := range s.published {
+		published = append(published, KeyID(pub))
+	}
+	return s.kid, published
+}
+
+func sameKeyIDs(a, b []*rsa.PublicKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if KeyID(a[i]) != KeyID(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// DiscoveryDocument returns the serialized discovery metadata
+func (s *Service) DiscoveryDocument() (*Document, error) {
+	return s.document(&s.discovery, func() any { return s.discoveryMetadataLocked() })
+}
+
+// JWKSDocument returns the serialized JWKS of the published keys
+func (s *Service) JWKSDocument() (*Document, error) {
+	return s.document(&s.jwks, func() any { return s.jwksLocked() })
+}
+
+// document returns *cached, building it from build while holding the write lock if needed
+func (s *Service) document(cached **Document, build func() any) (*Document, error) {
+	s.mu.RLock()
+	doc := *cached
+	s.mu.RUnlock()
+	if doc != nil {
+		return doc, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if *cached == nil {
+		d, err := newDocument(build())
+		if err != nil {
+			return nil, err
+		}
+		*cached = d
+	}
+	return *cached, nil
+}
+
 // GetDiscoveryMetadata returns the OIDC configuration (OIDC Discovery Section 4)
 func (s *Service) GetDiscoveryMetadata() DiscoveryMetadata {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.discoveryMetadataLocked()
+}
+
+// discoveryMetadataLocked builds the discovery metadata; the caller holds s.mu
+func (s *Service) discoveryMetadataLocked() DiscoveryMetadata {
+	c := s.capabilities
+	requestObjects := len(c.RequestObjectSigningAlgs) > 0
 	return DiscoveryMetadata{
 		Issuer:                           s.issuer,
 		AuthorizationEndpoint:            fmt.Sprintf("%s/oauth2/authorize", s.issuer),
 		TokenEndpoint:                    fmt.Sprintf("%s/oauth2/token", s.issuer),
 		JWKSURI:                          fmt.Sprintf("%s/jwks.json", s.issuer),
+		RevocationEndpoint:               fmt.Sprintf("%s/oauth2/revoke", s.issuer),
+		IntrospectionEndpoint:            fmt.Sprintf("%s/oauth2/introspect", s.issuer),
 		ResponseTypesSupported:           []string{"code"},
+		ResponseModesSupported:           []string{"query", "fragment"},
 		SubjectTypesSupported:            []string{"public"},
 		IDTokenSigningAlgValuesSupported: []string{"RS256"},
 		ScopesSupported:                  []string{"openid"},
-		GrantTypesSupported:              []string{"authorization_code", "refresh_token"},
+		GrantTypesSupported:              slices.Clone(c.GrantTypes),
+
+		TokenEndpointAuthMethodsSupported: slices.Clone(c.TokenEndpointAuthMethods),
+		CodeChallengeMethodsSupported:     slices.Clone(c.CodeChallengeMethods),
+
+		RequestParameterSupported:              requestObjects,
+		RequestURIParameterSupported:           requestObjects,
+		RequireRequestURIRegistration:          requestObjects,
+		RequestObjectSigningAlgValuesSupported: slices.Clone(c.RequestObjectSigningAlgs),
 	}
 }
 
+// TenantDiscoveryDocument serializes the discovery metadata extended with a tenant's custom scopes.
+// It is built on every call, as scopes change without notice to this service.
+func (s *Service) TenantDiscoveryDocument(tenantID string, scopes map[string]ScopeDescription) (*Document, error) {
+	meta := TenantDiscoveryMetadata{
+		DiscoveryMetadata: s.GetDiscoveryMetadata(),
+		TenantID:          tenantID,
+		ClaimsSupported:   []string{"aud", "exp", "iat", "iss", "sub"},
+		ScopeDescriptions: scopes,
+	}
+	names := make([]string, 0, len(scopes))
+	for name, scope := range scopes {
+		names = append(names, name)
+		for _, claim := range scope.Claims {
+			if !slices.Contains(meta.ClaimsSupported, claim) {
+				meta.ClaimsSupported = append(meta.ClaimsSupported, claim)
+			}
+		}
+	}
+	slices.Sort(names)
+	slices.Sort(meta.ClaimsSupported)
+	meta.ScopesSupported = append(meta.ScopesSupported, names...)
+	return newDocument(meta)
+}
+
 // GetJWKS returns the public keys in JWKS format (RFC 7517)
 func (s *Service) GetJWKS() JWKS {
-	pub := s.signingKey.PublicKey
-	n := base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
-	e := base64.RawURLEncoding.EncodeToString(bigIntToBytes(pub.E))
-
-	return JWKS{
-		Keys: []JWK{
-			{
-				Kty: "RSA",
-				Use: "sig",
-				Alg: "RS256",
-				Kid: s.kid,
-				N:   n,
-				E:   e,
-			},
-		},
-	}
-}
-
-// GenerateIDToken generates a signed id_token JWT (OIDC Core Section 2)
-func (s *Service) GenerateIDToken(userID, tenantID, clientID, nonce, accessToken string) (string, error) {
-	now := time.Now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jwksLocked()
+}
+
+// jwksLocked builds the JWKS; the caller holds s.mu
+func (s *Service) jwksLocked() JWKS {
+	return NewJWKS(s.published)
+}
+
+// NewJWKS builds a JWKS of RS256 signing keys, in the given order
+func NewJWKS(keys []*rsa.PublicKey) JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, pub := range keys {
+		jwks.Keys = append(jwks.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: KeyID(pub),
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(bigIntToBytes(pub.E)),
+		})
+	}
+	return jwks
+}
+
+// Subject returns the public subject identifier of a user: stable for the user within a tenant,
+// and not revealing the internal user ID
+func Subject(tenantID, userID string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%s", tenantID, userID)))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// Issuer returns the issuer identifier
+func (s *Service) Issuer() string {
+	return s.issuer
+}
 
-	subSource := fmt.Sprintf("%s:%s", tenantID, userID)
-	hash := sha256.Sum256([]byte(subSource))
-	sub := base64.RawURLEncoding.EncodeToString(hash[:])
+// registeredClaims are set by the server only (OIDC Core Section 2)
+var registeredClaims = []string{"iss", "sub", "aud", "exp", "iat", "nonce", "at_hash", "auth_time", "azp", "acr", "amr"}
 
-	claims := jwt.MapClaims{
-		"iss": s.issuer,
-		"sub": sub,
-		"aud": clientID,
-		"exp": now.Add(5 * time.Minute).Unix(),
-		"iat": now.Unix(),
+// GenerateIDToken generates a signed id_token JWT (OIDC Core Section 2). userClaims are added to
+// the token, such as those released by custom scopes; they cannot override the registered claims.
+func (s *Service) GenerateIDToken(ctx context.Context, userID, tenantID, clientID, nonce, accessToken string, userClaims map[string]any) (string, error) {
+	return s.GenerateIDTokenForAudience(ctx, userID, tenantID, []string{clientID}, nonce, accessToken, userClaims)
+}
+
+// GenerateIDTokenForAudience generates an id_token for several clients. The first audience is the
+// client the token is issued to; with more than one, aud is an array and azp names that client
+// (OIDC Core Section 2). Callers check that the other audiences may receive the token.
+func (s *Service) GenerateIDTokenForAudience(ctx context.Context, userID, tenantID string, audience []string, nonce, accessToken string, userClaims map[string]any) (string, error) {
+	if len(audience) == 0 {
+		return "", fmt.Errorf("id token needs an audience")
 	}
+	_, span := tracing.StartSpan(ctx, "oidc.GenerateIDToken", attribute.String("client_id", audience[0]))
+	idToken, err := s.generateIDToken(userID, tenantID, audience, nonce, accessToken, userClaims)
+	tracing.EndSpan(span, err)
+	return idToken, err
+}
+
+func (s *Service) generateIDToken(userID, tenantID string, audience []string, nonce, accessToken string, userClaims map[string]any) (string, error) {
+	now := time.Now()
+
+	claims := make(jwt.MapClaims, len(userClaims)+8)
+	for name, value := range userClaims {
+		if !slices.Contains(registeredClaims, name) {
+			claims[name] = value
+		}
+	}
+	claims["iss"] = s.issuer
+	claims["sub"] = Subject(tenantID, userID)
+	claims["aud"] = audience[0]
+	if len(audience) > 1 {
+		claims["aud"] = slices.Clone(audience)
+		claims["azp"] = audience[0]
+	}
+	claims["exp"] = now.Add(5 * time.Minute).Unix()
+	claims["iat"] = now.Unix()
 
 	// OIDC Core Section 3.1.2.1: Include nonce if provided
 	if nonce != "" {
@@ -149,10 +384,37 @@ func (s *Service) GenerateIDToken(userID, tenantID, clientID, nonce, accessToken
 
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 
+	s.mu.RLock()
+	key, kid := s.signingKey, s.kid
+	s.mu.RUnlock()
+
 	// Phase II.2: Include stable kid in header
-	token.Header["kid"] = s.kid
+	token.Header["kid"] = kid
 
-	return token.SignedString(s.signingKey)
+	return token.SignedString(key)
+}
+
+// ErrUnknownKeyID is returned by VerifyIDToken when no key matches the token's kid
+var ErrUnknownKeyID = apperr.New(apperr.CodeUnauthenticated, "token is signed with an unknown key")
+
+// VerifyIDToken checks the RS256 signature of an ID token against keys, indexed by kid,
+// and returns the token's kid and claims. Time claims are not validated, so expired
+// tokens can still be examined; callers check exp themselves.
+func VerifyIDToken(tokenString string, keys map[string]*rsa.PublicKey) (string, jwt.MapClaims, error) {
+	var kid string
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ = token.Header["kid"].(string)
+		key, ok := keys[kid]
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithoutClaimsValidation())
+	if err != nil {
+		return kid, nil, err
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	return kid, claims, nil
 }
 
 func bigIntToBytes(n int) []byte {
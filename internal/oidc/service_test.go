@@ -15,8 +15,13 @@
 package oidc
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -40,7 +45,7 @@ func TestOIDC_Service_GenerateIDToken(t *testing.T) {
 	nonce := "random-nonce"
 	accessToken := "raw-access-token"
 
-	tokenString, err := s.GenerateIDToken(userID, tenantID, clientID, nonce, accessToken)
+	tokenString, err := s.GenerateIDToken(context.Background(), userID, tenantID, clientID, nonce, accessToken, nil)
 	if err != nil {
 		t.Fatalf("failed to generate ID token: %v", err)
 	}
@@ -138,3 +143,161 @@ func TestOIDC_Service_GetJWKS(t *testing.T) {
 		t.Error("RSA public key components (N, E) missing")
 	}
 }
+
+// TestPurpose: Validates that rotating the signing key replaces the kid and the cached JWKS document.
+// Scope: Unit Test
+// Security: Key rotation (relying parties must see the new key; no stale key set is served)
+// Expected: Cached documents are reused until rotation; afterwards tokens and the JWKS use the new kid.
+// Test Case ID: OID-04
+// RelatedSpecs: RFC 7517, OIDC Core Section 10.1.1
+func TestOIDC_Service_RotateSigningKey(t *testing.T) {
+	s, _ := NewService("http://localhost")
+
+	jwks, err := s.JWKSDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	discovery, _ := s.DiscoveryDocument()
+	if again, _ := s.JWKSDocument(); again != jwks {
+		t.Error("expected the cached JWKS document to be reused")
+	}
+
+	oldKid := s.kid
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.RotateSigningKey(key)
+
+	rotated, _ := s.JWKSDocument()
+	if rotated.ETag == jwks.ETag || !strings.Contains(string(rotated.Body), s.kid) || s.kid == oldKid {
+		t.Errorf("expected a rebuilt JWKS with the new kid %s", s.kid)
+	}
+	if again, _ := s.DiscoveryDocument(); again != discovery {
+		t.Error("expected the discovery document to survive key rotation")
+	}
+
+	tokenString, _ := s.GenerateIDToken(context.Background(), "user", "tenant", "client", "", "", nil)
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	if err != nil || token.Header["kid"] != s.kid {
+		t.Errorf("expected a token signed with the new key, got %v", err)
+	}
+}
+
+// TestPurpose: Validates that keys in their rotation grace period stay published next to the signing key.
+// Scope: Unit Test
+// Security: Key rotation (tokens signed before a rotation must remain verifiable until they expire)
+// Expected: The JWKS lists the signing key first and the retiring key after it; reloading the same set keeps the cached document.
+// Test Case ID: OID-05
+// RelatedSpecs: RFC 7517, OIDC Core Section 10.1.1
+func TestOIDC_Service_SetSigningKeys(t *testing.T) {
+	s, _ := NewService("http://localhost")
+	oldKey := s.signingKey
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetSigningKeys(key, []*rsa.PublicKey{&key.PublicKey, &oldKey.PublicKey})
+
+	jwks := s.GetJWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 published keys, got %d", len(jwks.Keys))
+	}
+	if jwks.Keys[0].Kid != KeyID(&key.PublicKey) || jwks.Keys[1].Kid != KeyID(&oldKey.PublicKey) {
+		t.Errorf("expected the signing key first and the retiring key second, got %+v", jwks.Keys)
+	}
+
+	doc, _ := s.JWKSDocument()
+	s.SetSigningKeys(key, []*rsa.PublicKey{&key.PublicKey, &oldKey.PublicKey})
+	if again, _ := s.JWKSDocument(); again != doc {
+		t.Error("expected the cached JWKS document to survive reloading an unchanged key set")
+	}
+
+	s.SetSigningKeys(key, nil)
+	if again, _ := s.JWKSDocument(); again == doc || len(s.GetJWKS().Keys) != 1 {
+		t.Error("expected the JWKS to drop the retired key")
+	}
+}
+
+// TestPurpose: Validates ID token verification against a set of published keys, including expired tokens.
+// Scope: Unit Test
+// Security: Token signature verification (tokens signed by unknown keys or with other algorithms are rejected)
+// Expected: A token signed by a known key verifies even after rotation; unknown keys and alg=none fail.
+// Test Case ID: OID-06
+// RelatedSpecs: OIDC Core Section 3.1.3.7, RFC 7515
+func TestOIDC_VerifyIDToken(t *testing.T) {
+	s, _ := NewService("http://localhost")
+	signing := s.signingKey
+	tokenString, err := s.GenerateIDToken(context.Background(), "user", "tenant", "client", "n-1", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string]*rsa.PublicKey{KeyID(&signing.PublicKey): &signing.PublicKey}
+	kid, claims, err := VerifyIDToken(tokenString, keys)
+	if err != nil || kid != KeyID(&signing.PublicKey) || claims["nonce"] != "n-1" || claims["aud"] != "client" {
+		t.Fatalf("expected the token to verify, got kid %q claims %v err %v", kid, claims, err)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	s.RotateSigningKey(other)
+	if _, _, err := VerifyIDToken(tokenString, keys); err != nil {
+		t.Errorf("expected a token signed before rotation to verify with its key, got %v", err)
+	}
+	if _, _, err := VerifyIDToken(tokenString, map[string]*rsa.PublicKey{}); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("expected ErrUnknownKeyID, got %v", err)
+	}
+
+	expired := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})
+	expired.Header["kid"] = KeyID(&signing.PublicKey)
+	expiredString, _ := expired.SignedString(signing)
+	if _, claims, err := VerifyIDToken(expiredString, keys); err != nil || claims["exp"] == nil {
+		t.Errorf("expected an expired token to verify for inspection, got %v", err)
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "x"})
+	unsigned.Header["kid"] = KeyID(&signing.PublicKey)
+	unsignedString, _ := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, _, err := VerifyIDToken(unsignedString, keys); err == nil {
+		t.Error("expected alg=none to be rejected")
+	}
+}
+
+// TestPurpose: Validates that discovery metadata and ID tokens use the configured issuer consistently.
+// Scope: Unit Test
+// Security: Issuer binding (relying parties reject tokens whose iss differs from the discovered issuer)
+// Expected: Every discovered endpoint is under the issuer and ID tokens carry it as iss.
+// Test Case ID: OID-07
+// RelatedSpecs: OIDC Discovery Section 3 & 4.3
+func TestOIDC_Service_IssuerConsistency(t *testing.T) {
+	issuer := "https://auth.example.com/tenant-a"
+	s, err := NewService(issuer)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	meta := s.GetDiscoveryMetadata()
+	if meta.Issuer != issuer {
+		t.Errorf("expected issuer %s, got %s", issuer, meta.Issuer)
+	}
+	for _, endpoint := range []string{meta.AuthorizationEndpoint, meta.TokenEndpoint, meta.JWKSURI, meta.RevocationEndpoint, meta.IntrospectionEndpoint} {
+		if !strings.HasPrefix(endpoint, issuer+"/") {
+			t.Errorf("endpoint %s is not under the issuer", endpoint)
+		}
+	}
+
+	tokenString, err := s.GenerateIDToken(context.Background(), "user-1", "tenant-1", "client-1", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to generate ID token: %v", err)
+	}
+	_, claims, err := VerifyIDToken(tokenString, map[string]*rsa.PublicKey{s.kid: &s.signingKey.PublicKey})
+	if err != nil {
+		t.Fatalf("failed to verify ID token: %v", err)
+	}
+	if claims["iss"] != issuer {
+		t.Errorf("expected iss %s, got %v", issuer, claims["iss"])
+	}
+}
@@ -0,0 +1,1081 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openapi generates an OpenAPI 3.1 document from the swag-style
+// annotations (@Summary, @Param, @Router, ...) on HTTP handlers.
+//
+// Only the annotation subset used by this repository is supported. Anything
+// the generator cannot describe is reported as an error rather than dropped,
+// so a stale or mistyped annotation fails generation instead of producing a
+// misleading document.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Config controls document generation.
+type Config struct {
+	// Dir is the directory of the annotated handler package.
+	Dir string
+	// Routes lists the routes the server mounts, as "METHOD /pattern". Annotated
+	// paths are relative to @BasePath unless the route is only mounted at the
+	// root (e.g. /health), and operations whose route is not mounted are left
+	// out. When empty, every annotated operation is included under @BasePath.
+	Routes []string
+	// Unmounted, if set, is called for each operation left out because its
+	// route is not mounted.
+	Unmounted func(operationID, route string)
+}
+
+// Generate reads the annotations in cfg.Dir and returns the OpenAPI document
+// as indented JSON. The output is deterministic so it can be committed and
+// compared in CI.
+func Generate(cfg Config) ([]byte, error) {
+	root, module, err := findModule(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		fset:      token.NewFileSet(),
+		root:      root,
+		module:    module,
+		pkgs:      make(map[string]*pkg),
+		unmounted: cfg.Unmounted,
+		doc: &document{
+			OpenAPI: Version,
+			Paths:   make(map[string]pathItem),
+			Components: components{
+				Schemas:         make(map[string]*schema),
+				SecuritySchemes: make(map[string]securityScheme),
+			},
+		},
+	}
+	if len(cfg.Routes) > 0 {
+		g.routes = make(map[string]bool, len(cfg.Routes))
+		for _, r := range cfg.Routes {
+			method, pattern, ok := strings.Cut(r, " ")
+			if !ok {
+				return nil, fmt.Errorf("invalid route %q", r)
+			}
+			g.routes[strings.ToUpper(method)+" "+normalizePath(pattern)] = true
+		}
+	}
+
+	dir, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	p, err := g.load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.generalInfo(p); err != nil {
+		return nil, err
+	}
+	for _, f := range p.files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			if err := g.operation(p, f, fn); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", g.fset.Position(fn.Pos()), fn.Name.Name, err)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g.doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       info                `json:"info"`
+	Servers    []server            `json:"servers,omitempty"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components components          `json:"components"`
+}
+
+type info struct {
+	Title          string   `json:"title"`
+	Description    string   `json:"description,omitempty"`
+	TermsOfService string   `json:"termsOfService,omitempty"`
+	Contact        *contact `json:"contact,omitempty"`
+	License        *license `json:"license,omitempty"`
+	Version        string   `json:"version"`
+}
+
+type contact struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type license struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type server struct {
+	URL string `json:"url"`
+}
+
+// pathItem maps a lower-case HTTP method to its operation.
+type pathItem map[string]*operation
+
+type operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type components struct {
+	Schemas         map[string]*schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes,omitempty"`
+}
+
+type securityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Example              any                `json:"example,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+type generator struct {
+	fset      *token.FileSet
+	root      string // module root directory
+	module    string // module path
+	pkgs      map[string]*pkg
+	routes    map[string]bool
+	unmounted func(operationID, route string)
+	doc       *document
+
+	basePath string
+	host     string
+}
+
+// pkg is a parsed package of the module.
+type pkg struct {
+	name  string
+	files []*ast.File
+	types map[string]typeDecl
+}
+
+type typeDecl struct {
+	spec *ast.TypeSpec
+	doc  string
+	file *ast.File
+	pkg  *pkg
+}
+
+// findModule returns the root directory and path of the module containing dir.
+func findModule(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		data, err := os.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			for line := range strings.Lines(string(data)) {
+				if mod, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					return d, strings.Trim(strings.TrimSpace(mod), `"`), nil
+				}
+			}
+			return "", "", fmt.Errorf("%s: no module directive", filepath.Join(d, "go.mod"))
+		}
+		if filepath.Dir(d) == d {
+			return "", "", fmt.Errorf("no go.mod found above %s", dir)
+		}
+	}
+}
+
+// load parses the non-test Go files in dir.
+func (g *generator) load(dir string) (*pkg, error) {
+	if p, ok := g.pkgs[dir]; ok {
+		return p, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	p := &pkg{types: make(map[string]typeDecl)}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		p.name = f.Name.Name
+		p.files = append(p.files, f)
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+				p.types[ts.Name.Name] = typeDecl{spec: ts, doc: commentText(doc), file: f, pkg: p}
+			}
+		}
+	}
+	if len(p.files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	g.pkgs[dir] = p
+	return p, nil
+}
+
+// generalInfo reads the API-level annotations (@title, @host, ...) from the
+// comments that are not attached to a function.
+func (g *generator) generalInfo(p *pkg) error {
+	d := g.doc
+	var scheme string
+	for _, f := range p.files {
+		funcDocs := make(map[*ast.CommentGroup]bool)
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Doc != nil {
+				funcDocs[fn.Doc] = true
+			}
+		}
+		for _, cg := range f.Comments {
+			if funcDocs[cg] {
+				continue
+			}
+			for _, c := range cg.List {
+				key, value, ok := annotation(c.Text)
+				if !ok {
+					continue
+				}
+				switch strings.ToLower(key) {
+				case "title":
+					d.Info.Title = value
+				case "version":
+					d.Info.Version = value
+				case "description":
+					d.Info.Description = value
+				case "termsofservice":
+					d.Info.TermsOfService = value
+				case "contact.name":
+					d.contact().Name = value
+				case "contact.url":
+					d.contact().URL = value
+				case "contact.email":
+					d.contact().Email = value
+				case "license.name":
+					d.license().Name = value
+				case "license.url":
+					d.license().URL = value
+				case "host":
+					g.host = value
+				case "basepath":
+					g.basePath = value
+				case "securitydefinitions.apikey":
+					scheme = value
+					d.Components.SecuritySchemes[scheme] = securityScheme{Type: "apiKey"}
+				case "in", "name":
+					if scheme == "" {
+						return fmt.Errorf("%s: @%s outside a security definition", g.fset.Position(c.Pos()), key)
+					}
+					s := d.Components.SecuritySchemes[scheme]
+					if key == "in" {
+						s.In = value
+					} else {
+						s.Name = value
+					}
+					d.Components.SecuritySchemes[scheme] = s
+				}
+			}
+		}
+	}
+	if d.Info.Title == "" || d.Info.Version == "" {
+		return errors.New("missing @title or @version")
+	}
+	if g.host != "" {
+		url := "https://" + g.host
+		if g.routes == nil {
+			url += g.basePath
+		}
+		d.Servers = []server{{URL: url}}
+	}
+	return nil
+}
+
+func (d *document) contact() *contact {
+	if d.Info.Contact == nil {
+		d.Info.Contact = &contact{}
+	}
+	return d.Info.Contact
+}
+
+func (d *document) license() *license {
+	if d.Info.License == nil {
+		d.Info.License = &license{}
+	}
+	return d.Info.License
+}
+
+var pathParamRE = regexp.MustCompile(`\{([^}]+)\}`)
+
+// operation adds the operation annotated on fn, if any.
+func (g *generator) operation(p *pkg, f *ast.File, fn *ast.FuncDecl) error {
+	op := &operation{OperationID: fn.Name.Name, Responses: make(map[string]*response)}
+	var (
+		route, method string
+		accept        []string
+		produce       []string
+		params        []string
+		results       []string
+		form          *schema
+	)
+	for _, c := range fn.Doc.List {
+		key, value, ok := annotation(c.Text)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "summary":
+			op.Summary = value
+		case "description":
+			op.Description = strings.TrimSpace(op.Description + "\n" + value)
+		case "tags":
+			for t := range strings.SplitSeq(value, ",") {
+				op.Tags = append(op.Tags, strings.TrimSpace(t))
+			}
+		case "accept":
+			for _, m := range strings.Split(value, ",") {
+				accept = append(accept, mimeType(strings.TrimSpace(m)))
+			}
+		case "produce":
+			for _, m := range strings.Split(value, ",") {
+				produce = append(produce, mimeType(strings.TrimSpace(m)))
+			}
+		case "param":
+			params = append(params, value)
+		case "success", "failure":
+			results = append(results, value)
+		case "security":
+			if _, ok := g.doc.Components.SecuritySchemes[value]; !ok {
+				return fmt.Errorf("@Security %s: undefined security scheme", value)
+			}
+			op.Security = append(op.Security, map[string][]string{value: {}})
+		case "router":
+			fields := strings.Fields(value)
+			if len(fields) != 2 || !strings.HasPrefix(fields[1], "[") || !strings.HasSuffix(fields[1], "]") {
+				return fmt.Errorf("invalid @Router %q", value)
+			}
+			route, method = fields[0], strings.ToLower(strings.Trim(fields[1], "[]"))
+		case "id":
+			op.OperationID = value
+		default:
+			return fmt.Errorf("unsupported annotation @%s", key)
+		}
+	}
+	if route == "" {
+		if len(params)+len(results) > 0 || op.Summary != "" {
+			return errors.New("annotated handler has no @Router")
+		}
+		return nil
+	}
+	full, ok := g.resolve(method, route)
+	if !ok {
+		if g.unmounted != nil {
+			g.unmounted(op.OperationID, strings.ToUpper(method)+" "+route)
+		}
+		return nil
+	}
+	if len(accept) == 0 {
+		accept = []string{"application/json"}
+	}
+	if len(produce) == 0 {
+		produce = []string{"application/json"}
+	}
+
+	for _, value := range params {
+		tok, err := tokenize(value)
+		if err != nil {
+			return fmt.Errorf("@Param %q: %w", value, err)
+		}
+		if len(tok) < 4 {
+			return fmt.Errorf("@Param %q: want name, location, type and required", value)
+		}
+		name, in, typ := tok[0], tok[1], tok[2]
+		required, err := strconv.ParseBool(tok[3])
+		if err != nil {
+			return fmt.Errorf("@Param %q: %w", value, err)
+		}
+		var desc string
+		attrs := tok[4:]
+		if len(attrs) > 0 && strings.HasPrefix(attrs[0], `"`) {
+			desc, attrs = unquote(attrs[0]), attrs[1:]
+		}
+
+		if in == "body" {
+			expr, err := parser.ParseExpr(typ)
+			if err != nil {
+				return fmt.Errorf("@Param %q: %w", value, err)
+			}
+			s, err := g.schemaFor(expr, p, f)
+			if err != nil {
+				return fmt.Errorf("@Param %q: %w", value, err)
+			}
+			op.RequestBody = &requestBody{Description: desc, Required: required, Content: content(accept, s)}
+			continue
+		}
+
+		s, err := primitive(typ)
+		if err != nil {
+			return fmt.Errorf("@Param %q: %w", value, err)
+		}
+		if err := applyAttrs(s, attrs); err != nil {
+			return fmt.Errorf("@Param %q: %w", value, err)
+		}
+		switch in {
+		case "formData":
+			if form == nil {
+				form = &schema{Type: "object", Properties: make(map[string]*schema)}
+			}
+			s.Description = desc
+			form.Properties[name] = s
+			if required {
+				form.Required = append(form.Required, name)
+			}
+		case "path", "query", "header", "cookie":
+			op.Parameters = append(op.Parameters, parameter{Name: name, In: in, Description: desc, Required: required || in == "path", Schema: s})
+		default:
+			return fmt.Errorf("@Param %q: unsupported location %q", value, in)
+		}
+	}
+	if form != nil {
+		if op.RequestBody != nil {
+			return errors.New("both body and formData parameters")
+		}
+		formType := "application/x-www-form-urlencoded"
+		if slices.Contains(accept, "multipart/form-data") {
+			formType = "multipart/form-data"
+		}
+		op.RequestBody = &requestBody{Required: len(form.Required) > 0, Content: content([]string{formType}, form)}
+	}
+
+	for _, value := range results {
+		if err := g.response(op, p, f, produce, value); err != nil {
+			return err
+		}
+	}
+	if len(op.Responses) == 0 {
+		return errors.New("no @Success or @Failure responses")
+	}
+
+	// Every {param} in the path must be documented, and vice versa.
+	inPath := make(map[string]bool)
+	for _, m := range pathParamRE.FindAllStringSubmatch(route, -1) {
+		inPath[m[1]] = true
+	}
+	for _, prm := range op.Parameters {
+		if prm.In == "path" && !inPath[prm.Name] {
+			return fmt.Errorf("path parameter %q is not in @Router %s", prm.Name, route)
+		}
+		delete(inPath, prm.Name)
+	}
+	for name := range inPath {
+		return fmt.Errorf("@Router %s: path parameter %q is not documented", route, name)
+	}
+
+	item := g.doc.Paths[full]
+	if item == nil {
+		item = make(pathItem)
+		g.doc.Paths[full] = item
+	}
+	if _, dup := item[method]; dup {
+		return fmt.Errorf("duplicate operation %s %s", strings.ToUpper(method), full)
+	}
+	item[method] = op
+	return nil
+}
+
+// response adds an @Success or @Failure result to op.
+func (g *generator) response(op *operation, p *pkg, f *ast.File, produce []string, value string) error {
+	tok, err := tokenize(value)
+	if err != nil || len(tok) == 0 {
+		return fmt.Errorf("invalid response %q", value)
+	}
+	code, err := strconv.Atoi(tok[0])
+	if err != nil || http.StatusText(code) == "" {
+		return fmt.Errorf("response %q: invalid status code", value)
+	}
+	tok = tok[1:]
+
+	var s *schema
+	if len(tok) > 0 && strings.HasPrefix(tok[0], "{") {
+		if len(tok) < 2 {
+			return fmt.Errorf("response %q: missing type", value)
+		}
+		kind, typ := strings.Trim(tok[0], "{}"), tok[1]
+		tok = tok[2:]
+		switch kind {
+		case "object", "array":
+			expr, err := parser.ParseExpr(typ)
+			if err != nil {
+				return fmt.Errorf("response %q: %w", value, err)
+			}
+			if s, err = g.schemaFor(expr, p, f); err != nil {
+				return fmt.Errorf("response %q: %w", value, err)
+			}
+			if kind == "array" {
+				s = &schema{Type: "array", Items: s}
+			}
+		default:
+			if s, err = primitive(kind); err != nil {
+				return fmt.Errorf("response %q: %w", value, err)
+			}
+		}
+	}
+	desc := http.StatusText(code)
+	if len(tok) > 0 {
+		if !strings.HasPrefix(tok[0], `"`) || len(tok) > 1 {
+			return fmt.Errorf("response %q: unexpected %q", value, strings.Join(tok, " "))
+		}
+		desc = unquote(tok[0])
+	}
+
+	key := strconv.Itoa(code)
+	if r, ok := op.Responses[key]; ok {
+		// Several annotations for one status code document alternative causes.
+		if desc != http.StatusText(code) && r.Description != desc {
+			if r.Description == http.StatusText(code) {
+				r.Description = desc
+			} else {
+				r.Description += "; " + desc
+			}
+		}
+		return nil
+	}
+	r := &response{Description: desc}
+	if s != nil {
+		r.Content = content(produce, s)
+	}
+	op.Responses[key] = r
+	return nil
+}
+
+// resolve returns the mounted path of an annotated route.
+func (g *generator) resolve(method, route string) (string, bool) {
+	if g.routes == nil {
+		return normalizePath(route), true
+	}
+	full := normalizePath(path.Join("/", g.basePath, route))
+	for _, candidate := range []string{full, normalizePath(route)} {
+		if g.routes[strings.ToUpper(method)+" "+candidate] {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func normalizePath(p string) string {
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return p
+}
+
+func content(mediaTypes []string, s *schema) map[string]mediaType {
+	c := make(map[string]mediaType, len(mediaTypes))
+	for _, t := range mediaTypes {
+		c[t] = mediaType{Schema: s}
+	}
+	return c
+}
+
+var mimeTypes = map[string]string{
+	"json":                  "application/json",
+	"x-www-form-urlencoded": "application/x-www-form-urlencoded",
+	"mpfd":                  "multipart/form-data",
+	"html":                  "text/html",
+	"plain":                 "text/plain",
+}
+
+func mimeType(alias string) string {
+	if m, ok := mimeTypes[alias]; ok {
+		return m
+	}
+	return alias
+}
+
+// primitive returns the schema of a swag primitive type name.
+func primitive(typ string) (*schema, error) {
+	switch typ {
+	case "string":
+		return &schema{Type: "string"}, nil
+	case "int", "integer":
+		return &schema{Type: "integer"}, nil
+	case "number":
+		return &schema{Type: "number"}, nil
+	case "bool", "boolean":
+		return &schema{Type: "boolean"}, nil
+	case "file":
+		return &schema{Type: "string", Format: "binary"}, nil
+	}
+	return nil, fmt.Errorf("unsupported parameter type %q", typ)
+}
+
+// applyAttrs applies parameter attributes such as Enums(a, b) and example(x).
+func applyAttrs(s *schema, attrs []string) error {
+	for _, a := range attrs {
+		name, arg, ok := strings.Cut(a, "(")
+		if !ok || !strings.HasSuffix(arg, ")") {
+			return fmt.Errorf("invalid attribute %q", a)
+		}
+		arg = strings.TrimSuffix(arg, ")")
+		switch strings.ToLower(name) {
+		case "enums":
+			for v := range strings.SplitSeq(arg, ",") {
+				s.Enum = append(s.Enum, literal(s, unquote(strings.TrimSpace(v))))
+			}
+		case "example":
+			s.Example = literal(s, unquote(arg))
+		case "default":
+			s.Default = literal(s, unquote(arg))
+		case "minimum", "maximum":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return fmt.Errorf("attribute %q: %w", a, err)
+			}
+			if strings.EqualFold(name, "minimum") {
+				s.Minimum = &n
+			} else {
+				s.Maximum = &n
+			}
+		case "format":
+			s.Format = arg
+		default:
+			return fmt.Errorf("unsupported attribute %q", name)
+		}
+	}
+	return nil
+}
+
+// literal converts an attribute value to the JSON type of s.
+func literal(s *schema, v string) any {
+	switch s.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case "array", "object":
+		var x any
+		if err := json.Unmarshal([]byte(v), &x); err == nil {
+			return x
+		}
+	}
+	return v
+}
+
+// schemaFor returns the schema of a Go type expression appearing in file f of p.
+// Named struct types become components and are referenced.
+func (g *generator) schemaFor(expr ast.Expr, p *pkg, f *ast.File) (*schema, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if s, ok := builtin(t.Name); ok {
+			return s, nil
+		}
+		return g.named(p, t.Name)
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type %s", types.ExprString(expr))
+		}
+		imp, err := importPath(f, x.Name)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := externalTypes[imp+"."+t.Sel.Name]; ok {
+			c := *s
+			return &c, nil
+		}
+		if imp != g.module && !strings.HasPrefix(imp, g.module+"/") {
+			return nil, fmt.Errorf("unsupported external type %s.%s", imp, t.Sel.Name)
+		}
+		other, err := g.load(filepath.Join(g.root, filepath.FromSlash(strings.TrimPrefix(imp, g.module))))
+		if err != nil {
+			return nil, err
+		}
+		return g.named(other, t.Sel.Name)
+	case *ast.StarExpr:
+		return g.schemaFor(t.X, p, f)
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return &schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := g.schemaFor(t.Elt, p, f)
+		if err != nil {
+			return nil, err
+		}
+		return &schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		values, err := g.schemaFor(t.Value, p, f)
+		if err != nil {
+			return nil, err
+		}
+		return &schema{Type: "object", AdditionalProperties: values}, nil
+	case *ast.InterfaceType:
+		return &schema{}, nil
+	case *ast.StructType:
+		return g.object(t, p, f)
+	}
+	return nil, fmt.Errorf("unsupported type %s", types.ExprString(expr))
+}
+
+// named returns the schema of the type declared as name in p.
+func (g *generator) named(p *pkg, name string) (*schema, error) {
+	decl, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s.%s not found", p.name, name)
+	}
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok || decl.spec.TypeParams != nil {
+		if decl.spec.TypeParams != nil {
+			return nil, fmt.Errorf("generic type %s.%s is not supported", p.name, name)
+		}
+		// Defined non-struct types (e.g. type Status string) are inlined.
+		return g.schemaFor(decl.spec.Type, p, decl.file)
+	}
+	key := p.name + "." + name
+	ref := &schema{Ref: "#/components/schemas/" + key}
+	if _, done := g.doc.Components.Schemas[key]; done {
+		return ref, nil
+	}
+	// Register before building so recursive types terminate.
+	g.doc.Components.Schemas[key] = &schema{}
+	s, err := g.object(st, p, decl.file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	s.Description = decl.doc
+	g.doc.Components.Schemas[key] = s
+	return ref, nil
+}
+
+// object returns the schema of a struct, following encoding/json's rules for
+// field names, ignored fields and embedded structs.
+func (g *generator) object(st *ast.StructType, p *pkg, f *ast.File) (*schema, error) {
+	s := &schema{Type: "object", Properties: make(map[string]*schema)}
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(raw)
+		}
+		jsonName, opts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" && opts == "" {
+			continue
+		}
+		// As with swag, only validation tags make a field required.
+		required := slices.Contains(strings.Split(tag.Get("binding"), ","), "required") ||
+			slices.Contains(strings.Split(tag.Get("validate"), ","), "required")
+
+		if len(field.Names) == 0 && jsonName == "" {
+			// Embedded struct: its fields are promoted.
+			embedded, ep, ef, err := g.structOf(field.Type, p, f)
+			if err != nil {
+				return nil, err
+			}
+			inner, err := g.object(embedded, ep, ef)
+			if err != nil {
+				return nil, err
+			}
+			for name, prop := range inner.Properties {
+				if _, ok := s.Properties[name]; !ok {
+					s.Properties[name] = prop
+				}
+			}
+			for _, name := range inner.Required {
+				if !slices.Contains(s.Required, name) {
+					s.Required = append(s.Required, name)
+				}
+			}
+			continue
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{embeddedName(field.Type)}
+		}
+		for _, n := range names {
+			if !n.IsExported() {
+				continue
+			}
+			name := n.Name
+			if jsonName != "" {
+				name = jsonName
+			}
+			prop, err := g.schemaFor(field.Type, p, f)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", n.Name, err)
+			}
+			if doc := commentText(field.Doc); doc != "" {
+				prop.Description = doc
+			} else if c := commentText(field.Comment); c != "" {
+				prop.Description = c
+			}
+			if ex, ok := tag.Lookup("example"); ok {
+				prop.Example = literal(prop, ex)
+			}
+			s.Properties[name] = prop
+			if required {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+	return s, nil
+}
+
+// structOf resolves an embedded field type to its struct declaration.
+func (g *generator) structOf(expr ast.Expr, p *pkg, f *ast.File) (*ast.StructType, *pkg, *ast.File, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return g.structOf(t.X, p, f)
+	case *ast.Ident:
+		decl, ok := p.types[t.Name]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("embedded type %s.%s not found", p.name, t.Name)
+		}
+		if st, ok := decl.spec.Type.(*ast.StructType); ok {
+			return st, p, decl.file, nil
+		}
+		return g.structOf(decl.spec.Type, p, decl.file)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			imp, err := importPath(f, x.Name)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if strings.HasPrefix(imp, g.module+"/") {
+				other, err := g.load(filepath.Join(g.root, filepath.FromSlash(strings.TrimPrefix(imp, g.module))))
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				return g.structOf(t.Sel, other, nil)
+			}
+		}
+	}
+	return nil, nil, nil, fmt.Errorf("unsupported embedded type %s", types.ExprString(expr))
+}
+
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.Ident:
+		return t
+	}
+	return ast.NewIdent("_")
+}
+
+// importPath returns the import path bound to name in f.
+func importPath(f *ast.File, name string) (string, error) {
+	if f != nil {
+		for _, imp := range f.Imports {
+			p, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return "", err
+			}
+			local := path.Base(p)
+			if imp.Name != nil {
+				local = imp.Name.Name
+			}
+			if local == name {
+				return p, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("package %s is not imported", name)
+}
+
+// externalTypes describes the non-module types used in API payloads.
+var externalTypes = map[string]*schema{
+	"time.Time":                       {Type: "string", Format: "date-time"},
+	"time.Duration":                   {Type: "integer", Format: "int64"},
+	"encoding/json.RawMessage":        {},
+	"net/url.URL":                     {Type: "string", Format: "uri"},
+	"github.com/google/uuid.UUID":     {Type: "string", Format: "uuid"},
+	"github.com/google/uuid.NullUUID": {Type: "string", Format: "uuid"},
+}
+
+func builtin(name string) (*schema, bool) {
+	switch name {
+	case "string":
+		return &schema{Type: "string"}, true
+	case "bool":
+		return &schema{Type: "boolean"}, true
+	case "int", "int8", "int16", "uint", "uint8", "uint16", "uintptr":
+		return &schema{Type: "integer"}, true
+	case "int32", "uint32", "rune":
+		return &schema{Type: "integer", Format: "int32"}, true
+	case "int64", "uint64":
+		return &schema{Type: "integer", Format: "int64"}, true
+	case "float32":
+		return &schema{Type: "number", Format: "float"}, true
+	case "float64":
+		return &schema{Type: "number", Format: "double"}, true
+	case "any":
+		return &schema{}, true
+	}
+	return nil, false
+}
+
+// annotation splits a "// @Key value" comment line.
+func annotation(comment string) (key, value string, ok bool) {
+	text := strings.TrimSpace(strings.TrimPrefix(comment, "//"))
+	if !strings.HasPrefix(text, "@") {
+		return "", "", false
+	}
+	key, value, _ = strings.Cut(text[1:], " ")
+	return key, strings.TrimSpace(value), key != ""
+}
+
+// tokenize splits an annotation value on spaces, keeping quoted strings and
+// parenthesised attribute arguments together.
+func tokenize(s string) ([]string, error) {
+	var (
+		tokens []string
+		cur    strings.Builder
+		quoted bool
+		depth  int
+	)
+	for _, r := range s {
+		switch {
+		case r == '"' && depth == 0:
+			quoted = !quoted
+		case r == '(' && !quoted:
+			depth++
+		case r == ')' && !quoted:
+			depth--
+		case (r == ' ' || r == '\t') && !quoted && depth == 0:
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if quoted || depth != 0 {
+		return nil, errors.New("unbalanced quotes or parentheses")
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return strings.Trim(s, `"`)
+}
+
+func commentText(cg *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/openapi"
+)
+
+// TestPurpose: Validates OpenAPI generation from handler annotations.
+// Scope: Unit Test
+// Security: The published API description must match the routes the server actually mounts
+// Expected: Operations, parameters, bodies and schemas are generated; unmounted routes are reported and left out.
+// Test Case ID: API-01
+func TestGenerate(t *testing.T) {
+	var unmounted []string
+	out, err := openapi.Generate(openapi.Config{
+		Dir:    "testdata/petstore",
+		Routes: []string{"GET /api/pets/", "POST /api/pets", "POST /api/login", "GET /health"},
+		Unmounted: func(operationID, route string) {
+			unmounted = append(unmounted, operationID+" "+route)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	again, _ := openapi.Generate(openapi.Config{Dir: "testdata/petstore", Routes: []string{"GET /api/pets/", "POST /api/pets", "POST /api/login", "GET /health"}})
+	if string(out) != string(again) {
+		t.Error("expected deterministic output")
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct{ Title, Version string }
+		Servers []struct{ URL string }
+		Paths   map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name   string
+				Schema map[string]any
+			}
+			RequestBody *struct {
+				Content map[string]struct{ Schema map[string]any }
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Description string
+				Content     map[string]struct{ Schema map[string]any }
+			}
+			Security []map[string][]string
+		}
+		Components struct {
+			Schemas         map[string]map[string]any
+			SecuritySchemes map[string]map[string]string `json:"securitySchemes"`
+		}
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != openapi.Version || doc.Info.Title != "Pet Store" || doc.Info.Version != "1.0" {
+		t.Errorf("unexpected header: %s %+v", doc.OpenAPI, doc.Info)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "https://pets.example.com" {
+		t.Errorf("unexpected servers: %+v", doc.Servers)
+	}
+	if doc.Components.SecuritySchemes["CookieAuth"]["in"] != "cookie" {
+		t.Errorf("unexpected security schemes: %+v", doc.Components.SecuritySchemes)
+	}
+
+	// Paths are resolved against the mounted routes, inside or outside the base path.
+	for _, p := range []string{"/api/pets", "/api/login", "/health"} {
+		if _, ok := doc.Paths[p]; !ok {
+			t.Errorf("expected path %s, got %v", p, doc.Paths)
+		}
+	}
+	if len(unmounted) != 1 || unmounted[0] != "DeletePet DELETE /pets/{petID}" {
+		t.Errorf("expected DeletePet to be reported as unmounted, got %v", unmounted)
+	}
+
+	list := doc.Paths["/api/pets"]["get"]
+	if list.OperationID != "ListPets" || len(list.Parameters) != 2 {
+		t.Fatalf("unexpected list operation: %+v", list)
+	}
+	if enum := list.Parameters[0].Schema["enum"]; len(enum.([]any)) != 2 {
+		t.Errorf("expected status enum, got %v", enum)
+	}
+	if list.Parameters[1].Schema["type"] != "integer" || list.Parameters[1].Schema["default"] != float64(20) {
+		t.Errorf("unexpected limit schema: %v", list.Parameters[1].Schema)
+	}
+	if items := list.Responses["200"].Content["application/json"].Schema["items"]; items.(map[string]any)["$ref"] != "#/components/schemas/petstore.Pet" {
+		t.Errorf("expected array of Pet, got %v", items)
+	}
+
+	create := doc.Paths["/api/pets"]["post"]
+	if create.RequestBody == nil || create.RequestBody.Content["application/json"].Schema["$ref"] != "#/components/schemas/petstore.Pet" {
+		t.Errorf("unexpected request body: %+v", create.RequestBody)
+	}
+	if len(create.Security) != 1 {
+		t.Errorf("expected CookieAuth security, got %v", create.Security)
+	}
+	if d := create.Responses["400"].Description; d != "name is missing" {
+		t.Errorf("expected merged 400 description, got %q", d)
+	}
+
+	login := doc.Paths["/api/login"]["post"]
+	form := login.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	if req, _ := form["required"].([]any); len(req) != 2 {
+		t.Errorf("expected both form fields to be required, got %v", form)
+	}
+
+	pet := doc.Components.Schemas["petstore.Pet"]
+	props := pet["properties"].(map[string]any)
+	for _, name := range []string{"id", "created_at", "name", "tags", "owner", "status"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("expected property %s, got %v", name, props)
+		}
+	}
+	for _, name := range []string{"secret", "Hidden", "Base"} {
+		if _, ok := props[name]; ok {
+			t.Errorf("unexpected property %s", name)
+		}
+	}
+	if req := pet["required"].([]any); len(req) != 1 || req[0] != "name" {
+		t.Errorf("expected only name to be required, got %v", req)
+	}
+	if created := props["created_at"].(map[string]any); created["format"] != "date-time" {
+		t.Errorf("expected date-time, got %v", created)
+	}
+	if status := props["status"].(map[string]any); status["type"] != "string" {
+		t.Errorf("expected defined string type to be inlined, got %v", status)
+	}
+	if tags := props["tags"].(map[string]any); len(tags["example"].([]any)) != 1 {
+		t.Errorf("expected array example, got %v", tags)
+	}
+}
+
+// TestPurpose: Validates that inconsistent annotations fail generation.
+// Scope: Unit Test
+// Security: A stale or mistyped annotation must not silently produce a misleading API description
+// Expected: A path parameter missing from @Param is an error naming the handler.
+// Test Case ID: API-02
+func TestGenerate_Invalid(t *testing.T) {
+	_, err := openapi.Generate(openapi.Config{Dir: "testdata/invalid"})
+	if err == nil || !strings.Contains(err.Error(), "GetThing") || !strings.Contains(err.Error(), "thingID") {
+		t.Errorf("expected undocumented path parameter error, got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+// @title Invalid
+// @version 1.0
+
+package invalid
+
+import "net/http"
+
+// GetThing documents a path without its parameter
+// @Summary Get Thing
+// @Success 200 {string} string "OK"
+// @Router /things/{thingID} [get]
+func GetThing(w http.ResponseWriter, r *http.Request) {}
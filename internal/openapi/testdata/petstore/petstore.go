@@ -0,0 +1,83 @@
+// @title Pet Store
+// @version 1.0
+// @host pets.example.com
+// @BasePath /api
+
+// @securityDefinitions.apikey CookieAuth
+// @in cookie
+// @name session_id
+
+package petstore
+
+import (
+	"net/http"
+	"time"
+)
+
+// Base holds fields shared by all resources
+type Base struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Pet is an animal in the store
+type Pet struct {
+	Base
+	Name string   `json:"name" binding:"required" example:"Rex"`
+	Tags []string `json:"tags,omitempty" example:"[\"dog\"]"`
+	// Owner is set once the pet is sold
+	Owner  *Pet   `json:"owner,omitempty"`
+	Status Status `json:"status"`
+	secret string
+	Hidden string `json:"-"`
+}
+
+// Status is the sale status of a pet
+type Status string
+
+// ListPets lists pets
+// @Summary List Pets
+// @Tags Pets
+// @Produce json
+// @Param status query string false "Status filter" Enums(available, sold)
+// @Param limit query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {array} Pet
+// @Router /pets [get]
+func ListPets(w http.ResponseWriter, r *http.Request) {}
+
+// CreatePet creates a pet
+// @Summary Create Pet
+// @Tags Pets
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param request body Pet true "Pet"
+// @Success 201 {object} Pet
+// @Failure 400 {object} map[string]string
+// @Failure 400 {object} map[string]string "name is missing"
+// @Router /pets [post]
+func CreatePet(w http.ResponseWriter, r *http.Request) {}
+
+// DeletePet deletes a pet
+// @Summary Delete Pet
+// @Tags Pets
+// @Param petID path string true "Pet ID"
+// @Success 204
+// @Router /pets/{petID} [delete]
+func DeletePet(w http.ResponseWriter, r *http.Request) {}
+
+// Login signs in
+// @Summary Login
+// @Tags Auth
+// @Accept x-www-form-urlencoded
+// @Param username formData string true "User name"
+// @Param password formData string true "Password"
+// @Success 302 {string} string "Redirects home"
+// @Router /login [post]
+func Login(w http.ResponseWriter, r *http.Request) {}
+
+// Health is mounted outside the base path
+// @Summary Health
+// @Success 200 {string} string "OK"
+// @Router /health [get]
+func Health(w http.ResponseWriter, r *http.Request) {}
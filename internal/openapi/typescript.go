@@ -0,0 +1,475 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TypeScriptConfig controls client generation.
+type TypeScriptConfig struct {
+	// PathPrefix selects the operations to generate methods for, e.g. "/api/".
+	// When empty, every operation is included.
+	PathPrefix string
+	// ClassName is the name of the generated client class.
+	ClassName string
+}
+
+// TypeScript generates a dependency-free TypeScript client from an OpenAPI
+// document produced by Generate: an interface per referenced schema and a
+// client class with one method per operation, named after its operationId.
+// Like Generate, it fails on anything it cannot describe, and its output is
+// deterministic.
+func TypeScript(docJSON []byte, cfg TypeScriptConfig) ([]byte, error) {
+	var doc document
+	if err := json.Unmarshal(docJSON, &doc); err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+	if cfg.ClassName == "" {
+		cfg.ClassName = "Client"
+	}
+	g := &tsGenerator{doc: &doc, names: make(map[string]string), used: make(map[string]bool)}
+	for ref := range doc.Components.Schemas {
+		name := ref[strings.LastIndex(ref, ".")+1:]
+		if !tsIdent.MatchString(name) {
+			return nil, fmt.Errorf("schema %s: %q is not a TypeScript identifier", ref, name)
+		}
+		for other, taken := range g.names {
+			if taken == name {
+				return nil, fmt.Errorf("schemas %s and %s would both be named %s", other, ref, name)
+			}
+		}
+		g.names[ref] = name
+	}
+
+	var methods bytes.Buffer
+	for _, path := range sortedKeys(doc.Paths) {
+		if !strings.HasPrefix(path, cfg.PathPrefix) {
+			continue
+		}
+		item := doc.Paths[path]
+		for _, method := range sortedKeys(item) {
+			op := item[method]
+			if err := g.method(&methods, method, path, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %s: %w", strings.ToUpper(method), path, op.OperationID, err)
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by openapi-gen from the OpenAPI document. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "// %s client", doc.Info.Title)
+	if cfg.PathPrefix != "" {
+		fmt.Fprintf(&b, " for the operations under %s", cfg.PathPrefix)
+	}
+	b.WriteString(".\n\n")
+
+	// Schemas can reference further schemas, so the referenced set is closed
+	// before the declarations are written in name order.
+	for seen := make(map[string]bool); len(seen) < len(g.used); {
+		for _, ref := range sortedKeys(g.used) {
+			if !seen[ref] {
+				seen[ref] = true
+				if err := g.declaration(new(bytes.Buffer), ref); err != nil {
+					return nil, fmt.Errorf("schema %s: %w", ref, err)
+				}
+			}
+		}
+	}
+	refs := sortedKeys(g.used)
+	slices.SortFunc(refs, func(a, b string) int { return strings.Compare(g.names[a], g.names[b]) })
+	for _, ref := range refs {
+		if err := g.declaration(&b, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintf(&b, tsRuntime, cfg.ClassName)
+	b.Write(methods.Bytes())
+	b.WriteString(tsRequest)
+	return b.Bytes(), nil
+}
+
+// tsIdent matches the identifiers that can be used without quoting.
+var tsIdent = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+type tsGenerator struct {
+	doc   *document
+	names map[string]string // Component name to TypeScript name
+	used  map[string]bool   // Components referenced so far
+}
+
+// declaration writes the interface or type alias of a component schema.
+func (g *tsGenerator) declaration(b *bytes.Buffer, ref string) error {
+	s := g.doc.Components.Schemas[ref]
+	writeDoc(b, "", s.Description)
+	name := g.names[ref]
+	if s.Type == "object" && s.AdditionalProperties == nil {
+		fmt.Fprintf(b, "export interface %s ", name)
+		if err := g.object(b, "", s); err != nil {
+			return err
+		}
+		b.WriteString("\n\n")
+		return nil
+	}
+	typ, err := g.typeOf(s, "")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b, "export type %s = %s;\n\n", name, typ)
+	return nil
+}
+
+// object writes the body of an object type, one documented property per line.
+func (g *tsGenerator) object(b *bytes.Buffer, indent string, s *schema) error {
+	if len(s.Properties) == 0 {
+		b.WriteString("{}")
+		return nil
+	}
+	b.WriteString("{\n")
+	for _, name := range sortedKeys(s.Properties) {
+		prop := s.Properties[name]
+		writeDoc(b, indent+"  ", prop.Description)
+		typ, err := g.typeOf(prop, indent+"  ")
+		if err != nil {
+			return fmt.Errorf("property %s: %w", name, err)
+		}
+		fmt.Fprintf(b, "%s  %s%s: %s;\n", indent, propertyName(name), optional(slices.Contains(s.Required, name)), typ)
+	}
+	b.WriteString(indent + "}")
+	return nil
+}
+
+// typeOf returns the TypeScript type of a schema.
+func (g *tsGenerator) typeOf(s *schema, indent string) (string, error) {
+	if s == nil {
+		return "unknown", nil
+	}
+	if s.Ref != "" {
+		ref := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		name, ok := g.names[ref]
+		if !ok {
+			return "", fmt.Errorf("unresolved reference %s", s.Ref)
+		}
+		g.used[ref] = true
+		return name, nil
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			lit, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			values[i] = string(lit)
+		}
+		return strings.Join(values, " | "), nil
+	}
+	switch s.Type {
+	case "":
+		return "unknown", nil
+	case "string":
+		return "string", nil
+	case "integer", "number":
+		return "number", nil
+	case "boolean":
+		return "boolean", nil
+	case "array":
+		item, err := g.typeOf(s.Items, indent)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(item, " ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]", nil
+	case "object":
+		if s.AdditionalProperties != nil {
+			value, err := g.typeOf(s.AdditionalProperties, indent)
+			if err != nil {
+				return "", err
+			}
+			return "Record<string, " + value + ">", nil
+		}
+		if len(s.Properties) == 0 {
+			return "Record<string, unknown>", nil
+		}
+		var b bytes.Buffer
+		if err := g.object(&b, indent, s); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type)
+}
+
+// method writes the client method of an operation. Path parameters become
+// positional arguments, followed by the request body and an object holding
+// the query and header parameters.
+func (g *tsGenerator) method(b *bytes.Buffer, method, path string, op *operation) error {
+	if op.OperationID == "" {
+		return fmt.Errorf("missing operationId")
+	}
+	name := strings.ToLower(op.OperationID[:1]) + op.OperationID[1:]
+	if !tsIdent.MatchString(name) {
+		return fmt.Errorf("operationId is not a TypeScript identifier")
+	}
+
+	var args []string
+	urlExpr := path
+	var query, headers []parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			if !tsIdent.MatchString(p.Name) {
+				return fmt.Errorf("path parameter %q is not a TypeScript identifier", p.Name)
+			}
+			typ, err := g.typeOf(p.Schema, "  ")
+			if err != nil {
+				return fmt.Errorf("parameter %s: %w", p.Name, err)
+			}
+			args = append(args, p.Name+": "+typ)
+			urlExpr = strings.ReplaceAll(urlExpr, "{"+p.Name+"}", "${encodeURIComponent("+p.Name+")}")
+		case "query":
+			query = append(query, p)
+		case "header":
+			headers = append(headers, p)
+		default:
+			return fmt.Errorf("unsupported %s parameter %s", p.In, p.Name)
+		}
+	}
+
+	bodyArg := ""
+	if op.RequestBody != nil {
+		mediaType, body, err := requestContent(op.RequestBody)
+		if err != nil {
+			return err
+		}
+		typ, err := g.typeOf(body.Schema, "  ")
+		if err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+		args = append(args, "body"+optional(op.RequestBody.Required)+": "+typ)
+		bodyArg = fmt.Sprintf(", { type: %q, value: body }", mediaType)
+	}
+
+	queryArg, headersArg := "{}", "{}"
+	if params := append(slices.Clone(query), headers...); len(params) > 0 {
+		required := false
+		fields := make([]string, len(params))
+		for i, p := range params {
+			typ, err := g.typeOf(p.Schema, "  ")
+			if err != nil {
+				return fmt.Errorf("parameter %s: %w", p.Name, err)
+			}
+			required = required || p.Required
+			fields[i] = propertyName(p.Name) + optional(p.Required) + ": " + typ
+		}
+		arg := "params: { " + strings.Join(fields, "; ") + " }"
+		if !required {
+			arg += " = {}"
+		}
+		args = append(args, arg)
+		if len(query) > 0 {
+			queryArg = pick(query)
+		}
+		if len(headers) > 0 {
+			headersArg = pick(headers)
+		}
+	}
+
+	result, err := g.result(op)
+	if err != nil {
+		return err
+	}
+
+	writeDoc(b, "  ", strings.TrimSpace(op.Summary+"\n\n"+op.Description))
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), result)
+	fmt.Fprintf(b, "    return this.request(%q, `%s`, %s, %s%s);\n", strings.ToUpper(method), urlExpr, queryArg, headersArg, bodyArg)
+	b.WriteString("  }\n\n")
+	return nil
+}
+
+// result returns the type of the first successful response; operations
+// answering without a JSON body resolve to void.
+func (g *tsGenerator) result(op *operation) (string, error) {
+	for _, code := range sortedKeys(op.Responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		content, ok := op.Responses[code].Content["application/json"]
+		if !ok {
+			return "void", nil
+		}
+		typ, err := g.typeOf(content.Schema, "  ")
+		if err != nil {
+			return "", fmt.Errorf("response %s: %w", code, err)
+		}
+		return typ, nil
+	}
+	return "void", nil
+}
+
+// requestContent returns the single media type of a request body the client
+// can encode.
+func requestContent(body *requestBody) (string, mediaType, error) {
+	if len(body.Content) != 1 {
+		return "", mediaType{}, fmt.Errorf("request body has %d media types, expected one", len(body.Content))
+	}
+	for typ, content := range body.Content {
+		if typ != "application/json" && typ != "application/x-www-form-urlencoded" {
+			return "", mediaType{}, fmt.Errorf("unsupported request body media type %s", typ)
+		}
+		return typ, content, nil
+	}
+	panic("unreachable")
+}
+
+// pick returns an object literal copying the named parameters out of params.
+func pick(params []parameter) string {
+	fields := make([]string, len(params))
+	for i, p := range params {
+		if tsIdent.MatchString(p.Name) {
+			fields[i] = p.Name + ": params." + p.Name
+		} else {
+			fields[i] = strconv.Quote(p.Name) + ": params[" + strconv.Quote(p.Name) + "]"
+		}
+	}
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
+
+func propertyName(name string) string {
+	if tsIdent.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func optional(required bool) string {
+	if required {
+		return ""
+	}
+	return "?"
+}
+
+// writeDoc writes text as a JSDoc comment.
+func writeDoc(b *bytes.Buffer, indent, text string) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "*/", "*\\/")
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, text)
+		return
+	}
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s *%s\n", indent, strings.TrimRight(" "+line, " "))
+	}
+	b.WriteString(indent + " */\n")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tsRuntime opens the client class; %s is the class name.
+const tsRuntime = `/** Thrown for responses with a non-2xx status; body is the decoded error response, if any. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(` + "`request failed with status ${status}`" + `);
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Server URL, e.g. "https://auth.example.com"; empty for same-origin requests. */
+  baseUrl?: string;
+  /** fetch implementation; defaults to the global fetch. */
+  fetch?: typeof fetch;
+  /** Headers sent with every request. */
+  headers?: Record<string, string>;
+}
+
+type Params = Record<string, string | number | boolean | undefined>;
+
+interface Body {
+  type: string;
+  value: unknown;
+}
+
+/** Requests carry the browser's session cookie (credentials: "include"). */
+export class %s {
+  private readonly baseUrl: string;
+  private readonly fetch: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseUrl = (options.baseUrl ?? "").replace(/\/+$/, "");
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+    this.headers = options.headers ?? {};
+  }
+
+`
+
+// tsRequest closes the client class with the request helper.
+const tsRequest = `  private async request<T>(method: string, path: string, query: Params, headers: Params, body?: Body): Promise<T> {
+    const search = new URLSearchParams();
+    for (const [name, value] of Object.entries(query)) {
+      if (value !== undefined) search.set(name, String(value));
+    }
+    const init: RequestInit = { method, credentials: "include", headers: { Accept: "application/json", ...this.headers } };
+    const requestHeaders = init.headers as Record<string, string>;
+    for (const [name, value] of Object.entries(headers)) {
+      if (value !== undefined) requestHeaders[name] = String(value);
+    }
+    if (body !== undefined && body.value !== undefined) {
+      requestHeaders["Content-Type"] = body.type;
+      init.body =
+        body.type === "application/json"
+          ? JSON.stringify(body.value)
+          : new URLSearchParams(body.value as Record<string, string>).toString();
+    }
+
+    const queryString = search.toString();
+    const response = await this.fetch(this.baseUrl + path + (queryString ? "?" + queryString : ""), init);
+    const text = await response.text();
+    let data: unknown = undefined;
+    if (text !== "") {
+      data = response.headers.get("Content-Type")?.includes("json") ? JSON.parse(text) : text;
+    }
+    if (!response.ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+}
+`
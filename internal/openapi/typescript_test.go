@@ -0,0 +1,82 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/openapi"
+)
+
+// TestPurpose: Validates TypeScript client generation from a generated OpenAPI document.
+// Scope: Unit Test
+// Security: The admin console client must not drift from the API it calls
+// Expected: Referenced schemas become interfaces with required and optional properties, operations become methods with path, body and query arguments, the prefix selects operations, and unsupported media types fail generation.
+// Test Case ID: API-07
+func TestTypeScript(t *testing.T) {
+	doc, err := openapi.Generate(openapi.Config{Dir: "testdata/petstore"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out, err := openapi.TypeScript(doc, openapi.TypeScriptConfig{ClassName: "PetClient"})
+	if err != nil {
+		t.Fatalf("TypeScript: %v", err)
+	}
+	again, _ := openapi.TypeScript(doc, openapi.TypeScriptConfig{ClassName: "PetClient"})
+	if string(out) != string(again) {
+		t.Error("expected deterministic output")
+	}
+	client := string(out)
+
+	for _, want := range []string{
+		"// Code generated by openapi-gen from the OpenAPI document. DO NOT EDIT.",
+		"/** Pet is an animal in the store */\nexport interface Pet {",
+		"  name: string;\n",
+		"  /** Owner is set once the pet is sold */\n  owner?: Pet;\n",
+		"  tags?: string[];\n",
+		"export class PetClient {",
+		"  listPets(params: { status?: \"available\" | \"sold\"; limit?: number } = {}): Promise<Pet[]> {",
+		"`/pets`, { status: params.status, limit: params.limit }, {});",
+		"  createPet(body: Pet): Promise<Pet> {",
+		"{ type: \"application/json\", value: body });",
+		"  deletePet(petID: string): Promise<void> {",
+		"`/pets/${encodeURIComponent(petID)}`",
+		"{ type: \"application/x-www-form-urlencoded\", value: body });",
+		"  health(): Promise<string> {",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("expected client to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"secret", "Hidden"} {
+		if strings.Contains(client, unwanted) {
+			t.Errorf("unexpected %q in client", unwanted)
+		}
+	}
+
+	filtered, err := openapi.TypeScript(doc, openapi.TypeScriptConfig{PathPrefix: "/pets"})
+	if err != nil {
+		t.Fatalf("TypeScript: %v", err)
+	}
+	if s := string(filtered); strings.Contains(s, "login(") || strings.Contains(s, "health(") || !strings.Contains(s, "export class Client {") {
+		t.Errorf("expected only the /pets operations in the default class, got\n%s", s)
+	}
+
+	multipart := strings.Replace(string(doc), "application/x-www-form-urlencoded", "multipart/form-data", 1)
+	if _, err := openapi.TypeScript([]byte(multipart), openapi.TypeScriptConfig{}); err == nil || !strings.Contains(err.Error(), "Login") {
+		t.Errorf("expected unsupported media type error naming the operation, got %v", err)
+	}
+}
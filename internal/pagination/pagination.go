@@ -0,0 +1,125 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pagination implements keyset pagination over lists ordered by ID.
+// IDs are UUIDv7, so ordering by ID is stable and follows creation order.
+package pagination
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// Pagination errors
+var (
+	ErrInvalidCursor = apperr.New(apperr.CodeInvalidArgument, "invalid pagination cursor")
+	ErrInvalidLimit  = apperr.New(apperr.CodeInvalidArgument, "invalid page size")
+)
+
+// Page size limits
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Params selects one page of a list ordered by ascending ID
+type Params struct {
+	Limit int
+	After string // ID of the last item on the previous page; empty for the first page
+}
+
+// Parse builds Params from the limit and cursor query parameters
+func Parse(limit, cursor string) (Params, error) {
+	var p Params
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return p, ErrInvalidLimit
+		}
+		p.Limit = n
+	}
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return p, err
+		}
+		p.After = after
+	}
+	return p.Normalize(), nil
+}
+
+// Normalize applies the default and maximum page size
+func (p Params) Normalize() Params {
+	if p.Limit <= 0 {
+		p.Limit = DefaultLimit
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+	return p
+}
+
+// EncodeCursor returns an opaque cursor pointing after the given ID
+func EncodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// DecodeCursor returns the ID an opaque cursor points after
+func DecodeCursor(s string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	// Reject anything that is not an ID before it reaches a typed UUID column
+	if _, err := uuid.Parse(string(b)); err != nil {
+		return "", ErrInvalidCursor
+	}
+	return string(b), nil
+}
+
+// NextCursor returns the cursor for the page following items, or "" when
+// items is shorter than a full page and therefore the last one.
+func NextCursor[T any](items []T, p Params, id func(T) string) string {
+	p = p.Normalize()
+	if len(items) < p.Limit || len(items) == 0 {
+		return ""
+	}
+	return EncodeCursor(id(items[len(items)-1]))
+}
+
+// All walks every page of fetch and returns the concatenated items.
+// It is meant for internal callers, such as permission evaluation, that need the full set.
+func All[T any](fetch func(Params) ([]T, error), id func(T) string) ([]T, error) {
+	var all []T
+	p := Params{Limit: MaxLimit}
+	for {
+		items, err := fetch(p)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < p.Limit {
+			return all, nil
+		}
+		next := id(items[len(items)-1])
+		if next == p.After {
+			// Guard against a source that ignores the cursor
+			return all, nil
+		}
+		p.After = next
+	}
+}
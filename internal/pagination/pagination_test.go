@@ -0,0 +1,83 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagination
+
+import (
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPurpose: Validates parsing of the limit and cursor query parameters.
+// Scope: Unit Test
+// Security: Bounded result sets; untrusted cursors must not reach typed SQL columns
+// Expected: Defaults and the maximum are applied; malformed limits and cursors are rejected.
+// Test Case ID: PAG-01
+func TestPagination_Parse(t *testing.T) {
+	p, err := Parse("", "")
+	require.NoError(t, err)
+	assert.Equal(t, Params{Limit: DefaultLimit}, p)
+
+	p, err = Parse("1000", "")
+	require.NoError(t, err)
+	assert.Equal(t, MaxLimit, p.Limit)
+
+	for _, limit := range []string{"0", "-1", "ten"} {
+		_, err = Parse(limit, "")
+		assert.ErrorIs(t, err, ErrInvalidLimit)
+	}
+
+	last := id.NewUUIDv7()
+	p, err = Parse("10", EncodeCursor(last))
+	require.NoError(t, err)
+	assert.Equal(t, Params{Limit: 10, After: last}, p)
+
+	for _, cursor := range []string{"%%%", EncodeCursor("c1"), EncodeCursor("' OR 1=1 --")} {
+		_, err = Parse("", cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	}
+}
+
+// TestPurpose: Validates next-cursor computation and the full walk used by internal callers.
+// Scope: Unit Test
+// Security: Permission evaluation must see every assignment, not just the first page
+// Expected: A full page yields a cursor, a short page does not; All returns every item once.
+// Test Case ID: PAG-02
+func TestPagination_NextCursorAndAll(t *testing.T) {
+	var ids []string
+	for i := 0; i < 2*MaxLimit+3; i++ {
+		ids = append(ids, id.NewUUIDv7())
+	}
+	self := func(s string) string { return s }
+
+	assert.Equal(t, EncodeCursor(ids[1]), NextCursor(ids[:2], Params{Limit: 2}, self))
+	assert.Empty(t, NextCursor(ids[:1], Params{Limit: 2}, self))
+
+	calls := 0
+	all, err := All(func(p Params) ([]string, error) {
+		calls++
+		start := 0
+		for start < len(ids) && p.After != "" && ids[start] <= p.After {
+			start++
+		}
+		end := min(start+p.Limit, len(ids))
+		return ids[start:end], nil
+	}, self)
+	require.NoError(t, err)
+	assert.Equal(t, ids, all)
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPrefix namespaces the markers in a Redis database shared with other data
+const redisPrefix = "opentrusty:replay:"
+
+// Redis is a Store on Redis keys that expire by themselves, for deployments that already run Redis
+// and want to keep short-lived markers out of the database
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis creates a store for the Redis server at url, such as redis://:password@host:6379/0 or
+// rediss:// for TLS
+func NewRedis(url string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	return &Redis{client: redis.NewClient(opts)}, nil
+}
+
+// MarkOnce sets the marker's key only if it does not exist yet
+func (r *Redis) MarkOnce(ctx context.Context, kind, id string, ttl time.Duration) error {
+	set, err := r.client.SetNX(ctx, redisPrefix+kind+":"+id, 1, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to mark %s: %w", kind, err)
+	}
+	if !set {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// Close closes the client's connections
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
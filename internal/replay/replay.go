@@ -0,0 +1,42 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay records one-time identifiers, such as the jti of a client assertion, DPoP proof
+// or logout token, so that a replayed token is recognized by every replica.
+package replay
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// ErrReplayed is returned when an identifier has already been used
+var ErrReplayed = apperr.New(apperr.CodeAlreadyExists, "identifier already used")
+
+// Store marks identifiers as used
+type Store interface {
+	// MarkOnce records id within kind for ttl, typically until the token carrying it expires. It
+	// returns ErrReplayed when id is already recorded and has not expired.
+	MarkOnce(ctx context.Context, kind, id string, ttl time.Duration) error
+}
+
+// Repository is a Store kept in the database; expired markers stay until DeleteExpired removes them
+type Repository interface {
+	Store
+
+	// DeleteExpired deletes up to limit expired markers and returns how many were removed
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
+}
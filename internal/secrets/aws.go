@@ -0,0 +1,277 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWSConfig holds the static credentials and region used for AWS KMS and Secrets Manager
+type AWSConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint replaces https://<service>.<region>.amazonaws.com, e.g. for LocalStack
+	Endpoint string
+}
+
+// awsClient calls AWS JSON 1.1 APIs with Signature Version 4
+type awsClient struct {
+	cfg    AWSConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+func newAWSClient(cfg AWSConfig) *awsClient {
+	return &awsClient{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}, now: time.Now}
+}
+
+func (c *awsClient) configured() error {
+	if c.cfg.Region == "" || c.cfg.AccessKeyID == "" || c.cfg.SecretAccessKey == "" {
+		return fmt.Errorf("%w: set AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", ErrNotConfigured)
+	}
+	return nil
+}
+
+// call invokes target (e.g. TrentService.Encrypt) on service (e.g. kms)
+func (c *awsClient) call(ctx context.Context, service, target string, in, out any) error {
+	if err := c.configured(); err != nil {
+		return err
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	endpoint := c.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + service + "." + c.cfg.Region + ".amazonaws.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signV4(req, body, c.cfg, service, c.now())
+	return doJSON(c.client, req, out)
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to req
+func signV4(req *http.Request, body []byte, cfg AWSConfig, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + cfg.Region + "/" + service + "/aws4_request"
+	signature := signatureV4(cfg, service, date, amzDate, scope, canonicalRequest)
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+cfg.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// presignV4 adds AWS Signature Version 4 query parameters to u, making it valid for expires
+// without further headers. Only the host header is signed.
+func presignV4(u *url.URL, cfg AWSConfig, service, payloadHash string, expires time.Duration, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	scope := date + "/" + cfg.Region + "/" + service + "/aws4_request"
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", cfg.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if cfg.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		path,
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		payloadHash,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", signatureV4(cfg, service, date, amzDate, scope, canonicalRequest))
+	u.RawQuery = canonicalQuery(query)
+}
+
+// signatureV4 signs a canonical request with the signing key derived for date, region and service
+func signatureV4(cfg AWSConfig, service, date, amzDate, scope, canonicalRequest string) string {
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but the RFC 3986 unreserved characters
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// AWSSecretsManagerProvider reads secrets from AWS Secrets Manager.
+// References have the form aws-sm:<secret-id>[#field]; a field selects a value of a JSON secret.
+type AWSSecretsManagerProvider struct {
+	aws *awsClient
+}
+
+// NewAWSSecretsManagerProvider creates an AWS Secrets Manager provider
+func NewAWSSecretsManagerProvider(cfg AWSConfig) *AWSSecretsManagerProvider {
+	return &AWSSecretsManagerProvider{aws: newAWSClient(cfg)}
+}
+
+// Scheme implements Provider
+func (p *AWSSecretsManagerProvider) Scheme() string { return "aws-sm" }
+
+// Resolve implements Provider
+func (p *AWSSecretsManagerProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	secretID, field := splitField(ref)
+	var resp struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := p.aws.call(ctx, "secretsmanager", "secretsmanager.GetSecretValue", map[string]string{"SecretId": secretID}, &resp); err != nil {
+		return "", err
+	}
+	if field == "" {
+		return resp.SecretString, nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal([]byte(resp.SecretString), &values); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object; remove #%s", secretID, field)
+	}
+	return selectField(secretID, values, field)
+}
+
+// AWSKMS wraps data keys with an AWS KMS symmetric key
+type AWSKMS struct {
+	aws   *awsClient
+	keyID string
+}
+
+// NewAWSKMS creates an AWS KMS client for the key ID, ARN or alias
+func NewAWSKMS(cfg AWSConfig, keyID string) *AWSKMS {
+	return &AWSKMS{aws: newAWSClient(cfg), keyID: keyID}
+}
+
+// KeyID implements KMS
+func (k *AWSKMS) KeyID() string { return "aws-kms:" + k.keyID }
+
+// Encrypt implements KMS
+func (k *AWSKMS) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	var resp struct {
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+	}
+	in := map[string]any{"KeyId": k.keyID, "Plaintext": plaintext}
+	if err := k.aws.call(ctx, "kms", "TrentService.Encrypt", in, &resp); err != nil {
+		return nil, err
+	}
+	return resp.CiphertextBlob, nil
+}
+
+// Decrypt implements KMS
+func (k *AWSKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	in := map[string]any{"KeyId": k.keyID, "CiphertextBlob": ciphertext}
+	if err := k.aws.call(ctx, "kms", "TrentService.Decrypt", in, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
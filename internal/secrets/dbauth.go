@@ -0,0 +1,136 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+const (
+	// rdsTokenLifetime is how long an RDS IAM authentication token can open connections
+	rdsTokenLifetime = 15 * time.Minute
+	// emptyPayloadHash is the SHA-256 of an empty body, which RDS authentication tokens sign
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// gcpSQLLoginScope lets an access token sign in to Cloud SQL as its IAM principal
+	gcpSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+)
+
+// PasswordSource supplies the password of each new database connection, for credentials
+// that expire or rotate while the connection pool lives
+type PasswordSource interface {
+	Password(ctx context.Context) (string, error)
+}
+
+// RDSIAMAuth signs in to Amazon RDS and Aurora as an IAM principal. Its passwords are
+// authentication tokens: presigned rds-db:connect requests that open connections for 15
+// minutes. Connections already open are unaffected when a token expires.
+type RDSIAMAuth struct {
+	cfg  AWSConfig
+	host string
+	user string
+	now  func() time.Time
+}
+
+// NewRDSIAMAuth creates the password source for user on the database at host and port
+func NewRDSIAMAuth(cfg AWSConfig, host, port, user string) *RDSIAMAuth {
+	return &RDSIAMAuth{cfg: cfg, host: net.JoinHostPort(host, port), user: user, now: time.Now}
+}
+
+// Password implements PasswordSource; tokens are signed locally, so each connection gets a new one
+func (a *RDSIAMAuth) Password(ctx context.Context) (string, error) {
+	if a.cfg.Region == "" || a.cfg.AccessKeyID == "" || a.cfg.SecretAccessKey == "" {
+		return "", fmt.Errorf("%w: set AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", ErrNotConfigured)
+	}
+	u := &url.URL{Scheme: "https", Host: a.host, Path: "/", RawQuery: url.Values{
+		"Action": {"connect"},
+		"DBUser": {a.user},
+	}.Encode()}
+	presignV4(u, a.cfg, "rds-db", emptyPayloadHash, rdsTokenLifetime, a.now())
+	// The token is the presigned URL without its scheme
+	return u.Host + u.Path + "?" + u.RawQuery, nil
+}
+
+// CloudSQLIAMAuth signs in to Cloud SQL for PostgreSQL as an IAM principal. Its passwords are
+// OAuth2 access tokens of the service account, cached until a minute before they expire.
+type CloudSQLIAMAuth struct {
+	gcp *gcpClient
+}
+
+// NewCloudSQLIAMAuth creates the password source for the configured Google Cloud service account
+func NewCloudSQLIAMAuth(cfg GCPConfig) *CloudSQLIAMAuth {
+	c := newGCPClient(cfg)
+	c.scope = gcpSQLLoginScope
+	return &CloudSQLIAMAuth{gcp: c}
+}
+
+// Password implements PasswordSource
+func (a *CloudSQLIAMAuth) Password(ctx context.Context) (string, error) {
+	token, err := a.gcp.accessToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain a Google Cloud access token: %w", err)
+	}
+	return token, nil
+}
+
+// RefreshingSecret resolves a secret reference again once every interval, for credentials a
+// secret manager rotates, such as Vault database secrets or a Kubernetes secret file. When a
+// refresh fails, the last value keeps being used until the next interval.
+type RefreshingSecret struct {
+	resolver *Resolver
+	ref      string
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	value    string
+	resolved time.Time
+}
+
+// NewRefreshingSecret creates a password source for the reference ref
+func NewRefreshingSecret(resolver *Resolver, ref string, interval time.Duration) *RefreshingSecret {
+	return &RefreshingSecret{resolver: resolver, ref: ref, interval: interval, now: time.Now}
+}
+
+// Password implements PasswordSource
+func (s *RefreshingSecret) Password(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.value != "" && now.Before(s.resolved.Add(s.interval)) {
+		return s.value, nil
+	}
+	value, err := s.resolver.Resolve(ctx, s.ref)
+	if err != nil {
+		if s.value == "" {
+			return "", err
+		}
+		slog.WarnContext(ctx, "failed to refresh secret, keeping the previous value",
+			logger.String("reference", s.ref),
+			logger.Error(err),
+		)
+		s.resolved = now
+		return s.value, nil
+	}
+	s.value, s.resolved = value, now
+	return value, nil
+}
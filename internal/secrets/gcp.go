@@ -0,0 +1,255 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	gcpScope            = "https://www.googleapis.com/auth/cloud-platform"
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcpKMSEndpoint      = "https://cloudkms.googleapis.com/v1/"
+	gcpSecretsEndpoint  = "https://secretmanager.googleapis.com/v1/"
+)
+
+// GCPConfig configures access to Google Cloud
+type GCPConfig struct {
+	// CredentialsFile is a service account key file. When empty, tokens come from the
+	// metadata server of the GCE, GKE or Cloud Run instance.
+	CredentialsFile string
+}
+
+// gcpClient calls Google Cloud REST APIs with a cached OAuth2 access token
+type gcpClient struct {
+	cfg              GCPConfig
+	client           *http.Client
+	metadataTokenURL string
+	// scope of the access tokens requested with a service account key
+	scope string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newGCPClient(cfg GCPConfig) *gcpClient {
+	return &gcpClient{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}, metadataTokenURL: gcpMetadataTokenURL, scope: gcpScope}
+}
+
+func (c *gcpClient) do(ctx context.Context, method, url string, in, out any) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to obtain a Google Cloud access token: %w", err)
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(c.client, req, out)
+}
+
+func (c *gcpClient) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	var req *http.Request
+	var err error
+	if c.cfg.CredentialsFile != "" {
+		req, err = c.serviceAccountTokenRequest(ctx)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, c.metadataTokenURL, nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doJSON(c.client, req, &resp); err != nil {
+		return "", err
+	}
+	c.token = resp.AccessToken
+	// Refresh a minute early so a token never expires in flight
+	c.expires = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+	return c.token, nil
+}
+
+// serviceAccountTokenRequest builds an OAuth2 JWT bearer grant (RFC 7523) signed with the
+// service account key
+func (c *gcpClient) serviceAccountTokenRequest(ctx context.Context) (*http.Request, error) {
+	data, err := os.ReadFile(c.cfg.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var creds struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %w", c.cfg.CredentialsFile, err)
+	}
+	if creds.Type != "service_account" {
+		return nil, fmt.Errorf("unsupported credentials type %q in %s: use a service account key", creds.Type, c.cfg.CredentialsFile)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(creds.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in %s: %w", c.cfg.CredentialsFile, err)
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   creds.ClientEmail,
+		"scope": c.scope,
+		"aud":   creds.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = creds.PrivateKeyID
+	assertion, err := token.SignedString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// GCPSecretManagerProvider reads secrets from Google Cloud Secret Manager. References have the form
+// gcp-sm:projects/<project>/secrets/<name>[/versions/<version>][#field]; the latest version is read
+// by default and a field selects a value of a JSON secret.
+type GCPSecretManagerProvider struct {
+	gcp      *gcpClient
+	endpoint string
+}
+
+// NewGCPSecretManagerProvider creates a Secret Manager provider
+func NewGCPSecretManagerProvider(cfg GCPConfig) *GCPSecretManagerProvider {
+	return &GCPSecretManagerProvider{gcp: newGCPClient(cfg), endpoint: gcpSecretsEndpoint}
+}
+
+// Scheme implements Provider
+func (p *GCPSecretManagerProvider) Scheme() string { return "gcp-sm" }
+
+// Resolve implements Provider
+func (p *GCPSecretManagerProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	name, field := splitField(ref)
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/secrets/") {
+		return "", fmt.Errorf("invalid gcp-sm reference %q: use projects/<project>/secrets/<name>[/versions/<version>]", ref)
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := p.gcp.do(ctx, http.MethodGet, p.endpoint+name+":access", nil, &resp); err != nil {
+		return "", err
+	}
+	secret, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: invalid payload: %w", name, err)
+	}
+	if field == "" {
+		return string(secret), nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal(secret, &values); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object; remove #%s", name, field)
+	}
+	return selectField(name, values, field)
+}
+
+// GCPKMS wraps data keys with a Cloud KMS symmetric key
+type GCPKMS struct {
+	gcp      *gcpClient
+	keyName  string
+	endpoint string
+}
+
+// NewGCPKMS creates a Cloud KMS client for the key resource name
+// projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>
+func NewGCPKMS(cfg GCPConfig, keyName string) *GCPKMS {
+	return &GCPKMS{gcp: newGCPClient(cfg), keyName: keyName, endpoint: gcpKMSEndpoint}
+}
+
+// KeyID implements KMS
+func (k *GCPKMS) KeyID() string { return "gcp-kms:" + k.keyName }
+
+// Encrypt implements KMS
+func (k *GCPKMS) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	var resp struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := k.gcp.do(ctx, http.MethodPost, k.endpoint+k.keyName+":encrypt", map[string]any{"plaintext": plaintext}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+// Decrypt implements KMS
+func (k *GCPKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := k.gcp.do(ctx, http.MethodPost, k.endpoint+k.keyName+":decrypt", map[string]any{"ciphertext": ciphertext}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
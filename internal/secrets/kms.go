@@ -0,0 +1,54 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+)
+
+// KMS providers selectable with KMS_PROVIDER
+const (
+	KMSProviderNone = "none"
+	KMSProviderAWS  = "aws"
+	KMSProviderGCP  = "gcp"
+)
+
+// KMS wraps and unwraps data keys with a key that never leaves an external key management service
+type KMS interface {
+	// KeyID identifies the wrapping key, e.g. aws-kms:alias/opentrusty; it is stored with every wrapped key
+	KeyID() string
+
+	// Encrypt wraps a data key
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+
+	// Decrypt unwraps a data key wrapped by Encrypt
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// NewKMS creates the KMS client of provider for keyID. It returns nil for KMSProviderNone
+// and an empty provider.
+func NewKMS(provider, keyID string, aws AWSConfig, gcp GCPConfig) (KMS, error) {
+	switch provider {
+	case "", KMSProviderNone:
+		return nil, nil
+	case KMSProviderAWS:
+		return NewAWSKMS(aws, keyID), nil
+	case KMSProviderGCP:
+		return NewGCPKMS(gcp, keyID), nil
+	default:
+		return nil, fmt.Errorf("unsupported KMS provider %q: use none, aws or gcp", provider)
+	}
+}
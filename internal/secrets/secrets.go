@@ -0,0 +1,168 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets resolves secret references in the configuration against external secret
+// managers, and wraps data keys with an external key management service (KMS).
+//
+// A reference is a value of the form scheme:ref, for example
+// vault:secret/opentrusty#db_password or file:/run/secrets/db_password.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// Secret resolution errors
+var (
+	ErrNotConfigured  = apperr.New(apperr.CodeUnavailable, "secret provider is not configured")
+	ErrSecretNotFound = apperr.New(apperr.CodeNotFound, "secret not found")
+)
+
+// Provider resolves the secret references of one scheme
+type Provider interface {
+	// Scheme is the reference prefix handled by the provider, such as "vault"
+	Scheme() string
+
+	// Resolve returns the secret named by ref, the reference without its scheme prefix
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// Resolver dispatches secret references to the provider of their scheme
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver creates a resolver for the given providers
+func NewResolver(providers ...Provider) *Resolver {
+	r := &Resolver{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.Scheme()] = p
+	}
+	return r
+}
+
+// IsReference reports whether value is a reference of a known scheme.
+// Other values are plain secrets.
+func (r *Resolver) IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	_, known := r.providers[scheme]
+	return known
+}
+
+// Resolve returns the secret named by a reference, or value itself when it is not a reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !r.IsReference(value) {
+		return value, nil
+	}
+	scheme, ref, _ := strings.Cut(value, ":")
+	secret, err := r.providers[scheme].Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", scheme, err)
+	}
+	return secret, nil
+}
+
+// FileProvider reads secrets from files, such as Docker and Kubernetes secrets mounted
+// under /run/secrets. A trailing newline is removed.
+type FileProvider struct{}
+
+// Scheme implements Provider
+func (FileProvider) Scheme() string { return "file" }
+
+// Resolve implements Provider; ref is the file path
+func (FileProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, ref)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// splitField splits a reference into the secret path and the optional #field selecting
+// one value of a structured secret
+func splitField(ref string) (path, field string) {
+	path, field, _ = strings.Cut(ref, "#")
+	return path, field
+}
+
+// selectField returns the field of a structured secret. Without a field the secret must hold
+// exactly one value.
+func selectField(ref string, values map[string]any, field string) (string, error) {
+	if field == "" {
+		if len(values) != 1 {
+			return "", fmt.Errorf("secret %s holds %d values; select one with #field", ref, len(values))
+		}
+		for _, v := range values {
+			return fmt.Sprint(v), nil
+		}
+	}
+	v, ok := values[field]
+	if !ok {
+		return "", fmt.Errorf("%w: field %q of %s", ErrSecretNotFound, field, ref)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// doJSON sends req and decodes a JSON response into out. Error responses are returned with
+// the start of their body, which the secret managers use for error details.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, req.URL.Path)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, truncate(body, 200))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", req.Method, req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+func truncate(b []byte, n int) string {
+	s := strings.TrimSpace(string(b))
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultConfig configures access to HashiCorp Vault
+type VaultConfig struct {
+	Addr      string // e.g. https://vault.example.com:8200
+	Token     string
+	Namespace string // Vault Enterprise namespace, optional
+}
+
+// VaultProvider reads secrets from a Vault KV version 2 secrets engine.
+// References have the form vault:<mount>/<path>#<field>, e.g. vault:secret/opentrusty#db_password.
+type VaultProvider struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultProvider creates a Vault provider
+func NewVaultProvider(cfg VaultConfig) *VaultProvider {
+	return &VaultProvider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Scheme implements Provider
+func (p *VaultProvider) Scheme() string { return "vault" }
+
+// Resolve implements Provider
+func (p *VaultProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	if p.cfg.Addr == "" || p.cfg.Token == "" {
+		return "", fmt.Errorf("%w: set VAULT_ADDR and VAULT_TOKEN", ErrNotConfigured)
+	}
+	path, field := splitField(ref)
+	mount, secretPath, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || secretPath == "" {
+		return "", fmt.Errorf("invalid vault reference %q: use <mount>/<path>#<field>", ref)
+	}
+
+	url := strings.TrimRight(p.cfg.Addr, "/") + "/v1/" + mount + "/data/" + secretPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+
+	var resp struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(p.client, req, &resp); err != nil {
+		return "", err
+	}
+	return selectField(path, resp.Data.Data, field)
+}
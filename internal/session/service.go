@@ -19,20 +19,29 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
 )
 
+// revokeBatchSize bounds the sessions deleted per statement when revoking in bulk, so that a large
+// tenant does not hold row locks on the sessions table for the whole revocation
+const revokeBatchSize = 500
+
 // Service provides session management business logic
 type Service struct {
 	repo        Repository
+	auditLogger audit.Logger
 	lifetime    time.Duration
 	idleTimeout time.Duration
 }
 
 // NewService creates a new session service
-func NewService(repo Repository, lifetime, idleTimeout time.Duration) *Service {
+func NewService(repo Repository, auditLogger audit.Logger, lifetime, idleTimeout time.Duration) *Service {
 	return &Service{
 		repo:        repo,
+		auditLogger: auditLogger,
 		lifetime:    lifetime,
 		idleTimeout: idleTimeout,
 	}
@@ -40,19 +49,36 @@ func NewService(repo Repository, lifetime, idleTimeout time.Duration) *Service {
 
 // Create creates a new session for a user
 func (s *Service) Create(ctx context.Context, tenantID *string, userID, ipAddress, userAgent, namespace string) (*Session, error) {
-	session := &Session{
-		ID:         generateSessionID(),
-		TenantID:   tenantID,
-		UserID:     userID,
-		IPAddress:  ipAddress,
-		UserAgent:  userAgent,
-		Namespace:  namespace,
-		ExpiresAt:  time.Now().Add(s.lifetime),
-		CreatedAt:  time.Now(),
-		LastSeenAt: time.Now(),
-	}
-
-	if err := s.repo.Create(session); err != nil {
+	return s.create(ctx, &Session{
+		TenantID:  tenantID,
+		UserID:    userID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Namespace: namespace,
+	})
+}
+
+// CreatePasswordChange creates a session that can only be used to change the user's password, for a
+// user who signed in with an expired or temporary password
+func (s *Service) CreatePasswordChange(ctx context.Context, tenantID *string, userID, ipAddress, userAgent, namespace string) (*Session, error) {
+	return s.create(ctx, &Session{
+		TenantID:               tenantID,
+		UserID:                 userID,
+		IPAddress:              ipAddress,
+		UserAgent:              userAgent,
+		Namespace:              namespace,
+		PasswordChangeRequired: true,
+	})
+}
+
+func (s *Service) create(ctx context.Context, session *Session) (*Session, error) {
+	now := time.Now()
+	session.ID = generateSessionID()
+	session.ExpiresAt = now.Add(s.lifetime)
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	if err := s.repo.Create(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
@@ -61,20 +87,20 @@ func (s *Service) Create(ctx context.Context, tenantID *string, userID, ipAddres
 
 // Get retrieves and validates a session
 func (s *Service) Get(ctx context.Context, sessionID string) (*Session, error) {
-	session, err := s.repo.Get(sessionID)
+	session, err := s.repo.Get(ctx, sessionID)
 	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
 	// Check if session is expired
 	if session.IsExpired() {
-		s.repo.Delete(sessionID)
+		s.repo.Delete(ctx, sessionID)
 		return nil, ErrSessionExpired
 	}
 
 	// Check if session is idle
 	if session.IsIdle(s.idleTimeout) {
-		s.repo.Delete(sessionID)
+		s.repo.Delete(ctx, sessionID)
 		return nil, ErrSessionExpired
 	}
 
@@ -89,22 +115,154 @@ func (s *Service) Refresh(ctx context.Context, sessionID string) error {
 	}
 
 	session.LastSeenAt = time.Now()
-	return s.repo.Update(session)
+	return s.repo.Update(ctx, session)
+}
+
+// Reasons a renewal is unusual
+const (
+	RenewalAddressChanged   = "address_changed"
+	RenewalUserAgentChanged = "user_agent_changed"
+)
+
+// Renewal is the lifetime left to a session after it was renewed
+type Renewal struct {
+	ExpiresAt     time.Time // Absolute expiry, which renewal never extends
+	IdleExpiresAt time.Time // When the session ends without further activity, at the latest ExpiresAt
+	Unusual       []string  // RenewalAddressChanged and RenewalUserAgentChanged, if any
+}
+
+// Renew extends the idle timeout of a session returned by Get (sliding expiry) and reports how
+// long it has left. A renewal from another address or user agent than the session last saw is
+// audited as unusual, and the new ones are recorded, so a session moving to another client is
+// audited once.
+func (s *Service) Renew(ctx context.Context, session *Session, ipAddress, userAgent string) (*Renewal, error) {
+	var unusual []string
+	if addressHost(session.IPAddress) != addressHost(ipAddress) {
+		unusual = append(unusual, RenewalAddressChanged)
+	}
+	if session.UserAgent != userAgent {
+		unusual = append(unusual, RenewalUserAgentChanged)
+	}
+	previousIP, previousUserAgent := session.IPAddress, session.UserAgent
+
+	session.LastSeenAt = time.Now()
+	session.IPAddress = ipAddress
+	session.UserAgent = userAgent
+	if err := s.repo.Update(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to renew session: %w", err)
+	}
+
+	if len(unusual) > 0 {
+		tenantID := ""
+		if session.TenantID != nil {
+			tenantID = *session.TenantID
+		}
+		s.auditLogger.Log(ctx, audit.Event{
+			Type:      audit.TypeSessionRenewalUnusual,
+			TenantID:  tenantID,
+			ActorID:   session.UserID,
+			Resource:  audit.ResourceSession,
+			IPAddress: ipAddress,
+			UserAgent: userAgent,
+			Payload: &audit.SessionRenewalPayload{
+				SessionID:         session.ID,
+				Reasons:           unusual,
+				PreviousIPAddress: previousIP,
+				PreviousUserAgent: previousUserAgent,
+			},
+		})
+	}
+
+	idleExpiresAt := session.LastSeenAt.Add(s.idleTimeout)
+	if idleExpiresAt.After(session.ExpiresAt) {
+		idleExpiresAt = session.ExpiresAt
+	}
+	return &Renewal{ExpiresAt: session.ExpiresAt, IdleExpiresAt: idleExpiresAt, Unusual: unusual}, nil
+}
+
+// addressHost drops the port a client address may carry, which changes with every connection
+func addressHost(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return address
 }
 
 // Destroy destroys a session
 func (s *Service) Destroy(ctx context.Context, sessionID string) error {
-	return s.repo.Delete(sessionID)
+	return s.repo.Delete(ctx, sessionID)
 }
 
 // DestroyAllForUser destroys all sessions for a user
 func (s *Service) DestroyAllForUser(ctx context.Context, userID string) error {
-	return s.repo.DeleteByUserID(userID)
+	return s.repo.DeleteByUserID(ctx, userID)
+}
+
+// RevokeAllForTenant destroys every session of a tenant, for example when the tenant is suspended,
+// and returns how many were destroyed
+func (s *Service) RevokeAllForTenant(ctx context.Context, tenantID, actorID string) (int64, error) {
+	n, err := revokeInBatches(ctx, func(ctx context.Context, limit int) (int64, error) {
+		return s.repo.DeleteByTenantID(ctx, tenantID, limit)
+	})
+	if n > 0 {
+		s.auditLogger.Log(ctx, audit.Event{
+			Type:     audit.TypeSessionsRevoked,
+			TenantID: tenantID,
+			ActorID:  actorID,
+			Resource: audit.ResourceSession,
+			Payload:  &audit.SessionsRevokedPayload{Reason: "tenant", Count: n},
+		})
+	}
+	if err != nil {
+		return n, fmt.Errorf("failed to revoke tenant sessions: %w", err)
+	}
+	return n, nil
+}
+
+// RevokeAllForClient destroys the sessions of every user holding tokens issued to a client, for
+// example when the client is compromised, and returns how many were destroyed. The users' tokens
+// are left to the caller.
+func (s *Service) RevokeAllForClient(ctx context.Context, tenantID, clientID, actorID string) (int64, error) {
+	n, err := revokeInBatches(ctx, func(ctx context.Context, limit int) (int64, error) {
+		return s.repo.DeleteByClientID(ctx, clientID, limit)
+	})
+	if n > 0 {
+		s.auditLogger.Log(ctx, audit.Event{
+			Type:     audit.TypeSessionsRevoked,
+			TenantID: tenantID,
+			ActorID:  actorID,
+			Resource: audit.ResourceSession,
+			Payload:  &audit.SessionsRevokedPayload{Reason: "client", ClientID: clientID, Count: n},
+		})
+	}
+	if err != nil {
+		return n, fmt.Errorf("failed to revoke client sessions: %w", err)
+	}
+	return n, nil
+}
+
+// revokeInBatches calls del with revokeBatchSize until a batch comes back short and returns the
+// total removed, including the batches removed before an error
+func revokeInBatches(ctx context.Context, del func(ctx context.Context, limit int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		n, err := del(ctx, revokeBatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < revokeBatchSize {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
 }
 
-// CleanupExpired removes all expired sessions
-func (s *Service) CleanupExpired(ctx context.Context) error {
-	return s.repo.DeleteExpired()
+// CleanupExpired removes up to limit expired sessions and returns how many were removed
+func (s *Service) CleanupExpired(ctx context.Context, limit int) (int64, error) {
+	return s.repo.DeleteExpired(ctx, limit)
 }
 
 // generateSessionID generates a cryptographically secure session ID
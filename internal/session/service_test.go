@@ -0,0 +1,161 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// recordingAuditLogger keeps the logged events
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (l *recordingAuditLogger) Log(ctx context.Context, event audit.Event) {
+	l.events = append(l.events, event)
+}
+
+// TestPurpose: Validates that revoking the sessions of a tenant or a client removes all of them in batches and is audited.
+// Scope: Unit Test
+// Security: Session Management (a suspended tenant or compromised client must not keep signed-in users)
+// Expected: Every matching session is destroyed across several batches, other sessions are kept, and one sessions_revoked event reports the count; nothing is audited when there was nothing to revoke.
+// Test Case ID: SES-01
+func TestService_RevokeAll(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	repo := memory.NewSessionRepository(db)
+	auditLogger := &recordingAuditLogger{}
+	svc := session.NewService(repo, auditLogger, time.Hour, time.Hour)
+
+	create := func(id, tenantID, userID string) {
+		t.Helper()
+		if err := repo.Create(ctx, &session.Session{
+			ID: id, TenantID: &tenantID, UserID: userID,
+			ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(), LastSeenAt: time.Now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const tenantSessions = 1200
+	for i := range tenantSessions {
+		create(fmt.Sprintf("a%d", i), "tenant-a", fmt.Sprintf("user-%d", i))
+	}
+	create("b0", "tenant-b", "user-b0")
+	create("b1", "tenant-b", "user-b1")
+
+	n, err := svc.RevokeAllForTenant(ctx, "tenant-a", "admin-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != tenantSessions {
+		t.Errorf("expected %d sessions revoked, got %d", tenantSessions, n)
+	}
+	if _, err := repo.Get(ctx, "b0"); err != nil {
+		t.Errorf("other tenant's session was revoked: %v", err)
+	}
+	if len(auditLogger.events) != 1 {
+		t.Fatalf("expected one audit event, got %d", len(auditLogger.events))
+	}
+	event := auditLogger.events[0]
+	payload, ok := event.Payload.(*audit.SessionsRevokedPayload)
+	if event.Type != audit.TypeSessionsRevoked || event.TenantID != "tenant-a" || event.ActorID != "admin-1" || !ok || payload.Reason != "tenant" || payload.Count != tenantSessions {
+		t.Errorf("unexpected audit event: %+v", event)
+	}
+
+	if err := memory.NewAccessTokenRepository(db).Create(ctx, &oauth2.AccessToken{
+		ID: "at-1", TenantID: "tenant-b", TokenHash: "hash", ClientID: "client-1", UserID: "user-b1",
+		ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	n, err = svc.RevokeAllForClient(ctx, "tenant-b", "client-1", "admin-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 session revoked, got %d", n)
+	}
+	if _, err := repo.Get(ctx, "b0"); err != nil {
+		t.Errorf("session of a user without the client's tokens was revoked: %v", err)
+	}
+	if payload, ok := auditLogger.events[len(auditLogger.events)-1].Payload.(*audit.SessionsRevokedPayload); !ok || payload.Reason != "client" || payload.ClientID != "client-1" {
+		t.Errorf("unexpected audit event: %+v", auditLogger.events[len(auditLogger.events)-1])
+	}
+
+	if _, err := svc.RevokeAllForClient(ctx, "tenant-b", "client-1", "admin-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(auditLogger.events) != 2 {
+		t.Errorf("expected no audit event for an empty revocation, got %d events", len(auditLogger.events))
+	}
+}
+
+// TestPurpose: Validates that renewing a session slides its idle expiry and audits unusual renewals.
+// Scope: Unit Test
+// Security: Session Management (renewal never outlives the absolute lifetime; a session moving to another client is visible in the audit trail)
+// Expected: Renewal moves the idle expiry forward, capped by the absolute expiry; another port is not unusual; a renewal from a new address and user agent is audited once with both reasons and the previous values.
+// Test Case ID: SES-02
+func TestService_Renew(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewSessionRepository(memory.New())
+	auditLogger := &recordingAuditLogger{}
+	svc := session.NewService(repo, auditLogger, time.Hour, 30*time.Minute)
+
+	tenantID := "tenant-a"
+	sess, err := svc.Create(ctx, &tenantID, "user-1", "10.0.0.1", "browser/1", session.NamespaceAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renewal, err := svc.Renew(ctx, sess, "10.0.0.1:52100", "browser/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idle := time.Until(renewal.IdleExpiresAt); idle < 29*time.Minute || idle > 30*time.Minute {
+		t.Errorf("expected the idle expiry 30 minutes out, got %s", idle)
+	}
+	if !renewal.ExpiresAt.Equal(sess.ExpiresAt) || len(renewal.Unusual) != 0 || len(auditLogger.events) != 0 {
+		t.Errorf("expected a quiet renewal keeping the absolute expiry, got %+v and %d events", renewal, len(auditLogger.events))
+	}
+
+	sess.ExpiresAt = time.Now().Add(10 * time.Minute)
+	for range 2 {
+		if renewal, err = svc.Renew(ctx, sess, "10.9.9.9", "other/2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !renewal.IdleExpiresAt.Equal(sess.ExpiresAt) {
+		t.Errorf("expected the idle expiry to be capped at the absolute expiry, got %s", renewal.IdleExpiresAt)
+	}
+	if len(auditLogger.events) != 1 {
+		t.Fatalf("expected one session_renewal_unusual event, got %d", len(auditLogger.events))
+	}
+	event := auditLogger.events[0]
+	payload, ok := event.Payload.(*audit.SessionRenewalPayload)
+	if event.Type != audit.TypeSessionRenewalUnusual || !ok || len(payload.Reasons) != 2 || payload.PreviousIPAddress != "10.0.0.1:52100" || event.IPAddress != "10.9.9.9" {
+		t.Errorf("unexpected event %+v with payload %+v", event, event.Payload)
+	}
+	if stored, err := repo.Get(ctx, sess.ID); err != nil || stored.IPAddress != "10.9.9.9" || stored.UserAgent != "other/2" {
+		t.Errorf("expected the new address and user agent to be recorded, got %+v, %v", stored, err)
+	}
+}
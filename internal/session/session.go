@@ -15,15 +15,24 @@
 package session
 
 import (
-	"errors"
+	"context"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
 )
 
 // Domain errors
 var (
-	ErrSessionNotFound = errors.New("session not found")
-	ErrSessionExpired  = errors.New("session expired")
-	ErrSessionInvalid  = errors.New("session invalid")
+	ErrSessionNotFound = apperr.New(apperr.CodeNotFound, "session not found")
+	ErrSessionExpired  = apperr.New(apperr.CodeUnauthenticated, "session expired")
+	ErrSessionInvalid  = apperr.New(apperr.CodeUnauthenticated, "session invalid")
+)
+
+// Session namespaces keep the sessions of the two planes apart: an auth-plane session can only
+// authorize OAuth2 clients and an admin-plane session can only use the admin API
+const (
+	NamespaceAuth  = "auth"
+	NamespaceAdmin = "admin"
 )
 
 // Session represents a user session
@@ -36,7 +45,10 @@ type Session struct {
 	ExpiresAt  time.Time
 	CreatedAt  time.Time
 	LastSeenAt time.Time
-	Namespace  string // "auth" or "admin"
+	Namespace  string // NamespaceAuth or NamespaceAdmin
+	// PasswordChangeRequired restricts the session to changing the user's password, which has
+	// expired or was set by an admin as temporary
+	PasswordChangeRequired bool
 }
 
 // IsExpired checks if the session has expired
@@ -52,20 +64,27 @@ func (s *Session) IsIdle(idleTimeout time.Duration) bool {
 // Repository defines the interface for session persistence
 type Repository interface {
 	// Create creates a new session
-	Create(session *Session) error
+	Create(ctx context.Context, session *Session) error
 
 	// Get retrieves a session by ID
-	Get(sessionID string) (*Session, error)
+	Get(ctx context.Context, sessionID string) (*Session, error)
 
-	// Update updates session last seen time
-	Update(session *Session) error
+	// Update updates the session's last seen time, address and user agent
+	Update(ctx context.Context, session *Session) error
 
 	// Delete deletes a session
-	Delete(sessionID string) error
+	Delete(ctx context.Context, sessionID string) error
 
 	// DeleteByUserID deletes all sessions for a user
-	DeleteByUserID(userID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+
+	// DeleteByTenantID deletes up to limit sessions of a tenant and returns how many were removed
+	DeleteByTenantID(ctx context.Context, tenantID string, limit int) (int64, error)
+
+	// DeleteByClientID deletes up to limit sessions of users holding tokens issued to a client
+	// (by its public client_id) and returns how many were removed
+	DeleteByClientID(ctx context.Context, clientID string, limit int) (int64, error)
 
-	// DeleteExpired deletes all expired sessions
-	DeleteExpired() error
+	// DeleteExpired deletes up to limit expired sessions and returns how many were removed
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
 }
@@ -0,0 +1,92 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package startup retries the connections a process needs before it can serve, such as the
+// database and the secret managers, so a dependency that comes up a little later than the
+// server does not make it exit.
+package startup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// Policy says how long to keep trying to reach a dependency
+type Policy struct {
+	// Timeout is how long to keep retrying after the first failure; zero tries once
+	Timeout time.Duration
+	// Forever retries until the context is done, ignoring Timeout
+	Forever bool
+	// Backoff is the delay after the first failure; it doubles up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one that retrying cannot fix, such as a secret that does not exist
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// Do calls fn until it succeeds, returns a Permanent error, or the policy gives up. dependency
+// names what fn connects to in the log and the returned error.
+func (p Policy) Do(ctx context.Context, dependency string, fn func(ctx context.Context) error) error {
+	deadline := time.Now().Add(p.Timeout)
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				slog.InfoContext(ctx, "dependency available", logger.Component("startup"), "dependency", dependency, "attempts", attempt)
+			}
+			return nil
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if !p.Forever && !time.Now().Add(backoff).Before(deadline) {
+			if attempt > 1 {
+				return fmt.Errorf("%s unavailable after %d attempts: %w", dependency, attempt, err)
+			}
+			return err
+		}
+
+		slog.WarnContext(ctx, "dependency unavailable, retrying",
+			logger.Component("startup"),
+			"dependency", dependency,
+			"attempt", attempt,
+			"retry_in", backoff.String(),
+			logger.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s unavailable: %w", dependency, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, p.MaxBackoff)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package startup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestPurpose: Validates how startup retries a dependency that is not reachable yet.
+// Scope: Unit Test
+// Security: Availability (a database that starts after the server does not stop it; a missing secret still fails fast)
+// Expected: Failures are retried until success; a zero timeout tries once; the timeout and Permanent errors end the retries; Forever retries until the context is done.
+// Test Case ID: STU-01
+func TestPolicy_Do(t *testing.T) {
+	ctx := context.Background()
+	unavailable := errors.New("connection refused")
+	policy := Policy{Timeout: time.Second, Backoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+	attempts := 0
+	err := policy.Do(ctx, "database", func(ctx context.Context) error {
+		if attempts++; attempts < 3 {
+			return unavailable
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("expected success on the third attempt, got %v after %d", err, attempts)
+	}
+
+	attempts = 0
+	once := Policy{Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	if err := once.Do(ctx, "database", func(ctx context.Context) error {
+		attempts++
+		return unavailable
+	}); err != unavailable || attempts != 1 {
+		t.Errorf("expected a zero timeout to try once, got %v after %d", err, attempts)
+	}
+
+	short := Policy{Timeout: 20 * time.Millisecond, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	attempts = 0
+	if err := short.Do(ctx, "database", func(ctx context.Context) error {
+		attempts++
+		return unavailable
+	}); !errors.Is(err, unavailable) || attempts < 2 {
+		t.Errorf("expected retries to end with the last error, got %v after %d", err, attempts)
+	}
+
+	attempts = 0
+	missing := errors.New("secret not found")
+	if err := policy.Do(ctx, "DB_PASSWORD", func(ctx context.Context) error {
+		attempts++
+		return Permanent(missing)
+	}); err != missing || attempts != 1 {
+		t.Errorf("expected a permanent error to end the retries, got %v after %d", err, attempts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	forever := Policy{Forever: true, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	attempts = 0
+	if err := forever.Do(ctx, "database", func(ctx context.Context) error {
+		if attempts++; attempts == 5 {
+			cancel()
+		}
+		return unavailable
+	}); !errors.Is(err, unavailable) || attempts != 5 {
+		t.Errorf("expected Forever to retry until the context is done, got %v after %d", err, attempts)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// AccountDeletionRepository implements identity.AccountDeletionRepository
+type AccountDeletionRepository struct {
+	db *DB
+}
+
+// NewAccountDeletionRepository creates a new account deletion repository
+func NewAccountDeletionRepository(db *DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{db: db}
+}
+
+// Create stores an account deletion, replacing any earlier one of the user
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *identity.AccountDeletion) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d := *deletion
+	r.db.accountDeletions[d.UserID] = &d
+	return nil
+}
+
+// Get retrieves the account deletion of a user
+func (r *AccountDeletionRepository) Get(ctx context.Context, userID string) (*identity.AccountDeletion, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	d, ok := r.db.accountDeletions[userID]
+	if !ok {
+		return nil, identity.ErrAccountDeletionNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+// Delete removes the account deletion of a user
+func (r *AccountDeletionRepository) Delete(ctx context.Context, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.accountDeletions[userID]; !ok {
+		return identity.ErrAccountDeletionNotFound
+	}
+	delete(r.db.accountDeletions, userID)
+	return nil
+}
+
+// ListDue lists up to limit account deletions whose grace period ended before the given time
+func (r *AccountDeletionRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*identity.AccountDeletion, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var due []*identity.AccountDeletion
+	for _, d := range r.db.accountDeletions {
+		if d.DeleteAt.Before(before) {
+			cp := *d
+			due = append(due, &cp)
+		}
+	}
+	slices.SortFunc(due, func(a, b *identity.AccountDeletion) int { return a.DeleteAt.Compare(b.DeleteAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
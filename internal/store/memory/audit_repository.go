@@ -0,0 +1,204 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// AuditRepository implements audit.Repository
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new audit event repository
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func copyEvent(e *audit.Event) *audit.Event {
+	cp := *e
+	cp.Metadata = maps.Clone(e.Metadata)
+	return &cp
+}
+
+// Create stores an audit event
+func (r *AuditRepository) Create(ctx context.Context, event *audit.Event) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.auditEvents = append(r.db.auditEvents, copyEvent(event))
+	return nil
+}
+
+// List retrieves a page of audit events matching the filter, ordered by ID
+func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]*audit.Event, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var matched []*audit.Event
+	for _, e := range r.db.auditEvents {
+		if e.TenantID != filter.TenantID ||
+			(filter.Type != "" && e.Type != filter.Type) ||
+			(filter.ActorID != "" && e.ActorID != filter.ActorID) ||
+			(!filter.Since.IsZero() && e.Timestamp.Before(filter.Since)) ||
+			(!filter.Until.IsZero() && !e.Timestamp.Before(filter.Until)) {
+			continue
+		}
+		matched = append(matched, copyEvent(e))
+	}
+	return page(matched, filter.Page, func(e *audit.Event) string { return e.ID }), nil
+}
+
+// ListExpired retrieves up to filter.Limit events older than filter.Before, ordered by ID
+func (r *AuditRepository) ListExpired(ctx context.Context, filter audit.ExpiredFilter) ([]*audit.Event, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var matched []*audit.Event
+	for _, e := range r.db.auditEvents {
+		if !e.Timestamp.Before(filter.Before) ||
+			(len(filter.TenantIDs) > 0 && !slices.Contains(filter.TenantIDs, e.TenantID)) ||
+			slices.Contains(filter.ExceptTenantIDs, e.TenantID) {
+			continue
+		}
+		matched = append(matched, copyEvent(e))
+	}
+	slices.SortFunc(matched, func(a, b *audit.Event) int { return strings.Compare(a.ID, b.ID) })
+	if len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	return matched, nil
+}
+
+// Delete removes audit events by ID
+func (r *AuditRepository) Delete(ctx context.Context, ids []string) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	before := len(r.db.auditEvents)
+	r.db.auditEvents = slices.DeleteFunc(r.db.auditEvents, func(e *audit.Event) bool {
+		return slices.Contains(ids, e.ID)
+	})
+	return int64(before - len(r.db.auditEvents)), nil
+}
+
+// Stats aggregates the audit events of every tenant within the filter's window
+func (r *AuditRepository) Stats(ctx context.Context, filter audit.StatsFilter) (*audit.Stats, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	stats := &audit.Stats{EventCounts: make(map[string]int64)}
+	users := make(map[string]bool)
+	tokens := make(map[audit.ClientStats]int64)
+	for _, e := range r.db.auditEvents {
+		if e.Timestamp.Before(filter.Since) || !e.Timestamp.Before(filter.Until) {
+			continue
+		}
+		stats.EventCounts[e.Type]++
+		switch e.Type {
+		case audit.TypeLoginSuccess:
+			if e.ActorID != "" {
+				users[e.ActorID] = true
+			}
+		case audit.TypeTokenIssued:
+			if clientID, _ := e.Metadata["client_id"].(string); clientID != "" {
+				tokens[audit.ClientStats{TenantID: e.TenantID, ClientID: clientID}]++
+			}
+		}
+	}
+	stats.ActiveUsers = int64(len(users))
+
+	for client, n := range tokens {
+		client.TokensIssued = n
+		stats.TopClients = append(stats.TopClients, client)
+	}
+	slices.SortFunc(stats.TopClients, func(a, b audit.ClientStats) int {
+		return cmp.Or(
+			cmp.Compare(b.TokensIssued, a.TokensIssued),
+			strings.Compare(a.TenantID, b.TenantID),
+			strings.Compare(a.ClientID, b.ClientID),
+		)
+	})
+	if len(stats.TopClients) > filter.TopClients {
+		stats.TopClients = stats.TopClients[:filter.TopClients]
+	}
+	return stats, nil
+}
+
+// AuditRetentionRepository implements audit.RetentionRepository
+type AuditRetentionRepository struct {
+	db *DB
+}
+
+// NewAuditRetentionRepository creates a new audit retention policy repository
+func NewAuditRetentionRepository(db *DB) *AuditRetentionRepository {
+	return &AuditRetentionRepository{db: db}
+}
+
+// Get retrieves a tenant's retention policy
+func (r *AuditRetentionRepository) Get(ctx context.Context, tenantID string) (*audit.RetentionPolicy, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	p, ok := r.db.retention[tenantID]
+	if !ok {
+		return nil, audit.ErrRetentionNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+// Upsert creates or replaces a tenant's retention policy
+func (r *AuditRetentionRepository) Upsert(ctx context.Context, p *audit.RetentionPolicy) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	cp := *p
+	r.db.retention[p.TenantID] = &cp
+	return nil
+}
+
+// Delete removes a tenant's retention policy
+func (r *AuditRetentionRepository) Delete(ctx context.Context, tenantID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.retention[tenantID]; !ok {
+		return audit.ErrRetentionNotFound
+	}
+	delete(r.db.retention, tenantID)
+	return nil
+}
+
+// List retrieves all retention policies
+func (r *AuditRetentionRepository) List(ctx context.Context) ([]*audit.RetentionPolicy, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	policies := make([]*audit.RetentionPolicy, 0, len(r.db.retention))
+	for _, p := range r.db.retention {
+		cp := *p
+		policies = append(policies, &cp)
+	}
+	slices.SortFunc(policies, func(a, b *audit.RetentionPolicy) int { return strings.Compare(a.TenantID, b.TenantID) })
+	return policies, nil
+}
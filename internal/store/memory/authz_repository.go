@@ -0,0 +1,461 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// ProjectRepository implements authz.ProjectRepository
+type ProjectRepository struct {
+	db *DB
+}
+
+// NewProjectRepository creates a new project repository
+func NewProjectRepository(db *DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// Create creates a new project
+func (r *ProjectRepository) Create(ctx context.Context, project *authz.Project) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.projects[project.ID]; ok {
+		return fmt.Errorf("failed to create project: %w", ErrDuplicate)
+	}
+	cp := *project
+	r.db.projects[project.ID] = &cp
+	return nil
+}
+
+// GetByID retrieves a project by ID
+func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*authz.Project, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	p, ok := r.db.projects[id]
+	if !ok || p.DeletedAt != nil {
+		return nil, authz.ErrProjectNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+// GetByName retrieves a project by name
+func (r *ProjectRepository) GetByName(ctx context.Context, name string) (*authz.Project, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, p := range r.db.projects {
+		if p.DeletedAt == nil && p.Name == name {
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, authz.ErrProjectNotFound
+}
+
+// Update updates project information
+func (r *ProjectRepository) Update(ctx context.Context, project *authz.Project) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p, ok := r.db.projects[project.ID]
+	if !ok || p.DeletedAt != nil {
+		return authz.ErrProjectNotFound
+	}
+	p.Name = project.Name
+	p.Description = project.Description
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// Delete soft-deletes a project
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p, ok := r.db.projects[id]
+	if !ok || p.DeletedAt != nil {
+		return authz.ErrProjectNotFound
+	}
+	now := time.Now()
+	p.DeletedAt = &now
+	return nil
+}
+
+// PurgeDeleted permanently removes up to limit projects soft-deleted before the given time; their
+// members go with them
+func (r *ProjectRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var n int64
+	r.db.deleteProjects(func(p *authz.Project) bool {
+		if n >= int64(limit) || p.DeletedAt == nil || !p.DeletedAt.Before(before) {
+			return false
+		}
+		n++
+		return true
+	})
+	return n, nil
+}
+
+// ListByOwner retrieves a page of projects owned by a user, ordered by ID
+func (r *ProjectRepository) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*authz.Project, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var projects []*authz.Project
+	for _, p := range r.db.projects {
+		if p.DeletedAt == nil && p.OwnerID == ownerID {
+			cp := *p
+			projects = append(projects, &cp)
+		}
+	}
+	return page(projects, p, func(p *authz.Project) string { return p.ID }), nil
+}
+
+// ListByUser retrieves a page of projects a user has a role in, ordered by ID
+func (r *ProjectRepository) ListByUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Project, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var projects []*authz.Project
+	for _, m := range r.db.projectMembers {
+		if project, ok := r.db.projects[m.ProjectID]; ok && m.UserID == userID && project.DeletedAt == nil {
+			cp := *project
+			projects = append(projects, &cp)
+		}
+	}
+	return page(projects, p, func(p *authz.Project) string { return p.ID }), nil
+}
+
+// ListByTenant retrieves a page of a tenant's projects, ordered by ID
+func (r *ProjectRepository) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*authz.Project, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var projects []*authz.Project
+	for _, p := range r.db.projects {
+		if p.DeletedAt == nil && p.TenantID == tenantID {
+			cp := *p
+			projects = append(projects, &cp)
+		}
+	}
+	return page(projects, p, func(p *authz.Project) string { return p.ID }), nil
+}
+
+func projectMemberKey(projectID, userID string) string {
+	return projectID + "\x00" + userID
+}
+
+// SetMember gives a user a role in a project, replacing the role they had
+func (r *ProjectRepository) SetMember(ctx context.Context, member *authz.ProjectMember) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if p, ok := r.db.projects[member.ProjectID]; !ok || p.DeletedAt != nil {
+		return authz.ErrProjectNotFound
+	}
+	cp := *member
+	r.db.projectMembers[projectMemberKey(member.ProjectID, member.UserID)] = &cp
+	return nil
+}
+
+// RemoveMember removes a user from a project
+func (r *ProjectRepository) RemoveMember(ctx context.Context, projectID, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := projectMemberKey(projectID, userID)
+	if _, ok := r.db.projectMembers[key]; !ok {
+		return authz.ErrProjectMemberNotFound
+	}
+	delete(r.db.projectMembers, key)
+	return nil
+}
+
+// GetMember retrieves a user's role in a project
+func (r *ProjectRepository) GetMember(ctx context.Context, projectID, userID string) (*authz.ProjectMember, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	m, ok := r.db.projectMembers[projectMemberKey(projectID, userID)]
+	if !ok {
+		return nil, authz.ErrProjectMemberNotFound
+	}
+	cp := *m
+	return &cp, nil
+}
+
+// ListMembers retrieves the members of a project, oldest first
+func (r *ProjectRepository) ListMembers(ctx context.Context, projectID string) ([]*authz.ProjectMember, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var members []*authz.ProjectMember
+	for _, m := range r.db.projectMembers {
+		if m.ProjectID == projectID {
+			cp := *m
+			members = append(members, &cp)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].GrantedAt.Before(members[j].GrantedAt) })
+	return members, nil
+}
+
+// deleteProjects removes the projects that match, with their members. The caller holds the lock.
+func (db *DB) deleteProjects(match func(*authz.Project) bool) {
+	for id, p := range db.projects {
+		if match(p) {
+			delete(db.projects, id)
+		}
+	}
+	for key, m := range db.projectMembers {
+		if _, ok := db.projects[m.ProjectID]; !ok {
+			delete(db.projectMembers, key)
+		}
+	}
+}
+
+// RoleRepository implements authz.RoleRepository
+type RoleRepository struct {
+	db *DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func copyRole(role *authz.Role) *authz.Role {
+	cp := *role
+	cp.Permissions = slices.Clone(role.Permissions)
+	if cp.Permissions == nil {
+		cp.Permissions = []string{}
+	}
+	return &cp
+}
+
+// Create creates a new role
+func (r *RoleRepository) Create(ctx context.Context, role *authz.Role) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.roles[role.ID]; ok {
+		return fmt.Errorf("failed to create role: %w", ErrDuplicate)
+	}
+	for _, existing := range r.db.roles {
+		if existing.Name == role.Name && existing.Scope == role.Scope {
+			return fmt.Errorf("failed to create role: %w", ErrDuplicate)
+		}
+	}
+	r.db.roles[role.ID] = copyRole(role)
+	return nil
+}
+
+// GetByID retrieves a role by ID
+func (r *RoleRepository) GetByID(ctx context.Context, id string) (*authz.Role, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	role, ok := r.db.roles[id]
+	if !ok {
+		return nil, authz.ErrRoleNotFound
+	}
+	return copyRole(role), nil
+}
+
+// GetByName retrieves a role by name and scope
+func (r *RoleRepository) GetByName(ctx context.Context, name string, scope authz.Scope) (*authz.Role, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, role := range r.db.roles {
+		if role.Name == name && role.Scope == scope {
+			return copyRole(role), nil
+		}
+	}
+	return nil, authz.ErrRoleNotFound
+}
+
+// Update updates role information
+func (r *RoleRepository) Update(ctx context.Context, role *authz.Role) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.roles[role.ID]
+	if !ok {
+		return authz.ErrRoleNotFound
+	}
+	existing.Description = role.Description
+	existing.UpdatedAt = time.Now()
+	return nil
+}
+
+// Delete deletes a role and, like the SQL cascade, its assignments
+func (r *RoleRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.roles[id]; !ok {
+		return authz.ErrRoleNotFound
+	}
+	delete(r.db.roles, id)
+	for aid, a := range r.db.assignments {
+		if a.RoleID == id {
+			delete(r.db.assignments, aid)
+		}
+	}
+	return nil
+}
+
+// List retrieves all roles, optionally filtered by scope
+func (r *RoleRepository) List(ctx context.Context, scope *authz.Scope) ([]*authz.Role, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var roles []*authz.Role
+	for _, role := range r.db.roles {
+		if scope == nil || role.Scope == *scope {
+			roles = append(roles, copyRole(role))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+	return roles, nil
+}
+
+// AssignmentRepository implements authz.AssignmentRepository
+type AssignmentRepository struct {
+	db *DB
+}
+
+// NewAssignmentRepository creates a new assignment repository
+func NewAssignmentRepository(db *DB) *AssignmentRepository {
+	return &AssignmentRepository{db: db}
+}
+
+func copyAssignment(a *authz.Assignment) *authz.Assignment {
+	cp := *a
+	if a.ScopeContextID != nil {
+		id := *a.ScopeContextID
+		cp.ScopeContextID = &id
+	}
+	return &cp
+}
+
+// findAssignment returns the assignment matching the unique key; callers must hold the lock
+func (db *DB) findAssignment(userID, roleID string, scope authz.Scope, scopeContextID *string) (string, bool) {
+	for id, a := range db.assignments {
+		if a.UserID == userID && a.RoleID == roleID && a.Scope == scope && sameTenant(a.ScopeContextID, scopeContextID) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// Grant assigns a role to a user
+func (r *AssignmentRepository) Grant(ctx context.Context, assignment *authz.Assignment) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// Granting an existing assignment is a no-op, as with ON CONFLICT DO NOTHING
+	if _, ok := r.db.findAssignment(assignment.UserID, assignment.RoleID, assignment.Scope, assignment.ScopeContextID); ok {
+		return nil
+	}
+	r.db.assignments[assignment.ID] = copyAssignment(assignment)
+	return nil
+}
+
+// Revoke removes a role assignment
+func (r *AssignmentRepository) Revoke(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if id, ok := r.db.findAssignment(userID, roleID, scope, scopeContextID); ok {
+		delete(r.db.assignments, id)
+	}
+	return nil
+}
+
+// RevokeUnlessLast removes a role assignment unless it is the only one of the role at the scope
+func (r *AssignmentRepository) RevokeUnlessLast(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.revokeUnlessLast(userID, roleID, scope, scopeContextID)
+}
+
+// revokeUnlessLast removes a role assignment unless it is the only one of the role at the scope.
+// The caller holds the write lock.
+func (db *DB) revokeUnlessLast(userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	id, ok := db.findAssignment(userID, roleID, scope, scopeContextID)
+	if !ok {
+		return authz.ErrAssignmentNotFound
+	}
+	for other, a := range db.assignments {
+		if other != id && a.RoleID == roleID && a.Scope == scope && sameTenant(a.ScopeContextID, scopeContextID) {
+			delete(db.assignments, id)
+			return nil
+		}
+	}
+	return authz.ErrLastAssignment
+}
+
+// ListForUser retrieves a page of assignments for a user, ordered by ID
+func (r *AssignmentRepository) ListForUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Assignment, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var assignments []*authz.Assignment
+	for _, a := range r.db.assignments {
+		if a.UserID == userID {
+			assignments = append(assignments, copyAssignment(a))
+		}
+	}
+	return page(assignments, p, func(a *authz.Assignment) string { return a.ID }), nil
+}
+
+// ListByRole retrieves all users assigned a specific role at a scope
+func (r *AssignmentRepository) ListByRole(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var userIDs []string
+	for _, a := range r.db.assignments {
+		if a.RoleID == roleID && a.Scope == scope && sameTenant(a.ScopeContextID, scopeContextID) {
+			userIDs = append(userIDs, a.UserID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+// CheckExists checks if a specific assignment exists
+func (r *AssignmentRepository) CheckExists(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
+	userIDs, err := r.ListByRole(ctx, roleID, scope, scopeContextID)
+	if err != nil {
+		return false, err
+	}
+	return len(userIDs) > 0, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// ClientRepository implements oauth2.ClientRepository
+type ClientRepository struct {
+	db *DB
+}
+
+// NewClientRepository creates a new client repository
+func NewClientRepository(db *DB) *ClientRepository {
+	return &ClientRepository{db: db}
+}
+
+func copyClient(client *oauth2.Client) *oauth2.Client {
+	cp := *client
+	cp.RedirectURIs = slices.Clone(client.RedirectURIs)
+	cp.AllowedScopes = slices.Clone(client.AllowedScopes)
+	cp.GrantTypes = slices.Clone(client.GrantTypes)
+	cp.ResponseTypes = slices.Clone(client.ResponseTypes)
+	cp.JWKS = slices.Clone(client.JWKS)
+	cp.RequestURIs = slices.Clone(client.RequestURIs)
+	cp.AllowedOrigins = slices.Clone(client.AllowedOrigins)
+	return &cp
+}
+
+// Create creates a new OAuth2 client
+func (r *ClientRepository) Create(ctx context.Context, client *oauth2.Client) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.clients[client.ID]; ok {
+		return fmt.Errorf("failed to create client: %w", ErrDuplicate)
+	}
+	for _, existing := range r.db.clients {
+		if existing.ClientID == client.ClientID {
+			return fmt.Errorf("failed to create client: %w", ErrDuplicate)
+		}
+	}
+	client.Version = 1
+	r.db.clients[client.ID] = copyClient(client)
+	return nil
+}
+
+// GetByClientID retrieves a client by client_id
+func (r *ClientRepository) GetByClientID(ctx context.Context, clientID string) (*oauth2.Client, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, client := range r.db.clients {
+		if client.DeletedAt == nil && client.ClientID == clientID {
+			return copyClient(client), nil
+		}
+	}
+	return nil, oauth2.ErrClientNotFound
+}
+
+// GetByID retrieves a client by internal ID
+func (r *ClientRepository) GetByID(ctx context.Context, id string) (*oauth2.Client, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	client, ok := r.db.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return nil, oauth2.ErrClientNotFound
+	}
+	return copyClient(client), nil
+}
+
+// Update updates client information
+func (r *ClientRepository) Update(ctx context.Context, client *oauth2.Client) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.clients[client.ID]
+	if !ok || existing.DeletedAt != nil {
+		return oauth2.ErrClientNotFound
+	}
+	if existing.Version != client.Version {
+		return oauth2.ErrClientVersionConflict
+	}
+
+	// Identity, ownership and the secret are not updatable, as in the SQL backends
+	updated := copyClient(client)
+	updated.ClientID = existing.ClientID
+	updated.TenantID = existing.TenantID
+	updated.ClientSecretHash = existing.ClientSecretHash
+	updated.OwnerID = existing.OwnerID
+	updated.CreatedAt = existing.CreatedAt
+	updated.DeletedAt = nil
+	updated.UpdatedAt = time.Now()
+	updated.Version = existing.Version + 1
+	r.db.clients[client.ID] = updated
+	client.Version = updated.Version
+	return nil
+}
+
+// Delete soft-deletes a client
+func (r *ClientRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	client, ok := r.db.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return oauth2.ErrClientNotFound
+	}
+	now := time.Now()
+	client.DeletedAt = &now
+	return nil
+}
+
+// PurgeDeleted permanently removes up to limit clients soft-deleted before the given time
+func (r *ClientRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var n int64
+	for id, c := range r.db.clients {
+		if n >= int64(limit) {
+			break
+		}
+		if c.DeletedAt != nil && c.DeletedAt.Before(before) {
+			delete(r.db.clients, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// ListByOwner retrieves a page of clients for an owner, ordered by ID
+func (r *ClientRepository) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*oauth2.Client, error) {
+	return r.list(p, func(c *oauth2.Client) bool { return c.OwnerID == ownerID }), nil
+}
+
+// ListByTenant retrieves a page of clients for a tenant, ordered by ID
+func (r *ClientRepository) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*oauth2.Client, error) {
+	return r.list(p, func(c *oauth2.Client) bool { return c.TenantID == tenantID }), nil
+}
+
+// list returns a page of matching live clients
+func (r *ClientRepository) list(p pagination.Params, match func(*oauth2.Client) bool) []*oauth2.Client {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var clients []*oauth2.Client
+	for _, client := range r.db.clients {
+		if client.DeletedAt == nil && match(client) {
+			clients = append(clients, copyClient(client))
+		}
+	}
+	return page(clients, p, func(c *oauth2.Client) string { return c.ID })
+}
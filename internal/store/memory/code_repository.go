@@ -0,0 +1,104 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// AuthorizationCodeRepository implements oauth2.AuthorizationCodeRepository
+type AuthorizationCodeRepository struct {
+	db *DB
+}
+
+// NewAuthorizationCodeRepository creates a new authorization code repository
+func NewAuthorizationCodeRepository(db *DB) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{db: db}
+}
+
+// Create creates a new authorization code
+func (r *AuthorizationCodeRepository) Create(ctx context.Context, code *oauth2.AuthorizationCode) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.codes[code.Code]; ok {
+		return fmt.Errorf("failed to create authorization code: %w", ErrDuplicate)
+	}
+	cp := *code
+	r.db.codes[code.Code] = &cp
+	return nil
+}
+
+// GetByCode retrieves an authorization code issued within a tenant
+func (r *AuthorizationCodeRepository) GetByCode(ctx context.Context, tenantID, codeStr string) (*oauth2.AuthorizationCode, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	code, ok := r.db.codes[codeStr]
+	if !ok || code.TenantID != tenantID {
+		return nil, oauth2.ErrCodeNotFound
+	}
+	cp := *code
+	return &cp, nil
+}
+
+// MarkAsUsed marks the code as used
+func (r *AuthorizationCodeRepository) MarkAsUsed(ctx context.Context, tenantID, codeStr string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	code, ok := r.db.codes[codeStr]
+	if !ok || code.TenantID != tenantID {
+		return oauth2.ErrCodeNotFound
+	}
+	now := time.Now()
+	code.IsUsed = true
+	code.UsedAt = &now
+	return nil
+}
+
+// Delete deletes an authorization code
+func (r *AuthorizationCodeRepository) Delete(ctx context.Context, tenantID, codeStr string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if code, ok := r.db.codes[codeStr]; ok && code.TenantID == tenantID {
+		delete(r.db.codes, codeStr)
+	}
+	return nil
+}
+
+// DeleteExpired deletes up to limit expired authorization codes and returns how many were removed
+func (r *AuthorizationCodeRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for key, code := range r.db.codes {
+		if n >= int64(limit) {
+			break
+		}
+		if code.ExpiresAt.Before(now) {
+			delete(r.db.codes, key)
+			n++
+		}
+	}
+	return n, nil
+}
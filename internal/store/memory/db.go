@@ -0,0 +1,167 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memory implements the repository interfaces in process memory.
+// It backs unit tests and the demo mode; all data is lost when the process exits.
+package memory
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// ErrDuplicate mirrors a unique constraint violation in the SQL backends
+var ErrDuplicate = errors.New("duplicate key")
+
+// DB holds all records. Repositories created from the same DB share state,
+// so tenant roles and RBAC assignments see each other as they do in SQL.
+type DB struct {
+	mu sync.RWMutex
+
+	tenants               map[string]*tenantRow
+	users                 map[string]*identity.User
+	credentials           map[string]*identity.Credentials
+	sessions              map[string]*session.Session
+	projects              map[string]*authz.Project
+	roles                 map[string]*authz.Role
+	assignments           map[string]*authz.Assignment
+	clients               map[string]*oauth2.Client // keyed by internal ID
+	codes                 map[string]*oauth2.AuthorizationCode
+	accessTokens          map[string]*oauth2.AccessToken  // keyed by token hash
+	refreshTokens         map[string]*oauth2.RefreshToken // keyed by token hash
+	keys                  map[string]*oauth2.Key
+	emailSettings         map[string]*email.Settings
+	auditEvents           []*audit.Event
+	retention             map[string]*audit.RetentionPolicy
+	webhooks              map[string]*webhook.Subscription
+	deliveries            map[string]*webhook.Delivery
+	scopes                map[string]*oauth2.ScopeDefinition // keyed by tenant ID and name
+	replayMarkers         map[string]time.Time               // expiry keyed by kind and ID
+	pendingAuthorizations map[string]*oauth2.PendingAuthorization
+	protocolLogs          map[string]*oauth2.ProtocolLogSettings
+	memberships           map[string]*tenant.Membership
+	projectMembers        map[string]*authz.ProjectMember // keyed by project ID and user ID
+	securityPolicies      map[string]*tenant.SecurityPolicy
+	emailChanges          map[string]*identity.EmailChange
+	accountDeletions      map[string]*identity.AccountDeletion
+}
+
+// tenantRow adds the soft-delete marker that tenant.Tenant does not carry
+type tenantRow struct {
+	tenant    tenant.Tenant
+	deletedAt *time.Time
+}
+
+// New creates an empty store seeded with the built-in roles and permissions
+func New() *DB {
+	db := &DB{
+		tenants:               make(map[string]*tenantRow),
+		users:                 make(map[string]*identity.User),
+		credentials:           make(map[string]*identity.Credentials),
+		sessions:              make(map[string]*session.Session),
+		projects:              make(map[string]*authz.Project),
+		roles:                 make(map[string]*authz.Role),
+		assignments:           make(map[string]*authz.Assignment),
+		clients:               make(map[string]*oauth2.Client),
+		codes:                 make(map[string]*oauth2.AuthorizationCode),
+		accessTokens:          make(map[string]*oauth2.AccessToken),
+		refreshTokens:         make(map[string]*oauth2.RefreshToken),
+		keys:                  make(map[string]*oauth2.Key),
+		emailSettings:         make(map[string]*email.Settings),
+		retention:             make(map[string]*audit.RetentionPolicy),
+		webhooks:              make(map[string]*webhook.Subscription),
+		deliveries:            make(map[string]*webhook.Delivery),
+		scopes:                make(map[string]*oauth2.ScopeDefinition),
+		replayMarkers:         make(map[string]time.Time),
+		pendingAuthorizations: make(map[string]*oauth2.PendingAuthorization),
+		protocolLogs:          make(map[string]*oauth2.ProtocolLogSettings),
+		memberships:           make(map[string]*tenant.Membership),
+		projectMembers:        make(map[string]*authz.ProjectMember),
+		securityPolicies:      make(map[string]*tenant.SecurityPolicy),
+		emailChanges:          make(map[string]*identity.EmailChange),
+		accountDeletions:      make(map[string]*identity.AccountDeletion),
+	}
+	db.seed()
+	return db
+}
+
+// Seeded role IDs; these match the SQL migrations
+const (
+	platformAdminRoleID = "20000000-0000-0000-0000-000000000001"
+	tenantAdminRoleID   = "20000000-0000-0000-0000-000000000002"
+	memberRoleID        = "20000000-0000-0000-0000-000000000003"
+)
+
+// seed mirrors the seeding section of the initial schema migration
+func (db *DB) seed() {
+	all := []string{
+		"platform:manage_tenants", "platform:manage_admins", "platform:view_audit", "platform:bootstrap",
+		"tenant:manage_users", "tenant:manage_clients", "tenant:manage_settings", "tenant:view_users",
+		"tenant:view", "tenant:view_audit", "tenant:manage_projects",
+		"user:read_profile", "user:write_profile", "user:change_password", "user:manage_sessions",
+		"client:token_introspect", "client:token_revoke",
+	}
+	now := time.Now()
+
+	db.roles[platformAdminRoleID] = &authz.Role{
+		ID: platformAdminRoleID, Name: authz.RolePlatformAdmin, Scope: authz.ScopePlatform,
+		Description: "Platform-wide administrator", Permissions: all, CreatedAt: now, UpdatedAt: now,
+	}
+	db.roles[tenantAdminRoleID] = &authz.Role{
+		ID: tenantAdminRoleID, Name: authz.RoleTenantAdmin, Scope: authz.ScopeTenant,
+		Description: "Administrator for a specific tenant", Permissions: slices.Clone(all[4:11]), CreatedAt: now, UpdatedAt: now,
+	}
+	db.roles[memberRoleID] = &authz.Role{
+		ID: memberRoleID, Name: "member", Scope: authz.ScopeTenant,
+		Description: "Regular member of a tenant", Permissions: []string{}, CreatedAt: now, UpdatedAt: now,
+	}
+}
+
+// page sorts items by ID and returns the page selected by p
+func page[T any](items []T, p pagination.Params, id func(T) string) []T {
+	p = p.Normalize()
+	slices.SortFunc(items, func(a, b T) int { return strings.Compare(id(a), id(b)) })
+	if p.After != "" {
+		start := slices.IndexFunc(items, func(item T) bool { return id(item) > p.After })
+		if start < 0 {
+			start = len(items)
+		}
+		items = items[start:]
+	}
+	if len(items) > p.Limit {
+		items = items[:p.Limit]
+	}
+	return items
+}
+
+// sameTenant compares nullable tenant IDs the way SQL's IS NOT DISTINCT FROM does
+func sameTenant(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// EmailChangeRepository implements identity.EmailChangeRepository
+type EmailChangeRepository struct {
+	db *DB
+}
+
+// NewEmailChangeRepository creates a new email change repository
+func NewEmailChangeRepository(db *DB) *EmailChangeRepository {
+	return &EmailChangeRepository{db: db}
+}
+
+// Create stores an email change, replacing any earlier change of the user
+func (r *EmailChangeRepository) Create(ctx context.Context, change *identity.EmailChange) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, c := range r.db.emailChanges {
+		if c.UserID == change.UserID {
+			delete(r.db.emailChanges, id)
+		}
+	}
+	c := *change
+	r.db.emailChanges[c.ID] = &c
+	return nil
+}
+
+// GetByUser retrieves the email change of a user
+func (r *EmailChangeRepository) GetByUser(ctx context.Context, userID string) (*identity.EmailChange, error) {
+	return r.find(func(c *identity.EmailChange) bool { return c.UserID == userID })
+}
+
+// GetByTokenHash retrieves the email change whose current token has the given hash
+func (r *EmailChangeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*identity.EmailChange, error) {
+	return r.find(func(c *identity.EmailChange) bool { return c.TokenHash == tokenHash })
+}
+
+func (r *EmailChangeRepository) find(match func(c *identity.EmailChange) bool) (*identity.EmailChange, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, c := range r.db.emailChanges {
+		if match(c) {
+			cp := *c
+			return &cp, nil
+		}
+	}
+	return nil, identity.ErrEmailChangeNotFound
+}
+
+// Update replaces the status, token hash and expiry of an email change
+func (r *EmailChangeRepository) Update(ctx context.Context, change *identity.EmailChange) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	c, ok := r.db.emailChanges[change.ID]
+	if !ok {
+		return identity.ErrEmailChangeNotFound
+	}
+	c.Status = change.Status
+	c.TokenHash = change.TokenHash
+	c.ExpiresAt = change.ExpiresAt
+	return nil
+}
+
+// Delete removes an email change
+func (r *EmailChangeRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.emailChanges[id]; !ok {
+		return identity.ErrEmailChangeNotFound
+	}
+	delete(r.db.emailChanges, id)
+	return nil
+}
+
+// ListDue lists up to limit verified email changes whose cooling-off window ended before the given time
+func (r *EmailChangeRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*identity.EmailChange, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var due []*identity.EmailChange
+	for _, c := range r.db.emailChanges {
+		if c.Status == identity.EmailChangeVerified && c.ExpiresAt.Before(before) {
+			cp := *c
+			due = append(due, &cp)
+		}
+	}
+	slices.SortFunc(due, func(a, b *identity.EmailChange) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// DeleteExpired deletes up to limit pending email changes whose link expired and returns how many were removed
+func (r *EmailChangeRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for id, c := range r.db.emailChanges {
+		if n >= int64(limit) {
+			break
+		}
+		if c.Status == identity.EmailChangePending && c.ExpiresAt.Before(now) {
+			delete(r.db.emailChanges, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/opentrusty/opentrusty/internal/email"
+)
+
+// EmailSettingsRepository implements email.SettingsRepository
+type EmailSettingsRepository struct {
+	db *DB
+}
+
+// NewEmailSettingsRepository creates a new email settings repository
+func NewEmailSettingsRepository(db *DB) *EmailSettingsRepository {
+	return &EmailSettingsRepository{db: db}
+}
+
+func copySettings(s *email.Settings) *email.Settings {
+	cp := *s
+	cp.PasswordEncrypted = slices.Clone(s.PasswordEncrypted)
+	return &cp
+}
+
+// Get retrieves the email settings for a tenant
+func (r *EmailSettingsRepository) Get(ctx context.Context, tenantID string) (*email.Settings, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	s, ok := r.db.emailSettings[tenantID]
+	if !ok {
+		return nil, email.ErrSettingsNotFound
+	}
+	return copySettings(s), nil
+}
+
+// Upsert creates or replaces the email settings for a tenant
+func (r *EmailSettingsRepository) Upsert(ctx context.Context, s *email.Settings) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	updated := copySettings(s)
+	if existing, ok := r.db.emailSettings[s.TenantID]; ok {
+		updated.CreatedAt = existing.CreatedAt
+	}
+	r.db.emailSettings[s.TenantID] = updated
+	return nil
+}
+
+// Delete removes the email settings for a tenant
+func (r *EmailSettingsRepository) Delete(ctx context.Context, tenantID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.emailSettings[tenantID]; !ok {
+		return email.ErrSettingsNotFound
+	}
+	delete(r.db.emailSettings, tenantID)
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// errNoActiveKey plays the role of the no-rows error in the SQL backends
+var errNoActiveKey = errors.New("no active signing key")
+
+// KeyRepository implements oauth2.KeyRepository
+type KeyRepository struct {
+	db *DB
+}
+
+// NewKeyRepository creates a new key repository
+func NewKeyRepository(db *DB) *KeyRepository {
+	return &KeyRepository{db: db}
+}
+
+func copyKey(key *oauth2.Key) *oauth2.Key {
+	cp := *key
+	cp.PrivateKeyEncrypted = slices.Clone(key.PrivateKeyEncrypted)
+	cp.DataKeyEncrypted = slices.Clone(key.DataKeyEncrypted)
+	if key.ActivatedAt != nil {
+		t := *key.ActivatedAt
+		cp.ActivatedAt = &t
+	}
+	if key.RetiredAt != nil {
+		t := *key.RetiredAt
+		cp.RetiredAt = &t
+	}
+	return &cp
+}
+
+// Create stores a new key
+func (r *KeyRepository) Create(ctx context.Context, key *oauth2.Key) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.keys[key.ID]; ok {
+		return fmt.Errorf("failed to create key: %w", ErrDuplicate)
+	}
+	if key.Status == "" {
+		key.Status = oauth2.KeyStatusActive
+	}
+	r.db.keys[key.ID] = copyKey(key)
+	return nil
+}
+
+// GetByID retrieves a key by ID
+func (r *KeyRepository) GetByID(ctx context.Context, id string) (*oauth2.Key, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	key, ok := r.db.keys[id]
+	if !ok {
+		return nil, oauth2.ErrKeyNotFound
+	}
+	return copyKey(key), nil
+}
+
+// GetActiveKey retrieves the most recently activated unexpired active key
+func (r *KeyRepository) GetActiveKey(ctx context.Context) (*oauth2.Key, error) {
+	now := time.Now()
+	var active *oauth2.Key
+	for _, key := range r.list(func(k *oauth2.Key) bool {
+		return k.Status == oauth2.KeyStatusActive && k.ExpiresAt.After(now)
+	}) {
+		if active == nil || activatedAt(key).After(activatedAt(active)) {
+			active = key
+		}
+	}
+	if active == nil {
+		// Let the service decide whether to generate a key when none is found
+		return nil, errNoActiveKey
+	}
+	return active, nil
+}
+
+func activatedAt(key *oauth2.Key) time.Time {
+	if key.ActivatedAt != nil {
+		return *key.ActivatedAt
+	}
+	return key.CreatedAt
+}
+
+// ListValidKeys retrieves all published keys, newest first
+func (r *KeyRepository) ListValidKeys(ctx context.Context) ([]*oauth2.Key, error) {
+	now := time.Now()
+	return r.list(func(k *oauth2.Key) bool { return k.Published(now) }), nil
+}
+
+// List retrieves all keys, newest first
+func (r *KeyRepository) List(ctx context.Context) ([]*oauth2.Key, error) {
+	return r.list(func(*oauth2.Key) bool { return true }), nil
+}
+
+func (r *KeyRepository) list(match func(*oauth2.Key) bool) []*oauth2.Key {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var keys []*oauth2.Key
+	for _, key := range r.db.keys {
+		if match(key) {
+			keys = append(keys, copyKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys
+}
+
+// Update stores a key's lifecycle fields
+func (r *KeyRepository) Update(ctx context.Context, key *oauth2.Key) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.keys[key.ID]
+	if !ok {
+		return oauth2.ErrKeyNotFound
+	}
+	updated := copyKey(key)
+	stored.Status = updated.Status
+	stored.ActivatedAt = updated.ActivatedAt
+	stored.RetiredAt = updated.RetiredAt
+	stored.ExpiresAt = updated.ExpiresAt
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// MembershipRepository implements tenant.MembershipRepository
+type MembershipRepository struct {
+	db *DB
+}
+
+// NewMembershipRepository creates a new tenant membership repository
+func NewMembershipRepository(db *DB) *MembershipRepository {
+	return &MembershipRepository{db: db}
+}
+
+// Add stores a new membership
+func (r *MembershipRepository) Add(ctx context.Context, m *tenant.Membership) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.findMembership(m.TenantID, m.UserID); ok {
+		return tenant.ErrMembershipExists
+	}
+	cp := *m
+	r.db.memberships[m.ID] = &cp
+	return nil
+}
+
+// Remove deletes a membership
+func (r *MembershipRepository) Remove(ctx context.Context, tenantID, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	m, ok := r.db.findMembership(tenantID, userID)
+	if !ok {
+		return tenant.ErrMembershipNotFound
+	}
+	delete(r.db.memberships, m.ID)
+	return nil
+}
+
+// Get retrieves a user's membership in a tenant
+func (r *MembershipRepository) Get(ctx context.Context, tenantID, userID string) (*tenant.Membership, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	m, ok := r.db.findMembership(tenantID, userID)
+	if !ok {
+		return nil, tenant.ErrMembershipNotFound
+	}
+	cp := *m
+	return &cp, nil
+}
+
+// ListByTenant lists the members of a tenant, oldest first
+func (r *MembershipRepository) ListByTenant(ctx context.Context, tenantID string) ([]*tenant.Membership, error) {
+	memberships := r.list(func(m *tenant.Membership) bool { return m.TenantID == tenantID })
+	sort.Slice(memberships, func(i, j int) bool { return memberships[i].CreatedAt.Before(memberships[j].CreatedAt) })
+	return memberships, nil
+}
+
+// ListByUser lists a user's memberships in tenants that are not deleted, home first
+func (r *MembershipRepository) ListByUser(ctx context.Context, userID string) ([]*tenant.Membership, error) {
+	memberships := r.list(func(m *tenant.Membership) bool {
+		row, ok := r.db.tenants[m.TenantID]
+		return m.UserID == userID && ok && row.deletedAt == nil
+	})
+	sort.Slice(memberships, func(i, j int) bool {
+		if memberships[i].Home != memberships[j].Home {
+			return memberships[i].Home
+		}
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+	return memberships, nil
+}
+
+// list returns copies of the memberships that match
+func (r *MembershipRepository) list(match func(*tenant.Membership) bool) []*tenant.Membership {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var memberships []*tenant.Membership
+	for _, m := range r.db.memberships {
+		if match(m) {
+			cp := *m
+			memberships = append(memberships, &cp)
+		}
+	}
+	return memberships
+}
+
+// findMembership returns a user's membership in a tenant; callers must hold the lock
+func (db *DB) findMembership(tenantID, userID string) (*tenant.Membership, bool) {
+	for _, m := range db.memberships {
+		if m.TenantID == tenantID && m.UserID == userID {
+			return m, true
+		}
+	}
+	return nil, false
+}
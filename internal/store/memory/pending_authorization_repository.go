@@ -0,0 +1,76 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// PendingAuthorizationRepository implements oauth2.PendingAuthorizationRepository
+type PendingAuthorizationRepository struct {
+	db *DB
+}
+
+// NewPendingAuthorizationRepository creates a new pending authorization repository
+func NewPendingAuthorizationRepository(db *DB) *PendingAuthorizationRepository {
+	return &PendingAuthorizationRepository{db: db}
+}
+
+// Create stores a pending authorization
+func (r *PendingAuthorizationRepository) Create(ctx context.Context, pending *oauth2.PendingAuthorization) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p := *pending
+	p.Params = maps.Clone(pending.Params)
+	r.db.pendingAuthorizations[p.ID] = &p
+	return nil
+}
+
+// Consume deletes an unexpired pending authorization and returns it
+func (r *PendingAuthorizationRepository) Consume(ctx context.Context, id string) (*oauth2.PendingAuthorization, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	pending, ok := r.db.pendingAuthorizations[id]
+	if !ok || !time.Now().Before(pending.ExpiresAt) {
+		return nil, oauth2.ErrPendingAuthorizationNotFound
+	}
+	delete(r.db.pendingAuthorizations, id)
+	return pending, nil
+}
+
+// DeleteExpired deletes up to limit expired pending authorizations and returns how many were removed
+func (r *PendingAuthorizationRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for id, pending := range r.db.pendingAuthorizations {
+		if n >= int64(limit) {
+			break
+		}
+		if pending.ExpiresAt.Before(now) {
+			delete(r.db.pendingAuthorizations, id)
+			n++
+		}
+	}
+	return n, nil
+}
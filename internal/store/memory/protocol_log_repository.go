@@ -0,0 +1,54 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// ProtocolLogRepository implements oauth2.ProtocolLogRepository
+type ProtocolLogRepository struct {
+	db *DB
+}
+
+// NewProtocolLogRepository creates a new protocol log settings repository
+func NewProtocolLogRepository(db *DB) *ProtocolLogRepository {
+	return &ProtocolLogRepository{db: db}
+}
+
+// Get retrieves a tenant's protocol log settings
+func (r *ProtocolLogRepository) Get(ctx context.Context, tenantID string) (*oauth2.ProtocolLogSettings, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	p, ok := r.db.protocolLogs[tenantID]
+	if !ok {
+		return nil, oauth2.ErrProtocolLogNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+// Upsert creates or replaces a tenant's protocol log settings
+func (r *ProtocolLogRepository) Upsert(ctx context.Context, p *oauth2.ProtocolLogSettings) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	cp := *p
+	r.db.protocolLogs[p.TenantID] = &cp
+	return nil
+}
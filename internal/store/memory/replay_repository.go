@@ -0,0 +1,64 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/replay"
+)
+
+// ReplayRepository implements replay.Repository
+type ReplayRepository struct {
+	db *DB
+}
+
+// NewReplayRepository creates a new replay marker repository
+func NewReplayRepository(db *DB) *ReplayRepository {
+	return &ReplayRepository{db: db}
+}
+
+// MarkOnce records the marker unless an unexpired one exists
+func (r *ReplayRepository) MarkOnce(ctx context.Context, kind, id string, ttl time.Duration) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := kind + "/" + id
+	if expiresAt, ok := r.db.replayMarkers[key]; ok && time.Now().Before(expiresAt) {
+		return replay.ErrReplayed
+	}
+	r.db.replayMarkers[key] = time.Now().Add(ttl)
+	return nil
+}
+
+// DeleteExpired deletes up to limit expired markers and returns how many were removed
+func (r *ReplayRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for key, expiresAt := range r.db.replayMarkers {
+		if n >= int64(limit) {
+			break
+		}
+		if expiresAt.Before(now) {
+			delete(r.db.replayMarkers, key)
+			n++
+		}
+	}
+	return n, nil
+}
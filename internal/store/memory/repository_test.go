@@ -0,0 +1,317 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/replay"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPurpose: Validates that a new store is seeded with the same scoped RBAC roles as the SQL migrations.
+// Scope: Unit Test
+// Security: Authorization baseline (seeded roles must carry the same permissions in every backend)
+// Expected: tenant_admin carries tenant:manage_users; platform_admin carries all 17 permissions.
+// Test Case ID: MEM-01
+func TestMemory_New_SeedRoles(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	roles := NewRoleRepository(db)
+
+	role, err := roles.GetByName(ctx, authz.RoleTenantAdmin, authz.ScopeTenant)
+	require.NoError(t, err)
+	assert.Contains(t, role.Permissions, authz.PermTenantManageUsers)
+
+	platform, err := roles.GetByName(ctx, authz.RolePlatformAdmin, authz.ScopePlatform)
+	require.NoError(t, err)
+	assert.Len(t, platform.Permissions, 17)
+
+	// Returned records are copies and cannot alter the store
+	platform.Permissions[0] = "tampered"
+	again, err := roles.GetByID(ctx, platform.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, again.Permissions, "tampered")
+}
+
+// TestPurpose: Validates tenant-scoped user lookup, the (tenant, email) uniqueness rule and soft delete.
+// Scope: Unit Test
+// Security: Multi-tenant Data Separation (CWE-284)
+// Expected: Users are only found within their own tenant; duplicates are rejected; deleted users are hidden.
+// Test Case ID: MEM-02
+func TestMemory_UserRepository_TenantIsolationAndSoftDelete(t *testing.T) {
+	users := NewUserRepository(New())
+	ctx := context.Background()
+	tenantA, tenantB := "tenant-a", "tenant-b"
+
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "user-a", TenantID: &tenantA, Email: "shared@example.com"}))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "admin", Email: "shared@example.com"}))
+	err := users.Create(ctx, &identity.User{ID: "user-a2", TenantID: &tenantA, Email: "shared@example.com"})
+	assert.ErrorIs(t, err, ErrDuplicate)
+
+	found, err := users.GetByEmail(ctx, &tenantA, "shared@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "user-a", found.ID)
+
+	_, err = users.GetByEmail(ctx, &tenantB, "shared@example.com")
+	assert.ErrorIs(t, err, identity.ErrUserNotFound)
+
+	found, err = users.GetByEmail(ctx, nil, "shared@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", found.ID)
+
+	require.NoError(t, users.Delete(ctx, "user-a"))
+	_, err = users.GetByID(ctx, "user-a")
+	assert.ErrorIs(t, err, identity.ErrUserNotFound)
+	_, err = users.GetByEmail(ctx, &tenantA, "shared@example.com")
+	assert.ErrorIs(t, err, identity.ErrUserNotFound)
+}
+
+// TestPurpose: Validates that expiry cleanup removes only expired sessions, codes and tokens, and that expired keys are never active.
+// Scope: Unit Test
+// Security: Credential lifetime enforcement
+// Expected: DeleteExpired removes expired records and keeps live ones; GetActiveKey returns the newest unexpired key.
+// Test Case ID: MEM-03
+func TestMemory_Expiry(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	sessions := NewSessionRepository(db)
+	require.NoError(t, sessions.Create(ctx, &session.Session{ID: "expired", ExpiresAt: past}))
+	require.NoError(t, sessions.Create(ctx, &session.Session{ID: "live", ExpiresAt: future}))
+	n, err := sessions.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = sessions.Get(ctx, "expired")
+	assert.ErrorIs(t, err, session.ErrSessionNotFound)
+	_, err = sessions.Get(ctx, "live")
+	assert.NoError(t, err)
+
+	codes := NewAuthorizationCodeRepository(db)
+	require.NoError(t, codes.Create(ctx, &oauth2.AuthorizationCode{ID: "1", TenantID: "t1", Code: "expired", ExpiresAt: past}))
+	require.NoError(t, codes.Create(ctx, &oauth2.AuthorizationCode{ID: "2", TenantID: "t1", Code: "live", ExpiresAt: future}))
+	n, err = codes.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = codes.GetByCode(ctx, "t1", "expired")
+	assert.ErrorIs(t, err, oauth2.ErrCodeNotFound)
+	_, err = codes.GetByCode(ctx, "t2", "live")
+	assert.ErrorIs(t, err, oauth2.ErrCodeNotFound, "codes are not found in another tenant")
+	assert.ErrorIs(t, codes.MarkAsUsed(ctx, "t2", "live"), oauth2.ErrCodeNotFound)
+	require.NoError(t, codes.MarkAsUsed(ctx, "t1", "live"))
+	code, err := codes.GetByCode(ctx, "t1", "live")
+	require.NoError(t, err)
+	assert.True(t, code.IsUsed)
+	assert.NotNil(t, code.UsedAt)
+
+	tokens := NewAccessTokenRepository(db)
+	require.NoError(t, tokens.Create(ctx, &oauth2.AccessToken{ID: "1", TenantID: "t1", TokenHash: "expired", ExpiresAt: past}))
+	require.NoError(t, tokens.Create(ctx, &oauth2.AccessToken{ID: "2", TenantID: "t1", TokenHash: "live", ExpiresAt: future}))
+	n, err = tokens.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = tokens.GetByTokenHash(ctx, "t1", "expired")
+	assert.ErrorIs(t, err, oauth2.ErrTokenNotFound)
+	_, err = tokens.GetByTokenHash(ctx, "t2", "live")
+	assert.ErrorIs(t, err, oauth2.ErrTokenNotFound, "tokens are not found in another tenant")
+	assert.ErrorIs(t, tokens.Revoke(ctx, "t2", "live"), oauth2.ErrTokenNotFound)
+	require.NoError(t, tokens.Revoke(ctx, "t1", "live"))
+	token, err := tokens.GetByTokenHash(ctx, "t1", "live")
+	require.NoError(t, err)
+	assert.True(t, token.IsRevoked)
+	assert.ErrorIs(t, tokens.Revoke(ctx, "t1", "missing"), oauth2.ErrTokenNotFound)
+
+	keys := NewKeyRepository(db)
+	_, err = keys.GetActiveKey(ctx)
+	assert.Error(t, err)
+	require.NoError(t, keys.Create(ctx, &oauth2.Key{ID: "old", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: future}))
+	require.NoError(t, keys.Create(ctx, &oauth2.Key{ID: "new", CreatedAt: now, ExpiresAt: future}))
+	require.NoError(t, keys.Create(ctx, &oauth2.Key{ID: "expired", CreatedAt: now.Add(time.Minute), ExpiresAt: past}))
+	key, err := keys.GetActiveKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "new", key.ID)
+	valid, err := keys.ListValidKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, valid, 2)
+}
+
+// TestPurpose: Validates that tenant roles and RBAC assignments share state, and that client listing is tenant-scoped and hides soft-deleted clients.
+// Scope: Unit Test
+// Security: Multi-tenant Data Separation (CWE-284); authorization consistency across repositories
+// Expected: An assigned tenant role grants its RBAC permissions; revoking twice reports ErrRoleNotFound; deleted clients disappear.
+// Test Case ID: MEM-04
+func TestMemory_TenantRolesAndClients(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+
+	tenantRoles := NewTenantRoleRepository(db)
+	require.NoError(t, tenantRoles.AssignRole(ctx, &tenant.TenantUserRole{ID: "a1", TenantID: "t1", UserID: "u1", Role: tenant.RoleTenantAdmin}))
+
+	authzSvc := authz.NewService(NewProjectRepository(db), NewRoleRepository(db), NewAssignmentRepository(db))
+	tenantID, otherID := "t1", "t2"
+	allowed, err := authzSvc.HasPermission(ctx, "u1", authz.ScopeTenant, &tenantID, authz.PermTenantManageClients)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = authzSvc.HasPermission(ctx, "u1", authz.ScopeTenant, &otherID, authz.PermTenantManageClients)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	roles, err := tenantRoles.GetUserRoles(ctx, "t1", "u1")
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, authz.RoleTenantAdmin, roles[0].Role)
+
+	assert.ErrorIs(t, tenantRoles.RevokeOwnerRole(ctx, "t1", "u1", tenant.RoleTenantAdmin), tenant.ErrLastTenantOwner)
+	require.NoError(t, tenantRoles.AssignRole(ctx, &tenant.TenantUserRole{ID: "a2", TenantID: "t1", UserID: "u2", Role: tenant.RoleTenantOwner}))
+	require.NoError(t, tenantRoles.RevokeOwnerRole(ctx, "t1", "u2", tenant.RoleTenantOwner))
+	require.NoError(t, tenantRoles.RevokeRole(ctx, "t1", "u1", tenant.RoleTenantAdmin))
+	assert.ErrorIs(t, tenantRoles.RevokeRole(ctx, "t1", "u1", tenant.RoleTenantAdmin), tenant.ErrRoleNotFound)
+
+	clients := NewClientRepository(db)
+	for i, tenantID := range []string{"t1", "t1", "t2"} {
+		require.NoError(t, clients.Create(ctx, &oauth2.Client{
+			ID: fmt.Sprintf("c%d", i), ClientID: fmt.Sprintf("client-%d", i), TenantID: tenantID,
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+	require.NoError(t, clients.Delete(ctx, "c0"))
+	assert.ErrorIs(t, clients.Delete(ctx, "c0"), oauth2.ErrClientNotFound)
+
+	list, err := clients.ListByTenant(ctx, "t1", pagination.Params{})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "c1", list[0].ID)
+	_, err = clients.GetByClientID(ctx, "client-0")
+	assert.ErrorIs(t, err, oauth2.ErrClientNotFound)
+}
+
+// TestPurpose: Validates keyset pagination, search and soft delete in tenant listing.
+// Scope: Unit Test
+// Security: N/A (correctness of admin listing)
+// Expected: Paging newest-first visits every live tenant exactly once; search is case-insensitive.
+// Test Case ID: MEM-05
+func TestMemory_TenantRepository_ListPagination(t *testing.T) {
+	repo := NewTenantRepository(New())
+	ctx := context.Background()
+
+	base := time.Now()
+	for i := 0; i < 6; i++ {
+		created := base.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.Create(ctx, &tenant.Tenant{
+			ID: fmt.Sprintf("t%d", i), Name: fmt.Sprintf("Tenant %d", i), Status: tenant.StatusActive,
+			CreatedAt: created, UpdatedAt: created,
+		}))
+	}
+	require.NoError(t, repo.Delete(ctx, "t5"))
+
+	var ids []string
+	opts := tenant.ListOptions{Limit: 2, Sort: tenant.SortCreatedDesc}
+	for {
+		page, err := repo.List(ctx, opts)
+		require.NoError(t, err)
+		for _, tn := range page.Tenants {
+			ids = append(ids, tn.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"t4", "t3", "t2", "t1", "t0"}, ids)
+
+	page, err := repo.List(ctx, tenant.ListOptions{Limit: 10, Query: "TENANT 3", Sort: tenant.SortNameAsc})
+	require.NoError(t, err)
+	require.Len(t, page.Tenants, 1)
+	assert.Equal(t, "t3", page.Tenants[0].ID)
+}
+
+// TestPurpose: Validates optimistic concurrency on user and client updates.
+// Scope: Unit Test
+// Security: Integrity of administrative edits (lost update prevention)
+// Expected: An update based on a stale version fails with a conflict and leaves the stored record unchanged.
+// Test Case ID: MEM-06
+func TestMemory_UpdateVersionConflict(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+
+	users := NewUserRepository(db)
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u1", Email: "u1@example.com"}))
+
+	first, err := users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	second, err := users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Version)
+
+	first.Profile.FullName = "First"
+	require.NoError(t, users.Update(ctx, first))
+	assert.Equal(t, 2, first.Version)
+
+	second.Profile.FullName = "Second"
+	assert.ErrorIs(t, users.Update(ctx, second), identity.ErrUserVersionConflict)
+
+	stored, err := users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "First", stored.Profile.FullName)
+	assert.Equal(t, 2, stored.Version)
+
+	clients := NewClientRepository(db)
+	require.NoError(t, clients.Create(ctx, &oauth2.Client{ID: "c1", ClientID: "cid1", TenantID: "t1", ClientName: "Original"}))
+
+	stale, err := clients.GetByID(ctx, "c1")
+	require.NoError(t, err)
+	fresh, err := clients.GetByID(ctx, "c1")
+	require.NoError(t, err)
+
+	fresh.ClientName = "Renamed"
+	require.NoError(t, clients.Update(ctx, fresh))
+
+	stale.IsActive = false
+	assert.ErrorIs(t, clients.Update(ctx, stale), oauth2.ErrClientVersionConflict)
+
+	stale.ID = "missing"
+	assert.ErrorIs(t, clients.Update(ctx, stale), oauth2.ErrClientNotFound)
+}
+
+// TestPurpose: Validates that a one-time identifier is accepted once until it expires.
+// Scope: Unit Test
+// Security: Replay prevention (a jti seen before is refused while the token carrying it is valid)
+// Expected: A second MarkOnce is ErrReplayed; an expired marker may be taken over and is purged by DeleteExpired.
+// Test Case ID: MEM-07
+func TestMemory_ReplayRepository(t *testing.T) {
+	markers := NewReplayRepository(New())
+	ctx := context.Background()
+
+	require.NoError(t, markers.MarkOnce(ctx, "client_assertion", "jti-1", time.Minute))
+	assert.ErrorIs(t, markers.MarkOnce(ctx, "client_assertion", "jti-1", time.Minute), replay.ErrReplayed)
+
+	require.NoError(t, markers.MarkOnce(ctx, "client_assertion", "jti-2", -time.Second))
+	require.NoError(t, markers.MarkOnce(ctx, "client_assertion", "jti-2", -time.Second))
+	n, err := markers.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// ScopeRepository implements oauth2.ScopeRepository
+type ScopeRepository struct {
+	db *DB
+}
+
+// NewScopeRepository creates a new custom scope repository
+func NewScopeRepository(db *DB) *ScopeRepository {
+	return &ScopeRepository{db: db}
+}
+
+func scopeKey(tenantID, name string) string {
+	return tenantID + "\x00" + name
+}
+
+func copyScope(s *oauth2.ScopeDefinition) *oauth2.ScopeDefinition {
+	cp := *s
+	cp.Claims = slices.Clone(s.Claims)
+	cp.Permissions = slices.Clone(s.Permissions)
+	return &cp
+}
+
+// Create stores a new scope
+func (r *ScopeRepository) Create(ctx context.Context, scope *oauth2.ScopeDefinition) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := scopeKey(scope.TenantID, scope.Name)
+	if _, ok := r.db.scopes[key]; ok {
+		return oauth2.ErrScopeAlreadyExists
+	}
+	r.db.scopes[key] = copyScope(scope)
+	return nil
+}
+
+// Get retrieves a tenant's scope by name
+func (r *ScopeRepository) Get(ctx context.Context, tenantID, name string) (*oauth2.ScopeDefinition, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	scope, ok := r.db.scopes[scopeKey(tenantID, name)]
+	if !ok {
+		return nil, oauth2.ErrScopeNotFound
+	}
+	return copyScope(scope), nil
+}
+
+// List retrieves all of a tenant's scopes ordered by name
+func (r *ScopeRepository) List(ctx context.Context, tenantID string) ([]*oauth2.ScopeDefinition, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var scopes []*oauth2.ScopeDefinition
+	for _, scope := range r.db.scopes {
+		if scope.TenantID == tenantID {
+			scopes = append(scopes, copyScope(scope))
+		}
+	}
+	slices.SortFunc(scopes, func(a, b *oauth2.ScopeDefinition) int { return strings.Compare(a.Name, b.Name) })
+	return scopes, nil
+}
+
+// Update replaces a scope's description, claims and permissions
+func (r *ScopeRepository) Update(ctx context.Context, scope *oauth2.ScopeDefinition) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.scopes[scopeKey(scope.TenantID, scope.Name)]
+	if !ok {
+		return oauth2.ErrScopeNotFound
+	}
+	updated := copyScope(scope)
+	updated.ID = existing.ID
+	updated.CreatedAt = existing.CreatedAt
+	r.db.scopes[scopeKey(scope.TenantID, scope.Name)] = updated
+	return nil
+}
+
+// Delete removes a tenant's scope
+func (r *ScopeRepository) Delete(ctx context.Context, tenantID, name string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := scopeKey(tenantID, name)
+	if _, ok := r.db.scopes[key]; !ok {
+		return oauth2.ErrScopeNotFound
+	}
+	delete(r.db.scopes, key)
+	return nil
+}
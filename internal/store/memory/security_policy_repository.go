@@ -0,0 +1,66 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// SecurityPolicyRepository implements tenant.SecurityPolicyRepository
+type SecurityPolicyRepository struct {
+	db *DB
+}
+
+// NewSecurityPolicyRepository creates a new tenant security policy repository
+func NewSecurityPolicyRepository(db *DB) *SecurityPolicyRepository {
+	return &SecurityPolicyRepository{db: db}
+}
+
+// Get retrieves a tenant's security policy
+func (r *SecurityPolicyRepository) Get(ctx context.Context, tenantID string) (*tenant.SecurityPolicy, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	p, ok := r.db.securityPolicies[tenantID]
+	if !ok {
+		return nil, tenant.ErrSecurityPolicyNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+// Upsert creates or replaces a tenant's security policy
+func (r *SecurityPolicyRepository) Upsert(ctx context.Context, p *tenant.SecurityPolicy) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	cp := *p
+	r.db.securityPolicies[p.TenantID] = &cp
+	return nil
+}
+
+// Delete removes a tenant's security policy
+func (r *SecurityPolicyRepository) Delete(ctx context.Context, tenantID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.securityPolicies[tenantID]; !ok {
+		return tenant.ErrSecurityPolicyNotFound
+	}
+	delete(r.db.securityPolicies, tenantID)
+	return nil
+}
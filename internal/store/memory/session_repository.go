@@ -0,0 +1,163 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/session"
+)
+
+// SessionRepository implements session.Repository
+type SessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, sess *session.Session) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.sessions[sess.ID]; ok {
+		return fmt.Errorf("failed to create session: %w", ErrDuplicate)
+	}
+	cp := *sess
+	r.db.sessions[sess.ID] = &cp
+	return nil
+}
+
+// Get retrieves a session by ID
+func (r *SessionRepository) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	s, ok := r.db.sessions[sessionID]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+// Update updates the session's last seen time, address and user agent
+func (r *SessionRepository) Update(ctx context.Context, sess *session.Session) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	s, ok := r.db.sessions[sess.ID]
+	if !ok {
+		return session.ErrSessionNotFound
+	}
+	s.LastSeenAt = sess.LastSeenAt
+	s.IPAddress = sess.IPAddress
+	s.UserAgent = sess.UserAgent
+	return nil
+}
+
+// Delete deletes a session
+func (r *SessionRepository) Delete(ctx context.Context, sessionID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.sessions, sessionID)
+	return nil
+}
+
+// DeleteByUserID deletes all sessions for a user
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, s := range r.db.sessions {
+		if s.UserID == userID {
+			delete(r.db.sessions, id)
+		}
+	}
+	return nil
+}
+
+// DeleteByTenantID deletes up to limit sessions of a tenant and returns how many were removed
+func (r *SessionRepository) DeleteByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var n int64
+	for id, s := range r.db.sessions {
+		if n >= int64(limit) {
+			break
+		}
+		if s.TenantID != nil && *s.TenantID == tenantID {
+			delete(r.db.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteByClientID deletes up to limit sessions of users holding tokens issued to a client and
+// returns how many were removed
+func (r *SessionRepository) DeleteByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	users := make(map[string]bool)
+	for _, t := range r.db.accessTokens {
+		if t.ClientID == clientID {
+			users[t.UserID] = true
+		}
+	}
+	for _, t := range r.db.refreshTokens {
+		if t.ClientID == clientID {
+			users[t.UserID] = true
+		}
+	}
+	var n int64
+	for id, s := range r.db.sessions {
+		if n >= int64(limit) {
+			break
+		}
+		if users[s.UserID] {
+			delete(r.db.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteExpired deletes up to limit expired sessions and returns how many were removed
+func (r *SessionRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for id, s := range r.db.sessions {
+		if n >= int64(limit) {
+			break
+		}
+		if s.ExpiresAt.Before(now) {
+			delete(r.db.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TenantRepository implements tenant.Repository
+type TenantRepository struct {
+	db *DB
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(db *DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// Create creates a new tenant
+func (r *TenantRepository) Create(ctx context.Context, t *tenant.Tenant) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.tenants[t.ID]; ok {
+		return fmt.Errorf("failed to create tenant: %w", ErrDuplicate)
+	}
+	// Names stay reserved after soft delete, as with the SQL unique constraint
+	for _, row := range r.db.tenants {
+		if row.tenant.Name == t.Name {
+			return fmt.Errorf("failed to create tenant: %w", ErrDuplicate)
+		}
+	}
+	r.db.tenants[t.ID] = &tenantRow{tenant: *t}
+	return nil
+}
+
+// GetByID retrieves a tenant by ID
+func (r *TenantRepository) GetByID(ctx context.Context, id string) (*tenant.Tenant, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	row, ok := r.db.tenants[id]
+	if !ok || row.deletedAt != nil {
+		return nil, tenant.ErrTenantNotFound
+	}
+	t := row.tenant
+	return &t, nil
+}
+
+// GetByName retrieves a tenant by name
+func (r *TenantRepository) GetByName(ctx context.Context, name string) (*tenant.Tenant, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, row := range r.db.tenants {
+		if row.deletedAt == nil && row.tenant.Name == name {
+			t := row.tenant
+			return &t, nil
+		}
+	}
+	return nil, tenant.ErrTenantNotFound
+}
+
+// Update updates a tenant
+func (r *TenantRepository) Update(ctx context.Context, t *tenant.Tenant) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.tenants[t.ID]
+	if !ok || row.deletedAt != nil {
+		return tenant.ErrTenantNotFound
+	}
+	t.UpdatedAt = time.Now()
+	row.tenant.Name = t.Name
+	row.tenant.Status = t.Status
+	row.tenant.UpdatedAt = t.UpdatedAt
+	return nil
+}
+
+// Delete soft-deletes a tenant
+func (r *TenantRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.tenants[id]
+	if !ok || row.deletedAt != nil {
+		return tenant.ErrTenantNotFound
+	}
+	now := time.Now()
+	row.deletedAt = &now
+	return nil
+}
+
+// CountByStatus counts the tenants that are not deleted, by status
+func (r *TenantRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, row := range r.db.tenants {
+		if row.deletedAt == nil {
+			counts[row.tenant.Status]++
+		}
+	}
+	return counts, nil
+}
+
+// PurgeDeleted permanently removes up to limit tenants soft-deleted before the given time.
+// Tenants that still have users are kept until those users are purged.
+func (r *TenantRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	occupied := make(map[string]bool)
+	for _, u := range r.db.users {
+		if u.TenantID != nil {
+			occupied[*u.TenantID] = true
+		}
+	}
+
+	var n int64
+	for id, row := range r.db.tenants {
+		if n >= int64(limit) {
+			break
+		}
+		if row.deletedAt != nil && row.deletedAt.Before(before) && !occupied[id] {
+			delete(r.db.tenants, id)
+			for mid, m := range r.db.memberships {
+				if m.TenantID == id {
+					delete(r.db.memberships, mid)
+				}
+			}
+			r.db.deleteProjects(func(p *authz.Project) bool { return p.TenantID == id })
+			n++
+		}
+	}
+	return n, nil
+}
+
+// List lists tenants using keyset pagination
+func (r *TenantRepository) List(ctx context.Context, opts tenant.ListOptions) (*tenant.ListResult, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = tenant.DefaultListLimit
+	}
+
+	byName := opts.Sort == tenant.SortNameAsc || opts.Sort == tenant.SortNameDesc
+	desc := opts.Sort == "" || opts.Sort == tenant.SortCreatedDesc || opts.Sort == tenant.SortNameDesc
+
+	// compare orders two tenants by the sort key with ID as tiebreaker, ascending
+	compare := func(a, b *tenant.Tenant) int {
+		var c int
+		if byName {
+			c = strings.Compare(a.Name, b.Name)
+		} else {
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		return cmp.Or(c, strings.Compare(a.ID, b.ID))
+	}
+
+	var after *tenant.Tenant
+	if opts.Cursor != "" {
+		c, err := tenant.DecodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &tenant.Tenant{ID: c.ID, Name: c.Value}
+		if !byName {
+			t, err := time.Parse(time.RFC3339Nano, c.Value)
+			if err != nil {
+				return nil, tenant.ErrInvalidCursor
+			}
+			after.CreatedAt = t
+		}
+	}
+
+	query := strings.ToLower(opts.Query)
+
+	r.db.mu.RLock()
+	tenants := []*tenant.Tenant{}
+	for _, row := range r.db.tenants {
+		t := row.tenant
+		if row.deletedAt != nil {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(t.Name), query) {
+			continue
+		}
+		if opts.Status != "" && t.Status != opts.Status {
+			continue
+		}
+		if after != nil {
+			c := compare(&t, after)
+			if (desc && c >= 0) || (!desc && c <= 0) {
+				continue
+			}
+		}
+		tenants = append(tenants, &t)
+	}
+	r.db.mu.RUnlock()
+
+	slices.SortFunc(tenants, func(a, b *tenant.Tenant) int {
+		if desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+
+	result := &tenant.ListResult{Tenants: tenants}
+	if len(tenants) > opts.Limit {
+		result.Tenants = tenants[:opts.Limit]
+		last := result.Tenants[opts.Limit-1]
+		value := last.Name
+		if !byName {
+			value = last.CreatedAt.Format(time.RFC3339Nano)
+		}
+		result.NextCursor = tenant.EncodeCursor(tenant.Cursor{Sort: opts.Sort, Value: value, ID: last.ID})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TenantRoleRepository implements tenant.RoleRepository on top of the RBAC assignments
+type TenantRoleRepository struct {
+	db *DB
+}
+
+// NewTenantRoleRepository creates a new tenant role repository
+func NewTenantRoleRepository(db *DB) *TenantRoleRepository {
+	return &TenantRoleRepository{db: db}
+}
+
+// mapTenantRole maps internal tenant role names to seeded RBAC role IDs
+func mapTenantRole(role string) string {
+	switch role {
+	case tenant.RoleTenantOwner:
+		return tenantAdminRoleID // Map owner to admin for now
+	case tenant.RoleTenantAdmin:
+		return tenantAdminRoleID
+	default:
+		return memberRoleID
+	}
+}
+
+// AssignRole assigns a role to a user in a tenant
+func (r *TenantRoleRepository) AssignRole(ctx context.Context, role *tenant.TenantUserRole) error {
+	role.GrantedAt = time.Now()
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	roleID := mapTenantRole(role.Role)
+	tenantID := role.TenantID
+	if _, ok := r.db.findAssignment(role.UserID, roleID, authz.ScopeTenant, &tenantID); ok {
+		return nil
+	}
+	grantedBy := role.GrantedBy
+	if grantedBy == tenant.GranterSystem {
+		grantedBy = "" // Stored without a granter, as in the SQL stores
+	}
+	r.db.assignments[role.ID] = &authz.Assignment{
+		ID:             role.ID,
+		UserID:         role.UserID,
+		RoleID:         roleID,
+		Scope:          authz.ScopeTenant,
+		ScopeContextID: &tenantID,
+		GrantedAt:      role.GrantedAt,
+		GrantedBy:      grantedBy,
+	}
+	return nil
+}
+
+// RevokeRole revokes a role from a user in a tenant
+func (r *TenantRoleRepository) RevokeRole(ctx context.Context, tenantID, userID, role string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	id, ok := r.db.findAssignment(userID, mapTenantRole(role), authz.ScopeTenant, &tenantID)
+	if !ok {
+		return tenant.ErrRoleNotFound
+	}
+	delete(r.db.assignments, id)
+	return nil
+}
+
+// RevokeOwnerRole revokes an owner or admin role unless the user is the tenant's only owner
+func (r *TenantRoleRepository) RevokeOwnerRole(ctx context.Context, tenantID, userID, role string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	err := r.db.revokeUnlessLast(userID, mapTenantRole(role), authz.ScopeTenant, &tenantID)
+	switch {
+	case errors.Is(err, authz.ErrAssignmentNotFound):
+		return tenant.ErrRoleNotFound
+	case errors.Is(err, authz.ErrLastAssignment):
+		return tenant.ErrLastTenantOwner
+	}
+	return err
+}
+
+// GetUserRoles retrieves all roles a user has in a tenant
+func (r *TenantRoleRepository) GetUserRoles(ctx context.Context, tenantID, userID string) ([]*tenant.TenantUserRole, error) {
+	return r.list(tenantID, func(a *authz.Assignment) bool { return a.UserID == userID }), nil
+}
+
+// GetTenantUsers retrieves all users with roles in a tenant
+func (r *TenantRoleRepository) GetTenantUsers(ctx context.Context, tenantID string) ([]*tenant.TenantUserRole, error) {
+	return r.list(tenantID, func(a *authz.Assignment) bool { return true }), nil
+}
+
+// list returns the tenant-scoped assignments in a tenant that match, joined with their role names
+func (r *TenantRoleRepository) list(tenantID string, match func(*authz.Assignment) bool) []*tenant.TenantUserRole {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var roles []*tenant.TenantUserRole
+	for _, a := range r.db.assignments {
+		if a.Scope != authz.ScopeTenant || a.ScopeContextID == nil || *a.ScopeContextID != tenantID || !match(a) {
+			continue
+		}
+		role, ok := r.db.roles[a.RoleID]
+		if !ok {
+			continue
+		}
+		roles = append(roles, &tenant.TenantUserRole{
+			ID:        a.ID,
+			TenantID:  tenantID,
+			UserID:    a.UserID,
+			Role:      role.Name,
+			GrantedAt: a.GrantedAt,
+			GrantedBy: cmp.Or(a.GrantedBy, tenant.GranterSystem),
+		})
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].GrantedAt.Before(roles[j].GrantedAt) })
+	return roles
+}
@@ -0,0 +1,292 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// AccessTokenRepository implements oauth2.AccessTokenRepository
+type AccessTokenRepository struct {
+	db *DB
+}
+
+// NewAccessTokenRepository creates a new access token repository
+func NewAccessTokenRepository(db *DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+// Create creates a new access token
+func (r *AccessTokenRepository) Create(ctx context.Context, token *oauth2.AccessToken) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.accessTokens[token.TokenHash]; ok {
+		return fmt.Errorf("failed to create access token: %w", ErrDuplicate)
+	}
+	cp := *token
+	cp.Claims = maps.Clone(token.Claims)
+	r.db.accessTokens[token.TokenHash] = &cp
+	return nil
+}
+
+// GetByTokenHash retrieves an access token issued within a tenant
+func (r *AccessTokenRepository) GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*oauth2.AccessToken, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	token, ok := r.db.accessTokens[tokenHash]
+	if !ok || token.TenantID != tenantID {
+		return nil, oauth2.ErrTokenNotFound
+	}
+	cp := *token
+	return &cp, nil
+}
+
+// FindByTokenHash retrieves an access token issued within any tenant
+func (r *AccessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*oauth2.AccessToken, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	token, ok := r.db.accessTokens[tokenHash]
+	if !ok {
+		return nil, oauth2.ErrTokenNotFound
+	}
+	cp := *token
+	return &cp, nil
+}
+
+// Revoke revokes an access token
+func (r *AccessTokenRepository) Revoke(ctx context.Context, tenantID, tokenHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	token, ok := r.db.accessTokens[tokenHash]
+	if !ok || token.TenantID != tenantID {
+		return oauth2.ErrTokenNotFound
+	}
+	now := time.Now()
+	token.IsRevoked = true
+	token.RevokedAt = &now
+	return nil
+}
+
+// RevokeByUserID revokes every unrevoked access token of a user and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, token := range r.db.accessTokens {
+		if token.UserID == userID && !token.IsRevoked {
+			token.IsRevoked = true
+			token.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteExpired deletes up to limit expired access tokens and returns how many were removed
+func (r *AccessTokenRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for hash, token := range r.db.accessTokens {
+		if n >= int64(limit) {
+			break
+		}
+		if token.ExpiresAt.Before(now) {
+			delete(r.db.accessTokens, hash)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RefreshTokenRepository implements oauth2.RefreshTokenRepository
+type RefreshTokenRepository struct {
+	db *DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create creates a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *oauth2.RefreshToken) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.refreshTokens[token.TokenHash]; ok {
+		return fmt.Errorf("failed to create refresh token: %w", ErrDuplicate)
+	}
+	cp := *token
+	r.db.refreshTokens[token.TokenHash] = &cp
+	return nil
+}
+
+// GetByTokenHash retrieves a refresh token issued within a tenant
+func (r *RefreshTokenRepository) GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*oauth2.RefreshToken, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	token, ok := r.db.refreshTokens[tokenHash]
+	if !ok || token.TenantID != tenantID {
+		return nil, oauth2.ErrTokenNotFound
+	}
+	cp := *token
+	return &cp, nil
+}
+
+// Revoke revokes a refresh token
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, tenantID, tokenHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	token, ok := r.db.refreshTokens[tokenHash]
+	if !ok || token.TenantID != tenantID {
+		return oauth2.ErrTokenNotFound
+	}
+	now := time.Now()
+	token.IsRevoked = true
+	token.RevokedAt = &now
+	return nil
+}
+
+// RevokeByUserID revokes every unrevoked refresh token of a user and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, token := range r.db.refreshTokens {
+		if token.UserID == userID && !token.IsRevoked {
+			token.IsRevoked = true
+			token.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteExpired deletes up to limit expired refresh tokens and returns how many were removed
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for hash, token := range r.db.refreshTokens {
+		if n >= int64(limit) {
+			break
+		}
+		if token.ExpiresAt.Before(now) {
+			delete(r.db.refreshTokens, hash)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RevokeByTenantID revokes up to limit unexpired, unrevoked access tokens of a tenant and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, token := range r.db.accessTokens {
+		if n >= int64(limit) {
+			break
+		}
+		if token.TenantID == tenantID && !token.IsRevoked && token.ExpiresAt.After(now) {
+			token.IsRevoked = true
+			token.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RevokeByClientID revokes up to limit unexpired, unrevoked access tokens issued to a client and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, token := range r.db.accessTokens {
+		if n >= int64(limit) {
+			break
+		}
+		if token.ClientID == clientID && !token.IsRevoked && token.ExpiresAt.After(now) {
+			token.IsRevoked = true
+			token.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RevokeByTenantID revokes up to limit unexpired, unrevoked refresh tokens of a tenant and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, token := range r.db.refreshTokens {
+		if n >= int64(limit) {
+			break
+		}
+		if token.TenantID == tenantID && !token.IsRevoked && token.ExpiresAt.After(now) {
+			token.IsRevoked = true
+			token.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RevokeByClientID revokes up to limit unexpired, unrevoked refresh tokens issued to a client and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, token := range r.db.refreshTokens {
+		if n >= int64(limit) {
+			break
+		}
+		if token.ClientID == clientID && !token.IsRevoked && token.ExpiresAt.After(now) {
+			token.IsRevoked = true
+			token.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
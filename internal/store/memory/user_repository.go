@@ -0,0 +1,303 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// UserRepository implements identity.UserRepository
+type UserRepository struct {
+	db *DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db *DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create creates a new user identity
+func (r *UserRepository) Create(ctx context.Context, user *identity.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[user.ID]; ok {
+		return fmt.Errorf("failed to insert user: %w", ErrDuplicate)
+	}
+	// Soft-deleted users do not hold on to their email
+	for _, u := range r.db.users {
+		if u.DeletedAt == nil && u.Email == user.Email && sameTenant(u.TenantID, user.TenantID) {
+			return fmt.Errorf("failed to insert user: %w", ErrDuplicate)
+		}
+	}
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
+
+	cp := *user
+	r.db.users[user.ID] = &cp
+	if user.TenantID != nil {
+		// The user is a member of their home tenant; the home membership takes the user's ID
+		r.db.memberships[user.ID] = &tenant.Membership{
+			ID:        user.ID,
+			TenantID:  *user.TenantID,
+			UserID:    user.ID,
+			Home:      true,
+			CreatedAt: now,
+		}
+	}
+	return nil
+}
+
+// AddCredentials adds credentials for a user
+func (r *UserRepository) AddCredentials(ctx context.Context, credentials *identity.Credentials) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.credentials[credentials.UserID]; ok {
+		return fmt.Errorf("failed to insert credentials: %w", ErrDuplicate)
+	}
+
+	credentials.UpdatedAt = time.Now()
+	cp := *credentials
+	r.db.credentials[credentials.UserID] = &cp
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*identity.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	u, ok := r.db.users[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, identity.ErrUserNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+// GetByEmail retrieves a user by email within a tenant (or no tenant for Platform Admins)
+func (r *UserRepository) GetByEmail(ctx context.Context, tenantID *string, email string) (*identity.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, u := range r.db.users {
+		if u.DeletedAt == nil && u.Email == email && sameTenant(u.TenantID, tenantID) {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, identity.ErrUserNotFound
+}
+
+// ListByEmail retrieves the users registered with email in any tenant, platform admins
+// included, oldest first
+func (r *UserRepository) ListByEmail(ctx context.Context, email string) ([]*identity.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var users []*identity.User
+	for _, u := range r.db.users {
+		if u.DeletedAt == nil && u.Email == email {
+			cp := *u
+			users = append(users, &cp)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	return users, nil
+}
+
+// Update updates user information
+func (r *UserRepository) Update(ctx context.Context, user *identity.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	u, ok := r.db.users[user.ID]
+	if !ok || u.DeletedAt != nil || !sameTenant(u.TenantID, user.TenantID) {
+		return identity.ErrUserNotFound
+	}
+	if u.Version != user.Version {
+		return identity.ErrUserVersionConflict
+	}
+
+	u.Email = user.Email
+	u.EmailVerified = user.EmailVerified
+	u.Profile = user.Profile
+	u.UpdatedAt = time.Now()
+	u.Version++
+	user.Version = u.Version
+	return nil
+}
+
+// UpdateLockout updates user lockout status
+func (r *UserRepository) UpdateLockout(ctx context.Context, userID string, failedAttempts int, lockedUntil *time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if u, ok := r.db.users[userID]; ok {
+		u.FailedLoginAttempts = failedAttempts
+		u.LockedUntil = nil
+		if lockedUntil != nil {
+			t := *lockedUntil
+			u.LockedUntil = &t
+		}
+		u.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// Delete soft-deletes a user
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	u, ok := r.db.users[id]
+	if !ok || u.DeletedAt != nil {
+		return identity.ErrUserNotFound
+	}
+	now := time.Now()
+	u.DeletedAt = &now
+	return nil
+}
+
+// Erase anonymizes a user and removes their credentials, role assignments and memberships
+func (r *UserRepository) Erase(ctx context.Context, userID, anonEmail string, at time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	u, ok := r.db.users[userID]
+	if !ok {
+		return identity.ErrUserNotFound
+	}
+	delete(r.db.credentials, userID)
+	for id, a := range r.db.assignments {
+		if a.UserID == userID {
+			delete(r.db.assignments, id)
+		}
+	}
+	for id, m := range r.db.memberships {
+		if m.UserID == userID {
+			delete(r.db.memberships, id)
+		}
+	}
+	for key, m := range r.db.projectMembers {
+		if m.UserID == userID {
+			delete(r.db.projectMembers, key)
+		}
+	}
+	u.Email = anonEmail
+	u.EmailVerified = false
+	u.Profile = identity.Profile{}
+	u.FailedLoginAttempts = 0
+	u.LockedUntil = nil
+	u.UpdatedAt = at
+	if u.DeletedAt == nil {
+		u.DeletedAt = &at
+	}
+	u.Version++
+	return nil
+}
+
+// PurgeDeleted permanently removes up to limit users soft-deleted before the given time.
+// Users recorded as the granter of a role assignment are kept so the grant stays attributable.
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	granters := make(map[string]bool)
+	for _, a := range r.db.assignments {
+		granters[a.GrantedBy] = true
+	}
+
+	var n int64
+	for id, u := range r.db.users {
+		if n >= int64(limit) {
+			break
+		}
+		if u.DeletedAt != nil && u.DeletedAt.Before(before) && !granters[id] {
+			delete(r.db.users, id)
+			delete(r.db.credentials, id)
+			delete(r.db.accountDeletions, id)
+			for mid, m := range r.db.memberships {
+				if m.UserID == id {
+					delete(r.db.memberships, mid)
+				} else if m.CreatedBy == id {
+					m.CreatedBy = ""
+				}
+			}
+			r.db.deleteProjects(func(p *authz.Project) bool { return p.OwnerID == id })
+			for key, m := range r.db.projectMembers {
+				if m.UserID == id {
+					delete(r.db.projectMembers, key)
+				} else if m.GrantedBy == id {
+					m.GrantedBy = ""
+				}
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// GetCredentials retrieves user credentials
+func (r *UserRepository) GetCredentials(ctx context.Context, userID string) (*identity.Credentials, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	c, ok := r.db.credentials[userID]
+	if !ok {
+		return nil, identity.ErrUserNotFound
+	}
+	cp := *c
+	return &cp, nil
+}
+
+// UpdatePassword updates user password and clears its expiry
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	c, ok := r.db.credentials[userID]
+	if !ok {
+		return identity.ErrUserNotFound
+	}
+	c.PasswordHash = passwordHash
+	c.UpdatedAt = time.Now()
+	c.ExpiresAt = nil
+	return nil
+}
+
+// ExpirePassword marks user password as expired from the given time
+func (r *UserRepository) ExpirePassword(ctx context.Context, userID string, at time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	c, ok := r.db.credentials[userID]
+	if !ok {
+		return identity.ErrUserNotFound
+	}
+	c.ExpiresAt = &at
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// WebhookSubscriptionRepository implements webhook.SubscriptionRepository
+type WebhookSubscriptionRepository struct {
+	db *DB
+}
+
+// NewWebhookSubscriptionRepository creates a new webhook subscription repository
+func NewWebhookSubscriptionRepository(db *DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: db}
+}
+
+func copySubscription(s *webhook.Subscription) *webhook.Subscription {
+	cp := *s
+	cp.EventTypes = slices.Clone(s.EventTypes)
+	cp.SecretEncrypted = slices.Clone(s.SecretEncrypted)
+	return &cp
+}
+
+// Create stores a new subscription
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, sub *webhook.Subscription) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.webhooks[sub.ID]; ok {
+		return ErrDuplicate
+	}
+	r.db.webhooks[sub.ID] = copySubscription(sub)
+	return nil
+}
+
+// Get retrieves a tenant's subscription by ID
+func (r *WebhookSubscriptionRepository) Get(ctx context.Context, tenantID, id string) (*webhook.Subscription, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	sub, ok := r.db.webhooks[id]
+	if !ok || sub.TenantID != tenantID {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+	return copySubscription(sub), nil
+}
+
+// List retrieves all of a tenant's subscriptions ordered by ID
+func (r *WebhookSubscriptionRepository) List(ctx context.Context, tenantID string) ([]*webhook.Subscription, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var subs []*webhook.Subscription
+	for _, sub := range r.db.webhooks {
+		if sub.TenantID == tenantID {
+			subs = append(subs, copySubscription(sub))
+		}
+	}
+	slices.SortFunc(subs, func(a, b *webhook.Subscription) int { return strings.Compare(a.ID, b.ID) })
+	return subs, nil
+}
+
+// Update replaces a subscription's settings and secret
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, sub *webhook.Subscription) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.webhooks[sub.ID]
+	if !ok || existing.TenantID != sub.TenantID {
+		return webhook.ErrSubscriptionNotFound
+	}
+	updated := copySubscription(sub)
+	updated.CreatedBy = existing.CreatedBy
+	updated.CreatedAt = existing.CreatedAt
+	r.db.webhooks[sub.ID] = updated
+	return nil
+}
+
+// Delete removes a subscription together with its deliveries, as the SQL foreign key cascade does
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, tenantID, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	sub, ok := r.db.webhooks[id]
+	if !ok || sub.TenantID != tenantID {
+		return webhook.ErrSubscriptionNotFound
+	}
+	delete(r.db.webhooks, id)
+	for deliveryID, d := range r.db.deliveries {
+		if d.SubscriptionID == id {
+			delete(r.db.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// WebhookDeliveryRepository implements webhook.DeliveryRepository
+type WebhookDeliveryRepository struct {
+	db *DB
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+func copyDelivery(d *webhook.Delivery) *webhook.Delivery {
+	cp := *d
+	cp.Payload = slices.Clone(d.Payload)
+	if d.LastAttemptAt != nil {
+		t := *d.LastAttemptAt
+		cp.LastAttemptAt = &t
+	}
+	return &cp
+}
+
+// Create stores a new delivery
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d *webhook.Delivery) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.deliveries[d.ID]; ok {
+		return ErrDuplicate
+	}
+	r.db.deliveries[d.ID] = copyDelivery(d)
+	return nil
+}
+
+// Get retrieves a tenant's delivery by ID
+func (r *WebhookDeliveryRepository) Get(ctx context.Context, tenantID, id string) (*webhook.Delivery, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	d, ok := r.db.deliveries[id]
+	if !ok || d.TenantID != tenantID {
+		return nil, webhook.ErrDeliveryNotFound
+	}
+	return copyDelivery(d), nil
+}
+
+// List retrieves a page of a subscription's deliveries, ordered by ID
+func (r *WebhookDeliveryRepository) List(ctx context.Context, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var matched []*webhook.Delivery
+	for _, d := range r.db.deliveries {
+		if d.TenantID != filter.TenantID || d.SubscriptionID != filter.SubscriptionID ||
+			(filter.Status != "" && d.Status != filter.Status) {
+			continue
+		}
+		matched = append(matched, copyDelivery(d))
+	}
+	return page(matched, filter.Page, func(d *webhook.Delivery) string { return d.ID }), nil
+}
+
+// Update stores the outcome of a delivery attempt
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d *webhook.Delivery) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.deliveries[d.ID]; !ok {
+		return webhook.ErrDeliveryNotFound
+	}
+	r.db.deliveries[d.ID] = copyDelivery(d)
+	return nil
+}
+
+// ClaimDue leases up to limit due pending deliveries until leaseUntil and returns them
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var due []*webhook.Delivery
+	for _, d := range r.db.deliveries {
+		if d.Status == webhook.StatusPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, func(a, b *webhook.Delivery) int { return a.NextAttemptAt.Compare(b.NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*webhook.Delivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = leaseUntil
+		claimed = append(claimed, copyDelivery(d))
+	}
+	return claimed, nil
+}
+
+// DeleteFinished removes up to limit succeeded or dead deliveries last updated before the cutoff
+func (r *WebhookDeliveryRepository) DeleteFinished(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var n int64
+	for id, d := range r.db.deliveries {
+		if n >= int64(limit) {
+			break
+		}
+		if d.Status != webhook.StatusPending && d.UpdatedAt.Before(before) {
+			delete(r.db.deliveries, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// AccountDeletionRepository implements identity.AccountDeletionRepository
+type AccountDeletionRepository struct {
+	db *DB
+}
+
+// NewAccountDeletionRepository creates a new account deletion repository
+func NewAccountDeletionRepository(db *DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{db: db}
+}
+
+// Create stores an account deletion, replacing any earlier one of the user
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *identity.AccountDeletion) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO account_deletions (user_id, requested_at, delete_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			requested_at = EXCLUDED.requested_at,
+			delete_at = EXCLUDED.delete_at
+	`, deletion.UserID, deletion.RequestedAt, deletion.DeleteAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create account deletion: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves the account deletion of a user
+func (r *AccountDeletionRepository) Get(ctx context.Context, userID string) (*identity.AccountDeletion, error) {
+	var d identity.AccountDeletion
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT user_id, requested_at, delete_at FROM account_deletions WHERE user_id = $1
+	`, userID).Scan(&d.UserID, &d.RequestedAt, &d.DeleteAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, identity.ErrAccountDeletionNotFound
+		}
+		return nil, fmt.Errorf("failed to get account deletion: %w", err)
+	}
+
+	return &d, nil
+}
+
+// Delete removes the account deletion of a user
+func (r *AccountDeletionRepository) Delete(ctx context.Context, userID string) error {
+	result, err := r.db.pool.Exec(ctx, `DELETE FROM account_deletions WHERE user_id = $1`, userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete account deletion: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return identity.ErrAccountDeletionNotFound
+	}
+
+	return nil
+}
+
+// ListDue lists up to limit account deletions whose grace period ended before the given time
+func (r *AccountDeletionRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*identity.AccountDeletion, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT user_id, requested_at, delete_at FROM account_deletions
+		WHERE delete_at < $1 ORDER BY delete_at LIMIT $2
+	`, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due account deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*identity.AccountDeletion
+	for rows.Next() {
+		var d identity.AccountDeletion
+		if err := rows.Scan(&d.UserID, &d.RequestedAt, &d.DeleteAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account deletion: %w", err)
+		}
+		due = append(due, &d)
+	}
+
+	return due, rows.Err()
+}
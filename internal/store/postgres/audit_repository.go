@@ -0,0 +1,216 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// AuditRepository implements audit.Repository
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new audit event repository
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create stores an audit event
+func (r *AuditRepository) Create(ctx context.Context, event *audit.Event) error {
+	var metadata []byte
+	if len(event.Metadata) > 0 {
+		b, err := json.Marshal(event.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit metadata: %w", err)
+		}
+		metadata = b
+	}
+
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO audit_events (
+			id, schema_version, tenant_id, type, actor_id, resource, request_id, ip_address, user_agent, metadata, occurred_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, event.ID, event.SchemaVersion, event.TenantID, event.Type, event.ActorID, event.Resource,
+		event.RequestID, event.IPAddress, event.UserAgent, metadata, event.Timestamp)
+
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+// List retrieves a page of audit events matching the filter, ordered by ID.
+// On CockroachDB it is a follower read and may miss events from the last few seconds.
+func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]*audit.Event, error) {
+	conditions := []string{"tenant_id = $1"}
+	args := []any{filter.TenantID}
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Type != "" {
+		conditions = append(conditions, "type = "+addArg(filter.Type))
+	}
+	if filter.ActorID != "" {
+		conditions = append(conditions, "actor_id = "+addArg(filter.ActorID))
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "occurred_at >= "+addArg(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "occurred_at < "+addArg(filter.Until))
+	}
+
+	page, args := pageClause("id", filter.Page, args)
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, schema_version, tenant_id, type, actor_id, resource, request_id, ip_address, user_agent, metadata, occurred_at
+		FROM `+r.db.historical("audit_events")+`
+		WHERE `+strings.Join(conditions, " AND ")+page, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(rows)
+}
+
+// ListExpired retrieves up to filter.Limit events older than filter.Before, ordered by ID
+func (r *AuditRepository) ListExpired(ctx context.Context, filter audit.ExpiredFilter) ([]*audit.Event, error) {
+	conditions := []string{"occurred_at < $1"}
+	args := []any{filter.Before}
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.TenantIDs) > 0 {
+		conditions = append(conditions, "tenant_id = ANY("+addArg(filter.TenantIDs)+")")
+	}
+	if len(filter.ExceptTenantIDs) > 0 {
+		conditions = append(conditions, "tenant_id <> ALL("+addArg(filter.ExceptTenantIDs)+")")
+	}
+
+	limit := addArg(filter.Limit)
+
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, schema_version, tenant_id, type, actor_id, resource, request_id, ip_address, user_agent, metadata, occurred_at
+		FROM `+r.db.historical("audit_events")+`
+		WHERE `+strings.Join(conditions, " AND ")+" ORDER BY id LIMIT "+limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired audit events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(rows)
+}
+
+// Delete removes audit events by ID
+func (r *AuditRepository) Delete(ctx context.Context, ids []string) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `DELETE FROM audit_events WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit events: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// Stats aggregates the audit events of every tenant within the filter's window.
+// On CockroachDB it is a follower read and may miss events from the last few seconds.
+func (r *AuditRepository) Stats(ctx context.Context, filter audit.StatsFilter) (*audit.Stats, error) {
+	table := r.db.historical("audit_events")
+	stats := &audit.Stats{EventCounts: make(map[string]int64)}
+
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT type, COUNT(*) FROM `+table+`
+		WHERE occurred_at >= $1 AND occurred_at < $2
+		GROUP BY type
+	`, filter.Since, filter.Until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count audit events: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eventType string
+		var n int64
+		if err := rows.Scan(&eventType, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event count: %w", err)
+		}
+		stats.EventCounts[eventType] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	err = r.db.pool.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT actor_id) FROM `+table+`
+		WHERE occurred_at >= $1 AND occurred_at < $2 AND type = $3 AND actor_id <> ''
+	`, filter.Since, filter.Until, audit.TypeLoginSuccess).Scan(&stats.ActiveUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active users: %w", err)
+	}
+
+	clients, err := r.db.pool.Query(ctx, `
+		SELECT tenant_id, metadata->>'client_id', COUNT(*) AS n FROM `+table+`
+		WHERE occurred_at >= $1 AND occurred_at < $2 AND type = $3 AND metadata->>'client_id' <> ''
+		GROUP BY tenant_id, metadata->>'client_id'
+		ORDER BY n DESC, tenant_id, metadata->>'client_id'
+		LIMIT $4
+	`, filter.Since, filter.Until, audit.TypeTokenIssued, filter.TopClients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank clients: %w", err)
+	}
+	defer clients.Close()
+	for clients.Next() {
+		var c audit.ClientStats
+		if err := clients.Scan(&c.TenantID, &c.ClientID, &c.TokensIssued); err != nil {
+			return nil, fmt.Errorf("failed to scan client stats: %w", err)
+		}
+		stats.TopClients = append(stats.TopClients, c)
+	}
+	if err := clients.Err(); err != nil {
+		return nil, fmt.Errorf("failed to rank clients: %w", err)
+	}
+	return stats, nil
+}
+
+func scanAuditEvents(rows pgx.Rows) ([]*audit.Event, error) {
+	var events []*audit.Event
+	for rows.Next() {
+		var e audit.Event
+		var metadata []byte
+		if err := rows.Scan(&e.ID, &e.SchemaVersion, &e.TenantID, &e.Type, &e.ActorID, &e.Resource,
+			&e.RequestID, &e.IPAddress, &e.UserAgent, &metadata, &e.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &e.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode audit metadata: %w", err)
+			}
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, nil
+}
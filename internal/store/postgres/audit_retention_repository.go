@@ -0,0 +1,112 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// AuditRetentionRepository implements audit.RetentionRepository
+type AuditRetentionRepository struct {
+	db *DB
+}
+
+// NewAuditRetentionRepository creates a new audit retention policy repository
+func NewAuditRetentionRepository(db *DB) *AuditRetentionRepository {
+	return &AuditRetentionRepository{db: db}
+}
+
+// Get retrieves a tenant's retention policy
+func (r *AuditRetentionRepository) Get(ctx context.Context, tenantID string) (*audit.RetentionPolicy, error) {
+	var p audit.RetentionPolicy
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT tenant_id, retention_days, updated_by, updated_at
+		FROM audit_retention_policies
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&p.TenantID, &p.Days, &p.UpdatedBy, &p.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, audit.ErrRetentionNotFound
+		}
+		return nil, fmt.Errorf("failed to get audit retention policy: %w", err)
+	}
+	return &p, nil
+}
+
+// Upsert creates or replaces a tenant's retention policy
+func (r *AuditRetentionRepository) Upsert(ctx context.Context, p *audit.RetentionPolicy) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO audit_retention_policies (tenant_id, retention_days, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			retention_days = EXCLUDED.retention_days,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+	`, p.TenantID, p.Days, p.UpdatedBy, p.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save audit retention policy: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a tenant's retention policy
+func (r *AuditRetentionRepository) Delete(ctx context.Context, tenantID string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM audit_retention_policies WHERE tenant_id = $1
+	`, tenantID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete audit retention policy: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return audit.ErrRetentionNotFound
+	}
+
+	return nil
+}
+
+// List retrieves all retention policies
+func (r *AuditRetentionRepository) List(ctx context.Context) ([]*audit.RetentionPolicy, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT tenant_id, retention_days, updated_by, updated_at
+		FROM audit_retention_policies
+		ORDER BY tenant_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*audit.RetentionPolicy
+	for rows.Next() {
+		var p audit.RetentionPolicy
+		if err := rows.Scan(&p.TenantID, &p.Days, &p.UpdatedBy, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit retention policy: %w", err)
+		}
+		policies = append(policies, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit retention policies: %w", err)
+	}
+
+	return policies, nil
+}
@@ -17,11 +17,14 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
 )
 
 // ProjectRepository implements authz.ProjectRepository
@@ -34,16 +37,16 @@ func NewProjectRepository(db *DB) *ProjectRepository {
 	return &ProjectRepository{db: db}
 }
 
-// Create creates a new project
-func (r *ProjectRepository) Create(project *authz.Project) error {
-	ctx := context.Background()
+const projectColumns = `id, tenant_id, name, description, owner_id, created_at, updated_at, deleted_at`
 
+// Create creates a new project
+func (r *ProjectRepository) Create(ctx context.Context, project *authz.Project) error {
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO projects (
-			id, name, description, owner_id, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6)
+			id, tenant_id, name, description, owner_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`,
-		project.ID, project.Name, project.Description, project.OwnerID,
+		project.ID, project.TenantID, project.Name, project.Description, project.OwnerID,
 		project.CreatedAt, project.UpdatedAt,
 	)
 
@@ -55,73 +58,33 @@ func (r *ProjectRepository) Create(project *authz.Project) error {
 }
 
 // GetByID retrieves a project by ID
-func (r *ProjectRepository) GetByID(id string) (*authz.Project, error) {
-	ctx := context.Background()
-
-	var project authz.Project
-	var deletedAt sql.NullTime
-
-	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, name, description, owner_id, created_at, updated_at, deleted_at
-		FROM projects
-		WHERE id = $1 AND deleted_at IS NULL
-	`, id).Scan(
-		&project.ID, &project.Name, &project.Description, &project.OwnerID,
-		&project.CreatedAt, &project.UpdatedAt, &deletedAt,
-	)
-
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, authz.ErrProjectNotFound
-		}
-		return nil, fmt.Errorf("failed to get project: %w", err)
-	}
-
-	if deletedAt.Valid {
-		project.DeletedAt = &deletedAt.Time
-	}
-
-	return &project, nil
+func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*authz.Project, error) {
+	return r.get(ctx, `WHERE id = $1 AND deleted_at IS NULL`, id)
 }
 
 // GetByName retrieves a project by name
-func (r *ProjectRepository) GetByName(name string) (*authz.Project, error) {
-	ctx := context.Background()
-
-	var project authz.Project
-	var deletedAt sql.NullTime
-
-	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, name, description, owner_id, created_at, updated_at, deleted_at
-		FROM projects
-		WHERE name = $1 AND deleted_at IS NULL
-	`, name).Scan(
-		&project.ID, &project.Name, &project.Description, &project.OwnerID,
-		&project.CreatedAt, &project.UpdatedAt, &deletedAt,
-	)
+func (r *ProjectRepository) GetByName(ctx context.Context, name string) (*authz.Project, error) {
+	return r.get(ctx, `WHERE name = $1 AND deleted_at IS NULL`, name)
+}
 
+func (r *ProjectRepository) get(ctx context.Context, where string, args ...any) (*authz.Project, error) {
+	project, err := scanProject(r.db.pool.QueryRow(ctx, `SELECT `+projectColumns+` FROM projects `+where, args...))
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, authz.ErrProjectNotFound
 		}
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
-
-	if deletedAt.Valid {
-		project.DeletedAt = &deletedAt.Time
-	}
-
-	return &project, nil
+	return project, nil
 }
 
 // Update updates project information
-func (r *ProjectRepository) Update(project *authz.Project) error {
-	ctx := context.Background()
-
+func (r *ProjectRepository) Update(ctx context.Context, project *authz.Project) error {
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE projects SET
 			name = $2,
-			description = $3
+			description = $3,
+			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND deleted_at IS NULL
 	`,
 		project.ID, project.Name, project.Description,
@@ -139,9 +102,7 @@ func (r *ProjectRepository) Update(project *authz.Project) error {
 }
 
 // Delete soft-deletes a project
-func (r *ProjectRepository) Delete(id string) error {
-	ctx := context.Background()
-
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE projects SET deleted_at = $2
 		WHERE id = $1 AND deleted_at IS NULL
@@ -158,81 +119,172 @@ func (r *ProjectRepository) Delete(id string) error {
 	return nil
 }
 
-// ListByOwner retrieves all projects owned by a user
-func (r *ProjectRepository) ListByOwner(ownerID string) ([]*authz.Project, error) {
-	ctx := context.Background()
+// PurgeDeleted permanently removes up to limit projects soft-deleted before the given time; their
+// members go with them
+func (r *ProjectRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM projects WHERE id IN (
+			SELECT id FROM projects
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			LIMIT $2
+		)
+	`, before, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted projects: %w", err)
+	}
 
-	rows, err := r.db.pool.Query(ctx, `
-		SELECT id, name, description, owner_id, created_at, updated_at, deleted_at
+	return result.RowsAffected(), nil
+}
+
+// ListByOwner retrieves a page of projects owned by a user, ordered by ID
+func (r *ProjectRepository) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*authz.Project, error) {
+	page, args := pageClause("id", p, []any{ownerID})
+	return r.list(ctx, `
+		SELECT `+projectColumns+`
+		FROM projects
+		WHERE owner_id = $1 AND deleted_at IS NULL`+page, args...)
+}
+
+// ListByUser retrieves a page of projects a user has a role in, ordered by ID
+func (r *ProjectRepository) ListByUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Project, error) {
+	page, args := pageClause("p.id", p, []any{userID})
+	return r.list(ctx, `
+		SELECT p.id, p.tenant_id, p.name, p.description, p.owner_id, p.created_at, p.updated_at, p.deleted_at
+		FROM projects p
+		INNER JOIN project_members m ON p.id = m.project_id
+		WHERE m.user_id = $1 AND p.deleted_at IS NULL`+page, args...)
+}
+
+// ListByTenant retrieves a page of a tenant's projects, ordered by ID
+func (r *ProjectRepository) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*authz.Project, error) {
+	page, args := pageClause("id", p, []any{tenantID})
+	return r.list(ctx, `
+		SELECT `+projectColumns+`
 		FROM projects
-		WHERE owner_id = $1 AND deleted_at IS NULL
-	`, ownerID)
+		WHERE tenant_id = $1 AND deleted_at IS NULL`+page, args...)
+}
 
+func (r *ProjectRepository) list(ctx context.Context, query string, args ...any) ([]*authz.Project, error) {
+	rows, err := r.db.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
 	defer rows.Close()
 
 	var projects []*authz.Project
-
 	for rows.Next() {
-		var project authz.Project
-		var deletedAt sql.NullTime
-
-		if err := rows.Scan(
-			&project.ID, &project.Name, &project.Description, &project.OwnerID,
-			&project.CreatedAt, &project.UpdatedAt, &deletedAt,
-		); err != nil {
+		project, err := scanProject(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan project: %w", err)
 		}
-
-		if deletedAt.Valid {
-			project.DeletedAt = &deletedAt.Time
-		}
-
-		projects = append(projects, &project)
+		projects = append(projects, project)
 	}
+	return projects, rows.Err()
+}
 
-	return projects, nil
+func scanProject(s pgx.Row) (*authz.Project, error) {
+	var project authz.Project
+	var tenantID, description sql.NullString
+	var deletedAt sql.NullTime
+	if err := s.Scan(
+		&project.ID, &tenantID, &project.Name, &description, &project.OwnerID,
+		&project.CreatedAt, &project.UpdatedAt, &deletedAt,
+	); err != nil {
+		return nil, err
+	}
+	project.TenantID = tenantID.String
+	project.Description = description.String
+	if deletedAt.Valid {
+		project.DeletedAt = &deletedAt.Time
+	}
+	return &project, nil
 }
 
-// ListByUser retrieves all projects a user has access to
-func (r *ProjectRepository) ListByUser(userID string) ([]*authz.Project, error) {
-	ctx := context.Background()
+// SetMember gives a user a role in a project, replacing the role they had
+func (r *ProjectRepository) SetMember(ctx context.Context, member *authz.ProjectMember) error {
+	var grantedBy sql.NullString
+	if member.GrantedBy != "" {
+		grantedBy = sql.NullString{String: member.GrantedBy, Valid: true}
+	}
 
-	rows, err := r.db.pool.Query(ctx, `
-		SELECT DISTINCT p.id, p.name, p.description, p.owner_id, p.created_at, p.updated_at, p.deleted_at
-		FROM projects p
-		INNER JOIN user_project_roles upr ON p.id = upr.project_id
-		WHERE upr.user_id = $1 AND p.deleted_at IS NULL
-	`, userID)
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO project_members (project_id, user_id, role, granted_at, granted_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (project_id, user_id) DO UPDATE SET
+			role = EXCLUDED.role, granted_at = EXCLUDED.granted_at, granted_by = EXCLUDED.granted_by
+	`, member.ProjectID, member.UserID, member.Role, member.GrantedAt, grantedBy)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to list user projects: %w", err)
+		return fmt.Errorf("failed to set project member: %w", err)
 	}
-	defer rows.Close()
+	return nil
+}
 
-	var projects []*authz.Project
+// RemoveMember removes a user from a project
+func (r *ProjectRepository) RemoveMember(ctx context.Context, projectID, userID string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM project_members WHERE project_id = $1 AND user_id = $2
+	`, projectID, userID)
 
-	for rows.Next() {
-		var project authz.Project
-		var deletedAt sql.NullTime
+	if err != nil {
+		return fmt.Errorf("failed to remove project member: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return authz.ErrProjectMemberNotFound
+	}
+	return nil
+}
 
-		if err := rows.Scan(
-			&project.ID, &project.Name, &project.Description, &project.OwnerID,
-			&project.CreatedAt, &project.UpdatedAt, &deletedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan project: %w", err)
-		}
+// GetMember retrieves a user's role in a project
+func (r *ProjectRepository) GetMember(ctx context.Context, projectID, userID string) (*authz.ProjectMember, error) {
+	member, err := scanProjectMember(r.db.pool.QueryRow(ctx, `
+		SELECT project_id, user_id, role, granted_at, granted_by
+		FROM project_members
+		WHERE project_id = $1 AND user_id = $2
+	`, projectID, userID))
 
-		if deletedAt.Valid {
-			project.DeletedAt = &deletedAt.Time
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, authz.ErrProjectMemberNotFound
 		}
+		return nil, fmt.Errorf("failed to get project member: %w", err)
+	}
+	return member, nil
+}
+
+// ListMembers retrieves the members of a project, oldest first
+func (r *ProjectRepository) ListMembers(ctx context.Context, projectID string) ([]*authz.ProjectMember, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT project_id, user_id, role, granted_at, granted_by
+		FROM project_members
+		WHERE project_id = $1
+		ORDER BY granted_at, user_id
+	`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project members: %w", err)
+	}
+	defer rows.Close()
 
-		projects = append(projects, &project)
+	var members []*authz.ProjectMember
+	for rows.Next() {
+		member, err := scanProjectMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project member: %w", err)
+		}
+		members = append(members, member)
 	}
+	return members, rows.Err()
+}
 
-	return projects, nil
+func scanProjectMember(s pgx.Row) (*authz.ProjectMember, error) {
+	var m authz.ProjectMember
+	var grantedBy sql.NullString
+	if err := s.Scan(&m.ProjectID, &m.UserID, &m.Role, &m.GrantedAt, &grantedBy); err != nil {
+		return nil, err
+	}
+	m.GrantedBy = grantedBy.String
+	return &m, nil
 }
 
 // RoleRepository implements authz.RoleRepository
@@ -246,9 +298,7 @@ func NewRoleRepository(db *DB) *RoleRepository {
 }
 
 // Create creates a new role
-func (r *RoleRepository) Create(role *authz.Role) error {
-	ctx := context.Background()
-
+func (r *RoleRepository) Create(ctx context.Context, role *authz.Role) error {
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO rbac_roles (
 			id, name, scope, description, created_at, updated_at
@@ -266,9 +316,7 @@ func (r *RoleRepository) Create(role *authz.Role) error {
 }
 
 // GetByID retrieves a role by ID
-func (r *RoleRepository) GetByID(id string) (*authz.Role, error) {
-	ctx := context.Background()
-
+func (r *RoleRepository) GetByID(ctx context.Context, id string) (*authz.Role, error) {
 	var role authz.Role
 	var scopeStr string
 
@@ -297,9 +345,7 @@ func (r *RoleRepository) GetByID(id string) (*authz.Role, error) {
 }
 
 // GetByName retrieves a role by name and scope
-func (r *RoleRepository) GetByName(name string, scope authz.Scope) (*authz.Role, error) {
-	ctx := context.Background()
-
+func (r *RoleRepository) GetByName(ctx context.Context, name string, scope authz.Scope) (*authz.Role, error) {
 	var role authz.Role
 	var scopeStr string
 
@@ -328,9 +374,7 @@ func (r *RoleRepository) GetByName(name string, scope authz.Scope) (*authz.Role,
 }
 
 // Update updates role information
-func (r *RoleRepository) Update(role *authz.Role) error {
-	ctx := context.Background()
-
+func (r *RoleRepository) Update(ctx context.Context, role *authz.Role) error {
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE rbac_roles SET
 			description = $2,
@@ -352,9 +396,7 @@ func (r *RoleRepository) Update(role *authz.Role) error {
 }
 
 // Delete deletes a role
-func (r *RoleRepository) Delete(id string) error {
-	ctx := context.Background()
-
+func (r *RoleRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.db.pool.Exec(ctx, `
 		DELETE FROM rbac_roles WHERE id = $1
 	`, id)
@@ -371,9 +413,7 @@ func (r *RoleRepository) Delete(id string) error {
 }
 
 // List retrieves all roles, optionally filtered by scope
-func (r *RoleRepository) List(scope *authz.Scope) ([]*authz.Role, error) {
-	ctx := context.Background()
-
+func (r *RoleRepository) List(ctx context.Context, scope *authz.Scope) ([]*authz.Role, error) {
 	query := `
 		SELECT r.id, r.name, r.scope, r.description, r.created_at, r.updated_at,
 		       COALESCE(array_agg(p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
@@ -425,9 +465,7 @@ func NewAssignmentRepository(db *DB) *AssignmentRepository {
 }
 
 // Grant assigns a role to a user
-func (r *AssignmentRepository) Grant(assignment *authz.Assignment) error {
-	ctx := context.Background()
-
+func (r *AssignmentRepository) Grant(ctx context.Context, assignment *authz.Assignment) error {
 	var grantedBy sql.NullString
 	if assignment.GrantedBy != "" {
 		grantedBy = sql.NullString{String: assignment.GrantedBy, Valid: true}
@@ -452,9 +490,7 @@ func (r *AssignmentRepository) Grant(assignment *authz.Assignment) error {
 }
 
 // Revoke removes a role assignment
-func (r *AssignmentRepository) Revoke(userID, roleID string, scope authz.Scope, scopeContextID *string) error {
-	ctx := context.Background()
-
+func (r *AssignmentRepository) Revoke(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
 	var query string
 	var args []interface{}
 
@@ -480,15 +516,52 @@ func (r *AssignmentRepository) Revoke(userID, roleID string, scope authz.Scope,
 	return nil
 }
 
-// ListForUser retrieves all assignments for a user
-func (r *AssignmentRepository) ListForUser(userID string) ([]*authz.Assignment, error) {
-	ctx := context.Background()
+// RevokeUnlessLast removes a role assignment unless it is the only one of the role at the scope
+func (r *AssignmentRepository) RevokeUnlessLast(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	return revokeUnlessLast(ctx, r.db, userID, roleID, string(scope), scopeContextID)
+}
+
+// revokeUnlessLast removes a role assignment in a transaction that first locks every assignment of
+// the role at the scope. A concurrent revocation waits for the lock and then no longer sees the
+// assignment removed before it, so the last holder is never revoked.
+func revokeUnlessLast(ctx context.Context, db *DB, userID, roleID, scope string, scopeContextID *string) error {
+	return pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT user_id FROM rbac_assignments
+			WHERE role_id = $1 AND scope = $2 AND scope_context_id IS NOT DISTINCT FROM $3
+			FOR UPDATE
+		`, roleID, scope, scopeContextID)
+		if err != nil {
+			return fmt.Errorf("failed to lock role assignments: %w", err)
+		}
+		holders, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("failed to lock role assignments: %w", err)
+		}
+		if !slices.Contains(holders, userID) {
+			return authz.ErrAssignmentNotFound
+		}
+		if len(holders) == 1 {
+			return authz.ErrLastAssignment
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM rbac_assignments
+			WHERE user_id = $1 AND role_id = $2 AND scope = $3 AND scope_context_id IS NOT DISTINCT FROM $4
+		`, userID, roleID, scope, scopeContextID); err != nil {
+			return fmt.Errorf("failed to revoke role: %w", err)
+		}
+		return nil
+	})
+}
 
+// ListForUser retrieves a page of assignments for a user, ordered by ID
+func (r *AssignmentRepository) ListForUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Assignment, error) {
+	page, args := pageClause("id", p, []any{userID})
 	rows, err := r.db.pool.Query(ctx, `
 		SELECT id, user_id, role_id, scope, scope_context_id, granted_at, COALESCE(granted_by::text, '')
 		FROM rbac_assignments
-		WHERE user_id = $1
-	`, userID)
+		WHERE user_id = $1`+page, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user assignments: %w", err)
@@ -518,9 +591,7 @@ func (r *AssignmentRepository) ListForUser(userID string) ([]*authz.Assignment,
 }
 
 // ListByRole retrieves all users assigned a specific role at a scope
-func (r *AssignmentRepository) ListByRole(roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
-	ctx := context.Background()
-
+func (r *AssignmentRepository) ListByRole(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
 	var query string
 	var args []interface{}
 
@@ -557,9 +628,7 @@ func (r *AssignmentRepository) ListByRole(roleID string, scope authz.Scope, scop
 }
 
 // CheckExists checks if a specific assignment exists
-func (r *AssignmentRepository) CheckExists(roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
-	ctx := context.Background()
-
+func (r *AssignmentRepository) CheckExists(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
 	var query string
 	var args []interface{}
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
 )
 
 // ClientRepository implements oauth2.ClientRepository
@@ -36,9 +37,7 @@ func NewClientRepository(db *DB) *ClientRepository {
 }
 
 // Create creates a new OAuth2 client
-func (r *ClientRepository) Create(client *oauth2.Client) error {
-	ctx := context.Background()
-
+func (r *ClientRepository) Create(ctx context.Context, client *oauth2.Client) error {
 	redirectURIs, err := json.Marshal(client.RedirectURIs)
 	if err != nil {
 		return fmt.Errorf("failed to marshal redirect URIs: %w", err)
@@ -59,6 +58,16 @@ func (r *ClientRepository) Create(client *oauth2.Client) error {
 		return fmt.Errorf("failed to marshal response types: %w", err)
 	}
 
+	requestURIs, err := json.Marshal(client.RequestURIs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request URIs: %w", err)
+	}
+
+	allowedOrigins, err := json.Marshal(client.AllowedOrigins)
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed origins: %w", err)
+	}
+
 	var ownerID sql.NullString
 	if client.OwnerID != "" {
 		ownerID = sql.NullString{String: client.OwnerID, Valid: true}
@@ -69,28 +78,29 @@ func (r *ClientRepository) Create(client *oauth2.Client) error {
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			owner_id, is_trusted, is_active, created_at, updated_at,
+			jwks, request_uris, require_signed_request_object, allowed_origins, client_group, application_type, min_state_length, jwks_uri
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28)
 	`,
 		client.ID, client.ClientID, client.TenantID, client.ClientSecretHash, client.ClientName, client.ClientURI, client.LogoURI,
 		redirectURIs, allowedScopes, grantTypes, responseTypes,
 		client.TokenEndpointAuthMethod, client.AccessTokenLifetime, client.RefreshTokenLifetime, client.IDTokenLifetime,
 		ownerID, client.IsTrusted, client.IsActive, client.CreatedAt, client.UpdatedAt,
+		clientJWKS(client), requestURIs, client.RequireSignedRequestObject, allowedOrigins, client.ClientGroup, client.ApplicationType, client.MinStateLength, client.JWKSURI,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
+	client.Version = 1
 
 	return nil
 }
 
 // GetByClientID retrieves a client by client_id
-func (r *ClientRepository) GetByClientID(clientID string) (*oauth2.Client, error) {
-	ctx := context.Background()
-
+func (r *ClientRepository) GetByClientID(ctx context.Context, clientID string) (*oauth2.Client, error) {
 	var client oauth2.Client
-	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, jwksJSON, requestURIsJSON, allowedOriginsJSON []byte
 	var clientURI, logoURI, ownerID sql.NullString
 	var deletedAt sql.NullTime
 
@@ -99,14 +109,16 @@ func (r *ClientRepository) GetByClientID(clientID string) (*oauth2.Client, error
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, version, deleted_at,
+			jwks, request_uris, require_signed_request_object, allowed_origins, client_group, application_type, min_state_length, jwks_uri
 		FROM oauth2_clients
 		WHERE client_id = $1 AND deleted_at IS NULL
 	`, clientID).Scan(
 		&client.ID, &client.ClientID, &client.TenantID, &client.ClientSecretHash, &client.ClientName, &clientURI, &logoURI,
 		&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
 		&client.TokenEndpointAuthMethod, &client.AccessTokenLifetime, &client.RefreshTokenLifetime, &client.IDTokenLifetime,
-		&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &deletedAt,
+		&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &client.Version, &deletedAt,
+		&jwksJSON, &requestURIsJSON, &client.RequireSignedRequestObject, &allowedOriginsJSON, &client.ClientGroup, &client.ApplicationType, &client.MinStateLength, &client.JWKSURI,
 	)
 
 	if err != nil {
@@ -129,6 +141,13 @@ func (r *ClientRepository) GetByClientID(clientID string) (*oauth2.Client, error
 	if err := json.Unmarshal(responseTypesJSON, &client.ResponseTypes); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response types: %w", err)
 	}
+	if err := json.Unmarshal(requestURIsJSON, &client.RequestURIs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request URIs: %w", err)
+	}
+	if err := json.Unmarshal(allowedOriginsJSON, &client.AllowedOrigins); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed origins: %w", err)
+	}
+	client.JWKS = jwksJSON
 
 	if clientURI.Valid {
 		client.ClientURI = clientURI.String
@@ -147,11 +166,9 @@ func (r *ClientRepository) GetByClientID(clientID string) (*oauth2.Client, error
 }
 
 // GetByID retrieves a client by internal ID
-func (r *ClientRepository) GetByID(id string) (*oauth2.Client, error) {
-	ctx := context.Background()
-
+func (r *ClientRepository) GetByID(ctx context.Context, id string) (*oauth2.Client, error) {
 	var client oauth2.Client
-	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, jwksJSON, requestURIsJSON, allowedOriginsJSON []byte
 	var ownerID sql.NullString
 	var deletedAt sql.NullTime
 
@@ -160,14 +177,16 @@ func (r *ClientRepository) GetByID(id string) (*oauth2.Client, error) {
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, version, deleted_at,
+			jwks, request_uris, require_signed_request_object, allowed_origins, client_group, application_type, min_state_length, jwks_uri
 		FROM oauth2_clients
 		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(
 		&client.ID, &client.ClientID, &client.TenantID, &client.ClientSecretHash, &client.ClientName, &client.ClientURI, &client.LogoURI,
 		&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
 		&client.TokenEndpointAuthMethod, &client.AccessTokenLifetime, &client.RefreshTokenLifetime, &client.IDTokenLifetime,
-		&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &deletedAt,
+		&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &client.Version, &deletedAt,
+		&jwksJSON, &requestURIsJSON, &client.RequireSignedRequestObject, &allowedOriginsJSON, &client.ClientGroup, &client.ApplicationType, &client.MinStateLength, &client.JWKSURI,
 	)
 
 	if err != nil {
@@ -190,6 +209,13 @@ func (r *ClientRepository) GetByID(id string) (*oauth2.Client, error) {
 	if err := json.Unmarshal(responseTypesJSON, &client.ResponseTypes); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response types: %w", err)
 	}
+	if err := json.Unmarshal(requestURIsJSON, &client.RequestURIs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request URIs: %w", err)
+	}
+	if err := json.Unmarshal(allowedOriginsJSON, &client.AllowedOrigins); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed origins: %w", err)
+	}
+	client.JWKS = jwksJSON
 
 	if ownerID.Valid {
 		client.OwnerID = ownerID.String
@@ -202,9 +228,7 @@ func (r *ClientRepository) GetByID(id string) (*oauth2.Client, error) {
 }
 
 // Update updates client information
-func (r *ClientRepository) Update(client *oauth2.Client) error {
-	ctx := context.Background()
-
+func (r *ClientRepository) Update(ctx context.Context, client *oauth2.Client) error {
 	redirectURIs, err := json.Marshal(client.RedirectURIs)
 	if err != nil {
 		return fmt.Errorf("failed to marshal redirect URIs: %w", err)
@@ -225,6 +249,16 @@ func (r *ClientRepository) Update(client *oauth2.Client) error {
 		return fmt.Errorf("failed to marshal response types: %w", err)
 	}
 
+	requestURIs, err := json.Marshal(client.RequestURIs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request URIs: %w", err)
+	}
+
+	allowedOrigins, err := json.Marshal(client.AllowedOrigins)
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed origins: %w", err)
+	}
+
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE oauth2_clients SET
 			client_name = $2,
@@ -239,13 +273,24 @@ func (r *ClientRepository) Update(client *oauth2.Client) error {
 			refresh_token_lifetime = $11,
 			id_token_lifetime = $12,
 			is_trusted = $13,
-			is_active = $14
-		WHERE id = $1 AND deleted_at IS NULL
+			is_active = $14,
+			jwks = $16,
+			request_uris = $17,
+			require_signed_request_object = $18,
+			allowed_origins = $19,
+			client_group = $20,
+			application_type = $21,
+			min_state_length = $22,
+			jwks_uri = $23,
+			updated_at = CURRENT_TIMESTAMP,
+			version = version + 1
+		WHERE id = $1 AND version = $15 AND deleted_at IS NULL
 	`,
 		client.ID, client.ClientName, client.ClientURI, client.LogoURI,
 		redirectURIs, allowedScopes, grantTypes, responseTypes,
 		client.TokenEndpointAuthMethod, client.AccessTokenLifetime, client.RefreshTokenLifetime, client.IDTokenLifetime,
-		client.IsTrusted, client.IsActive,
+		client.IsTrusted, client.IsActive, client.Version,
+		clientJWKS(client), requestURIs, client.RequireSignedRequestObject, allowedOrigins, client.ClientGroup, client.ApplicationType, client.MinStateLength, client.JWKSURI,
 	)
 
 	if err != nil {
@@ -253,16 +298,19 @@ func (r *ClientRepository) Update(client *oauth2.Client) error {
 	}
 
 	if result.RowsAffected() == 0 {
-		return oauth2.ErrClientNotFound
+		// Distinguish a stale version from a missing client
+		if _, err := r.GetByID(ctx, client.ID); err != nil {
+			return err
+		}
+		return oauth2.ErrClientVersionConflict
 	}
+	client.Version++
 
 	return nil
 }
 
 // Delete soft-deletes a client
-func (r *ClientRepository) Delete(id string) error {
-	ctx := context.Background()
-
+func (r *ClientRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE oauth2_clients SET deleted_at = $2
 		WHERE id = $1 AND deleted_at IS NULL
@@ -279,19 +327,18 @@ func (r *ClientRepository) Delete(id string) error {
 	return nil
 }
 
-// ListByOwner retrieves all clients for an owner
-func (r *ClientRepository) ListByOwner(ownerID string) ([]*oauth2.Client, error) {
-	ctx := context.Background()
-
+// ListByOwner retrieves a page of clients for an owner, ordered by ID
+func (r *ClientRepository) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*oauth2.Client, error) {
+	page, args := pageClause("id", p, []any{ownerID})
 	rows, err := r.db.pool.Query(ctx, `
 		SELECT 
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, version, deleted_at,
+			jwks, request_uris, require_signed_request_object, allowed_origins, client_group, application_type, min_state_length, jwks_uri
 		FROM oauth2_clients
-		WHERE owner_id = $1 AND deleted_at IS NULL
-	`, ownerID)
+		WHERE owner_id = $1 AND deleted_at IS NULL`+page, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to query clients: %w", err)
@@ -302,7 +349,7 @@ func (r *ClientRepository) ListByOwner(ownerID string) ([]*oauth2.Client, error)
 
 	for rows.Next() {
 		var client oauth2.Client
-		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, jwksJSON, requestURIsJSON, allowedOriginsJSON []byte
 		var ownerID sql.NullString
 		var deletedAt sql.NullTime
 
@@ -310,7 +357,8 @@ func (r *ClientRepository) ListByOwner(ownerID string) ([]*oauth2.Client, error)
 			&client.ID, &client.ClientID, &client.TenantID, &client.ClientSecretHash, &client.ClientName, &client.ClientURI, &client.LogoURI,
 			&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
 			&client.TokenEndpointAuthMethod, &client.AccessTokenLifetime, &client.RefreshTokenLifetime, &client.IDTokenLifetime,
-			&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &deletedAt,
+			&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &client.Version, &deletedAt,
+			&jwksJSON, &requestURIsJSON, &client.RequireSignedRequestObject, &allowedOriginsJSON, &client.ClientGroup, &client.ApplicationType, &client.MinStateLength, &client.JWKSURI,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan client: %w", err)
@@ -328,6 +376,13 @@ func (r *ClientRepository) ListByOwner(ownerID string) ([]*oauth2.Client, error)
 		if err := json.Unmarshal(responseTypesJSON, &client.ResponseTypes); err != nil {
 			continue
 		}
+		if err := json.Unmarshal(requestURIsJSON, &client.RequestURIs); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(allowedOriginsJSON, &client.AllowedOrigins); err != nil {
+			continue
+		}
+		client.JWKS = jwksJSON
 
 		if ownerID.Valid {
 			client.OwnerID = ownerID.String
@@ -346,20 +401,18 @@ func (r *ClientRepository) ListByOwner(ownerID string) ([]*oauth2.Client, error)
 	return clients, nil
 }
 
-// ListByTenant retrieves all clients for a tenant
-func (r *ClientRepository) ListByTenant(tenantID string) ([]*oauth2.Client, error) {
-	ctx := context.Background()
-
+// ListByTenant retrieves a page of clients for a tenant, ordered by ID
+func (r *ClientRepository) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*oauth2.Client, error) {
+	page, args := pageClause("id", p, []any{tenantID})
 	rows, err := r.db.pool.Query(ctx, `
 		SELECT 
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, version, deleted_at,
+			jwks, request_uris, require_signed_request_object, allowed_origins, client_group, application_type, min_state_length, jwks_uri
 		FROM oauth2_clients
-		WHERE tenant_id = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC
-	`, tenantID)
+		WHERE tenant_id = $1 AND deleted_at IS NULL`+page, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to query clients: %w", err)
@@ -370,7 +423,7 @@ func (r *ClientRepository) ListByTenant(tenantID string) ([]*oauth2.Client, erro
 
 	for rows.Next() {
 		var client oauth2.Client
-		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, jwksJSON, requestURIsJSON, allowedOriginsJSON []byte
 		var ownerID sql.NullString
 		var deletedAt sql.NullTime
 
@@ -378,7 +431,8 @@ func (r *ClientRepository) ListByTenant(tenantID string) ([]*oauth2.Client, erro
 			&client.ID, &client.ClientID, &client.TenantID, &client.ClientSecretHash, &client.ClientName, &client.ClientURI, &client.LogoURI,
 			&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
 			&client.TokenEndpointAuthMethod, &client.AccessTokenLifetime, &client.RefreshTokenLifetime, &client.IDTokenLifetime,
-			&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &deletedAt,
+			&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &client.Version, &deletedAt,
+			&jwksJSON, &requestURIsJSON, &client.RequireSignedRequestObject, &allowedOriginsJSON, &client.ClientGroup, &client.ApplicationType, &client.MinStateLength, &client.JWKSURI,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan client: %w", err)
@@ -396,6 +450,13 @@ func (r *ClientRepository) ListByTenant(tenantID string) ([]*oauth2.Client, erro
 		if err := json.Unmarshal(responseTypesJSON, &client.ResponseTypes); err != nil {
 			continue
 		}
+		if err := json.Unmarshal(requestURIsJSON, &client.RequestURIs); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(allowedOriginsJSON, &client.AllowedOrigins); err != nil {
+			continue
+		}
+		client.JWKS = jwksJSON
 
 		if ownerID.Valid {
 			client.OwnerID = ownerID.String
@@ -413,3 +474,28 @@ func (r *ClientRepository) ListByTenant(tenantID string) ([]*oauth2.Client, erro
 
 	return clients, nil
 }
+
+// PurgeDeleted permanently removes up to limit clients soft-deleted before the given time
+func (r *ClientRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM oauth2_clients WHERE id IN (
+			SELECT id FROM oauth2_clients
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			LIMIT $2
+		)
+	`, before, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted clients: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// clientJWKS returns the client's JWKS as stored, NULL when it has none
+func clientJWKS(client *oauth2.Client) []byte {
+	if len(client.JWKS) == 0 {
+		return nil
+	}
+	return client.JWKS
+}
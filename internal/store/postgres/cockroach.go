@@ -0,0 +1,121 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"strings"
+)
+
+// CompatCockroachDB adapts the driver to CockroachDB, which speaks the PostgreSQL wire protocol but
+// lacks triggers, row-level security, declarative partitioning and advisory locks
+const CompatCockroachDB = "cockroachdb"
+
+// Markers around the parts of a migration that only PostgreSQL can run, and before a statement
+// that only CockroachDB runs: PostgreSQL reads the line as a comment
+const (
+	postgresOnlyBegin = "-- postgres-only:begin"
+	postgresOnlyEnd   = "-- postgres-only:end"
+	cockroachOnly     = "-- cockroachdb-only: "
+)
+
+// Migrations returns the up migrations for the database's compatibility mode
+func (db *DB) Migrations() []string {
+	if !db.cockroach {
+		return Migrations()
+	}
+	var scripts []string
+	for _, script := range Migrations() {
+		scripts = append(scripts, stripPostgresOnly(script))
+	}
+	return scripts
+}
+
+// stripPostgresOnly removes the lines between postgres-only markers, markers included, and
+// uncomments the cockroachdb-only lines
+func stripPostgresOnly(script string) string {
+	var b strings.Builder
+	skip := false
+	for _, line := range strings.SplitAfter(script, "\n") {
+		switch strings.TrimSpace(line) {
+		case postgresOnlyBegin:
+			skip = true
+			continue
+		case postgresOnlyEnd:
+			skip = false
+			continue
+		}
+		if !skip {
+			b.WriteString(strings.TrimPrefix(line, cockroachOnly))
+		}
+	}
+	return b.String()
+}
+
+// splitStatements splits a script into its statements, dropping comments. CockroachDB runs a
+// multi-statement query as one transaction in which a new column cannot be written, so its
+// migrations are applied a statement at a time. Dollar-quoted bodies are kept whole.
+func splitStatements(script string) []string {
+	var statements []string
+	var b strings.Builder
+	inQuote, inDollar := false, false
+	flush := func() {
+		if s := strings.TrimSpace(b.String()); s != "" {
+			statements = append(statements, s)
+		}
+		b.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case inQuote:
+			inQuote = c != '\''
+		case inDollar:
+			if strings.HasPrefix(script[i:], "$$") {
+				inDollar = false
+				b.WriteByte(c)
+				i++
+				c = script[i]
+			}
+		case c == '\'':
+			inQuote = true
+		case strings.HasPrefix(script[i:], "$$"):
+			inDollar = true
+			b.WriteByte(c)
+			i++
+			c = script[i]
+		case strings.HasPrefix(script[i:], "--"):
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			c = '\n'
+		case c == ';':
+			flush()
+			continue
+		}
+		b.WriteByte(c)
+	}
+	flush()
+	return statements
+}
+
+// historical returns table for a FROM clause that may read slightly stale data. On CockroachDB the
+// read is served at the follower read timestamp, a few seconds in the past, so heavy scans do not
+// contend with writes and can be answered by the nearest replica.
+func (db *DB) historical(table string) string {
+	if db.cockroach {
+		return table + " AS OF SYSTEM TIME follower_read_timestamp()"
+	}
+	return table
+}
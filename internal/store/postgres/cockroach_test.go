@@ -0,0 +1,213 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// TestPurpose: Validates that the CockroachDB migrations leave out the PostgreSQL-only features and split into single statements.
+// Scope: Unit Test
+// Security: Availability (DB_COMPAT=cockroachdb must migrate a fresh database without unsupported statements)
+// Expected: No trigger, policy, partition or dollar-quoted body survives; quoted semicolons and the seed data are kept; PostgreSQL keeps every script whole.
+// Test Case ID: STO-14
+func TestCockroachMigrations(t *testing.T) {
+	postgresDB := &DB{}
+	for i, script := range postgresDB.Migrations() {
+		if script != Migrations()[i] {
+			t.Fatalf("migration %d changed without DB_COMPAT", i+1)
+		}
+	}
+
+	cockroachDB := &DB{cockroach: true}
+	var statements []string
+	for _, script := range cockroachDB.Migrations() {
+		if strings.Contains(script, "postgres-only") {
+			t.Fatal("marker left in a CockroachDB migration")
+		}
+		statements = append(statements, splitStatements(script)...)
+	}
+	for _, statement := range statements {
+		for _, unsupported := range []string{"TRIGGER", "POLICY", "ROW LEVEL SECURITY", "PARTITION", "$$", "pg_"} {
+			if strings.Contains(statement, unsupported) {
+				t.Errorf("statement uses %s: %s", unsupported, statement)
+			}
+		}
+		if strings.HasPrefix(statement, "--") {
+			t.Errorf("comment left in statement: %s", statement)
+		}
+	}
+	if !strings.Contains(strings.Join(statements, "\n"), "INSERT INTO rbac_permissions") {
+		t.Error("seed data missing from the CockroachDB migrations")
+	}
+
+	got := splitStatements("-- header; ignored\nINSERT INTO t VALUES ('a;b', 'it''s');\n\nSELECT 1")
+	if len(got) != 2 || got[0] != "INSERT INTO t VALUES ('a;b', 'it''s')" || got[1] != "SELECT 1" {
+		t.Errorf("unexpected statements %q", got)
+	}
+}
+
+// TestPurpose: Validates that statements aborted by a serialization failure are retried and other errors are not.
+// Scope: Unit Test
+// Security: Availability (CockroachDB contention must not fail requests), Integrity (constraint violations are never retried)
+// Expected: A 40001 error is retried until the statement succeeds or the attempts run out; a unique violation fails at once.
+// Test Case ID: STO-15
+func TestRetry_SerializationFailure(t *testing.T) {
+	ctx := context.Background()
+	serialization := &pgconn.PgError{Code: "40001"}
+	var policy retryPolicy
+
+	calls := 0
+	err := policy.retry(ctx, false, func() error {
+		calls++
+		if calls < 3 {
+			return serialization
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected success on the third attempt, got %v after %d", err, calls)
+	}
+
+	calls = 0
+	err = policy.retry(ctx, false, func() error {
+		calls++
+		return serialization
+	})
+	if !errors.Is(err, serialization) || calls != maxRetries+1 {
+		t.Errorf("expected the serialization failure after %d attempts, got %v after %d", maxRetries+1, err, calls)
+	}
+
+	calls = 0
+	err = policy.retry(ctx, false, func() error {
+		calls++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a unique violation to fail at once, got %v after %d", err, calls)
+	}
+}
+
+// TestPurpose: Validates that only transient errors are retried and that writes are not repeated after their connection broke.
+// Scope: Unit Test
+// Security: Availability (transient failures must not surface as 500s), Integrity (a write that may have committed is never run twice)
+// Expected: Deadlocks and errors raised before the statement was sent are retried for any statement, broken connections only for SELECTs, context errors never; retried statements are counted as recovered or failed.
+// Test Case ID: STO-31
+func TestRetry_TransientErrors(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := newRetryPolicy(meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broken := &pgconn.PgError{Code: "08006"}
+	for _, tc := range []struct {
+		err        error
+		idempotent bool
+		want       string
+	}{
+		{&pgconn.PgError{Code: "40P01"}, false, retryDeadlock},
+		{&pgconn.ConnectError{}, false, retryConnection},
+		{broken, true, retryConnection},
+		{broken, false, ""},
+		{io.ErrUnexpectedEOF, true, retryConnection},
+		{io.ErrUnexpectedEOF, false, ""},
+		{context.DeadlineExceeded, true, ""},
+		{ErrPoolExhausted, true, ""},
+	} {
+		if got := transient(tc.err, tc.idempotent); got != tc.want {
+			t.Errorf("transient(%v, idempotent %v) = %q, want %q", tc.err, tc.idempotent, got, tc.want)
+		}
+	}
+
+	for sql, want := range map[string]bool{
+		"\n\t\tSELECT id FROM users": true,
+		"select\n1":                  true,
+		"UPDATE users SET name = $1": false,
+		"SELECTED":                   false,
+	} {
+		if idempotent(sql) != want {
+			t.Errorf("idempotent(%q) = %v, want %v", sql, !want, want)
+		}
+	}
+
+	ctx := context.Background()
+	calls := 0
+	if err := policy.retry(ctx, true, func() error {
+		calls++
+		if calls == 1 {
+			return broken
+		}
+		return nil
+	}); err != nil || calls != 2 {
+		t.Errorf("expected the read to succeed on the second attempt, got %v after %d", err, calls)
+	}
+	calls = 0
+	if err := policy.retry(ctx, false, func() error {
+		calls++
+		if calls == 1 {
+			return &pgconn.PgError{Code: "40P01"}
+		}
+		return broken
+	}); !errors.Is(err, broken) || calls != 2 {
+		t.Errorf("expected the write to stop at the broken connection, got %v after %d", err, calls)
+	}
+	policy.retry(ctx, false, func() error { return nil })
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[attribute.Distinct]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if data, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == "db.client.retried_operations" {
+				for _, dp := range data.DataPoints {
+					counts[dp.Attributes.Equivalent()] = dp.Value
+				}
+			}
+		}
+	}
+	for _, want := range []attribute.Set{
+		attribute.NewSet(attribute.String("reason", retryConnection), attribute.String("outcome", "recovered")),
+		attribute.NewSet(attribute.String("reason", retryDeadlock), attribute.String("outcome", "failed")),
+	} {
+		if counts[want.Equivalent()] != 1 {
+			t.Errorf("expected one operation %v, got %v", want.ToSlice(), counts)
+		}
+	}
+	if len(counts) != 2 {
+		t.Errorf("expected statements that were not retried to go uncounted, got %v", counts)
+	}
+}
@@ -35,9 +35,7 @@ func NewAuthorizationCodeRepository(db *DB) *AuthorizationCodeRepository {
 }
 
 // Create creates a new authorization code
-func (r *AuthorizationCodeRepository) Create(code *oauth2.AuthorizationCode) error {
-	ctx := context.Background()
-
+func (r *AuthorizationCodeRepository) Create(ctx context.Context, code *oauth2.AuthorizationCode) error {
 	var usedAt sql.NullTime
 	if code.UsedAt != nil {
 		usedAt = sql.NullTime{Time: *code.UsedAt, Valid: true}
@@ -45,15 +43,15 @@ func (r *AuthorizationCodeRepository) Create(code *oauth2.AuthorizationCode) err
 
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO authorization_codes (
-			id, code, client_id, user_id, 
+			id, tenant_id, code, client_id, user_id,
 			redirect_uri, scope, state, nonce,
-			code_challenge, code_challenge_method,
+			code_challenge, code_challenge_method, session_id,
 			expires_at, used_at, is_used, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`,
-		code.ID, code.Code, code.ClientID, code.UserID,
+		code.ID, code.TenantID, code.Code, code.ClientID, code.UserID,
 		code.RedirectURI, code.Scope, code.State, code.Nonce,
-		code.CodeChallenge, code.CodeChallengeMethod,
+		code.CodeChallenge, code.CodeChallengeMethod, code.SessionID,
 		code.ExpiresAt, usedAt, code.IsUsed, code.CreatedAt,
 	)
 
@@ -64,25 +62,23 @@ func (r *AuthorizationCodeRepository) Create(code *oauth2.AuthorizationCode) err
 	return nil
 }
 
-// GetByCode retrieves an authorization code
-func (r *AuthorizationCodeRepository) GetByCode(codeStr string) (*oauth2.AuthorizationCode, error) {
-	ctx := context.Background()
-
+// GetByCode retrieves an authorization code issued within a tenant
+func (r *AuthorizationCodeRepository) GetByCode(ctx context.Context, tenantID, codeStr string) (*oauth2.AuthorizationCode, error) {
 	var code oauth2.AuthorizationCode
 	var usedAt sql.NullTime
 
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT 
-			id, code, client_id, user_id, 
+		SELECT
+			id, tenant_id, code, client_id, user_id,
 			redirect_uri, scope, state, nonce,
-			code_challenge, code_challenge_method,
+			code_challenge, code_challenge_method, session_id,
 			expires_at, used_at, is_used, created_at
 		FROM authorization_codes
-		WHERE code = $1
-	`, codeStr).Scan(
-		&code.ID, &code.Code, &code.ClientID, &code.UserID,
+		WHERE tenant_id = $1 AND code = $2
+	`, tenantID, codeStr).Scan(
+		&code.ID, &code.TenantID, &code.Code, &code.ClientID, &code.UserID,
 		&code.RedirectURI, &code.Scope, &code.State, &code.Nonce,
-		&code.CodeChallenge, &code.CodeChallengeMethod,
+		&code.CodeChallenge, &code.CodeChallengeMethod, &code.SessionID,
 		&code.ExpiresAt, &usedAt, &code.IsUsed, &code.CreatedAt,
 	)
 
@@ -101,13 +97,11 @@ func (r *AuthorizationCodeRepository) GetByCode(codeStr string) (*oauth2.Authori
 }
 
 // MarkAsUsed marks the code as used
-func (r *AuthorizationCodeRepository) MarkAsUsed(code string) error {
-	ctx := context.Background()
-
+func (r *AuthorizationCodeRepository) MarkAsUsed(ctx context.Context, tenantID, code string) error {
 	result, err := r.db.pool.Exec(ctx, `
-		UPDATE authorization_codes SET is_used = true, used_at = $2
-		WHERE code = $1
-	`, code, time.Now())
+		UPDATE authorization_codes SET is_used = true, used_at = $3
+		WHERE tenant_id = $1 AND code = $2
+	`, tenantID, code, time.Now())
 
 	if err != nil {
 		return fmt.Errorf("failed to mark code as used: %w", err)
@@ -121,12 +115,10 @@ func (r *AuthorizationCodeRepository) MarkAsUsed(code string) error {
 }
 
 // Delete deletes an authorization code
-func (r *AuthorizationCodeRepository) Delete(code string) error {
-	ctx := context.Background()
-
+func (r *AuthorizationCodeRepository) Delete(ctx context.Context, tenantID, code string) error {
 	_, err := r.db.pool.Exec(ctx, `
-		DELETE FROM authorization_codes WHERE code = $1
-	`, code)
+		DELETE FROM authorization_codes WHERE tenant_id = $1 AND code = $2
+	`, tenantID, code)
 
 	if err != nil {
 		return fmt.Errorf("failed to delete code: %w", err)
@@ -135,17 +127,17 @@ func (r *AuthorizationCodeRepository) Delete(code string) error {
 	return nil
 }
 
-// DeleteExpired deletes all expired authorization codes
-func (r *AuthorizationCodeRepository) DeleteExpired() error {
-	ctx := context.Background()
-
-	_, err := r.db.pool.Exec(ctx, `
-		DELETE FROM authorization_codes WHERE expires_at < $1
-	`, time.Now())
+// DeleteExpired deletes up to limit expired authorization codes and returns how many were removed
+func (r *AuthorizationCodeRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM authorization_codes WHERE id IN (
+			SELECT id FROM authorization_codes WHERE expires_at < $1 LIMIT $2
+		)
+	`, time.Now(), limit)
 
 	if err != nil {
-		return fmt.Errorf("failed to delete expired codes: %w", err)
+		return 0, fmt.Errorf("failed to delete expired codes: %w", err)
 	}
 
-	return nil
+	return result.RowsAffected(), nil
 }
@@ -18,16 +18,177 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/tenant"
 )
 
 //go:embed migrations/001_initial_schema.up.sql
 var InitialSchema string
 
+//go:embed migrations/002_tenant_email_settings.up.sql
+var TenantEmailSettingsSchema string
+
+//go:embed migrations/003_tenant_listing_indexes.up.sql
+var TenantListingIndexes string
+
+//go:embed migrations/004_list_pagination_indexes.up.sql
+var ListPaginationIndexes string
+
+//go:embed migrations/005_audit_events.up.sql
+var AuditEventsSchema string
+
+//go:embed migrations/006_audit_retention.up.sql
+var AuditRetentionSchema string
+
+//go:embed migrations/007_audit_schema_version.up.sql
+var AuditSchemaVersion string
+
+//go:embed migrations/008_audit_request_id.up.sql
+var AuditRequestID string
+
+//go:embed migrations/009_webhooks.up.sql
+var WebhooksSchema string
+
+//go:embed migrations/010_password_expiry.up.sql
+var PasswordExpirySchema string
+
+//go:embed migrations/011_signing_key_lifecycle.up.sql
+var SigningKeyLifecycleSchema string
+
+//go:embed migrations/012_signing_key_envelope.up.sql
+var SigningKeyEnvelopeSchema string
+
+//go:embed migrations/013_tenant_scoped_codes.up.sql
+var TenantScopedCodesSchema string
+
+//go:embed migrations/014_tenant_row_security.up.sql
+var TenantRowSecuritySchema string
+
+//go:embed migrations/015_record_versions.up.sql
+var RecordVersionsSchema string
+
+//go:embed migrations/016_token_partitions.up.sql
+var TokenPartitionsSchema string
+
+//go:embed migrations/017_oauth2_scopes.up.sql
+var OAuth2ScopesSchema string
+
+//go:embed migrations/018_request_objects.up.sql
+var RequestObjectsSchema string
+
+//go:embed migrations/019_access_token_claims.up.sql
+var AccessTokenClaimsSchema string
+
+//go:embed migrations/020_code_sessions.up.sql
+var CodeSessionsSchema string
+
+//go:embed migrations/021_replay_markers.up.sql
+var ReplayMarkersSchema string
+
+//go:embed migrations/022_session_namespace.up.sql
+var SessionNamespaceSchema string
+
+//go:embed migrations/023_pending_authorizations.up.sql
+var PendingAuthorizationsSchema string
+
+//go:embed migrations/024_client_origins.up.sql
+var ClientOriginsSchema string
+
+//go:embed migrations/025_client_groups.up.sql
+var ClientGroupsSchema string
+
+//go:embed migrations/026_client_application_types.up.sql
+var ClientApplicationTypesSchema string
+
+//go:embed migrations/027_protocol_log_settings.up.sql
+var ProtocolLogSettingsSchema string
+
+//go:embed migrations/028_client_state_policy.up.sql
+var ClientStatePolicySchema string
+
+//go:embed migrations/029_client_jwks_uri.up.sql
+var ClientJWKSURISchema string
+
+//go:embed migrations/030_tenant_memberships.up.sql
+var TenantMembershipsSchema string
+
+//go:embed migrations/031_project_members.up.sql
+var ProjectMembersSchema string
+
+//go:embed migrations/032_tenant_security_policies.up.sql
+var TenantSecurityPoliciesSchema string
+
+//go:embed migrations/033_email_changes.up.sql
+var EmailChangesSchema string
+
+//go:embed migrations/034_password_change.up.sql
+var PasswordChangeSchema string
+
+//go:embed migrations/035_account_deletions.up.sql
+var AccountDeletionsSchema string
+
+//go:embed migrations/036_profile_claims.up.sql
+var ProfileClaimsSchema string
+
+//go:embed migrations/037_token_client_index.up.sql
+var TokenClientIndexSchema string
+
+//go:embed migrations/038_live_user_email.up.sql
+var LiveUserEmailSchema string
+
+// Migrations returns all up migrations in the order they must be applied
+func Migrations() []string {
+	return []string{
+		InitialSchema,
+		TenantEmailSettingsSchema,
+		TenantListingIndexes,
+		ListPaginationIndexes,
+		AuditEventsSchema,
+		AuditRetentionSchema,
+		AuditSchemaVersion,
+		AuditRequestID,
+		WebhooksSchema,
+		PasswordExpirySchema,
+		SigningKeyLifecycleSchema,
+		SigningKeyEnvelopeSchema,
+		TenantScopedCodesSchema,
+		TenantRowSecuritySchema,
+		RecordVersionsSchema,
+		TokenPartitionsSchema,
+		OAuth2ScopesSchema,
+		RequestObjectsSchema,
+		AccessTokenClaimsSchema,
+		CodeSessionsSchema,
+		ReplayMarkersSchema,
+		SessionNamespaceSchema,
+		PendingAuthorizationsSchema,
+		ClientOriginsSchema,
+		ClientGroupsSchema,
+		ClientApplicationTypesSchema,
+		ProtocolLogSettingsSchema,
+		ClientStatePolicySchema,
+		ClientJWKSURISchema,
+		TenantMembershipsSchema,
+		ProjectMembersSchema,
+		TenantSecurityPoliciesSchema,
+		EmailChangesSchema,
+		PasswordChangeSchema,
+		AccountDeletionsSchema,
+		ProfileClaimsSchema,
+		TokenClientIndexSchema,
+		LiveUserEmailSchema,
+	}
+}
+
 // DB wraps the PostgreSQL connection pool
 type DB struct {
-	pool *pgxpool.Pool
+	pool      retryPool
+	cockroach bool
 }
 
 // Config holds database configuration
@@ -40,16 +201,32 @@ type Config struct {
 	SSLMode      string
 	MaxOpenConns int
 	MaxIdleConns int
+	// ConnMaxLifetime closes connections older than this, so rotated credentials reach the whole
+	// pool; zero keeps pgx's default of an hour
+	ConnMaxLifetime time.Duration
+	// AcquireTimeout bounds how long a statement waits for a free connection before failing with
+	// ErrPoolExhausted and opening the circuit breaker for BreakerCooldown; zero waits as long as
+	// the request allows
+	AcquireTimeout  time.Duration
+	BreakerCooldown time.Duration
+	// PasswordFunc, when set, supplies the password of each new connection instead of Password, for
+	// IAM authentication tokens and credentials rotated while the pool lives
+	PasswordFunc func(ctx context.Context) (string, error)
+	// Compat is empty for PostgreSQL or CompatCockroachDB
+	Compat string
+	// Meter records query counts and latency per repository method; nil records none
+	Meter *metrics.Meter
+	// SlowQueryThreshold logs queries that take at least this long; zero disables the log
+	SlowQueryThreshold time.Duration
 }
 
 // New creates a new database connection
 func New(ctx context.Context, cfg Config) (*DB, error) {
 	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s pool_max_conns=%d pool_min_conns=%d",
+		"host=%s port=%s user=%s dbname=%s sslmode=%s pool_max_conns=%d pool_min_conns=%d",
 		cfg.Host,
 		cfg.Port,
 		cfg.User,
-		cfg.Password,
 		cfg.Database,
 		cfg.SSLMode,
 		cfg.MaxOpenConns,
@@ -60,6 +237,40 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
+	if cfg.Password != "" {
+		poolConfig.ConnConfig.Password = cfg.Password
+	}
+	if cfg.PasswordFunc != nil {
+		poolConfig.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+			password, err := cfg.PasswordFunc(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to obtain database password: %w", err)
+			}
+			cc.Password = password
+			return nil
+		}
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	}
+	tracer, err := newQueryTracer(cfg.Meter, cfg.SlowQueryThreshold)
+	if err != nil {
+		return nil, err
+	}
+	breaker, err := newPoolBreaker(cfg.Meter, cfg.AcquireTimeout, cfg.BreakerCooldown)
+	if err != nil {
+		return nil, err
+	}
+	retries, err := newRetryPolicy(cfg.Meter)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.ConnConfig.Tracer = connTracer{tracer, breaker}
+	cockroach := cfg.Compat == CompatCockroachDB
+	if !cockroach {
+		// CockroachDB has no row-level security to scope; tenants are isolated by the queries alone
+		poolConfig.BeforeAcquire = scopeConn
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -68,10 +279,21 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 
 	// Verify connection
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	if err := breaker.observe(cfg.Meter, pool); err != nil {
+		pool.Close()
+		return nil, err
+	}
 
-	return &DB{pool: pool}, nil
+	return &DB{pool: retryPool{Pool: pool, breaker: breaker, retries: retries}, cockroach: cockroach}, nil
+}
+
+// Saturated reports whether the connection pool is exhausted: an acquire timed out recently and no
+// connection is free, so statements fail fast with ErrPoolExhausted
+func (db *DB) Saturated() bool {
+	return db.pool.breaker.Saturated()
 }
 
 // Close closes the database connection
@@ -81,11 +303,34 @@ func (db *DB) Close() {
 
 // Pool returns the underlying connection pool
 func (db *DB) Pool() *pgxpool.Pool {
-	return db.pool
+	return db.pool.Pool
 }
 
-// Migrate runs a SQL script
+// Migrate runs a SQL script. Migrations are platform-wide work: their data changes span every
+// tenant's rows.
 func (db *DB) Migrate(ctx context.Context, script string) error {
-	_, err := db.pool.Exec(ctx, script)
-	return err
+	ctx = tenant.WithoutScope(ctx)
+	if !db.cockroach {
+		_, err := db.pool.Exec(ctx, script)
+		return err
+	}
+	for _, statement := range splitStatements(script) {
+		if _, err := db.pool.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pageClause returns the keyset condition, ordering and limit for a list ordered by column.
+// It must follow a WHERE clause; args holds the query's existing arguments.
+func pageClause(column string, p pagination.Params, args []any) (string, []any) {
+	p = p.Normalize()
+	clause := ""
+	if p.After != "" {
+		args = append(args, p.After)
+		clause = fmt.Sprintf(" AND %s > $%d", column, len(args))
+	}
+	args = append(args, p.Limit)
+	return clause + fmt.Sprintf(" ORDER BY %s LIMIT $%d", column, len(args)), args
 }
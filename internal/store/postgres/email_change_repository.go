@@ -0,0 +1,154 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// EmailChangeRepository implements identity.EmailChangeRepository
+type EmailChangeRepository struct {
+	db *DB
+}
+
+// NewEmailChangeRepository creates a new email change repository
+func NewEmailChangeRepository(db *DB) *EmailChangeRepository {
+	return &EmailChangeRepository{db: db}
+}
+
+// Create stores an email change, replacing any earlier change of the user
+func (r *EmailChangeRepository) Create(ctx context.Context, change *identity.EmailChange) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO email_changes (id, user_id, old_email, new_email, status, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			id = EXCLUDED.id,
+			old_email = EXCLUDED.old_email,
+			new_email = EXCLUDED.new_email,
+			status = EXCLUDED.status,
+			token_hash = EXCLUDED.token_hash,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+	`, change.ID, change.UserID, change.OldEmail, change.NewEmail, change.Status, change.TokenHash, change.ExpiresAt, change.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create email change: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUser retrieves the email change of a user
+func (r *EmailChangeRepository) GetByUser(ctx context.Context, userID string) (*identity.EmailChange, error) {
+	return r.get(ctx, "user_id", userID)
+}
+
+// GetByTokenHash retrieves the email change whose current token has the given hash
+func (r *EmailChangeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*identity.EmailChange, error) {
+	return r.get(ctx, "token_hash", tokenHash)
+}
+
+func (r *EmailChangeRepository) get(ctx context.Context, column, value string) (*identity.EmailChange, error) {
+	var c identity.EmailChange
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT id, user_id, old_email, new_email, status, token_hash, expires_at, created_at
+		FROM email_changes WHERE `+column+` = $1
+	`, value).Scan(&c.ID, &c.UserID, &c.OldEmail, &c.NewEmail, &c.Status, &c.TokenHash, &c.ExpiresAt, &c.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, identity.ErrEmailChangeNotFound
+		}
+		return nil, fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	return &c, nil
+}
+
+// Update replaces the status, token hash and expiry of an email change
+func (r *EmailChangeRepository) Update(ctx context.Context, change *identity.EmailChange) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE email_changes SET status = $2, token_hash = $3, expires_at = $4 WHERE id = $1
+	`, change.ID, change.Status, change.TokenHash, change.ExpiresAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update email change: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return identity.ErrEmailChangeNotFound
+	}
+
+	return nil
+}
+
+// Delete removes an email change
+func (r *EmailChangeRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.pool.Exec(ctx, `DELETE FROM email_changes WHERE id = $1`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete email change: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return identity.ErrEmailChangeNotFound
+	}
+
+	return nil
+}
+
+// ListDue lists up to limit verified email changes whose cooling-off window ended before the given time
+func (r *EmailChangeRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*identity.EmailChange, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, user_id, old_email, new_email, status, token_hash, expires_at, created_at
+		FROM email_changes WHERE status = $1 AND expires_at < $2
+		ORDER BY expires_at LIMIT $3
+	`, identity.EmailChangeVerified, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due email changes: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*identity.EmailChange
+	for rows.Next() {
+		var c identity.EmailChange
+		if err := rows.Scan(&c.ID, &c.UserID, &c.OldEmail, &c.NewEmail, &c.Status, &c.TokenHash, &c.ExpiresAt, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan email change: %w", err)
+		}
+		due = append(due, &c)
+	}
+
+	return due, rows.Err()
+}
+
+// DeleteExpired deletes up to limit pending email changes whose link expired and returns how many were removed
+func (r *EmailChangeRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM email_changes WHERE id IN (
+			SELECT id FROM email_changes WHERE status = $1 AND expires_at < $2 LIMIT $3
+		)
+	`, identity.EmailChangePending, time.Now(), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired email changes: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
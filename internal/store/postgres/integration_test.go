@@ -19,10 +19,15 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 
+	"github.com/opentrusty/opentrusty/internal/id"
 	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/tenant"
 )
 
 // TestPurpose: Validates that the database repository maintains strict tenant isolation, preventing cross-tenant data leakage during user retrieval by email.
@@ -41,7 +46,7 @@ func TestUserRepository_TenantIsolation(t *testing.T) {
 		dbURL = "host=localhost port=5432 user=opentrusty password=opentrusty_dev_password dbname=opentrusty sslmode=disable"
 	}
 
-	ctx := context.Background()
+	ctx := tenant.WithoutScope(context.Background())
 	cfg := Config{
 		Host:         "localhost",
 		Port:         "5432",
@@ -67,44 +72,44 @@ func TestUserRepository_TenantIsolation(t *testing.T) {
 
 	userA := &identity.User{
 		ID:       "user-a",
-		TenantID: tenantA,
+		TenantID: &tenantA,
 		Email:    email,
 	}
 
 	userB := &identity.User{
 		ID:       "user-b",
-		TenantID: tenantB,
+		TenantID: &tenantB,
 		Email:    email,
 	}
 
 	// 1. Create User A in Tenant A
-	err = repo.Create(userA)
+	err = repo.Create(ctx, userA)
 	if err != nil {
 		t.Fatalf("failed to create user A: %v", err)
 	}
 	defer repo.db.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", userA.ID)
 
 	// 2. Create User B in Tenant B
-	err = repo.Create(userB)
+	err = repo.Create(ctx, userB)
 	if err != nil {
 		t.Fatalf("failed to create user B: %v", err)
 	}
 	defer repo.db.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", userB.ID)
 
 	// 3. Try to get User B using Tenant A context -> Should fail
-	_, err = repo.GetByEmail(tenantA, email)
+	_, err = repo.GetByEmail(ctx, &tenantA, email)
 	if err != nil && err != identity.ErrUserNotFound {
 		t.Errorf("expected ErrUserNotFound or found user A, got error: %v", err)
 	}
 
 	// Verify it's actually User A if found
-	foundA, _ := repo.GetByEmail(tenantA, email)
+	foundA, _ := repo.GetByEmail(ctx, &tenantA, email)
 	if foundA != nil && foundA.ID != userA.ID {
 		t.Errorf("cross-tenant leakage! expected user A, got %s", foundA.ID)
 	}
 
 	// 4. Get User B using Tenant B context -> Should succeed
-	foundB, err := repo.GetByEmail(tenantB, email)
+	foundB, err := repo.GetByEmail(ctx, &tenantB, email)
 	if err != nil {
 		t.Errorf("failed to get user B in tenant B: %v", err)
 	}
@@ -112,3 +117,213 @@ func TestUserRepository_TenantIsolation(t *testing.T) {
 		t.Errorf("expected user B, got %v", foundB)
 	}
 }
+
+// TestPurpose: Validates that PostgreSQL row-level security hides other tenants' rows even when a query omits its tenant filter.
+// Scope: Database Integration Test
+// Security: Multi-tenant Data Separation (CWE-284), Defense in Depth
+// Expected: Under a tenant scope, lookups by ID only see that tenant's rows and writes into another tenant are rejected; platform-wide access sees all rows and access without a scope sees none.
+// Test Case ID: ISO-02
+// Metadata:
+//   - Category: Tenant
+//   - Priority: High
+//   - Tags: multi-tenancy, security, row-level-security
+func TestRowLevelSecurity_TenantScope(t *testing.T) {
+	unscoped := context.Background()
+	ctx := tenant.WithoutScope(unscoped)
+	cfg := Config{
+		Host:         "localhost",
+		Port:         "5432",
+		User:         "opentrusty",
+		Password:     "opentrusty_dev_password",
+		Database:     "opentrusty",
+		SSLMode:      "disable",
+		MaxOpenConns: 5,
+		MaxIdleConns: 5,
+	}
+
+	db, err := New(ctx, cfg)
+	if err != nil {
+		t.Skipf("Skipping integration test: failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	for _, script := range Migrations() {
+		if err := db.Migrate(ctx, script); err != nil {
+			t.Fatalf("failed to apply migrations: %v", err)
+		}
+	}
+
+	// Policies never apply to superusers or BYPASSRLS roles.
+	var bypass bool
+	if err := db.pool.QueryRow(ctx,
+		"SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass); err != nil {
+		t.Fatalf("failed to inspect role: %v", err)
+	}
+	if bypass {
+		t.Skip("Skipping integration test: database role bypasses row-level security")
+	}
+
+	tenants := NewTenantRepository(db)
+	users := NewUserRepository(db)
+
+	tenantA := &tenant.Tenant{ID: id.NewUUIDv7(), Name: "rls-a-" + id.NewUUIDv7(), Status: tenant.StatusActive}
+	tenantB := &tenant.Tenant{ID: id.NewUUIDv7(), Name: "rls-b-" + id.NewUUIDv7(), Status: tenant.StatusActive}
+	for _, tn := range []*tenant.Tenant{tenantA, tenantB} {
+		if err := tenants.Create(ctx, tn); err != nil {
+			t.Fatalf("failed to create tenant: %v", err)
+		}
+		defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tn.ID)
+	}
+
+	userA := &identity.User{ID: id.NewUUIDv7(), TenantID: &tenantA.ID, Email: "rls-a@example.com"}
+	userB := &identity.User{ID: id.NewUUIDv7(), TenantID: &tenantB.ID, Email: "rls-b@example.com"}
+	for _, u := range []*identity.User{userA, userB} {
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		defer db.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", u.ID)
+	}
+
+	scopedA := tenant.WithScope(ctx, tenantA.ID)
+
+	// GetByID does not filter by tenant; the policy must.
+	if _, err := users.GetByID(scopedA, userA.ID); err != nil {
+		t.Errorf("expected own-tenant user to be visible, got %v", err)
+	}
+	if _, err := users.GetByID(scopedA, userB.ID); err != identity.ErrUserNotFound {
+		t.Errorf("cross-tenant leakage! expected ErrUserNotFound, got %v", err)
+	}
+
+	var count int
+	if err := db.pool.QueryRow(scopedA, "SELECT count(*) FROM users WHERE id = ANY($1)",
+		[]string{userA.ID, userB.ID}).Scan(&count); err != nil {
+		t.Fatalf("scoped count failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 visible user under tenant scope, got %d", count)
+	}
+
+	intruder := &identity.User{ID: id.NewUUIDv7(), TenantID: &tenantB.ID, Email: "rls-intruder@example.com"}
+	if err := users.Create(scopedA, intruder); err == nil {
+		db.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", intruder.ID)
+		t.Error("expected insert into another tenant to be rejected under tenant scope")
+	}
+
+	// A connection returned to the pool must not keep the previous scope.
+	if err := db.pool.QueryRow(ctx, "SELECT count(*) FROM users WHERE id = ANY($1)",
+		[]string{userA.ID, userB.ID}).Scan(&count); err != nil {
+		t.Fatalf("platform-wide count failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 visible users platform-wide, got %d", count)
+	}
+
+	// Without a scope nothing is visible and nothing can be written.
+	if err := db.pool.QueryRow(unscoped, "SELECT count(*) FROM users WHERE id = ANY($1)",
+		[]string{userA.ID, userB.ID}).Scan(&count); err != nil {
+		t.Fatalf("unscoped count failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no visible users without a scope, got %d", count)
+	}
+	stray := &identity.User{ID: id.NewUUIDv7(), TenantID: &tenantA.ID, Email: "rls-stray@example.com"}
+	if err := users.Create(unscoped, stray); err == nil {
+		db.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", stray.ID)
+		t.Error("expected insert without a scope to be rejected")
+	}
+}
+
+// TestPurpose: Validates that concurrent revocations of a tenant's owners cannot remove the last one.
+// Scope: Database Integration Test
+// Security: Availability of administration; two owners revoking each other at the same time must leave one of them
+// Expected: Of two concurrent revocations of the only two owners, exactly one succeeds and the other gets ErrLastTenantOwner; one owner remains.
+// Test Case ID: STO-35
+func TestTenantRoleRepository_RevokeLastOwner_Concurrent(t *testing.T) {
+	ctx := tenant.WithoutScope(context.Background())
+	cfg := Config{
+		Host:         "localhost",
+		Port:         "5432",
+		User:         "opentrusty",
+		Password:     "opentrusty_dev_password",
+		Database:     "opentrusty",
+		SSLMode:      "disable",
+		MaxOpenConns: 5,
+		MaxIdleConns: 5,
+	}
+
+	db, err := New(ctx, cfg)
+	if err != nil {
+		t.Skipf("Skipping integration test: failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	for _, script := range Migrations() {
+		if err := db.Migrate(ctx, script); err != nil {
+			t.Fatalf("failed to apply migrations: %v", err)
+		}
+	}
+
+	tn := &tenant.Tenant{ID: id.NewUUIDv7(), Name: "owners-" + id.NewUUIDv7(), Status: tenant.StatusActive}
+	if err := NewTenantRepository(db).Create(ctx, tn); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	defer db.pool.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tn.ID)
+
+	users := NewUserRepository(db)
+	roles := NewTenantRoleRepository(db)
+	var owners []string
+	for i := range 2 {
+		u := &identity.User{ID: id.NewUUIDv7(), TenantID: &tn.ID, Email: fmt.Sprintf("owner-%d@example.com", i)}
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		defer db.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", u.ID)
+		owners = append(owners, u.ID)
+	}
+
+	for range 20 {
+		for _, userID := range owners {
+			role := &tenant.TenantUserRole{ID: id.NewUUIDv7(), TenantID: tn.ID, UserID: userID, Role: tenant.RoleTenantOwner, GrantedBy: tenant.GranterSystem}
+			if err := roles.AssignRole(ctx, role); err != nil {
+				t.Fatalf("failed to assign owner: %v", err)
+			}
+		}
+
+		errs := make([]error, len(owners))
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i, userID := range owners {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				errs[i] = roles.RevokeOwnerRole(ctx, tn.ID, userID, tenant.RoleTenantOwner)
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		refused := 0
+		for _, err := range errs {
+			switch {
+			case errors.Is(err, tenant.ErrLastTenantOwner):
+				refused++
+			case err != nil:
+				t.Fatalf("RevokeOwnerRole: %v", err)
+			}
+		}
+		if refused != 1 {
+			t.Fatalf("expected exactly one revocation to be refused, got %v", errs)
+		}
+		remaining, err := roles.GetTenantUsers(ctx, tn.ID)
+		if err != nil {
+			t.Fatalf("GetTenantUsers: %v", err)
+		}
+		if len(remaining) != 1 {
+			t.Fatalf("expected the tenant to keep one owner, got %d", len(remaining))
+		}
+		if err := roles.RevokeRole(ctx, tn.ID, remaining[0].UserID, tenant.RoleTenantOwner); err != nil {
+			t.Fatalf("RevokeRole: %v", err)
+		}
+	}
+}
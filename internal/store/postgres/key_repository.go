@@ -16,9 +16,11 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/opentrusty/opentrusty/internal/oauth2"
 )
 
@@ -32,14 +34,31 @@ func NewKeyRepository(db *DB) *KeyRepository {
 	return &KeyRepository{db: db}
 }
 
+const keyColumns = `id, type, algorithm, public_key, private_key_encrypted, data_key_encrypted, kms_key_id, status, created_at, activated_at, retired_at, expires_at`
+
+func scanKey(row pgx.Row) (*oauth2.Key, error) {
+	var key oauth2.Key
+	err := row.Scan(
+		&key.ID, &key.Type, &key.Algorithm, &key.PublicKey, &key.PrivateKeyEncrypted, &key.DataKeyEncrypted, &key.KMSKeyID, &key.Status,
+		&key.CreatedAt, &key.ActivatedAt, &key.RetiredAt, &key.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
 // Create stores a new key
 func (r *KeyRepository) Create(ctx context.Context, key *oauth2.Key) error {
+	if key.Status == "" {
+		key.Status = oauth2.KeyStatusActive
+	}
 	_, err := r.db.pool.Exec(ctx, `
-		INSERT INTO openid_keys (
-			id, type, algorithm, public_key, private_key_encrypted, created_at, expires_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO openid_keys (`+keyColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`,
-		key.ID, key.Type, key.Algorithm, key.PublicKey, key.PrivateKeyEncrypted, key.CreatedAt, key.ExpiresAt,
+		key.ID, key.Type, key.Algorithm, key.PublicKey, key.PrivateKeyEncrypted, key.DataKeyEncrypted, key.KMSKeyID, key.Status,
+		key.CreatedAt, key.ActivatedAt, key.RetiredAt, key.ExpiresAt,
 	)
 
 	if err != nil {
@@ -49,36 +68,48 @@ func (r *KeyRepository) Create(ctx context.Context, key *oauth2.Key) error {
 	return nil
 }
 
-// GetActiveKey retrieves the most recent valid key
+// GetByID retrieves a key by ID
+func (r *KeyRepository) GetByID(ctx context.Context, id string) (*oauth2.Key, error) {
+	key, err := scanKey(r.db.pool.QueryRow(ctx, `SELECT `+keyColumns+` FROM openid_keys WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, oauth2.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	return key, nil
+}
+
+// GetActiveKey retrieves the most recently activated unexpired active key
 func (r *KeyRepository) GetActiveKey(ctx context.Context) (*oauth2.Key, error) {
-	var key oauth2.Key
-	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, type, algorithm, public_key, private_key_encrypted, created_at, expires_at
+	key, err := scanKey(r.db.pool.QueryRow(ctx, `
+		SELECT `+keyColumns+`
 		FROM openid_keys
-		WHERE expires_at > $1
-		ORDER BY created_at DESC
+		WHERE status = 'active' AND expires_at > $1
+		ORDER BY COALESCE(activated_at, created_at) DESC
 		LIMIT 1
-	`, time.Now()).Scan(
-		&key.ID, &key.Type, &key.Algorithm, &key.PublicKey, &key.PrivateKeyEncrypted, &key.CreatedAt, &key.ExpiresAt,
-	)
+	`, time.Now()))
 
 	if err != nil {
-		// return nil, fmt.Errorf("failed to get active key: %w", err)
-		// Return specific error or nil if not found, let service handle generation
+		// Let the service decide whether to generate a key when none is found
 		return nil, err
 	}
 
-	return &key, nil
+	return key, nil
 }
 
-// ListValidKeys retrieves all valid keys
+// ListValidKeys retrieves all published keys, newest first
 func (r *KeyRepository) ListValidKeys(ctx context.Context) ([]*oauth2.Key, error) {
-	rows, err := r.db.pool.Query(ctx, `
-		SELECT id, type, algorithm, public_key, private_key_encrypted, created_at, expires_at
-		FROM openid_keys
-		WHERE expires_at > $1
-		ORDER BY created_at DESC
-	`, time.Now())
+	return r.list(ctx, `WHERE status <> 'retired' AND expires_at > $1`, time.Now())
+}
+
+// List retrieves all keys, newest first
+func (r *KeyRepository) List(ctx context.Context) ([]*oauth2.Key, error) {
+	return r.list(ctx, ``)
+}
+
+func (r *KeyRepository) list(ctx context.Context, where string, args ...any) ([]*oauth2.Key, error) {
+	rows, err := r.db.pool.Query(ctx, `SELECT `+keyColumns+` FROM openid_keys `+where+` ORDER BY created_at DESC`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list keys: %w", err)
 	}
@@ -86,12 +117,28 @@ func (r *KeyRepository) ListValidKeys(ctx context.Context) ([]*oauth2.Key, error
 
 	var keys []*oauth2.Key
 	for rows.Next() {
-		var key oauth2.Key
-		if err := rows.Scan(&key.ID, &key.Type, &key.Algorithm, &key.PublicKey, &key.PrivateKeyEncrypted, &key.CreatedAt, &key.ExpiresAt); err != nil {
+		key, err := scanKey(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan key: %w", err)
 		}
-		keys = append(keys, &key)
+		keys = append(keys, key)
 	}
 
-	return keys, nil
+	return keys, rows.Err()
+}
+
+// Update stores a key's lifecycle fields
+func (r *KeyRepository) Update(ctx context.Context, key *oauth2.Key) error {
+	tag, err := r.db.pool.Exec(ctx, `
+		UPDATE openid_keys
+		SET status = $2, activated_at = $3, retired_at = $4, expires_at = $5
+		WHERE id = $1
+	`, key.ID, key.Status, key.ActivatedAt, key.RetiredAt, key.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to update key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return oauth2.ErrKeyNotFound
+	}
+	return nil
 }
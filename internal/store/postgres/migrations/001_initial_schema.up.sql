@@ -49,6 +49,7 @@ CREATE TABLE IF NOT EXISTS rbac_role_permissions (
 -- -----------------------------------------------------------------------------
 
 -- Local helper for updated_at
+-- postgres-only:begin
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -56,6 +57,7 @@ BEGIN
     RETURN NEW;
 END;
 $$ language 'plpgsql';
+-- postgres-only:end
 
 -- Tenants Table
 CREATE TABLE IF NOT EXISTS tenants (
@@ -251,6 +253,7 @@ CREATE TABLE IF NOT EXISTS openid_keys (
 -- -----------------------------------------------------------------------------
 
 -- Triggers for updated_at
+-- postgres-only:begin
 DROP TRIGGER IF EXISTS update_rbac_roles_updated_at ON rbac_roles;
 CREATE TRIGGER update_rbac_roles_updated_at BEFORE UPDATE ON rbac_roles
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -266,6 +269,7 @@ CREATE TRIGGER update_projects_updated_at BEFORE UPDATE ON projects
 DROP TRIGGER IF EXISTS update_oauth2_clients_updated_at ON oauth2_clients;
 CREATE TRIGGER update_oauth2_clients_updated_at BEFORE UPDATE ON oauth2_clients
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+-- postgres-only:end
 
 -- Seed Permissions
 INSERT INTO rbac_permissions (id, name, description) VALUES
@@ -0,0 +1,3 @@
+-- 002_tenant_email_settings.down.sql
+
+DROP TABLE IF EXISTS tenant_email_settings;
@@ -0,0 +1,23 @@
+-- 002_tenant_email_settings.up.sql
+-- Per-tenant outbound email configuration.
+-- smtp_password_encrypted holds AES-256-GCM ciphertext; plaintext credentials are never stored.
+
+CREATE TABLE IF NOT EXISTS tenant_email_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    smtp_host VARCHAR(255) NOT NULL,
+    smtp_port INTEGER NOT NULL,
+    smtp_username VARCHAR(255),
+    smtp_password_encrypted BYTEA,
+    from_address VARCHAR(255) NOT NULL,
+    from_name VARCHAR(255),
+    tls_mode VARCHAR(20) NOT NULL DEFAULT 'starttls' CHECK (tls_mode IN ('starttls', 'implicit', 'none')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- postgres-only:begin
+DROP TRIGGER IF EXISTS update_tenant_email_settings_updated_at ON tenant_email_settings;
+CREATE TRIGGER update_tenant_email_settings_updated_at BEFORE UPDATE ON tenant_email_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+-- postgres-only:end
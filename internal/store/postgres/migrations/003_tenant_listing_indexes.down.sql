@@ -0,0 +1,5 @@
+-- 003_tenant_listing_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_tenants_status;
+DROP INDEX IF EXISTS idx_tenants_name_id;
+DROP INDEX IF EXISTS idx_tenants_created_at_id;
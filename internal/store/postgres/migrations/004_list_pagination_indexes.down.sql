@@ -0,0 +1,6 @@
+-- 004_list_pagination_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_rbac_assignments_user_id_id;
+DROP INDEX IF EXISTS idx_projects_owner_id_id;
+DROP INDEX IF EXISTS idx_oauth2_clients_owner_id_id;
+DROP INDEX IF EXISTS idx_oauth2_clients_tenant_id_id;
@@ -0,0 +1,7 @@
+-- 004_list_pagination_indexes.up.sql
+-- Keyset pagination indexes for lists ordered by id within an owner, tenant or user.
+
+CREATE INDEX IF NOT EXISTS idx_oauth2_clients_tenant_id_id ON oauth2_clients(tenant_id, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_oauth2_clients_owner_id_id ON oauth2_clients(owner_id, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_projects_owner_id_id ON projects(owner_id, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_rbac_assignments_user_id_id ON rbac_assignments(user_id, id);
@@ -0,0 +1,3 @@
+-- 005_audit_events.down.sql
+
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,20 @@
+-- 005_audit_events.up.sql
+-- Persistent audit trail for compliance review.
+-- tenant_id and actor_id are not foreign keys so events outlive the tenants and users they describe.
+-- Platform-level events have an empty tenant_id.
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    type VARCHAR(100) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    resource VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    metadata JSONB,
+    occurred_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_id_id ON audit_events(tenant_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_type_id ON audit_events(tenant_id, type, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_actor_id ON audit_events(tenant_id, actor_id, id);
@@ -0,0 +1,4 @@
+-- 006_audit_retention.down.sql
+
+DROP INDEX IF EXISTS idx_audit_events_occurred_at;
+DROP TABLE IF EXISTS audit_retention_policies;
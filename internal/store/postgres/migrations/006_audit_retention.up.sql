@@ -0,0 +1,12 @@
+-- 006_audit_retention.up.sql
+-- Per-tenant audit retention overrides; tenants without a row use the platform default.
+-- tenant_id is not a foreign key so a regulated tenant's retention still applies after the tenant is purged.
+
+CREATE TABLE IF NOT EXISTS audit_retention_policies (
+    tenant_id VARCHAR(255) PRIMARY KEY,
+    retention_days INTEGER NOT NULL CHECK (retention_days >= 0),
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events(occurred_at);
@@ -0,0 +1,3 @@
+-- 007_audit_schema_version.down.sql
+
+ALTER TABLE audit_events DROP COLUMN IF EXISTS schema_version;
@@ -0,0 +1,5 @@
+-- 007_audit_schema_version.up.sql
+-- Version of the typed event payload stored in metadata.
+-- Existing rows keep version 0, which marks legacy free-form metadata.
+
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- 008_audit_request_id.down.sql
+
+ALTER TABLE audit_events DROP COLUMN IF EXISTS request_id;
@@ -0,0 +1,6 @@
+-- 008_audit_request_id.up.sql
+-- Correlates audit events with the HTTP request (X-Request-Id) that caused them.
+-- ip_address is widened because it holds the X-Forwarded-For chain behind proxies.
+
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS request_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE audit_events ALTER COLUMN ip_address TYPE TEXT;
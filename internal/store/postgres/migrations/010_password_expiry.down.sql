@@ -0,0 +1,3 @@
+-- 010_password_expiry.down.sql
+
+ALTER TABLE credentials DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,4 @@
+-- 010_password_expiry.up.sql
+-- An admin can force a password change; the user must set a new password at the next login.
+
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
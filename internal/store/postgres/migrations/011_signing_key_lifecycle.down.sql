@@ -0,0 +1,6 @@
+-- 011_signing_key_lifecycle.down.sql
+
+DROP INDEX IF EXISTS idx_openid_keys_status_expires_at;
+ALTER TABLE openid_keys DROP COLUMN IF EXISTS retired_at;
+ALTER TABLE openid_keys DROP COLUMN IF EXISTS activated_at;
+ALTER TABLE openid_keys DROP COLUMN IF EXISTS status;
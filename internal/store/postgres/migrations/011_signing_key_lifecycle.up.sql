@@ -0,0 +1,8 @@
+-- 011_signing_key_lifecycle.up.sql
+-- Signing keys move through pending -> active -> retiring -> retired; keys written before keep signing.
+
+ALTER TABLE openid_keys ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE openid_keys ADD COLUMN IF NOT EXISTS activated_at TIMESTAMP;
+ALTER TABLE openid_keys ADD COLUMN IF NOT EXISTS retired_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_openid_keys_status_expires_at ON openid_keys(status, expires_at);
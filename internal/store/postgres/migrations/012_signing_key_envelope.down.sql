@@ -0,0 +1,4 @@
+-- 012_signing_key_envelope.down.sql
+
+ALTER TABLE openid_keys DROP COLUMN IF EXISTS kms_key_id;
+ALTER TABLE openid_keys DROP COLUMN IF EXISTS data_key_encrypted;
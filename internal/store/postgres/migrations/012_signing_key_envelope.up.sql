@@ -0,0 +1,6 @@
+-- 012_signing_key_envelope.up.sql
+-- With a KMS, private keys are sealed with a per-key data key that the KMS wraps; keys written before
+-- are sealed with OPENID_KEY_ENCRYPTION_KEY directly and have no data key.
+
+ALTER TABLE openid_keys ADD COLUMN IF NOT EXISTS data_key_encrypted BYTEA;
+ALTER TABLE openid_keys ADD COLUMN IF NOT EXISTS kms_key_id VARCHAR(512) NOT NULL DEFAULT '';
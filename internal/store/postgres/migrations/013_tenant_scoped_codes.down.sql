@@ -0,0 +1,4 @@
+-- 013_tenant_scoped_codes.down.sql
+
+DROP INDEX IF EXISTS idx_authorization_codes_tenant;
+ALTER TABLE authorization_codes DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,15 @@
+-- 013_tenant_scoped_codes.up.sql
+-- Authorization codes belong to the tenant of their client and are only looked up within it, like tokens.
+-- Codes live for minutes, so existing ones take their client's tenant and any left without one are dropped.
+
+ALTER TABLE authorization_codes ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+
+UPDATE authorization_codes c SET tenant_id = cl.tenant_id
+FROM oauth2_clients cl
+WHERE cl.client_id = c.client_id AND c.tenant_id IS NULL;
+
+DELETE FROM authorization_codes WHERE tenant_id IS NULL;
+
+ALTER TABLE authorization_codes ALTER COLUMN tenant_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_authorization_codes_tenant ON authorization_codes(tenant_id);
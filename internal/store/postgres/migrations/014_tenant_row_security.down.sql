@@ -0,0 +1,27 @@
+-- 014_tenant_row_security.down.sql
+
+DROP POLICY IF EXISTS tenant_isolation ON refresh_tokens;
+ALTER TABLE refresh_tokens NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON access_tokens;
+ALTER TABLE access_tokens NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE access_tokens DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON authorization_codes;
+ALTER TABLE authorization_codes NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE authorization_codes DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON oauth2_clients;
+ALTER TABLE oauth2_clients NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE oauth2_clients DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON sessions;
+ALTER TABLE sessions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE sessions DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON users;
+ALTER TABLE users NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS tenant_row_visible(UUID);
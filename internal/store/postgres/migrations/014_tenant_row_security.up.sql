@@ -0,0 +1,49 @@
+-- 014_tenant_row_security.up.sql
+-- Row-level security on tenant-owned tables, as a second line of defence behind the repositories' WHERE clauses.
+-- The store sets app.tenant_id on each connection from the context's tenant scope; under a tenant, only rows of
+-- that tenant and rows that belong to no tenant (platform users and sessions) can be read or written. Platform-wide
+-- work such as login, the janitor, migrations and the CLI sets it to '*' and sees every row. A connection that never
+-- set it, or left it empty, sees none.
+-- FORCE applies the policies to the table owner too; superusers and BYPASSRLS roles still skip them.
+-- PostgreSQL only: SQLite has no row-level security and DB_COMPAT=cockroachdb skips this migration.
+
+-- postgres-only:begin
+CREATE OR REPLACE FUNCTION tenant_row_visible(row_tenant UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT CASE COALESCE(current_setting('app.tenant_id', true), '')
+        WHEN '' THEN FALSE
+        WHEN '*' THEN TRUE
+        ELSE row_tenant IS NULL OR row_tenant::text = current_setting('app.tenant_id', true)
+    END
+$$;
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users USING (tenant_row_visible(tenant_id));
+
+ALTER TABLE sessions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sessions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON sessions;
+CREATE POLICY tenant_isolation ON sessions USING (tenant_row_visible(tenant_id));
+
+ALTER TABLE oauth2_clients ENABLE ROW LEVEL SECURITY;
+ALTER TABLE oauth2_clients FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON oauth2_clients;
+CREATE POLICY tenant_isolation ON oauth2_clients USING (tenant_row_visible(tenant_id));
+
+ALTER TABLE authorization_codes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE authorization_codes FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON authorization_codes;
+CREATE POLICY tenant_isolation ON authorization_codes USING (tenant_row_visible(tenant_id));
+
+ALTER TABLE access_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE access_tokens FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON access_tokens;
+CREATE POLICY tenant_isolation ON access_tokens USING (tenant_row_visible(tenant_id));
+
+ALTER TABLE refresh_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON refresh_tokens;
+CREATE POLICY tenant_isolation ON refresh_tokens USING (tenant_row_visible(tenant_id));
+-- postgres-only:end
@@ -0,0 +1,4 @@
+-- 015_record_versions.down.sql
+
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
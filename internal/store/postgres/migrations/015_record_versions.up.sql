@@ -0,0 +1,5 @@
+-- 015_record_versions.up.sql
+-- Row versions for optimistic concurrency: updates must name the version they were based on.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
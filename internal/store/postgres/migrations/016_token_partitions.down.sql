@@ -0,0 +1,72 @@
+-- 016_token_partitions.down.sql
+-- Converts the token tables back to plain tables, keeping unexpired tokens.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'access_tokens'::regclass) THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE refresh_tokens RENAME TO refresh_tokens_partitioned;
+    ALTER INDEX refresh_tokens_pkey RENAME TO refresh_tokens_partitioned_pkey;
+    ALTER TABLE access_tokens RENAME TO access_tokens_partitioned;
+    ALTER INDEX access_tokens_pkey RENAME TO access_tokens_partitioned_pkey;
+
+    CREATE TABLE access_tokens (
+        id UUID PRIMARY KEY,
+        tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+        token_hash VARCHAR(255) UNIQUE NOT NULL,
+        client_id UUID NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+        user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        scope TEXT NOT NULL,
+        token_type VARCHAR(50) DEFAULT 'Bearer',
+        expires_at TIMESTAMP NOT NULL,
+        revoked_at TIMESTAMP,
+        is_revoked BOOLEAN DEFAULT false,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE refresh_tokens (
+        id UUID PRIMARY KEY,
+        tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+        token_hash VARCHAR(255) UNIQUE NOT NULL,
+        access_token_id UUID REFERENCES access_tokens(id) ON DELETE CASCADE,
+        client_id UUID NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+        user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        scope TEXT NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        revoked_at TIMESTAMP,
+        is_revoked BOOLEAN DEFAULT false,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    INSERT INTO access_tokens
+    SELECT id, tenant_id, token_hash, client_id, user_id,
+        scope, token_type, expires_at, revoked_at, is_revoked, created_at
+    FROM access_tokens_partitioned
+    WHERE expires_at > LOCALTIMESTAMP;
+
+    -- Refresh tokens whose access token is gone lose the link rather than the token
+    INSERT INTO refresh_tokens
+    SELECT r.id, r.tenant_id, r.token_hash, a.id, r.client_id, r.user_id,
+        r.scope, r.expires_at, r.revoked_at, r.is_revoked, r.created_at
+    FROM refresh_tokens_partitioned r
+    LEFT JOIN access_tokens a ON a.id = r.access_token_id
+    WHERE r.expires_at > LOCALTIMESTAMP;
+
+    DROP TABLE refresh_tokens_partitioned;
+    DROP TABLE access_tokens_partitioned;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_tenant ON access_tokens(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_tenant ON refresh_tokens(tenant_id);
+
+ALTER TABLE access_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE access_tokens FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON access_tokens;
+CREATE POLICY tenant_isolation ON access_tokens USING (tenant_row_visible(tenant_id));
+
+ALTER TABLE refresh_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON refresh_tokens;
+CREATE POLICY tenant_isolation ON refresh_tokens USING (tenant_row_visible(tenant_id));
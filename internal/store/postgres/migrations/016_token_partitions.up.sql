@@ -0,0 +1,111 @@
+-- 016_token_partitions.up.sql
+-- Access and refresh tokens are range-partitioned by created_at into daily partitions, so expired tokens
+-- are removed by dropping whole partitions instead of deleting rows. The janitor creates partitions
+-- ahead of time and drops a partition once all of its tokens have expired (token_partitions.go).
+--
+-- The existing tables are converted once: unexpired tokens move into a single legacy partition that ends
+-- two days from now, the janitor continues with daily partitions from there, and a default partition
+-- catches rows if the janitor falls behind. A partitioned table can only enforce uniqueness together with
+-- the partition key, so token_hash is unique together with created_at (hashes of 256-bit random values).
+--
+-- For the same reason refresh_tokens.access_token_id is no longer a foreign key: access_tokens(id) is not
+-- unique on its own. The column still records the access token a refresh token was issued with, but
+-- nothing reads it to find that token, and deleting an access token (or dropping its partition) no longer
+-- deletes its refresh tokens through ON DELETE CASCADE. This is what partitioning needs: a refresh token
+-- outlives its access token, and revocation updates both tables itself. An access_token_id may therefore
+-- name a token that no longer exists.
+-- DB_COMPAT=cockroachdb skips this migration and keeps the plain tables.
+
+-- postgres-only:begin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'access_tokens'::regclass) THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE refresh_tokens RENAME TO refresh_tokens_unpartitioned;
+    ALTER INDEX refresh_tokens_pkey RENAME TO refresh_tokens_unpartitioned_pkey;
+    ALTER TABLE access_tokens RENAME TO access_tokens_unpartitioned;
+    ALTER INDEX access_tokens_pkey RENAME TO access_tokens_unpartitioned_pkey;
+
+    CREATE TABLE access_tokens (
+        id UUID NOT NULL,
+        tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+        token_hash VARCHAR(255) NOT NULL,
+        client_id UUID NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+        user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        scope TEXT NOT NULL,
+        token_type VARCHAR(50) DEFAULT 'Bearer',
+        expires_at TIMESTAMP NOT NULL,
+        revoked_at TIMESTAMP,
+        is_revoked BOOLEAN DEFAULT false,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (id, created_at)
+    ) PARTITION BY RANGE (created_at);
+
+    CREATE TABLE refresh_tokens (
+        id UUID NOT NULL,
+        tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+        token_hash VARCHAR(255) NOT NULL,
+        access_token_id UUID,
+        client_id UUID NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+        user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+        scope TEXT NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        revoked_at TIMESTAMP,
+        is_revoked BOOLEAN DEFAULT false,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (id, created_at)
+    ) PARTITION BY RANGE (created_at);
+
+    EXECUTE format('CREATE TABLE access_tokens_legacy PARTITION OF access_tokens FOR VALUES FROM (MINVALUE) TO (%L)',
+        CURRENT_DATE + 2);
+    EXECUTE format('CREATE TABLE refresh_tokens_legacy PARTITION OF refresh_tokens FOR VALUES FROM (MINVALUE) TO (%L)',
+        CURRENT_DATE + 2);
+    CREATE TABLE access_tokens_default PARTITION OF access_tokens DEFAULT;
+    CREATE TABLE refresh_tokens_default PARTITION OF refresh_tokens DEFAULT;
+
+    INSERT INTO access_tokens (
+        id, tenant_id, token_hash, client_id, user_id,
+        scope, token_type, expires_at, revoked_at, is_revoked, created_at
+    )
+    SELECT id, tenant_id, token_hash, client_id, user_id,
+        scope, token_type, expires_at, revoked_at, is_revoked, created_at
+    FROM access_tokens_unpartitioned
+    WHERE expires_at > LOCALTIMESTAMP;
+
+    INSERT INTO refresh_tokens (
+        id, tenant_id, token_hash, access_token_id, client_id, user_id,
+        scope, expires_at, revoked_at, is_revoked, created_at
+    )
+    SELECT id, tenant_id, token_hash, access_token_id, client_id, user_id,
+        scope, expires_at, revoked_at, is_revoked, created_at
+    FROM refresh_tokens_unpartitioned
+    WHERE expires_at > LOCALTIMESTAMP;
+
+    DROP TABLE refresh_tokens_unpartitioned;
+    DROP TABLE access_tokens_unpartitioned;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_tenant ON access_tokens(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_access_tokens_expires ON access_tokens(expires_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_tenant ON refresh_tokens(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires ON refresh_tokens(expires_at);
+
+-- The unique indexes also serve lookups by hash, replacing the plain token_hash indexes of earlier versions
+DROP INDEX IF EXISTS idx_access_tokens_hash;
+DROP INDEX IF EXISTS idx_refresh_tokens_hash;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_access_tokens_hash_created ON access_tokens(token_hash, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_hash_created ON refresh_tokens(token_hash, created_at);
+
+-- Row-level security (014) applies to the partitioned tables through their parents
+ALTER TABLE access_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE access_tokens FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON access_tokens;
+CREATE POLICY tenant_isolation ON access_tokens USING (tenant_row_visible(tenant_id));
+
+ALTER TABLE refresh_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON refresh_tokens;
+CREATE POLICY tenant_isolation ON refresh_tokens USING (tenant_row_visible(tenant_id));
+-- postgres-only:end
@@ -0,0 +1,3 @@
+-- 017_oauth2_scopes.down.sql
+
+DROP TABLE IF EXISTS oauth2_scopes;
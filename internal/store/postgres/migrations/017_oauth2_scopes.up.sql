@@ -0,0 +1,26 @@
+-- 017_oauth2_scopes.up.sql
+-- Custom OAuth2 scopes defined by tenants. A scope's name is unique within its tenant; claims lists
+-- the user claims it releases in the ID token and permissions what introspection reports for it.
+
+CREATE TABLE IF NOT EXISTS oauth2_scopes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(128) NOT NULL,
+    description TEXT NOT NULL,
+    claims TEXT[] NOT NULL DEFAULT '{}',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, name)
+);
+
+-- postgres-only:begin
+DROP TRIGGER IF EXISTS update_oauth2_scopes_updated_at ON oauth2_scopes;
+CREATE TRIGGER update_oauth2_scopes_updated_at BEFORE UPDATE ON oauth2_scopes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE oauth2_scopes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE oauth2_scopes FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON oauth2_scopes;
+CREATE POLICY tenant_isolation ON oauth2_scopes USING (tenant_row_visible(tenant_id));
+-- postgres-only:end
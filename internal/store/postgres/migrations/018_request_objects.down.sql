@@ -0,0 +1,5 @@
+-- 018_request_objects.down.sql
+
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS require_signed_request_object;
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS request_uris;
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS jwks;
@@ -0,0 +1,7 @@
+-- 018_request_objects.up.sql
+-- Client metadata for JWT-secured authorization requests (RFC 9101): the public keys verifying the
+-- client's request objects, the URLs they may be fetched from, and whether they are required.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS jwks JSONB;
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS request_uris JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS require_signed_request_object BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- 019_access_token_claims.down.sql
+
+ALTER TABLE access_tokens DROP COLUMN IF EXISTS claims;
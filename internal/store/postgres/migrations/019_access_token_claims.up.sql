@@ -0,0 +1,5 @@
+-- 019_access_token_claims.up.sql
+-- Custom claims added to an access token at issuance by a claim enricher, reported by introspection.
+-- access_tokens is partitioned, so the column is added to every partition.
+
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS claims JSONB;
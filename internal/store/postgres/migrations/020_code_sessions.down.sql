@@ -0,0 +1,3 @@
+-- 020_code_sessions.down.sql
+
+ALTER TABLE authorization_codes DROP COLUMN IF EXISTS session_id;
@@ -0,0 +1,5 @@
+-- 020_code_sessions.up.sql
+-- The login session an authorization code was issued under, so that a code cannot be redeemed
+-- once its session has ended. Codes issued before this migration have no session.
+
+ALTER TABLE authorization_codes ADD COLUMN IF NOT EXISTS session_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- 021_replay_markers.down.sql
+
+DROP TABLE IF EXISTS replay_markers;
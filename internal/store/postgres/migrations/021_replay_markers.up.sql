@@ -0,0 +1,12 @@
+-- 021_replay_markers.up.sql
+-- One-time identifiers, such as the jti of client assertions, DPoP proofs and logout tokens, kept
+-- until the token carrying them expires so that a replay is refused by every replica.
+
+CREATE TABLE IF NOT EXISTS replay_markers (
+    kind VARCHAR(64) NOT NULL,
+    id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (kind, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_replay_markers_expires_at ON replay_markers(expires_at);
@@ -0,0 +1,3 @@
+-- 022_session_namespace.down.sql
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS namespace;
@@ -0,0 +1,5 @@
+-- 022_session_namespace.up.sql
+-- Plane a session was issued for (auth or admin). Sessions created before namespaces existed have
+-- none and are refused by both planes, so their users sign in again.
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS namespace VARCHAR(16) NOT NULL DEFAULT '';
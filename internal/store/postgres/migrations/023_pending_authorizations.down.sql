@@ -0,0 +1,3 @@
+-- 023_pending_authorizations.down.sql
+
+DROP TABLE IF EXISTS pending_authorizations;
@@ -0,0 +1,13 @@
+-- 023_pending_authorizations.up.sql
+-- Authorization requests made before the user signed in, kept briefly so that they resume once
+-- the user has authenticated.
+
+CREATE TABLE IF NOT EXISTS pending_authorizations (
+    id VARCHAR(255) PRIMARY KEY,
+    client_id VARCHAR(255) NOT NULL,
+    params JSONB NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_authorizations_expires_at ON pending_authorizations(expires_at);
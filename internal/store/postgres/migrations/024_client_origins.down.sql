@@ -0,0 +1,3 @@
+-- 024_client_origins.down.sql
+
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS allowed_origins;
@@ -0,0 +1,5 @@
+-- 024_client_origins.up.sql
+-- Browser origins allowed to call the token and revocation endpoints for a client. Empty derives
+-- them from the client's redirect URIs.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS allowed_origins JSONB NOT NULL DEFAULT '[]'::jsonb;
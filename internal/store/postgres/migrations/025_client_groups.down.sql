@@ -0,0 +1,3 @@
+-- 025_client_groups.down.sql
+
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS client_group;
@@ -0,0 +1,5 @@
+-- 025_client_groups.up.sql
+-- Clients of a tenant sharing a group may be named as additional ID token audiences of each other.
+-- Empty puts the client in no group.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS client_group TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- 026_client_application_types.down.sql
+
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS application_type;
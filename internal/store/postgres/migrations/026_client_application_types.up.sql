@@ -0,0 +1,5 @@
+-- 026_client_application_types.up.sql
+-- Application type of a client (web, native, spa, service), restricting its other metadata. Empty
+-- for clients registered without one.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS application_type TEXT NOT NULL DEFAULT '';
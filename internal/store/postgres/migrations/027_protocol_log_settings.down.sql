@@ -0,0 +1,3 @@
+-- 027_protocol_log_settings.down.sql
+
+DROP TABLE IF EXISTS protocol_log_settings;
@@ -0,0 +1,10 @@
+-- 027_protocol_log_settings.up.sql
+-- Per-tenant logging of authorization and token decisions; tenants without a row log none.
+
+CREATE TABLE IF NOT EXISTS protocol_log_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP,
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- 028_client_state_policy.down.sql
+
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS min_state_length;
@@ -0,0 +1,5 @@
+-- 028_client_state_policy.up.sql
+-- Minimum length of the state parameter a client's authorization requests must carry. Zero
+-- leaves state optional.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS min_state_length INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- 029_client_jwks_uri.down.sql
+
+ALTER TABLE oauth2_clients DROP COLUMN IF EXISTS jwks_uri;
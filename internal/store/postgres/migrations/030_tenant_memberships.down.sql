@@ -0,0 +1,8 @@
+-- 030_tenant_memberships.down.sql
+
+-- postgres-only:begin
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users USING (tenant_row_visible(tenant_id));
+-- postgres-only:end
+
+DROP TABLE IF EXISTS tenant_memberships;
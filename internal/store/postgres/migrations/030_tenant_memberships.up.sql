@@ -0,0 +1,39 @@
+-- 030_tenant_memberships.up.sql
+-- Tenants a user is a member of. A user is a member of the tenant they are registered in (users.tenant_id,
+-- the home membership) and of any tenant a platform admin added them to, so one identity can sign in to
+-- several tenants and hold roles in each. Platform users have no home tenant and no memberships.
+
+CREATE TABLE IF NOT EXISTS tenant_memberships (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    is_home BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE(tenant_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_user_id ON tenant_memberships(user_id);
+
+-- Existing users are members of their home tenant; a home membership takes the ID of its user
+INSERT INTO tenant_memberships (id, tenant_id, user_id, is_home, created_at)
+SELECT id, tenant_id, id, TRUE, created_at FROM users WHERE tenant_id IS NOT NULL
+ON CONFLICT (tenant_id, user_id) DO NOTHING;
+
+-- Under a tenant scope (014_tenant_row_security) the members of the tenant are visible, not only
+-- the users registered in it, so a member signed in to the tenant can read their own record
+-- postgres-only:begin
+ALTER TABLE tenant_memberships ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_memberships FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON tenant_memberships;
+CREATE POLICY tenant_isolation ON tenant_memberships USING (tenant_row_visible(tenant_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users USING (
+    tenant_row_visible(tenant_id)
+    OR EXISTS (
+        SELECT 1 FROM tenant_memberships m
+        WHERE m.user_id = users.id AND m.tenant_id::text = current_setting('app.tenant_id', true)
+    )
+);
+-- postgres-only:end
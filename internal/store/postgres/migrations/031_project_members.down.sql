@@ -0,0 +1,12 @@
+-- 031_project_members.down.sql
+
+-- postgres-only:begin
+DROP POLICY IF EXISTS tenant_isolation ON projects;
+ALTER TABLE projects NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE projects DISABLE ROW LEVEL SECURITY;
+-- postgres-only:end
+
+DELETE FROM rbac_permissions WHERE id = '10000000-0000-0000-0000-000000000017';
+DROP TABLE IF EXISTS project_members;
+DROP INDEX IF EXISTS idx_projects_tenant_id_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,52 @@
+-- 031_project_members.up.sql
+-- Projects belong to a tenant, and users hold a project role (project_admin or project_member) in the
+-- projects they are members of. Project roles are fixed in code, so they are stored by name rather than
+-- as RBAC roles; tenant:manage_projects lets tenant admins manage every project of their tenant.
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- Existing projects belong to the tenant of their owner
+UPDATE projects SET tenant_id = (SELECT u.tenant_id FROM users u WHERE u.id = projects.owner_id)
+WHERE tenant_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_projects_tenant_id_id ON projects(tenant_id, id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL CHECK (role IN ('project_admin', 'project_member')),
+    granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+-- Owners administer their existing projects
+INSERT INTO project_members (project_id, user_id, role, granted_at)
+SELECT id, owner_id, 'project_admin', created_at FROM projects
+ON CONFLICT (project_id, user_id) DO NOTHING;
+
+INSERT INTO rbac_permissions (id, name, description) VALUES
+    ('10000000-0000-0000-0000-000000000017', 'tenant:manage_projects', 'Manage projects in a tenant')
+ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, description = EXCLUDED.description;
+
+INSERT INTO rbac_role_permissions (role_id, permission_id) VALUES
+    ('20000000-0000-0000-0000-000000000001', '10000000-0000-0000-0000-000000000017'),
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000017')
+ON CONFLICT DO NOTHING;
+
+-- postgres-only:begin
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE projects FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON projects;
+CREATE POLICY tenant_isolation ON projects USING (tenant_row_visible(tenant_id));
+
+-- Members are visible with the projects they belong to
+ALTER TABLE project_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_members FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON project_members;
+CREATE POLICY tenant_isolation ON project_members USING (
+    EXISTS (SELECT 1 FROM projects p WHERE p.id = project_members.project_id)
+);
+-- postgres-only:end
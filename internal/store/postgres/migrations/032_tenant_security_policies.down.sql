@@ -0,0 +1,3 @@
+-- 032_tenant_security_policies.down.sql
+
+DROP TABLE IF EXISTS tenant_security_policies;
@@ -0,0 +1,14 @@
+-- 032_tenant_security_policies.up.sql
+-- Per-tenant lockout and rate limit settings; a zero column inherits the platform's setting.
+
+CREATE TABLE IF NOT EXISTS tenant_security_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    lockout_max_attempts INTEGER NOT NULL DEFAULT 0,
+    lockout_duration_seconds BIGINT NOT NULL DEFAULT 0,
+    login_rps DOUBLE PRECISION NOT NULL DEFAULT 0,
+    login_burst INTEGER NOT NULL DEFAULT 0,
+    token_rps DOUBLE PRECISION NOT NULL DEFAULT 0,
+    token_burst INTEGER NOT NULL DEFAULT 0,
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
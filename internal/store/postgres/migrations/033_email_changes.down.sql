@@ -0,0 +1,3 @@
+-- 033_email_changes.down.sql
+
+DROP TABLE IF EXISTS email_changes;
@@ -0,0 +1,16 @@
+-- 033_email_changes.up.sql
+-- Email address changes in progress: pending until the new address is verified, then verified until
+-- the cooling-off window (expires_at) ends. token_hash is the hash of the token mailed for the state.
+
+CREATE TABLE IF NOT EXISTS email_changes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_changes_status_expires_at ON email_changes(status, expires_at);
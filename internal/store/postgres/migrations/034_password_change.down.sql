@@ -0,0 +1,4 @@
+-- 034_password_change.down.sql
+
+ALTER TABLE tenant_security_policies DROP COLUMN IF EXISTS password_max_age_seconds;
+ALTER TABLE sessions DROP COLUMN IF EXISTS password_change_required;
@@ -0,0 +1,6 @@
+-- 034_password_change.up.sql
+-- Sessions restricted to changing an expired or temporary password, and the maximum password age
+-- of a tenant's security policy (zero inherits the platform's).
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS password_change_required BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenant_security_policies ADD COLUMN IF NOT EXISTS password_max_age_seconds BIGINT NOT NULL DEFAULT 0;
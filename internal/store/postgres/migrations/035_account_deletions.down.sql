@@ -0,0 +1,3 @@
+-- 035_account_deletions.down.sql
+
+DROP TABLE IF EXISTS account_deletions;
@@ -0,0 +1,11 @@
+-- 035_account_deletions.up.sql
+-- Accounts their users asked to delete: erased once the grace period (delete_at) ends unless the
+-- user cancels or logs in before then.
+
+CREATE TABLE IF NOT EXISTS account_deletions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    requested_at TIMESTAMP NOT NULL,
+    delete_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_deletions_delete_at ON account_deletions(delete_at);
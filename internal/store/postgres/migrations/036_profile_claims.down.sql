@@ -0,0 +1,4 @@
+-- 036_profile_claims.down.sql
+
+ALTER TABLE users DROP COLUMN IF EXISTS birthdate;
+ALTER TABLE users DROP COLUMN IF EXISTS website;
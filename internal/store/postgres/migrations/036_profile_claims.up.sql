@@ -0,0 +1,7 @@
+-- 036_profile_claims.up.sql
+-- The website and birthdate profile claims (OIDC Core Section 5.1), and room for BCP 47 locales
+-- longer than a language and region.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS website TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS birthdate VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE users ALTER COLUMN locale TYPE VARCHAR(35);
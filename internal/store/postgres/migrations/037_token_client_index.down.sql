@@ -0,0 +1,4 @@
+-- 037_token_client_index.down.sql
+
+DROP INDEX IF EXISTS idx_refresh_tokens_client;
+DROP INDEX IF EXISTS idx_access_tokens_client;
@@ -0,0 +1,5 @@
+-- 038_live_user_email.down.sql
+-- Fails while a soft-deleted user shares its email with a live user of the same tenant.
+
+ALTER TABLE users ADD CONSTRAINT users_tenant_id_email_key UNIQUE (tenant_id, email);
+DROP INDEX IF EXISTS idx_users_tenant_email_live;
@@ -0,0 +1,11 @@
+-- 038_live_user_email.up.sql
+-- An email is unique among the live users of a tenant only, so a soft-deleted user no longer blocks
+-- creating a user, or changing an email, to the address it had until the janitor purges it.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email_live ON users(tenant_id, email) WHERE deleted_at IS NULL;
+
+-- postgres-only:begin
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_tenant_id_email_key;
+-- postgres-only:end
+-- CockroachDB drops the index behind a unique constraint instead of the constraint
+-- cockroachdb-only: DROP INDEX IF EXISTS users@users_tenant_id_email_key CASCADE;
@@ -0,0 +1,71 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/opentrusty/opentrusty/internal/bus"
+)
+
+// busChannel is the LISTEN/NOTIFY channel that carries the replicas' bus messages
+const busChannel = "opentrusty_bus"
+
+// busTransport is a bus.Transport on LISTEN/NOTIFY
+type busTransport struct {
+	db *DB
+}
+
+// BusTransport returns a bus transport on LISTEN/NOTIFY, or nil on CockroachDB, which has neither
+func (db *DB) BusTransport() bus.Transport {
+	if db.cockroach {
+		return nil
+	}
+	return busTransport{db: db}
+}
+
+// Publish notifies every session listening on the bus channel. Payloads are limited to 8000 bytes.
+func (t busTransport) Publish(ctx context.Context, message []byte) error {
+	_, err := t.db.pool.Exec(ctx, "SELECT pg_notify($1, $2)", busChannel, string(message))
+	return err
+}
+
+// Listen subscribes a dedicated connection, which is taken out of the pool for good since the
+// subscription belongs to it, and passes each notification to deliver
+func (t busTransport) Listen(ctx context.Context, connected func(), deliver func(message []byte)) error {
+	pooled, err := t.db.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+busChannel); err != nil {
+		return err
+	}
+	connected()
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		deliver([]byte(notification.Payload))
+	}
+}
+
+// Close does nothing: the listening connection is closed by Listen and the pool by DB.Close
+func (busTransport) Close() error {
+	return nil
+}
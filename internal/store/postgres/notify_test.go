@@ -0,0 +1,57 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestPurpose: Validates that bus messages published on one connection reach every listener.
+// Scope: Database Integration Test
+// Security: Authorization (a deleted client must be evicted from other replicas' caches)
+// Expected: The listener reports the connection and then receives the published message unchanged.
+// Test Case ID: STO-17
+func TestBusTransport(t *testing.T) {
+	db := openMigratedDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	transport := db.BusTransport()
+	connected := make(chan struct{})
+	messages := make(chan string, 8)
+	go transport.Listen(ctx, func() { close(connected) }, func(m []byte) { messages <- string(m) })
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not connect")
+	}
+
+	if err := transport.Publish(ctx, []byte(`{"topic":"client.changed","payload":"id-1"}`)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-messages:
+		if m != `{"topic":"client.changed","payload":"id-1"}` {
+			t.Errorf("unexpected message %q", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// PendingAuthorizationRepository implements oauth2.PendingAuthorizationRepository
+type PendingAuthorizationRepository struct {
+	db *DB
+}
+
+// NewPendingAuthorizationRepository creates a new pending authorization repository
+func NewPendingAuthorizationRepository(db *DB) *PendingAuthorizationRepository {
+	return &PendingAuthorizationRepository{db: db}
+}
+
+// Create stores a pending authorization
+func (r *PendingAuthorizationRepository) Create(ctx context.Context, pending *oauth2.PendingAuthorization) error {
+	params, err := json.Marshal(pending.Params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal authorization parameters: %w", err)
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO pending_authorizations (id, client_id, params, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, pending.ID, pending.ClientID, params, pending.ExpiresAt, pending.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create pending authorization: %w", err)
+	}
+
+	return nil
+}
+
+// Consume deletes an unexpired pending authorization and returns it
+func (r *PendingAuthorizationRepository) Consume(ctx context.Context, id string) (*oauth2.PendingAuthorization, error) {
+	pending := oauth2.PendingAuthorization{ID: id}
+	var params []byte
+
+	err := r.db.pool.QueryRow(ctx, `
+		DELETE FROM pending_authorizations WHERE id = $1 AND expires_at > $2
+		RETURNING client_id, params, expires_at, created_at
+	`, id, time.Now()).Scan(&pending.ClientID, &params, &pending.ExpiresAt, &pending.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, oauth2.ErrPendingAuthorizationNotFound
+		}
+		return nil, fmt.Errorf("failed to consume pending authorization: %w", err)
+	}
+
+	if err := json.Unmarshal(params, &pending.Params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal authorization parameters: %w", err)
+	}
+
+	return &pending, nil
+}
+
+// DeleteExpired deletes up to limit expired pending authorizations and returns how many were removed
+func (r *PendingAuthorizationRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM pending_authorizations WHERE id IN (
+			SELECT id FROM pending_authorizations WHERE expires_at < $1 LIMIT $2
+		)
+	`, time.Now(), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired pending authorizations: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrPoolExhausted is returned when no connection became free within the acquire timeout, or none
+// was free while the circuit breaker is open
+var ErrPoolExhausted = apperr.New(apperr.CodeUnavailable, "database connection pool exhausted")
+
+// poolBreaker bounds how long a statement waits for a pooled connection and fails fast while the
+// pool is exhausted. When an acquire times out the breaker opens for cooldown: statements that
+// find no free connection meanwhile get ErrPoolExhausted at once instead of queueing, and the
+// first connection acquired closes it again. A zero acquireTimeout waits as long as the caller's
+// context allows and never opens.
+type poolBreaker struct {
+	acquireTimeout time.Duration
+	cooldown       time.Duration
+
+	pool      atomic.Pointer[pgxpool.Pool]
+	openUntil atomic.Int64 // Unix nanoseconds; zero while closed
+	pending   atomic.Int64 // Acquires waiting for a connection
+
+	waitTime metric.Float64Histogram
+	timeouts metric.Int64Counter
+	rejected metric.Int64Counter
+}
+
+var _ pgxpool.AcquireTracer = (*poolBreaker)(nil)
+
+// newPoolBreaker creates the acquire metrics on meter; a nil meter records none
+func newPoolBreaker(meter *metrics.Meter, acquireTimeout, cooldown time.Duration) (*poolBreaker, error) {
+	b := &poolBreaker{acquireTimeout: acquireTimeout, cooldown: cooldown}
+	if meter == nil {
+		return b, nil
+	}
+	var err error
+	b.waitTime, err = meter.CreateHistogram("db.client.connections.wait_time", "Time to acquire a pooled database connection", "ms")
+	if err != nil {
+		return nil, err
+	}
+	b.timeouts, err = meter.CreateCounter("db.client.connections.timeouts", "Acquires of a database connection that timed out")
+	if err != nil {
+		return nil, err
+	}
+	b.rejected, err = meter.CreateCounter("db.client.connections.rejected", "Statements failed fast while the connection pool was exhausted")
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// observe reports the state of pool as gauges on meter; a nil meter reports none
+func (b *poolBreaker) observe(meter *metrics.Meter, pool *pgxpool.Pool) error {
+	b.pool.Store(pool)
+	if meter == nil {
+		return nil
+	}
+	m := meter.GetMeter()
+	usage, err := m.Int64ObservableGauge("db.client.connections.usage",
+		metric.WithDescription("Database connections by state: used or idle"))
+	if err != nil {
+		return fmt.Errorf("failed to create gauge db.client.connections.usage: %w", err)
+	}
+	maxConns, err := m.Int64ObservableGauge("db.client.connections.max",
+		metric.WithDescription("Maximum number of open database connections"))
+	if err != nil {
+		return fmt.Errorf("failed to create gauge db.client.connections.max: %w", err)
+	}
+	pending, err := m.Int64ObservableGauge("db.client.connections.pending_requests",
+		metric.WithDescription("Statements waiting for a database connection"))
+	if err != nil {
+		return fmt.Errorf("failed to create gauge db.client.connections.pending_requests: %w", err)
+	}
+	acquireWait, err := m.Float64ObservableCounter("db.client.connections.acquire_wait",
+		metric.WithDescription("Total time statements spent acquiring a database connection"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return fmt.Errorf("failed to create counter db.client.connections.acquire_wait: %w", err)
+	}
+	open, err := m.Int64ObservableGauge("db.client.connections.breaker_open",
+		metric.WithDescription("1 while the connection pool circuit breaker is open"))
+	if err != nil {
+		return fmt.Errorf("failed to create gauge db.client.connections.breaker_open: %w", err)
+	}
+	_, err = m.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		stat := pool.Stat()
+		o.ObserveInt64(usage, int64(stat.AcquiredConns()), metric.WithAttributes(attribute.String("state", "used")))
+		o.ObserveInt64(usage, int64(stat.IdleConns()), metric.WithAttributes(attribute.String("state", "idle")))
+		o.ObserveInt64(maxConns, int64(stat.MaxConns()))
+		o.ObserveInt64(pending, b.pending.Load())
+		o.ObserveFloat64(acquireWait, stat.AcquireDuration().Seconds())
+		var isOpen int64
+		if b.open(time.Now()) {
+			isOpen = 1
+		}
+		o.ObserveInt64(open, isOpen)
+		return nil
+	}, usage, maxConns, pending, acquireWait, open)
+	if err != nil {
+		return fmt.Errorf("failed to register connection pool gauges: %w", err)
+	}
+	return nil
+}
+
+// acquireStartKey holds when the acquire in flight started and the cancel of its timeout
+type acquireStartKey struct{}
+
+type acquireStart struct {
+	at     time.Time
+	caller context.Context
+	cancel context.CancelFunc
+}
+
+// TraceAcquireStart bounds the acquire by acquireTimeout; the statement itself runs with the
+// caller's context
+func (b *poolBreaker) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	b.pending.Add(1)
+	start := acquireStart{at: time.Now(), caller: ctx, cancel: func() {}}
+	if b.acquireTimeout > 0 {
+		ctx, start.cancel = context.WithTimeout(ctx, b.acquireTimeout)
+	}
+	return context.WithValue(ctx, acquireStartKey{}, start)
+}
+
+// TraceAcquireEnd records the wait and opens or closes the breaker
+func (b *poolBreaker) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	b.pending.Add(-1)
+	start, ok := ctx.Value(acquireStartKey{}).(acquireStart)
+	if !ok {
+		return
+	}
+	// The acquire timed out if its context expired while the caller's did not
+	timedOut := errors.Is(data.Err, context.DeadlineExceeded) && start.caller.Err() == nil
+	start.cancel()
+	if b.waitTime != nil {
+		b.waitTime.Record(ctx, float64(time.Since(start.at).Microseconds())/1000)
+	}
+	switch {
+	case timedOut:
+		if b.timeouts != nil {
+			b.timeouts.Add(ctx, 1)
+		}
+		b.trip(ctx)
+	case data.Err == nil:
+		b.reset(ctx)
+	}
+}
+
+// admit fails fast with ErrPoolExhausted while the breaker is open and no connection is free
+func (b *poolBreaker) admit(ctx context.Context) error {
+	if b == nil || !b.Saturated() {
+		return nil
+	}
+	if b.rejected != nil {
+		b.rejected.Add(ctx, 1)
+	}
+	return ErrPoolExhausted
+}
+
+// exhausted translates the error of a statement whose acquire timed out into ErrPoolExhausted
+func (b *poolBreaker) exhausted(ctx context.Context, err error) error {
+	if b == nil || b.acquireTimeout <= 0 || ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return ErrPoolExhausted
+}
+
+// Saturated reports whether the breaker is open and the pool has no free connection
+func (b *poolBreaker) Saturated() bool {
+	if b == nil {
+		return false
+	}
+	pool := b.pool.Load()
+	if !b.open(time.Now()) || pool == nil {
+		return false
+	}
+	stat := pool.Stat()
+	return stat.IdleConns() == 0 && stat.TotalConns() >= stat.MaxConns()
+}
+
+func (b *poolBreaker) open(now time.Time) bool {
+	return now.UnixNano() < b.openUntil.Load()
+}
+
+// trip opens the breaker for cooldown, logging when the pool was not already known to be exhausted
+func (b *poolBreaker) trip(ctx context.Context) {
+	if b.openUntil.Swap(time.Now().Add(b.cooldown).UnixNano()) != 0 {
+		return
+	}
+	attrs := []any{
+		logger.Component("postgres"),
+		slog.Int64("pending", b.pending.Load()),
+		slog.Duration("acquire_timeout", b.acquireTimeout),
+		slog.Duration("cooldown", b.cooldown),
+	}
+	if pool := b.pool.Load(); pool != nil {
+		stat := pool.Stat()
+		attrs = append(attrs, slog.Int("acquired", int(stat.AcquiredConns())), slog.Int("max_conns", int(stat.MaxConns())))
+	}
+	slog.WarnContext(ctx, "database connection pool exhausted; failing fast while no connection is free", attrs...)
+}
+
+// reset closes the breaker after a connection was acquired
+func (b *poolBreaker) reset(ctx context.Context) {
+	if b.openUntil.Load() == 0 || b.openUntil.Swap(0) == 0 {
+		return
+	}
+	slog.InfoContext(ctx, "database connection pool recovered", logger.Component("postgres"))
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TestPurpose: Validates that the pool breaker opens when acquiring a connection times out and translates the timeout.
+// Scope: Unit Test
+// Security: Availability (an exhausted pool fails fast with 503 instead of queueing requests into timeouts)
+// Expected: An acquire is bounded by the acquire timeout; its timeout opens the breaker, is logged once and surfaces as ErrPoolExhausted, while a caller's own deadline does not; a later acquire closes the breaker.
+// Test Case ID: STO-30
+func TestPostgres_PoolBreaker(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	breaker, err := newPoolBreaker(nil, 20*time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	acquire := func(ctx context.Context) {
+		ctx = breaker.TraceAcquireStart(ctx, nil, pgxpool.TraceAcquireStartData{})
+		if _, ok := ctx.Deadline(); !ok {
+			t.Fatal("expected the acquire to be bounded by the acquire timeout")
+		}
+		<-ctx.Done()
+		breaker.TraceAcquireEnd(ctx, nil, pgxpool.TraceAcquireEndData{Err: ctx.Err()})
+	}
+
+	expired, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	acquire(expired)
+	if breaker.open(time.Now()) {
+		t.Fatal("expected the caller's own deadline not to open the breaker")
+	}
+	if err := breaker.exhausted(expired, context.DeadlineExceeded); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline to be reported as is, got %v", err)
+	}
+
+	acquire(ctx)
+	acquire(ctx)
+	if !breaker.open(time.Now()) {
+		t.Fatal("expected the acquire timeout to open the breaker")
+	}
+	if n := strings.Count(logs.String(), "connection pool exhausted"); n != 1 {
+		t.Errorf("expected the exhaustion to be logged once, got %d:\n%s", n, logs.String())
+	}
+	if err := breaker.exhausted(ctx, context.DeadlineExceeded); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("expected ErrPoolExhausted, got %v", err)
+	}
+	if breaker.Saturated() {
+		t.Error("expected a breaker without a pool not to report saturation")
+	}
+
+	breaker.TraceAcquireEnd(breaker.TraceAcquireStart(ctx, nil, pgxpool.TraceAcquireStartData{}), nil, pgxpool.TraceAcquireEndData{})
+	if breaker.open(time.Now()) || !strings.Contains(logs.String(), "connection pool recovered") {
+		t.Errorf("expected a successful acquire to close the breaker, got:\n%s", logs.String())
+	}
+	if breaker.pending.Load() != 0 {
+		t.Errorf("expected no pending acquires, got %d", breaker.pending.Load())
+	}
+
+	var disabled *poolBreaker
+	if err := disabled.admit(ctx); err != nil {
+		t.Errorf("expected a missing breaker to admit every statement, got %v", err)
+	}
+}
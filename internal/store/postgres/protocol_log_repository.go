@@ -0,0 +1,79 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// ProtocolLogRepository implements oauth2.ProtocolLogRepository
+type ProtocolLogRepository struct {
+	db *DB
+}
+
+// NewProtocolLogRepository creates a new protocol log settings repository
+func NewProtocolLogRepository(db *DB) *ProtocolLogRepository {
+	return &ProtocolLogRepository{db: db}
+}
+
+// Get retrieves a tenant's protocol log settings
+func (r *ProtocolLogRepository) Get(ctx context.Context, tenantID string) (*oauth2.ProtocolLogSettings, error) {
+	var p oauth2.ProtocolLogSettings
+	var expiresAt sql.NullTime
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT tenant_id, enabled, expires_at, updated_by, updated_at
+		FROM protocol_log_settings
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&p.TenantID, &p.Enabled, &expiresAt, &p.UpdatedBy, &p.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, oauth2.ErrProtocolLogNotFound
+		}
+		return nil, fmt.Errorf("failed to get protocol log settings: %w", err)
+	}
+	if expiresAt.Valid {
+		p.ExpiresAt = &expiresAt.Time
+	}
+	return &p, nil
+}
+
+// Upsert creates or replaces a tenant's protocol log settings
+func (r *ProtocolLogRepository) Upsert(ctx context.Context, p *oauth2.ProtocolLogSettings) error {
+	var expiresAt sql.NullTime
+	if p.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *p.ExpiresAt, Valid: true}
+	}
+
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO protocol_log_settings (tenant_id, enabled, expires_at, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			expires_at = EXCLUDED.expires_at,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+	`, p.TenantID, p.Enabled, expiresAt, p.UpdatedBy, p.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save protocol log settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Retries of statements that failed with a transient error
+const (
+	maxRetries     = 4
+	retryBaseDelay = 10 * time.Millisecond
+)
+
+// Reasons a statement is retried, recorded on db.client.retried_operations
+const (
+	retrySerialization = "serialization_failure"
+	retryDeadlock      = "deadlock"
+	retryConnection    = "connection"
+)
+
+// retryPool retries single statements that fail with a transient error. Each statement runs in its
+// own implicit transaction, so one aborted by a serialization failure (SQLSTATE 40001) or a deadlock
+// (40P01) was rolled back and running it again is safe. CockroachDB reports contention this way under
+// its default SERIALIZABLE isolation and expects clients to retry; PostgreSQL raises it only under
+// stricter isolation levels. A statement that never reached the server is retried as well; one whose
+// connection broke while it ran is retried only if it is a SELECT, because a write may have committed.
+//
+// Statements fail with ErrPoolExhausted, without waiting, while the breaker finds the pool exhausted.
+type retryPool struct {
+	*pgxpool.Pool
+	breaker *poolBreaker
+	retries retryPolicy
+}
+
+// Exec runs a statement, retrying on transient errors
+func (p retryPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if err := p.breaker.admit(ctx); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	var tag pgconn.CommandTag
+	err := p.retries.retry(ctx, idempotent(sql), func() error {
+		var err error
+		tag, err = p.Pool.Exec(ctx, sql, args...)
+		return err
+	})
+	return tag, p.breaker.exhausted(ctx, err)
+}
+
+// Query runs a query, retrying when it fails before returning rows
+func (p retryPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := p.breaker.admit(ctx); err != nil {
+		return nil, err
+	}
+	var rows pgx.Rows
+	err := p.retries.retry(ctx, idempotent(sql), func() error {
+		var err error
+		rows, err = p.Pool.Query(ctx, sql, args...)
+		return err
+	})
+	return rows, p.breaker.exhausted(ctx, err)
+}
+
+// QueryRow runs a query when the row is scanned, retrying on transient errors
+func (p retryPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return retryRow{pool: p, ctx: ctx, sql: sql, args: args}
+}
+
+// retryRow defers a QueryRow to Scan, where pgx reports the query's error
+type retryRow struct {
+	pool retryPool
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+// Scan runs the query and scans its first row into dest
+func (r retryRow) Scan(dest ...any) error {
+	if err := r.pool.breaker.admit(r.ctx); err != nil {
+		return err
+	}
+	err := r.pool.retries.retry(r.ctx, idempotent(r.sql), func() error {
+		return r.pool.Pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	})
+	return r.pool.breaker.exhausted(r.ctx, err)
+}
+
+// retryPolicy counts the statements it retried by reason and outcome: "recovered" when a retry
+// succeeded, "failed" when the statement still failed. The zero value records nothing.
+type retryPolicy struct {
+	retried metric.Int64Counter
+}
+
+// newRetryPolicy creates the retry metric on meter; a nil meter records none
+func newRetryPolicy(meter *metrics.Meter) (retryPolicy, error) {
+	var p retryPolicy
+	if meter == nil {
+		return p, nil
+	}
+	var err error
+	p.retried, err = meter.CreateCounter("db.client.retried_operations", "Database statements retried after a transient error, by reason and outcome")
+	return p, err
+}
+
+// retry calls fn until it succeeds, fails with an error that is not transient or runs out of
+// attempts, backing off exponentially with jitter in between. idempotent allows retrying after the
+// connection broke while fn ran.
+func (p retryPolicy) retry(ctx context.Context, idempotent bool, fn func() error) error {
+	delay := retryBaseDelay
+	var reason string
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			p.record(ctx, reason, "recovered")
+			return nil
+		}
+		next := transient(err, idempotent)
+		if next == "" || attempt == maxRetries {
+			p.record(ctx, reason, "failed")
+			return err
+		}
+		reason = next
+		select {
+		case <-ctx.Done():
+			p.record(ctx, reason, "failed")
+			return err
+		case <-time.After(delay + rand.N(delay)):
+		}
+		delay *= 2
+	}
+}
+
+// record counts a statement that was retried; reason is empty when it was not
+func (p retryPolicy) record(ctx context.Context, reason, outcome string) {
+	if reason == "" || p.retried == nil {
+		return
+	}
+	p.retried.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason), attribute.String("outcome", outcome)))
+}
+
+// transient returns why err may go away when the statement runs again, or "" if it will not or
+// running it again is unsafe. Context errors are never transient: the request gave up, or the
+// breaker bounded the wait for a connection.
+func transient(err error, idempotent bool) string {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ""
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "40001":
+			return retrySerialization
+		case pgErr.Code == "40P01":
+			return retryDeadlock
+		case idempotent && (strings.HasPrefix(pgErr.Code, "08") || pgErr.Code == "57P01"):
+			// Connection exception, or the server terminated the session
+			return retryConnection
+		}
+		return ""
+	}
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) || pgconn.SafeToRetry(err) {
+		return retryConnection
+	}
+	var netErr net.Error
+	if idempotent && (errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+		return retryConnection
+	}
+	return ""
+}
+
+// idempotent reports whether sql only reads, so running it twice is harmless
+func idempotent(sql string) bool {
+	const verb = "SELECT"
+	sql = strings.TrimLeftFunc(sql, unicode.IsSpace)
+	return len(sql) > len(verb) && strings.EqualFold(sql[:len(verb)], verb) && unicode.IsSpace(rune(sql[len(verb)]))
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// tenantSetting is the session setting the row-level security policies compare tenant_id with
+// (see migration 014). tenant.AllTenants makes every row visible; empty makes none visible.
+const tenantSetting = "app.tenant_id"
+
+// scopeConn sets app.tenant_id on a connection as it is acquired from the pool, to the scope of
+// the query's context (see tenant.ScopeFrom); a context without one gets empty and so no rows. The value is remembered on the connection so
+// queries in the same scope as the previous one cost no extra round trip. A connection whose
+// setting cannot be changed is discarded rather than used with a stale scope.
+func scopeConn(ctx context.Context, conn *pgx.Conn) bool {
+	want := tenant.ScopeFrom(ctx)
+	data := conn.PgConn().CustomData()
+	if current, ok := data[tenantSetting].(string); ok && current == want {
+		return true
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT set_config('"+tenantSetting+"', $1, false)", want); err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		slog.WarnContext(ctx, "failed to set tenant scope on connection",
+			logger.Component("postgres"),
+			logger.Error(err),
+		)
+		return false
+	}
+	data[tenantSetting] = want
+	return true
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// ScopeRepository implements oauth2.ScopeRepository
+type ScopeRepository struct {
+	db *DB
+}
+
+// NewScopeRepository creates a new custom scope repository
+func NewScopeRepository(db *DB) *ScopeRepository {
+	return &ScopeRepository{db: db}
+}
+
+const scopeColumns = `id, tenant_id, name, description, claims, permissions, created_at, updated_at`
+
+// Create stores a new scope; the unique (tenant_id, name) constraint rejects a duplicate name
+func (r *ScopeRepository) Create(ctx context.Context, scope *oauth2.ScopeDefinition) error {
+	result, err := r.db.pool.Exec(ctx, `
+		INSERT INTO oauth2_scopes (`+scopeColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, name) DO NOTHING
+	`, scope.ID, scope.TenantID, scope.Name, scope.Description, textArray(scope.Claims), textArray(scope.Permissions),
+		scope.CreatedAt, scope.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create scope: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return oauth2.ErrScopeAlreadyExists
+	}
+	return nil
+}
+
+// Get retrieves a tenant's scope by name
+func (r *ScopeRepository) Get(ctx context.Context, tenantID, name string) (*oauth2.ScopeDefinition, error) {
+	row := r.db.pool.QueryRow(ctx, `
+		SELECT `+scopeColumns+`
+		FROM oauth2_scopes
+		WHERE tenant_id = $1 AND name = $2
+	`, tenantID, name)
+
+	scope, err := scanScope(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, oauth2.ErrScopeNotFound
+		}
+		return nil, fmt.Errorf("failed to get scope: %w", err)
+	}
+	return scope, nil
+}
+
+// List retrieves all of a tenant's scopes ordered by name
+func (r *ScopeRepository) List(ctx context.Context, tenantID string) ([]*oauth2.ScopeDefinition, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+scopeColumns+`
+		FROM oauth2_scopes
+		WHERE tenant_id = $1
+		ORDER BY name
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scopes: %w", err)
+	}
+	defer rows.Close()
+
+	var scopes []*oauth2.ScopeDefinition
+	for rows.Next() {
+		scope, err := scanScope(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scope: %w", err)
+		}
+		scopes = append(scopes, scope)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list scopes: %w", err)
+	}
+
+	return scopes, nil
+}
+
+// Update replaces a scope's description, claims and permissions
+func (r *ScopeRepository) Update(ctx context.Context, scope *oauth2.ScopeDefinition) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE oauth2_scopes
+		SET description = $1, claims = $2, permissions = $3, updated_at = $4
+		WHERE tenant_id = $5 AND name = $6
+	`, scope.Description, textArray(scope.Claims), textArray(scope.Permissions), scope.UpdatedAt,
+		scope.TenantID, scope.Name)
+
+	if err != nil {
+		return fmt.Errorf("failed to update scope: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return oauth2.ErrScopeNotFound
+	}
+	return nil
+}
+
+// Delete removes a tenant's scope
+func (r *ScopeRepository) Delete(ctx context.Context, tenantID, name string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM oauth2_scopes WHERE tenant_id = $1 AND name = $2
+	`, tenantID, name)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete scope: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return oauth2.ErrScopeNotFound
+	}
+	return nil
+}
+
+func scanScope(s pgx.Row) (*oauth2.ScopeDefinition, error) {
+	var scope oauth2.ScopeDefinition
+	if err := s.Scan(&scope.ID, &scope.TenantID, &scope.Name, &scope.Description, &scope.Claims, &scope.Permissions,
+		&scope.CreatedAt, &scope.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &scope, nil
+}
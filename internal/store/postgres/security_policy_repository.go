@@ -0,0 +1,107 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// SecurityPolicyRepository implements tenant.SecurityPolicyRepository
+type SecurityPolicyRepository struct {
+	db *DB
+}
+
+// NewSecurityPolicyRepository creates a new tenant security policy repository
+func NewSecurityPolicyRepository(db *DB) *SecurityPolicyRepository {
+	return &SecurityPolicyRepository{db: db}
+}
+
+// Get retrieves a tenant's security policy
+func (r *SecurityPolicyRepository) Get(ctx context.Context, tenantID string) (*tenant.SecurityPolicy, error) {
+	var p tenant.SecurityPolicy
+	var lockoutSeconds, maxAgeSeconds int64
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT tenant_id, lockout_max_attempts, lockout_duration_seconds,
+			login_rps, login_burst, token_rps, token_burst, password_max_age_seconds, updated_by, updated_at
+		FROM tenant_security_policies
+		WHERE tenant_id = $1
+	`, tenantID).Scan(
+		&p.TenantID, &p.Settings.LockoutMaxAttempts, &lockoutSeconds,
+		&p.Settings.LoginRPS, &p.Settings.LoginBurst, &p.Settings.TokenRPS, &p.Settings.TokenBurst, &maxAgeSeconds,
+		&p.UpdatedBy, &p.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, tenant.ErrSecurityPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get security policy: %w", err)
+	}
+	p.Settings.LockoutDuration = time.Duration(lockoutSeconds) * time.Second
+	p.Settings.PasswordMaxAge = time.Duration(maxAgeSeconds) * time.Second
+	return &p, nil
+}
+
+// Upsert creates or replaces a tenant's security policy
+func (r *SecurityPolicyRepository) Upsert(ctx context.Context, p *tenant.SecurityPolicy) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO tenant_security_policies (
+			tenant_id, lockout_max_attempts, lockout_duration_seconds,
+			login_rps, login_burst, token_rps, token_burst, password_max_age_seconds, updated_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			lockout_max_attempts = EXCLUDED.lockout_max_attempts,
+			lockout_duration_seconds = EXCLUDED.lockout_duration_seconds,
+			login_rps = EXCLUDED.login_rps,
+			login_burst = EXCLUDED.login_burst,
+			token_rps = EXCLUDED.token_rps,
+			token_burst = EXCLUDED.token_burst,
+			password_max_age_seconds = EXCLUDED.password_max_age_seconds,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+	`,
+		p.TenantID, p.Settings.LockoutMaxAttempts, int64(p.Settings.LockoutDuration/time.Second),
+		p.Settings.LoginRPS, p.Settings.LoginBurst, p.Settings.TokenRPS, p.Settings.TokenBurst,
+		int64(p.Settings.PasswordMaxAge/time.Second),
+		p.UpdatedBy, p.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save security policy: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a tenant's security policy
+func (r *SecurityPolicyRepository) Delete(ctx context.Context, tenantID string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM tenant_security_policies WHERE tenant_id = $1
+	`, tenantID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete security policy: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return tenant.ErrSecurityPolicyNotFound
+	}
+
+	return nil
+}
@@ -34,15 +34,13 @@ func NewSessionRepository(db *DB) *SessionRepository {
 }
 
 // Create creates a new session
-func (r *SessionRepository) Create(sess *session.Session) error {
-	ctx := context.Background()
-
+func (r *SessionRepository) Create(ctx context.Context, sess *session.Session) error {
 	_, err := r.db.pool.Exec(ctx, `
-		INSERT INTO sessions (id, tenant_id, user_id, ip_address, user_agent, expires_at, created_at, last_seen_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO sessions (id, tenant_id, user_id, ip_address, user_agent, expires_at, created_at, last_seen_at, namespace, password_change_required)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`,
 		sess.ID, sess.TenantID, sess.UserID, sess.IPAddress, sess.UserAgent,
-		sess.ExpiresAt, sess.CreatedAt, sess.LastSeenAt,
+		sess.ExpiresAt, sess.CreatedAt, sess.LastSeenAt, sess.Namespace, sess.PasswordChangeRequired,
 	)
 
 	if err != nil {
@@ -53,18 +51,16 @@ func (r *SessionRepository) Create(sess *session.Session) error {
 }
 
 // Get retrieves a session by ID
-func (r *SessionRepository) Get(sessionID string) (*session.Session, error) {
-	ctx := context.Background()
-
+func (r *SessionRepository) Get(ctx context.Context, sessionID string) (*session.Session, error) {
 	var sess session.Session
 
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, tenant_id, user_id, ip_address, user_agent, expires_at, created_at, last_seen_at
+		SELECT id, tenant_id, user_id, ip_address, user_agent, expires_at, created_at, last_seen_at, namespace, password_change_required
 		FROM sessions
 		WHERE id = $1
 	`, sessionID).Scan(
 		&sess.ID, &sess.TenantID, &sess.UserID, &sess.IPAddress, &sess.UserAgent,
-		&sess.ExpiresAt, &sess.CreatedAt, &sess.LastSeenAt,
+		&sess.ExpiresAt, &sess.CreatedAt, &sess.LastSeenAt, &sess.Namespace, &sess.PasswordChangeRequired,
 	)
 
 	if err != nil {
@@ -77,14 +73,12 @@ func (r *SessionRepository) Get(sessionID string) (*session.Session, error) {
 	return &sess, nil
 }
 
-// Update updates session last seen time
-func (r *SessionRepository) Update(sess *session.Session) error {
-	ctx := context.Background()
-
+// Update updates the session's last seen time, address and user agent
+func (r *SessionRepository) Update(ctx context.Context, sess *session.Session) error {
 	result, err := r.db.pool.Exec(ctx, `
-		UPDATE sessions SET last_seen_at = $2
+		UPDATE sessions SET last_seen_at = $2, ip_address = $3, user_agent = $4
 		WHERE id = $1
-	`, sess.ID, sess.LastSeenAt)
+	`, sess.ID, sess.LastSeenAt, sess.IPAddress, sess.UserAgent)
 
 	if err != nil {
 		return fmt.Errorf("failed to update session: %w", err)
@@ -98,9 +92,7 @@ func (r *SessionRepository) Update(sess *session.Session) error {
 }
 
 // Delete deletes a session
-func (r *SessionRepository) Delete(sessionID string) error {
-	ctx := context.Background()
-
+func (r *SessionRepository) Delete(ctx context.Context, sessionID string) error {
 	_, err := r.db.pool.Exec(ctx, `
 		DELETE FROM sessions WHERE id = $1
 	`, sessionID)
@@ -113,9 +105,7 @@ func (r *SessionRepository) Delete(sessionID string) error {
 }
 
 // DeleteByUserID deletes all sessions for a user
-func (r *SessionRepository) DeleteByUserID(userID string) error {
-	ctx := context.Background()
-
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
 	_, err := r.db.pool.Exec(ctx, `
 		DELETE FROM sessions WHERE user_id = $1
 	`, userID)
@@ -127,17 +117,52 @@ func (r *SessionRepository) DeleteByUserID(userID string) error {
 	return nil
 }
 
-// DeleteExpired deletes all expired sessions
-func (r *SessionRepository) DeleteExpired() error {
-	ctx := context.Background()
+// DeleteByTenantID deletes up to limit sessions of a tenant and returns how many were removed
+func (r *SessionRepository) DeleteByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM sessions WHERE id IN (
+			SELECT id FROM sessions WHERE tenant_id = $1 LIMIT $2
+		)
+	`, tenantID, limit)
 
-	_, err := r.db.pool.Exec(ctx, `
-		DELETE FROM sessions WHERE expires_at < $1
-	`, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tenant sessions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// DeleteByClientID deletes up to limit sessions of users holding tokens issued to a client and
+// returns how many were removed
+func (r *SessionRepository) DeleteByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM sessions WHERE id IN (
+			SELECT id FROM sessions WHERE user_id IN (
+				SELECT user_id FROM access_tokens WHERE client_id = $1
+				UNION
+				SELECT user_id FROM refresh_tokens WHERE client_id = $1
+			) LIMIT $2
+		)
+	`, clientID, limit)
 
 	if err != nil {
-		return fmt.Errorf("failed to delete expired sessions: %w", err)
+		return 0, fmt.Errorf("failed to delete client sessions: %w", err)
 	}
 
-	return nil
+	return result.RowsAffected(), nil
+}
+
+// DeleteExpired deletes up to limit expired sessions and returns how many were removed
+func (r *SessionRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM sessions WHERE id IN (
+			SELECT id FROM sessions WHERE expires_at < $1 LIMIT $2
+		)
+	`, time.Now(), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
 }
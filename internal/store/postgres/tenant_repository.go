@@ -18,6 +18,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -130,21 +131,66 @@ func (r *TenantRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// List lists tenants
-func (r *TenantRepository) List(ctx context.Context, limit, offset int) ([]*tenant.Tenant, error) {
-	rows, err := r.db.pool.Query(ctx, `
+// List lists tenants using keyset pagination
+func (r *TenantRepository) List(ctx context.Context, opts tenant.ListOptions) (*tenant.ListResult, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = tenant.DefaultListLimit
+	}
+
+	sortColumn, direction, cmp := "created_at", "DESC", "<"
+	switch opts.Sort {
+	case tenant.SortCreatedAsc:
+		direction, cmp = "ASC", ">"
+	case tenant.SortNameAsc:
+		sortColumn, direction, cmp = "name", "ASC", ">"
+	case tenant.SortNameDesc:
+		sortColumn = "name"
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
+	args := []any{}
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if opts.Query != "" {
+		conditions = append(conditions, "name ILIKE "+addArg("%"+escapeLike(opts.Query)+"%"))
+	}
+	if opts.Status != "" {
+		conditions = append(conditions, "status = "+addArg(opts.Status))
+	}
+	if opts.Cursor != "" {
+		c, err := tenant.DecodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		var value any = c.Value
+		if sortColumn == "created_at" {
+			t, err := time.Parse(time.RFC3339Nano, c.Value)
+			if err != nil {
+				return nil, tenant.ErrInvalidCursor
+			}
+			value = t
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", sortColumn, cmp, addArg(value), addArg(c.ID)))
+	}
+
+	query := fmt.Sprintf(`
 		SELECT id, name, status, created_at, updated_at
 		FROM tenants
-		WHERE deleted_at IS NULL
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`, limit, offset)
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT %s
+	`, strings.Join(conditions, " AND "), sortColumn, direction, direction, addArg(opts.Limit+1))
+
+	rows, err := r.db.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tenants: %w", err)
 	}
 	defer rows.Close()
 
-	var tenants []*tenant.Tenant
+	tenants := []*tenant.Tenant{}
 	for rows.Next() {
 		var t tenant.Tenant
 		if err := rows.Scan(&t.ID, &t.Name, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
@@ -152,6 +198,69 @@ func (r *TenantRepository) List(ctx context.Context, limit, offset int) ([]*tena
 		}
 		tenants = append(tenants, &t)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	result := &tenant.ListResult{Tenants: tenants}
+	if len(tenants) > opts.Limit {
+		result.Tenants = tenants[:opts.Limit]
+		last := result.Tenants[opts.Limit-1]
+		value := last.Name
+		if sortColumn == "created_at" {
+			value = last.CreatedAt.Format(time.RFC3339Nano)
+		}
+		result.NextCursor = tenant.EncodeCursor(tenant.Cursor{Sort: opts.Sort, Value: value, ID: last.ID})
+	}
+
+	return result, nil
+}
+
+// escapeLike escapes LIKE/ILIKE wildcards in user-supplied search terms
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// CountByStatus counts the tenants that are not deleted, by status
+func (r *TenantRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT status, COUNT(*) FROM tenants WHERE deleted_at IS NULL GROUP BY status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenants: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var n int64
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant count: %w", err)
+		}
+		counts[status] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count tenants: %w", err)
+	}
+	return counts, nil
+}
+
+// PurgeDeleted permanently removes up to limit tenants soft-deleted before the given time.
+// Tenants that still have users are kept until those users are purged.
+func (r *TenantRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM tenants WHERE id IN (
+			SELECT id FROM tenants
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM users u WHERE u.tenant_id = tenants.id)
+			LIMIT $2
+		)
+	`, before, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted tenants: %w", err)
+	}
 
-	return tenants, nil
+	return result.RowsAffected(), nil
 }
@@ -17,9 +17,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/opentrusty/opentrusty/internal/authz"
 	"github.com/opentrusty/opentrusty/internal/tenant"
 )
 
@@ -54,7 +56,7 @@ func (r *TenantRoleRepository) AssignRole(ctx context.Context, role *tenant.Tena
 	roleID := MapTenantRole(role.Role)
 
 	var grantedBy sql.NullString
-	if role.GrantedBy != "" {
+	if role.GrantedBy != "" && role.GrantedBy != tenant.GranterSystem {
 		grantedBy = sql.NullString{String: role.GrantedBy, Valid: true}
 	}
 
@@ -90,6 +92,18 @@ func (r *TenantRoleRepository) RevokeRole(ctx context.Context, tenantID, userID,
 	return nil
 }
 
+// RevokeOwnerRole revokes an owner or admin role unless the user is the tenant's only owner
+func (r *TenantRoleRepository) RevokeOwnerRole(ctx context.Context, tenantID, userID, role string) error {
+	err := revokeUnlessLast(ctx, r.db, userID, MapTenantRole(role), "tenant", &tenantID)
+	switch {
+	case errors.Is(err, authz.ErrAssignmentNotFound):
+		return tenant.ErrRoleNotFound
+	case errors.Is(err, authz.ErrLastAssignment):
+		return tenant.ErrLastTenantOwner
+	}
+	return err
+}
+
 // GetUserRoles retrieves all roles a user has in a tenant
 func (r *TenantRoleRepository) GetUserRoles(ctx context.Context, tenantID, userID string) ([]*tenant.TenantUserRole, error) {
 	rows, err := r.db.pool.Query(ctx, `
@@ -110,6 +124,7 @@ func (r *TenantRoleRepository) GetUserRoles(ctx context.Context, tenantID, userI
 		if err := rows.Scan(&role.ID, &role.TenantID, &role.UserID, &role.Role, &role.GrantedAt, &grantedBy); err != nil {
 			return nil, fmt.Errorf("failed to scan role: %w", err)
 		}
+		role.GrantedBy = tenant.GranterSystem
 		if grantedBy.Valid {
 			role.GrantedBy = grantedBy.String
 		}
@@ -139,6 +154,7 @@ func (r *TenantRoleRepository) GetTenantUsers(ctx context.Context, tenantID stri
 		if err := rows.Scan(&role.ID, &role.TenantID, &role.UserID, &role.Role, &role.GrantedAt, &grantedBy); err != nil {
 			return nil, fmt.Errorf("failed to scan role: %w", err)
 		}
+		role.GrantedBy = tenant.GranterSystem
 		if grantedBy.Valid {
 			role.GrantedBy = grantedBy.String
 		}
@@ -0,0 +1,155 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Access and refresh tokens are range-partitioned by created_at into daily partitions
+// (migration 016). Partitions are created ahead of time, and a partition whose range has
+// passed is dropped as a whole once every token in it has expired, so expiry at high
+// volume costs a DROP TABLE rather than millions of row deletes.
+
+// tokenPartitionsAhead is how many days beyond today have a partition ready
+const tokenPartitionsAhead = 7
+
+// tokenPartition is a child table of a partitioned token table
+type tokenPartition struct {
+	name string
+	// upper is the exclusive upper bound of the partition's created_at range; nil for the default partition
+	upper *time.Time
+}
+
+// maintainTokenPartitions drops the past partitions of parent whose tokens have all expired and
+// creates the daily partitions up to tokenPartitionsAhead days from now. It returns the number of
+// tokens dropped. Replicas serialize on an advisory lock; a replica that cannot take it skips the step.
+func (db *DB) maintainTokenPartitions(ctx context.Context, parent string, now time.Time) (int64, error) {
+	if db.cockroach {
+		// Migration 016 is skipped on CockroachDB, so there are no partitions to maintain
+		return 0, nil
+	}
+	// created_at holds the application's wall clock, so days are counted on it
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Dropping runs on its own so that a partition that cannot be created does not hold it up
+	var dropped int64
+	err := db.withPartitionLock(ctx, parent, func(tx pgx.Tx, partitions []tokenPartition) error {
+		for _, p := range partitions {
+			// A partition whose range ended before today receives no new tokens
+			if p.upper == nil || p.upper.After(today) {
+				continue
+			}
+			name := pgx.Identifier{p.name}.Sanitize()
+			var live bool
+			var count int64
+			if err := tx.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM `+name+` WHERE expires_at >= $1), (SELECT count(*) FROM `+name+`)`, now,
+			).Scan(&live, &count); err != nil {
+				return fmt.Errorf("failed to inspect partition %s: %w", p.name, err)
+			}
+			if live {
+				continue
+			}
+			if _, err := tx.Exec(ctx, `DROP TABLE `+name); err != nil {
+				return fmt.Errorf("failed to drop partition %s: %w", p.name, err)
+			}
+			dropped += count
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = db.withPartitionLock(ctx, parent, func(tx pgx.Tx, partitions []tokenPartition) error {
+		// Extend the range contiguously from the last partition so partitions never overlap.
+		// This fails if the default partition already holds tokens for the next day.
+		next := today
+		for _, p := range partitions {
+			if p.upper != nil && p.upper.After(next) {
+				next = *p.upper
+			}
+		}
+		for end := today.AddDate(0, 0, tokenPartitionsAhead+1); next.Before(end); next = next.AddDate(0, 0, 1) {
+			name := fmt.Sprintf("%s_p%s", parent, next.Format("20060102"))
+			if _, err := tx.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+				pgx.Identifier{name}.Sanitize(), pgx.Identifier{parent}.Sanitize(),
+				next.Format(time.DateOnly), next.AddDate(0, 0, 1).Format(time.DateOnly),
+			)); err != nil {
+				return fmt.Errorf("failed to create partition %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	return dropped, err
+}
+
+// withPartitionLock runs fn in a transaction holding the partition maintenance lock of parent
+func (db *DB) withPartitionLock(ctx context.Context, parent string, fn func(tx pgx.Tx, partitions []tokenPartition) error) error {
+	return pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, "token_partitions:"+parent).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to lock %s partitions: %w", parent, err)
+		}
+		if !locked {
+			return nil
+		}
+		partitions, err := listTokenPartitions(ctx, tx, parent)
+		if err != nil {
+			return err
+		}
+		return fn(tx, partitions)
+	})
+}
+
+// listTokenPartitions returns the partitions of parent with their upper bounds
+func listTokenPartitions(ctx context.Context, tx pgx.Tx, parent string) ([]tokenPartition, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.relname,
+			substring(pg_get_expr(c.relpartbound, c.oid) FROM 'TO \(''([^'']+)''\)')::date
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass
+		ORDER BY c.relname
+	`, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions of %s: %w", parent, err)
+	}
+	defer rows.Close()
+
+	var partitions []tokenPartition
+	for rows.Next() {
+		var p tokenPartition
+		if err := rows.Scan(&p.name, &p.upper); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, rows.Err()
+}
+
+// expiredTokenTable returns the table of parent that DeleteExpired purges row by row: the default
+// partition, or the whole table when it is not partitioned
+func (db *DB) expiredTokenTable(parent string) string {
+	if db.cockroach {
+		return parent
+	}
+	return parent + "_default"
+}
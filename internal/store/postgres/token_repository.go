@@ -17,6 +17,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -35,22 +36,28 @@ func NewAccessTokenRepository(db *DB) *AccessTokenRepository {
 }
 
 // Create creates a new access token
-func (r *AccessTokenRepository) Create(token *oauth2.AccessToken) error {
-	ctx := context.Background()
-
+func (r *AccessTokenRepository) Create(ctx context.Context, token *oauth2.AccessToken) error {
 	var revokedAt sql.NullTime
 	if token.RevokedAt != nil {
 		revokedAt = sql.NullTime{Time: *token.RevokedAt, Valid: true}
 	}
 
+	var claims []byte
+	if len(token.Claims) > 0 {
+		var err error
+		if claims, err = json.Marshal(token.Claims); err != nil {
+			return fmt.Errorf("failed to marshal access token claims: %w", err)
+		}
+	}
+
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO access_tokens (
 			id, tenant_id, token_hash, client_id, user_id, 
-			scope, token_type, expires_at, revoked_at, is_revoked, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			scope, token_type, expires_at, revoked_at, is_revoked, created_at, claims
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`,
 		token.ID, token.TenantID, token.TokenHash, token.ClientID, token.UserID,
-		token.Scope, token.TokenType, token.ExpiresAt, revokedAt, token.IsRevoked, token.CreatedAt,
+		token.Scope, token.TokenType, token.ExpiresAt, revokedAt, token.IsRevoked, token.CreatedAt, claims,
 	)
 
 	if err != nil {
@@ -60,22 +67,30 @@ func (r *AccessTokenRepository) Create(token *oauth2.AccessToken) error {
 	return nil
 }
 
-// GetByTokenHash retrieves an access token
-func (r *AccessTokenRepository) GetByTokenHash(tokenHash string) (*oauth2.AccessToken, error) {
-	ctx := context.Background()
+// GetByTokenHash retrieves an access token issued within a tenant
+func (r *AccessTokenRepository) GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*oauth2.AccessToken, error) {
+	return r.get(ctx, "tenant_id = $1 AND token_hash = $2", tenantID, tokenHash)
+}
+
+// FindByTokenHash retrieves an access token issued within any tenant. Under a tenant scope row-level
+// security still hides other tenants' tokens.
+func (r *AccessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*oauth2.AccessToken, error) {
+	return r.get(ctx, "token_hash = $1", tokenHash)
+}
 
+func (r *AccessTokenRepository) get(ctx context.Context, where string, args ...any) (*oauth2.AccessToken, error) {
 	var token oauth2.AccessToken
 	var revokedAt sql.NullTime
+	var claims []byte
 
 	err := r.db.pool.QueryRow(ctx, `
 		SELECT 
 			id, tenant_id, token_hash, client_id, user_id, 
-			scope, token_type, expires_at, revoked_at, is_revoked, created_at
+			scope, token_type, expires_at, revoked_at, is_revoked, created_at, claims
 		FROM access_tokens
-		WHERE token_hash = $1
-	`, tokenHash).Scan(
+		WHERE `+where, args...).Scan(
 		&token.ID, &token.TenantID, &token.TokenHash, &token.ClientID, &token.UserID,
-		&token.Scope, &token.TokenType, &token.ExpiresAt, &revokedAt, &token.IsRevoked, &token.CreatedAt,
+		&token.Scope, &token.TokenType, &token.ExpiresAt, &revokedAt, &token.IsRevoked, &token.CreatedAt, &claims,
 	)
 
 	if err != nil {
@@ -88,18 +103,21 @@ func (r *AccessTokenRepository) GetByTokenHash(tokenHash string) (*oauth2.Access
 	if revokedAt.Valid {
 		token.RevokedAt = &revokedAt.Time
 	}
+	if claims != nil {
+		if err := json.Unmarshal(claims, &token.Claims); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal access token claims: %w", err)
+		}
+	}
 
 	return &token, nil
 }
 
 // Revoke revokes an access token
-func (r *AccessTokenRepository) Revoke(tokenHash string) error {
-	ctx := context.Background()
-
+func (r *AccessTokenRepository) Revoke(ctx context.Context, tenantID, tokenHash string) error {
 	result, err := r.db.pool.Exec(ctx, `
-		UPDATE access_tokens SET is_revoked = true, revoked_at = $2
-		WHERE token_hash = $1
-	`, tokenHash, time.Now())
+		UPDATE access_tokens SET is_revoked = true, revoked_at = $3
+		WHERE tenant_id = $1 AND token_hash = $2
+	`, tenantID, tokenHash, time.Now())
 
 	if err != nil {
 		return fmt.Errorf("failed to revoke access token: %w", err)
@@ -112,19 +130,42 @@ func (r *AccessTokenRepository) Revoke(tokenHash string) error {
 	return nil
 }
 
-// DeleteExpired deletes all expired access tokens
-func (r *AccessTokenRepository) DeleteExpired() error {
-	ctx := context.Background()
+// RevokeByUserID revokes every unrevoked access token of a user and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE access_tokens SET is_revoked = true, revoked_at = $2
+		WHERE user_id = $1 AND is_revoked = false
+	`, userID, time.Now())
 
-	_, err := r.db.pool.Exec(ctx, `
-		DELETE FROM access_tokens WHERE expires_at < $1
-	`, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// DeleteExpired deletes up to limit expired access tokens from the default partition and returns how many
+// were removed. Tokens in the daily partitions are removed with their partition by MaintainPartitions.
+// On CockroachDB the table is not partitioned and all expired tokens are deleted here.
+func (r *AccessTokenRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	table := r.db.expiredTokenTable("access_tokens")
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM `+table+` WHERE id IN (
+			SELECT id FROM `+table+` WHERE expires_at < $1 LIMIT $2
+		)
+	`, time.Now(), limit)
 
 	if err != nil {
-		return fmt.Errorf("failed to delete expired access tokens: %w", err)
+		return 0, fmt.Errorf("failed to delete expired access tokens: %w", err)
 	}
 
-	return nil
+	return result.RowsAffected(), nil
+}
+
+// MaintainPartitions drops the daily partitions whose access tokens have all expired and creates the
+// partitions for the coming days. It returns the number of tokens dropped.
+func (r *AccessTokenRepository) MaintainPartitions(ctx context.Context) (int64, error) {
+	return r.db.maintainTokenPartitions(ctx, "access_tokens", time.Now())
 }
 
 // RefreshTokenRepository implements oauth2.RefreshTokenRepository
@@ -138,9 +179,7 @@ func NewRefreshTokenRepository(db *DB) *RefreshTokenRepository {
 }
 
 // Create creates a new refresh token
-func (r *RefreshTokenRepository) Create(token *oauth2.RefreshToken) error {
-	ctx := context.Background()
-
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *oauth2.RefreshToken) error {
 	var revokedAt sql.NullTime
 	if token.RevokedAt != nil {
 		revokedAt = sql.NullTime{Time: *token.RevokedAt, Valid: true}
@@ -168,10 +207,8 @@ func (r *RefreshTokenRepository) Create(token *oauth2.RefreshToken) error {
 	return nil
 }
 
-// GetByTokenHash retrieves a refresh token
-func (r *RefreshTokenRepository) GetByTokenHash(tokenHash string) (*oauth2.RefreshToken, error) {
-	ctx := context.Background()
-
+// GetByTokenHash retrieves a refresh token issued within a tenant
+func (r *RefreshTokenRepository) GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*oauth2.RefreshToken, error) {
 	var token oauth2.RefreshToken
 	var revokedAt sql.NullTime
 	var accessTokenID sql.NullString
@@ -181,8 +218,8 @@ func (r *RefreshTokenRepository) GetByTokenHash(tokenHash string) (*oauth2.Refre
 			id, tenant_id, token_hash, access_token_id, client_id, user_id, 
 			scope, expires_at, revoked_at, is_revoked, created_at
 		FROM refresh_tokens
-		WHERE token_hash = $1
-	`, tokenHash).Scan(
+		WHERE tenant_id = $1 AND token_hash = $2
+	`, tenantID, tokenHash).Scan(
 		&token.ID, &token.TenantID, &token.TokenHash, &accessTokenID, &token.ClientID, &token.UserID,
 		&token.Scope, &token.ExpiresAt, &revokedAt, &token.IsRevoked, &token.CreatedAt,
 	)
@@ -205,13 +242,11 @@ func (r *RefreshTokenRepository) GetByTokenHash(tokenHash string) (*oauth2.Refre
 }
 
 // Revoke revokes a refresh token
-func (r *RefreshTokenRepository) Revoke(tokenHash string) error {
-	ctx := context.Background()
-
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, tenantID, tokenHash string) error {
 	result, err := r.db.pool.Exec(ctx, `
-		UPDATE refresh_tokens SET is_revoked = true, revoked_at = $2
-		WHERE token_hash = $1
-	`, tokenHash, time.Now())
+		UPDATE refresh_tokens SET is_revoked = true, revoked_at = $3
+		WHERE tenant_id = $1 AND token_hash = $2
+	`, tenantID, tokenHash, time.Now())
 
 	if err != nil {
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
@@ -224,17 +259,77 @@ func (r *RefreshTokenRepository) Revoke(tokenHash string) error {
 	return nil
 }
 
-// DeleteExpired deletes all expired refresh tokens
-func (r *RefreshTokenRepository) DeleteExpired() error {
-	ctx := context.Background()
+// RevokeByUserID revokes every unrevoked refresh token of a user and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE refresh_tokens SET is_revoked = true, revoked_at = $2
+		WHERE user_id = $1 AND is_revoked = false
+	`, userID, time.Now())
 
-	_, err := r.db.pool.Exec(ctx, `
-		DELETE FROM refresh_tokens WHERE expires_at < $1
-	`, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// DeleteExpired deletes up to limit expired refresh tokens from the default partition and returns how many
+// were removed. Tokens in the daily partitions are removed with their partition by MaintainPartitions.
+// On CockroachDB the table is not partitioned and all expired tokens are deleted here.
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	table := r.db.expiredTokenTable("refresh_tokens")
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM `+table+` WHERE id IN (
+			SELECT id FROM `+table+` WHERE expires_at < $1 LIMIT $2
+		)
+	`, time.Now(), limit)
 
 	if err != nil {
-		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
 	}
 
-	return nil
+	return result.RowsAffected(), nil
+}
+
+// MaintainPartitions drops the daily partitions whose refresh tokens have all expired and creates the
+// partitions for the coming days. It returns the number of tokens dropped.
+func (r *RefreshTokenRepository) MaintainPartitions(ctx context.Context) (int64, error) {
+	return r.db.maintainTokenPartitions(ctx, "refresh_tokens", time.Now())
+}
+
+// RevokeByTenantID revokes up to limit unexpired, unrevoked access tokens of a tenant and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "access_tokens", "tenant_id", tenantID, limit)
+}
+
+// RevokeByClientID revokes up to limit unexpired, unrevoked access tokens issued to a client and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "access_tokens", "client_id", clientID, limit)
+}
+
+// RevokeByTenantID revokes up to limit unexpired, unrevoked refresh tokens of a tenant and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "refresh_tokens", "tenant_id", tenantID, limit)
+}
+
+// RevokeByClientID revokes up to limit unexpired, unrevoked refresh tokens issued to a client and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "refresh_tokens", "client_id", clientID, limit)
+}
+
+// revokeTokens revokes up to limit unexpired, unrevoked tokens of table whose column equals value
+// and returns how many were revoked
+func (db *DB) revokeTokens(ctx context.Context, table, column, value string, limit int) (int64, error) {
+	result, err := db.pool.Exec(ctx, `
+		UPDATE `+table+` SET is_revoked = true, revoked_at = $2
+		WHERE id IN (
+			SELECT id FROM `+table+` WHERE `+column+` = $1 AND is_revoked = false AND expires_at > $2 LIMIT $3
+		)
+	`, value, time.Now(), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke %s: %w", table, err)
+	}
+
+	return result.RowsAffected(), nil
 }
@@ -0,0 +1,213 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/observability/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer starts a span for every Query, QueryRow and Exec on the pool, counts and times the
+// query per repository method and logs queries slower than slowQuery. Only the SQL text is recorded
+// as is: arguments carry password hashes, token hashes and other secrets, so slow-query logs show
+// them redacted. The zero value only traces.
+type queryTracer struct {
+	queries   metric.Int64Counter
+	duration  metric.Float64Histogram
+	slowQuery time.Duration
+}
+
+var _ pgx.QueryTracer = queryTracer{}
+
+// connTracer is the pool's tracer: queryTracer for statements and poolBreaker for acquires
+type connTracer struct {
+	queryTracer
+	*poolBreaker
+}
+
+// newQueryTracer creates the query metrics on meter; a nil meter records none
+func newQueryTracer(meter *metrics.Meter, slowQuery time.Duration) (queryTracer, error) {
+	tracer := queryTracer{slowQuery: slowQuery}
+	if meter == nil {
+		return tracer, nil
+	}
+	var err error
+	tracer.queries, err = meter.CreateCounter("db.client.queries", "Database queries by repository method and status")
+	if err != nil {
+		return tracer, err
+	}
+	tracer.duration, err = meter.CreateHistogram("db.client.query.duration", "Database query latency by repository method", "ms")
+	if err != nil {
+		return tracer, err
+	}
+	return tracer, nil
+}
+
+// queryStartKey holds the queryStart of the query in flight
+type queryStartKey struct{}
+
+// queryStart is what TraceQueryEnd needs to know about the query it ends
+type queryStart struct {
+	method    string
+	statement string
+	args      []any
+	at        time.Time
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	statement := strings.Join(strings.Fields(data.SQL), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+	method := repositoryMethod(data.SQL)
+	ctx, _ = tracing.StartSpan(ctx, "postgres."+operation,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", operation),
+		attribute.String("db.statement", statement),
+		attribute.String("code.function", method),
+	)
+	return context.WithValue(ctx, queryStartKey{}, queryStart{method: method, statement: statement, args: data.Args, at: time.Now()})
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A missing row is an answer, not a failure
+		err = nil
+	}
+	tracing.EndSpan(span, err)
+
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.at)
+	if t.queries != nil {
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		t.queries.Add(ctx, 1, metric.WithAttributes(attribute.String("db.method", start.method), attribute.String("status", status)))
+		t.duration.Record(ctx, float64(elapsed.Microseconds())/1000, metric.WithAttributes(attribute.String("db.method", start.method)))
+	}
+	if t.slowQuery > 0 && elapsed >= t.slowQuery {
+		slog.WarnContext(ctx, "slow query",
+			logger.Component("postgres"),
+			logger.Operation(start.method),
+			logger.Query(start.statement),
+			slog.Any("args", redactArgs(start.args)),
+			logger.Duration(elapsed.Milliseconds()),
+			logger.RowsAffected(data.CommandTag.RowsAffected()),
+			logger.Error(err),
+		)
+	}
+}
+
+// packagePrefix is the prefix of this package's function names in stack frames
+var packagePrefix = reflect.TypeOf(DB{}).PkgPath() + "."
+
+// repositoryMethods caches the method found for each SQL text. A statement is issued from one
+// method, and statements built from filters have a bounded number of shapes.
+var repositoryMethods sync.Map
+
+// repositoryMethod returns the repository method, such as UserRepository.GetByEmail, that issued
+// sql: the innermost function of this package on the stack other than the retry wrappers
+func repositoryMethod(sql string) string {
+	if method, ok := repositoryMethods.Load(sql); ok {
+		return method.(string)
+	}
+	method := "unknown"
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, packagePrefix); ok && !strings.HasPrefix(name, "retry") {
+			method = functionName(name)
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	repositoryMethods.Store(sql, method)
+	return method
+}
+
+// functionName shortens a function name from a stack frame: (*UserRepository).GetByEmail.func1
+// becomes UserRepository.GetByEmail and scopeConn stays scopeConn
+func functionName(name string) string {
+	if rest, ok := strings.CutPrefix(name, "(*"); ok {
+		receiver, fn, _ := strings.Cut(rest, ").")
+		fn, _, _ = strings.Cut(fn, ".")
+		return receiver + "." + fn
+	}
+	fn, _, _ := strings.Cut(name, ".")
+	return fn
+}
+
+// uuidPattern matches the identifiers the repositories bind, which are safe to log
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// redactArgs renders query arguments for a log line. Numbers, booleans, times and UUIDs are kept;
+// any other value, such as an email address, a hash or a serialized document, is redacted.
+func redactArgs(args []any) []string {
+	rendered := make([]string, len(args))
+	for i, arg := range args {
+		rendered[i] = redactArg(arg)
+	}
+	return rendered
+}
+
+func redactArg(arg any) string {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case string:
+		if uuidPattern.MatchString(v) {
+			return v
+		}
+	}
+	// Pointers to kept types are rendered like their value
+	if rv := reflect.ValueOf(arg); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return redactArg(rv.Elem().Interface())
+	}
+	return "[REDACTED]"
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestPurpose: Validates that the pgx query tracer names spans after the SQL operation and omits query arguments.
+// Scope: Unit Test
+// Security: Observability (bound arguments such as token hashes never reach the trace backend)
+// Expected: Spans carry the normalized statement but no argument values; ErrNoRows is not reported as a failure, other errors are.
+// Test Case ID: STO-11
+func TestPostgres_QueryTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	tracer := queryTracer{}
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "select id\n\t\tFROM access_tokens\n\t\tWHERE token_hash = $1",
+		Args: []any{"secret-token-hash"},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "UPDATE users SET locked_until = $1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 0"), Err: errors.New("deadlock detected")})
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	selectSpan := spans[0]
+	if selectSpan.Name() != "postgres.SELECT" {
+		t.Errorf("expected span postgres.SELECT, got %s", selectSpan.Name())
+	}
+	if selectSpan.Status().Code == codes.Error {
+		t.Error("expected ErrNoRows not to fail the span")
+	}
+	for _, attr := range selectSpan.Attributes() {
+		if attr.Key == "db.statement" && attr.Value.AsString() != "select id FROM access_tokens WHERE token_hash = $1" {
+			t.Errorf("unexpected statement %q", attr.Value.AsString())
+		}
+		if attr.Value.AsString() == "secret-token-hash" {
+			t.Errorf("span attribute %s leaks a query argument", attr.Key)
+		}
+	}
+
+	if spans[1].Name() != "postgres.UPDATE" || spans[1].Status().Code != codes.Error {
+		t.Errorf("expected failed postgres.UPDATE span, got %s (%v)", spans[1].Name(), spans[1].Status().Code)
+	}
+}
+
+// fakeRepository issues queries through the tracer like a repository method
+type fakeRepository struct {
+	tracer queryTracer
+}
+
+func (r *fakeRepository) Lookup(ctx context.Context, err error, args ...any) {
+	ctx = r.tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT id FROM users WHERE email = $1 AND tenant_id = $2", Args: args})
+	r.tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+}
+
+// TestPurpose: Validates that queries are counted and timed per repository method and that slow queries are logged with their arguments redacted.
+// Scope: Unit Test
+// Security: Observability (slow-query logs must not leak emails, hashes or other bound secrets)
+// Expected: Calls and errors are counted under the issuing method with a latency sample each; the slow-query log keeps numbers, UUIDs and NULLs and redacts strings.
+// Test Case ID: STO-16
+func TestPostgres_QueryMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer, err := newQueryTracer(meter, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &fakeRepository{tracer: tracer}
+
+	ctx := context.Background()
+	var lockedUntil *time.Time
+	repo.Lookup(ctx, nil, "user@example.com", "0190f5e4-8a5c-7d6e-9f00-123456789abc", 5, lockedUntil)
+	repo.Lookup(ctx, pgx.ErrNoRows)
+	repo.Lookup(ctx, errors.New("connection reset"))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	calls := map[attribute.Distinct]int64{}
+	var samples uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					calls[dp.Attributes.Equivalent()] = dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					method, _ := dp.Attributes.Value("db.method")
+					if method.AsString() == "fakeRepository.Lookup" {
+						samples += dp.Count
+					}
+				}
+			}
+		}
+	}
+	for status, want := range map[string]int64{"ok": 2, "error": 1} {
+		set := attribute.NewSet(attribute.String("db.method", "fakeRepository.Lookup"), attribute.String("status", status))
+		if got := calls[set.Equivalent()]; got != want {
+			t.Errorf("expected %d %s calls, got %d", want, status, got)
+		}
+	}
+	if samples != 3 {
+		t.Errorf("expected 3 latency samples, got %d", samples)
+	}
+
+	line, _, _ := strings.Cut(logs.String(), "\n")
+	if !strings.Contains(line, `"msg":"slow query"`) || !strings.Contains(line, `"operation":"fakeRepository.Lookup"`) {
+		t.Errorf("unexpected slow-query log %s", line)
+	}
+	if strings.Contains(line, "user@example.com") {
+		t.Errorf("slow-query log leaks an argument: %s", line)
+	}
+	if !strings.Contains(line, `"args":["[REDACTED]","0190f5e4-8a5c-7d6e-9f00-123456789abc","5","NULL"]`) {
+		t.Errorf("unexpected arguments in %s", line)
+	}
+}
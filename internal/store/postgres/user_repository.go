@@ -22,6 +22,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/tenant"
 )
 
 // UserRepository implements identity.UserRepository
@@ -35,19 +36,25 @@ func NewUserRepository(db *DB) *UserRepository {
 }
 
 // Create creates a new user identity
-func (r *UserRepository) Create(user *identity.User) error {
-	ctx := context.Background()
+func (r *UserRepository) Create(ctx context.Context, user *identity.User) error {
 	now := time.Now()
+	// The user becomes a member of their home tenant in the same statement; CockroachDB has no triggers
 	_, err := r.db.pool.Exec(ctx, `
-		INSERT INTO users (
-			id, tenant_id, email, email_verified,
-			given_name, family_name, full_name, nickname, picture, locale, timezone,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		WITH u AS (
+			INSERT INTO users (
+				id, tenant_id, email, email_verified,
+				given_name, family_name, full_name, nickname, picture, locale, timezone, website, birthdate,
+				created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			RETURNING id, tenant_id, created_at
+		)
+		INSERT INTO tenant_memberships (id, tenant_id, user_id, is_home, created_at)
+		SELECT id, tenant_id, id, TRUE, created_at FROM u WHERE tenant_id IS NOT NULL
 	`,
 		user.ID, user.TenantID, user.Email, user.EmailVerified,
 		user.Profile.GivenName, user.Profile.FamilyName, user.Profile.FullName,
 		user.Profile.Nickname, user.Profile.Picture, user.Profile.Locale, user.Profile.Timezone,
+		user.Profile.Website, user.Profile.Birthdate,
 		now, now,
 	)
 	if err != nil {
@@ -56,13 +63,13 @@ func (r *UserRepository) Create(user *identity.User) error {
 
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
 
 	return nil
 }
 
 // AddCredentials adds credentials for a user
-func (r *UserRepository) AddCredentials(credentials *identity.Credentials) error {
-	ctx := context.Background()
+func (r *UserRepository) AddCredentials(ctx context.Context, credentials *identity.Credentials) error {
 	now := time.Now()
 
 	_, err := r.db.pool.Exec(ctx, `
@@ -79,23 +86,22 @@ func (r *UserRepository) AddCredentials(credentials *identity.Credentials) error
 }
 
 // GetByID retrieves a user by ID
-func (r *UserRepository) GetByID(id string) (*identity.User, error) {
-	ctx := context.Background()
-
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*identity.User, error) {
 	var user identity.User
 	var deletedAt sql.NullTime
 
 	err := r.db.pool.QueryRow(ctx, `
 		SELECT id, tenant_id, email, email_verified,
-			given_name, family_name, full_name, nickname, picture, locale, timezone,
-			created_at, updated_at, deleted_at
+			given_name, family_name, full_name, nickname, picture, locale, timezone, website, birthdate,
+			created_at, updated_at, version, deleted_at
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(
 		&user.ID, &user.TenantID, &user.Email, &user.EmailVerified,
 		&user.Profile.GivenName, &user.Profile.FamilyName, &user.Profile.FullName,
 		&user.Profile.Nickname, &user.Profile.Picture, &user.Profile.Locale, &user.Profile.Timezone,
-		&user.CreatedAt, &user.UpdatedAt, &deletedAt,
+		&user.Profile.Website, &user.Profile.Birthdate,
+		&user.CreatedAt, &user.UpdatedAt, &user.Version, &deletedAt,
 	)
 
 	if err != nil {
@@ -113,23 +119,22 @@ func (r *UserRepository) GetByID(id string) (*identity.User, error) {
 }
 
 // GetByEmail retrieves a user by email within a tenant (or no tenant for Platform Admins)
-func (r *UserRepository) GetByEmail(tenantID *string, email string) (*identity.User, error) {
-	ctx := context.Background()
-
+func (r *UserRepository) GetByEmail(ctx context.Context, tenantID *string, email string) (*identity.User, error) {
 	var user identity.User
 	var deletedAt sql.NullTime
 
 	err := r.db.pool.QueryRow(ctx, `
 		SELECT id, tenant_id, email, email_verified,
-			given_name, family_name, full_name, nickname, picture, locale, timezone,
-			created_at, updated_at, deleted_at
+			given_name, family_name, full_name, nickname, picture, locale, timezone, website, birthdate,
+			created_at, updated_at, version, deleted_at
 		FROM users
 		WHERE tenant_id IS NOT DISTINCT FROM $1 AND email = $2 AND deleted_at IS NULL
 	`, tenantID, email).Scan(
 		&user.ID, &user.TenantID, &user.Email, &user.EmailVerified,
 		&user.Profile.GivenName, &user.Profile.FamilyName, &user.Profile.FullName,
 		&user.Profile.Nickname, &user.Profile.Picture, &user.Profile.Locale, &user.Profile.Timezone,
-		&user.CreatedAt, &user.UpdatedAt, &deletedAt,
+		&user.Profile.Website, &user.Profile.Birthdate,
+		&user.CreatedAt, &user.UpdatedAt, &user.Version, &deletedAt,
 	)
 
 	if err != nil {
@@ -146,10 +151,45 @@ func (r *UserRepository) GetByEmail(tenantID *string, email string) (*identity.U
 	return &user, nil
 }
 
-// Update updates user information
-func (r *UserRepository) Update(user *identity.User) error {
-	ctx := context.Background()
+// ListByEmail retrieves the users registered with email in any tenant, platform admins
+// included, oldest first
+func (r *UserRepository) ListByEmail(ctx context.Context, email string) ([]*identity.User, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, tenant_id, email, email_verified,
+			given_name, family_name, full_name, nickname, picture, locale, timezone, website, birthdate,
+			created_at, updated_at, version
+		FROM users
+		WHERE email = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*identity.User
+	for rows.Next() {
+		var user identity.User
+		if err := rows.Scan(
+			&user.ID, &user.TenantID, &user.Email, &user.EmailVerified,
+			&user.Profile.GivenName, &user.Profile.FamilyName, &user.Profile.FullName,
+			&user.Profile.Nickname, &user.Profile.Picture, &user.Profile.Locale, &user.Profile.Timezone,
+			&user.Profile.Website, &user.Profile.Birthdate,
+			&user.CreatedAt, &user.UpdatedAt, &user.Version,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
 
+// Update updates user information
+func (r *UserRepository) Update(ctx context.Context, user *identity.User) error {
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE users SET
 			email = $3,
@@ -160,12 +200,18 @@ func (r *UserRepository) Update(user *identity.User) error {
 			nickname = $8,
 			picture = $9,
 			locale = $10,
-			timezone = $11
-		WHERE id = $1 AND tenant_id IS NOT DISTINCT FROM $2 AND deleted_at IS NULL
+			timezone = $11,
+			website = $12,
+			birthdate = $13,
+			updated_at = CURRENT_TIMESTAMP,
+			version = version + 1
+		WHERE id = $1 AND tenant_id IS NOT DISTINCT FROM $2 AND version = $14 AND deleted_at IS NULL
 	`,
 		user.ID, user.TenantID, user.Email, user.EmailVerified,
 		user.Profile.GivenName, user.Profile.FamilyName, user.Profile.FullName,
 		user.Profile.Nickname, user.Profile.Picture, user.Profile.Locale, user.Profile.Timezone,
+		user.Profile.Website, user.Profile.Birthdate,
+		user.Version,
 	)
 
 	if err != nil {
@@ -173,20 +219,33 @@ func (r *UserRepository) Update(user *identity.User) error {
 	}
 
 	if result.RowsAffected() == 0 {
+		// Distinguish a stale version from a missing user
+		var exists bool
+		if err := r.db.pool.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM users WHERE id = $1 AND tenant_id IS NOT DISTINCT FROM $2 AND deleted_at IS NULL
+			)
+		`, user.ID, user.TenantID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if exists {
+			return identity.ErrUserVersionConflict
+		}
 		return identity.ErrUserNotFound
 	}
+	user.Version++
 
 	return nil
 }
 
 // UpdateLockout updates user lockout status
-func (r *UserRepository) UpdateLockout(userID string, failedAttempts int, lockedUntil *time.Time) error {
+func (r *UserRepository) UpdateLockout(ctx context.Context, userID string, failedAttempts int, lockedUntil *time.Time) error {
 	query := `
 		UPDATE users
 		SET failed_login_attempts = $1, locked_until = $2, updated_at = NOW()
 		WHERE id = $3
 	`
-	_, err := r.db.pool.Exec(context.Background(), query, failedAttempts, lockedUntil, userID)
+	_, err := r.db.pool.Exec(ctx, query, failedAttempts, lockedUntil, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update user lockout status: %w", err)
 	}
@@ -194,9 +253,7 @@ func (r *UserRepository) UpdateLockout(userID string, failedAttempts int, locked
 }
 
 // Delete soft-deletes a user
-func (r *UserRepository) Delete(id string) error {
-	ctx := context.Background()
-
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE users SET deleted_at = $2
 		WHERE id = $1 AND deleted_at IS NULL
@@ -214,16 +271,14 @@ func (r *UserRepository) Delete(id string) error {
 }
 
 // GetCredentials retrieves user credentials
-func (r *UserRepository) GetCredentials(userID string) (*identity.Credentials, error) {
-	ctx := context.Background()
-
+func (r *UserRepository) GetCredentials(ctx context.Context, userID string) (*identity.Credentials, error) {
 	var creds identity.Credentials
 
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT user_id, password_hash, updated_at
+		SELECT user_id, password_hash, updated_at, expires_at
 		FROM credentials
 		WHERE user_id = $1
-	`, userID).Scan(&creds.UserID, &creds.PasswordHash, &creds.UpdatedAt)
+	`, userID).Scan(&creds.UserID, &creds.PasswordHash, &creds.UpdatedAt, &creds.ExpiresAt)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -235,12 +290,10 @@ func (r *UserRepository) GetCredentials(userID string) (*identity.Credentials, e
 	return &creds, nil
 }
 
-// UpdatePassword updates user password
-func (r *UserRepository) UpdatePassword(userID string, passwordHash string) error {
-	ctx := context.Background()
-
+// UpdatePassword updates user password and clears its expiry
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
 	result, err := r.db.pool.Exec(ctx, `
-		UPDATE credentials SET password_hash = $2
+		UPDATE credentials SET password_hash = $2, updated_at = NOW(), expires_at = NULL
 		WHERE user_id = $1
 	`, userID, passwordHash)
 
@@ -254,3 +307,75 @@ func (r *UserRepository) UpdatePassword(userID string, passwordHash string) erro
 
 	return nil
 }
+
+// ExpirePassword marks user password as expired from the given time
+func (r *UserRepository) ExpirePassword(ctx context.Context, userID string, at time.Time) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE credentials SET expires_at = $2
+		WHERE user_id = $1
+	`, userID, at)
+
+	if err != nil {
+		return fmt.Errorf("failed to expire password: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return identity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Erase anonymizes a user and removes their credentials, role assignments and memberships in one
+// transaction. It runs without a tenant scope, as a user is erased from every tenant they belong to.
+func (r *UserRepository) Erase(ctx context.Context, userID, anonEmail string, at time.Time) error {
+	ctx = tenant.WithoutScope(ctx)
+	return pgx.BeginFunc(ctx, r.db.pool, func(tx pgx.Tx) error {
+		for _, table := range []string{"credentials", "rbac_assignments", "tenant_memberships", "project_members"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", table, err)
+			}
+		}
+
+		result, err := tx.Exec(ctx, `
+			UPDATE users SET
+				email = $2, email_verified = FALSE,
+				given_name = '', family_name = '', full_name = '',
+				nickname = '', picture = '', locale = '', timezone = '',
+				website = '', birthdate = '',
+				failed_login_attempts = 0, locked_until = NULL,
+				updated_at = $3, deleted_at = COALESCE(deleted_at, $3),
+				version = version + 1
+			WHERE id = $1
+		`, userID, anonEmail, at)
+
+		if err != nil {
+			return fmt.Errorf("failed to erase user: %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return identity.ErrUserNotFound
+		}
+
+		return nil
+	})
+}
+
+// PurgeDeleted permanently removes up to limit users soft-deleted before the given time.
+// Users recorded as the granter of a role assignment are kept so the grant stays attributable.
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM users WHERE id IN (
+			SELECT id FROM users
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM rbac_assignments a WHERE a.granted_by = users.id)
+			LIMIT $2
+		)
+	`, before, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// WebhookSubscriptionRepository implements webhook.SubscriptionRepository
+type WebhookSubscriptionRepository struct {
+	db *DB
+}
+
+// NewWebhookSubscriptionRepository creates a new webhook subscription repository
+func NewWebhookSubscriptionRepository(db *DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `id, tenant_id, url, description, event_types, secret_encrypted, enabled, created_by, created_at, updated_at`
+
+// Create stores a new subscription
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, sub *webhook.Subscription) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO webhook_subscriptions (`+webhookSubscriptionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, sub.ID, sub.TenantID, sub.URL, sub.Description, textArray(sub.EventTypes), sub.SecretEncrypted, sub.Enabled,
+		sub.CreatedBy, sub.CreatedAt, sub.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a tenant's subscription by ID
+func (r *WebhookSubscriptionRepository) Get(ctx context.Context, tenantID, id string) (*webhook.Subscription, error) {
+	row := r.db.pool.QueryRow(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id)
+
+	sub, err := scanWebhookSubscription(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, webhook.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// List retrieves all of a tenant's subscriptions ordered by ID
+func (r *WebhookSubscriptionRepository) List(ctx context.Context, tenantID string) ([]*webhook.Subscription, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE tenant_id = $1
+		ORDER BY id
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*webhook.Subscription
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// Update replaces a subscription's settings and secret
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, sub *webhook.Subscription) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $1, description = $2, event_types = $3, secret_encrypted = $4, enabled = $5, updated_at = $6
+		WHERE tenant_id = $7 AND id = $8
+	`, sub.URL, sub.Description, textArray(sub.EventTypes), sub.SecretEncrypted, sub.Enabled, sub.UpdatedAt,
+		sub.TenantID, sub.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// Delete removes a subscription; its deliveries are removed by the foreign key cascade
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM webhook_subscriptions WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// textArray maps a nil list to an empty array for NOT NULL array columns
+func textArray(types []string) []string {
+	if types == nil {
+		return []string{}
+	}
+	return types
+}
+
+func scanWebhookSubscription(s pgx.Row) (*webhook.Subscription, error) {
+	var sub webhook.Subscription
+	if err := s.Scan(&sub.ID, &sub.TenantID, &sub.URL, &sub.Description, &sub.EventTypes, &sub.SecretEncrypted,
+		&sub.Enabled, &sub.CreatedBy, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// WebhookDeliveryRepository implements webhook.DeliveryRepository
+type WebhookDeliveryRepository struct {
+	db *DB
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+const webhookDeliveryColumns = `id, subscription_id, tenant_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_attempt_at, response_status, last_error, created_at, updated_at`
+
+// Create stores a new delivery
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d *webhook.Delivery) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, d.ID, d.SubscriptionID, d.TenantID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts,
+		d.NextAttemptAt, d.LastAttemptAt, d.ResponseStatus, d.LastError,
+		d.CreatedAt, d.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a tenant's delivery by ID
+func (r *WebhookDeliveryRepository) Get(ctx context.Context, tenantID, id string) (*webhook.Delivery, error) {
+	row := r.db.pool.QueryRow(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id)
+
+	d, err := scanWebhookDelivery(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, webhook.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+// List retrieves a page of a subscription's deliveries, ordered by ID
+func (r *WebhookDeliveryRepository) List(ctx context.Context, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	query := `WHERE tenant_id = $1 AND subscription_id = $2`
+	args := []any{filter.TenantID, filter.SubscriptionID}
+	if filter.Status != "" {
+		query += ` AND status = $3`
+		args = append(args, filter.Status)
+	}
+
+	page, args := pageClause("id", filter.Page, args)
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		`+query+page, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// Update stores the outcome of a delivery attempt
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d *webhook.Delivery) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_attempt_at = $4, response_status = $5,
+		    last_error = $6, updated_at = $7
+		WHERE id = $8
+	`, d.Status, d.Attempts, d.NextAttemptAt, d.LastAttemptAt, d.ResponseStatus,
+		d.LastError, d.UpdatedAt, d.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return webhook.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+// ClaimDue leases up to limit due pending deliveries until leaseUntil and returns them.
+// Rows locked by a concurrent claim are skipped, so replicas never claim the same delivery.
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns,
+		leaseUntil, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// DeleteFinished removes up to limit succeeded or dead deliveries last updated before the cutoff
+func (r *WebhookDeliveryRepository) DeleteFinished(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM webhook_deliveries WHERE id IN (
+			SELECT id FROM webhook_deliveries WHERE status <> 'pending' AND updated_at < $1 LIMIT $2
+		)
+	`, before, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanWebhookDelivery(s pgx.Row) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	if err := s.Scan(&d.ID, &d.SubscriptionID, &d.TenantID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.LastError,
+		&d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func scanWebhookDeliveries(rows pgx.Rows) ([]*webhook.Delivery, error) {
+	var deliveries []*webhook.Delivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,621 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// ProjectRepository implements authz.ProjectRepository
+type ProjectRepository struct {
+	db *DB
+}
+
+// NewProjectRepository creates a new project repository
+func NewProjectRepository(db *DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// Create creates a new project
+func (r *ProjectRepository) Create(ctx context.Context, project *authz.Project) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO projects (
+			id, tenant_id, name, description, owner_id, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		project.ID, project.TenantID, project.Name, project.Description, project.OwnerID,
+		timestamp(project.CreatedAt), timestamp(project.UpdatedAt),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a project by ID
+func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*authz.Project, error) {
+	return r.get(ctx, `WHERE id = ? AND deleted_at IS NULL`, id)
+}
+
+// GetByName retrieves a project by name
+func (r *ProjectRepository) GetByName(ctx context.Context, name string) (*authz.Project, error) {
+	return r.get(ctx, `WHERE name = ? AND deleted_at IS NULL`, name)
+}
+
+func (r *ProjectRepository) get(ctx context.Context, where string, args ...any) (*authz.Project, error) {
+	project, err := scanProject(r.db.db.QueryRowContext(ctx, `
+		SELECT `+projectColumns+`
+		FROM projects
+		`+where, args...))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, authz.ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// Update updates project information
+func (r *ProjectRepository) Update(ctx context.Context, project *authz.Project) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE projects SET
+			name = ?,
+			description = ?,
+			updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`,
+		project.Name, project.Description, timestamp(time.Now()), project.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return authz.ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// Delete soft-deletes a project
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE projects SET deleted_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, timestamp(time.Now()), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return authz.ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes up to limit projects soft-deleted before the given time; their
+// members go with them
+func (r *ProjectRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM projects WHERE id IN (
+			SELECT id FROM projects
+			WHERE deleted_at IS NOT NULL AND deleted_at < ?
+			LIMIT ?
+		)
+	`, timestamp(before), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted projects: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ListByOwner retrieves a page of projects owned by a user, ordered by ID
+func (r *ProjectRepository) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*authz.Project, error) {
+	page, args := pageClause("id", p, []any{ownerID})
+	return r.list(ctx, `
+		SELECT `+projectColumns+`
+		FROM projects
+		WHERE owner_id = ? AND deleted_at IS NULL`+page, args...)
+}
+
+// ListByUser retrieves a page of projects a user has a role in, ordered by ID
+func (r *ProjectRepository) ListByUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Project, error) {
+	page, args := pageClause("p.id", p, []any{userID})
+	return r.list(ctx, `
+		SELECT p.id, p.tenant_id, p.name, p.description, p.owner_id, p.created_at, p.updated_at, p.deleted_at
+		FROM projects p
+		INNER JOIN project_members m ON p.id = m.project_id
+		WHERE m.user_id = ? AND p.deleted_at IS NULL`+page, args...)
+}
+
+// ListByTenant retrieves a page of a tenant's projects, ordered by ID
+func (r *ProjectRepository) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*authz.Project, error) {
+	page, args := pageClause("id", p, []any{tenantID})
+	return r.list(ctx, `
+		SELECT `+projectColumns+`
+		FROM projects
+		WHERE tenant_id = ? AND deleted_at IS NULL`+page, args...)
+}
+
+func (r *ProjectRepository) list(ctx context.Context, query string, args ...any) ([]*authz.Project, error) {
+	rows, err := r.db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []*authz.Project
+
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+// SetMember gives a user a role in a project, replacing the role they had
+func (r *ProjectRepository) SetMember(ctx context.Context, member *authz.ProjectMember) error {
+	var grantedBy sql.NullString
+	if member.GrantedBy != "" {
+		grantedBy = sql.NullString{String: member.GrantedBy, Valid: true}
+	}
+
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO project_members (project_id, user_id, role, granted_at, granted_by)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, user_id) DO UPDATE SET
+			role = excluded.role, granted_at = excluded.granted_at, granted_by = excluded.granted_by
+	`, member.ProjectID, member.UserID, member.Role, timestamp(member.GrantedAt), grantedBy)
+
+	if err != nil {
+		return fmt.Errorf("failed to set project member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a project
+func (r *ProjectRepository) RemoveMember(ctx context.Context, projectID, userID string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM project_members WHERE project_id = ? AND user_id = ?
+	`, projectID, userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to remove project member: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return authz.ErrProjectMemberNotFound
+	}
+	return nil
+}
+
+// GetMember retrieves a user's role in a project
+func (r *ProjectRepository) GetMember(ctx context.Context, projectID, userID string) (*authz.ProjectMember, error) {
+	member, err := scanProjectMember(r.db.db.QueryRowContext(ctx, `
+		SELECT project_id, user_id, role, granted_at, granted_by
+		FROM project_members
+		WHERE project_id = ? AND user_id = ?
+	`, projectID, userID))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, authz.ErrProjectMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get project member: %w", err)
+	}
+	return member, nil
+}
+
+// ListMembers retrieves the members of a project, oldest first
+func (r *ProjectRepository) ListMembers(ctx context.Context, projectID string) ([]*authz.ProjectMember, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT project_id, user_id, role, granted_at, granted_by
+		FROM project_members
+		WHERE project_id = ?
+		ORDER BY granted_at, user_id
+	`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*authz.ProjectMember
+	for rows.Next() {
+		member, err := scanProjectMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// scanner is satisfied by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+const projectColumns = `id, tenant_id, name, description, owner_id, created_at, updated_at, deleted_at`
+
+func scanProject(s scanner) (*authz.Project, error) {
+	var project authz.Project
+	var tenantID, description sql.NullString
+	var deletedAt sql.NullTime
+
+	if err := s.Scan(
+		&project.ID, &tenantID, &project.Name, &description, &project.OwnerID,
+		&project.CreatedAt, &project.UpdatedAt, &deletedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	project.TenantID = tenantID.String
+	project.Description = description.String
+	if deletedAt.Valid {
+		project.DeletedAt = &deletedAt.Time
+	}
+
+	return &project, nil
+}
+
+func scanProjectMember(s scanner) (*authz.ProjectMember, error) {
+	var m authz.ProjectMember
+	var grantedBy sql.NullString
+	if err := s.Scan(&m.ProjectID, &m.UserID, &m.Role, &m.GrantedAt, &grantedBy); err != nil {
+		return nil, err
+	}
+	m.GrantedBy = grantedBy.String
+	return &m, nil
+}
+
+// RoleRepository implements authz.RoleRepository
+type RoleRepository struct {
+	db *DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// roleSelect aggregates permission names; SQLite has no arrays, so they are joined with commas
+const roleSelect = `
+	SELECT r.id, r.name, r.scope, r.description, r.created_at, r.updated_at,
+	       group_concat(p.name, ',')
+	FROM rbac_roles r
+	LEFT JOIN rbac_role_permissions rp ON r.id = rp.role_id
+	LEFT JOIN rbac_permissions p ON rp.permission_id = p.id
+`
+
+const roleGroupBy = ` GROUP BY r.id, r.name, r.scope, r.description, r.created_at, r.updated_at`
+
+// Create creates a new role
+func (r *RoleRepository) Create(ctx context.Context, role *authz.Role) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO rbac_roles (
+			id, name, scope, description, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		role.ID, role.Name, string(role.Scope), role.Description,
+		timestamp(role.CreatedAt), timestamp(role.UpdatedAt),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a role by ID
+func (r *RoleRepository) GetByID(ctx context.Context, id string) (*authz.Role, error) {
+	role, err := scanRole(r.db.db.QueryRowContext(ctx, roleSelect+` WHERE r.id = ?`+roleGroupBy, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, authz.ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+// GetByName retrieves a role by name and scope
+func (r *RoleRepository) GetByName(ctx context.Context, name string, scope authz.Scope) (*authz.Role, error) {
+	role, err := scanRole(r.db.db.QueryRowContext(ctx, roleSelect+` WHERE r.name = ? AND r.scope = ?`+roleGroupBy, name, string(scope)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, authz.ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+// Update updates role information
+func (r *RoleRepository) Update(ctx context.Context, role *authz.Role) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE rbac_roles SET
+			description = ?,
+			updated_at = ?
+		WHERE id = ?
+	`,
+		role.Description, timestamp(time.Now()), role.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return authz.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a role
+func (r *RoleRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM rbac_roles WHERE id = ?
+	`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return authz.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// List retrieves all roles, optionally filtered by scope
+func (r *RoleRepository) List(ctx context.Context, scope *authz.Scope) ([]*authz.Role, error) {
+	query := roleSelect
+	var args []any
+	if scope != nil {
+		query += " WHERE r.scope = ?"
+		args = append(args, string(*scope))
+	}
+	query += roleGroupBy
+
+	rows, err := r.db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*authz.Role
+
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func scanRole(s scanner) (*authz.Role, error) {
+	var role authz.Role
+	var scopeStr string
+	var description, permissions sql.NullString
+
+	if err := s.Scan(
+		&role.ID, &role.Name, &scopeStr, &description,
+		&role.CreatedAt, &role.UpdatedAt, &permissions,
+	); err != nil {
+		return nil, err
+	}
+
+	role.Scope = authz.Scope(scopeStr)
+	role.Description = description.String
+	role.Permissions = []string{}
+	if permissions.String != "" {
+		role.Permissions = strings.Split(permissions.String, ",")
+	}
+
+	return &role, nil
+}
+
+// AssignmentRepository implements authz.AssignmentRepository
+type AssignmentRepository struct {
+	db *DB
+}
+
+// NewAssignmentRepository creates a new assignment repository
+func NewAssignmentRepository(db *DB) *AssignmentRepository {
+	return &AssignmentRepository{db: db}
+}
+
+// Grant assigns a role to a user
+func (r *AssignmentRepository) Grant(ctx context.Context, assignment *authz.Assignment) error {
+	var grantedBy sql.NullString
+	if assignment.GrantedBy != "" {
+		grantedBy = sql.NullString{String: assignment.GrantedBy, Valid: true}
+	}
+
+	// SQLite treats NULLs as distinct in UNIQUE constraints, so platform grants
+	// (scope_context_id IS NULL) are de-duplicated explicitly.
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO rbac_assignments (
+			id, user_id, role_id, scope, scope_context_id, granted_at, granted_by
+		)
+		SELECT ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM rbac_assignments
+			WHERE user_id = ? AND role_id = ? AND scope = ? AND scope_context_id IS ?
+		)
+	`,
+		assignment.ID, assignment.UserID, assignment.RoleID,
+		string(assignment.Scope), assignment.ScopeContextID,
+		timestamp(assignment.GrantedAt), grantedBy,
+		assignment.UserID, assignment.RoleID, string(assignment.Scope), assignment.ScopeContextID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke removes a role assignment
+func (r *AssignmentRepository) Revoke(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM rbac_assignments
+		WHERE user_id = ? AND role_id = ? AND scope = ? AND scope_context_id IS ?
+	`, userID, roleID, string(scope), scopeContextID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUnlessLast removes a role assignment unless it is the only one of the role at the scope
+func (r *AssignmentRepository) RevokeUnlessLast(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	return revokeUnlessLast(ctx, r.db, userID, roleID, string(scope), scopeContextID)
+}
+
+// revokeUnlessLast removes a role assignment in a transaction that first counts the holders of the
+// role at the scope. The database has a single connection, so no revocation can run in between.
+func revokeUnlessLast(ctx context.Context, db *DB, userID, roleID, scope string, scopeContextID *string) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var holders int
+	var held bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(MAX(user_id = ?), FALSE) FROM rbac_assignments
+		WHERE role_id = ? AND scope = ? AND scope_context_id IS ?
+	`, userID, roleID, scope, scopeContextID).Scan(&holders, &held)
+	if err != nil {
+		return fmt.Errorf("failed to count role assignments: %w", err)
+	}
+	if !held {
+		return authz.ErrAssignmentNotFound
+	}
+	if holders == 1 {
+		return authz.ErrLastAssignment
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM rbac_assignments
+		WHERE user_id = ? AND role_id = ? AND scope = ? AND scope_context_id IS ?
+	`, userID, roleID, scope, scopeContextID); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListForUser retrieves a page of assignments for a user, ordered by ID
+func (r *AssignmentRepository) ListForUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Assignment, error) {
+	page, args := pageClause("id", p, []any{userID})
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT id, user_id, role_id, scope, scope_context_id, granted_at, COALESCE(granted_by, '')
+		FROM rbac_assignments
+		WHERE user_id = ?`+page, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*authz.Assignment
+
+	for rows.Next() {
+		var a authz.Assignment
+		var scopeStr string
+
+		if err := rows.Scan(
+			&a.ID, &a.UserID, &a.RoleID, &scopeStr, &a.ScopeContextID,
+			&a.GrantedAt, &a.GrantedBy,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment: %w", err)
+		}
+
+		a.Scope = authz.Scope(scopeStr)
+		assignments = append(assignments, &a)
+	}
+
+	return assignments, rows.Err()
+}
+
+// ListByRole retrieves all users assigned a specific role at a scope
+func (r *AssignmentRepository) ListByRole(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT user_id FROM rbac_assignments
+		WHERE role_id = ? AND scope = ? AND scope_context_id IS ?
+	`, roleID, string(scope), scopeContextID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users by role: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// CheckExists checks if a specific assignment exists
+func (r *AssignmentRepository) CheckExists(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
+	var exists bool
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM rbac_assignments
+			WHERE role_id = ? AND scope = ? AND scope_context_id IS ?
+		)
+	`, roleID, string(scope), scopeContextID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check assignment existence: %w", err)
+	}
+
+	return exists, nil
+}
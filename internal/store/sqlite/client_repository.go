@@ -0,0 +1,303 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// ClientRepository implements oauth2.ClientRepository
+type ClientRepository struct {
+	db *DB
+}
+
+// NewClientRepository creates a new client repository
+func NewClientRepository(db *DB) *ClientRepository {
+	return &ClientRepository{db: db}
+}
+
+const clientColumns = `
+	id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
+	redirect_uris, allowed_scopes, grant_types, response_types,
+	token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
+	owner_id, is_trusted, is_active, created_at, updated_at, version, deleted_at,
+	jwks, request_uris, require_signed_request_object, allowed_origins, client_group, application_type, min_state_length, jwks_uri
+`
+
+// Create creates a new OAuth2 client
+func (r *ClientRepository) Create(ctx context.Context, client *oauth2.Client) error {
+	lists, err := marshalClientLists(client)
+	if err != nil {
+		return err
+	}
+
+	var ownerID sql.NullString
+	if client.OwnerID != "" {
+		ownerID = sql.NullString{String: client.OwnerID, Valid: true}
+	}
+
+	_, err = r.db.db.ExecContext(ctx, `
+		INSERT INTO oauth2_clients (
+			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
+			redirect_uris, allowed_scopes, grant_types, response_types,
+			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
+			owner_id, is_trusted, is_active, created_at, updated_at,
+			jwks, request_uris, require_signed_request_object, allowed_origins, client_group, application_type, min_state_length, jwks_uri
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		client.ID, client.ClientID, client.TenantID, client.ClientSecretHash, client.ClientName, client.ClientURI, client.LogoURI,
+		lists[0], lists[1], lists[2], lists[3],
+		client.TokenEndpointAuthMethod, client.AccessTokenLifetime, client.RefreshTokenLifetime, client.IDTokenLifetime,
+		ownerID, client.IsTrusted, client.IsActive, timestamp(client.CreatedAt), timestamp(client.UpdatedAt),
+		clientJWKS(client), lists[4], client.RequireSignedRequestObject, lists[5], client.ClientGroup, client.ApplicationType, client.MinStateLength, client.JWKSURI,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	client.Version = 1
+
+	return nil
+}
+
+// GetByClientID retrieves a client by client_id
+func (r *ClientRepository) GetByClientID(ctx context.Context, clientID string) (*oauth2.Client, error) {
+	return r.get(ctx, `WHERE client_id = ? AND deleted_at IS NULL`, clientID)
+}
+
+// GetByID retrieves a client by internal ID
+func (r *ClientRepository) GetByID(ctx context.Context, id string) (*oauth2.Client, error) {
+	return r.get(ctx, `WHERE id = ? AND deleted_at IS NULL`, id)
+}
+
+func (r *ClientRepository) get(ctx context.Context, where string, args ...any) (*oauth2.Client, error) {
+	client, err := scanClient(r.db.db.QueryRowContext(ctx, `SELECT `+clientColumns+` FROM oauth2_clients `+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauth2.ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	return client, nil
+}
+
+// Update updates client information
+func (r *ClientRepository) Update(ctx context.Context, client *oauth2.Client) error {
+	lists, err := marshalClientLists(client)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE oauth2_clients SET
+			client_name = ?,
+			client_uri = ?,
+			logo_uri = ?,
+			redirect_uris = ?,
+			allowed_scopes = ?,
+			grant_types = ?,
+			response_types = ?,
+			token_endpoint_auth_method = ?,
+			access_token_lifetime = ?,
+			refresh_token_lifetime = ?,
+			id_token_lifetime = ?,
+			is_trusted = ?,
+			is_active = ?,
+			jwks = ?,
+			request_uris = ?,
+			require_signed_request_object = ?,
+			allowed_origins = ?,
+			client_group = ?,
+			application_type = ?,
+			min_state_length = ?,
+			jwks_uri = ?,
+			updated_at = ?,
+			version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
+	`,
+		client.ClientName, client.ClientURI, client.LogoURI,
+		lists[0], lists[1], lists[2], lists[3],
+		client.TokenEndpointAuthMethod, client.AccessTokenLifetime, client.RefreshTokenLifetime, client.IDTokenLifetime,
+		client.IsTrusted, client.IsActive, clientJWKS(client), lists[4], client.RequireSignedRequestObject, lists[5], client.ClientGroup, client.ApplicationType, client.MinStateLength, client.JWKSURI,
+		timestamp(time.Now()), client.ID, client.Version,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update client: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Distinguish a stale version from a missing client
+		if _, err := r.GetByID(ctx, client.ID); err != nil {
+			return err
+		}
+		return oauth2.ErrClientVersionConflict
+	}
+	client.Version++
+
+	return nil
+}
+
+// Delete soft-deletes a client
+func (r *ClientRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE oauth2_clients SET deleted_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, timestamp(time.Now()), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return oauth2.ErrClientNotFound
+	}
+
+	return nil
+}
+
+// ListByOwner retrieves a page of clients for an owner, ordered by ID
+func (r *ClientRepository) ListByOwner(ctx context.Context, ownerID string, p pagination.Params) ([]*oauth2.Client, error) {
+	page, args := pageClause("id", p, []any{ownerID})
+	return r.list(ctx, `WHERE owner_id = ? AND deleted_at IS NULL`+page, args...)
+}
+
+// ListByTenant retrieves a page of clients for a tenant, ordered by ID
+func (r *ClientRepository) ListByTenant(ctx context.Context, tenantID string, p pagination.Params) ([]*oauth2.Client, error) {
+	page, args := pageClause("id", p, []any{tenantID})
+	return r.list(ctx, `WHERE tenant_id = ? AND deleted_at IS NULL`+page, args...)
+}
+
+func (r *ClientRepository) list(ctx context.Context, where string, args ...any) ([]*oauth2.Client, error) {
+	rows, err := r.db.db.QueryContext(ctx, `SELECT `+clientColumns+` FROM oauth2_clients `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clients: %w", err)
+	}
+	defer rows.Close()
+
+	var clients []*oauth2.Client
+
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan client: %w", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return clients, nil
+}
+
+func marshalClientLists(client *oauth2.Client) ([6]string, error) {
+	var out [6]string
+	for i, v := range [][]string{client.RedirectURIs, client.AllowedScopes, client.GrantTypes, client.ResponseTypes, client.RequestURIs, client.AllowedOrigins} {
+		if v == nil {
+			v = []string{}
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return out, fmt.Errorf("failed to marshal client fields: %w", err)
+		}
+		out[i] = string(b)
+	}
+	return out, nil
+}
+
+// clientJWKS returns the client's JWKS as stored, NULL when it has none
+func clientJWKS(client *oauth2.Client) sql.NullString {
+	if len(client.JWKS) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(client.JWKS), Valid: true}
+}
+
+func scanClient(s scanner) (*oauth2.Client, error) {
+	var client oauth2.Client
+	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, requestURIsJSON, allowedOriginsJSON string
+	var clientURI, logoURI, authMethod, ownerID, jwks sql.NullString
+	var deletedAt sql.NullTime
+
+	if err := s.Scan(
+		&client.ID, &client.ClientID, &client.TenantID, &client.ClientSecretHash, &client.ClientName, &clientURI, &logoURI,
+		&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
+		&authMethod, &client.AccessTokenLifetime, &client.RefreshTokenLifetime, &client.IDTokenLifetime,
+		&ownerID, &client.IsTrusted, &client.IsActive, &client.CreatedAt, &client.UpdatedAt, &client.Version, &deletedAt,
+		&jwks, &requestURIsJSON, &client.RequireSignedRequestObject, &allowedOriginsJSON, &client.ClientGroup, &client.ApplicationType, &client.MinStateLength, &client.JWKSURI,
+	); err != nil {
+		return nil, err
+	}
+
+	// Unmarshal JSON fields
+	if err := json.Unmarshal([]byte(redirectURIsJSON), &client.RedirectURIs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal redirect URIs: %w", err)
+	}
+	if err := json.Unmarshal([]byte(allowedScopesJSON), &client.AllowedScopes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed scopes: %w", err)
+	}
+	if err := json.Unmarshal([]byte(grantTypesJSON), &client.GrantTypes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal grant types: %w", err)
+	}
+	if err := json.Unmarshal([]byte(responseTypesJSON), &client.ResponseTypes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response types: %w", err)
+	}
+	if err := json.Unmarshal([]byte(requestURIsJSON), &client.RequestURIs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request URIs: %w", err)
+	}
+	if err := json.Unmarshal([]byte(allowedOriginsJSON), &client.AllowedOrigins); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed origins: %w", err)
+	}
+	if jwks.Valid {
+		client.JWKS = json.RawMessage(jwks.String)
+	}
+
+	client.ClientURI = clientURI.String
+	client.LogoURI = logoURI.String
+	client.TokenEndpointAuthMethod = authMethod.String
+	client.OwnerID = ownerID.String
+	if deletedAt.Valid {
+		client.DeletedAt = &deletedAt.Time
+	}
+
+	return &client, nil
+}
+
+// PurgeDeleted permanently removes up to limit clients soft-deleted before the given time
+func (r *ClientRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM oauth2_clients WHERE id IN (
+			SELECT id FROM oauth2_clients
+			WHERE deleted_at IS NOT NULL AND deleted_at < ?
+			LIMIT ?
+		)
+	`, timestamp(before), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted clients: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// AuthorizationCodeRepository implements oauth2.AuthorizationCodeRepository
+type AuthorizationCodeRepository struct {
+	db *DB
+}
+
+// NewAuthorizationCodeRepository creates a new authorization code repository
+func NewAuthorizationCodeRepository(db *DB) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{db: db}
+}
+
+// Create creates a new authorization code
+func (r *AuthorizationCodeRepository) Create(ctx context.Context, code *oauth2.AuthorizationCode) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO authorization_codes (
+			id, tenant_id, code, client_id, user_id,
+			redirect_uri, scope, state, nonce,
+			code_challenge, code_challenge_method, session_id,
+			expires_at, used_at, is_used, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		code.ID, code.TenantID, code.Code, code.ClientID, code.UserID,
+		code.RedirectURI, code.Scope, code.State, code.Nonce,
+		code.CodeChallenge, code.CodeChallengeMethod, code.SessionID,
+		timestamp(code.ExpiresAt), nullTimestamp(code.UsedAt), code.IsUsed, timestamp(code.CreatedAt),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create authorization code: %w", err)
+	}
+
+	return nil
+}
+
+// GetByCode retrieves an authorization code issued within a tenant
+func (r *AuthorizationCodeRepository) GetByCode(ctx context.Context, tenantID, codeStr string) (*oauth2.AuthorizationCode, error) {
+	var code oauth2.AuthorizationCode
+	var state, nonce, challenge, challengeMethod sql.NullString
+	var usedAt sql.NullTime
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT
+			id, tenant_id, code, client_id, user_id,
+			redirect_uri, scope, state, nonce,
+			code_challenge, code_challenge_method, session_id,
+			expires_at, used_at, is_used, created_at
+		FROM authorization_codes
+		WHERE tenant_id = ? AND code = ?
+	`, tenantID, codeStr).Scan(
+		&code.ID, &code.TenantID, &code.Code, &code.ClientID, &code.UserID,
+		&code.RedirectURI, &code.Scope, &state, &nonce,
+		&challenge, &challengeMethod, &code.SessionID,
+		&code.ExpiresAt, &usedAt, &code.IsUsed, &code.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauth2.ErrCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	code.State = state.String
+	code.Nonce = nonce.String
+	code.CodeChallenge = challenge.String
+	code.CodeChallengeMethod = challengeMethod.String
+	if usedAt.Valid {
+		code.UsedAt = &usedAt.Time
+	}
+
+	return &code, nil
+}
+
+// MarkAsUsed marks the code as used
+func (r *AuthorizationCodeRepository) MarkAsUsed(ctx context.Context, tenantID, code string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE authorization_codes SET is_used = true, used_at = ?
+		WHERE tenant_id = ? AND code = ?
+	`, timestamp(time.Now()), tenantID, code)
+
+	if err != nil {
+		return fmt.Errorf("failed to mark code as used: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return oauth2.ErrCodeNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes an authorization code
+func (r *AuthorizationCodeRepository) Delete(ctx context.Context, tenantID, code string) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM authorization_codes WHERE tenant_id = ? AND code = ?
+	`, tenantID, code)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete code: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes up to limit expired authorization codes and returns how many were removed
+func (r *AuthorizationCodeRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM authorization_codes WHERE id IN (
+			SELECT id FROM authorization_codes WHERE expires_at < ? LIMIT ?
+		)
+	`, timestamp(time.Now()), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired codes: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,323 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite implements the repository interfaces on SQLite.
+// It is intended for local development, demos and tests; production deployments use PostgreSQL.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/001_initial_schema.sql
+var InitialSchema string
+
+//go:embed migrations/002_tenant_email_settings.sql
+var TenantEmailSettingsSchema string
+
+//go:embed migrations/003_tenant_listing_indexes.sql
+var TenantListingIndexes string
+
+//go:embed migrations/004_list_pagination_indexes.sql
+var ListPaginationIndexes string
+
+//go:embed migrations/005_audit_events.sql
+var AuditEventsSchema string
+
+//go:embed migrations/006_audit_retention.sql
+var AuditRetentionSchema string
+
+//go:embed migrations/007_audit_schema_version.sql
+var AuditSchemaVersion string
+
+//go:embed migrations/008_audit_request_id.sql
+var AuditRequestID string
+
+//go:embed migrations/009_webhooks.sql
+var WebhooksSchema string
+
+//go:embed migrations/010_password_expiry.sql
+var PasswordExpirySchema string
+
+//go:embed migrations/011_signing_key_lifecycle.sql
+var SigningKeyLifecycleSchema string
+
+//go:embed migrations/012_signing_key_envelope.sql
+var SigningKeyEnvelopeSchema string
+
+//go:embed migrations/013_tenant_scoped_codes.sql
+var TenantScopedCodesSchema string
+
+//go:embed migrations/014_record_versions.sql
+var RecordVersionsSchema string
+
+//go:embed migrations/015_oauth2_scopes.sql
+var OAuth2ScopesSchema string
+
+//go:embed migrations/016_request_objects.sql
+var RequestObjectsSchema string
+
+//go:embed migrations/017_access_token_claims.sql
+var AccessTokenClaimsSchema string
+
+//go:embed migrations/018_code_sessions.sql
+var CodeSessionsSchema string
+
+//go:embed migrations/019_replay_markers.sql
+var ReplayMarkersSchema string
+
+//go:embed migrations/020_session_namespace.sql
+var SessionNamespaceSchema string
+
+//go:embed migrations/021_pending_authorizations.sql
+var PendingAuthorizationsSchema string
+
+//go:embed migrations/022_client_origins.sql
+var ClientOriginsSchema string
+
+//go:embed migrations/023_client_groups.sql
+var ClientGroupsSchema string
+
+//go:embed migrations/024_client_application_types.sql
+var ClientApplicationTypesSchema string
+
+//go:embed migrations/025_protocol_log_settings.sql
+var ProtocolLogSettingsSchema string
+
+//go:embed migrations/026_client_state_policy.sql
+var ClientStatePolicySchema string
+
+//go:embed migrations/027_client_jwks_uri.sql
+var ClientJWKSURISchema string
+
+//go:embed migrations/028_tenant_memberships.sql
+var TenantMembershipsSchema string
+
+//go:embed migrations/029_project_members.sql
+var ProjectMembersSchema string
+
+//go:embed migrations/030_tenant_security_policies.sql
+var TenantSecurityPoliciesSchema string
+
+//go:embed migrations/031_email_changes.sql
+var EmailChangesSchema string
+
+//go:embed migrations/032_password_change.sql
+var PasswordChangeSchema string
+
+//go:embed migrations/033_account_deletions.sql
+var AccountDeletionsSchema string
+
+//go:embed migrations/034_profile_claims.sql
+var ProfileClaimsSchema string
+
+//go:embed migrations/035_token_client_index.sql
+var TokenClientIndexSchema string
+
+//go:embed migrations/036_live_user_email.sql
+var LiveUserEmailSchema string
+
+// Migrations returns all migrations in the order they must be applied
+func Migrations() []string {
+	return []string{
+		InitialSchema,
+		TenantEmailSettingsSchema,
+		TenantListingIndexes,
+		ListPaginationIndexes,
+		AuditEventsSchema,
+		AuditRetentionSchema,
+		AuditSchemaVersion,
+		AuditRequestID,
+		WebhooksSchema,
+		PasswordExpirySchema,
+		SigningKeyLifecycleSchema,
+		SigningKeyEnvelopeSchema,
+		TenantScopedCodesSchema,
+		RecordVersionsSchema,
+		OAuth2ScopesSchema,
+		RequestObjectsSchema,
+		AccessTokenClaimsSchema,
+		CodeSessionsSchema,
+		ReplayMarkersSchema,
+		SessionNamespaceSchema,
+		PendingAuthorizationsSchema,
+		ClientOriginsSchema,
+		ClientGroupsSchema,
+		ClientApplicationTypesSchema,
+		ProtocolLogSettingsSchema,
+		ClientStatePolicySchema,
+		ClientJWKSURISchema,
+		TenantMembershipsSchema,
+		ProjectMembersSchema,
+		TenantSecurityPoliciesSchema,
+		EmailChangesSchema,
+		PasswordChangeSchema,
+		AccountDeletionsSchema,
+		ProfileClaimsSchema,
+		TokenClientIndexSchema,
+		LiveUserEmailSchema,
+	}
+}
+
+// MemoryPath opens a private in-memory database
+const MemoryPath = ":memory:"
+
+// DB wraps the SQLite connection
+type DB struct {
+	db *sql.DB
+}
+
+// Config holds database configuration
+type Config struct {
+	// Path is the database file path, or MemoryPath
+	Path string
+}
+
+// New opens a SQLite database
+func New(ctx context.Context, cfg Config) (*DB, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("sqlite path is required")
+	}
+
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", cfg.Path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; one connection also keeps in-memory databases shared
+	db.SetMaxOpenConns(1)
+
+	// Verify connection
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Close closes the database connection
+func (db *DB) Close() {
+	db.db.Close()
+}
+
+// Migrate runs a SQL script.
+// Scripts are re-applied on every start and SQLite has no ADD COLUMN IF NOT EXISTS,
+// so a column that was already added is not an error. LiveUserEmailSchema rebuilds the users
+// table and is run by rebuildUsers instead.
+func (db *DB) Migrate(ctx context.Context, script string) error {
+	if script == LiveUserEmailSchema {
+		return db.rebuildUsers(ctx, script)
+	}
+	_, err := db.db.ExecContext(ctx, script)
+	if err != nil && strings.Contains(err.Error(), "duplicate column name") {
+		return nil
+	}
+	return err
+}
+
+// rebuildUsers runs the users table rebuild of LiveUserEmailSchema, once: only while the table
+// still has UNIQUE(tenant_id, email). Foreign keys are off meanwhile, so dropping the old table
+// neither cascades to nor is refused by the tables that reference users.
+func (db *DB) rebuildUsers(ctx context.Context, script string) error {
+	var ddl string
+	err := db.db.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'users'`).Scan(&ddl)
+	if err != nil {
+		return fmt.Errorf("failed to read the users table definition: %w", err)
+	}
+	if !strings.Contains(ddl, "UNIQUE(tenant_id, email)") {
+		return nil
+	}
+
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// The pragma is a no-op inside a transaction, so it is set around it
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "PRAGMA foreign_keys = ON")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	broken := rows.Next()
+	rows.Close()
+	if broken {
+		return fmt.Errorf("rebuilding the users table would break a foreign key")
+	}
+	return tx.Commit()
+}
+
+// timeLayout is fixed-width so stored timestamps compare correctly as text
+const timeLayout = "2006-01-02 15:04:05.000000000"
+
+// timestamp formats t for storage
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTimestamp formats an optional time for storage
+func nullTimestamp(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return timestamp(*t)
+}
+
+// placeholders returns n comma-separated bind parameters for an IN list
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// appendStrings appends values to query arguments
+func appendStrings(args []any, values []string) []any {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
+// pageClause returns the keyset condition, ordering and limit for a list ordered by column.
+// It must follow a WHERE clause; args holds the query's existing arguments.
+func pageClause(column string, p pagination.Params, args []any) (string, []any) {
+	p = p.Normalize()
+	clause := ""
+	if p.After != "" {
+		clause = fmt.Sprintf(" AND %s > ?", column)
+		args = append(args, p.After)
+	}
+	args = append(args, p.Limit)
+	return clause + fmt.Sprintf(" ORDER BY %s LIMIT ?", column), args
+}
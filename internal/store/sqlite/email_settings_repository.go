@@ -0,0 +1,105 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/email"
+)
+
+// EmailSettingsRepository implements email.SettingsRepository
+type EmailSettingsRepository struct {
+	db *DB
+}
+
+// NewEmailSettingsRepository creates a new email settings repository
+func NewEmailSettingsRepository(db *DB) *EmailSettingsRepository {
+	return &EmailSettingsRepository{db: db}
+}
+
+// Get retrieves the email settings for a tenant
+func (r *EmailSettingsRepository) Get(ctx context.Context, tenantID string) (*email.Settings, error) {
+	var s email.Settings
+	var username, fromName sql.NullString
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT tenant_id, enabled, smtp_host, smtp_port, smtp_username, smtp_password_encrypted,
+		       from_address, from_name, tls_mode, created_at, updated_at
+		FROM tenant_email_settings
+		WHERE tenant_id = ?
+	`, tenantID).Scan(
+		&s.TenantID, &s.Enabled, &s.Host, &s.Port, &username, &s.PasswordEncrypted,
+		&s.FromAddress, &fromName, &s.TLSMode, &s.CreatedAt, &s.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, email.ErrSettingsNotFound
+		}
+		return nil, fmt.Errorf("failed to get email settings: %w", err)
+	}
+
+	s.Username = username.String
+	s.FromName = fromName.String
+
+	return &s, nil
+}
+
+// Upsert creates or replaces the email settings for a tenant
+func (r *EmailSettingsRepository) Upsert(ctx context.Context, s *email.Settings) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO tenant_email_settings (
+			tenant_id, enabled, smtp_host, smtp_port, smtp_username, smtp_password_encrypted,
+			from_address, from_name, tls_mode, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			smtp_host = excluded.smtp_host,
+			smtp_port = excluded.smtp_port,
+			smtp_username = excluded.smtp_username,
+			smtp_password_encrypted = excluded.smtp_password_encrypted,
+			from_address = excluded.from_address,
+			from_name = excluded.from_name,
+			tls_mode = excluded.tls_mode,
+			updated_at = excluded.updated_at
+	`, s.TenantID, s.Enabled, s.Host, s.Port, s.Username, s.PasswordEncrypted,
+		s.FromAddress, s.FromName, s.TLSMode, timestamp(s.CreatedAt), timestamp(s.UpdatedAt))
+
+	if err != nil {
+		return fmt.Errorf("failed to save email settings: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the email settings for a tenant
+func (r *EmailSettingsRepository) Delete(ctx context.Context, tenantID string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM tenant_email_settings WHERE tenant_id = ?
+	`, tenantID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete email settings: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return email.ErrSettingsNotFound
+	}
+
+	return nil
+}
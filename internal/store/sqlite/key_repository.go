@@ -0,0 +1,151 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// KeyRepository implements oauth2.KeyRepository
+type KeyRepository struct {
+	db *DB
+}
+
+// NewKeyRepository creates a new key repository
+func NewKeyRepository(db *DB) *KeyRepository {
+	return &KeyRepository{db: db}
+}
+
+const keyColumns = `id, type, algorithm, public_key, private_key_encrypted, data_key_encrypted, kms_key_id, status, created_at, activated_at, retired_at, expires_at`
+
+func scanKey(row scanner) (*oauth2.Key, error) {
+	var key oauth2.Key
+	var activatedAt, retiredAt sql.NullTime
+	err := row.Scan(
+		&key.ID, &key.Type, &key.Algorithm, &key.PublicKey, &key.PrivateKeyEncrypted, &key.DataKeyEncrypted, &key.KMSKeyID, &key.Status,
+		&key.CreatedAt, &activatedAt, &retiredAt, &key.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if activatedAt.Valid {
+		key.ActivatedAt = &activatedAt.Time
+	}
+	if retiredAt.Valid {
+		key.RetiredAt = &retiredAt.Time
+	}
+	return &key, nil
+}
+
+// Create stores a new key
+func (r *KeyRepository) Create(ctx context.Context, key *oauth2.Key) error {
+	if key.Status == "" {
+		key.Status = oauth2.KeyStatusActive
+	}
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO openid_keys (`+keyColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		key.ID, string(key.Type), string(key.Algorithm), key.PublicKey, key.PrivateKeyEncrypted, key.DataKeyEncrypted, key.KMSKeyID, string(key.Status),
+		timestamp(key.CreatedAt), nullTimestamp(key.ActivatedAt), nullTimestamp(key.RetiredAt), timestamp(key.ExpiresAt),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a key by ID
+func (r *KeyRepository) GetByID(ctx context.Context, id string) (*oauth2.Key, error) {
+	key, err := scanKey(r.db.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM openid_keys WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, oauth2.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	return key, nil
+}
+
+// GetActiveKey retrieves the most recently activated unexpired active key
+func (r *KeyRepository) GetActiveKey(ctx context.Context) (*oauth2.Key, error) {
+	key, err := scanKey(r.db.db.QueryRowContext(ctx, `
+		SELECT `+keyColumns+`
+		FROM openid_keys
+		WHERE status = 'active' AND expires_at > ?
+		ORDER BY COALESCE(activated_at, created_at) DESC
+		LIMIT 1
+	`, timestamp(time.Now())))
+
+	if err != nil {
+		// Let the service decide whether to generate a key when none is found
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ListValidKeys retrieves all published keys, newest first
+func (r *KeyRepository) ListValidKeys(ctx context.Context) ([]*oauth2.Key, error) {
+	return r.list(ctx, `WHERE status <> 'retired' AND expires_at > ?`, timestamp(time.Now()))
+}
+
+// List retrieves all keys, newest first
+func (r *KeyRepository) List(ctx context.Context) ([]*oauth2.Key, error) {
+	return r.list(ctx, ``)
+}
+
+func (r *KeyRepository) list(ctx context.Context, where string, args ...any) ([]*oauth2.Key, error) {
+	rows, err := r.db.db.QueryContext(ctx, `SELECT `+keyColumns+` FROM openid_keys `+where+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*oauth2.Key
+	for rows.Next() {
+		key, err := scanKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Update stores a key's lifecycle fields
+func (r *KeyRepository) Update(ctx context.Context, key *oauth2.Key) error {
+	res, err := r.db.db.ExecContext(ctx, `
+		UPDATE openid_keys
+		SET status = ?, activated_at = ?, retired_at = ?, expires_at = ?
+		WHERE id = ?
+	`, string(key.Status), nullTimestamp(key.ActivatedAt), nullTimestamp(key.RetiredAt), timestamp(key.ExpiresAt), key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return oauth2.ErrKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// MembershipRepository implements tenant.MembershipRepository
+type MembershipRepository struct {
+	db *DB
+}
+
+// NewMembershipRepository creates a new tenant membership repository
+func NewMembershipRepository(db *DB) *MembershipRepository {
+	return &MembershipRepository{db: db}
+}
+
+const membershipColumns = `id, tenant_id, user_id, is_home, created_at, created_by`
+
+// Add stores a new membership; the unique (tenant_id, user_id) constraint rejects a second one
+func (r *MembershipRepository) Add(ctx context.Context, m *tenant.Membership) error {
+	var createdBy sql.NullString
+	if m.CreatedBy != "" {
+		createdBy = sql.NullString{String: m.CreatedBy, Valid: true}
+	}
+
+	result, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO tenant_memberships (`+membershipColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id, user_id) DO NOTHING
+	`, m.ID, m.TenantID, m.UserID, m.Home, timestamp(m.CreatedAt), createdBy)
+
+	if err != nil {
+		return fmt.Errorf("failed to add membership: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return tenant.ErrMembershipExists
+	}
+	return nil
+}
+
+// Remove deletes a membership
+func (r *MembershipRepository) Remove(ctx context.Context, tenantID, userID string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM tenant_memberships WHERE tenant_id = ? AND user_id = ?
+	`, tenantID, userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to remove membership: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return tenant.ErrMembershipNotFound
+	}
+	return nil
+}
+
+// Get retrieves a user's membership in a tenant
+func (r *MembershipRepository) Get(ctx context.Context, tenantID, userID string) (*tenant.Membership, error) {
+	row := r.db.db.QueryRowContext(ctx, `
+		SELECT `+membershipColumns+`
+		FROM tenant_memberships
+		WHERE tenant_id = ? AND user_id = ?
+	`, tenantID, userID)
+
+	m, err := scanMembership(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tenant.ErrMembershipNotFound
+		}
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return m, nil
+}
+
+// ListByTenant lists the members of a tenant, oldest first
+func (r *MembershipRepository) ListByTenant(ctx context.Context, tenantID string) ([]*tenant.Membership, error) {
+	return r.list(ctx, `
+		SELECT `+membershipColumns+`
+		FROM tenant_memberships
+		WHERE tenant_id = ?
+		ORDER BY created_at, id
+	`, tenantID)
+}
+
+// ListByUser lists a user's memberships in tenants that are not deleted, home first
+func (r *MembershipRepository) ListByUser(ctx context.Context, userID string) ([]*tenant.Membership, error) {
+	return r.list(ctx, `
+		SELECT m.id, m.tenant_id, m.user_id, m.is_home, m.created_at, m.created_by
+		FROM tenant_memberships m
+		JOIN tenants t ON t.id = m.tenant_id
+		WHERE m.user_id = ? AND t.deleted_at IS NULL
+		ORDER BY m.is_home DESC, m.created_at, m.id
+	`, userID)
+}
+
+func (r *MembershipRepository) list(ctx context.Context, query string, args ...any) ([]*tenant.Membership, error) {
+	rows, err := r.db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+	defer rows.Close()
+
+	var memberships []*tenant.Membership
+	for rows.Next() {
+		m, err := scanMembership(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan membership: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+	return memberships, rows.Err()
+}
+
+func scanMembership(s scanner) (*tenant.Membership, error) {
+	var m tenant.Membership
+	var createdBy sql.NullString
+	if err := s.Scan(&m.ID, &m.TenantID, &m.UserID, &m.Home, &m.CreatedAt, &createdBy); err != nil {
+		return nil, err
+	}
+	m.CreatedBy = createdBy.String
+	return &m, nil
+}
@@ -0,0 +1,240 @@
+-- 001_initial_schema.sql (SQLite)
+-- Mirrors internal/store/postgres/migrations/001_initial_schema.up.sql for
+-- development and tests. UUIDs are stored as TEXT, JSONB as TEXT, and
+-- updated_at is maintained by the repositories instead of triggers.
+
+-- -----------------------------------------------------------------------------
+-- 1. Scoped RBAC Tables (Foundation)
+-- -----------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS rbac_permissions (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS rbac_roles (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    scope TEXT NOT NULL CHECK (scope IN ('platform', 'tenant', 'client')),
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(name, scope)
+);
+
+CREATE TABLE IF NOT EXISTS rbac_role_permissions (
+    role_id TEXT NOT NULL REFERENCES rbac_roles(id) ON DELETE CASCADE,
+    permission_id TEXT NOT NULL REFERENCES rbac_permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- -----------------------------------------------------------------------------
+-- 2. Core Identity Tables
+-- -----------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT REFERENCES tenants(id) ON DELETE RESTRICT,
+    email TEXT NOT NULL,
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    given_name TEXT,
+    family_name TEXT,
+    full_name TEXT,
+    nickname TEXT,
+    picture TEXT,
+    locale TEXT,
+    timezone TEXT,
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE(tenant_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+
+CREATE TABLE IF NOT EXISTS credentials (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip_address TEXT,
+    user_agent TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- -----------------------------------------------------------------------------
+-- 3. Scoped RBAC Assignments
+-- -----------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS rbac_assignments (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id TEXT NOT NULL REFERENCES rbac_roles(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL CHECK (scope IN ('platform', 'tenant', 'client')),
+    scope_context_id TEXT,
+    granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    granted_by TEXT REFERENCES users(id),
+    UNIQUE(user_id, role_id, scope, scope_context_id),
+    CHECK ((scope = 'platform' AND scope_context_id IS NULL) OR (scope != 'platform' AND scope_context_id IS NOT NULL))
+);
+
+CREATE TABLE IF NOT EXISTS projects (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- -----------------------------------------------------------------------------
+-- 4. OAuth2 & OIDC Support
+-- -----------------------------------------------------------------------------
+
+CREATE TABLE IF NOT EXISTS oauth2_clients (
+    id TEXT PRIMARY KEY,
+    client_id TEXT UNIQUE NOT NULL,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    client_secret_hash TEXT NOT NULL,
+    client_name TEXT NOT NULL,
+    client_uri TEXT,
+    logo_uri TEXT,
+    redirect_uris TEXT NOT NULL DEFAULT '[]',
+    allowed_scopes TEXT NOT NULL DEFAULT '["openid"]',
+    grant_types TEXT NOT NULL DEFAULT '["authorization_code"]',
+    response_types TEXT NOT NULL DEFAULT '["code"]',
+    token_endpoint_auth_method TEXT DEFAULT 'client_secret_basic',
+    access_token_lifetime INTEGER DEFAULT 3600,
+    refresh_token_lifetime INTEGER DEFAULT 2592000,
+    id_token_lifetime INTEGER DEFAULT 3600,
+    owner_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    is_trusted BOOLEAN DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS authorization_codes (
+    id TEXT PRIMARY KEY,
+    code TEXT UNIQUE NOT NULL,
+    client_id TEXT NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    state TEXT,
+    nonce TEXT,
+    code_challenge TEXT,
+    code_challenge_method TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    is_used BOOLEAN DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS access_tokens (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    client_id TEXT NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL,
+    token_type TEXT DEFAULT 'Bearer',
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    is_revoked BOOLEAN DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_tenant ON access_tokens(tenant_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    access_token_id TEXT REFERENCES access_tokens(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    is_revoked BOOLEAN DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_tenant ON refresh_tokens(tenant_id);
+
+CREATE TABLE IF NOT EXISTS openid_keys (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    algorithm TEXT NOT NULL,
+    public_key TEXT NOT NULL,
+    private_key_encrypted BLOB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- -----------------------------------------------------------------------------
+-- 5. Seeding
+-- -----------------------------------------------------------------------------
+
+INSERT INTO rbac_permissions (id, name, description) VALUES
+    ('10000000-0000-0000-0000-000000000001', 'platform:manage_tenants', 'Create, update, and delete tenants'),
+    ('10000000-0000-0000-0000-000000000002', 'platform:manage_admins', 'Manage platform administrators'),
+    ('10000000-0000-0000-0000-000000000003', 'platform:view_audit', 'View platform audit logs'),
+    ('10000000-0000-0000-0000-000000000004', 'platform:bootstrap', 'Execute bootstrap operations'),
+    ('10000000-0000-0000-0000-000000000005', 'tenant:manage_users', 'Manage users in a tenant'),
+    ('10000000-0000-0000-0000-000000000006', 'tenant:manage_clients', 'Manage OAuth2 clients'),
+    ('10000000-0000-0000-0000-000000000007', 'tenant:manage_settings', 'Manage tenant settings'),
+    ('10000000-0000-0000-0000-000000000008', 'tenant:view_users', 'View users in a tenant'),
+    ('10000000-0000-0000-0000-000000000009', 'tenant:view', 'View tenant metadata'),
+    ('10000000-0000-0000-0000-000000000010', 'tenant:view_audit', 'View tenant audit logs'),
+    ('10000000-0000-0000-0000-000000000011', 'user:read_profile', 'Read own profile'),
+    ('10000000-0000-0000-0000-000000000012', 'user:write_profile', 'Update own profile'),
+    ('10000000-0000-0000-0000-000000000013', 'user:change_password', 'Change own password'),
+    ('10000000-0000-0000-0000-000000000014', 'user:manage_sessions', 'Manage own sessions'),
+    ('10000000-0000-0000-0000-000000000015', 'client:token_introspect', 'Introspect tokens'),
+    ('10000000-0000-0000-0000-000000000016', 'client:token_revoke', 'Revoke tokens')
+ON CONFLICT (id) DO UPDATE SET name = excluded.name, description = excluded.description;
+
+INSERT INTO rbac_roles (id, name, scope, description) VALUES
+    ('20000000-0000-0000-0000-000000000001', 'platform_admin', 'platform', 'Platform-wide administrator'),
+    ('20000000-0000-0000-0000-000000000002', 'tenant_admin', 'tenant', 'Administrator for a specific tenant'),
+    ('20000000-0000-0000-0000-000000000003', 'member', 'tenant', 'Regular member of a tenant')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO rbac_role_permissions (role_id, permission_id)
+SELECT '20000000-0000-0000-0000-000000000001', id FROM rbac_permissions WHERE true
+ON CONFLICT DO NOTHING;
+
+INSERT INTO rbac_role_permissions (role_id, permission_id) VALUES
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000005'),
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000006'),
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000007'),
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000008'),
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000009'),
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000010')
+ON CONFLICT DO NOTHING;
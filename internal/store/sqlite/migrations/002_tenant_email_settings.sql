@@ -0,0 +1,15 @@
+-- 002_tenant_email_settings.sql (SQLite)
+
+CREATE TABLE IF NOT EXISTS tenant_email_settings (
+    tenant_id TEXT PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    smtp_host TEXT NOT NULL,
+    smtp_port INTEGER NOT NULL,
+    smtp_username TEXT,
+    smtp_password_encrypted BLOB,
+    from_address TEXT NOT NULL,
+    from_name TEXT,
+    tls_mode TEXT NOT NULL DEFAULT 'starttls' CHECK (tls_mode IN ('starttls', 'implicit', 'none')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- 003_tenant_listing_indexes.sql (SQLite)
+
+CREATE INDEX IF NOT EXISTS idx_tenants_created_at_id ON tenants(created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tenants_name_id ON tenants(name, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tenants_status ON tenants(status) WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- 005_audit_events.sql (SQLite)
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    actor_id TEXT NOT NULL DEFAULT '',
+    resource TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    metadata TEXT,
+    occurred_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_id_id ON audit_events(tenant_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_type_id ON audit_events(tenant_id, type, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_actor_id ON audit_events(tenant_id, actor_id, id);
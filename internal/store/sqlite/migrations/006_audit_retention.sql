@@ -0,0 +1,10 @@
+-- 006_audit_retention.sql (SQLite)
+
+CREATE TABLE IF NOT EXISTS audit_retention_policies (
+    tenant_id TEXT PRIMARY KEY,
+    retention_days INTEGER NOT NULL CHECK (retention_days >= 0),
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events(occurred_at);
@@ -0,0 +1,3 @@
+-- 007_audit_schema_version.sql (SQLite)
+
+ALTER TABLE audit_events ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- 008_audit_request_id.sql (SQLite)
+
+ALTER TABLE audit_events ADD COLUMN request_id TEXT NOT NULL DEFAULT '';
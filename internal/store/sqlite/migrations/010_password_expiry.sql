@@ -0,0 +1,3 @@
+-- 010_password_expiry.sql (SQLite)
+
+ALTER TABLE credentials ADD COLUMN expires_at TIMESTAMP;
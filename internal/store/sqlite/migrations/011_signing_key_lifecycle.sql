@@ -0,0 +1,7 @@
+-- 011_signing_key_lifecycle.sql (SQLite)
+
+ALTER TABLE openid_keys ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE openid_keys ADD COLUMN activated_at TIMESTAMP;
+ALTER TABLE openid_keys ADD COLUMN retired_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_openid_keys_status_expires_at ON openid_keys(status, expires_at);
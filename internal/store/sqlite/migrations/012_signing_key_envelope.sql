@@ -0,0 +1,4 @@
+-- 012_signing_key_envelope.sql (SQLite)
+
+ALTER TABLE openid_keys ADD COLUMN data_key_encrypted BLOB;
+ALTER TABLE openid_keys ADD COLUMN kms_key_id TEXT NOT NULL DEFAULT '';
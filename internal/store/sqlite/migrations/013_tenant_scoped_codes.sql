@@ -0,0 +1,12 @@
+-- 013_tenant_scoped_codes.sql (SQLite)
+-- SQLite cannot add a NOT NULL column without a default; the repository always writes tenant_id.
+
+ALTER TABLE authorization_codes ADD COLUMN tenant_id TEXT REFERENCES tenants(id) ON DELETE CASCADE;
+
+UPDATE authorization_codes SET tenant_id = (
+    SELECT tenant_id FROM oauth2_clients WHERE oauth2_clients.client_id = authorization_codes.client_id
+) WHERE tenant_id IS NULL;
+
+DELETE FROM authorization_codes WHERE tenant_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_authorization_codes_tenant ON authorization_codes(tenant_id);
@@ -0,0 +1,5 @@
+-- 014_record_versions.sql (SQLite)
+-- Row versions for optimistic concurrency: updates must name the version they were based on.
+
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE oauth2_clients ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
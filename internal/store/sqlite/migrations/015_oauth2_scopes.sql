@@ -0,0 +1,13 @@
+-- 015_oauth2_scopes.sql (SQLite)
+
+CREATE TABLE IF NOT EXISTS oauth2_scopes (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    claims TEXT NOT NULL DEFAULT '[]', -- JSON array
+    permissions TEXT NOT NULL DEFAULT '[]', -- JSON array
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, name)
+);
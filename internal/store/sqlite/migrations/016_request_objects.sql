@@ -0,0 +1,7 @@
+-- 016_request_objects.sql (SQLite)
+-- Client metadata for JWT-secured authorization requests (RFC 9101): the public keys verifying the
+-- client's request objects, the URLs they may be fetched from, and whether they are required.
+
+ALTER TABLE oauth2_clients ADD COLUMN jwks TEXT;
+ALTER TABLE oauth2_clients ADD COLUMN request_uris TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE oauth2_clients ADD COLUMN require_signed_request_object BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- 017_access_token_claims.sql (SQLite)
+-- Custom claims added to an access token at issuance by a claim enricher, reported by introspection.
+
+ALTER TABLE access_tokens ADD COLUMN claims TEXT;
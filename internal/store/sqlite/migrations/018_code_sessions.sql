@@ -0,0 +1,5 @@
+-- 018_code_sessions.sql (SQLite)
+-- The login session an authorization code was issued under, so that a code cannot be redeemed
+-- once its session has ended. Codes issued before this migration have no session.
+
+ALTER TABLE authorization_codes ADD COLUMN session_id TEXT NOT NULL DEFAULT '';
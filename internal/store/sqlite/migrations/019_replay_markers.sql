@@ -0,0 +1,12 @@
+-- 019_replay_markers.sql (SQLite)
+-- One-time identifiers, such as the jti of client assertions, DPoP proofs and logout tokens, kept
+-- until the token carrying them expires so that a replay is refused.
+
+CREATE TABLE IF NOT EXISTS replay_markers (
+    kind TEXT NOT NULL,
+    id TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (kind, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_replay_markers_expires_at ON replay_markers(expires_at);
@@ -0,0 +1,5 @@
+-- 020_session_namespace.sql (SQLite)
+-- Plane a session was issued for (auth or admin). Sessions created before namespaces existed have
+-- none and are refused by both planes, so their users sign in again.
+
+ALTER TABLE sessions ADD COLUMN namespace TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- 021_pending_authorizations.sql (SQLite)
+-- Authorization requests made before the user signed in, kept briefly so that they resume once
+-- the user has authenticated.
+
+CREATE TABLE IF NOT EXISTS pending_authorizations (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    params TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_authorizations_expires_at ON pending_authorizations(expires_at);
@@ -0,0 +1,5 @@
+-- 022_client_origins.sql (SQLite)
+-- Browser origins allowed to call the token and revocation endpoints for a client. Empty derives
+-- them from the client's redirect URIs.
+
+ALTER TABLE oauth2_clients ADD COLUMN allowed_origins TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,5 @@
+-- 023_client_groups.sql (SQLite)
+-- Clients of a tenant sharing a group may be named as additional ID token audiences of each other.
+-- Empty puts the client in no group.
+
+ALTER TABLE oauth2_clients ADD COLUMN client_group TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- 024_client_application_types.sql (SQLite)
+-- Application type of a client (web, native, spa, service), restricting its other metadata. Empty
+-- for clients registered without one.
+
+ALTER TABLE oauth2_clients ADD COLUMN application_type TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- 025_protocol_log_settings.sql (SQLite)
+
+CREATE TABLE IF NOT EXISTS protocol_log_settings (
+    tenant_id TEXT PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP,
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,4 @@
+-- 027_client_jwks_uri.sql (SQLite)
+-- URL a client publishes its public keys at (RFC 7591 Section 2), used instead of a registered jwks.
+
+ALTER TABLE oauth2_clients ADD COLUMN jwks_uri TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,28 @@
+-- 028_tenant_memberships.sql (SQLite)
+-- Tenants a user is a member of. A user is a member of the tenant they are registered in (users.tenant_id,
+-- the home membership) and of any tenant a platform admin added them to, so one identity can sign in to
+-- several tenants and hold roles in each. Platform users have no home tenant and no memberships.
+
+CREATE TABLE IF NOT EXISTS tenant_memberships (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    is_home BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE(tenant_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_user_id ON tenant_memberships(user_id);
+
+-- Existing users are members of their home tenant; a home membership takes the ID of its user
+INSERT OR IGNORE INTO tenant_memberships (id, tenant_id, user_id, is_home, created_at)
+SELECT id, tenant_id, id, TRUE, created_at FROM users WHERE tenant_id IS NOT NULL;
+
+-- New users become members of their home tenant as they are created
+CREATE TRIGGER IF NOT EXISTS users_home_membership AFTER INSERT ON users
+WHEN NEW.tenant_id IS NOT NULL
+BEGIN
+    INSERT OR IGNORE INTO tenant_memberships (id, tenant_id, user_id, is_home, created_at)
+    VALUES (NEW.id, NEW.tenant_id, NEW.id, TRUE, NEW.created_at);
+END;
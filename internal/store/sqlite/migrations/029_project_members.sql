@@ -0,0 +1,34 @@
+-- 029_project_members.sql (SQLite)
+-- Projects belong to a tenant, and users hold a project role (project_admin or project_member) in the
+-- projects they are members of. Project roles are fixed in code, so they are stored by name rather than
+-- as RBAC roles; tenant:manage_projects lets tenant admins manage every project of their tenant.
+
+ALTER TABLE projects ADD COLUMN tenant_id TEXT REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- Existing projects belong to the tenant of their owner
+UPDATE projects SET tenant_id = (SELECT u.tenant_id FROM users u WHERE u.id = projects.owner_id)
+WHERE tenant_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_projects_tenant_id_id ON projects(tenant_id, id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('project_admin', 'project_member')),
+    granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    granted_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+-- Owners administer their existing projects
+INSERT OR IGNORE INTO project_members (project_id, user_id, role, granted_at)
+SELECT id, owner_id, 'project_admin', created_at FROM projects;
+
+INSERT OR IGNORE INTO rbac_permissions (id, name, description) VALUES
+    ('10000000-0000-0000-0000-000000000017', 'tenant:manage_projects', 'Manage projects in a tenant');
+
+INSERT OR IGNORE INTO rbac_role_permissions (role_id, permission_id) VALUES
+    ('20000000-0000-0000-0000-000000000001', '10000000-0000-0000-0000-000000000017'),
+    ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000017');
@@ -0,0 +1,13 @@
+-- 030_tenant_security_policies.sql (SQLite)
+
+CREATE TABLE IF NOT EXISTS tenant_security_policies (
+    tenant_id TEXT PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    lockout_max_attempts INTEGER NOT NULL DEFAULT 0,
+    lockout_duration_seconds INTEGER NOT NULL DEFAULT 0,
+    login_rps REAL NOT NULL DEFAULT 0,
+    login_burst INTEGER NOT NULL DEFAULT 0,
+    token_rps REAL NOT NULL DEFAULT 0,
+    token_burst INTEGER NOT NULL DEFAULT 0,
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+-- 032_password_change.sql (SQLite)
+-- Sessions restricted to changing an expired or temporary password, and the maximum password age
+-- of a tenant's security policy (zero inherits the platform's).
+
+ALTER TABLE sessions ADD COLUMN password_change_required BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenant_security_policies ADD COLUMN password_max_age_seconds INTEGER NOT NULL DEFAULT 0;
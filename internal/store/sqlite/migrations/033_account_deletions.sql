@@ -0,0 +1,9 @@
+-- 033_account_deletions.sql (SQLite)
+
+CREATE TABLE IF NOT EXISTS account_deletions (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    requested_at TIMESTAMP NOT NULL,
+    delete_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_deletions_delete_at ON account_deletions(delete_at);
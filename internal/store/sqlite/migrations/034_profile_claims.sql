@@ -0,0 +1,5 @@
+-- 034_profile_claims.sql (SQLite)
+-- The website and birthdate profile claims (OIDC Core Section 5.1).
+
+ALTER TABLE users ADD COLUMN website TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN birthdate TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- 035_token_client_index.sql (SQLite)
+-- Index tokens by client so that a client's tokens can be revoked in batches without scanning
+-- the whole tables.
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_client ON access_tokens(client_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_client ON refresh_tokens(client_id);
@@ -0,0 +1,52 @@
+-- 036_live_user_email.sql (SQLite)
+-- An email is unique among the live users of a tenant only, so a soft-deleted user no longer blocks
+-- creating a user, or changing an email, to the address it had until the janitor purges it.
+-- SQLite cannot drop a table constraint, so the users table is rebuilt without UNIQUE(tenant_id, email)
+-- and its indexes and trigger are recreated. DB.Migrate runs this script only while the constraint is
+-- still there, inside a transaction with foreign keys off, so dropping the old table cascades nowhere.
+
+CREATE TABLE users_rebuild (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT REFERENCES tenants(id) ON DELETE RESTRICT,
+    email TEXT NOT NULL,
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    given_name TEXT,
+    family_name TEXT,
+    full_name TEXT,
+    nickname TEXT,
+    picture TEXT,
+    locale TEXT,
+    timezone TEXT,
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    website TEXT NOT NULL DEFAULT '',
+    birthdate TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO users_rebuild (
+    id, tenant_id, email, email_verified, given_name, family_name, full_name, nickname, picture, locale,
+    timezone, failed_login_attempts, locked_until, created_at, updated_at, deleted_at, version, website, birthdate
+)
+SELECT
+    id, tenant_id, email, email_verified, given_name, family_name, full_name, nickname, picture, locale,
+    timezone, failed_login_attempts, locked_until, created_at, updated_at, deleted_at, version, website, birthdate
+FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_rebuild RENAME TO users;
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email_live ON users(tenant_id, email) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER IF NOT EXISTS users_home_membership AFTER INSERT ON users
+WHEN NEW.tenant_id IS NOT NULL
+BEGIN
+    INSERT OR IGNORE INTO tenant_memberships (id, tenant_id, user_id, is_home, created_at)
+    VALUES (NEW.id, NEW.tenant_id, NEW.id, TRUE, NEW.created_at);
+END;
@@ -0,0 +1,74 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// ProtocolLogRepository implements oauth2.ProtocolLogRepository
+type ProtocolLogRepository struct {
+	db *DB
+}
+
+// NewProtocolLogRepository creates a new protocol log settings repository
+func NewProtocolLogRepository(db *DB) *ProtocolLogRepository {
+	return &ProtocolLogRepository{db: db}
+}
+
+// Get retrieves a tenant's protocol log settings
+func (r *ProtocolLogRepository) Get(ctx context.Context, tenantID string) (*oauth2.ProtocolLogSettings, error) {
+	var p oauth2.ProtocolLogSettings
+	var expiresAt sql.NullTime
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT tenant_id, enabled, expires_at, updated_by, updated_at
+		FROM protocol_log_settings
+		WHERE tenant_id = ?
+	`, tenantID).Scan(&p.TenantID, &p.Enabled, &expiresAt, &p.UpdatedBy, &p.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauth2.ErrProtocolLogNotFound
+		}
+		return nil, fmt.Errorf("failed to get protocol log settings: %w", err)
+	}
+	if expiresAt.Valid {
+		p.ExpiresAt = &expiresAt.Time
+	}
+	return &p, nil
+}
+
+// Upsert creates or replaces a tenant's protocol log settings
+func (r *ProtocolLogRepository) Upsert(ctx context.Context, p *oauth2.ProtocolLogSettings) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO protocol_log_settings (tenant_id, enabled, expires_at, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			expires_at = excluded.expires_at,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, p.TenantID, p.Enabled, nullTimestamp(p.ExpiresAt), p.UpdatedBy, timestamp(p.UpdatedAt))
+
+	if err != nil {
+		return fmt.Errorf("failed to save protocol log settings: %w", err)
+	}
+	return nil
+}
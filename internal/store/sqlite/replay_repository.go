@@ -0,0 +1,64 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/replay"
+)
+
+// ReplayRepository implements replay.Repository
+type ReplayRepository struct {
+	db *DB
+}
+
+// NewReplayRepository creates a new replay marker repository
+func NewReplayRepository(db *DB) *ReplayRepository {
+	return &ReplayRepository{db: db}
+}
+
+// MarkOnce inserts the marker, or takes over an expired one the janitor has not removed yet
+func (r *ReplayRepository) MarkOnce(ctx context.Context, kind, id string, ttl time.Duration) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO replay_markers (kind, id, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (kind, id) DO UPDATE SET expires_at = excluded.expires_at
+		WHERE replay_markers.expires_at < ?
+	`, kind, id, timestamp(time.Now().Add(ttl)), timestamp(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to mark %s: %w", kind, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return replay.ErrReplayed
+	}
+	return nil
+}
+
+// DeleteExpired deletes up to limit expired markers and returns how many were removed
+func (r *ReplayRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM replay_markers WHERE (kind, id) IN (
+			SELECT kind, id FROM replay_markers WHERE expires_at < ? LIMIT ?
+		)
+	`, timestamp(time.Now()), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired replay markers: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,1251 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/replay"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// platformAdminRoleID is the seeded platform_admin role
+const platformAdminRoleID = "20000000-0000-0000-0000-000000000001"
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	ctx := context.Background()
+	db, err := New(ctx, Config{Path: MemoryPath})
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	for _, script := range Migrations() {
+		require.NoError(t, db.Migrate(ctx, script))
+	}
+	return db
+}
+
+// TestPurpose: Validates that migrations are idempotent and seed the scoped RBAC roles with their permissions.
+// Scope: Database Unit Test
+// Security: Authorization baseline (seeded roles must carry the same permissions as on PostgreSQL)
+// Expected: Re-running migrations succeeds; tenant_admin carries tenant:manage_users and tenant:manage_projects.
+// Test Case ID: STO-01
+func TestSQLite_Migrations_SeedRoles(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	for _, script := range Migrations() {
+		require.NoError(t, db.Migrate(ctx, script))
+	}
+
+	role, err := NewRoleRepository(db).GetByName(ctx, authz.RoleTenantAdmin, authz.ScopeTenant)
+	require.NoError(t, err)
+	assert.Contains(t, role.Permissions, authz.PermTenantManageUsers)
+	assert.Contains(t, role.Permissions, authz.PermTenantManageProjects)
+
+	platform, err := NewRoleRepository(db).GetByName(ctx, authz.RolePlatformAdmin, authz.ScopePlatform)
+	require.NoError(t, err)
+	assert.Len(t, platform.Permissions, 17)
+}
+
+// TestPurpose: Validates that user lookup by email is scoped by tenant, including the NULL tenant of platform admins.
+// Scope: Database Unit Test
+// Security: Multi-tenant Data Separation (CWE-284)
+// Expected: A user is only found within its own tenant; a platform user is only found with a nil tenant; ListByEmail, used to choose an account at login, returns both.
+// Test Case ID: STO-02
+func TestSQLite_UserRepository_TenantIsolation(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	tenants := NewTenantRepository(db)
+	users := NewUserRepository(db)
+
+	tenantA, tenantB := "tenant-a", "tenant-b"
+	for _, id := range []string{tenantA, tenantB} {
+		require.NoError(t, tenants.Create(ctx, &tenant.Tenant{ID: id, Name: id, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	}
+
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "user-a", TenantID: &tenantA, Email: "shared@example.com"}))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "admin", Email: "shared@example.com"}))
+
+	found, err := users.GetByEmail(ctx, &tenantA, "shared@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "user-a", found.ID)
+
+	_, err = users.GetByEmail(ctx, &tenantB, "shared@example.com")
+	assert.ErrorIs(t, err, identity.ErrUserNotFound)
+
+	found, err = users.GetByEmail(ctx, nil, "shared@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", found.ID)
+	assert.Nil(t, found.TenantID)
+
+	// Account selection at login lists the email in every tenant
+	all, err := users.ListByEmail(ctx, "shared@example.com")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+// TestPurpose: Validates that platform-scoped grants are not duplicated even though SQLite treats NULLs as distinct in UNIQUE constraints.
+// Scope: Database Unit Test
+// Security: Privilege assignment integrity
+// Expected: Granting the same platform role twice yields a single assignment.
+// Test Case ID: STO-03
+func TestSQLite_AssignmentRepository_PlatformGrantIdempotent(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, NewUserRepository(db).Create(ctx, &identity.User{ID: "admin", Email: "admin@example.com"}))
+
+	assignments := NewAssignmentRepository(db)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, assignments.Grant(ctx, &authz.Assignment{
+			ID:        fmt.Sprintf("assignment-%d", i),
+			UserID:    "admin",
+			RoleID:    platformAdminRoleID,
+			Scope:     authz.ScopePlatform,
+			GrantedAt: time.Now(),
+		}))
+	}
+
+	list, err := assignments.ListForUser(ctx, "admin", pagination.Params{})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	exists, err := assignments.CheckExists(ctx, platformAdminRoleID, authz.ScopePlatform, nil)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+// TestPurpose: Validates keyset pagination over text-encoded timestamps.
+// Scope: Database Unit Test
+// Security: N/A (correctness of admin listing)
+// Expected: Paging newest-first visits every tenant exactly once in creation order.
+// Test Case ID: STO-04
+func TestSQLite_TenantRepository_ListPagination(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewTenantRepository(db)
+
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		created := base.Add(time.Duration(i) * 1500 * time.Millisecond)
+		require.NoError(t, repo.Create(ctx, &tenant.Tenant{
+			ID: fmt.Sprintf("t%d", i), Name: fmt.Sprintf("Tenant %d", i), Status: tenant.StatusActive,
+			CreatedAt: created, UpdatedAt: created,
+		}))
+	}
+
+	var ids []string
+	opts := tenant.ListOptions{Limit: 2, Sort: tenant.SortCreatedDesc}
+	for {
+		page, err := repo.List(ctx, opts)
+		require.NoError(t, err)
+		for _, tn := range page.Tenants {
+			ids = append(ids, tn.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"t4", "t3", "t2", "t1", "t0"}, ids)
+
+	page, err := repo.List(ctx, tenant.ListOptions{Limit: 10, Query: "tenant 3"})
+	require.NoError(t, err)
+	require.Len(t, page.Tenants, 1)
+	assert.Equal(t, "t3", page.Tenants[0].ID)
+}
+
+// TestPurpose: Validates that email settings are upserted in place and credentials round-trip as bytes.
+// Scope: Database Unit Test
+// Security: Encrypted credential storage must not be truncated or re-encoded
+// Expected: A second upsert replaces the row; encrypted bytes are returned unchanged.
+// Test Case ID: STO-05
+func TestSQLite_EmailSettingsRepository_Upsert(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: "t1", Name: "t1", Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+
+	repo := NewEmailSettingsRepository(db)
+	settings := &email.Settings{
+		TenantID: "t1", Host: "smtp.example.com", Port: 587, FromAddress: "a@example.com",
+		TLSMode: email.TLSModeStartTLS, PasswordEncrypted: []byte{0x00, 0xff, 0x10},
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repo.Upsert(ctx, settings))
+
+	settings.Port = 465
+	settings.TLSMode = email.TLSModeImplicit
+	require.NoError(t, repo.Upsert(ctx, settings))
+
+	got, err := repo.Get(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, 465, got.Port)
+	assert.Equal(t, []byte{0x00, 0xff, 0x10}, got.PasswordEncrypted)
+
+	require.NoError(t, repo.Delete(ctx, "t1"))
+	_, err = repo.Get(ctx, "t1")
+	assert.ErrorIs(t, err, email.ErrSettingsNotFound)
+}
+
+// TestPurpose: Validates keyset pagination of client listing by UUIDv7 id.
+// Scope: Database Unit Test
+// Security: Multi-tenant Data Separation (CWE-284); bounded result sets
+// Expected: Paging visits every client of the tenant once in creation order and never returns another tenant's clients.
+// Test Case ID: STO-06
+func TestSQLite_ClientRepository_ListByTenantPagination(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	for _, tid := range []string{"t1", "t2"} {
+		require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: tid, Name: tid, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	}
+
+	repo := NewClientRepository(db)
+	var want []string
+	for i := 0; i < 5; i++ {
+		tenantID := "t1"
+		if i == 2 {
+			tenantID = "t2"
+		}
+		clientID := id.NewUUIDv7()
+		require.NoError(t, repo.Create(ctx, &oauth2.Client{
+			ID: clientID, ClientID: fmt.Sprintf("client-%d", i), TenantID: tenantID, ClientName: "app",
+			CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}))
+		if tenantID == "t1" {
+			want = append(want, clientID)
+		}
+	}
+
+	var got []string
+	p := pagination.Params{Limit: 3}
+	for {
+		clients, err := repo.ListByTenant(ctx, "t1", p)
+		require.NoError(t, err)
+		for _, c := range clients {
+			assert.Equal(t, "t1", c.TenantID)
+			got = append(got, c.ID)
+		}
+		if len(clients) < p.Limit {
+			break
+		}
+		p.After = clients[len(clients)-1].ID
+	}
+	assert.Equal(t, want, got)
+}
+
+// TestPurpose: Validates batched expiry deletes and the purge of soft-deleted rows past retention.
+// Scope: Database Unit Test
+// Security: Data minimisation (expired credentials and deleted accounts do not accumulate)
+// Expected: DeleteExpired honours the batch limit; a deleted tenant is kept while it still has users and purged after them.
+// Test Case ID: STO-07
+func TestSQLite_Purge(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	tenants := NewTenantRepository(db)
+	users := NewUserRepository(db)
+
+	tenantID := "t1"
+	require.NoError(t, tenants.Create(ctx, &tenant.Tenant{ID: tenantID, Name: tenantID, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u1", TenantID: &tenantID, Email: "u1@example.com"}))
+
+	sessions := NewSessionRepository(db)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sessions.Create(ctx, &session.Session{
+			ID: fmt.Sprintf("s%d", i), TenantID: &tenantID, UserID: "u1",
+			ExpiresAt: time.Now().Add(-time.Minute), CreatedAt: time.Now(), LastSeenAt: time.Now(),
+		}))
+	}
+	n, err := sessions.DeleteExpired(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	n, err = sessions.DeleteExpired(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	require.NoError(t, users.Delete(ctx, "u1"))
+	require.NoError(t, tenants.Delete(ctx, tenantID))
+
+	// Nothing is old enough yet
+	n, err = users.PurgeDeleted(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	later := time.Now().Add(time.Second)
+	n, err = tenants.PurgeDeleted(ctx, later, 10)
+	require.NoError(t, err)
+	assert.Zero(t, n, "tenant with remaining users must be kept")
+
+	n, err = users.PurgeDeleted(ctx, later, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = tenants.PurgeDeleted(ctx, later, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+// TestPurpose: Validates audit event storage, filters and pagination.
+// Scope: Database Unit Test
+// Security: Multi-tenant Data Separation (CWE-284) of the audit trail
+// Expected: Filters on type, actor and time range are applied; other tenants' and platform events are never returned; metadata round-trips.
+// Test Case ID: STO-08
+func TestSQLite_AuditRepository_List(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewAuditRepository(db)
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	events := []*audit.Event{
+		{Type: audit.TypeLoginFailed, TenantID: "t1", Metadata: map[string]any{audit.AttrEmail: "a@example.com"}},
+		{Type: audit.TypeClientCreated, TenantID: "t1", ActorID: "u1", SchemaVersion: 1, RequestID: "req-1"},
+		{Type: audit.TypeLoginFailed, TenantID: "t1"},
+		{Type: audit.TypeLoginFailed, TenantID: "t2"},
+		{Type: audit.TypePlatformAdminBootstrap},
+	}
+	for i, e := range events {
+		e.ID = id.NewUUIDv7()
+		e.Timestamp = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Create(ctx, e))
+	}
+
+	list, err := repo.List(ctx, audit.Filter{TenantID: "t1", Type: audit.TypeLoginFailed})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, events[0].ID, list[0].ID)
+	assert.Equal(t, "a@example.com", list[0].Metadata[audit.AttrEmail])
+	assert.True(t, events[0].Timestamp.Equal(list[0].Timestamp))
+
+	list, err = repo.List(ctx, audit.Filter{TenantID: "t1", ActorID: "u1"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, audit.TypeClientCreated, list[0].Type)
+	assert.Equal(t, 1, list[0].SchemaVersion)
+	assert.Equal(t, "req-1", list[0].RequestID)
+
+	list, err = repo.List(ctx, audit.Filter{TenantID: "t1", Since: events[1].Timestamp, Until: events[2].Timestamp})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, events[1].ID, list[0].ID)
+
+	list, err = repo.List(ctx, audit.Filter{TenantID: "t1", Page: pagination.Params{Limit: 2, After: events[1].ID}})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, events[2].ID, list[0].ID)
+
+	list, err = repo.List(ctx, audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, audit.TypePlatformAdminBootstrap, list[0].Type)
+}
+
+// TestPurpose: Validates expired audit event selection, deletion and retention policy storage.
+// Scope: Database Unit Test
+// Security: Retention purges must only touch events past their cutoff in the selected tenants
+// Expected: ListExpired honors the cutoff, tenant include and exclude lists and the limit; Delete removes by ID; policies round-trip.
+// Test Case ID: STO-09
+func TestSQLite_AuditRetention(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewAuditRepository(db)
+
+	old := time.Now().AddDate(0, 0, -100)
+	events := []*audit.Event{
+		{TenantID: "t1", Timestamp: old},
+		{TenantID: "t2", Timestamp: old},
+		{TenantID: "", Timestamp: old},
+		{TenantID: "t1", Timestamp: time.Now()},
+	}
+	for _, e := range events {
+		e.ID = id.NewUUIDv7()
+		e.Type = audit.TypeLoginSuccess
+		require.NoError(t, repo.Create(ctx, e))
+	}
+	cutoff := time.Now().AddDate(0, 0, -90)
+
+	expired, err := repo.ListExpired(ctx, audit.ExpiredFilter{Before: cutoff, TenantIDs: []string{"t1"}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, events[0].ID, expired[0].ID)
+
+	expired, err = repo.ListExpired(ctx, audit.ExpiredFilter{Before: cutoff, ExceptTenantIDs: []string{"t1"}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+	assert.Equal(t, events[1].ID, expired[0].ID)
+	assert.Equal(t, events[2].ID, expired[1].ID)
+
+	expired, err = repo.ListExpired(ctx, audit.ExpiredFilter{Before: cutoff, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+
+	n, err := repo.Delete(ctx, []string{events[0].ID, events[1].ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	expired, err = repo.ListExpired(ctx, audit.ExpiredFilter{Before: cutoff, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, events[2].ID, expired[0].ID)
+
+	policies := NewAuditRetentionRepository(db)
+	_, err = policies.Get(ctx, "t1")
+	assert.ErrorIs(t, err, audit.ErrRetentionNotFound)
+
+	require.NoError(t, policies.Upsert(ctx, &audit.RetentionPolicy{TenantID: "t1", Days: 2555, UpdatedBy: "u1", UpdatedAt: time.Now()}))
+	require.NoError(t, policies.Upsert(ctx, &audit.RetentionPolicy{TenantID: "t1", Days: 365, UpdatedBy: "u2", UpdatedAt: time.Now()}))
+	p, err := policies.Get(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, 365, p.Days)
+	assert.Equal(t, "u2", p.UpdatedBy)
+
+	list, err := policies.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, policies.Delete(ctx, "t1"))
+	assert.ErrorIs(t, policies.Delete(ctx, "t1"), audit.ErrRetentionNotFound)
+}
+
+// TestPurpose: Validates webhook subscription and delivery storage, including claiming and purging deliveries.
+// Scope: Database Unit Test
+// Security: Subscriptions are tenant scoped; a claimed delivery must not be claimed again while it is in flight
+// Expected: Subscriptions round-trip their event types; ClaimDue leases due pending rows only; DeleteFinished keeps pending rows; deleting a subscription cascades.
+// Test Case ID: STO-10
+func TestSQLite_WebhookRepositories(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: "t1", Name: "t1", Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+
+	subs := NewWebhookSubscriptionRepository(db)
+	sub := &webhook.Subscription{
+		ID: id.NewUUIDv7(), TenantID: "t1", URL: "https://hooks.example.com/in", EventTypes: []string{audit.TypeUserCreated},
+		SecretEncrypted: []byte{1, 2, 3}, Enabled: true, CreatedBy: "u1", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+	require.NoError(t, subs.Create(ctx, sub))
+
+	got, err := subs.Get(ctx, "t1", sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{audit.TypeUserCreated}, got.EventTypes)
+	assert.Equal(t, []byte{1, 2, 3}, got.SecretEncrypted)
+	_, err = subs.Get(ctx, "t2", sub.ID)
+	assert.ErrorIs(t, err, webhook.ErrSubscriptionNotFound)
+
+	got.EventTypes = nil
+	got.Enabled = false
+	require.NoError(t, subs.Update(ctx, got))
+	list, err := subs.List(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].EventTypes)
+	assert.False(t, list[0].Enabled)
+
+	deliveries := NewWebhookDeliveryRepository(db)
+	now := time.Now()
+	newDelivery := func(status string, next time.Time) *webhook.Delivery {
+		d := &webhook.Delivery{
+			ID: id.NewUUIDv7(), SubscriptionID: sub.ID, TenantID: "t1", EventID: id.NewUUIDv7(), EventType: audit.TypeUserCreated,
+			Payload: []byte(`{}`), Status: status, NextAttemptAt: next, CreatedAt: now, UpdatedAt: now.Add(-time.Hour),
+		}
+		require.NoError(t, deliveries.Create(ctx, d))
+		return d
+	}
+	due := newDelivery(webhook.StatusPending, now.Add(-time.Minute))
+	newDelivery(webhook.StatusPending, now.Add(time.Hour))
+	dead := newDelivery(webhook.StatusDead, now.Add(-time.Minute))
+
+	claimed, err := deliveries.ClaimDue(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+	claimed, err = deliveries.ClaimDue(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "a leased delivery is not claimed again")
+
+	attemptedAt := now
+	due.Status, due.Attempts, due.LastAttemptAt, due.ResponseStatus = webhook.StatusSucceeded, 1, &attemptedAt, 200
+	due.UpdatedAt = now.Add(time.Second)
+	require.NoError(t, deliveries.Update(ctx, due))
+	got2, err := deliveries.Get(ctx, "t1", due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.StatusSucceeded, got2.Status)
+	require.NotNil(t, got2.LastAttemptAt)
+
+	page, err := deliveries.List(ctx, webhook.DeliveryFilter{TenantID: "t1", SubscriptionID: sub.ID, Status: webhook.StatusDead})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, dead.ID, page[0].ID)
+
+	n, err := deliveries.DeleteFinished(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "only the dead delivery was finished before the cutoff")
+
+	require.NoError(t, subs.Delete(ctx, "t1", sub.ID))
+	page, err = deliveries.List(ctx, webhook.DeliveryFilter{TenantID: "t1", SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	assert.Empty(t, page, "deliveries are removed with their subscription")
+}
+
+// TestPurpose: Validates custom OAuth2 scope storage.
+// Scope: Database Unit Test
+// Security: Scope names are unique per tenant and not visible to other tenants
+// Expected: Scopes round-trip their claims and permissions, a duplicate name is rejected, and List orders by name.
+// Test Case ID: STO-19
+func TestSQLite_ScopeRepository(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: "t1", Name: "t1", Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+
+	scopes := NewScopeRepository(db)
+	newScope := func(name string) *oauth2.ScopeDefinition {
+		return &oauth2.ScopeDefinition{ID: id.NewUUIDv7(), TenantID: "t1", Name: name, Description: name, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	}
+	write := newScope("orders:write")
+	write.Claims, write.Permissions = []string{"email"}, []string{"orders.write"}
+	require.NoError(t, scopes.Create(ctx, write))
+	require.NoError(t, scopes.Create(ctx, newScope("orders:read")))
+	assert.ErrorIs(t, scopes.Create(ctx, newScope("orders:read")), oauth2.ErrScopeAlreadyExists)
+
+	got, err := scopes.Get(ctx, "t1", "orders:write")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email"}, got.Claims)
+	assert.Equal(t, []string{"orders.write"}, got.Permissions)
+	_, err = scopes.Get(ctx, "t2", "orders:write")
+	assert.ErrorIs(t, err, oauth2.ErrScopeNotFound)
+
+	got.Claims = nil
+	require.NoError(t, scopes.Update(ctx, got))
+	list, err := scopes.List(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "orders:read", list[0].Name)
+	assert.Empty(t, list[1].Claims)
+
+	require.NoError(t, scopes.Delete(ctx, "t1", "orders:read"))
+	assert.ErrorIs(t, scopes.Delete(ctx, "t1", "orders:read"), oauth2.ErrScopeNotFound)
+}
+
+// TestPurpose: Validates optimistic concurrency on user and client updates.
+// Scope: Database Unit Test
+// Security: Integrity of administrative edits (lost update prevention)
+// Expected: Updates bump the version; one based on a stale version fails with a conflict, a missing row with not found.
+// Test Case ID: STO-12
+func TestSQLite_UpdateVersionConflict(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: "t1", Name: "t1", Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+
+	users := NewUserRepository(db)
+	tenantID := "t1"
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u1", TenantID: &tenantID, Email: "u1@example.com"}))
+
+	stale, err := users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stale.Version)
+	fresh := *stale
+
+	fresh.Profile.FullName = "Fresh"
+	require.NoError(t, users.Update(ctx, &fresh))
+	assert.Equal(t, 2, fresh.Version)
+
+	stale.Profile.FullName = "Stale"
+	assert.ErrorIs(t, users.Update(ctx, stale), identity.ErrUserVersionConflict)
+
+	stored, err := users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "Fresh", stored.Profile.FullName)
+	assert.Equal(t, 2, stored.Version)
+
+	stale.ID = "missing"
+	assert.ErrorIs(t, users.Update(ctx, stale), identity.ErrUserNotFound)
+
+	clients := NewClientRepository(db)
+	require.NoError(t, clients.Create(ctx, &oauth2.Client{
+		ID: "c1", ClientID: "cid1", TenantID: "t1", ClientName: "Original",
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}))
+
+	staleClient, err := clients.GetByID(ctx, "c1")
+	require.NoError(t, err)
+	freshClient := *staleClient
+
+	freshClient.ClientName = "Renamed"
+	require.NoError(t, clients.Update(ctx, &freshClient))
+	assert.Equal(t, 2, freshClient.Version)
+
+	assert.ErrorIs(t, clients.Update(ctx, staleClient), oauth2.ErrClientVersionConflict)
+
+	staleClient.ID = "missing"
+	assert.ErrorIs(t, clients.Update(ctx, staleClient), oauth2.ErrClientNotFound)
+}
+
+// TestPurpose: Validates bulk session revocation by tenant and by client.
+// Scope: Database Unit Test
+// Security: Session Management (suspending a tenant or compromising a client must sign its users out); Multi-tenant Data Separation (CWE-284)
+// Expected: Sessions keep their namespace; deletes honour the batch limit; only sessions of users holding the client's tokens, or of the tenant, are removed.
+// Test Case ID: STO-18
+func TestSQLite_SessionRepository_BulkDelete(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	sessions := NewSessionRepository(db)
+
+	userTenant := map[string]string{"u1": "t1", "u2": "t1", "u3": "t2"}
+	for _, tid := range []string{"t1", "t2"} {
+		require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: tid, Name: tid, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	}
+	for _, uid := range []string{"u1", "u2", "u3"} {
+		tenantID := userTenant[uid]
+		require.NoError(t, NewUserRepository(db).Create(ctx, &identity.User{ID: uid, TenantID: &tenantID, Email: uid + "@example.com"}))
+	}
+	for i, uid := range []string{"u1", "u1", "u2", "u3"} {
+		tenantID := userTenant[uid]
+		require.NoError(t, sessions.Create(ctx, &session.Session{
+			ID: fmt.Sprintf("s%d", i), TenantID: &tenantID, UserID: uid, Namespace: session.NamespaceAdmin,
+			ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(), LastSeenAt: time.Now(), PasswordChangeRequired: i == 1,
+		}))
+	}
+	got, err := sessions.Get(ctx, "s0")
+	require.NoError(t, err)
+	assert.Equal(t, session.NamespaceAdmin, got.Namespace)
+	assert.False(t, got.PasswordChangeRequired)
+	got, err = sessions.Get(ctx, "s1")
+	require.NoError(t, err)
+	assert.True(t, got.PasswordChangeRequired)
+	require.NoError(t, NewClientRepository(db).Create(ctx, &oauth2.Client{
+		ID: id.NewUUIDv7(), ClientID: "client-1", TenantID: "t1", ClientName: "app", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}))
+	require.NoError(t, NewRefreshTokenRepository(db).Create(ctx, &oauth2.RefreshToken{
+		ID: id.NewUUIDv7(), TenantID: "t1", TokenHash: "hash", ClientID: "client-1", UserID: "u1",
+		ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+	}))
+
+	n, err := sessions.DeleteByClientID(ctx, "client-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = sessions.DeleteByClientID(ctx, "client-1", 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = sessions.Get(ctx, "s2")
+	require.NoError(t, err, "sessions of users without the client's tokens must be kept")
+
+	n, err = sessions.DeleteByTenantID(ctx, "t1", 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = sessions.Get(ctx, "s3")
+	require.NoError(t, err, "other tenants' sessions must be kept")
+}
+
+// TestPurpose: Validates that a one-time identifier is accepted once until it expires.
+// Scope: Database Unit Test
+// Security: Replay prevention (a jti seen before is refused while the token carrying it is valid)
+// Expected: A second MarkOnce is ErrReplayed; other kinds do not collide; an expired marker may be taken over; DeleteExpired removes only expired markers.
+// Test Case ID: STO-20
+func TestSQLite_ReplayRepository(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	markers := NewReplayRepository(db)
+
+	require.NoError(t, markers.MarkOnce(ctx, "dpop", "jti-1", time.Minute))
+	assert.ErrorIs(t, markers.MarkOnce(ctx, "dpop", "jti-1", time.Minute), replay.ErrReplayed)
+	require.NoError(t, markers.MarkOnce(ctx, "logout_token", "jti-1", time.Minute), "kinds must not collide")
+
+	require.NoError(t, markers.MarkOnce(ctx, "dpop", "jti-2", -time.Second))
+	require.NoError(t, markers.MarkOnce(ctx, "dpop", "jti-2", -time.Second), "an expired marker must be taken over")
+
+	n, err := markers.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.ErrorIs(t, markers.MarkOnce(ctx, "dpop", "jti-1", time.Minute), replay.ErrReplayed)
+}
+
+// TestPurpose: Validates that a pending authorization request is returned once, with its parameters, until it expires.
+// Scope: Database Unit Test
+// Security: Request integrity (a saved authorization request resumes once and never after it expires)
+// Expected: Consume returns the saved parameters and then ErrPendingAuthorizationNotFound; an expired request is not returned and DeleteExpired removes it.
+// Test Case ID: STO-21
+func TestSQLite_PendingAuthorizationRepository(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewPendingAuthorizationRepository(db)
+	now := time.Now()
+
+	params := url.Values{"client_id": {"client-1"}, "state": {"a b&c"}}
+	require.NoError(t, repo.Create(ctx, &oauth2.PendingAuthorization{ID: "p1", ClientID: "client-1", Params: params, ExpiresAt: now.Add(time.Minute), CreatedAt: now}))
+	require.NoError(t, repo.Create(ctx, &oauth2.PendingAuthorization{ID: "p2", ClientID: "client-1", Params: params, ExpiresAt: now.Add(-time.Second), CreatedAt: now}))
+
+	pending, err := repo.Consume(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, params, pending.Params)
+	assert.Equal(t, "client-1", pending.ClientID)
+	_, err = repo.Consume(ctx, "p1")
+	assert.ErrorIs(t, err, oauth2.ErrPendingAuthorizationNotFound)
+	_, err = repo.Consume(ctx, "p2")
+	assert.ErrorIs(t, err, oauth2.ErrPendingAuthorizationNotFound, "an expired request must not resume")
+
+	n, err := repo.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+// TestPurpose: Validates that repository queries honour the deadline of the request context.
+// Scope: Database Unit Test
+// Security: Availability (a request past its route timeout stops querying instead of holding a connection)
+// Expected: Queries made with an expired context fail with context.DeadlineExceeded; the same queries succeed with a live context.
+// Test Case ID: STO-22
+func TestSQLite_ContextDeadline(t *testing.T) {
+	db := newTestDB(t)
+	events := NewAuditRepository(db)
+	sessions := NewSessionRepository(db)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err := events.List(expired, audit.Filter{TenantID: "t1"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = sessions.Get(expired, "s1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = events.List(context.Background(), audit.Filter{TenantID: "t1"})
+	assert.NoError(t, err)
+}
+
+// TestPurpose: Validates the platform statistics aggregated from tenants and audit events.
+// Scope: Database Unit Test
+// Security: Operational visibility (platform stats are computed in the store without exposing raw events)
+// Expected: Deleted tenants are not counted; events outside the window are ignored; active users are distinct login_success actors; clients are ranked by tokens issued and limited.
+// Test Case ID: STO-23
+func TestSQLite_PlatformStats(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	tenants := NewTenantRepository(db)
+	for _, tn := range []*tenant.Tenant{
+		{ID: "t1", Name: "t1", Status: tenant.StatusActive},
+		{ID: "t2", Name: "t2", Status: tenant.StatusInactive},
+		{ID: "t3", Name: "t3", Status: tenant.StatusActive},
+	} {
+		tn.CreatedAt, tn.UpdatedAt = now, now
+		require.NoError(t, tenants.Create(ctx, tn))
+	}
+	require.NoError(t, tenants.Delete(ctx, "t3"))
+	counts, err := tenants.CountByStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{tenant.StatusActive: 1, tenant.StatusInactive: 1}, counts)
+
+	repo := NewAuditRepository(db)
+	events := []*audit.Event{
+		{Type: audit.TypeLoginSuccess, TenantID: "t1", ActorID: "u1"},
+		{Type: audit.TypeLoginSuccess, TenantID: "t1", ActorID: "u1"},
+		{Type: audit.TypeLoginSuccess, TenantID: "t1", ActorID: "u2"},
+		{Type: audit.TypeLoginFailed, TenantID: "t1"},
+		{Type: audit.TypeTokenIssued, TenantID: "t1", Metadata: map[string]any{"client_id": "app"}},
+		{Type: audit.TypeTokenIssued, TenantID: "t1", Metadata: map[string]any{"client_id": "app"}},
+		{Type: audit.TypeTokenIssued, TenantID: "t2", Metadata: map[string]any{"client_id": "cli"}},
+		{Type: audit.TypeTokenIssued, TenantID: "t2", Metadata: map[string]any{"client_id": "web"}},
+		{Type: audit.TypeLoginSuccess, TenantID: "t1", ActorID: "u3", Timestamp: now.Add(-2 * time.Hour)},
+	}
+	for _, e := range events {
+		e.ID = id.NewUUIDv7()
+		if e.Timestamp.IsZero() {
+			e.Timestamp = now
+		}
+		require.NoError(t, repo.Create(ctx, e))
+	}
+
+	stats, err := repo.Stats(ctx, audit.StatsFilter{Since: now.Add(-time.Hour), Until: now.Add(time.Second), TopClients: 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{audit.TypeLoginSuccess: 3, audit.TypeLoginFailed: 1, audit.TypeTokenIssued: 4}, stats.EventCounts)
+	assert.Equal(t, int64(2), stats.ActiveUsers)
+	assert.Equal(t, []audit.ClientStats{
+		{TenantID: "t1", ClientID: "app", TokensIssued: 2},
+		{TenantID: "t2", ClientID: "cli", TokensIssued: 1},
+	}, stats.TopClients)
+}
+
+// TestPurpose: Validates tenant memberships, including the home membership every tenant user gets on creation.
+// Scope: Database Unit Test
+// Security: Multi-tenant Data Separation (CWE-284) (an identity only reaches the tenants it is a member of)
+// Expected: A new tenant user is a home member of their tenant and a platform user of none; a second membership is listed after the home one and a duplicate is refused; memberships in deleted tenants are not listed; removed memberships are not found.
+// Test Case ID: STO-24
+func TestSQLite_TenantMemberships(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	tenants := NewTenantRepository(db)
+	users := NewUserRepository(db)
+	memberships := NewMembershipRepository(db)
+
+	tenantA, tenantB := "tenant-a", "tenant-b"
+	for _, id := range []string{tenantA, tenantB} {
+		require.NoError(t, tenants.Create(ctx, &tenant.Tenant{ID: id, Name: id, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	}
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "user-a", TenantID: &tenantA, Email: "a@example.com"}))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "admin", Email: "admin@example.com"}))
+
+	home, err := memberships.Get(ctx, tenantA, "user-a")
+	require.NoError(t, err)
+	assert.True(t, home.Home)
+	assert.Equal(t, "user-a", home.ID)
+	platform, err := memberships.ListByUser(ctx, "admin")
+	require.NoError(t, err)
+	assert.Empty(t, platform)
+
+	m := &tenant.Membership{ID: "m1", TenantID: tenantB, UserID: "user-a", CreatedAt: time.Now(), CreatedBy: "admin"}
+	require.NoError(t, memberships.Add(ctx, m))
+	assert.ErrorIs(t, memberships.Add(ctx, &tenant.Membership{ID: "m2", TenantID: tenantB, UserID: "user-a", CreatedAt: time.Now()}), tenant.ErrMembershipExists)
+
+	list, err := memberships.ListByUser(ctx, "user-a")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, tenantA, list[0].TenantID)
+	assert.Equal(t, tenantB, list[1].TenantID)
+	assert.False(t, list[1].Home)
+	assert.Equal(t, "admin", list[1].CreatedBy)
+
+	members, err := memberships.ListByTenant(ctx, tenantB)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, "user-a", members[0].UserID)
+
+	require.NoError(t, tenants.Delete(ctx, tenantB))
+	list, err = memberships.ListByUser(ctx, "user-a")
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, memberships.Remove(ctx, tenantB, "user-a"))
+	_, err = memberships.Get(ctx, tenantB, "user-a")
+	assert.ErrorIs(t, err, tenant.ErrMembershipNotFound)
+	assert.ErrorIs(t, memberships.Remove(ctx, tenantB, "user-a"), tenant.ErrMembershipNotFound)
+}
+
+// TestPurpose: Validates that projects belong to a tenant and record the roles their members hold.
+// Scope: Database Unit Test
+// Security: Multi-tenant Data Separation (CWE-284) and Authorization (project roles decide project-scoped permissions)
+// Expected: Projects are listed by tenant and by the users holding a role in them; setting a member's role replaces it; removing an unknown member is not found; deleted projects are no longer listed.
+// Test Case ID: STO-25
+func TestSQLite_ProjectMembers(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	tenants := NewTenantRepository(db)
+	users := NewUserRepository(db)
+	projects := NewProjectRepository(db)
+
+	tenantA, tenantB := "tenant-a", "tenant-b"
+	for _, id := range []string{tenantA, tenantB} {
+		require.NoError(t, tenants.Create(ctx, &tenant.Tenant{ID: id, Name: id, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	}
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "owner", TenantID: &tenantA, Email: "owner@example.com"}))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "viewer", TenantID: &tenantA, Email: "viewer@example.com"}))
+
+	now := time.Now()
+	for _, p := range []*authz.Project{
+		{ID: "p1", TenantID: tenantA, Name: "Billing", OwnerID: "owner", CreatedAt: now, UpdatedAt: now},
+		{ID: "p2", TenantID: tenantB, Name: "Other", OwnerID: "owner", CreatedAt: now, UpdatedAt: now},
+	} {
+		require.NoError(t, projects.Create(ctx, p))
+	}
+	got, err := projects.GetByID(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, tenantA, got.TenantID)
+	list, err := projects.ListByTenant(ctx, tenantA, pagination.Params{})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "p1", list[0].ID)
+
+	require.NoError(t, projects.SetMember(ctx, &authz.ProjectMember{ProjectID: "p1", UserID: "owner", Role: authz.RoleProjectAdmin, GrantedAt: now, GrantedBy: "owner"}))
+	require.NoError(t, projects.SetMember(ctx, &authz.ProjectMember{ProjectID: "p1", UserID: "viewer", Role: authz.RoleProjectAdmin, GrantedAt: now.Add(time.Second)}))
+	require.NoError(t, projects.SetMember(ctx, &authz.ProjectMember{ProjectID: "p1", UserID: "viewer", Role: authz.RoleProjectMember, GrantedAt: now.Add(time.Second), GrantedBy: "owner"}))
+
+	member, err := projects.GetMember(ctx, "p1", "viewer")
+	require.NoError(t, err)
+	assert.Equal(t, authz.RoleProjectMember, member.Role)
+	assert.Equal(t, "owner", member.GrantedBy)
+	members, err := projects.ListMembers(ctx, "p1")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "owner", members[0].UserID)
+	mine, err := projects.ListByUser(ctx, "viewer", pagination.Params{})
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, "p1", mine[0].ID)
+
+	require.NoError(t, projects.RemoveMember(ctx, "p1", "viewer"))
+	_, err = projects.GetMember(ctx, "p1", "viewer")
+	assert.ErrorIs(t, err, authz.ErrProjectMemberNotFound)
+	assert.ErrorIs(t, projects.RemoveMember(ctx, "p1", "viewer"), authz.ErrProjectMemberNotFound)
+
+	require.NoError(t, projects.Delete(ctx, "p1"))
+	list, err = projects.ListByTenant(ctx, tenantA, pagination.Params{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	mine, err = projects.ListByUser(ctx, "owner", pagination.Params{})
+	require.NoError(t, err)
+	assert.Empty(t, mine)
+}
+
+// TestPurpose: Validates the storage of email changes in progress.
+// Scope: Database Unit Test
+// Security: Account takeover protection (CWE-640); a change is found only by its current token, and a user has one change at a time
+// Expected: A new change replaces the user's earlier one; changes are found by user and token hash; only verified changes past their window are due and only expired pending ones are purged; deleting twice is not found.
+// Test Case ID: STO-26
+func TestSQLite_EmailChangeRepository(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+	repo := NewEmailChangeRepository(db)
+
+	for _, id := range []string{"u1", "u2", "u3"} {
+		require.NoError(t, users.Create(ctx, &identity.User{ID: id, Email: id + "@example.com"}))
+	}
+	now := time.Now()
+	require.NoError(t, repo.Create(ctx, &identity.EmailChange{ID: "c0", UserID: "u1", OldEmail: "u1@example.com", NewEmail: "a@example.com", Status: identity.EmailChangePending, TokenHash: "h0", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+	require.NoError(t, repo.Create(ctx, &identity.EmailChange{ID: "c1", UserID: "u1", OldEmail: "u1@example.com", NewEmail: "b@example.com", Status: identity.EmailChangePending, TokenHash: "h1", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+	require.NoError(t, repo.Create(ctx, &identity.EmailChange{ID: "c2", UserID: "u2", OldEmail: "u2@example.com", NewEmail: "c@example.com", Status: identity.EmailChangePending, TokenHash: "h2", ExpiresAt: now.Add(-time.Second), CreatedAt: now}))
+	require.NoError(t, repo.Create(ctx, &identity.EmailChange{ID: "c3", UserID: "u3", OldEmail: "u3@example.com", NewEmail: "d@example.com", Status: identity.EmailChangePending, TokenHash: "h3", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+
+	got, err := repo.GetByUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "b@example.com", got.NewEmail, "a new change must replace the earlier one")
+	_, err = repo.GetByTokenHash(ctx, "h0")
+	assert.ErrorIs(t, err, identity.ErrEmailChangeNotFound)
+
+	got.Status, got.TokenHash, got.ExpiresAt = identity.EmailChangeVerified, "r1", now.Add(-time.Second)
+	require.NoError(t, repo.Update(ctx, got))
+	got, err = repo.GetByTokenHash(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, identity.EmailChangeVerified, got.Status)
+
+	due, err := repo.ListDue(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "c1", due[0].ID)
+
+	n, err := repo.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "only the expired pending change is purged")
+	_, err = repo.GetByUser(ctx, "u2")
+	assert.ErrorIs(t, err, identity.ErrEmailChangeNotFound)
+
+	require.NoError(t, repo.Delete(ctx, "c1"))
+	assert.ErrorIs(t, repo.Delete(ctx, "c1"), identity.ErrEmailChangeNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, got), identity.ErrEmailChangeNotFound)
+}
+
+// TestPurpose: Validates the storage of account deletions and the erasure of a user record.
+// Scope: Database Unit Test
+// Security: Right to erasure (GDPR Art. 17); an erased user keeps no email, profile or credentials
+// Expected: A new deletion replaces the user's earlier one; only deletions past their grace period are due, soonest first; deleting twice is not found; Erase anonymizes the user, removes the credentials, role assignments and memberships and soft-deletes the record.
+// Test Case ID: STO-27
+func TestSQLite_AccountDeletions(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+	repo := NewAccountDeletionRepository(db)
+
+	for _, id := range []string{"u1", "u2"} {
+		require.NoError(t, users.Create(ctx, &identity.User{ID: id, Email: id + "@example.com", Profile: identity.Profile{GivenName: "Ada"}}))
+	}
+	now := time.Now()
+	require.NoError(t, repo.Create(ctx, &identity.AccountDeletion{UserID: "u1", RequestedAt: now, DeleteAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.Create(ctx, &identity.AccountDeletion{UserID: "u1", RequestedAt: now, DeleteAt: now.Add(-time.Minute)}))
+	require.NoError(t, repo.Create(ctx, &identity.AccountDeletion{UserID: "u2", RequestedAt: now, DeleteAt: now.Add(time.Hour)}))
+
+	got, err := repo.Get(ctx, "u1")
+	require.NoError(t, err)
+	assert.True(t, got.DeleteAt.Before(now), "a new deletion must replace the earlier one")
+
+	due, err := repo.ListDue(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "u1", due[0].UserID)
+
+	require.NoError(t, repo.Delete(ctx, "u1"))
+	assert.ErrorIs(t, repo.Delete(ctx, "u1"), identity.ErrAccountDeletionNotFound)
+	_, err = repo.Get(ctx, "u1")
+	assert.ErrorIs(t, err, identity.ErrAccountDeletionNotFound)
+
+	require.NoError(t, users.AddCredentials(ctx, &identity.Credentials{UserID: "u1", PasswordHash: "hash"}))
+	require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: "t1", Name: "t1", Status: tenant.StatusActive, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, NewMembershipRepository(db).Add(ctx, &tenant.Membership{ID: "m1", TenantID: "t1", UserID: "u1", CreatedAt: now}))
+	require.NoError(t, NewTenantRoleRepository(db).AssignRole(ctx, &tenant.TenantUserRole{ID: "r1", TenantID: "t1", UserID: "u1", Role: tenant.RoleTenantAdmin, GrantedAt: now, GrantedBy: tenant.GranterSystem}))
+	require.NoError(t, users.Erase(ctx, "u1", "u1@erased.invalid", now))
+	_, err = users.GetByID(ctx, "u1")
+	assert.ErrorIs(t, err, identity.ErrUserNotFound, "an erased user is soft-deleted")
+	var email, givenName string
+	require.NoError(t, db.db.QueryRowContext(ctx, `SELECT email, given_name FROM users WHERE id = ?`, "u1").Scan(&email, &givenName))
+	assert.Equal(t, "u1@erased.invalid", email)
+	assert.Empty(t, givenName)
+	_, err = users.GetCredentials(ctx, "u1")
+	assert.Error(t, err, "credentials are removed")
+	for _, table := range []string{"rbac_assignments", "tenant_memberships"} {
+		var n int
+		require.NoError(t, db.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table+` WHERE user_id = ?`, "u1").Scan(&n))
+		assert.Zero(t, n, "%s of an erased user are removed", table)
+	}
+	assert.ErrorIs(t, users.Erase(ctx, "missing", "missing@erased.invalid", now), identity.ErrUserNotFound)
+}
+
+// TestPurpose: Validates that every profile field, the OIDC profile claims included, is stored and updated.
+// Scope: Database Unit Test
+// Expected: The picture, website, birthdate, locale and time zone survive a create, an update and a lookup by email.
+// Test Case ID: STO-28
+func TestSQLite_UserRepository_ProfileClaims(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+
+	profile := identity.Profile{
+		GivenName: "Ada", Picture: "https://example.com/ada.png", Website: "https://ada.example.com",
+		Birthdate: "0000-12-10", Locale: "en-GB", Timezone: "Europe/London",
+	}
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u1", Email: "ada@example.com", Profile: profile}))
+	got, err := users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, profile, got.Profile)
+
+	got.Profile.Website, got.Profile.Birthdate = "https://lovelace.example.com", "1815-12-10"
+	require.NoError(t, users.Update(ctx, got))
+	found, err := users.GetByEmail(ctx, nil, "ada@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, got.Profile, found.Profile)
+}
+
+// TestPurpose: Validates the batched revocation of a tenant's or a client's tokens.
+// Scope: Database Unit Test
+// Security: Incident response (a force-logout invalidates every live token of a tenant or client)
+// Expected: Each call revokes at most limit tokens; expired, already revoked and other tenants' or clients' tokens are left alone.
+// Test Case ID: STO-29
+func TestSQLite_TokenRepository_BulkRevoke(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	access := NewAccessTokenRepository(db)
+	refresh := NewRefreshTokenRepository(db)
+
+	for _, tid := range []string{"t1", "t2"} {
+		require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: tid, Name: tid, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	}
+	for _, uid := range []string{"u1", "u2"} {
+		require.NoError(t, NewUserRepository(db).Create(ctx, &identity.User{ID: uid, Email: uid + "@example.com"}))
+	}
+	for _, c := range []struct{ clientID, tenantID string }{{"client-1", "t1"}, {"client-2", "t1"}, {"client-3", "t2"}} {
+		require.NoError(t, NewClientRepository(db).Create(ctx, &oauth2.Client{
+			ID: id.NewUUIDv7(), ClientID: c.clientID, TenantID: c.tenantID, ClientName: "app", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}))
+	}
+	tokens := []struct {
+		tenantID, clientID string
+		expiresIn          time.Duration
+	}{
+		{"t1", "client-1", time.Hour},
+		{"t1", "client-1", time.Hour},
+		{"t1", "client-2", time.Hour},
+		{"t1", "client-1", -time.Hour},
+		{"t2", "client-3", time.Hour},
+	}
+	for i, tok := range tokens {
+		require.NoError(t, access.Create(ctx, &oauth2.AccessToken{
+			ID: id.NewUUIDv7(), TenantID: tok.tenantID, TokenHash: fmt.Sprintf("at%d", i), ClientID: tok.clientID, UserID: "u1",
+			ExpiresAt: time.Now().Add(tok.expiresIn), CreatedAt: time.Now(),
+		}))
+		require.NoError(t, refresh.Create(ctx, &oauth2.RefreshToken{
+			ID: id.NewUUIDv7(), TenantID: tok.tenantID, TokenHash: fmt.Sprintf("rt%d", i), ClientID: tok.clientID, UserID: "u2",
+			ExpiresAt: time.Now().Add(tok.expiresIn), CreatedAt: time.Now(),
+		}))
+	}
+
+	n, err := refresh.RevokeByClientID(ctx, "client-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = refresh.RevokeByClientID(ctx, "client-1", 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "expired and already revoked tokens must be skipped")
+	rt, err := refresh.GetByTokenHash(ctx, "t1", "rt2")
+	require.NoError(t, err)
+	assert.False(t, rt.IsRevoked, "other clients' tokens must be kept")
+
+	n, err = access.RevokeByTenantID(ctx, "t1", 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	at, err := access.GetByTokenHash(ctx, "t1", "at0")
+	require.NoError(t, err)
+	assert.True(t, at.IsRevoked)
+	assert.NotNil(t, at.RevokedAt)
+	at, err = access.GetByTokenHash(ctx, "t2", "at4")
+	require.NoError(t, err)
+	assert.False(t, at.IsRevoked, "other tenants' tokens must be kept")
+}
+
+// TestPurpose: Validates that soft-deleted rows neither block new rows nor outlive their retention.
+// Scope: Database Unit Test
+// Security: Data minimisation (deleted projects do not accumulate), Integrity (emails stay unique among live users)
+// Expected: A deleted user's email can be taken by a new user while a second live user with it is rejected; deleted projects are purged with their members once old enough and live projects are kept.
+// Test Case ID: STO-32
+func TestSQLite_SoftDeleteLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	tenants := NewTenantRepository(db)
+	users := NewUserRepository(db)
+	projects := NewProjectRepository(db)
+
+	tenantID := "t1"
+	require.NoError(t, tenants.Create(ctx, &tenant.Tenant{ID: tenantID, Name: tenantID, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u1", TenantID: &tenantID, Email: "alice@example.com"}))
+	require.NoError(t, users.Delete(ctx, "u1"))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u2", TenantID: &tenantID, Email: "alice@example.com"}))
+	assert.Error(t, users.Create(ctx, &identity.User{ID: "u3", TenantID: &tenantID, Email: "alice@example.com"}))
+
+	now := time.Now()
+	for _, id := range []string{"deleted", "live"} {
+		require.NoError(t, projects.Create(ctx, &authz.Project{ID: id, TenantID: tenantID, Name: id, OwnerID: "u2", CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, projects.SetMember(ctx, &authz.ProjectMember{ProjectID: id, UserID: "u2", Role: authz.RoleProjectAdmin, GrantedAt: now, GrantedBy: "u2"}))
+	}
+	require.NoError(t, projects.Delete(ctx, "deleted"))
+
+	n, err := projects.PurgeDeleted(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Zero(t, n, "nothing is old enough yet")
+	n, err = projects.PurgeDeleted(ctx, time.Now().Add(time.Second), 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = projects.GetMember(ctx, "deleted", "u2")
+	assert.ErrorIs(t, err, authz.ErrProjectMemberNotFound)
+	_, err = projects.GetByID(ctx, "live")
+	assert.NoError(t, err)
+}
+
+// TestPurpose: Validates that databases created with the tenant-wide email constraint are rebuilt once without it.
+// Scope: Database Unit Test
+// Security: Integrity (credentials and memberships survive the users table rebuild)
+// Expected: After migrating, the users table no longer has UNIQUE(tenant_id, email), a second run leaves it alone, rows referencing users are kept and a deleted user's email can be reused.
+// Test Case ID: STO-33
+func TestSQLite_LiveUserEmailRebuild(t *testing.T) {
+	ctx := context.Background()
+	db, err := New(ctx, Config{Path: MemoryPath})
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	for _, script := range Migrations() {
+		if script != LiveUserEmailSchema {
+			require.NoError(t, db.Migrate(ctx, script))
+		}
+	}
+
+	tenants := NewTenantRepository(db)
+	users := NewUserRepository(db)
+	memberships := NewMembershipRepository(db)
+	tenantID := "t1"
+	require.NoError(t, tenants.Create(ctx, &tenant.Tenant{ID: tenantID, Name: tenantID, Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u1", TenantID: &tenantID, Email: "alice@example.com"}))
+	require.NoError(t, users.AddCredentials(ctx, &identity.Credentials{UserID: "u1", PasswordHash: "hash"}))
+	require.NoError(t, users.Delete(ctx, "u1"))
+	assert.Error(t, users.Create(ctx, &identity.User{ID: "u2", TenantID: &tenantID, Email: "alice@example.com"}), "the old constraint counts deleted users")
+
+	for range 2 {
+		for _, script := range Migrations() {
+			require.NoError(t, db.Migrate(ctx, script))
+		}
+	}
+
+	var ddl string
+	require.NoError(t, db.db.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'users'`).Scan(&ddl))
+	assert.NotContains(t, ddl, "UNIQUE(tenant_id, email)")
+	creds, err := users.GetCredentials(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", creds.PasswordHash)
+	_, err = memberships.Get(ctx, tenantID, "u1")
+	assert.NoError(t, err, "the home membership is kept")
+
+	require.NoError(t, users.Create(ctx, &identity.User{ID: "u2", TenantID: &tenantID, Email: "alice@example.com"}))
+	_, err = memberships.Get(ctx, tenantID, "u2")
+	assert.NoError(t, err, "the home membership trigger is recreated")
+	assert.Error(t, users.Create(ctx, &identity.User{ID: "u3", TenantID: &tenantID, Email: "alice@example.com"}))
+	var fk int
+	require.NoError(t, db.db.QueryRowContext(ctx, `PRAGMA foreign_keys`).Scan(&fk))
+	assert.Equal(t, 1, fk, "foreign keys are back on")
+}
+
+// TestPurpose: Validates that concurrent revocations cannot remove the last tenant owner or the last platform admin.
+// Scope: Database Unit Test
+// Security: Availability of administration; two owners or admins revoking each other at the same time must leave one of them
+// Expected: Of two concurrent revocations of the only two holders of a role, exactly one succeeds and the other is refused with ErrLastTenantOwner or ErrLastAssignment; one holder remains.
+// Test Case ID: STO-34
+func TestSQLite_RevokeLastHolder_Concurrent(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+	tenantRoles := NewTenantRoleRepository(db)
+	assignments := NewAssignmentRepository(db)
+
+	require.NoError(t, NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: "t1", Name: "t1", Status: tenant.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+	for _, id := range []string{"u1", "u2"} {
+		require.NoError(t, users.Create(ctx, &identity.User{ID: id, Email: id + "@example.com"}))
+	}
+
+	// race runs revoke for both users at once and returns their errors
+	race := func(revoke func(userID string) error) []error {
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i, userID := range []string{"u1", "u2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				errs[i] = revoke(userID)
+			}()
+		}
+		close(start)
+		wg.Wait()
+		return errs
+	}
+
+	for round := range 10 {
+		for _, id := range []string{"u1", "u2"} {
+			require.NoError(t, tenantRoles.AssignRole(ctx, &tenant.TenantUserRole{ID: fmt.Sprintf("%s-%d", id, round), TenantID: "t1", UserID: id, Role: tenant.RoleTenantOwner, GrantedBy: tenant.GranterSystem}))
+		}
+		errs := race(func(userID string) error {
+			return tenantRoles.RevokeOwnerRole(ctx, "t1", userID, tenant.RoleTenantOwner)
+		})
+		assert.ElementsMatch(t, []error{nil, tenant.ErrLastTenantOwner}, errs)
+		owners, err := tenantRoles.GetTenantUsers(ctx, "t1")
+		require.NoError(t, err)
+		require.Len(t, owners, 1, "the tenant keeps one owner")
+		require.NoError(t, tenantRoles.RevokeRole(ctx, "t1", owners[0].UserID, tenant.RoleTenantOwner))
+
+		for _, id := range []string{"u1", "u2"} {
+			require.NoError(t, assignments.Grant(ctx, &authz.Assignment{ID: fmt.Sprintf("p-%s-%d", id, round), UserID: id, RoleID: platformAdminRoleID, Scope: authz.ScopePlatform, GrantedAt: time.Now()}))
+		}
+		errs = race(func(userID string) error {
+			return assignments.RevokeUnlessLast(ctx, userID, platformAdminRoleID, authz.ScopePlatform, nil)
+		})
+		assert.ElementsMatch(t, []error{nil, authz.ErrLastAssignment}, errs)
+		admins, err := assignments.ListByRole(ctx, platformAdminRoleID, authz.ScopePlatform, nil)
+		require.NoError(t, err)
+		require.Len(t, admins, 1, "the platform keeps one admin")
+		require.NoError(t, assignments.Revoke(ctx, admins[0], platformAdminRoleID, authz.ScopePlatform, nil))
+	}
+	assert.ErrorIs(t, assignments.RevokeUnlessLast(ctx, "u1", platformAdminRoleID, authz.ScopePlatform, nil), authz.ErrAssignmentNotFound)
+}
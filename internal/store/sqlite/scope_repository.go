@@ -0,0 +1,179 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// ScopeRepository implements oauth2.ScopeRepository
+type ScopeRepository struct {
+	db *DB
+}
+
+// NewScopeRepository creates a new custom scope repository
+func NewScopeRepository(db *DB) *ScopeRepository {
+	return &ScopeRepository{db: db}
+}
+
+const scopeColumns = `id, tenant_id, name, description, claims, permissions, created_at, updated_at`
+
+// Create stores a new scope; the unique (tenant_id, name) constraint rejects a duplicate name
+func (r *ScopeRepository) Create(ctx context.Context, scope *oauth2.ScopeDefinition) error {
+	claims, permissions, err := marshalScopeLists(scope)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO oauth2_scopes (`+scopeColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id, name) DO NOTHING
+	`, scope.ID, scope.TenantID, scope.Name, scope.Description, claims, permissions,
+		timestamp(scope.CreatedAt), timestamp(scope.UpdatedAt))
+
+	if err != nil {
+		return fmt.Errorf("failed to create scope: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return oauth2.ErrScopeAlreadyExists
+	}
+	return nil
+}
+
+// Get retrieves a tenant's scope by name
+func (r *ScopeRepository) Get(ctx context.Context, tenantID, name string) (*oauth2.ScopeDefinition, error) {
+	row := r.db.db.QueryRowContext(ctx, `
+		SELECT `+scopeColumns+`
+		FROM oauth2_scopes
+		WHERE tenant_id = ? AND name = ?
+	`, tenantID, name)
+
+	scope, err := scanScope(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauth2.ErrScopeNotFound
+		}
+		return nil, fmt.Errorf("failed to get scope: %w", err)
+	}
+	return scope, nil
+}
+
+// List retrieves all of a tenant's scopes ordered by name
+func (r *ScopeRepository) List(ctx context.Context, tenantID string) ([]*oauth2.ScopeDefinition, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT `+scopeColumns+`
+		FROM oauth2_scopes
+		WHERE tenant_id = ?
+		ORDER BY name
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scopes: %w", err)
+	}
+	defer rows.Close()
+
+	var scopes []*oauth2.ScopeDefinition
+	for rows.Next() {
+		scope, err := scanScope(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scope: %w", err)
+		}
+		scopes = append(scopes, scope)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list scopes: %w", err)
+	}
+
+	return scopes, nil
+}
+
+// Update replaces a scope's description, claims and permissions
+func (r *ScopeRepository) Update(ctx context.Context, scope *oauth2.ScopeDefinition) error {
+	claims, permissions, err := marshalScopeLists(scope)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE oauth2_scopes
+		SET description = ?, claims = ?, permissions = ?, updated_at = ?
+		WHERE tenant_id = ? AND name = ?
+	`, scope.Description, claims, permissions, timestamp(scope.UpdatedAt), scope.TenantID, scope.Name)
+
+	if err != nil {
+		return fmt.Errorf("failed to update scope: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return oauth2.ErrScopeNotFound
+	}
+	return nil
+}
+
+// Delete removes a tenant's scope
+func (r *ScopeRepository) Delete(ctx context.Context, tenantID, name string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM oauth2_scopes WHERE tenant_id = ? AND name = ?
+	`, tenantID, name)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete scope: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return oauth2.ErrScopeNotFound
+	}
+	return nil
+}
+
+// marshalScopeLists encodes the claims and permissions of a scope as JSON arrays
+func marshalScopeLists(scope *oauth2.ScopeDefinition) (string, string, error) {
+	claims, permissions := scope.Claims, scope.Permissions
+	if claims == nil {
+		claims = []string{}
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal scope claims: %w", err)
+	}
+	p, err := json.Marshal(permissions)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal scope permissions: %w", err)
+	}
+	return string(c), string(p), nil
+}
+
+func scanScope(s scanner) (*oauth2.ScopeDefinition, error) {
+	var scope oauth2.ScopeDefinition
+	var claims, permissions string
+	if err := s.Scan(&scope.ID, &scope.TenantID, &scope.Name, &scope.Description, &claims, &permissions,
+		&scope.CreatedAt, &scope.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(claims), &scope.Claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scope claims: %w", err)
+	}
+	if err := json.Unmarshal([]byte(permissions), &scope.Permissions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scope permissions: %w", err)
+	}
+	return &scope, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// SecurityPolicyRepository implements tenant.SecurityPolicyRepository
+type SecurityPolicyRepository struct {
+	db *DB
+}
+
+// NewSecurityPolicyRepository creates a new tenant security policy repository
+func NewSecurityPolicyRepository(db *DB) *SecurityPolicyRepository {
+	return &SecurityPolicyRepository{db: db}
+}
+
+// Get retrieves a tenant's security policy
+func (r *SecurityPolicyRepository) Get(ctx context.Context, tenantID string) (*tenant.SecurityPolicy, error) {
+	var p tenant.SecurityPolicy
+	var lockoutSeconds, maxAgeSeconds int64
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT tenant_id, lockout_max_attempts, lockout_duration_seconds,
+			login_rps, login_burst, token_rps, token_burst, password_max_age_seconds, updated_by, updated_at
+		FROM tenant_security_policies
+		WHERE tenant_id = ?
+	`, tenantID).Scan(
+		&p.TenantID, &p.Settings.LockoutMaxAttempts, &lockoutSeconds,
+		&p.Settings.LoginRPS, &p.Settings.LoginBurst, &p.Settings.TokenRPS, &p.Settings.TokenBurst, &maxAgeSeconds,
+		&p.UpdatedBy, &p.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tenant.ErrSecurityPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get security policy: %w", err)
+	}
+	p.Settings.LockoutDuration = time.Duration(lockoutSeconds) * time.Second
+	p.Settings.PasswordMaxAge = time.Duration(maxAgeSeconds) * time.Second
+	return &p, nil
+}
+
+// Upsert creates or replaces a tenant's security policy
+func (r *SecurityPolicyRepository) Upsert(ctx context.Context, p *tenant.SecurityPolicy) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO tenant_security_policies (
+			tenant_id, lockout_max_attempts, lockout_duration_seconds,
+			login_rps, login_burst, token_rps, token_burst, password_max_age_seconds, updated_by, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			lockout_max_attempts = excluded.lockout_max_attempts,
+			lockout_duration_seconds = excluded.lockout_duration_seconds,
+			login_rps = excluded.login_rps,
+			login_burst = excluded.login_burst,
+			token_rps = excluded.token_rps,
+			token_burst = excluded.token_burst,
+			password_max_age_seconds = excluded.password_max_age_seconds,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`,
+		p.TenantID, p.Settings.LockoutMaxAttempts, int64(p.Settings.LockoutDuration/time.Second),
+		p.Settings.LoginRPS, p.Settings.LoginBurst, p.Settings.TokenRPS, p.Settings.TokenBurst,
+		int64(p.Settings.PasswordMaxAge/time.Second),
+		p.UpdatedBy, timestamp(p.UpdatedAt),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save security policy: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a tenant's security policy
+func (r *SecurityPolicyRepository) Delete(ctx context.Context, tenantID string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM tenant_security_policies WHERE tenant_id = ?
+	`, tenantID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete security policy: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return tenant.ErrSecurityPolicyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/session"
+)
+
+// SessionRepository implements session.Repository
+type SessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, sess *session.Session) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, tenant_id, user_id, ip_address, user_agent, expires_at, created_at, last_seen_at, namespace, password_change_required)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		sess.ID, sess.TenantID, sess.UserID, sess.IPAddress, sess.UserAgent,
+		timestamp(sess.ExpiresAt), timestamp(sess.CreatedAt), timestamp(sess.LastSeenAt), sess.Namespace, sess.PasswordChangeRequired,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a session by ID
+func (r *SessionRepository) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	var sess session.Session
+	var ipAddress, userAgent sql.NullString
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT id, tenant_id, user_id, ip_address, user_agent, expires_at, created_at, last_seen_at, namespace, password_change_required
+		FROM sessions
+		WHERE id = ?
+	`, sessionID).Scan(
+		&sess.ID, &sess.TenantID, &sess.UserID, &ipAddress, &userAgent,
+		&sess.ExpiresAt, &sess.CreatedAt, &sess.LastSeenAt, &sess.Namespace, &sess.PasswordChangeRequired,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, session.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	sess.IPAddress = ipAddress.String
+	sess.UserAgent = userAgent.String
+
+	return &sess, nil
+}
+
+// Update updates the session's last seen time, address and user agent
+func (r *SessionRepository) Update(ctx context.Context, sess *session.Session) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE sessions SET last_seen_at = ?, ip_address = ?, user_agent = ?
+		WHERE id = ?
+	`, timestamp(sess.LastSeenAt), sess.IPAddress, sess.UserAgent, sess.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return session.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a session
+func (r *SessionRepository) Delete(ctx context.Context, sessionID string) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM sessions WHERE id = ?
+	`, sessionID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByUserID deletes all sessions for a user
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM sessions WHERE user_id = ?
+	`, userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByTenantID deletes up to limit sessions of a tenant and returns how many were removed
+func (r *SessionRepository) DeleteByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM sessions WHERE id IN (
+			SELECT id FROM sessions WHERE tenant_id = ? LIMIT ?
+		)
+	`, tenantID, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tenant sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteByClientID deletes up to limit sessions of users holding tokens issued to a client and
+// returns how many were removed
+func (r *SessionRepository) DeleteByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM sessions WHERE id IN (
+			SELECT id FROM sessions WHERE user_id IN (
+				SELECT user_id FROM access_tokens WHERE client_id = ?
+				UNION
+				SELECT user_id FROM refresh_tokens WHERE client_id = ?
+			) LIMIT ?
+		)
+	`, clientID, clientID, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete client sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpired deletes up to limit expired sessions and returns how many were removed
+func (r *SessionRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM sessions WHERE id IN (
+			SELECT id FROM sessions WHERE expires_at < ? LIMIT ?
+		)
+	`, timestamp(time.Now()), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
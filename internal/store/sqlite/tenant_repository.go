@@ -0,0 +1,251 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TenantRepository implements tenant.Repository
+type TenantRepository struct {
+	db *DB
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(db *DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// Create creates a new tenant
+func (r *TenantRepository) Create(ctx context.Context, t *tenant.Tenant) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO tenants (id, name, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, t.ID, t.Name, t.Status, timestamp(t.CreatedAt), timestamp(t.UpdatedAt))
+
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a tenant by ID
+func (r *TenantRepository) GetByID(ctx context.Context, id string) (*tenant.Tenant, error) {
+	return r.get(ctx, `WHERE id = ? AND deleted_at IS NULL`, id)
+}
+
+// GetByName retrieves a tenant by name
+func (r *TenantRepository) GetByName(ctx context.Context, name string) (*tenant.Tenant, error) {
+	return r.get(ctx, `WHERE name = ? AND deleted_at IS NULL`, name)
+}
+
+func (r *TenantRepository) get(ctx context.Context, where string, args ...any) (*tenant.Tenant, error) {
+	var t tenant.Tenant
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT id, name, status, created_at, updated_at
+		FROM tenants
+		`+where, args...).Scan(
+		&t.ID, &t.Name, &t.Status, &t.CreatedAt, &t.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tenant.ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	return &t, nil
+}
+
+// Update updates a tenant
+func (r *TenantRepository) Update(ctx context.Context, t *tenant.Tenant) error {
+	t.UpdatedAt = time.Now()
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE tenants SET name = ?, status = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, t.Name, t.Status, timestamp(t.UpdatedAt), t.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return tenant.ErrTenantNotFound
+	}
+
+	return nil
+}
+
+// Delete soft-deletes a tenant
+func (r *TenantRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE tenants SET deleted_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, timestamp(time.Now()), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return tenant.ErrTenantNotFound
+	}
+
+	return nil
+}
+
+// List lists tenants using keyset pagination
+func (r *TenantRepository) List(ctx context.Context, opts tenant.ListOptions) (*tenant.ListResult, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = tenant.DefaultListLimit
+	}
+
+	sortColumn, direction, cmp := "created_at", "DESC", "<"
+	switch opts.Sort {
+	case tenant.SortCreatedAsc:
+		direction, cmp = "ASC", ">"
+	case tenant.SortNameAsc:
+		sortColumn, direction, cmp = "name", "ASC", ">"
+	case tenant.SortNameDesc:
+		sortColumn = "name"
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
+	args := []any{}
+
+	if opts.Query != "" {
+		// LIKE is case-insensitive for ASCII in SQLite
+		conditions = append(conditions, `name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(opts.Query)+"%")
+	}
+	if opts.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, opts.Status)
+	}
+	if opts.Cursor != "" {
+		c, err := tenant.DecodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		value := c.Value
+		if sortColumn == "created_at" {
+			t, err := time.Parse(time.RFC3339Nano, c.Value)
+			if err != nil {
+				return nil, tenant.ErrInvalidCursor
+			}
+			value = timestamp(t)
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (?, ?)", sortColumn, cmp))
+		args = append(args, value, c.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, name, status, created_at, updated_at
+		FROM tenants
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT ?
+	`, strings.Join(conditions, " AND "), sortColumn, direction, direction)
+	args = append(args, opts.Limit+1)
+
+	rows, err := r.db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*tenant.Tenant{}
+	for rows.Next() {
+		var t tenant.Tenant
+		if err := rows.Scan(&t.ID, &t.Name, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	result := &tenant.ListResult{Tenants: tenants}
+	if len(tenants) > opts.Limit {
+		result.Tenants = tenants[:opts.Limit]
+		last := result.Tenants[opts.Limit-1]
+		value := last.Name
+		if sortColumn == "created_at" {
+			value = last.CreatedAt.Format(time.RFC3339Nano)
+		}
+		result.NextCursor = tenant.EncodeCursor(tenant.Cursor{Sort: opts.Sort, Value: value, ID: last.ID})
+	}
+
+	return result, nil
+}
+
+// escapeLike escapes LIKE wildcards in user-supplied search terms
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// CountByStatus counts the tenants that are not deleted, by status
+func (r *TenantRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT status, COUNT(*) FROM tenants WHERE deleted_at IS NULL GROUP BY status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenants: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var n int64
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant count: %w", err)
+		}
+		counts[status] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count tenants: %w", err)
+	}
+	return counts, nil
+}
+
+// PurgeDeleted permanently removes up to limit tenants soft-deleted before the given time.
+// Tenants that still have users are kept until those users are purged.
+func (r *TenantRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM tenants WHERE id IN (
+			SELECT id FROM tenants
+			WHERE deleted_at IS NOT NULL AND deleted_at < ?
+			AND NOT EXISTS (SELECT 1 FROM users u WHERE u.tenant_id = tenants.id)
+			LIMIT ?
+		)
+	`, timestamp(before), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted tenants: %w", err)
+	}
+
+	return result.RowsAffected()
+}
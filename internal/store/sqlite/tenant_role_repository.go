@@ -0,0 +1,153 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TenantRoleRepository implements tenant.RoleRepository
+type TenantRoleRepository struct {
+	db *DB
+}
+
+// NewTenantRoleRepository creates a new tenant role repository
+func NewTenantRoleRepository(db *DB) *TenantRoleRepository {
+	return &TenantRoleRepository{db: db}
+}
+
+// mapTenantRole maps internal tenant role names to seeded RBAC role IDs
+func mapTenantRole(role string) string {
+	switch role {
+	case tenant.RoleTenantOwner:
+		return "20000000-0000-0000-0000-000000000002" // Map owner to admin for now
+	case tenant.RoleTenantAdmin:
+		return "20000000-0000-0000-0000-000000000002"
+	default:
+		return "20000000-0000-0000-0000-000000000003"
+	}
+}
+
+// AssignRole assigns a role to a user in a tenant
+func (r *TenantRoleRepository) AssignRole(ctx context.Context, role *tenant.TenantUserRole) error {
+	role.GrantedAt = time.Now()
+
+	roleID := mapTenantRole(role.Role)
+
+	var grantedBy sql.NullString
+	if role.GrantedBy != "" && role.GrantedBy != tenant.GranterSystem {
+		grantedBy = sql.NullString{String: role.GrantedBy, Valid: true}
+	}
+
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO rbac_assignments (id, user_id, role_id, scope, scope_context_id, granted_at, granted_by)
+		VALUES (?, ?, ?, 'tenant', ?, ?, ?)
+		ON CONFLICT (user_id, role_id, scope, scope_context_id) DO NOTHING
+	`, role.ID, role.UserID, roleID, role.TenantID, timestamp(role.GrantedAt), grantedBy)
+
+	if err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRole revokes a role from a user in a tenant
+func (r *TenantRoleRepository) RevokeRole(ctx context.Context, tenantID, userID, role string) error {
+	roleID := mapTenantRole(role)
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM rbac_assignments
+		WHERE user_id = ? AND role_id = ? AND scope = 'tenant' AND scope_context_id = ?
+	`, userID, roleID, tenantID)
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return tenant.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// RevokeOwnerRole revokes an owner or admin role unless the user is the tenant's only owner
+func (r *TenantRoleRepository) RevokeOwnerRole(ctx context.Context, tenantID, userID, role string) error {
+	err := revokeUnlessLast(ctx, r.db, userID, mapTenantRole(role), "tenant", &tenantID)
+	switch {
+	case errors.Is(err, authz.ErrAssignmentNotFound):
+		return tenant.ErrRoleNotFound
+	case errors.Is(err, authz.ErrLastAssignment):
+		return tenant.ErrLastTenantOwner
+	}
+	return err
+}
+
+// GetUserRoles retrieves all roles a user has in a tenant
+func (r *TenantRoleRepository) GetUserRoles(ctx context.Context, tenantID, userID string) ([]*tenant.TenantUserRole, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT a.id, a.scope_context_id, a.user_id, r.name, a.granted_at, a.granted_by
+		FROM rbac_assignments a
+		JOIN rbac_roles r ON a.role_id = r.id
+		WHERE a.user_id = ? AND a.scope = 'tenant' AND a.scope_context_id = ?
+	`, userID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTenantUserRoles(rows)
+}
+
+// GetTenantUsers retrieves all users with roles in a tenant
+func (r *TenantRoleRepository) GetTenantUsers(ctx context.Context, tenantID string) ([]*tenant.TenantUserRole, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT a.id, a.scope_context_id, a.user_id, r.name, a.granted_at, a.granted_by
+		FROM rbac_assignments a
+		JOIN rbac_roles r ON a.role_id = r.id
+		WHERE a.scope = 'tenant' AND a.scope_context_id = ?
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTenantUserRoles(rows)
+}
+
+func scanTenantUserRoles(rows *sql.Rows) ([]*tenant.TenantUserRole, error) {
+	var roles []*tenant.TenantUserRole
+	for rows.Next() {
+		var role tenant.TenantUserRole
+		var grantedBy sql.NullString
+		if err := rows.Scan(&role.ID, &role.TenantID, &role.UserID, &role.Role, &role.GrantedAt, &grantedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		role.GrantedBy = tenant.GranterSystem
+		if grantedBy.Valid {
+			role.GrantedBy = grantedBy.String
+		}
+		roles = append(roles, &role)
+	}
+
+	return roles, rows.Err()
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// AccessTokenRepository implements oauth2.AccessTokenRepository
+type AccessTokenRepository struct {
+	db *DB
+}
+
+// NewAccessTokenRepository creates a new access token repository
+func NewAccessTokenRepository(db *DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+// Create creates a new access token
+func (r *AccessTokenRepository) Create(ctx context.Context, token *oauth2.AccessToken) error {
+	var claims sql.NullString
+	if len(token.Claims) > 0 {
+		b, err := json.Marshal(token.Claims)
+		if err != nil {
+			return fmt.Errorf("failed to marshal access token claims: %w", err)
+		}
+		claims = sql.NullString{String: string(b), Valid: true}
+	}
+
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO access_tokens (
+			id, tenant_id, token_hash, client_id, user_id,
+			scope, token_type, expires_at, revoked_at, is_revoked, created_at, claims
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		token.ID, token.TenantID, token.TokenHash, token.ClientID, token.UserID,
+		token.Scope, token.TokenType, timestamp(token.ExpiresAt), nullTimestamp(token.RevokedAt), token.IsRevoked, timestamp(token.CreatedAt), claims,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTokenHash retrieves an access token issued within a tenant
+func (r *AccessTokenRepository) GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*oauth2.AccessToken, error) {
+	return r.get(ctx, "tenant_id = ? AND token_hash = ?", tenantID, tokenHash)
+}
+
+// FindByTokenHash retrieves an access token issued within any tenant
+func (r *AccessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*oauth2.AccessToken, error) {
+	return r.get(ctx, "token_hash = ?", tokenHash)
+}
+
+func (r *AccessTokenRepository) get(ctx context.Context, where string, args ...any) (*oauth2.AccessToken, error) {
+	var token oauth2.AccessToken
+	var tokenType, claims sql.NullString
+	var revokedAt sql.NullTime
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT
+			id, tenant_id, token_hash, client_id, user_id,
+			scope, token_type, expires_at, revoked_at, is_revoked, created_at, claims
+		FROM access_tokens
+		WHERE `+where, args...).Scan(
+		&token.ID, &token.TenantID, &token.TokenHash, &token.ClientID, &token.UserID,
+		&token.Scope, &tokenType, &token.ExpiresAt, &revokedAt, &token.IsRevoked, &token.CreatedAt, &claims,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauth2.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	token.TokenType = tokenType.String
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if claims.Valid {
+		if err := json.Unmarshal([]byte(claims.String), &token.Claims); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal access token claims: %w", err)
+		}
+	}
+
+	return &token, nil
+}
+
+// Revoke revokes an access token
+func (r *AccessTokenRepository) Revoke(ctx context.Context, tenantID, tokenHash string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE access_tokens SET is_revoked = true, revoked_at = ?
+		WHERE tenant_id = ? AND token_hash = ?
+	`, timestamp(time.Now()), tenantID, tokenHash)
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return oauth2.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeByUserID revokes every unrevoked access token of a user and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE access_tokens SET is_revoked = true, revoked_at = ?
+		WHERE user_id = ? AND is_revoked = false
+	`, timestamp(time.Now()), userID)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return n, nil
+}
+
+// DeleteExpired deletes up to limit expired access tokens and returns how many were removed
+func (r *AccessTokenRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM access_tokens WHERE id IN (
+			SELECT id FROM access_tokens WHERE expires_at < ? LIMIT ?
+		)
+	`, timestamp(time.Now()), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired access tokens: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// RefreshTokenRepository implements oauth2.RefreshTokenRepository
+type RefreshTokenRepository struct {
+	db *DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create creates a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *oauth2.RefreshToken) error {
+	var accessTokenID sql.NullString
+	if token.AccessTokenID != "" {
+		accessTokenID = sql.NullString{String: token.AccessTokenID, Valid: true}
+	}
+
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (
+			id, tenant_id, token_hash, access_token_id, client_id, user_id,
+			scope, expires_at, revoked_at, is_revoked, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		token.ID, token.TenantID, token.TokenHash, accessTokenID, token.ClientID, token.UserID,
+		token.Scope, timestamp(token.ExpiresAt), nullTimestamp(token.RevokedAt), token.IsRevoked, timestamp(token.CreatedAt),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTokenHash retrieves a refresh token issued within a tenant
+func (r *RefreshTokenRepository) GetByTokenHash(ctx context.Context, tenantID, tokenHash string) (*oauth2.RefreshToken, error) {
+	var token oauth2.RefreshToken
+	var revokedAt sql.NullTime
+	var accessTokenID sql.NullString
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT
+			id, tenant_id, token_hash, access_token_id, client_id, user_id,
+			scope, expires_at, revoked_at, is_revoked, created_at
+		FROM refresh_tokens
+		WHERE tenant_id = ? AND token_hash = ?
+	`, tenantID, tokenHash).Scan(
+		&token.ID, &token.TenantID, &token.TokenHash, &accessTokenID, &token.ClientID, &token.UserID,
+		&token.Scope, &token.ExpiresAt, &revokedAt, &token.IsRevoked, &token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauth2.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	token.AccessTokenID = accessTokenID.String
+
+	return &token, nil
+}
+
+// Revoke revokes a refresh token
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, tenantID, tokenHash string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET is_revoked = true, revoked_at = ?
+		WHERE tenant_id = ? AND token_hash = ?
+	`, timestamp(time.Now()), tenantID, tokenHash)
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return oauth2.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeByUserID revokes every unrevoked refresh token of a user and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, userID string) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET is_revoked = true, revoked_at = ?
+		WHERE user_id = ? AND is_revoked = false
+	`, timestamp(time.Now()), userID)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return n, nil
+}
+
+// DeleteExpired deletes up to limit expired refresh tokens and returns how many were removed
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM refresh_tokens WHERE id IN (
+			SELECT id FROM refresh_tokens WHERE expires_at < ? LIMIT ?
+		)
+	`, timestamp(time.Now()), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// RevokeByTenantID revokes up to limit unexpired, unrevoked access tokens of a tenant and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "access_tokens", "tenant_id", tenantID, limit)
+}
+
+// RevokeByClientID revokes up to limit unexpired, unrevoked access tokens issued to a client and returns how many were revoked
+func (r *AccessTokenRepository) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "access_tokens", "client_id", clientID, limit)
+}
+
+// RevokeByTenantID revokes up to limit unexpired, unrevoked refresh tokens of a tenant and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByTenantID(ctx context.Context, tenantID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "refresh_tokens", "tenant_id", tenantID, limit)
+}
+
+// RevokeByClientID revokes up to limit unexpired, unrevoked refresh tokens issued to a client and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeByClientID(ctx context.Context, clientID string, limit int) (int64, error) {
+	return r.db.revokeTokens(ctx, "refresh_tokens", "client_id", clientID, limit)
+}
+
+// revokeTokens revokes up to limit unexpired, unrevoked tokens of table whose column equals value
+// and returns how many were revoked
+func (db *DB) revokeTokens(ctx context.Context, table, column, value string, limit int) (int64, error) {
+	now := timestamp(time.Now())
+	result, err := db.db.ExecContext(ctx, `
+		UPDATE `+table+` SET is_revoked = true, revoked_at = ?
+		WHERE id IN (
+			SELECT id FROM `+table+` WHERE `+column+` = ? AND is_revoked = false AND expires_at > ? LIMIT ?
+		)
+	`, now, value, now, limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke %s: %w", table, err)
+	}
+
+	return result.RowsAffected()
+}
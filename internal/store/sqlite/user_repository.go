@@ -0,0 +1,361 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// UserRepository implements identity.UserRepository
+type UserRepository struct {
+	db *DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db *DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create creates a new user identity
+func (r *UserRepository) Create(ctx context.Context, user *identity.User) error {
+	now := time.Now()
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO users (
+			id, tenant_id, email, email_verified,
+			given_name, family_name, full_name, nickname, picture, locale, timezone, website, birthdate,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		user.ID, user.TenantID, user.Email, user.EmailVerified,
+		user.Profile.GivenName, user.Profile.FamilyName, user.Profile.FullName,
+		user.Profile.Nickname, user.Profile.Picture, user.Profile.Locale, user.Profile.Timezone,
+		user.Profile.Website, user.Profile.Birthdate,
+		timestamp(now), timestamp(now),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
+
+	return nil
+}
+
+// AddCredentials adds credentials for a user
+func (r *UserRepository) AddCredentials(ctx context.Context, credentials *identity.Credentials) error {
+	now := time.Now()
+
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO credentials (user_id, password_hash, updated_at)
+		VALUES (?, ?, ?)
+	`, credentials.UserID, credentials.PasswordHash, timestamp(now))
+	if err != nil {
+		return fmt.Errorf("failed to insert credentials: %w", err)
+	}
+
+	credentials.UpdatedAt = now
+
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*identity.User, error) {
+	return r.get(ctx, `WHERE id = ? AND deleted_at IS NULL`, id)
+}
+
+// GetByEmail retrieves a user by email within a tenant (or no tenant for Platform Admins)
+func (r *UserRepository) GetByEmail(ctx context.Context, tenantID *string, email string) (*identity.User, error) {
+	return r.get(ctx, `WHERE tenant_id IS ? AND email = ? AND deleted_at IS NULL`, tenantID, email)
+}
+
+// ListByEmail retrieves the users registered with email in any tenant, platform admins
+// included, oldest first
+func (r *UserRepository) ListByEmail(ctx context.Context, email string) ([]*identity.User, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT id, tenant_id, email, email_verified,
+			given_name, family_name, full_name, nickname, picture, locale, timezone, website, birthdate,
+			failed_login_attempts, locked_until, created_at, updated_at, version
+		FROM users
+		WHERE email = ? AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*identity.User
+	for rows.Next() {
+		var user identity.User
+		var lockedUntil sql.NullTime
+		if err := rows.Scan(
+			&user.ID, &user.TenantID, &user.Email, &user.EmailVerified,
+			&user.Profile.GivenName, &user.Profile.FamilyName, &user.Profile.FullName,
+			&user.Profile.Nickname, &user.Profile.Picture, &user.Profile.Locale, &user.Profile.Timezone,
+			&user.Profile.Website, &user.Profile.Birthdate,
+			&user.FailedLoginAttempts, &lockedUntil, &user.CreatedAt, &user.UpdatedAt, &user.Version,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if lockedUntil.Valid {
+			user.LockedUntil = &lockedUntil.Time
+		}
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) get(ctx context.Context, where string, args ...any) (*identity.User, error) {
+	var user identity.User
+	var lockedUntil, deletedAt sql.NullTime
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT id, tenant_id, email, email_verified,
+			given_name, family_name, full_name, nickname, picture, locale, timezone, website, birthdate,
+			failed_login_attempts, locked_until, created_at, updated_at, version, deleted_at
+		FROM users
+		`+where, args...).Scan(
+		&user.ID, &user.TenantID, &user.Email, &user.EmailVerified,
+		&user.Profile.GivenName, &user.Profile.FamilyName, &user.Profile.FullName,
+		&user.Profile.Nickname, &user.Profile.Picture, &user.Profile.Locale, &user.Profile.Timezone,
+		&user.Profile.Website, &user.Profile.Birthdate,
+		&user.FailedLoginAttempts, &lockedUntil, &user.CreatedAt, &user.UpdatedAt, &user.Version, &deletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, identity.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
+	}
+
+	return &user, nil
+}
+
+// Update updates user information
+func (r *UserRepository) Update(ctx context.Context, user *identity.User) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE users SET
+			email = ?,
+			email_verified = ?,
+			given_name = ?,
+			family_name = ?,
+			full_name = ?,
+			nickname = ?,
+			picture = ?,
+			locale = ?,
+			timezone = ?,
+			website = ?,
+			birthdate = ?,
+			updated_at = ?,
+			version = version + 1
+		WHERE id = ? AND tenant_id IS ? AND version = ? AND deleted_at IS NULL
+	`,
+		user.Email, user.EmailVerified,
+		user.Profile.GivenName, user.Profile.FamilyName, user.Profile.FullName,
+		user.Profile.Nickname, user.Profile.Picture, user.Profile.Locale, user.Profile.Timezone,
+		user.Profile.Website, user.Profile.Birthdate,
+		timestamp(time.Now()), user.ID, user.TenantID, user.Version,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Distinguish a stale version from a missing user
+		var exists bool
+		if err := r.db.db.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM users WHERE id = ? AND tenant_id IS ? AND deleted_at IS NULL)
+		`, user.ID, user.TenantID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if exists {
+			return identity.ErrUserVersionConflict
+		}
+		return identity.ErrUserNotFound
+	}
+	user.Version++
+
+	return nil
+}
+
+// UpdateLockout updates user lockout status
+func (r *UserRepository) UpdateLockout(ctx context.Context, userID string, failedAttempts int, lockedUntil *time.Time) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		UPDATE users
+		SET failed_login_attempts = ?, locked_until = ?, updated_at = ?
+		WHERE id = ?
+	`, failedAttempts, nullTimestamp(lockedUntil), timestamp(time.Now()), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user lockout status: %w", err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a user
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE users SET deleted_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, timestamp(time.Now()), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return identity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// GetCredentials retrieves user credentials
+func (r *UserRepository) GetCredentials(ctx context.Context, userID string) (*identity.Credentials, error) {
+	var creds identity.Credentials
+	var expiresAt sql.NullTime
+
+	err := r.db.db.QueryRowContext(ctx, `
+		SELECT user_id, password_hash, updated_at, expires_at
+		FROM credentials
+		WHERE user_id = ?
+	`, userID).Scan(&creds.UserID, &creds.PasswordHash, &creds.UpdatedAt, &expiresAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, identity.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	if expiresAt.Valid {
+		creds.ExpiresAt = &expiresAt.Time
+	}
+
+	return &creds, nil
+}
+
+// UpdatePassword updates user password and clears its expiry
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE credentials SET password_hash = ?, updated_at = ?, expires_at = NULL
+		WHERE user_id = ?
+	`, passwordHash, timestamp(time.Now()), userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return identity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ExpirePassword marks user password as expired from the given time
+func (r *UserRepository) ExpirePassword(ctx context.Context, userID string, at time.Time) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE credentials SET expires_at = ?
+		WHERE user_id = ?
+	`, timestamp(at), userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to expire password: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return identity.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Erase anonymizes a user and removes their credentials, role assignments and memberships in one
+// transaction
+func (r *UserRepository) Erase(ctx context.Context, userID, anonEmail string, at time.Time) error {
+	tx, err := r.db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"credentials", "rbac_assignments", "tenant_memberships", "project_members"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = ?`, userID); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET
+			email = ?, email_verified = FALSE,
+			given_name = '', family_name = '', full_name = '',
+			nickname = '', picture = '', locale = '', timezone = '',
+			website = '', birthdate = '',
+			failed_login_attempts = 0, locked_until = NULL,
+			updated_at = ?, deleted_at = COALESCE(deleted_at, ?),
+			version = version + 1
+		WHERE id = ?
+	`, anonEmail, timestamp(at), timestamp(at), userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to erase user: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return identity.ErrUserNotFound
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeleted permanently removes up to limit users soft-deleted before the given time.
+// Users recorded as the granter of a role assignment are kept so the grant stays attributable.
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM users WHERE id IN (
+			SELECT id FROM users
+			WHERE deleted_at IS NOT NULL AND deleted_at < ?
+			AND NOT EXISTS (SELECT 1 FROM rbac_assignments a WHERE a.granted_by = users.id)
+			LIMIT ?
+		)
+	`, timestamp(before), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// WebhookSubscriptionRepository implements webhook.SubscriptionRepository
+type WebhookSubscriptionRepository struct {
+	db *DB
+}
+
+// NewWebhookSubscriptionRepository creates a new webhook subscription repository
+func NewWebhookSubscriptionRepository(db *DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `id, tenant_id, url, description, event_types, secret_encrypted, enabled, created_by, created_at, updated_at`
+
+// Create stores a new subscription
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, sub *webhook.Subscription) error {
+	eventTypes, err := marshalEventTypes(sub.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.db.ExecContext(ctx, `
+		INSERT INTO webhook_subscriptions (`+webhookSubscriptionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, sub.ID, sub.TenantID, sub.URL, sub.Description, eventTypes, sub.SecretEncrypted, sub.Enabled,
+		sub.CreatedBy, timestamp(sub.CreatedAt), timestamp(sub.UpdatedAt))
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a tenant's subscription by ID
+func (r *WebhookSubscriptionRepository) Get(ctx context.Context, tenantID, id string) (*webhook.Subscription, error) {
+	row := r.db.db.QueryRowContext(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE tenant_id = ? AND id = ?
+	`, tenantID, id)
+
+	sub, err := scanWebhookSubscription(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// List retrieves all of a tenant's subscriptions ordered by ID
+func (r *WebhookSubscriptionRepository) List(ctx context.Context, tenantID string) ([]*webhook.Subscription, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE tenant_id = ?
+		ORDER BY id
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*webhook.Subscription
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// Update replaces a subscription's settings and secret
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, sub *webhook.Subscription) error {
+	eventTypes, err := marshalEventTypes(sub.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = ?, description = ?, event_types = ?, secret_encrypted = ?, enabled = ?, updated_at = ?
+		WHERE tenant_id = ? AND id = ?
+	`, sub.URL, sub.Description, eventTypes, sub.SecretEncrypted, sub.Enabled, timestamp(sub.UpdatedAt),
+		sub.TenantID, sub.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// Delete removes a subscription; its deliveries are removed by the foreign key cascade
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM webhook_subscriptions WHERE tenant_id = ? AND id = ?
+	`, tenantID, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func marshalEventTypes(eventTypes []string) (string, error) {
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	b, err := json.Marshal(eventTypes)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal webhook event types: %w", err)
+	}
+	return string(b), nil
+}
+
+func scanWebhookSubscription(s scanner) (*webhook.Subscription, error) {
+	var sub webhook.Subscription
+	var eventTypes string
+	if err := s.Scan(&sub.ID, &sub.TenantID, &sub.URL, &sub.Description, &eventTypes, &sub.SecretEncrypted,
+		&sub.Enabled, &sub.CreatedBy, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(eventTypes), &sub.EventTypes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook event types: %w", err)
+	}
+	return &sub, nil
+}
+
+// WebhookDeliveryRepository implements webhook.DeliveryRepository
+type WebhookDeliveryRepository struct {
+	db *DB
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+const webhookDeliveryColumns = `id, subscription_id, tenant_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_attempt_at, response_status, last_error, created_at, updated_at`
+
+// Create stores a new delivery
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d *webhook.Delivery) error {
+	_, err := r.db.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, d.ID, d.SubscriptionID, d.TenantID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts,
+		timestamp(d.NextAttemptAt), nullTimestamp(d.LastAttemptAt), d.ResponseStatus, d.LastError,
+		timestamp(d.CreatedAt), timestamp(d.UpdatedAt))
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a tenant's delivery by ID
+func (r *WebhookDeliveryRepository) Get(ctx context.Context, tenantID, id string) (*webhook.Delivery, error) {
+	row := r.db.db.QueryRowContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE tenant_id = ? AND id = ?
+	`, tenantID, id)
+
+	d, err := scanWebhookDelivery(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+// List retrieves a page of a subscription's deliveries, ordered by ID
+func (r *WebhookDeliveryRepository) List(ctx context.Context, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	query := `WHERE tenant_id = ? AND subscription_id = ?`
+	args := []any{filter.TenantID, filter.SubscriptionID}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
+	page, args := pageClause("id", filter.Page, args)
+	rows, err := r.db.db.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		`+query+page, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// Update stores the outcome of a delivery attempt
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d *webhook.Delivery) error {
+	result, err := r.db.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_attempt_at = ?, response_status = ?,
+		    last_error = ?, updated_at = ?
+		WHERE id = ?
+	`, d.Status, d.Attempts, timestamp(d.NextAttemptAt), nullTimestamp(d.LastAttemptAt), d.ResponseStatus,
+		d.LastError, timestamp(d.UpdatedAt), d.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return webhook.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+// ClaimDue leases up to limit due pending deliveries until leaseUntil and returns them
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	rows, err := r.db.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+		)
+		RETURNING `+webhookDeliveryColumns,
+		timestamp(leaseUntil), timestamp(now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// DeleteFinished removes up to limit succeeded or dead deliveries last updated before the cutoff
+func (r *WebhookDeliveryRepository) DeleteFinished(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.db.ExecContext(ctx, `
+		DELETE FROM webhook_deliveries WHERE id IN (
+			SELECT id FROM webhook_deliveries WHERE status <> 'pending' AND updated_at < ? LIMIT ?
+		)
+	`, timestamp(before), limit)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func scanWebhookDelivery(s scanner) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	var lastAttemptAt sql.NullTime
+	if err := s.Scan(&d.ID, &d.SubscriptionID, &d.TenantID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.NextAttemptAt, &lastAttemptAt, &d.ResponseStatus, &d.LastError,
+		&d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastAttemptAt.Valid {
+		d.LastAttemptAt = &lastAttemptAt.Time
+	}
+	return &d, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*webhook.Delivery, error) {
+	var deliveries []*webhook.Delivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
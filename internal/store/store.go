@@ -0,0 +1,275 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package store selects a storage backend and exposes its repositories.
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/bus"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/replay"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/store/postgres"
+	"github.com/opentrusty/opentrusty/internal/store/sqlite"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// Supported drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+// Config selects and configures a storage backend
+type Config struct {
+	Driver   string
+	Postgres postgres.Config
+	SQLite   sqlite.Config
+}
+
+// Store bundles the repositories of one backend
+type Store struct {
+	Users                 identity.UserRepository
+	Sessions              session.Repository
+	Projects              authz.ProjectRepository
+	Roles                 authz.RoleRepository
+	Assignments           authz.AssignmentRepository
+	Clients               oauth2.ClientRepository
+	Codes                 oauth2.AuthorizationCodeRepository
+	AccessTokens          oauth2.AccessTokenRepository
+	RefreshTokens         oauth2.RefreshTokenRepository
+	Keys                  oauth2.KeyRepository
+	Scopes                oauth2.ScopeRepository
+	Tenants               tenant.Repository
+	TenantRoles           tenant.RoleRepository
+	Memberships           tenant.MembershipRepository
+	EmailSettings         email.SettingsRepository
+	AuditEvents           audit.Repository
+	AuditRetention        audit.RetentionRepository
+	Webhooks              webhook.SubscriptionRepository
+	Deliveries            webhook.DeliveryRepository
+	Replay                replay.Repository
+	PendingAuthorizations oauth2.PendingAuthorizationRepository
+	ProtocolLogs          oauth2.ProtocolLogRepository
+	SecurityPolicies      tenant.SecurityPolicyRepository
+	EmailChanges          identity.EmailChangeRepository
+	AccountDeletions      identity.AccountDeletionRepository
+
+	driver       string
+	migrations   []string
+	migrate      func(ctx context.Context, script string) error
+	busTransport bus.Transport
+	saturated    func() bool
+	close        func()
+}
+
+// Open connects to the configured backend
+func Open(ctx context.Context, cfg Config) (*Store, error) {
+	switch cfg.Driver {
+	case DriverPostgres, "":
+		db, err := postgres.New(ctx, cfg.Postgres)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{
+			Users:                 postgres.NewUserRepository(db),
+			Sessions:              postgres.NewSessionRepository(db),
+			Projects:              postgres.NewProjectRepository(db),
+			Roles:                 postgres.NewRoleRepository(db),
+			Assignments:           postgres.NewAssignmentRepository(db),
+			Clients:               postgres.NewClientRepository(db),
+			Codes:                 postgres.NewAuthorizationCodeRepository(db),
+			AccessTokens:          postgres.NewAccessTokenRepository(db),
+			RefreshTokens:         postgres.NewRefreshTokenRepository(db),
+			Keys:                  postgres.NewKeyRepository(db),
+			Scopes:                postgres.NewScopeRepository(db),
+			Tenants:               postgres.NewTenantRepository(db),
+			TenantRoles:           postgres.NewTenantRoleRepository(db),
+			Memberships:           postgres.NewMembershipRepository(db),
+			EmailSettings:         postgres.NewEmailSettingsRepository(db),
+			AuditEvents:           postgres.NewAuditRepository(db),
+			AuditRetention:        postgres.NewAuditRetentionRepository(db),
+			Webhooks:              postgres.NewWebhookSubscriptionRepository(db),
+			Deliveries:            postgres.NewWebhookDeliveryRepository(db),
+			Replay:                postgres.NewReplayRepository(db),
+			PendingAuthorizations: postgres.NewPendingAuthorizationRepository(db),
+			ProtocolLogs:          postgres.NewProtocolLogRepository(db),
+			SecurityPolicies:      postgres.NewSecurityPolicyRepository(db),
+			EmailChanges:          postgres.NewEmailChangeRepository(db),
+			AccountDeletions:      postgres.NewAccountDeletionRepository(db),
+			driver:                DriverPostgres,
+			migrations:            db.Migrations(),
+			migrate:               db.Migrate,
+			busTransport:          db.BusTransport(),
+			saturated:             db.Saturated,
+			close:                 db.Close,
+		}, nil
+
+	case DriverSQLite:
+		db, err := sqlite.New(ctx, cfg.SQLite)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{
+			Users:                 sqlite.NewUserRepository(db),
+			Sessions:              sqlite.NewSessionRepository(db),
+			Projects:              sqlite.NewProjectRepository(db),
+			Roles:                 sqlite.NewRoleRepository(db),
+			Assignments:           sqlite.NewAssignmentRepository(db),
+			Clients:               sqlite.NewClientRepository(db),
+			Codes:                 sqlite.NewAuthorizationCodeRepository(db),
+			AccessTokens:          sqlite.NewAccessTokenRepository(db),
+			RefreshTokens:         sqlite.NewRefreshTokenRepository(db),
+			Keys:                  sqlite.NewKeyRepository(db),
+			Scopes:                sqlite.NewScopeRepository(db),
+			Tenants:               sqlite.NewTenantRepository(db),
+			TenantRoles:           sqlite.NewTenantRoleRepository(db),
+			Memberships:           sqlite.NewMembershipRepository(db),
+			EmailSettings:         sqlite.NewEmailSettingsRepository(db),
+			AuditEvents:           sqlite.NewAuditRepository(db),
+			AuditRetention:        sqlite.NewAuditRetentionRepository(db),
+			Webhooks:              sqlite.NewWebhookSubscriptionRepository(db),
+			Deliveries:            sqlite.NewWebhookDeliveryRepository(db),
+			Replay:                sqlite.NewReplayRepository(db),
+			PendingAuthorizations: sqlite.NewPendingAuthorizationRepository(db),
+			ProtocolLogs:          sqlite.NewProtocolLogRepository(db),
+			SecurityPolicies:      sqlite.NewSecurityPolicyRepository(db),
+			EmailChanges:          sqlite.NewEmailChangeRepository(db),
+			AccountDeletions:      sqlite.NewAccountDeletionRepository(db),
+			driver:                DriverSQLite,
+			migrations:            sqlite.Migrations(),
+			migrate:               db.Migrate,
+			close:                 db.Close,
+		}, nil
+
+	case DriverMemory:
+		db := memory.New()
+		return &Store{
+			Users:                 memory.NewUserRepository(db),
+			Sessions:              memory.NewSessionRepository(db),
+			Projects:              memory.NewProjectRepository(db),
+			Roles:                 memory.NewRoleRepository(db),
+			Assignments:           memory.NewAssignmentRepository(db),
+			Clients:               memory.NewClientRepository(db),
+			Codes:                 memory.NewAuthorizationCodeRepository(db),
+			AccessTokens:          memory.NewAccessTokenRepository(db),
+			RefreshTokens:         memory.NewRefreshTokenRepository(db),
+			Keys:                  memory.NewKeyRepository(db),
+			Scopes:                memory.NewScopeRepository(db),
+			Tenants:               memory.NewTenantRepository(db),
+			TenantRoles:           memory.NewTenantRoleRepository(db),
+			Memberships:           memory.NewMembershipRepository(db),
+			EmailSettings:         memory.NewEmailSettingsRepository(db),
+			AuditEvents:           memory.NewAuditRepository(db),
+			AuditRetention:        memory.NewAuditRetentionRepository(db),
+			Webhooks:              memory.NewWebhookSubscriptionRepository(db),
+			Deliveries:            memory.NewWebhookDeliveryRepository(db),
+			Replay:                memory.NewReplayRepository(db),
+			PendingAuthorizations: memory.NewPendingAuthorizationRepository(db),
+			ProtocolLogs:          memory.NewProtocolLogRepository(db),
+			SecurityPolicies:      memory.NewSecurityPolicyRepository(db),
+			EmailChanges:          memory.NewEmailChangeRepository(db),
+			AccountDeletions:      memory.NewAccountDeletionRepository(db),
+			driver:                DriverMemory,
+			// The in-memory store is seeded on creation and has no schema to migrate
+			close: func() {},
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// With returns a copy of s that uses the repositories set in overrides instead of its own, so that
+// a program embedding the server can keep some of the data in its own storage. Migrations, the bus
+// transport and Close still apply to the backend of s only.
+func (s *Store) With(overrides Store) *Store {
+	c := *s
+	override(&c.Users, overrides.Users)
+	override(&c.Sessions, overrides.Sessions)
+	override(&c.Projects, overrides.Projects)
+	override(&c.Roles, overrides.Roles)
+	override(&c.Assignments, overrides.Assignments)
+	override(&c.Clients, overrides.Clients)
+	override(&c.Codes, overrides.Codes)
+	override(&c.AccessTokens, overrides.AccessTokens)
+	override(&c.RefreshTokens, overrides.RefreshTokens)
+	override(&c.Keys, overrides.Keys)
+	override(&c.Scopes, overrides.Scopes)
+	override(&c.Tenants, overrides.Tenants)
+	override(&c.TenantRoles, overrides.TenantRoles)
+	override(&c.Memberships, overrides.Memberships)
+	override(&c.EmailSettings, overrides.EmailSettings)
+	override(&c.AuditEvents, overrides.AuditEvents)
+	override(&c.AuditRetention, overrides.AuditRetention)
+	override(&c.Webhooks, overrides.Webhooks)
+	override(&c.Deliveries, overrides.Deliveries)
+	override(&c.Replay, overrides.Replay)
+	override(&c.PendingAuthorizations, overrides.PendingAuthorizations)
+	override(&c.ProtocolLogs, overrides.ProtocolLogs)
+	override(&c.SecurityPolicies, overrides.SecurityPolicies)
+	override(&c.EmailChanges, overrides.EmailChanges)
+	override(&c.AccountDeletions, overrides.AccountDeletions)
+	return &c
+}
+
+// override sets *repo to replacement unless replacement is nil
+func override[T any](repo *T, replacement T) {
+	if any(replacement) != nil {
+		*repo = replacement
+	}
+}
+
+// Driver returns the name of the backend in use
+func (s *Store) Driver() string {
+	return s.driver
+}
+
+// Migrate applies all schema migrations for the backend
+func (s *Store) Migrate(ctx context.Context) error {
+	for i, script := range s.migrations {
+		if err := s.migrate(ctx, script); err != nil {
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// BusTransport returns the transport the database offers for the cluster event bus: LISTEN/NOTIFY
+// on PostgreSQL. SQLite and the in-memory store serve a single process and return nil, as does
+// CockroachDB, which has no LISTEN/NOTIFY.
+func (s *Store) BusTransport() bus.Transport {
+	return s.busTransport
+}
+
+// Saturated reports whether the database connection pool is exhausted and queries fail fast. Only
+// PostgreSQL pools connections; the other backends always return false.
+func (s *Store) Saturated() bool {
+	return s.saturated != nil && s.saturated()
+}
+
+// Close closes the underlying connection
+func (s *Store) Close() {
+	s.close()
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import "context"
+
+type scopeKey struct{}
+
+// AllTenants is the scope of platform-wide work, such as migrations, background jobs, the CLI
+// and requests whose tenant is not known yet. Stores that enforce tenant isolation below the
+// query level see every row under it, and none at all without a scope.
+const AllTenants = "*"
+
+// WithScope returns a context scoped to a tenant. Stores that enforce tenant isolation
+// below the query level, such as PostgreSQL row-level security, only return and accept
+// rows of that tenant (and rows that belong to no tenant) for queries made with it.
+// An empty tenant ID leaves the context as it is.
+func WithScope(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, scopeKey{}, tenantID)
+}
+
+// ScopeFrom returns the tenant a context is scoped to, AllTenants for platform-wide work, or ""
+// when it has no scope
+func ScopeFrom(ctx context.Context) string {
+	tenantID, _ := ctx.Value(scopeKey{}).(string)
+	return tenantID
+}
+
+// WithoutScope returns a context for platform-wide work, which is not restricted to a tenant even
+// if ctx is. Besides the entry points of such work, it is for the platform operations that read a
+// record of another tenant than the one a request is scoped to.
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, AllTenants)
+}
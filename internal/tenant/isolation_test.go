@@ -19,6 +19,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/id"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -39,6 +40,11 @@ func (m *mockRoleRepo) RevokeRole(ctx context.Context, tenantID, userID, role st
 	return args.Error(0)
 }
 
+func (m *mockRoleRepo) RevokeOwnerRole(ctx context.Context, tenantID, userID, role string) error {
+	args := m.Called(ctx, tenantID, userID, role)
+	return args.Error(0)
+}
+
 func (m *mockRoleRepo) GetUserRoles(ctx context.Context, tenantID, userID string) ([]*TenantUserRole, error) {
 	args := m.Called(ctx, tenantID, userID)
 	if args.Get(0) == nil {
@@ -155,9 +161,12 @@ func TestTenant_Isolation_RevokeRole_ValidRole_Succeeds(t *testing.T) {
 
 	roleRepo.On("RevokeRole", ctx, tenantID, userID, RoleTenantMember).Return(nil)
 
-	err := service.RevokeRole(ctx, tenantID, userID, RoleTenantMember)
+	err := service.RevokeRole(ctx, tenantID, userID, RoleTenantMember, "admin-1")
 	assert.NoError(t, err)
 	roleRepo.AssertExpectations(t)
+	auditLogger.AssertCalled(t, "Log", mock.Anything, mock.MatchedBy(func(e audit.Event) bool {
+		return e.Type == audit.TypeRoleRevoked && e.ActorID == "admin-1"
+	}))
 }
 
 // TestPurpose: Validates that retrieval of user roles returns all roles assigned to that user in a specific tenant.
@@ -0,0 +1,125 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+)
+
+// Listing errors
+var (
+	ErrInvalidCursor = apperr.New(apperr.CodeInvalidArgument, "invalid pagination cursor")
+	ErrInvalidSort   = apperr.New(apperr.CodeInvalidArgument, "invalid sort option")
+	ErrInvalidStatus = apperr.New(apperr.CodeInvalidArgument, "invalid status filter")
+)
+
+// Pagination limits
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+// Sort options for tenant listing
+const (
+	SortCreatedDesc = "created_at_desc"
+	SortCreatedAsc  = "created_at_asc"
+	SortNameAsc     = "name_asc"
+	SortNameDesc    = "name_desc"
+)
+
+// ListOptions controls tenant listing
+type ListOptions struct {
+	Limit  int
+	Cursor string
+	Query  string // Case-insensitive substring match on name
+	Status string
+	Sort   string
+}
+
+// ListResult is a single page of tenants
+type ListResult struct {
+	Tenants    []*Tenant `json:"tenants"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// Cursor is the decoded position of the last item on a page.
+// Value holds the sort key of that item (RFC 3339 timestamp or name); ID breaks ties.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Normalize applies defaults and validates the options
+func (o *ListOptions) Normalize() error {
+	if o.Limit <= 0 {
+		o.Limit = DefaultListLimit
+	}
+	if o.Limit > MaxListLimit {
+		o.Limit = MaxListLimit
+	}
+
+	o.Query = strings.TrimSpace(o.Query)
+
+	switch o.Status {
+	case "", StatusActive, StatusInactive:
+	default:
+		return ErrInvalidStatus
+	}
+
+	switch o.Sort {
+	case "":
+		o.Sort = SortCreatedDesc
+	case SortCreatedDesc, SortCreatedAsc, SortNameAsc, SortNameDesc:
+	default:
+		return ErrInvalidSort
+	}
+
+	if o.Cursor != "" {
+		c, err := DecodeCursor(o.Cursor)
+		if err != nil {
+			return err
+		}
+		// A cursor is only meaningful for the ordering that produced it
+		if c.Sort != o.Sort {
+			return ErrInvalidCursor
+		}
+	}
+
+	return nil
+}
+
+// EncodeCursor returns an opaque cursor string
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses an opaque cursor string
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
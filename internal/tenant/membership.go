@@ -0,0 +1,193 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/id"
+)
+
+var (
+	ErrMembershipNotFound = apperr.New(apperr.CodeNotFound, "membership not found")
+	ErrMembershipExists   = apperr.New(apperr.CodeAlreadyExists, "user is already a member of the tenant")
+	ErrHomeMembership     = apperr.New(apperr.CodeFailedPrecondition, "cannot remove a user from the tenant they are registered in")
+	ErrPlatformUserMember = apperr.New(apperr.CodeFailedPrecondition, "platform users cannot be tenant members")
+	ErrNotMember          = apperr.New(apperr.CodeFailedPrecondition, "user is not a member of the tenant")
+)
+
+// Membership makes a user a member of a tenant. A user is a member of the tenant they are registered
+// in (their home tenant) from creation until purged; further memberships let the same identity sign
+// in to other tenants and be granted roles there.
+type Membership struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	UserID    string    `json:"user_id"`
+	Home      bool      `json:"home"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"` // User ID of whoever added the member; empty for home memberships
+}
+
+// MembershipRepository defines the interface for tenant membership storage
+type MembershipRepository interface {
+	Add(ctx context.Context, m *Membership) error
+	Remove(ctx context.Context, tenantID, userID string) error
+	Get(ctx context.Context, tenantID, userID string) (*Membership, error)
+	// ListByTenant lists the members of a tenant, oldest first
+	ListByTenant(ctx context.Context, tenantID string) ([]*Membership, error)
+	// ListByUser lists a user's memberships in tenants that are not deleted, home first
+	ListByUser(ctx context.Context, userID string) ([]*Membership, error)
+}
+
+// MembershipService manages which tenants a user is a member of
+type MembershipService struct {
+	repo        MembershipRepository
+	tenants     *Service
+	auditLogger audit.Logger
+}
+
+// NewMembershipService creates a new membership service; tenants revokes a removed member's roles
+func NewMembershipService(repo MembershipRepository, tenants *Service, auditLogger audit.Logger) *MembershipService {
+	return &MembershipService{
+		repo:        repo,
+		tenants:     tenants,
+		auditLogger: auditLogger,
+	}
+}
+
+// AddMember makes a user registered in homeTenantID a member of tenantID.
+// Platform users, who have no home tenant, are never tenant members: their access is granted by platform roles.
+func (s *MembershipService) AddMember(ctx context.Context, tenantID, userID, homeTenantID, addedBy string) (*Membership, error) {
+	if homeTenantID == "" {
+		return nil, ErrPlatformUserMember
+	}
+	if homeTenantID == tenantID {
+		return nil, ErrMembershipExists
+	}
+	if _, err := s.tenants.GetTenant(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	m := &Membership{
+		ID:        id.NewUUIDv7(),
+		TenantID:  tenantID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		CreatedBy: addedBy,
+	}
+	if err := s.repo.Add(ctx, m); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeMemberAdded,
+		TenantID: tenantID,
+		ActorID:  addedBy,
+		Resource: audit.ResourceMembership,
+		Payload:  &audit.MembershipPayload{MembershipID: m.ID, TargetUserID: userID, HomeTenantID: homeTenantID},
+	})
+	return m, nil
+}
+
+// IsLastOwnerAnywhere reports whether userID is the last owner of their home tenant or of a tenant
+// they are a member of
+func (s *MembershipService) IsLastOwnerAnywhere(ctx context.Context, homeTenantID, userID string) (bool, error) {
+	memberships, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	tenantIDs := []string{homeTenantID}
+	for _, m := range memberships {
+		if !slices.Contains(tenantIDs, m.TenantID) {
+			tenantIDs = append(tenantIDs, m.TenantID)
+		}
+	}
+	for _, tenantID := range tenantIDs {
+		if tenantID == "" {
+			continue
+		}
+		last, err := s.tenants.isLastOwner(ctx, tenantID, userID)
+		if err != nil || last {
+			return last, err
+		}
+	}
+	return false, nil
+}
+
+// RemoveMember removes a user from a tenant together with their roles in it.
+// The home membership cannot be removed, and neither can the tenant's last owner.
+func (s *MembershipService) RemoveMember(ctx context.Context, tenantID, userID, removedBy string) error {
+	m, err := s.repo.Get(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if m.Home {
+		return ErrHomeMembership
+	}
+	last, err := s.tenants.isLastOwner(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if last {
+		return ErrLastTenantOwner
+	}
+
+	roles, err := s.tenants.GetUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if err := s.tenants.RevokeRole(ctx, tenantID, userID, r.Role, removedBy); err != nil && !errors.Is(err, ErrRoleNotFound) {
+			return fmt.Errorf("failed to revoke %s role: %w", r.Role, err)
+		}
+	}
+	if err := s.repo.Remove(ctx, tenantID, userID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeMemberRemoved,
+		TenantID: tenantID,
+		ActorID:  removedBy,
+		Resource: audit.ResourceMembership,
+		Payload:  &audit.MembershipPayload{MembershipID: m.ID, TargetUserID: userID},
+	})
+	return nil
+}
+
+// ListMembers lists the members of a tenant
+func (s *MembershipService) ListMembers(ctx context.Context, tenantID string) ([]*Membership, error) {
+	return s.repo.ListByTenant(ctx, tenantID)
+}
+
+// ListUserMemberships lists the tenants a user is a member of, home first
+func (s *MembershipService) ListUserMemberships(ctx context.Context, userID string) ([]*Membership, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// IsMember reports whether a user is a member of a tenant
+func (s *MembershipService) IsMember(ctx context.Context, tenantID, userID string) (bool, error) {
+	_, err := s.repo.Get(ctx, tenantID, userID)
+	if errors.Is(err, ErrMembershipNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -16,13 +16,15 @@ package tenant
 
 import (
 	"context"
-	"errors"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
 )
 
 var (
-	ErrTenantNotFound    = errors.New("tenant not found")
-	ErrRoleNotFound      = errors.New("role not found")
-	ErrRoleAlreadyExists = errors.New("role assignment already exists")
+	ErrTenantNotFound    = apperr.New(apperr.CodeNotFound, "tenant not found")
+	ErrRoleNotFound      = apperr.New(apperr.CodeNotFound, "role not found")
+	ErrRoleAlreadyExists = apperr.New(apperr.CodeAlreadyExists, "role assignment already exists")
 )
 
 // Repository defines the interface for tenant storage
@@ -32,13 +34,22 @@ type Repository interface {
 	GetByName(ctx context.Context, name string) (*Tenant, error)
 	Update(ctx context.Context, tenant *Tenant) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, limit, offset int) ([]*Tenant, error)
+	List(ctx context.Context, opts ListOptions) (*ListResult, error)
+	// CountByStatus counts the tenants that are not deleted, by status
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+	// PurgeDeleted permanently removes up to limit tenants soft-deleted before the given time.
+	// Tenants that still have users are kept until those users are purged.
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
 }
 
 // RoleRepository defines the interface for tenant role storage
 type RoleRepository interface {
 	AssignRole(ctx context.Context, role *TenantUserRole) error
 	RevokeRole(ctx context.Context, tenantID, userID, role string) error
+	// RevokeOwnerRole revokes an owner or admin role unless the user is the tenant's only owner, which
+	// returns ErrLastTenantOwner. The check and the revocation are atomic, so owners revoking each
+	// other at the same time cannot leave the tenant without one.
+	RevokeOwnerRole(ctx context.Context, tenantID, userID, role string) error
 	GetUserRoles(ctx context.Context, tenantID, userID string) ([]*TenantUserRole, error)
 	GetTenantUsers(ctx context.Context, tenantID string) ([]*TenantUserRole, error)
 }
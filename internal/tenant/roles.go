@@ -23,6 +23,10 @@ const (
 	RoleTenantMember = "tenant_member"
 )
 
+// GranterSystem is the granter of role assignments made by OpenTrusty itself, such as by bootstrap or
+// the CLI, rather than by a user. Assignments stored without a granter are reported as system grants.
+const GranterSystem = "system"
+
 // TenantUserRole represents a user's role assignment in a tenant
 type TenantUserRole struct {
 	ID        string    `json:"id"`
@@ -30,5 +34,5 @@ type TenantUserRole struct {
 	UserID    string    `json:"user_id"`
 	Role      string    `json:"role"`
 	GrantedAt time.Time `json:"granted_at"`
-	GrantedBy string    `json:"granted_by"`
+	GrantedBy string    `json:"granted_by"` // User ID of the granter, or GranterSystem
 }
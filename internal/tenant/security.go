@@ -0,0 +1,280 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// Security policy errors
+var (
+	ErrSecurityPolicyNotFound = apperr.New(apperr.CodeNotFound, "security policy not found")
+	ErrInvalidSecurityPolicy  = apperr.New(apperr.CodeInvalidArgument, "invalid security policy")
+)
+
+// securityPolicyCacheTTL bounds how long a tenant's effective settings are cached, and so how long a
+// change takes to reach every instance
+const securityPolicyCacheTTL = 30 * time.Second
+
+// SecuritySettings are the protections of a tenant's logins and token endpoint requests
+type SecuritySettings struct {
+	LockoutMaxAttempts int           // Failed logins that lock an account
+	LockoutDuration    time.Duration // How long a locked account stays locked
+	LoginRPS           float64       // Login attempts per second for one user; zero leaves them unlimited
+	LoginBurst         int
+	TokenRPS           float64 // Token endpoint requests per second for one OAuth2 client; zero leaves them unlimited
+	TokenBurst         int
+	PasswordMaxAge     time.Duration // How long a password lasts before it must be changed; zero keeps it forever
+}
+
+// Merge returns s with the non-zero fields of override in place of its own
+func (s SecuritySettings) Merge(override SecuritySettings) SecuritySettings {
+	if override.LockoutMaxAttempts != 0 {
+		s.LockoutMaxAttempts = override.LockoutMaxAttempts
+	}
+	if override.LockoutDuration != 0 {
+		s.LockoutDuration = override.LockoutDuration
+	}
+	if override.LoginRPS != 0 {
+		s.LoginRPS = override.LoginRPS
+	}
+	if override.LoginBurst != 0 {
+		s.LoginBurst = override.LoginBurst
+	}
+	if override.TokenRPS != 0 {
+		s.TokenRPS = override.TokenRPS
+	}
+	if override.TokenBurst != 0 {
+		s.TokenBurst = override.TokenBurst
+	}
+	if override.PasswordMaxAge != 0 {
+		s.PasswordMaxAge = override.PasswordMaxAge
+	}
+	return s
+}
+
+// SecurityBounds are the platform's security settings and the strictest a tenant may choose. A
+// tenant can only tighten the platform's settings, so every value it sets lies between the two.
+type SecurityBounds struct {
+	Platform  SecuritySettings // Applies to tenants without a policy of their own
+	Strictest SecuritySettings
+}
+
+// validate reports the first setting of override that is looser than the platform's or stricter
+// than the strictest allowed. Unset fields are always valid.
+func (b SecurityBounds) validate(override SecuritySettings) error {
+	p, strict := b.Platform, b.Strictest
+	loginRPS, tokenRPS := unlimited(p.LoginRPS), unlimited(p.TokenRPS)
+	maxAge := unlimited(p.PasswordMaxAge.Seconds())
+	checks := []struct {
+		name      string
+		value     float64
+		low, high float64
+	}{
+		{"lockout_max_attempts", float64(override.LockoutMaxAttempts), float64(min(strict.LockoutMaxAttempts, p.LockoutMaxAttempts)), float64(p.LockoutMaxAttempts)},
+		{"lockout_duration_seconds", override.LockoutDuration.Seconds(), p.LockoutDuration.Seconds(), max(strict.LockoutDuration, p.LockoutDuration).Seconds()},
+		{"login_rps", override.LoginRPS, min(strict.LoginRPS, loginRPS), loginRPS},
+		{"login_burst", float64(override.LoginBurst), 1, burstLimit(p.LoginRPS, p.LoginBurst)},
+		{"token_rps", override.TokenRPS, min(strict.TokenRPS, tokenRPS), tokenRPS},
+		{"token_burst", float64(override.TokenBurst), 1, burstLimit(p.TokenRPS, p.TokenBurst)},
+		{"password_max_age_seconds", override.PasswordMaxAge.Seconds(), min(strict.PasswordMaxAge.Seconds(), maxAge), maxAge},
+	}
+	for _, c := range checks {
+		if c.value == 0 || (c.value >= c.low && c.value <= c.high) {
+			continue
+		}
+		if math.IsInf(c.high, 1) {
+			return fmt.Errorf("%w: %s must be at least %v", ErrInvalidSecurityPolicy, c.name, c.low)
+		}
+		return fmt.Errorf("%w: %s must be between %v and %v", ErrInvalidSecurityPolicy, c.name, c.low, c.high)
+	}
+
+	effective := p.Merge(override)
+	if override.LoginBurst != 0 && effective.LoginRPS == 0 {
+		return fmt.Errorf("%w: login_burst requires login_rps", ErrInvalidSecurityPolicy)
+	}
+	if override.TokenBurst != 0 && effective.TokenRPS == 0 {
+		return fmt.Errorf("%w: token_burst requires token_rps", ErrInvalidSecurityPolicy)
+	}
+	return nil
+}
+
+// unlimited returns limit, or no bound when the platform leaves it unlimited
+func unlimited(limit float64) float64 {
+	if limit <= 0 {
+		return math.Inf(1)
+	}
+	return limit
+}
+
+// burstLimit returns the platform's burst, or no bound when it leaves the rate unlimited
+func burstLimit(rps float64, burst int) float64 {
+	if rps <= 0 {
+		return math.Inf(1)
+	}
+	return float64(max(burst, 1))
+}
+
+// SecurityPolicy is a tenant's own security settings, so that a high-security tenant can tighten
+// lockout, rate limits and password age without a deployment
+type SecurityPolicy struct {
+	TenantID  string
+	Settings  SecuritySettings // Zero fields inherit the platform's
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+// SecurityPolicyRepository persists per-tenant security policies
+type SecurityPolicyRepository interface {
+	// Get retrieves a tenant's policy
+	Get(ctx context.Context, tenantID string) (*SecurityPolicy, error)
+
+	// Upsert creates or replaces a tenant's policy
+	Upsert(ctx context.Context, policy *SecurityPolicy) error
+
+	// Delete removes a tenant's policy so the platform's settings apply again
+	Delete(ctx context.Context, tenantID string) error
+}
+
+// securityPolicyEntry caches a tenant's effective settings
+type securityPolicyEntry struct {
+	settings  SecuritySettings
+	fetchedAt time.Time
+}
+
+// securityPolicies holds the security policies, their bounds and the effective settings cache
+type securityPolicies struct {
+	repo   SecurityPolicyRepository
+	bounds SecurityBounds
+	cache  sync.Map // tenant ID -> securityPolicyEntry
+}
+
+// EnableSecurityPolicies lets tenants tighten the platform's security settings within bounds, with
+// policies kept in repo. Without it, every tenant has the platform's settings.
+func (s *Service) EnableSecurityPolicies(repo SecurityPolicyRepository, bounds SecurityBounds) {
+	s.security = &securityPolicies{repo: repo, bounds: bounds}
+}
+
+// SecurityBounds returns the platform's security settings and the strictest a tenant may choose
+func (s *Service) SecurityBounds() SecurityBounds {
+	if s.security == nil {
+		return SecurityBounds{}
+	}
+	return s.security.bounds
+}
+
+// GetSecurityPolicy returns a tenant's policy; a tenant that never set one gets an empty policy
+func (s *Service) GetSecurityPolicy(ctx context.Context, tenantID string) (*SecurityPolicy, error) {
+	if s.security == nil {
+		return &SecurityPolicy{TenantID: tenantID}, nil
+	}
+	policy, err := s.security.repo.Get(ctx, tenantID)
+	if errors.Is(err, ErrSecurityPolicyNotFound) {
+		return &SecurityPolicy{TenantID: tenantID}, nil
+	}
+	return policy, err
+}
+
+// SetSecurityPolicy replaces a tenant's policy. Every setting given must lie between the platform's
+// and the strictest allowed; settings left zero inherit the platform's.
+func (s *Service) SetSecurityPolicy(ctx context.Context, tenantID, actorID string, settings SecuritySettings) (*SecurityPolicy, error) {
+	if s.security == nil {
+		return nil, fmt.Errorf("%w: security policies are not available", ErrInvalidSecurityPolicy)
+	}
+	if err := s.security.bounds.validate(settings); err != nil {
+		return nil, err
+	}
+
+	policy := &SecurityPolicy{
+		TenantID:  tenantID,
+		Settings:  settings,
+		UpdatedBy: actorID,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.security.repo.Upsert(ctx, policy); err != nil {
+		return nil, err
+	}
+	s.security.cache.Delete(tenantID)
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeSecurityPolicyUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceSecurityPolicy,
+		Payload: &audit.SecurityPolicyPayload{
+			LockoutMaxAttempts:     settings.LockoutMaxAttempts,
+			LockoutDurationSeconds: int(settings.LockoutDuration.Seconds()),
+			LoginRPS:               settings.LoginRPS,
+			LoginBurst:             settings.LoginBurst,
+			TokenRPS:               settings.TokenRPS,
+			TokenBurst:             settings.TokenBurst,
+			PasswordMaxAgeSeconds:  int(settings.PasswordMaxAge.Seconds()),
+		},
+	})
+	return policy, nil
+}
+
+// DeleteSecurityPolicy removes a tenant's policy so the platform's settings apply again
+func (s *Service) DeleteSecurityPolicy(ctx context.Context, tenantID, actorID string) error {
+	if s.security == nil {
+		return ErrSecurityPolicyNotFound
+	}
+	if err := s.security.repo.Delete(ctx, tenantID); err != nil {
+		return err
+	}
+	s.security.cache.Delete(tenantID)
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeSecurityPolicyDeleted,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceSecurityPolicy,
+	})
+	return nil
+}
+
+// EffectiveSecurity returns the settings in force for a tenant: its policy over the platform's
+// settings, read at most once per securityPolicyCacheTTL. A policy that cannot be read leaves the
+// platform's settings in force.
+func (s *Service) EffectiveSecurity(ctx context.Context, tenantID string) SecuritySettings {
+	if s.security == nil {
+		return SecuritySettings{}
+	}
+	now := time.Now()
+	if v, ok := s.security.cache.Load(tenantID); ok {
+		if entry := v.(securityPolicyEntry); now.Sub(entry.fetchedAt) < securityPolicyCacheTTL {
+			return entry.settings
+		}
+	}
+
+	policy, err := s.GetSecurityPolicy(ctx, tenantID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read security policy", logger.TenantID(tenantID), logger.Error(err))
+		return s.security.bounds.Platform
+	}
+	settings := s.security.bounds.Platform.Merge(policy.Settings)
+	s.security.cache.Store(tenantID, securityPolicyEntry{settings: settings, fetchedAt: now})
+	return settings
+}
@@ -32,6 +32,7 @@ type Service struct {
 	roleRepo    RoleRepository
 	authzRepo   authz.AssignmentRepository
 	auditLogger audit.Logger
+	security    *securityPolicies // nil gives every tenant the platform's security settings
 }
 
 // NewService creates a new tenant service
@@ -86,10 +87,10 @@ func (s *Service) CreateTenant(ctx context.Context, name string, creatorUserID s
 		Scope:          authz.ScopeTenant,
 		ScopeContextID: &tenantID,
 		GrantedAt:      now,
-		GrantedBy:      audit.ActorSystemBootstrap, // System-granted during creation
+		GrantedBy:      creatorUserID,
 	}
 
-	if err := s.authzRepo.Grant(assignment); err != nil {
+	if err := s.authzRepo.Grant(ctx, assignment); err != nil {
 		// Note: In a true transaction, we'd rollback tenant creation here
 		// For MVP, we log and continue
 		return nil, fmt.Errorf("failed to assign tenant admin role: %w", err)
@@ -108,13 +109,26 @@ func (s *Service) GetTenantByName(ctx context.Context, name string) (*Tenant, er
 	return s.repo.GetByName(ctx, name)
 }
 
-// ListTenants lists tenants with pagination
-func (s *Service) ListTenants(ctx context.Context, limit, offset int) ([]*Tenant, error) {
-	return s.repo.List(ctx, limit, offset)
+// ListTenants lists tenants with cursor pagination, name search and status filtering
+func (s *Service) ListTenants(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, opts)
+}
+
+// CountTenants counts the tenants that are not deleted, by status
+func (s *Service) CountTenants(ctx context.Context) (map[string]int64, error) {
+	return s.repo.CountByStatus(ctx)
 }
 
-// AssignRole assigns a role to a user in a tenant
+// AssignRole assigns a role to a user in a tenant.
+// grantedBy is the granting user's ID, or GranterSystem; it is required so the assignment and its audit event name who made it.
 func (s *Service) AssignRole(ctx context.Context, tenantID, userID, role string, grantedBy string) error {
+	if grantedBy == "" {
+		return ErrGranterRequired
+	}
+
 	// Validate role
 	if role != RoleTenantOwner && role != RoleTenantAdmin && role != RoleTenantMember {
 		return fmt.Errorf("invalid role: %s", role)
@@ -137,33 +151,52 @@ func (s *Service) AssignRole(ctx context.Context, tenantID, userID, role string,
 		Type:     audit.TypeRoleAssigned,
 		TenantID: tenantID,
 		ActorID:  grantedBy,
-		Resource: role,
-		Metadata: map[string]any{audit.AttrActorID: userID},
+		Resource: audit.ResourceRole,
+		Payload:  &audit.RolePayload{RoleID: role, TargetUserID: userID},
 	})
 
 	return nil
 }
 
-// RevokeRole revokes a role from a user in a tenant
-func (s *Service) RevokeRole(ctx context.Context, tenantID, userID, role string) error {
-	if err := s.roleRepo.RevokeRole(ctx, tenantID, userID, role); err != nil {
+// RevokeRole revokes a role from a user in a tenant.
+// The tenant's last owner cannot be revoked, including by themselves, so the tenant always keeps someone able to manage it.
+func (s *Service) RevokeRole(ctx context.Context, tenantID, userID, role string, revokedBy string) error {
+	revoke := s.roleRepo.RevokeRole
+	if role == RoleTenantOwner || role == RoleTenantAdmin {
+		revoke = s.roleRepo.RevokeOwnerRole
+	}
+	if err := revoke(ctx, tenantID, userID, role); err != nil {
 		return err
 	}
 
-	// Audit role revocation (Note: ActorID logic needs context, here assumed context or empty.
-	// We might need to pass `revokedBy` similar to `grantedBy` but for now we'll rely on ActorID if context provided it, or leave empty)
-	// Actually, `ctx` doesn't inherently carry ActorID unless we standardise it.
-	// For now, let's leave ActorID empty or "system" if unknown.
+	// Audit role revocation
 	s.auditLogger.Log(ctx, audit.Event{
 		Type:     audit.TypeRoleRevoked,
 		TenantID: tenantID,
-		Resource: role,
-		Metadata: map[string]any{audit.AttrActorID: userID},
+		ActorID:  revokedBy,
+		Resource: audit.ResourceRole,
+		Payload:  &audit.RolePayload{RoleID: role, TargetUserID: userID},
 	})
 
 	return nil
 }
 
+// isLastOwner reports whether userID is the only user who owns the tenant.
+// Owners are stored with the tenant_admin role, so every user able to manage the tenant counts as an owner.
+func (s *Service) isLastOwner(ctx context.Context, tenantID, userID string) (bool, error) {
+	roles, err := s.roleRepo.GetTenantUsers(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+	owners := make(map[string]bool)
+	for _, r := range roles {
+		if r.Role == RoleTenantOwner || r.Role == RoleTenantAdmin {
+			owners[r.UserID] = true
+		}
+	}
+	return owners[userID] && len(owners) == 1, nil
+}
+
 // GetUserRoles retrieves all roles a user has in a tenant
 func (s *Service) GetUserRoles(ctx context.Context, tenantID, userID string) ([]*TenantUserRole, error) {
 	return s.roleRepo.GetUserRoles(ctx, tenantID, userID)
@@ -2,11 +2,14 @@ package tenant
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
 	"github.com/opentrusty/opentrusty/internal/rbac"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -41,26 +44,41 @@ func (m *mockRepo) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
-func (m *mockRepo) List(ctx context.Context, limit, offset int) ([]*Tenant, error) {
-	args := m.Called(ctx, limit, offset)
-	return args.Get(0).([]*Tenant), args.Error(1)
+func (m *mockRepo) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *mockRepo) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).(*ListResult), args.Error(1)
+}
+
+func (m *mockRepo) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 type mockAssignmentRepo struct {
 	mock.Mock
 }
 
-func (m *mockAssignmentRepo) Grant(assignment *authz.Assignment) error {
+func (m *mockAssignmentRepo) Grant(ctx context.Context, assignment *authz.Assignment) error {
 	args := m.Called(assignment)
 	return args.Error(0)
 }
 
-func (m *mockAssignmentRepo) Revoke(userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+func (m *mockAssignmentRepo) Revoke(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
+	args := m.Called(userID, roleID, scope, scopeContextID)
+	return args.Error(0)
+}
+
+func (m *mockAssignmentRepo) RevokeUnlessLast(ctx context.Context, userID, roleID string, scope authz.Scope, scopeContextID *string) error {
 	args := m.Called(userID, roleID, scope, scopeContextID)
 	return args.Error(0)
 }
 
-func (m *mockAssignmentRepo) ListForUser(userID string) ([]*authz.Assignment, error) {
+func (m *mockAssignmentRepo) ListForUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Assignment, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -68,12 +86,12 @@ func (m *mockAssignmentRepo) ListForUser(userID string) ([]*authz.Assignment, er
 	return args.Get(0).([]*authz.Assignment), args.Error(1)
 }
 
-func (m *mockAssignmentRepo) ListByRole(roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
+func (m *mockAssignmentRepo) ListByRole(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
 	args := m.Called(roleID, scope, scopeContextID)
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *mockAssignmentRepo) CheckExists(roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
+func (m *mockAssignmentRepo) CheckExists(ctx context.Context, roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
 	args := m.Called(roleID, scope, scopeContextID)
 	return args.Get(0).(bool), args.Error(1)
 }
@@ -131,3 +149,121 @@ func TestTenant_Service_CreateTenant_UUIDv7(t *testing.T) {
 	repo.AssertExpectations(t)
 	authzRepo.AssertExpectations(t)
 }
+
+// TestPurpose: Validates that tenant listing options are normalized and validated before reaching the repository.
+// Scope: Unit Test
+// Security: Bounded page sizes prevent unbounded scans; malformed cursors and filters are rejected
+// Expected: Defaults are applied, limits are capped, and invalid sort/status/cursor values return errors.
+// Test Case ID: TEN-08
+func TestTenant_Service_ListTenants_Options(t *testing.T) {
+	repo := new(mockRepo)
+	service := NewService(repo, nil, nil, nil)
+	ctx := context.Background()
+
+	repo.On("List", ctx, ListOptions{Limit: MaxListLimit, Query: "acme", Sort: SortCreatedDesc}).
+		Return(&ListResult{Tenants: []*Tenant{}}, nil)
+
+	result, err := service.ListTenants(ctx, ListOptions{Limit: 10000, Query: "  acme  "})
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	repo.AssertExpectations(t)
+
+	_, err = service.ListTenants(ctx, ListOptions{Sort: "random"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+
+	_, err = service.ListTenants(ctx, ListOptions{Status: "deleted"})
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+
+	_, err = service.ListTenants(ctx, ListOptions{Cursor: "!!not-base64!!"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	// A cursor issued for one ordering must not be replayed against another
+	cursor := EncodeCursor(Cursor{Sort: SortNameAsc, Value: "acme", ID: "t-1"})
+	_, err = service.ListTenants(ctx, ListOptions{Cursor: cursor, Sort: SortCreatedDesc})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	decoded, err := DecodeCursor(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "t-1", decoded.ID)
+}
+
+type memorySecurityPolicies map[string]*SecurityPolicy
+
+func (m memorySecurityPolicies) Get(ctx context.Context, tenantID string) (*SecurityPolicy, error) {
+	if p, ok := m[tenantID]; ok {
+		return p, nil
+	}
+	return nil, ErrSecurityPolicyNotFound
+}
+
+func (m memorySecurityPolicies) Upsert(ctx context.Context, p *SecurityPolicy) error {
+	m[p.TenantID] = p
+	return nil
+}
+
+func (m memorySecurityPolicies) Delete(ctx context.Context, tenantID string) error {
+	if _, ok := m[tenantID]; !ok {
+		return ErrSecurityPolicyNotFound
+	}
+	delete(m, tenantID)
+	return nil
+}
+
+// TestPurpose: Validates that a tenant's security policy can only tighten the platform's settings, within the platform's bounds.
+// Scope: Unit Test
+// Security: Brute-force protection (CWE-307) and rate limiting (CWE-770) cannot be loosened by a tenant
+// Expected: Settings between the platform's and the strictest are kept and audited and take effect over the platform's; looser or stricter settings, and a burst without a rate, are refused; deleting the policy restores the platform's settings.
+// Test Case ID: TEN-13
+func TestTenant_Service_SecurityPolicy(t *testing.T) {
+	ctx := context.Background()
+	auditLog := new(mockAudit)
+	auditLog.On("Log", mock.Anything, mock.Anything).Return()
+	s := NewService(new(mockRepo), new(mockRoleRepo), new(mockAssignmentRepo), auditLog)
+	s.EnableSecurityPolicies(memorySecurityPolicies{}, SecurityBounds{
+		Platform:  SecuritySettings{LockoutMaxAttempts: 5, LockoutDuration: 15 * time.Minute, LoginRPS: 0.1, LoginBurst: 5},
+		Strictest: SecuritySettings{LockoutMaxAttempts: 3, LockoutDuration: 24 * time.Hour, LoginRPS: 0.01, LoginBurst: 1, TokenRPS: 1, TokenBurst: 1, PasswordMaxAge: 24 * time.Hour},
+	})
+
+	if got := s.EffectiveSecurity(ctx, "t1"); got.LockoutMaxAttempts != 5 || got.LoginRPS != 0.1 {
+		t.Errorf("expected the platform's settings without a policy, got %+v", got)
+	}
+
+	for name, settings := range map[string]SecuritySettings{
+		"looser lockout":      {LockoutMaxAttempts: 10},
+		"too strict lockout":  {LockoutMaxAttempts: 1},
+		"shorter lockout":     {LockoutDuration: time.Minute},
+		"too long lockout":    {LockoutDuration: 48 * time.Hour},
+		"faster logins":       {LoginRPS: 1},
+		"larger login burst":  {LoginBurst: 6},
+		"negative token rate": {TokenRPS: -1},
+		"token burst alone":   {TokenBurst: 2},
+		"hourly passwords":    {PasswordMaxAge: time.Hour},
+	} {
+		if _, err := s.SetSecurityPolicy(ctx, "t1", "admin", settings); !errors.Is(err, ErrInvalidSecurityPolicy) {
+			t.Errorf("%s: expected ErrInvalidSecurityPolicy, got %v", name, err)
+		}
+	}
+
+	policy, err := s.SetSecurityPolicy(ctx, "t1", "admin", SecuritySettings{LockoutMaxAttempts: 3, LockoutDuration: time.Hour, TokenRPS: 5, TokenBurst: 10, PasswordMaxAge: 90 * 24 * time.Hour})
+	if err != nil || policy.UpdatedBy != "admin" {
+		t.Fatalf("expected the policy to be set, got %+v, %v", policy, err)
+	}
+	got := s.EffectiveSecurity(ctx, "t1")
+	if got.LockoutMaxAttempts != 3 || got.LockoutDuration != time.Hour || got.LoginRPS != 0.1 || got.LoginBurst != 5 || got.TokenRPS != 5 || got.PasswordMaxAge != 90*24*time.Hour {
+		t.Errorf("expected the tenant's settings over the platform's, got %+v", got)
+	}
+	auditLog.AssertCalled(t, "Log", mock.Anything, mock.MatchedBy(func(e audit.Event) bool {
+		p, ok := e.Payload.(*audit.SecurityPolicyPayload)
+		return e.Type == audit.TypeSecurityPolicyUpdated && e.TenantID == "t1" && ok && p.LockoutDurationSeconds == 3600
+	}))
+
+	if err := s.DeleteSecurityPolicy(ctx, "t1", "admin"); err != nil {
+		t.Fatalf("DeleteSecurityPolicy: %v", err)
+	}
+	if got := s.EffectiveSecurity(ctx, "t1"); got.LockoutMaxAttempts != 5 || got.TokenRPS != 0 {
+		t.Errorf("expected the platform's settings once the policy is deleted, got %+v", got)
+	}
+	if err := s.DeleteSecurityPolicy(ctx, "t1", "admin"); !errors.Is(err, ErrSecurityPolicyNotFound) {
+		t.Errorf("expected ErrSecurityPolicyNotFound, got %v", err)
+	}
+}
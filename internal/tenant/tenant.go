@@ -15,14 +15,17 @@
 package tenant
 
 import (
-	"errors"
 	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
 )
 
 // Domain errors (ErrTenantNotFound is defined in repository.go)
 var (
-	ErrTenantAlreadyExists = errors.New("tenant already exists")
-	ErrInvalidTenantName   = errors.New("invalid tenant name")
+	ErrTenantAlreadyExists = apperr.New(apperr.CodeAlreadyExists, "tenant already exists")
+	ErrInvalidTenantName   = apperr.New(apperr.CodeInvalidArgument, "invalid tenant name")
+	ErrLastTenantOwner     = apperr.New(apperr.CodeConflict, "cannot revoke the last owner of the tenant")
+	ErrGranterRequired     = apperr.New(apperr.CodeInvalidArgument, "role assignment requires a granter")
 )
 
 // Tenant represents an isolated environment or customer account
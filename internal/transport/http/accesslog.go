@@ -0,0 +1,117 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// unmatchedRoute labels requests that matched no route, so unknown paths cannot grow the metric series
+const unmatchedRoute = "unmatched"
+
+// AccessLog writes one structured log line per request and records its latency per route.
+// A nil AccessLog still logs but records no metrics.
+type AccessLog struct {
+	latency metric.Float64Histogram
+}
+
+// NewAccessLog creates the per-route latency histogram
+func NewAccessLog(meter *metrics.Meter) (*AccessLog, error) {
+	latency, err := meter.CreateHistogram("http.server.route.duration", "Request latency by method, route pattern and status", "ms")
+	if err != nil {
+		return nil, err
+	}
+	return &AccessLog{latency: latency}, nil
+}
+
+// accessLogEntry collects the request details only known once authentication or routing has
+// run further down the chain; those handlers see a derived context, so they fill this in
+type accessLogEntry struct {
+	tenantID string
+	userID   string
+}
+
+const accessLogKey contextKey = "access_log"
+
+// annotateAccessLog records the tenant and user serving the request in its access log line.
+// Empty values leave what is already recorded.
+func annotateAccessLog(ctx context.Context, tenantID, userID string) {
+	entry, ok := ctx.Value(accessLogKey).(*accessLogEntry)
+	if !ok {
+		return
+	}
+	if tenantID != "" {
+		entry.tenantID = tenantID
+	}
+	if userID != "" {
+		entry.userID = userID
+	}
+}
+
+func (a *AccessLog) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &accessLogEntry{}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			elapsed := time.Since(start)
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				// Nothing was written; net/http answers 200
+				status = http.StatusOK
+			}
+
+			slog.InfoContext(r.Context(), "http_request",
+				logger.RequestID(middleware.GetReqID(r.Context())),
+				logger.Method(r.Method),
+				logger.Route(route),
+				logger.Path(r.URL.Path),
+				logger.StatusCode(status),
+				logger.Duration(elapsed.Milliseconds()),
+				logger.Bytes(ww.BytesWritten()),
+				logger.TenantID(entry.tenantID),
+				logger.UserID(entry.userID),
+				logger.RemoteAddr(r.RemoteAddr),
+				logger.UserAgent(r.UserAgent()),
+			)
+
+			if a != nil {
+				a.latency.Record(r.Context(), float64(elapsed)/float64(time.Millisecond), metric.WithAttributes(
+					attribute.String("method", r.Method),
+					attribute.String("route", route),
+					attribute.String("status_code", strconv.Itoa(status)),
+				))
+			}
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), accessLogKey, entry)))
+	})
+}
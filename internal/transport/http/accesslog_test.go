@@ -0,0 +1,114 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// TestPurpose: Validates the access log line and per-route latency histogram.
+// Scope: Unit Test
+// Security: Observability (requests are attributable to a tenant and user; unknown paths cannot create metric series)
+// Expected: One log line carries the route pattern, status, bytes, tenant, user and request ID; latency is recorded per route pattern and unmatched paths share one series.
+// Test Case ID: API-05
+func TestAccessLog(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessLog, err := NewAccessLog(meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(accessLog.middleware)
+	r.Get("/tenants/{tenantID}/users/{userID}", func(w http.ResponseWriter, r *http.Request) {
+		annotateAccessLog(r.Context(), chi.URLParam(r, "tenantID"), "admin-1")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tenants/t1/users/u1", nil))
+	var line map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+		t.Fatalf("expected one JSON log line, got %q: %v", logs.String(), err)
+	}
+	want := map[string]any{
+		"msg":         "http_request",
+		"method":      "GET",
+		"route":       "/tenants/{tenantID}/users/{userID}",
+		"path":        "/tenants/t1/users/u1",
+		"status_code": float64(http.StatusAccepted),
+		"bytes":       float64(5),
+		"tenant_id":   "t1",
+		"user_id":     "admin-1",
+	}
+	for key, value := range want {
+		if line[key] != value {
+			t.Errorf("%s = %v, want %v", key, line[key], value)
+		}
+	}
+	if line["request_id"] == "" || line["request_id"] == nil {
+		t.Error("expected a request_id")
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope/1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope/2", nil))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	routes := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "http.server.route.duration" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				route, _ := dp.Attributes.Value("route")
+				routes[route.AsString()] += dp.Count
+			}
+		}
+	}
+	if len(routes) != 2 || routes["/tenants/{tenantID}/users/{userID}"] != 1 || routes[unmatchedRoute] != 2 {
+		t.Errorf("unexpected latency series %v", routes)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// AccountDeletionRequest asks to delete the current user's account
+type AccountDeletionRequest struct {
+	Password string `json:"password"` // The user's current password
+}
+
+// AccountDeletionResponse represents a pending account deletion
+type AccountDeletionResponse struct {
+	RequestedAt time.Time `json:"requested_at"`
+	// DeleteAt ends the grace period; the account is erased then unless the user logs in or cancels before
+	DeleteAt time.Time `json:"delete_at"`
+}
+
+func newAccountDeletionResponse(d *identity.AccountDeletion) AccountDeletionResponse {
+	return AccountDeletionResponse{
+		RequestedAt: d.RequestedAt,
+		DeleteAt:    d.DeleteAt,
+	}
+}
+
+// GetAccountDeletion handles retrieving the current user's pending account deletion
+// @Summary Get Account Deletion
+// @Description Get the deletion of the current user's account, pending until its grace period ends
+// @Tags User
+// @Produce json
+// @Success 200 {object} AccountDeletionResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/account-deletion [get]
+// @Security CookieAuth
+// @Security BearerAuth
+func (h *Handler) GetAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authorizeEmailChange(w, r)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.identityService.GetAccountDeletion(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to get account deletion")
+		return
+	}
+	respondJSON(w, http.StatusOK, newAccountDeletionResponse(deletion))
+}
+
+// RequestAccountDeletion handles a user asking to delete their own account
+// @Summary Request Account Deletion
+// @Description Delete the current user's account, confirmed with their password. The account is erased when the grace period ends unless the user logs in or cancels before; its record is anonymized and all its sessions and tokens are revoked. Without a grace period the account is erased at once and 204 is returned. Wrong passwords count towards lockout; the last owner of a tenant or the last platform admin is refused with 409.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body AccountDeletionRequest true "Current password"
+// @Success 202 {object} AccountDeletionResponse
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /user/account-deletion [post]
+// @Security CookieAuth
+// @Security BearerAuth
+func (h *Handler) RequestAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authorizeEmailChange(w, r)
+	if !ok {
+		return
+	}
+
+	var req AccountDeletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	deletion, err := h.identityService.RequestAccountDeletion(r.Context(), userID, req.Password)
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidCredentials) {
+			respondError(w, http.StatusUnauthorized, "invalid password")
+			return
+		}
+		respondServiceError(w, r, err, "failed to request account deletion")
+		return
+	}
+	if !deletion.DeleteAt.After(deletion.RequestedAt) {
+		// Erased at once; the session went with it
+		h.clearSessionCookie(w)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, newAccountDeletionResponse(deletion))
+}
+
+// CancelAccountDeletion handles a user keeping their account
+// @Summary Cancel Account Deletion
+// @Description Cancel the pending deletion of the current user's account
+// @Tags User
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/account-deletion [delete]
+// @Security CookieAuth
+// @Security BearerAuth
+func (h *Handler) CancelAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authorizeEmailChange(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.identityService.CancelAccountDeletion(r.Context(), userID); err != nil {
+		respondServiceError(w, r, err, "failed to cancel account deletion")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
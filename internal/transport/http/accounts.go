@@ -0,0 +1,146 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// AccountChoiceResponse is the body of a 409 when the credentials match accounts in several
+// tenants, or the account is a member of several tenants. The client must let the user choose and
+// repeat the login with the chosen account_id, or membership_id.
+type AccountChoiceResponse struct {
+	Error    string         `json:"error" example:"account selection required"`
+	Accounts []LoginAccount `json:"accounts"`
+}
+
+// LoginAccount is an account a login may sign in with; an empty tenant_id is the platform account.
+// When the choice is among an account's memberships, membership_id names the tenant to sign in to.
+type LoginAccount struct {
+	AccountID    string `json:"account_id"`
+	MembershipID string `json:"membership_id,omitempty"`
+	TenantID     string `json:"tenant_id,omitempty"`
+	TenantName   string `json:"tenant_name,omitempty"`
+}
+
+// selectAccount returns the tenant of the account a login authenticates, and true when the login
+// may proceed; otherwise it has answered the request. An email registered in one tenant needs no
+// choice. Registered in several, account_id names the account; without it the accounts the password
+// matches are offered with a 409, unless it matches only one. Memberships are never listed before
+// the password is verified.
+func (h *Handler) selectAccount(w http.ResponseWriter, r *http.Request, req *LoginRequest) (string, bool) {
+	accounts, err := h.identityService.Accounts(r.Context(), req.Email)
+	if err != nil {
+		h.loginFailed(w, r, req.Email)
+		return "", false
+	}
+
+	if req.AccountID != "" {
+		i := slices.IndexFunc(accounts, func(u *identity.User) bool { return u.ID == req.AccountID })
+		if i < 0 {
+			h.loginFailed(w, r, req.Email)
+			return "", false
+		}
+		return accountTenant(accounts[i]), true
+	}
+	switch len(accounts) {
+	case 0:
+		// Authenticate reports the unknown email like a wrong password
+		return "", true
+	case 1:
+		return accountTenant(accounts[0]), true
+	}
+
+	matched, err := h.identityService.MatchAccounts(r.Context(), accounts, req.Password)
+	if err != nil {
+		h.loginFailed(w, r, req.Email)
+		return "", false
+	}
+	if len(matched) == 1 {
+		return accountTenant(matched[0]), true
+	}
+
+	choice := AccountChoiceResponse{Error: "account selection required"}
+	for _, u := range matched {
+		choice.Accounts = append(choice.Accounts, h.loginAccount(r, LoginAccount{AccountID: u.ID, TenantID: accountTenant(u)}))
+	}
+	respondJSON(w, http.StatusConflict, choice)
+	return "", false
+}
+
+// selectMembership returns the tenant an authenticated user signs in to, and true when the login
+// may proceed; otherwise it has answered the request. A user who is a member of several tenants
+// chooses one with membership_id for an admin session; without it the memberships are offered with
+// a 409. Auth sessions stay in the home tenant, since the client being authorized names the tenant.
+func (h *Handler) selectMembership(w http.ResponseWriter, r *http.Request, req *LoginRequest, user *identity.User, namespace string) (string, bool) {
+	home := accountTenant(user)
+	if h.memberships == nil || namespace != session.NamespaceAdmin || home == "" {
+		return home, true
+	}
+
+	memberships, err := h.memberships.ListUserMemberships(r.Context(), user.ID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to list memberships")
+		return "", false
+	}
+	if req.MembershipID != "" {
+		i := slices.IndexFunc(memberships, func(m *tenant.Membership) bool { return m.ID == req.MembershipID })
+		if i < 0 {
+			respondError(w, http.StatusBadRequest, "membership_id is not a membership of the account")
+			return "", false
+		}
+		return memberships[i].TenantID, true
+	}
+	if len(memberships) <= 1 {
+		return home, true
+	}
+
+	choice := AccountChoiceResponse{Error: "tenant selection required"}
+	for _, m := range memberships {
+		choice.Accounts = append(choice.Accounts, h.loginAccount(r, LoginAccount{AccountID: user.ID, MembershipID: m.ID, TenantID: m.TenantID}))
+	}
+	respondJSON(w, http.StatusConflict, choice)
+	return "", false
+}
+
+// loginAccount names the tenant of an account offered for a login
+func (h *Handler) loginAccount(r *http.Request, account LoginAccount) LoginAccount {
+	if account.TenantID != "" && h.tenantService != nil {
+		if t, err := h.tenantService.GetTenant(r.Context(), account.TenantID); err == nil {
+			account.TenantName = t.Name
+		}
+	}
+	return account
+}
+
+func accountTenant(u *identity.User) string {
+	if u.TenantID == nil {
+		return ""
+	}
+	return *u.TenantID
+}
+
+// optionalTenant returns the tenant ID stored on a session; platform sessions have none
+func optionalTenant(tenantID string) *string {
+	if tenantID == "" {
+		return nil
+	}
+	return &tenantID
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TestPurpose: Validates that a user with accounts in several tenants chooses the account login authenticates.
+// Scope: Unit Test
+// Security: Tenant membership disclosure (accounts are only listed once the password is verified) and cross-tenant login confusion
+// Expected: Credentials matching several accounts get a 409 listing only those accounts; account_id picks one and proceeds to the role check; an account_id whose password does not match, or a password matching none, is invalid credentials; a single account needs no choice.
+// Test Case ID: LGN-08
+func TestAuth_Login_AccountChooser(t *testing.T) {
+	ctx := context.Background()
+	db := newClientTestStore(t)
+	tenants := memory.NewTenantRepository(db)
+	for _, tn := range []*tenant.Tenant{{ID: "t1", Name: "Acme", Status: tenant.StatusActive}, {ID: "t2", Name: "Globex", Status: tenant.StatusActive}} {
+		if err := tenants.Create(ctx, tn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	identitySvc := identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 10, time.Hour)
+	accounts := make(map[string]string)
+	for _, a := range []struct{ tenantID, email, password string }{
+		{"t1", "shared@example.com", "SecurePassword123"},
+		{"t2", "shared@example.com", "SecurePassword123"},
+		{"t3", "shared@example.com", "OtherPassword456"},
+		{"t1", "single@example.com", "SecurePassword123"},
+	} {
+		user, err := identitySvc.ProvisionIdentity(ctx, a.tenantID, a.email, identity.Profile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := identitySvc.AddPassword(ctx, user.ID, a.password); err != nil {
+			t.Fatal(err)
+		}
+		accounts[a.tenantID+"/"+a.email] = user.ID
+	}
+	h := &Handler{
+		identityService: identitySvc,
+		tenantService:   tenant.NewService(tenants, nil, nil, audit.NewSlogLogger()),
+		authzService:    authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+		auditLogger:     audit.NewSlogLogger(),
+	}
+	login := func(req LoginRequest) (int, AccountChoiceResponse) {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		h.Login(w, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(string(body))))
+		var resp AccountChoiceResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp
+	}
+
+	code, choice := login(LoginRequest{Email: "shared@example.com", Password: "SecurePassword123"})
+	if code != http.StatusConflict || len(choice.Accounts) != 2 {
+		t.Fatalf("expected a choice between the two matching accounts, got %d %+v", code, choice)
+	}
+	for _, a := range choice.Accounts {
+		if a.AccountID != accounts[a.TenantID+"/shared@example.com"] || (a.TenantID == "t1") != (a.TenantName == "Acme") {
+			t.Errorf("unexpected account %+v", a)
+		}
+	}
+
+	// A chosen account reaches the role check; these members are then refused for lacking an admin role
+	if code, _ := login(LoginRequest{Email: "shared@example.com", Password: "SecurePassword123", AccountID: accounts["t2/shared@example.com"]}); code != http.StatusForbidden {
+		t.Errorf("expected the chosen account to authenticate, got %d", code)
+	}
+	if code, _ := login(LoginRequest{Email: "shared@example.com", Password: "OtherPassword456"}); code != http.StatusForbidden {
+		t.Errorf("expected a password matching one account to need no choice, got %d", code)
+	}
+	if code, _ := login(LoginRequest{Email: "shared@example.com", Password: "SecurePassword123", AccountID: accounts["t3/shared@example.com"]}); code != http.StatusUnauthorized {
+		t.Errorf("expected another account's password to be refused, got %d", code)
+	}
+	if code, _ := login(LoginRequest{Email: "shared@example.com", Password: "SecurePassword123", AccountID: accounts["t1/single@example.com"]}); code != http.StatusUnauthorized {
+		t.Errorf("expected an account of another email to be refused, got %d", code)
+	}
+	if code, choice := login(LoginRequest{Email: "shared@example.com", Password: "WrongPassword"}); code != http.StatusUnauthorized || choice.Accounts != nil {
+		t.Errorf("expected a wrong password to list no accounts, got %d %+v", code, choice)
+	}
+	if code, _ := login(LoginRequest{Email: "single@example.com", Password: "SecurePassword123"}); code != http.StatusForbidden {
+		t.Errorf("expected a single account to need no choice, got %d", code)
+	}
+}
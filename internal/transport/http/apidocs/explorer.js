@@ -0,0 +1,147 @@
+// Copyright 2026 The OpenTrusty Authors. Licensed under the Apache License, Version 2.0.
+
+// API explorer for the OpenAPI document served at /openapi.json. It has no
+// dependencies so it can be embedded in the server binary.
+"use strict";
+
+const mutating = ["post", "put", "patch", "delete"];
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    if (k === "class") node.className = v;
+    else node.setAttribute(k, v);
+  }
+  for (const c of children) {
+    if (c !== null && c !== undefined) node.append(c);
+  }
+  return node;
+}
+
+// resolve follows a local $ref such as #/components/schemas/http.WebhookRequest.
+function resolve(doc, schema) {
+  if (!schema || !schema.$ref) return schema || {};
+  return schema.$ref.slice(2).split("/").reduce((node, key) => node && node[key], doc) || {};
+}
+
+// sample builds an example value for a schema, used to prefill request bodies.
+function sample(doc, schema, depth = 0) {
+  schema = resolve(doc, schema);
+  if (schema.example !== undefined) return schema.example;
+  if (depth > 4) return null;
+  switch (schema.type) {
+    case "object": {
+      const out = {};
+      for (const [name, prop] of Object.entries(schema.properties || {})) out[name] = sample(doc, prop, depth + 1);
+      return out;
+    }
+    case "array": return [sample(doc, schema.items, depth + 1)];
+    case "integer": case "number": return 0;
+    case "boolean": return false;
+    case "string": return schema.format === "date-time" ? new Date(0).toISOString() : "";
+    default: return null;
+  }
+}
+
+function describe(doc, schema) {
+  return JSON.stringify(sample(doc, schema), null, 2);
+}
+
+function operationView(doc, path, method, op) {
+  const body = el("div", { class: "operation" });
+  if (op.description) body.append(el("p", {}, op.description));
+
+  const inputs = [];
+  if (op.parameters && op.parameters.length) {
+    const table = el("table", {}, el("tr", {}, el("th", {}, "Parameter"), el("th", {}, "In"), el("th", {}, "Description"), el("th", {}, "Value")));
+    for (const p of op.parameters) {
+      const input = el("input", { placeholder: p.required ? "required" : "" });
+      if (p.schema && p.schema.example !== undefined) input.value = p.schema.example;
+      inputs.push([p, input]);
+      table.append(el("tr", {}, el("td", {}, p.name), el("td", {}, p.in), el("td", {}, p.description || ""), el("td", {}, input)));
+    }
+    body.append(table);
+  }
+
+  let bodyInput = null, bodyType = null;
+  if (op.requestBody) {
+    bodyType = Object.keys(op.requestBody.content)[0];
+    const schema = op.requestBody.content[bodyType].schema;
+    bodyInput = el("textarea", {});
+    bodyInput.value = bodyType === "application/json"
+      ? describe(doc, schema)
+      : Object.keys(resolve(doc, schema).properties || {}).map((k) => k + "=").join("&");
+    body.append(el("h4", {}, "Request body (" + bodyType + ")"), bodyInput);
+  }
+
+  const responses = el("table", {}, el("tr", {}, el("th", {}, "Status"), el("th", {}, "Description"), el("th", {}, "Example")));
+  for (const [status, r] of Object.entries(op.responses || {})) {
+    const media = r.content && Object.values(r.content)[0];
+    responses.append(el("tr", {}, el("td", {}, status), el("td", {}, r.description), el("td", {}, media ? el("pre", {}, describe(doc, media.schema)) : "")));
+  }
+  body.append(el("h4", {}, "Responses"), responses);
+
+  const output = el("pre", { hidden: "" });
+  const send = el("button", { type: "button" }, "Send request");
+  send.addEventListener("click", async () => {
+    let url = path;
+    const query = new URLSearchParams();
+    const headers = {};
+    for (const [p, input] of inputs) {
+      if (input.value === "") continue;
+      if (p.in === "path") url = url.replace("{" + p.name + "}", encodeURIComponent(input.value));
+      else if (p.in === "query") query.append(p.name, input.value);
+      else if (p.in === "header") headers[p.name] = input.value;
+    }
+    if (query.toString()) url += "?" + query;
+    // The admin plane requires a custom header on state-changing requests.
+    if (mutating.includes(method)) headers["X-CSRF-Token"] = "api-explorer";
+    if (bodyInput) headers["Content-Type"] = bodyType;
+
+    output.hidden = false;
+    output.textContent = "…";
+    try {
+      const res = await fetch(url, { method: method.toUpperCase(), headers, body: bodyInput ? bodyInput.value : undefined, credentials: "same-origin", redirect: "manual" });
+      const text = await res.text();
+      let pretty = text;
+      try { pretty = JSON.stringify(JSON.parse(text), null, 2); } catch (_) { /* not JSON */ }
+      output.textContent = res.type === "opaqueredirect" ? "Redirect" : res.status + " " + res.statusText + "\n\n" + pretty;
+    } catch (err) {
+      output.textContent = String(err);
+    }
+  });
+  body.append(send, output);
+
+  return el("details", {},
+    el("summary", {}, el("span", { class: "method " + method }, method), path, el("span", { class: "text" }, op.summary || "")),
+    body);
+}
+
+async function main() {
+  const container = document.getElementById("operations");
+  let doc;
+  try {
+    const res = await fetch("/openapi.json", { credentials: "same-origin" });
+    doc = await res.json();
+  } catch (err) {
+    container.textContent = "Failed to load /openapi.json: " + err;
+    return;
+  }
+  document.getElementById("title").textContent = doc.info.title;
+  document.getElementById("version").textContent = "Version " + doc.info.version + " · OpenAPI " + doc.openapi;
+
+  const byTag = new Map();
+  for (const [path, item] of Object.entries(doc.paths).sort(([a], [b]) => a.localeCompare(b))) {
+    for (const [method, op] of Object.entries(item)) {
+      const tag = (op.tags && op.tags[0]) || "Other";
+      if (!byTag.has(tag)) byTag.set(tag, []);
+      byTag.get(tag).push(operationView(doc, path, method, op));
+    }
+  }
+  container.replaceChildren();
+  for (const tag of [...byTag.keys()].sort()) {
+    container.append(el("h2", {}, tag), ...byTag.get(tag));
+  }
+}
+
+main();
@@ -0,0 +1,169 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// auditedRoutes lists the audit events recorded by every mutating route.
+// A new POST, PUT, PATCH or DELETE route must be added here together with its audit event.
+var auditedRoutes = map[string][]string{
+	"POST /api/v1/auth/login":                                                                {audit.TypeLoginSuccess, audit.TypeLoginFailed, audit.TypeSessionRevoked, audit.TypePasswordChanged},
+	"POST /api/v1/auth/logout":                                                               {audit.TypeLogout},
+	"POST /api/v1/auth/refresh-session":                                                      {audit.TypeSessionRenewalUnusual},
+	"POST /api/v1/user/change-password":                                                      {audit.TypePasswordChanged},
+	"POST /api/v1/user/email-change/":                                                        {audit.TypeEmailChangeRequested},
+	"DELETE /api/v1/user/email-change/":                                                      {audit.TypeEmailChangeCancelled},
+	"POST /api/v1/user/account-deletion/":                                                    {audit.TypeAccountDeletionRequested, audit.TypeAccountErased},
+	"DELETE /api/v1/user/account-deletion/":                                                  {audit.TypeAccountDeletionCancelled},
+	"POST /api/v1/email-change/verify":                                                       {audit.TypeEmailChangeVerified, audit.TypeEmailChanged},
+	"POST /api/v1/email-change/revert":                                                       {audit.TypeEmailChangeReverted},
+	"PUT /api/v1/user/profile":                                                               {audit.TypeProfileUpdated},
+	"POST /api/v1/tenants/":                                                                  {audit.TypeTenantCreated},
+	"POST /api/v1/tenants/{tenantID}/users/{userID}/roles/":                                  {audit.TypeRoleAssigned},
+	"DELETE /api/v1/tenants/{tenantID}/users/{userID}/roles/{role}":                          {audit.TypeRoleRevoked},
+	"POST /api/v1/tenants/{tenantID}/members/":                                               {audit.TypeMemberAdded},
+	"DELETE /api/v1/tenants/{tenantID}/members/{userID}":                                     {audit.TypeMemberRemoved, audit.TypeRoleRevoked},
+	"DELETE /api/v1/tenants/{tenantID}/users/{userID}/lockout":                               {audit.TypeUserUnlocked},
+	"POST /api/v1/tenants/{tenantID}/users/{userID}/password":                                {audit.TypePasswordReset},
+	"POST /api/v1/tenants/{tenantID}/users/{userID}/password/expire":                         {audit.TypePasswordExpired},
+	"POST /api/v1/tenants/{tenantID}/force-logout":                                           {audit.TypeSessionsRevoked, audit.TypeTokensRevoked},
+	"POST /api/v1/tenants/{tenantID}/clients/":                                               {audit.TypeClientCreated},
+	"DELETE /api/v1/tenants/{tenantID}/clients/{clientID}/":                                  {audit.TypeClientDeleted},
+	"POST /api/v1/tenants/{tenantID}/clients/{clientID}/secret":                              {audit.TypeSecretRotated},
+	"PUT /api/v1/tenants/{tenantID}/clients/{clientID}/jwks":                                 {audit.TypeClientKeysUpdated},
+	"POST /api/v1/tenants/{tenantID}/clients/{clientID}/force-logout":                        {audit.TypeSessionsRevoked, audit.TypeTokensRevoked},
+	"PUT /api/v1/tenants/{tenantID}/email-settings/":                                         {audit.TypeEmailSettingsUpdated},
+	"DELETE /api/v1/tenants/{tenantID}/email-settings/":                                      {audit.TypeEmailSettingsDeleted},
+	"POST /api/v1/tenants/{tenantID}/email-settings/test":                                    {audit.TypeEmailTestSent},
+	"PUT /api/v1/tenants/{tenantID}/audit-retention/":                                        {audit.TypeAuditRetentionUpdated},
+	"DELETE /api/v1/tenants/{tenantID}/audit-retention/":                                     {audit.TypeAuditRetentionDeleted},
+	"PUT /api/v1/tenants/{tenantID}/protocol-logging/":                                       {audit.TypeProtocolLogUpdated},
+	"PUT /api/v1/tenants/{tenantID}/security-policy/":                                        {audit.TypeSecurityPolicyUpdated},
+	"DELETE /api/v1/tenants/{tenantID}/security-policy/":                                     {audit.TypeSecurityPolicyDeleted},
+	"POST /api/v1/tenants/{tenantID}/scopes/":                                                {audit.TypeScopeCreated},
+	"PUT /api/v1/tenants/{tenantID}/scopes/{scopeName}/":                                     {audit.TypeScopeUpdated},
+	"DELETE /api/v1/tenants/{tenantID}/scopes/{scopeName}/":                                  {audit.TypeScopeDeleted},
+	"POST /api/v1/tenants/{tenantID}/projects/":                                              {audit.TypeProjectCreated},
+	"PUT /api/v1/tenants/{tenantID}/projects/{projectID}/":                                   {audit.TypeProjectUpdated},
+	"DELETE /api/v1/tenants/{tenantID}/projects/{projectID}/":                                {audit.TypeProjectDeleted},
+	"PUT /api/v1/tenants/{tenantID}/projects/{projectID}/members/{userID}":                   {audit.TypeRoleAssigned},
+	"DELETE /api/v1/tenants/{tenantID}/projects/{projectID}/members/{userID}":                {audit.TypeRoleRevoked},
+	"POST /api/v1/tenants/{tenantID}/webhooks/":                                              {audit.TypeWebhookCreated},
+	"PUT /api/v1/tenants/{tenantID}/webhooks/{webhookID}/":                                   {audit.TypeWebhookUpdated},
+	"DELETE /api/v1/tenants/{tenantID}/webhooks/{webhookID}/":                                {audit.TypeWebhookDeleted},
+	"POST /api/v1/tenants/{tenantID}/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver": {audit.TypeWebhookRedelivered},
+	"POST /oauth2/token":                                                                     {audit.TypeTokenIssued},
+	"POST /oauth2/revoke":                                                                    {audit.TypeTokenRevoked},
+}
+
+// unauditedRoutes lists mutating routes that change nothing, with the reason
+var unauditedRoutes = map[string]string{
+	"POST /api/v1/auth/register": "anonymous registration is disabled and always rejected",
+	"POST /oauth2/introspect":    "read-only token lookup; POST only because RFC 7662 requires it",
+}
+
+// TestAuditCoverage walks the router and fails for any mutating route without a declared audit event
+func TestAuditCoverage(t *testing.T) {
+	r := NewRouter(&Handler{}, nil, nil, "all")
+
+	seen := map[string]bool{}
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+			return nil
+		}
+		key := method + " " + route
+		seen[key] = true
+		if _, ok := unauditedRoutes[key]; ok {
+			return nil
+		}
+		types, ok := auditedRoutes[key]
+		if !ok {
+			t.Errorf("mutating route %s has no audit event; audit it and list it in auditedRoutes", key)
+			return nil
+		}
+		for _, typ := range types {
+			if err := audit.ValidateEvent(audit.Event{Type: typ}); err == audit.ErrUnknownEventType {
+				t.Errorf("route %s declares unknown audit event type %q", key, typ)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key := range auditedRoutes {
+		if !seen[key] {
+			t.Errorf("auditedRoutes lists %s, which is not routed", key)
+		}
+	}
+}
+
+// TestAuditContextMiddleware tests that events logged by services carry the actor and the request details
+func TestAuditContextMiddleware(t *testing.T) {
+	db := newClientTestStore(t)
+	ctx := context.Background()
+	tenantRoles := memory.NewTenantRoleRepository(db)
+	if err := tenantRoles.AssignRole(ctx, &tenant.TenantUserRole{ID: "u2-member-t1", TenantID: "t1", UserID: "u2", Role: tenant.RoleTenantMember}); err != nil {
+		t.Fatal(err)
+	}
+	events := memory.NewAuditRepository(db)
+	assignments := memory.NewAssignmentRepository(db)
+	h := &Handler{
+		authzService:  authz.NewService(nil, memory.NewRoleRepository(db), assignments),
+		tenantService: tenant.NewService(memory.NewTenantRepository(db), tenantRoles, assignments, audit.NewStoreLogger(events)),
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID, AuditContextMiddleware)
+	r.Delete("/tenants/{tenantID}/users/{userID}/roles/{role}", h.RevokeTenantRole)
+
+	req := httptest.NewRequest("DELETE", "/tenants/t1/users/u2/roles/"+tenant.RoleTenantMember, nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set("User-Agent", "console/1.0")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), userIDKey, "u1")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+
+	stored, err := events.List(ctx, audit.Filter{TenantID: "t1", Type: audit.TypeRoleRevoked})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("expected one role_revoked event, got %d", len(stored))
+	}
+	e := stored[0]
+	if e.ActorID != "u1" || e.RequestID != "req-1" || e.IPAddress != "203.0.113.7" || e.UserAgent != "console/1.0" {
+		t.Errorf("expected actor and request details on the event, got %+v", e)
+	}
+	if e.SchemaVersion != audit.SchemaVersion {
+		t.Errorf("expected a versioned event, got schema version %d", e.SchemaVersion)
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// AuditEventResponse represents a stored audit event
+type AuditEventResponse struct {
+	ID            string         `json:"id"`
+	SchemaVersion int            `json:"schema_version" example:"1"` // 0 marks legacy free-form metadata
+	Type          string         `json:"type" example:"client_created"`
+	TenantID      string         `json:"tenant_id"`
+	ActorID       string         `json:"actor_id"`
+	Resource      string         `json:"resource" example:"client"`
+	RequestID     string         `json:"request_id,omitempty"` // X-Request-Id of the request that caused the event
+	IPAddress     string         `json:"ip_address,omitempty"`
+	UserAgent     string         `json:"user_agent,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+	Timestamp     time.Time      `json:"timestamp"`
+}
+
+// ListAuditEventsResponse is a page of audit events
+type ListAuditEventsResponse struct {
+	Events     []AuditEventResponse `json:"events"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// AuditRetentionRequest sets a tenant's audit retention
+type AuditRetentionRequest struct {
+	RetentionDays int `json:"retention_days" example:"2555"`
+}
+
+// AuditRetentionResponse represents the audit retention that applies to a tenant
+type AuditRetentionResponse struct {
+	TenantID      string     `json:"tenant_id"`
+	RetentionDays int        `json:"retention_days" example:"90"` // Zero keeps events forever
+	Inherited     bool       `json:"inherited"`                   // The platform default applies
+	UpdatedBy     string     `json:"updated_by,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+func newAuditRetentionResponse(p *audit.RetentionPolicy) AuditRetentionResponse {
+	resp := AuditRetentionResponse{
+		TenantID:      p.TenantID,
+		RetentionDays: p.Days,
+		Inherited:     p.Inherited,
+		UpdatedBy:     p.UpdatedBy,
+	}
+	if !p.Inherited {
+		resp.UpdatedAt = &p.UpdatedAt
+	}
+	return resp
+}
+
+// ListAuditEvents handles querying a tenant's audit trail
+// @Summary List Audit Events
+// @Description List a tenant's audit events oldest first with filters and cursor pagination
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param type query string false "Event type, e.g. login_failed"
+// @Param actor_id query string false "ID of the user who performed the action"
+// @Param since query string false "Earliest event time (RFC 3339, inclusive)"
+// @Param until query string false "Latest event time (RFC 3339, exclusive)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Opaque cursor from a previous page's next_cursor"
+// @Success 200 {object} ListAuditEventsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/audit-events [get]
+func (h *Handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Audit view permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantViewAudit)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "audit access required")
+		return
+	}
+
+	// 2. Parse filters and pagination
+	q := r.URL.Query()
+	filter := audit.Filter{
+		TenantID: tenantID,
+		Type:     q.Get("type"),
+		ActorID:  q.Get("actor_id"),
+	}
+	if filter.Since, err = parseTimeParam(q.Get("since")); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid since: expected RFC 3339 time")
+		return
+	}
+	if filter.Until, err = parseTimeParam(q.Get("until")); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid until: expected RFC 3339 time")
+		return
+	}
+	if filter.Page, err = pagination.Parse(q.Get("limit"), q.Get("cursor")); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, next, err := h.auditService.ListEvents(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list audit events")
+		return
+	}
+
+	resp := ListAuditEventsResponse{
+		Events:     make([]AuditEventResponse, 0, len(events)),
+		NextCursor: next,
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, AuditEventResponse{
+			ID:            e.ID,
+			SchemaVersion: e.SchemaVersion,
+			Type:          e.Type,
+			TenantID:      e.TenantID,
+			ActorID:       e.ActorID,
+			Resource:      e.Resource,
+			RequestID:     e.RequestID,
+			IPAddress:     e.IPAddress,
+			UserAgent:     e.UserAgent,
+			Metadata:      e.Metadata,
+			Timestamp:     e.Timestamp,
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// parseTimeParam parses an optional RFC 3339 query parameter
+func parseTimeParam(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// GetAuditRetention handles retrieving the audit retention that applies to a tenant
+// @Summary Get Audit Retention
+// @Description Get how long the tenant's audit events are kept before they are purged
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 200 {object} AuditRetentionResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/audit-retention [get]
+func (h *Handler) GetAuditRetention(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Audit view permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantViewAudit)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "audit access required")
+		return
+	}
+
+	policy, err := h.auditService.GetRetention(r.Context(), tenantID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get audit retention")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newAuditRetentionResponse(policy))
+}
+
+// UpdateAuditRetention handles setting a tenant's audit retention
+// @Summary Update Audit Retention
+// @Description Override the platform audit retention for a tenant, e.g. seven years for a regulated tenant. Zero keeps events forever. Platform administrators only.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body AuditRetentionRequest true "Retention"
+// @Success 200 {object} AuditRetentionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/audit-retention [put]
+func (h *Handler) UpdateAuditRetention(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Retention is a platform compliance decision, not a tenant setting
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopePlatform, nil, authz.PermPlatformManageTenants)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "platform admin access required")
+		return
+	}
+
+	var req AuditRetentionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if _, err := h.tenantService.GetTenant(r.Context(), tenantID); err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			respondError(w, http.StatusNotFound, "tenant not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get tenant")
+		return
+	}
+
+	policy, err := h.auditService.SetRetention(r.Context(), tenantID, userID, req.RetentionDays)
+	if err != nil {
+		if errors.Is(err, audit.ErrInvalidRetention) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to update audit retention")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newAuditRetentionResponse(policy))
+}
+
+// DeleteAuditRetention handles removing a tenant's audit retention override
+// @Summary Delete Audit Retention
+// @Description Remove the tenant's retention override so the platform default applies again. Platform administrators only.
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/audit-retention [delete]
+func (h *Handler) DeleteAuditRetention(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Retention is a platform compliance decision, not a tenant setting
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopePlatform, nil, authz.PermPlatformManageTenants)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "platform admin access required")
+		return
+	}
+
+	if err := h.auditService.DeleteRetention(r.Context(), tenantID, userID); err != nil {
+		if errors.Is(err, audit.ErrRetentionNotFound) {
+			respondError(w, http.StatusNotFound, "audit retention not configured")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete audit retention")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
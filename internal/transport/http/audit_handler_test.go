@@ -0,0 +1,145 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+func listAuditEvents(h *Handler, userID, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	ctx := context.WithValue(req.Context(), tenantIDKey, "t1")
+	ctx = context.WithValue(ctx, userIDKey, userID)
+	w := httptest.NewRecorder()
+	h.ListAuditEvents(w, req.WithContext(ctx))
+	return w
+}
+
+// TestListAuditEvents tests that the audit query is tenant-scoped, filterable and permission-checked
+func TestListAuditEvents(t *testing.T) {
+	db := newClientTestStore(t)
+	repo := memory.NewAuditRepository(db)
+	logger := audit.NewStoreLogger(repo)
+	ctx := context.Background()
+	logger.Log(ctx, audit.Event{Type: audit.TypeClientCreated, TenantID: "t1", ActorID: "u1", Resource: audit.ResourceClient,
+		Payload: &audit.ClientPayload{ClientID: "c1"}})
+	logger.Log(ctx, audit.Event{Type: audit.TypeLoginFailed, TenantID: "t1", Resource: audit.ResourceUser,
+		Metadata: map[string]any{audit.AttrEmail: "a@example.com", "password": "hunter2"}})
+	logger.Log(ctx, audit.Event{Type: audit.TypeLoginFailed, TenantID: "t2", Resource: audit.ResourceUser,
+		Payload: &audit.LoginFailedPayload{Reason: "invalid_credentials"}})
+
+	h := &Handler{
+		auditService: audit.NewService(repo, memory.NewAuditRetentionRepository(db), logger, 90),
+		authzService: authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+	}
+
+	w := listAuditEvents(h, "u1", "/tenants/t1/audit-events?type=login_failed")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	var resp ListAuditEventsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != 1 || resp.Events[0].TenantID != "t1" {
+		t.Fatalf("expected only t1's login_failed event, got %+v", resp.Events)
+	}
+	if resp.Events[0].Metadata["password"] != "[REDACTED]" {
+		t.Errorf("expected stored secrets to be redacted, got %v", resp.Events[0].Metadata["password"])
+	}
+
+	if w := listAuditEvents(h, "u1", "/tenants/t1/audit-events?since=yesterday"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid since, got %d", w.Code)
+	}
+
+	if w := listAuditEvents(h, "u2", "/tenants/t1/audit-events"); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for user without audit access, got %d", w.Code)
+	}
+}
+
+func auditRetentionRequest(h *Handler, handle http.HandlerFunc, method, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/tenants/t1/audit-retention", strings.NewReader(body))
+	ctx := context.WithValue(req.Context(), tenantIDKey, "t1")
+	ctx = context.WithValue(ctx, userIDKey, userID)
+	w := httptest.NewRecorder()
+	handle(w, req.WithContext(ctx))
+	return w
+}
+
+// TestAuditRetention tests that tenant admins can read but only platform admins can change audit retention
+func TestAuditRetention(t *testing.T) {
+	db := newClientTestStore(t)
+	ctx := context.Background()
+	if err := memory.NewTenantRepository(db).Create(ctx, &tenant.Tenant{ID: "t1", Name: "Acme", Status: tenant.StatusActive}); err != nil {
+		t.Fatal(err)
+	}
+	assignments := memory.NewAssignmentRepository(db)
+	if err := assignments.Grant(ctx, &authz.Assignment{ID: "p1", UserID: "p1", RoleID: rbac.RoleIDPlatformAdmin, Scope: authz.ScopePlatform}); err != nil {
+		t.Fatal(err)
+	}
+	events := memory.NewAuditRepository(db)
+	h := &Handler{
+		auditService:  audit.NewService(events, memory.NewAuditRetentionRepository(db), audit.NewStoreLogger(events), 90),
+		authzService:  authz.NewService(nil, memory.NewRoleRepository(db), assignments),
+		tenantService: tenant.NewService(memory.NewTenantRepository(db), nil, assignments, audit.NewSlogLogger()),
+	}
+
+	w := auditRetentionRequest(h, h.GetAuditRetention, "GET", "u1", "")
+	var resp AuditRetentionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	if !resp.Inherited || resp.RetentionDays != 90 {
+		t.Errorf("expected inherited default retention, got %+v", resp)
+	}
+
+	if w := auditRetentionRequest(h, h.UpdateAuditRetention, "PUT", "u1", `{"retention_days": 1}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for tenant admin, got %d", w.Code)
+	}
+	if w := auditRetentionRequest(h, h.UpdateAuditRetention, "PUT", "p1", `{"retention_days": -1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for negative retention, got %d", w.Code)
+	}
+
+	w = auditRetentionRequest(h, h.UpdateAuditRetention, "PUT", "p1", `{"retention_days": 2555}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	w = auditRetentionRequest(h, h.GetAuditRetention, "GET", "u1", "")
+	resp = AuditRetentionResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Inherited || resp.RetentionDays != 2555 || resp.UpdatedBy != "p1" {
+		t.Errorf("expected tenant override, got %+v", resp)
+	}
+
+	if w := auditRetentionRequest(h, h.DeleteAuditRetention, "DELETE", "p1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w := auditRetentionRequest(h, h.DeleteAuditRetention, "DELETE", "p1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without an override, got %d", w.Code)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/captcha"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// ChallengeResponse is the body of a 401. When the captcha fields are set, the client must
+// render the provider's widget with the site key and repeat the request with captcha_response.
+type ChallengeResponse struct {
+	Error           string `json:"error" example:"challenge required"`
+	CaptchaProvider string `json:"captcha_provider,omitempty" example:"turnstile"`
+	CaptchaSiteKey  string `json:"captcha_site_key,omitempty"`
+}
+
+// checkChallenge returns true when the request may proceed, and otherwise answers it: with a
+// 401 asking for a challenge when none was solved, or a 503 when the provider is unreachable.
+// The tenant whose mode applies is the one of the user that login would authenticate; an
+// unknown email gets the default mode.
+func (h *Handler) checkChallenge(w http.ResponseWriter, r *http.Request, email, response string) bool {
+	c := h.challenge
+	if c == nil {
+		return true
+	}
+	remoteIP := getClientIP(r)
+	tenantID := ""
+	if c.PerTenant() {
+		if user, err := h.identityService.GetByEmail(r.Context(), "", email); err == nil && user.TenantID != nil {
+			tenantID = *user.TenantID
+		}
+	}
+	if !c.Required(tenantID, remoteIP) {
+		return true
+	}
+
+	challenge := ChallengeResponse{
+		Error:           "challenge required",
+		CaptchaProvider: c.Provider,
+		CaptchaSiteKey:  c.SiteKey,
+	}
+	if response == "" {
+		respondJSON(w, http.StatusUnauthorized, challenge)
+		return false
+	}
+	err := c.Verify(r.Context(), response, remoteIP)
+	switch {
+	case errors.Is(err, captcha.ErrChallengeFailed):
+		h.auditLogger.Log(r.Context(), audit.Event{
+			Type:     audit.TypeLoginFailed,
+			TenantID: tenantID,
+			Resource: email,
+			Payload:  &audit.LoginFailedPayload{Reason: "challenge_failed"},
+		})
+		challenge.Error = "challenge failed"
+		respondJSON(w, http.StatusUnauthorized, challenge)
+		return false
+	case err != nil:
+		slog.ErrorContext(r.Context(), "captcha verification unavailable", logger.Error(err))
+		respondError(w, http.StatusServiceUnavailable, "challenge verification unavailable")
+		return false
+	}
+	return true
+}
+
+// recordLoginFailure counts a failed login against the client address, flagging it for abuse
+// at the threshold
+func (h *Handler) recordLoginFailure(r *http.Request) {
+	if h.challenge != nil {
+		h.challenge.Flags.Record(getClientIP(r))
+	}
+}
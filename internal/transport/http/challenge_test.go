@@ -0,0 +1,118 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/captcha"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// stubVerifier accepts "solved", rejects other responses, and fails when down
+type stubVerifier struct {
+	down bool
+}
+
+func (v *stubVerifier) Verify(ctx context.Context, response, remoteIP string) error {
+	switch {
+	case v.down:
+		return errors.New("connection refused")
+	case response != "solved":
+		return captcha.ErrChallengeFailed
+	}
+	return nil
+}
+
+// TestPurpose: Validates that login requires a solved challenge once the client address is flagged, or always when configured so.
+// Scope: Unit Test
+// Security: Bot mitigation (credential stuffing must solve a challenge per attempt once detected)
+// Expected: Failed logins flag the address; flagged attempts get a 401 with the provider and site key until a valid response is sent; an unreachable provider fails closed with 503.
+// Test Case ID: LGN-07
+func TestAuth_Login_Challenge(t *testing.T) {
+	ctx := context.Background()
+	db := newClientTestStore(t)
+	identitySvc := identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 10, time.Hour)
+	user, err := identitySvc.ProvisionIdentity(ctx, "", "member@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := identitySvc.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	verifier := &stubVerifier{}
+	h := &Handler{
+		identityService: identitySvc,
+		authzService:    authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+		auditLogger:     audit.NewSlogLogger(),
+		challenge: &captcha.Challenge{
+			Provider: captcha.ProviderTurnstile,
+			SiteKey:  "site-key",
+			Verifier: verifier,
+			Mode:     captcha.ModeAbuse,
+			Flags:    captcha.NewFlags(2, time.Hour),
+		},
+	}
+	login := func(remoteAddr, email, password, response string) (int, ChallengeResponse) {
+		body, _ := json.Marshal(LoginRequest{Email: email, Password: password, CaptchaResponse: response})
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(string(body)))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		h.Login(w, req)
+		var resp ChallengeResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp
+	}
+
+	for range 2 {
+		if code, resp := login("203.0.113.7:4000", "member@example.com", "WrongPassword", ""); code != http.StatusUnauthorized || resp.CaptchaProvider != "" {
+			t.Fatalf("expected plain invalid credentials before the address is flagged, got %d %+v", code, resp)
+		}
+	}
+	code, resp := login("203.0.113.7:4000", "member@example.com", "SecurePassword123", "")
+	if code != http.StatusUnauthorized || resp.CaptchaProvider != captcha.ProviderTurnstile || resp.CaptchaSiteKey != "site-key" {
+		t.Fatalf("expected a challenge for the flagged address, got %d %+v", code, resp)
+	}
+	if code, resp := login("203.0.113.7:4000", "member@example.com", "SecurePassword123", "forged"); code != http.StatusUnauthorized || resp.Error != "challenge failed" {
+		t.Errorf("expected a forged response to fail, got %d %+v", code, resp)
+	}
+	// A solved challenge reaches the password check; the member is then refused for lacking an admin role
+	if code, _ := login("203.0.113.7:4000", "member@example.com", "SecurePassword123", "solved"); code != http.StatusForbidden {
+		t.Errorf("expected the solved challenge to pass, got %d", code)
+	}
+
+	if code, resp := login("198.51.100.1:4000", "member@example.com", "SecurePassword123", ""); code != http.StatusForbidden {
+		t.Errorf("expected other addresses to be unaffected, got %d %+v", code, resp)
+	}
+
+	h.challenge.Mode = captcha.ModeAlways
+	if code, resp := login("198.51.100.1:4000", "member@example.com", "SecurePassword123", ""); code != http.StatusUnauthorized || resp.CaptchaProvider == "" {
+		t.Errorf("expected the always mode to require a challenge from any address, got %d %+v", code, resp)
+	}
+	verifier.down = true
+	if code, _ := login("198.51.100.1:4000", "member@example.com", "SecurePassword123", "solved"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 when the provider is unreachable, got %d", code)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// RateLimitLayerClientAuth reports requests refused while their client or address is banned
+const RateLimitLayerClientAuth = "client_auth"
+
+// ClientAuthBackoff configures temporary bans after failed client authentications on the token,
+// revocation and introspection endpoints; a zero MaxFailures disables them
+type ClientAuthBackoff struct {
+	MaxFailures int           // Failures of a client_id from an address, or of an address, tolerated before a ban
+	Backoff     time.Duration // First ban; every further failure doubles it
+	MaxBackoff  time.Duration // Longest ban; failures are forgotten after this long without another
+}
+
+// authFailures counts failed client authentications per key and bans keys with exponential
+// backoff. Like the rate limiter buckets, the counts are kept in memory per instance.
+type authFailures struct {
+	cfg     ClientAuthBackoff
+	mu      sync.Mutex
+	entries map[string]*authFailure
+	pruned  time.Time
+	now     func() time.Time
+}
+
+type authFailure struct {
+	count       int
+	last        time.Time
+	bannedUntil time.Time
+}
+
+func newAuthFailures(cfg ClientAuthBackoff) *authFailures {
+	if cfg.MaxFailures <= 0 {
+		return nil
+	}
+	cfg.Backoff = max(cfg.Backoff, time.Second)
+	cfg.MaxBackoff = max(cfg.MaxBackoff, cfg.Backoff)
+	return &authFailures{cfg: cfg, entries: make(map[string]*authFailure), now: time.Now}
+}
+
+// banned returns how long key remains banned, or zero
+func (f *authFailures) banned(key string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if e, ok := f.entries[key]; ok {
+		return max(e.bannedUntil.Sub(f.now()), 0)
+	}
+	return 0
+}
+
+// fail counts a failure of key and returns the total and the ban it starts, if any
+func (f *authFailures) fail(key string) (int, time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.prune(now)
+	e, ok := f.entries[key]
+	if !ok {
+		e = &authFailure{}
+		f.entries[key] = e
+	}
+	e.count++
+	e.last = now
+	if e.count <= f.cfg.MaxFailures {
+		return e.count, 0
+	}
+	ban := f.cfg.MaxBackoff
+	if shift := e.count - f.cfg.MaxFailures - 1; shift < 32 {
+		ban = min(f.cfg.Backoff<<shift, f.cfg.MaxBackoff)
+	}
+	e.bannedUntil = now.Add(ban)
+	return e.count, ban
+}
+
+// reset forgets the failures of key
+func (f *authFailures) reset(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, key)
+}
+
+// prune forgets keys without a failure for MaxBackoff, at most once per MaxBackoff
+func (f *authFailures) prune(now time.Time) {
+	if now.Sub(f.pruned) < f.cfg.MaxBackoff {
+		return
+	}
+	for key, e := range f.entries {
+		if now.Sub(e.last) >= f.cfg.MaxBackoff && !now.Before(e.bannedUntil) {
+			delete(f.entries, key)
+		}
+	}
+	f.pruned = now
+}
+
+// ClientAuthBackoffMiddleware bans a client_id from a client address, and separately the address,
+// after repeated failed client authentications (responses with 401 invalid_client). Keying the
+// client_id by address keeps an attacker who knows a client_id from locking the client out
+// everywhere. A banned request gets 429 with Retry-After without reaching the endpoint. Each
+// failure is audited as client_auth_failed and each ban as client_auth_banned; a success clears
+// the failures of the client_id from the address but not the address's, which may be guessing the
+// secrets of several clients.
+func (h *Handler) ClientAuthBackoffMiddleware(l *RateLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if l == nil || l.clientAuth == nil {
+			return next
+		}
+		f := l.clientAuth
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientID := clientKey(r)
+			clientIP := getClientIP(r)
+			keys := []string{"ip:" + clientIP}
+			if clientID != "" {
+				keys = append(keys, clientAddrKey(clientID, clientIP))
+			}
+
+			var wait time.Duration
+			for _, key := range keys {
+				wait = max(wait, f.banned(key))
+			}
+			l.record(r, RateLimitLayerClientAuth, wait == 0)
+			if wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(wait)))
+				respondJSON(w, http.StatusTooManyRequests, oauth2.NewError(oauth2.ErrInvalidClient, "too many failed client authentications"))
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			switch status := ww.Status(); {
+			case status == http.StatusUnauthorized:
+				h.recordClientAuthFailure(r, f, clientID, clientIP)
+			case status < http.StatusBadRequest && clientID != "":
+				f.reset(clientAddrKey(clientID, clientIP))
+			}
+		})
+	}
+}
+
+// clientAddrKey is the failure key of a client_id presented from a client address
+func clientAddrKey(clientID, clientIP string) string {
+	return "client:" + clientID + "@" + clientIP
+}
+
+// recordClientAuthFailure counts a failed client authentication against the client_id from the
+// address and against the address, auditing the failure and any ban it starts
+func (h *Handler) recordClientAuthFailure(r *http.Request, f *authFailures, clientID, clientIP string) {
+	tenantID := h.clientTenantKey(r)
+	failures, ipBan := f.fail("ip:" + clientIP)
+	var clientBan time.Duration
+	if clientID != "" {
+		failures, clientBan = f.fail(clientAddrKey(clientID, clientIP))
+	}
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     audit.TypeClientAuthFailed,
+		TenantID: tenantID,
+		Resource: audit.ResourceClient,
+		Payload:  &audit.ClientAuthFailedPayload{ClientID: clientID, ClientIP: clientIP, Failures: failures},
+	})
+
+	bans := []struct {
+		key string
+		ban time.Duration
+	}{{"client_id", clientBan}, {"client_ip", ipBan}}
+	for _, b := range bans {
+		if b.ban == 0 {
+			continue
+		}
+		slog.WarnContext(r.Context(), "client authentication banned after repeated failures",
+			"client_id", clientID,
+			"client_ip", clientIP,
+			"key", b.key,
+			"ban", b.ban,
+		)
+		h.auditLogger.Log(r.Context(), audit.Event{
+			Type:     audit.TypeClientAuthBanned,
+			TenantID: tenantID,
+			Resource: audit.ResourceClient,
+			Payload: &audit.ClientAuthBannedPayload{
+				ClientID:   clientID,
+				ClientIP:   clientIP,
+				Key:        b.key,
+				BanSeconds: ceilSeconds(b.ban),
+			},
+		})
+	}
+}
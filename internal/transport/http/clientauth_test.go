@@ -0,0 +1,106 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// TestClientAuthBackoff tests that failed client authentications ban the client_id from an address, and the address, with exponential backoff
+func TestClientAuthBackoff(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	h := &Handler{
+		oauth2Service: oauth2.NewService(memory.NewClientRepository(memory.New()), nil, nil, nil, audit.NewSlogLogger(), nil, 0, 0, 0),
+		auditLogger:   logger,
+	}
+	limits := &RateLimits{clientAuth: newAuthFailures(ClientAuthBackoff{MaxFailures: 2, Backoff: time.Minute, MaxBackoff: 3 * time.Minute})}
+	now := time.Now()
+	limits.clientAuth.now = func() time.Time { return now }
+
+	token := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Form.Get("client_secret") != "right" {
+			h.respondOAuthError(w, oauth2.NewError(oauth2.ErrInvalidClient, "invalid client credentials"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	endpoint := h.ClientAuthBackoffMiddleware(limits)(token)
+	send := func(clientID, secret, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader("client_id="+clientID+"&client_secret="+secret))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		endpoint.ServeHTTP(w, req)
+		return w
+	}
+
+	send("c1", "wrong", "192.0.2.1:1000")
+	send("c1", "right", "192.0.2.1:1000")
+	send("c1", "wrong", "192.0.2.1:1000")
+	if w := send("c1", "wrong", "192.0.2.1:1000"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected failures up to the limit to reach the endpoint, got %d", w.Code)
+	}
+	// A success cleared the client's failures from the address, not the address's: the address is banned first
+	w := send("c1", "right", "192.0.2.1:1000")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected the address to be banned for a minute, got %d Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// An attacker guessing the secret of c1 gets banned from their own address only
+	for range 3 {
+		if w := send("c1", "wrong", "192.0.2.2:1000"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected failures from another address to reach the endpoint, got %d", w.Code)
+		}
+	}
+	if w := send("c1", "right", "192.0.2.2:1000"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the client to be banned from the attacker's address after its third failure, got %d", w.Code)
+	}
+	if w := send("c1", "right", "192.0.2.3:1000"); w.Code != http.StatusOK {
+		t.Errorf("expected the client to authenticate from its own address, got %d", w.Code)
+	}
+
+	now = now.Add(time.Minute)
+	send("c1", "wrong", "192.0.2.2:1000")
+	if w := send("c2", "right", "192.0.2.4:1000"); w.Code != http.StatusOK {
+		t.Errorf("expected another client from a fresh address to pass, got %d", w.Code)
+	}
+	if w := send("c1", "right", "192.0.2.2:1000"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "120" {
+		t.Errorf("expected the ban to double, got %d Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := send("c1", "right", "192.0.2.3:1000"); w.Code != http.StatusOK {
+		t.Errorf("expected the client to still authenticate from its own address, got %d", w.Code)
+	}
+
+	var failed, banned int
+	for _, e := range logger.events {
+		switch e.Type {
+		case audit.TypeClientAuthFailed:
+			failed++
+		case audit.TypeClientAuthBanned:
+			banned++
+		}
+	}
+	if failed != 7 || banned != 5 {
+		t.Errorf("expected 7 failures and 5 bans (first address, then the attacker's address and client_id twice) to be audited, got %d and %d", failed, banned)
+	}
+}
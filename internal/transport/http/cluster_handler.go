@@ -0,0 +1,53 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/cluster"
+)
+
+// GetClusterStatus reports whether the deployment is ready to run several replicas, and the
+// signing key fingerprint of each replica
+// @Summary Get Cluster Status
+// @Description Lists the clustering readiness checks of this replica's configuration (signing keys, sessions, rate limits, replay store and event bus, each "pass" or "warn") and the replicas heard from on the event bus in the last three key reload intervals, with the kids they sign and publish.
+// @Description consistent is false when the replicas' key fingerprints differ, that is when they serve different JWKS documents. Right after a rotation this lasts until every replica has reloaded its keys.
+// @Tags System
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Success 200 {object} cluster.Report
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /system/cluster [get]
+func (h *Handler) GetClusterStatus(w http.ResponseWriter, r *http.Request) {
+	// 1. Authorization Check: Platform Admin required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopePlatform, nil, authz.PermPlatformManageTenants)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "platform admin administrative access required")
+		return
+	}
+
+	if h.cluster == nil {
+		respondError(w, http.StatusNotFound, "cluster status is not available")
+		return
+	}
+	var report cluster.Report = h.cluster.Report(time.Now())
+	respondJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/bus"
+	"github.com/opentrusty/opentrusty/internal/cluster"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// TestClusterStatus tests that only platform admins see the readiness checks and replica key fingerprints
+func TestClusterStatus(t *testing.T) {
+	db := newClientTestStore(t)
+	assignments := memory.NewAssignmentRepository(db)
+	if err := assignments.Grant(context.Background(), &authz.Assignment{ID: "p1", UserID: "p1", RoleID: rbac.RoleIDPlatformAdmin, Scope: authz.ScopePlatform}); err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{authzService: authz.NewService(nil, memory.NewRoleRepository(db), assignments)}
+
+	if w := auditRetentionRequest(h, h.GetClusterStatus, "GET", "p1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a registry, got %d", w.Code)
+	}
+
+	h.cluster = cluster.NewRegistry(bus.New(nil), time.Minute, cluster.Checklist(cluster.Setup{}), func() (string, []string) {
+		return "k1", []string{"k1"}
+	})
+	if w := auditRetentionRequest(h, h.GetClusterStatus, "GET", "u1", ""); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for tenant admin, got %d", w.Code)
+	}
+	w := auditRetentionRequest(h, h.GetClusterStatus, "GET", "p1", "")
+	var report cluster.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	if !report.Consistent || len(report.Replicas) != 1 || report.Replicas[0].SigningKeyID != "k1" || len(report.Checks) == 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
@@ -14,7 +14,11 @@
 
 package http
 
-import "context"
+import (
+	"context"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
 
 type contextKey string
 
@@ -22,6 +26,10 @@ const (
 	tenantIDKey  contextKey = "tenant_id"
 	userIDKey    contextKey = "user_id"
 	sessionIDKey contextKey = "session_id"
+	// passwordChangeKey marks a session restricted to changing the user's password
+	passwordChangeKey contextKey = "password_change_required"
+	// accessTokenKey holds the access token that authenticated an admin API request
+	accessTokenKey contextKey = "access_token"
 )
 
 // GetUserID retrieves the authenticated User ID from context.
@@ -47,3 +55,17 @@ func GetSessionID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// passwordChangeRequired reports whether the request's session can only change the user's password
+func passwordChangeRequired(ctx context.Context) bool {
+	val, _ := ctx.Value(passwordChangeKey).(bool)
+	return val
+}
+
+// getAccessToken retrieves the access token that authenticated an admin API request, or nil for a session.
+func getAccessToken(ctx context.Context) *oauth2.AccessToken {
+	if val, ok := ctx.Value(accessTokenKey).(*oauth2.AccessToken); ok {
+		return val
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// corsMaxAge is how long, in seconds, browsers may cache a token endpoint preflight
+const corsMaxAge = "600"
+
+// ClientCORSMiddleware lets browser-based clients, such as SPAs using the authorization code flow
+// with PKCE, call the token and revocation endpoints. A cross-origin request is answered with
+// Access-Control-Allow-Origin only when the origin is allowed for the requesting client, and is
+// refused when the client does not allow it. Credentials are never allowed: these endpoints take
+// no cookies. Requests without an Origin header, such as those of server-side clients, pass as is.
+func (h *Handler) ClientCORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		clientID := clientKey(r)
+		if clientID == "" || h.oauth2Service == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		client, err := h.oauth2Service.GetClientByClientID(r.Context(), clientID)
+		if err != nil {
+			// The handler reports the unknown client
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !client.AllowsOrigin(origin) {
+			slog.WarnContext(r.Context(), "token endpoint called from an origin the client does not allow",
+				"client_id", clientID, "origin", origin, "path", r.URL.Path)
+			h.respondOAuthError(w, oauth2.NewError(oauth2.ErrInvalidRequest, "origin not allowed for this client"))
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientCORSPreflight answers CORS preflights on the token and revocation endpoints. A preflight
+// carries no client_id, so any web origin is answered; ClientCORSMiddleware checks the origin
+// against the client on the request that follows.
+func (h *Handler) ClientCORSPreflight(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	w.Header().Add("Vary", "Origin")
+	if !oauth2.IsWebOrigin(origin) || r.Header.Get("Access-Control-Request-Method") != http.MethodPost {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", http.MethodPost)
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, DPoP")
+	w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// TestPurpose: Validates CORS on the token and revocation endpoints for browser-based clients.
+// Scope: Unit Test
+// Security: Cross-origin token access (only origins a client allows can read its token responses)
+// Expected: Preflights from web origins are answered for POST without credentials; a request from an origin the client allows, explicitly or through its redirect URIs, gets Access-Control-Allow-Origin even on errors; another origin is refused; requests without Origin are unchanged.
+// Test Case ID: PRO-10
+func TestHTTP_Protocol_ClientCORS(t *testing.T) {
+	db := memory.New()
+	clients := memory.NewClientRepository(db)
+	for _, c := range []*oauth2.Client{
+		{ID: "c1", ClientID: "spa", TenantID: "t1", RedirectURIs: []string{"https://spa.example.com:443/cb", "com.example.app:/cb"}, TokenEndpointAuthMethod: "none", IsActive: true},
+		{ID: "c2", ClientID: "spa-explicit", TenantID: "t1", RedirectURIs: []string{"https://spa.example.com/cb"}, AllowedOrigins: []string{"https://cdn.example.com"}, TokenEndpointAuthMethod: "none", IsActive: true},
+	} {
+		if err := clients.Create(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := &Handler{
+		oauth2Service: oauth2.NewService(clients, memory.NewAuthorizationCodeRepository(db), memory.NewAccessTokenRepository(db), memory.NewRefreshTokenRepository(db), audit.NewSlogLogger(), nil, 5*time.Minute, time.Hour, time.Hour),
+		auditLogger:   audit.NewSlogLogger(),
+	}
+	r := chi.NewRouter()
+	r.Options("/oauth2/token", h.ClientCORSPreflight)
+	r.With(h.ClientCORSMiddleware).Post("/oauth2/token", h.Token)
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/oauth2/token", nil)
+	preflight.Header.Set("Origin", "https://spa.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	preflight.Header.Set("Access-Control-Request-Headers", "content-type")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, preflight)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://spa.example.com" ||
+		w.Header().Get("Access-Control-Allow-Methods") != http.MethodPost || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("unexpected preflight response %d %v", w.Code, w.Header())
+	}
+
+	token := func(clientID, origin string) *httptest.ResponseRecorder {
+		form := url.Values{"grant_type": {"authorization_code"}, "code": {"unknown"}, "client_id": {clientID}, "redirect_uri": {"https://spa.example.com/cb"}, "code_verifier": {strings.Repeat("v", 43)}}
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	for _, tt := range []struct {
+		clientID, origin string
+		allowed          bool
+	}{
+		{"spa", "https://spa.example.com", true},
+		{"spa", "https://evil.example.com", false},
+		{"spa", "http://spa.example.com", false},
+		{"spa-explicit", "https://cdn.example.com", true},
+		{"spa-explicit", "https://spa.example.com", false},
+	} {
+		w := token(tt.clientID, tt.origin)
+		got := w.Header().Get("Access-Control-Allow-Origin")
+		if tt.allowed && (got != tt.origin || w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_grant")) {
+			t.Errorf("%s from %s: expected the token error to be readable, got %d %q %s", tt.clientID, tt.origin, w.Code, got, w.Body.String())
+		}
+		if !tt.allowed && (got != "" || !strings.Contains(w.Body.String(), "origin not allowed")) {
+			t.Errorf("%s from %s: expected the origin to be refused, got %d %q %s", tt.clientID, tt.origin, w.Code, got, w.Body.String())
+		}
+	}
+	if w := token("spa", ""); w.Header().Get("Access-Control-Allow-Origin") != "" || !strings.Contains(w.Body.String(), "invalid_grant") {
+		t.Errorf("expected a request without Origin to reach the handler unchanged, got %d %s", w.Code, w.Body.String())
+	}
+}
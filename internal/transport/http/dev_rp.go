@@ -0,0 +1,394 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+//go:embed devrp
+var devRPFS embed.FS
+
+const (
+	devRPPath    = "/dev/rp"
+	devRPCookie  = "opentrusty_dev_rp"
+	devRPFlowTTL = 10 * time.Minute
+	// devRPMaxFlows bounds the flows started but not finished
+	devRPMaxFlows = 100
+	// devRPTokenTimeout bounds the back-channel token request
+	devRPTokenTimeout = 10 * time.Second
+)
+
+var devRPPages = template.Must(template.ParseFS(devRPFS, "devrp/*.html"))
+
+// devRPFlow is an authorization request started by the test relying party
+type devRPFlow struct {
+	ClientID     string
+	ClientSecret string
+	Scope        string
+	Nonce        string
+	Verifier     string
+	CreatedAt    time.Time
+}
+
+// devRP is a relying party served by the instance itself, so integrators can check a client's
+// configuration without writing an app: it sends the browser to the authorization endpoint,
+// redeems the code through the router and verifies the ID token. Flows are kept in memory, so a
+// flow must finish on the instance that started it.
+type devRP struct {
+	h      *Handler
+	router http.Handler
+
+	mu    sync.Mutex
+	flows map[string]*devRPFlow // state -> flow
+}
+
+// devRPStep is one row of the flow shown on the result page
+type devRPStep struct {
+	Name    string
+	OK      bool
+	Skipped bool // Not run: OpenTrusty has nothing to test at this step
+	Detail  string
+}
+
+// devRPResult is the data of the result page
+type devRPResult struct {
+	ClientID      string
+	Steps         []devRPStep
+	TokenResponse string
+	IDTokenHeader string
+	IDTokenClaims string
+}
+
+// mountDevRP serves the test relying party at /dev/rp when enabled. The configuration refuses it
+// in production.
+func (h *Handler) mountDevRP(r *chi.Mux) {
+	if !h.devRP {
+		return
+	}
+	rp := &devRP{h: h, router: r, flows: make(map[string]*devRPFlow)}
+	assets, err := fs.Sub(devRPFS, "devrp/assets")
+	if err != nil {
+		panic(err) // the embedded directory always exists
+	}
+	r.Route(devRPPath, func(r chi.Router) {
+		r.Use(devRPHeaders)
+		r.Get("/", rp.index)
+		r.Post("/start", rp.start)
+		r.Get("/callback", rp.callback)
+		r.Handle("/assets/*", http.StripPrefix(devRPPath+"/assets/", http.FileServerFS(assets)))
+	})
+}
+
+// devRPHeaders keeps the pages to their own assets; forms may post to the page itself and reach
+// the authorization endpoint through its redirect
+func devRPHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'self'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (rp *devRP) issuer() string {
+	if rp.h.oidcService == nil {
+		return ""
+	}
+	return rp.h.oidcService.Issuer()
+}
+
+// redirectURI is the callback a client must register to be tested
+func (rp *devRP) redirectURI() string {
+	return rp.issuer() + devRPPath + "/callback"
+}
+
+func (rp *devRP) index(w http.ResponseWriter, r *http.Request) {
+	rp.render(w, http.StatusOK, "index.html", map[string]any{
+		"RedirectURI": rp.redirectURI(),
+		"HostedLogin": rp.h.loginURL != "",
+	})
+}
+
+// start saves a new flow and sends the browser to the authorization endpoint with state, nonce
+// and a PKCE challenge
+func (rp *devRP) start(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") == "" {
+		rp.render(w, http.StatusBadRequest, "index.html", map[string]any{
+			"RedirectURI": rp.redirectURI(),
+			"HostedLogin": rp.h.loginURL != "",
+			"Error":       "client_id is required",
+		})
+		return
+	}
+	scope := strings.TrimSpace(r.PostForm.Get("scope"))
+	if scope == "" {
+		scope = "openid"
+	}
+	flow := &devRPFlow{
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		Scope:        scope,
+		Nonce:        devRPRandom(),
+		Verifier:     devRPRandom(),
+		CreatedAt:    time.Now(),
+	}
+	state := devRPRandom()
+	if !rp.save(state, flow) {
+		http.Error(w, "too many test flows in progress; try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     devRPCookie,
+		Value:    state,
+		Path:     devRPPath,
+		MaxAge:   int(devRPFlowTTL.Seconds()),
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {flow.ClientID},
+		"redirect_uri":          {rp.redirectURI()},
+		"scope":                 {flow.Scope},
+		"state":                 {state},
+		"nonce":                 {flow.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	http.Redirect(w, r, "/oauth2/authorize?"+query.Encode(), http.StatusFound)
+}
+
+// callback checks the authorization response against the browser's flow, redeems the code and
+// verifies the ID token (OIDC Core Section 3.1.2.5 to 3.1.3.7)
+func (rp *devRP) callback(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	cookie, err := r.Cookie(devRPCookie)
+	http.SetCookie(w, &http.Cookie{Name: devRPCookie, Path: devRPPath, MaxAge: -1})
+	if err != nil || state == "" || cookie.Value != state {
+		http.Error(w, "the state does not match a flow started by this browser", http.StatusBadRequest)
+		return
+	}
+	flow := rp.take(state)
+	if flow == nil {
+		http.Error(w, "the flow expired or was already finished", http.StatusBadRequest)
+		return
+	}
+
+	result := devRPResult{ClientID: flow.ClientID}
+	if code := r.URL.Query().Get("error"); code != "" {
+		result.Steps = append(result.Steps, devRPStep{Name: "Authorization", Detail: code + ": " + r.URL.Query().Get("error_description")})
+		rp.render(w, http.StatusOK, "result.html", result)
+		return
+	}
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		result.Steps = append(result.Steps, devRPStep{Name: "Authorization", Detail: "the response has neither code nor error"})
+		rp.render(w, http.StatusOK, "result.html", result)
+		return
+	}
+	result.Steps = append(result.Steps,
+		devRPStep{Name: "Login", OK: true, Detail: "the authorization endpoint found an auth session"},
+		devRPStep{Name: "Consent", Skipped: true, Detail: "not applicable: OpenTrusty approves requests for registered clients without a consent screen"},
+		devRPStep{Name: "Authorization code", OK: true, Detail: "returned to " + rp.redirectURI() + " with the expected state"},
+	)
+
+	status, body, err := rp.exchange(r, flow, code)
+	result.TokenResponse = body
+	if err != nil || status != http.StatusOK {
+		detail := fmt.Sprintf("token endpoint answered %d", status)
+		if err != nil {
+			detail = err.Error()
+		}
+		result.Steps = append(result.Steps, devRPStep{Name: "Token", Detail: detail})
+		rp.render(w, http.StatusOK, "result.html", result)
+		return
+	}
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := json.Unmarshal([]byte(body), &tokens); err != nil {
+		result.Steps = append(result.Steps, devRPStep{Name: "Token", Detail: "invalid token response: " + err.Error()})
+		rp.render(w, http.StatusOK, "result.html", result)
+		return
+	}
+	result.Steps = append(result.Steps, devRPStep{Name: "Token", OK: tokens.AccessToken != "", Detail: "code redeemed with the PKCE verifier"})
+
+	if tokens.IDToken == "" {
+		result.Steps = append(result.Steps, devRPStep{Name: "ID token", Detail: "no id_token: request the openid scope"})
+	} else {
+		header, claims, err := rp.verifyIDToken(tokens.IDToken, tokens.AccessToken, flow)
+		result.IDTokenHeader = header
+		result.IDTokenClaims = claims
+		step := devRPStep{Name: "ID token", OK: err == nil, Detail: "signature, issuer, audience, expiry, nonce and at_hash verified"}
+		if err != nil {
+			step.Detail = err.Error()
+		}
+		result.Steps = append(result.Steps, step)
+	}
+	result.Steps = append(result.Steps, devRPStep{Name: "UserInfo", Skipped: true, Detail: "not applicable: OpenTrusty has no UserInfo endpoint; the user's claims are those of the ID token"})
+	rp.render(w, http.StatusOK, "result.html", result)
+}
+
+// exchange redeems the code through the router, as a client would at the token endpoint, and
+// returns the status and body of the response
+func (rp *devRP) exchange(r *http.Request, flow *devRPFlow, code string) (int, string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {rp.redirectURI()},
+		"code_verifier": {flow.Verifier},
+	}
+	if flow.ClientSecret == "" {
+		form.Set("client_id", flow.ClientID)
+	}
+	// A fresh context: the router must not see the routing state of the callback request
+	ctx, cancel := context.WithTimeout(context.Background(), devRPTokenTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = r.RemoteAddr
+	if flow.ClientSecret != "" {
+		req.SetBasicAuth(flow.ClientID, flow.ClientSecret)
+	}
+	rec := httptest.NewRecorder()
+	rp.router.ServeHTTP(rec, req)
+
+	var out bytes.Buffer
+	if json.Indent(&out, rec.Body.Bytes(), "", "  ") != nil {
+		return rec.Code, rec.Body.String(), nil
+	}
+	return rec.Code, out.String(), nil
+}
+
+// verifyIDToken checks an ID token against the published keys and the flow, and returns its
+// header and claims as indented JSON
+func (rp *devRP) verifyIDToken(idToken, accessToken string, flow *devRPFlow) (string, string, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+		if rp.h.oidcService == nil {
+			return nil, errors.New("no signing keys")
+		}
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range rp.h.oidcService.GetJWKS().Keys {
+			if key.Kid != kid {
+				continue
+			}
+			n, errN := base64.RawURLEncoding.DecodeString(key.N)
+			e, errE := base64.RawURLEncoding.DecodeString(key.E)
+			if errN != nil || errE != nil {
+				return nil, errors.New("malformed key")
+			}
+			return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+		}
+		return nil, fmt.Errorf("kid %q is not published", kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(rp.issuer()),
+		jwt.WithAudience(flow.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err == nil && claims["nonce"] != flow.Nonce {
+		err = errors.New("nonce does not match the request")
+	}
+	if err == nil {
+		sum := sha256.Sum256([]byte(accessToken))
+		if claims["at_hash"] != base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]) {
+			err = errors.New("at_hash does not match the access token")
+		}
+	}
+
+	var header []byte
+	if token != nil {
+		header, _ = json.MarshalIndent(token.Header, "", "  ")
+	}
+	body, _ := json.MarshalIndent(claims, "", "  ")
+	return string(header), string(body), err
+}
+
+// save stores a flow under state, dropping expired flows; it refuses when too many are pending
+func (rp *devRP) save(state string, flow *devRPFlow) bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	for s, f := range rp.flows {
+		if time.Since(f.CreatedAt) > devRPFlowTTL {
+			delete(rp.flows, s)
+		}
+	}
+	if len(rp.flows) >= devRPMaxFlows {
+		return false
+	}
+	rp.flows[state] = flow
+	return true
+}
+
+// take removes and returns the unexpired flow saved under state
+func (rp *devRP) take(state string) *devRPFlow {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	flow := rp.flows[state]
+	delete(rp.flows, state)
+	if flow == nil || time.Since(flow.CreatedAt) > devRPFlowTTL {
+		return nil
+	}
+	return flow
+}
+
+func (rp *devRP) render(w http.ResponseWriter, status int, page string, data any) {
+	var buf bytes.Buffer
+	if err := devRPPages.ExecuteTemplate(&buf, page, data); err != nil {
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// devRPRandom returns 32 random bytes, base64url-encoded
+func devRPRandom() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
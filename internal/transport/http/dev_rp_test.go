@@ -0,0 +1,144 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/oidc"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// TestPurpose: Validates the built-in test relying party through a full authorization code flow.
+// Scope: Unit Test
+// Security: Development tooling (off by default; the flow is bound to the browser by state and PKCE and finishes once)
+// Expected: /dev/rp is not served unless enabled; the page starts an authorization request with state, nonce and PKCE,
+// the callback redeems the code and verifies the ID token, consent and UserInfo are reported as not applicable, and a
+// replayed or foreign callback is refused.
+// Test Case ID: PRO-11
+func TestHTTP_Protocol_DevRP(t *testing.T) {
+	t.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
+	db := memory.New()
+	clientRepo := memory.NewClientRepository(db)
+	if err := clientRepo.Create(context.Background(), &oauth2.Client{
+		ID:                  "c1",
+		ClientID:            "client-1",
+		ClientSecretHash:    oauth2.HashClientSecret("secret-1"),
+		RedirectURIs:        []string{"http://localhost/dev/rp/callback"},
+		AllowedScopes:       []string{"openid", "profile"},
+		IsActive:            true,
+		AccessTokenLifetime: 3600,
+		TenantID:            "tenant-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	oidcSvc, _ := oidc.NewService("http://localhost")
+	oauth2Svc := oauth2.NewService(clientRepo, memory.NewAuthorizationCodeRepository(db), memory.NewAccessTokenRepository(db), memory.NewRefreshTokenRepository(db), audit.NewSlogLogger(), oidcSvc, 5*time.Minute, time.Hour, time.Hour)
+	sessSvc := session.NewService(memory.NewSessionRepository(db), audit.NewSlogLogger(), 24*time.Hour, time.Hour)
+	sess, err := sessSvc.Create(context.Background(), strPtr("tenant-1"), "user-1", "127.0.0.1", "test-agent", session.NamespaceAuth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{
+		oauth2Service:  oauth2Svc,
+		oidcService:    oidcSvc,
+		sessionService: sessSvc,
+		auditLogger:    audit.NewSlogLogger(),
+		sessionConfig:  SessionConfig{CookieName: "session_id"},
+	}
+
+	send := func(r http.Handler, req *http.Request, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(NewRouter(h, nil, nil, "auth"), httptest.NewRequest("GET", "/dev/rp/", nil)); w.Code != http.StatusNotFound {
+		t.Fatalf("expected the test relying party to be disabled by default, got %d", w.Code)
+	}
+	h.devRP = true
+	r := NewRouter(h, nil, nil, "auth")
+	if w := send(NewRouter(h, nil, nil, "admin"), httptest.NewRequest("GET", "/dev/rp/", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("expected the admin plane not to serve the test relying party, got %d", w.Code)
+	}
+
+	w := send(r, httptest.NewRequest("GET", "/dev/rp/", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "http://localhost/dev/rp/callback") {
+		t.Fatalf("expected the page to show the redirect URI, got %d", w.Code)
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'self'") {
+		t.Errorf("expected a restrictive CSP, got %q", csp)
+	}
+	if w := send(r, httptest.NewRequest("GET", "/dev/rp/assets/rp.js", nil)); w.Code != http.StatusOK {
+		t.Errorf("expected the page script, got %d", w.Code)
+	}
+
+	start := func() (*url.URL, *http.Cookie) {
+		t.Helper()
+		form := url.Values{"client_id": {"client-1"}, "client_secret": {"secret-1"}, "scope": {"openid profile"}}
+		req := httptest.NewRequest("POST", "/dev/rp/start", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := send(r, req)
+		location, _ := url.Parse(w.Header().Get("Location"))
+		cookies := w.Result().Cookies()
+		if w.Code != http.StatusFound || location.Path != "/oauth2/authorize" || len(cookies) != 1 {
+			t.Fatalf("expected a redirect to the authorization endpoint, got %d: %s", w.Code, location)
+		}
+		q := location.Query()
+		if q.Get("state") != cookies[0].Value || q.Get("nonce") == "" || q.Get("code_challenge_method") != "S256" || q.Get("redirect_uri") != "http://localhost/dev/rp/callback" {
+			t.Errorf("unexpected authorization request %s", location)
+		}
+
+		w = send(r, httptest.NewRequest("GET", location.String(), nil), &http.Cookie{Name: "session_id", Value: sess.ID})
+		callback, _ := url.Parse(w.Header().Get("Location"))
+		if w.Code != http.StatusFound || callback.Path != "/dev/rp/callback" || callback.Query().Get("code") == "" {
+			t.Fatalf("expected the authorization endpoint to return a code, got %d: %s", w.Code, w.Body.String())
+		}
+		return callback, cookies[0]
+	}
+
+	callback, cookie := start()
+	w = send(r, httptest.NewRequest("GET", callback.RequestURI(), nil), cookie)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "signature, issuer, audience, expiry, nonce and at_hash verified") || !strings.Contains(body, "access_token") {
+		t.Fatalf("expected a verified flow, got %d: %s", w.Code, body)
+	}
+	if strings.Contains(body, "✗") {
+		t.Errorf("expected every step to pass: %s", body)
+	}
+	if n := strings.Count(body, `class="skipped"`); n != 2 {
+		t.Errorf("expected the consent and UserInfo steps to be reported as not applicable, got %d skipped steps", n)
+	}
+	if w := send(r, httptest.NewRequest("GET", callback.RequestURI(), nil), cookie); w.Code != http.StatusBadRequest {
+		t.Errorf("expected a replayed callback to be refused, got %d", w.Code)
+	}
+
+	callback, _ = start()
+	if w := send(r, httptest.NewRequest("GET", callback.RequestURI(), nil)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected a callback without the browser's cookie to be refused, got %d", w.Code)
+	}
+}
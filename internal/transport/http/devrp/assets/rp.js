@@ -0,0 +1,24 @@
+// Copyright 2026 The OpenTrusty Authors. Licensed under the Apache License, Version 2.0.
+
+// Signs the browser in with an auth-plane session, the only kind the authorization
+// endpoint accepts, before the test relying party starts a flow.
+"use strict";
+
+document.getElementById("login").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const form = new FormData(event.target);
+  const status = document.getElementById("login-status");
+  status.textContent = "Signing in…";
+  try {
+    const resp = await fetch("/api/v1/auth/login", {
+      method: "POST",
+      credentials: "same-origin",
+      headers: { "Content-Type": "application/json", "X-CSRF-Token": "dev-rp" },
+      body: JSON.stringify({ email: form.get("email"), password: form.get("password"), namespace: "auth" }),
+    });
+    const body = await resp.json().catch(() => ({}));
+    status.textContent = resp.ok ? "Signed in." : "Sign in failed: " + (body.error || resp.status);
+  } catch (err) {
+    status.textContent = "Sign in failed: " + err;
+  }
+});
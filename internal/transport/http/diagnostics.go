@@ -0,0 +1,94 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"runtime/metrics"
+)
+
+// NewDiagnosticsHandler serves net/http/pprof under /debug/pprof/ and a snapshot of the Go
+// runtime metrics at /debug/runtime. It has no authentication: mount it only on a loopback
+// listener, since heap profiles expose memory contents such as keys and tokens.
+func NewDiagnosticsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", runtimeMetrics)
+	return mux
+}
+
+// histogramSummary condenses a runtime/metrics histogram; quantiles are bucket upper bounds
+type histogramSummary struct {
+	Count uint64  `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+// runtimeMetrics reports every supported runtime/metrics value, keyed by metric name
+func runtimeMetrics(w http.ResponseWriter, r *http.Request) {
+	descs := metrics.All()
+	samples := make([]metrics.Sample, len(descs))
+	for i := range descs {
+		samples[i].Name = descs[i].Name
+	}
+	metrics.Read(samples)
+
+	values := make(map[string]any, len(samples))
+	for _, sample := range samples {
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			values[sample.Name] = sample.Value.Uint64()
+		case metrics.KindFloat64:
+			values[sample.Name] = sample.Value.Float64()
+		case metrics.KindFloat64Histogram:
+			values[sample.Name] = summarize(sample.Value.Float64Histogram())
+		}
+	}
+	respondJSON(w, http.StatusOK, values)
+}
+
+func summarize(h *metrics.Float64Histogram) histogramSummary {
+	var summary histogramSummary
+	for _, count := range h.Counts {
+		summary.Count += count
+	}
+	quantile := func(q float64) float64 {
+		if summary.Count == 0 {
+			return 0
+		}
+		rank := uint64(math.Ceil(q * float64(summary.Count)))
+		var seen uint64
+		for i, count := range h.Counts {
+			seen += count
+			if seen >= rank {
+				// Buckets[i+1] is the upper bound of Counts[i]; the last one may be +Inf
+				if upper := h.Buckets[i+1]; !math.IsInf(upper, 1) {
+					return upper
+				}
+				return h.Buckets[i]
+			}
+		}
+		return 0
+	}
+	summary.P50, summary.P90, summary.P99 = quantile(0.5), quantile(0.9), quantile(0.99)
+	return summary
+}
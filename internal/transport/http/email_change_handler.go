@@ -0,0 +1,223 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// EmailChangeRequest starts changing the current user's email address
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" example:"new@example.com"`
+	Password string `json:"password"` // The user's current password
+}
+
+// EmailChangeTokenRequest carries the token of a link sent by the email change flow
+type EmailChangeTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// EmailChangeResponse represents an email change in progress
+type EmailChangeResponse struct {
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+	Status   string `json:"status" example:"pending"` // pending until the new address is verified, then verified until the change takes effect
+	// ExpiresAt ends the verification link of a pending change; a verified change takes effect then
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newEmailChangeResponse(c *identity.EmailChange) EmailChangeResponse {
+	return EmailChangeResponse{
+		OldEmail:  c.OldEmail,
+		NewEmail:  c.NewEmail,
+		Status:    c.Status,
+		ExpiresAt: c.ExpiresAt,
+	}
+}
+
+// authorizeEmailChange checks that the user may change their own profile, which also covers
+// deleting their own account
+func (h *Handler) authorizeEmailChange(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopePlatform, nil, authz.PermUserWriteProfile)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "update profile access required")
+		return "", false
+	}
+	return userID, true
+}
+
+// GetEmailChange handles retrieving the current user's email change
+// @Summary Get Email Change
+// @Description Get the email address change the current user has in progress
+// @Tags User
+// @Produce json
+// @Success 200 {object} EmailChangeResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/email-change [get]
+// @Security CookieAuth
+// @Security BearerAuth
+func (h *Handler) GetEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authorizeEmailChange(w, r)
+	if !ok {
+		return
+	}
+
+	change, err := h.identityService.GetEmailChange(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to get email change")
+		return
+	}
+	respondJSON(w, http.StatusOK, newEmailChangeResponse(change))
+}
+
+// RequestEmailChange handles starting an email change
+// @Summary Request Email Change
+// @Description Start changing the current user's email address. A verification link is mailed to the new address; the address changes only once it is followed. A new request replaces one in progress.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body EmailChangeRequest true "New address and current password"
+// @Success 202 {object} EmailChangeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /user/email-change [post]
+// @Security CookieAuth
+// @Security BearerAuth
+func (h *Handler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authorizeEmailChange(w, r)
+	if !ok {
+		return
+	}
+
+	var req EmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	change, err := h.identityService.RequestEmailChange(r.Context(), userID, req.NewEmail, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrInvalidCredentials):
+			respondError(w, http.StatusUnauthorized, "invalid password")
+		case errors.Is(err, identity.ErrUserAlreadyExists):
+			respondError(w, http.StatusConflict, "email address already in use")
+		default:
+			respondServiceError(w, r, err, "failed to request email change")
+		}
+		return
+	}
+	respondJSON(w, http.StatusAccepted, newEmailChangeResponse(change))
+}
+
+// CancelEmailChange handles abandoning an email change
+// @Summary Cancel Email Change
+// @Description Abandon the email address change the current user has in progress
+// @Tags User
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/email-change [delete]
+// @Security CookieAuth
+// @Security BearerAuth
+func (h *Handler) CancelEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authorizeEmailChange(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.identityService.CancelEmailChange(r.Context(), userID); err != nil {
+		respondServiceError(w, r, err, "failed to cancel email change")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmailChange handles the link mailed to the new address
+// @Summary Verify Email Change
+// @Description Verify a new email address with the token mailed to it. The address changes at once, or, with a cooling-off window, when the window ends unless the old address reverts it.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body EmailChangeTokenRequest true "Verification token"
+// @Success 200 {object} EmailChangeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /email-change/verify [post]
+func (h *Handler) VerifyEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req EmailChangeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	change, err := h.identityService.VerifyEmailChange(r.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrEmailChangeNotFound):
+			respondError(w, http.StatusNotFound, "email change link expired or unknown")
+		case errors.Is(err, identity.ErrUserAlreadyExists):
+			respondError(w, http.StatusConflict, "email address already in use")
+		default:
+			respondServiceError(w, r, err, "failed to verify email change")
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, newEmailChangeResponse(change))
+}
+
+// RevertEmailChange handles the revert link mailed to the old address
+// @Summary Revert Email Change
+// @Description Keep the current email address by cancelling a verified change during its cooling-off window, with the token mailed to the old address
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body EmailChangeTokenRequest true "Revert token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /email-change/revert [post]
+func (h *Handler) RevertEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req EmailChangeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if _, err := h.identityService.RevertEmailChange(r.Context(), req.Token); err != nil {
+		if errors.Is(err, identity.ErrEmailChangeNotFound) {
+			respondError(w, http.StatusNotFound, "email change link expired or unknown")
+			return
+		}
+		respondServiceError(w, r, err, "failed to revert email change")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "email change reverted",
+	})
+}
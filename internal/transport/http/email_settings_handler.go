@@ -0,0 +1,251 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// EmailSettingsRequest represents the tenant outbound email configuration
+type EmailSettingsRequest struct {
+	Enabled     bool    `json:"enabled" example:"true"`
+	Host        string  `json:"host" binding:"required" example:"smtp.example.com"`
+	Port        int     `json:"port" binding:"required" example:"587"`
+	Username    string  `json:"username" example:"mailer@example.com"`
+	Password    *string `json:"password,omitempty" example:"app-password"`
+	FromAddress string  `json:"from_address" binding:"required" example:"no-reply@example.com"`
+	FromName    string  `json:"from_name" example:"Example Inc."`
+	TLSMode     string  `json:"tls_mode" example:"starttls"`
+}
+
+// EmailSettingsResponse represents the tenant outbound email configuration without credentials
+type EmailSettingsResponse struct {
+	TenantID    string    `json:"tenant_id"`
+	Enabled     bool      `json:"enabled"`
+	Host        string    `json:"host"`
+	Port        int       `json:"port"`
+	Username    string    `json:"username"`
+	HasPassword bool      `json:"has_password"`
+	FromAddress string    `json:"from_address"`
+	FromName    string    `json:"from_name"`
+	TLSMode     string    `json:"tls_mode"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SendTestEmailRequest represents a request to send a test email
+type SendTestEmailRequest struct {
+	To string `json:"to" binding:"required" example:"admin@example.com"`
+}
+
+func newEmailSettingsResponse(s *email.Settings) EmailSettingsResponse {
+	return EmailSettingsResponse{
+		TenantID:    s.TenantID,
+		Enabled:     s.Enabled,
+		Host:        s.Host,
+		Port:        s.Port,
+		Username:    s.Username,
+		HasPassword: s.HasPassword(),
+		FromAddress: s.FromAddress,
+		FromName:    s.FromName,
+		TLSMode:     s.TLSMode,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+// GetEmailSettings handles retrieving a tenant's outbound email settings
+// @Summary Get Email Settings
+// @Description Get the tenant's outbound email configuration. Credentials are never returned.
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 200 {object} EmailSettingsResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string "tenant uses the platform default sender"
+// @Router /tenants/{tenantID}/email-settings [get]
+func (h *Handler) GetEmailSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return
+	}
+
+	settings, err := h.emailService.GetSettings(r.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, email.ErrSettingsNotFound) {
+			respondError(w, http.StatusNotFound, "email settings not configured")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get email settings")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newEmailSettingsResponse(settings))
+}
+
+// UpdateEmailSettings handles creating or replacing a tenant's outbound email settings
+// @Summary Update Email Settings
+// @Description Configure tenant SMTP credentials. Omit password to keep the stored credential. tls_mode "none" is refused with a username or password.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body EmailSettingsRequest true "Email Settings"
+// @Success 200 {object} EmailSettingsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/email-settings [put]
+func (h *Handler) UpdateEmailSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return
+	}
+
+	var req EmailSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	settings, err := h.emailService.UpdateSettings(r.Context(), tenantID, userID, email.SettingsInput{
+		Enabled:     req.Enabled,
+		Host:        req.Host,
+		Port:        req.Port,
+		Username:    req.Username,
+		Password:    req.Password,
+		FromAddress: req.FromAddress,
+		FromName:    req.FromName,
+		TLSMode:     req.TLSMode,
+	})
+	if err != nil {
+		if errors.Is(err, email.ErrInvalidSettings) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to update email settings")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newEmailSettingsResponse(settings))
+}
+
+// DeleteEmailSettings handles removing a tenant's outbound email settings
+// @Summary Delete Email Settings
+// @Description Remove tenant SMTP credentials and fall back to the platform default sender
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/email-settings [delete]
+func (h *Handler) DeleteEmailSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return
+	}
+
+	if err := h.emailService.DeleteSettings(r.Context(), tenantID, userID); err != nil {
+		if errors.Is(err, email.ErrSettingsNotFound) {
+			respondError(w, http.StatusNotFound, "email settings not configured")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete email settings")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendTestEmail handles sending a test message with the tenant's stored settings
+// @Summary Send Test Email
+// @Description Send a test message using the tenant's stored SMTP settings, even if they are not yet enabled
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body SendTestEmailRequest true "Recipient"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string "smtp delivery failed"
+// @Router /tenants/{tenantID}/email-settings/test [post]
+func (h *Handler) SendTestEmail(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return
+	}
+
+	var req SendTestEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.emailService.SendTest(r.Context(), tenantID, userID, req.To); err != nil {
+		switch {
+		case errors.Is(err, email.ErrInvalidRecipient):
+			respondError(w, http.StatusBadRequest, "invalid recipient address")
+		case errors.Is(err, email.ErrSettingsNotFound):
+			respondError(w, http.StatusNotFound, "email settings not configured")
+		default:
+			slog.WarnContext(r.Context(), "test email delivery failed",
+				logger.TenantID(tenantID),
+				logger.Error(err),
+			)
+			respondError(w, http.StatusBadGateway, "test email delivery failed: "+err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		JSONKeyStatus: "sent",
+	})
+}
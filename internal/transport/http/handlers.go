@@ -31,25 +31,37 @@
 // @in cookie
 // @name session_id
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
 package http
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/opentrusty/opentrusty/internal/apperr"
 	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/captcha"
+	"github.com/opentrusty/opentrusty/internal/cluster"
+	"github.com/opentrusty/opentrusty/internal/email"
 	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/jobs"
 	"github.com/opentrusty/opentrusty/internal/oauth2"
 	"github.com/opentrusty/opentrusty/internal/observability/logger"
 	"github.com/opentrusty/opentrusty/internal/oidc"
 	"github.com/opentrusty/opentrusty/internal/session"
 	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/opentrusty/opentrusty/internal/webhook"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
@@ -70,11 +82,27 @@ type Handler struct {
 	oauth2Service   *oauth2.Service
 	authzService    *authz.Service
 	tenantService   *tenant.Service
+	memberships     *tenant.MembershipService // nil treats every user as a member of their home tenant only
 	oidcService     *oidc.Service
+	emailService    *email.Service
+	auditService    *audit.Service
+	webhookService  *webhook.Service
 	auditLogger     audit.Logger
 	// Configuration
 	sessionConfig SessionConfig
-	mode          string // "auth", "admin", or "all"
+	apiDocs       APIDocsConfig
+	timeouts      RouteTimeouts
+	ipFilter      *IPFilter              // nil admits every client
+	adminScopes   authz.ScopePermissions // empty refuses access tokens on the admin API
+	challenge     *captcha.Challenge     // nil never requires a challenge on login
+	jobs          *jobs.Runner           // background jobs reported by the health check; nil reports none
+	cluster       *cluster.Registry      // replicas reported at /api/v1/system/cluster; nil answers 404
+	dbSaturated   func() bool            // reports an exhausted database pool, answered with 503; nil never sheds load
+	loginURL      string                 // hosted login page for unauthenticated authorization requests; empty answers 401
+	secretGuard   string                 // SecretGuardOff, SecretGuardLog or SecretGuardBlock (empty)
+	devRP         bool                   // serves the test relying party at /dev/rp; never set in production
+	mode          string                 // "auth", "admin", or "all"
+	rateLimits    *RateLimits            // set by NewRouter; login checks the limits of the chosen account's tenant
 }
 
 // SessionConfig holds session cookie configuration
@@ -94,9 +122,24 @@ func NewHandler(
 	oauthSvc *oauth2.Service,
 	authzSvc *authz.Service,
 	tenantSvc *tenant.Service,
+	membershipSvc *tenant.MembershipService,
 	oidcSvc *oidc.Service,
+	emailSvc *email.Service,
+	auditSvc *audit.Service,
+	webhookSvc *webhook.Service,
 	auditLogger audit.Logger,
 	sessConfig SessionConfig,
+	docsConfig APIDocsConfig,
+	timeouts RouteTimeouts,
+	ipFilter *IPFilter,
+	adminScopes authz.ScopePermissions,
+	challenge *captcha.Challenge,
+	jobRunner *jobs.Runner,
+	clusterRegistry *cluster.Registry,
+	dbSaturated func() bool,
+	loginURL string,
+	secretGuard string,
+	devRP bool,
 	mode string,
 ) *Handler {
 	return &Handler{
@@ -105,20 +148,38 @@ func NewHandler(
 		oauth2Service:   oauthSvc,
 		authzService:    authzSvc,
 		tenantService:   tenantSvc,
+		memberships:     membershipSvc,
 		oidcService:     oidcSvc,
+		emailService:    emailSvc,
+		auditService:    auditSvc,
+		webhookService:  webhookSvc,
 		auditLogger:     auditLogger,
 		sessionConfig:   sessConfig,
+		apiDocs:         docsConfig,
+		timeouts:        timeouts,
+		ipFilter:        ipFilter,
+		adminScopes:     adminScopes,
+		challenge:       challenge,
+		jobs:            jobRunner,
+		cluster:         clusterRegistry,
+		dbSaturated:     dbSaturated,
+		loginURL:        loginURL,
+		secretGuard:     secretGuard,
+		devRP:           devRP,
 		mode:            mode,
 	}
 }
 
 // NewRouter creates a new HTTP router
-func NewRouter(h *Handler, rateLimiter *RateLimiter, mode string) *chi.Mux {
+func NewRouter(h *Handler, rateLimits *RateLimits, accessLog *AccessLog, mode string) *chi.Mux {
+	h.rateLimits = rateLimits
 	r := chi.NewRouter()
 
 	// Middleware
 	r.Use(middleware.RequestID)
-	r.Use(RateLimitMiddleware(rateLimiter))
+	r.Use(PlatformScopeMiddleware)
+	r.Use(AuditContextMiddleware)
+	r.Use(rateLimits.limit(RateLimitLayerIP, getClientIP))
 	r.Use(func(handler http.Handler) http.Handler {
 		return otelhttp.NewHandler(handler, "http_request",
 			otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
@@ -126,9 +187,11 @@ func NewRouter(h *Handler, rateLimiter *RateLimiter, mode string) *chi.Mux {
 			}),
 		)
 	})
-	r.Use(LoggingMiddleware())
+	r.Use(accessLog.middleware)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
+	r.Use(h.PoolBreakerMiddleware)
+	r.Use(h.SecretGuardMiddleware)
+	r.Use(h.timeouts.middleware(r))
 
 	// Health check (Available in all modes)
 	r.Get("/health", h.HealthCheck)
@@ -137,26 +200,39 @@ func NewRouter(h *Handler, rateLimiter *RateLimiter, mode string) *chi.Mux {
 	if mode == "auth" || mode == "all" {
 		// OIDC Discovery & JWKS (Phase II.2)
 		r.Get("/.well-known/openid-configuration", h.Discovery)
+		r.Get("/.well-known/openid-configuration/tenants/{tenantID}", h.TenantDiscovery)
+		r.Get("/.well-known/oauth-authorization-server", h.AuthorizationServerMetadata)
 		r.Get("/jwks.json", h.JWKS)
 
 		// OAuth2 routes (Tenant-Scoped)
 		r.Route("/oauth2", func(r chi.Router) {
+			r.Use(NoStoreMiddleware) // Tokens, codes in redirects and token errors must never be cached
 			r.Use(TenantMiddleware)
-			r.With(h.AuthMiddleware).Get("/authorize", h.Authorize)
-			r.Post("/token", h.Token)
-			r.Post("/revoke", h.Revoke)
+			r.With(h.OptionalSessionMiddleware(session.NamespaceAuth)).Get("/authorize", h.Authorize)
+			r.Options("/token", h.ClientCORSPreflight)
+			r.Options("/revoke", h.ClientCORSPreflight)
+			r.Group(func(r chi.Router) {
+				r.Use(rateLimits.limit(RateLimitLayerClient, clientKey))
+				r.Use(rateLimits.limit(RateLimitLayerTenant, h.clientTenantKey))
+				r.Use(rateLimits.limitTenant(RateLimitLayerClient, h.clientTenantKey, clientKey))
+				r.Use(h.ClientAuthBackoffMiddleware(rateLimits))
+				r.With(h.ClientCORSMiddleware).Post("/token", h.Token)
+				r.With(h.ClientCORSMiddleware).Post("/revoke", h.Revoke)
+				r.Post("/introspect", h.Introspect)
+			})
 		})
 	}
 
 	// Consolidate /api/v1 routes to avoid double-mount panic in "all" mode
 	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(NoStoreMiddleware) // Session details, client secrets and user data must never be cached
 		r.Use(TenantMiddleware)
 
 		// Auth Plane Endpoints
 		if mode == "auth" || mode == "all" {
 			r.Group(func(r chi.Router) {
 				r.Use(h.CSRFMiddleware) // Enforce CSRF protection for Auth Plane (Login/Logout)
-				r.Post("/auth/login", h.Login)
+				r.With(rateLimits.limit(RateLimitLayerUser, loginKey)).Post("/auth/login", h.Login)
 				r.Post("/auth/logout", h.Logout)
 				// Note: /auth/register is DISABLED but would be here
 				r.Post("/auth/register", h.Register)
@@ -167,17 +243,41 @@ func NewRouter(h *Handler, rateLimiter *RateLimiter, mode string) *chi.Mux {
 		if mode == "admin" || mode == "all" {
 			// Session Check (Required for Console)
 			// Available in Admin mode so Console can check if cookie is valid
-			r.With(h.AuthMiddleware).Get("/auth/me", h.GetCurrentUser)
+			r.With(h.AdminIPMiddleware, h.AdminAuthMiddleware).Get("/auth/me", h.GetCurrentUser)
+			// Console heartbeat; authenticates the cookie itself so the renewal is not a side effect of the middleware
+			r.With(h.AdminIPMiddleware, h.CSRFMiddleware).Post("/auth/refresh-session", h.RefreshSession)
+			// Email change links; the mailed token is the credential
+			r.With(h.AdminIPMiddleware).Post("/email-change/verify", h.VerifyEmailChange)
+			r.With(h.AdminIPMiddleware).Post("/email-change/revert", h.RevertEmailChange)
 
 			// Protected Admin routes
 			r.Group(func(r chi.Router) {
-				r.Use(h.AuthMiddleware)
-				r.Use(h.CSRFMiddleware) // Enforce CSRF protection for Admin Plane
+				r.Use(h.AdminIPMiddleware)   // Client address allow/deny lists, before authentication
+				r.Use(h.AdminAuthMiddleware) // Session cookie or access token
+				r.Use(h.CSRFMiddleware)      // Enforce CSRF protection for Admin Plane (sessions only)
 
 				// User profile (Self) - Available in Admin for "My Profile" page
 				r.Get("/user/profile", h.GetProfile)
-				r.Put("/user/profile", h.UpdateProfile)
-				r.Post("/user/change-password", h.ChangePassword)
+				r.With(SessionOnlyMiddleware).Put("/user/profile", h.UpdateProfile)
+				r.With(SessionOnlyMiddleware).Post("/user/change-password", h.ChangePassword)
+				r.Route("/user/email-change", func(r chi.Router) {
+					r.Use(SessionOnlyMiddleware)
+					r.Get("/", h.GetEmailChange)
+					r.Post("/", h.RequestEmailChange)
+					r.Delete("/", h.CancelEmailChange)
+				})
+				r.Route("/user/account-deletion", func(r chi.Router) {
+					r.Use(SessionOnlyMiddleware)
+					r.Get("/", h.GetAccountDeletion)
+					r.Post("/", h.RequestAccountDeletion)
+					r.Delete("/", h.CancelAccountDeletion)
+				})
+
+				// Replica coherence (Platform admin)
+				r.Get("/system/cluster", h.GetClusterStatus)
+
+				// Ops dashboard (Platform admin)
+				r.Get("/platform/stats", h.GetPlatformStats)
 
 				// Tenant management (Platform & Tenant assignments)
 				r.Route("/tenants", func(r chi.Router) {
@@ -187,17 +287,35 @@ func NewRouter(h *Handler, rateLimiter *RateLimiter, mode string) *chi.Mux {
 
 					// Specific tenant operations
 					r.Route("/{tenantID}", func(r chi.Router) {
+						r.Use(rateLimits.limit(RateLimitLayerTenant, tenantKey))
+						r.Use(h.TenantIPMiddleware)
 						r.Use(func(next http.Handler) http.Handler {
 							return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 								tid := chi.URLParam(r, "tenantID")
 								ctx := context.WithValue(r.Context(), tenantIDKey, tid)
+								ctx = tenant.WithScope(ctx, tid)
+								annotateAccessLog(ctx, tid, "")
 								next.ServeHTTP(w, r.WithContext(ctx))
 							})
 						})
+						r.Use(TenantTokenMiddleware) // An access token only reaches its own tenant
+						r.Get("/users", h.ListTenantUsers)
+						r.Route("/members", func(r chi.Router) {
+							r.Get("/", h.ListTenantMembers)
+							r.Post("/", h.AddTenantMember)
+							r.Delete("/{userID}", h.RemoveTenantMember)
+						})
 						r.Route("/users/{userID}/roles", func(r chi.Router) {
 							r.Post("/", h.AssignTenantRole)
 							r.Delete("/{role}", h.RevokeTenantRole)
 						})
+						// Lockout management
+						r.Get("/users/{userID}/lockout", h.GetUserLockout)
+						r.Delete("/users/{userID}/lockout", h.UnlockUser)
+						r.Post("/users/{userID}/password", h.SetUserPassword)
+						r.Post("/users/{userID}/password/expire", h.ExpireUserPassword)
+						// Incident response
+						r.Post("/force-logout", h.ForceLogoutTenant)
 						// OAuth2 Client Management
 						r.Route("/clients", func(r chi.Router) {
 							r.Get("/", h.ListClients)
@@ -206,6 +324,69 @@ func NewRouter(h *Handler, rateLimiter *RateLimiter, mode string) *chi.Mux {
 								r.Get("/", h.GetClient)
 								r.Delete("/", h.DeleteClient)
 								r.Post("/secret", h.RegenerateClientSecret)
+								r.Get("/jwks", h.GetClientKeys)
+								r.Put("/jwks", h.UpdateClientKeys)
+								r.Post("/force-logout", h.ForceLogoutClient)
+							})
+						})
+						// Outbound email configuration
+						r.Route("/email-settings", func(r chi.Router) {
+							r.Get("/", h.GetEmailSettings)
+							r.Put("/", h.UpdateEmailSettings)
+							r.Delete("/", h.DeleteEmailSettings)
+							r.Post("/test", h.SendTestEmail)
+						})
+						// Audit trail
+						r.Get("/audit-events", h.ListAuditEvents)
+						r.Route("/audit-retention", func(r chi.Router) {
+							r.Get("/", h.GetAuditRetention)
+							r.Put("/", h.UpdateAuditRetention)
+							r.Delete("/", h.DeleteAuditRetention)
+						})
+						// Protocol decision logging
+						r.Route("/protocol-logging", func(r chi.Router) {
+							r.Get("/", h.GetProtocolLog)
+							r.Put("/", h.UpdateProtocolLog)
+						})
+						// Lockout and rate limit overrides
+						r.Route("/security-policy", func(r chi.Router) {
+							r.Get("/", h.GetSecurityPolicy)
+							r.Put("/", h.UpdateSecurityPolicy)
+							r.Delete("/", h.DeleteSecurityPolicy)
+						})
+						// Custom OAuth2 scopes
+						r.Route("/scopes", func(r chi.Router) {
+							r.Get("/", h.ListScopes)
+							r.Post("/", h.CreateScope)
+							r.Route("/{scopeName}", func(r chi.Router) {
+								r.Get("/", h.GetScope)
+								r.Put("/", h.UpdateScope)
+								r.Delete("/", h.DeleteScope)
+							})
+						})
+						// Projects and project roles
+						r.Route("/projects", func(r chi.Router) {
+							r.Get("/", h.ListProjects)
+							r.Post("/", h.CreateProject)
+							r.Route("/{projectID}", func(r chi.Router) {
+								r.Get("/", h.GetProject)
+								r.Put("/", h.UpdateProject)
+								r.Delete("/", h.DeleteProject)
+								r.Get("/members", h.ListProjectMembers)
+								r.Put("/members/{userID}", h.SetProjectMember)
+								r.Delete("/members/{userID}", h.RemoveProjectMember)
+							})
+						})
+						// Outbound webhooks
+						r.Route("/webhooks", func(r chi.Router) {
+							r.Get("/", h.ListWebhooks)
+							r.Post("/", h.CreateWebhook)
+							r.Route("/{webhookID}", func(r chi.Router) {
+								r.Get("/", h.GetWebhook)
+								r.Put("/", h.UpdateWebhook)
+								r.Delete("/", h.DeleteWebhook)
+								r.Get("/deliveries", h.ListWebhookDeliveries)
+								r.Post("/deliveries/{deliveryID}/redeliver", h.RedeliverWebhook)
 							})
 						})
 					})
@@ -214,19 +395,43 @@ func NewRouter(h *Handler, rateLimiter *RateLimiter, mode string) *chi.Mux {
 		}
 	})
 
+	// Test relying party (Auth mode, development only)
+	if mode == "auth" || mode == "all" {
+		h.mountDevRP(r)
+	}
+
+	// API description (Available in all modes)
+	h.mountAPIDocs(r)
+
 	return r
 }
 
 // HealthResponse represents the health status response (RFC draft-inadarei-api-health-check)
 type HealthResponse struct {
-	Status  string `json:"status" example:"pass"`
-	Service string `json:"service" example:"opentrusty"`
-	Version string `json:"version,omitempty" example:"v1.0.0"`
+	Status  string                         `json:"status" example:"pass"`
+	Service string                         `json:"service" example:"opentrusty"`
+	Version string                         `json:"version,omitempty" example:"v1.0.0"`
+	Checks  map[string][]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the state of one background job, keyed "job:<name>" in HealthResponse.Checks
+type HealthCheckResult struct {
+	ComponentType  string     `json:"componentType" example:"job"`
+	Status         string     `json:"status" example:"pass"`
+	Time           *time.Time `json:"time,omitempty"`
+	LastSuccess    *time.Time `json:"lastSuccess,omitempty"`
+	LastDurationMs int64      `json:"lastDurationMs" example:"42"`
+	LastProcessed  int64      `json:"lastProcessed" example:"120"`
+	Runs           int64      `json:"runs" example:"10"`
+	Failures       int64      `json:"failures" example:"0"`
+	Output         string     `json:"output,omitempty"`
 }
 
 // HealthCheck returns the health status
 // @Summary Health Check
 // @Description Checks if the service is up and running. Returns "pass", "fail", or "warn".
+// @Description Background jobs are listed under checks. A job whose last run failed, or that has not succeeded within three of its intervals, is reported as "warn" and turns the overall status to "warn".
+// @Description An exhausted database connection pool is reported as "warn" under database:pool while other requests fail fast with 503.
 // @Tags System
 // @Produce json
 // @Success 200 {object} HealthResponse
@@ -237,10 +442,64 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Pragma", "no-cache")
 	w.Header().Set("Expires", "0")
 
-	respondJSON(w, http.StatusOK, HealthResponse{
+	resp := HealthResponse{
 		Status:  "pass",
 		Service: "opentrusty",
-	})
+	}
+	now := time.Now()
+	for _, job := range h.jobs.Status() {
+		check := jobHealth(job, now)
+		if check.Status != "pass" {
+			resp.Status = "warn"
+		}
+		if resp.Checks == nil {
+			resp.Checks = make(map[string][]HealthCheckResult)
+		}
+		resp.Checks["job:"+job.Name] = []HealthCheckResult{check}
+	}
+	if h.dbSaturated != nil && h.dbSaturated() {
+		resp.Status = "warn"
+		if resp.Checks == nil {
+			resp.Checks = make(map[string][]HealthCheckResult)
+		}
+		resp.Checks["database:pool"] = []HealthCheckResult{{
+			ComponentType: "datastore",
+			Status:        "warn",
+			Time:          &now,
+			Output:        "connection pool exhausted; requests are failing fast with 503",
+		}}
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// jobHealth reports a job as "warn" when its last run failed or it has stopped succeeding
+func jobHealth(job jobs.Status, now time.Time) HealthCheckResult {
+	check := HealthCheckResult{
+		ComponentType:  "job",
+		Status:         "pass",
+		LastDurationMs: job.LastDuration.Milliseconds(),
+		LastProcessed:  job.LastProcessed,
+		Runs:           job.Runs,
+		Failures:       job.Failures,
+		Output:         job.LastError,
+	}
+	if !job.LastRun.IsZero() {
+		check.Time = &job.LastRun
+	}
+	if !job.LastSuccess.IsZero() {
+		check.LastSuccess = &job.LastSuccess
+	}
+	switch {
+	case job.Stale(now):
+		check.Status = "warn"
+		if check.Output == "" {
+			check.Output = "no successful run within " + job.StaleAfter.String()
+		}
+	case job.Failing():
+		check.Status = "warn"
+	}
+	return check
 }
 
 // RegisterRequest represents registration data
@@ -278,19 +537,36 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required" example:"user@example.com"`
 	Password string `json:"password" binding:"required" example:"secret123"`
+	// NewPassword replaces an expired password as part of the login
+	NewPassword string `json:"new_password,omitempty"`
+	// CaptchaResponse is the solved challenge, once a login was answered with captcha_provider
+	CaptchaResponse string `json:"captcha_response,omitempty"`
+	// Namespace is the plane the session is for: "auth" to authorize OAuth2 clients or "admin" for
+	// the admin API. The auth plane only issues auth sessions; serving both planes, the default is admin.
+	Namespace string `json:"namespace,omitempty" enums:"auth,admin"`
+	// AuthorizationRequest is the pending authorization request that sent the user to login. It
+	// implies an auth session, and the response's redirect_to resumes the request.
+	AuthorizationRequest string `json:"authorization_request,omitempty"`
+	// AccountID chooses among the accounts offered when the email is registered in several tenants
+	AccountID string `json:"account_id,omitempty"`
+	// MembershipID chooses the tenant of an admin session among those offered when the account is a
+	// member of several tenants
+	MembershipID string `json:"membership_id,omitempty"`
 }
 
 // Login handles user login
 // @Summary Login
-// @Description Authenticate admin user and create a session (tenant derived from user record)
+// @Description Authenticate admin user and create a session (tenant derived from user record, or the membership chosen for an admin session). With authorization_request, redirect_to resumes the pending authorization request. An expired or temporary password gets password_change_required and a session that can only change it, unless new_password replaces it in the same request.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Credentials"
 // @Success 200 {object} map[string]any
 // @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 401 {object} ChallengeResponse "invalid credentials, or a challenge must be solved"
 // @Failure 403 {object} map[string]string "non-admin user"
+// @Failure 409 {object} AccountChoiceResponse "the credentials match accounts in several tenants, or the account is a member of several; repeat with account_id or membership_id"
+// @Failure 503 {object} map[string]string "challenge verification unavailable"
 // @Router /auth/login [post]
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	// Security Hardening: Reject tenant context from client
@@ -309,29 +585,59 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "invalid request")
 		return
 	}
+	if req.AuthorizationRequest != "" {
+		if req.Namespace == session.NamespaceAdmin {
+			respondError(w, http.StatusBadRequest, "authorization_request requires namespace auth")
+			return
+		}
+		req.Namespace = session.NamespaceAuth
+	}
+	namespace, err := h.loginNamespace(req.Namespace)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.checkChallenge(w, r, req.Email, req.CaptchaResponse) {
+		return
+	}
 
 	// Control Plane Login Model (Rule D):
-	// - Authenticate by email + password globally (no tenant filtering)
+	// - Authenticate by email + password globally; the user chooses among accounts in several tenants
 	// - Derive tenant_id from authenticated user record
 	// - Only allow admin-capable roles
 
-	// Use Authenticate with empty string tenant for global lookup
-	user, err := h.identityService.Authenticate(r.Context(), "", req.Email, req.Password)
+	// The same email may be registered in several tenants; the account chosen gives the tenant
+	tenantID, ok := h.selectAccount(w, r, &req)
+	if !ok {
+		return
+	}
+	// The tenant may limit its users' logins more tightly than the platform
+	if !h.rateLimits.allowTenant(w, r, RateLimitLayerUser, tenantID, userKey(req.Email)) {
+		return
+	}
+	user, err := h.identityService.Authenticate(r.Context(), tenantID, req.Email, req.Password)
+	if err == nil && user.PasswordChangeRequired && req.NewPassword != "" {
+		user, err = h.identityService.ChangeExpiredPassword(r.Context(), tenantID, req.Email, req.Password, req.NewPassword)
+		switch {
+		case errors.Is(err, identity.ErrWeakPassword):
+			respondError(w, http.StatusBadRequest, "new password does not meet security requirements")
+			return
+		case errors.Is(err, identity.ErrPasswordReused):
+			respondError(w, http.StatusBadRequest, "new password must differ from the expired one")
+			return
+		}
+	}
 	if err != nil {
-		h.auditLogger.Log(r.Context(), audit.Event{
-			Type:     audit.TypeLoginFailed,
-			Resource: req.Email,
-			Metadata: map[string]any{audit.AttrReason: "invalid_credentials"},
-		})
-		respondError(w, http.StatusUnauthorized, "invalid credentials")
+		h.loginFailed(w, r, req.Email)
 		return
 	}
 
-	// Extract tenant for audit and permission checks
-	userTenantID := ""
-	if user.TenantID != nil {
-		userTenantID = *user.TenantID
+	// The tenant signed in to, for audit and permission checks: the home tenant, or the membership chosen
+	userTenantID, ok := h.selectMembership(w, r, &req, user, namespace)
+	if !ok {
+		return
 	}
+	annotateAccessLog(r.Context(), userTenantID, user.ID)
 
 	// Control Plane Authorization: Only admin-capable users can login to UI
 	// Members authenticate via OAuth2 flows to external applications
@@ -357,7 +663,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 			TenantID: userTenantID,
 			ActorID:  user.ID,
 			Resource: req.Email,
-			Metadata: map[string]any{audit.AttrReason: "insufficient_privileges"},
+			Payload:  &audit.LoginFailedPayload{Reason: "insufficient_privileges"},
 		})
 		respondError(w, http.StatusForbidden, "access denied: admin role required for UI login")
 		return
@@ -365,20 +671,31 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Session Rotation (Hardening Step): Destroy old session if it exists
 	if oldSessionID := h.getSessionFromCookie(r); oldSessionID != "" {
+		if oldSession, err := h.sessionService.Get(r.Context(), oldSessionID); err == nil {
+			oldTenantID := ""
+			if oldSession.TenantID != nil {
+				oldTenantID = *oldSession.TenantID
+			}
+			h.auditLogger.Log(r.Context(), audit.Event{
+				Type:     audit.TypeSessionRevoked,
+				TenantID: oldTenantID,
+				ActorID:  user.ID,
+				Resource: audit.ResourceSession,
+				Payload:  &audit.SessionPayload{SessionID: oldSession.ID},
+			})
+		}
 		_ = h.sessionService.Destroy(r.Context(), oldSessionID)
 	}
 
-	// Determine session namespace based on mode
-	// If in "all" or "admin" mode, we treat this as an admin session
-	namespace := "admin"
-	if h.mode == "auth" {
-		namespace = "auth"
+	// Create session with the immutable tenant_id of the tenant signed in to. An expired or
+	// temporary password gets a session that can only change it.
+	create := h.sessionService.Create
+	if user.PasswordChangeRequired {
+		create = h.sessionService.CreatePasswordChange
 	}
-
-	// Create session with immutable tenant_id from user record
-	sess, err := h.sessionService.Create(
+	sess, err := create(
 		r.Context(),
-		user.TenantID,
+		optionalTenant(userTenantID),
 		user.ID,
 		getIPAddress(r),
 		r.UserAgent(),
@@ -399,13 +716,52 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		Resource:  audit.ResourceSession,
 		IPAddress: getIPAddress(r),
 		UserAgent: r.UserAgent(),
-		Metadata:  map[string]any{audit.AttrSessionID: sess.ID},
+		Payload:   &audit.LoginSuccessPayload{SessionID: sess.ID, PasswordChangeRequired: sess.PasswordChangeRequired},
 	})
 
-	respondJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		JSONKeyUserID: user.ID,
 		JSONKeyEmail:  user.Email,
+	}
+	switch {
+	case sess.PasswordChangeRequired:
+		// The authorization request cannot resume until a new password is set
+		resp["password_change_required"] = true
+	case req.AuthorizationRequest != "":
+		resp["redirect_to"] = h.resumeAuthorizationURL(req.AuthorizationRequest)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// loginFailed answers a login whose credentials do not match an account
+func (h *Handler) loginFailed(w http.ResponseWriter, r *http.Request, email string) {
+	h.recordLoginFailure(r)
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     audit.TypeLoginFailed,
+		Resource: email,
+		Payload:  &audit.LoginFailedPayload{Reason: "invalid_credentials"},
 	})
+	respondError(w, http.StatusUnauthorized, "invalid credentials")
+}
+
+// loginNamespace resolves the namespace of the session a login creates. The auth plane only issues
+// auth sessions; serving both planes, the caller chooses, and the admin console gets the default.
+func (h *Handler) loginNamespace(requested string) (string, error) {
+	switch requested {
+	case "":
+		if h.mode == "auth" {
+			return session.NamespaceAuth, nil
+		}
+		return session.NamespaceAdmin, nil
+	case session.NamespaceAuth:
+		return requested, nil
+	case session.NamespaceAdmin:
+		if h.mode == "auth" {
+			return "", errors.New("admin sessions are not issued by the auth plane")
+		}
+		return requested, nil
+	}
+	return "", fmt.Errorf("unsupported namespace %q: use auth or admin", requested)
 }
 
 // Logout handles user logout
@@ -439,7 +795,7 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 			Resource:  audit.ResourceSession,
 			IPAddress: getIPAddress(r),
 			UserAgent: r.UserAgent(),
-			Metadata:  map[string]any{"session_id": sess.ID},
+			Payload:   &audit.SessionPayload{SessionID: sess.ID},
 		})
 		h.sessionService.Destroy(r.Context(), sessionID)
 	}
@@ -451,6 +807,61 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// SessionRenewalResponse reports the lifetime left to a renewed console session
+type SessionRenewalResponse struct {
+	// ExpiresIn is the number of seconds until the session ends without further activity
+	ExpiresIn int64 `json:"expires_in" example:"1800"`
+	// IdleExpiresAt is when the session ends without further activity
+	IdleExpiresAt time.Time `json:"idle_expires_at"`
+	// ExpiresAt is the absolute expiry, which renewal never extends; the user must sign in again then
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RefreshSession renews the console session
+// @Summary Renew console session
+// @Description Extend the idle timeout of the current admin session (sliding expiry) for a console user still at work and return how long it has left. The absolute lifetime is never extended. A renewal from another address or user agent than the session last saw is audited. A session restricted to a password change is not renewed (403).
+// @Tags Auth
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {object} SessionRenewalResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /auth/refresh-session [post]
+func (h *Handler) RefreshSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := h.getSessionFromCookie(r)
+	if sessionID == "" {
+		respondError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	sess, err := h.sessionService.Get(r.Context(), sessionID)
+	if err != nil {
+		h.clearSessionCookie(w)
+		respondError(w, http.StatusUnauthorized, "invalid or expired session")
+		return
+	}
+	if sess.Namespace != session.NamespaceAdmin {
+		respondError(w, http.StatusForbidden, "only admin sessions are renewed; sign in with namespace \"admin\"")
+		return
+	}
+	// A session restricted to a password change is not kept alive either
+	if sess.PasswordChangeRequired {
+		respondError(w, http.StatusForbidden, passwordChangeMessage)
+		return
+	}
+
+	renewal, err := h.sessionService.Renew(r.Context(), sess, getIPAddress(r), r.UserAgent())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to renew session", logger.Error(err))
+		respondError(w, http.StatusInternalServerError, "failed to renew session")
+		return
+	}
+	respondJSON(w, http.StatusOK, SessionRenewalResponse{
+		ExpiresIn:     int64(time.Until(renewal.IdleExpiresAt).Seconds()),
+		IdleExpiresAt: renewal.IdleExpiresAt,
+		ExpiresAt:     renewal.ExpiresAt,
+	})
+}
+
 // GetCurrentUser returns the current user's information
 // @Summary Get current user
 // @Description Get the current authenticated user's information
@@ -459,7 +870,8 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]any
 // @Failure 401 {object} map[string]string
 // @Router /auth/me [get]
-// @Security SessionCookie
+// @Security CookieAuth
+// @Security BearerAuth
 func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// Tenant context is derived from session by AuthMiddleware
 	// See: docs/architecture/tenant-context-resolution.md
@@ -505,20 +917,22 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 			"email_verified": user.EmailVerified,
 			"profile":        user.Profile,
 		},
-		"role_assignments": assignments,
-		"current_tenant":   currentTenant, // null for platform admins
+		"role_assignments":         assignments,
+		"current_tenant":           currentTenant, // null for platform admins
+		"password_change_required": passwordChangeRequired(r.Context()),
 	})
 }
 
 // GetProfile returns the user's profile
 // @Summary Get user profile
-// @Description Get the current user's profile information
+// @Description Get the current user's profile information. The ETag response header carries the record version for If-Match on update.
 // @Tags User
 // @Produce json
 // @Success 200 {object} map[string]any
 // @Failure 401 {object} map[string]string
 // @Router /user/profile [get]
-// @Security SessionCookie
+// @Security CookieAuth
+// @Security BearerAuth
 func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	// Tenant context from session
 	userID := GetUserID(r.Context())
@@ -536,6 +950,7 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(user.Version))
 	respondJSON(w, http.StatusOK, map[string]any{
 		"user_id":        user.ID,
 		"email":          user.Email,
@@ -549,16 +964,20 @@ type UpdateProfileRequest = identity.Profile
 
 // UpdateProfile updates the user's profile
 // @Summary Update user profile
-// @Description Update the current user's profile information
+// @Description Update the current user's profile information. Picture and Website must be http(s) URLs, Locale a BCP 47 tag, Timezone an IANA time zone and Birthdate YYYY-MM-DD, 0000-MM-DD or YYYY; they are released as the picture, website, locale, zoneinfo and birthdate claims under the profile scope. Send the ETag from the last read in If-Match to avoid overwriting a concurrent change.
 // @Tags User
 // @Accept json
 // @Produce json
 // @Param request body UpdateProfileRequest true "Profile Data"
+// @Param If-Match header string false "ETag from GET /user/profile; the update fails with 412 if the profile has changed since"
 // @Success 200 {object} map[string]any
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
 // @Router /user/profile [put]
-// @Security SessionCookie
+// @Security CookieAuth
+// @Security BearerAuth
 func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	// Tenant context from session
 	userID := GetUserID(r.Context())
@@ -576,11 +995,28 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.identityService.UpdateProfile(r.Context(), userID, profile); err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to update profile")
+	version, preconditioned := ifMatchVersion(r)
+	user, err := h.identityService.UpdateProfile(r.Context(), userID, profile, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrUserVersionConflict):
+			respondVersionConflict(w, preconditioned)
+		case errors.Is(err, identity.ErrInvalidProfile):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update profile")
+		}
 		return
 	}
 
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     audit.TypeProfileUpdated,
+		TenantID: GetTenantID(r.Context()),
+		ActorID:  userID,
+		Resource: audit.ResourceUser,
+	})
+
+	w.Header().Set("ETag", versionETag(user.Version))
 	respondJSON(w, http.StatusOK, map[string]string{
 		"message": "profile updated successfully",
 	})
@@ -603,7 +1039,8 @@ type ChangePasswordRequest struct {
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /user/change-password [post]
-// @Security SessionCookie
+// @Security CookieAuth
+// @Security BearerAuth
 func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	// Tenant context from session
 	userID := GetUserID(r.Context())
@@ -624,13 +1061,15 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 
 	err = h.identityService.ChangePassword(r.Context(), userID, req.OldPassword, req.NewPassword)
 	if err != nil {
-		switch err {
-		case identity.ErrInvalidCredentials:
+		switch {
+		case errors.Is(err, identity.ErrInvalidCredentials):
 			respondError(w, http.StatusUnauthorized, "invalid old password")
-		case identity.ErrWeakPassword:
+		case errors.Is(err, identity.ErrWeakPassword):
 			respondError(w, http.StatusBadRequest, "new password does not meet security requirements")
+		case errors.Is(err, identity.ErrPasswordReused):
+			respondError(w, http.StatusBadRequest, "new password must differ from the current one")
 		default:
-			respondError(w, http.StatusInternalServerError, "failed to change password")
+			respondServiceError(w, r, err, "failed to change password")
 		}
 		return
 	}
@@ -644,11 +1083,35 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		UserAgent: r.UserAgent(),
 	})
 
+	// The session restricted to this change is replaced by one without the restriction
+	if passwordChangeRequired(r.Context()) && !h.rotatePasswordChangeSession(w, r) {
+		return
+	}
+
 	respondJSON(w, http.StatusOK, map[string]string{
 		"message": "password changed successfully",
 	})
 }
 
+// rotatePasswordChangeSession replaces the request's session, restricted to a password change, by
+// an unrestricted session of the same user, tenant and namespace
+func (h *Handler) rotatePasswordChangeSession(w http.ResponseWriter, r *http.Request) bool {
+	old, err := h.sessionService.Get(r.Context(), GetSessionID(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "invalid or expired session")
+		return false
+	}
+	sess, err := h.sessionService.Create(r.Context(), old.TenantID, old.UserID, getIPAddress(r), r.UserAgent(), old.Namespace)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to create session", logger.Error(err))
+		respondError(w, http.StatusInternalServerError, "failed to create session")
+		return false
+	}
+	_ = h.sessionService.Destroy(r.Context(), old.ID)
+	h.setSessionCookie(w, sess.ID)
+	return true
+}
+
 // Helper functions
 func (h *Handler) setSessionCookie(w http.ResponseWriter, sessionID string) {
 	http.SetCookie(w, &http.Cookie{
@@ -693,6 +1156,18 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	})
 }
 
+// respondServiceError translates a domain error by its apperr code. Errors without a code are
+// logged and answered with a 500 and the fallback message, so internal details stay out of the
+// response.
+func respondServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	if apperr.CodeOf(err) == apperr.CodeInternal {
+		slog.ErrorContext(r.Context(), fallback, logger.Error(err))
+		respondError(w, http.StatusInternalServerError, fallback)
+		return
+	}
+	respondError(w, apperr.HTTPStatus(err), apperr.Message(err))
+}
+
 func getIPAddress(r *http.Request) string {
 	// Check X-Forwarded-For header first
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
@@ -201,6 +201,47 @@ func TestSecurity_HealthCheck_ReturnsValidJSON(t *testing.T) {
 	assert.NotEmpty(t, resp.Status, "Health response should have status")
 }
 
+// TestPurpose: Validates that every response that can carry a token, code, secret or session is marked uncacheable.
+// Scope: Unit Test
+// Security: Tokens and credentials must not be stored by browsers or shared caches (RFC 6749 Section 5.1)
+// Expected: OAuth2 and /api/v1 responses, including errors, carry Cache-Control: no-store and Pragma: no-cache; public documents keep their own policy.
+// Test Case ID: SEC-11
+func TestSecurity_TokenBearingResponses_AreNotCacheable(t *testing.T) {
+	router := NewRouter(createMinimalHandler(t), nil, nil, "all")
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		noStore bool
+	}{
+		{"token error", http.MethodPost, "/oauth2/token", "grant_type=password", true},
+		{"authorize without session", http.MethodGet, "/oauth2/authorize?client_id=c1", "", true},
+		{"login without csrf token", http.MethodPost, "/api/v1/auth/login", "{}", true},
+		{"session check", http.MethodGet, "/api/v1/auth/me", "", true},
+		{"admin api", http.MethodGet, "/api/v1/tenants", "", true},
+		{"api description", http.MethodGet, "/openapi.json", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.method == http.MethodPost && strings.HasPrefix(tt.path, "/oauth2/") {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if !tt.noStore {
+				assert.NotContains(t, w.Header().Get("Cache-Control"), "no-store")
+				return
+			}
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"), "status %d", w.Code)
+			assert.Equal(t, "no-cache", w.Header().Get("Pragma"))
+		})
+	}
+}
+
 // =============================================================================
 // TENANT HANDLER INPUT VALIDATION TESTS
 // Category: Tenant API - Input Validation
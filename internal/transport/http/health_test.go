@@ -0,0 +1,83 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/jobs"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPurpose: Validates that background jobs are reported by the health check.
+// Scope: Unit Test
+// Security: Availability (a failing or stuck janitor is visible to operators and probes)
+// Expected: Each job appears under "job:<name>"; a failing job is "warn" with its error and the overall status is "warn" with HTTP 200.
+// Test Case ID: API-06
+func TestHealthCheck_ReportsJobs(t *testing.T) {
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	require.NoError(t, err)
+	runner, err := jobs.NewRunner(meter)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runner.Start(ctx, jobs.Job{Name: "ok", Interval: time.Millisecond, Run: func(ctx context.Context) (int64, error) {
+		return 3, nil
+	}})
+	runner.Start(ctx, jobs.Job{Name: "broken", Interval: time.Millisecond, Run: func(ctx context.Context) (int64, error) {
+		return 0, errors.New("database unavailable")
+	}})
+	require.Eventually(t, func() bool {
+		for _, s := range runner.Status() {
+			if s.Runs == 0 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+
+	h := createMinimalHandler(t)
+	h.jobs = runner
+	w := httptest.NewRecorder()
+	h.HealthCheck(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "warn", resp.Status)
+
+	require.Len(t, resp.Checks["job:ok"], 1)
+	ok := resp.Checks["job:ok"][0]
+	assert.Equal(t, "job", ok.ComponentType)
+	assert.Equal(t, "pass", ok.Status)
+	assert.Equal(t, int64(3), ok.LastProcessed)
+	assert.NotNil(t, ok.LastSuccess)
+
+	require.Len(t, resp.Checks["job:broken"], 1)
+	broken := resp.Checks["job:broken"][0]
+	assert.Equal(t, "warn", broken.Status)
+	assert.Equal(t, "database unavailable", broken.Output)
+	assert.Nil(t, broken.LastSuccess)
+	assert.Positive(t, broken.Failures)
+}
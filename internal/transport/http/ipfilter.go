@@ -0,0 +1,217 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// IP filter rules, as reported in ip_blocked audit events
+const (
+	IPRuleAllowlist       = "allowlist"
+	IPRuleDenylist        = "denylist"
+	IPRuleTenantAllowlist = "tenant_allowlist"
+)
+
+// IPFilterConfig restricts the client addresses that may use the admin plane.
+// Entries are CIDR prefixes or single addresses.
+type IPFilterConfig struct {
+	// Allow admits only matching clients; empty admits every client that is not denied
+	Allow []string
+	// Deny refuses matching clients, even when they are allowed
+	Deny []string
+	// TenantAllow further restricts the routes under /tenants/{tenantID} of a tenant
+	TenantAllow map[string][]string
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For entries are believed
+	TrustedProxies []string
+}
+
+// IPFilter admits or refuses admin plane requests by client address
+type IPFilter struct {
+	allow       []netip.Prefix
+	deny        []netip.Prefix
+	tenantAllow map[string][]netip.Prefix
+	trusted     []netip.Prefix
+}
+
+// NewIPFilter parses the configured prefixes
+func NewIPFilter(cfg IPFilterConfig) (*IPFilter, error) {
+	f := &IPFilter{tenantAllow: make(map[string][]netip.Prefix)}
+	var err error
+	if f.allow, err = parsePrefixes(cfg.Allow); err != nil {
+		return nil, err
+	}
+	if f.deny, err = parsePrefixes(cfg.Deny); err != nil {
+		return nil, err
+	}
+	if f.trusted, err = parsePrefixes(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+	for tenantID, entries := range cfg.TenantAllow {
+		if f.tenantAllow[tenantID], err = parsePrefixes(entries); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP resolves the address of the client. X-Forwarded-For is walked from the right only
+// while the previous hop is a trusted proxy, so clients cannot choose the address they appear from.
+func (f *IPFilter) ClientIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	addr = addr.Unmap()
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && containsAddr(f.trusted, addr); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+	}
+	return addr, true
+}
+
+// check returns the rule refusing addr, or "" when it is admitted
+func (f *IPFilter) check(addr netip.Addr, ok bool) string {
+	if !ok {
+		if len(f.allow) > 0 {
+			return IPRuleAllowlist
+		}
+		if len(f.deny) > 0 {
+			return IPRuleDenylist
+		}
+		return ""
+	}
+	if containsAddr(f.deny, addr) {
+		return IPRuleDenylist
+	}
+	if len(f.allow) > 0 && !containsAddr(f.allow, addr) {
+		return IPRuleAllowlist
+	}
+	return ""
+}
+
+// checkTenant returns IPRuleTenantAllowlist when the tenant restricts addresses and addr is not among them
+func (f *IPFilter) checkTenant(tenantID string, addr netip.Addr, ok bool) string {
+	allow, restricted := f.tenantAllow[tenantID]
+	if !restricted || (ok && containsAddr(allow, addr)) {
+		return ""
+	}
+	return IPRuleTenantAllowlist
+}
+
+// AdminIPMiddleware refuses admin plane requests from clients outside the allow and deny lists
+func (h *Handler) AdminIPMiddleware(next http.Handler) http.Handler {
+	if h.ipFilter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := h.ipFilter.ClientIP(r)
+		if rule := h.ipFilter.check(addr, ok); rule != "" {
+			h.refuseIP(w, r, "", addr, rule)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TenantIPMiddleware refuses requests to a tenant's routes from clients outside the tenant's allowlist
+func (h *Handler) TenantIPMiddleware(next http.Handler) http.Handler {
+	if h.ipFilter == nil || len(h.ipFilter.tenantAllow) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := chi.URLParam(r, "tenantID")
+		addr, ok := h.ipFilter.ClientIP(r)
+		if rule := h.ipFilter.checkTenant(tenantID, addr, ok); rule != "" {
+			h.refuseIP(w, r, tenantID, addr, rule)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) refuseIP(w http.ResponseWriter, r *http.Request, tenantID string, addr netip.Addr, rule string) {
+	clientIP := r.RemoteAddr
+	if addr.IsValid() {
+		clientIP = addr.String()
+	}
+	slog.WarnContext(r.Context(), "admin plane request blocked by ip filter",
+		"client_ip", clientIP,
+		"rule", rule,
+		"path", r.URL.Path,
+	)
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     audit.TypeIPBlocked,
+		TenantID: tenantID,
+		Resource: audit.ResourceAdminPlane,
+		Payload: &audit.IPBlockedPayload{
+			ClientIP: clientIP,
+			Rule:     rule,
+			Method:   r.Method,
+			Path:     r.URL.Path,
+		},
+	})
+	respondError(w, http.StatusForbidden, "access from this address is not allowed")
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+)
+
+// recordingAuditLogger keeps the events it is given
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (l *recordingAuditLogger) Log(ctx context.Context, event audit.Event) {
+	l.events = append(l.events, event)
+}
+
+func ipRequest(method, target, remoteAddr, forwardedFor string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	return req
+}
+
+// TestPurpose: Validates that the client address is only taken from X-Forwarded-For through trusted proxies.
+// Scope: Unit Test
+// Security: Spoofing (a client cannot pick the address the allowlist sees)
+// Expected: Untrusted peers are used as is; trusted proxies are skipped from the right.
+// Test Case ID: IPF-01
+func TestIPFilter_ClientIP(t *testing.T) {
+	f, err := NewIPFilter(IPFilterConfig{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, remoteAddr, forwardedFor, want string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"untrusted peer with header", "203.0.113.7:5000", "10.1.1.1", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:5000", "198.51.100.4", "198.51.100.4"},
+		{"spoofed leftmost entry", "10.0.0.2:5000", "10.9.9.9, 198.51.100.4", "198.51.100.4"},
+		{"proxy chain", "192.168.1.1:5000", "198.51.100.4, 10.0.0.3", "198.51.100.4"},
+		{"garbage entry", "10.0.0.2:5000", "198.51.100.4, not-an-ip", "10.0.0.2"},
+		{"ipv4-mapped peer", "[::ffff:203.0.113.7]:5000", "", "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, ok := f.ClientIP(ipRequest(http.MethodGet, "/", tt.remoteAddr, tt.forwardedFor))
+			if !ok || addr.String() != tt.want {
+				t.Errorf("expected %s, got %s (ok=%v)", tt.want, addr, ok)
+			}
+		})
+	}
+
+	if _, err := NewIPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+}
+
+// TestPurpose: Validates the admin plane allow and deny lists and the per-tenant allowlist.
+// Scope: Unit Test
+// Security: Network access control for the admin plane with audited refusals
+// Expected: Denied or unlisted clients get 403 and an ip_blocked event; auth plane routes are not filtered.
+// Test Case ID: IPF-02
+func TestIPFilter_Middleware(t *testing.T) {
+	f, err := NewIPFilter(IPFilterConfig{
+		Allow:          []string{"198.51.100.0/24", "2001:db8::/32"},
+		Deny:           []string{"198.51.100.66"},
+		TenantAllow:    map[string][]string{"t1": {"198.51.100.0/28"}},
+		TrustedProxies: []string{"10.0.0.1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &recordingAuditLogger{}
+	h := &Handler{ipFilter: f, auditLogger: logger}
+	router := NewRouter(h, nil, nil, "all")
+
+	tests := []struct {
+		name, method, target, remoteAddr, forwardedFor string
+		want                                           int
+	}{
+		{"allowed client reaches authentication", http.MethodGet, "/api/v1/tenants", "198.51.100.7:1", "", http.StatusUnauthorized},
+		{"allowed ipv6 client", http.MethodGet, "/api/v1/auth/me", "[2001:db8::1]:1", "", http.StatusUnauthorized},
+		{"unlisted client", http.MethodGet, "/api/v1/tenants", "203.0.113.7:1", "", http.StatusForbidden},
+		{"denied client", http.MethodDelete, "/api/v1/tenants/t1/webhooks/w1", "198.51.100.66:1", "", http.StatusForbidden},
+		{"allowed through trusted proxy", http.MethodGet, "/api/v1/tenants", "10.0.0.1:1", "198.51.100.7", http.StatusUnauthorized},
+		{"spoofed header from untrusted peer", http.MethodGet, "/api/v1/tenants", "203.0.113.7:1", "198.51.100.7", http.StatusForbidden},
+		{"auth plane is not filtered", http.MethodGet, "/health", "203.0.113.7:1", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, ipRequest(tt.method, tt.target, tt.remoteAddr, tt.forwardedFor))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if len(logger.events) != 3 {
+		t.Fatalf("expected 3 ip_blocked events, got %d", len(logger.events))
+	}
+	denied := logger.events[1]
+	payload, _ := denied.Payload.(*audit.IPBlockedPayload)
+	if denied.Type != audit.TypeIPBlocked || payload == nil || payload.Rule != IPRuleDenylist ||
+		payload.ClientIP != "198.51.100.66" || payload.Method != http.MethodDelete {
+		t.Errorf("unexpected event %+v", denied)
+	}
+
+	// Tenant allowlist on the routes of that tenant only
+	r := chi.NewRouter()
+	r.With(h.TenantIPMiddleware).Get("/tenants/{tenantID}", func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range []struct {
+		target, remoteAddr string
+		want               int
+	}{
+		{"/tenants/t1", "198.51.100.7:1", http.StatusOK},
+		{"/tenants/t1", "198.51.100.77:1", http.StatusForbidden},
+		{"/tenants/t2", "198.51.100.77:1", http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, ipRequest(http.MethodGet, tt.target, tt.remoteAddr, ""))
+		if w.Code != tt.want {
+			t.Errorf("%s from %s: expected %d, got %d", tt.target, tt.remoteAddr, tt.want, w.Code)
+		}
+	}
+	last := logger.events[len(logger.events)-1]
+	if payload, _ := last.Payload.(*audit.IPBlockedPayload); last.TenantID != "t1" || payload == nil || payload.Rule != IPRuleTenantAllowlist {
+		t.Errorf("unexpected tenant event %+v", last)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TenantMemberResponse is a member of a tenant
+type TenantMemberResponse struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email,omitempty"`
+	Home      bool      `json:"home"` // The user is registered in this tenant; false for users added from another tenant
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"` // Platform admin who added the member
+}
+
+// AddMemberRequest names the user to add to a tenant
+type AddMemberRequest struct {
+	UserID string `json:"user_id" binding:"required" example:"uuid"`
+}
+
+// ListTenantMembers lists the members of a tenant
+// @Summary List Tenant Members
+// @Description List the users who are members of a tenant: those registered in it and those added from other tenants. Only members can sign in to the tenant and be granted roles in it.
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 200 {array} TenantMemberResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/members [get]
+func (h *Handler) ListTenantMembers(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	// 1. Authorization Check: Tenant View Users permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantViewUsers)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant view access required")
+		return
+	}
+
+	members, err := h.memberships.ListMembers(r.Context(), tenantID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to list tenant members")
+		return
+	}
+
+	resp := make([]TenantMemberResponse, 0, len(members))
+	for _, m := range members {
+		member := TenantMemberResponse{
+			ID:        m.ID,
+			TenantID:  m.TenantID,
+			UserID:    m.UserID,
+			Home:      m.Home,
+			CreatedAt: m.CreatedAt,
+			CreatedBy: m.CreatedBy,
+		}
+		if u, err := h.identityService.GetUser(r.Context(), m.UserID); err == nil {
+			member.Email = u.Email
+		}
+		resp = append(resp, member)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// AddTenantMember adds a user registered in another tenant to a tenant
+// @Summary Add Tenant Member
+// @Description Make a user registered in another tenant a member of this tenant, so they can sign in to it and be granted roles in it (Platform Admin Only). The user is sent an invitation through the tenant's email sender. Platform users, who belong to no tenant, cannot be added.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body AddMemberRequest true "Member"
+// @Success 201 {object} TenantMemberResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "already a member, or a platform user"
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/members [post]
+func (h *Handler) AddTenantMember(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	// 1. Authorization: Platform Admin only, since the user comes from another tenant
+	actorID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), actorID, authz.ScopePlatform, nil, authz.PermPlatformManageTenants)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "platform administrative access required")
+		return
+	}
+
+	var req AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		respondError(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	// 2. The user is registered in another tenant, outside the scope of this request
+	user, err := h.identityService.GetUser(tenant.WithoutScope(r.Context()), req.UserID)
+	if errors.Is(err, identity.ErrUserNotFound) {
+		respondError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		respondServiceError(w, r, err, "failed to add tenant member")
+		return
+	}
+
+	// 3. Add the membership (audited by the membership service)
+	m, err := h.memberships.AddMember(r.Context(), tenantID, user.ID, accountTenant(user), actorID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to add tenant member")
+		return
+	}
+
+	// 4. Invite the user; the membership stands even if the message cannot be sent
+	h.sendInvitation(r.Context(), tenantID, user)
+
+	respondJSON(w, http.StatusCreated, TenantMemberResponse{
+		ID:        m.ID,
+		TenantID:  m.TenantID,
+		UserID:    m.UserID,
+		Email:     user.Email,
+		Home:      m.Home,
+		CreatedAt: m.CreatedAt,
+		CreatedBy: m.CreatedBy,
+	})
+}
+
+// RemoveTenantMember removes a member from a tenant
+// @Summary Remove Tenant Member
+// @Description Remove a user added from another tenant, revoking their roles in this tenant. Users cannot be removed from the tenant they are registered in, and the tenant's last owner cannot be removed; both are refused with 409.
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param userID path string true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/members/{userID} [delete]
+func (h *Handler) RemoveTenantMember(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	userID := chi.URLParam(r, "userID")
+
+	// 1. Authorization Check: Tenant Admin or Platform Admin required
+	actorID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), actorID, authz.ScopeTenant, &tenantID, authz.PermTenantManageUsers)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant administrative access required")
+		return
+	}
+
+	if err := h.memberships.RemoveMember(r.Context(), tenantID, userID, actorID); err != nil {
+		respondServiceError(w, r, err, "failed to remove tenant member")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "removed"})
+}
+
+// belongsToTenant reports whether a user may hold roles in and authorize the clients of a tenant.
+// Tenant users must be members of it; platform users belong to no tenant and are not limited to one.
+// Without a membership service every check passes.
+func (h *Handler) belongsToTenant(r *http.Request, tenantID, userID string) (bool, error) {
+	if h.memberships == nil {
+		return true, nil
+	}
+	user, err := h.identityService.GetUser(tenant.WithoutScope(r.Context()), userID)
+	if err != nil {
+		return false, err
+	}
+	if user.TenantID == nil {
+		return true, nil
+	}
+	return h.memberships.IsMember(r.Context(), tenantID, userID)
+}
+
+// sendInvitation tells a user added to a tenant that they can sign in to it, through the tenant's
+// email sender
+func (h *Handler) sendInvitation(ctx context.Context, tenantID string, user *identity.User) {
+	if h.emailService == nil {
+		return
+	}
+	name := tenantID
+	if t, err := h.tenantService.GetTenant(ctx, tenantID); err == nil {
+		name = t.Name
+	}
+	err := h.emailService.Send(ctx, tenantID, email.Message{
+		Kind:    email.KindInvitation,
+		To:      user.Email,
+		Subject: fmt.Sprintf("You have been added to %s", name),
+		TextBody: fmt.Sprintf("Your account %s is now a member of %s.\n\n", user.Email, name) +
+			"Sign in to it with your existing email address and password.\n",
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to send a tenant invitation",
+			logger.TenantID(tenantID),
+			logger.Error(err),
+		)
+	}
+}
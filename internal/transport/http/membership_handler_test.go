@@ -0,0 +1,266 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// outbox records the messages sent through it
+type outbox []email.Message
+
+func (b *outbox) Send(ctx context.Context, msg email.Message) error {
+	*b = append(*b, msg)
+	return nil
+}
+
+// membershipTestHandler returns a handler over a memory store with tenants t1 (Acme) and t2 (Globex),
+// and the outbox of its platform email sender
+func membershipTestHandler(t *testing.T) (*Handler, *memory.DB, *outbox) {
+	t.Helper()
+	ctx := context.Background()
+	db := memory.New()
+	tenants := memory.NewTenantRepository(db)
+	for _, tn := range []*tenant.Tenant{{ID: "t1", Name: "Acme", Status: tenant.StatusActive}, {ID: "t2", Name: "Globex", Status: tenant.StatusActive}} {
+		if err := tenants.Create(ctx, tn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assignments := memory.NewAssignmentRepository(db)
+	tenantSvc := tenant.NewService(tenants, memory.NewTenantRoleRepository(db), assignments, audit.NewSlogLogger())
+	sent := &outbox{}
+	emailSvc, err := email.NewService(memory.NewEmailSettingsRepository(db), sent, []byte("01234567890123456789012345678901"), audit.NewSlogLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Handler{
+		identityService: identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 10, time.Hour),
+		sessionService:  session.NewService(memory.NewSessionRepository(db), audit.NewSlogLogger(), 24*time.Hour, time.Hour),
+		authzService:    authz.NewService(memory.NewProjectRepository(db), memory.NewRoleRepository(db), assignments),
+		tenantService:   tenantSvc,
+		memberships:     tenant.NewMembershipService(memory.NewMembershipRepository(db), tenantSvc, audit.NewSlogLogger()),
+		emailService:    emailSvc,
+		auditLogger:     audit.NewSlogLogger(),
+		sessionConfig:   SessionConfig{CookieName: "session_id"},
+	}, db, sent
+}
+
+// memberRequest calls a membership handler for tenant t1 as actorID
+func memberRequest(h *Handler, fn http.HandlerFunc, method, actorID, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/tenants/t1/members", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenantID", "t1")
+	rctx.URLParams.Add("userID", userID)
+	req = req.WithContext(context.WithValue(context.WithValue(req.Context(), chi.RouteCtxKey, rctx), userIDKey, actorID))
+	w := httptest.NewRecorder()
+	fn(w, req)
+	return w
+}
+
+// TestPurpose: Validates adding, listing and removing tenant members, and that roles in a tenant are only granted to its members.
+// Scope: Unit Test
+// Security: Multi-tenant Data Separation (CWE-284) (an identity of another tenant only reaches a tenant it was made a member of)
+// Permissions: platform:manage_tenants, tenant:manage_users, tenant:view_users
+// Expected: Only a platform admin adds members, who are sent an invitation; duplicates and platform users are refused with 409 and unknown users with 404; the listing shows home and added members; a non-member cannot be granted a role; removing a member revokes their roles, while the home membership cannot be removed; a sole owner is reported by IsLastOwnerAnywhere until a member shares the ownership.
+// Test Case ID: TEN-12
+func TestTenant_Members(t *testing.T) {
+	ctx := context.Background()
+	h, db, sent := membershipTestHandler(t)
+
+	owner, err := h.identityService.ProvisionIdentity(ctx, "t1", "owner@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	guest, err := h.identityService.ProvisionIdentity(ctx, "t2", "guest@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outsider, err := h.identityService.ProvisionIdentity(ctx, "t2", "outsider@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform, err := h.identityService.ProvisionIdentity(ctx, "", "ops@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.NewAssignmentRepository(db).Grant(ctx, &authz.Assignment{ID: "p1", UserID: platform.ID, RoleID: rbac.RoleIDPlatformAdmin, Scope: authz.ScopePlatform}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.tenantService.AssignRole(ctx, "t1", owner.ID, tenant.RoleTenantAdmin, tenant.GranterSystem); err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(actorID, userID string) int {
+		return memberRequest(h, h.AddTenantMember, http.MethodPost, actorID, "", `{"user_id":"`+userID+`"}`).Code
+	}
+	if code := add(owner.ID, guest.ID); code != http.StatusForbidden {
+		t.Errorf("expected a tenant admin to be refused, got %d", code)
+	}
+	if code := add(platform.ID, guest.ID); code != http.StatusCreated {
+		t.Fatalf("expected the guest to be added, got %d", code)
+	}
+	if len(*sent) != 1 || (*sent)[0].Kind != email.KindInvitation || (*sent)[0].To != "guest@example.com" || !strings.Contains((*sent)[0].Subject, "Acme") {
+		t.Errorf("expected an invitation to Acme for the guest, got %+v", *sent)
+	}
+	if code := add(platform.ID, guest.ID); code != http.StatusConflict {
+		t.Errorf("expected a duplicate membership to be refused, got %d", code)
+	}
+	if code := add(platform.ID, owner.ID); code != http.StatusConflict {
+		t.Errorf("expected a home member to be refused, got %d", code)
+	}
+	if code := add(platform.ID, platform.ID); code != http.StatusConflict {
+		t.Errorf("expected a platform user to be refused, got %d", code)
+	}
+	if code := add(platform.ID, "missing"); code != http.StatusNotFound {
+		t.Errorf("expected an unknown user to be not found, got %d", code)
+	}
+
+	w := memberRequest(h, h.ListTenantMembers, http.MethodGet, owner.ID, "", "")
+	var members []TenantMemberResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &members); err != nil || w.Code != http.StatusOK || len(members) != 2 {
+		t.Fatalf("expected two members, got %d %s", w.Code, w.Body)
+	}
+	for _, m := range members {
+		if m.Home != (m.UserID == owner.ID) || (m.UserID == guest.ID && (m.Email != "guest@example.com" || m.CreatedBy != platform.ID)) {
+			t.Errorf("unexpected member %+v", m)
+		}
+	}
+
+	assign := func(userID string) int {
+		return memberRequest(h, h.AssignTenantRole, http.MethodPost, owner.ID, userID, `{"role":"tenant_member"}`).Code
+	}
+	if code := assign(outsider.ID); code != http.StatusConflict {
+		t.Errorf("expected a role for a non-member to be refused, got %d", code)
+	}
+	if code := assign(guest.ID); code != http.StatusOK {
+		t.Errorf("expected a role for a member to be granted, got %d", code)
+	}
+
+	if last, err := h.memberships.IsLastOwnerAnywhere(ctx, "t1", owner.ID); err != nil || !last {
+		t.Errorf("expected the owner to be the last owner of t1, got %v, %v", last, err)
+	}
+	if last, _ := h.memberships.IsLastOwnerAnywhere(ctx, "t2", guest.ID); last {
+		t.Error("expected a plain member not to be a last owner")
+	}
+	if err := h.tenantService.AssignRole(ctx, "t1", guest.ID, tenant.RoleTenantAdmin, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+	if last, _ := h.memberships.IsLastOwnerAnywhere(ctx, "t1", owner.ID); last {
+		t.Error("expected the owner not to be the last owner once a member shares the role")
+	}
+
+	if code := memberRequest(h, h.RemoveTenantMember, http.MethodDelete, owner.ID, owner.ID, "").Code; code != http.StatusConflict {
+		t.Errorf("expected the home membership to be kept, got %d", code)
+	}
+	if code := memberRequest(h, h.RemoveTenantMember, http.MethodDelete, owner.ID, guest.ID, "").Code; code != http.StatusOK {
+		t.Fatalf("expected the guest to be removed, got %d", code)
+	}
+	if roles, _ := h.tenantService.GetUserRoles(ctx, "t1", guest.ID); len(roles) != 0 {
+		t.Errorf("expected the removed member's roles to be revoked, got %+v", roles)
+	}
+	if code := memberRequest(h, h.RemoveTenantMember, http.MethodDelete, owner.ID, guest.ID, "").Code; code != http.StatusNotFound {
+		t.Errorf("expected a removed member to be not found, got %d", code)
+	}
+	if code := assign(guest.ID); code != http.StatusConflict {
+		t.Errorf("expected a role for a removed member to be refused, got %d", code)
+	}
+}
+
+// TestPurpose: Validates that a user who is a member of several tenants chooses the tenant of an admin session.
+// Scope: Unit Test
+// Security: Tenant membership disclosure (memberships are only listed once the password is verified) and cross-tenant login confusion
+// Expected: An admin login gets a 409 listing the memberships with tenant names; membership_id signs in to the chosen tenant, whose roles decide admin access; an unknown membership_id is a 400; an auth login needs no choice and is decided by the roles of the home tenant.
+// Test Case ID: LGN-10
+func TestAuth_Login_MembershipChooser(t *testing.T) {
+	ctx := context.Background()
+	h, _, _ := membershipTestHandler(t)
+
+	user, err := h.identityService.ProvisionIdentity(ctx, "t1", "shared@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.identityService.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	guest, err := h.memberships.AddMember(ctx, "t2", user.ID, "t1", "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.tenantService.AssignRole(ctx, "t2", user.ID, tenant.RoleTenantAdmin, tenant.GranterSystem); err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(req LoginRequest) (*httptest.ResponseRecorder, AccountChoiceResponse) {
+		req.Email, req.Password = "shared@example.com", "SecurePassword123"
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		h.Login(w, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(string(body))))
+		var resp AccountChoiceResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	w, choice := login(LoginRequest{Namespace: session.NamespaceAdmin})
+	if w.Code != http.StatusConflict || len(choice.Accounts) != 2 {
+		t.Fatalf("expected a choice between the two memberships, got %d %s", w.Code, w.Body)
+	}
+	if a := choice.Accounts[0]; a.TenantID != "t1" || a.TenantName != "Acme" || a.AccountID != user.ID {
+		t.Errorf("expected the home tenant first, got %+v", a)
+	}
+	if a := choice.Accounts[1]; a.TenantID != "t2" || a.TenantName != "Globex" || a.MembershipID != guest.ID {
+		t.Errorf("unexpected membership %+v", a)
+	}
+
+	// The user is an admin of t2 only
+	if w, _ := login(LoginRequest{Namespace: session.NamespaceAdmin, MembershipID: choice.Accounts[0].MembershipID}); w.Code != http.StatusForbidden {
+		t.Errorf("expected the home tenant to be refused without an admin role, got %d", w.Code)
+	}
+	w, _ = login(LoginRequest{Namespace: session.NamespaceAdmin, MembershipID: guest.ID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the chosen tenant to sign in, got %d %s", w.Code, w.Body)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a session cookie, got %d", len(cookies))
+	}
+	sess, err := h.sessionService.Get(ctx, cookies[0].Value)
+	if err != nil || sess.TenantID == nil || *sess.TenantID != "t2" {
+		t.Errorf("expected an admin session for t2, got %+v %v", sess, err)
+	}
+	if w, _ := login(LoginRequest{Namespace: session.NamespaceAdmin, MembershipID: "other"}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown membership to be refused, got %d", w.Code)
+	}
+
+	// Auth sessions are refused for lacking an admin role in the home tenant, without a choice
+	if w, _ := login(LoginRequest{Namespace: session.NamespaceAuth}); w.Code != http.StatusForbidden {
+		t.Errorf("expected an auth login to need no choice, got %d", w.Code)
+	}
+}
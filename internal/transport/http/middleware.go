@@ -16,12 +16,15 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/tenant"
 )
 
 // Platform Authorization Principles:
@@ -34,37 +37,41 @@ import (
 // - Empty/NULL tenant_id implying platform privileges
 // - Hardcoded role checks (use permission checks)
 
-// LoggingMiddleware logs HTTP requests
-func LoggingMiddleware() func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-
-			// Log request start
-			slog.InfoContext(r.Context(), "http_request_start",
-				logger.RequestID(middleware.GetReqID(r.Context())),
-				logger.Method(r.Method),
-				logger.Path(r.URL.Path),
-				logger.RemoteAddr(r.RemoteAddr),
-			)
-
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
-
-			defer func() {
-				slog.InfoContext(r.Context(), "http_request_end",
-					logger.RequestID(middleware.GetReqID(r.Context())),
-					logger.Method(r.Method),
-					logger.Path(r.URL.Path),
-					logger.RemoteAddr(r.RemoteAddr),
-					logger.UserAgent(r.UserAgent()),
-					logger.StatusCode(ww.Status()),
-					logger.Duration(time.Since(start).Milliseconds()),
-				)
-			}()
-
-			next.ServeHTTP(ww, r)
+// AuditContextMiddleware attaches the request ID, client IP and user agent to the request context
+// so that every audit event logged while serving the request, including events logged by the
+// services it calls, can be correlated with the request.
+func AuditContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithRequest(r.Context(), audit.Request{
+			ID:        middleware.GetReqID(r.Context()),
+			IPAddress: getIPAddress(r),
+			UserAgent: r.UserAgent(),
 		})
-	}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// PlatformScopeMiddleware starts every request as platform-wide work (tenant.WithoutScope): its
+// tenant is not known until the session, access token, client or route has been resolved, and
+// those lookups span every tenant. The middleware that resolves the tenant narrows the scope with
+// tenant.WithScope; a store query made from a context that lost the request's scope sees no
+// tenant rows on PostgreSQL.
+func PlatformScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(tenant.WithoutScope(r.Context())))
+	})
+}
+
+// NoStoreMiddleware marks every response as uncacheable (RFC 6749 Section 5.1, RFC 9111 Section 5.2.2.5).
+// It is mounted on every route group whose responses can carry tokens, authorization codes, client
+// secrets or session details, so a handler or error path cannot forget the headers and a shared cache
+// or the browser never keeps a copy. Pragma covers HTTP/1.0 caches.
+func NoStoreMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+		next.ServeHTTP(w, r)
+	})
 }
 
 // TenantMiddleware is a no-op passthrough in the control plane model.
@@ -94,11 +101,46 @@ func RequireTenant(next http.Handler) http.Handler {
 	})
 }
 
-// AuthMiddleware validates session and adds user_id to context
+// AuthMiddleware validates an admin-plane session and adds user_id to context
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
+	return h.SessionMiddleware(session.NamespaceAdmin)(next)
+}
+
+// SessionMiddleware validates a session of namespace and adds user_id to context. A valid session
+// of the other namespace is refused with 403, so a console session cannot authorize OAuth2 clients
+// and a session made for an authorization flow cannot use the admin API. A session restricted to a
+// password change only reaches passwordChangeRoutes; optional middleware treats it as no session.
+func (h *Handler) SessionMiddleware(namespace string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return h.sessionMiddleware(namespace, false, next)
+	}
+}
+
+// OptionalSessionMiddleware is SessionMiddleware for handlers that also serve unauthenticated
+// requests: without a valid session the request continues without user_id.
+func (h *Handler) OptionalSessionMiddleware(namespace string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return h.sessionMiddleware(namespace, true, next)
+	}
+}
+
+// passwordChangeRoutes are the only routes a session restricted to a password change may use
+var passwordChangeRoutes = map[string]bool{
+	"GET /api/v1/auth/me":               true,
+	"POST /api/v1/user/change-password": true,
+}
+
+// passwordChangeMessage refuses a request outside passwordChangeRoutes
+const passwordChangeMessage = "password change required: set a new password with /user/change-password"
+
+func (h *Handler) sessionMiddleware(namespace string, optional bool, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sessionID := h.getSessionFromCookie(r)
 		if sessionID == "" {
+			if optional {
+				next.ServeHTTP(w, r)
+				return
+			}
 			respondError(w, http.StatusUnauthorized, "not authenticated")
 			return
 		}
@@ -106,15 +148,30 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 		sess, err := h.sessionService.Get(r.Context(), sessionID)
 		if err != nil {
 			h.clearSessionCookie(w)
+			if optional {
+				next.ServeHTTP(w, r)
+				return
+			}
 			respondError(w, http.StatusUnauthorized, "invalid or expired session")
 			return
 		}
 
-		// Namespace Isolation (Hardening Step)
-		// admin-plane only accepts "admin" sessions.
-		// auth-plane only accepts "auth" or "admin" sessions (admin can log into auth flows).
-		if h.mode == "admin" && sess.Namespace != "admin" {
-			respondError(w, http.StatusForbidden, "invalid session namespace for admin plane")
+		// Namespace Isolation (Hardening Step): admin routes only accept "admin" sessions and the
+		// authorization endpoint only "auth" sessions, whatever mode the server runs in
+		if sess.Namespace != namespace {
+			slog.WarnContext(r.Context(), "session used outside its namespace",
+				"namespace", sess.Namespace, "required", namespace, "path", r.URL.Path)
+			respondError(w, http.StatusForbidden, fmt.Sprintf("invalid session namespace for %s plane: sign in with namespace %q", namespace, namespace))
+			return
+		}
+
+		// A session signed in with an expired or temporary password can only set a new one
+		if sess.PasswordChangeRequired && !passwordChangeRoutes[r.Method+" "+r.URL.Path] {
+			if optional {
+				next.ServeHTTP(w, r)
+				return
+			}
+			respondError(w, http.StatusForbidden, passwordChangeMessage)
 			return
 		}
 
@@ -138,6 +195,9 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 		// Add user_id to context
 		ctx := context.WithValue(r.Context(), userIDKey, sess.UserID)
 		ctx = context.WithValue(ctx, sessionIDKey, sess.ID)
+		if sess.PasswordChangeRequired {
+			ctx = context.WithValue(ctx, passwordChangeKey, true)
+		}
 
 		// Inject session tenant as authoritative tenant context
 		// Platform admins may have NULL tenant_id; tenant-scoped admins have a tenant_id.
@@ -147,6 +207,8 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 			sessionTenant = *sess.TenantID
 		}
 		ctx = context.WithValue(ctx, tenantIDKey, sessionTenant)
+		ctx = tenant.WithScope(ctx, sessionTenant)
+		annotateAccessLog(ctx, sessionTenant, sess.UserID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -156,6 +218,12 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 // We enforce a custom header 'X-CSRF-Token'.
 func (h *Handler) CSRFMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Browsers never attach an access token by themselves, so token requests cannot be forged
+		if getAccessToken(r.Context()) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Only enforce for state-changing methods
 		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions || r.Method == http.MethodTrace {
 			next.ServeHTTP(w, r)
@@ -16,22 +16,38 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/authz"
 	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
 )
 
 // RegisterClientRequest represents the data for registering a new OAuth2 client
 type RegisterClientRequest struct {
-	ClientName              string   `json:"client_name" binding:"required" example:"My Application"`
-	RedirectURIs            []string `json:"redirect_uris" binding:"required" example:"[\"http://localhost:3000/callback\"]"`
-	AllowedScopes           []string `json:"allowed_scopes" example:"[\"openid\", \"profile\"]"`
-	GrantTypes              []string `json:"grant_types" example:"[\"authorization_code\", \"refresh_token\"]"`
-	ResponseTypes           []string `json:"response_types" example:"[\"code\"]"`
-	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method" example:"client_secret_basic"`
+	ClientName                 string          `json:"client_name" binding:"required" example:"My Application"`
+	RedirectURIs               []string        `json:"redirect_uris" binding:"required" example:"[\"http://localhost:3000/callback\"]"`
+	AllowedScopes              []string        `json:"allowed_scopes" example:"[\"openid\", \"profile\"]"`
+	GrantTypes                 []string        `json:"grant_types" example:"[\"authorization_code\", \"refresh_token\"]"`
+	ResponseTypes              []string        `json:"response_types" example:"[\"code\"]"`
+	TokenEndpointAuthMethod    string          `json:"token_endpoint_auth_method" example:"client_secret_basic"`
+	JWKS                       json.RawMessage `json:"jwks,omitempty" swaggertype:"object"`
+	JWKSURI                    string          `json:"jwks_uri,omitempty" example:"https://app.example.com/jwks.json"` // Where the client publishes its public keys, instead of jwks
+	RequestURIs                []string        `json:"request_uris,omitempty" example:"[\"https://app.example.com/request.jwt\"]"`
+	RequireSignedRequestObject bool            `json:"require_signed_request_object,omitempty"` // Refuse authorization requests without a signed request object (RFC 9101)
+	// AllowedOrigins may call the token and revocation endpoints from a browser; empty uses the redirect URI origins
+	AllowedOrigins []string `json:"allowed_origins,omitempty" example:"[\"https://app.example.com\"]"`
+	// ClientGroup names related clients of the tenant, such as a mobile and a web app sharing a backend,
+	// that may request ID tokens for each other
+	ClientGroup string `json:"client_group,omitempty" example:"storefront"`
+	// ApplicationType is web, native, spa or service, and restricts the other metadata to what the type needs
+	ApplicationType string `json:"application_type,omitempty" example:"web" enums:"web,native,spa,service"`
+	// MinStateLength makes authorization requests carry a random state of at least this many characters
+	// (8 to 256); zero leaves state optional
+	MinStateLength int `json:"min_state_length,omitempty" example:"16"`
 }
 
 // RegisterClientResponse represents the response after registering a client
@@ -48,12 +64,13 @@ type RegisterClientResponse struct {
 // @Accept json
 // @Produce json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param tenantID path string true "Tenant ID"
 // @Param request body RegisterClientRequest true "Client Data"
 // @Success 201 {object} RegisterClientResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /tenants/{tenantID}/oauth2/clients [post]
+// @Router /tenants/{tenantID}/clients [post]
 func (h *Handler) RegisterClient(w http.ResponseWriter, r *http.Request) {
 	tenantID := GetTenantID(r.Context())
 
@@ -80,32 +97,41 @@ func (h *Handler) RegisterClient(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &oauth2.Client{
-		TenantID:                tenantID,
-		ClientName:              req.ClientName,
-		ClientSecretHash:        clientSecretHash,
-		RedirectURIs:            req.RedirectURIs,
-		AllowedScopes:           req.AllowedScopes,
-		GrantTypes:              req.GrantTypes,
-		ResponseTypes:           req.ResponseTypes,
-		TokenEndpointAuthMethod: req.TokenEndpointAuthMethod,
-		AccessTokenLifetime:     3600,
-		RefreshTokenLifetime:    2592000,
-		IDTokenLifetime:         3600,
-		IsActive:                true,
+		TenantID:                   tenantID,
+		ClientName:                 req.ClientName,
+		ClientSecretHash:           clientSecretHash,
+		RedirectURIs:               req.RedirectURIs,
+		AllowedScopes:              req.AllowedScopes,
+		GrantTypes:                 req.GrantTypes,
+		ResponseTypes:              req.ResponseTypes,
+		TokenEndpointAuthMethod:    req.TokenEndpointAuthMethod,
+		JWKS:                       req.JWKS,
+		JWKSURI:                    req.JWKSURI,
+		RequestURIs:                req.RequestURIs,
+		AllowedOrigins:             req.AllowedOrigins,
+		ClientGroup:                req.ClientGroup,
+		ApplicationType:            req.ApplicationType,
+		MinStateLength:             req.MinStateLength,
+		AccessTokenLifetime:        3600,
+		RefreshTokenLifetime:       2592000,
+		IDTokenLifetime:            3600,
+		IsActive:                   true,
+		RequireSignedRequestObject: req.RequireSignedRequestObject,
 	}
 
 	if len(client.AllowedScopes) == 0 {
 		client.AllowedScopes = []string{"openid"}
 	}
-	if len(client.GrantTypes) == 0 {
+	// Services only authenticate to the introspection endpoint; other clients default to the code flow
+	if len(client.GrantTypes) == 0 && client.ApplicationType != oauth2.ApplicationTypeService {
 		client.GrantTypes = []string{"authorization_code"}
 	}
-	if len(client.ResponseTypes) == 0 {
+	if len(client.ResponseTypes) == 0 && client.ApplicationType != oauth2.ApplicationTypeService {
 		client.ResponseTypes = []string{"code"}
 	}
 
 	if err := h.oauth2Service.CreateClient(r.Context(), client); err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to register client: "+err.Error())
+		respondServiceError(w, r, err, "failed to register client")
 		return
 	}
 
@@ -115,12 +141,13 @@ func (h *Handler) RegisterClient(w http.ResponseWriter, r *http.Request) {
 		TenantID: tenantID,
 		ActorID:  userID,
 		Resource: audit.ResourceClient,
-		Metadata: map[string]any{
-			"client_id":   client.ClientID,
-			"client_name": client.ClientName,
+		Payload: &audit.ClientPayload{
+			ClientID:   client.ClientID,
+			ClientName: client.ClientName,
 		},
 	})
 
+	designateSecretFields(r, "client_secret")
 	respondJSON(w, http.StatusCreated, RegisterClientResponse{
 		ClientID:     client.ClientID,
 		ClientSecret: clientSecret,
@@ -128,19 +155,31 @@ func (h *Handler) RegisterClient(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListClientsResponse is a page of OAuth2 clients
+type ListClientsResponse struct {
+	Clients    []*oauth2.Client `json:"clients"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
 // ListClients handles listing OAuth2 clients for a tenant
+// @Summary List Clients
+// @Description List a tenant's OAuth2 clients in creation order with cursor pagination
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Opaque cursor from a previous page's next_cursor"
+// @Success 200 {object} ListClientsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/clients [get]
 func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
 	tenantID := GetTenantID(r.Context())
 
-	clients, err := h.oauth2Service.ListClients(r.Context(), tenantID)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to list clients: "+err.Error())
-		return
-	}
-
-	// Permission check is already done by route wrapper in handlers.go if we moved it there,
-	// but currently handlers.go routes are under AuthMiddleware and TenantMiddleware.
-	// Actually, I should check permission here to be safe and specific.
+	// 1. Authorization Check: Client management permission required
 	userID := GetUserID(r.Context())
 	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageClients)
 	if err != nil || !allowed {
@@ -148,9 +187,25 @@ func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]any{
-		"clients": clients,
-		"total":   len(clients),
+	// 2. Parse pagination
+	page, err := pagination.Parse(r.URL.Query().Get("limit"), r.URL.Query().Get("cursor"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	clients, next, err := h.oauth2Service.ListClients(r.Context(), tenantID, page)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to list clients")
+		return
+	}
+	if clients == nil {
+		clients = []*oauth2.Client{}
+	}
+
+	respondJSON(w, http.StatusOK, ListClientsResponse{
+		Clients:    clients,
+		NextCursor: next,
 	})
 }
 
@@ -170,6 +225,7 @@ func (h *Handler) GetClient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(client.Version))
 	respondJSON(w, http.StatusOK, client)
 }
 
@@ -204,11 +260,11 @@ func (h *Handler) DeleteClient(w http.ResponseWriter, r *http.Request) {
 
 	if h.auditLogger != nil {
 		h.auditLogger.Log(r.Context(), audit.Event{
-			Type:     audit.TypeRoleRevoked, // Using generic revoked for now
+			Type:     audit.TypeClientDeleted,
 			TenantID: tenantID,
 			ActorID:  userID,
 			Resource: audit.ResourceClient,
-			Metadata: map[string]any{"client_id": clientID},
+			Payload:  &audit.ClientPayload{ClientID: clientID},
 		})
 	}
 
@@ -236,10 +292,19 @@ func (h *Handler) RegenerateClientSecret(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	version, preconditioned := ifMatchVersion(r)
+	if preconditioned {
+		client.Version = version
+	}
+
 	newSecret := oauth2.GenerateClientSecret()
 	client.ClientSecretHash = oauth2.HashClientSecret(newSecret)
 
 	if err := h.oauth2Service.UpdateClient(r.Context(), client); err != nil {
+		if errors.Is(err, oauth2.ErrClientVersionConflict) {
+			respondVersionConflict(w, preconditioned)
+			return
+		}
 		respondError(w, http.StatusInternalServerError, "failed to update client secret")
 		return
 	}
@@ -249,10 +314,144 @@ func (h *Handler) RegenerateClientSecret(w http.ResponseWriter, r *http.Request)
 		TenantID: tenantID,
 		ActorID:  GetUserID(r.Context()),
 		Resource: audit.ResourceClient,
-		Metadata: map[string]any{"client_id": clientID},
+		Payload:  &audit.ClientPayload{ClientID: clientID},
 	})
 
+	w.Header().Set("ETag", versionETag(client.Version))
+	designateSecretFields(r, "client_secret")
 	respondJSON(w, http.StatusOK, map[string]string{
 		"client_secret": newSecret,
 	})
 }
+
+// ClientKeysRequest replaces the public keys of a client: a JWKS, or the jwks_uri the client
+// publishes its keys at. Leaving both empty removes the keys.
+type ClientKeysRequest struct {
+	JWKS    json.RawMessage `json:"jwks,omitempty" swaggertype:"object"`
+	JWKSURI string          `json:"jwks_uri,omitempty" example:"https://app.example.com/jwks.json"`
+}
+
+// ClientKeysResponse represents the public keys of a client
+type ClientKeysResponse struct {
+	ClientID string          `json:"client_id"`
+	JWKS     json.RawMessage `json:"jwks,omitempty" swaggertype:"object"`
+	JWKSURI  string          `json:"jwks_uri,omitempty"`
+	KeyIDs   []string        `json:"key_ids,omitempty"` // Keys in use after an update; for a jwks_uri, as fetched then
+}
+
+// GetClientKeys handles retrieving the public keys of an OAuth2 client
+// @Summary Get Client Keys
+// @Description Get the JWKS or jwks_uri that verifies the client's request objects
+// @Tags OAuth2
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param clientID path string true "Client ID"
+// @Success 200 {object} ClientKeysResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/clients/{clientID}/jwks [get]
+func (h *Handler) GetClientKeys(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.managedClient(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(client.Version))
+	respondJSON(w, http.StatusOK, ClientKeysResponse{
+		ClientID: client.ClientID,
+		JWKS:     client.JWKS,
+		JWKSURI:  client.JWKSURI,
+	})
+}
+
+// UpdateClientKeys handles replacing the public keys of an OAuth2 client
+// @Summary Update Client Keys
+// @Description Upload or rotate the client's public keys with a jwks, or set the jwks_uri the client publishes them at. A jwks_uri is fetched at once and then refetched every 5 minutes, or sooner for an unknown kid. Keys must be public RSA (2048 to 8192 bits) or EC P-256 keys, at most 10 with distinct kid values. To rotate, publish the new key next to the old one until clients sign with it.
+// @Tags OAuth2
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param clientID path string true "Client ID"
+// @Param request body ClientKeysRequest true "Client Keys"
+// @Success 200 {object} ClientKeysResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /tenants/{tenantID}/clients/{clientID}/jwks [put]
+func (h *Handler) UpdateClientKeys(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.managedClient(w, r)
+	if !ok {
+		return
+	}
+
+	var req ClientKeysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if string(req.JWKS) == "null" {
+		req.JWKS = nil
+	}
+
+	version, preconditioned := ifMatchVersion(r)
+	if preconditioned {
+		client.Version = version
+	}
+
+	kids, err := h.oauth2Service.SetClientKeys(r.Context(), client, req.JWKS, req.JWKSURI)
+	if err != nil {
+		if errors.Is(err, oauth2.ErrClientVersionConflict) {
+			respondVersionConflict(w, preconditioned)
+			return
+		}
+		respondServiceError(w, r, err, "failed to update client keys")
+		return
+	}
+
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     audit.TypeClientKeysUpdated,
+		TenantID: client.TenantID,
+		ActorID:  GetUserID(r.Context()),
+		Resource: audit.ResourceClient,
+		Payload:  &audit.ClientKeysPayload{ClientID: client.ClientID, JWKSURI: client.JWKSURI, KeyIDs: kids},
+	})
+
+	w.Header().Set("ETag", versionETag(client.Version))
+	respondJSON(w, http.StatusOK, ClientKeysResponse{
+		ClientID: client.ClientID,
+		JWKS:     client.JWKS,
+		JWKSURI:  client.JWKSURI,
+		KeyIDs:   kids,
+	})
+}
+
+// managedClient loads the client named in the path for a caller allowed to manage the tenant's
+// clients, answering the request itself when it cannot
+func (h *Handler) managedClient(w http.ResponseWriter, r *http.Request) (*oauth2.Client, bool) {
+	tenantID := GetTenantID(r.Context())
+
+	// Authorization check
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageClients)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "client management access required")
+		return nil, false
+	}
+
+	client, err := h.oauth2Service.GetClient(r.Context(), chi.URLParam(r, "clientID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, "client not found")
+		return nil, false
+	}
+	if client.TenantID != tenantID {
+		respondError(w, http.StatusForbidden, "access denied")
+		return nil, false
+	}
+	return client, true
+}
@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/id"
 	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
 )
 
 // TestListClients_Integration tests the client listing with proper tenant scoping
@@ -22,39 +27,21 @@ func TestListClients_Integration(t *testing.T) {
 	defer os.Unsetenv("OPENID_KEY_ENCRYPTION_KEY")
 
 	// Setup repositories
-	mockClientRepo := &stubClientRepo{
-		clients: map[string]*oauth2.Client{
-			"c1": {ID: "c1", ClientName: "Client 1", TenantID: "t1", ClientID: "cid1"},
-			"c2": {ID: "c2", ClientName: "Client 2", TenantID: "t1", ClientID: "cid2"},
-			"c3": {ID: "c3", ClientName: "Client 3", TenantID: "t2", ClientID: "cid3"},
-		},
+	db := newClientTestStore(t)
+	mockClientRepo := memory.NewClientRepository(db)
+	for _, c := range []*oauth2.Client{
+		{ID: "c1", ClientName: "Client 1", TenantID: "t1", ClientID: "cid1"},
+		{ID: "c2", ClientName: "Client 2", TenantID: "t1", ClientID: "cid2"},
+		{ID: "c3", ClientName: "Client 3", TenantID: "t2", ClientID: "cid3"},
+	} {
+		if err := mockClientRepo.Create(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
 	}
 
 	// Create authorization service (we need the real one for this integration test)
 	// For a pure unit test, we'd mock it, but this validates the full flow
-	assignmentRepo := &stubAssignmentRepo{assignments: make(map[string]*authz.Assignment)}
-	roleRepo := &stubRoleRepo{roles: make(map[string]*authz.Role)}
-
-	// Create tenant admin role with manage_clients permission
-	adminRole := &authz.Role{
-		ID:          "admin-role",
-		Name:        "tenant_admin",
-		Scope:       authz.ScopeTenant,
-		Permissions: []string{authz.PermTenantManageClients},
-	}
-	roleRepo.roles["admin-role"] = adminRole
-
-	// Assign the role to user u1 for tenant t1
-	tenantID := "t1"
-	assignmentRepo.assignments["u1-admin-t1"] = &authz.Assignment{
-		ID:             "u1-admin-t1",
-		UserID:         "u1",
-		RoleID:         "admin-role",
-		Scope:          authz.ScopeTenant,
-		ScopeContextID: &tenantID,
-	}
-
-	authzSvc := authz.NewService(nil, roleRepo, assignmentRepo)
+	authzSvc := authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db))
 	oauth2Svc := oauth2.NewService(mockClientRepo, nil, nil, nil, audit.NewSlogLogger(), nil, 0, 0, 0)
 
 	h := &Handler{
@@ -101,35 +88,76 @@ func TestListClients_Integration(t *testing.T) {
 	}
 }
 
+// TestListClients_Pagination tests that client listing pages through all clients with next_cursor
+func TestListClients_Pagination(t *testing.T) {
+	t.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
+
+	db := newClientTestStore(t)
+	clientRepo := memory.NewClientRepository(db)
+	var want []string
+	for i := 0; i < 3; i++ {
+		c := &oauth2.Client{ID: id.NewUUIDv7(), ClientID: fmt.Sprintf("cid%d", i), TenantID: "t1"}
+		if err := clientRepo.Create(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, c.ID)
+	}
+
+	h := &Handler{
+		oauth2Service: oauth2.NewService(clientRepo, nil, nil, nil, audit.NewSlogLogger(), nil, 0, 0, 0),
+		authzService:  authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+		auditLogger:   audit.NewSlogLogger(),
+	}
+
+	var got []string
+	target := "/tenants/t1/clients?limit=2"
+	for page := 0; page < 3; page++ {
+		req := httptest.NewRequest("GET", target, nil)
+		ctx := context.WithValue(req.Context(), tenantIDKey, "t1")
+		ctx = context.WithValue(ctx, userIDKey, "u1")
+		w := httptest.NewRecorder()
+		h.ListClients(w, req.WithContext(ctx))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+		}
+
+		var resp ListClientsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range resp.Clients {
+			got = append(got, c.ID)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		target = "/tenants/t1/clients?limit=2&cursor=" + resp.NextCursor
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected clients %v in creation order, got %v", want, got)
+	}
+
+	// Malformed cursors are rejected
+	req := httptest.NewRequest("GET", "/tenants/t1/clients?cursor=bogus", nil)
+	ctx := context.WithValue(req.Context(), tenantIDKey, "t1")
+	ctx = context.WithValue(ctx, userIDKey, "u1")
+	w := httptest.NewRecorder()
+	h.ListClients(w, req.WithContext(ctx))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid cursor, got %d", w.Code)
+	}
+}
+
 // TestRegisterClient_Integration tests the client registration flow
 func TestRegisterClient_Integration(t *testing.T) {
 	// Set required encryption key for OAuth2 service
 	os.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
 	defer os.Unsetenv("OPENID_KEY_ENCRYPTION_KEY")
 
-	mockClientRepo := &stubClientRepo{clients: make(map[string]*oauth2.Client)}
+	db := newClientTestStore(t)
+	mockClientRepo := memory.NewClientRepository(db)
 
-	assignmentRepo := &stubAssignmentRepo{assignments: make(map[string]*authz.Assignment)}
-	roleRepo := &stubRoleRepo{roles: make(map[string]*authz.Role)}
-
-	adminRole := &authz.Role{
-		ID:          "admin-role",
-		Name:        "tenant_admin",
-		Scope:       authz.ScopeTenant,
-		Permissions: []string{authz.PermTenantManageClients},
-	}
-	roleRepo.roles["admin-role"] = adminRole
-
-	tenantID := "t1"
-	assignmentRepo.assignments["u1-admin-t1"] = &authz.Assignment{
-		ID:             "u1-admin-t1",
-		UserID:         "u1",
-		RoleID:         "admin-role",
-		Scope:          authz.ScopeTenant,
-		ScopeContextID: &tenantID,
-	}
-
-	authzSvc := authz.NewService(nil, roleRepo, assignmentRepo)
+	authzSvc := authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db))
 	oauth2Svc := oauth2.NewService(mockClientRepo, nil, nil, nil, audit.NewSlogLogger(), nil, 0, 0, 0)
 
 	h := &Handler{
@@ -170,34 +198,14 @@ func TestDeleteClient_Integration(t *testing.T) {
 	os.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
 	defer os.Unsetenv("OPENID_KEY_ENCRYPTION_KEY")
 
+	db := newClientTestStore(t)
+	mockClientRepo := memory.NewClientRepository(db)
 	client := &oauth2.Client{ID: "c1", ClientID: "cid1", TenantID: "t1", ClientName: "Test Client"}
-	mockClientRepo := &stubClientRepo{
-		clients: map[string]*oauth2.Client{
-			"c1": client,
-		},
-	}
-
-	assignmentRepo := &stubAssignmentRepo{assignments: make(map[string]*authz.Assignment)}
-	roleRepo := &stubRoleRepo{roles: make(map[string]*authz.Role)}
-
-	adminRole := &authz.Role{
-		ID:          "admin-role",
-		Name:        "tenant_admin",
-		Scope:       authz.ScopeTenant,
-		Permissions: []string{authz.PermTenantManageClients},
-	}
-	roleRepo.roles["admin-role"] = adminRole
-
-	tenantID := "t1"
-	assignmentRepo.assignments["u1-admin-t1"] = &authz.Assignment{
-		ID:             "u1-admin-t1",
-		UserID:         "u1",
-		RoleID:         "admin-role",
-		Scope:          authz.ScopeTenant,
-		ScopeContextID: &tenantID,
+	if err := mockClientRepo.Create(context.Background(), client); err != nil {
+		t.Fatal(err)
 	}
 
-	authzSvc := authz.NewService(nil, roleRepo, assignmentRepo)
+	authzSvc := authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db))
 	oauth2Svc := oauth2.NewService(mockClientRepo, nil, nil, nil, audit.NewSlogLogger(), nil, 0, 0, 0)
 
 	h := &Handler{
@@ -224,79 +232,75 @@ func TestDeleteClient_Integration(t *testing.T) {
 	}
 }
 
-// Stub repositories for testing
-type stubAssignmentRepo struct {
-	assignments map[string]*authz.Assignment
-}
+// TestRegenerateClientSecret_IfMatch tests that a secret rotation based on a stale read is rejected
+func TestRegenerateClientSecret_IfMatch(t *testing.T) {
+	os.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
+	defer os.Unsetenv("OPENID_KEY_ENCRYPTION_KEY")
 
-func (r *stubAssignmentRepo) Grant(a *authz.Assignment) error {
-	r.assignments[a.ID] = a
-	return nil
-}
+	db := newClientTestStore(t)
+	clientRepo := memory.NewClientRepository(db)
+	client := &oauth2.Client{ID: "c1", ClientID: "cid1", TenantID: "t1", ClientName: "Test Client", TokenEndpointAuthMethod: "client_secret_basic"}
+	if err := clientRepo.Create(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
 
-func (r *stubAssignmentRepo) Revoke(userID, roleID string, scope authz.Scope, scopeContextID *string) error {
-	return nil
-}
+	h := &Handler{
+		oauth2Service: oauth2.NewService(clientRepo, nil, nil, nil, audit.NewSlogLogger(), nil, 0, 0, 0),
+		auditLogger:   audit.NewSlogLogger(),
+	}
 
-func (r *stubAssignmentRepo) ListForUser(userID string) ([]*authz.Assignment, error) {
-	var result []*authz.Assignment
-	for _, a := range r.assignments {
-		if a.UserID == userID {
-			result = append(result, a)
+	do := func(method, path, ifMatch string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
 		}
+		ctx := context.WithValue(req.Context(), tenantIDKey, "t1")
+		ctx = context.WithValue(ctx, userIDKey, "u1")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("clientID", "c1")
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		w := httptest.NewRecorder()
+		handler(w, req.WithContext(ctx))
+		return w
 	}
-	return result, nil
-}
 
-func (r *stubAssignmentRepo) ListByRole(roleID string, scope authz.Scope, scopeContextID *string) ([]string, error) {
-	return nil, nil
-}
-
-func (r *stubAssignmentRepo) CheckExists(roleID string, scope authz.Scope, scopeContextID *string) (bool, error) {
-	return false, nil
-}
-
-type stubRoleRepo struct {
-	roles map[string]*authz.Role
-}
-
-func (r *stubRoleRepo) Create(role *authz.Role) error {
-	r.roles[role.ID] = role
-	return nil
-}
+	w := do("GET", "/tenants/t1/clients/c1", "", h.GetClient)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("expected 200 with ETag \"1\", got %d %q", w.Code, w.Header().Get("ETag"))
+	}
 
-func (r *stubRoleRepo) GetByID(id string) (*authz.Role, error) {
-	if role, ok := r.roles[id]; ok {
-		return role, nil
+	w = do("POST", "/tenants/t1/clients/c1/secret", `"1"`, h.RegenerateClientSecret)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected 200 with ETag \"2\", got %d %q body: %s", w.Code, w.Header().Get("ETag"), w.Body.String())
 	}
-	return nil, authz.ErrRoleNotFound
-}
 
-func (r *stubRoleRepo) GetByName(name string, scope authz.Scope) (*authz.Role, error) {
-	for _, role := range r.roles {
-		if role.Name == name && role.Scope == scope {
-			return role, nil
+	// The first rotation moved the client on; a second one based on the same read must not win
+	for _, stale := range []string{`"1"`, `W/"2"`, `bogus`} {
+		w = do("POST", "/tenants/t1/clients/c1/secret", stale, h.RegenerateClientSecret)
+		if w.Code != http.StatusPreconditionFailed {
+			t.Errorf("If-Match %s: expected 412, got %d", stale, w.Code)
 		}
 	}
-	return nil, authz.ErrRoleNotFound
-}
-
-func (r *stubRoleRepo) Update(role *authz.Role) error {
-	r.roles[role.ID] = role
-	return nil
-}
 
-func (r *stubRoleRepo) Delete(id string) error {
-	delete(r.roles, id)
-	return nil
+	// Without a precondition the rotation applies to the current version
+	w = do("POST", "/tenants/t1/clients/c1/secret", "", h.RegenerateClientSecret)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"3"` {
+		t.Errorf("expected 200 with ETag \"3\", got %d %q", w.Code, w.Header().Get("ETag"))
+	}
 }
 
-func (r *stubRoleRepo) List(scope *authz.Scope) ([]*authz.Role, error) {
-	var result []*authz.Role
-	for _, role := range r.roles {
-		if scope == nil || role.Scope == *scope {
-			result = append(result, role)
-		}
+// newClientTestStore returns an in-memory store where user u1 is tenant admin of t1
+func newClientTestStore(t *testing.T) *memory.DB {
+	t.Helper()
+	db := memory.New()
+	err := memory.NewTenantRoleRepository(db).AssignRole(context.Background(), &tenant.TenantUserRole{
+		ID:       "u1-admin-t1",
+		TenantID: "t1",
+		UserID:   "u1",
+		Role:     tenant.RoleTenantAdmin,
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
-	return result, nil
+	return db
 }
@@ -15,10 +15,15 @@
 package http
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/opentrusty/opentrusty/internal/apperr"
 	"github.com/opentrusty/opentrusty/internal/oauth2"
 	"github.com/opentrusty/opentrusty/internal/oidc"
 )
@@ -37,11 +42,40 @@ import (
 // @Param nonce query string false "Nonce (OIDC)"
 // @Param code_challenge query string false "PKCE Challenge"
 // @Param code_challenge_method query string false "PKCE Method (S256)"
+// @Param response_mode query string false "Response Mode (query or fragment)"
+// @Param prompt query string false "select_account to choose the account again at login"
+// @Param request query string false "Signed request object carrying the parameters (RFC 9101)"
+// @Param request_uri query string false "Registered URL of a signed request object (RFC 9101)"
+// @Param authorization_request query string false "Resumes a request made before signing in; replaces all other parameters"
 // @Success 302 {string} string "Redirects to callback or login"
+// @Failure 400 {object} oauth2.Error
+// @Failure 401 {object} map[string]string "authentication required; carries the authorization_request to resume after login"
 // @Router /oauth2/authorize [get]
 func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	query := r.URL.Query()
+	userID := GetUserID(r.Context())
+
+	// A request saved before the user signed in is resumed by its ID; it is only consumed once
+	// the user has a session, so an unauthenticated retry is sent to login again
+	var query url.Values
+	var err error
+	pendingID := r.URL.Query().Get("authorization_request")
+	if pendingID != "" {
+		if userID == "" {
+			h.requireLogin(w, r, pendingID)
+			return
+		}
+		query, err = h.oauth2Service.ResumePendingAuthorization(r.Context(), pendingID)
+	} else {
+		// Parse query parameters, or those of the request object (RFC 9101 Section 6.3)
+		query, err = h.oauth2Service.ResolveRequestObject(r.Context(), r.URL.Query())
+	}
+	if err != nil {
+		// The redirect_uri is not trusted until the request is verified, so errors are not redirected
+		slog.WarnContext(r.Context(), "invalid authorization request", "error", err, "client_id", r.URL.Query().Get("client_id"))
+		h.logProtocolDecision(r, oauth2.ProtocolDecision{Endpoint: "authorize", ClientID: r.URL.Query().Get("client_id")}, err)
+		h.respondOAuthError(w, err)
+		return
+	}
 	req := &oauth2.AuthorizeRequest{
 		ClientID:            query.Get("client_id"),
 		RedirectURI:         query.Get("redirect_uri"),
@@ -51,47 +85,67 @@ func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
 		Nonce:               query.Get("nonce"),
 		CodeChallenge:       query.Get("code_challenge"),
 		CodeChallengeMethod: query.Get("code_challenge_method"),
+		ResponseMode:        query.Get("response_mode"),
 	}
+	decision := oauth2.ProtocolDecision{Endpoint: "authorize", ClientID: req.ClientID, GrantType: req.ResponseType, Scope: req.Scope}
 
 	// Validate request parameters first
-	_, err := h.oauth2Service.ValidateAuthorizeRequest(r.Context(), req)
+	client, err := h.oauth2Service.ValidateAuthorizeRequest(r.Context(), req)
 	if err != nil {
 		slog.ErrorContext(r.Context(), "invalid authorize request",
 			"error", err,
 			"client_id", req.ClientID,
 			"redirect_uri", req.RedirectURI,
 		)
+		h.logProtocolDecision(r, decision, err)
 
-		// If redirect URI is valid, redirect back with error
-		// Otherwise, show error page (for now, JSON error)
-		if oe, ok := err.(*oauth2.Error); ok {
-			// If redirect_uri is invalid, we can't redirect
-			if oe.Code == oauth2.ErrInvalidRequest && strings.Contains(oe.Description, "redirect") {
-				h.respondOAuthError(w, err)
-				return
-			}
-
-			// Redirect back with error
-			redirectURL := addQueryParams(req.RedirectURI, map[string]string{
-				"error":             oe.Code,
-				"error_description": oe.Description,
-				"state":             req.State,
-			})
-			http.Redirect(w, r, redirectURL, http.StatusFound)
+		// Without a resolved client and a matched redirect_uri the error is shown, not redirected
+		// (RFC 6749 Section 4.1.2.1)
+		if client == nil {
+			h.respondOAuthError(w, err)
 			return
 		}
+		oe := oauth2.AsError(err)
+		h.redirectAuthorize(w, r, req, map[string]string{
+			"error":             oe.Code,
+			"error_description": oe.Description,
+			"state":             req.State,
+		})
+		return
+	}
 
-		h.respondOAuthError(w, err)
+	// prompt=select_account (OIDC Core Section 3.1.2.1) sends the user back to login to choose the
+	// account again, unless this is the request resumed after that login
+	selectAccount := pendingID == "" && slices.Contains(strings.Fields(query.Get("prompt")), "select_account")
+
+	// Keep the validated request while the user signs in; the login response leads back to it
+	if userID == "" || selectAccount {
+		pendingID, err := h.oauth2Service.SavePendingAuthorization(r.Context(), query)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to save authorization request", "error", err)
+			h.logProtocolDecision(r, decision, err)
+			h.respondOAuthError(w, err)
+			return
+		}
+		decision.Decision = oauth2.DecisionLoginRequired
+		h.logProtocolDecision(r, decision, nil)
+		h.requireLogin(w, r, pendingID)
 		return
 	}
 
-	// Check if user is authenticated
-	userID := GetUserID(r.Context())
-	if userID == "" {
-		// Store authorization request in session or cookie (simplified for now)
-		// Redirect to login page with return_to param
-		// For API-first approach, we return 401 and expect client to handle login
-		respondError(w, http.StatusUnauthorized, "authentication required")
+	// Tenant users may only authorize the clients of tenants they are members of
+	member, err := h.belongsToTenant(r, client.TenantID, userID)
+	if err == nil && !member {
+		err = oauth2.NewError(oauth2.ErrAccessDenied, "the user is not a member of the client's tenant")
+	}
+	if err != nil {
+		h.logProtocolDecision(r, decision, err)
+		oe := oauth2.AsError(err)
+		h.redirectAuthorize(w, r, req, map[string]string{
+			"error":             oe.Code,
+			"error_description": oe.Description,
+			"state":             req.State,
+		})
 		return
 	}
 
@@ -99,23 +153,56 @@ func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
 	// For now, auto-approve if user is authenticated
 
 	// Generate authorization code
-	code, err := h.oauth2Service.CreateAuthorizationCode(r.Context(), req, userID)
+	code, err := h.oauth2Service.CreateAuthorizationCode(r.Context(), req, userID, GetSessionID(r.Context()))
 	if err != nil {
 		slog.ErrorContext(r.Context(), "failed to create authorization code", "error", err)
-		redirectURL := addQueryParams(req.RedirectURI, map[string]string{
+		h.logProtocolDecision(r, decision, err)
+		h.redirectAuthorize(w, r, req, map[string]string{
 			"error": "server_error",
 			"state": req.State,
 		})
-		http.Redirect(w, r, redirectURL, http.StatusFound)
 		return
 	}
 
 	// Redirect back with code
-	redirectURL := addQueryParams(req.RedirectURI, map[string]string{
+	decision.Decision = oauth2.DecisionGranted
+	h.logProtocolDecision(r, decision, nil)
+	h.redirectAuthorize(w, r, req, map[string]string{
 		"code":  code.Code,
 		"state": req.State,
 	})
-	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// requireLogin answers an authorization request made without a session. A request saved as
+// pendingID is sent to the hosted login page when one is configured; otherwise the ID is returned
+// for the client to pass to login. Without pendingID the request must be sent again after login.
+func (h *Handler) requireLogin(w http.ResponseWriter, r *http.Request, pendingID string) {
+	if pendingID == "" {
+		respondError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if h.loginURL != "" {
+		if login, err := url.Parse(h.loginURL); err == nil {
+			q := login.Query()
+			q.Set("authorization_request", pendingID)
+			login.RawQuery = q.Encode()
+			http.Redirect(w, r, login.String(), http.StatusFound)
+			return
+		}
+	}
+	respondJSON(w, http.StatusUnauthorized, map[string]string{
+		"error":                 "authentication required",
+		"authorization_request": pendingID,
+	})
+}
+
+// resumeAuthorizationURL is where the browser resumes a pending authorization request after login
+func (h *Handler) resumeAuthorizationURL(pendingID string) string {
+	issuer := ""
+	if h.oidcService != nil {
+		issuer = h.oidcService.Issuer()
+	}
+	return issuer + "/oauth2/authorize?" + url.Values{"authorization_request": {pendingID}}.Encode()
 }
 
 // Token endpoint
@@ -132,6 +219,7 @@ func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
 // @Param code_verifier formData string false "PKCE Verifier"
 // @Param refresh_token formData string false "Refresh Token (for refresh_token grant)"
 // @Param scope formData string false "Scope"
+// @Param audience formData string false "Client of the same client group to also address the ID token to; may be repeated"
 // @Success 200 {object} oauth2.TokenResponse
 // @Failure 400 {object} oauth2.Error
 // @Failure 401 {object} oauth2.Error
@@ -142,18 +230,7 @@ func (h *Handler) Token(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract credentials
-	clientID := r.Form.Get("client_id")
-	clientSecret := r.Form.Get("client_secret")
-
-	// Support Basic Auth (RFC 6749 Section 2.3.1)
-	if clientID == "" {
-		username, password, ok := r.BasicAuth()
-		if ok {
-			clientID = username
-			clientSecret = password
-		}
-	}
+	clientID, clientSecret := clientCredentials(r)
 
 	req := &oauth2.TokenRequest{
 		GrantType:    r.Form.Get("grant_type"),
@@ -164,7 +241,9 @@ func (h *Handler) Token(w http.ResponseWriter, r *http.Request) {
 		CodeVerifier: r.Form.Get("code_verifier"), // RFC 7636 Section 4.5
 		RefreshToken: r.Form.Get("refresh_token"), // RFC 6749 Section 6
 		Scope:        r.Form.Get("scope"),
+		Audience:     r.Form["audience"],
 	}
+	decision := oauth2.ProtocolDecision{Endpoint: "token", ClientID: clientID, GrantType: req.GrantType, Scope: req.Scope}
 
 	var resp *oauth2.TokenResponse
 	var err error
@@ -177,20 +256,23 @@ func (h *Handler) Token(w http.ResponseWriter, r *http.Request) {
 		// RFC 6749 Section 6
 		resp, err = h.oauth2Service.RefreshAccessToken(r.Context(), req)
 	default:
-		h.respondOAuthError(w, oauth2.NewError(oauth2.ErrUnsupportedGrantType, "unsupported grant_type"))
+		err = oauth2.NewError(oauth2.ErrUnsupportedGrantType, "unsupported grant_type")
+		h.logProtocolDecision(r, decision, err)
+		h.respondOAuthError(w, err)
 		return
 	}
 
 	if err != nil {
 		slog.ErrorContext(r.Context(), "token request failed", "error", err, "grant_type", req.GrantType)
+		h.logProtocolDecision(r, decision, err)
 		h.respondOAuthError(w, err)
 		return
 	}
+	decision.Decision, decision.Scope = oauth2.DecisionGranted, resp.Scope
+	h.logProtocolDecision(r, decision, nil)
 
-	// Prevent caching (RFC 6749 Section 5.1)
-	w.Header().Set("Cache-Control", "no-store")
-	w.Header().Set("Pragma", "no-cache")
-
+	// Cache-Control: no-store (RFC 6749 Section 5.1) is set for the whole route group by NoStoreMiddleware
+	designateSecretFields(r, "access_token", "refresh_token", "id_token")
 	respondJSON(w, http.StatusOK, resp)
 }
 
@@ -212,17 +294,7 @@ func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	clientID := r.Form.Get("client_id")
-	clientSecret := r.Form.Get("client_secret")
-
-	// Support Basic Auth
-	if clientID == "" {
-		username, password, ok := r.BasicAuth()
-		if ok {
-			clientID = username
-			clientSecret = password
-		}
-	}
+	clientID, clientSecret := clientCredentials(r)
 
 	token := r.Form.Get("token")
 	if token == "" {
@@ -230,8 +302,8 @@ func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate client first
-	_, err := h.oauth2Service.ValidateClientCredentials(clientID, clientSecret)
+	// Validate client first; its tenant scopes the token lookup
+	client, err := h.oauth2Service.ValidateClientCredentials(r.Context(), clientID, clientSecret)
 	if err != nil {
 		h.respondOAuthError(w, err)
 		return
@@ -239,49 +311,148 @@ func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
 
 	// Revoke (we only support refresh tokens for now in Phase I.2, but should handle access tokens too if hash is stored)
 	// For now, we attempt to revoke it as a refresh token
-	_ = h.oauth2Service.RevokeRefreshToken(r.Context(), token, clientID)
+	_ = h.oauth2Service.RevokeRefreshToken(r.Context(), client.TenantID, token, clientID)
 
 	// RFC 7009 Section 2.2: The authorization server responds with an HTTP 200 OK
 	// regardless of whether the token was already revoked or the token was invalid.
 	w.WriteHeader(http.StatusOK)
 }
 
-// Helper to add query params to URL
-func addQueryParams(rawURL string, params map[string]string) string {
-	if strings.Contains(rawURL, "?") {
-		rawURL += "&"
-	} else {
-		rawURL += "?"
+// Introspect handles the token introspection request (RFC 7662)
+// @Summary Introspect Token
+// @Description Report whether an access token of the calling client's tenant is active (RFC 7662). The caller authenticates as a confidential client with its secret, typically the resource server; public clients are refused with invalid_client.
+// @Tags OAuth2
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Access token to introspect"
+// @Param token_type_hint formData string false "Token type hint (only access_token is supported)"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client Secret"
+// @Success 200 {object} oauth2.IntrospectionResponse
+// @Failure 400 {object} oauth2.Error
+// @Failure 401 {object} oauth2.Error
+// @Router /oauth2/introspect [post]
+func (h *Handler) Introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.respondOAuthError(w, oauth2.NewError(oauth2.ErrInvalidRequest, "invalid request"))
+		return
 	}
 
-	var parts []string
-	for k, v := range params {
-		parts = append(parts, k+"="+v)
+	clientID, clientSecret := clientCredentials(r)
+
+	token := r.Form.Get("token")
+	if token == "" {
+		h.respondOAuthError(w, oauth2.NewError(oauth2.ErrInvalidRequest, "missing token"))
+		return
 	}
 
-	return rawURL + strings.Join(parts, "&")
+	// RFC 7662 Section 2.1: the endpoint requires authorization; the client's tenant scopes the lookup.
+	// A public client has no secret and its client_id is in every authorization URL, so it cannot
+	// authorize the caller.
+	client, err := h.oauth2Service.ValidateClientCredentials(r.Context(), clientID, clientSecret)
+	if err != nil {
+		h.respondOAuthError(w, err)
+		return
+	}
+	if client.ClientSecretHash == "" {
+		h.respondOAuthError(w, oauth2.NewError(oauth2.ErrInvalidClient, "introspection requires a confidential client"))
+		return
+	}
+
+	// Refresh tokens are never presented to resource servers, so only access tokens are looked up
+	// whatever token_type_hint says (RFC 7662 Section 2.1 lets the server ignore it)
+	resp, at := h.oauth2Service.IntrospectAccessToken(r.Context(), client.TenantID, token)
+	if at != nil {
+		resp.Subject = oidc.Subject(at.TenantID, at.UserID)
+		resp.Issuer = h.oidcService.Issuer()
+	}
+	respondJSON(w, http.StatusOK, resp)
 }
 
-// respondOAuthError serializes a protocol error into HTTP response.
-// Fix for B-PROTOCOL-01 (Rule 2 - Domain-Driven Translation)
-func (h *Handler) respondOAuthError(w http.ResponseWriter, err error) {
-	if oauthErr, ok := err.(*oauth2.Error); ok {
-		status := http.StatusBadRequest
-		if oauthErr.Code == oauth2.ErrInvalidClient {
-			status = http.StatusUnauthorized
+// clientCredentials returns the client credentials of a token endpoint request, from the form or
+// from HTTP Basic authentication (RFC 6749 Section 2.3.1)
+func clientCredentials(r *http.Request) (clientID, clientSecret string) {
+	clientID = r.Form.Get("client_id")
+	clientSecret = r.Form.Get("client_secret")
+	if clientID == "" {
+		if username, password, ok := r.BasicAuth(); ok {
+			clientID = username
+			clientSecret = password
 		}
-		if oauthErr.Code == oauth2.ErrServerError {
-			status = http.StatusInternalServerError
+	}
+	return clientID, clientSecret
+}
+
+// redirectAuthorize sends the authorization response back to the client's redirect URI
+func (h *Handler) redirectAuthorize(w http.ResponseWriter, r *http.Request, req *oauth2.AuthorizeRequest, params map[string]string) {
+	redirectURL, err := authorizeRedirectURL(req.RedirectURI, req.ResponseMode, params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to build authorization redirect", "error", err, "redirect_uri", req.RedirectURI)
+		h.respondOAuthError(w, oauth2.NewError(oauth2.ErrServerError, "invalid redirect_uri"))
+		return
+	}
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// authorizeRedirectURL builds the authorization response URI (RFC 6749 Section 4.1.2).
+// Parameters are form-encoded and added to the query the redirect URI already has (RFC 6749 Section 3.1.2),
+// or to the fragment for response_mode=fragment. Any fragment of the redirect URI is dropped and
+// empty values are omitted.
+func authorizeRedirectURL(redirectURI, responseMode string, params map[string]string) (string, error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", err
+	}
+	u.Fragment, u.RawFragment = "", ""
+
+	values := url.Values{}
+	for k, v := range params {
+		if v != "" {
+			values.Set(k, v)
 		}
-		respondJSON(w, status, oauthErr)
+	}
+	encoded := values.Encode()
+
+	if responseMode == oauth2.ResponseModeFragment {
+		return u.String() + "#" + encoded, nil
+	}
+	if u.RawQuery != "" {
+		encoded = u.RawQuery + "&" + encoded
+	}
+	u.RawQuery = encoded
+	return u.String(), nil
+}
+
+// logProtocolDecision logs an authorization or token decision for tenants that turned protocol
+// logging on, correlated with the request ID. A request that failed with err is logged as denied
+// with its error code.
+func (h *Handler) logProtocolDecision(r *http.Request, d oauth2.ProtocolDecision, err error) {
+	if h.oauth2Service == nil {
 		return
 	}
+	d.RequestID = middleware.GetReqID(r.Context())
+	if err != nil {
+		d.Decision = oauth2.DecisionDenied
+		var oidcErr *oidc.Error
+		if errors.As(err, &oidcErr) {
+			d.ErrorCode = oidcErr.Code
+		} else {
+			d.ErrorCode = oauth2.AsError(err).Code
+		}
+	}
+	h.oauth2Service.LogProtocolDecision(r.Context(), d)
+}
 
-	if oidcErr, ok := err.(*oidc.Error); ok {
-		respondJSON(w, http.StatusBadRequest, oidcErr)
+// respondOAuthError serializes a protocol error into HTTP response.
+// Fix for B-PROTOCOL-01 (Rule 2 - Domain-Driven Translation)
+func (h *Handler) respondOAuthError(w http.ResponseWriter, err error) {
+	var oidcErr *oidc.Error
+	if errors.As(err, &oidcErr) {
+		respondJSON(w, apperr.HTTPStatus(oidcErr), oidcErr)
 		return
 	}
 
-	// Fallback for internal errors (opaque)
-	respondJSON(w, http.StatusInternalServerError, oauth2.NewError(oauth2.ErrServerError, "internal server error"))
+	// Domain errors are translated by their code; errors without one stay opaque
+	oauthErr := oauth2.AsError(err)
+	respondJSON(w, apperr.HTTPStatus(oauthErr), oauthErr)
 }
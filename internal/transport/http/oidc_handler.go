@@ -16,31 +16,56 @@ package http
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/opentrusty/opentrusty/internal/oidc"
 )
 
+// Discovery and JWKS responses may be cached by clients and proxies for these periods.
+// JWKS is kept short so relying parties pick up a rotated key soon after the rotation.
+const (
+	discoveryMaxAge = time.Hour
+	jwksMaxAge      = 10 * time.Minute
+)
+
 // Discovery returns the OpenID Connect metadata (OIDC Discovery Section 4)
 // @Summary OIDC Discovery
 // @Description Returns OpenID Connect configuration metadata
 // @Tags OIDC
 // @Produce json
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} oidc.DiscoveryMetadata
+// @Success 304
 // @Router /.well-known/openid-configuration [get]
 func (h *Handler) Discovery(w http.ResponseWriter, r *http.Request) {
-	// For Phase II.2, we assume h.oauth2Service has an oidcProvider that implements a new interface
-	// or we add oidcService directly to the handler if needed.
-	// But according to our architecture, h.oauth2Service.OIDCHook could be used.
-	// However, for clean separation, we'll cast it or handle it appropriately.
-
-	// Better yet, we can add oidcService to the Handler struct.
-	// Since OIDC logic should stay in internal/oidc, we'll use a specific service field.
-
-	var metadata oidc.DiscoveryMetadata = h.oidcService.GetDiscoveryMetadata()
-
 	// OIDC Discovery Section 4.2: Content-Type MUST be application/json
-	w.Header().Set("Content-Type", "application/json")
-	respondJSON(w, http.StatusOK, metadata)
+	doc, err := h.oidcService.DiscoveryDocument()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to build discovery metadata")
+		return
+	}
+	serveDocument(w, r, doc, discoveryMaxAge)
+}
+
+// AuthorizationServerMetadata returns the OAuth 2.0 authorization server metadata (RFC 8414)
+// @Summary OAuth 2.0 Authorization Server Metadata
+// @Description Returns the OAuth 2.0 authorization server metadata, with the grant types, client authentication methods and PKCE methods the server accepts
+// @Tags OIDC
+// @Produce json
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} oidc.AuthorizationServerMetadata
+// @Success 304
+// @Router /.well-known/oauth-authorization-server [get]
+func (h *Handler) AuthorizationServerMetadata(w http.ResponseWriter, r *http.Request) {
+	// RFC 8414 Section 3.2: the response is a JSON object with Content-Type application/json
+	doc, err := h.oidcService.AuthorizationServerDocument()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to build authorization server metadata")
+		return
+	}
+	serveDocument(w, r, doc, discoveryMaxAge)
 }
 
 // JWKS returns the JSON Web Key Set (RFC 7517)
@@ -48,13 +73,42 @@ func (h *Handler) Discovery(w http.ResponseWriter, r *http.Request) {
 // @Description Returns the JSON Web Key Set for verify signing
 // @Tags OIDC
 // @Produce json
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} oidc.JWKS
+// @Success 304
 // @Router /jwks.json [get]
 func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
-	var jwks oidc.JWKS = h.oidcService.GetJWKS()
-
 	// RFC 7517 Section 8.1: Content-Type SHOULD be application/jwk-set+json
 	// but OIDC clients often expect application/json.
+	doc, err := h.oidcService.JWKSDocument()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to build key set")
+		return
+	}
+	serveDocument(w, r, doc, jwksMaxAge)
+}
+
+// serveDocument writes a serialized JSON document with caching headers,
+// answering 304 Not Modified when If-None-Match names its ETag (RFC 9110 Section 13.1.2)
+func serveDocument(w http.ResponseWriter, r *http.Request, doc *oidc.Document, maxAge time.Duration) {
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	w.Header().Set("ETag", doc.ETag)
+	if etagMatches(r.Header.Get("If-None-Match"), doc.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	respondJSON(w, http.StatusOK, jwks)
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc.Body)
+}
+
+// etagMatches applies the weak comparison If-None-Match uses to a comma-separated list of entity tags
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,153 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate go run ../../../cmd/openapi-gen -out openapi.json -ts ../../../clients/typescript/src/index.ts
+
+package http
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/opentrusty/opentrusty/internal/openapi"
+)
+
+// openAPIDocument is generated from the handler annotations by cmd/openapi-gen.
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// AdminClient configures the TypeScript admin API client that cmd/openapi-gen
+// generates from the document into clients/typescript.
+var AdminClient = openapi.TypeScriptConfig{PathPrefix: "/api/", ClassName: "AdminClient"}
+
+//go:embed apidocs
+var apiExplorerFS embed.FS
+
+// APIDocsConfig controls the API description served by the router
+type APIDocsConfig struct {
+	// Version replaces info.version in the served document
+	Version string
+	// Explorer serves the interactive API explorer at /docs/
+	Explorer bool
+}
+
+// Routes returns every route NewRouter mounts in "all" mode as "METHOD /pattern".
+// cmd/openapi-gen checks the handler annotations against it.
+func Routes() []string {
+	var routes []string
+	_ = chi.Walk(NewRouter(&Handler{}, nil, nil, "all"), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+	return routes
+}
+
+// mountAPIDocs serves the OpenAPI document at /openapi.json and, if enabled,
+// the API explorer. The document only describes the operations mounted on r,
+// so an auth-plane server does not advertise admin endpoints and vice versa.
+func (h *Handler) mountAPIDocs(r *chi.Mux) {
+	document := sync.OnceValues(func() ([]byte, error) {
+		return servedDocument(r, h.apiDocs.Version)
+	})
+	r.Get("/openapi.json", func(w http.ResponseWriter, req *http.Request) {
+		doc, err := document()
+		if err != nil {
+			slog.ErrorContext(req.Context(), "failed to build OpenAPI document", "error", err)
+			respondError(w, http.StatusInternalServerError, "API description unavailable")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(doc)
+	})
+
+	if !h.apiDocs.Explorer {
+		return
+	}
+	assets, err := fs.Sub(apiExplorerFS, "apidocs")
+	if err != nil {
+		panic(err) // the embedded directory always exists
+	}
+	r.Get("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently).ServeHTTP)
+	r.With(explorerHeaders).Handle("/docs/*", http.StripPrefix("/docs/", http.FileServerFS(assets)))
+}
+
+// explorerHeaders restricts the explorer page to its own assets and origin.
+func explorerHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// servedDocument returns the embedded document reduced to the operations
+// mounted on r, with the given version and a same-origin server.
+func servedDocument(r chi.Routes, version string) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(openAPIDocument, &doc); err != nil {
+		return nil, err
+	}
+	paths, ok := doc["paths"].(map[string]any)
+	if !ok {
+		return nil, errors.New("openapi.json has no paths")
+	}
+
+	mounted := make(map[string]bool)
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		mounted[strings.ToLower(method)+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for p, item := range paths {
+		ops, _ := item.(map[string]any)
+		for method := range ops {
+			if !mounted[method+" "+p] {
+				delete(ops, method)
+			}
+		}
+		if len(ops) == 0 {
+			delete(paths, p)
+		}
+	}
+
+	if info, ok := doc["info"].(map[string]any); ok && version != "" {
+		info["version"] = version
+	}
+	doc["servers"] = []any{map[string]any{"url": "/"}}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty/internal/openapi"
+)
+
+// TestPurpose: Validates that the committed OpenAPI document matches the handler annotations.
+// Scope: Unit Test
+// Security: The published API description must not drift from the routes the server exposes
+// Expected: Regenerating openapi.json yields the embedded document byte for byte, and the TypeScript admin client generated from it matches the committed one.
+// Test Case ID: API-03
+func TestOpenAPIDocument_UpToDate(t *testing.T) {
+	doc, err := openapi.Generate(openapi.Config{Dir: ".", Routes: Routes()})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !bytes.Equal(doc, openAPIDocument) {
+		t.Error("openapi.json is out of date; run go generate ./internal/transport/http")
+	}
+
+	client, err := openapi.TypeScript(openAPIDocument, AdminClient)
+	if err != nil {
+		t.Fatalf("TypeScript: %v", err)
+	}
+	committed, err := os.ReadFile("../../../clients/typescript/src/index.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(client, committed) {
+		t.Error("clients/typescript/src/index.ts is out of date; run go generate ./internal/transport/http")
+	}
+}
+
+// TestPurpose: Validates the OpenAPI document and explorer served by the router.
+// Scope: Unit Test
+// Security: Each plane only advertises its own endpoints; the explorer is opt-in and restricted by CSP
+// Expected: /openapi.json lists the mounted operations with the configured version; /docs/ is served only when enabled.
+// Test Case ID: API-04
+func TestOpenAPIDocument_Served(t *testing.T) {
+	get := func(r http.Handler, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	paths := func(t *testing.T, w *httptest.ResponseRecorder) (map[string]map[string]any, string) {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var doc struct {
+			Info  struct{ Version string }
+			Paths map[string]map[string]any
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc.Paths, doc.Info.Version
+	}
+
+	auth, version := paths(t, get(NewRouter(&Handler{apiDocs: APIDocsConfig{Version: "v1.2.3"}}, nil, nil, "auth"), "/openapi.json"))
+	if version != "v1.2.3" {
+		t.Errorf("expected served version v1.2.3, got %q", version)
+	}
+	if _, ok := auth["/oauth2/token"]["post"]; !ok {
+		t.Error("expected auth plane to describe /oauth2/token")
+	}
+	if _, ok := auth["/api/v1/tenants"]; ok {
+		t.Error("auth plane must not describe admin endpoints")
+	}
+
+	admin, _ := paths(t, get(NewRouter(&Handler{}, nil, nil, "admin"), "/openapi.json"))
+	if _, ok := admin["/api/v1/tenants/{tenantID}/webhooks"]["post"]; !ok {
+		t.Error("expected admin plane to describe webhook creation")
+	}
+	if _, ok := admin["/oauth2/token"]; ok {
+		t.Error("admin plane must not describe OAuth2 endpoints")
+	}
+
+	if w := get(NewRouter(&Handler{}, nil, nil, "all"), "/docs/"); w.Code != http.StatusNotFound {
+		t.Errorf("expected explorer to be disabled by default, got %d", w.Code)
+	}
+	explorer := NewRouter(&Handler{apiDocs: APIDocsConfig{Explorer: true}}, nil, nil, "all")
+	w := get(explorer, "/docs/")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "explorer.js") {
+		t.Fatalf("expected explorer page, got %d", w.Code)
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'self'") {
+		t.Errorf("expected restrictive CSP, got %q", csp)
+	}
+	if w := get(explorer, "/docs/explorer.js"); w.Code != http.StatusOK {
+		t.Errorf("expected explorer script, got %d", w.Code)
+	}
+}
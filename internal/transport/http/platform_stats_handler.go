@@ -0,0 +1,136 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// statsWindows are the windows the platform stats may be aggregated over
+var statsWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// statsTopClients is the number of clients reported by traffic
+const statsTopClients = 10
+
+// PlatformStatsResponse aggregates platform activity over a window for the ops dashboard
+type PlatformStatsResponse struct {
+	Window       string              `json:"window" example:"24h"`
+	Since        time.Time           `json:"since"`
+	Until        time.Time           `json:"until"`
+	Tenants      TenantCounts        `json:"tenants"`
+	ActiveUsers  int64               `json:"active_users"`  // Distinct users with a successful login in the window
+	TokensIssued int64               `json:"tokens_issued"` // Token responses issued in the window
+	ErrorRates   PlatformErrorRates  `json:"error_rates"`
+	TopClients   []audit.ClientStats `json:"top_clients"` // Clients issued the most tokens, busiest first
+}
+
+// TenantCounts counts the tenants that are not deleted
+type TenantCounts struct {
+	Total  int64 `json:"total"`
+	Active int64 `json:"active"`
+}
+
+// PlatformErrorRates are failure ratios between 0 and 1; zero when there were no attempts
+type PlatformErrorRates struct {
+	Login      float64 `json:"login" example:"0.04"`       // login_failed / (login_success + login_failed)
+	ClientAuth float64 `json:"client_auth" example:"0.01"` // client_auth_failed / (client_auth_failed + token_issued)
+}
+
+// GetPlatformStats aggregates tenants, users, tokens and errors over a window
+// @Summary Get Platform Stats
+// @Description Aggregates platform activity from the audit trail for the ops dashboard: tenant counts, distinct users who logged in, tokens issued, login and client authentication failure rates, and the ten clients issued the most tokens.
+// @Description window is 1h, 24h (default), 7d or 30d, ending now. On CockroachDB the figures are follower reads and may miss the last few seconds.
+// @Tags System
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param window query string false "Window to aggregate over: 1h, 24h, 7d or 30d"
+// @Success 200 {object} PlatformStatsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /platform/stats [get]
+func (h *Handler) GetPlatformStats(w http.ResponseWriter, r *http.Request) {
+	// 1. Authorization Check: Platform Admin required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopePlatform, nil, authz.PermPlatformManageTenants)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "platform admin administrative access required")
+		return
+	}
+
+	// 2. Window
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = "24h"
+	}
+	d, ok := statsWindows[window]
+	if !ok {
+		respondError(w, http.StatusBadRequest, "window must be 1h, 24h, 7d or 30d")
+		return
+	}
+	until := time.Now().UTC()
+	since := until.Add(-d)
+
+	// 3. Aggregate
+	counts, err := h.tenantService.CountTenants(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to count tenants")
+		return
+	}
+	stats, err := h.auditService.Stats(r.Context(), audit.StatsFilter{Since: since, Until: until, TopClients: statsTopClients})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to aggregate audit events")
+		return
+	}
+
+	resp := PlatformStatsResponse{
+		Window:       window,
+		Since:        since,
+		Until:        until,
+		Tenants:      TenantCounts{Active: counts[tenant.StatusActive]},
+		ActiveUsers:  stats.ActiveUsers,
+		TokensIssued: stats.EventCounts[audit.TypeTokenIssued],
+		ErrorRates: PlatformErrorRates{
+			Login:      failureRate(stats.EventCounts[audit.TypeLoginFailed], stats.EventCounts[audit.TypeLoginSuccess]),
+			ClientAuth: failureRate(stats.EventCounts[audit.TypeClientAuthFailed], stats.EventCounts[audit.TypeTokenIssued]),
+		},
+		TopClients: stats.TopClients,
+	}
+	for _, n := range counts {
+		resp.Tenants.Total += n
+	}
+	if resp.TopClients == nil {
+		resp.TopClients = []audit.ClientStats{}
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// failureRate is failed / (failed + succeeded), or zero without attempts
+func failureRate(failed, succeeded int64) float64 {
+	if failed+succeeded == 0 {
+		return 0
+	}
+	return float64(failed) / float64(failed+succeeded)
+}
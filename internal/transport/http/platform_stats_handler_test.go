@@ -0,0 +1,89 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+func platformStatsRequest(h *Handler, userID, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	w := httptest.NewRecorder()
+	h.GetPlatformStats(w, req.WithContext(context.WithValue(req.Context(), userIDKey, userID)))
+	return w
+}
+
+// TestPlatformStats tests that platform admins get tenant, user, token and error figures for a window
+func TestPlatformStats(t *testing.T) {
+	db := newClientTestStore(t)
+	ctx := context.Background()
+	tenants := memory.NewTenantRepository(db)
+	for _, tn := range []*tenant.Tenant{{ID: "t1", Name: "Acme", Status: tenant.StatusActive}, {ID: "t2", Name: "Beta", Status: tenant.StatusInactive}} {
+		if err := tenants.Create(ctx, tn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assignments := memory.NewAssignmentRepository(db)
+	if err := assignments.Grant(ctx, &authz.Assignment{ID: "p1", UserID: "p1", RoleID: rbac.RoleIDPlatformAdmin, Scope: authz.ScopePlatform}); err != nil {
+		t.Fatal(err)
+	}
+	events := memory.NewAuditRepository(db)
+	logger := audit.NewStoreLogger(events)
+	logger.Log(ctx, audit.Event{Type: audit.TypeLoginSuccess, TenantID: "t1", ActorID: "u1", Resource: audit.ResourceUser})
+	logger.Log(ctx, audit.Event{Type: audit.TypeLoginFailed, TenantID: "t1", Resource: audit.ResourceUser})
+	logger.Log(ctx, audit.Event{Type: audit.TypeTokenIssued, TenantID: "t1", ActorID: "u1", Resource: audit.ResourceToken, Payload: &audit.TokenPayload{ClientID: "app"}})
+	logger.Log(ctx, audit.Event{Type: audit.TypeLoginSuccess, TenantID: "t1", ActorID: "u2", Resource: audit.ResourceUser, Timestamp: time.Now().Add(-48 * time.Hour)})
+
+	h := &Handler{
+		authzService:  authz.NewService(nil, memory.NewRoleRepository(db), assignments),
+		tenantService: tenant.NewService(tenants, nil, assignments, audit.NewSlogLogger()),
+		auditService:  audit.NewService(events, nil, logger, 0),
+	}
+
+	if w := platformStatsRequest(h, "u1", "/platform/stats"); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for tenant admin, got %d", w.Code)
+	}
+	if w := platformStatsRequest(h, "p1", "/platform/stats?window=1y"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown window, got %d", w.Code)
+	}
+
+	w := platformStatsRequest(h, "p1", "/platform/stats")
+	var resp PlatformStatsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	if resp.Window != "24h" || resp.Tenants.Total != 2 || resp.Tenants.Active != 1 || resp.ActiveUsers != 1 || resp.TokensIssued != 1 {
+		t.Errorf("unexpected stats %+v", resp)
+	}
+	if resp.ErrorRates.Login != 0.5 || len(resp.TopClients) != 1 || resp.TopClients[0].ClientID != "app" {
+		t.Errorf("unexpected error rates or top clients %+v", resp)
+	}
+
+	w = platformStatsRequest(h, "p1", "/platform/stats?window=7d")
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.ActiveUsers != 2 {
+		t.Errorf("expected the 7d window to include the older login, got %d body: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+)
+
+// poolRetryAfter is the Retry-After sent while the database pool is exhausted; the breaker lets
+// requests through again as soon as a connection is free
+const poolRetryAfter = "1"
+
+// PoolBreakerMiddleware answers 503 right away while the database connection pool is exhausted,
+// instead of letting every request queue for a connection until it times out. The health check
+// stays reachable and reports the pool.
+func (h *Handler) PoolBreakerMiddleware(next http.Handler) http.Handler {
+	if h.dbSaturated == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && h.dbSaturated() {
+			w.Header().Set("Retry-After", poolRetryAfter)
+			respondError(w, http.StatusServiceUnavailable, "temporarily unavailable")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// TestPurpose: Validates that requests fail fast while the database connection pool is exhausted.
+// Scope: Unit Test
+// Security: Availability (a saturated pool must not queue every request into a timeout)
+// Expected: Requests are answered 503 with Retry-After while the pool is saturated and reach the handler otherwise; the health check stays reachable and reports the pool as "warn".
+// Test Case ID: API-08
+func TestPoolBreakerMiddleware(t *testing.T) {
+	var saturated atomic.Bool
+	h := &Handler{dbSaturated: saturated.Load}
+	r := NewRouter(h, nil, nil, "auth")
+	send := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := send("/openapi.json"); w.Code != http.StatusOK {
+		t.Fatalf("expected the request to pass while the pool has connections, got %d", w.Code)
+	}
+
+	saturated.Store(true)
+	w := send("/openapi.json")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != poolRetryAfter {
+		t.Errorf("expected 503 with Retry-After, got %d Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	w = send("/health")
+	var health HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&health); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected the health check to answer, got %d (%v)", w.Code, err)
+	}
+	if health.Status != "warn" || len(health.Checks["database:pool"]) != 1 {
+		t.Errorf("expected the exhausted pool to be reported, got %+v", health)
+	}
+}
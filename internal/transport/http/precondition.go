@@ -0,0 +1,63 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Mutable records (users, OAuth2 clients) carry a version that every update increments.
+// Handlers expose it as a strong ETag and accept it back in If-Match, so an edit based on
+// a stale read is rejected instead of silently overwriting a concurrent one.
+
+// versionETag renders a record version as a strong entity tag
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion returns the record version named by the If-Match header (RFC 9110 Section 13.1.1).
+// ok is false when the header is absent or "*". Weak or malformed tags, and lists of tags, can never
+// match a single current version and yield -1.
+func ifMatchVersion(r *http.Request) (version int, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, false
+	}
+	tag, found := strings.CutPrefix(header, `"`)
+	if !found {
+		return -1, true
+	}
+	tag, found = strings.CutSuffix(tag, `"`)
+	if !found {
+		return -1, true
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return -1, true
+	}
+	return version, true
+}
+
+// respondVersionConflict answers an update that lost against a concurrent one:
+// 412 when the request named the version it expected in If-Match, 409 when it did not.
+func respondVersionConflict(w http.ResponseWriter, preconditioned bool) {
+	if preconditioned {
+		respondError(w, http.StatusPreconditionFailed, "resource has changed since it was read; reload and retry")
+		return
+	}
+	respondError(w, http.StatusConflict, "resource was modified concurrently; reload and retry")
+}
@@ -0,0 +1,426 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// ProjectRequest represents a project to create or update
+type ProjectRequest struct {
+	Name        string `json:"name" binding:"required" example:"Billing"`
+	Description string `json:"description" example:"Invoices and payments"`
+}
+
+// ProjectResponse represents a project
+type ProjectResponse struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	OwnerID     string    `json:"owner_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListProjectsResponse is a page of projects
+type ListProjectsResponse struct {
+	Projects   []ProjectResponse `json:"projects"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// ProjectMemberRequest sets a user's role in a project
+type ProjectMemberRequest struct {
+	Role string `json:"role" binding:"required" enums:"project_admin,project_member"`
+}
+
+// ProjectMemberResponse represents a user's role in a project
+type ProjectMemberResponse struct {
+	ProjectID string    `json:"project_id"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	GrantedAt time.Time `json:"granted_at"`
+	GrantedBy string    `json:"granted_by,omitempty"`
+}
+
+func newProjectResponse(p *authz.Project) ProjectResponse {
+	return ProjectResponse{
+		ID:          p.ID,
+		TenantID:    p.TenantID,
+		Name:        p.Name,
+		Description: p.Description,
+		OwnerID:     p.OwnerID,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+// authorizeProject loads the project named by the route and checks a project permission, which
+// project roles grant as well as tenant:manage_projects in the project's tenant. A project of
+// another tenant is not found. It returns false once it has answered the request.
+func (h *Handler) authorizeProject(w http.ResponseWriter, r *http.Request, permission string) (*authz.Project, bool) {
+	tenantID := chi.URLParam(r, "tenantID")
+	project, err := h.authzService.GetProject(r.Context(), tenantID, chi.URLParam(r, "projectID"))
+	if err != nil {
+		respondServiceError(w, r, err, "failed to get project")
+		return nil, false
+	}
+
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeProject, &project.ID, permission)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "project access required")
+		return nil, false
+	}
+	return project, true
+}
+
+// logProject records a change to a project
+func (h *Handler) logProject(r *http.Request, eventType string, p *authz.Project) {
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     eventType,
+		TenantID: p.TenantID,
+		ActorID:  GetUserID(r.Context()),
+		Resource: audit.ResourceProject,
+		Payload:  &audit.ProjectPayload{ProjectID: p.ID, ProjectName: p.Name},
+	})
+}
+
+// ListProjects handles listing a tenant's projects
+// @Summary List Projects
+// @Description List a tenant's projects with cursor pagination. Users without tenant:manage_projects see only the projects they have a role in, in a single page.
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Opaque cursor from a previous page's next_cursor"
+// @Success 200 {object} ListProjectsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/projects [get]
+func (h *Handler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	userID := GetUserID(r.Context())
+
+	page, err := pagination.Parse(r.URL.Query().Get("limit"), r.URL.Query().Get("cursor"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var projects []*authz.Project
+	var next string
+	manager, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageProjects)
+	if err == nil && manager {
+		projects, next, err = h.authzService.ListProjects(r.Context(), tenantID, page)
+	} else {
+		projects, err = h.authzService.ListMemberProjects(r.Context(), tenantID, userID)
+	}
+	if err != nil {
+		respondServiceError(w, r, err, "failed to list projects")
+		return
+	}
+
+	resp := ListProjectsResponse{Projects: make([]ProjectResponse, 0, len(projects)), NextCursor: next}
+	for _, p := range projects {
+		resp.Projects = append(resp.Projects, newProjectResponse(p))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// CreateProject handles creating a project
+// @Summary Create Project
+// @Description Create a project in the tenant. The creator becomes its owner and a project admin.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body ProjectRequest true "Project"
+// @Success 201 {object} ProjectResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/projects [post]
+func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+
+	// 1. Authorization Check: Project management permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageProjects)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "project management access required")
+		return
+	}
+
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	project, err := h.authzService.CreateProject(r.Context(), tenantID, userID, req.Name, req.Description)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to create project")
+		return
+	}
+
+	h.logProject(r, audit.TypeProjectCreated, project)
+	respondJSON(w, http.StatusCreated, newProjectResponse(project))
+}
+
+// GetProject handles retrieving a project
+// @Summary Get Project
+// @Description Get a project of the tenant (requires project:view)
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param projectID path string true "Project ID"
+// @Success 200 {object} ProjectResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/projects/{projectID} [get]
+func (h *Handler) GetProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeProject(w, r, authz.PermProjectView)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, newProjectResponse(project))
+}
+
+// UpdateProject handles renaming a project
+// @Summary Update Project
+// @Description Replace a project's name and description (requires project:manage)
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param projectID path string true "Project ID"
+// @Param request body ProjectRequest true "Project"
+// @Success 200 {object} ProjectResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/projects/{projectID} [put]
+func (h *Handler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeProject(w, r, authz.PermProjectManage)
+	if !ok {
+		return
+	}
+
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	project, err := h.authzService.UpdateProject(r.Context(), project.TenantID, project.ID, req.Name, req.Description)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to update project")
+		return
+	}
+
+	h.logProject(r, audit.TypeProjectUpdated, project)
+	respondJSON(w, http.StatusOK, newProjectResponse(project))
+}
+
+// DeleteProject handles deleting a project
+// @Summary Delete Project
+// @Description Delete a project (requires project:manage). Its members lose their project roles.
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param projectID path string true "Project ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/projects/{projectID} [delete]
+func (h *Handler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeProject(w, r, authz.PermProjectManage)
+	if !ok {
+		return
+	}
+
+	if err := h.authzService.DeleteProject(r.Context(), project.TenantID, project.ID); err != nil {
+		respondServiceError(w, r, err, "failed to delete project")
+		return
+	}
+
+	h.logProject(r, audit.TypeProjectDeleted, project)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListProjectMembers handles listing the members of a project
+// @Summary List Project Members
+// @Description List the users holding a role in a project (requires project:view)
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param projectID path string true "Project ID"
+// @Success 200 {array} ProjectMemberResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/projects/{projectID}/members [get]
+func (h *Handler) ListProjectMembers(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeProject(w, r, authz.PermProjectView)
+	if !ok {
+		return
+	}
+
+	members, err := h.authzService.ListProjectMembers(r.Context(), project.TenantID, project.ID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to list project members")
+		return
+	}
+
+	resp := make([]ProjectMemberResponse, 0, len(members))
+	for _, m := range members {
+		member := ProjectMemberResponse{
+			ProjectID: m.ProjectID,
+			UserID:    m.UserID,
+			Role:      m.Role,
+			GrantedAt: m.GrantedAt,
+			GrantedBy: m.GrantedBy,
+		}
+		// Members added from other tenants are not registered in this one
+		if u, err := h.identityService.GetUser(tenant.WithoutScope(r.Context()), m.UserID); err == nil {
+			member.Email = u.Email
+		}
+		resp = append(resp, member)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// SetProjectMember handles giving a user a role in a project
+// @Summary Set Project Member
+// @Description Give a member of the tenant a role in a project, replacing the role they had (requires project:manage_members). project_admin manages the project and its members; project_member can view it. Demoting the last project admin is refused with 409.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param projectID path string true "Project ID"
+// @Param userID path string true "User ID"
+// @Param request body ProjectMemberRequest true "Role"
+// @Success 200 {object} ProjectMemberResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "not a member of the tenant, or the last project admin"
+// @Router /tenants/{tenantID}/projects/{projectID}/members/{userID} [put]
+func (h *Handler) SetProjectMember(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeProject(w, r, authz.PermProjectManageMembers)
+	if !ok {
+		return
+	}
+	userID := chi.URLParam(r, "userID")
+
+	var req ProjectMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	// Project roles, like tenant roles, are only held by members of the tenant
+	member, err := h.belongsToTenant(r, project.TenantID, userID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to set project member")
+		return
+	}
+	if !member {
+		respondServiceError(w, r, tenant.ErrNotMember, "failed to set project member")
+		return
+	}
+
+	actorID := GetUserID(r.Context())
+	m, err := h.authzService.SetProjectMember(r.Context(), project.TenantID, project.ID, userID, req.Role, actorID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to set project member")
+		return
+	}
+
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     audit.TypeRoleAssigned,
+		TenantID: project.TenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceRole,
+		Payload:  &audit.RolePayload{RoleID: m.Role, TargetUserID: userID, ProjectID: project.ID},
+	})
+
+	respondJSON(w, http.StatusOK, ProjectMemberResponse{
+		ProjectID: m.ProjectID,
+		UserID:    m.UserID,
+		Role:      m.Role,
+		GrantedAt: m.GrantedAt,
+		GrantedBy: m.GrantedBy,
+	})
+}
+
+// RemoveProjectMember handles removing a user from a project
+// @Summary Remove Project Member
+// @Description Remove a user's role in a project (requires project:manage_members). Removing the last project admin is refused with 409.
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param projectID path string true "Project ID"
+// @Param userID path string true "User ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /tenants/{tenantID}/projects/{projectID}/members/{userID} [delete]
+func (h *Handler) RemoveProjectMember(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeProject(w, r, authz.PermProjectManageMembers)
+	if !ok {
+		return
+	}
+
+	m, err := h.authzService.RemoveProjectMember(r.Context(), project.TenantID, project.ID, chi.URLParam(r, "userID"))
+	if err != nil {
+		respondServiceError(w, r, err, "failed to remove project member")
+		return
+	}
+
+	h.auditLogger.Log(r.Context(), audit.Event{
+		Type:     audit.TypeRoleRevoked,
+		TenantID: project.TenantID,
+		ActorID:  GetUserID(r.Context()),
+		Resource: audit.ResourceRole,
+		Payload:  &audit.RolePayload{RoleID: m.Role, TargetUserID: m.UserID, ProjectID: project.ID},
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
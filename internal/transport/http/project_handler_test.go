@@ -0,0 +1,143 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// projectRequest calls a project handler as actorID for a project and user of a tenant
+func projectRequest(fn http.HandlerFunc, method, actorID, tenantID, projectID, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/tenants/"+tenantID+"/projects", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenantID", tenantID)
+	rctx.URLParams.Add("projectID", projectID)
+	rctx.URLParams.Add("userID", userID)
+	req = req.WithContext(context.WithValue(context.WithValue(req.Context(), chi.RouteCtxKey, rctx), userIDKey, actorID))
+	w := httptest.NewRecorder()
+	fn(w, req)
+	return w
+}
+
+// TestPurpose: Validates project management and that project roles are enforced at project scope.
+// Scope: Unit Test
+// Security: Authorization (CWE-285) and Multi-tenant Data Separation (CWE-284)
+// Permissions: tenant:manage_projects, project:view, project:manage, project:manage_members
+// Expected: Only tenant project managers create projects, whose creator becomes project admin; a project member can view but not change a project; other tenant users only list the projects they belong to and are refused the rest; roles go only to tenant members; the last project admin cannot be demoted or removed; a project is not found through another tenant.
+// Test Case ID: AUT-09
+func TestAuthz_ProjectScope(t *testing.T) {
+	ctx := context.Background()
+	h, _, _ := membershipTestHandler(t)
+
+	provision := func(tenantID, email string) string {
+		u, err := h.identityService.ProvisionIdentity(ctx, tenantID, email, identity.Profile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.ID
+	}
+	admin, dev, other, outsider := provision("t1", "admin@example.com"), provision("t1", "dev@example.com"), provision("t1", "other@example.com"), provision("t2", "outsider@example.com")
+	if err := h.tenantService.AssignRole(ctx, "t1", admin, tenant.RoleTenantAdmin, tenant.GranterSystem); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := projectRequest(h.CreateProject, http.MethodPost, dev, "t1", "", "", `{"name":"Billing"}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected a user without tenant:manage_projects to be refused, got %d", w.Code)
+	}
+	if w := projectRequest(h.CreateProject, http.MethodPost, admin, "t1", "", "", `{"name":" "}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected a project without a name to be refused, got %d", w.Code)
+	}
+	w := projectRequest(h.CreateProject, http.MethodPost, admin, "t1", "", "", `{"name":"Billing","description":"Invoices"}`)
+	var project ProjectResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &project); err != nil || w.Code != http.StatusCreated || project.TenantID != "t1" || project.OwnerID != admin {
+		t.Fatalf("expected the project to be created, got %d %s", w.Code, w.Body)
+	}
+	member := func(userID, role string) int {
+		return projectRequest(h.SetProjectMember, http.MethodPut, admin, "t1", project.ID, userID, `{"role":"`+role+`"}`).Code
+	}
+
+	if code := projectRequest(h.GetProject, http.MethodGet, dev, "t1", project.ID, "", "").Code; code != http.StatusForbidden {
+		t.Errorf("expected a user without a project role to be refused, got %d", code)
+	}
+	if code := member(dev, authz.RoleProjectMember); code != http.StatusOK {
+		t.Fatalf("expected the project role to be set, got %d", code)
+	}
+	if code := member(outsider, authz.RoleProjectMember); code != http.StatusConflict {
+		t.Errorf("expected a user of another tenant to be refused, got %d", code)
+	}
+	if code := member(dev, "owner"); code != http.StatusBadRequest {
+		t.Errorf("expected an unknown project role to be refused, got %d", code)
+	}
+	if code := projectRequest(h.GetProject, http.MethodGet, dev, "t1", project.ID, "", "").Code; code != http.StatusOK {
+		t.Errorf("expected a project member to view the project, got %d", code)
+	}
+	if code := projectRequest(h.UpdateProject, http.MethodPut, dev, "t1", project.ID, "", `{"name":"Mine"}`).Code; code != http.StatusForbidden {
+		t.Errorf("expected a project member not to update the project, got %d", code)
+	}
+	if code := projectRequest(h.SetProjectMember, http.MethodPut, dev, "t1", project.ID, other, `{"role":"project_member"}`).Code; code != http.StatusForbidden {
+		t.Errorf("expected a project member not to manage members, got %d", code)
+	}
+
+	list := func(userID string) ListProjectsResponse {
+		var resp ListProjectsResponse
+		json.Unmarshal(projectRequest(h.ListProjects, http.MethodGet, userID, "t1", "", "", "").Body.Bytes(), &resp)
+		return resp
+	}
+	if resp := list(dev); len(resp.Projects) != 1 || resp.Projects[0].ID != project.ID {
+		t.Errorf("expected a member to list their project, got %+v", resp)
+	}
+	if resp := list(other); len(resp.Projects) != 0 {
+		t.Errorf("expected a user without project roles to list none, got %+v", resp)
+	}
+
+	var members []ProjectMemberResponse
+	json.Unmarshal(projectRequest(h.ListProjectMembers, http.MethodGet, dev, "t1", project.ID, "", "").Body.Bytes(), &members)
+	if len(members) != 2 || members[0].UserID != admin || members[0].Role != authz.RoleProjectAdmin || members[1].Email != "dev@example.com" || members[1].GrantedBy != admin {
+		t.Errorf("unexpected project members %+v", members)
+	}
+
+	if code := member(admin, authz.RoleProjectMember); code != http.StatusConflict {
+		t.Errorf("expected the last project admin not to be demoted, got %d", code)
+	}
+	if code := projectRequest(h.RemoveProjectMember, http.MethodDelete, admin, "t1", project.ID, admin, "").Code; code != http.StatusConflict {
+		t.Errorf("expected the last project admin not to be removed, got %d", code)
+	}
+	if code := projectRequest(h.RemoveProjectMember, http.MethodDelete, admin, "t1", project.ID, dev, "").Code; code != http.StatusNoContent {
+		t.Errorf("expected the member to be removed, got %d", code)
+	}
+	if code := projectRequest(h.GetProject, http.MethodGet, dev, "t1", project.ID, "", "").Code; code != http.StatusForbidden {
+		t.Errorf("expected a removed member to lose access, got %d", code)
+	}
+
+	if code := projectRequest(h.GetProject, http.MethodGet, admin, "t2", project.ID, "", "").Code; code != http.StatusNotFound {
+		t.Errorf("expected the project not to be found through another tenant, got %d", code)
+	}
+	if code := projectRequest(h.DeleteProject, http.MethodDelete, admin, "t1", project.ID, "", "").Code; code != http.StatusNoContent {
+		t.Errorf("expected the project to be deleted, got %d", code)
+	}
+	if code := projectRequest(h.GetProject, http.MethodGet, admin, "t1", project.ID, "", "").Code; code != http.StatusNotFound {
+		t.Errorf("expected a deleted project to be not found, got %d", code)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+)
+
+// ProtocolLogRequest turns a tenant's protocol decision logging on or off
+type ProtocolLogRequest struct {
+	Enabled   bool       `json:"enabled" example:"true"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-02T15:04:05Z"` // Logging stops by itself after this time
+}
+
+// ProtocolLogResponse represents a tenant's protocol decision logging
+type ProtocolLogResponse struct {
+	TenantID  string     `json:"tenant_id"`
+	Enabled   bool       `json:"enabled"`
+	Active    bool       `json:"active"` // Enabled and not yet expired
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func newProtocolLogResponse(s *oauth2.ProtocolLogSettings) ProtocolLogResponse {
+	resp := ProtocolLogResponse{
+		TenantID:  s.TenantID,
+		Enabled:   s.Enabled,
+		Active:    s.Enabled && (s.ExpiresAt == nil || time.Now().Before(*s.ExpiresAt)),
+		ExpiresAt: s.ExpiresAt,
+		UpdatedBy: s.UpdatedBy,
+	}
+	if !s.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &s.UpdatedAt
+	}
+	return resp
+}
+
+// GetProtocolLog handles retrieving a tenant's protocol decision logging
+// @Summary Get Protocol Logging
+// @Description Get whether the tenant's authorization and token decisions are logged
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 200 {object} ProtocolLogResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/protocol-logging [get]
+func (h *Handler) GetProtocolLog(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return
+	}
+
+	settings, err := h.oauth2Service.GetProtocolLogSettings(r.Context(), tenantID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get protocol logging")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newProtocolLogResponse(settings))
+}
+
+// UpdateProtocolLog handles turning a tenant's protocol decision logging on or off
+// @Summary Update Protocol Logging
+// @Description Log the client, scopes, decision and error code of the tenant's authorization and token requests, correlated by request ID, to debug a client integration. Codes, tokens and secrets are never logged. Set expires_at, at most 30 days ahead, to stop logging by itself.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body ProtocolLogRequest true "Protocol Logging"
+// @Success 200 {object} ProtocolLogResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/protocol-logging [put]
+func (h *Handler) UpdateProtocolLog(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return
+	}
+
+	var req ProtocolLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	settings, err := h.oauth2Service.SetProtocolLogSettings(r.Context(), tenantID, userID, req.Enabled, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, oauth2.ErrInvalidProtocolLog) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to update protocol logging")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newProtocolLogResponse(settings))
+}
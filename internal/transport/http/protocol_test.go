@@ -16,6 +16,8 @@ package http
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -30,6 +32,7 @@ import (
 	"github.com/opentrusty/opentrusty/internal/oauth2"
 	"github.com/opentrusty/opentrusty/internal/oidc"
 	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
 )
 
 // TestPurpose: Validates that the OIDC discovery endpoint returns correct configuration for clients.
@@ -106,6 +109,59 @@ func TestHTTP_Protocol_JWKS(t *testing.T) {
 	}
 }
 
+// TestPurpose: Validates caching headers and conditional requests on the discovery and JWKS endpoints.
+// Scope: Unit Test
+// Security: Availability (relying parties cache metadata) and timely propagation of rotated keys
+// Expected: Responses carry Cache-Control and ETag; a matching If-None-Match gets 304; a key rotation changes the JWKS ETag.
+// Test Case ID: PRO-06
+// RelatedSpecs: RFC 9110 Section 13.1.2, RFC 9111 Section 5.2
+func TestHTTP_Protocol_DiscoveryCaching(t *testing.T) {
+	oidcService, _ := oidc.NewService("https://auth.opentrusty.org")
+	h := &Handler{oidcService: oidcService}
+
+	get := func(handler http.HandlerFunc, target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	for _, tt := range []struct {
+		target       string
+		handler      http.HandlerFunc
+		cacheControl string
+	}{
+		{"/.well-known/openid-configuration", h.Discovery, "public, max-age=3600"},
+		{"/.well-known/oauth-authorization-server", h.AuthorizationServerMetadata, "public, max-age=3600"},
+		{"/jwks.json", h.JWKS, "public, max-age=600"},
+	} {
+		w := get(tt.handler, tt.target, "")
+		etag := w.Header().Get("ETag")
+		if w.Code != http.StatusOK || etag == "" || w.Header().Get("Cache-Control") != tt.cacheControl {
+			t.Errorf("%s: expected 200 with ETag and %q, got %d %v", tt.target, tt.cacheControl, w.Code, w.Header())
+		}
+		if w := get(tt.handler, tt.target, `"other", W/`+etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s: expected 304 for a matching ETag, got %d", tt.target, w.Code)
+		}
+		if w := get(tt.handler, tt.target, `"other"`); w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200 for a stale ETag, got %d", tt.target, w.Code)
+		}
+	}
+
+	before := get(h.JWKS, "/jwks.json", "").Header().Get("ETag")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oidcService.RotateSigningKey(key)
+	if w := get(h.JWKS, "/jwks.json", before); w.Code != http.StatusOK || w.Header().Get("ETag") == before {
+		t.Errorf("expected a new JWKS after rotation, got %d with ETag %s", w.Code, w.Header().Get("ETag"))
+	}
+}
+
 // TestPurpose: Validates that the token endpoint rejects invalid requests with a 400 Bad Request or 401 Unauthorized.
 // Scope: Unit Test
 // Security: Token endpoint protection
@@ -129,97 +185,10 @@ func TestHTTP_Protocol_Token_BadRequest(t *testing.T) {
 	}
 }
 
-// Mocks for Protocol Testing
-type stubClientRepo struct {
-	clients map[string]*oauth2.Client
-}
-
-func (m *stubClientRepo) GetByClientID(id string) (*oauth2.Client, error) {
-	if c, ok := m.clients[id]; ok {
-		return c, nil
-	}
-	return nil, oauth2.ErrClientNotFound
-}
-func (m *stubClientRepo) GetByID(id string) (*oauth2.Client, error) {
-	if c, ok := m.clients[id]; ok {
-		return c, nil
-	}
-	return nil, oauth2.ErrClientNotFound
-}
-func (m *stubClientRepo) Create(c *oauth2.Client) error                   { return nil }
-func (m *stubClientRepo) Update(c *oauth2.Client) error                   { return nil }
-func (m *stubClientRepo) Delete(id string) error                          { return nil }
-func (m *stubClientRepo) ListByOwner(id string) ([]*oauth2.Client, error) { return nil, nil }
-func (m *stubClientRepo) ListByTenant(id string) ([]*oauth2.Client, error) {
-	var res []*oauth2.Client
-	for _, c := range m.clients {
-		if c.TenantID == id {
-			res = append(res, c)
-		}
-	}
-	return res, nil
-}
-
-type stubCodeRepo struct {
-	codes map[string]*oauth2.AuthorizationCode
-}
-
-func (m *stubCodeRepo) Create(c *oauth2.AuthorizationCode) error { m.codes[c.Code] = c; return nil }
-func (m *stubCodeRepo) GetByCode(code string) (*oauth2.AuthorizationCode, error) {
-	if c, ok := m.codes[code]; ok {
-		return c, nil
-	}
-	return nil, oauth2.ErrCodeNotFound
-}
-func (m *stubCodeRepo) MarkAsUsed(code string) error {
-	if c, ok := m.codes[code]; ok {
-		c.IsUsed = true
-	}
-	return nil
-}
-func (m *stubCodeRepo) Delete(code string) error { return nil }
-func (m *stubCodeRepo) DeleteExpired() error     { return nil }
-
-type stubAccessRepo struct{}
-
-func (m *stubAccessRepo) Create(t *oauth2.AccessToken) error                   { return nil }
-func (m *stubAccessRepo) GetByTokenHash(h string) (*oauth2.AccessToken, error) { return nil, nil }
-func (m *stubAccessRepo) Revoke(h string) error                                { return nil }
-func (m *stubAccessRepo) DeleteExpired() error                                 { return nil }
-
-type stubRefreshRepo struct{}
-
-func (m *stubRefreshRepo) Create(t *oauth2.RefreshToken) error                   { return nil }
-func (m *stubRefreshRepo) GetByTokenHash(h string) (*oauth2.RefreshToken, error) { return nil, nil }
-func (m *stubRefreshRepo) Revoke(h string) error                                 { return nil }
-func (m *stubRefreshRepo) DeleteExpired() error                                  { return nil }
-
-// mockSessionService is harder to mock because Service is a struct.
-// But checking AuthMiddleware requires sessionService.
-// We can test Cross-Tenant by mocking the sessionService behavior via a custom Handler if possible,
-// or just creating a real sessionService with mock Repo.
-// Session Service needs SessionRepository.
-
-type stubSessionRepo struct {
-	sessions map[string]*session.Session
-}
-
-func (m *stubSessionRepo) Create(s *session.Session) error { m.sessions[s.ID] = s; return nil }
-func (m *stubSessionRepo) Get(id string) (*session.Session, error) {
-	if s, ok := m.sessions[id]; ok {
-		return s, nil
-	}
-	return nil, session.ErrSessionNotFound
-}
-func (m *stubSessionRepo) Update(s *session.Session) error { return nil }
-func (m *stubSessionRepo) Delete(id string) error          { delete(m.sessions, id); return nil }
-func (m *stubSessionRepo) DeleteExpired() error            { return nil }
-func (m *stubSessionRepo) DeleteByUserID(uid string) error { return nil }
-
 // TestPurpose: Validates a full OAuth2 authorization code flow from code creation to token exchange via HTTP.
 // Scope: Unit Test
 // Security: End-to-end OAuth2 protocol correctness (RFC 6749)
-// Expected: Successful exchange of a valid code for access, refresh, and ID tokens through the HTTP handler.
+// Expected: Successful exchange of a valid code for access, refresh, and ID tokens through the HTTP router, with an uncacheable response.
 // Test Case ID: PRO-04
 func TestHTTP_Protocol_HappyPath_Flow(t *testing.T) {
 	// Set required encryption key for OAuth2 service
@@ -227,18 +196,22 @@ func TestHTTP_Protocol_HappyPath_Flow(t *testing.T) {
 	defer os.Unsetenv("OPENID_KEY_ENCRYPTION_KEY")
 
 	// 1. Setup Dependencies
-	clientRepo := &stubClientRepo{clients: map[string]*oauth2.Client{
-		"client-1": {
-			ClientID:            "client-1",
-			ClientSecretHash:    oauth2.HashClientSecret("secret-1"),
-			RedirectURIs:        []string{"https://app.com/cb"},
-			AllowedScopes:       []string{"openid", "profile"},
-			IsActive:            true,
-			AccessTokenLifetime: 3600,
-			TenantID:            "tenant-1",
-		},
-	}}
-	codeRepo := &stubCodeRepo{codes: make(map[string]*oauth2.AuthorizationCode)}
+	db := memory.New()
+	clientRepo := memory.NewClientRepository(db)
+	err := clientRepo.Create(context.Background(), &oauth2.Client{
+		ID:                  "c1",
+		ClientID:            "client-1",
+		ClientSecretHash:    oauth2.HashClientSecret("secret-1"),
+		RedirectURIs:        []string{"https://app.com/cb"},
+		AllowedScopes:       []string{"openid", "profile"},
+		IsActive:            true,
+		AccessTokenLifetime: 3600,
+		TenantID:            "tenant-1",
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	codeRepo := memory.NewAuthorizationCodeRepository(db)
 
 	// Create OIDC service for ID Token generation
 	oidcSvc, _ := oidc.NewService("http://localhost")
@@ -249,7 +222,7 @@ func TestHTTP_Protocol_HappyPath_Flow(t *testing.T) {
 	// oauth2.OIDCProvider has GenerateIDToken. oidc.Service has GenerateIDToken.
 	// Yes, signatures match.
 	// However, NewService arg is explicitly `oidcProvider`.
-	oauth2Svc := oauth2.NewService(clientRepo, codeRepo, &stubAccessRepo{}, &stubRefreshRepo{}, audit.NewSlogLogger(), oidcSvc, 5*time.Minute, 1*time.Hour, 720*time.Hour)
+	oauth2Svc := oauth2.NewService(clientRepo, codeRepo, memory.NewAccessTokenRepository(db), memory.NewRefreshTokenRepository(db), audit.NewSlogLogger(), oidcSvc, 5*time.Minute, 1*time.Hour, 720*time.Hour)
 
 	h := &Handler{
 		oauth2Service: oauth2Svc,
@@ -271,7 +244,7 @@ func TestHTTP_Protocol_HappyPath_Flow(t *testing.T) {
 		State:       "state-1",
 		Nonce:       "nonce-1",
 	}
-	code, err := oauth2Svc.CreateAuthorizationCode(ctx, authReq, "user-1")
+	code, err := oauth2Svc.CreateAuthorizationCode(ctx, authReq, "user-1", "")
 	if err != nil {
 		t.Fatalf("failed to create code: %v", err)
 	}
@@ -288,11 +261,14 @@ func TestHTTP_Protocol_HappyPath_Flow(t *testing.T) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	w := httptest.NewRecorder()
 
-	h.Token(w, req)
+	NewRouter(h, nil, nil, "auth").ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected 200 OK, got %d body: %s", w.Code, w.Body.String())
 	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("expected Cache-Control: no-store on the token response, got %q", cc)
+	}
 
 	var resp map[string]interface{}
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
@@ -307,6 +283,138 @@ func TestHTTP_Protocol_HappyPath_Flow(t *testing.T) {
 	}
 }
 
+// TestPurpose: Validates that authorization responses are encoded so client-supplied values cannot alter the redirect.
+// Scope: Unit Test
+// Security: Open redirect and parameter injection prevention in authorization responses
+// Expected: state and error_description are form-encoded, the registered query is kept, and response_mode=fragment uses the fragment.
+// Test Case ID: PRO-07
+// RelatedSpecs: RFC 6749 Section 4.1.2, OAuth 2.0 Multiple Response Type Encoding Practices Section 2.1
+func TestHTTP_Protocol_AuthorizeRedirectEncoding(t *testing.T) {
+	tests := []struct {
+		name, redirectURI, responseMode string
+		params                          map[string]string
+		want                            string
+	}{
+		{"plain", "https://app.com/cb", "", map[string]string{"code": "abc", "state": "xyz"}, "https://app.com/cb?code=abc&state=xyz"},
+		{"state with injected parameter", "https://app.com/cb", "", map[string]string{"code": "abc", "state": "x&code=evil"}, "https://app.com/cb?code=abc&state=x%26code%3Devil"},
+		{"state with fragment and spaces", "https://app.com/cb", "", map[string]string{"state": "a b#https://evil.com"}, "https://app.com/cb?state=a+b%23https%3A%2F%2Fevil.com"},
+		{"registered query is kept", "https://app.com/cb?tenant=t1", "query", map[string]string{"code": "abc"}, "https://app.com/cb?tenant=t1&code=abc"},
+		{"empty state is omitted", "https://app.com/cb", "", map[string]string{"error": "access_denied", "state": ""}, "https://app.com/cb?error=access_denied"},
+		{"fragment mode", "https://app.com/cb?tenant=t1#old", "fragment", map[string]string{"code": "abc", "state": "s/1?"}, "https://app.com/cb?tenant=t1#code=abc&state=s%2F1%3F"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := authorizeRedirectURL(tt.redirectURI, tt.responseMode, tt.params)
+			if err != nil || got != tt.want {
+				t.Errorf("expected %s, got %s (err=%v)", tt.want, got, err)
+			}
+		})
+	}
+
+	// Through the handler: the state round-trips intact and nothing else is injected
+	os.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
+	defer os.Unsetenv("OPENID_KEY_ENCRYPTION_KEY")
+	db := memory.New()
+	clientRepo := memory.NewClientRepository(db)
+	if err := clientRepo.Create(context.Background(), &oauth2.Client{
+		ID:            "c1",
+		ClientID:      "client-1",
+		RedirectURIs:  []string{"https://app.com/cb"},
+		AllowedScopes: []string{"openid"},
+		IsActive:      true,
+		TenantID:      "tenant-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	oauth2Svc := oauth2.NewService(clientRepo, memory.NewAuthorizationCodeRepository(db), memory.NewAccessTokenRepository(db), memory.NewRefreshTokenRepository(db), audit.NewSlogLogger(), nil, 5*time.Minute, time.Hour, time.Hour)
+	h := &Handler{oauth2Service: oauth2Svc, auditLogger: audit.NewSlogLogger()}
+
+	authorize := func(query url.Values) *url.URL {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/oauth2/authorize?"+query.Encode(), nil)
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, "user-1"))
+		w := httptest.NewRecorder()
+		h.Authorize(w, req)
+		if w.Code != http.StatusFound {
+			t.Fatalf("expected 302, got %d: %s", w.Code, w.Body.String())
+		}
+		location, err := url.Parse(w.Header().Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return location
+	}
+
+	state := "x&code=evil#frag ment"
+	query := url.Values{"client_id": {"client-1"}, "redirect_uri": {"https://app.com/cb"}, "response_type": {"code"}, "state": {state}}
+	location := authorize(query)
+	if got := location.Query(); got.Get("state") != state || len(got["code"]) != 1 || got.Get("code") == "evil" || location.Fragment != "" {
+		t.Errorf("unexpected redirect %s", location)
+	}
+
+	query.Set("response_mode", "fragment")
+	location = authorize(query)
+	fragment, _ := url.ParseQuery(location.EscapedFragment())
+	if location.RawQuery != "" || fragment.Get("state") != state || fragment.Get("code") == "" {
+		t.Errorf("expected the response in the fragment, got %s", location)
+	}
+
+	query.Set("response_mode", "form_post")
+	location = authorize(query)
+	if got := location.Query(); got.Get("error") != oauth2.ErrInvalidRequest || got.Get("state") != state {
+		t.Errorf("expected invalid_request for an unsupported response_mode, got %s", location)
+	}
+}
+
+// TestPurpose: Validates that authorization errors are only redirected to a redirect_uri registered for a resolved client.
+// Scope: Unit Test
+// Security: Open redirect prevention (an attacker cannot bounce users off the authorization endpoint)
+// Expected: An unknown or disabled client_id or an unregistered redirect_uri gets 400 without a Location; other errors redirect to the registered URI.
+// Test Case ID: PRO-14
+// RelatedSpecs: RFC 6749 Section 4.1.2.1
+func TestHTTP_Protocol_AuthorizeErrorsNotRedirectedToUnverifiedURI(t *testing.T) {
+	db := memory.New()
+	clientRepo := memory.NewClientRepository(db)
+	for _, c := range []*oauth2.Client{
+		{ID: "c1", ClientID: "client-1", RedirectURIs: []string{"https://app.com/cb"}, AllowedScopes: []string{"openid"}, IsActive: true, TenantID: "tenant-1"},
+		{ID: "c2", ClientID: "disabled", RedirectURIs: []string{"https://evil.com/cb"}, AllowedScopes: []string{"openid"}, IsActive: false, TenantID: "tenant-1"},
+	} {
+		if err := clientRepo.Create(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oauth2Svc := oauth2.NewService(clientRepo, memory.NewAuthorizationCodeRepository(db), memory.NewAccessTokenRepository(db), memory.NewRefreshTokenRepository(db), audit.NewSlogLogger(), nil, 5*time.Minute, time.Hour, time.Hour)
+	h := &Handler{oauth2Service: oauth2Svc, auditLogger: audit.NewSlogLogger()}
+
+	authorize := func(clientID, redirectURI string) *httptest.ResponseRecorder {
+		query := url.Values{"client_id": {clientID}, "redirect_uri": {redirectURI}, "response_type": {"token"}, "state": {"s"}}
+		req := httptest.NewRequest("GET", "/oauth2/authorize?"+query.Encode(), nil)
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, "user-1"))
+		w := httptest.NewRecorder()
+		h.Authorize(w, req)
+		return w
+	}
+
+	for _, tt := range []struct{ name, clientID, redirectURI string }{
+		{"unknown client", "attacker", "https://evil.com/cb"},
+		{"disabled client", "disabled", "https://evil.com/cb"},
+		{"unregistered redirect_uri", "client-1", "https://evil.com/cb"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := authorize(tt.clientID, tt.redirectURI)
+			if w.Code != http.StatusBadRequest || w.Header().Get("Location") != "" {
+				t.Errorf("expected 400 without a redirect, got %d to %q", w.Code, w.Header().Get("Location"))
+			}
+		})
+	}
+
+	w := authorize("client-1", "https://app.com/cb")
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil || w.Code != http.StatusFound || location.Host != "app.com" || location.Query().Get("error") == "" {
+		t.Errorf("expected the error redirected to the registered URI, got %d to %q", w.Code, w.Header().Get("Location"))
+	}
+}
+
 // TestPurpose: Validates that a session for Tenant A cannot be used to access Tenant B resources through the HTTP middleware.
 // Scope: Unit Test
 // Security: Horizontal privilege escalation prevention (Cross-tenant access)
@@ -314,18 +422,16 @@ func TestHTTP_Protocol_HappyPath_Flow(t *testing.T) {
 // Test Case ID: PRO-05
 func TestHTTP_Protocol_CrossTenant_Negative(t *testing.T) {
 	// Setup Session Service
-	sessRepo := &stubSessionRepo{sessions: make(map[string]*session.Session)}
-	sessSvc := session.NewService(sessRepo, 24*time.Hour, 1*time.Hour)
+	sessSvc := session.NewService(memory.NewSessionRepository(memory.New()), audit.NewSlogLogger(), 24*time.Hour, 1*time.Hour)
 
 	// 2. Create session
 	ctx := context.Background()
-	sess, err := sessSvc.Create(ctx, nil, "user_123", "127.0.0.1", "test-agent", "admin")
+	sess, err := sessSvc.Create(ctx, strPtr("tenant-A"), "user_123", "127.0.0.1", "test-agent", "admin")
 	if err != nil {
 		t.Fatalf("failed to create session: %v", err)
 	}
-	sessRepo.sessions[sess.ID].TenantID = strPtr("tenant-A")
 
-	h := NewHandler(nil, sessSvc, nil, nil, nil, nil, audit.NewSlogLogger(), SessionConfig{CookieName: "session_id"}, "admin")
+	h := NewHandler(nil, sessSvc, nil, nil, nil, nil, nil, nil, nil, nil, audit.NewSlogLogger(), SessionConfig{CookieName: "session_id"}, APIDocsConfig{}, RouteTimeouts{}, nil, nil, nil, nil, nil, nil, "", "", false, "admin")
 
 	// Create Router with Middleware
 	r := chi.NewRouter()
@@ -349,6 +455,195 @@ func TestHTTP_Protocol_CrossTenant_Negative(t *testing.T) {
 		t.Errorf("expected 400 Bad Request for cross-tenant access, got %d", w.Code)
 	}
 }
+
+// TestPurpose: Validates that sessions are only accepted by the plane they were issued for.
+// Scope: Unit Test
+// Security: Session confusion prevention (a console session cannot authorize OAuth2 clients, an authorization session cannot use the admin API)
+// Expected: Admin routes accept admin sessions and refuse auth sessions with 403, the authorization endpoint the reverse, in every mode; login issues the requested namespace where the plane allows it.
+// Test Case ID: PRO-08
+func TestHTTP_Protocol_SessionNamespaces(t *testing.T) {
+	sessSvc := session.NewService(memory.NewSessionRepository(memory.New()), audit.NewSlogLogger(), 24*time.Hour, 1*time.Hour)
+	ctx := context.Background()
+	sessions := make(map[string]string)
+	for _, namespace := range []string{session.NamespaceAuth, session.NamespaceAdmin} {
+		sess, err := sessSvc.Create(ctx, strPtr("tenant-A"), "user_123", "127.0.0.1", "test-agent", namespace)
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		sessions[namespace] = sess.ID
+	}
+
+	for _, mode := range []string{"auth", "admin", "all"} {
+		h := NewHandler(nil, sessSvc, nil, nil, nil, nil, nil, nil, nil, nil, audit.NewSlogLogger(), SessionConfig{CookieName: "session_id"}, APIDocsConfig{}, RouteTimeouts{}, nil, nil, nil, nil, nil, nil, "", "", false, mode)
+		r := chi.NewRouter()
+		ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+		r.With(h.AuthMiddleware).Get("/admin", ok)
+		r.With(h.SessionMiddleware(session.NamespaceAuth)).Get("/authorize", ok)
+
+		for _, tt := range []struct {
+			path, namespace string
+			want            int
+		}{
+			{"/admin", session.NamespaceAdmin, http.StatusOK},
+			{"/admin", session.NamespaceAuth, http.StatusForbidden},
+			{"/authorize", session.NamespaceAuth, http.StatusOK},
+			{"/authorize", session.NamespaceAdmin, http.StatusForbidden},
+		} {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.AddCookie(&http.Cookie{Name: "session_id", Value: sessions[tt.namespace]})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("mode %s: %s with a %s session: expected %d, got %d", mode, tt.path, tt.namespace, tt.want, w.Code)
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		mode, requested, want string
+		fails                 bool
+	}{
+		{"auth", "", session.NamespaceAuth, false},
+		{"auth", "admin", "", true},
+		{"all", "", session.NamespaceAdmin, false},
+		{"all", "auth", session.NamespaceAuth, false},
+		{"all", "other", "", true},
+	} {
+		h := &Handler{mode: tt.mode}
+		got, err := h.loginNamespace(tt.requested)
+		if got != tt.want || (err != nil) != tt.fails {
+			t.Errorf("mode %s, namespace %q: got %q, %v", tt.mode, tt.requested, got, err)
+		}
+	}
+}
+
+// TestPurpose: Validates that an authorization request made before signing in resumes after login.
+// Scope: Unit Test
+// Security: Login CSRF and request tampering (only the validated request saved server-side resumes, once)
+// Expected: Without a session, or with prompt=select_account, the request is saved and its ID returned, or sent to the hosted login page; login with the ID issues an auth session and a redirect_to that issues the code with the saved state; the ID cannot be reused.
+// Test Case ID: PRO-09
+func TestHTTP_Protocol_PendingAuthorization(t *testing.T) {
+	db := memory.New()
+	clientRepo := memory.NewClientRepository(db)
+	if err := clientRepo.Create(context.Background(), &oauth2.Client{
+		ID:            "c1",
+		ClientID:      "client-1",
+		RedirectURIs:  []string{"https://app.com/cb"},
+		AllowedScopes: []string{"openid"},
+		IsActive:      true,
+		TenantID:      "tenant-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	oauth2Svc := oauth2.NewService(clientRepo, memory.NewAuthorizationCodeRepository(db), memory.NewAccessTokenRepository(db), memory.NewRefreshTokenRepository(db), audit.NewSlogLogger(), nil, 5*time.Minute, time.Hour, time.Hour)
+	oauth2Svc.EnablePendingAuthorizations(memory.NewPendingAuthorizationRepository(db))
+	sessSvc := session.NewService(memory.NewSessionRepository(db), audit.NewSlogLogger(), 24*time.Hour, time.Hour)
+	sess, err := sessSvc.Create(context.Background(), strPtr("tenant-1"), "user-1", "127.0.0.1", "test-agent", session.NamespaceAuth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorize := func(h *Handler, query string, sessionID string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := chi.NewRouter()
+		r.With(h.OptionalSessionMiddleware(session.NamespaceAuth)).Get("/oauth2/authorize", h.Authorize)
+		req := httptest.NewRequest("GET", "/oauth2/authorize?"+query, nil)
+		if sessionID != "" {
+			req.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	h := &Handler{oauth2Service: oauth2Svc, sessionService: sessSvc, auditLogger: audit.NewSlogLogger(), sessionConfig: SessionConfig{CookieName: "session_id"}}
+	query := url.Values{"client_id": {"client-1"}, "redirect_uri": {"https://app.com/cb"}, "response_type": {"code"}, "scope": {"openid"}, "state": {"xyz"}}.Encode()
+
+	w := authorize(h, query, "")
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusUnauthorized || body["authorization_request"] == "" {
+		t.Fatalf("expected 401 with an authorization_request, got %d: %s", w.Code, w.Body.String())
+	}
+	pendingID := body["authorization_request"]
+	if w := authorize(h, "authorization_request="+pendingID, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected resuming without a session to require login, got %d", w.Code)
+	}
+
+	resume, err := url.Parse(h.resumeAuthorizationURL(pendingID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = authorize(h, resume.RawQuery, sess.ID)
+	location, _ := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || location.Query().Get("code") == "" || location.Query().Get("state") != "xyz" {
+		t.Fatalf("expected the saved request to issue a code, got %d: %s", w.Code, w.Header().Get("Location"))
+	}
+	if w := authorize(h, resume.RawQuery, sess.ID); w.Code != http.StatusBadRequest {
+		t.Errorf("expected a resumed request not to resume again, got %d", w.Code)
+	}
+
+	// prompt=select_account sends a signed-in user back to login; the resumed request does not
+	w = authorize(h, query+"&prompt=select_account", sess.ID)
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusUnauthorized || body["authorization_request"] == "" {
+		t.Fatalf("expected select_account to require login, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := authorize(h, "authorization_request="+body["authorization_request"], sess.ID); w.Code != http.StatusFound {
+		t.Errorf("expected the request resumed after selecting an account to issue a code, got %d", w.Code)
+	}
+
+	hosted := *h
+	hosted.loginURL = "https://login.example.com/signin?lang=en"
+	w = authorize(&hosted, query, "")
+	location, _ = url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || location.Host != "login.example.com" || location.Query().Get("lang") != "en" || location.Query().Get("authorization_request") == "" {
+		t.Errorf("expected a redirect to the hosted login page, got %d: %s", w.Code, w.Header().Get("Location"))
+	}
+}
+
+// TestPurpose: Validates that only confidential clients authenticated with their secret can introspect tokens.
+// Scope: Unit Test
+// Security: Token disclosure (RFC 7662 Section 2.1); a public client_id is not a credential
+// Expected: A public client, a confidential client with a wrong secret and an unknown client get 401 invalid_client; the confidential client with its secret gets 200.
+// Test Case ID: PRO-13
+// RelatedSpecs: RFC 7662 Section 2.1
+func TestHTTP_Protocol_IntrospectionRequiresConfidentialClient(t *testing.T) {
+	db := memory.New()
+	clientRepo := memory.NewClientRepository(db)
+	secret := "resource-server-secret"
+	for _, c := range []*oauth2.Client{
+		{ID: "c1", ClientID: "spa", IsActive: true, TenantID: "tenant-1"},
+		{ID: "c2", ClientID: "api", ClientSecretHash: oauth2.HashClientSecret(secret), IsActive: true, TenantID: "tenant-1"},
+	} {
+		if err := clientRepo.Create(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oauth2Svc := oauth2.NewService(clientRepo, memory.NewAuthorizationCodeRepository(db), memory.NewAccessTokenRepository(db), memory.NewRefreshTokenRepository(db), audit.NewSlogLogger(), nil, 5*time.Minute, time.Hour, time.Hour)
+	h := &Handler{oauth2Service: oauth2Svc, auditLogger: audit.NewSlogLogger()}
+
+	introspect := func(form url.Values) *httptest.ResponseRecorder {
+		form.Set("token", "some-access-token")
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/introspect", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.Introspect(w, req)
+		return w
+	}
+
+	for name, form := range map[string]url.Values{
+		"public client":  {"client_id": {"spa"}},
+		"wrong secret":   {"client_id": {"api"}, "client_secret": {"guess"}},
+		"unknown client": {"client_id": {"missing"}},
+	} {
+		w := introspect(form)
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "invalid_client") {
+			t.Errorf("%s: expected 401 invalid_client, got %d %s", name, w.Code, w.Body)
+		}
+	}
+	if w := introspect(url.Values{"client_id": {"api"}, "client_secret": {secret}}); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"active":false`) {
+		t.Errorf("expected the confidential client to introspect, got %d %s", w.Code, w.Body)
+	}
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -15,14 +15,45 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/captcha"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"golang.org/x/time/rate"
 )
 
-// RateLimiter manages rate limiting for IPs
+// Rate limit layers, used as the "layer" metric attribute
+const (
+	RateLimitLayerIP     = "ip"
+	RateLimitLayerTenant = "tenant"
+	RateLimitLayerClient = "client"
+	RateLimitLayerUser   = "user"
+)
+
+// Standard rate limit response headers (draft-ietf-httpapi-ratelimit-headers)
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// maxLoginBodySize bounds how much of a login body is read to find the user
+const maxLoginBodySize = 1 << 20
+
+// RateLimiter manages token bucket rate limits per key (an IP, tenant, OAuth2 client or user)
 type RateLimiter struct {
 	ips             map[string]*rate.Limiter
 	mu              sync.RWMutex
@@ -46,20 +77,76 @@ func NewRateLimiter(rps float64, burst int) *RateLimiter {
 	return rl
 }
 
-// GetLimiter returns a limiter for an IP
-func (rl *RateLimiter) GetLimiter(ip string) *rate.Limiter {
+// GetLimiter returns the limiter for a key
+func (rl *RateLimiter) GetLimiter(key string) *rate.Limiter {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	limiter, exists := rl.ips[ip]
+	limiter, exists := rl.ips[key]
 	if !exists {
 		limiter = rate.NewLimiter(rl.rps, rl.burst)
-		rl.ips[ip] = limiter
+		rl.ips[key] = limiter
 	}
 
 	return limiter
 }
 
+// rateStatus is the state of a key's bucket after a request was counted against it
+type rateStatus struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration // Until the bucket is full again
+	retryAfter time.Duration // Until the next request is allowed
+}
+
+// allow counts a request against the key's bucket
+func (rl *RateLimiter) allow(key string) rateStatus {
+	return take(rl.GetLimiter(key), time.Now())
+}
+
+// allowLimit counts a request against the key's bucket, which takes l in place of the limiter's
+// own rate and burst
+func (rl *RateLimiter) allowLimit(key string, l RateLimit) rateStatus {
+	now := time.Now()
+	rl.mu.Lock()
+	limiter, exists := rl.ips[key]
+	if !exists {
+		limiter = rate.NewLimiter(rate.Limit(l.RequestsPerSecond), l.Burst)
+		rl.ips[key] = limiter
+	}
+	rl.mu.Unlock()
+
+	// The tenant may have changed its limit since the bucket was created
+	if limiter.Limit() != rate.Limit(l.RequestsPerSecond) {
+		limiter.SetLimitAt(now, rate.Limit(l.RequestsPerSecond))
+	}
+	if limiter.Burst() != l.Burst {
+		limiter.SetBurstAt(now, l.Burst)
+	}
+	return take(limiter, now)
+}
+
+// take counts a request against limiter at now
+func take(limiter *rate.Limiter, now time.Time) rateStatus {
+	allowed := limiter.AllowN(now, 1)
+	tokens := limiter.TokensAt(now)
+	rps, burst := float64(limiter.Limit()), limiter.Burst()
+
+	status := rateStatus{
+		allowed:   allowed,
+		limit:     burst,
+		remaining: max(int(math.Floor(tokens)), 0),
+	}
+	if rps > 0 {
+		status.reset = time.Duration((float64(burst) - tokens) / rps * float64(time.Second))
+		if tokens < 1 {
+			status.retryAfter = time.Duration((1 - tokens) / rps * float64(time.Second))
+		}
+	}
+	return status
+}
+
 // cleanup removes old entries (simplified: just clear all every interval for now to prevent memory leak)
 // In production, we'd track last access time per IP
 func (rl *RateLimiter) cleanup() {
@@ -73,23 +160,250 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
-// RateLimitMiddleware creates a middleware for rate limiting
-func RateLimitMiddleware(rl *RateLimiter) func(http.Handler) http.Handler {
+// RateLimit configures one layer; a zero RequestsPerSecond disables it
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// TenantRateLimits returns the login (User layer) and OAuth2 client (Client layer) limits a tenant
+// set for itself; a zero RateLimit leaves only the platform's
+type TenantRateLimits func(ctx context.Context, tenantID string) (login, client RateLimit)
+
+// RateLimitConfig configures each rate limiting layer independently
+type RateLimitConfig struct {
+	IP     RateLimit // Every request, by client IP
+	Tenant RateLimit // Tenant-scoped admin API and the token endpoint, by tenant
+	Client RateLimit // Token and revocation endpoints, by OAuth2 client_id
+	User   RateLimit // Login, by submitted email
+	// Tenants adds each tenant's own limits to the User and Client layers; nil adds none
+	Tenants TenantRateLimits
+	// ClientAuth bans client_ids and addresses after failed client authentications on the OAuth2 endpoints
+	ClientAuth ClientAuthBackoff
+	// Abuse flags the client address of every rejected request, so login asks it for a challenge; nil flags none
+	Abuse *captcha.Flags
+}
+
+// RateLimits holds the limiter of each layer; a nil limiter disables its layer
+type RateLimits struct {
+	IP     *RateLimiter
+	Tenant *RateLimiter
+	Client *RateLimiter
+	User   *RateLimiter
+
+	clientAuth *authFailures // nil disables the bans
+	abuse      *captcha.Flags
+	requests   metric.Int64Counter
+
+	// Buckets of the tenants that set their own limits, keyed by tenant and then user or client
+	tenants      TenantRateLimits
+	tenantUser   *RateLimiter
+	tenantClient *RateLimiter
+}
+
+// NewRateLimits creates the limiters for the enabled layers
+func NewRateLimits(cfg RateLimitConfig, meter *metrics.Meter) (*RateLimits, error) {
+	requests, err := meter.CreateCounter("http.ratelimit.requests", "Requests checked against a rate limit, by layer and outcome")
+	if err != nil {
+		return nil, err
+	}
+	newLayer := func(l RateLimit) *RateLimiter {
+		if l.RequestsPerSecond <= 0 {
+			return nil
+		}
+		return NewRateLimiter(l.RequestsPerSecond, max(l.Burst, 1))
+	}
+	l := &RateLimits{
+		IP:         newLayer(cfg.IP),
+		Tenant:     newLayer(cfg.Tenant),
+		Client:     newLayer(cfg.Client),
+		User:       newLayer(cfg.User),
+		clientAuth: newAuthFailures(cfg.ClientAuth),
+		abuse:      cfg.Abuse,
+		requests:   requests,
+		tenants:    cfg.Tenants,
+	}
+	if cfg.Tenants != nil {
+		l.tenantUser = NewRateLimiter(0, 1)
+		l.tenantClient = NewRateLimiter(0, 1)
+	}
+	return l, nil
+}
+
+// limit returns a middleware counting each request against the bucket of the key it maps to.
+// Requests without a key, or any request when the layer is disabled, pass unchecked.
+// The most restrictive layer seen by a request is reported in the RateLimit-* headers.
+func (l *RateLimits) limit(layer string, key func(r *http.Request) string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
+		rl := l.layer(layer)
+		if rl == nil {
+			return next
+		}
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := getClientIP(r)
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			limiter := rl.GetLimiter(ip)
-			if !limiter.Allow() {
-				respondError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			if !l.check(w, r, layer, rl.allow(k)) {
 				return
 			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
+// limitTenant returns a middleware counting each request against the limit its tenant set for
+// layer, on top of the platform's. Requests without a tenant or key, or of tenants without a
+// limit of their own, pass unchecked.
+func (l *RateLimits) limitTenant(layer string, tenantKey, key func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if l == nil || l.tenants == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !l.allowTenant(w, r, layer, tenantKey(r), key(r)) {
+				return
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// allowTenant counts a request for key against the limit tenantID set for layer (RateLimitLayerUser
+// or RateLimitLayerClient), and answers it with 429 when that limit is exhausted. It reports
+// whether the request may proceed.
+func (l *RateLimits) allowTenant(w http.ResponseWriter, r *http.Request, layer, tenantID, key string) bool {
+	if l == nil || l.tenants == nil || tenantID == "" || key == "" {
+		return true
+	}
+	login, client := l.tenants(r.Context(), tenantID)
+	limit, rl := login, l.tenantUser
+	if layer == RateLimitLayerClient {
+		limit, rl = client, l.tenantClient
+	}
+	if limit.RequestsPerSecond <= 0 {
+		return true
+	}
+	limit.Burst = max(limit.Burst, 1)
+	return l.check(w, r, layer, rl.allowLimit(tenantID+"\x00"+key, limit))
+}
+
+// check reports status and answers the request with 429 when it was rejected
+func (l *RateLimits) check(w http.ResponseWriter, r *http.Request, layer string, status rateStatus) bool {
+	setRateLimitHeaders(w, status)
+	l.record(r, layer, status.allowed)
+	if !status.allowed {
+		l.abuse.Flag(getClientIP(r))
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(status.retryAfter)))
+		respondError(w, http.StatusTooManyRequests, "rate limit exceeded")
+	}
+	return status.allowed
+}
+
+// layer returns the limiter of a layer, or nil if it is disabled
+func (l *RateLimits) layer(name string) *RateLimiter {
+	if l == nil {
+		return nil
+	}
+	switch name {
+	case RateLimitLayerIP:
+		return l.IP
+	case RateLimitLayerTenant:
+		return l.Tenant
+	case RateLimitLayerClient:
+		return l.Client
+	case RateLimitLayerUser:
+		return l.User
+	}
+	return nil
+}
+
+func (l *RateLimits) record(r *http.Request, layer string, allowed bool) {
+	if l.requests == nil {
+		return
+	}
+	outcome := "allowed"
+	if !allowed {
+		outcome = "rejected"
+	}
+	l.requests.Add(r.Context(), 1, metric.WithAttributes(
+		attribute.String("layer", layer),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// setRateLimitHeaders reports status unless an earlier layer left fewer requests remaining
+func setRateLimitHeaders(w http.ResponseWriter, status rateStatus) {
+	if current := w.Header().Get(HeaderRateLimitRemaining); current != "" {
+		if remaining, err := strconv.Atoi(current); err == nil && remaining <= status.remaining && status.allowed {
+			return
+		}
+	}
+	w.Header().Set(HeaderRateLimitLimit, strconv.Itoa(status.limit))
+	w.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(status.remaining))
+	w.Header().Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(status.reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// tenantKey keys tenant-scoped routes by the tenant in the URL
+func tenantKey(r *http.Request) string {
+	return chi.URLParam(r, "tenantID")
+}
+
+// clientKey keys OAuth2 endpoints by the client_id in the form or Basic credentials
+func clientKey(r *http.Request) string {
+	if err := r.ParseForm(); err == nil {
+		if clientID := r.Form.Get("client_id"); clientID != "" {
+			return clientID
+		}
+	}
+	if username, _, ok := r.BasicAuth(); ok {
+		return username
+	}
+	return ""
+}
+
+// clientTenantKey keys OAuth2 endpoints by the tenant owning the requesting client
+func (h *Handler) clientTenantKey(r *http.Request) string {
+	clientID := clientKey(r)
+	if clientID == "" || h.oauth2Service == nil {
+		return ""
+	}
+	client, err := h.oauth2Service.GetClientByClientID(r.Context(), clientID)
+	if err != nil {
+		return ""
+	}
+	return client.TenantID
+}
+
+// loginKey keys login attempts by the submitted email, leaving the body readable for the handler
+func loginKey(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxLoginBodySize))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var req LoginRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	return userKey(req.Email)
+}
+
+// userKey keys login attempts by email, whatever its case
+func userKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // getClientIP extracts IP from request (handling proxies)
 func getClientIP(r *http.Request) string {
 	// Check X-Forwarded-For
@@ -97,6 +411,9 @@ func getClientIP(r *http.Request) string {
 	if forwarded != "" {
 		return forwarded
 	}
-	// Fallback to RemoteAddr
+	// Fallback to RemoteAddr, without the port so every connection from a host shares a bucket
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
 	return r.RemoteAddr
 }
@@ -0,0 +1,201 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/captcha"
+)
+
+// TestRateLimits tests that each layer limits its own key and reports the most restrictive layer
+func TestRateLimits(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	t.Run("ip", func(t *testing.T) {
+		limits := &RateLimits{IP: NewRateLimiter(0.001, 2), abuse: captcha.NewFlags(5, time.Hour)}
+		h := limits.limit(RateLimitLayerIP, getClientIP)(ok)
+		serve := func(remoteAddr string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/health", nil)
+			req.RemoteAddr = remoteAddr
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			return w
+		}
+
+		w := serve("192.0.2.1:1000")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected first request to pass, got %d", w.Code)
+		}
+		if w.Header().Get(HeaderRateLimitLimit) != "2" || w.Header().Get(HeaderRateLimitRemaining) != "1" {
+			t.Errorf("unexpected rate limit headers: %v", w.Header())
+		}
+		serve("192.0.2.1:1001") // Another connection from the same host shares the bucket
+		w = serve("192.0.2.1:1002")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429 once the burst is used, got %d", w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" || w.Header().Get(HeaderRateLimitRemaining) != "0" {
+			t.Errorf("expected Retry-After and no remaining requests, got %v", w.Header())
+		}
+		if !limits.abuse.Flagged("192.0.2.1") {
+			t.Error("expected the rejected address to be flagged for abuse")
+		}
+		if w := serve("192.0.2.2:1000"); w.Code != http.StatusNoContent || limits.abuse.Flagged("192.0.2.2") {
+			t.Errorf("expected another IP to be unaffected, got %d", w.Code)
+		}
+	})
+
+	t.Run("client", func(t *testing.T) {
+		limits := &RateLimits{Client: NewRateLimiter(0.001, 1)}
+		h := limits.limit(RateLimitLayerClient, clientKey)(ok)
+		serve := func(body string, basicUser string) int {
+			req := httptest.NewRequest("POST", "/oauth2/token", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if basicUser != "" {
+				req.SetBasicAuth(basicUser, "secret")
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		if code := serve("grant_type=refresh_token&client_id=c1", ""); code != http.StatusNoContent {
+			t.Fatalf("expected first request to pass, got %d", code)
+		}
+		if code := serve("grant_type=refresh_token", "c1"); code != http.StatusTooManyRequests {
+			t.Errorf("expected Basic credentials to share the client's bucket, got %d", code)
+		}
+		if code := serve("grant_type=refresh_token&client_id=c2", ""); code != http.StatusNoContent {
+			t.Errorf("expected another client to be unaffected, got %d", code)
+		}
+		if code := serve("grant_type=refresh_token", ""); code != http.StatusNoContent {
+			t.Errorf("expected requests without a client to pass, got %d", code)
+		}
+	})
+
+	t.Run("user", func(t *testing.T) {
+		limits := &RateLimits{User: NewRateLimiter(0.001, 1)}
+		var body string
+		h := limits.limit(RateLimitLayerUser, loginKey)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = string(b)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		serve := func(payload string) int {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(payload)))
+			return w.Code
+		}
+
+		login := `{"email":"Admin@example.com","password":"x"}`
+		if code := serve(login); code != http.StatusNoContent || body != login {
+			t.Fatalf("expected login to pass with its body intact, got %d %q", code, body)
+		}
+		if code := serve(`{"email":"admin@example.com ","password":"y"}`); code != http.StatusTooManyRequests {
+			t.Errorf("expected the same user to be limited regardless of case, got %d", code)
+		}
+		if code := serve(`{"email":"other@example.com","password":"x"}`); code != http.StatusNoContent {
+			t.Errorf("expected another user to be unaffected, got %d", code)
+		}
+	})
+
+	t.Run("layers", func(t *testing.T) {
+		limits := &RateLimits{IP: NewRateLimiter(0.001, 10), Tenant: NewRateLimiter(0.001, 2)}
+		r := chi.NewRouter()
+		r.Use(limits.limit(RateLimitLayerIP, getClientIP))
+		r.With(limits.limit(RateLimitLayerTenant, tenantKey)).Get("/tenants/{tenantID}", ok)
+
+		serve := func(target string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+			return w
+		}
+		w := serve("/tenants/t1")
+		if w.Header().Get(HeaderRateLimitLimit) != "2" || w.Header().Get(HeaderRateLimitRemaining) != "1" {
+			t.Errorf("expected the tenant layer to be reported, got %v", w.Header())
+		}
+		serve("/tenants/t1")
+		if w := serve("/tenants/t1"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("expected the tenant limit to apply, got %d", w.Code)
+		}
+		if w := serve("/tenants/t2"); w.Code != http.StatusNoContent {
+			t.Errorf("expected another tenant to be unaffected, got %d", w.Code)
+		}
+	})
+
+	t.Run("tenant", func(t *testing.T) {
+		tenantLimit := RateLimit{RequestsPerSecond: 0.001, Burst: 1}
+		limits := &RateLimits{
+			tenants: func(ctx context.Context, tenantID string) (RateLimit, RateLimit) {
+				if tenantID == "strict" {
+					return RateLimit{}, tenantLimit
+				}
+				return RateLimit{}, RateLimit{}
+			},
+			tenantClient: NewRateLimiter(0, 1),
+		}
+		tenantOf := func(r *http.Request) string { return r.URL.Query().Get("tenant") }
+		h := limits.limitTenant(RateLimitLayerClient, tenantOf, clientKey)(ok)
+		serve := func(tenantID, clientID string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/oauth2/token?tenant="+tenantID, strings.NewReader("client_id="+clientID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			return w
+		}
+
+		if w := serve("strict", "c1"); w.Code != http.StatusNoContent || w.Header().Get(HeaderRateLimitLimit) != "1" {
+			t.Fatalf("expected the first request to pass under the tenant's limit, got %d %v", w.Code, w.Header())
+		}
+		if w := serve("strict", "c1"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("expected the tenant's limit to apply, got %d", w.Code)
+		}
+		if w := serve("strict", "c2"); w.Code != http.StatusNoContent {
+			t.Errorf("expected another client of the tenant to have its own bucket, got %d", w.Code)
+		}
+		for range 3 {
+			if w := serve("relaxed", "c1"); w.Code != http.StatusNoContent || w.Header().Get(HeaderRateLimitLimit) != "" {
+				t.Fatalf("expected a tenant without limits of its own to pass unchecked, got %d", w.Code)
+			}
+		}
+
+		tenantLimit = RateLimit{RequestsPerSecond: 1000, Burst: 5}
+		serve("strict", "c1")             // Takes the new rate; tokens used so far stay used
+		time.Sleep(10 * time.Millisecond) // Enough to refill at the new rate
+		if w := serve("strict", "c1"); w.Code != http.StatusNoContent || w.Header().Get(HeaderRateLimitLimit) != "5" {
+			t.Errorf("expected the bucket to take the tenant's new limit, got %d %v", w.Code, w.Header())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var limits *RateLimits
+		w := httptest.NewRecorder()
+		limits.limit(RateLimitLayerIP, getClientIP)(ok).ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		if w.Code != http.StatusNoContent || w.Header().Get(HeaderRateLimitLimit) != "" {
+			t.Errorf("expected a disabled layer to pass without headers, got %d %v", w.Code, w.Header())
+		}
+	})
+}
@@ -44,9 +44,9 @@ func TestRouterSeparation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// We use a safe rate limiter
-			rl := transportHTTP.NewRateLimiter(100, 100)
+			rl := &transportHTTP.RateLimits{IP: transportHTTP.NewRateLimiter(100, 100)}
 
-			r := transportHTTP.NewRouter(h, rl, tt.mode)
+			r := transportHTTP.NewRouter(h, rl, nil, tt.mode)
 
 			req := httptest.NewRequest(tt.method, tt.path, nil)
 
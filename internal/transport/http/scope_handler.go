@@ -0,0 +1,315 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/oidc"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// tenantDiscoveryMaxAge is kept short so that scope changes reach consent screens soon
+const tenantDiscoveryMaxAge = 5 * time.Minute
+
+// ScopeRequest represents a tenant's custom OAuth2 scope
+type ScopeRequest struct {
+	Name        string   `json:"name" example:"orders:read"` // Create only; the name cannot be changed
+	Description string   `json:"description" binding:"required" example:"Read your order history"`
+	Claims      []string `json:"claims" example:"[\"email\", \"name\"]"`  // User claims released in the ID token
+	Permissions []string `json:"permissions" example:"[\"orders.read\"]"` // Reported by token introspection
+}
+
+// ScopeResponse represents a custom scope
+type ScopeResponse struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Claims      []string  `json:"claims"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListScopesResponse lists a tenant's custom scopes
+type ListScopesResponse struct {
+	Scopes []ScopeResponse `json:"scopes"`
+}
+
+func newScopeResponse(s *oauth2.ScopeDefinition) ScopeResponse {
+	resp := ScopeResponse{
+		ID:          s.ID,
+		TenantID:    s.TenantID,
+		Name:        s.Name,
+		Description: s.Description,
+		Claims:      s.Claims,
+		Permissions: s.Permissions,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+	if resp.Claims == nil {
+		resp.Claims = []string{}
+	}
+	if resp.Permissions == nil {
+		resp.Permissions = []string{}
+	}
+	return resp
+}
+
+// canManageScopes checks the client management permission that guards every scope route
+func (h *Handler) canManageScopes(w http.ResponseWriter, r *http.Request, tenantID string) bool {
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageClients)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "client management access required")
+		return false
+	}
+	return true
+}
+
+// respondScopeError maps scope service errors to HTTP responses
+func respondScopeError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, oauth2.ErrInvalidScopeDefinition):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, oauth2.ErrScopeNotFound):
+		respondError(w, http.StatusNotFound, "scope not found")
+	case errors.Is(err, oauth2.ErrScopeAlreadyExists):
+		respondError(w, http.StatusConflict, "scope already exists")
+	default:
+		respondError(w, http.StatusInternalServerError, "failed to "+action)
+	}
+}
+
+// ListScopes handles listing a tenant's custom scopes
+// @Summary List Scopes
+// @Description List the tenant's custom OAuth2 scopes
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 200 {object} ListScopesResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/scopes [get]
+func (h *Handler) ListScopes(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Client management permission required
+	if !h.canManageScopes(w, r, tenantID) {
+		return
+	}
+
+	scopes, err := h.oauth2Service.ListScopes(r.Context(), tenantID)
+	if err != nil {
+		respondScopeError(w, err, "list scopes")
+		return
+	}
+
+	resp := ListScopesResponse{Scopes: make([]ScopeResponse, 0, len(scopes))}
+	for _, s := range scopes {
+		resp.Scopes = append(resp.Scopes, newScopeResponse(s))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// CreateScope handles defining a custom scope
+// @Summary Create Scope
+// @Description Define a custom OAuth2 scope that the tenant's clients can be allowed to request. Standard scopes such as openid and profile cannot be redefined.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body ScopeRequest true "Scope"
+// @Success 201 {object} ScopeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /tenants/{tenantID}/scopes [post]
+func (h *Handler) CreateScope(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Client management permission required
+	if !h.canManageScopes(w, r, tenantID) {
+		return
+	}
+
+	var req ScopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	scope, err := h.oauth2Service.CreateScope(r.Context(), tenantID, GetUserID(r.Context()), oauth2.ScopeInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Claims:      req.Claims,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		respondScopeError(w, err, "create scope")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, newScopeResponse(scope))
+}
+
+// GetScope handles retrieving a custom scope
+// @Summary Get Scope
+// @Description Get one of the tenant's custom scopes by name
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param scopeName path string true "Scope name"
+// @Success 200 {object} ScopeResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/scopes/{scopeName} [get]
+func (h *Handler) GetScope(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Client management permission required
+	if !h.canManageScopes(w, r, tenantID) {
+		return
+	}
+
+	scope, err := h.oauth2Service.GetScope(r.Context(), tenantID, chi.URLParam(r, "scopeName"))
+	if err != nil {
+		respondScopeError(w, err, "get scope")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newScopeResponse(scope))
+}
+
+// UpdateScope handles replacing a custom scope
+// @Summary Update Scope
+// @Description Replace a scope's description, claims and permissions. Tokens already issued with the scope report the new permissions when introspected.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param scopeName path string true "Scope name"
+// @Param request body ScopeRequest true "Scope"
+// @Success 200 {object} ScopeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/scopes/{scopeName} [put]
+func (h *Handler) UpdateScope(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Client management permission required
+	if !h.canManageScopes(w, r, tenantID) {
+		return
+	}
+
+	var req ScopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	scope, err := h.oauth2Service.UpdateScope(r.Context(), tenantID, chi.URLParam(r, "scopeName"), GetUserID(r.Context()), oauth2.ScopeInput{
+		Description: req.Description,
+		Claims:      req.Claims,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		respondScopeError(w, err, "update scope")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newScopeResponse(scope))
+}
+
+// DeleteScope handles removing a custom scope
+// @Summary Delete Scope
+// @Description Remove a custom scope. Clients that still allow it can no longer request it.
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param scopeName path string true "Scope name"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/scopes/{scopeName} [delete]
+func (h *Handler) DeleteScope(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Client management permission required
+	if !h.canManageScopes(w, r, tenantID) {
+		return
+	}
+
+	if err := h.oauth2Service.DeleteScope(r.Context(), tenantID, chi.URLParam(r, "scopeName"), GetUserID(r.Context())); err != nil {
+		respondScopeError(w, err, "delete scope")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TenantDiscovery returns the OpenID Connect metadata extended with a tenant's custom scopes
+// @Summary Tenant OIDC Discovery
+// @Description Returns the OpenID Connect metadata with the tenant's custom scopes added to scopes_supported, and their descriptions and released claims in scope_descriptions, for relying parties and consent screens
+// @Tags OIDC
+// @Produce json
+// @Param tenantID path string true "Tenant ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} oidc.TenantDiscoveryMetadata
+// @Success 304
+// @Failure 404 {object} map[string]string
+// @Router /.well-known/openid-configuration/tenants/{tenantID} [get]
+func (h *Handler) TenantDiscovery(w http.ResponseWriter, r *http.Request) {
+	tenantID := chi.URLParam(r, "tenantID")
+	ctx := tenant.WithScope(r.Context(), tenantID)
+	if _, err := h.tenantService.GetTenant(ctx, tenantID); err != nil {
+		respondError(w, http.StatusNotFound, "tenant not found")
+		return
+	}
+
+	scopes, err := h.oauth2Service.ListScopes(ctx, tenantID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to build discovery metadata")
+		return
+	}
+	descriptions := make(map[string]oidc.ScopeDescription, len(scopes))
+	for _, s := range scopes {
+		descriptions[s.Name] = oidc.ScopeDescription{Description: s.Description, Claims: s.Claims}
+	}
+
+	doc, err := h.oidcService.TenantDiscoveryDocument(tenantID, descriptions)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to build discovery metadata")
+		return
+	}
+	serveDocument(w, r, doc, tenantDiscoveryMaxAge)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/oidc"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+func newScopeTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	db := newClientTestStore(t)
+	if err := memory.NewTenantRepository(db).Create(context.Background(), &tenant.Tenant{ID: "t1", Name: "Acme", Status: "active"}); err != nil {
+		t.Fatal(err)
+	}
+	oauth2Svc := oauth2.NewService(memory.NewClientRepository(db), nil, nil, nil, audit.NewSlogLogger(), nil, 0, 0, 0)
+	oauth2Svc.SetScopes(memory.NewScopeRepository(db), nil)
+	oidcSvc, err := oidc.NewService("https://auth.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{
+		oauth2Service: oauth2Svc,
+		oidcService:   oidcSvc,
+		tenantService: tenant.NewService(memory.NewTenantRepository(db), nil, nil, audit.NewSlogLogger()),
+		authzService:  authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/.well-known/openid-configuration/tenants/{tenantID}", h.TenantDiscovery)
+	r.Route("/scopes", func(r chi.Router) {
+		r.Get("/", h.ListScopes)
+		r.Post("/", h.CreateScope)
+		r.Route("/{scopeName}", func(r chi.Router) {
+			r.Get("/", h.GetScope)
+			r.Put("/", h.UpdateScope)
+			r.Delete("/", h.DeleteScope)
+		})
+	})
+	return r
+}
+
+// TestScopeHandlers tests custom scope management and the tenant discovery document that describes the scopes
+func TestScopeHandlers(t *testing.T) {
+	r := newScopeTestRouter(t)
+
+	if w := webhookRequest(r, "POST", "u2", "/scopes/", `{"name":"orders:read","description":"Read orders"}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for user without client access, got %d", w.Code)
+	}
+	if w := webhookRequest(r, "POST", "u1", "/scopes/", `{"name":"email","description":"Email"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when redefining a standard scope, got %d", w.Code)
+	}
+
+	body := `{"name":"orders:read","description":"Read your orders","claims":["email"],"permissions":["orders.read"]}`
+	w := webhookRequest(r, "POST", "u1", "/scopes/", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body: %s", w.Code, w.Body.String())
+	}
+	if w := webhookRequest(r, "POST", "u1", "/scopes/", body); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate name, got %d", w.Code)
+	}
+
+	w = webhookRequest(r, "PUT", "u1", "/scopes/orders:read/", `{"description":"Read your order history","permissions":["orders.read","orders.export"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	var updated ScopeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Description != "Read your order history" || len(updated.Claims) != 0 || len(updated.Permissions) != 2 {
+		t.Errorf("expected the definition to be replaced, got %+v", updated)
+	}
+
+	req := httptest.NewRequest("GET", "/.well-known/openid-configuration/tenants/t1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	var meta oidc.TenantDiscoveryMetadata
+	if err := json.Unmarshal(w.Body.Bytes(), &meta); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(meta.ScopesSupported, "orders:read") || meta.ScopeDescriptions["orders:read"].Description != "Read your order history" {
+		t.Errorf("expected the custom scope in the tenant metadata, got %+v", meta)
+	}
+	req = httptest.NewRequest("GET", "/.well-known/openid-configuration/tenants/missing", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown tenant, got %d", w.Code)
+	}
+
+	if w := webhookRequest(r, "DELETE", "u1", "/scopes/orders:read/", ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w := webhookRequest(r, "GET", "u1", "/scopes/orders:read/", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/opentrusty/opentrusty/internal/observability/redact"
+)
+
+// Secret guard modes selectable with SECURITY_SECRET_GUARD
+const (
+	SecretGuardOff   = "off"
+	SecretGuardLog   = "log"
+	SecretGuardBlock = "block"
+)
+
+type secretFieldsKey struct{}
+
+// designateSecretFields lets fields of the response carry a secret on purpose, such as a newly
+// issued client_secret; the secret guard passes them
+func designateSecretFields(r *http.Request, fields ...string) {
+	if designated, ok := r.Context().Value(secretFieldsKey{}).(*[]string); ok {
+		*designated = append(*designated, fields...)
+	}
+}
+
+// SecretGuardMiddleware holds back JSON responses until they are scanned for non-empty fields
+// named like secrets (passwords, client secrets, tokens, hashes; see redact.SensitiveKey) that
+// the handler did not designate. A finding is logged with the field paths, never the values; in
+// block mode (the default) the response is replaced by a 500 so the secret never leaves.
+func (h *Handler) SecretGuardMiddleware(next http.Handler) http.Handler {
+	if h.secretGuard == SecretGuardOff {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var designated []string
+		r = r.WithContext(context.WithValue(r.Context(), secretFieldsKey{}, &designated))
+		gw := &guardWriter{ResponseWriter: w}
+		next.ServeHTTP(gw, r)
+		if !gw.buffering {
+			return
+		}
+
+		body := gw.body.Bytes()
+		if fields := redact.ScanJSON(body, designated...); len(fields) > 0 {
+			slog.ErrorContext(r.Context(), "response contains secret fields",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"fields", fields,
+				"blocked", h.secretGuard != SecretGuardLog,
+			)
+			if h.secretGuard != SecretGuardLog {
+				w.Header().Del("ETag")
+				w.Header().Del("Location")
+				respondError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+		}
+		w.WriteHeader(gw.status)
+		w.Write(body)
+	})
+}
+
+// guardWriter buffers JSON responses and passes any other content through
+type guardWriter struct {
+	http.ResponseWriter
+	status    int
+	decided   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (g *guardWriter) WriteHeader(status int) {
+	if g.decided {
+		return
+	}
+	g.decided = true
+	g.status = status
+	mediaType, _, _ := mime.ParseMediaType(g.Header().Get("Content-Type"))
+	g.buffering = mediaType == "application/json"
+	if !g.buffering {
+		g.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (g *guardWriter) Write(b []byte) (int, error) {
+	if !g.decided {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.buffering {
+		return g.body.Write(b)
+	}
+	return g.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (g *guardWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
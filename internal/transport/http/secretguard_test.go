@@ -0,0 +1,62 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestPurpose: Validates that responses leaking an undesignated secret never leave the server.
+// Scope: Unit Test
+// Security: Credential disclosure (e.g. a stored token or secret hash serialized into an API response)
+// Expected: Block mode replaces a JSON response with an undesignated token_hash by a 500 without the value, designated secrets and non-JSON bodies pass, and log mode passes the response unchanged.
+// Test Case ID: SEC-14
+func TestSecretGuard(t *testing.T) {
+	endpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/leak":
+			w.Header().Set("ETag", `"1"`)
+			respondJSON(w, http.StatusCreated, map[string]string{"id": "t1", "token_hash": "c2VjcmV0"})
+		case "/issued":
+			designateSecretFields(r, "client_secret")
+			respondJSON(w, http.StatusCreated, map[string]string{"client_id": "c1", "client_secret": "c2VjcmV0"})
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`{"password":"c2VjcmV0"}`))
+		}
+	})
+	send := func(mode, path string) *httptest.ResponseRecorder {
+		h := &Handler{secretGuard: mode}
+		w := httptest.NewRecorder()
+		h.SecretGuardMiddleware(endpoint).ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := send(SecretGuardBlock, "/leak")
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "c2VjcmV0") || w.Header().Get("ETag") != "" {
+		t.Errorf("expected the leaking response to be blocked, got %d %s (ETag %q)", w.Code, w.Body, w.Header().Get("ETag"))
+	}
+	for _, path := range []string{"/issued", "/text"} {
+		if w := send("", path); !strings.Contains(w.Body.String(), "c2VjcmV0") {
+			t.Errorf("%s: expected the response to pass, got %d %s", path, w.Code, w.Body)
+		}
+	}
+	if w := send(SecretGuardLog, "/leak"); w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), "token_hash") {
+		t.Errorf("expected log mode to pass the response, got %d %s", w.Code, w.Body)
+	}
+}
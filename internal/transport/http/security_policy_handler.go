@@ -0,0 +1,197 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// SecuritySettings are a tenant's lockout, rate limit and password age settings; in a request, settings left out
+// inherit the platform's
+type SecuritySettings struct {
+	LockoutMaxAttempts     int     `json:"lockout_max_attempts,omitempty" example:"3"`        // Failed logins that lock an account
+	LockoutDurationSeconds int     `json:"lockout_duration_seconds,omitempty" example:"3600"` // How long a locked account stays locked
+	LoginRPS               float64 `json:"login_rps,omitempty" example:"0.05"`                // Login attempts per second for one user
+	LoginBurst             int     `json:"login_burst,omitempty" example:"3"`
+	TokenRPS               float64 `json:"token_rps,omitempty" example:"5"` // Token endpoint requests per second for one OAuth2 client
+	TokenBurst             int     `json:"token_burst,omitempty" example:"10"`
+	PasswordMaxAgeSeconds  int     `json:"password_max_age_seconds,omitempty" example:"7776000"` // Password age after which the user must change it at the next login
+}
+
+func newSecuritySettings(s tenant.SecuritySettings) SecuritySettings {
+	return SecuritySettings{
+		LockoutMaxAttempts:     s.LockoutMaxAttempts,
+		LockoutDurationSeconds: int(s.LockoutDuration / time.Second),
+		LoginRPS:               s.LoginRPS,
+		LoginBurst:             s.LoginBurst,
+		TokenRPS:               s.TokenRPS,
+		TokenBurst:             s.TokenBurst,
+		PasswordMaxAgeSeconds:  int(s.PasswordMaxAge / time.Second),
+	}
+}
+
+func (s SecuritySettings) settings() tenant.SecuritySettings {
+	return tenant.SecuritySettings{
+		LockoutMaxAttempts: s.LockoutMaxAttempts,
+		LockoutDuration:    time.Duration(s.LockoutDurationSeconds) * time.Second,
+		LoginRPS:           s.LoginRPS,
+		LoginBurst:         s.LoginBurst,
+		TokenRPS:           s.TokenRPS,
+		TokenBurst:         s.TokenBurst,
+		PasswordMaxAge:     time.Duration(s.PasswordMaxAgeSeconds) * time.Second,
+	}
+}
+
+// SecurityPolicyResponse represents a tenant's security policy and the settings in force
+type SecurityPolicyResponse struct {
+	TenantID  string           `json:"tenant_id"`
+	Settings  SecuritySettings `json:"settings"`  // The tenant's own settings
+	Effective SecuritySettings `json:"effective"` // The tenant's settings over the platform's
+	Platform  SecuritySettings `json:"platform"`  // The loosest settings a tenant may choose
+	Strictest SecuritySettings `json:"strictest"` // The strictest settings a tenant may choose
+	UpdatedBy string           `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time       `json:"updated_at,omitempty"`
+}
+
+func (h *Handler) newSecurityPolicyResponse(p *tenant.SecurityPolicy) SecurityPolicyResponse {
+	bounds := h.tenantService.SecurityBounds()
+	resp := SecurityPolicyResponse{
+		TenantID:  p.TenantID,
+		Settings:  newSecuritySettings(p.Settings),
+		Effective: newSecuritySettings(bounds.Platform.Merge(p.Settings)),
+		Platform:  newSecuritySettings(bounds.Platform),
+		Strictest: newSecuritySettings(bounds.Strictest),
+		UpdatedBy: p.UpdatedBy,
+	}
+	if !p.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &p.UpdatedAt
+	}
+	return resp
+}
+
+// authorizeSecurityPolicy checks that the user may manage the tenant's settings
+func (h *Handler) authorizeSecurityPolicy(w http.ResponseWriter, r *http.Request, tenantID string) (string, bool) {
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return "", false
+	}
+	return userID, true
+}
+
+// GetSecurityPolicy handles retrieving a tenant's security policy
+// @Summary Get Security Policy
+// @Description Get the tenant's lockout and rate limit settings, those in force, and the range the platform allows
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 200 {object} SecurityPolicyResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/security-policy [get]
+func (h *Handler) GetSecurityPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	if _, ok := h.authorizeSecurityPolicy(w, r, tenantID); !ok {
+		return
+	}
+
+	policy, err := h.tenantService.GetSecurityPolicy(r.Context(), tenantID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get security policy")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, h.newSecurityPolicyResponse(policy))
+}
+
+// UpdateSecurityPolicy handles replacing a tenant's security policy
+// @Summary Update Security Policy
+// @Description Tighten the platform's account lockout and login and token endpoint rate limits for the tenant. Each setting must lie between the platform's and the strictest allowed; settings left out inherit the platform's. Changes reach every instance within 30 seconds.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body SecuritySettings true "Security Settings"
+// @Success 200 {object} SecurityPolicyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/security-policy [put]
+func (h *Handler) UpdateSecurityPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	userID, ok := h.authorizeSecurityPolicy(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	var req SecuritySettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	policy, err := h.tenantService.SetSecurityPolicy(r.Context(), tenantID, userID, req.settings())
+	if err != nil {
+		if errors.Is(err, tenant.ErrInvalidSecurityPolicy) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to update security policy")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, h.newSecurityPolicyResponse(policy))
+}
+
+// DeleteSecurityPolicy handles removing a tenant's security policy
+// @Summary Delete Security Policy
+// @Description Remove the tenant's security policy so the platform's settings apply again
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/security-policy [delete]
+func (h *Handler) DeleteSecurityPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+	userID, ok := h.authorizeSecurityPolicy(w, r, tenantID)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService.DeleteSecurityPolicy(r.Context(), tenantID, userID); err != nil {
+		if errors.Is(err, tenant.ErrSecurityPolicyNotFound) {
+			respondError(w, http.StatusNotFound, "security policy not configured")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete security policy")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
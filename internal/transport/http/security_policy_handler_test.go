@@ -0,0 +1,122 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// TestPurpose: Validates that tenant admins tighten their tenant's lockout and rate limits within the platform's bounds, and that login applies the tenant's rate.
+// Scope: Unit Test
+// Security: Brute-force protection (CWE-307) and rate limiting (CWE-770) per tenant, without loosening the platform's
+// Permissions: tenant:manage_settings
+// Expected: Only tenant admins read and change the policy; settings outside the bounds get 400; the response shows the tenant's, effective, platform and strictest settings; logins of the tenant's users are rejected with 429 beyond the tenant's login rate; deleting the policy restores the platform's settings.
+// Test Case ID: TEN-14
+func TestTenant_SecurityPolicy(t *testing.T) {
+	ctx := context.Background()
+	h, db, _ := membershipTestHandler(t)
+	h.tenantService.EnableSecurityPolicies(memory.NewSecurityPolicyRepository(db), tenant.SecurityBounds{
+		Platform:  tenant.SecuritySettings{LockoutMaxAttempts: 10, LockoutDuration: time.Hour, LoginRPS: 10, LoginBurst: 20},
+		Strictest: tenant.SecuritySettings{LockoutMaxAttempts: 3, LockoutDuration: 24 * time.Hour, LoginRPS: 0.001, LoginBurst: 1, TokenRPS: 1, TokenBurst: 1},
+	})
+	h.rateLimits = &RateLimits{
+		tenants: func(ctx context.Context, tenantID string) (RateLimit, RateLimit) {
+			s := h.tenantService.EffectiveSecurity(ctx, tenantID)
+			return RateLimit{RequestsPerSecond: s.LoginRPS, Burst: s.LoginBurst}, RateLimit{}
+		},
+		tenantUser: NewRateLimiter(0, 1),
+	}
+
+	admin, err := h.identityService.ProvisionIdentity(ctx, "t1", "admin@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.tenantService.AssignRole(ctx, "t1", admin.ID, tenant.RoleTenantAdmin, tenant.GranterSystem); err != nil {
+		t.Fatal(err)
+	}
+	user, err := h.identityService.ProvisionIdentity(ctx, "t1", "user@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.identityService.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+
+	policyRequest := func(fn http.HandlerFunc, method, actorID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/tenants/t1/security-policy", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(context.WithValue(req.Context(), tenantIDKey, "t1"), userIDKey, actorID))
+		w := httptest.NewRecorder()
+		fn(w, req)
+		return w
+	}
+
+	if w := policyRequest(h.GetSecurityPolicy, http.MethodGet, user.ID, ""); w.Code != http.StatusForbidden {
+		t.Errorf("expected a user without tenant:manage_settings to be refused, got %d", w.Code)
+	}
+	if w := policyRequest(h.UpdateSecurityPolicy, http.MethodPut, user.ID, `{"lockout_max_attempts":3}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected a user without tenant:manage_settings to be refused, got %d", w.Code)
+	}
+	for _, body := range []string{`{"lockout_max_attempts":20}`, `{"lockout_duration_seconds":60}`, `{"login_rps":100}`, `{"login_burst":0.5}`} {
+		if w := policyRequest(h.UpdateSecurityPolicy, http.MethodPut, admin.ID, body); w.Code != http.StatusBadRequest {
+			t.Errorf("expected %s to be refused, got %d %s", body, w.Code, w.Body)
+		}
+	}
+
+	w := policyRequest(h.UpdateSecurityPolicy, http.MethodPut, admin.ID, `{"lockout_max_attempts":3,"login_rps":0.001,"login_burst":1}`)
+	var resp SecurityPolicyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected the policy to be set, got %d %s", w.Code, w.Body)
+	}
+	if resp.Settings.LockoutDurationSeconds != 0 || resp.Effective.LockoutMaxAttempts != 3 || resp.Effective.LockoutDurationSeconds != 3600 ||
+		resp.Platform.LoginBurst != 20 || resp.Strictest.LockoutMaxAttempts != 3 || resp.UpdatedBy != admin.ID {
+		t.Errorf("unexpected security policy %+v", resp)
+	}
+
+	login := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"user@example.com","password":"wrong"}`))
+		w := httptest.NewRecorder()
+		h.Login(w, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chi.NewRouteContext())))
+		return w.Code
+	}
+	if code := login(); code != http.StatusUnauthorized {
+		t.Errorf("expected the first login to be checked, got %d", code)
+	}
+	if code := login(); code != http.StatusTooManyRequests {
+		t.Errorf("expected the tenant's login rate to apply, got %d", code)
+	}
+
+	if w := policyRequest(h.DeleteSecurityPolicy, http.MethodDelete, admin.ID, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected the policy to be deleted, got %d", w.Code)
+	}
+	if w := policyRequest(h.DeleteSecurityPolicy, http.MethodDelete, admin.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected a missing policy to be not found, got %d", w.Code)
+	}
+	resp = SecurityPolicyResponse{}
+	json.Unmarshal(policyRequest(h.GetSecurityPolicy, http.MethodGet, admin.ID, "").Body.Bytes(), &resp)
+	if resp.Settings != (SecuritySettings{}) || resp.Effective.LockoutMaxAttempts != 10 {
+		t.Errorf("expected the platform's settings once the policy is deleted, got %+v", resp)
+	}
+}
@@ -13,10 +13,10 @@ func TestRouter_Separation(t *testing.T) {
 	h := &Handler{
 		sessionConfig: SessionConfig{CookieName: "test-session"},
 	}
-	rl := NewRateLimiter(100, 100)
+	rl := &RateLimits{IP: NewRateLimiter(100, 100)}
 
 	t.Run("Mode: Auth", func(t *testing.T) {
-		r := NewRouter(h, rl, "auth")
+		r := NewRouter(h, rl, nil, "auth")
 
 		// 1. Should have Auth endpoints
 		req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
@@ -34,7 +34,7 @@ func TestRouter_Separation(t *testing.T) {
 	})
 
 	t.Run("Mode: Admin", func(t *testing.T) {
-		r := NewRouter(h, rl, "admin")
+		r := NewRouter(h, rl, nil, "admin")
 
 		// 1. Should have Admin endpoints
 		req := httptest.NewRequest("GET", "/api/v1/tenants", nil)
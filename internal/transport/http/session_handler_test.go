@@ -0,0 +1,85 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// TestPurpose: Validates the console session heartbeat.
+// Scope: Unit Test
+// Security: Session Management (only admin sessions are renewed, by same-site requests, and renewal cannot revive an expired session)
+// Expected: An admin session is renewed with its remaining lifetime; a request without the CSRF header is 403, an auth session 403, and a missing or expired session 401.
+// Test Case ID: LGN-09
+func TestAuth_RefreshSession(t *testing.T) {
+	sessSvc := session.NewService(memory.NewSessionRepository(memory.New()), audit.NewSlogLogger(), 8*time.Hour, 30*time.Minute)
+	ctx := context.Background()
+	sessions := make(map[string]string)
+	for _, namespace := range []string{session.NamespaceAuth, session.NamespaceAdmin} {
+		sess, err := sessSvc.Create(ctx, strPtr("tenant-A"), "user_123", "192.0.2.1:1234", "test-agent", namespace)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions[namespace] = sess.ID
+	}
+	r := NewRouter(&Handler{sessionService: sessSvc, sessionConfig: SessionConfig{CookieName: "session_id"}}, nil, nil, "admin")
+
+	refresh := func(sessionID string, csrf bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/auth/refresh-session", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		if csrf {
+			req.Header.Set("X-CSRF-Token", "1")
+		}
+		if sessionID != "" {
+			req.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := refresh(sessions[session.NamespaceAdmin], true)
+	var resp SessionRenewalResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected the admin session to be renewed, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.ExpiresIn < 29*60 || resp.ExpiresIn > 30*60 || resp.ExpiresAt.Before(resp.IdleExpiresAt) {
+		t.Errorf("unexpected remaining lifetime %+v", resp)
+	}
+
+	for _, tt := range []struct {
+		name, sessionID string
+		csrf            bool
+		want            int
+	}{
+		{"without CSRF header", sessions[session.NamespaceAdmin], false, http.StatusForbidden},
+		{"auth session", sessions[session.NamespaceAuth], true, http.StatusForbidden},
+		{"no session", "", true, http.StatusUnauthorized},
+		{"unknown session", "expired", true, http.StatusUnauthorized},
+	} {
+		if w := refresh(tt.sessionID, tt.csrf); w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
@@ -16,8 +16,10 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/opentrusty/opentrusty/internal/audit"
@@ -38,6 +40,7 @@ type CreateTenantRequest struct {
 // @Accept json
 // @Produce json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param request body CreateTenantRequest true "Tenant Data"
 // @Success 201 {object} tenant.Tenant
 // @Failure 400 {object} map[string]string
@@ -62,15 +65,14 @@ func (h *Handler) CreateTenant(w http.ResponseWriter, r *http.Request) {
 	t, err := h.tenantService.CreateTenant(r.Context(), req.Name, userID)
 	if err != nil {
 		// Map domain errors to HTTP status codes
-		if err == tenant.ErrInvalidTenantName {
+		switch {
+		case errors.Is(err, tenant.ErrInvalidTenantName):
 			respondError(w, http.StatusBadRequest, "invalid tenant name")
-			return
-		}
-		if err == tenant.ErrTenantAlreadyExists {
+		case errors.Is(err, tenant.ErrTenantAlreadyExists):
 			respondError(w, http.StatusConflict, "tenant with this name already exists")
-			return
+		default:
+			respondServiceError(w, r, err, "failed to create tenant")
 		}
-		respondError(w, http.StatusInternalServerError, "failed to create tenant")
 		return
 	}
 
@@ -79,9 +81,9 @@ func (h *Handler) CreateTenant(w http.ResponseWriter, r *http.Request) {
 		Type:     audit.TypeTenantCreated,
 		ActorID:  userID,
 		Resource: audit.ResourceTenant,
-		Metadata: map[string]any{
-			audit.AttrTenantID:   t.ID,
-			audit.AttrTenantName: t.Name,
+		Payload: &audit.TenantPayload{
+			TenantID:   t.ID,
+			TenantName: t.Name,
 		},
 	})
 
@@ -104,6 +106,7 @@ type ProvisionUserRequest struct {
 // @Accept json
 // @Produce json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param tenantID path string true "Tenant ID"
 // @Param request body ProvisionUserRequest true "User Data"
 // @Success 200 {object} map[string]any
@@ -139,7 +142,7 @@ func (h *Handler) ProvisionTenantUser(w http.ResponseWriter, r *http.Request) {
 	user, err := h.identityService.GetByEmail(r.Context(), tenantID, req.Email)
 	if err == nil && user != nil {
 		// User exists, just assign role
-	} else if err == identity.ErrUserNotFound {
+	} else if errors.Is(err, identity.ErrUserNotFound) {
 		// Create user
 		if req.Password == "" {
 			respondError(w, http.StatusBadRequest, "password is required for new user")
@@ -152,17 +155,25 @@ func (h *Handler) ProvisionTenantUser(w http.ResponseWriter, r *http.Request) {
 		}
 		user, err = h.identityService.ProvisionIdentity(r.Context(), tenantID, req.Email, profile)
 		if err != nil {
-			respondError(w, http.StatusInternalServerError, "failed to provision user: "+err.Error())
+			respondServiceError(w, r, err, "failed to provision user")
 			return
 		}
 
 		if err := h.identityService.AddPassword(r.Context(), user.ID, req.Password); err != nil {
-			respondError(w, http.StatusBadRequest, "failed to set password: "+err.Error())
+			respondServiceError(w, r, err, "failed to set password")
 			return
 		}
+
+		h.auditLogger.Log(r.Context(), audit.Event{
+			Type:     audit.TypeUserCreated,
+			TenantID: tenantID,
+			ActorID:  userID,
+			Resource: audit.ResourceUser,
+			Payload:  &audit.UserCreatedPayload{UserID: user.ID, Email: user.Email},
+		})
 	} else {
 		slog.ErrorContext(r.Context(), "failed to check user", "error", err, "tenant_id", tenantID, "email", req.Email)
-		respondError(w, http.StatusInternalServerError, "failed to check user: "+err.Error())
+		respondError(w, http.StatusInternalServerError, "failed to check user")
 		return
 	}
 
@@ -175,11 +186,11 @@ func (h *Handler) ProvisionTenantUser(w http.ResponseWriter, r *http.Request) {
 	err = h.tenantService.AssignRole(r.Context(), tenantID, user.ID, req.Role, granterID)
 	if err != nil {
 		slog.ErrorContext(r.Context(), "failed to assign role", "error", err, "tenant_id", tenantID, "user_id", user.ID, "role", req.Role)
-		if err == tenant.ErrRoleAlreadyExists {
+		if errors.Is(err, tenant.ErrRoleAlreadyExists) {
 			respondError(w, http.StatusConflict, "role already assigned")
 			return
 		}
-		respondError(w, http.StatusInternalServerError, "failed to assign role: "+err.Error())
+		respondServiceError(w, r, err, "failed to assign role")
 		return
 	}
 
@@ -196,16 +207,19 @@ type AssignRoleRequest struct {
 
 // AssignTenantRole handles assigning a role to an existing user
 // @Summary Assign Role
-// @Description Assign a role to a user within a tenant
+// @Description Assign a role to a member of a tenant; assigning to a user who is not a member is refused with 409
 // @Tags Tenant
 // @Accept json
 // @Produce json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param tenantID path string true "Tenant ID"
 // @Param userID path string true "User ID"
 // @Param request body AssignRoleRequest true "Role Data"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string "the user is not a member of the tenant"
 // @Failure 500 {object} map[string]string
 // @Router /tenants/{tenantID}/users/{userID}/roles [post]
 func (h *Handler) AssignTenantRole(w http.ResponseWriter, r *http.Request) {
@@ -226,6 +240,17 @@ func (h *Handler) AssignTenantRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Roles in a tenant are only granted to its members, and to platform users
+	member, err := h.belongsToTenant(r, tenantID, userID)
+	if err != nil {
+		respondServiceError(w, r, err, "failed to assign role")
+		return
+	}
+	if !member {
+		respondServiceError(w, r, tenant.ErrNotMember, "failed to assign role")
+		return
+	}
+
 	err = h.tenantService.AssignRole(r.Context(), tenantID, userID, req.Role, granterID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to assign role")
@@ -237,14 +262,18 @@ func (h *Handler) AssignTenantRole(w http.ResponseWriter, r *http.Request) {
 
 // RevokeTenantRole handles revoking a role
 // @Summary Revoke Role
-// @Description Revoke a role from a user within a tenant
+// @Description Revoke a role from a user within a tenant. Revoking tenant_owner or tenant_admin from the tenant's last owner, including yourself, is refused with 409.
 // @Tags Tenant
 // @Produce json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param tenantID path string true "Tenant ID"
 // @Param userID path string true "User ID"
 // @Param role path string true "Role"
 // @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /tenants/{tenantID}/users/{userID}/roles/{role} [delete]
 func (h *Handler) RevokeTenantRole(w http.ResponseWriter, r *http.Request) {
@@ -260,23 +289,36 @@ func (h *Handler) RevokeTenantRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.tenantService.RevokeRole(r.Context(), tenantID, userID, role)
+	err = h.tenantService.RevokeRole(r.Context(), tenantID, userID, role, actorID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, r, err, "failed to revoke role")
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
 }
 
+// TenantUserRoleResponse is a role assignment and who granted it
+type TenantUserRoleResponse struct {
+	ID             string    `json:"id"`
+	TenantID       string    `json:"tenant_id"`
+	UserID         string    `json:"user_id"`
+	Role           string    `json:"role" example:"tenant_admin"`
+	GrantedAt      time.Time `json:"granted_at"`
+	GrantedBy      string    `json:"granted_by" example:"system"` // User ID of the granter, or "system" for bootstrap, CLI and legacy grants
+	GrantedByEmail string    `json:"granted_by_email,omitempty"`  // Email of the granting user, while that user exists
+}
+
 // ListTenantUsers lists users with roles
 // @Summary List Tenant Users
-// @Description List all users and their roles in a tenant
+// @Description List all users and their roles in a tenant, with who granted each role. granted_by is the granting user's ID, or "system" for roles granted by bootstrap, the CLI or before granters were recorded.
 // @Tags Tenant
 // @Produce json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param tenantID path string true "Tenant ID"
-// @Success 200 {array} tenant.TenantUserRole
+// @Success 200 {array} TenantUserRoleResponse
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /tenants/{tenantID}/users [get]
 func (h *Handler) ListTenantUsers(w http.ResponseWriter, r *http.Request) {
@@ -292,11 +334,32 @@ func (h *Handler) ListTenantUsers(w http.ResponseWriter, r *http.Request) {
 
 	roles, err := h.tenantService.GetTenantUsers(r.Context(), tenantID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, r, err, "failed to list tenant users")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, roles)
+	// 2. Resolve each granter once
+	emails := make(map[string]string)
+	resp := make([]TenantUserRoleResponse, 0, len(roles))
+	for _, role := range roles {
+		email, seen := emails[role.GrantedBy]
+		if !seen && role.GrantedBy != tenant.GranterSystem {
+			if granter, err := h.identityService.GetUser(r.Context(), role.GrantedBy); err == nil {
+				email = granter.Email
+			}
+			emails[role.GrantedBy] = email
+		}
+		resp = append(resp, TenantUserRoleResponse{
+			ID:             role.ID,
+			TenantID:       role.TenantID,
+			UserID:         role.UserID,
+			Role:           role.Role,
+			GrantedAt:      role.GrantedAt,
+			GrantedBy:      role.GrantedBy,
+			GrantedByEmail: email,
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
 }
 
 // AssignOwnerRequest represents tenant owner assignment data
@@ -311,6 +374,7 @@ type AssignOwnerRequest struct {
 // @Accept json
 // @Produce json
 // @Security CookieAuth
+// @Security BearerAuth
 // @Param tenantID path string true "Tenant ID"
 // @Param request body AssignOwnerRequest true "Owner Data"
 // @Success 200 {object} map[string]string
@@ -332,24 +396,12 @@ func (h *Handler) AssignTenantOwner(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 2. Assign 'tenant_owner' role
+	// 2. Assign 'tenant_owner' role (audited by the tenant service)
 	err = h.tenantService.AssignRole(r.Context(), tenantID, req.UserID, tenant.RoleTenantOwner, actorID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to assign tenant owner: "+err.Error())
+		respondServiceError(w, r, err, "failed to assign tenant owner")
 		return
 	}
 
-	// 3. Audit Log
-	h.auditLogger.Log(r.Context(), audit.Event{
-		Type:     audit.TypeRoleAssigned,
-		TenantID: tenantID,
-		ActorID:  actorID,
-		Resource: audit.ResourceTenant,
-		Metadata: map[string]any{
-			audit.AttrRoleID: tenant.RoleTenantOwner,
-			"target_user_id": req.UserID,
-		},
-	})
-
 	respondJSON(w, http.StatusOK, map[string]string{"status": "owner_assigned"})
 }
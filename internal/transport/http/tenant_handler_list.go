@@ -15,18 +15,28 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/tenant"
 )
 
 // ListTenants handles listing all tenants
 // @Summary List Tenants
-// @Description List all platform tenants (Platform Admin Only)
+// @Description List platform tenants with cursor pagination, name search and status filtering (Platform Admin Only)
 // @Tags Tenant
 // @Produce json
 // @Security CookieAuth
-// @Success 200 {array} tenant.Tenant
+// @Security BearerAuth
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Opaque cursor from a previous page's next_cursor"
+// @Param q query string false "Case-insensitive name search"
+// @Param status query string false "Status filter" Enums(active, inactive)
+// @Param sort query string false "Sort order" Enums(created_at_desc, created_at_asc, name_asc, name_desc)
+// @Success 200 {object} tenant.ListResult
+// @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /tenants [get]
@@ -39,11 +49,35 @@ func (h *Handler) ListTenants(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tenants, err := h.tenantService.ListTenants(r.Context(), 100, 0)
+	// 2. Parse listing options
+	q := r.URL.Query()
+	opts := tenant.ListOptions{
+		Cursor: q.Get("cursor"),
+		Query:  q.Get("q"),
+		Status: q.Get("status"),
+		Sort:   q.Get("sort"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			respondError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		opts.Limit = limit
+	}
+
+	result, err := h.tenantService.ListTenants(r.Context(), opts)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to list tenants")
+		switch {
+		case errors.Is(err, tenant.ErrInvalidCursor),
+			errors.Is(err, tenant.ErrInvalidSort),
+			errors.Is(err, tenant.ErrInvalidStatus):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to list tenants")
+		}
 		return
 	}
 
-	respondJSON(w, http.StatusOK, tenants)
+	respondJSON(w, http.StatusOK, result)
 }
@@ -7,9 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/opentrusty/opentrusty/internal/audit"
 	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
 	"github.com/opentrusty/opentrusty/internal/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -20,16 +25,21 @@ type mockAssignmentRepo struct {
 	mock.Mock
 }
 
-func (m *mockAssignmentRepo) Grant(a *authz.Assignment) error                     { return nil }
-func (m *mockAssignmentRepo) Revoke(u, r string, s authz.Scope, sc *string) error { return nil }
-func (m *mockAssignmentRepo) ListForUser(userID string) ([]*authz.Assignment, error) {
+func (m *mockAssignmentRepo) Grant(ctx context.Context, a *authz.Assignment) error { return nil }
+func (m *mockAssignmentRepo) Revoke(ctx context.Context, u, r string, s authz.Scope, sc *string) error {
+	return nil
+}
+func (m *mockAssignmentRepo) RevokeUnlessLast(ctx context.Context, u, r string, s authz.Scope, sc *string) error {
+	return nil
+}
+func (m *mockAssignmentRepo) ListForUser(ctx context.Context, userID string, p pagination.Params) ([]*authz.Assignment, error) {
 	args := m.Called(userID)
 	return args.Get(0).([]*authz.Assignment), args.Error(1)
 }
-func (m *mockAssignmentRepo) ListByRole(r string, s authz.Scope, sc *string) ([]string, error) {
+func (m *mockAssignmentRepo) ListByRole(ctx context.Context, r string, s authz.Scope, sc *string) ([]string, error) {
 	return nil, nil
 }
-func (m *mockAssignmentRepo) CheckExists(r string, s authz.Scope, sc *string) (bool, error) {
+func (m *mockAssignmentRepo) CheckExists(ctx context.Context, r string, s authz.Scope, sc *string) (bool, error) {
 	return false, nil
 }
 
@@ -37,15 +47,19 @@ type mockAuthzRoleRepo struct {
 	mock.Mock
 }
 
-func (m *mockAuthzRoleRepo) Create(r *authz.Role) error { return nil }
-func (m *mockAuthzRoleRepo) GetByID(id string) (*authz.Role, error) {
+func (m *mockAuthzRoleRepo) Create(ctx context.Context, r *authz.Role) error { return nil }
+func (m *mockAuthzRoleRepo) GetByID(ctx context.Context, id string) (*authz.Role, error) {
 	args := m.Called(id)
 	return args.Get(0).(*authz.Role), args.Error(1)
 }
-func (m *mockAuthzRoleRepo) GetByName(n string, s authz.Scope) (*authz.Role, error) { return nil, nil }
-func (m *mockAuthzRoleRepo) Update(r *authz.Role) error                             { return nil }
-func (m *mockAuthzRoleRepo) Delete(id string) error                                 { return nil }
-func (m *mockAuthzRoleRepo) List(s *authz.Scope) ([]*authz.Role, error)             { return nil, nil }
+func (m *mockAuthzRoleRepo) GetByName(ctx context.Context, n string, s authz.Scope) (*authz.Role, error) {
+	return nil, nil
+}
+func (m *mockAuthzRoleRepo) Update(ctx context.Context, r *authz.Role) error { return nil }
+func (m *mockAuthzRoleRepo) Delete(ctx context.Context, id string) error     { return nil }
+func (m *mockAuthzRoleRepo) List(ctx context.Context, s *authz.Scope) ([]*authz.Role, error) {
+	return nil, nil
+}
 
 // Mock Repository for Tenant
 type mockTenantRepo struct {
@@ -64,8 +78,19 @@ func (m *mockTenantRepo) GetByName(ctx context.Context, name string) (*tenant.Te
 }
 func (m *mockTenantRepo) Update(ctx context.Context, t *tenant.Tenant) error { return nil }
 func (m *mockTenantRepo) Delete(ctx context.Context, id string) error        { return nil }
-func (m *mockTenantRepo) List(ctx context.Context, l, o int) ([]*tenant.Tenant, error) {
-	return nil, nil
+func (m *mockTenantRepo) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *mockTenantRepo) List(ctx context.Context, opts tenant.ListOptions) (*tenant.ListResult, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).(*tenant.ListResult), args.Error(1)
+}
+
+func (m *mockTenantRepo) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 // TestPurpose: Validates authorization rules for creating tenants (only platform admins).
@@ -147,3 +172,156 @@ func TestTenant_Create_AuthorizationEnforcement(t *testing.T) {
 		assert.NotEmpty(t, resp.ID)
 	})
 }
+
+// TestPurpose: Validates that the tenant listing endpoint forwards pagination, search and filter parameters.
+// Scope: Unit Test
+// Security: RBAC enforcement and input validation on listing parameters
+// Permissions: platform:manage_tenants
+// Expected: Query parameters reach the repository; invalid parameters return HTTP 400.
+// Test Case ID: TEN-09
+func TestTenant_List_PaginationAndFilters(t *testing.T) {
+	assignRepo := new(mockAssignmentRepo)
+	authzRoleRepo := new(mockAuthzRoleRepo)
+	authzSvc := authz.NewService(nil, authzRoleRepo, assignRepo)
+
+	tenantRepo := new(mockTenantRepo)
+	tenantSvc := tenant.NewService(tenantRepo, nil, assignRepo, audit.NewSlogLogger())
+
+	h := &Handler{
+		authzService:  authzSvc,
+		tenantService: tenantSvc,
+		auditLogger:   audit.NewSlogLogger(),
+	}
+
+	userID := "admin-123"
+	roleID := "role-admin"
+	authzRoleRepo.On("GetByID", roleID).Return(&authz.Role{
+		ID:          roleID,
+		Permissions: []string{authz.PermPlatformManageTenants},
+	}, nil)
+	assignRepo.On("ListForUser", userID).Return([]*authz.Assignment{
+		{UserID: userID, RoleID: roleID, Scope: authz.ScopePlatform},
+	}, nil)
+
+	tenantRepo.On("List", mock.Anything, tenant.ListOptions{
+		Limit:  25,
+		Query:  "acme",
+		Status: tenant.StatusActive,
+		Sort:   tenant.SortNameAsc,
+	}).Return(&tenant.ListResult{
+		Tenants:    []*tenant.Tenant{{ID: "t-1", Name: "Acme"}},
+		NextCursor: "next",
+	}, nil)
+
+	newRequest := func(query string) *http.Request {
+		req := httptest.NewRequest("GET", "/tenants?"+query, nil)
+		return req.WithContext(context.WithValue(req.Context(), userIDKey, userID))
+	}
+
+	w := httptest.NewRecorder()
+	h.ListTenants(w, newRequest("limit=25&q=acme&status=active&sort=name_asc"))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp tenant.ListResult
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Tenants, 1)
+	assert.Equal(t, "next", resp.NextCursor)
+
+	for _, query := range []string{"limit=abc", "sort=random", "status=deleted", "cursor=%21%21"} {
+		w = httptest.NewRecorder()
+		h.ListTenants(w, newRequest(query))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	tenantRepo.AssertExpectations(t)
+}
+
+// TestPurpose: Validates that the last owner of a tenant cannot be revoked.
+// Scope: Unit Test
+// Security: Availability of tenant administration (a tenant must not be left without anyone able to manage it)
+// Permissions: tenant:manage_users
+// Expected: Revoking the only owner, including self-demotion, returns HTTP 409 and keeps the role; once another owner exists the revocation succeeds.
+// Test Case ID: TEN-10
+func TestTenant_RevokeRole_LastOwner(t *testing.T) {
+	db := newClientTestStore(t)
+	assignments := memory.NewAssignmentRepository(db)
+	tenantRoles := memory.NewTenantRoleRepository(db)
+	h := &Handler{
+		authzService:  authz.NewService(nil, memory.NewRoleRepository(db), assignments),
+		tenantService: tenant.NewService(memory.NewTenantRepository(db), tenantRoles, assignments, audit.NewSlogLogger()),
+	}
+
+	revoke := func(actorID, userID, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/tenants/t1/users/"+userID+"/roles/"+role, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("tenantID", "t1")
+		rctx.URLParams.Add("userID", userID)
+		rctx.URLParams.Add("role", role)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, userIDKey, actorID)
+		w := httptest.NewRecorder()
+		h.RevokeTenantRole(w, req.WithContext(ctx))
+		return w
+	}
+
+	for _, role := range []string{tenant.RoleTenantOwner, tenant.RoleTenantAdmin} {
+		w := revoke("u1", "u1", role)
+		assert.Equal(t, http.StatusConflict, w.Code, role)
+		assert.Contains(t, w.Body.String(), "last owner")
+	}
+	roles, err := tenantRoles.GetUserRoles(context.Background(), "t1", "u1")
+	assert.NoError(t, err)
+	assert.Len(t, roles, 1, "the last owner must keep the role")
+
+	err = tenantRoles.AssignRole(context.Background(), &tenant.TenantUserRole{ID: "u2-owner-t1", TenantID: "t1", UserID: "u2", Role: tenant.RoleTenantOwner})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, revoke("u1", "u1", tenant.RoleTenantAdmin).Code)
+	assert.Equal(t, http.StatusConflict, revoke("u2", "u2", tenant.RoleTenantOwner).Code)
+}
+
+// TestPurpose: Validates that every role assignment records who granted it and that the user listing shows the granter.
+// Scope: Unit Test
+// Security: Accountability (role grants are attributable to a user or to the system)
+// Permissions: tenant:manage_users, tenant:view
+// Expected: AssignRole without a granter is rejected; the listing reports the granting user's ID and email, and "system" for system grants.
+// Test Case ID: TEN-11
+func TestTenant_ListUsers_Granter(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	assignments := memory.NewAssignmentRepository(db)
+	identitySvc := identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 10, time.Hour)
+	tenantSvc := tenant.NewService(memory.NewTenantRepository(db), memory.NewTenantRoleRepository(db), assignments, audit.NewSlogLogger())
+	h := &Handler{
+		identityService: identitySvc,
+		authzService:    authz.NewService(nil, memory.NewRoleRepository(db), assignments),
+		tenantService:   tenantSvc,
+	}
+
+	admin, err := identitySvc.ProvisionIdentity(ctx, "t1", "admin@example.com", identity.Profile{})
+	assert.NoError(t, err)
+	member, err := identitySvc.ProvisionIdentity(ctx, "t1", "member@example.com", identity.Profile{})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, tenantSvc.AssignRole(ctx, "t1", admin.ID, tenant.RoleTenantAdmin, ""), tenant.ErrGranterRequired)
+	assert.NoError(t, tenantSvc.AssignRole(ctx, "t1", admin.ID, tenant.RoleTenantAdmin, tenant.GranterSystem))
+	assert.NoError(t, tenantSvc.AssignRole(ctx, "t1", member.ID, tenant.RoleTenantMember, admin.ID))
+
+	req := httptest.NewRequest("GET", "/tenants/t1/users", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("tenantID", "t1")
+	req = req.WithContext(context.WithValue(context.WithValue(req.Context(), chi.RouteCtxKey, rctx), userIDKey, admin.ID))
+	w := httptest.NewRecorder()
+	h.ListTenantUsers(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var roles []TenantUserRoleResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &roles))
+	granters := make(map[string]TenantUserRoleResponse)
+	for _, r := range roles {
+		granters[r.UserID] = r
+	}
+	assert.Equal(t, tenant.GranterSystem, granters[admin.ID].GrantedBy)
+	assert.Empty(t, granters[admin.ID].GrantedByEmail)
+	assert.Equal(t, admin.ID, granters[member.ID].GrantedBy)
+	assert.Equal(t, "admin@example.com", granters[member.ID].GrantedByEmail)
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// DefaultRequestTimeout bounds requests of a route group without a configured timeout
+const DefaultRequestTimeout = 60 * time.Second
+
+// RouteTimeouts bounds how long a request may run, by route group. The deadline is set on the
+// request context, so it reaches the services and the repository queries made with it; a
+// request still running then is answered 504. Zero uses DefaultRequestTimeout.
+type RouteTimeouts struct {
+	Default time.Duration // Routes of no other group
+	OAuth2  time.Duration // The /oauth2/ endpoints: authorization, token, revocation and introspection
+	Bulk    time.Duration // Long-running admin operations, listed in bulkRoutes
+}
+
+// bulkRoutes are the operations bounded by RouteTimeouts.Bulk, as "METHOD /pattern"
+var bulkRoutes = map[string]bool{
+	"GET /api/v1/tenants/{tenantID}/audit-events": true,
+}
+
+// middleware applies the timeout of the group of the route mux matches. The route is looked up
+// before routing because a deadline set here cannot be extended by a middleware further down.
+func (t RouteTimeouts) middleware(mux *chi.Mux) func(http.Handler) http.Handler {
+	orDefault := func(d time.Duration) time.Duration {
+		if d <= 0 {
+			return DefaultRequestTimeout
+		}
+		return d
+	}
+	return func(next http.Handler) http.Handler {
+		standard := middleware.Timeout(orDefault(t.Default))(next)
+		oauth2 := middleware.Timeout(orDefault(t.OAuth2))(next)
+		bulk := middleware.Timeout(orDefault(t.Bulk))(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.RawPath
+			if path == "" {
+				path = r.URL.Path
+			}
+			pattern := mux.Find(chi.NewRouteContext(), r.Method, path)
+			switch {
+			case strings.HasPrefix(pattern, "/oauth2/"):
+				oauth2.ServeHTTP(w, r)
+			case bulkRoutes[r.Method+" "+pattern]:
+				bulk.ServeHTTP(w, r)
+			default:
+				standard.ServeHTTP(w, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestPurpose: Validates that each route group runs under its own timeout.
+// Scope: Unit Test
+// Security: Availability (slow requests release their database connections; OAuth2 endpoints fail fast)
+// Expected: OAuth2, bulk and other routes see the deadline of their group on the request context, even when the bulk
+// timeout is longer than the default; a request outliving it is answered 504; every bulk route is mounted.
+// Test Case ID: PRO-12
+func TestHTTP_RouteTimeouts(t *testing.T) {
+	timeouts := RouteTimeouts{Default: time.Minute, OAuth2: 5 * time.Second, Bulk: time.Hour}
+	var remaining time.Duration
+	record := func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			t.Error("expected a deadline on the request context")
+		}
+		remaining = time.Until(deadline)
+	}
+	r := chi.NewRouter()
+	r.Use(timeouts.middleware(r))
+	r.Get("/health", record)
+	r.Route("/oauth2", func(r chi.Router) { r.Post("/token", record) })
+	r.Route("/api/v1/tenants/{tenantID}", func(r chi.Router) {
+		r.Get("/audit-events", record)
+	})
+
+	for _, tt := range []struct {
+		method, path string
+		want         time.Duration
+	}{
+		{"GET", "/health", time.Minute},
+		{"POST", "/oauth2/token", 5 * time.Second},
+		{"GET", "/api/v1/tenants/t1/audit-events", time.Hour},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		if remaining > tt.want || remaining < tt.want-time.Second {
+			t.Errorf("%s %s: expected a %s deadline, got %s", tt.method, tt.path, tt.want, remaining)
+		}
+	}
+
+	short := chi.NewRouter()
+	short.Use(RouteTimeouts{Default: 10 * time.Millisecond}.middleware(short))
+	short.Get("/slow", func(w http.ResponseWriter, r *http.Request) { <-r.Context().Done() })
+	w := httptest.NewRecorder()
+	short.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected 504 once the deadline passed, got %d", w.Code)
+	}
+
+	routes := Routes()
+	for route := range bulkRoutes {
+		if !slices.Contains(routes, route) && !slices.Contains(routes, route+"/") {
+			t.Errorf("bulk route %q is not mounted", route)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// AdminAuthMiddleware authenticates admin API requests with an Authorization: Bearer access token
+// or, without one, a session (AuthMiddleware). The token's tenant becomes the tenant context, as a
+// session's does, and its scopes are translated to the permissions it may exercise on top of the
+// user's roles (authz.WithTokenPermissions). A user who must change their password only reaches
+// passwordChangeRoutes, whichever way they authenticate.
+func (h *Handler) AdminAuthMiddleware(next http.Handler) http.Handler {
+	session := h.AuthMiddleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			session.ServeHTTP(w, r)
+			return
+		}
+		if len(h.adminScopes) == 0 {
+			tokenChallenge(w, http.StatusUnauthorized, "invalid_token", "access tokens are not accepted on the admin API")
+			return
+		}
+		if r.Header.Get("X-Tenant-ID") != "" {
+			respondError(w, http.StatusBadRequest, "X-Tenant-ID header is not allowed on authenticated requests; tenant is derived from the access token")
+			return
+		}
+
+		at, err := h.oauth2Service.AuthenticateAccessToken(r.Context(), token)
+		if err != nil {
+			tokenChallenge(w, http.StatusUnauthorized, "invalid_token", "invalid or expired access token")
+			return
+		}
+		if at.UserID == "" {
+			tokenChallenge(w, http.StatusForbidden, "insufficient_scope", "access token is not issued to a user")
+			return
+		}
+		// An expired or temporary password restricts a token as it does a session
+		if !passwordChangeRoutes[r.Method+" "+r.URL.Path] {
+			required, err := h.identityService.PasswordChangeRequired(r.Context(), at.TenantID, at.UserID)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "failed to check password expiry", logger.Error(err))
+				respondError(w, http.StatusInternalServerError, "failed to authenticate access token")
+				return
+			}
+			if required {
+				respondError(w, http.StatusForbidden, passwordChangeMessage)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey, at.UserID)
+		ctx = context.WithValue(ctx, accessTokenKey, at)
+		ctx = context.WithValue(ctx, tenantIDKey, at.TenantID)
+		ctx = tenant.WithScope(ctx, at.TenantID)
+		ctx = authz.WithTokenPermissions(ctx, h.adminScopes.Permissions(at.Scope))
+		annotateAccessLog(ctx, at.TenantID, at.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TenantTokenMiddleware refuses an access token on the routes of a tenant other than the one that issued it
+func TenantTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if at := getAccessToken(r.Context()); at != nil && at.TenantID != GetTenantID(r.Context()) {
+			respondError(w, http.StatusForbidden, "access token was issued by another tenant")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SessionOnlyMiddleware refuses access tokens on routes that change how the user signs in or whether
+// the account exists; those need the user's own session
+func SessionOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if getAccessToken(r.Context()) != nil {
+			tokenChallenge(w, http.StatusForbidden, "insufficient_scope", "this endpoint requires a session")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the token of an Authorization: Bearer header (RFC 6750 Section 2.1)
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// tokenChallenge rejects an access token with a WWW-Authenticate challenge (RFC 6750 Section 3)
+func tokenChallenge(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="`+code+`"`)
+	respondError(w, status, message)
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// TestAdminAPI_AccessTokens tests that access tokens reach the admin API, limited to their tenant and the permissions mapped from their scopes
+func TestAdminAPI_AccessTokens(t *testing.T) {
+	db := newClientTestStore(t)
+	ctx := context.Background()
+	tokens := memory.NewAccessTokenRepository(db)
+	issue := func(token, scope string) {
+		hash := sha256.Sum256([]byte(token))
+		err := tokens.Create(ctx, &oauth2.AccessToken{
+			ID: token, TenantID: "t1", TokenHash: base64.RawURLEncoding.EncodeToString(hash[:]), ClientID: "c1", UserID: "u1",
+			Scope: scope, TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	issue("clients-token", "openid admin:clients")
+	issue("read-token", "openid admin:read")
+
+	oauth2Svc := oauth2.NewService(memory.NewClientRepository(db), nil, tokens, nil, audit.NewSlogLogger(), nil, 0, 0, 0)
+	oauth2Svc.SetScopes(memory.NewScopeRepository(db), nil)
+	h := &Handler{
+		oauth2Service:   oauth2Svc,
+		authzService:    authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+		identityService: identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 10, time.Hour),
+		adminScopes: authz.ScopePermissions{
+			"admin:clients": {authz.PermTenantManageClients},
+			"admin:read":    {authz.PermTenantView},
+		},
+		mode: "admin",
+	}
+	r := NewRouter(h, nil, nil, "admin")
+
+	send := func(method, target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{"name":"orders:read","description":"Read your orders"}`))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("POST", "/api/v1/tenants/t1/scopes/", "clients-token"); w.Code != http.StatusCreated {
+		t.Errorf("expected 201 without a CSRF header, got %d body: %s", w.Code, w.Body.String())
+	}
+	if w := send("POST", "/api/v1/tenants/t1/scopes/", "read-token"); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a scope without the permission, got %d", w.Code)
+	}
+	if w := send("GET", "/api/v1/tenants/t2/scopes/", "clients-token"); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "another tenant") {
+		t.Errorf("expected 403 for another tenant's route, got %d body: %s", w.Code, w.Body.String())
+	}
+	w := send("GET", "/api/v1/tenants/t1/scopes/", "unknown-token")
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Header().Get("WWW-Authenticate"), "invalid_token") {
+		t.Errorf("expected an invalid_token challenge, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if w := send("GET", "/api/v1/tenants/", "clients-token"); w.Code != http.StatusForbidden {
+		t.Errorf("expected platform routes to check the token's user, got %d", w.Code)
+	}
+	if w := send("POST", "/api/v1/tenants/t1/scopes/", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without credentials, got %d", w.Code)
+	}
+
+	h.adminScopes = nil
+	r = NewRouter(h, nil, nil, "admin")
+	if w := send("GET", "/api/v1/tenants/t1/scopes/", "clients-token"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 when no scopes are mapped, got %d", w.Code)
+	}
+}
+
+// TestPurpose: Validates that access tokens cannot use the self-service routes beyond their scopes.
+// Scope: Unit Test
+// Security: Least privilege for access tokens (a token cannot change the profile, password, email or existence of its user)
+// Expected: Profile update, password change, email change and account deletion refuse every token with an insufficient_scope challenge.
+// Test Case ID: API-09
+func TestAdminAPI_SelfServiceAccessTokens(t *testing.T) {
+	db := newClientTestStore(t)
+	ctx := context.Background()
+	tokens := memory.NewAccessTokenRepository(db)
+	for token, scope := range map[string]string{"read-token": "openid admin:read", "full-token": "openid admin:all"} {
+		hash := sha256.Sum256([]byte(token))
+		err := tokens.Create(ctx, &oauth2.AccessToken{
+			ID: token, TenantID: "t1", TokenHash: base64.RawURLEncoding.EncodeToString(hash[:]), ClientID: "c1", UserID: "u1",
+			Scope: scope, TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := &Handler{
+		oauth2Service:   oauth2.NewService(memory.NewClientRepository(db), nil, tokens, nil, audit.NewSlogLogger(), nil, 0, 0, 0),
+		authzService:    authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+		identityService: identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 10, time.Hour),
+		adminScopes: authz.ScopePermissions{
+			"admin:read": {authz.PermTenantView},
+			"admin:all":  {"*"},
+		},
+		mode: "admin",
+	}
+	r := NewRouter(h, nil, nil, "admin")
+
+	send := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, route := range []struct{ method, target, body string }{
+		{http.MethodPut, "/api/v1/user/profile", `{"full_name": "Mallory"}`},
+		{http.MethodPost, "/api/v1/user/change-password", `{"old_password": "a", "new_password": "b"}`},
+		{http.MethodGet, "/api/v1/user/email-change/", ""},
+		{http.MethodPost, "/api/v1/user/email-change/", `{"new_email": "mallory@example.com"}`},
+		{http.MethodDelete, "/api/v1/user/email-change/", ""},
+		{http.MethodGet, "/api/v1/user/account-deletion/", ""},
+		{http.MethodPost, "/api/v1/user/account-deletion/", `{"password": "SecurePassword123"}`},
+		{http.MethodDelete, "/api/v1/user/account-deletion/", ""},
+	} {
+		for _, token := range []string{"read-token", "full-token"} {
+			w := send(route.method, route.target, token, route.body)
+			if w.Code != http.StatusForbidden || !strings.Contains(w.Header().Get("WWW-Authenticate"), "insufficient_scope") {
+				t.Errorf("%s %s with %s: expected 403 insufficient_scope, got %d %q", route.method, route.target, token, w.Code, w.Header().Get("WWW-Authenticate"))
+			}
+		}
+	}
+}
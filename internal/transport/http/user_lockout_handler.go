@@ -0,0 +1,208 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+)
+
+// UserLockoutResponse represents a user's failed-login state
+type UserLockoutResponse struct {
+	UserID              string     `json:"user_id"`
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	Locked              bool       `json:"locked"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"` // Only set while locked
+	PasswordExpired     bool       `json:"password_expired"`
+	PasswordExpiresAt   *time.Time `json:"password_expires_at,omitempty"`
+}
+
+func newUserLockoutResponse(l *identity.Lockout, now time.Time) UserLockoutResponse {
+	resp := UserLockoutResponse{
+		UserID:              l.UserID,
+		FailedLoginAttempts: l.FailedLoginAttempts,
+		Locked:              l.Locked(now),
+		PasswordExpired:     l.PasswordExpired(now),
+		PasswordExpiresAt:   l.PasswordExpiresAt,
+	}
+	if resp.Locked {
+		resp.LockedUntil = l.LockedUntil
+	}
+	return resp
+}
+
+// canManageUsers checks the tenant user management permission that guards every lockout route
+func (h *Handler) canManageUsers(w http.ResponseWriter, r *http.Request, tenantID string) bool {
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageUsers)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant administrative access required")
+		return false
+	}
+	return true
+}
+
+// respondLockoutError maps identity service errors to HTTP responses
+func respondLockoutError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, identity.ErrUserNotFound):
+		respondError(w, http.StatusNotFound, "user not found")
+	case errors.Is(err, identity.ErrNoPassword):
+		respondError(w, http.StatusConflict, "user has no password")
+	default:
+		respondError(w, http.StatusInternalServerError, "failed to "+action)
+	}
+}
+
+// GetUserLockout handles viewing a user's lockout state
+// @Summary Get User Lockout
+// @Description Show a user's failed login attempts, lockout and password expiry
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param userID path string true "User ID"
+// @Success 200 {object} UserLockoutResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/users/{userID}/lockout [get]
+func (h *Handler) GetUserLockout(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant Admin or Platform Admin required
+	if !h.canManageUsers(w, r, tenantID) {
+		return
+	}
+
+	lockout, err := h.identityService.GetLockout(r.Context(), tenantID, chi.URLParam(r, "userID"))
+	if err != nil {
+		respondLockoutError(w, err, "get user lockout")
+		return
+	}
+	respondJSON(w, http.StatusOK, newUserLockoutResponse(lockout, time.Now()))
+}
+
+// UnlockUser handles clearing a user's lockout
+// @Summary Unlock User
+// @Description Clear a user's failed login attempts and lockout
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param userID path string true "User ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/users/{userID}/lockout [delete]
+func (h *Handler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant Admin or Platform Admin required
+	if !h.canManageUsers(w, r, tenantID) {
+		return
+	}
+
+	if err := h.identityService.Unlock(r.Context(), tenantID, chi.URLParam(r, "userID"), GetUserID(r.Context())); err != nil {
+		respondLockoutError(w, err, "unlock user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExpireUserPassword handles forcing a password change
+// @Summary Expire User Password
+// @Description Expire a user's password. The user must set a new one (new_password) at their next login.
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param userID path string true "User ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "the user has no password"
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/users/{userID}/password/expire [post]
+func (h *Handler) ExpireUserPassword(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant Admin or Platform Admin required
+	if !h.canManageUsers(w, r, tenantID) {
+		return
+	}
+
+	if err := h.identityService.ExpirePassword(r.Context(), tenantID, chi.URLParam(r, "userID"), GetUserID(r.Context())); err != nil {
+		respondLockoutError(w, err, "expire password")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetUserPasswordRequest sets a user's password
+type SetUserPasswordRequest struct {
+	Password   string `json:"password"`
+	MustChange bool   `json:"must_change"` // The password is temporary: the user must change it at the next login
+}
+
+// SetUserPassword handles an admin setting a user's password
+// @Summary Set User Password
+// @Description Replace a user's password, clearing a forced password change. With must_change the password is temporary: its next login only gets a session that can change it.
+// @Tags Tenant
+// @Accept json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param userID path string true "User ID"
+// @Param request body SetUserPasswordRequest true "New password"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/users/{userID}/password [post]
+func (h *Handler) SetUserPassword(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant Admin or Platform Admin required
+	if !h.canManageUsers(w, r, tenantID) {
+		return
+	}
+
+	var req SetUserPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err := h.identityService.ResetPassword(r.Context(), tenantID, chi.URLParam(r, "userID"), req.Password, GetUserID(r.Context()), req.MustChange)
+	if errors.Is(err, identity.ErrWeakPassword) {
+		respondError(w, http.StatusBadRequest, "password does not meet security requirements")
+		return
+	}
+	if err != nil {
+		respondLockoutError(w, err, "set password")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// TestUserLockoutHandlers tests lockout management: admins see, clear and force password expiry only for their tenant's users
+func TestUserLockoutHandlers(t *testing.T) {
+	ctx := context.Background()
+	db := newClientTestStore(t)
+	identitySvc := identity.NewService(memory.NewUserRepository(db), identity.NewPasswordHasher(65536, 1, 1, 16, 32), audit.NewSlogLogger(), 2, time.Hour)
+	h := &Handler{
+		identityService: identitySvc,
+		authzService:    authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+		auditLogger:     audit.NewSlogLogger(),
+	}
+	r := chi.NewRouter()
+	r.Get("/users/{userID}/lockout", h.GetUserLockout)
+	r.Delete("/users/{userID}/lockout", h.UnlockUser)
+	r.Post("/users/{userID}/password/expire", h.ExpireUserPassword)
+
+	user, err := identitySvc.ProvisionIdentity(ctx, "t1", "locked@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := identitySvc.AddPassword(ctx, user.ID, "SecurePassword123"); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := identitySvc.ProvisionIdentity(ctx, "t2", "other@example.com", identity.Profile{})
+	identitySvc.Authenticate(ctx, "t1", "locked@example.com", "WrongPassword")
+	identitySvc.Authenticate(ctx, "t1", "locked@example.com", "WrongPassword")
+
+	lockout := func() UserLockoutResponse {
+		t.Helper()
+		w := webhookRequest(r, http.MethodGet, "u1", "/users/"+user.ID+"/lockout", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp UserLockoutResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	if resp := lockout(); !resp.Locked || resp.FailedLoginAttempts != 2 || resp.LockedUntil == nil {
+		t.Errorf("expected locked user, got %+v", resp)
+	}
+
+	// Permission and tenant checks
+	if w := webhookRequest(r, http.MethodDelete, "u2", "/users/"+user.ID+"/lockout", ""); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for non-admin, got %d", w.Code)
+	}
+	if w := webhookRequest(r, http.MethodGet, "u1", "/users/"+other.ID+"/lockout", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another tenant's user, got %d", w.Code)
+	}
+
+	if w := webhookRequest(r, http.MethodDelete, "u1", "/users/"+user.ID+"/lockout", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 on unlock, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp := lockout(); resp.Locked || resp.FailedLoginAttempts != 0 || resp.PasswordExpired {
+		t.Errorf("expected unlocked user, got %+v", resp)
+	}
+
+	if w := webhookRequest(r, http.MethodPost, "u1", "/users/"+user.ID+"/password/expire", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 on expire, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp := lockout(); !resp.PasswordExpired || resp.PasswordExpiresAt == nil {
+		t.Errorf("expected expired password, got %+v", resp)
+	}
+
+	// An expired password can be replaced at login
+	platformUser, _ := identitySvc.ProvisionIdentity(ctx, "", "platform@example.com", identity.Profile{})
+	identitySvc.AddPassword(ctx, platformUser.ID, "SecurePassword123")
+	if err := memory.NewUserRepository(db).ExpirePassword(ctx, platformUser.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	login := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.Login(w, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body)))
+		return w
+	}
+	if w := login(`{"email": "platform@example.com", "password": "SecurePassword123", "new_password": "short"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a weak new password, got %d", w.Code)
+	}
+	// The password is replaced before the admin role check rejects this user
+	login(`{"email": "platform@example.com", "password": "SecurePassword123", "new_password": "NewSecurePassword456"}`)
+	if _, err := identitySvc.Authenticate(ctx, "", "platform@example.com", "NewSecurePassword456"); err != nil {
+		t.Errorf("expected the new password to be set, got %v", err)
+	}
+
+	// Users without a password cannot have it expired
+	invited, _ := identitySvc.ProvisionIdentity(ctx, "t1", "invited@example.com", identity.Profile{})
+	if w := webhookRequest(r, http.MethodPost, "u1", "/users/"+invited.ID+"/password/expire", ""); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a user without password, got %d", w.Code)
+	}
+}
+
+// TestPurpose: Validates temporary passwords set by an admin and the session restricted to changing them.
+// Scope: Unit Test
+// Security: Credential lifecycle (CWE-262); a temporary or expired password signs in only to be replaced
+// Permissions: tenant:manage_users
+// Expected: An admin sets a temporary password for a user of their tenant (weak passwords 400, other tenants 404); its login answers password_change_required with a session refused everywhere but /auth/me and /user/change-password and never renewed; the user's access tokens are restricted the same way; changing the password replaces the session with an unrestricted one and lifts the restriction on tokens.
+// Test Case ID: LGN-11
+func TestAuth_PasswordChangeRequired(t *testing.T) {
+	ctx := context.Background()
+	h, db, _ := membershipTestHandler(t)
+	tokens := memory.NewAccessTokenRepository(db)
+	h.oauth2Service = oauth2.NewService(memory.NewClientRepository(db), nil, tokens, nil, audit.NewSlogLogger(), nil, 0, 0, 0)
+	h.adminScopes = authz.ScopePermissions{"admin": {"*"}}
+	r := NewRouter(h, nil, nil, "admin")
+
+	admin, err := h.identityService.ProvisionIdentity(ctx, "", "root@example.com", identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.NewAssignmentRepository(db).Grant(ctx, &authz.Assignment{ID: "p1", UserID: admin.ID, RoleID: rbac.RoleIDPlatformAdmin, Scope: authz.ScopePlatform}); err != nil {
+		t.Fatal(err)
+	}
+	adminSession, err := h.sessionService.Create(ctx, nil, admin.ID, "192.0.2.1", "test-agent", session.NamespaceAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, _ := h.identityService.ProvisionIdentity(ctx, "t1", "ops@example.com", identity.Profile{})
+	other, _ := h.identityService.ProvisionIdentity(ctx, "t2", "other@example.com", identity.Profile{})
+
+	request := func(method, path, sessionID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-CSRF-Token", "1")
+		req.Header.Set("User-Agent", "test-agent")
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// A tenant admin sets a temporary password
+	if w := request(http.MethodPost, "/api/v1/tenants/t1/users/"+ops.ID+"/password", adminSession.ID, `{"password": "weak"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a weak password, got %d", w.Code)
+	}
+	if w := request(http.MethodPost, "/api/v1/tenants/t1/users/"+other.ID+"/password", adminSession.ID, `{"password": "TemporaryPassword1"}`); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another tenant's user, got %d", w.Code)
+	}
+	w := request(http.MethodPost, "/api/v1/tenants/t1/users/"+ops.ID+"/password", adminSession.ID, `{"password": "TemporaryPassword1", "must_change": true}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body)
+	}
+	if user, err := h.identityService.Authenticate(ctx, "t1", "ops@example.com", "TemporaryPassword1"); err != nil || !user.PasswordChangeRequired {
+		t.Errorf("expected a temporary password, got %+v %v", user, err)
+	}
+
+	// Signing in with a temporary password gets a restricted session
+	if err := h.identityService.ResetPassword(ctx, "", admin.ID, "TemporaryPassword1", "cli", true); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	h.Login(w, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email": "root@example.com", "password": "TemporaryPassword1", "namespace": "admin"}`)))
+	var login map[string]any
+	json.Unmarshal(w.Body.Bytes(), &login)
+	if w.Code != http.StatusOK || login["password_change_required"] != true || len(w.Result().Cookies()) != 1 {
+		t.Fatalf("expected a login that must change the password, got %d: %s", w.Code, w.Body)
+	}
+	restricted := w.Result().Cookies()[0].Value
+
+	if w := request(http.MethodGet, "/api/v1/tenants/t1/users", restricted, ""); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "password change required") {
+		t.Errorf("expected the restricted session to be refused, got %d: %s", w.Code, w.Body)
+	}
+	if w := request(http.MethodPost, "/api/v1/auth/refresh-session", restricted, ""); w.Code != http.StatusForbidden {
+		t.Errorf("expected the restricted session not to be renewed, got %d", w.Code)
+	}
+
+	// The user's access tokens are restricted as well
+	hash := sha256.Sum256([]byte("admin-token"))
+	if err := tokens.Create(ctx, &oauth2.AccessToken{
+		ID: "admin-token", TenantID: "t1", TokenHash: base64.RawURLEncoding.EncodeToString(hash[:]), ClientID: "c1", UserID: admin.ID,
+		Scope: "admin", TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bearer := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	if w := bearer(http.MethodGet, "/api/v1/tenants/"); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "password change required") {
+		t.Errorf("expected the token to be refused until the password changes, got %d: %s", w.Code, w.Body)
+	}
+	if w := bearer(http.MethodGet, "/api/v1/auth/me"); w.Code != http.StatusOK {
+		t.Errorf("expected the token to reach /auth/me, got %d: %s", w.Code, w.Body)
+	}
+	me := func(sessionID string) (int, bool) {
+		w := request(http.MethodGet, "/api/v1/auth/me", sessionID, "")
+		var resp struct {
+			PasswordChangeRequired bool `json:"password_change_required"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.PasswordChangeRequired
+	}
+	if code, required := me(restricted); code != http.StatusOK || !required {
+		t.Errorf("expected /auth/me to report the required change, got %d %v", code, required)
+	}
+
+	// Changing the password lifts the restriction with a new session
+	if w := request(http.MethodPost, "/api/v1/user/change-password", restricted, `{"old_password": "TemporaryPassword1", "new_password": "TemporaryPassword1"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unchanged password, got %d", w.Code)
+	}
+	w = request(http.MethodPost, "/api/v1/user/change-password", restricted, `{"old_password": "TemporaryPassword1", "new_password": "OwnSecurePassword2"}`)
+	if w.Code != http.StatusOK || len(w.Result().Cookies()) != 1 {
+		t.Fatalf("expected the password to change with a new session, got %d: %s", w.Code, w.Body)
+	}
+	if code, _ := me(restricted); code != http.StatusUnauthorized {
+		t.Errorf("expected the restricted session to end, got %d", code)
+	}
+	if code, required := me(w.Result().Cookies()[0].Value); code != http.StatusOK || required {
+		t.Errorf("expected an unrestricted session, got %d %v", code, required)
+	}
+	if w := bearer(http.MethodGet, "/api/v1/tenants/"); w.Code != http.StatusOK {
+		t.Errorf("expected the token to be unrestricted, got %d: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// WebhookRequest represents a tenant webhook subscription
+type WebhookRequest struct {
+	URL          string   `json:"url" binding:"required" example:"https://hooks.example.com/opentrusty"`
+	Description  string   `json:"description" example:"SIEM forwarder"`
+	EventTypes   []string `json:"event_types" example:"[\"user_created\", \"login_failed\"]"` // Empty subscribes to all events
+	Enabled      bool     `json:"enabled" example:"true"`
+	RotateSecret bool     `json:"rotate_secret,omitempty"` // Update only: issue a new signing secret
+}
+
+// WebhookResponse represents a webhook subscription without its signing secret
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	Enabled     bool      `json:"enabled"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Secret is only returned when the subscription is created or its secret is rotated
+	Secret string `json:"secret,omitempty" example:"whsec_..."`
+}
+
+// ListWebhooksResponse lists a tenant's webhook subscriptions
+type ListWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveryResponse represents one delivery of an event and its latest attempt
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type" example:"user_created"`
+	Status         string          `json:"status" example:"dead"` // pending, succeeded or dead
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"` // Only set while pending
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"` // The signed request body
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ListWebhookDeliveriesResponse is a page of webhook deliveries
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+}
+
+func newWebhookResponse(s *webhook.Subscription, secret string) WebhookResponse {
+	eventTypes := s.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return WebhookResponse{
+		ID:          s.ID,
+		TenantID:    s.TenantID,
+		URL:         s.URL,
+		Description: s.Description,
+		EventTypes:  eventTypes,
+		Enabled:     s.Enabled,
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+		Secret:      secret,
+	}
+}
+
+func newWebhookDeliveryResponse(d *webhook.Delivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastAttemptAt:  d.LastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		Payload:        json.RawMessage(d.Payload),
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == webhook.StatusPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return resp
+}
+
+// canManageWebhooks checks the tenant settings permission that guards every webhook route
+func (h *Handler) canManageWebhooks(w http.ResponseWriter, r *http.Request, tenantID string) bool {
+	userID := GetUserID(r.Context())
+	allowed, err := h.authzService.HasPermission(r.Context(), userID, authz.ScopeTenant, &tenantID, authz.PermTenantManageSettings)
+	if err != nil || !allowed {
+		respondError(w, http.StatusForbidden, "tenant settings access required")
+		return false
+	}
+	return true
+}
+
+// respondWebhookError maps webhook service errors to HTTP responses
+func respondWebhookError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidSubscription):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, webhook.ErrSubscriptionNotFound):
+		respondError(w, http.StatusNotFound, "webhook not found")
+	case errors.Is(err, webhook.ErrDeliveryNotFound):
+		respondError(w, http.StatusNotFound, "webhook delivery not found")
+	case errors.Is(err, webhook.ErrDeliveryPending):
+		respondError(w, http.StatusConflict, "webhook delivery is still pending")
+	default:
+		respondError(w, http.StatusInternalServerError, "failed to "+action)
+	}
+}
+
+// ListWebhooks handles listing a tenant's webhook subscriptions
+// @Summary List Webhooks
+// @Description List the tenant's outbound webhook subscriptions. Signing secrets are never returned.
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Success 200 {object} ListWebhooksResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/webhooks [get]
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	if !h.canManageWebhooks(w, r, tenantID) {
+		return
+	}
+
+	subs, err := h.webhookService.ListSubscriptions(r.Context(), tenantID)
+	if err != nil {
+		respondWebhookError(w, err, "list webhooks")
+		return
+	}
+
+	resp := ListWebhooksResponse{Webhooks: make([]WebhookResponse, 0, len(subs))}
+	for _, s := range subs {
+		resp.Webhooks = append(resp.Webhooks, newWebhookResponse(s, ""))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// CreateWebhook handles registering a webhook endpoint
+// @Summary Create Webhook
+// @Description Subscribe an HTTPS endpoint to the tenant's events. The signing secret is returned once.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param request body WebhookRequest true "Webhook"
+// @Success 201 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenants/{tenantID}/webhooks [post]
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	if !h.canManageWebhooks(w, r, tenantID) {
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sub, secret, err := h.webhookService.CreateSubscription(r.Context(), tenantID, GetUserID(r.Context()), webhook.SubscriptionInput{
+		URL:         req.URL,
+		Description: req.Description,
+		EventTypes:  req.EventTypes,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		respondWebhookError(w, err, "create webhook")
+		return
+	}
+
+	designateSecretFields(r, "secret")
+	respondJSON(w, http.StatusCreated, newWebhookResponse(sub, secret))
+}
+
+// GetWebhook handles retrieving a webhook subscription
+// @Summary Get Webhook
+// @Description Get one of the tenant's webhook subscriptions
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param webhookID path string true "Webhook ID"
+// @Success 200 {object} WebhookResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/webhooks/{webhookID} [get]
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	if !h.canManageWebhooks(w, r, tenantID) {
+		return
+	}
+
+	sub, err := h.webhookService.GetSubscription(r.Context(), tenantID, chi.URLParam(r, "webhookID"))
+	if err != nil {
+		respondWebhookError(w, err, "get webhook")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newWebhookResponse(sub, ""))
+}
+
+// UpdateWebhook handles replacing a webhook subscription
+// @Summary Update Webhook
+// @Description Replace a webhook's endpoint, event types and state. Set rotate_secret to issue a new signing secret, which is returned once.
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param webhookID path string true "Webhook ID"
+// @Param request body WebhookRequest true "Webhook"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/webhooks/{webhookID} [put]
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	if !h.canManageWebhooks(w, r, tenantID) {
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sub, secret, err := h.webhookService.UpdateSubscription(r.Context(), tenantID, chi.URLParam(r, "webhookID"), GetUserID(r.Context()), webhook.SubscriptionInput{
+		URL:          req.URL,
+		Description:  req.Description,
+		EventTypes:   req.EventTypes,
+		Enabled:      req.Enabled,
+		RotateSecret: req.RotateSecret,
+	})
+	if err != nil {
+		respondWebhookError(w, err, "update webhook")
+		return
+	}
+
+	designateSecretFields(r, "secret")
+	respondJSON(w, http.StatusOK, newWebhookResponse(sub, secret))
+}
+
+// DeleteWebhook handles removing a webhook subscription
+// @Summary Delete Webhook
+// @Description Remove a webhook subscription together with its delivery history
+// @Tags Tenant
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param webhookID path string true "Webhook ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/webhooks/{webhookID} [delete]
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	if !h.canManageWebhooks(w, r, tenantID) {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), tenantID, chi.URLParam(r, "webhookID"), GetUserID(r.Context())); err != nil {
+		respondWebhookError(w, err, "delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles querying a webhook's delivery history
+// @Summary List Webhook Deliveries
+// @Description List a webhook's deliveries oldest first with cursor pagination. Dead deliveries exhausted their retries.
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param webhookID path string true "Webhook ID"
+// @Param status query string false "pending, succeeded or dead"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Opaque cursor from a previous page's next_cursor"
+// @Success 200 {object} ListWebhookDeliveriesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tenants/{tenantID}/webhooks/{webhookID}/deliveries [get]
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	if !h.canManageWebhooks(w, r, tenantID) {
+		return
+	}
+
+	// 2. Parse filters and pagination
+	q := r.URL.Query()
+	filter := webhook.DeliveryFilter{
+		TenantID:       tenantID,
+		SubscriptionID: chi.URLParam(r, "webhookID"),
+		Status:         q.Get("status"),
+	}
+	switch filter.Status {
+	case "", webhook.StatusPending, webhook.StatusSucceeded, webhook.StatusDead:
+	default:
+		respondError(w, http.StatusBadRequest, "invalid status: expected pending, succeeded or dead")
+		return
+	}
+	var err error
+	if filter.Page, err = pagination.Parse(q.Get("limit"), q.Get("cursor")); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deliveries, next, err := h.webhookService.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		respondWebhookError(w, err, "list webhook deliveries")
+		return
+	}
+
+	resp := ListWebhookDeliveriesResponse{
+		Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+		NextCursor: next,
+	}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, newWebhookDeliveryResponse(d))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// RedeliverWebhook handles sending a finished delivery again
+// @Summary Redeliver Webhook Event
+// @Description Queue a succeeded or dead delivery again. The event keeps its ID; the new delivery gets its own.
+// @Tags Tenant
+// @Produce json
+// @Security CookieAuth
+// @Security BearerAuth
+// @Param tenantID path string true "Tenant ID"
+// @Param webhookID path string true "Webhook ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 202 {object} WebhookDeliveryResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "the delivery is still pending"
+// @Router /tenants/{tenantID}/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver [post]
+func (h *Handler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	// 1. Authorization Check: Tenant settings permission required
+	if !h.canManageWebhooks(w, r, tenantID) {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(r.Context(), tenantID, chi.URLParam(r, "webhookID"), chi.URLParam(r, "deliveryID"), GetUserID(r.Context()))
+	if err != nil {
+		respondWebhookError(w, err, "redeliver webhook event")
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, newWebhookDeliveryResponse(delivery))
+}
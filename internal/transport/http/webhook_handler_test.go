@@ -0,0 +1,127 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+func newWebhookTestRouter(t *testing.T) (http.Handler, *webhook.Publisher) {
+	t.Helper()
+	db := newClientTestStore(t)
+	subs := memory.NewWebhookSubscriptionRepository(db)
+	deliveries := memory.NewWebhookDeliveryRepository(db)
+	svc, err := webhook.NewService(subs, deliveries, []byte("01234567890123456789012345678901"), audit.NewSlogLogger(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{
+		webhookService: svc,
+		authzService:   authz.NewService(nil, memory.NewRoleRepository(db), memory.NewAssignmentRepository(db)),
+	}
+
+	r := chi.NewRouter()
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Get("/", h.ListWebhooks)
+		r.Post("/", h.CreateWebhook)
+		r.Route("/{webhookID}", func(r chi.Router) {
+			r.Get("/", h.GetWebhook)
+			r.Put("/", h.UpdateWebhook)
+			r.Delete("/", h.DeleteWebhook)
+			r.Get("/deliveries", h.ListWebhookDeliveries)
+			r.Post("/deliveries/{deliveryID}/redeliver", h.RedeliverWebhook)
+		})
+	})
+	return r, webhook.NewPublisher(subs, deliveries)
+}
+
+func webhookRequest(r http.Handler, method, userID, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := context.WithValue(req.Context(), tenantIDKey, "t1")
+	ctx = context.WithValue(ctx, userIDKey, userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req.WithContext(ctx))
+	return w
+}
+
+// TestWebhookHandlers tests webhook management: the secret is shown once, deliveries are listed and permissions are checked
+func TestWebhookHandlers(t *testing.T) {
+	r, publisher := newWebhookTestRouter(t)
+
+	if w := webhookRequest(r, "POST", "u1", "/webhooks/", `{"url":"http://hooks.example.com/in","enabled":true}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a plain http endpoint, got %d", w.Code)
+	}
+	if w := webhookRequest(r, "POST", "u2", "/webhooks/", `{"url":"https://hooks.example.com/in","enabled":true}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for user without settings access, got %d", w.Code)
+	}
+
+	w := webhookRequest(r, "POST", "u1", "/webhooks/", `{"url":"https://hooks.example.com/in","event_types":["user_created"],"enabled":true}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body: %s", w.Code, w.Body.String())
+	}
+	var created WebhookResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Secret, "whsec_") {
+		t.Errorf("expected the signing secret on create, got %q", created.Secret)
+	}
+
+	w = webhookRequest(r, "GET", "u1", "/webhooks/", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), created.Secret) {
+		t.Errorf("expected the list without secrets, got %d body: %s", w.Code, w.Body.String())
+	}
+
+	publisher.Log(context.Background(), audit.Event{Type: audit.TypeUserCreated, TenantID: "t1", Resource: audit.ResourceUser,
+		Payload: &audit.UserCreatedPayload{UserID: "u9", Email: "new@example.com"}})
+
+	w = webhookRequest(r, "GET", "u1", "/webhooks/"+created.ID+"/deliveries?status=pending", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+	var deliveries ListWebhookDeliveriesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &deliveries); err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries.Deliveries) != 1 || deliveries.Deliveries[0].EventType != audit.TypeUserCreated {
+		t.Fatalf("expected one pending user_created delivery, got %+v", deliveries.Deliveries)
+	}
+
+	w = webhookRequest(r, "POST", "u1", "/webhooks/"+created.ID+"/deliveries/"+deliveries.Deliveries[0].ID+"/redeliver", "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 when redelivering a pending delivery, got %d", w.Code)
+	}
+	if w := webhookRequest(r, "GET", "u1", "/webhooks/"+created.ID+"/deliveries?status=failed", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown status, got %d", w.Code)
+	}
+
+	if w := webhookRequest(r, "DELETE", "u1", "/webhooks/"+created.ID+"/", ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w := webhookRequest(r, "GET", "u1", "/webhooks/"+created.ID+"/", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tlsconfig builds the TLS configuration of the HTTP servers, from certificate
+// files that are reloaded when they change or from certificates obtained through ACME.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Config selects where certificates come from and which protocol versions are accepted.
+// Either CertFile and KeyFile or ACMEDomains must be set.
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ReloadInterval is how often the certificate files are checked for changes; zero disables polling
+	ReloadInterval time.Duration
+
+	// ACMEDomains are the host names certificates are requested for, via TLS-ALPN-01 on the listener itself
+	ACMEDomains      []string
+	ACMEEmail        string
+	ACMECacheDir     string
+	ACMEDirectoryURL string // Empty uses Let's Encrypt
+
+	MinVersion   string   // "1.2" or "1.3"
+	CipherSuites []string // TLS 1.2 suite names; empty uses the Go defaults
+}
+
+// Provider supplies certificates to the servers sharing its TLS configuration
+type Provider struct {
+	config *tls.Config
+	cert   *Reloader // nil when certificates come from ACME
+}
+
+// New builds a provider, loading the certificate files or preparing the ACME manager
+func New(cfg Config) (*Provider, error) {
+	minVersion, err := ParseVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	suites, err := ParseCipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{}
+	switch {
+	case len(cfg.ACMEDomains) > 0:
+		if cfg.CertFile != "" || cfg.KeyFile != "" {
+			return nil, errors.New("certificate files and ACME are mutually exclusive")
+		}
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.ACMEDomains...),
+			Email:      cfg.ACMEEmail,
+		}
+		if cfg.ACMECacheDir != "" {
+			m.Cache = autocert.DirCache(cfg.ACMECacheDir)
+		}
+		if cfg.ACMEDirectoryURL != "" {
+			m.Client = &acme.Client{DirectoryURL: cfg.ACMEDirectoryURL}
+		}
+		p.config = m.TLSConfig()
+	case cfg.CertFile != "" && cfg.KeyFile != "":
+		p.cert, err = NewReloader(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		p.config = &tls.Config{
+			GetCertificate: p.cert.GetCertificate,
+			NextProtos:     []string{"h2", "http/1.1"},
+		}
+	default:
+		return nil, errors.New("a certificate and key file or ACME domains are required")
+	}
+	p.config.MinVersion = minVersion
+	p.config.CipherSuites = suites
+	return p, nil
+}
+
+// TLSConfig returns a copy of the configuration for one server
+func (p *Provider) TLSConfig() *tls.Config {
+	return p.config.Clone()
+}
+
+// Reload reads the certificate files again; ACME certificates are renewed by the manager
+func (p *Provider) Reload() error {
+	if p.cert == nil {
+		return nil
+	}
+	return p.cert.Reload()
+}
+
+// Watch reloads the certificate files whenever they change, checking every interval until ctx is cancelled
+func (p *Provider) Watch(ctx context.Context, interval time.Duration) {
+	if p.cert == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !p.cert.changed() {
+				continue
+			}
+			if err := p.cert.Reload(); err != nil {
+				slog.ErrorContext(ctx, "failed to reload tls certificate", logger.Error(err))
+			}
+		}
+	}
+}
+
+// Reloader serves a certificate loaded from files and swaps it when the files are reloaded.
+// A failed reload keeps the previous certificate.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewReloader loads the certificate and key files
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate and key files and starts serving the new certificate
+func (r *Reloader) Reload() error {
+	modTime := r.lastModified()
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	slog.Info("loaded tls certificate", "cert_file", r.certFile, "not_after", cert.Leaf.NotAfter)
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// changed reports whether either file was modified since the last successful load
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !r.lastModified().Equal(r.modTime)
+}
+
+// lastModified returns the later modification time of the two files
+func (r *Reloader) lastModified() time.Time {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		if info, err := os.Stat(name); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// ParseVersion maps "1.2" or "1.3" to the TLS version constant; empty defaults to TLS 1.2
+func ParseVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported tls version %q: use 1.2 or 1.3", v)
+}
+
+// ParseCipherSuites maps suite names to IDs. Only suites Go considers secure are accepted;
+// they apply to TLS 1.2, since TLS 1.3 suites are not configurable.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	secure := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		secure[s.Name] = s.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := secure[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure tls cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
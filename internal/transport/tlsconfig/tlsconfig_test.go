@@ -0,0 +1,118 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate for commonName to certFile and keyFile
+func writeCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func servedName(t *testing.T, p *Provider) string {
+	t.Helper()
+	cert, err := p.TLSConfig().GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	return cert.Leaf.Subject.CommonName
+}
+
+// TestPurpose: Validates that replaced certificate files are picked up without a restart.
+// Scope: Unit Test
+// Security: Availability (certificates can be rotated before they expire without downtime)
+// Expected: The new certificate is served after a reload; a broken file keeps the previous one.
+// Test Case ID: TLS-01
+func TestProvider_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "old.example.com")
+
+	p, err := New(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	assert.Equal(t, "old.example.com", servedName(t, p))
+	assert.False(t, p.cert.changed())
+
+	writeCert(t, certFile, keyFile, "new.example.com")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	assert.True(t, p.cert.changed())
+	require.NoError(t, p.Reload())
+	assert.Equal(t, "new.example.com", servedName(t, p))
+	assert.False(t, p.cert.changed())
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0o600))
+	assert.Error(t, p.Reload())
+	assert.Equal(t, "new.example.com", servedName(t, p))
+}
+
+// TestPurpose: Validates protocol version and cipher suite enforcement.
+// Scope: Unit Test
+// Security: Transport security (legacy TLS versions and weak cipher suites are refused)
+// Expected: TLS 1.2 is the default minimum; unknown versions and insecure suites are rejected.
+// Test Case ID: TLS-02
+func TestProvider_Policy(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "example.com")
+
+	p, err := New(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), p.TLSConfig().MinVersion)
+	assert.Nil(t, p.TLSConfig().CipherSuites)
+
+	p, err = New(Config{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), p.TLSConfig().MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, p.TLSConfig().CipherSuites)
+
+	_, err = New(Config{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"})
+	assert.Error(t, err)
+	_, err = New(Config{CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}})
+	assert.Error(t, err)
+	_, err = New(Config{CertFile: certFile, KeyFile: keyFile, ACMEDomains: []string{"example.com"}})
+	assert.Error(t, err)
+	_, err = New(Config{})
+	assert.Error(t, err)
+}
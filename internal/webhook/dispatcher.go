@@ -0,0 +1,294 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/jobs"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Delivery outcomes recorded by the webhook.deliveries metric
+const (
+	outcomeSucceeded = "succeeded"
+	outcomeRetry     = "retry"
+	outcomeDead      = "dead"
+)
+
+// userAgent identifies webhook requests to receivers
+const userAgent = "OpenTrusty-Webhooks/1.0"
+
+// errPrivateAddress is returned when an endpoint resolves to an address the dispatcher must not reach
+var errPrivateAddress = errors.New("webhook endpoint resolves to a private or loopback address")
+
+// DispatcherConfig controls how deliveries are attempted and retried
+type DispatcherConfig struct {
+	// PollInterval is the delay between scans for due deliveries
+	PollInterval time.Duration
+	// BatchSize caps the deliveries attempted concurrently in one scan
+	BatchSize int
+	// Timeout bounds a single delivery request
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery becomes a dead letter
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles with every failed attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Dispatcher sends due deliveries and schedules retries
+type Dispatcher struct {
+	svc      *Service
+	cfg      DispatcherConfig
+	client   *http.Client
+	attempts metric.Int64Counter
+	now      func() time.Time
+}
+
+// NewDispatcher creates a dispatcher for the service's deliveries.
+// Unless the service allows insecure endpoints, connections to private, loopback and
+// link-local addresses are refused at dial time, after DNS resolution.
+func NewDispatcher(svc *Service, cfg DispatcherConfig, meter *metrics.Meter) (*Dispatcher, error) {
+	if cfg.BatchSize <= 0 || cfg.MaxAttempts <= 0 {
+		return nil, errors.New("webhook batch size and max attempts must be positive")
+	}
+	attempts, err := meter.CreateCounter("webhook.deliveries", "Webhook delivery attempts by outcome")
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !svc.allowInsecure {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip, err := netip.ParseAddr(host); err != nil || !publicAddr(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	return &Dispatcher{
+		svc: svc,
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				// No proxy: the address check must see the endpoint itself
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: cfg.Timeout,
+				MaxIdleConnsPerHost: 2,
+			},
+			// A redirect could point anywhere; receivers must answer on the registered URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		attempts: attempts,
+		now:      time.Now,
+	}, nil
+}
+
+// Run dispatches due deliveries every poll interval until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchPending(ctx); err != nil {
+				slog.ErrorContext(ctx, "webhook dispatch failed", logger.Component("webhook"), logger.Error(err))
+			}
+		}
+	}
+}
+
+// Job returns the dispatcher as a background job for a jobs.Runner
+func (d *Dispatcher) Job() jobs.Job {
+	return jobs.Job{
+		Name:     "webhook_dispatcher",
+		Interval: d.cfg.PollInterval,
+		Run:      d.DispatchPending,
+	}
+}
+
+// DispatchPending sends batches of due deliveries until one comes back short and returns how many
+// were attempted. Draining in one go means a backlog does not wait a full interval per batch.
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		n, err := d.DispatchOnce(ctx)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if n < d.cfg.BatchSize {
+			break
+		}
+	}
+	return total, nil
+}
+
+// DispatchOnce attempts one batch of due deliveries and returns how many were attempted
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	now := d.now()
+	// Lease the batch for longer than an attempt can take; an unfinished delivery is retried once the lease expires
+	due, err := d.svc.deliveries.ClaimDue(ctx, now, now.Add(2*d.cfg.Timeout+time.Minute), d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range due {
+		wg.Go(func() {
+			d.attempt(ctx, delivery)
+		})
+	}
+	wg.Wait()
+	return len(due), nil
+}
+
+// attempt sends a delivery once and records the outcome
+func (d *Dispatcher) attempt(ctx context.Context, delivery *Delivery) {
+	sub, err := d.svc.subs.Get(ctx, delivery.TenantID, delivery.SubscriptionID)
+	switch {
+	case errors.Is(err, ErrSubscriptionNotFound):
+		d.finish(ctx, delivery, StatusDead, 0, "subscription deleted")
+		return
+	case err != nil:
+		// Leave the delivery leased; it is retried when the lease expires
+		slog.ErrorContext(ctx, "failed to load webhook subscription",
+			logger.Component("webhook"),
+			logger.TenantID(delivery.TenantID),
+			logger.Error(err),
+		)
+		return
+	case !sub.Enabled:
+		d.finish(ctx, delivery, StatusDead, 0, "subscription disabled")
+		return
+	}
+
+	secret, err := d.svc.decrypt(sub.SecretEncrypted)
+	if err != nil {
+		d.finish(ctx, delivery, StatusDead, 0, "failed to decrypt signing secret")
+		return
+	}
+
+	status, err := d.send(ctx, sub.URL, string(secret), delivery)
+	attemptedAt := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &attemptedAt
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		d.finish(ctx, delivery, StatusSucceeded, status, "")
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		d.finish(ctx, delivery, StatusDead, status, failure(status, err))
+	default:
+		delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+		d.finish(ctx, delivery, StatusPending, status, failure(status, err))
+	}
+}
+
+// send posts the signed payload and returns the response status
+func (d *Dispatcher) send(ctx context.Context, endpoint, secret string, delivery *Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderSignature, Sign(secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a bounded amount so the connection can be reused; the body itself is not kept
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// finish stores the outcome of an attempt
+func (d *Dispatcher) finish(ctx context.Context, delivery *Delivery, status string, responseStatus int, lastError string) {
+	delivery.Status = status
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = lastError
+	delivery.UpdatedAt = d.now()
+
+	outcome := outcomeRetry
+	switch status {
+	case StatusSucceeded:
+		outcome = outcomeSucceeded
+	case StatusDead:
+		outcome = outcomeDead
+		slog.WarnContext(ctx, "webhook delivery moved to dead letters",
+			logger.Component("webhook"),
+			logger.TenantID(delivery.TenantID),
+			logger.String("delivery_id", delivery.ID),
+			logger.String("reason", lastError),
+		)
+	}
+	d.attempts.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+
+	if err := d.svc.deliveries.Update(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "failed to record webhook delivery",
+			logger.Component("webhook"),
+			logger.TenantID(delivery.TenantID),
+			logger.Error(err),
+		)
+	}
+}
+
+// backoff returns the delay before the retry that follows the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
+
+// failure describes a failed attempt for the delivery history
+func failure(status int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("endpoint responded with status %d", status)
+}
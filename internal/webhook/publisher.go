@@ -0,0 +1,102 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/observability/logger"
+)
+
+// Publisher queues deliveries of tenant events for their webhook subscribers.
+// It is also an audit.Logger, so identity, tenant and every other module that records
+// audit events publishes them without depending on this package.
+type Publisher struct {
+	subs       SubscriptionRepository
+	deliveries DeliveryRepository
+}
+
+// NewPublisher creates a publisher
+func NewPublisher(subs SubscriptionRepository, deliveries DeliveryRepository) *Publisher {
+	return &Publisher{subs: subs, deliveries: deliveries}
+}
+
+// Publish queues a delivery of the event for every enabled subscription of its tenant that matches the event type
+func (p *Publisher) Publish(ctx context.Context, env Envelope) error {
+	subs, err := p.subs.List(ctx, env.TenantID)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	now := time.Now()
+	for _, sub := range subs {
+		if !sub.Enabled || !sub.Matches(env.Type) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(env); err != nil {
+				return fmt.Errorf("failed to encode webhook event: %w", err)
+			}
+		}
+		err := p.deliveries.Create(ctx, &Delivery{
+			ID:             id.NewUUIDv7(),
+			SubscriptionID: sub.ID,
+			TenantID:       env.TenantID,
+			EventID:        env.ID,
+			EventType:      env.Type,
+			Payload:        body,
+			Status:         StatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Log queues the event for the subscribers of its tenant.
+// Platform events without a tenant are not published.
+func (p *Publisher) Log(ctx context.Context, event audit.Event) {
+	if event.TenantID == "" {
+		return
+	}
+
+	event = audit.Prepare(ctx, event)
+	err := p.Publish(ctx, Envelope{
+		ID:        event.ID,
+		Type:      event.Type,
+		TenantID:  event.TenantID,
+		CreatedAt: event.Timestamp,
+		Data:      event,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to publish webhook event",
+			logger.Component("webhook"),
+			logger.TenantID(event.TenantID),
+			slog.String(audit.AttrAuditType, event.Type),
+			logger.Error(err),
+		)
+	}
+}
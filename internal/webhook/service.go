@@ -0,0 +1,361 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// MaxSubscriptions caps the webhook subscriptions of one tenant
+const MaxSubscriptions = 25
+
+// maxURLLength caps the length of an endpoint URL
+const maxURLLength = 2048
+
+// SubscriptionInput carries the fields a tenant admin may set.
+// RotateSecret is only honoured on update; a new subscription always gets a fresh secret.
+type SubscriptionInput struct {
+	URL          string
+	Description  string
+	EventTypes   []string
+	Enabled      bool
+	RotateSecret bool
+}
+
+// Service manages webhook subscriptions and queues deliveries for published events
+type Service struct {
+	subs          SubscriptionRepository
+	deliveries    DeliveryRepository
+	encryptionKey []byte
+	auditLogger   audit.Logger
+	allowInsecure bool
+}
+
+// NewService creates a new webhook service.
+// encryptionKey MUST be exactly 32 bytes (AES-256). allowInsecure permits http:// endpoints and
+// private, loopback and link-local addresses, which is only meant for local development.
+func NewService(subs SubscriptionRepository, deliveries DeliveryRepository, encryptionKey []byte, auditLogger audit.Logger, allowInsecure bool) (*Service, error) {
+	if len(encryptionKey) != 32 {
+		return nil, errors.New("webhook secret encryption key must be exactly 32 bytes")
+	}
+	return &Service{
+		subs:          subs,
+		deliveries:    deliveries,
+		encryptionKey: encryptionKey,
+		auditLogger:   auditLogger,
+		allowInsecure: allowInsecure,
+	}, nil
+}
+
+// ListSubscriptions retrieves a tenant's webhook subscriptions
+func (s *Service) ListSubscriptions(ctx context.Context, tenantID string) ([]*Subscription, error) {
+	return s.subs.List(ctx, tenantID)
+}
+
+// GetSubscription retrieves one of a tenant's webhook subscriptions
+func (s *Service) GetSubscription(ctx context.Context, tenantID, id string) (*Subscription, error) {
+	return s.subs.Get(ctx, tenantID, id)
+}
+
+// CreateSubscription registers an endpoint and returns it with its signing secret.
+// The secret is only ever returned here and when it is rotated.
+func (s *Service) CreateSubscription(ctx context.Context, tenantID, actorID string, in SubscriptionInput) (*Subscription, string, error) {
+	// 1. Validate input
+	if err := s.validate(&in); err != nil {
+		return nil, "", err
+	}
+	existing, err := s.subs.List(ctx, tenantID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= MaxSubscriptions {
+		return nil, "", fmt.Errorf("%w: a tenant may have at most %d webhooks", ErrInvalidSubscription, MaxSubscriptions)
+	}
+
+	// 2. Generate and encrypt the signing secret
+	secret, encrypted, err := s.newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	sub := &Subscription{
+		ID:              id.NewUUIDv7(),
+		TenantID:        tenantID,
+		URL:             in.URL,
+		Description:     in.Description,
+		EventTypes:      in.EventTypes,
+		SecretEncrypted: encrypted,
+		Enabled:         in.Enabled,
+		CreatedBy:       actorID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.subs.Create(ctx, sub); err != nil {
+		return nil, "", err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeWebhookCreated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceWebhook,
+		Payload:  webhookPayload(sub, false),
+	})
+
+	return sub, secret, nil
+}
+
+// UpdateSubscription replaces a subscription's settings.
+// The returned secret is empty unless in.RotateSecret was set.
+func (s *Service) UpdateSubscription(ctx context.Context, tenantID, id, actorID string, in SubscriptionInput) (*Subscription, string, error) {
+	if err := s.validate(&in); err != nil {
+		return nil, "", err
+	}
+
+	sub, err := s.subs.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var secret string
+	if in.RotateSecret {
+		var encrypted []byte
+		if secret, encrypted, err = s.newSecret(); err != nil {
+			return nil, "", err
+		}
+		sub.SecretEncrypted = encrypted
+	}
+	sub.URL = in.URL
+	sub.Description = in.Description
+	sub.EventTypes = in.EventTypes
+	sub.Enabled = in.Enabled
+	sub.UpdatedAt = time.Now()
+
+	if err := s.subs.Update(ctx, sub); err != nil {
+		return nil, "", err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeWebhookUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceWebhook,
+		Payload:  webhookPayload(sub, in.RotateSecret),
+	})
+
+	return sub, secret, nil
+}
+
+// DeleteSubscription removes a subscription and its delivery history
+func (s *Service) DeleteSubscription(ctx context.Context, tenantID, id, actorID string) error {
+	sub, err := s.subs.Get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.subs.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeWebhookDeleted,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceWebhook,
+		Payload:  webhookPayload(sub, false),
+	})
+
+	return nil
+}
+
+// ListDeliveries retrieves a page of a subscription's deliveries and the cursor of the next page
+func (s *Service) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*Delivery, string, error) {
+	if _, err := s.subs.Get(ctx, filter.TenantID, filter.SubscriptionID); err != nil {
+		return nil, "", err
+	}
+	filter.Page = filter.Page.Normalize()
+	deliveries, err := s.deliveries.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return deliveries, pagination.NextCursor(deliveries, filter.Page, func(d *Delivery) string { return d.ID }), nil
+}
+
+// Redeliver queues a finished delivery again as a new delivery of the same event.
+// The original delivery is kept so the history shows every attempt.
+func (s *Service) Redeliver(ctx context.Context, tenantID, subscriptionID, deliveryID, actorID string) (*Delivery, error) {
+	original, err := s.deliveries.Get(ctx, tenantID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.SubscriptionID != subscriptionID {
+		return nil, ErrDeliveryNotFound
+	}
+	if original.Status == StatusPending {
+		return nil, ErrDeliveryPending
+	}
+
+	now := time.Now()
+	delivery := &Delivery{
+		ID:             id.NewUUIDv7(),
+		SubscriptionID: original.SubscriptionID,
+		TenantID:       tenantID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         StatusPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.deliveries.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeWebhookRedelivered,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceWebhook,
+		Payload: &audit.WebhookDeliveryPayload{
+			WebhookID:  subscriptionID,
+			DeliveryID: delivery.ID,
+			EventID:    delivery.EventID,
+		},
+	})
+
+	return delivery, nil
+}
+
+// validate normalises the input and checks the endpoint and event types
+func (s *Service) validate(in *SubscriptionInput) error {
+	in.URL = strings.TrimSpace(in.URL)
+	in.Description = strings.TrimSpace(in.Description)
+	if in.URL == "" || len(in.URL) > maxURLLength {
+		return fmt.Errorf("%w: url is required and must be at most %d characters", ErrInvalidSubscription, maxURLLength)
+	}
+
+	u, err := url.Parse(in.URL)
+	if err != nil || u.Host == "" || u.Fragment != "" {
+		return fmt.Errorf("%w: url must be absolute and have no fragment", ErrInvalidSubscription)
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && s.allowInsecure:
+	default:
+		return fmt.Errorf("%w: url must use https", ErrInvalidSubscription)
+	}
+	if !s.allowInsecure {
+		host := u.Hostname()
+		if ip, err := netip.ParseAddr(host); (err == nil && !publicAddr(ip)) || strings.EqualFold(host, "localhost") {
+			return fmt.Errorf("%w: url must not point to a private or loopback address", ErrInvalidSubscription)
+		}
+	}
+
+	known := audit.EventTypes()
+	for _, t := range in.EventTypes {
+		if _, found := slices.BinarySearch(known, t); !found {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, t)
+		}
+	}
+	slices.Sort(in.EventTypes)
+	in.EventTypes = slices.Compact(in.EventTypes)
+	return nil
+}
+
+// newSecret generates a signing secret and its encrypted form
+func (s *Service) newSecret() (string, []byte, error) {
+	secret, err := NewSecret()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	encrypted, err := s.encrypt([]byte(secret))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+	return secret, encrypted, nil
+}
+
+func webhookPayload(sub *Subscription, secretRotated bool) *audit.WebhookPayload {
+	return &audit.WebhookPayload{
+		WebhookID:     sub.ID,
+		URL:           sub.URL,
+		EventTypes:    sub.EventTypes,
+		Enabled:       sub.Enabled,
+		SecretRotated: secretRotated,
+	}
+}
+
+// publicAddr reports whether ip is a routable unicast address outside private and loopback ranges
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// Helper functions
+
+func (s *Service) encrypt(data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func (s *Service) decrypt(data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
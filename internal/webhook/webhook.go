@@ -0,0 +1,120 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook delivers tenant events to subscriber endpoints.
+// Each delivery is signed with the subscription's secret, retried with exponential
+// backoff while the endpoint fails and kept as a dead letter once retries run out.
+package webhook
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/apperr"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+)
+
+// Domain errors
+var (
+	ErrSubscriptionNotFound = apperr.New(apperr.CodeNotFound, "webhook subscription not found")
+	ErrDeliveryNotFound     = apperr.New(apperr.CodeNotFound, "webhook delivery not found")
+	ErrInvalidSubscription  = apperr.New(apperr.CodeInvalidArgument, "invalid webhook subscription")
+	ErrDeliveryPending      = apperr.New(apperr.CodeFailedPrecondition, "webhook delivery is still pending")
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"   // Waiting for its first attempt or a retry
+	StatusSucceeded = "succeeded" // The endpoint answered with a 2xx status
+	StatusDead      = "dead"      // Retries are exhausted; kept for inspection and redelivery
+)
+
+// Subscription is a tenant's registration of an endpoint for events.
+// The signing secret is stored encrypted and only returned in plaintext when it is created.
+type Subscription struct {
+	ID              string    `json:"id"`
+	TenantID        string    `json:"tenant_id"`
+	URL             string    `json:"url"`
+	Description     string    `json:"description"`
+	EventTypes      []string  `json:"event_types"` // Empty subscribes to all events
+	SecretEncrypted []byte    `json:"-"`
+	Enabled         bool      `json:"enabled"`
+	CreatedBy       string    `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Matches reports whether the subscription receives events of the given type
+func (s *Subscription) Matches(eventType string) bool {
+	return len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, eventType)
+}
+
+// Delivery is one event sent to one subscription, together with its attempt history
+type Delivery struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscription_id"`
+	TenantID       string     `json:"tenant_id"`
+	EventID        string     `json:"event_id"` // Stable across retries and redeliveries; receivers deduplicate on it
+	EventType      string     `json:"event_type"`
+	Payload        []byte     `json:"-"` // The exact request body that is signed
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Envelope is the JSON body posted to subscribers
+type Envelope struct {
+	ID        string    `json:"id"` // Event ID
+	Type      string    `json:"type"`
+	TenantID  string    `json:"tenant_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// DeliveryFilter selects a page of a subscription's deliveries, oldest first
+type DeliveryFilter struct {
+	TenantID       string
+	SubscriptionID string
+	Status         string // Empty matches every status
+	Page           pagination.Params
+}
+
+// SubscriptionRepository defines the interface for webhook subscription storage
+type SubscriptionRepository interface {
+	Create(ctx context.Context, sub *Subscription) error
+	Get(ctx context.Context, tenantID, id string) (*Subscription, error)
+	List(ctx context.Context, tenantID string) ([]*Subscription, error)
+	Update(ctx context.Context, sub *Subscription) error
+	// Delete removes a subscription together with its deliveries
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+// DeliveryRepository defines the interface for webhook delivery storage
+type DeliveryRepository interface {
+	Create(ctx context.Context, delivery *Delivery) error
+	Get(ctx context.Context, tenantID, id string) (*Delivery, error)
+	List(ctx context.Context, filter DeliveryFilter) ([]*Delivery, error)
+	Update(ctx context.Context, delivery *Delivery) error
+	// ClaimDue returns up to limit pending deliveries due at now and moves their next attempt
+	// to leaseUntil, so a concurrent dispatcher does not pick them up while they are in flight
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*Delivery, error)
+	// DeleteFinished removes up to limit succeeded or dead deliveries last updated before the cutoff
+	DeleteFinished(ctx context.Context, before time.Time, limit int) (int64, error)
+}
@@ -0,0 +1,358 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = []byte("01234567890123456789012345678901")
+
+type testEnv struct {
+	svc        *webhook.Service
+	publisher  *webhook.Publisher
+	subs       *memory.WebhookSubscriptionRepository
+	deliveries *memory.WebhookDeliveryRepository
+	events     *memory.AuditRepository
+}
+
+func newTestEnv(t *testing.T, allowInsecure bool) *testEnv {
+	t.Helper()
+	db := memory.New()
+	env := &testEnv{
+		subs:       memory.NewWebhookSubscriptionRepository(db),
+		deliveries: memory.NewWebhookDeliveryRepository(db),
+		events:     memory.NewAuditRepository(db),
+	}
+	svc, err := webhook.NewService(env.subs, env.deliveries, testKey, audit.NewStoreLogger(env.events), allowInsecure)
+	require.NoError(t, err)
+	env.svc = svc
+	env.publisher = webhook.NewPublisher(env.subs, env.deliveries)
+	return env
+}
+
+func newTestDispatcher(t *testing.T, svc *webhook.Service, cfg webhook.DispatcherConfig) *webhook.Dispatcher {
+	t.Helper()
+	meter, err := metrics.New(context.Background(), metrics.Config{}, "test")
+	require.NoError(t, err)
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	d, err := webhook.NewDispatcher(svc, cfg, meter)
+	require.NoError(t, err)
+	return d
+}
+
+// receiver records the requests posted to a test endpoint
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	t.Helper()
+	rec := &receiver{status: status}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+		rec.mu.Unlock()
+		w.WriteHeader(rec.status)
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+// TestPurpose: Validates webhook request signing and verification.
+// Scope: Unit Test
+// Security: Receivers must be able to reject forged, tampered and replayed deliveries
+// Expected: A signature verifies with its secret and body only, and is rejected once outside the tolerance.
+// Test Case ID: WHK-01
+func TestWebhook_Signature(t *testing.T) {
+	secret, err := webhook.NewSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	now := time.Now()
+	body := []byte(`{"id":"e1"}`)
+	header := webhook.Sign(secret, now, body)
+
+	assert.NoError(t, webhook.Verify(secret, header, body, 5*time.Minute, now))
+	assert.ErrorIs(t, webhook.Verify(secret, header, []byte(`{"id":"e2"}`), 5*time.Minute, now), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("whsec_other", header, body, 5*time.Minute, now), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify(secret, header, body, 5*time.Minute, now.Add(10*time.Minute)), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify(secret, "v1=deadbeef", body, 0, now), webhook.ErrInvalidSignature)
+}
+
+// TestPurpose: Validates webhook subscription management.
+// Scope: Unit Test
+// Security: Endpoints must be HTTPS on public addresses; signing secrets are shown once and stored encrypted
+// Expected: Invalid URLs and event types are rejected; create and rotate return a secret; every change is audited.
+// Test Case ID: WHK-02
+func TestWebhook_SubscriptionLifecycle(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, false)
+
+	for _, in := range []webhook.SubscriptionInput{
+		{URL: "http://hooks.example.com/in"},
+		{URL: "https://127.0.0.1/in"},
+		{URL: "https://10.0.0.5/in"},
+		{URL: "https://localhost/in"},
+		{URL: "hooks.example.com"},
+		{URL: "https://hooks.example.com/in", EventTypes: []string{"no_such_event"}},
+	} {
+		_, _, err := env.svc.CreateSubscription(ctx, "t1", "u1", in)
+		assert.ErrorIs(t, err, webhook.ErrInvalidSubscription, in.URL)
+	}
+
+	sub, secret, err := env.svc.CreateSubscription(ctx, "t1", "u1", webhook.SubscriptionInput{
+		URL:        " https://hooks.example.com/in ",
+		EventTypes: []string{audit.TypeUserCreated, audit.TypeLoginFailed, audit.TypeUserCreated},
+		Enabled:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/in", sub.URL)
+	assert.Equal(t, []string{audit.TypeLoginFailed, audit.TypeUserCreated}, sub.EventTypes)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+	assert.NotContains(t, string(sub.SecretEncrypted), secret)
+
+	_, err = env.svc.GetSubscription(ctx, "t2", sub.ID)
+	assert.ErrorIs(t, err, webhook.ErrSubscriptionNotFound, "subscriptions are tenant scoped")
+
+	updated, rotated, err := env.svc.UpdateSubscription(ctx, "t1", sub.ID, "u1", webhook.SubscriptionInput{
+		URL:          sub.URL,
+		Enabled:      false,
+		RotateSecret: true,
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled)
+	assert.Empty(t, updated.EventTypes)
+	assert.NotEmpty(t, rotated)
+	assert.NotEqual(t, secret, rotated)
+
+	_, unchanged, err := env.svc.UpdateSubscription(ctx, "t1", sub.ID, "u1", webhook.SubscriptionInput{URL: sub.URL})
+	require.NoError(t, err)
+	assert.Empty(t, unchanged, "the secret is only returned when rotated")
+
+	require.NoError(t, env.svc.DeleteSubscription(ctx, "t1", sub.ID, "u1"))
+	assert.ErrorIs(t, env.svc.DeleteSubscription(ctx, "t1", sub.ID, "u1"), webhook.ErrSubscriptionNotFound)
+
+	events, err := env.events.List(ctx, audit.Filter{TenantID: "t1"})
+	require.NoError(t, err)
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type)
+		assert.Equal(t, "u1", e.ActorID)
+		assert.Equal(t, audit.SchemaVersion, e.SchemaVersion)
+	}
+	assert.Equal(t, []string{audit.TypeWebhookCreated, audit.TypeWebhookUpdated, audit.TypeWebhookUpdated, audit.TypeWebhookDeleted}, types)
+	assert.Equal(t, true, events[1].Metadata["rotated"])
+}
+
+// TestPurpose: Validates that audit events reach matching subscribers as signed deliveries.
+// Scope: Unit Test
+// Security: Events only reach the subscriptions of their own tenant; receivers can verify the sender
+// Expected: Matching enabled subscriptions get one signed delivery each; other tenants, types and platform events get none.
+// Test Case ID: WHK-03
+func TestWebhook_PublishAndDispatch(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, true)
+	rec, srv := newReceiver(t, http.StatusNoContent)
+
+	all, secret, err := env.svc.CreateSubscription(ctx, "t1", "u1", webhook.SubscriptionInput{URL: srv.URL, Enabled: true})
+	require.NoError(t, err)
+	_, _, err = env.svc.CreateSubscription(ctx, "t1", "u1", webhook.SubscriptionInput{
+		URL: srv.URL, EventTypes: []string{audit.TypeLoginFailed}, Enabled: true,
+	})
+	require.NoError(t, err)
+	_, _, err = env.svc.CreateSubscription(ctx, "t1", "u1", webhook.SubscriptionInput{URL: srv.URL, Enabled: false})
+	require.NoError(t, err)
+	_, _, err = env.svc.CreateSubscription(ctx, "t2", "u2", webhook.SubscriptionInput{URL: srv.URL, Enabled: true})
+	require.NoError(t, err)
+
+	env.publisher.Log(ctx, audit.Event{
+		Type:     audit.TypeUserCreated,
+		TenantID: "t1",
+		ActorID:  "u1",
+		Resource: audit.ResourceUser,
+		Payload:  &audit.UserCreatedPayload{UserID: "u9", Email: "new@example.com"},
+	})
+	env.publisher.Log(ctx, audit.Event{Type: audit.TypePlatformAdminBootstrap, Payload: &audit.BootstrapPayload{Email: "root@example.com"}})
+
+	d := newTestDispatcher(t, env.svc, webhook.DispatcherConfig{InitialBackoff: time.Minute, MaxBackoff: time.Hour})
+	n, err := d.DispatchOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n, "only the enabled catch-all subscription of t1 matches")
+	require.Len(t, rec.requests, 1)
+
+	req, body := rec.requests[0], rec.bodies[0]
+	assert.Equal(t, audit.TypeUserCreated, req.Header.Get(webhook.HeaderEvent))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.NoError(t, webhook.Verify(secret, req.Header.Get(webhook.HeaderSignature), body, time.Minute, time.Now()))
+
+	var env1 struct {
+		ID       string      `json:"id"`
+		Type     string      `json:"type"`
+		TenantID string      `json:"tenant_id"`
+		Data     audit.Event `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &env1))
+	assert.Equal(t, "t1", env1.TenantID)
+	assert.Equal(t, env1.ID, env1.Data.ID)
+	assert.Equal(t, "u9", env1.Data.Metadata["user_id"])
+
+	deliveries, _, err := env.svc.ListDeliveries(ctx, webhook.DeliveryFilter{TenantID: "t1", SubscriptionID: all.ID})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, req.Header.Get(webhook.HeaderDelivery), deliveries[0].ID)
+	assert.Equal(t, webhook.StatusSucceeded, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusNoContent, deliveries[0].ResponseStatus)
+
+	n, err = d.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "succeeded deliveries are not sent again")
+}
+
+// TestPurpose: Validates retries, dead letters and manual redelivery.
+// Scope: Unit Test
+// Security: Failed deliveries are kept for inspection instead of being silently lost
+// Expected: A failing endpoint is retried with backoff, becomes dead after the last attempt and can be redelivered once.
+// Test Case ID: WHK-04
+func TestWebhook_RetryAndRedeliver(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, true)
+	rec, srv := newReceiver(t, http.StatusInternalServerError)
+
+	sub, _, err := env.svc.CreateSubscription(ctx, "t1", "u1", webhook.SubscriptionInput{URL: srv.URL, Enabled: true})
+	require.NoError(t, err)
+	require.NoError(t, env.publisher.Publish(ctx, webhook.Envelope{ID: "e1", Type: audit.TypeLogout, TenantID: "t1", CreatedAt: time.Now()}))
+
+	list := func(status string) []*webhook.Delivery {
+		t.Helper()
+		deliveries, _, err := env.svc.ListDeliveries(ctx, webhook.DeliveryFilter{
+			TenantID: "t1", SubscriptionID: sub.ID, Status: status, Page: pagination.Params{Limit: 10},
+		})
+		require.NoError(t, err)
+		return deliveries
+	}
+
+	// First attempt fails and is scheduled after the initial backoff
+	slow := newTestDispatcher(t, env.svc, webhook.DispatcherConfig{MaxAttempts: 2, InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+	_, err = slow.DispatchOnce(ctx)
+	require.NoError(t, err)
+	pending := list(webhook.StatusPending)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, pending[0].ResponseStatus)
+	assert.Contains(t, pending[0].LastError, "500")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), pending[0].NextAttemptAt, time.Minute)
+
+	n, err := slow.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "the retry is not due yet")
+
+	// Make the retry due; the second failure exhausts the attempts
+	pending[0].NextAttemptAt = time.Now().Add(-time.Second)
+	require.NoError(t, env.deliveries.Update(ctx, pending[0]))
+	_, err = slow.DispatchOnce(ctx)
+	require.NoError(t, err)
+	dead := list(webhook.StatusDead)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 2, dead[0].Attempts)
+	assert.Len(t, rec.requests, 2)
+
+	_, err = env.svc.Redeliver(ctx, "t1", "00000000-0000-0000-0000-000000000000", dead[0].ID, "u1")
+	assert.ErrorIs(t, err, webhook.ErrDeliveryNotFound)
+	_, err = env.svc.Redeliver(ctx, "t2", sub.ID, dead[0].ID, "u1")
+	assert.ErrorIs(t, err, webhook.ErrDeliveryNotFound)
+
+	rec.mu.Lock()
+	rec.status = http.StatusOK
+	rec.mu.Unlock()
+	redelivery, err := env.svc.Redeliver(ctx, "t1", sub.ID, dead[0].ID, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "e1", redelivery.EventID)
+	assert.NotEqual(t, dead[0].ID, redelivery.ID)
+	_, err = env.svc.Redeliver(ctx, "t1", sub.ID, redelivery.ID, "u1")
+	assert.ErrorIs(t, err, webhook.ErrDeliveryPending)
+
+	_, err = slow.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list(webhook.StatusSucceeded), 1)
+	assert.Len(t, list(webhook.StatusDead), 1, "the dead letter stays in the history")
+	require.Len(t, rec.requests, 3)
+	assert.Equal(t, redelivery.ID, rec.requests[2].Header.Get(webhook.HeaderDelivery))
+	assert.Equal(t, rec.bodies[0], rec.bodies[2], "a redelivery sends the original event")
+
+	events, err := env.events.List(ctx, audit.Filter{TenantID: "t1", Type: audit.TypeWebhookRedelivered})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, redelivery.ID, events[0].Metadata["delivery_id"])
+}
+
+// TestPurpose: Validates that the dispatcher refuses to reach private addresses.
+// Scope: Unit Test
+// Security: Webhooks must not become a server-side request forgery channel into the internal network
+// Expected: A subscription whose host resolves to loopback is never contacted and its delivery fails.
+// Test Case ID: WHK-05
+func TestWebhook_BlocksPrivateAddresses(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, true)
+	rec, srv := newReceiver(t, http.StatusOK)
+
+	// Registered while insecure endpoints were allowed, then dispatched by a strict service
+	sub, _, err := env.svc.CreateSubscription(ctx, "t1", "u1", webhook.SubscriptionInput{URL: srv.URL, Enabled: true})
+	require.NoError(t, err)
+	require.NoError(t, env.publisher.Publish(ctx, webhook.Envelope{ID: "e1", Type: audit.TypeLogout, TenantID: "t1"}))
+
+	strict, err := webhook.NewService(env.subs, env.deliveries, testKey, audit.NewSlogLogger(), false)
+	require.NoError(t, err)
+	d := newTestDispatcher(t, strict, webhook.DispatcherConfig{MaxAttempts: 1, InitialBackoff: time.Minute, MaxBackoff: time.Minute})
+	_, err = d.DispatchOnce(ctx)
+	require.NoError(t, err)
+
+	assert.Empty(t, rec.requests)
+	deliveries, _, err := env.svc.ListDeliveries(ctx, webhook.DeliveryFilter{TenantID: "t1", SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhook.StatusDead, deliveries[0].Status)
+	assert.Contains(t, deliveries[0].LastError, "private or loopback")
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package opentrusty embeds the OpenTrusty identity provider in another Go program, for
+// deployments too small to run it as a separate process.
+//
+//	cfg := opentrusty.DefaultConfig()
+//	cfg.OAuth2.Issuer = "https://app.example.com/idp"
+//	cfg.Database.Driver = "sqlite"
+//	cfg.Database.SQLitePath = "/var/lib/app/idp.db"
+//	cfg.Security.KeyEncryptionKey = os.Getenv("IDP_KEY_ENCRYPTION_KEY")
+//
+//	idp, err := opentrusty.New(ctx, cfg, opentrusty.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer idp.Close(context.Background())
+//	if err := idp.Start(ctx); err != nil {
+//		return err
+//	}
+//	idp.Mount(router) // serves /idp/.well-known/openid-configuration, /idp/oauth2/token, ...
+//
+// The server behaves as `opentrusty serve` with the same configuration, except that the program
+// owns the listeners, TLS, logging and tracing. PostgreSQL schemas are applied with `opentrusty
+// migrate` or Options.Migrate; SQLite is migrated on New.
+package opentrusty
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/app"
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/store"
+)
+
+// Config is the server configuration. Each field is set by an environment variable in
+// ConfigFromEnv.
+type Config = config.Config
+
+// DefaultConfig returns the configuration used when no environment variable is set. The key
+// encryption key and the database settings must be filled in before calling New.
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// ConfigFromEnv reads the configuration from the environment, as `opentrusty serve` does
+func ConfigFromEnv() (*Config, error) {
+	return config.Load()
+}
+
+// Options adjusts an embedded server
+type Options struct {
+	// Mode selects the routes served: "auth" (OIDC and OAuth2), "admin" (management API) or
+	// "all" (default)
+	Mode string
+
+	// Repositories replace repositories of the configured database backend
+	Repositories Repositories
+
+	// Migrate applies the schema migrations of the database backend on New
+	Migrate bool
+}
+
+// Server is an embedded identity provider
+type Server struct {
+	cfg     *Config
+	app     *app.App
+	store   *store.Store
+	meter   *metrics.Meter
+	handler http.Handler
+}
+
+// New validates cfg, connects to the database and assembles the server. The caller must call
+// Close when done.
+func New(ctx context.Context, cfg *Config, opts Options) (*Server, error) {
+	mode := opts.Mode
+	switch mode {
+	case "":
+		mode = "all"
+	case "auth", "admin", "all":
+	default:
+		return nil, fmt.Errorf("unsupported mode %q: use auth, admin or all", mode)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	meter, err := metrics.New(ctx, metrics.Config{
+		Enabled:            cfg.Observability.OTELEnabled,
+		ServiceVersion:     cfg.Observability.ServiceVersion,
+		ResourceAttributes: cfg.Observability.ResourceAttributes,
+		Exporter:           cfg.Observability.OTLP(),
+		ExportInterval:     cfg.Observability.MetricsExportInterval,
+	}, cfg.Observability.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize meter: %w", err)
+	}
+	st, err := app.OpenStore(ctx, cfg, meter)
+	if err != nil {
+		meter.Shutdown(ctx)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	s := &Server{cfg: cfg, store: st, meter: meter}
+	if opts.Migrate {
+		if err := st.Migrate(ctx); err != nil {
+			s.shutdown(ctx)
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	s.app, err = app.New(ctx, cfg, st.With(opts.Repositories.store()), meter)
+	if err != nil {
+		s.shutdown(ctx)
+		return nil, err
+	}
+	s.handler, err = s.app.Handler(mode)
+	if err != nil {
+		s.app.Close(ctx)
+		s.shutdown(ctx)
+		return nil, err
+	}
+	return s, nil
+}
+
+// Handler returns the HTTP handler. It serves the routes at the root; when the issuer has a
+// path, strip it first or use Mount.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Mount mounts the handler on r at the path of the issuer, so that the endpoints advertised by
+// discovery are the ones served. With an issuer without a path the routes are mounted at "/".
+func (s *Server) Mount(r chi.Router) {
+	pattern := "/"
+	if u, err := url.Parse(s.cfg.OAuth2.Issuer); err == nil && u.Path != "" {
+		pattern = u.Path
+	}
+	r.Mount(pattern, s.handler)
+}
+
+// Start runs the event bus and the background jobs (janitor, signing key reload, webhook
+// delivery) until Close is called or ctx is done
+func (s *Server) Start(ctx context.Context) error {
+	return s.app.Start(ctx)
+}
+
+// Close stops the background jobs, letting runs in progress finish, delivers queued audit events
+// and closes the database. ctx bounds the shutdown.
+func (s *Server) Close(ctx context.Context) error {
+	s.app.Close(ctx)
+	return s.shutdown(ctx)
+}
+
+func (s *Server) shutdown(ctx context.Context) error {
+	s.store.Close()
+	return s.meter.Shutdown(ctx)
+}
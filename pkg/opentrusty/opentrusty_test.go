@@ -0,0 +1,107 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentrusty
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opentrusty/opentrusty/internal/store/memory"
+)
+
+// countingKeys records the signing keys created through an overriding repository
+type countingKeys struct {
+	KeyRepository
+	created int
+}
+
+func (k *countingKeys) Create(ctx context.Context, key *Key) error {
+	k.created++
+	return k.KeyRepository.Create(ctx, key)
+}
+
+// TestPurpose: Validates embedding the identity provider in another program's router.
+// Scope: Unit Test
+// Security: An embedded server must advertise and serve the same issuer-relative endpoints as a standalone one, and keep validating its configuration
+// Expected: Mounted under the issuer path, discovery and health are served next to the host's routes, a supplied repository replaces the backend's, and invalid configurations or modes are rejected.
+// Test Case ID: EMB-01
+func TestNew_Mount(t *testing.T) {
+	ctx := context.Background()
+	cfg := DefaultConfig()
+	cfg.Database.Driver = "memory"
+	cfg.OAuth2.Issuer = "http://localhost/idp"
+	cfg.Security.KeyEncryptionKey = "q8ZrT1vXbN4mK7pLw2sYc9dHf6gJa3eU"
+
+	keys := &countingKeys{KeyRepository: memory.NewKeyRepository(memory.New())}
+	idp, err := New(ctx, cfg, Options{Repositories: Repositories{Keys: keys}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer idp.Close(ctx)
+	if err := idp.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if keys.created != 1 {
+		t.Errorf("expected the signing key to be created in the supplied repository, got %d keys", keys.created)
+	}
+
+	r := chi.NewRouter()
+	r.Get("/app", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("host")) })
+	idp.Mount(r)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil))
+		return w
+	}
+
+	w := get("/idp/.well-known/openid-configuration")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected discovery under the issuer path, got %d: %s", w.Code, w.Body.String())
+	}
+	var discovery struct {
+		Issuer        string `json:"issuer"`
+		TokenEndpoint string `json:"token_endpoint"`
+		JWKSURI       string `json:"jwks_uri"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &discovery); err != nil {
+		t.Fatal(err)
+	}
+	if discovery.Issuer != cfg.OAuth2.Issuer || discovery.TokenEndpoint != "http://localhost/idp/oauth2/token" {
+		t.Errorf("unexpected discovery document: %+v", discovery)
+	}
+	if w := get(strings.TrimPrefix(discovery.JWKSURI, "http://localhost")); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"kid"`) {
+		t.Errorf("expected the advertised JWKS to be served, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := get("/idp/health"); w.Code != http.StatusOK {
+		t.Errorf("expected health under the issuer path, got %d", w.Code)
+	}
+	if w := get("/app"); w.Body.String() != "host" {
+		t.Errorf("expected host routes to be kept, got %d: %s", w.Code, w.Body.String())
+	}
+
+	invalid := DefaultConfig()
+	invalid.Database.Driver = "memory"
+	if _, err := New(ctx, invalid, Options{}); err == nil || !strings.Contains(err.Error(), "invalid configuration") {
+		t.Errorf("expected a missing key encryption key to be rejected, got %v", err)
+	}
+	if _, err := New(ctx, cfg, Options{Mode: "console"}); err == nil {
+		t.Error("expected an unsupported mode to be rejected")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentrusty
+
+import (
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/email"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/pagination"
+	"github.com/opentrusty/opentrusty/internal/session"
+	"github.com/opentrusty/opentrusty/internal/store"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/opentrusty/opentrusty/internal/webhook"
+)
+
+// Repositories replaces repositories of the configured database backend. Fields left nil keep the
+// backend's own. A replacement reports a missing record with the matching not-found error below,
+// wrapped or not.
+type Repositories struct {
+	Users         UserRepository
+	Sessions      SessionRepository
+	Projects      ProjectRepository
+	Roles         RoleRepository
+	Assignments   AssignmentRepository
+	Clients       ClientRepository
+	Codes         AuthorizationCodeRepository
+	AccessTokens  AccessTokenRepository
+	RefreshTokens RefreshTokenRepository
+	Keys          KeyRepository
+	Scopes        ScopeRepository
+	Tenants       TenantRepository
+	TenantRoles   TenantRoleRepository
+	// Memberships must be replaced together with Users: the backend's users become members of their
+	// home tenant as they are created, which a replaced UserRepository does not do
+	Memberships    MembershipRepository
+	EmailSettings  EmailSettingsRepository
+	AuditEvents    AuditRepository
+	AuditRetention AuditRetentionRepository
+	Webhooks       WebhookRepository
+	Deliveries     WebhookDeliveryRepository
+}
+
+// store returns the repositories as a store bundle for store.With
+func (r Repositories) store() store.Store {
+	return store.Store{
+		Users:          r.Users,
+		Sessions:       r.Sessions,
+		Projects:       r.Projects,
+		Roles:          r.Roles,
+		Assignments:    r.Assignments,
+		Clients:        r.Clients,
+		Codes:          r.Codes,
+		AccessTokens:   r.AccessTokens,
+		RefreshTokens:  r.RefreshTokens,
+		Keys:           r.Keys,
+		Scopes:         r.Scopes,
+		Tenants:        r.Tenants,
+		TenantRoles:    r.TenantRoles,
+		Memberships:    r.Memberships,
+		EmailSettings:  r.EmailSettings,
+		AuditEvents:    r.AuditEvents,
+		AuditRetention: r.AuditRetention,
+		Webhooks:       r.Webhooks,
+		Deliveries:     r.Deliveries,
+	}
+}
+
+// Repository interfaces
+type (
+	UserRepository              = identity.UserRepository
+	SessionRepository           = session.Repository
+	ProjectRepository           = authz.ProjectRepository
+	RoleRepository              = authz.RoleRepository
+	AssignmentRepository        = authz.AssignmentRepository
+	ClientRepository            = oauth2.ClientRepository
+	AuthorizationCodeRepository = oauth2.AuthorizationCodeRepository
+	AccessTokenRepository       = oauth2.AccessTokenRepository
+	RefreshTokenRepository      = oauth2.RefreshTokenRepository
+	KeyRepository               = oauth2.KeyRepository
+	ScopeRepository             = oauth2.ScopeRepository
+	TenantRepository            = tenant.Repository
+	TenantRoleRepository        = tenant.RoleRepository
+	MembershipRepository        = tenant.MembershipRepository
+	EmailSettingsRepository     = email.SettingsRepository
+	AuditRepository             = audit.Repository
+	AuditRetentionRepository    = audit.RetentionRepository
+	WebhookRepository           = webhook.SubscriptionRepository
+	WebhookDeliveryRepository   = webhook.DeliveryRepository
+)
+
+// Records and arguments of the repository interfaces
+type (
+	User                  = identity.User
+	Credentials           = identity.Credentials
+	Session               = session.Session
+	Project               = authz.Project
+	ProjectMember         = authz.ProjectMember
+	Role                  = authz.Role
+	RoleScope             = authz.Scope
+	Assignment            = authz.Assignment
+	Client                = oauth2.Client
+	AuthorizationCode     = oauth2.AuthorizationCode
+	AccessToken           = oauth2.AccessToken
+	RefreshToken          = oauth2.RefreshToken
+	Key                   = oauth2.Key
+	ScopeDefinition       = oauth2.ScopeDefinition
+	Tenant                = tenant.Tenant
+	TenantListOptions     = tenant.ListOptions
+	TenantListResult      = tenant.ListResult
+	TenantUserRole        = tenant.TenantUserRole
+	Membership            = tenant.Membership
+	EmailSettings         = email.Settings
+	AuditEvent            = audit.Event
+	AuditFilter           = audit.Filter
+	AuditExpiredFilter    = audit.ExpiredFilter
+	AuditRetentionPolicy  = audit.RetentionPolicy
+	WebhookSubscription   = webhook.Subscription
+	WebhookDelivery       = webhook.Delivery
+	WebhookDeliveryFilter = webhook.DeliveryFilter
+	Page                  = pagination.Params
+)
+
+// Not-found errors returned by the repositories
+var (
+	ErrUserNotFound                = identity.ErrUserNotFound
+	ErrSessionNotFound             = session.ErrSessionNotFound
+	ErrProjectNotFound             = authz.ErrProjectNotFound
+	ErrProjectMemberNotFound       = authz.ErrProjectMemberNotFound
+	ErrRoleNotFound                = authz.ErrRoleNotFound
+	ErrAssignmentNotFound          = authz.ErrAssignmentNotFound
+	ErrClientNotFound              = oauth2.ErrClientNotFound
+	ErrAuthorizationCodeNotFound   = oauth2.ErrCodeNotFound
+	ErrTokenNotFound               = oauth2.ErrTokenNotFound
+	ErrKeyNotFound                 = oauth2.ErrKeyNotFound
+	ErrScopeNotFound               = oauth2.ErrScopeNotFound
+	ErrTenantNotFound              = tenant.ErrTenantNotFound
+	ErrTenantRoleNotFound          = tenant.ErrRoleNotFound
+	ErrMembershipNotFound          = tenant.ErrMembershipNotFound
+	ErrEmailSettingsNotFound       = email.ErrSettingsNotFound
+	ErrAuditRetentionNotFound      = audit.ErrRetentionNotFound
+	ErrWebhookSubscriptionNotFound = webhook.ErrSubscriptionNotFound
+	ErrWebhookDeliveryNotFound     = webhook.ErrDeliveryNotFound
+)
+
+// ErrScopeAlreadyExists is returned by ScopeRepository.Create for a name the tenant already uses
+var ErrScopeAlreadyExists = oauth2.ErrScopeAlreadyExists
+
+// ErrMembershipExists is returned by MembershipRepository.Add for a user already in the tenant
+var ErrMembershipExists = tenant.ErrMembershipExists
@@ -0,0 +1,145 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// introspectionCacheSize bounds the cached results; the cache is emptied when it is full
+const introspectionCacheSize = 10000
+
+// introspector checks opaque tokens at the issuer's introspection endpoint (RFC 7662)
+type introspector struct {
+	url          string
+	issuer       string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+	ttl          time.Duration
+	now          func() time.Time
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]introspectionEntry
+}
+
+// introspectionEntry is a cached result; principal is nil for an inactive token
+type introspectionEntry struct {
+	principal *Principal
+	expires   time.Time
+}
+
+func newIntrospector(cfg Config, client *http.Client, now func() time.Time) *introspector {
+	return &introspector{
+		url:          cfg.IntrospectionURL,
+		issuer:       cfg.Issuer,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		client:       client,
+		ttl:          cfg.CacheTTL,
+		now:          now,
+		entries:      make(map[[sha256.Size]byte]introspectionEntry),
+	}
+}
+
+// introspect returns the principal of an active token. Results, inactive ones included, are
+// cached for the TTL but never beyond the token's expiry; tokens are keyed by their hash so
+// that the cache holds no usable credentials.
+func (i *introspector) introspect(ctx context.Context, token string) (*Principal, error) {
+	key := sha256.Sum256([]byte(token))
+	if i.ttl > 0 {
+		i.mu.Lock()
+		entry, ok := i.entries[key]
+		i.mu.Unlock()
+		if ok && i.now().Before(entry.expires) {
+			return activePrincipal(entry.principal)
+		}
+	}
+
+	p, err := i.fetch(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.ttl > 0 {
+		expires := i.now().Add(i.ttl)
+		if p != nil && !p.ExpiresAt.IsZero() && p.ExpiresAt.Before(expires) {
+			expires = p.ExpiresAt
+		}
+		i.mu.Lock()
+		if len(i.entries) >= introspectionCacheSize {
+			clear(i.entries)
+		}
+		i.entries[key] = introspectionEntry{principal: p, expires: expires}
+		i.mu.Unlock()
+	}
+	return activePrincipal(p)
+}
+
+// activePrincipal returns a copy of a cached principal, or ErrInvalidToken for an inactive token
+func activePrincipal(p *Principal) (*Principal, error) {
+	if p == nil {
+		return nil, fmt.Errorf("%w: token is not active", ErrInvalidToken)
+	}
+	cp := *p
+	cp.Scopes = append([]string(nil), p.Scopes...)
+	cp.Audience = append([]string(nil), p.Audience...)
+	cp.Claims = maps.Clone(p.Claims)
+	return &cp, nil
+}
+
+// fetch calls the introspection endpoint; it returns nil for an inactive token
+func (i *introspector) fetch(ctx context.Context, token string) (*Principal, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// Sent as is: the token endpoints read Basic credentials without form-decoding them
+	req.SetBasicAuth(i.clientID, i.clientSecret)
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: introspect: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// A 401 means the resource server's own credentials were rejected, not the token
+		return nil, fmt.Errorf("%w: introspect: %s", ErrUnavailable, resp.Status)
+	}
+
+	var claims map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%w: decode introspection response: %v", ErrUnavailable, err)
+	}
+	if active, _ := claims["active"].(bool); !active {
+		return nil, nil
+	}
+	if iss := stringClaim(claims, "iss"); iss != "" && strings.TrimSuffix(iss, "/") != i.issuer {
+		return nil, nil
+	}
+	return principal(claims), nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceserver
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRefreshInterval limits JWKS refetches for unknown key IDs, so that tokens with made-up key
+// IDs cannot make the validator flood the issuer
+const minRefreshInterval = 10 * time.Second
+
+// keySet caches the issuer's signing keys
+type keySet struct {
+	url    string
+	client *http.Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetched   time.Time // Last successful fetch
+	attempted time.Time // Last fetch, successful or not
+}
+
+func newKeySet(url string, client *http.Client, ttl time.Duration, now func() time.Time) *keySet {
+	return &keySet{url: url, client: client, ttl: ttl, now: now}
+}
+
+// key returns the public key with ID kid. The set is refetched when it is older than the TTL or
+// does not contain kid, which is how newly rotated keys are picked up.
+func (k *keySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	key, known := k.keys[kid]
+	stale := now.Sub(k.fetched) >= k.ttl
+	if known && !stale {
+		return key, nil
+	}
+	if now.Sub(k.attempted) >= minRefreshInterval {
+		k.attempted = now
+		if err := k.refresh(ctx); err != nil {
+			if known {
+				// Keep verifying with a known key while the issuer is unreachable
+				return key, nil
+			}
+			return nil, err
+		}
+		key, known = k.keys[kid]
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// jwk is the part of a JSON Web Key (RFC 7517) that RSA signature keys use
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// refresh fetches the key set; the caller holds k.mu
+func (k *keySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: fetch JWKS: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: fetch JWKS: %s", ErrUnavailable, resp.Status)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("%w: decode JWKS: %v", ErrUnavailable, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		pub, err := rsaPublicKey(key)
+		if err != nil {
+			continue
+		}
+		keys[key.Kid] = pub
+	}
+	k.keys = keys
+	k.fetched = k.now()
+	return nil
+}
+
+func rsaPublicKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, err
+	}
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+// verifyJWT verifies a JWT access token (RFC 9068) against the issuer's keys
+func (v *Validator) verifyJWT(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		// RFC 9068 Section 4: access tokens are explicitly typed, so that an ID token signed with
+		// the same keys is not accepted as an access token
+		typ, _ := t.Header["typ"].(string)
+		if !strings.EqualFold(typ, "at+jwt") && !strings.EqualFold(typ, "application/at+jwt") {
+			return nil, errors.New("not an access token")
+		}
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(v.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(v.now),
+	)
+	if err != nil {
+		if errors.Is(err, ErrUnavailable) || errors.Is(err, ErrInvalidToken) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return principal(claims), nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type contextKey struct{}
+
+// WithPrincipal returns a context carrying p
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFromContext returns the principal that Middleware stored in the request context
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
+
+// Middleware requires a valid bearer token (RFC 6750 Section 2.1) and stores its principal in the
+// request context. Rejected requests are answered with a WWW-Authenticate challenge.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			challenge(w, v.cfg.Realm, ErrMissingToken, "")
+			return
+		}
+		p, err := v.Validate(r.Context(), token)
+		if err != nil {
+			challenge(w, v.cfg.Realm, err, strings.Join(v.cfg.RequiredScopes, " "))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+// RequireScopes returns middleware that requires the principal stored by Middleware to carry
+// every one of scopes
+func RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				challenge(w, "", ErrMissingToken, "")
+				return
+			}
+			for _, scope := range scopes {
+				if !p.HasScope(scope) {
+					challenge(w, "", ErrInsufficientScope, strings.Join(scopes, " "))
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// challenge answers a rejected request (RFC 6750 Section 3). Failures to reach the issuer are
+// reported as 503 without a challenge, since the client's token may well be valid.
+func challenge(w http.ResponseWriter, realm string, err error, scope string) {
+	var status int
+	var code string
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		status = http.StatusUnauthorized
+	case errors.Is(err, ErrInsufficientScope):
+		status, code = http.StatusForbidden, "insufficient_scope"
+	case errors.Is(err, ErrInvalidToken):
+		status, code = http.StatusUnauthorized, "invalid_token"
+	default:
+		status, code = http.StatusServiceUnavailable, "temporarily_unavailable"
+	}
+
+	if status != http.StatusServiceUnavailable {
+		params := []string{}
+		if realm != "" {
+			params = append(params, `realm="`+quote(realm)+`"`)
+		}
+		if code != "" {
+			params = append(params, `error="`+code+`"`)
+		}
+		if scope != "" && code == "insufficient_scope" {
+			params = append(params, `scope="`+quote(scope)+`"`)
+		}
+		value := "Bearer"
+		if len(params) > 0 {
+			value += " " + strings.Join(params, ", ")
+		}
+		w.Header().Set("WWW-Authenticate", value)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if code == "" {
+		// A request without credentials gets no error code (RFC 6750 Section 3.1)
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+// quote escapes a quoted-string value (RFC 9110 Section 5.6.4)
+func quote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
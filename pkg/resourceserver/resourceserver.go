@@ -0,0 +1,239 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resourceserver validates OpenTrusty access tokens in the APIs that accept them.
+//
+//	validator, err := resourceserver.New(resourceserver.Config{
+//		Issuer:       "https://auth.example.com",
+//		ClientID:     "orders-api",
+//		ClientSecret: os.Getenv("ORDERS_API_CLIENT_SECRET"),
+//		Audiences:    []string{"orders-api"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	mux.Handle("/orders", validator.Middleware(resourceserver.RequireScopes("orders:read")(orders)))
+//
+// OpenTrusty issues opaque access tokens, which are checked with the introspection endpoint
+// (RFC 7662) using the resource server's own client credentials. Access tokens in JWT form
+// (RFC 9068, header typ "at+jwt") are verified locally against the issuer's JWKS instead. Both
+// results are cached; CacheTTL bounds how long a revoked token is still accepted.
+//
+// Handlers read the caller with PrincipalFromContext.
+package resourceserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validation errors. Errors returned by Validate wrap one of them.
+var (
+	ErrMissingToken      = errors.New("missing bearer token")
+	ErrInvalidToken      = errors.New("invalid token")
+	ErrInsufficientScope = errors.New("insufficient scope")
+	ErrUnavailable       = errors.New("token validation unavailable") // The issuer could not be reached
+)
+
+// Defaults for Config
+const (
+	DefaultCacheTTL = time.Minute
+	defaultTimeout  = 10 * time.Second
+	clockSkew       = 30 * time.Second
+)
+
+// Config configures a Validator
+type Config struct {
+	// Issuer is the OpenTrusty issuer URL; tokens from other issuers are rejected
+	Issuer string
+
+	// IntrospectionURL defaults to Issuer + "/oauth2/introspect"
+	IntrospectionURL string
+
+	// JWKSURL defaults to Issuer + "/jwks.json"
+	JWKSURL string
+
+	// ClientID and ClientSecret authenticate the resource server at the introspection endpoint.
+	// Without them only JWT access tokens are accepted.
+	ClientID     string
+	ClientSecret string
+
+	// Audiences, when set, requires the token to be issued for at least one of them
+	Audiences []string
+
+	// RequiredScopes must all be granted to the token for every request
+	RequiredScopes []string
+
+	// CacheTTL is how long introspection results and the JWKS are reused; zero uses
+	// DefaultCacheTTL and a negative value disables caching of introspection results
+	CacheTTL time.Duration
+
+	// HTTPClient calls the issuer; defaults to a client with a 10 second timeout
+	HTTPClient *http.Client
+
+	// Realm is reported in WWW-Authenticate challenges
+	Realm string
+}
+
+// Principal is the caller identified by a valid access token
+type Principal struct {
+	Subject   string         // Public subject identifier of the user, as in the ID token
+	ClientID  string         // Client the token was issued to
+	TenantID  string         // Tenant that issued the token
+	Scopes    []string       // Granted scopes
+	Audience  []string       // Intended audiences, when the token names them
+	ExpiresAt time.Time      // Token expiry
+	Claims    map[string]any // Raw JWT claims or introspection response
+}
+
+// HasScope reports whether the token was granted scope
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// Validator validates access tokens
+type Validator struct {
+	cfg           Config
+	client        *http.Client
+	keys          *keySet
+	introspection *introspector
+	now           func() time.Time
+}
+
+// New creates a Validator
+func New(cfg Config) (*Validator, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("resourceserver: issuer is required")
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	if cfg.IntrospectionURL == "" {
+		cfg.IntrospectionURL = cfg.Issuer + "/oauth2/introspect"
+	}
+	if cfg.JWKSURL == "" {
+		cfg.JWKSURL = cfg.Issuer + "/jwks.json"
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = DefaultCacheTTL
+	}
+	if cfg.Realm == "" {
+		cfg.Realm = cfg.Issuer
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	v := &Validator{cfg: cfg, client: client, now: time.Now}
+	v.keys = newKeySet(cfg.JWKSURL, client, max(cfg.CacheTTL, DefaultCacheTTL), v.clock)
+	if cfg.ClientID != "" {
+		v.introspection = newIntrospector(cfg, client, v.clock)
+	}
+	return v, nil
+}
+
+func (v *Validator) clock() time.Time {
+	return v.now()
+}
+
+// Validate checks an access token and returns its principal. Besides being active, the token
+// must come from the configured issuer, name one of the configured audiences and carry the
+// required scopes.
+func (v *Validator) Validate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	var p *Principal
+	var err error
+	switch {
+	case isJWT(token):
+		p, err = v.verifyJWT(ctx, token)
+	case v.introspection != nil:
+		p, err = v.introspection.introspect(ctx, token)
+	default:
+		return nil, fmt.Errorf("%w: opaque tokens need client credentials for introspection", ErrInvalidToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.ExpiresAt.IsZero() && v.now().After(p.ExpiresAt.Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if len(v.cfg.Audiences) > 0 && !slices.ContainsFunc(p.Audience, func(aud string) bool {
+		return slices.Contains(v.cfg.Audiences, aud)
+	}) {
+		return nil, fmt.Errorf("%w: token is not issued for this audience", ErrInvalidToken)
+	}
+	for _, scope := range v.cfg.RequiredScopes {
+		if !p.HasScope(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInsufficientScope, strings.Join(v.cfg.RequiredScopes, " "))
+		}
+	}
+	return p, nil
+}
+
+// isJWT reports whether token has the shape of a compact JWS; opaque OpenTrusty tokens do not
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// audience reads an aud claim, which is a string or an array of strings
+func audience(v any) []string {
+	switch aud := v.(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		var out []string
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// stringClaim reads a string claim, returning "" when absent or of another type
+func stringClaim(claims map[string]any, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// timeClaim reads a NumericDate claim
+func timeClaim(claims map[string]any, name string) time.Time {
+	if n, ok := claims[name].(float64); ok {
+		return time.Unix(int64(n), 0)
+	}
+	return time.Time{}
+}
+
+// principal builds the principal from JWT claims or an introspection response
+func principal(claims map[string]any) *Principal {
+	return &Principal{
+		Subject:   stringClaim(claims, "sub"),
+		ClientID:  stringClaim(claims, "client_id"),
+		TenantID:  stringClaim(claims, "tenant_id"),
+		Scopes:    strings.Fields(stringClaim(claims, "scope")),
+		Audience:  audience(claims["aud"]),
+		ExpiresAt: timeClaim(claims, "exp"),
+		Claims:    claims,
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeIssuer serves a JWKS and an introspection endpoint
+type fakeIssuer struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	keys           map[string]*rsa.PrivateKey
+	active         map[string]map[string]any // Introspection responses by token
+	jwksCalls      int
+	introspections int
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	f := &fakeIssuer{keys: map[string]*rsa.PrivateKey{}, active: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.jwksCalls++
+		var keys []map[string]string
+		for kid, key := range f.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA", "use": "sig", "alg": "RS256", "kid": kid,
+				"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	})
+	mux.HandleFunc("POST /oauth2/introspect", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.introspections++
+		if id, secret, ok := r.BasicAuth(); !ok || id != "orders-api" || secret != "s3cret%" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp, ok := f.active[r.PostFormValue("token")]
+		if !ok {
+			resp = map[string]any{"active": false}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeIssuer) addKey(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys[kid] = key
+}
+
+func (f *fakeIssuer) sign(t *testing.T, kid, typ string, claims jwt.MapClaims) string {
+	t.Helper()
+	f.mu.Lock()
+	key := f.keys[kid]
+	f.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	token.Header["typ"] = typ
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (f *fakeIssuer) calls() (jwks, introspections int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jwksCalls, f.introspections
+}
+
+// TestPurpose: Validates local verification of JWT access tokens against the issuer's JWKS.
+// Scope: Unit Test
+// Security: Token validation (RFC 9068); ID tokens, foreign issuers and expired tokens must not authorize API calls
+// Expected: A typed access token yields its principal; ID tokens, other issuers, other audiences, expired tokens and tampered signatures are rejected as invalid_token; a rotated key is fetched on first use while unknown key IDs are not refetched more than every 10 seconds.
+// Test Case ID: RSV-01
+func TestValidator_JWT(t *testing.T) {
+	ctx := context.Background()
+	issuer := newFakeIssuer(t)
+	issuer.addKey(t, "k1")
+	v, err := New(Config{Issuer: issuer.URL, Audiences: []string{"orders-api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": issuer.URL, "sub": "user-1", "aud": []string{"orders-api", "billing-api"},
+			"client_id": "web", "tenant_id": "tenant-1", "scope": "openid orders:read",
+			"exp": now.Add(time.Hour).Unix(), "iat": now.Unix(),
+		}
+	}
+
+	p, err := v.Validate(ctx, issuer.sign(t, "k1", "at+jwt", claims()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "user-1" || p.ClientID != "web" || p.TenantID != "tenant-1" || !p.HasScope("orders:read") || p.HasScope("orders:write") {
+		t.Errorf("unexpected principal: %+v", p)
+	}
+
+	rejected := map[string]string{
+		"id token":       issuer.sign(t, "k1", "JWT", claims()),
+		"other issuer":   issuer.sign(t, "k1", "at+jwt", func() jwt.MapClaims { c := claims(); c["iss"] = "https://evil.example.com"; return c }()),
+		"other audience": issuer.sign(t, "k1", "at+jwt", func() jwt.MapClaims { c := claims(); c["aud"] = "billing-api"; return c }()),
+		"expired":        issuer.sign(t, "k1", "at+jwt", func() jwt.MapClaims { c := claims(); c["exp"] = now.Add(-time.Hour).Unix(); return c }()),
+		"tampered":       issuer.sign(t, "k1", "at+jwt", claims()) + "x",
+	}
+	for name, token := range rejected {
+		if _, err := v.Validate(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+
+	// A key rotated in after the JWKS was cached is fetched on first use
+	now = now.Add(minRefreshInterval)
+	issuer.addKey(t, "k2")
+	if _, err := v.Validate(ctx, issuer.sign(t, "k2", "at+jwt", claims())); err != nil {
+		t.Fatalf("token signed with a rotated key: %v", err)
+	}
+	jwks, _ := issuer.calls()
+	for range 3 {
+		if _, err := v.Validate(ctx, issuer.sign(t, "k1", "at+jwt", claims())[:0]+"e30.e30.sig"); err == nil {
+			t.Fatal("expected a token without key ID to be rejected")
+		}
+	}
+	if after, _ := issuer.calls(); after != jwks {
+		t.Errorf("unknown key IDs refetched the JWKS %d times within the refresh interval", after-jwks)
+	}
+}
+
+// TestPurpose: Validates opaque tokens through the introspection endpoint and the result cache.
+// Scope: Unit Test
+// Security: Token validation (RFC 7662); revocation latency bounded by the cache TTL
+// Expected: Active tokens yield their principal and are introspected once per TTL; inactive tokens and other issuers are rejected as invalid_token; missing scopes are insufficient_scope; rejected resource server credentials report ErrUnavailable.
+// Test Case ID: RSV-02
+func TestValidator_Introspection(t *testing.T) {
+	ctx := context.Background()
+	issuer := newFakeIssuer(t)
+	now := time.Now()
+	issuer.active["opaque-1"] = map[string]any{
+		"active": true, "iss": issuer.URL, "sub": "user-1", "client_id": "web", "tenant_id": "tenant-1",
+		"scope": "orders:read", "exp": float64(now.Add(time.Hour).Unix()),
+	}
+	issuer.active["foreign"] = map[string]any{"active": true, "iss": "https://evil.example.com", "scope": "orders:read"}
+
+	v, err := New(Config{Issuer: issuer.URL + "/", ClientID: "orders-api", ClientSecret: "s3cret%", RequiredScopes: []string{"orders:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return now }
+
+	for range 2 {
+		p, err := v.Validate(ctx, "opaque-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Subject != "user-1" || p.TenantID != "tenant-1" {
+			t.Errorf("unexpected principal: %+v", p)
+		}
+		p.Scopes[0] = "admin" // must not leak into the cache
+	}
+	if _, n := issuer.calls(); n != 1 {
+		t.Errorf("expected one introspection within the TTL, got %d", n)
+	}
+	now = now.Add(DefaultCacheTTL)
+	if _, err := v.Validate(ctx, "opaque-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, n := issuer.calls(); n != 2 {
+		t.Errorf("expected a new introspection after the TTL, got %d", n)
+	}
+
+	for _, token := range []string{"revoked", "foreign"} {
+		if _, err := v.Validate(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", token, err)
+		}
+	}
+
+	issuer.active["no-scope"] = map[string]any{"active": true, "iss": issuer.URL, "scope": "profile"}
+	if _, err := v.Validate(ctx, "no-scope"); !errors.Is(err, ErrInsufficientScope) {
+		t.Errorf("expected ErrInsufficientScope, got %v", err)
+	}
+
+	wrongSecret, err := New(Config{Issuer: issuer.URL, ClientID: "orders-api", ClientSecret: "wrong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrongSecret.Validate(ctx, "opaque-1"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for rejected credentials, got %v", err)
+	}
+
+	jwtOnly, err := New(Config{Issuer: issuer.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtOnly.Validate(ctx, "opaque-1"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected opaque tokens to be rejected without client credentials, got %v", err)
+	}
+}
+
+// TestPurpose: Validates the HTTP middleware responses and principal propagation.
+// Scope: Unit Test
+// Security: Bearer token usage (RFC 6750); authorization by scope
+// Expected: Missing tokens get a bare 401 challenge, invalid tokens 401 invalid_token, missing scopes 403 insufficient_scope with the required scope, an unreachable issuer 503; accepted requests see the principal.
+// Test Case ID: RSV-03
+func TestMiddleware(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.active["read"] = map[string]any{"active": true, "sub": "user-1", "scope": "orders:read"}
+	v, err := New(Config{Issuer: issuer.URL, ClientID: "orders-api", ClientSecret: "s3cret%", Realm: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := func(scopes ...string) http.Handler {
+		return v.Middleware(RequireScopes(scopes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, _ := PrincipalFromContext(r.Context())
+			w.Write([]byte(p.Subject))
+		})))
+	}
+	serve := func(h http.Handler, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name          string
+		scopes        []string
+		authorization string
+		wantStatus    int
+		wantChallenge string
+	}{
+		{"missing", nil, "", http.StatusUnauthorized, `Bearer realm="orders"`},
+		{"basic", nil, "Basic dXNlcjpwYXNz", http.StatusUnauthorized, `Bearer realm="orders"`},
+		{"invalid", nil, "Bearer nope", http.StatusUnauthorized, `Bearer realm="orders", error="invalid_token"`},
+		{"insufficient scope", []string{"orders:write"}, "Bearer read", http.StatusForbidden, `Bearer error="insufficient_scope", scope="orders:write"`},
+		{"ok", []string{"orders:read"}, "bearer read", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(handler(tt.scopes...), tt.authorization)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("expected challenge %q, got %q", tt.wantChallenge, got)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != "user-1" {
+				t.Errorf("principal not passed to the handler: %q", rec.Body.String())
+			}
+		})
+	}
+
+	issuer.Close()
+	if rec := serve(handler(), "Bearer uncached"); rec.Code != http.StatusServiceUnavailable || rec.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("expected 503 without a challenge when the issuer is down, got %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+}
@@ -2,26 +2,18 @@
 set -e
 
 # check-docs.sh
-# Verifies that the generated API documentation is up-to-date with the code.
+# Verifies that the committed OpenAPI document and the TypeScript admin client generated from it are
+# up-to-date with the handler annotations.
 
 echo "Checking API documentation freshness..."
 
 # Ensure we are in the project root
 cd "$(dirname "$0")/.."
 
-# Run generation
-export PATH=$PATH:$(go env GOPATH)/bin
-make docs-gen
-
-# Check for changes in docs/api
-if git diff --quiet docs/api; then
+if make docs-check; then
     echo "✅ API documentation is up-to-date."
-    exit 0
 else
     echo "❌ API documentation is stale!"
-    echo "The following files have changed after running 'make docs-gen':"
-    git diff --name-only docs/api
-    echo ""
-    echo "Please run 'make docs-gen' locally and commit the changes."
+    echo "Please run 'make docs-gen' locally and commit internal/transport/http/openapi.json and clients/typescript/src/index.ts."
     exit 1
 fi
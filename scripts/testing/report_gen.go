@@ -46,6 +46,7 @@ type FinalTestResult struct {
 	Elapsed     float64      `json:"elapsed_seconds"`
 	Package     string       `json:"package"`
 	Failure     string       `json:"failure_reason,omitempty"`
+	Artifact    string       `json:"artifact,omitempty"` // Path of the test's artifact file, if any
 	Annotations TestMetadata `json:"annotations"`
 }
 
@@ -69,6 +70,7 @@ func main() {
 	excludeCats := flag.String("exclude-categories", "", "Comma-separated list of categories to exclude")
 	filterType := flag.String("filter-type", "", "Filter by test type (UT, ST, E2E, etc.)")
 	excludeType := flag.String("exclude-type", "", "Exclude by test type (UT, ST, E2E, etc.)")
+	artifactsDir := flag.String("artifacts", "", "Directory of per-test artifact files to link from the reports")
 	flag.Parse()
 
 	if *inputPath == "" || *outputJSON == "" || *outputMD == "" {
@@ -143,6 +145,10 @@ func main() {
 		results = filtered
 	}
 
+	if *artifactsDir != "" {
+		attachArtifacts(results, *artifactsDir)
+	}
+
 	// 4. Generate Reports
 	summary := generateSummary(results)
 
@@ -395,6 +401,28 @@ func parseTestOutput(path string, meta map[string]TestMetadata) []FinalTestResul
 	return list
 }
 
+// attachArtifacts links each result to its artifact file in dir. Files are named after the test,
+// with the subtest separator "/" replaced by "__", as tests/e2e/harness writes them.
+func attachArtifacts(results []FinalTestResult, dir string) {
+	for i := range results {
+		path := filepath.Join(dir, strings.ReplaceAll(results[i].Name, "/", "__")+".json")
+		if _, err := os.Stat(path); err == nil {
+			results[i].Artifact = path
+		}
+	}
+}
+
+// artifactLink returns the path of a result's artifact relative to the report at reportPath
+func artifactLink(t FinalTestResult, reportPath string) string {
+	if t.Artifact == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(filepath.Dir(reportPath), t.Artifact); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(t.Artifact)
+}
+
 func generateSummary(results []FinalTestResult) ReportSummary {
 	summary := ReportSummary{
 		GeneratedAt: time.Now(),
@@ -482,8 +510,13 @@ func saveMarkdown(summary ReportSummary, path string, title string) {
 				securityHighlight = "**" + securityHighlight + "**"
 			}
 
+			name := t.Name
+			if link := artifactLink(t, path); link != "" {
+				name += fmt.Sprintf(" ([transcript](%s))", link)
+			}
+
 			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
-				t.Annotations.TestCaseID, t.Name, statusIcon, t.Annotations.Purpose, securityHighlight))
+				t.Annotations.TestCaseID, name, statusIcon, t.Annotations.Purpose, securityHighlight))
 		}
 		sb.WriteString("\n")
 	}
@@ -622,9 +655,14 @@ func saveHTML(summary ReportSummary, path string, title string) {
 				security = `<span class="security-mark">🛡️ ` + security + `</span>`
 			}
 
+			name := `<code>` + t.Name + `</code>`
+			if link := artifactLink(t, path); link != "" {
+				name += ` <a href="` + link + `">transcript</a>`
+			}
+
 			sb.WriteString(`<tr>
                     <td class="col-id">` + t.Annotations.TestCaseID + `</td>
-                    <td class="col-name"><code>` + t.Name + `</code></td>
+                    <td class="col-name">` + name + `</td>
                     <td class="col-status"><span class="status-icon">` + icon + `</span></td>
                     <td class="col-purpose">` + t.Annotations.Purpose + `</td>
                     <td class="col-security">` + security + `</td>
@@ -39,15 +39,13 @@ until $(curl -sf http://localhost:8080/health > /dev/null); do
     sleep 2
     COUNT=$((COUNT+1))
 done
-sleep 5
-docker ps -a --filter name=opentrusty
-docker logs docker-opentrusty_test-1
-echo "--- Running Database Migrations ---"
-docker exec docker-opentrusty_test-1 ./opentrusty migrate
 
 echo "--- Running Go E2E Tests ---"
 cd "$PROJECT_ROOT"
 export OPENTRUSTY_API_URL="http://localhost:8080"
-go test "$@" ./tests/e2e/ --tags=e2e
+export E2E_OPENTRUSTY_CLI="docker exec -i docker-opentrusty_test-1 ./opentrusty"
+export E2E_CODE_LIFETIME="2s"
+export E2E_ARTIFACTS_DIR="${E2E_ARTIFACTS_DIR:-$PROJECT_ROOT/artifacts/tests/e2e}"
+go test "$@" ./tests/e2e/... --tags=e2e
 
 echo "--- E2E Tests Passed! ---"
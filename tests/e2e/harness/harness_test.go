@@ -0,0 +1,58 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/config"
+)
+
+// TestPurpose: Validates the relying party scenarios against an in-process server on the memory store.
+// Scope: E2E Test
+// Security: Authorization codes are bound to their PKCE verifier, single-use and short-lived; revoked refresh tokens and ended sessions grant nothing
+// Expected: Every scenario passes, and each writes a transcript in which codes, tokens, secrets and passwords are redacted.
+// Test Case ID: E2E-02
+func TestSuite_InProcess(t *testing.T) {
+	suite := StartInProcess(t, func(cfg *config.Config) {
+		cfg.OAuth2.AuthCodeLifetime = time.Second
+		cfg.RateLimit.LoginBurst = 100 // every scenario signs in as the same user
+	})
+	artifacts := t.TempDir()
+	suite.ArtifactsDir = artifacts
+	suite.Run(t)
+
+	for _, scenario := range Scenarios {
+		data, err := os.ReadFile(filepath.Join(artifacts, ArtifactName(t.Name()+"/"+scenario.Name)))
+		if err != nil {
+			t.Errorf("missing artifact of %s: %v", scenario.Name, err)
+			continue
+		}
+		var artifact Artifact
+		if err := json.Unmarshal(data, &artifact); err != nil || artifact.Status != "pass" || len(artifact.Exchanges) == 0 {
+			t.Errorf("unexpected artifact of %s: %v %+v", scenario.Name, err, artifact)
+		}
+		for _, leaked := range []string{AdminPassword, suite.RP.ClientSecret, `"access_token":"ey`} {
+			if strings.Contains(string(data), leaked) {
+				t.Errorf("artifact of %s contains %q", scenario.Name, leaked)
+			}
+		}
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Metadata is the part of the OIDC discovery document the relying party uses
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	RevocationEndpoint    string `json:"revocation_endpoint"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+}
+
+// RP is a fake relying party. It serves the redirect URI the authorization server sends the browser
+// back to and calls the endpoints advertised by discovery. Register its RedirectURI for the client,
+// then set ClientID and ClientSecret.
+type RP struct {
+	Metadata Metadata
+
+	ClientID     string
+	ClientSecret string
+	Scope        string
+
+	// Transport sends the requests of new sessions; nil uses http.DefaultTransport
+	Transport http.RoundTripper
+
+	server    *http.Server
+	listener  net.Listener
+	mu        sync.Mutex
+	callbacks map[string]url.Values // Authorization responses by state
+}
+
+// NewRP fetches the discovery document of issuer and starts the callback server on a loopback port
+func NewRP(ctx context.Context, issuer string) (*RP, error) {
+	rp := &RP{Scope: "openid profile", callbacks: make(map[string]url.Values)}
+	if err := getJSON(ctx, http.DefaultClient, issuer+"/.well-known/openid-configuration", &rp.Metadata); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if rp.Metadata.Issuer != issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", rp.Metadata.Issuer, issuer)
+	}
+
+	var err error
+	rp.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		rp.mu.Lock()
+		rp.callbacks[query.Get("state")] = query
+		rp.mu.Unlock()
+		w.Write([]byte("ok"))
+	})
+	rp.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go rp.server.Serve(rp.listener)
+	return rp, nil
+}
+
+// RedirectURI is the callback URL of the relying party
+func (rp *RP) RedirectURI() string {
+	return "http://" + rp.listener.Addr().String() + "/callback"
+}
+
+// Close stops the callback server
+func (rp *RP) Close() error {
+	return rp.server.Close()
+}
+
+// callback returns and forgets the authorization response for state
+func (rp *RP) callback(state string) (url.Values, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	query, ok := rp.callbacks[state]
+	delete(rp.callbacks, state)
+	return query, ok
+}
+
+// Flow holds the per-request values of one authorization request
+type Flow struct {
+	State    string
+	Nonce    string
+	Verifier string // PKCE code_verifier; the S256 challenge is sent
+}
+
+// NewFlow returns a flow with random state, nonce and code verifier
+func NewFlow() Flow {
+	return Flow{State: randomString(), Nonce: randomString(), Verifier: randomString() + randomString()}
+}
+
+// challenge is the S256 code challenge of the flow (RFC 7636 Section 4.2)
+func (f Flow) challenge() string {
+	sum := sha256.Sum256([]byte(f.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Tokens is a token endpoint response
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	Scope        string `json:"scope"`
+}
+
+// OAuthError is an error response of the authorization server
+type OAuthError struct {
+	Status      int    // HTTP status, 0 for errors returned to the redirect URI
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s (HTTP %d): %s", e.Code, e.Status, e.Description)
+	}
+	return fmt.Sprintf("%s (HTTP %d)", e.Code, e.Status)
+}
+
+// IsOAuthError reports whether err is an error response with the given error code
+func IsOAuthError(err error, code string) bool {
+	var oe *OAuthError
+	return errors.As(err, &oe) && oe.Code == code
+}
+
+// Session is a browser and the relying party's back channel for one scenario. Requests are recorded
+// in its transcript.
+type Session struct {
+	rp         *RP
+	issuer     string
+	browser    *http.Client
+	backend    *http.Client
+	transcript *Transcript
+}
+
+// NewSession returns a session with an empty cookie jar. A nil transcript disables recording.
+func (rp *RP) NewSession(transcript *Transcript) *Session {
+	next := rp.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	transport := transcript.transport(next)
+	jar, _ := cookiejar.New(nil)
+	return &Session{
+		rp:         rp,
+		issuer:     rp.Metadata.Issuer,
+		browser:    &http.Client{Jar: jar, Transport: transport, Timeout: 30 * time.Second},
+		backend:    &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		transcript: transcript,
+	}
+}
+
+// Login signs the browser in through the login API with an auth-plane session, the only kind the
+// authorization endpoint accepts. The user must hold an admin role; end users have no other way to
+// create a session yet.
+func (s *Session) Login(ctx context.Context, email, password string) error {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password, "namespace": "auth"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.issuer+"/api/v1/auth/login", strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-CSRF-Token", "e2e")
+	return expectStatus(s.browser.Do(req))
+}
+
+// Logout ends the browser's session
+func (s *Session) Logout(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.issuer+"/api/v1/auth/logout", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-CSRF-Token", "e2e")
+	return expectStatus(s.browser.Do(req))
+}
+
+// Authorize sends the browser to the authorization endpoint and returns the code delivered to the
+// relying party's callback. An error redirect is returned as *OAuthError; a response that does not
+// redirect to the callback, such as 401 without a session, as an error with its status.
+func (s *Session) Authorize(ctx context.Context, flow Flow) (string, error) {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.rp.ClientID},
+		"redirect_uri":          {s.rp.RedirectURI()},
+		"scope":                 {s.rp.Scope},
+		"state":                 {flow.State},
+		"nonce":                 {flow.Nonce},
+		"code_challenge":        {flow.challenge()},
+		"code_challenge_method": {"S256"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.rp.Metadata.AuthorizationEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if err := expectStatus(s.browser.Do(req)); err != nil {
+		return "", err
+	}
+	response, ok := s.rp.callback(flow.State)
+	if !ok {
+		return "", fmt.Errorf("the browser did not reach the callback with state %q", flow.State)
+	}
+	if code := response.Get("error"); code != "" {
+		return "", &OAuthError{Code: code, Description: response.Get("error_description")}
+	}
+	if response.Get("code") == "" {
+		return "", errors.New("callback without code")
+	}
+	return response.Get("code"), nil
+}
+
+// Exchange redeems an authorization code with the given PKCE verifier
+func (s *Session) Exchange(ctx context.Context, code, verifier string) (*Tokens, error) {
+	return s.token(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.rp.RedirectURI()},
+		"code_verifier": {verifier},
+	})
+}
+
+// Refresh redeems a refresh token
+func (s *Session) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	return s.token(ctx, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+}
+
+func (s *Session) token(ctx context.Context, form url.Values) (*Tokens, error) {
+	var tokens Tokens
+	if err := s.post(ctx, s.rp.Metadata.TokenEndpoint, form, &tokens); err != nil {
+		return nil, err
+	}
+	if tokens.AccessToken == "" || !strings.EqualFold(tokens.TokenType, "Bearer") {
+		return nil, fmt.Errorf("unexpected token response: token_type %q", tokens.TokenType)
+	}
+	return &tokens, nil
+}
+
+// Revoke revokes a token (RFC 7009)
+func (s *Session) Revoke(ctx context.Context, token string) error {
+	return s.post(ctx, s.rp.Metadata.RevocationEndpoint, url.Values{"token": {token}}, nil)
+}
+
+// Introspect reports whether an access token is active (RFC 7662)
+func (s *Session) Introspect(ctx context.Context, token string) (bool, error) {
+	var resp struct {
+		Active bool `json:"active"`
+	}
+	err := s.post(ctx, s.rp.Metadata.IntrospectionEndpoint, url.Values{"token": {token}}, &resp)
+	return resp.Active, err
+}
+
+// post sends a form authenticated with the client credentials and decodes the JSON response into
+// out, if any. Error responses are returned as *OAuthError.
+func (s *Session) post(ctx context.Context, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.rp.ClientID, s.rp.ClientSecret)
+	resp, err := s.backend.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		oe := &OAuthError{Status: resp.StatusCode}
+		json.NewDecoder(resp.Body).Decode(oe)
+		return oe
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// VerifyIDToken checks the signature of an ID token against the JWKS and its claims against the
+// flow and the access token issued with it (OIDC Core Section 3.1.3.7)
+func (s *Session) VerifyIDToken(ctx context.Context, tokens *Tokens, flow Flow) (jwt.MapClaims, error) {
+	if tokens.IDToken == "" {
+		return nil, errors.New("no id_token in the token response")
+	}
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, s.backend, s.rp.Metadata.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokens.IDToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range jwks.Keys {
+			if key.Kid != kid {
+				continue
+			}
+			n, errN := base64.RawURLEncoding.DecodeString(key.N)
+			e, errE := base64.RawURLEncoding.DecodeString(key.E)
+			if errN != nil || errE != nil {
+				return nil, errors.New("malformed key")
+			}
+			return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+		}
+		return nil, fmt.Errorf("kid %q is not published", kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(s.rp.Metadata.Issuer),
+		jwt.WithAudience(s.rp.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims["nonce"] != flow.Nonce {
+		return nil, fmt.Errorf("nonce %v does not match the request", claims["nonce"])
+	}
+	sum := sha256.Sum256([]byte(tokens.AccessToken))
+	if claims["at_hash"] != base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]) {
+		return nil, errors.New("at_hash does not match the access token")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("missing sub")
+	}
+	return claims, nil
+}
+
+// expectStatus closes the response and turns a status other than 200 into an error
+func expectStatus(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: HTTP %d", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode)
+	}
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: HTTP %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package harness drives an OpenTrusty instance through complete relying party flows: authorization
+// code with PKCE, refresh, revocation and logout, and the ways they must fail. The same scenarios
+// run against an in-process server in this package's tests and against a container in tests/e2e.
+//
+// Each scenario is a subtest. When ArtifactsDir is set, its HTTP transcript, with credentials
+// redacted, is written there as <test name>.json for scripts/testing/report_gen.go -artifacts.
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Suite runs the scenarios against one client and user
+type Suite struct {
+	// RP is the relying party. Its ClientID and ClientSecret must belong to a confidential client
+	// registered with RP.RedirectURI and the authorization_code and refresh_token grant types.
+	RP *RP
+
+	// Email and Password sign the user in. The user must be allowed to use the login API.
+	Email    string
+	Password string
+
+	// CodeLifetime is the authorization code lifetime configured on the server. The expired code
+	// scenario waits it out, and is skipped when it is zero.
+	CodeLifetime time.Duration
+
+	// ArtifactsDir receives the scenario transcripts. Empty disables them.
+	ArtifactsDir string
+}
+
+// Scenario is one relying party flow
+type Scenario struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, suite *Suite, s *Session) error
+}
+
+// errSkip is returned by a scenario that does not apply to the suite
+var errSkip = errors.New("skipped")
+
+// Scenarios are the flows Run executes, in order
+var Scenarios = []Scenario{
+	{
+		Name:        "code_pkce",
+		Description: "Authorization code with PKCE yields verifiable tokens",
+		Run: func(ctx context.Context, suite *Suite, s *Session) error {
+			flow, tokens, err := suite.signIn(ctx, s)
+			if err != nil {
+				return err
+			}
+			claims, err := s.VerifyIDToken(ctx, tokens, flow)
+			if err != nil {
+				return fmt.Errorf("id_token: %w", err)
+			}
+			if email, ok := claims["email"]; ok && email != suite.Email {
+				return fmt.Errorf("id_token email %v, expected %s", email, suite.Email)
+			}
+			return expectActive(ctx, s, tokens.AccessToken, true)
+		},
+	},
+	{
+		Name:        "refresh",
+		Description: "A refresh token yields a new active access token",
+		Run: func(ctx context.Context, suite *Suite, s *Session) error {
+			_, tokens, err := suite.signIn(ctx, s)
+			if err != nil {
+				return err
+			}
+			if tokens.RefreshToken == "" {
+				return errors.New("no refresh_token issued; does the client allow the refresh_token grant?")
+			}
+			refreshed, err := s.Refresh(ctx, tokens.RefreshToken)
+			if err != nil {
+				return fmt.Errorf("refresh: %w", err)
+			}
+			if refreshed.AccessToken == tokens.AccessToken {
+				return errors.New("refresh returned the same access token")
+			}
+			return expectActive(ctx, s, refreshed.AccessToken, true)
+		},
+	},
+	{
+		Name:        "revoked_refresh_token",
+		Description: "A revoked refresh token is rejected with invalid_grant",
+		Run: func(ctx context.Context, suite *Suite, s *Session) error {
+			_, tokens, err := suite.signIn(ctx, s)
+			if err != nil {
+				return err
+			}
+			if err := s.Revoke(ctx, tokens.RefreshToken); err != nil {
+				return fmt.Errorf("revoke: %w", err)
+			}
+			_, err = s.Refresh(ctx, tokens.RefreshToken)
+			return expectOAuthError(err, "invalid_grant")
+		},
+	},
+	{
+		Name:        "logout",
+		Description: "After logout the authorization endpoint requires a new login",
+		Run: func(ctx context.Context, suite *Suite, s *Session) error {
+			if _, _, err := suite.signIn(ctx, s); err != nil {
+				return err
+			}
+			if err := s.Logout(ctx); err != nil {
+				return fmt.Errorf("logout: %w", err)
+			}
+			if _, err := s.Authorize(ctx, NewFlow()); err == nil {
+				return errors.New("authorization succeeded after logout")
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "expired_code",
+		Description: "An authorization code redeemed after its lifetime is rejected with invalid_grant",
+		Run: func(ctx context.Context, suite *Suite, s *Session) error {
+			if suite.CodeLifetime == 0 {
+				return errSkip
+			}
+			flow, code, err := suite.authorize(ctx, s)
+			if err != nil {
+				return err
+			}
+			select {
+			case <-time.After(suite.CodeLifetime + time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			_, err = s.Exchange(ctx, code, flow.Verifier)
+			return expectOAuthError(err, "invalid_grant")
+		},
+	},
+	{
+		Name:        "wrong_verifier",
+		Description: "A code redeemed with another PKCE verifier is rejected with invalid_grant",
+		Run: func(ctx context.Context, suite *Suite, s *Session) error {
+			_, code, err := suite.authorize(ctx, s)
+			if err != nil {
+				return err
+			}
+			_, err = s.Exchange(ctx, code, NewFlow().Verifier)
+			return expectOAuthError(err, "invalid_grant")
+		},
+	},
+	{
+		Name:        "code_replay",
+		Description: "A code can be redeemed only once",
+		Run: func(ctx context.Context, suite *Suite, s *Session) error {
+			flow, code, err := suite.authorize(ctx, s)
+			if err != nil {
+				return err
+			}
+			if _, err := s.Exchange(ctx, code, flow.Verifier); err != nil {
+				return fmt.Errorf("first exchange: %w", err)
+			}
+			_, err = s.Exchange(ctx, code, flow.Verifier)
+			return expectOAuthError(err, "invalid_grant")
+		},
+	},
+}
+
+// Run executes the scenarios as subtests of t
+func (suite *Suite) Run(t *testing.T) {
+	t.Helper()
+	for _, scenario := range Scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			transcript := &Transcript{}
+			start := time.Now()
+			err := scenario.Run(ctx, suite, suite.RP.NewSession(transcript))
+			if werr := suite.writeArtifact(t.Name(), scenario, start, err, transcript); werr != nil {
+				t.Errorf("artifact: %v", werr)
+			}
+			switch {
+			case errors.Is(err, errSkip):
+				t.Skip("not applicable: CodeLifetime is not set")
+			case err != nil:
+				t.Fatalf("%s: %v", scenario.Description, err)
+			}
+		})
+	}
+}
+
+// Artifact is the file written for each scenario
+type Artifact struct {
+	Test        string        `json:"test"`
+	Scenario    string        `json:"scenario"`
+	Description string        `json:"description"`
+	Status      string        `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	Started     time.Time     `json:"started"`
+	Duration    time.Duration `json:"duration_ns"`
+	Exchanges   []Exchange    `json:"exchanges"`
+}
+
+// ArtifactName is the file name of the artifact of a test
+func ArtifactName(test string) string {
+	return strings.ReplaceAll(test, "/", "__") + ".json"
+}
+
+func (suite *Suite) writeArtifact(test string, scenario Scenario, start time.Time, err error, transcript *Transcript) error {
+	if suite.ArtifactsDir == "" {
+		return nil
+	}
+	artifact := Artifact{
+		Test:        test,
+		Scenario:    scenario.Name,
+		Description: scenario.Description,
+		Status:      "pass",
+		Started:     start.UTC(),
+		Duration:    time.Since(start),
+		Exchanges:   transcript.Exchanges,
+	}
+	switch {
+	case errors.Is(err, errSkip):
+		artifact.Status = "skip"
+	case err != nil:
+		artifact.Status = "fail"
+		artifact.Error = err.Error()
+	}
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(suite.ArtifactsDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(suite.ArtifactsDir, ArtifactName(test)), data, 0o644)
+}
+
+// signIn logs in and completes an authorization code flow
+func (suite *Suite) signIn(ctx context.Context, s *Session) (Flow, *Tokens, error) {
+	flow, code, err := suite.authorize(ctx, s)
+	if err != nil {
+		return flow, nil, err
+	}
+	tokens, err := s.Exchange(ctx, code, flow.Verifier)
+	if err != nil {
+		return flow, nil, fmt.Errorf("exchange: %w", err)
+	}
+	return flow, tokens, nil
+}
+
+// authorize logs in and obtains an authorization code
+func (suite *Suite) authorize(ctx context.Context, s *Session) (Flow, string, error) {
+	flow := NewFlow()
+	if err := s.Login(ctx, suite.Email, suite.Password); err != nil {
+		return flow, "", fmt.Errorf("login: %w", err)
+	}
+	code, err := s.Authorize(ctx, flow)
+	if err != nil {
+		return flow, "", fmt.Errorf("authorize: %w", err)
+	}
+	return flow, code, nil
+}
+
+func expectOAuthError(err error, code string) error {
+	if err == nil {
+		return fmt.Errorf("expected %s, the request succeeded", code)
+	}
+	if !IsOAuthError(err, code) {
+		return fmt.Errorf("expected %s, got %w", code, err)
+	}
+	return nil
+}
+
+func expectActive(ctx context.Context, s *Session, token string, want bool) error {
+	active, err := s.Introspect(ctx, token)
+	if err != nil {
+		return fmt.Errorf("introspect: %w", err)
+	}
+	if active != want {
+		return fmt.Errorf("introspection reports active=%t, expected %t", active, want)
+	}
+	return nil
+}
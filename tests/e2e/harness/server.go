@@ -0,0 +1,183 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/app"
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/authz"
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/observability/metrics"
+	"github.com/opentrusty/opentrusty/internal/rbac"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+)
+
+// Credentials of the platform admin the suites sign in as
+const (
+	AdminEmail    = "e2e-admin@example.com"
+	AdminPassword = "E2e-Admin-Passw0rd!"
+)
+
+// StartInProcess serves an identity provider on the memory store from the test process and returns
+// a suite for it, with the admin and a client provisioned. configure adjusts the configuration
+// before the server is built. Everything is torn down when the test ends.
+func StartInProcess(t testing.TB, configure func(*config.Config)) *Suite {
+	t.Helper()
+	ctx := context.Background()
+	server := httptest.NewUnstartedServer(nil)
+
+	cfg := config.Default()
+	cfg.Database.Driver = "memory"
+	cfg.OAuth2.Issuer = "http://" + server.Listener.Addr().String()
+	cfg.Security.KeyEncryptionKey = "q8ZrT1vXbN4mK7pLw2sYc9dHf6gJa3eU"
+	cfg.Session.CookieSecure = false
+	if configure != nil {
+		configure(cfg)
+	}
+
+	meter, err := metrics.New(ctx, metrics.Config{}, "opentrusty-e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := app.OpenStore(ctx, cfg, meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	application, err := app.New(ctx, cfg, st, meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { application.Close(ctx) })
+	if err := application.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	handler, err := application.Handler("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Config.Handler = handler
+	server.Start()
+	t.Cleanup(server.Close)
+
+	rp, err := NewRP(ctx, cfg.OAuth2.Issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rp.Close() })
+
+	// A platform administrator, since only administrators can sign in, and a client of a tenant
+	now := time.Now()
+	tenantID := id.NewUUIDv7()
+	if err := st.Tenants.Create(ctx, &tenant.Tenant{ID: tenantID, Name: "harness", Status: tenant.StatusActive, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := application.Identity.ProvisionIdentity(ctx, "", AdminEmail, identity.Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := application.Identity.AddPassword(ctx, user.ID, AdminPassword); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Assignments.Grant(ctx, &authz.Assignment{
+		ID:        id.NewUUIDv7(),
+		UserID:    user.ID,
+		RoleID:    rbac.RoleIDPlatformAdmin,
+		Scope:     authz.ScopePlatform,
+		GrantedAt: now,
+		GrantedBy: audit.ActorSystemBootstrap,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	secret := oauth2.GenerateClientSecret()
+	client := &oauth2.Client{
+		TenantID:                tenantID,
+		ClientSecretHash:        oauth2.HashClientSecret(secret),
+		ClientName:              "harness",
+		RedirectURIs:            []string{rp.RedirectURI()},
+		AllowedScopes:           []string{"openid", "profile"},
+		GrantTypes:              []string{"authorization_code", "refresh_token"},
+		ResponseTypes:           []string{"code"},
+		TokenEndpointAuthMethod: "client_secret_basic",
+		AccessTokenLifetime:     3600,
+		RefreshTokenLifetime:    3600,
+		IDTokenLifetime:         3600,
+		IsActive:                true,
+	}
+	if err := application.OAuth2.CreateClient(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	rp.ClientID, rp.ClientSecret = client.ClientID, secret
+
+	return &Suite{RP: rp, Email: AdminEmail, Password: AdminPassword, CodeLifetime: cfg.OAuth2.AuthCodeLifetime}
+}
+
+// ProvisionWithCLI provisions the admin, a tenant and a client for rp through the opentrusty CLI
+// of the server under test, run as the command cli, and returns a suite for them. Bootstrap is
+// idempotent, so this can run again against the same database.
+func ProvisionWithCLI(ctx context.Context, cli []string, rp *RP) (*Suite, error) {
+	var admin struct {
+		Tenant struct {
+			ID string `json:"id"`
+		} `json:"tenant"`
+	}
+	if err := runCLI(ctx, cli, AdminPassword+"\n", &admin,
+		"bootstrap", "--email", AdminEmail, "--create-tenant", "e2e", "--password-stdin", "-o", "json"); err != nil {
+		return nil, err
+	}
+
+	var client struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := runCLI(ctx, cli, "", &client,
+		"client", "create", "--tenant", admin.Tenant.ID, "-o", "json",
+		"--name", "e2e-rp-"+id.NewUUIDv7()[:8],
+		"--redirect-uri", rp.RedirectURI(),
+		"--scope", "openid,profile",
+		"--grant-type", "authorization_code,refresh_token"); err != nil {
+		return nil, err
+	}
+	rp.ClientID, rp.ClientSecret = client.ClientID, client.ClientSecret
+	return &Suite{RP: rp, Email: AdminEmail, Password: AdminPassword}, nil
+}
+
+// runCLI runs a CLI command with stdin and decodes its JSON output into out
+func runCLI(ctx context.Context, cli []string, stdin string, out any, args ...string) error {
+	cmd := exec.CommandContext(ctx, cli[0], append(cli[1:], args...)...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("opentrusty %s: %w: %s", args[0], err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		return fmt.Errorf("opentrusty %s: %w: %s", args[0], err, stdout.String())
+	}
+	return nil
+}
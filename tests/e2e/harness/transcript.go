@@ -0,0 +1,153 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted lists the parameters and JSON fields whose values are replaced in transcripts: they are
+// credentials, or grant them
+var redacted = map[string]bool{
+	"code":          true,
+	"code_verifier": true,
+	"password":      true,
+	"client_secret": true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+}
+
+// Exchange is one recorded HTTP request and its response
+type Exchange struct {
+	Method       string        `json:"method"`
+	URL          string        `json:"url"`
+	RequestBody  string        `json:"request_body,omitempty"`
+	Status       int           `json:"status"`
+	Location     string        `json:"location,omitempty"`
+	ResponseBody string        `json:"response_body,omitempty"`
+	Duration     time.Duration `json:"duration_ns"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Transcript records the HTTP exchanges of a scenario with credentials redacted
+type Transcript struct {
+	mu        sync.Mutex
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// transport records the exchanges sent through next. A nil transcript records nothing.
+func (t *Transcript) transport(next http.RoundTripper) http.RoundTripper {
+	if t == nil {
+		return next
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		exchange := Exchange{Method: req.Method, URL: redactURL(req.URL)}
+		if req.Body != nil {
+			body, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			exchange.RequestBody = redactBody(req.Header.Get("Content-Type"), body)
+		}
+
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		exchange.Duration = time.Since(start)
+		if err != nil {
+			exchange.Error = err.Error()
+			t.add(exchange)
+			return nil, err
+		}
+		exchange.Status = resp.StatusCode
+		if location, err := resp.Location(); err == nil {
+			exchange.Location = redactURL(location)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			exchange.Error = err.Error()
+		}
+		exchange.ResponseBody = redactBody(resp.Header.Get("Content-Type"), body)
+		t.add(exchange)
+		return resp, nil
+	})
+}
+
+func (t *Transcript) add(exchange Exchange) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Exchanges = append(t.Exchanges, exchange)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	c.RawQuery = redactValues(u.Query()).Encode()
+	return c.String()
+}
+
+func redactValues(values url.Values) url.Values {
+	for key := range values {
+		if redacted[key] {
+			values[key] = []string{"REDACTED"}
+		}
+	}
+	return values
+}
+
+// redactBody returns a JSON or form body with credentials replaced; other bodies, such as HTML,
+// are left out
+func redactBody(contentType string, body []byte) string {
+	switch {
+	case len(body) == 0:
+		return ""
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		return redactValues(values).Encode()
+	case strings.HasPrefix(contentType, "application/json"):
+		var fields map[string]any
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ""
+		}
+		for key := range fields {
+			if redacted[key] {
+				fields[key] = "REDACTED"
+			}
+		}
+		out, _ := json.Marshal(fields)
+		return string(out)
+	}
+	return ""
+}
@@ -21,7 +21,7 @@ else
     GOARCH=amd64 # fallback
 fi
 echo "Building for linux/$GOARCH (Static)..."
-CGO_ENABLED=0 GOOS=linux GOARCH=$GOARCH go build -o bin/opentrusty-linux ./cmd/server
+CGO_ENABLED=0 GOOS=linux GOARCH=$GOARCH go build -o bin/opentrusty-linux ./cmd/opentrusty
 
 echo "--- Building Systemd Test Image ---"
 docker build -t opentrusty-systemd -f "$SCRIPT_DIR/Dockerfile.systemd" .
@@ -69,7 +69,7 @@ DB_PORT=5432
 DB_USER=opentrusty
 DB_PASSWORD=dummy_pass_for_smoke_test
 DB_NAME=opentrusty
-ISSUER=http://localhost:8080
+OIDC_ISSUER=http://localhost:8080
 PORT=8080
 LOG_LEVEL=debug
 EOF
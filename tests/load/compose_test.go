@@ -0,0 +1,80 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build load
+
+package load
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/tests/e2e/harness"
+)
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// TestPurpose: Gates a release on the latency objectives of login, code exchange and introspection against the containerized server and PostgreSQL.
+// Scope: Load Test
+// Expected: At LOAD_RPS requests per second per target for LOAD_DURATION, every target meets its SLO; the report is written to LOAD_REPORT_DIR.
+// Test Case ID: LOAD-03
+func TestLoad_Compose(t *testing.T) {
+	ctx := context.Background()
+	rate, err := strconv.ParseFloat(getEnv("LOAD_RPS", "20"), 64)
+	if err != nil {
+		t.Fatalf("LOAD_RPS: %v", err)
+	}
+	duration, err := time.ParseDuration(getEnv("LOAD_DURATION", "30s"))
+	if err != nil {
+		t.Fatalf("LOAD_DURATION: %v", err)
+	}
+
+	rp, err := harness.NewRP(ctx, getEnv("OPENTRUSTY_API_URL", "http://localhost:8080"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rp.Close()
+	suite, err := harness.ProvisionWithCLI(ctx, strings.Fields(getEnv("E2E_OPENTRUSTY_CLI", "docker exec -i docker-opentrusty_test-1 ./opentrusty")), rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{Rate: rate, Duration: duration}
+	targets, err := Targets(ctx, suite, cfg.MaxInFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Run(ctx, cfg, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir := os.Getenv("LOAD_REPORT_DIR"); dir != "" {
+		if err := report.Write(dir); err != nil {
+			t.Errorf("report: %v", err)
+		}
+	}
+	t.Log("\n" + report.Markdown())
+	for _, violation := range report.Violations() {
+		t.Error(violation)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package load drives an OpenTrusty instance at a constant request rate and checks the latencies
+// against service level objectives. Requests are sent on schedule whether or not earlier ones have
+// returned, so a slow server shows up as latency rather than as a lower request rate.
+//
+// The targets exercise login, the authorization code exchange and token introspection through the
+// relying party of tests/e2e/harness. A short run is a load test; a run of an hour or more against
+// the same instance is a soak test, for which the report keeps per-window latencies.
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Config sets the rate and length of a run
+type Config struct {
+	Rate        float64       // Requests per second, per target
+	Duration    time.Duration // Length of the run
+	Timeout     time.Duration // Per request; a request that takes longer fails. Default 10s.
+	MaxInFlight int           // Requests of a target outstanding at once; beyond it requests are dropped. Default 512.
+	Window      time.Duration // Granularity of the per-window latencies. Default 10s.
+}
+
+// SLO is the service level objective of a target. Zero fields are not checked.
+type SLO struct {
+	P95          time.Duration
+	P99          time.Duration
+	MaxErrorRate float64 // Failed and dropped requests over all requests, 0 to 1
+}
+
+// Target is one kind of request
+type Target struct {
+	Name string
+	SLO  SLO
+	Op   func(ctx context.Context) error
+}
+
+// errDropped marks a request not sent because MaxInFlight requests were outstanding
+var errDropped = errors.New("dropped: too many requests in flight")
+
+// sample is the outcome of one request
+type sample struct {
+	at      time.Duration // Since the start of the run
+	latency time.Duration
+	err     error
+}
+
+// Run sends the targets' requests concurrently for cfg.Duration and reports the results
+func Run(ctx context.Context, cfg Config, targets []Target) (*Report, error) {
+	if cfg.Rate <= 0 || cfg.Duration <= 0 {
+		return nil, fmt.Errorf("rate and duration must be positive")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 512
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+
+	report := &Report{Started: time.Now().UTC(), Rate: cfg.Rate, Duration: cfg.Duration.String()}
+	results := make([]TargetReport, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = summarize(target, cfg, attack(ctx, cfg, target))
+		}()
+	}
+	wg.Wait()
+
+	report.Elapsed = time.Since(report.Started).Round(time.Millisecond).String()
+	report.Targets = results
+	report.Passed = true
+	for _, result := range results {
+		if len(result.Violations) > 0 {
+			report.Passed = false
+		}
+	}
+	return report, nil
+}
+
+// attack sends the requests of one target at cfg.Rate until cfg.Duration has passed, then waits
+// for the outstanding ones
+func attack(ctx context.Context, cfg Config, target Target) []sample {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	interval := time.Duration(float64(time.Second) / cfg.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		mu       sync.Mutex
+		samples  []sample
+		wg       sync.WaitGroup
+		inFlight = make(chan struct{}, cfg.MaxInFlight)
+		start    = time.Now()
+	)
+	record := func(s sample) {
+		mu.Lock()
+		samples = append(samples, s)
+		mu.Unlock()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return samples
+		case <-ticker.C:
+		}
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			record(sample{at: time.Since(start), err: errDropped})
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			// Outstanding requests may finish after the run ends
+			reqCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.Timeout)
+			defer cancel()
+			sent := time.Now()
+			err := target.Op(reqCtx)
+			record(sample{at: sent.Sub(start), latency: time.Since(sent), err: err})
+		}()
+	}
+}
+
+// percentile returns the nearest-rank percentile p (0 to 100) of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(float64(len(sorted))*p/100+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// latencies returns the sorted latencies of the requests that were sent
+func latencies(samples []sample) []time.Duration {
+	var out []time.Duration
+	for _, s := range samples {
+		if !errors.Is(s.err, errDropped) {
+			out = append(out, s.latency)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
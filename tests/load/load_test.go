@@ -0,0 +1,108 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/config"
+	"github.com/opentrusty/opentrusty/tests/e2e/harness"
+)
+
+// TestPurpose: Validates the load driver against an in-process server on the memory store.
+// Scope: Load Test
+// Expected: Each target sends requests at the configured rate without failures, and the JSON and Markdown reports are written.
+// Test Case ID: LOAD-01
+func TestRun_InProcess(t *testing.T) {
+	ctx := context.Background()
+	suite := harness.StartInProcess(t, func(cfg *config.Config) {
+		cfg.RateLimit = config.RateLimitConfig{}
+	})
+	targets, err := Targets(ctx, suite, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(ctx, Config{Rate: 10, Duration: time.Second, Window: 500 * time.Millisecond}, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(report.Targets))
+	}
+	for _, target := range report.Targets {
+		if target.Requests < 5 || target.Failed > 0 || target.Dropped > 0 {
+			t.Errorf("%s: expected about 10 successful requests, got %+v", target.Name, target)
+		}
+		if target.Latency.P95 <= 0 || len(target.Windows) == 0 {
+			t.Errorf("%s: expected latencies and windows, got %+v", target.Name, target)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := report.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	md, err := os.ReadFile(filepath.Join(dir, "load-report.md"))
+	if err != nil || !strings.Contains(string(md), "| authorization_code |") {
+		t.Errorf("unexpected Markdown report: %v\n%s", err, md)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "load-report.json")); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestPurpose: Validates the latency percentiles and SLO checks of a target report.
+// Scope: Unit Test
+// Expected: Nearest-rank percentiles are reported, dropped requests count as errors but not as latencies, and each missed objective is listed.
+// Test Case ID: LOAD-02
+func TestSummarize_SLO(t *testing.T) {
+	var samples []sample
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, sample{at: time.Duration(i) * 10 * time.Millisecond, latency: time.Duration(i) * time.Millisecond})
+	}
+	samples = append(samples,
+		sample{at: time.Second, err: errDropped},
+		sample{at: time.Second, latency: time.Millisecond, err: errors.New("boom")},
+	)
+	cfg := Config{Duration: time.Second, Window: 500 * time.Millisecond}
+
+	r := summarize(Target{Name: "t", SLO: SLO{P95: 90 * time.Millisecond, P99: time.Second, MaxErrorRate: 0.01}}, cfg, samples)
+	if r.Requests != 102 || r.Failed != 1 || r.Dropped != 1 || r.Errors["boom"] != 1 {
+		t.Errorf("unexpected counts: %+v", r)
+	}
+	if r.Latency.P50 != 50 || r.Latency.P95 != 95 || r.Latency.Max != 100 {
+		t.Errorf("unexpected latencies: %+v", r.Latency)
+	}
+	if len(r.Violations) != 2 || !strings.HasPrefix(r.Violations[0], "p95 ") || !strings.HasPrefix(r.Violations[1], "error rate ") {
+		t.Errorf("expected p95 and error rate violations, got %v", r.Violations)
+	}
+	if len(r.Windows) != 3 || r.Windows[0].Requests != 49 {
+		t.Errorf("unexpected windows: %+v", r.Windows)
+	}
+
+	if r := summarize(Target{Name: "t", SLO: SLO{P95: time.Second}}, cfg, samples[:100]); len(r.Violations) != 0 {
+		t.Errorf("expected no violations, got %v", r.Violations)
+	}
+	if r := summarize(Target{Name: "t"}, cfg, nil); len(r.Violations) != 1 {
+		t.Errorf("expected a run without requests to fail, got %v", r.Violations)
+	}
+}
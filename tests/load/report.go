@@ -0,0 +1,251 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report is the outcome of a run
+type Report struct {
+	Started  time.Time      `json:"started"`
+	Duration string         `json:"duration"`
+	Elapsed  string         `json:"elapsed"`
+	Rate     float64        `json:"rate_per_target"`
+	Passed   bool           `json:"passed"`
+	Targets  []TargetReport `json:"targets"`
+}
+
+// TargetReport is the outcome of one target. Latencies are in milliseconds.
+type TargetReport struct {
+	Name       string         `json:"name"`
+	Requests   int            `json:"requests"`
+	Failed     int            `json:"failed"`
+	Dropped    int            `json:"dropped"`
+	ErrorRate  float64        `json:"error_rate"`
+	Throughput float64        `json:"throughput_per_second"` // Successful requests
+	Latency    Latency        `json:"latency_ms"`
+	SLO        SLOReport      `json:"slo"`
+	Violations []string       `json:"violations,omitempty"`
+	Errors     map[string]int `json:"errors,omitempty"` // Failures by message
+	Windows    []Window       `json:"windows"`
+}
+
+// Latency summarizes the latencies of the requests sent, in milliseconds
+type Latency struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// SLOReport is the objective a target was checked against, in milliseconds
+type SLOReport struct {
+	P95          float64 `json:"p95_ms,omitempty"`
+	P99          float64 `json:"p99_ms,omitempty"`
+	MaxErrorRate float64 `json:"max_error_rate,omitempty"`
+}
+
+// Window is the outcome of the requests sent during one window of the run; in a soak test, a p95
+// that climbs from window to window points at a leak or a growing table
+type Window struct {
+	Start    string  `json:"start"` // Offset from the start of the run
+	Requests int     `json:"requests"`
+	Failed   int     `json:"failed"`
+	P95      float64 `json:"p95_ms"`
+}
+
+// maxErrorMessages bounds the distinct failure messages kept per target
+const maxErrorMessages = 10
+
+// summarize computes the report of a target from its samples and checks its SLO
+func summarize(target Target, cfg Config, samples []sample) TargetReport {
+	r := TargetReport{
+		Name:     target.Name,
+		Requests: len(samples),
+		SLO: SLOReport{
+			P95:          ms(target.SLO.P95),
+			P99:          ms(target.SLO.P99),
+			MaxErrorRate: target.SLO.MaxErrorRate,
+		},
+	}
+	for _, s := range samples {
+		switch {
+		case errors.Is(s.err, errDropped):
+			r.Dropped++
+		case s.err != nil:
+			r.Failed++
+			if r.Errors == nil {
+				r.Errors = make(map[string]int)
+			}
+			if _, ok := r.Errors[s.err.Error()]; ok || len(r.Errors) < maxErrorMessages {
+				r.Errors[s.err.Error()]++
+			}
+		}
+	}
+	if r.Requests > 0 {
+		r.ErrorRate = float64(r.Failed+r.Dropped) / float64(r.Requests)
+	}
+	r.Throughput = float64(r.Requests-r.Failed-r.Dropped) / cfg.Duration.Seconds()
+
+	sorted := latencies(samples)
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	if len(sorted) > 0 {
+		r.Latency = Latency{
+			Mean: ms(total / time.Duration(len(sorted))),
+			P50:  ms(percentile(sorted, 50)),
+			P90:  ms(percentile(sorted, 90)),
+			P95:  ms(percentile(sorted, 95)),
+			P99:  ms(percentile(sorted, 99)),
+			Max:  ms(sorted[len(sorted)-1]),
+		}
+	}
+	r.Windows = windows(samples, cfg.Window)
+	r.Violations = check(target.SLO, r, percentile(sorted, 95), percentile(sorted, 99))
+	return r
+}
+
+// check lists the objectives of slo the target missed
+func check(slo SLO, r TargetReport, p95, p99 time.Duration) []string {
+	var violations []string
+	if r.Requests == 0 {
+		return []string{"no requests were sent"}
+	}
+	if slo.P95 > 0 && p95 > slo.P95 {
+		violations = append(violations, fmt.Sprintf("p95 %s exceeds %s", p95.Round(time.Millisecond), slo.P95))
+	}
+	if slo.P99 > 0 && p99 > slo.P99 {
+		violations = append(violations, fmt.Sprintf("p99 %s exceeds %s", p99.Round(time.Millisecond), slo.P99))
+	}
+	if slo.MaxErrorRate > 0 && r.ErrorRate > slo.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", r.ErrorRate*100, slo.MaxErrorRate*100))
+	}
+	return violations
+}
+
+func windows(samples []sample, size time.Duration) []Window {
+	buckets := make(map[int][]sample)
+	for _, s := range samples {
+		buckets[int(s.at/size)] = append(buckets[int(s.at/size)], s)
+	}
+	indexes := make([]int, 0, len(buckets))
+	for i := range buckets {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	out := make([]Window, 0, len(indexes))
+	for _, i := range indexes {
+		w := Window{Start: (time.Duration(i) * size).String(), Requests: len(buckets[i])}
+		for _, s := range buckets[i] {
+			if s.err != nil {
+				w.Failed++
+			}
+		}
+		w.P95 = ms(percentile(latencies(buckets[i]), 95))
+		out = append(out, w)
+	}
+	return out
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Violations lists the missed objectives of all targets
+func (r *Report) Violations() []string {
+	var out []string
+	for _, t := range r.Targets {
+		for _, v := range t.Violations {
+			out = append(out, t.Name+": "+v)
+		}
+	}
+	return out
+}
+
+// Write saves the report as load-report.json and load-report.md in dir
+func (r *Report) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "load-report.json"), data, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "load-report.md"), []byte(r.Markdown()), 0o644)
+}
+
+// Markdown renders the report for human review
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# OpenTrusty Load Test Report\n\n")
+	sb.WriteString(fmt.Sprintf("**Started:** %s  \n", r.Started.Format("2006-01-02 15:04:05 MST")))
+	sb.WriteString(fmt.Sprintf("**Rate:** %g requests/s per target for %s  \n", r.Rate, r.Duration))
+	status := "✅ PASSED"
+	if !r.Passed {
+		status = "❌ FAILED"
+	}
+	sb.WriteString(fmt.Sprintf("**Status:** %s\n\n", status))
+
+	sb.WriteString("| Target | Requests | Error Rate | Throughput/s | p50 ms | p95 ms | p99 ms | Max ms | SLO p95/p99 ms | Status |\n")
+	sb.WriteString("|--------|----------|------------|--------------|--------|--------|--------|--------|----------------|--------|\n")
+	for _, t := range r.Targets {
+		icon := "✅"
+		if len(t.Violations) > 0 {
+			icon = "❌"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.2f%% | %.1f | %.1f | %.1f | %.1f | %.1f | %g/%g | %s |\n",
+			t.Name, t.Requests, t.ErrorRate*100, t.Throughput, t.Latency.P50, t.Latency.P95, t.Latency.P99, t.Latency.Max, t.SLO.P95, t.SLO.P99, icon))
+	}
+
+	if violations := r.Violations(); len(violations) > 0 {
+		sb.WriteString("\n## SLO Violations\n\n")
+		for _, v := range violations {
+			sb.WriteString("- " + v + "\n")
+		}
+	}
+	for _, t := range r.Targets {
+		if len(t.Errors) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## Errors: %s\n\n", t.Name))
+		messages := make([]string, 0, len(t.Errors))
+		for message := range t.Errors {
+			messages = append(messages, message)
+		}
+		sort.Strings(messages)
+		for _, message := range messages {
+			sb.WriteString(fmt.Sprintf("- %d× `%s`\n", t.Errors[message], message))
+		}
+	}
+
+	sb.WriteString("\n---\n*Report generated by OpenTrusty Test Infrastructure*\n")
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+#!/bin/bash
+set -e
+
+# run-load.sh
+# Runs the load tests against the E2E Docker environment with rate limiting turned off.
+# LOAD_RPS (requests per second per target) and LOAD_DURATION set the run; a long
+# LOAD_DURATION such as 1h makes it a soak test.
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )"
+PROJECT_ROOT="$( cd "$SCRIPT_DIR/../.." &> /dev/null && pwd )"
+COMPOSE_FILES="-f $PROJECT_ROOT/tests/e2e/docker/docker-compose.test.yml -f $SCRIPT_DIR/docker-compose.load.yml"
+
+# Determine Docker Compose command
+if docker compose version > /dev/null 2>&1; then
+    DOCKER_COMPOSE="docker compose"
+else
+    DOCKER_COMPOSE="docker-compose"
+fi
+
+echo "--- Starting Load Test Docker Environment ---"
+$DOCKER_COMPOSE $COMPOSE_FILES up -d --build
+
+cleanup() {
+    echo "--- Tearing down Load Test Docker Environment ---"
+    $DOCKER_COMPOSE $COMPOSE_FILES down -v
+}
+trap cleanup EXIT
+
+echo "--- Waiting for OpenTrusty to be ready ---"
+MAX_RETRIES=30
+COUNT=0
+until curl -sf http://localhost:8080/health > /dev/null; do
+    if [ $COUNT -eq $MAX_RETRIES ]; then
+      echo "Timeout waiting for service"
+      $DOCKER_COMPOSE $COMPOSE_FILES logs opentrusty_test
+      exit 1
+    fi
+    echo "Waiting... ($COUNT/$MAX_RETRIES)"
+    sleep 2
+    COUNT=$((COUNT+1))
+done
+
+echo "--- Running Go Load Tests ---"
+cd "$PROJECT_ROOT"
+export OPENTRUSTY_API_URL="http://localhost:8080"
+export E2E_OPENTRUSTY_CLI="docker exec -i docker-opentrusty_test-1 ./opentrusty"
+export LOAD_REPORT_DIR="${LOAD_REPORT_DIR:-$PROJECT_ROOT/artifacts/tests/load}"
+# The run itself lasts LOAD_DURATION; leave room for provisioning and outstanding requests
+go test -count=1 -timeout 0 -run TestLoad_Compose -v "$@" ./tests/load/ --tags=load
+
+echo "--- Load Tests Passed! Report: $LOAD_REPORT_DIR/load-report.md ---"
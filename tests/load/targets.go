@@ -0,0 +1,93 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opentrusty/opentrusty/tests/e2e/harness"
+)
+
+// Default objectives. Login hashes the password with Argon2id and is the slowest request by design.
+var (
+	LoginSLO             = SLO{P95: 500 * time.Millisecond, P99: time.Second, MaxErrorRate: 0.01}
+	AuthorizationCodeSLO = SLO{P95: 250 * time.Millisecond, P99: 500 * time.Millisecond, MaxErrorRate: 0.01}
+	IntrospectionSLO     = SLO{P95: 100 * time.Millisecond, P99: 250 * time.Millisecond, MaxErrorRate: 0.01}
+)
+
+// Targets returns the login, authorization code and introspection targets for the client and user
+// of suite. It signs in once to obtain the session and the access token the last two reuse.
+//
+// The server must not rate limit the load: set RATELIMIT_RPS, RATELIMIT_LOGIN_RPS,
+// RATELIMIT_TENANT_RPS and RATELIMIT_CLIENT_RPS to 0.
+func Targets(ctx context.Context, suite *harness.Suite, maxInFlight int) ([]Target, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = max(maxInFlight, 100)
+	suite.RP.Transport = transport
+
+	session := suite.RP.NewSession(nil)
+	if err := session.Login(ctx, suite.Email, suite.Password); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	flow := harness.NewFlow()
+	code, err := session.Authorize(ctx, flow)
+	if err != nil {
+		return nil, fmt.Errorf("authorize: %w", err)
+	}
+	tokens, err := session.Exchange(ctx, code, flow.Verifier)
+	if err != nil {
+		return nil, fmt.Errorf("exchange: %w", err)
+	}
+
+	return []Target{
+		{
+			// Every login is a new browser, so each creates a session
+			Name: "login",
+			SLO:  LoginSLO,
+			Op: func(ctx context.Context) error {
+				return suite.RP.NewSession(nil).Login(ctx, suite.Email, suite.Password)
+			},
+		},
+		{
+			// Authorization request and code exchange with PKCE on the signed-in session
+			Name: "authorization_code",
+			SLO:  AuthorizationCodeSLO,
+			Op: func(ctx context.Context) error {
+				flow := harness.NewFlow()
+				code, err := session.Authorize(ctx, flow)
+				if err != nil {
+					return err
+				}
+				_, err = session.Exchange(ctx, code, flow.Verifier)
+				return err
+			},
+		},
+		{
+			Name: "introspection",
+			SLO:  IntrospectionSLO,
+			Op: func(ctx context.Context) error {
+				active, err := session.Introspect(ctx, tokens.AccessToken)
+				if err == nil && !active {
+					err = errors.New("token reported inactive")
+				}
+				return err
+			},
+		},
+	}, nil
+}
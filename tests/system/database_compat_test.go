@@ -0,0 +1,137 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package system
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty/internal/audit"
+	"github.com/opentrusty/opentrusty/internal/id"
+	"github.com/opentrusty/opentrusty/internal/identity"
+	"github.com/opentrusty/opentrusty/internal/oauth2"
+	"github.com/opentrusty/opentrusty/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// DATABASE COMPATIBILITY TESTS
+// =============================================================================
+
+// TestPurpose: Validates that migrations can be applied again to an up-to-date schema, as every start does.
+// Scope: Integration Test
+// Security: Availability (a replica restarting against a migrated database must come up)
+// Expected: Re-running all migrations succeeds on SQLite, PostgreSQL and CockroachDB (DB_COMPAT=cockroachdb).
+// Test Case ID: DB-01
+func TestDatabase_Migrations_Idempotent(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Integration test requires database")
+	}
+
+	require.NoError(t, testDB.Migrate(context.Background()), "DB-01: migrations must apply to a migrated schema")
+}
+
+// TestPurpose: Validates that concurrent writes to one row all succeed.
+// Scope: Integration Test
+// Security: Availability (contention such as repeated failed logins must not surface as errors)
+// Expected: Every concurrent lockout update succeeds; CockroachDB serialization failures are retried by the store.
+// Test Case ID: DB-02
+func TestDatabase_ConcurrentUpdates_Succeed(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Integration test requires database")
+	}
+
+	ctx := context.Background()
+	identityService := identity.NewService(testDB.Users, nil, audit.NewSlogLogger(), 5, time.Hour)
+	user, err := identityService.ProvisionIdentity(ctx, "", "contention-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{})
+	require.NoError(t, err)
+
+	const writers = 8
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = testDB.Users.UpdateLockout(ctx, user.ID, i+1, nil)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		assert.NoError(t, err, "DB-02: writer %d must succeed", i)
+	}
+	stored, err := testDB.Users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.FailedLoginAttempts >= 1 && stored.FailedLoginAttempts <= writers, "DB-02: one of the writes must win")
+}
+
+// TestPurpose: Validates that updates maintain updated_at and the record version without database triggers.
+// Scope: Integration Test
+// Security: Data integrity (optimistic concurrency and change tracking must not depend on PostgreSQL-only features)
+// Expected: Each client update bumps the version and moves updated_at forward on every backend.
+// Test Case ID: DB-03
+func TestDatabase_Update_MaintainsTimestampAndVersion(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Integration test requires database")
+	}
+
+	ctx := context.Background()
+	auditLogger := audit.NewSlogLogger()
+	tenantService := tenant.NewService(testDB.Tenants, testDB.TenantRoles, testDB.Assignments, auditLogger)
+	identityService := identity.NewService(testDB.Users, nil, auditLogger, 5, time.Hour)
+
+	creator, err := identityService.ProvisionIdentity(ctx, "", "compat-creator-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{})
+	require.NoError(t, err)
+	tn, err := tenantService.CreateTenant(ctx, "Compat - "+id.NewUUIDv7()[:8], creator.ID)
+	require.NoError(t, err)
+
+	client := &oauth2.Client{
+		ID:                      id.NewUUIDv7(),
+		TenantID:                tn.ID,
+		ClientID:                id.NewUUIDv7(),
+		ClientName:              "Compat Client",
+		ClientSecretHash:        oauth2.HashClientSecret("secret"),
+		RedirectURIs:            []string{"https://app.example.com/callback"},
+		AllowedScopes:           []string{"openid"},
+		GrantTypes:              []string{"authorization_code"},
+		ResponseTypes:           []string{"code"},
+		TokenEndpointAuthMethod: "client_secret_basic",
+		AccessTokenLifetime:     3600,
+		RefreshTokenLifetime:    86400,
+		IDTokenLifetime:         3600,
+		IsActive:                true,
+	}
+	require.NoError(t, testDB.Clients.Create(ctx, client))
+
+	client.ClientName = "Compat Client v2"
+	require.NoError(t, testDB.Clients.Update(ctx, client))
+	first, err := testDB.Clients.GetByID(ctx, client.ID)
+	require.NoError(t, err)
+
+	// SQLite stores timestamps to the second
+	time.Sleep(1100 * time.Millisecond)
+	client.ClientName = "Compat Client v3"
+	require.NoError(t, testDB.Clients.Update(ctx, client))
+	second, err := testDB.Clients.GetByID(ctx, client.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Compat Client v3", second.ClientName)
+	assert.Equal(t, first.Version+1, second.Version, "DB-03: update must bump the version")
+	assert.True(t, second.UpdatedAt.After(first.UpdatedAt), "DB-03: update must move updated_at forward")
+}
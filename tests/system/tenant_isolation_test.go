@@ -12,26 +12,36 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package system provides integration tests that run against a real PostgreSQL database.
+// Package system provides integration tests that run against a real database.
 //
-// Test Execution:
+// By default the tests use a temporary SQLite database, so they run as part of
+// `go test ./...` without any external services.
+//
+// To run against PostgreSQL:
 //
 //	INTEGRATION_TEST=true go test -v ./tests/system/...
 //
-// Prerequisites:
+// Prerequisites (PostgreSQL only):
 //
 //	docker compose up -d postgres
 //
+// To run against CockroachDB (DB_COMPAT=cockroachdb), without any CI services:
+//
+//	docker compose --profile cockroachdb up -d cockroachdb
+//	make test-system-cockroachdb
+//
 // Test Categories:
-//   - TEN-*: Tenant isolation tests
+//   - TEN-*: Tenant isolation tests, including tenant-scoped codes and tokens
 //   - AUT-*: Authorization tests
 //   - OA2-*: OAuth2 flow tests
 //   - OID-*: OIDC compliance tests
+//   - DB-*: Database compatibility tests (migrations, contention, timestamps)
 package system
 
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -40,51 +50,63 @@ import (
 	"github.com/opentrusty/opentrusty/internal/identity"
 	"github.com/opentrusty/opentrusty/internal/oauth2"
 	"github.com/opentrusty/opentrusty/internal/oidc"
+	"github.com/opentrusty/opentrusty/internal/store"
 	"github.com/opentrusty/opentrusty/internal/store/postgres"
+	"github.com/opentrusty/opentrusty/internal/store/sqlite"
 	"github.com/opentrusty/opentrusty/internal/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 // testDB is the shared database connection for integration tests
-var testDB *postgres.DB
+var testDB *store.Store
 
 // TestMain sets up and tears down the test database connection
 func TestMain(m *testing.M) {
-	// Skip if not integration test
-	if os.Getenv("INTEGRATION_TEST") != "true" {
-		os.Exit(0)
+	ctx := context.Background()
+	cfg := store.Config{Driver: store.DriverSQLite}
+
+	if os.Getenv("INTEGRATION_TEST") == "true" {
+		cfg = store.Config{
+			Driver: store.DriverPostgres,
+			Postgres: postgres.Config{
+				Host:         getEnvOrDefault("DB_HOST", "localhost"),
+				Port:         getEnvOrDefault("DB_PORT", "5432"),
+				User:         getEnvOrDefault("DB_USER", "opentrusty"),
+				Password:     getEnvOrDefault("DB_PASSWORD", "opentrusty_dev_password"),
+				Database:     getEnvOrDefault("DB_NAME", "opentrusty"),
+				SSLMode:      "disable",
+				MaxOpenConns: 5,
+				MaxIdleConns: 2,
+				Compat:       os.Getenv("DB_COMPAT"),
+			},
+		}
+	} else {
+		dir, err := os.MkdirTemp("", "opentrusty-system-*")
+		if err != nil {
+			panic("failed to create temp dir: " + err.Error())
+		}
+		defer os.RemoveAll(dir)
+		cfg.SQLite = sqlite.Config{Path: filepath.Join(dir, "system.db")}
 	}
 
 	// Setup database
-	ctx := context.Background()
-	db, err := postgres.New(ctx, postgres.Config{
-		Host:         getEnvOrDefault("DB_HOST", "localhost"),
-		Port:         getEnvOrDefault("DB_PORT", "5432"),
-		User:         getEnvOrDefault("DB_USER", "opentrusty"),
-		Password:     getEnvOrDefault("DB_PASSWORD", "opentrusty_dev_password"),
-		Database:     getEnvOrDefault("DB_NAME", "opentrusty"),
-		SSLMode:      "disable",
-		MaxOpenConns: 5,
-		MaxIdleConns: 2,
-	})
+	db, err := store.Open(ctx, cfg)
 	if err != nil {
 		panic("failed to connect to test database: " + err.Error())
 	}
 	testDB = db
 
 	// Apply migrations
-	if err := db.Migrate(ctx, postgres.InitialSchema); err != nil {
-		// Ignore errors for already existing tables
-		_ = err
+	if err := db.Migrate(ctx); err != nil {
+		panic("failed to migrate test database: " + err.Error())
 	}
 
-	// Run tests
-	code := m.Run()
+	// Run tests; returning lets deferred cleanup run before the exit code is reported
+	m.Run()
 
 	// Cleanup
 	testDB.Close()
-	os.Exit(code)
 }
 
 func getEnvOrDefault(key, def string) string {
@@ -111,15 +133,15 @@ func TestTenant_Isolation_UserFromTenantACannotAccessTenantBResources(t *testing
 	ctx := context.Background()
 
 	// Setup repositories
-	tenantRepo := postgres.NewTenantRepository(testDB)
-	roleRepo := postgres.NewTenantRoleRepository(testDB)
-	authzRepo := postgres.NewAssignmentRepository(testDB)
+	tenantRepo := testDB.Tenants
+	roleRepo := testDB.TenantRoles
+	authzRepo := testDB.Assignments
 	auditLogger := audit.NewSlogLogger()
 
 	tenantService := tenant.NewService(tenantRepo, roleRepo, authzRepo, auditLogger)
 
 	// Create creator users (required for RBAC assignment constraint)
-	identityRepo := postgres.NewUserRepository(testDB)
+	identityRepo := testDB.Users
 	identityService := identity.NewService(identityRepo, nil, auditLogger, 5, time.Hour)
 
 	creatorA, err := identityService.ProvisionIdentity(ctx, "", "creator-a-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{FullName: "Creator A"})
@@ -144,7 +166,7 @@ func TestTenant_Isolation_UserFromTenantACannotAccessTenantBResources(t *testing
 	user, err := identityService.ProvisionIdentity(ctx, tenantA.ID, "user-a-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{FullName: "User A"})
 	require.NoError(t, err)
 
-	err = tenantService.AssignRole(ctx, tenantA.ID, user.ID, tenant.RoleTenantMember, "")
+	err = tenantService.AssignRole(ctx, tenantA.ID, user.ID, tenant.RoleTenantMember, tenant.GranterSystem)
 	require.NoError(t, err, "TEN-01: Failed to assign role in Tenant A")
 
 	// Verify user has role in Tenant A
@@ -178,15 +200,15 @@ func TestAuthz_TenantAdmin_CanManageUsersInOwnTenant(t *testing.T) {
 	ctx := context.Background()
 
 	// Setup
-	tenantRepo := postgres.NewTenantRepository(testDB)
-	roleRepo := postgres.NewTenantRoleRepository(testDB)
-	authzRepo := postgres.NewAssignmentRepository(testDB)
+	tenantRepo := testDB.Tenants
+	roleRepo := testDB.TenantRoles
+	authzRepo := testDB.Assignments
 	auditLogger := audit.NewSlogLogger()
 
 	tenantService := tenant.NewService(tenantRepo, roleRepo, authzRepo, auditLogger)
 
 	// Create creator and tenant
-	identityRepo := postgres.NewUserRepository(testDB)
+	identityRepo := testDB.Users
 	identityService := identity.NewService(identityRepo, nil, auditLogger, 5, time.Hour)
 	creator, err := identityService.ProvisionIdentity(ctx, "", "admin-creator-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{FullName: "Admin Creator"})
 	require.NoError(t, err)
@@ -201,7 +223,7 @@ func TestAuthz_TenantAdmin_CanManageUsersInOwnTenant(t *testing.T) {
 	require.NoError(t, err)
 
 	// Assign admin role (granted by system)
-	err = tenantService.AssignRole(ctx, testTenant.ID, admin.ID, tenant.RoleTenantAdmin, "")
+	err = tenantService.AssignRole(ctx, testTenant.ID, admin.ID, tenant.RoleTenantAdmin, tenant.GranterSystem)
 	require.NoError(t, err, "AUT-01: Failed to assign admin role")
 
 	// Admin assigns member role
@@ -227,14 +249,14 @@ func TestAuthz_RoleAssignment_InvalidRoleNameIsRejected(t *testing.T) {
 
 	ctx := context.Background()
 
-	tenantRepo := postgres.NewTenantRepository(testDB)
-	roleRepo := postgres.NewTenantRoleRepository(testDB)
-	authzRepo := postgres.NewAssignmentRepository(testDB)
+	tenantRepo := testDB.Tenants
+	roleRepo := testDB.TenantRoles
+	authzRepo := testDB.Assignments
 	auditLogger := audit.NewSlogLogger()
 
 	tenantService := tenant.NewService(tenantRepo, roleRepo, authzRepo, auditLogger)
 
-	identityRepo := postgres.NewUserRepository(testDB)
+	identityRepo := testDB.Users
 	identityService := identity.NewService(identityRepo, nil, auditLogger, 5, time.Hour)
 	creator, err := identityService.ProvisionIdentity(ctx, "", "role-creator-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{FullName: "Role Creator"})
 	require.NoError(t, err)
@@ -255,7 +277,7 @@ func TestAuthz_RoleAssignment_InvalidRoleNameIsRejected(t *testing.T) {
 	}
 
 	for _, invalidRole := range invalidRoles {
-		err := tenantService.AssignRole(ctx, testTenant.ID, user.ID, invalidRole, "")
+		err := tenantService.AssignRole(ctx, testTenant.ID, user.ID, invalidRole, tenant.GranterSystem)
 		assert.Error(t, err,
 			"AUT-02 SECURITY: Invalid role '%s' should be rejected", invalidRole)
 	}
@@ -283,18 +305,18 @@ func TestOAuth2_AuthorizationCode_OneTimeUseEnforced(t *testing.T) {
 	ctx := context.Background()
 
 	// Setup repositories
-	tenantRepo := postgres.NewTenantRepository(testDB)
-	roleRepo := postgres.NewTenantRoleRepository(testDB)
-	clientRepo := postgres.NewClientRepository(testDB)
-	codeRepo := postgres.NewAuthorizationCodeRepository(testDB)
-	accessRepo := postgres.NewAccessTokenRepository(testDB)
-	refreshRepo := postgres.NewRefreshTokenRepository(testDB)
-	authzRepo := postgres.NewAssignmentRepository(testDB)
+	tenantRepo := testDB.Tenants
+	roleRepo := testDB.TenantRoles
+	clientRepo := testDB.Clients
+	codeRepo := testDB.Codes
+	accessRepo := testDB.AccessTokens
+	refreshRepo := testDB.RefreshTokens
+	authzRepo := testDB.Assignments
 	auditLogger := audit.NewSlogLogger()
 
 	tenantService := tenant.NewService(tenantRepo, roleRepo, authzRepo, auditLogger)
 
-	identityRepo := postgres.NewUserRepository(testDB)
+	identityRepo := testDB.Users
 	identityService := identity.NewService(identityRepo, nil, auditLogger, 5, time.Hour)
 	creator, err := identityService.ProvisionIdentity(ctx, "", "oauth-creator-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{FullName: "OAuth Creator"})
 	require.NoError(t, err)
@@ -320,7 +342,7 @@ func TestOAuth2_AuthorizationCode_OneTimeUseEnforced(t *testing.T) {
 		IDTokenLifetime:         3600,
 		IsActive:                true,
 	}
-	err = clientRepo.Create(client)
+	err = clientRepo.Create(ctx, client)
 	require.NoError(t, err, "OA2-01: Failed to create client")
 
 	// Create OIDC service
@@ -347,7 +369,7 @@ func TestOAuth2_AuthorizationCode_OneTimeUseEnforced(t *testing.T) {
 		CodeChallenge:       "test-challenge",
 		CodeChallengeMethod: "plain",
 	}
-	code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID)
+	code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID, "")
 	require.NoError(t, err, "OA2-01: Failed to create auth code")
 
 	// First exchange - should succeed
@@ -386,18 +408,18 @@ func TestOAuth2_RefreshToken_RevocationPreventsUsage(t *testing.T) {
 	ctx := context.Background()
 
 	// Setup
-	tenantRepo := postgres.NewTenantRepository(testDB)
-	roleRepo := postgres.NewTenantRoleRepository(testDB)
-	clientRepo := postgres.NewClientRepository(testDB)
-	codeRepo := postgres.NewAuthorizationCodeRepository(testDB)
-	accessRepo := postgres.NewAccessTokenRepository(testDB)
-	refreshRepo := postgres.NewRefreshTokenRepository(testDB)
-	authzRepo := postgres.NewAssignmentRepository(testDB)
+	tenantRepo := testDB.Tenants
+	roleRepo := testDB.TenantRoles
+	clientRepo := testDB.Clients
+	codeRepo := testDB.Codes
+	accessRepo := testDB.AccessTokens
+	refreshRepo := testDB.RefreshTokens
+	authzRepo := testDB.Assignments
 	auditLogger := audit.NewSlogLogger()
 
 	tenantService := tenant.NewService(tenantRepo, roleRepo, authzRepo, auditLogger)
 
-	identityRepo := postgres.NewUserRepository(testDB)
+	identityRepo := testDB.Users
 	identityService := identity.NewService(identityRepo, nil, auditLogger, 5, time.Hour)
 	creator, err := identityService.ProvisionIdentity(ctx, "", "revoke-creator-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{FullName: "Revoke Creator"})
 	require.NoError(t, err)
@@ -422,7 +444,7 @@ func TestOAuth2_RefreshToken_RevocationPreventsUsage(t *testing.T) {
 		IDTokenLifetime:         3600,
 		IsActive:                true,
 	}
-	err = clientRepo.Create(client)
+	err = clientRepo.Create(ctx, client)
 	require.NoError(t, err)
 
 	oidcService, err := oidc.NewService("https://auth.example.com")
@@ -444,7 +466,7 @@ func TestOAuth2_RefreshToken_RevocationPreventsUsage(t *testing.T) {
 		Scope:         "openid",
 		CodeChallenge: "revoke-challenge",
 	}
-	code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID)
+	code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID, "")
 	require.NoError(t, err)
 
 	tokenResp, err := oauth2Service.ExchangeCodeForToken(ctx, &oauth2.TokenRequest{
@@ -459,7 +481,7 @@ func TestOAuth2_RefreshToken_RevocationPreventsUsage(t *testing.T) {
 	require.NotEmpty(t, tokenResp.RefreshToken, "OA2-02: Must have refresh token")
 
 	// Revoke the refresh token
-	err = oauth2Service.RevokeRefreshToken(ctx, tokenResp.RefreshToken, client.ClientID)
+	err = oauth2Service.RevokeRefreshToken(ctx, testTenant.ID, tokenResp.RefreshToken, client.ClientID)
 	require.NoError(t, err, "OA2-02: Revocation should succeed")
 
 	// CRITICAL: Attempt to use revoked token - must fail
@@ -473,6 +495,125 @@ func TestOAuth2_RefreshToken_RevocationPreventsUsage(t *testing.T) {
 		"OA2-02 SECURITY: Revoked refresh token MUST NOT be usable")
 }
 
+// TestPurpose: Validates that authorization codes and tokens are only found within the tenant they were issued in.
+// Scope: Integration Test
+// Security: Cross-tenant replay of OAuth2 artifacts (a client of another tenant presenting a stolen code or token)
+// RelatedDocs: docs/architecture/tenant-context-resolution.md
+// Expected: A code, refresh token or access token of tenant A is not found when looked up for tenant B, cannot be redeemed or revoked by tenant B's client, and keeps working for tenant A.
+// Test Case ID: TEN-10
+func TestTenant_Isolation_OAuth2ArtifactsAreTenantScoped(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Integration test requires database")
+	}
+
+	os.Setenv("OPENID_KEY_ENCRYPTION_KEY", "01234567890123456789012345678901")
+	defer os.Unsetenv("OPENID_KEY_ENCRYPTION_KEY")
+
+	ctx := context.Background()
+	auditLogger := audit.NewSlogLogger()
+	tenantService := tenant.NewService(testDB.Tenants, testDB.TenantRoles, testDB.Assignments, auditLogger)
+	identityService := identity.NewService(testDB.Users, nil, auditLogger, 5, time.Hour)
+	oauth2Service := oauth2.NewService(
+		testDB.Clients, testDB.Codes, testDB.AccessTokens, testDB.RefreshTokens, auditLogger, nil,
+		5*time.Minute, 1*time.Hour, 720*time.Hour,
+	)
+
+	creator, err := identityService.ProvisionIdentity(ctx, "", "artifacts-creator-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{})
+	require.NoError(t, err)
+
+	newClient := func(tenantID, secret string) *oauth2.Client {
+		client := &oauth2.Client{
+			ID:                      id.NewUUIDv7(),
+			TenantID:                tenantID,
+			ClientID:                id.NewUUIDv7(),
+			ClientName:              "Artifact Scope Client",
+			ClientSecretHash:        oauth2.HashClientSecret(secret),
+			RedirectURIs:            []string{"https://app.example.com/callback"},
+			AllowedScopes:           []string{"openid"},
+			GrantTypes:              []string{"authorization_code", "refresh_token"},
+			ResponseTypes:           []string{"code"},
+			TokenEndpointAuthMethod: "client_secret_basic",
+			AccessTokenLifetime:     3600,
+			RefreshTokenLifetime:    86400,
+			IDTokenLifetime:         3600,
+			IsActive:                true,
+		}
+		require.NoError(t, testDB.Clients.Create(ctx, client))
+		return client
+	}
+
+	tenantA, err := tenantService.CreateTenant(ctx, "Artifacts A - "+id.NewUUIDv7()[:8], creator.ID)
+	require.NoError(t, err)
+	tenantB, err := tenantService.CreateTenant(ctx, "Artifacts B - "+id.NewUUIDv7()[:8], creator.ID)
+	require.NoError(t, err)
+	clientA := newClient(tenantA.ID, "secret-a")
+	clientB := newClient(tenantB.ID, "secret-b")
+	// Tenant B's client is registered with the same redirect URI, so only the tenant boundary stops the replay
+	userA, err := identityService.ProvisionIdentity(ctx, tenantA.ID, "artifacts-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{})
+	require.NoError(t, err)
+
+	code, err := oauth2Service.CreateAuthorizationCode(ctx, &oauth2.AuthorizeRequest{
+		ClientID:     clientA.ClientID,
+		RedirectURI:  "https://app.example.com/callback",
+		ResponseType: "code",
+		Scope:        "openid",
+	}, userA.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, tenantA.ID, code.TenantID, "TEN-10: code must belong to its client's tenant")
+
+	// Authorization code
+	_, err = testDB.Codes.GetByCode(ctx, tenantB.ID, code.Code)
+	assert.ErrorIs(t, err, oauth2.ErrCodeNotFound, "TEN-10 SECURITY: code must not be found in another tenant")
+	assert.Error(t, testDB.Codes.MarkAsUsed(ctx, tenantB.ID, code.Code), "TEN-10 SECURITY: another tenant must not consume the code")
+
+	_, err = oauth2Service.ExchangeCodeForToken(ctx, &oauth2.TokenRequest{
+		GrantType:    "authorization_code",
+		ClientID:     clientB.ClientID,
+		ClientSecret: "secret-b",
+		RedirectURI:  "https://app.example.com/callback",
+		Code:         code.Code,
+	})
+	assert.Error(t, err, "TEN-10 SECURITY: tenant B's client must not redeem tenant A's code")
+
+	tokens, err := oauth2Service.ExchangeCodeForToken(ctx, &oauth2.TokenRequest{
+		GrantType:    "authorization_code",
+		ClientID:     clientA.ClientID,
+		ClientSecret: "secret-a",
+		RedirectURI:  "https://app.example.com/callback",
+		Code:         code.Code,
+	})
+	require.NoError(t, err, "TEN-10: the code must still be redeemable in its own tenant")
+	require.NotEmpty(t, tokens.RefreshToken)
+
+	// Refresh token
+	_, err = oauth2Service.RefreshAccessToken(ctx, &oauth2.TokenRequest{
+		GrantType:    "refresh_token",
+		ClientID:     clientB.ClientID,
+		ClientSecret: "secret-b",
+		RefreshToken: tokens.RefreshToken,
+	})
+	assert.Error(t, err, "TEN-10 SECURITY: tenant B's client must not use tenant A's refresh token")
+	assert.ErrorIs(t, oauth2Service.RevokeRefreshToken(ctx, tenantB.ID, tokens.RefreshToken, clientA.ClientID), oauth2.ErrTokenNotFound,
+		"TEN-10 SECURITY: tenant B must not revoke tenant A's refresh token")
+
+	// Access token
+	_, err = oauth2Service.ValidateAccessToken(ctx, tenantB.ID, tokens.AccessToken)
+	assert.ErrorIs(t, err, oauth2.ErrTokenNotFound, "TEN-10 SECURITY: access token must not validate in another tenant")
+	_, err = oauth2Service.LookupToken(ctx, tenantB.ID, tokens.AccessToken)
+	assert.ErrorIs(t, err, oauth2.ErrTokenNotFound)
+
+	at, err := oauth2Service.ValidateAccessToken(ctx, tenantA.ID, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, tenantA.ID, at.TenantID)
+	_, err = oauth2Service.RefreshAccessToken(ctx, &oauth2.TokenRequest{
+		GrantType:    "refresh_token",
+		ClientID:     clientA.ClientID,
+		ClientSecret: "secret-a",
+		RefreshToken: tokens.RefreshToken,
+	})
+	assert.NoError(t, err, "TEN-10: the refresh token must keep working in its own tenant")
+}
+
 // =============================================================================
 // OIDC COMPLIANCE TESTS
 // =============================================================================
@@ -494,18 +635,18 @@ func TestOIDC_TokenExchange_IDTokenOnlyWithOpenIDScope(t *testing.T) {
 	ctx := context.Background()
 
 	// Setup
-	tenantRepo := postgres.NewTenantRepository(testDB)
-	roleRepo := postgres.NewTenantRoleRepository(testDB)
-	clientRepo := postgres.NewClientRepository(testDB)
-	codeRepo := postgres.NewAuthorizationCodeRepository(testDB)
-	accessRepo := postgres.NewAccessTokenRepository(testDB)
-	refreshRepo := postgres.NewRefreshTokenRepository(testDB)
-	authzRepo := postgres.NewAssignmentRepository(testDB)
+	tenantRepo := testDB.Tenants
+	roleRepo := testDB.TenantRoles
+	clientRepo := testDB.Clients
+	codeRepo := testDB.Codes
+	accessRepo := testDB.AccessTokens
+	refreshRepo := testDB.RefreshTokens
+	authzRepo := testDB.Assignments
 	auditLogger := audit.NewSlogLogger()
 
 	tenantService := tenant.NewService(tenantRepo, roleRepo, authzRepo, auditLogger)
 
-	identityRepo := postgres.NewUserRepository(testDB)
+	identityRepo := testDB.Users
 	identityService := identity.NewService(identityRepo, nil, auditLogger, 5, time.Hour)
 	creator, err := identityService.ProvisionIdentity(ctx, "", "oidc-creator-"+id.NewUUIDv7()[:8]+"@example.com", identity.Profile{FullName: "OIDC Creator"})
 	require.NoError(t, err)
@@ -529,7 +670,7 @@ func TestOIDC_TokenExchange_IDTokenOnlyWithOpenIDScope(t *testing.T) {
 		IDTokenLifetime:         3600,
 		IsActive:                true,
 	}
-	err = clientRepo.Create(client)
+	err = clientRepo.Create(ctx, client)
 	require.NoError(t, err)
 
 	oidcService, err := oidc.NewService("https://auth.example.com")
@@ -552,7 +693,7 @@ func TestOIDC_TokenExchange_IDTokenOnlyWithOpenIDScope(t *testing.T) {
 			Scope:         "openid profile", // Has openid
 			CodeChallenge: "oidc-challenge-1",
 		}
-		code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID)
+		code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID, "")
 		require.NoError(t, err)
 
 		resp, err := oauth2Service.ExchangeCodeForToken(ctx, &oauth2.TokenRequest{
@@ -577,7 +718,7 @@ func TestOIDC_TokenExchange_IDTokenOnlyWithOpenIDScope(t *testing.T) {
 			Scope:         "profile", // NO openid
 			CodeChallenge: "oidc-challenge-2",
 		}
-		code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID)
+		code, err := oauth2Service.CreateAuthorizationCode(ctx, authReq, user.ID, "")
 		require.NoError(t, err)
 
 		resp, err := oauth2Service.ExchangeCodeForToken(ctx, &oauth2.TokenRequest{